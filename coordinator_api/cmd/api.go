@@ -2,17 +2,27 @@ package cmd
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net/http"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/catalystcommunity/app-utils-go/errorutils"
 	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/admission"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/chaos"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/handlers"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/hooks"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/jobcontrol"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/pubsub"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/secrets"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/postgres_store"
 	"github.com/gammazero/workerpool"
 	"github.com/sirupsen/logrus"
@@ -28,6 +38,7 @@ func Serve() error {
 
 	// set stores
 	store.AppStore = postgres_store.PostgresStore
+	installChaos()
 
 	// init stores and defer any functions we need to
 	deferredStoreFuncs := initStores()
@@ -35,27 +46,16 @@ func Serve() error {
 		defer deferredFunc()
 	}
 
+	if err := registerHooks(); err != nil {
+		return fmt.Errorf("failed to register hooks: %w", err)
+	}
+
 	// Initialize Corndogs client if configured
-	var corndogsClient *corndogs.Client
-	if config.CornDogsBaseURL != "" {
-		client, err := corndogs.NewClient(corndogs.Config{
-			BaseURL:      config.CornDogsBaseURL,
-			QueueName:    config.DefaultQueueName,
-			Timeout:      time.Duration(config.DefaultTimeout) * time.Second,
-			MaxRetries:   3,
-			RetryBackoff: time.Second,
-		})
-		if err != nil {
-			logging.Log.WithError(err).Error("Failed to initialize Corndogs client")
-			// Continue without Corndogs - jobs will be created but not queued
-		} else {
-			corndogsClient = client
-			defer client.Close()
-			logging.Log.Info("Corndogs client initialized")
-		}
-	} else {
-		logging.Log.Warn("Corndogs not configured - jobs will not be queued")
+	corndogsClient, closeCorndogs, err := newCorndogsClient()
+	if err != nil {
+		return err
 	}
+	defer closeCorndogs()
 
 	// Wire the pub/sub bus and start the Postgres LISTEN bridge. Each
 	// coordinator replica holds one dedicated connection; notifications
@@ -72,20 +72,238 @@ func Serve() error {
 		logging.Log.Warn("No pgx pool available; WebSocket streams disabled")
 	}
 
+	// Flag running jobs that look hung and apply the stuck-job policy.
+	if models.IsValidStuckJobAction(config.StuckJobAction) {
+		jobcontrol.NewStuckJobMonitor(store.AppStore, corndogsClient, pubsub.NewPublisher(postgres_store.PgxPool()), jobcontrol.StuckJobMonitorConfig{
+			Interval: time.Duration(config.StuckJobIntervalSeconds) * time.Second,
+			Policy: models.StuckJobPolicy{
+				Action:        config.StuckJobAction,
+				NoLogAfter:    time.Duration(config.StuckJobNoLogMinutes) * time.Minute,
+				P95Multiplier: config.StuckJobP95Multiplier,
+			},
+			MinBaselineRuns: config.StuckJobMinBaselineRuns,
+		}).Start(context.Background())
+	} else {
+		logging.Log.WithField("action", config.StuckJobAction).Error("Invalid REACTORCIDE_STUCK_JOB_ACTION; stuck job monitor disabled")
+	}
+
+	// Boost queued jobs nearing their deadline and alert on missed ones.
+	if config.DeadlineCheckIntervalSeconds > 0 {
+		jobcontrol.NewDeadlineMonitor(store.AppStore, corndogsClient, pubsub.NewPublisher(postgres_store.PgxPool()), jobcontrol.DeadlineMonitorConfig{
+			Interval:         time.Duration(config.DeadlineCheckIntervalSeconds) * time.Second,
+			BoostWindow:      time.Duration(config.DeadlineBoostWindowMinutes) * time.Minute,
+			MaxBoostPriority: config.DeadlineBoostMaxPriority,
+		}).Start(context.Background())
+	}
+
+	// Move old finished jobs and webhook events to the archive tables.
+	if config.JobArchiveAfterDays > 0 {
+		jobcontrol.NewJobArchiver(store.AppStore, jobcontrol.JobArchiveConfig{
+			Interval:     time.Duration(config.JobArchiveIntervalMinutes) * time.Minute,
+			ArchiveAfter: time.Duration(config.JobArchiveAfterDays) * 24 * time.Hour,
+			BatchSize:    config.JobArchiveBatchSize,
+		}).Start(context.Background())
+	}
+
+	// Purge projects and jobs that have been in the trash too long.
+	if config.TrashRetentionDays > 0 {
+		jobcontrol.NewTrashPurger(store.AppStore, jobcontrol.TrashPurgeConfig{
+			Interval:  time.Duration(config.TrashPurgeIntervalMinutes) * time.Minute,
+			Retention: time.Duration(config.TrashRetentionDays) * 24 * time.Hour,
+		}).Start(context.Background())
+	}
+
+	// Refuse to start with a CORS policy that can't be applied as written.
+	if err := config.ValidateCORS(); err != nil {
+		return fmt.Errorf("invalid CORS configuration: %w", err)
+	}
+	if err := config.ValidateWorkerAccess(); err != nil {
+		return fmt.Errorf("invalid worker access configuration: %w", err)
+	}
+
 	// Create the handler with routes
+	handlers.SetExpectedMigrationVersion(GetExpectedMigrationVersion())
 	handler := handlers.NewRouter(corndogsClient)
 
+	// Catch master keys that are missing or wrong before the first secret
+	// read fails on them.
+	if health, err := handlers.CheckMasterKeys(); err != nil {
+		logging.Log.WithError(err).Error("Failed to check master key health")
+	} else if health != nil && !health.Healthy {
+		for _, problem := range health.Problems {
+			logging.Log.WithField("problem", problem).Error("Master key health check failed")
+		}
+		if config.MasterKeyStrictStartup {
+			return fmt.Errorf("master key health check failed: %s", strings.Join(health.Problems, "; "))
+		}
+	}
+
+	// Re-encrypt secrets as their tokens age, so old keys can be retired.
+	secrets.SetMaxTokenAge(time.Duration(config.SecretMaxTokenAgeDays) * 24 * time.Hour)
+	handlers.StartSecretReencryption(context.Background(),
+		time.Duration(config.SecretReencryptIntervalMinutes)*time.Minute,
+		time.Duration(config.SecretMaxTokenAgeDays)*24*time.Hour)
+
+	// Release deploy jobs once the freeze windows holding them end.
+	handlers.StartFreezeWindowSweep(context.Background(), time.Duration(config.FreezeSweepIntervalSeconds)*time.Second)
+
+	// Advance native merge queues: test each queued PR's merge, then land it.
+	if config.MergeQueueIntervalSeconds > 0 {
+		handlers.StartMergeQueue(context.Background(), time.Duration(config.MergeQueueIntervalSeconds)*time.Second)
+	}
+
+	// Keep LDAP users' roles in line with their directory groups.
+	handlers.StartLDAPSync(context.Background(), time.Duration(config.LDAPSyncIntervalMinutes)*time.Minute)
+
+	// Acknowledge webhooks once stored and process them in the background.
+	if config.WebhookAsync {
+		handlers.StartWebhookQueue(context.Background(), handlers.WebhookQueueConfig{
+			Workers:      config.WebhookWorkers,
+			QueueSize:    config.WebhookQueueSize,
+			MaxAttempts:  config.WebhookMaxAttempts,
+			RetryBackoff: time.Duration(config.WebhookRetryBackoffSeconds) * time.Second,
+		})
+	}
+
+	// Turn webhooks away with a 503 while the coordinator is overloaded.
+	handlers.StartWebhookLoadShedding(context.Background(), handlers.LoadSheddingConfig{
+		QueueFullPercent:   config.WebhookShedQueuePercent,
+		BacklogThreshold:   int64(config.WebhookShedBacklog),
+		DBLatencyThreshold: time.Duration(config.WebhookShedDbLatencyMillis) * time.Millisecond,
+		SampleInterval:     time.Duration(config.WebhookShedSampleSeconds) * time.Second,
+		RetryAfter:         time.Duration(config.WebhookShedRetryAfterSeconds) * time.Second,
+	})
+
+	// Deliver queued commit statuses and PR comments, retrying failures.
+	handlers.StartStatusOutbox(context.Background(), time.Duration(config.VCSOutboxIntervalSeconds)*time.Second)
+
+	// Move finished jobs' logs to cold storage and delete them as they age.
+	handlers.StartLogLifecycle(context.Background(), jobcontrol.LogLifecycleConfig{
+		Interval:         time.Duration(config.LogLifecycleIntervalMinutes) * time.Minute,
+		ColdAfter:        time.Duration(config.LogColdAfterDays) * 24 * time.Hour,
+		DeleteAfter:      time.Duration(config.LogDeleteAfterDays) * 24 * time.Hour,
+		ColdStorageClass: config.LogColdStorageClass,
+		ColdPrefix:       config.LogColdPrefix,
+	})
+
+	// Export each day's jobs to the object store for the data warehouse.
+	if config.JobExportPrefix != "" {
+		handlers.StartJobExport(context.Background(), jobcontrol.JobExportConfig{
+			Interval:     time.Duration(config.JobExportIntervalMinutes) * time.Minute,
+			Prefix:       config.JobExportPrefix,
+			Format:       config.JobExportFormat,
+			SettleAfter:  time.Duration(config.JobExportSettleHours) * time.Hour,
+			BackfillDays: config.JobExportBackfillDays,
+		})
+	}
+
+	// Serve the worker endpoints over mutual TLS on their own port.
+	if config.WorkerTLSPort > 0 {
+		workerServer, err := newWorkerTLSServer(handler)
+		if err != nil {
+			return fmt.Errorf("failed to set up the worker TLS listener: %w", err)
+		}
+		logging.Log.Infof("Starting worker mTLS server on port %d", config.WorkerTLSPort)
+		go func() {
+			err := workerServer.ListenAndServeTLS(config.WorkerTLSCertFile, config.WorkerTLSKeyFile)
+			logging.Log.WithError(err).Fatal("Worker mTLS server exited")
+		}()
+	}
+
 	// Log startup information
 	logging.Log.Infof("Starting HTTP server on port %d", config.Port)
 
 	// Start the HTTP server
-	err := http.ListenAndServe(fmt.Sprintf(":%d", config.Port), handler)
+	err = http.ListenAndServe(fmt.Sprintf(":%d", config.Port), handler)
 
 	// ListenAndServe always eventually errors out, so we log it and return it
 	errorutils.LogOnErr(nil, "ListenAndServe exited with: ", err)
 	return err
 }
 
+// newWorkerTLSServer creates the worker endpoints' listener: TLS with the
+// REACTORCIDE_WORKER_TLS_CERT_FILE certificate, accepting only clients
+// whose certificates REACTORCIDE_WORKER_TLS_CLIENT_CA_FILE signed.
+func newWorkerTLSServer(handler http.Handler) (*http.Server, error) {
+	caPEM, err := os.ReadFile(config.WorkerTLSClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the worker client CA: %w", err)
+	}
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificates found in %s", config.WorkerTLSClientCAFile)
+	}
+	// Fail now, not on the first handshake, if the key pair is unusable.
+	if _, err := tls.LoadX509KeyPair(config.WorkerTLSCertFile, config.WorkerTLSKeyFile); err != nil {
+		return nil, fmt.Errorf("failed to load the worker TLS certificate: %w", err)
+	}
+	return &http.Server{
+		Addr:              fmt.Sprintf(":%d", config.WorkerTLSPort),
+		Handler:           handlers.WorkerEndpointsOnly(handler),
+		ReadHeaderTimeout: 30 * time.Second,
+		TLSConfig: &tls.Config{
+			ClientAuth: tls.RequireAndVerifyClientCert,
+			ClientCAs:  clientCAs,
+			MinVersion: tls.VersionTLS12,
+		},
+	}, nil
+}
+
+// newCorndogsClient connects to Corndogs if REACTORCIDE_CORNDOGS_BASE_URL
+// is set. A client that fails to start is logged and left nil, as is an
+// unconfigured one: jobs are then created but not queued. Only a bad
+// payload encryption key is an error. closeClient releases the client.
+func newCorndogsClient() (client corndogs.ClientInterface, closeClient func(), err error) {
+	closeClient = func() {}
+	if config.CornDogsBaseURL == "" {
+		logging.Log.Warn("Corndogs not configured - jobs will not be queued")
+		return nil, closeClient, nil
+	}
+	var sealer *corndogs.PayloadSealer
+	if config.PayloadPublicKeyPath != "" {
+		if sealer, err = corndogs.LoadPayloadSealer(config.PayloadPublicKeyPath); err != nil {
+			return nil, closeClient, fmt.Errorf("failed to load payload encryption key: %w", err)
+		}
+		logging.Log.WithField("key_id", sealer.KeyID()).Info("Corndogs payload encryption enabled")
+	}
+	c, err := corndogs.NewClient(corndogs.Config{
+		BaseURL:       config.CornDogsBaseURL,
+		QueueName:     config.DefaultQueueName,
+		Timeout:       time.Duration(config.DefaultTimeout) * time.Second,
+		MaxRetries:    3,
+		RetryBackoff:  time.Second,
+		PayloadSealer: sealer,
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("Failed to initialize Corndogs client")
+		// Continue without Corndogs - jobs will be created but not queued
+		return nil, closeClient, nil
+	}
+	logging.Log.Info("Corndogs client initialized")
+	return chaos.WrapCorndogs(c), func() { c.Close() }, nil
+}
+
+// registerHooks registers the admission policy check and the lifecycle
+// hook commands configured in the environment. See internal/hooks.
+func registerHooks() error {
+	admission.RegisterJobHook(store.AppStore)
+	return hooks.RegisterCommands(map[hooks.Point]string{
+		hooks.PreJobCreate:     config.HookPreJobCreate,
+		hooks.PostStatusChange: config.HookPostStatusChange,
+		hooks.PreSecretInject:  config.HookPreSecretInject,
+	}, time.Duration(config.HookTimeoutSeconds)*time.Second)
+}
+
+// installChaos installs chaos mode's fault injector if it's enabled.
+// Call it before the stores are initialized. See internal/chaos.
+func installChaos() {
+	if !config.ChaosEnabled {
+		return
+	}
+	chaos.Install(chaos.NewInjector(chaos.ConfigFromEnv()))
+	logging.Log.Warn("CHAOS MODE IS ENABLED: faults are being injected on purpose; never run this in production")
+}
+
 func initStores() []func() {
 	// initialize stores using a worker pool to speed up startup
 	pool := workerpool.New(5)
@@ -0,0 +1,577 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/secrets"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/urfave/cli/v2"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// backupFormatVersion is bumped when the layout of a backup changes in a
+// way older restores can't read.
+const backupFormatVersion = 1
+
+// Files and directories of a backup.
+const (
+	backupManifestFile = "manifest.json"
+	backupDatabaseFile = "database.dump"
+	backupObjectsDir   = "objects"
+)
+
+// backupManifest describes a backup. It's written last, so a directory
+// without one is an incomplete backup.
+type backupManifest struct {
+	FormatVersion int       `json:"format_version"`
+	CreatedAt     time.Time `json:"created_at"`
+	// SchemaVersion is the latest migration applied to the database.
+	SchemaVersion int64              `json:"schema_version"`
+	Database      backupFile         `json:"database"`
+	Objects       []backupFile       `json:"objects"`
+	ObjectsBytes  int64              `json:"objects_bytes"`
+	MasterKeys    []backupMasterKey  `json:"master_keys"`
+	Skipped       []string           `json:"skipped,omitempty"`
+	Options       backupManifestOpts `json:"options"`
+}
+
+// backupManifestOpts records how the backup was taken.
+type backupManifestOpts struct {
+	ObjectsIncluded    bool `json:"objects_included"`
+	KeyMaterialWrapped bool `json:"key_material_wrapped"`
+}
+
+// backupFile is a file of the backup with its checksum. For objects,
+// Path is the object key, stored under objects/.
+type backupFile struct {
+	Path        string `json:"path"`
+	Size        int64  `json:"size"`
+	SHA256      string `json:"sha256"`
+	ContentType string `json:"content_type,omitempty"`
+}
+
+// backupMasterKey is a master_keys row. The table's data is left out of
+// the database dump: key material only leaves the database wrapped with
+// the backup passphrase, and without one it isn't backed up at all.
+type backupMasterKey struct {
+	KeyID       string    `json:"key_id"`
+	Name        string    `json:"name"`
+	CreatedAt   time.Time `json:"created_at"`
+	IsActive    bool      `json:"is_active"`
+	IsPrimary   bool      `json:"is_primary"`
+	Description string    `json:"description,omitempty"`
+	Canary      []byte    `json:"canary,omitempty"`
+	// WrappedKeyMaterial is the key material encrypted with the backup
+	// passphrase (see secrets.WrapWithPassphrase).
+	WrappedKeyMaterial []byte `json:"wrapped_key_material,omitempty"`
+	// KeyMaterialExcluded is set for a key whose material was in the
+	// database but wasn't backed up; it has to be supplied through
+	// REACTORCIDE_MASTER_KEYS after a restore.
+	KeyMaterialExcluded bool `json:"key_material_excluded,omitempty"`
+}
+
+var backupDBURIFlag = &cli.StringFlag{
+	Name:        "db-uri",
+	Aliases:     []string{"db"},
+	Usage:       "The uri to use to connect to the db",
+	Destination: &config.DbUri,
+	EnvVars:     []string{"REACTORCIDE_DB_URI", "DB_URI"},
+}
+
+var backupPassphraseFlag = &cli.StringFlag{
+	Name:    "key-passphrase",
+	Usage:   "Passphrase that wraps master key material in the backup (omit to leave key material out)",
+	EnvVars: []string{"REACTORCIDE_BACKUP_PASSPHRASE"},
+}
+
+// BackupCommand snapshots the database and the object store into a
+// directory that RestoreCommand can restore into a fresh install.
+var BackupCommand = &cli.Command{
+	Name:  "backup",
+	Usage: "Back up the database and object store into a directory",
+	Flags: []cli.Flag{
+		backupDBURIFlag,
+		backupPassphraseFlag,
+		&cli.StringFlag{
+			Name:     "output",
+			Aliases:  []string{"o"},
+			Usage:    "Directory to write the backup to (must not exist or be empty)",
+			Required: true,
+		},
+		&cli.BoolFlag{
+			Name:  "skip-objects",
+			Usage: "Back up only the database",
+		},
+		&cli.StringFlag{
+			Name:  "pg-dump",
+			Value: "pg_dump",
+			Usage: "Path to the pg_dump binary",
+		},
+	},
+	Action: backupAction,
+}
+
+// RestoreCommand restores a backup written by BackupCommand.
+var RestoreCommand = &cli.Command{
+	Name:  "restore",
+	Usage: "Restore a backup into a fresh install, or verify it",
+	Flags: []cli.Flag{
+		backupDBURIFlag,
+		backupPassphraseFlag,
+		&cli.StringFlag{
+			Name:     "input",
+			Aliases:  []string{"i"},
+			Usage:    "Directory of the backup to restore",
+			Required: true,
+		},
+		&cli.BoolFlag{
+			Name:  "verify",
+			Usage: "Only check the backup's checksums and dump, restoring nothing",
+		},
+		&cli.BoolFlag{
+			Name:  "skip-objects",
+			Usage: "Restore only the database",
+		},
+		&cli.StringFlag{
+			Name:  "pg-restore",
+			Value: "pg_restore",
+			Usage: "Path to the pg_restore binary",
+		},
+	},
+	Action: restoreAction,
+}
+
+func backupAction(ctx *cli.Context) error {
+	dir := ctx.String("output")
+	passphrase := ctx.String("key-passphrase")
+	if err := prepareBackupDir(dir); err != nil {
+		return err
+	}
+
+	db, err := gorm.Open(postgres.Open(config.DbUri), &gorm.Config{})
+	if err != nil {
+		return fmt.Errorf("failed to connect to the database: %w", err)
+	}
+	manifest := &backupManifest{
+		FormatVersion: backupFormatVersion,
+		CreatedAt:     time.Now().UTC(),
+		Options:       backupManifestOpts{ObjectsIncluded: !ctx.Bool("skip-objects"), KeyMaterialWrapped: passphrase != ""},
+	}
+	if err := db.Raw("SELECT COALESCE(MAX(version_id), 0) FROM goose_db_version WHERE is_applied").Scan(&manifest.SchemaVersion).Error; err != nil {
+		return fmt.Errorf("failed to read the schema version: %w", err)
+	}
+	var masterKeys []models.MasterKey
+	if err := db.Order("created_at ASC").Find(&masterKeys).Error; err != nil {
+		return fmt.Errorf("failed to read master keys: %w", err)
+	}
+	if manifest.MasterKeys, err = backupMasterKeys(masterKeys, passphrase); err != nil {
+		return err
+	}
+
+	// pg_dump reads from a single snapshot, so the dump is consistent on
+	// its own. Objects are copied after it: every object a dumped row
+	// points at was written before the dump started.
+	logging.Log.Info("Dumping the database")
+	dumpPath := filepath.Join(dir, backupDatabaseFile)
+	dump, err := pgCommand(ctx.Context, ctx.String("pg-dump"), config.DbUri,
+		"--format=custom", "--no-owner", "--no-privileges", "--exclude-table-data=master_keys", "--file="+dumpPath)
+	if err != nil {
+		return err
+	}
+	if err := dump.Run(); err != nil {
+		return fmt.Errorf("pg_dump failed: %w", err)
+	}
+	if manifest.Database, err = checksumFile(dir, backupDatabaseFile); err != nil {
+		return err
+	}
+
+	if manifest.Options.ObjectsIncluded {
+		objectStore, err := configuredObjectStore()
+		if err != nil {
+			return fmt.Errorf("failed to initialize the object store: %w", err)
+		}
+		logging.Log.Info("Copying the object store")
+		if manifest.Objects, manifest.Skipped, err = backupObjects(ctx.Context, objectStore, dir); err != nil {
+			return err
+		}
+		for _, obj := range manifest.Objects {
+			manifest.ObjectsBytes += obj.Size
+		}
+	}
+
+	if err := writeBackupManifest(dir, manifest); err != nil {
+		return err
+	}
+	logging.Log.Infof("Backup written to %s: schema version %d, %d objects (%d bytes), %d master keys",
+		dir, manifest.SchemaVersion, len(manifest.Objects), manifest.ObjectsBytes, len(manifest.MasterKeys))
+	for _, key := range manifest.Skipped {
+		logging.Log.Warnf("Skipped object %s: its key can't be stored in a backup", key)
+	}
+	if !manifest.Options.KeyMaterialWrapped {
+		logging.Log.Warn("No key passphrase given: master key material was left out; restores need REACTORCIDE_MASTER_KEYS")
+	}
+	return nil
+}
+
+func restoreAction(ctx *cli.Context) error {
+	dir := ctx.String("input")
+	passphrase := ctx.String("key-passphrase")
+	pgRestore := ctx.String("pg-restore")
+
+	manifest, err := verifyBackup(dir)
+	if err != nil {
+		return err
+	}
+	list, err := pgCommand(ctx.Context, pgRestore, "", "--list", filepath.Join(dir, backupDatabaseFile))
+	if err != nil {
+		return err
+	}
+	list.Stdout = io.Discard
+	if err := list.Run(); err != nil {
+		return fmt.Errorf("the database dump is unreadable: %w", err)
+	}
+	masterKeys, err := restoredMasterKeys(manifest.MasterKeys, passphrase)
+	if err != nil {
+		return err
+	}
+	if ctx.Bool("verify") {
+		logging.Log.Infof("Backup %s verified: taken %s, schema version %d, %d objects (%d bytes), %d master keys",
+			dir, manifest.CreatedAt.Format(time.RFC3339), manifest.SchemaVersion, len(manifest.Objects), manifest.ObjectsBytes, len(masterKeys))
+		return nil
+	}
+
+	db, err := gorm.Open(postgres.Open(config.DbUri), &gorm.Config{})
+	if err != nil {
+		return fmt.Errorf("failed to connect to the database: %w", err)
+	}
+	var tables int64
+	if err := db.Raw("SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = 'public'").Scan(&tables).Error; err != nil {
+		return fmt.Errorf("failed to inspect the database: %w", err)
+	}
+	if tables > 0 {
+		return fmt.Errorf("the database already has %d tables; restore into a fresh database, before running migrate", tables)
+	}
+
+	// The schema goes first, then master_keys (left out of the dump), so
+	// the org keys in the data section have keys to reference.
+	dumpPath := filepath.Join(dir, backupDatabaseFile)
+	for _, section := range []string{"pre-data", "data", "post-data"} {
+		logging.Log.Infof("Restoring the database: %s", section)
+		restore, err := pgCommand(ctx.Context, pgRestore, config.DbUri, "--no-owner", "--no-privileges", "--exit-on-error", "--section="+section, dumpPath)
+		if err != nil {
+			return err
+		}
+		if err := restore.Run(); err != nil {
+			return fmt.Errorf("pg_restore of %s failed: %w", section, err)
+		}
+		if section == "pre-data" && len(masterKeys) > 0 {
+			if err := db.Create(&masterKeys).Error; err != nil {
+				return fmt.Errorf("failed to restore master keys: %w", err)
+			}
+		}
+	}
+
+	if manifest.Options.ObjectsIncluded && !ctx.Bool("skip-objects") {
+		objectStore, err := configuredObjectStore()
+		if err != nil {
+			return fmt.Errorf("failed to initialize the object store: %w", err)
+		}
+		logging.Log.Infof("Restoring %d objects", len(manifest.Objects))
+		if err := restoreObjects(ctx.Context, objectStore, dir, manifest.Objects); err != nil {
+			return err
+		}
+	}
+
+	logging.Log.Infof("Restored backup %s taken %s", dir, manifest.CreatedAt.Format(time.RFC3339))
+	for _, key := range manifest.MasterKeys {
+		if key.KeyMaterialExcluded && key.IsActive {
+			logging.Log.Warnf("Master key %s was restored without its key material; supply it through REACTORCIDE_MASTER_KEYS", key.Name)
+		}
+	}
+	return nil
+}
+
+// prepareBackupDir creates dir, which must not exist or be empty.
+func prepareBackupDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err == nil && len(entries) > 0 {
+		return fmt.Errorf("backup directory %s is not empty", dir)
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return os.MkdirAll(dir, 0700)
+}
+
+// configuredObjectStore builds the object store from the environment, the
+// secondary store included, so objects that failed over are backed up too.
+func configuredObjectStore() (objects.ObjectStore, error) {
+	objectStoreConfig := objects.ObjectStoreConfig{
+		Type: config.ObjectStoreType,
+		Config: map[string]string{
+			"base_path": config.ObjectStoreBasePath,
+			"bucket":    config.ObjectStoreBucket,
+			"prefix":    config.ObjectStorePrefix,
+		},
+	}
+	if config.ObjectStoreSecondaryType != "" {
+		objectStoreConfig.Secondary = &objects.ObjectStoreConfig{
+			Type: config.ObjectStoreSecondaryType,
+			Config: map[string]string{
+				"base_path": config.ObjectStoreSecondaryBasePath,
+				"bucket":    config.ObjectStoreSecondaryBucket,
+				"prefix":    config.ObjectStoreSecondaryPrefix,
+				"region":    config.ObjectStoreSecondaryRegion,
+				"endpoint":  config.ObjectStoreSecondaryEndpoint,
+			},
+		}
+		objectStoreConfig.FailoverThreshold = config.ObjectStoreFailoverThreshold
+		objectStoreConfig.FailoverRetryAfter = time.Duration(config.ObjectStoreFailoverRetrySeconds) * time.Second
+	}
+	return objects.NewObjectStore(objectStoreConfig)
+}
+
+// pgCommand builds a pg_dump or pg_restore command against dbURI, if it's
+// set. The password of a URI goes through PGPASSWORD so it stays out of
+// the process list.
+func pgCommand(ctx context.Context, binary, dbURI string, args ...string) (*exec.Cmd, error) {
+	env := os.Environ()
+	if dbURI != "" {
+		u, err := url.Parse(dbURI)
+		if err != nil {
+			return nil, errors.New("invalid database URI")
+		}
+		if u.User != nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+			if password, ok := u.User.Password(); ok {
+				env = append(env, "PGPASSWORD="+password)
+				u.User = url.User(u.User.Username())
+				dbURI = u.String()
+			}
+		}
+		args = append(args, "--dbname="+dbURI)
+	}
+	cmd := exec.CommandContext(ctx, binary, args...)
+	cmd.Env = env
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	return cmd, nil
+}
+
+// backupMasterKeys returns the master keys to record in the manifest,
+// their material wrapped with passphrase, or left out without one.
+func backupMasterKeys(keys []models.MasterKey, passphrase string) ([]backupMasterKey, error) {
+	backedUp := make([]backupMasterKey, 0, len(keys))
+	for _, key := range keys {
+		entry := backupMasterKey{
+			KeyID:       key.KeyID,
+			Name:        key.Name,
+			CreatedAt:   key.CreatedAt,
+			IsActive:    key.IsActive,
+			IsPrimary:   key.IsPrimary,
+			Description: key.Description,
+			Canary:      key.Canary,
+		}
+		switch {
+		case len(key.KeyMaterial) == 0:
+		case passphrase == "":
+			entry.KeyMaterialExcluded = true
+		default:
+			wrapped, err := secrets.WrapWithPassphrase(passphrase, key.KeyMaterial)
+			if err != nil {
+				return nil, fmt.Errorf("failed to wrap master key %s: %w", key.Name, err)
+			}
+			entry.WrappedKeyMaterial = wrapped
+		}
+		backedUp = append(backedUp, entry)
+	}
+	return backedUp, nil
+}
+
+// restoredMasterKeys turns the manifest's master keys back into rows,
+// unwrapping their material with passphrase.
+func restoredMasterKeys(keys []backupMasterKey, passphrase string) ([]models.MasterKey, error) {
+	restored := make([]models.MasterKey, 0, len(keys))
+	for _, key := range keys {
+		row := models.MasterKey{
+			KeyID:       key.KeyID,
+			CreatedAt:   key.CreatedAt,
+			Name:        key.Name,
+			IsActive:    key.IsActive,
+			IsPrimary:   key.IsPrimary,
+			Description: key.Description,
+			Canary:      key.Canary,
+		}
+		if len(key.WrappedKeyMaterial) > 0 {
+			if passphrase == "" {
+				return nil, fmt.Errorf("master key %s is wrapped; the key passphrase is required", key.Name)
+			}
+			material, err := secrets.UnwrapWithPassphrase(passphrase, key.WrappedKeyMaterial)
+			if err != nil {
+				return nil, fmt.Errorf("failed to unwrap master key %s (wrong passphrase?): %w", key.Name, err)
+			}
+			row.KeyMaterial = material
+		}
+		restored = append(restored, row)
+	}
+	return restored, nil
+}
+
+// backupObjects copies every object in objectStore under dir/objects,
+// returning their checksums and the keys that couldn't be stored as a
+// path.
+func backupObjects(ctx context.Context, objectStore objects.ObjectStore, dir string) ([]backupFile, []string, error) {
+	infos, err := objectStore.List(ctx, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list objects: %w", err)
+	}
+	backedUp := make([]backupFile, 0, len(infos))
+	var skipped []string
+	for _, info := range infos {
+		if !filepath.IsLocal(filepath.FromSlash(info.Key)) {
+			skipped = append(skipped, info.Key)
+			continue
+		}
+		file, err := backupObject(ctx, objectStore, dir, info)
+		if errors.Is(err, objects.ErrNotFound) {
+			// Deleted since it was listed.
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		backedUp = append(backedUp, file)
+	}
+	return backedUp, skipped, nil
+}
+
+func backupObject(ctx context.Context, objectStore objects.ObjectStore, dir string, info objects.ObjectInfo) (backupFile, error) {
+	reader, err := objectStore.Get(ctx, info.Key)
+	if err != nil {
+		return backupFile{}, err
+	}
+	defer reader.Close()
+
+	path := filepath.Join(dir, backupObjectsDir, filepath.FromSlash(info.Key))
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return backupFile{}, err
+	}
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return backupFile{}, err
+	}
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(out, hash), reader)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return backupFile{}, fmt.Errorf("failed to copy object %s: %w", info.Key, err)
+	}
+	return backupFile{Path: info.Key, Size: size, SHA256: hex.EncodeToString(hash.Sum(nil)), ContentType: info.ContentType}, nil
+}
+
+// restoreObjects puts the backed-up objects into objectStore, overwriting
+// any with the same key.
+func restoreObjects(ctx context.Context, objectStore objects.ObjectStore, dir string, files []backupFile) error {
+	for _, file := range files {
+		if err := restoreObject(ctx, objectStore, dir, file); err != nil {
+			return fmt.Errorf("failed to restore object %s: %w", file.Path, err)
+		}
+	}
+	return nil
+}
+
+func restoreObject(ctx context.Context, objectStore objects.ObjectStore, dir string, file backupFile) error {
+	in, err := os.Open(filepath.Join(dir, backupObjectsDir, filepath.FromSlash(file.Path)))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	return objectStore.Put(ctx, file.Path, in, file.ContentType)
+}
+
+// verifyBackup reads the manifest in dir and checks the size and checksum
+// of the dump and of every object against it.
+func verifyBackup(dir string) (*backupManifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, backupManifestFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%s has no %s; the backup is incomplete", dir, backupManifestFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var manifest backupManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid backup manifest: %w", err)
+	}
+	if manifest.FormatVersion != backupFormatVersion {
+		return nil, fmt.Errorf("unsupported backup format version %d (expected %d)", manifest.FormatVersion, backupFormatVersion)
+	}
+
+	if err := verifyBackupFile(dir, manifest.Database); err != nil {
+		return nil, err
+	}
+	var problems []error
+	for _, obj := range manifest.Objects {
+		if !filepath.IsLocal(filepath.FromSlash(obj.Path)) {
+			problems = append(problems, fmt.Errorf("object key %s escapes the backup", obj.Path))
+			continue
+		}
+		if err := verifyBackupFile(filepath.Join(dir, backupObjectsDir), obj); err != nil {
+			problems = append(problems, err)
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%d of %d objects failed verification: %w", len(problems), len(manifest.Objects), errors.Join(problems...))
+	}
+	return &manifest, nil
+}
+
+func verifyBackupFile(dir string, want backupFile) error {
+	got, err := checksumFile(dir, want.Path)
+	if err != nil {
+		return err
+	}
+	if got.Size != want.Size || got.SHA256 != want.SHA256 {
+		return fmt.Errorf("%s doesn't match the manifest (size %d, expected %d)", want.Path, got.Size, want.Size)
+	}
+	return nil
+}
+
+// checksumFile returns the size and SHA-256 of dir/path.
+func checksumFile(dir, path string) (backupFile, error) {
+	f, err := os.Open(filepath.Join(dir, filepath.FromSlash(path)))
+	if err != nil {
+		return backupFile{}, err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return backupFile{}, err
+	}
+	return backupFile{Path: path, Size: size, SHA256: hex.EncodeToString(hash.Sum(nil))}, nil
+}
+
+func writeBackupManifest(dir string, manifest *backupManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, backupManifestFile), data, 0600)
+}
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+func TestBackupObjectsVerifyAndRestore(t *testing.T) {
+	ctx := context.Background()
+	source := objects.NewMemoryObjectStore()
+	contents := map[string]string{
+		"logs/job-1/stdout.json":   `[{"line":"hello"}]`,
+		"artifacts/job-1/out.tgz":  "binary",
+		"exports/jobs/date=x.json": "{}",
+	}
+	for key, content := range contents {
+		if err := source.Put(ctx, key, strings.NewReader(content), "application/json"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dir := filepath.Join(t.TempDir(), "backup")
+	if err := prepareBackupDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	files, skipped, err := backupObjects(ctx, source, dir)
+	if err != nil {
+		t.Fatalf("backupObjects failed: %v", err)
+	}
+	if len(files) != len(contents) || len(skipped) != 0 {
+		t.Fatalf("backed up %d objects, skipped %v; want %d", len(files), skipped, len(contents))
+	}
+	if err := os.WriteFile(filepath.Join(dir, backupDatabaseFile), []byte("dump"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	database, err := checksumFile(dir, backupDatabaseFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := &backupManifest{FormatVersion: backupFormatVersion, Database: database, Objects: files}
+	if err := writeBackupManifest(dir, manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	verified, err := verifyBackup(dir)
+	if err != nil {
+		t.Fatalf("verifyBackup failed: %v", err)
+	}
+
+	target := objects.NewMemoryObjectStore()
+	if err := restoreObjects(ctx, target, dir, verified.Objects); err != nil {
+		t.Fatalf("restoreObjects failed: %v", err)
+	}
+	for key, content := range contents {
+		reader, err := target.Get(ctx, key)
+		if err != nil {
+			t.Fatalf("restored store lacks %s: %v", key, err)
+		}
+		got, _ := io.ReadAll(reader)
+		reader.Close()
+		if string(got) != content {
+			t.Errorf("%s = %q, want %q", key, got, content)
+		}
+	}
+
+	if err := prepareBackupDir(dir); err == nil {
+		t.Error("expected a non-empty backup directory to be refused")
+	}
+
+	// A tampered object fails verification.
+	if err := os.WriteFile(filepath.Join(dir, backupObjectsDir, "logs", "job-1", "stdout.json"), []byte(`[{"line":"HELLO"}]`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifyBackup(dir); err == nil || !strings.Contains(err.Error(), "logs/job-1/stdout.json") {
+		t.Errorf("expected the tampered object to fail verification, got %v", err)
+	}
+
+	// So does a manifest key escaping the backup.
+	manifest.Objects = []backupFile{{Path: "../manifest.json"}}
+	if err := writeBackupManifest(dir, manifest); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifyBackup(dir); err == nil || !strings.Contains(err.Error(), "escapes") {
+		t.Errorf("expected the escaping key to fail verification, got %v", err)
+	}
+
+	if err := os.Remove(filepath.Join(dir, backupManifestFile)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifyBackup(dir); err == nil || !strings.Contains(err.Error(), "incomplete") {
+		t.Errorf("expected a backup without a manifest to be incomplete, got %v", err)
+	}
+}
+
+func TestBackupMasterKeys(t *testing.T) {
+	material := bytes.Repeat([]byte{7}, 32)
+	keys := []models.MasterKey{
+		{KeyID: "k1", Name: "mk-1", IsActive: true, IsPrimary: true, KeyMaterial: material},
+		{KeyID: "k2", Name: "mk-env", IsActive: true},
+	}
+
+	excluded, err := backupMasterKeys(keys, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !excluded[0].KeyMaterialExcluded || excluded[0].WrappedKeyMaterial != nil || excluded[1].KeyMaterialExcluded {
+		t.Errorf("without a passphrase only stored material should be marked excluded: %+v", excluded)
+	}
+	restored, err := restoredMasterKeys(excluded, "")
+	if err != nil || restored[0].KeyMaterial != nil || !restored[0].IsPrimary {
+		t.Errorf("restoredMasterKeys = %+v, %v", restored, err)
+	}
+
+	wrapped, err := backupMasterKeys(keys, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wrapped[0].WrappedKeyMaterial) == 0 || bytes.Contains(wrapped[0].WrappedKeyMaterial, material) {
+		t.Fatalf("key material should be wrapped: %+v", wrapped[0])
+	}
+	if _, err := restoredMasterKeys(wrapped, ""); err == nil {
+		t.Error("expected wrapped keys to need the passphrase")
+	}
+	if _, err := restoredMasterKeys(wrapped, "wrong"); err == nil {
+		t.Error("expected a wrong passphrase to fail")
+	}
+	restored, err = restoredMasterKeys(wrapped, "correct horse")
+	if err != nil {
+		t.Fatalf("restoredMasterKeys failed: %v", err)
+	}
+	if !bytes.Equal(restored[0].KeyMaterial, material) || restored[1].KeyMaterial != nil {
+		t.Errorf("unexpected restored key material: %+v", restored)
+	}
+}
+
+func TestPgCommandHidesPassword(t *testing.T) {
+	cmd, err := pgCommand(context.Background(), "pg_dump", "postgresql://user:s3cret@db:5432/app?sslmode=disable", "--format=custom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := strings.Join(cmd.Args, " ")
+	if strings.Contains(args, "s3cret") {
+		t.Errorf("password leaked into args: %s", args)
+	}
+	if !strings.Contains(args, "--dbname=postgresql://user@db:5432/app?sslmode=disable") {
+		t.Errorf("unexpected args: %s", args)
+	}
+	if cmd.Env[len(cmd.Env)-1] != "PGPASSWORD=s3cret" {
+		t.Error("expected the password in PGPASSWORD")
+	}
+}
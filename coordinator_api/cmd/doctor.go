@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/doctor"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/handlers"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/postgres_store"
+	"github.com/urfave/cli/v2"
+)
+
+// DoctorCommand runs the self-diagnostics: against a running coordinator
+// (GET /api/v1/admin/doctor) with --api-url, otherwise in this process with
+// the coordinator's own configuration, which also works when it won't
+// start.
+var DoctorCommand = &cli.Command{
+	Name:  "doctor",
+	Usage: "Check a coordinator install and print how to fix what's wrong",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:        "db-uri",
+			Aliases:     []string{"db"},
+			Usage:       "The uri to use to connect to the db",
+			Destination: &config.DbUri,
+			EnvVars:     []string{"REACTORCIDE_DB_URI", "DB_URI"},
+		},
+		&cli.StringFlag{
+			Name:  "api-url",
+			Usage: "Check this running coordinator instead (e.g., http://localhost:6080); needs an admin token",
+		},
+		&cli.StringFlag{
+			Name:    "token",
+			Aliases: []string{"t"},
+			Usage:   "API token for --api-url",
+			EnvVars: []string{"REACTORCIDE_API_TOKEN"},
+		},
+		&cli.DurationFlag{
+			Name:  "check-timeout",
+			Value: doctor.DefaultCheckTimeout,
+			Usage: "How long each check may take",
+		},
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "Print the report as JSON",
+		},
+	},
+	Action: doctorAction,
+}
+
+func doctorAction(ctx *cli.Context) error {
+	var report doctor.Report
+	if apiURL := strings.TrimSuffix(ctx.String("api-url"), "/"); apiURL != "" {
+		token := ctx.String("token")
+		if token == "" {
+			return fmt.Errorf("API token is required with --api-url (use --token or REACTORCIDE_API_TOKEN)")
+		}
+		var err error
+		if report, err = fetchDoctorReport(ctx.Context, apiURL, token); err != nil {
+			return err
+		}
+	} else {
+		report = runLocalDoctor(ctx.Context, ctx.Duration("check-timeout"))
+	}
+
+	if ctx.Bool("json") {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		printDoctorReport(os.Stdout, report)
+	}
+	if !report.Healthy {
+		return fmt.Errorf("doctor found failing checks (score %d/100)", report.Score)
+	}
+	return nil
+}
+
+// runLocalDoctor sets up the database, Corndogs client, object store and
+// master keys the way serve does, without migrating, and checks them. A
+// database it can't connect to fails the database check rather than the
+// command.
+func runLocalDoctor(ctx context.Context, timeout time.Duration) doctor.Report {
+	store.AppStore = postgres_store.PostgresStore
+	if cleanup, err := store.AppStore.Initialize(); err != nil {
+		logging.Log.WithError(err).Error("Failed to connect to the database")
+	} else if cleanup != nil {
+		defer cleanup()
+	}
+
+	corndogsClient, closeCorndogs, err := newCorndogsClient()
+	if err != nil {
+		logging.Log.WithError(err).Error("Failed to set up the Corndogs client")
+	}
+	defer closeCorndogs()
+
+	handlers.SetExpectedMigrationVersion(GetExpectedMigrationVersion())
+	handlers.NewRouter(corndogsClient)
+	return doctor.Run(ctx, doctor.Checks(handlers.DoctorDeps()), timeout)
+}
+
+// fetchDoctorReport runs the doctor on the coordinator at apiURL.
+func fetchDoctorReport(ctx context.Context, apiURL, token string) (doctor.Report, error) {
+	var report doctor.Report
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL+"/api/v1/admin/doctor", nil)
+	if err != nil {
+		return report, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := (&http.Client{Timeout: 5 * time.Minute}).Do(req)
+	if err != nil {
+		return report, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return report, fmt.Errorf("API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return report, fmt.Errorf("failed to decode doctor report: %w", err)
+	}
+	return report, nil
+}
+
+// printDoctorReport writes report for a person: one line per check, with
+// the fix under each one that didn't pass.
+func printDoctorReport(out io.Writer, report doctor.Report) {
+	verdict := "healthy"
+	if !report.Healthy {
+		verdict = "unhealthy"
+	}
+	fmt.Fprintf(out, "Health score: %d/100 (%s)\n\n", report.Score, verdict)
+	width := 0
+	for _, result := range report.Checks {
+		if len(result.Name) > width {
+			width = len(result.Name)
+		}
+	}
+	for _, result := range report.Checks {
+		fmt.Fprintf(out, "  %-6s %-*s  %s\n", "["+strings.ToUpper(result.Status)+"]", width, result.Name, result.Message)
+		if result.Remediation != "" {
+			fmt.Fprintf(out, "  %-6s %-*s  fix: %s\n", "", width, "", result.Remediation)
+		}
+	}
+}
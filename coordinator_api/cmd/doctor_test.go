@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/doctor"
+)
+
+func TestFetchDoctorReport(t *testing.T) {
+	want := doctor.Report{Score: 75, Healthy: true, Checks: []doctor.Result{
+		{Name: doctor.CheckDatabase, Status: doctor.StatusPass, Message: "connected"},
+		{Name: doctor.CheckWebhookSecret, Status: doctor.StatusWarn, Message: "no global webhook secret", Remediation: "set one"},
+	}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/admin/doctor" || r.Header.Get("Authorization") != "Bearer api-token" {
+			t.Fatalf("unexpected request %s", r.URL.String())
+		}
+		_ = json.NewEncoder(w).Encode(want)
+	}))
+	defer server.Close()
+
+	report, err := fetchDoctorReport(context.Background(), server.URL, "api-token")
+	if err != nil {
+		t.Fatalf("fetchDoctorReport: %v", err)
+	}
+	if report.Score != 75 || len(report.Checks) != 2 || report.Checks[1].Remediation != "set one" {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	var out bytes.Buffer
+	printDoctorReport(&out, report)
+	for _, line := range []string{
+		"Health score: 75/100 (healthy)",
+		"[PASS] database        connected",
+		"[WARN] webhook_secret  no global webhook secret",
+		"fix: set one",
+	} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("report is missing %q:\n%s", line, out.String())
+		}
+	}
+}
+
+func TestFetchDoctorReportReportsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"forbidden"}`, http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	if _, err := fetchDoctorReport(context.Background(), server.URL, "api-token"); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("expected a 403 error, got %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// ExportJobsCommand streams job records from a remote coordinator's job
+// export (GET /api/v1/admin/exports/jobs), following its cursor page by
+// page, so exports of any size never sit in memory.
+var ExportJobsCommand = &cli.Command{
+	Name:  "export-jobs",
+	Usage: "Export job records from a remote Reactorcide coordinator (admin)",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "api-url",
+			Aliases: []string{"u"},
+			Usage:   "Coordinator API URL (e.g., http://localhost:6080)",
+			EnvVars: []string{"REACTORCIDE_API_URL"},
+		},
+		&cli.StringFlag{
+			Name:    "token",
+			Aliases: []string{"t"},
+			Usage:   "API token for authentication",
+			EnvVars: []string{"REACTORCIDE_API_TOKEN"},
+		},
+		&cli.StringFlag{
+			Name:  "format",
+			Value: "ndjson",
+			Usage: "Export format: ndjson or csv",
+		},
+		&cli.StringFlag{
+			Name:  "since",
+			Usage: "Only jobs created at or after this RFC 3339 time",
+		},
+		&cli.StringFlag{
+			Name:  "until",
+			Usage: "Only jobs created before this RFC 3339 time",
+		},
+		&cli.StringFlag{
+			Name:  "project-id",
+			Usage: "Only this project's jobs",
+		},
+		&cli.IntFlag{
+			Name:  "page-size",
+			Value: 1000,
+			Usage: "Jobs per request (at most 10000)",
+		},
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Usage:   "Output file (default: stdout)",
+		},
+	},
+	Action: exportJobsAction,
+}
+
+func exportJobsAction(ctx *cli.Context) error {
+	apiURL := strings.TrimSuffix(ctx.String("api-url"), "/")
+	if apiURL == "" {
+		return fmt.Errorf("API URL is required (use --api-url or REACTORCIDE_API_URL)")
+	}
+	format := ctx.String("format")
+	if format != "ndjson" && format != "csv" {
+		return fmt.Errorf("invalid format: %s (must be ndjson or csv)", format)
+	}
+
+	token := ctx.String("token")
+	if token == "" {
+		var err error
+		if token, err = promptForSecret("REACTORCIDE_API_TOKEN", "API token: "); err != nil {
+			return err
+		}
+	}
+	if token == "" {
+		return fmt.Errorf("API token is required (use --token or REACTORCIDE_API_TOKEN)")
+	}
+
+	query := url.Values{}
+	query.Set("format", format)
+	query.Set("limit", fmt.Sprint(ctx.Int("page-size")))
+	for flag, param := range map[string]string{"since": "since", "until": "until", "project-id": "project_id"} {
+		if value := ctx.String(flag); value != "" {
+			query.Set(param, value)
+		}
+	}
+
+	var out io.Writer = os.Stdout
+	if outputFile := ctx.String("output"); outputFile != "" {
+		f, err := os.Create(outputFile)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	pages, err := streamJobExport(apiURL, token, query, out)
+	if err != nil {
+		return fmt.Errorf("failed to export jobs: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d page(s)\n", pages)
+	return nil
+}
+
+// streamJobExport copies the job export selected by query to out, a page
+// at a time: each page's X-Next-Cursor is passed back as ?cursor= until a
+// page comes without one. Pages are copied as they arrive rather than
+// collected, and a CSV export keeps only the first page's header row. It
+// returns how many pages it read.
+func streamJobExport(apiURL, token string, query url.Values, out io.Writer) (int, error) {
+	client := &http.Client{Timeout: 5 * time.Minute}
+	csv := query.Get("format") == "csv"
+	pages := 0
+	for {
+		req, err := http.NewRequest(http.MethodGet, apiURL+"/api/v1/admin/exports/jobs?"+query.Encode(), nil)
+		if err != nil {
+			return pages, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return pages, fmt.Errorf("failed to send request: %w", err)
+		}
+		next, err := copyJobExportPage(resp, out, csv && pages > 0)
+		resp.Body.Close()
+		if err != nil {
+			return pages, err
+		}
+		pages++
+		if next == "" {
+			return pages, nil
+		}
+		query.Set("cursor", next)
+	}
+}
+
+// copyJobExportPage copies one export page's body to out, dropping its
+// first line if skipHeader is set, and returns the page's next cursor.
+func copyJobExportPage(resp *http.Response, out io.Writer, skipHeader bool) (string, error) {
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", fmt.Errorf("API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	body := bufio.NewReader(resp.Body)
+	if skipHeader {
+		if _, err := body.ReadString('\n'); err != nil && err != io.EOF {
+			return "", fmt.Errorf("failed to read export page: %w", err)
+		}
+	}
+	if _, err := io.Copy(out, body); err != nil {
+		return "", fmt.Errorf("failed to write export page: %w", err)
+	}
+	return resp.Header.Get("X-Next-Cursor"), nil
+}
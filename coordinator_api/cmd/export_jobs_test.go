@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestStreamJobExportFollowsCursor(t *testing.T) {
+	pages := map[string]string{
+		"":   "job_id,status\njob-1,completed\njob-2,failed\n",
+		"c1": "job_id,status\njob-3,completed\n",
+	}
+	var cursors []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/admin/exports/jobs" || r.Header.Get("Authorization") != "Bearer api-token" {
+			t.Fatalf("unexpected request %s", r.URL.String())
+		}
+		if r.URL.Query().Get("project_id") != "project-1" {
+			t.Fatalf("filters not kept across pages: %s", r.URL.RawQuery)
+		}
+		cursor := r.URL.Query().Get("cursor")
+		cursors = append(cursors, cursor)
+		if cursor == "" {
+			w.Header().Set("X-Next-Cursor", "c1")
+		}
+		_, _ = w.Write([]byte(pages[cursor]))
+	}))
+	defer server.Close()
+
+	query := url.Values{"format": {"csv"}, "project_id": {"project-1"}}
+	var out bytes.Buffer
+	n, err := streamJobExport(server.URL, "api-token", query, &out)
+	if err != nil {
+		t.Fatalf("streamJobExport: %v", err)
+	}
+	if n != 2 || len(cursors) != 2 || cursors[1] != "c1" {
+		t.Fatalf("expected two pages, the second at c1; got %d pages, cursors %q", n, cursors)
+	}
+	if want := "job_id,status\njob-1,completed\njob-2,failed\njob-3,completed\n"; out.String() != want {
+		t.Errorf("export = %q, want %q", out.String(), want)
+	}
+}
+
+func TestStreamJobExportReportsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"forbidden"}`, http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	if _, err := streamJobExport(server.URL, "api-token", url.Values{"format": {"ndjson"}}, &out); err == nil {
+		t.Fatal("expected an error for a forbidden export")
+	}
+}
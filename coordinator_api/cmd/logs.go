@@ -120,6 +120,7 @@ func fetchJobLogs(apiURL, token, jobID, stream string) ([]byte, error) {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
+	printAnnouncement(resp)
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
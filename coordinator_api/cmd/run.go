@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/handlers"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/worker"
+	"github.com/urfave/cli/v2"
+)
+
+// RunCommand uploads a local directory and runs a job against it on a
+// remote Reactorcide coordinator
+var RunCommand = &cli.Command{
+	Name:      "run",
+	Usage:     "Upload a local directory and run a command against it on a remote Reactorcide coordinator",
+	ArgsUsage: "[--] <command>...",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "api-url",
+			Aliases: []string{"u"},
+			Usage:   "Coordinator API URL (e.g., http://localhost:6080)",
+			EnvVars: []string{"REACTORCIDE_API_URL"},
+		},
+		&cli.StringFlag{
+			Name:    "token",
+			Aliases: []string{"t"},
+			Usage:   "API token for authentication",
+			EnvVars: []string{"REACTORCIDE_API_TOKEN"},
+		},
+		&cli.StringFlag{
+			Name:    "dir",
+			Aliases: []string{"d"},
+			Value:   ".",
+			Usage:   "Directory to upload as the job's source",
+		},
+		&cli.StringFlag{
+			Name:    "job-file",
+			Aliases: []string{"f"},
+			Usage:   "Job definition to take the name, image, command and environment from; flags and the command override it",
+		},
+		&cli.StringFlag{
+			Name:  "name",
+			Usage: "Job name (default: run-<directory name>)",
+		},
+		&cli.StringFlag{
+			Name:  "image",
+			Usage: "Runner image for the job",
+		},
+		&cli.StringSliceFlag{
+			Name:    "env",
+			Aliases: []string{"e"},
+			Usage:   "Environment variable KEY=VALUE for the job (can be repeated)",
+		},
+		&cli.StringFlag{
+			Name:  "queue",
+			Usage: "Queue to submit the job to",
+		},
+		&cli.BoolFlag{
+			Name:  "include-vcs",
+			Usage: "Upload VCS metadata (.git, .hg, .svn) too",
+		},
+		&cli.BoolFlag{
+			Name:    "wait",
+			Aliases: []string{"w"},
+			Usage:   "Wait for job to complete and show final status",
+		},
+		&cli.IntFlag{
+			Name:  "poll-interval",
+			Value: 5,
+			Usage: "Polling interval in seconds when using --wait",
+		},
+	},
+	Action: runAction,
+}
+
+func runAction(ctx *cli.Context) error {
+	apiURL := strings.TrimSuffix(ctx.String("api-url"), "/")
+	token := ctx.String("token")
+	if apiURL == "" {
+		return fmt.Errorf("API URL is required (use --api-url or REACTORCIDE_API_URL)")
+	}
+
+	dir, err := filepath.Abs(ctx.String("dir"))
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return fmt.Errorf("not a directory: %s", dir)
+	}
+
+	spec := &worker.JobSpec{}
+	if jobFile := ctx.String("job-file"); jobFile != "" {
+		if spec, err = worker.LoadJobSpec(jobFile); err != nil {
+			return err
+		}
+	}
+	if err := applyRunFlags(spec, ctx, dir); err != nil {
+		return err
+	}
+
+	// ${secret:path:key} references are passed through to the API, as
+	// submit does.
+	spec.Environment = worker.ResolveEnvInMap(spec.Environment)
+
+	if token == "" {
+		token, err = promptForSecret("REACTORCIDE_API_TOKEN", "API token: ")
+		if err != nil {
+			return err
+		}
+	}
+	if token == "" {
+		return fmt.Errorf("API token is required (use --token or REACTORCIDE_API_TOKEN)")
+	}
+
+	fmt.Fprintf(os.Stderr, "Uploading %s\n", dir)
+	upload, err := uploadSourceToAPI(apiURL, token, dir, ctx.Bool("include-vcs"))
+	if err != nil {
+		return fmt.Errorf("failed to upload source: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Uploaded %d bytes\n", upload.Size)
+
+	req := specToCreateJobRequest(spec)
+	req.SourceType = "upload"
+	req.SourceURL = ""
+	req.SourceRef = ""
+	req.SourcePath = upload.UploadID
+	req.QueueName = ctx.String("queue")
+
+	fmt.Fprintf(os.Stderr, "Submitting job: %s\n", spec.Name)
+	jobResp, err := submitJobToAPI(apiURL, token, req)
+	if err != nil {
+		return fmt.Errorf("failed to submit job: %w", err)
+	}
+
+	fmt.Println("Job submitted successfully!")
+	fmt.Printf("  Job ID: %s\n", jobResp.JobID)
+	fmt.Printf("  Status: %s\n", jobResp.Status)
+	fmt.Printf("  Name:   %s\n", jobResp.Name)
+
+	if ctx.Bool("wait") {
+		return waitAndReport(apiURL, token, jobResp.JobID, ctx.Int("poll-interval"))
+	}
+	return nil
+}
+
+// applyRunFlags applies run's arguments and flags over spec: the command,
+// name, image and environment.
+func applyRunFlags(spec *worker.JobSpec, ctx *cli.Context, dir string) error {
+	if ctx.NArg() > 0 {
+		spec.Command = strings.Join(ctx.Args().Slice(), " ")
+	}
+	if spec.Command == "" {
+		return fmt.Errorf("usage: reactorcide run [flags] [--] <command>... (or a command in --job-file)")
+	}
+	if name := ctx.String("name"); name != "" {
+		spec.Name = name
+	}
+	if spec.Name == "" {
+		spec.Name = "run-" + filepath.Base(dir)
+	}
+	if image := ctx.String("image"); image != "" {
+		spec.Image = image
+	}
+	for _, kv := range ctx.StringSlice("env") {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("invalid --env %q: use KEY=VALUE", kv)
+		}
+		if spec.Environment == nil {
+			spec.Environment = make(map[string]string)
+		}
+		spec.Environment[key] = value
+	}
+	return nil
+}
+
+// uploadSourceToAPI streams dir to the coordinator as a gzipped tar.
+func uploadSourceToAPI(apiURL, token, dir string, includeVCS bool) (*handlers.SourceUploadResponse, error) {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(worker.WriteSourceArchive(pw, dir, includeVCS))
+	}()
+	defer pr.Close()
+
+	httpReq, err := http.NewRequest("POST", apiURL+handlers.SourceUploadPath, pr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/gzip")
+	httpReq.Header.Set("Authorization", "Bearer "+token)
+
+	client := &http.Client{Timeout: 10 * time.Minute}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	printAnnouncement(resp)
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("API error (%d): %s", resp.StatusCode, string(body))
+	}
+
+	var upload handlers.SourceUploadResponse
+	if err := json.Unmarshal(body, &upload); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return &upload, nil
+}
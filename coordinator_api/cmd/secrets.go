@@ -329,6 +329,10 @@ var SecretsCommand = &cli.Command{
 				return nil
 			},
 		},
+		secretsImportCommand,
+		secretsExportCommand,
+		secretsMoveCommand,
+		secretsRenderCommand,
 	},
 }
 
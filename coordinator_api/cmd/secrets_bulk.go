@@ -0,0 +1,514 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/secrets"
+	"github.com/urfave/cli/v2"
+)
+
+// redactedSecretValue stands in for values in an export without
+// --include-values.
+const redactedSecretValue = "[REDACTED]"
+
+var secretsImportCommand = &cli.Command{
+	Name:      "import",
+	Usage:     "Import secrets into a path from a .env or JSON file",
+	ArgsUsage: "<path> <file|->",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "format",
+			Aliases: []string{"f"},
+			Usage:   "Input format: env or json (default: json for .json files, env otherwise)",
+		},
+		&cli.BoolFlag{
+			Name:  "overwrite",
+			Usage: "Replace secrets that already exist (skipped by default)",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		if ctx.NArg() < 2 {
+			return fmt.Errorf("usage: reactorcide secrets import <path> <file|->")
+		}
+		path := ctx.Args().Get(0)
+		file := ctx.Args().Get(1)
+
+		data, err := readSecretsInput(file)
+		if err != nil {
+			return err
+		}
+		format := ctx.String("format")
+		if format == "" {
+			format = "env"
+			if strings.EqualFold(filepath.Ext(file), ".json") {
+				format = "json"
+			}
+		}
+		var values map[string]string
+		switch format {
+		case "env":
+			values, err = parseDotEnv(data)
+		case "json":
+			values, err = parseSecretsJSON(data)
+		default:
+			return fmt.Errorf("unknown format: %s", format)
+		}
+		if err != nil {
+			return err
+		}
+
+		backend, err := openSecretsBackend(ctx)
+		if err != nil {
+			return err
+		}
+
+		var skipped []string
+		if !ctx.Bool("overwrite") {
+			existing, err := backend.ListKeys(path)
+			if err != nil {
+				return err
+			}
+			for _, key := range existing {
+				if _, ok := values[key]; ok {
+					skipped = append(skipped, key)
+					delete(values, key)
+				}
+			}
+		}
+
+		if len(values) > 0 {
+			if err := backend.SetMulti(path, values); err != nil {
+				return err
+			}
+		}
+
+		fmt.Printf("Imported %d secrets into %s\n", len(values), path)
+		if len(skipped) > 0 {
+			sort.Strings(skipped)
+			fmt.Printf("Skipped %d existing (use --overwrite to replace): %s\n", len(skipped), strings.Join(skipped, ", "))
+		}
+		return nil
+	},
+}
+
+var secretsExportCommand = &cli.Command{
+	Name:      "export",
+	Usage:     "Export a path's secrets as .env or JSON (values redacted unless --include-values)",
+	ArgsUsage: "<path>",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "format",
+			Aliases: []string{"f"},
+			Usage:   "Output format: env or json (default: env)",
+			Value:   "env",
+		},
+		&cli.BoolFlag{
+			Name:  "include-values",
+			Usage: "Include secret values in the output",
+		},
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Usage:   "Write to this file (mode 0600) instead of stdout",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		if ctx.NArg() < 1 {
+			return fmt.Errorf("usage: reactorcide secrets export <path>")
+		}
+		path := ctx.Args().Get(0)
+		format := ctx.String("format")
+		if format != "env" && format != "json" {
+			return fmt.Errorf("unknown format: %s", format)
+		}
+
+		backend, err := openSecretsBackend(ctx)
+		if err != nil {
+			return err
+		}
+
+		var values map[string]string
+		if ctx.Bool("include-values") {
+			values, err = backend.GetPath(path)
+			if err != nil {
+				return err
+			}
+		} else {
+			keys, err := backend.ListKeys(path)
+			if err != nil {
+				return err
+			}
+			values = make(map[string]string, len(keys))
+			for _, key := range keys {
+				values[key] = redactedSecretValue
+			}
+		}
+
+		var out []byte
+		if format == "json" {
+			out, err = json.MarshalIndent(values, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal secrets: %w", err)
+			}
+			out = append(out, '\n')
+		} else {
+			out = formatDotEnv(values)
+		}
+		return writeSecretsOutput(ctx.String("output"), out)
+	},
+}
+
+var secretsMoveCommand = &cli.Command{
+	Name:      "move",
+	Aliases:   []string{"mv", "rename"},
+	Usage:     "Move or rename a secret, or every secret in a path",
+	ArgsUsage: "<from-path>[:key] <to-path>[:key]",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "overwrite",
+			Usage: "Replace secrets that already exist at the destination",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		if ctx.NArg() < 2 {
+			return fmt.Errorf("usage: reactorcide secrets move <from-path>[:key] <to-path>[:key]")
+		}
+		from := parseSecretRefArg(ctx.Args().Get(0))
+		to := parseSecretRefArg(ctx.Args().Get(1))
+		if from.Key == "" && to.Key != "" {
+			return fmt.Errorf("a whole path can only be moved to a path, not a key")
+		}
+
+		backend, err := openSecretsBackend(ctx)
+		if err != nil {
+			return err
+		}
+
+		keys := []string{from.Key}
+		if from.Key == "" {
+			if keys, err = backend.ListKeys(from.Path); err != nil {
+				return err
+			}
+		}
+		if !ctx.Bool("overwrite") {
+			existing, err := backend.ListKeys(to.Path)
+			if err != nil {
+				return err
+			}
+			for _, key := range keys {
+				dest := key
+				if to.Key != "" {
+					dest = to.Key
+				}
+				if from.Path == to.Path && dest == key {
+					continue
+				}
+				for _, e := range existing {
+					if e == dest {
+						return fmt.Errorf("secret already exists: %s:%s (use --overwrite to replace)", to.Path, dest)
+					}
+				}
+			}
+		}
+
+		moved, err := backend.Move(from, to)
+		if err != nil {
+			return err
+		}
+		if moved == 0 {
+			return fmt.Errorf("secret not found: %s", ctx.Args().Get(0))
+		}
+		fmt.Printf("Moved %d secrets: %s -> %s\n", moved, ctx.Args().Get(0), ctx.Args().Get(1))
+		return nil
+	},
+}
+
+var secretsRenderCommand = &cli.Command{
+	Name:      "render",
+	Usage:     `Render a template file, replacing {{secret "path" "key"}} with secret values`,
+	ArgsUsage: "<template|->",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Usage:   "Write to this file (mode 0600) instead of stdout",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		if ctx.NArg() < 1 {
+			return fmt.Errorf("usage: reactorcide secrets render <template|->")
+		}
+		text, err := readSecretsInput(ctx.Args().Get(0))
+		if err != nil {
+			return err
+		}
+
+		backend, err := openSecretsBackend(ctx)
+		if err != nil {
+			return err
+		}
+
+		// Each path is fetched once, however many of its keys the template uses.
+		paths := make(map[string]map[string]string)
+		rendered, err := secrets.RenderTemplate(string(text), func(path, key string) (string, error) {
+			if _, ok := paths[path]; !ok {
+				values, err := backend.GetPath(path)
+				if err != nil {
+					return "", err
+				}
+				paths[path] = values
+			}
+			return paths[path][key], nil
+		})
+		if err != nil {
+			return err
+		}
+		return writeSecretsOutput(ctx.String("output"), []byte(rendered))
+	},
+}
+
+// secretsBackend is what the bulk secrets commands need from either local
+// storage or the coordinator API.
+type secretsBackend interface {
+	ListKeys(path string) ([]string, error)
+	GetPath(path string) (map[string]string, error)
+	SetMulti(path string, values map[string]string) error
+	Move(from, to secrets.SecretRef) (int, error)
+}
+
+// localSecretsBackend is local storage with the password asked for once.
+type localSecretsBackend struct {
+	storage  *secrets.Storage
+	password string
+}
+
+func (b *localSecretsBackend) ListKeys(path string) ([]string, error) {
+	return b.storage.ListKeys(path, b.password)
+}
+
+func (b *localSecretsBackend) GetPath(path string) (map[string]string, error) {
+	return b.storage.GetPath(path, b.password)
+}
+
+func (b *localSecretsBackend) SetMulti(path string, values map[string]string) error {
+	return b.storage.SetMulti(path, values, b.password)
+}
+
+func (b *localSecretsBackend) Move(from, to secrets.SecretRef) (int, error) {
+	return b.storage.Move(from, to, b.password)
+}
+
+func openSecretsBackend(ctx *cli.Context) (secretsBackend, error) {
+	if secretsAPIEnabled(ctx) {
+		return newSecretsAPIClient(ctx)
+	}
+	pw, err := getPassword("Secrets password: ")
+	if err != nil {
+		return nil, err
+	}
+	return &localSecretsBackend{storage: secrets.NewStorage(), password: pw}, nil
+}
+
+// GetPath fetches every secret in a path.
+func (c *secretsAPIClient) GetPath(path string) (map[string]string, error) {
+	keys, err := c.ListKeys(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return map[string]string{}, nil
+	}
+	refs := make([]secrets.SecretRef, 0, len(keys))
+	for _, key := range keys {
+		refs = append(refs, secrets.SecretRef{Path: path, Key: key})
+	}
+	return c.GetMulti(refs)
+}
+
+// SetMulti stores several secrets in a path in one request.
+func (c *secretsAPIClient) SetMulti(path string, values map[string]string) error {
+	type secretValue struct {
+		Path  string `json:"path"`
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+	body := struct {
+		Secrets []secretValue `json:"secrets"`
+	}{}
+	for key, value := range values {
+		body.Secrets = append(body.Secrets, secretValue{Path: path, Key: key, Value: value})
+	}
+	return c.doJSON(http.MethodPost, "/api/v1/secrets/batch/set", body, http.StatusOK, nil)
+}
+
+// Move copies the secrets to their destination, then deletes the
+// originals. Unlike local storage it isn't atomic: if a delete fails, the
+// secret is left in both places.
+func (c *secretsAPIClient) Move(from, to secrets.SecretRef) (int, error) {
+	values, err := c.GetPath(from.Path)
+	if err != nil {
+		return 0, err
+	}
+	moved := make(map[string]string)
+	for key, value := range values {
+		if from.Key == "" || key == from.Key {
+			moved[key] = value
+		}
+	}
+	if len(moved) == 0 {
+		return 0, nil
+	}
+	sources := make([]string, 0, len(moved))
+	for key := range moved {
+		sources = append(sources, key)
+	}
+	if to.Key != "" {
+		moved = map[string]string{to.Key: moved[from.Key]}
+	}
+
+	if err := c.SetMulti(to.Path, moved); err != nil {
+		return 0, err
+	}
+	for _, key := range sources {
+		dest := key
+		if to.Key != "" {
+			dest = to.Key
+		}
+		if to.Path == from.Path && dest == key {
+			continue
+		}
+		if _, err := c.Delete(from.Path, key); err != nil {
+			return 0, fmt.Errorf("copied to %s but failed to delete %s:%s: %w", to.Path, from.Path, key, err)
+		}
+	}
+	return len(moved), nil
+}
+
+// parseSecretRefArg parses path[:key].
+func parseSecretRefArg(arg string) secrets.SecretRef {
+	path, key, _ := strings.Cut(arg, ":")
+	return secrets.SecretRef{Path: path, Key: key}
+}
+
+// readSecretsInput reads a file, or stdin for "-".
+func readSecretsInput(file string) ([]byte, error) {
+	if file == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read stdin: %w", err)
+		}
+		return data, nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	return data, nil
+}
+
+// writeSecretsOutput writes to stdout, or to file readable only by its owner.
+func writeSecretsOutput(file string, data []byte) error {
+	if file == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(file, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	return nil
+}
+
+// parseDotEnv parses KEY=VALUE lines. Blank lines, # comments and a leading
+// "export " are ignored. Double-quoted values may use escapes (\n, \", \\);
+// single-quoted values are taken literally; unquoted values end at " #".
+func parseDotEnv(data []byte) (map[string]string, error) {
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", lineNum)
+		}
+		value = strings.TrimSpace(value)
+
+		switch {
+		case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid quoted value", lineNum)
+			}
+			value = unquoted
+		case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+			value = value[1 : len(value)-1]
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+		}
+		values[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read .env: %w", err)
+	}
+	return values, nil
+}
+
+// parseSecretsJSON parses a flat JSON object of keys to values. Numbers and
+// booleans are taken as their JSON text.
+func parseSecretsJSON(data []byte) (map[string]string, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("expected a JSON object of keys to values: %w", err)
+	}
+	values := make(map[string]string, len(raw))
+	for key, value := range raw {
+		var s string
+		if err := json.Unmarshal(value, &s); err == nil {
+			values[key] = s
+			continue
+		}
+		text := strings.TrimSpace(string(value))
+		if text == "" || text[0] == '{' || text[0] == '[' || text == "null" {
+			return nil, fmt.Errorf("%s: value must be a string, number or boolean", key)
+		}
+		values[key] = text
+	}
+	return values, nil
+}
+
+// formatDotEnv writes values as sorted KEY=VALUE lines that parseDotEnv
+// reads back, quoting values that need it.
+func formatDotEnv(values map[string]string) []byte {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var out bytes.Buffer
+	for _, key := range keys {
+		value := values[key]
+		if value != redactedSecretValue && strings.ContainsAny(value, " \t\r\n#\"'\\") {
+			value = strconv.Quote(value)
+		}
+		fmt.Fprintf(&out, "%s=%s\n", key, value)
+	}
+	return out.Bytes()
+}
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/secrets"
+	"github.com/urfave/cli/v2"
+)
+
+func TestParseDotEnv(t *testing.T) {
+	values, err := parseDotEnv([]byte(`
+# database
+DB_HOST=localhost
+export DB_USER = admin
+DB_PASSWORD="p@ss word\nline2"
+LITERAL='no $escapes\n'
+TRAILING=value # a comment
+EMPTY=
+`))
+	if err != nil {
+		t.Fatalf("parseDotEnv failed: %v", err)
+	}
+	expected := map[string]string{
+		"DB_HOST":     "localhost",
+		"DB_USER":     "admin",
+		"DB_PASSWORD": "p@ss word\nline2",
+		"LITERAL":     `no $escapes\n`,
+		"TRAILING":    "value",
+		"EMPTY":       "",
+	}
+	if len(values) != len(expected) {
+		t.Fatalf("expected %d values, got %d", len(expected), len(values))
+	}
+	for key, want := range expected {
+		if values[key] != want {
+			t.Errorf("%s: expected %q, got %q", key, want, values[key])
+		}
+	}
+
+	if _, err := parseDotEnv([]byte("NO_EQUALS\n")); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("expected a line 1 error, got %v", err)
+	}
+}
+
+func TestFormatDotEnvRoundTrip(t *testing.T) {
+	values := map[string]string{"PLAIN": "abc", "SPACES": "a b", "MULTI": "one\ntwo", "QUOTES": `say "hi"`}
+	parsed, err := parseDotEnv(formatDotEnv(values))
+	if err != nil {
+		t.Fatalf("parseDotEnv failed: %v", err)
+	}
+	for key, want := range values {
+		if parsed[key] != want {
+			t.Errorf("%s: expected %q, got %q", key, want, parsed[key])
+		}
+	}
+}
+
+func TestParseSecretsJSON(t *testing.T) {
+	values, err := parseSecretsJSON([]byte(`{"A":"one","PORT":5432,"DEBUG":true}`))
+	if err != nil {
+		t.Fatalf("parseSecretsJSON failed: %v", err)
+	}
+	if values["A"] != "one" || values["PORT"] != "5432" || values["DEBUG"] != "true" {
+		t.Errorf("unexpected values: %v", values)
+	}
+	if _, err := parseSecretsJSON([]byte(`{"NESTED":{"a":"b"}}`)); err == nil {
+		t.Error("expected nested values to be rejected")
+	}
+}
+
+// fakeSecretsAPI keeps secrets in memory behind the endpoints the bulk
+// commands use.
+func fakeSecretsAPI(t *testing.T, data map[string]map[string]string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer api-token" {
+			t.Fatalf("missing bearer token")
+		}
+		query := r.URL.Query()
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/secrets":
+			keys := []string{}
+			for key := range data[query.Get("path")] {
+				keys = append(keys, key)
+			}
+			_ = json.NewEncoder(w).Encode(map[string][]string{"keys": keys})
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/secrets/batch/get":
+			var req struct{ Refs []secrets.SecretRef }
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			results := map[string]string{}
+			for _, ref := range req.Refs {
+				results[ref.Key] = data[ref.Path][ref.Key]
+			}
+			_ = json.NewEncoder(w).Encode(map[string]map[string]string{"secrets": results})
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/secrets/batch/set":
+			var req struct {
+				Secrets []struct{ Path, Key, Value string }
+			}
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			for _, s := range req.Secrets {
+				if data[s.Path] == nil {
+					data[s.Path] = map[string]string{}
+				}
+				data[s.Path][s.Key] = s.Value
+			}
+			_, _ = w.Write([]byte(`{"status":"ok"}`))
+		case r.Method == http.MethodDelete && r.URL.Path == "/api/v1/secrets/value":
+			delete(data[query.Get("path")], query.Get("key"))
+			_, _ = w.Write([]byte(`{"status":"ok"}`))
+		default:
+			t.Fatalf("unexpected request %s %s", r.Method, r.URL.String())
+		}
+	}))
+}
+
+func TestSecretsBulkCommandsThroughAPI(t *testing.T) {
+	data := map[string]map[string]string{"app": {"EXISTING": "keep"}}
+	server := fakeSecretsAPI(t, data)
+	defer server.Close()
+
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		app := cli.NewApp()
+		app.Commands = []*cli.Command{SecretsCommand}
+		base := []string{"reactorcide", "secrets", "--api-url", server.URL, "--token", "api-token"}
+		if err := app.Run(append(base, args...)); err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+	}
+
+	envFile := filepath.Join(dir, "app.env")
+	if err := os.WriteFile(envFile, []byte("EXISTING=replaced\nDB_PASSWORD=\"s3cret value\"\nAPI_KEY=abc\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	run("import", "app", envFile)
+	if data["app"]["EXISTING"] != "keep" || data["app"]["DB_PASSWORD"] != "s3cret value" || data["app"]["API_KEY"] != "abc" {
+		t.Fatalf("unexpected secrets after import: %v", data["app"])
+	}
+
+	redacted := filepath.Join(dir, "redacted.env")
+	run("export", "-o", redacted, "app")
+	out, _ := os.ReadFile(redacted)
+	if strings.Contains(string(out), "s3cret") || !strings.Contains(string(out), "DB_PASSWORD=[REDACTED]") {
+		t.Errorf("expected values redacted, got %q", out)
+	}
+	full := filepath.Join(dir, "full.json")
+	run("export", "--format", "json", "--include-values", "-o", full, "app")
+	out, _ = os.ReadFile(full)
+	var exported map[string]string
+	if err := json.Unmarshal(out, &exported); err != nil || exported["DB_PASSWORD"] != "s3cret value" {
+		t.Errorf("expected values included, got %q (%v)", out, err)
+	}
+
+	run("move", "app:API_KEY", "deploy:TOKEN")
+	if _, ok := data["app"]["API_KEY"]; ok || data["deploy"]["TOKEN"] != "abc" {
+		t.Errorf("unexpected secrets after move: %v", data)
+	}
+
+	template := filepath.Join(dir, "config.tmpl")
+	if err := os.WriteFile(template, []byte(`password: {{secret "app" "DB_PASSWORD"}}, token: {{secret "deploy" "TOKEN"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	rendered := filepath.Join(dir, "config.yaml")
+	run("render", "-o", rendered, template)
+	out, _ = os.ReadFile(rendered)
+	if string(out) != "password: s3cret value, token: abc" {
+		t.Errorf("unexpected render %q", out)
+	}
+	if info, err := os.Stat(rendered); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("expected rendered file mode 0600, got %v", info.Mode())
+	}
+}
@@ -8,8 +8,10 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/handlers"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/worker"
 	"github.com/urfave/cli/v2"
 )
@@ -185,43 +187,7 @@ func submitAction(ctx *cli.Context) error {
 
 	// Optionally wait for completion
 	if wait {
-		fmt.Println("\nWaiting for completion...")
-		startTime := time.Now()
-
-		finalResp, err := waitForJobCompletion(apiURL, token, jobResp.JobID, pollInterval)
-		if err != nil {
-			return fmt.Errorf("failed while waiting for job: %w", err)
-		}
-
-		elapsed := time.Since(startTime).Round(time.Second)
-
-		fmt.Println()
-		switch finalResp.Status {
-		case "completed":
-			fmt.Println("Job completed!")
-		case "failed":
-			fmt.Println("Job failed!")
-		case "cancelled":
-			fmt.Println("Job cancelled!")
-		case "timeout":
-			fmt.Println("Job timed out!")
-		default:
-			fmt.Printf("Job ended with status: %s\n", finalResp.Status)
-		}
-
-		if finalResp.ExitCode != nil {
-			fmt.Printf("  Exit Code: %d\n", *finalResp.ExitCode)
-		}
-		fmt.Printf("  Duration:  %s\n", elapsed)
-
-		// Return non-zero exit if job failed
-		if finalResp.Status != "completed" {
-			exitCode := 1
-			if finalResp.ExitCode != nil {
-				exitCode = *finalResp.ExitCode
-			}
-			return cli.Exit("", exitCode)
-		}
+		return waitAndReport(apiURL, token, jobResp.JobID, pollInterval)
 	}
 
 	return nil
@@ -260,6 +226,25 @@ func specToCreateJobRequest(spec *worker.JobSpec) *CreateJobRequest {
 	return req
 }
 
+// announcementShown makes printAnnouncement print at most once per run.
+var announcementShown sync.Once
+
+// printAnnouncement prints the coordinator's active announcement, e.g.
+// planned maintenance, to stderr the first time a response carries one.
+func printAnnouncement(resp *http.Response) {
+	message := resp.Header.Get(handlers.AnnouncementHeader)
+	if message == "" {
+		return
+	}
+	announcementShown.Do(func() {
+		severity := resp.Header.Get(handlers.AnnouncementSeverityHeader)
+		if severity == "" {
+			severity = "info"
+		}
+		fmt.Fprintf(os.Stderr, "[%s] %s\n", strings.ToUpper(severity), message)
+	})
+}
+
 // submitJobToAPI sends a job creation request to the coordinator API
 func submitJobToAPI(apiURL, token string, req *CreateJobRequest) (*JobResponse, error) {
 	jsonBody, err := json.Marshal(req)
@@ -281,6 +266,7 @@ func submitJobToAPI(apiURL, token string, req *CreateJobRequest) (*JobResponse,
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
+	printAnnouncement(resp)
 
 	body, _ := io.ReadAll(resp.Body)
 
@@ -296,6 +282,49 @@ func submitJobToAPI(apiURL, token string, req *CreateJobRequest) (*JobResponse,
 	return &jobResp, nil
 }
 
+// waitAndReport waits for a submitted job to finish and prints its
+// outcome, exiting non-zero if it didn't complete.
+func waitAndReport(apiURL, token, jobID string, pollInterval int) error {
+	fmt.Println("\nWaiting for completion...")
+	startTime := time.Now()
+
+	finalResp, err := waitForJobCompletion(apiURL, token, jobID, pollInterval)
+	if err != nil {
+		return fmt.Errorf("failed while waiting for job: %w", err)
+	}
+
+	elapsed := time.Since(startTime).Round(time.Second)
+
+	fmt.Println()
+	switch finalResp.Status {
+	case "completed":
+		fmt.Println("Job completed!")
+	case "failed":
+		fmt.Println("Job failed!")
+	case "cancelled":
+		fmt.Println("Job cancelled!")
+	case "timeout":
+		fmt.Println("Job timed out!")
+	default:
+		fmt.Printf("Job ended with status: %s\n", finalResp.Status)
+	}
+
+	if finalResp.ExitCode != nil {
+		fmt.Printf("  Exit Code: %d\n", *finalResp.ExitCode)
+	}
+	fmt.Printf("  Duration:  %s\n", elapsed)
+
+	// Return non-zero exit if job failed
+	if finalResp.Status != "completed" {
+		exitCode := 1
+		if finalResp.ExitCode != nil {
+			exitCode = *finalResp.ExitCode
+		}
+		return cli.Exit("", exitCode)
+	}
+	return nil
+}
+
 // waitForJobCompletion polls the API until the job reaches a terminal state
 func waitForJobCompletion(apiURL, token, jobID string, pollInterval int) (*JobResponse, error) {
 	client := &http.Client{Timeout: 30 * time.Second}
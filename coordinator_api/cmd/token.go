@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"strings"
 
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
@@ -34,6 +35,10 @@ var TokenCommand = &cli.Command{
 					Usage:   "User ID to associate with the token (defaults to REACTORCIDE_DEFAULT_USER_ID)",
 					EnvVars: []string{"REACTORCIDE_DEFAULT_USER_ID"},
 				},
+				&cli.StringSliceFlag{
+					Name:  "scope",
+					Usage: "Scope for the token, limiting it to the secrets token_scope access policies grant (repeatable)",
+				},
 				&cli.StringFlag{
 					Name:        "db-uri",
 					Aliases:     []string{"db"},
@@ -77,6 +82,7 @@ var TokenCommand = &cli.Command{
 					TokenHash: tokenHash,
 					Name:      tokenName,
 					IsActive:  true,
+					Scopes:    ctx.StringSlice("scope"),
 				}
 
 				if err := store.AppStore.CreateAPIToken(context.Background(), apiToken); err != nil {
@@ -85,6 +91,9 @@ var TokenCommand = &cli.Command{
 
 				fmt.Printf("Token created successfully!\n")
 				fmt.Printf("Token ID: %s\n", apiToken.TokenID)
+				if len(apiToken.Scopes) > 0 {
+					fmt.Printf("Scopes: %s\n", strings.Join(apiToken.Scopes, ", "))
+				}
 				fmt.Printf("Token: %s\n", tokenString)
 				fmt.Printf("\nSave this token - it cannot be retrieved again!\n")
 
@@ -16,6 +16,7 @@ import (
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/pubsub"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/secrets"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/postgres_store"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/worker"
@@ -64,9 +65,15 @@ var workerFlags = []cli.Flag{
 		Name:    "container-runtime",
 		Aliases: []string{"r"},
 		Value:   "auto",
-		Usage:   "Container runtime backend: docker, containerd, kubernetes, or auto",
+		Usage:   "Container runtime backend: docker, containerd, kubernetes, host, or auto",
 		EnvVars: []string{"REACTORCIDE_CONTAINER_RUNTIME", "CONTAINER_RUNTIME"},
 	},
+	&cli.StringFlag{
+		Name:    "platform",
+		Value:   "",
+		Usage:   "Platform (os/arch, e.g. windows/amd64) to claim jobs for; defaults to the base queue on Linux and this host's platform elsewhere",
+		EnvVars: []string{"REACTORCIDE_WORKER_PLATFORM", "WORKER_PLATFORM"},
+	},
 	&cli.DurationFlag{
 		Name:    "shutdown-timeout",
 		Value:   time.Hour,
@@ -84,6 +91,7 @@ func RunWorker(ctx *cli.Context) error {
 
 	// Set up stores
 	store.AppStore = postgres_store.PostgresStore
+	installChaos()
 
 	// Initialize stores
 	deferredStoreFuncs := initStores()
@@ -91,6 +99,10 @@ func RunWorker(ctx *cli.Context) error {
 		defer deferredFunc()
 	}
 
+	if err := registerHooks(); err != nil {
+		return fmt.Errorf("failed to register hooks: %w", err)
+	}
+
 	// Get worker configuration from CLI flags
 	queueName := ctx.String("queue")
 	pollInterval := time.Duration(ctx.Int("poll-interval")) * time.Second
@@ -98,6 +110,13 @@ func RunWorker(ctx *cli.Context) error {
 	dryRun := ctx.Bool("dry-run")
 	containerRuntime := ctx.String("container-runtime")
 	shutdownTimeout := ctx.Duration("shutdown-timeout")
+	platform := worker.DefaultWorkerPlatform()
+	if label := ctx.String("platform"); label != "" {
+		var err error
+		if platform, err = worker.ParsePlatform(label); err != nil {
+			return fmt.Errorf("invalid worker platform: %w", err)
+		}
+	}
 
 	// Log startup information
 	logging.Log.Infof("Starting worker for queue: %s", queueName)
@@ -105,6 +124,7 @@ func RunWorker(ctx *cli.Context) error {
 	logging.Log.Infof("Concurrency: %d", concurrency)
 	logging.Log.Infof("Dry run mode: %t", dryRun)
 	logging.Log.Infof("Container runtime: %s", containerRuntime)
+	logging.Log.Infof("Platform: %s (queue: %s)", platform, platform.Queue(queueName))
 	logging.Log.Infof("Shutdown timeout: %v", shutdownTimeout)
 
 	// Initialize object store for log shipping
@@ -117,6 +137,20 @@ func RunWorker(ctx *cli.Context) error {
 			"prefix":    config.ObjectStorePrefix,
 		},
 	}
+	if config.ObjectStoreSecondaryType != "" {
+		objectStoreConfig.Secondary = &objects.ObjectStoreConfig{
+			Type: config.ObjectStoreSecondaryType,
+			Config: map[string]string{
+				"base_path": config.ObjectStoreSecondaryBasePath,
+				"bucket":    config.ObjectStoreSecondaryBucket,
+				"prefix":    config.ObjectStoreSecondaryPrefix,
+				"region":    config.ObjectStoreSecondaryRegion,
+				"endpoint":  config.ObjectStoreSecondaryEndpoint,
+			},
+		}
+		objectStoreConfig.FailoverThreshold = config.ObjectStoreFailoverThreshold
+		objectStoreConfig.FailoverRetryAfter = time.Duration(config.ObjectStoreFailoverRetrySeconds) * time.Second
+	}
 	objectStore, err := objects.NewObjectStore(objectStoreConfig)
 	if err != nil {
 		logging.Log.WithError(err).Warn("Failed to initialize object store - log shipping will be disabled")
@@ -124,6 +158,36 @@ func RunWorker(ctx *cli.Context) error {
 		logging.Log.Infof("Object store initialized: %s", config.ObjectStoreType)
 	}
 
+	var logUploader *worker.LogUploadClient
+	if config.LogUploadURL != "" {
+		if config.LogUploadClientCertFile != "" || config.LogUploadClientKeyFile != "" {
+			tlsConfig, err := worker.LoadClientTLSConfig(config.LogUploadClientCertFile, config.LogUploadClientKeyFile, config.LogUploadCAFile)
+			if err != nil {
+				return fmt.Errorf("failed to set up log upload client certificate: %w", err)
+			}
+			logUploader = worker.NewLogUploadClientWithTLS(config.LogUploadURL, config.LogUploadToken, tlsConfig)
+		} else {
+			logUploader = worker.NewLogUploadClient(config.LogUploadURL, config.LogUploadToken)
+		}
+		logging.Log.Infof("Shipping logs through coordinator: %s", config.LogUploadURL)
+	}
+
+	var dynamicSecrets *secrets.DynamicSecrets
+	if config.DynamicSecretsEnabled {
+		engines := map[string]secrets.DynamicSecretsEngine{
+			models.CloudProviderGCP: secrets.NewGCPEngine(),
+		}
+		if awsEngine, err := secrets.NewAWSSTSEngine(context.Background()); err != nil {
+			logging.Log.WithError(err).Warn("Failed to initialize AWS STS - AWS cloud roles will fail to mint")
+		} else {
+			engines[models.CloudProviderAWS] = awsEngine
+		}
+		dynamicSecrets = secrets.NewDynamicSecrets(engines)
+	}
+
+	// Re-encrypt secrets jobs read once their tokens age.
+	secrets.SetMaxTokenAge(time.Duration(config.SecretMaxTokenAgeDays) * 24 * time.Hour)
+
 	// Create worker configuration
 	workerConfig := &worker.Config{
 		QueueName:        queueName,
@@ -131,9 +195,47 @@ func RunWorker(ctx *cli.Context) error {
 		Concurrency:      concurrency,
 		DryRun:           dryRun,
 		Store:            store.AppStore,
+		WorkerID:         config.WorkerID,
 		ContainerRuntime: containerRuntime,
+		Platform:         platform,
 		ObjectStore:      objectStore,
 		CancelGrace:      time.Duration(config.CancelGraceSeconds) * time.Second,
+		CostRates: models.CostRates{
+			CPUSecond:      config.CostCPUSecondRate,
+			MemoryGBSecond: config.CostMemoryGBSecondRate,
+			StorageGB:      config.CostStorageGBRate,
+			Currency:       config.CostCurrency,
+		},
+		ResourceSampleInterval: time.Duration(config.CostSampleIntervalSeconds) * time.Second,
+		ResourceMetrics:        config.JobResourceMetrics,
+		DebugSessions: worker.DebugSessionConfig{
+			ListenAddr:   config.DebugListenAddr,
+			AdvertiseURL: config.DebugAdvertiseURL,
+			TTL:          time.Duration(config.DebugSessionMinutes) * time.Minute,
+		},
+		Affinity: worker.AffinityConfig{
+			Wait:   time.Duration(config.AffinityWaitSeconds) * time.Second,
+			Window: time.Duration(config.AffinityWindowMinutes) * time.Minute,
+		},
+		SharedWorkspaces: worker.SharedWorkspaceConfig{
+			Upload: config.SharedWorkspaceUpload,
+			TTL:    time.Duration(config.SharedWorkspaceTTLHours) * time.Hour,
+		},
+		JobCaches: worker.JobCacheConfig{
+			QuotaBytes: int64(config.JobCacheQuotaMB) << 20,
+		},
+		LogUploader:         logUploader,
+		DynamicSecrets:      dynamicSecrets,
+		MaxDeliveryAttempts: config.JobMaxDeliveryAttempts,
+		OCISources: worker.OCISourceConfig{
+			AuthPath:           config.OCISourceAuthPath,
+			InsecureRegistries: strings.Split(config.OCISourceInsecureRegistries, ","),
+		},
+		GitMirrors: worker.GitMirrorConfig{
+			Dir:      config.GitMirrorDir,
+			MaxBytes: int64(config.GitMirrorMaxBytes),
+			Refresh:  time.Duration(config.GitMirrorRefreshSeconds) * time.Second,
+		},
 	}
 
 	// Set up graceful shutdown
@@ -150,13 +252,31 @@ func RunWorker(ctx *cli.Context) error {
 		// Use Corndogs-based worker
 		logging.Log.Info("Using Corndogs-based worker")
 
+		// Payloads the worker submits itself (triggered jobs, retries) are
+		// sealed to its current key, as the coordinator's are.
+		var sealer *corndogs.PayloadSealer
+		if config.PayloadPrivateKeyPaths != "" {
+			opener, err := corndogs.LoadPayloadOpener(config.PayloadEncryptionRequired, strings.Split(config.PayloadPrivateKeyPaths, ",")...)
+			if err != nil {
+				return fmt.Errorf("failed to load payload encryption keys: %w", err)
+			}
+			workerConfig.PayloadOpener = opener
+			sealer = opener.Sealer()
+			logging.Log.WithField("key_id", sealer.KeyID()).Info("Corndogs payload encryption enabled")
+		} else if config.PayloadEncryptionRequired {
+			return fmt.Errorf("REACTORCIDE_PAYLOAD_ENCRYPTION_REQUIRED is set without REACTORCIDE_PAYLOAD_PRIVATE_KEY_PATHS")
+		}
+
 		// Initialize Corndogs client
 		corndogsClient, err := corndogs.NewClient(corndogs.Config{
-			BaseURL:      config.CornDogsBaseURL,
-			QueueName:    queueName,
-			Timeout:      time.Duration(config.DefaultTimeout) * time.Second,
-			MaxRetries:   3,
-			RetryBackoff: time.Second,
+			BaseURL:       config.CornDogsBaseURL,
+			QueueName:     queueName,
+			OS:            platform.OS,
+			Arch:          platform.Arch,
+			Timeout:       time.Duration(config.DefaultTimeout) * time.Second,
+			MaxRetries:    3,
+			RetryBackoff:  time.Second,
+			PayloadSealer: sealer,
 		})
 		if err != nil {
 			logging.Log.WithError(err).Fatal("Failed to initialize Corndogs client")
@@ -0,0 +1,140 @@
+// Package admission evaluates the admission policies admins write (see
+// models.AdmissionPolicy) against jobs about to be created, the job specs
+// of triggers documents and projects being saved.
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/hooks"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// HookName is the name the job policy check is registered under at
+// hooks.PreJobCreate.
+const HookName = "admission_policy"
+
+// policyStore is the narrow store capability admission needs. See
+// postgres_store/admission_operations.go.
+type policyStore interface {
+	ListAdmissionPolicies(ctx context.Context) ([]models.AdmissionPolicy, error)
+}
+
+// DeniedError is returned when something breaks a "deny" policy. It wraps
+// store.ErrForbidden.
+type DeniedError struct {
+	Target     string
+	Violations []models.PolicyViolation
+}
+
+func (e *DeniedError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = fmt.Sprintf("%s: %s", v.Policy, v.Message)
+	}
+	return fmt.Sprintf("denied by admission policy: %s", strings.Join(messages, "; "))
+}
+
+func (e *DeniedError) Unwrap() error {
+	return store.ErrForbidden
+}
+
+// Evaluate evaluates the policies that apply to target in projectID
+// against doc, returning the violations of "deny" policies and of "warn"
+// ones separately.
+func Evaluate(policies []models.AdmissionPolicy, target, projectID string, doc map[string]interface{}) (denials, warnings []models.PolicyViolation) {
+	for i := range policies {
+		if !policies[i].AppliesTo(target, projectID) {
+			continue
+		}
+		for _, v := range policies[i].Evaluate(doc) {
+			if v.Enforcement == models.PolicyEnforceDeny {
+				denials = append(denials, v)
+			} else {
+				warnings = append(warnings, v)
+			}
+		}
+	}
+	return denials, warnings
+}
+
+// Document returns the JSON object form of subject, what policy rules'
+// fields refer to.
+func Document(subject interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(subject)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// Violations loads the admission policies and returns what subject breaks
+// of those for target in projectID: the "deny" violations, having logged
+// the "warn" ones. A store without admission policies has none.
+func Violations(ctx context.Context, st store.Store, target, projectID string, subject interface{}) ([]models.PolicyViolation, error) {
+	ps, ok := st.(policyStore)
+	if !ok {
+		return nil, nil
+	}
+	policies, err := ps.ListAdmissionPolicies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load admission policies: %w", err)
+	}
+	if len(policies) == 0 {
+		return nil, nil
+	}
+	doc, err := Document(subject)
+	if err != nil {
+		return nil, err
+	}
+	denials, warnings := Evaluate(policies, target, projectID, doc)
+	for _, v := range warnings {
+		logging.Log.WithFields(map[string]interface{}{
+			"policy":     v.Policy,
+			"policy_id":  v.PolicyID,
+			"target":     target,
+			"project_id": projectID,
+			"field":      v.Field,
+		}).Warn("Admission policy violated: " + v.Message)
+	}
+	return denials, nil
+}
+
+// Check returns a *DeniedError if subject breaks a "deny" policy for
+// target in projectID.
+func Check(ctx context.Context, st store.Store, target, projectID string, subject interface{}) error {
+	denials, err := Violations(ctx, st, target, projectID, subject)
+	if err != nil {
+		return err
+	}
+	if len(denials) > 0 {
+		return &DeniedError{Target: target, Violations: denials}
+	}
+	return nil
+}
+
+// RegisterJobHook checks every job about to be created against the "job"
+// policies in st, as a hooks.PreJobCreate hook. It returns a function that
+// removes the hook again.
+func RegisterJobHook(st store.Store) func() {
+	return hooks.Register(hooks.PreJobCreate, HookName, hooks.HookFunc(func(ctx context.Context, event *hooks.Event) error {
+		return Check(ctx, st, models.PolicyTargetJob, ProjectID(event.Job.ProjectID), event.Job)
+	}))
+}
+
+// ProjectID dereferences an optional project ID.
+func ProjectID(projectID *string) string {
+	if projectID == nil {
+		return ""
+	}
+	return *projectID
+}
@@ -55,6 +55,11 @@ var (
 	// trusted-identity row or trusted-domain-pattern regex.
 	ErrNotAdmitted = errors.New("auth: identity is not on the admission list")
 
+	// ErrServiceAccountLogin is returned by LoginService.FinishLogin when
+	// the identity maps to a service account, which only authenticates
+	// with API tokens.
+	ErrServiceAccountLogin = errors.New("auth: service accounts cannot log in interactively")
+
 	// ErrAttemptExpired is returned by LoginService.FinishLogin when the
 	// consumed login attempt's expires_at has already passed.
 	ErrAttemptExpired = errors.New("auth: login attempt has expired")
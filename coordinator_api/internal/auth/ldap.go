@@ -0,0 +1,433 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// LDAPDomain is the auth_identities.domain of users provisioned from LDAP.
+// Their subject is "<lowercased username>@ldap".
+const LDAPDomain = "ldap"
+
+// ldapCacheLimit is when the authentication cache prunes expired entries.
+const ldapCacheLimit = 1024
+
+// ErrInvalidCredentials is returned by LDAPAuthenticator.AuthenticateBasic
+// when the username or password is wrong.
+var ErrInvalidCredentials = errors.New("auth: invalid credentials")
+
+// LDAPStore is the narrow store surface LDAP authentication and group sync
+// consume: identity->user provisioning plus role updates.
+type LDAPStore interface {
+	GetAuthIdentityBySubject(ctx context.Context, subject string) (*models.AuthIdentity, error)
+	CreateAuthIdentity(ctx context.Context, identity *models.AuthIdentity) error
+	UpdateAuthIdentityLogin(ctx context.Context, identityID string, displayName string) error
+	ListAuthIdentitiesByDomain(ctx context.Context, domain string) ([]models.AuthIdentity, error)
+	CreateUser(ctx context.Context, user *models.User) error
+	GetUserByID(ctx context.Context, userID string) (*models.User, error)
+	SetUserRoles(ctx context.Context, userID string, roles []string) error
+	DeactivateAPITokensByUser(ctx context.Context, userID string) (int64, error)
+}
+
+// LDAPDirectory is one connection to the directory; *ldap.Conn in
+// production.
+type LDAPDirectory interface {
+	Bind(dn, password string) error
+	Search(req *ldap.SearchRequest) (*ldap.SearchResult, error)
+	Close() error
+}
+
+// LDAPConfig is how LDAPAuthenticator finds users and maps their groups.
+type LDAPConfig struct {
+	BindDN         string
+	BindPassword   string
+	UserBaseDN     string
+	UserAttribute  string
+	GroupAttribute string
+	// GroupRoles maps normalized group DNs (see normalizeDN) to the user
+	// role members get.
+	GroupRoles map[string]string
+	// CacheTTL is how long a successful authentication is remembered.
+	CacheTTL time.Duration
+	// Timeout bounds each directory search, server side.
+	Timeout time.Duration
+}
+
+// LDAPConfigFromEnv builds an LDAPConfig from the REACTORCIDE_LDAP_* env
+// vars. Callers should check config.LDAPURL is set first.
+func LDAPConfigFromEnv() (LDAPConfig, error) {
+	if err := config.ValidateLDAP(); err != nil {
+		return LDAPConfig{}, err
+	}
+	groupRoles, err := ParseLDAPGroupRoles(config.LDAPGroupRoles)
+	if err != nil {
+		return LDAPConfig{}, err
+	}
+	return LDAPConfig{
+		BindDN:         config.LDAPBindDN,
+		BindPassword:   config.LDAPBindPassword,
+		UserBaseDN:     config.LDAPUserBaseDN,
+		UserAttribute:  config.LDAPUserAttribute,
+		GroupAttribute: config.LDAPGroupAttribute,
+		GroupRoles:     groupRoles,
+		CacheTTL:       time.Duration(config.LDAPCacheSeconds) * time.Second,
+		Timeout:        time.Duration(config.LDAPTimeoutSeconds) * time.Second,
+	}, nil
+}
+
+// ParseLDAPGroupRoles parses REACTORCIDE_LDAP_GROUP_ROLES: semicolon-
+// separated "group DN=role" entries, split at the last "=" since DNs are
+// full of them. Roles are admin or support; every LDAP user is a user.
+func ParseLDAPGroupRoles(s string) (map[string]string, error) {
+	out := map[string]string{}
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		i := strings.LastIndex(entry, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid REACTORCIDE_LDAP_GROUP_ROLES entry %q: want \"group DN=role\"", entry)
+		}
+		dn, role := normalizeDN(entry[:i]), strings.TrimSpace(entry[i+1:])
+		switch models.UserRole(role) {
+		case models.UserRoleAdmin, models.UserRoleSupport, models.UserRoleUser:
+		default:
+			return nil, fmt.Errorf("invalid role %q in REACTORCIDE_LDAP_GROUP_ROLES: must be admin, support or user", role)
+		}
+		out[dn] = role
+	}
+	return out, nil
+}
+
+// normalizeDN lowercases a DN and drops the spaces around its separators,
+// so the directory's spelling of a group matches the configured one.
+func normalizeDN(dn string) string {
+	parts := strings.Split(strings.ToLower(dn), ",")
+	for i, p := range parts {
+		rdn := strings.SplitN(p, "=", 2)
+		for j := range rdn {
+			rdn[j] = strings.TrimSpace(rdn[j])
+		}
+		parts[i] = strings.Join(rdn, "=")
+	}
+	return strings.Join(parts, ",")
+}
+
+type ldapCacheEntry struct {
+	userID  string
+	expires time.Time
+}
+
+// LDAPAuthenticator verifies API credentials against an LDAP or Active
+// Directory server by binding as the user, provisions a local user on
+// first success, and keeps users' roles in line with their groups.
+type LDAPAuthenticator struct {
+	config LDAPConfig
+	store  LDAPStore
+	dial   func() (LDAPDirectory, error)
+	now    func() time.Time
+
+	mu    sync.Mutex
+	cache map[[32]byte]ldapCacheEntry
+}
+
+// NewLDAPAuthenticator returns an authenticator for cfg; dial opens a
+// connection to the directory.
+func NewLDAPAuthenticator(cfg LDAPConfig, st LDAPStore, dial func() (LDAPDirectory, error)) *LDAPAuthenticator {
+	return &LDAPAuthenticator{
+		config: cfg,
+		store:  st,
+		dial:   dial,
+		now:    time.Now,
+		cache:  map[[32]byte]ldapCacheEntry{},
+	}
+}
+
+// DialLDAPFromEnv dials the directory in REACTORCIDE_LDAP_URL, upgrading
+// an ldap:// connection with StartTLS if REACTORCIDE_LDAP_START_TLS is set.
+func DialLDAPFromEnv() (LDAPDirectory, error) {
+	u, err := url.Parse(config.LDAPURL)
+	if err != nil {
+		return nil, fmt.Errorf("ldap: invalid URL: %w", err)
+	}
+	timeout := time.Duration(config.LDAPTimeoutSeconds) * time.Second
+	tlsConfig := &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}
+	conn, err := ldap.DialURL(config.LDAPURL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: timeout}),
+		ldap.DialWithTLSConfig(tlsConfig),
+	)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(timeout)
+	if config.LDAPStartTLS && u.Scheme == "ldap" {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("ldap: StartTLS: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// AuthenticateBasic verifies username and password against the directory
+// and returns the local user, provisioning it on first login. Returns
+// ErrInvalidCredentials for unknown users and wrong passwords.
+func (a *LDAPAuthenticator) AuthenticateBasic(ctx context.Context, username, password string) (*models.User, error) {
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+	key := sha256.Sum256([]byte(username + "\x00" + password))
+	if userID, ok := a.cached(key); ok {
+		user, err := a.store.GetUserByID(ctx, userID)
+		if err == nil && !user.IsServiceAccount && !user.IsDeactivated() {
+			return user, nil
+		}
+	}
+
+	dir, err := a.dial()
+	if err != nil {
+		return nil, fmt.Errorf("auth: connecting to ldap: %w", err)
+	}
+	defer dir.Close()
+	if err := dir.Bind(a.config.BindDN, a.config.BindPassword); err != nil {
+		return nil, fmt.Errorf("auth: ldap service bind: %w", err)
+	}
+	entry, err := a.lookup(dir, username)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, ErrInvalidCredentials
+	}
+	if err := dir.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("auth: ldap user bind: %w", err)
+	}
+
+	user, err := a.provision(ctx, username, entry)
+	if err != nil {
+		return nil, err
+	}
+	a.remember(key, user.UserID)
+	return user, nil
+}
+
+// Sync brings every LDAP user's roles in line with their groups. Users no
+// longer in the directory drop to the user role and lose their API
+// tokens. Returns how many users changed.
+func (a *LDAPAuthenticator) Sync(ctx context.Context) (int, error) {
+	identities, err := a.store.ListAuthIdentitiesByDomain(ctx, LDAPDomain)
+	if err != nil {
+		return 0, fmt.Errorf("auth: listing ldap users: %w", err)
+	}
+	if len(identities) == 0 {
+		return 0, nil
+	}
+	dir, err := a.dial()
+	if err != nil {
+		return 0, fmt.Errorf("auth: connecting to ldap: %w", err)
+	}
+	defer dir.Close()
+	if err := dir.Bind(a.config.BindDN, a.config.BindPassword); err != nil {
+		return 0, fmt.Errorf("auth: ldap service bind: %w", err)
+	}
+
+	changed := 0
+	for _, identity := range identities {
+		user, err := a.store.GetUserByID(ctx, identity.UserID)
+		if err != nil {
+			return changed, fmt.Errorf("auth: loading ldap user %s: %w", identity.UserID, err)
+		}
+		entry, err := a.lookup(dir, identity.Handle)
+		if err != nil {
+			return changed, err
+		}
+		roles := []string{string(models.UserRoleUser)}
+		if entry != nil {
+			roles = a.rolesFor(entry)
+		} else {
+			a.forget(user.UserID)
+			if _, err := a.store.DeactivateAPITokensByUser(ctx, user.UserID); err != nil {
+				return changed, fmt.Errorf("auth: deactivating tokens of removed ldap user %s: %w", user.UserID, err)
+			}
+		}
+		updated, err := a.setRoles(ctx, user, roles)
+		if err != nil {
+			return changed, err
+		}
+		if updated {
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+// lookup finds username's entry, or nil if there isn't exactly one.
+func (a *LDAPAuthenticator) lookup(dir LDAPDirectory, username string) (*ldap.Entry, error) {
+	result, err := dir.Search(ldap.NewSearchRequest(
+		a.config.UserBaseDN,
+		ldap.ScopeWholeSubtree,
+		ldap.NeverDerefAliases,
+		2,
+		int(a.config.Timeout/time.Second),
+		false,
+		ldapEqualityFilter(a.config.UserAttribute, username),
+		[]string{a.config.UserAttribute, a.config.GroupAttribute, "mail", "displayName"},
+		nil,
+	))
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		// More than one entry matched.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("auth: searching ldap for %s: %w", username, err)
+	}
+	if len(result.Entries) != 1 {
+		return nil, nil
+	}
+	return result.Entries[0], nil
+}
+
+// ldapEqualityFilter matches entries whose attr is value, escaping value
+// so a username can't inject filter syntax.
+func ldapEqualityFilter(attr, value string) string {
+	return "(" + attr + "=" + ldap.EscapeFilter(value) + ")"
+}
+
+// provision maps a directory entry to a local user, creating the user and
+// its auth identity the first time, and applies its group roles.
+func (a *LDAPAuthenticator) provision(ctx context.Context, username string, entry *ldap.Entry) (*models.User, error) {
+	subject := strings.ToLower(username) + "@" + LDAPDomain
+	displayName := entry.GetEqualFoldAttributeValue("displayName")
+
+	identity, err := a.store.GetAuthIdentityBySubject(ctx, subject)
+	switch {
+	case err == nil:
+	case errors.Is(err, store.ErrNotFound):
+		user := &models.User{
+			Username: username,
+			Email:    entry.GetEqualFoldAttributeValue("mail"),
+		}
+		if err := a.store.CreateUser(ctx, user); err != nil {
+			return nil, fmt.Errorf("auth: creating ldap user: %w", err)
+		}
+		identity = &models.AuthIdentity{
+			UserID:      user.UserID,
+			Subject:     subject,
+			Handle:      username,
+			Domain:      LDAPDomain,
+			DisplayName: displayName,
+		}
+		if err := a.store.CreateAuthIdentity(ctx, identity); err != nil {
+			return nil, fmt.Errorf("auth: creating ldap identity: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("auth: looking up ldap identity: %w", err)
+	}
+	if err := a.store.UpdateAuthIdentityLogin(ctx, identity.IdentityID, displayName); err != nil {
+		return nil, fmt.Errorf("auth: stamping ldap login: %w", err)
+	}
+
+	user, err := a.store.GetUserByID(ctx, identity.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("auth: loading ldap user: %w", err)
+	}
+	if user.IsServiceAccount {
+		return nil, ErrServiceAccountLogin
+	}
+	if user.IsDeactivated() {
+		return nil, ErrUserDeactivated
+	}
+	if _, err := a.setRoles(ctx, user, a.rolesFor(entry)); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// rolesFor returns the sorted roles entry's groups map to, always
+// including user.
+func (a *LDAPAuthenticator) rolesFor(entry *ldap.Entry) []string {
+	set := map[string]bool{string(models.UserRoleUser): true}
+	for _, group := range entry.GetEqualFoldAttributeValues(a.config.GroupAttribute) {
+		if role, ok := a.config.GroupRoles[normalizeDN(group)]; ok {
+			set[role] = true
+		}
+	}
+	roles := make([]string, 0, len(set))
+	for role := range set {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// setRoles stores roles on user if they differ, reporting whether they did.
+func (a *LDAPAuthenticator) setRoles(ctx context.Context, user *models.User, roles []string) (bool, error) {
+	current := append([]string(nil), user.Roles...)
+	sort.Strings(current)
+	if strings.Join(current, ",") == strings.Join(roles, ",") {
+		return false, nil
+	}
+	if err := a.store.SetUserRoles(ctx, user.UserID, roles); err != nil {
+		return false, fmt.Errorf("auth: updating roles of ldap user %s: %w", user.UserID, err)
+	}
+	user.Roles = roles
+	return true, nil
+}
+
+func (a *LDAPAuthenticator) cached(key [32]byte) (string, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	entry, ok := a.cache[key]
+	if !ok || !a.now().Before(entry.expires) {
+		return "", false
+	}
+	return entry.userID, true
+}
+
+func (a *LDAPAuthenticator) remember(key [32]byte, userID string) {
+	if a.config.CacheTTL <= 0 {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := a.now()
+	if len(a.cache) >= ldapCacheLimit {
+		for k, entry := range a.cache {
+			if !now.Before(entry.expires) {
+				delete(a.cache, k)
+			}
+		}
+	}
+	if len(a.cache) < ldapCacheLimit {
+		a.cache[key] = ldapCacheEntry{userID: userID, expires: now.Add(a.config.CacheTTL)}
+	}
+}
+
+// forget drops userID's cached authentications.
+func (a *LDAPAuthenticator) forget(userID string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for k, entry := range a.cache {
+		if entry.userID == userID {
+			delete(a.cache, k)
+		}
+	}
+}
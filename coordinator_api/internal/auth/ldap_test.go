@@ -0,0 +1,219 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// --- LDAPStore, on top of fakeStore ---------------------------------------
+
+type ldapFakeStore struct {
+	*fakeStore
+	deactivated map[string]bool
+}
+
+func (f *ldapFakeStore) ListAuthIdentitiesByDomain(_ context.Context, domain string) ([]models.AuthIdentity, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []models.AuthIdentity
+	for _, identity := range f.authIdentitiesBySubject {
+		if identity.Domain == domain {
+			out = append(out, identity)
+		}
+	}
+	return out, nil
+}
+
+func (f *ldapFakeStore) SetUserRoles(_ context.Context, userID string, roles []string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	user, ok := f.users[userID]
+	if !ok {
+		return store.ErrNotFound
+	}
+	user.Roles = roles
+	f.users[userID] = user
+	return nil
+}
+
+func (f *ldapFakeStore) DeactivateAPITokensByUser(_ context.Context, userID string) (int64, error) {
+	f.deactivated[userID] = true
+	return 1, nil
+}
+
+// fakeDirectory is an LDAPDirectory over an in-memory set of entries,
+// keyed by uid, with passwords by DN. It records the filters searched.
+type fakeDirectory struct {
+	entries   map[string]*ldap.Entry
+	passwords map[string]string
+	dials     int
+	filters   []string
+}
+
+func (d *fakeDirectory) dial() (LDAPDirectory, error) {
+	d.dials++
+	return d, nil
+}
+
+func (d *fakeDirectory) Bind(dn, password string) error {
+	if want, ok := d.passwords[dn]; !ok || want != password || password == "" {
+		return ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("invalid credentials"))
+	}
+	return nil
+}
+
+func (d *fakeDirectory) Search(req *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	// Match each entry's own uid filter.
+	d.filters = append(d.filters, req.Filter)
+	result := &ldap.SearchResult{}
+	for _, e := range d.entries {
+		if ldapEqualityFilter("uid", e.GetAttributeValue("uid")) == req.Filter {
+			result.Entries = append(result.Entries, e)
+		}
+	}
+	return result, nil
+}
+
+func (d *fakeDirectory) Close() error { return nil }
+
+const (
+	adminsGroup  = "cn=ci-admins,ou=groups,dc=example,dc=com"
+	supportGroup = "cn=ci-support,ou=groups,dc=example,dc=com"
+	serviceDN    = "cn=reactorcide,dc=example,dc=com"
+	aliceDN      = "uid=alice,ou=people,dc=example,dc=com"
+)
+
+func aliceEntry(groups ...string) *ldap.Entry {
+	return ldap.NewEntry(aliceDN, map[string][]string{
+		"uid":         {"alice"},
+		"mail":        {"alice@example.com"},
+		"displayName": {"Alice"},
+		"memberOf":    groups,
+	})
+}
+
+func newLDAPTest(t *testing.T) (*LDAPAuthenticator, *ldapFakeStore, *fakeDirectory) {
+	t.Helper()
+	roles, err := ParseLDAPGroupRoles("CN=ci-admins, OU=groups, DC=example, DC=com=admin; " + supportGroup + "=support")
+	if err != nil {
+		t.Fatalf("ParseLDAPGroupRoles: %v", err)
+	}
+	dir := &fakeDirectory{
+		entries: map[string]*ldap.Entry{
+			"alice": aliceEntry(adminsGroup),
+		},
+		passwords: map[string]string{serviceDN: "svc-pass", aliceDN: "alice-pass"},
+	}
+	st := &ldapFakeStore{fakeStore: newFakeStore(), deactivated: map[string]bool{}}
+	a := NewLDAPAuthenticator(LDAPConfig{
+		BindDN:         serviceDN,
+		BindPassword:   "svc-pass",
+		UserBaseDN:     "ou=people,dc=example,dc=com",
+		UserAttribute:  "uid",
+		GroupAttribute: "memberOf",
+		GroupRoles:     roles,
+		CacheTTL:       time.Minute,
+	}, st, dir.dial)
+	return a, st, dir
+}
+
+func TestLDAPAuthenticator_AuthenticateBasic(t *testing.T) {
+	a, st, dir := newLDAPTest(t)
+	ctx := context.Background()
+
+	user, err := a.AuthenticateBasic(ctx, "alice", "alice-pass")
+	if err != nil {
+		t.Fatalf("AuthenticateBasic: %v", err)
+	}
+	if user.Username != "alice" || user.Email != "alice@example.com" {
+		t.Errorf("provisioned user = %+v", user)
+	}
+	if got := []string(st.users[user.UserID].Roles); len(got) != 2 || got[0] != "admin" || got[1] != "user" {
+		t.Errorf("roles = %v, want [admin user]", got)
+	}
+	identity, err := st.GetAuthIdentityBySubject(ctx, "alice@ldap")
+	if err != nil || identity.UserID != user.UserID || identity.Domain != LDAPDomain {
+		t.Errorf("identity = %+v, %v", identity, err)
+	}
+
+	// A second login reuses the user, and the cache skips the directory.
+	again, err := a.AuthenticateBasic(ctx, "alice", "alice-pass")
+	if err != nil || again.UserID != user.UserID {
+		t.Errorf("second login = %+v, %v", again, err)
+	}
+	if dir.dials != 1 {
+		t.Errorf("dials = %d, want the cached login not to dial", dir.dials)
+	}
+
+	for _, tt := range []struct{ username, password string }{
+		{"alice", "wrong"},
+		{"alice", ""},
+		{"mallory", "alice-pass"},
+		{"*", "alice-pass"},
+	} {
+		if _, err := a.AuthenticateBasic(ctx, tt.username, tt.password); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("AuthenticateBasic(%q, %q) = %v, want ErrInvalidCredentials", tt.username, tt.password, err)
+		}
+	}
+	if got := dir.filters[len(dir.filters)-1]; got != `(uid=\2a)` {
+		t.Errorf("filter for username * = %q, want it escaped", got)
+	}
+}
+
+func TestLDAPAuthenticator_Sync(t *testing.T) {
+	a, st, dir := newLDAPTest(t)
+	ctx := context.Background()
+	user, err := a.AuthenticateBasic(ctx, "alice", "alice-pass")
+	if err != nil {
+		t.Fatalf("AuthenticateBasic: %v", err)
+	}
+
+	// Moved from admins to support.
+	dir.entries["alice"] = aliceEntry(supportGroup)
+	changed, err := a.Sync(ctx)
+	if err != nil || changed != 1 {
+		t.Fatalf("Sync = %d, %v; want 1 change", changed, err)
+	}
+	if got := []string(st.users[user.UserID].Roles); len(got) != 2 || got[0] != "support" || got[1] != "user" {
+		t.Errorf("roles after move = %v, want [support user]", got)
+	}
+	if changed, _ := a.Sync(ctx); changed != 0 {
+		t.Errorf("second Sync changed %d users, want 0", changed)
+	}
+
+	// Removed from the directory.
+	delete(dir.entries, "alice")
+	if _, err := a.Sync(ctx); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if got := []string(st.users[user.UserID].Roles); len(got) != 1 || got[0] != "user" {
+		t.Errorf("roles after removal = %v, want [user]", got)
+	}
+	if !st.deactivated[user.UserID] {
+		t.Error("expected the removed user's tokens to be deactivated")
+	}
+	if _, err := a.AuthenticateBasic(ctx, "alice", "alice-pass"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("login after removal = %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestParseLDAPGroupRoles(t *testing.T) {
+	roles, err := ParseLDAPGroupRoles("cn=a,dc=x=admin;;cn=b , dc=x = support")
+	if err != nil {
+		t.Fatalf("ParseLDAPGroupRoles: %v", err)
+	}
+	if roles["cn=a,dc=x"] != "admin" || roles["cn=b,dc=x"] != "support" {
+		t.Errorf("roles = %v", roles)
+	}
+	for _, bad := range []string{"cn=a,dc=x=owner", "admin"} {
+		if _, err := ParseLDAPGroupRoles(bad); err == nil {
+			t.Errorf("ParseLDAPGroupRoles(%q) succeeded, want an error", bad)
+		}
+	}
+}
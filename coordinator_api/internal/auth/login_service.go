@@ -183,6 +183,12 @@ func (l *LoginService) FinishLogin(ctx context.Context, attemptToken, arrivedURL
 	if err != nil {
 		return "", nil, err
 	}
+	if user.IsServiceAccount {
+		return "", nil, ErrServiceAccountLogin
+	}
+	if user.IsDeactivated() {
+		return "", nil, ErrUserDeactivated
+	}
 
 	token, err := l.sessions.MintSession(ctx, user.UserID)
 	if err != nil {
@@ -221,15 +227,22 @@ func (l *LoginService) provisionUser(ctx context.Context, v *VerifiedIdentity) (
 			return nil, fmt.Errorf("auth: updating identity login: %w", err)
 		}
 	case errors.Is(err, store.ErrNotFound):
-		user := &models.User{
-			Username: usernameFor(v),
-			Email:    v.Claims["email"],
+		userID, err := l.scimUserFor(ctx, v)
+		if err != nil {
+			return nil, err
 		}
-		if err := l.store.CreateUser(ctx, user); err != nil {
-			return nil, fmt.Errorf("auth: creating user: %w", err)
+		if userID == "" {
+			user := &models.User{
+				Username: usernameFor(v),
+				Email:    v.Claims["email"],
+			}
+			if err := l.store.CreateUser(ctx, user); err != nil {
+				return nil, fmt.Errorf("auth: creating user: %w", err)
+			}
+			userID = user.UserID
 		}
 		identity = &models.AuthIdentity{
-			UserID:      user.UserID,
+			UserID:      userID,
 			Subject:     subject,
 			Handle:      v.Handle,
 			Domain:      v.Domain,
@@ -257,6 +270,28 @@ func (l *LoginService) provisionUser(ctx context.Context, v *VerifiedIdentity) (
 	return user, nil
 }
 
+// scimUserFor returns the ID of the SCIM-provisioned user whose userName is
+// v's "handle@domain", so their first login lands on the account the
+// identity provider created, or "" if there's none (or the store has no
+// SCIM users).
+func (l *LoginService) scimUserFor(ctx context.Context, v *VerifiedIdentity) (string, error) {
+	scim, ok := l.store.(interface {
+		GetSCIMUserByUserName(ctx context.Context, userName string) (*models.SCIMUser, error)
+	})
+	if !ok || v.Handle == "" {
+		return "", nil
+	}
+	link, err := scim.GetSCIMUserByUserName(ctx, strings.ToLower(v.Handle+"@"+v.Domain))
+	switch {
+	case err == nil:
+		return link.UserID, nil
+	case errors.Is(err, store.ErrNotFound):
+		return "", nil
+	default:
+		return "", fmt.Errorf("auth: looking up scim user: %w", err)
+	}
+}
+
 // maybeGrantFirstAdmin grants global admin to userID exactly once: only
 // when REACTORCIDE_FIRST_ADMIN is configured, v matches it, and no global
 // admin role assignment exists yet. Safe to call on every login (a no-op
@@ -0,0 +1,388 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// ErrUserDeactivated is returned when a deprovisioned user tries to log in.
+var ErrUserDeactivated = errors.New("auth: user has been deactivated")
+
+// SCIMStore is the narrow store surface SCIM provisioning consumes. See
+// postgres_store/scim_operations.go.
+type SCIMStore interface {
+	CreateUser(ctx context.Context, user *models.User) error
+	GetUserByID(ctx context.Context, userID string) (*models.User, error)
+	UpdateUser(ctx context.Context, user *models.User) error
+	SetUserRoles(ctx context.Context, userID string, roles []string) error
+	SetUserDeactivated(ctx context.Context, userID string, deactivatedAt *time.Time) error
+	DeactivateAPITokensByUser(ctx context.Context, userID string) (int64, error)
+	RevokeUISessionsByUser(ctx context.Context, userID string) (int64, error)
+
+	GetSCIMUser(ctx context.Context, userID string) (*models.SCIMUser, error)
+	GetSCIMUserByUserName(ctx context.Context, userName string) (*models.SCIMUser, error)
+	ListSCIMUsers(ctx context.Context, userName string, limit, offset int) ([]models.SCIMUser, int64, error)
+	CreateSCIMUser(ctx context.Context, user *models.SCIMUser) error
+	UpdateSCIMUser(ctx context.Context, user *models.SCIMUser) error
+	DeleteSCIMUser(ctx context.Context, userID string) error
+
+	GetSCIMGroup(ctx context.Context, groupID string) (*models.SCIMGroup, error)
+	ListSCIMGroups(ctx context.Context, displayName string, limit, offset int) ([]models.SCIMGroup, int64, error)
+	ListSCIMGroupsByUser(ctx context.Context, userID string) ([]models.SCIMGroup, error)
+	CreateSCIMGroup(ctx context.Context, group *models.SCIMGroup) error
+	UpdateSCIMGroup(ctx context.Context, group *models.SCIMGroup) error
+	DeleteSCIMGroup(ctx context.Context, groupID string) error
+	ListSCIMGroupMembers(ctx context.Context, groupID string) ([]string, error)
+	AddSCIMGroupMembers(ctx context.Context, groupID string, userIDs []string) error
+	RemoveSCIMGroupMembers(ctx context.Context, groupID string, userIDs []string) error
+}
+
+// SCIMUserInput is what an identity provider sets on a user.
+type SCIMUserInput struct {
+	UserName    string
+	ExternalID  string
+	DisplayName string
+	GivenName   string
+	FamilyName  string
+	Email       string
+	Active      bool
+}
+
+// SCIMProvisioner applies what an identity provider pushes over SCIM:
+// creating users, deprovisioning them, and keeping their roles in line with
+// their groups.
+type SCIMProvisioner struct {
+	store SCIMStore
+	// groupRoles maps lowercased group display names to the user role
+	// members get.
+	groupRoles map[string]string
+	now        func() time.Time
+}
+
+// NewSCIMProvisioner creates a SCIMProvisioner; groupRoles comes from
+// ParseSCIMGroupRoles.
+func NewSCIMProvisioner(st SCIMStore, groupRoles map[string]string) *SCIMProvisioner {
+	return &SCIMProvisioner{store: st, groupRoles: groupRoles, now: time.Now}
+}
+
+// SCIMProvisionerFromEnv creates a SCIMProvisioner mapping groups to roles
+// per REACTORCIDE_SCIM_GROUP_ROLES.
+func SCIMProvisionerFromEnv(st SCIMStore) (*SCIMProvisioner, error) {
+	groupRoles, err := ParseSCIMGroupRoles(config.SCIMGroupRoles)
+	if err != nil {
+		return nil, err
+	}
+	return NewSCIMProvisioner(st, groupRoles), nil
+}
+
+// ParseSCIMGroupRoles parses REACTORCIDE_SCIM_GROUP_ROLES: semicolon-
+// separated "group display name=role" entries. Display names are matched
+// case-insensitively. Roles are admin or support; every SCIM user is a
+// user.
+func ParseSCIMGroupRoles(s string) (map[string]string, error) {
+	out := map[string]string{}
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		i := strings.LastIndex(entry, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid REACTORCIDE_SCIM_GROUP_ROLES entry %q: want \"group=role\"", entry)
+		}
+		group, role := strings.ToLower(strings.TrimSpace(entry[:i])), strings.TrimSpace(entry[i+1:])
+		switch models.UserRole(role) {
+		case models.UserRoleAdmin, models.UserRoleSupport, models.UserRoleUser:
+		default:
+			return nil, fmt.Errorf("invalid role %q in REACTORCIDE_SCIM_GROUP_ROLES: must be admin, support or user", role)
+		}
+		out[group] = role
+	}
+	return out, nil
+}
+
+// CreateUser provisions a new user. Returns store.ErrAlreadyExists if a
+// SCIM user or any other user already has in.UserName.
+func (p *SCIMProvisioner) CreateUser(ctx context.Context, in SCIMUserInput) (*models.SCIMUser, *models.User, error) {
+	userName := strings.ToLower(strings.TrimSpace(in.UserName))
+	if userName == "" {
+		return nil, nil, store.ErrInvalidInput
+	}
+	if _, err := p.store.GetSCIMUserByUserName(ctx, userName); err == nil {
+		return nil, nil, store.ErrAlreadyExists
+	} else if !errors.Is(err, store.ErrNotFound) {
+		return nil, nil, err
+	}
+
+	user := &models.User{Username: userName, Email: in.Email}
+	if err := p.store.CreateUser(ctx, user); err != nil {
+		return nil, nil, fmt.Errorf("auth: creating scim user: %w", err)
+	}
+	link := &models.SCIMUser{UserID: user.UserID}
+	applySCIMUserInput(link, in)
+	if err := p.store.CreateSCIMUser(ctx, link); err != nil {
+		return nil, nil, fmt.Errorf("auth: linking scim user: %w", err)
+	}
+	if !in.Active {
+		if err := p.deprovision(ctx, user); err != nil {
+			return nil, nil, err
+		}
+	}
+	return link, user, nil
+}
+
+// ReplaceUser sets a SCIM user's attributes to in. Setting Active to
+// false deprovisions them (see DeleteUser); setting it back restores their
+// group roles and lets them log in again, but their API tokens stay
+// deactivated.
+func (p *SCIMProvisioner) ReplaceUser(ctx context.Context, userID string, in SCIMUserInput) (*models.SCIMUser, *models.User, error) {
+	link, err := p.store.GetSCIMUser(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	user, err := p.store.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	userName := strings.ToLower(strings.TrimSpace(in.UserName))
+	if userName == "" {
+		return nil, nil, store.ErrInvalidInput
+	}
+	if userName != link.UserName {
+		if other, err := p.store.GetSCIMUserByUserName(ctx, userName); err == nil && other.UserID != userID {
+			return nil, nil, store.ErrAlreadyExists
+		} else if err != nil && !errors.Is(err, store.ErrNotFound) {
+			return nil, nil, err
+		}
+	}
+	applySCIMUserInput(link, in)
+	if err := p.store.UpdateSCIMUser(ctx, link); err != nil {
+		return nil, nil, fmt.Errorf("auth: updating scim user: %w", err)
+	}
+	if user.Email != in.Email {
+		user.Email = in.Email
+		if err := p.store.UpdateUser(ctx, user); err != nil {
+			return nil, nil, fmt.Errorf("auth: updating scim user email: %w", err)
+		}
+	}
+
+	switch {
+	case !in.Active && !user.IsDeactivated():
+		if err := p.deprovision(ctx, user); err != nil {
+			return nil, nil, err
+		}
+	case in.Active && user.IsDeactivated():
+		if err := p.store.SetUserDeactivated(ctx, userID, nil); err != nil {
+			return nil, nil, fmt.Errorf("auth: reactivating scim user %s: %w", userID, err)
+		}
+		user.DeactivatedAt = nil
+		if err := p.syncRoles(ctx, user); err != nil {
+			return nil, nil, err
+		}
+	}
+	return link, user, nil
+}
+
+// DeleteUser deprovisions a SCIM user and forgets their SCIM link and
+// group memberships. The users row stays, deactivated, since jobs and
+// audit entries refer to it.
+func (p *SCIMProvisioner) DeleteUser(ctx context.Context, userID string) error {
+	if _, err := p.store.GetSCIMUser(ctx, userID); err != nil {
+		return err
+	}
+	user, err := p.store.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.IsDeactivated() {
+		if err := p.deprovision(ctx, user); err != nil {
+			return err
+		}
+	}
+	if err := p.store.DeleteSCIMUser(ctx, userID); err != nil {
+		return fmt.Errorf("auth: deleting scim user %s: %w", userID, err)
+	}
+	return nil
+}
+
+// CreateGroup creates a SCIM group with memberIDs, granting them the role
+// it maps to. Returns store.ErrInvalidInput if a member isn't a SCIM user.
+func (p *SCIMProvisioner) CreateGroup(ctx context.Context, group *models.SCIMGroup, memberIDs []string) error {
+	if strings.TrimSpace(group.DisplayName) == "" {
+		return store.ErrInvalidInput
+	}
+	if err := p.store.CreateSCIMGroup(ctx, group); err != nil {
+		return fmt.Errorf("auth: creating scim group: %w", err)
+	}
+	return p.AddMembers(ctx, group.GroupID, memberIDs)
+}
+
+// ReplaceGroup saves a SCIM group and sets its members to memberIDs.
+func (p *SCIMProvisioner) ReplaceGroup(ctx context.Context, group *models.SCIMGroup, memberIDs []string) error {
+	if strings.TrimSpace(group.DisplayName) == "" {
+		return store.ErrInvalidInput
+	}
+	if err := p.checkMembers(ctx, memberIDs); err != nil {
+		return err
+	}
+	current, err := p.store.ListSCIMGroupMembers(ctx, group.GroupID)
+	if err != nil {
+		return err
+	}
+	if err := p.store.UpdateSCIMGroup(ctx, group); err != nil {
+		return fmt.Errorf("auth: updating scim group: %w", err)
+	}
+	keep := make(map[string]bool, len(memberIDs))
+	for _, id := range memberIDs {
+		keep[id] = true
+	}
+	var removed []string
+	for _, id := range current {
+		if !keep[id] {
+			removed = append(removed, id)
+		}
+	}
+	if err := p.RemoveMembers(ctx, group.GroupID, removed); err != nil {
+		return err
+	}
+	// A rename can change what the group maps to, so every member is
+	// synced, not only the new ones.
+	if err := p.store.AddSCIMGroupMembers(ctx, group.GroupID, memberIDs); err != nil {
+		return fmt.Errorf("auth: adding scim group members: %w", err)
+	}
+	return p.syncUsers(ctx, memberIDs)
+}
+
+// AddMembers adds userIDs to a SCIM group and syncs their roles.
+func (p *SCIMProvisioner) AddMembers(ctx context.Context, groupID string, userIDs []string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	if err := p.checkMembers(ctx, userIDs); err != nil {
+		return err
+	}
+	if err := p.store.AddSCIMGroupMembers(ctx, groupID, userIDs); err != nil {
+		return fmt.Errorf("auth: adding scim group members: %w", err)
+	}
+	return p.syncUsers(ctx, userIDs)
+}
+
+// RemoveMembers removes userIDs from a SCIM group and syncs their roles.
+func (p *SCIMProvisioner) RemoveMembers(ctx context.Context, groupID string, userIDs []string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	if err := p.store.RemoveSCIMGroupMembers(ctx, groupID, userIDs); err != nil {
+		return fmt.Errorf("auth: removing scim group members: %w", err)
+	}
+	return p.syncUsers(ctx, userIDs)
+}
+
+// DeleteGroup deletes a SCIM group; its members lose the role it gave them.
+func (p *SCIMProvisioner) DeleteGroup(ctx context.Context, groupID string) error {
+	members, err := p.store.ListSCIMGroupMembers(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	if err := p.store.DeleteSCIMGroup(ctx, groupID); err != nil {
+		return fmt.Errorf("auth: deleting scim group %s: %w", groupID, err)
+	}
+	return p.syncUsers(ctx, members)
+}
+
+// deprovision deactivates user: they can't log in, their API tokens and
+// login sessions are revoked and they drop to the user role.
+func (p *SCIMProvisioner) deprovision(ctx context.Context, user *models.User) error {
+	now := p.now().UTC()
+	if err := p.store.SetUserDeactivated(ctx, user.UserID, &now); err != nil {
+		return fmt.Errorf("auth: deactivating scim user %s: %w", user.UserID, err)
+	}
+	user.DeactivatedAt = &now
+	if _, err := p.store.DeactivateAPITokensByUser(ctx, user.UserID); err != nil {
+		return fmt.Errorf("auth: deactivating tokens of scim user %s: %w", user.UserID, err)
+	}
+	if _, err := p.store.RevokeUISessionsByUser(ctx, user.UserID); err != nil {
+		return fmt.Errorf("auth: revoking sessions of scim user %s: %w", user.UserID, err)
+	}
+	return p.setRoles(ctx, user, []string{string(models.UserRoleUser)})
+}
+
+func (p *SCIMProvisioner) syncUsers(ctx context.Context, userIDs []string) error {
+	for _, userID := range userIDs {
+		user, err := p.store.GetUserByID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("auth: loading scim user %s: %w", userID, err)
+		}
+		if err := p.syncRoles(ctx, user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncRoles gives user the roles their groups map to; a deactivated user
+// only keeps user.
+func (p *SCIMProvisioner) syncRoles(ctx context.Context, user *models.User) error {
+	if user.IsDeactivated() {
+		return p.setRoles(ctx, user, []string{string(models.UserRoleUser)})
+	}
+	groups, err := p.store.ListSCIMGroupsByUser(ctx, user.UserID)
+	if err != nil {
+		return fmt.Errorf("auth: listing groups of scim user %s: %w", user.UserID, err)
+	}
+	set := map[string]bool{string(models.UserRoleUser): true}
+	for _, group := range groups {
+		if role, ok := p.groupRoles[strings.ToLower(group.DisplayName)]; ok {
+			set[role] = true
+		}
+	}
+	roles := make([]string, 0, len(set))
+	for role := range set {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return p.setRoles(ctx, user, roles)
+}
+
+// setRoles stores roles on user if they differ.
+func (p *SCIMProvisioner) setRoles(ctx context.Context, user *models.User, roles []string) error {
+	current := append([]string(nil), user.Roles...)
+	sort.Strings(current)
+	if strings.Join(current, ",") == strings.Join(roles, ",") {
+		return nil
+	}
+	if err := p.store.SetUserRoles(ctx, user.UserID, roles); err != nil {
+		return fmt.Errorf("auth: updating roles of scim user %s: %w", user.UserID, err)
+	}
+	user.Roles = roles
+	return nil
+}
+
+// checkMembers returns store.ErrInvalidInput unless every one of userIDs
+// is a SCIM user.
+func (p *SCIMProvisioner) checkMembers(ctx context.Context, userIDs []string) error {
+	for _, userID := range userIDs {
+		if _, err := p.store.GetSCIMUser(ctx, userID); errors.Is(err, store.ErrNotFound) {
+			return fmt.Errorf("%w: %s is not a scim user", store.ErrInvalidInput, userID)
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applySCIMUserInput(link *models.SCIMUser, in SCIMUserInput) {
+	link.UserName = strings.ToLower(strings.TrimSpace(in.UserName))
+	link.ExternalID = in.ExternalID
+	link.DisplayName = in.DisplayName
+	link.GivenName = in.GivenName
+	link.FamilyName = in.FamilyName
+}
@@ -0,0 +1,297 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// --- SCIMStore, on top of ldapFakeStore ------------------------------------
+
+type scimFakeStore struct {
+	*ldapFakeStore
+	scimUsers      map[string]models.SCIMUser
+	groups         map[string]models.SCIMGroup
+	members        map[string]map[string]bool // group ID -> user IDs
+	revokedSession map[string]bool
+}
+
+func newSCIMFakeStore() *scimFakeStore {
+	return &scimFakeStore{
+		ldapFakeStore:  &ldapFakeStore{fakeStore: newFakeStore(), deactivated: map[string]bool{}},
+		scimUsers:      map[string]models.SCIMUser{},
+		groups:         map[string]models.SCIMGroup{},
+		members:        map[string]map[string]bool{},
+		revokedSession: map[string]bool{},
+	}
+}
+
+func (f *scimFakeStore) UpdateUser(_ context.Context, user *models.User) error {
+	f.users[user.UserID] = *user
+	return nil
+}
+
+func (f *scimFakeStore) SetUserDeactivated(_ context.Context, userID string, deactivatedAt *time.Time) error {
+	user, ok := f.users[userID]
+	if !ok {
+		return store.ErrNotFound
+	}
+	user.DeactivatedAt = deactivatedAt
+	f.users[userID] = user
+	return nil
+}
+
+func (f *scimFakeStore) RevokeUISessionsByUser(_ context.Context, userID string) (int64, error) {
+	f.revokedSession[userID] = true
+	return 1, nil
+}
+
+func (f *scimFakeStore) GetSCIMUser(_ context.Context, userID string) (*models.SCIMUser, error) {
+	user, ok := f.scimUsers[userID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &user, nil
+}
+
+func (f *scimFakeStore) GetSCIMUserByUserName(_ context.Context, userName string) (*models.SCIMUser, error) {
+	for _, user := range f.scimUsers {
+		if user.UserName == strings.ToLower(userName) {
+			return &user, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (f *scimFakeStore) ListSCIMUsers(_ context.Context, userName string, limit, offset int) ([]models.SCIMUser, int64, error) {
+	var out []models.SCIMUser
+	for _, user := range f.scimUsers {
+		if userName == "" || user.UserName == strings.ToLower(userName) {
+			out = append(out, user)
+		}
+	}
+	return out, int64(len(out)), nil
+}
+
+func (f *scimFakeStore) CreateSCIMUser(_ context.Context, user *models.SCIMUser) error {
+	f.scimUsers[user.UserID] = *user
+	return nil
+}
+
+func (f *scimFakeStore) UpdateSCIMUser(_ context.Context, user *models.SCIMUser) error {
+	f.scimUsers[user.UserID] = *user
+	return nil
+}
+
+func (f *scimFakeStore) DeleteSCIMUser(_ context.Context, userID string) error {
+	delete(f.scimUsers, userID)
+	for _, members := range f.members {
+		delete(members, userID)
+	}
+	return nil
+}
+
+func (f *scimFakeStore) GetSCIMGroup(_ context.Context, groupID string) (*models.SCIMGroup, error) {
+	group, ok := f.groups[groupID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &group, nil
+}
+
+func (f *scimFakeStore) ListSCIMGroups(_ context.Context, displayName string, limit, offset int) ([]models.SCIMGroup, int64, error) {
+	var out []models.SCIMGroup
+	for _, group := range f.groups {
+		if displayName == "" || strings.EqualFold(group.DisplayName, displayName) {
+			out = append(out, group)
+		}
+	}
+	return out, int64(len(out)), nil
+}
+
+func (f *scimFakeStore) ListSCIMGroupsByUser(_ context.Context, userID string) ([]models.SCIMGroup, error) {
+	var out []models.SCIMGroup
+	for groupID, members := range f.members {
+		if members[userID] {
+			out = append(out, f.groups[groupID])
+		}
+	}
+	return out, nil
+}
+
+func (f *scimFakeStore) CreateSCIMGroup(_ context.Context, group *models.SCIMGroup) error {
+	group.GroupID = f.genID("group")
+	f.groups[group.GroupID] = *group
+	f.members[group.GroupID] = map[string]bool{}
+	return nil
+}
+
+func (f *scimFakeStore) UpdateSCIMGroup(_ context.Context, group *models.SCIMGroup) error {
+	f.groups[group.GroupID] = *group
+	return nil
+}
+
+func (f *scimFakeStore) DeleteSCIMGroup(_ context.Context, groupID string) error {
+	delete(f.groups, groupID)
+	delete(f.members, groupID)
+	return nil
+}
+
+func (f *scimFakeStore) ListSCIMGroupMembers(_ context.Context, groupID string) ([]string, error) {
+	var out []string
+	for userID := range f.members[groupID] {
+		out = append(out, userID)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func (f *scimFakeStore) AddSCIMGroupMembers(_ context.Context, groupID string, userIDs []string) error {
+	for _, userID := range userIDs {
+		f.members[groupID][userID] = true
+	}
+	return nil
+}
+
+func (f *scimFakeStore) RemoveSCIMGroupMembers(_ context.Context, groupID string, userIDs []string) error {
+	for _, userID := range userIDs {
+		delete(f.members[groupID], userID)
+	}
+	return nil
+}
+
+func TestParseSCIMGroupRoles(t *testing.T) {
+	got, err := ParseSCIMGroupRoles(" Reactorcide Admins=admin ; support-team=support;")
+	if err != nil {
+		t.Fatalf("ParseSCIMGroupRoles() error = %v", err)
+	}
+	want := map[string]string{"reactorcide admins": "admin", "support-team": "support"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseSCIMGroupRoles() = %v, want %v", got, want)
+	}
+	for _, bad := range []string{"admins", "=admin", "admins=owner"} {
+		if _, err := ParseSCIMGroupRoles(bad); err == nil {
+			t.Errorf("ParseSCIMGroupRoles(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestSCIMProvisioner(t *testing.T) {
+	ctx := context.Background()
+	fs := newSCIMFakeStore()
+	p := NewSCIMProvisioner(fs, map[string]string{"admins": "admin"})
+	roles := func(userID string) []string {
+		t.Helper()
+		user, err := fs.GetUserByID(ctx, userID)
+		if err != nil {
+			t.Fatalf("GetUserByID() error = %v", err)
+		}
+		return user.Roles
+	}
+
+	link, user, err := p.CreateUser(ctx, SCIMUserInput{UserName: "Alice@Example.com", Email: "alice@example.com", Active: true})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	if link.UserName != "alice@example.com" || user.Username != "alice@example.com" {
+		t.Fatalf("userName not lowercased: link %q, user %q", link.UserName, user.Username)
+	}
+	if _, _, err := p.CreateUser(ctx, SCIMUserInput{UserName: "alice@example.com", Active: true}); !errors.Is(err, store.ErrAlreadyExists) {
+		t.Fatalf("CreateUser() duplicate error = %v, want ErrAlreadyExists", err)
+	}
+
+	group := &models.SCIMGroup{DisplayName: "Admins"}
+	if err := p.CreateGroup(ctx, group, []string{user.UserID}); err != nil {
+		t.Fatalf("CreateGroup() error = %v", err)
+	}
+	if got := roles(user.UserID); !reflect.DeepEqual(got, []string{"admin", "user"}) {
+		t.Fatalf("roles after joining admins = %v, want [admin user]", got)
+	}
+	if err := p.AddMembers(ctx, group.GroupID, []string{"not-a-scim-user"}); !errors.Is(err, store.ErrInvalidInput) {
+		t.Fatalf("AddMembers() of a non-SCIM user error = %v, want ErrInvalidInput", err)
+	}
+
+	// Deprovisioning deactivates the user, their tokens and sessions and
+	// drops their roles.
+	_, user, err = p.ReplaceUser(ctx, user.UserID, SCIMUserInput{UserName: "alice@example.com", Email: "alice@example.com", Active: false})
+	if err != nil {
+		t.Fatalf("ReplaceUser(active=false) error = %v", err)
+	}
+	if !user.IsDeactivated() || !fs.deactivated[user.UserID] || !fs.revokedSession[user.UserID] {
+		t.Fatalf("deprovisioned user: deactivated %v, tokens off %v, sessions revoked %v", user.IsDeactivated(), fs.deactivated[user.UserID], fs.revokedSession[user.UserID])
+	}
+	if got := roles(user.UserID); !reflect.DeepEqual(got, []string{"user"}) {
+		t.Fatalf("roles after deprovisioning = %v, want [user]", got)
+	}
+
+	// Reactivating restores the group's role.
+	if _, user, err = p.ReplaceUser(ctx, user.UserID, SCIMUserInput{UserName: "alice@example.com", Active: true}); err != nil {
+		t.Fatalf("ReplaceUser(active=true) error = %v", err)
+	}
+	if user.IsDeactivated() || !reflect.DeepEqual(roles(user.UserID), []string{"admin", "user"}) {
+		t.Fatalf("reactivated user: deactivated %v, roles %v", user.IsDeactivated(), roles(user.UserID))
+	}
+
+	if err := p.DeleteGroup(ctx, group.GroupID); err != nil {
+		t.Fatalf("DeleteGroup() error = %v", err)
+	}
+	if got := roles(user.UserID); !reflect.DeepEqual(got, []string{"user"}) {
+		t.Fatalf("roles after the group was deleted = %v, want [user]", got)
+	}
+
+	if err := p.DeleteUser(ctx, user.UserID); err != nil {
+		t.Fatalf("DeleteUser() error = %v", err)
+	}
+	if _, ok := fs.scimUsers[user.UserID]; ok {
+		t.Fatal("DeleteUser() kept the SCIM link")
+	}
+	if kept, ok := fs.users[user.UserID]; !ok || !kept.IsDeactivated() {
+		t.Fatal("DeleteUser() must keep the user row, deactivated")
+	}
+}
+
+func TestLoginServiceLinksSCIMUser(t *testing.T) {
+	ctx := context.Background()
+	fs := newSCIMFakeStore()
+	trustDomain(t, fs.fakeStore, "example.com")
+	p := NewSCIMProvisioner(fs, nil)
+	_, provisioned, err := p.CreateUser(ctx, SCIMUserInput{UserName: "alice@example.com", Active: true})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+
+	identity := &VerifiedIdentity{Subject: "alice-subj", Domain: "example.com", Handle: "Alice"}
+	backend := &fakeBackend{mode: ModeLocalRP, beginRedirect: "https://x", beginPending: []byte("p"), completeIdentity: identity}
+	ls := NewLoginService(fs, backend)
+	login := func() (*models.User, error) {
+		t.Helper()
+		started, err := ls.StartLogin(ctx, "alice@example.com", "https://cb")
+		if err != nil {
+			t.Fatalf("StartLogin() error = %v", err)
+		}
+		_, user, err := ls.FinishLogin(ctx, started.AttemptToken, "https://cb?encrypted_token=abc")
+		return user, err
+	}
+
+	user, err := login()
+	if err != nil {
+		t.Fatalf("FinishLogin() error = %v", err)
+	}
+	if user.UserID != provisioned.UserID {
+		t.Fatalf("login landed on user %q, want the SCIM user %q", user.UserID, provisioned.UserID)
+	}
+
+	if _, _, err := p.ReplaceUser(ctx, provisioned.UserID, SCIMUserInput{UserName: "alice@example.com", Active: false}); err != nil {
+		t.Fatalf("ReplaceUser(active=false) error = %v", err)
+	}
+	if _, err := login(); !errors.Is(err, ErrUserDeactivated) {
+		t.Fatalf("FinishLogin() of a deprovisioned user error = %v, want ErrUserDeactivated", err)
+	}
+}
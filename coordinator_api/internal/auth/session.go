@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
 )
@@ -18,9 +19,15 @@ const (
 	// UI_AUTH_PLAN.md's "Sessions" section.
 	SessionTokenBytes = 32
 
-	// SessionExpiry is how long a freshly minted session is valid for.
+	// SessionExpiry is how long a freshly minted session is valid for
+	// unless REACTORCIDE_SESSION_ABSOLUTE_TIMEOUT_HOURS says otherwise.
 	SessionExpiry = 30 * 24 * time.Hour
 
+	// minSessionIdleTimeout floors the idle timeout: last_seen_at is only
+	// written every sessionTouchThrottle, so a shorter one would end
+	// sessions that are in use.
+	minSessionIdleTimeout = 3 * sessionTouchThrottle
+
 	// sessionTouchThrottle bounds how often ResolveSession writes
 	// last_seen_at back to the store: once per 5 minutes of session
 	// activity, so routine polling doesn't hammer ui_sessions.
@@ -45,11 +52,33 @@ type SessionStore interface {
 type Sessions struct {
 	store SessionStore
 	now   func() time.Time
+	// absoluteTimeout is how long a session lives from minting;
+	// idleTimeout ends it early after that long without use (zero: never).
+	absoluteTimeout time.Duration
+	idleTimeout     time.Duration
 }
 
-// NewSessions constructs a Sessions backed by store.
+// NewSessions constructs a Sessions backed by store, with the idle and
+// absolute timeouts from REACTORCIDE_SESSION_IDLE_TIMEOUT_MINUTES and
+// REACTORCIDE_SESSION_ABSOLUTE_TIMEOUT_HOURS.
 func NewSessions(s SessionStore) *Sessions {
-	return &Sessions{store: s, now: time.Now}
+	absolute := time.Duration(config.SessionAbsoluteTimeoutHours) * time.Hour
+	if absolute <= 0 {
+		absolute = SessionExpiry
+	}
+	idle := time.Duration(config.SessionIdleTimeoutMinutes) * time.Minute
+	if idle > 0 && idle < minSessionIdleTimeout {
+		idle = minSessionIdleTimeout
+	}
+	return &Sessions{store: s, now: time.Now, absoluteTimeout: absolute, idleTimeout: idle}
+}
+
+// CSRFToken is the CSRF token that goes with a raw session token. It's
+// derived from the token, so it needs no storage, and can't be computed
+// by a page that can't read the (HttpOnly) session cookie.
+func CSRFToken(sessionToken string) string {
+	sum := sha256.Sum256([]byte("reactorcide-csrf\x00" + sessionToken))
+	return hex.EncodeToString(sum[:])
 }
 
 func hashToken(token string) []byte {
@@ -68,31 +97,37 @@ func generateToken() (string, error) {
 	return hex.EncodeToString(buf), nil
 }
 
-// MintSession creates a new session for userID, valid for SessionExpiry, and
-// returns the raw bearer token. The token is returned exactly once; only its
-// SHA-256 hash is persisted.
+// MintSession creates a new session for userID, valid for the absolute
+// timeout, and returns the raw bearer token. The token is returned exactly
+// once; only its SHA-256 hash is persisted.
 func (s *Sessions) MintSession(ctx context.Context, userID string) (string, error) {
+	token, _, err := s.Mint(ctx, userID)
+	return token, err
+}
+
+// Mint is MintSession, also returning the new session.
+func (s *Sessions) Mint(ctx context.Context, userID string) (string, *models.UISession, error) {
 	token, err := generateToken()
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 	now := s.now()
 	session := &models.UISession{
 		TokenHash:  hashToken(token),
 		UserID:     userID,
 		CreatedAt:  now,
-		ExpiresAt:  now.Add(SessionExpiry),
+		ExpiresAt:  now.Add(s.absoluteTimeout),
 		LastSeenAt: now,
 	}
 	if err := s.store.CreateUISession(ctx, session); err != nil {
-		return "", fmt.Errorf("auth: creating session: %w", err)
+		return "", nil, fmt.Errorf("auth: creating session: %w", err)
 	}
-	return token, nil
+	return token, session, nil
 }
 
 // ResolveSession looks up the active session for a raw bearer token and its
 // owning user. Returns store.ErrNotFound if the token is empty, unknown,
-// expired, or revoked. Lazily (and best-effort) touches last_seen_at,
+// expired, revoked, or idle past the idle timeout (which revokes it). Lazily (and best-effort) touches last_seen_at,
 // throttled to once per sessionTouchThrottle so routine polling doesn't
 // write on every call.
 func (s *Sessions) ResolveSession(ctx context.Context, token string) (*models.User, *models.UISession, error) {
@@ -103,10 +138,19 @@ func (s *Sessions) ResolveSession(ctx context.Context, token string) (*models.Us
 	if err != nil {
 		return nil, nil, err
 	}
+	if s.idleTimeout > 0 && s.now().Sub(session.LastSeenAt) > s.idleTimeout {
+		if err := s.store.RevokeUISession(ctx, session.SessionID); err != nil && !errors.Is(err, store.ErrNotFound) {
+			return nil, nil, fmt.Errorf("auth: revoking idle session: %w", err)
+		}
+		return nil, nil, store.ErrNotFound
+	}
 	user, err := s.store.GetUserByID(ctx, session.UserID)
 	if err != nil {
 		return nil, nil, fmt.Errorf("auth: resolving session user: %w", err)
 	}
+	if user.IsDeactivated() {
+		return nil, nil, store.ErrNotFound
+	}
 
 	if s.now().Sub(session.LastSeenAt) > sessionTouchThrottle {
 		if touchErr := s.store.TouchUISessionLastSeen(ctx, session.SessionID); touchErr == nil {
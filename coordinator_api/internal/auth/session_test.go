@@ -137,3 +137,43 @@ func TestSessionsResolveTouchesLastSeenWhenStale(t *testing.T) {
 		t.Fatalf("expected last_seen_at to advance past the throttle window, got %v", session.LastSeenAt)
 	}
 }
+
+func TestSessionsResolveIdle(t *testing.T) {
+	ctx := context.Background()
+	fs := newFakeStore()
+	must(t, fs.CreateUser(ctx, &models.User{UserID: "user-1", Username: "alice"}))
+
+	sessions := NewSessions(fs)
+	sessions.idleTimeout = time.Hour
+	start := time.Now()
+	sessions.now = func() time.Time { return start }
+	token, session, err := sessions.Mint(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("Mint() error = %v", err)
+	}
+	if want := start.Add(sessions.absoluteTimeout); !session.ExpiresAt.Equal(want) {
+		t.Fatalf("ExpiresAt = %v, want %v", session.ExpiresAt, want)
+	}
+
+	sessions.now = func() time.Time { return start.Add(59 * time.Minute) }
+	if _, _, err := sessions.ResolveSession(ctx, token); err != nil {
+		t.Fatalf("ResolveSession() within the idle timeout error = %v", err)
+	}
+
+	// Idle past the timeout: the session ends, and stays ended.
+	sessions.now = func() time.Time { return start.Add(59*time.Minute + 61*time.Minute) }
+	if _, _, err := sessions.ResolveSession(ctx, token); !errors.Is(err, store.ErrNotFound) {
+		t.Fatalf("ResolveSession() on an idle session error = %v, want store.ErrNotFound", err)
+	}
+	sessions.now = func() time.Time { return start.Add(59*time.Minute + 62*time.Minute) }
+	if _, _, err := sessions.ResolveSession(ctx, token); !errors.Is(err, store.ErrNotFound) {
+		t.Fatalf("ResolveSession() after idle revocation error = %v, want store.ErrNotFound", err)
+	}
+}
+
+func TestCSRFToken(t *testing.T) {
+	a, b := CSRFToken("token-a"), CSRFToken("token-b")
+	if a == b || a != CSRFToken("token-a") || a == "token-a" {
+		t.Fatalf("CSRFToken must be deterministic per token and differ from it: %q %q", a, b)
+	}
+}
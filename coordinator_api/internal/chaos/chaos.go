@@ -0,0 +1,226 @@
+// Package chaos injects faults at a configured rate (Corndogs submit
+// errors, database latency, object store errors, webhook signature
+// failures) so tests of reactorcide itself can exercise its resilience
+// paths: the outboxes, retries, failover and reconciliation. It's off
+// unless REACTORCIDE_CHAOS_ENABLED is set, and must never be enabled in
+// production.
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	pb "github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs/v1alpha1"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/metrics"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+	"gorm.io/gorm"
+)
+
+// ErrInjected is wrapped by every error chaos mode injects.
+var ErrInjected = errors.New("chaos: injected fault")
+
+// Fault is a kind of injected fault, as counted in
+// reactorcide_chaos_faults_injected_total.
+type Fault string
+
+// Faults.
+const (
+	FaultCorndogsSubmit   Fault = "corndogs_submit"
+	FaultDBLatency        Fault = "db_latency"
+	FaultObjectStore      Fault = "object_store"
+	FaultWebhookSignature Fault = "webhook_signature"
+)
+
+// Config sets the rate, from 0 to 1, of each fault.
+type Config struct {
+	// CorndogsSubmitErrorRate is the fraction of Corndogs task submissions
+	// that fail.
+	CorndogsSubmitErrorRate float64
+	// DBLatencyRate is the fraction of database statements delayed by
+	// DBLatency.
+	DBLatencyRate float64
+	DBLatency     time.Duration
+	// ObjectStoreErrorRate is the fraction of primary object store
+	// operations that fail as a 500 would.
+	ObjectStoreErrorRate float64
+	// WebhookSignatureFailureRate is the fraction of webhook signature
+	// checks that fail, whatever the signature.
+	WebhookSignatureFailureRate float64
+	// Seed seeds the fault decisions, for repeatable runs. Zero seeds from
+	// the clock.
+	Seed int64
+}
+
+// ConfigFromEnv returns the chaos configuration from the environment.
+func ConfigFromEnv() Config {
+	return Config{
+		CorndogsSubmitErrorRate:     config.ChaosCorndogsSubmitErrorRate,
+		DBLatencyRate:               config.ChaosDbLatencyRate,
+		DBLatency:                   time.Duration(config.ChaosDbLatencyMillis) * time.Millisecond,
+		ObjectStoreErrorRate:        config.ChaosObjectStoreErrorRate,
+		WebhookSignatureFailureRate: config.ChaosWebhookSignatureFailureRate,
+		Seed:                        int64(config.ChaosSeed),
+	}
+}
+
+// Injector decides which operations fail. A nil *Injector injects
+// nothing.
+type Injector struct {
+	config Config
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// NewInjector returns an Injector for cfg.
+func NewInjector(cfg Config) *Injector {
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &Injector{config: cfg, rand: rand.New(rand.NewSource(seed))}
+}
+
+// inject reports whether to inject fault, with probability rate, counting
+// it if so.
+func (i *Injector) inject(fault Fault, rate float64) bool {
+	if i == nil || rate <= 0 {
+		return false
+	}
+	i.mu.Lock()
+	hit := i.rand.Float64() < rate
+	i.mu.Unlock()
+	if hit {
+		metrics.RecordChaosFault(string(fault))
+		logging.Log.WithField("fault", fault).Debug("Chaos mode injected a fault")
+	}
+	return hit
+}
+
+// active is the installed Injector; nil outside chaos mode.
+var active *Injector
+
+// Install makes inj the process's injector: object stores built from
+// then on fail at its rate, and WrapCorndogs, RegisterDBLatency and
+// WebhookSignatureFails use it. Install it before the stores are set up.
+// nil uninstalls it.
+func Install(inj *Injector) {
+	active = inj
+	if inj == nil || inj.config.ObjectStoreErrorRate <= 0 {
+		objects.SetFaultInjector(nil)
+		return
+	}
+	objects.SetFaultInjector(func(op string) error {
+		if inj.inject(FaultObjectStore, inj.config.ObjectStoreErrorRate) {
+			return fmt.Errorf("%w: object store %s: 500 Internal Server Error", ErrInjected, op)
+		}
+		return nil
+	})
+}
+
+// WebhookSignatureFails reports whether to fail a webhook signature check
+// that would otherwise pass.
+func WebhookSignatureFails() bool {
+	if active == nil {
+		return false
+	}
+	return active.inject(FaultWebhookSignature, active.config.WebhookSignatureFailureRate)
+}
+
+// queueSubmitter is the optional Corndogs capability of submitting to a
+// named queue (see corndogs.Client.SubmitTaskToQueue).
+type queueSubmitter interface {
+	SubmitTaskToQueue(ctx context.Context, queue string, payload *corndogs.TaskPayload, priority int64) (*pb.Task, error)
+}
+
+// WrapCorndogs returns client, failing task submissions at the installed
+// injector's rate. The result can submit to a named queue if client can.
+// Without an injector, client is returned as is.
+func WrapCorndogs(client corndogs.ClientInterface) corndogs.ClientInterface {
+	if active == nil || active.config.CorndogsSubmitErrorRate <= 0 {
+		return client
+	}
+	faulty := &faultyCorndogsClient{ClientInterface: client, inj: active}
+	if _, ok := client.(queueSubmitter); ok {
+		return faultyQueueCorndogsClient{faulty}
+	}
+	return faulty
+}
+
+type faultyCorndogsClient struct {
+	corndogs.ClientInterface
+	inj *Injector
+}
+
+// faultyQueueCorndogsClient is a faultyCorndogsClient over a client that
+// can submit to a named queue.
+type faultyQueueCorndogsClient struct {
+	*faultyCorndogsClient
+}
+
+func (c *faultyCorndogsClient) submitFault() error {
+	if c.inj.inject(FaultCorndogsSubmit, c.inj.config.CorndogsSubmitErrorRate) {
+		return fmt.Errorf("%w: corndogs submit: service unavailable", ErrInjected)
+	}
+	return nil
+}
+
+func (c *faultyCorndogsClient) SubmitTask(ctx context.Context, payload *corndogs.TaskPayload, priority int64) (*pb.Task, error) {
+	if err := c.submitFault(); err != nil {
+		return nil, err
+	}
+	return c.ClientInterface.SubmitTask(ctx, payload, priority)
+}
+
+func (c faultyQueueCorndogsClient) SubmitTaskToQueue(ctx context.Context, queue string, payload *corndogs.TaskPayload, priority int64) (*pb.Task, error) {
+	if err := c.submitFault(); err != nil {
+		return nil, err
+	}
+	return c.ClientInterface.(queueSubmitter).SubmitTaskToQueue(ctx, queue, payload, priority)
+}
+
+// RegisterDBLatency delays db's statements at the installed injector's
+// rate. Without one, it does nothing.
+func RegisterDBLatency(db *gorm.DB) error {
+	if active == nil || active.config.DBLatencyRate <= 0 || active.config.DBLatency <= 0 {
+		return nil
+	}
+	inj := active
+	delay := func(tx *gorm.DB) {
+		if !inj.inject(FaultDBLatency, inj.config.DBLatencyRate) {
+			return
+		}
+		ctx := tx.Statement.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		timer := time.NewTimer(inj.config.DBLatency)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+	}
+	const name = "reactorcide:chaos_latency"
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Create().Before("*").Register(name, delay),
+		cb.Query().Before("*").Register(name, delay),
+		cb.Update().Before("*").Register(name, delay),
+		cb.Delete().Before("*").Register(name, delay),
+		cb.Row().Before("*").Register(name, delay),
+		cb.Raw().Before("*").Register(name, delay),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+)
+
+func install(t *testing.T, cfg Config) {
+	t.Helper()
+	Install(NewInjector(cfg))
+	t.Cleanup(func() { Install(nil) })
+}
+
+func TestInjectorRates(t *testing.T) {
+	inj := NewInjector(Config{Seed: 1})
+	hits := 0
+	for i := 0; i < 1000; i++ {
+		if inj.inject(FaultWebhookSignature, 0.25) {
+			hits++
+		}
+	}
+	if hits < 200 || hits > 300 {
+		t.Errorf("a 0.25 rate injected %d of 1000 faults", hits)
+	}
+
+	var none *Injector
+	if none.inject(FaultWebhookSignature, 1) || inj.inject(FaultWebhookSignature, 0) {
+		t.Error("a nil injector or a zero rate must inject nothing")
+	}
+
+	// The same seed injects the same faults.
+	a, b := NewInjector(Config{Seed: 42}), NewInjector(Config{Seed: 42})
+	for i := 0; i < 100; i++ {
+		if a.inject(FaultDBLatency, 0.5) != b.inject(FaultDBLatency, 0.5) {
+			t.Fatal("seeded injectors diverged")
+		}
+	}
+}
+
+func TestObjectStoreFaults(t *testing.T) {
+	install(t, Config{ObjectStoreErrorRate: 1})
+	store, err := objects.NewObjectStore(objects.ObjectStoreConfig{Type: "memory"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.Put(context.Background(), "logs/job-1/stdout.json", strings.NewReader("[]"), "application/json")
+	if !errors.Is(err, ErrInjected) {
+		t.Errorf("Put error = %v, want an injected fault", err)
+	}
+
+	// Faults hit the primary only, so writes fail over to the secondary.
+	store, err = objects.NewObjectStore(objects.ObjectStoreConfig{
+		Type:              "memory",
+		Secondary:         &objects.ObjectStoreConfig{Type: "memory"},
+		FailoverThreshold: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(context.Background(), "logs/job-1/stdout.json", strings.NewReader("[]"), "application/json"); err != nil {
+		t.Errorf("Put should have failed over, got %v", err)
+	}
+	if exists, err := store.Exists(context.Background(), "logs/job-1/stdout.json"); err != nil || !exists {
+		t.Errorf("Exists = %v, %v; want the object in the secondary", exists, err)
+	}
+
+	Install(nil)
+	store, _ = objects.NewObjectStore(objects.ObjectStoreConfig{Type: "memory"})
+	if err := store.Put(context.Background(), "k", strings.NewReader("v"), ""); err != nil {
+		t.Errorf("Put after uninstalling = %v", err)
+	}
+}
+
+func TestWrapCorndogs(t *testing.T) {
+	mock := corndogs.NewMockClient()
+	if WrapCorndogs(mock) != corndogs.ClientInterface(mock) {
+		t.Error("without chaos mode the client must be returned as is")
+	}
+
+	install(t, Config{CorndogsSubmitErrorRate: 1})
+	client := WrapCorndogs(mock)
+	if _, err := client.SubmitTask(context.Background(), &corndogs.TaskPayload{}, 0); !errors.Is(err, ErrInjected) {
+		t.Errorf("SubmitTask error = %v, want an injected fault", err)
+	}
+	qs, ok := client.(queueSubmitter)
+	if !ok {
+		t.Fatal("the wrapped client lost SubmitTaskToQueue")
+	}
+	if _, err := qs.SubmitTaskToQueue(context.Background(), "q", &corndogs.TaskPayload{}, 0); !errors.Is(err, ErrInjected) {
+		t.Errorf("SubmitTaskToQueue error = %v, want an injected fault", err)
+	}
+	if len(mock.SubmitTaskCalls) != 0 {
+		t.Error("failed submissions must not reach Corndogs")
+	}
+	if _, err := client.GetTaskByID(context.Background(), "task-1"); err != nil {
+		t.Errorf("other calls pass through, got %v", err)
+	}
+}
+
+func TestWebhookSignatureFails(t *testing.T) {
+	if WebhookSignatureFails() {
+		t.Error("without chaos mode signatures never fail")
+	}
+	install(t, Config{WebhookSignatureFailureRate: 1})
+	if !WebhookSignatureFails() {
+		t.Error("a rate of 1 always fails")
+	}
+}
@@ -14,6 +14,8 @@ type contextKey string
 const (
 	UserContextKey     contextKey = "user"
 	VerifiedContextKey contextKey = "verified"
+	APITokenContextKey contextKey = "api_token"
+	SessionContextKey  contextKey = "session"
 )
 
 // GetUserFromContext retrieves the authenticated user from the request context
@@ -42,6 +44,34 @@ func SetVerifiedContext(ctx context.Context, verified bool) context.Context {
 	return context.WithValue(ctx, VerifiedContextKey, verified)
 }
 
+// GetAPITokenFromContext retrieves the API token the request authenticated
+// with, or nil if it didn't use one
+func GetAPITokenFromContext(ctx context.Context) *models.APIToken {
+	if token, ok := ctx.Value(APITokenContextKey).(*models.APIToken); ok {
+		return token
+	}
+	return nil
+}
+
+// SetAPITokenContext adds the API token the request authenticated with to the context
+func SetAPITokenContext(ctx context.Context, token *models.APIToken) context.Context {
+	return context.WithValue(ctx, APITokenContextKey, token)
+}
+
+// GetSessionFromContext retrieves the login session the request
+// authenticated with (by cookie), or nil if it didn't use one
+func GetSessionFromContext(ctx context.Context) *models.UISession {
+	if session, ok := ctx.Value(SessionContextKey).(*models.UISession); ok {
+		return session
+	}
+	return nil
+}
+
+// SetSessionContext adds the login session the request authenticated with to the context
+func SetSessionContext(ctx context.Context, session *models.UISession) context.Context {
+	return context.WithValue(ctx, SessionContextKey, session)
+}
+
 // ValidateAPIToken validates an API token against its stored hash
 func ValidateAPIToken(tokenString string, hash []byte) bool {
 	tokenHash := sha256.Sum256([]byte(tokenString))
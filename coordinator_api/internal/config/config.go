@@ -19,6 +19,15 @@ var (
 	CornDogsBaseURL = env.GetEnvOrDefault("REACTORCIDE_CORNDOGS_BASE_URL", "")
 	CornDogsAPIKey  = env.GetEnvOrDefault("REACTORCIDE_CORNDOGS_API_KEY", "")
 
+	// Corndogs payload encryption. The coordinator seals task payloads to
+	// the X25519 public key at PayloadPublicKeyPath (PEM); workers open
+	// them with the private keys at PayloadPrivateKeyPaths (comma-separated
+	// PEM files, current key first) and, with PayloadEncryptionRequired,
+	// refuse plaintext ones. Unset, payloads travel in plaintext.
+	PayloadPublicKeyPath      = env.GetEnvOrDefault("REACTORCIDE_PAYLOAD_PUBLIC_KEY_PATH", "")
+	PayloadPrivateKeyPaths    = env.GetEnvOrDefault("REACTORCIDE_PAYLOAD_PRIVATE_KEY_PATHS", "")
+	PayloadEncryptionRequired = env.GetEnvAsBoolOrDefault("REACTORCIDE_PAYLOAD_ENCRYPTION_REQUIRED", "false")
+
 	// Default queue settings
 	DefaultQueueName = env.GetEnvOrDefault("REACTORCIDE_DEFAULT_QUEUE_NAME", "reactorcide-jobs")
 	DefaultTimeout   = env.GetEnvAsIntOrDefault("REACTORCIDE_DEFAULT_TIMEOUT", "3600")
@@ -30,12 +39,144 @@ var (
 	// Default user for API token auth
 	DefaultUserID = env.GetEnvOrDefault("REACTORCIDE_DEFAULT_USER_ID", "")
 
+	// Owner of jobs the coordinator starts itself (webhook eval jobs,
+	// downstream triggers), normally a service account. Falls back to
+	// DefaultUserID; see AutomationUserID.
+	WebhookUserID = env.GetEnvOrDefault("REACTORCIDE_WEBHOOK_USER_ID", "")
+
+	// Request body limits (coordinator), in bytes. Larger bodies get a 413.
+	// Webhooks get their own limit since providers send large payloads
+	// (GitHub caps them at 25 MB), as do source uploads (`reactorcide run`).
+	// Zero disables a limit.
+	MaxRequestBodyBytes      = env.GetEnvAsIntOrDefault("REACTORCIDE_MAX_REQUEST_BODY_BYTES", "10485760")
+	MaxWebhookBodyBytes      = env.GetEnvAsIntOrDefault("REACTORCIDE_MAX_WEBHOOK_BODY_BYTES", "26214400")
+	MaxSourceUploadBodyBytes = env.GetEnvAsIntOrDefault("REACTORCIDE_MAX_SOURCE_UPLOAD_BYTES", "104857600")
+
+	// Request timeouts (coordinator), in seconds, by route class. A request
+	// past its timeout has its context cancelled, so the database, Corndogs
+	// and object store calls it's waiting on give up. Webhooks get a short
+	// timeout since providers retry; log and signed object downloads and
+	// source uploads a long one. WebSocket streams have none. Zero disables a timeout.
+	RequestTimeoutSeconds        = env.GetEnvAsIntOrDefault("REACTORCIDE_REQUEST_TIMEOUT_SECONDS", "60")
+	WebhookRequestTimeoutSeconds = env.GetEnvAsIntOrDefault("REACTORCIDE_WEBHOOK_REQUEST_TIMEOUT_SECONDS", "10")
+	LogRequestTimeoutSeconds     = env.GetEnvAsIntOrDefault("REACTORCIDE_LOG_REQUEST_TIMEOUT_SECONDS", "600")
+
+	// Asynchronous webhook processing (coordinator). Deliveries are stored
+	// and acknowledged, then processed by WebhookWorkers workers, each tried
+	// up to WebhookMaxAttempts times with exponential backoff from
+	// WebhookRetryBackoffSeconds. Disabled, webhooks are processed in the
+	// request.
+	WebhookAsync               = env.GetEnvAsBoolOrDefault("REACTORCIDE_WEBHOOK_ASYNC", "true")
+	WebhookWorkers             = env.GetEnvAsIntOrDefault("REACTORCIDE_WEBHOOK_WORKERS", "4")
+	WebhookQueueSize           = env.GetEnvAsIntOrDefault("REACTORCIDE_WEBHOOK_QUEUE_SIZE", "256")
+	WebhookMaxAttempts         = env.GetEnvAsIntOrDefault("REACTORCIDE_WEBHOOK_MAX_ATTEMPTS", "5")
+	WebhookRetryBackoffSeconds = env.GetEnvAsIntOrDefault("REACTORCIDE_WEBHOOK_RETRY_BACKOFF_SECONDS", "30")
+
+	// Webhook load shedding (coordinator). While the async webhook queue is
+	// WebhookShedQueuePercent full, WebhookShedBacklog queued events are
+	// waiting, or the database round trip takes WebhookShedDbLatencyMillis,
+	// webhooks are answered 503 with Retry-After before any work is done.
+	// The backlog and latency are sampled every WebhookShedSampleSeconds.
+	// Zero disables a check.
+	WebhookShedQueuePercent      = env.GetEnvAsIntOrDefault("REACTORCIDE_WEBHOOK_SHED_QUEUE_PERCENT", "90")
+	WebhookShedBacklog           = env.GetEnvAsIntOrDefault("REACTORCIDE_WEBHOOK_SHED_BACKLOG", "1000")
+	WebhookShedDbLatencyMillis   = env.GetEnvAsIntOrDefault("REACTORCIDE_WEBHOOK_SHED_DB_LATENCY_MS", "2000")
+	WebhookShedSampleSeconds     = env.GetEnvAsIntOrDefault("REACTORCIDE_WEBHOOK_SHED_SAMPLE_SECONDS", "5")
+	WebhookShedRetryAfterSeconds = env.GetEnvAsIntOrDefault("REACTORCIDE_WEBHOOK_SHED_RETRY_AFTER_SECONDS", "60")
+
+	// API versioning (coordinator). v1 routes with an /api/v2 successor
+	// carry Deprecation and Link headers, and a Sunset header once
+	// APIV1Sunset (YYYY-MM-DD) names the date they may be removed.
+	APIV1Sunset = env.GetEnvOrDefault("REACTORCIDE_API_V1_SUNSET", "")
+
+	// Database failover (coordinator and worker). With DbStandbyUri set, the
+	// store checks both databases every DbFailoverCheckSeconds and moves
+	// every connection to the standby once it's promoted, fencing off the
+	// primary until restart. With DbPromoteStandby it promotes the standby
+	// itself after DbFailoverThreshold failed checks of the primary.
+	DbStandbyUri           = env.GetEnvOrDefault("REACTORCIDE_DB_STANDBY_URI", "")
+	DbFailoverCheckSeconds = env.GetEnvAsIntOrDefault("REACTORCIDE_DB_FAILOVER_CHECK_SECONDS", "10")
+	DbFailoverThreshold    = env.GetEnvAsIntOrDefault("REACTORCIDE_DB_FAILOVER_THRESHOLD", "3")
+	DbPromoteStandby       = env.GetEnvAsBoolOrDefault("REACTORCIDE_DB_PROMOTE_STANDBY", "false")
+
+	// Database connection pool (coordinator and worker). Zero leaves a
+	// setting unlimited. Keep DbMaxOpenConns under Postgres's
+	// max_connections divided by the number of replicas.
+	DbMaxOpenConns           = env.GetEnvAsIntOrDefault("REACTORCIDE_DB_MAX_OPEN_CONNS", "25")
+	DbMaxIdleConns           = env.GetEnvAsIntOrDefault("REACTORCIDE_DB_MAX_IDLE_CONNS", "10")
+	DbConnMaxLifetimeMinutes = env.GetEnvAsIntOrDefault("REACTORCIDE_DB_CONN_MAX_LIFETIME_MINUTES", "30")
+	DbConnMaxIdleTimeMinutes = env.GetEnvAsIntOrDefault("REACTORCIDE_DB_CONN_MAX_IDLE_TIME_MINUTES", "5")
+
+	// Slow query logging. Queries slower than DbSlowQueryMillis are logged,
+	// parameterized, and counted; DbSlowQueryExplainRate of them (0 to 1)
+	// also get their generic query plan logged. Zero disables either.
+	DbSlowQueryMillis      = env.GetEnvAsIntOrDefault("REACTORCIDE_DB_SLOW_QUERY_MS", "500")
+	DbSlowQueryExplainRate = env.GetEnvAsFloatOrDefault("REACTORCIDE_DB_SLOW_QUERY_EXPLAIN_RATE", "0")
+
 	// Object store configuration
 	ObjectStoreType     = env.GetEnvOrDefault("REACTORCIDE_OBJECT_STORE_TYPE", "filesystem") // s3, gcs, filesystem, memory
 	ObjectStoreBucket   = env.GetEnvOrDefault("REACTORCIDE_OBJECT_STORE_BUCKET", "reactorcide-objects")
 	ObjectStoreBasePath = env.GetEnvOrDefault("REACTORCIDE_OBJECT_STORE_BASE_PATH", "./objects") // for filesystem
 	ObjectStorePrefix   = env.GetEnvOrDefault("REACTORCIDE_OBJECT_STORE_PREFIX", "reactorcide/") // for s3/gcs
 
+	// Secondary object store (coordinator and worker), e.g. a bucket in
+	// another region. With ObjectStoreSecondaryType set, writes fail over
+	// to it after ObjectStoreFailoverThreshold consecutive failures of the
+	// primary, which is retried every ObjectStoreFailoverRetrySeconds;
+	// reads check both stores.
+	ObjectStoreSecondaryType        = env.GetEnvOrDefault("REACTORCIDE_OBJECT_STORE_SECONDARY_TYPE", "")
+	ObjectStoreSecondaryBucket      = env.GetEnvOrDefault("REACTORCIDE_OBJECT_STORE_SECONDARY_BUCKET", "")
+	ObjectStoreSecondaryBasePath    = env.GetEnvOrDefault("REACTORCIDE_OBJECT_STORE_SECONDARY_BASE_PATH", "")
+	ObjectStoreSecondaryPrefix      = env.GetEnvOrDefault("REACTORCIDE_OBJECT_STORE_SECONDARY_PREFIX", "reactorcide/")
+	ObjectStoreSecondaryRegion      = env.GetEnvOrDefault("REACTORCIDE_OBJECT_STORE_SECONDARY_REGION", "")
+	ObjectStoreSecondaryEndpoint    = env.GetEnvOrDefault("REACTORCIDE_OBJECT_STORE_SECONDARY_ENDPOINT", "")
+	ObjectStoreFailoverThreshold    = env.GetEnvAsIntOrDefault("REACTORCIDE_OBJECT_STORE_FAILOVER_THRESHOLD", "3")
+	ObjectStoreFailoverRetrySeconds = env.GetEnvAsIntOrDefault("REACTORCIDE_OBJECT_STORE_FAILOVER_RETRY_SECONDS", "60")
+
+	// Signed download URLs (coordinator). For object stores that can't
+	// presign URLs (filesystem), the coordinator signs its own with
+	// URLSigningKey, which every replica must share; unset, each replica
+	// uses a random key. PublicAPIURL is the coordinator's public base URL
+	// (e.g. https://ci.example.com); unset, the URLs are relative.
+	URLSigningKey = env.GetEnvOrDefault("REACTORCIDE_URL_SIGNING_KEY", "")
+	PublicAPIURL  = env.GetEnvOrDefault("REACTORCIDE_PUBLIC_API_URL", "")
+
+	// Log lifecycle (coordinator). Finished jobs' logs move to cold storage
+	// after LogColdAfterDays and are deleted after LogDeleteAfterDays; zero
+	// disables either. Cold logs move to LogColdStorageClass on S3 when it's
+	// set, else under LogColdPrefix.
+	LogColdAfterDays            = env.GetEnvAsIntOrDefault("REACTORCIDE_LOG_COLD_AFTER_DAYS", "0")
+	LogDeleteAfterDays          = env.GetEnvAsIntOrDefault("REACTORCIDE_LOG_DELETE_AFTER_DAYS", "0")
+	LogColdStorageClass         = env.GetEnvOrDefault("REACTORCIDE_LOG_COLD_STORAGE_CLASS", "")
+	LogColdPrefix               = env.GetEnvOrDefault("REACTORCIDE_LOG_COLD_PREFIX", "cold/")
+	LogLifecycleIntervalMinutes = env.GetEnvAsIntOrDefault("REACTORCIDE_LOG_LIFECYCLE_INTERVAL_MINUTES", "60")
+
+	// Job archive (coordinator). Finished jobs and webhook events older than
+	// JobArchiveAfterDays move to the archive tables; zero disables it.
+	JobArchiveAfterDays       = env.GetEnvAsIntOrDefault("REACTORCIDE_JOB_ARCHIVE_AFTER_DAYS", "0")
+	JobArchiveIntervalMinutes = env.GetEnvAsIntOrDefault("REACTORCIDE_JOB_ARCHIVE_INTERVAL_MINUTES", "60")
+	JobArchiveBatchSize       = env.GetEnvAsIntOrDefault("REACTORCIDE_JOB_ARCHIVE_BATCH_SIZE", "1000")
+
+	// Trash (coordinator). Deleted projects and jobs can be restored for
+	// TrashRetentionDays, then are purged for good; zero keeps them.
+	TrashRetentionDays        = env.GetEnvAsIntOrDefault("REACTORCIDE_TRASH_RETENTION_DAYS", "30")
+	TrashPurgeIntervalMinutes = env.GetEnvAsIntOrDefault("REACTORCIDE_TRASH_PURGE_INTERVAL_MINUTES", "60")
+
+	// Test results (workers and coordinator). Test outcomes from jobs'
+	// JUnit reports are kept TestResultRetentionDays, the window quarantined
+	// tests' pass rates are reported over.
+	TestResultRetentionDays = env.GetEnvAsIntOrDefault("REACTORCIDE_TEST_RESULT_RETENTION_DAYS", "14")
+
+	// Job data exports (coordinator). With JobExportPrefix set, each day's
+	// jobs are exported under it in the object store, in JobExportFormat
+	// (ndjson or csv), JobExportSettleHours after the day ends. Each sweep
+	// fills in the last JobExportBackfillDays days not exported yet.
+	JobExportPrefix          = env.GetEnvOrDefault("REACTORCIDE_JOB_EXPORT_PREFIX", "")
+	JobExportFormat          = env.GetEnvOrDefault("REACTORCIDE_JOB_EXPORT_FORMAT", "ndjson")
+	JobExportSettleHours     = env.GetEnvAsIntOrDefault("REACTORCIDE_JOB_EXPORT_SETTLE_HOURS", "24")
+	JobExportBackfillDays    = env.GetEnvAsIntOrDefault("REACTORCIDE_JOB_EXPORT_BACKFILL_DAYS", "7")
+	JobExportIntervalMinutes = env.GetEnvAsIntOrDefault("REACTORCIDE_JOB_EXPORT_INTERVAL_MINUTES", "60")
+
 	// VCS Integration configuration
 	VCSGitHubToken   = env.GetEnvOrDefault("REACTORCIDE_VCS_GITHUB_TOKEN", "")
 	VCSGitLabToken   = env.GetEnvOrDefault("REACTORCIDE_VCS_GITLAB_TOKEN", "")
@@ -45,9 +186,49 @@ var (
 	VCSEnabled       = env.GetEnvAsBoolOrDefault("REACTORCIDE_VCS_ENABLED", "false")
 	VCSBaseURL       = env.GetEnvOrDefault("REACTORCIDE_VCS_BASE_URL", "https://reactorcide.example.com") // Base URL for status links
 
+	// VCSProviders is a comma-separated list of the VCS providers whose
+	// clients and webhook routes are set up (see vcs.Providers); empty
+	// enables every built-in provider.
+	VCSProviders = env.GetEnvOrDefault("REACTORCIDE_VCS_PROVIDERS", "")
+
+	// Gerrit (coordinator). VCSGerritURL is the Gerrit server's web URL,
+	// from which repositories are cloned and reviews posted. The token is
+	// "username:http-password". Job results are voted on each of
+	// VCSGerritLabels (comma-separated).
+	VCSGerritURL    = env.GetEnvOrDefault("REACTORCIDE_VCS_GERRIT_URL", "")
+	VCSGerritToken  = env.GetEnvOrDefault("REACTORCIDE_VCS_GERRIT_TOKEN", "")
+	VCSGerritSecret = env.GetEnvOrDefault("REACTORCIDE_VCS_GERRIT_SECRET", "")
+	VCSGerritLabels = env.GetEnvOrDefault("REACTORCIDE_VCS_GERRIT_LABELS", "Verified")
+
+	// VCS API rate limiting. Requests rate limited by the provider are
+	// retried up to VCSRetryMax times, waiting for the quota to reset when
+	// that's no more than VCSRateLimitMaxWaitSeconds away. GET responses
+	// are reused for VCSResponseCacheTTLSeconds, then revalidated by ETag.
+	VCSRetryMax                = env.GetEnvAsIntOrDefault("REACTORCIDE_VCS_RETRY_MAX", "3")
+	VCSRateLimitMaxWaitSeconds = env.GetEnvAsIntOrDefault("REACTORCIDE_VCS_RATE_LIMIT_MAX_WAIT_SECONDS", "60")
+	VCSResponseCacheTTLSeconds = env.GetEnvAsIntOrDefault("REACTORCIDE_VCS_RESPONSE_CACHE_TTL_SECONDS", "10")
+
+	// Job commit statuses and PR comments are queued in the status outbox
+	// and delivered by a dispatcher every VCSOutboxIntervalSeconds, retried
+	// with backoff up to VCSOutboxMaxAttempts times. When disabled they're
+	// delivered inline, once.
+	VCSStatusOutbox          = env.GetEnvAsBoolOrDefault("REACTORCIDE_VCS_STATUS_OUTBOX", "true")
+	VCSOutboxIntervalSeconds = env.GetEnvAsIntOrDefault("REACTORCIDE_VCS_OUTBOX_INTERVAL_SECONDS", "5")
+	VCSOutboxMaxAttempts     = env.GetEnvAsIntOrDefault("REACTORCIDE_VCS_OUTBOX_MAX_ATTEMPTS", "15")
+
 	// CI Code Security configuration
 	CiCodeAllowlist = env.GetEnvOrDefault("REACTORCIDE_CI_CODE_ALLOWLIST", "")
 
+	// Source URL policy. Job source and CI source URLs must use an allowed
+	// scheme and may not point at private, loopback or link-local addresses
+	// unless SourceURLAllowPrivateNetworks is set. Host lists are
+	// comma-separated; "*.example.com" matches subdomains. Admins can
+	// override these with the "source_url_policy" global setting.
+	SourceURLAllowedSchemes       = env.GetEnvOrDefault("REACTORCIDE_SOURCE_URL_ALLOWED_SCHEMES", "https,http,ssh,git")
+	SourceURLAllowedHosts         = env.GetEnvOrDefault("REACTORCIDE_SOURCE_URL_ALLOWED_HOSTS", "")
+	SourceURLDeniedHosts          = env.GetEnvOrDefault("REACTORCIDE_SOURCE_URL_DENIED_HOSTS", "")
+	SourceURLAllowPrivateNetworks = env.GetEnvAsBoolOrDefault("REACTORCIDE_SOURCE_URL_ALLOW_PRIVATE_NETWORKS", "false")
+
 	// Default CI code repository for jobs that don't specify one
 	DefaultCiSourceURL = env.GetEnvOrDefault("REACTORCIDE_DEFAULT_CI_SOURCE_URL", "")
 	DefaultCiSourceRef = env.GetEnvOrDefault("REACTORCIDE_DEFAULT_CI_SOURCE_REF", "main")
@@ -64,4 +245,173 @@ var (
 	// UI_AUTH_PLAN.md's "Cancel vs Kill" section. Not used for kill (admin
 	// force-kill skips the grace period entirely).
 	CancelGraceSeconds = env.GetEnvAsIntOrDefault("REACTORCIDE_CANCEL_GRACE_SECONDS", "60")
+
+	// Cost accounting rates, applied by the worker to each job's recorded
+	// resource usage. Rates are snapshotted onto the usage row, so changing
+	// them only affects jobs that finish afterward. All default to zero,
+	// which records usage without pricing it.
+	CostCPUSecondRate      = env.GetEnvAsFloatOrDefault("REACTORCIDE_COST_CPU_SECOND_RATE", "0")
+	CostMemoryGBSecondRate = env.GetEnvAsFloatOrDefault("REACTORCIDE_COST_MEMORY_GB_SECOND_RATE", "0")
+	CostStorageGBRate      = env.GetEnvAsFloatOrDefault("REACTORCIDE_COST_STORAGE_GB_RATE", "0")
+	CostCurrency           = env.GetEnvOrDefault("REACTORCIDE_COST_CURRENCY", "USD")
+	// CostSampleIntervalSeconds is how often the worker samples a running
+	// job container's CPU and memory (Docker runner only).
+	CostSampleIntervalSeconds = env.GetEnvAsIntOrDefault("REACTORCIDE_COST_SAMPLE_INTERVAL_SECONDS", "10")
+	// JobResourceMetrics records the CPU time, peak memory and network bytes
+	// sampled from each job's container on the job and in Prometheus.
+	JobResourceMetrics = env.GetEnvAsBoolOrDefault("REACTORCIDE_JOB_RESOURCE_METRICS", "false")
+
+	// WorkerID names this worker in job records, image pre-pull reports and
+	// jobs' avoid_workers lists. Defaults to the host name (the pod name on
+	// Kubernetes).
+	WorkerID = env.GetEnvOrDefault("REACTORCIDE_WORKER_ID", "")
+
+	// Scheduling affinity (worker). A job with affinity "project" waits up
+	// to AffinityWaitSeconds in the queue for a worker that started a job
+	// of its project within the last AffinityWindowMinutes.
+	AffinityWaitSeconds   = env.GetEnvAsIntOrDefault("REACTORCIDE_AFFINITY_WAIT_SECONDS", "30")
+	AffinityWindowMinutes = env.GetEnvAsIntOrDefault("REACTORCIDE_AFFINITY_WINDOW_MINUTES", "60")
+
+	// Debug on failure (worker, Docker runtime only). Failed jobs that set
+	// debug_on_failure are kept alive for DebugSessionMinutes and reached
+	// through the coordinator via the worker's exec bridge, which listens on
+	// DebugListenAddr and is advertised as DebugAdvertiseURL. Disabled
+	// unless both are set.
+	DebugListenAddr     = env.GetEnvOrDefault("REACTORCIDE_DEBUG_LISTEN_ADDR", "")
+	DebugAdvertiseURL   = env.GetEnvOrDefault("REACTORCIDE_DEBUG_ADVERTISE_URL", "")
+	DebugSessionMinutes = env.GetEnvAsIntOrDefault("REACTORCIDE_DEBUG_SESSION_MINUTES", "30")
+
+	// Shared workspaces (worker). After a job writes its pipeline's shared
+	// workspace, the worker uploads it to the object store for downstream
+	// jobs on other workers, unless SharedWorkspaceUpload is off (all
+	// workers share one workspace volume). Local copies unused for
+	// SharedWorkspaceTTLHours are removed.
+	SharedWorkspaceUpload   = env.GetEnvAsBoolOrDefault("REACTORCIDE_SHARED_WORKSPACE_UPLOAD", "true")
+	SharedWorkspaceTTLHours = env.GetEnvAsIntOrDefault("REACTORCIDE_SHARED_WORKSPACE_TTL_HOURS", "24")
+
+	// Job caches (worker). Caches jobs keep between runs are stored in the
+	// object store, namespaced by project; saving one evicts the project's
+	// least recently saved caches past JobCacheQuotaMB.
+	JobCacheQuotaMB = env.GetEnvAsIntOrDefault("REACTORCIDE_JOB_CACHE_QUOTA_MB", "2048")
+
+	// OCI sources (worker). Jobs with source_type "oci" are pulled from
+	// their registry using the credentials in the docker config.json at
+	// OCISourceAuthPath, if it has an entry for the registry. Registries
+	// in OCISourceInsecureRegistries (comma-separated host[:port]) are
+	// reached over plain HTTP.
+	OCISourceAuthPath           = env.GetEnvOrDefault("REACTORCIDE_OCI_SOURCE_AUTH_PATH", "")
+	OCISourceInsecureRegistries = env.GetEnvOrDefault("REACTORCIDE_OCI_SOURCE_INSECURE_REGISTRIES", "")
+
+	// Git mirror cache (worker). With GitMirrorDir set, the worker keeps
+	// bare mirrors of the repositories its git jobs clone there, fetched
+	// every GitMirrorRefreshSeconds, and jobs clone with --reference to
+	// them. The least recently used are removed to stay under
+	// GitMirrorMaxBytes.
+	GitMirrorDir            = env.GetEnvOrDefault("REACTORCIDE_GIT_MIRROR_DIR", "")
+	GitMirrorMaxBytes       = env.GetEnvAsIntOrDefault("REACTORCIDE_GIT_MIRROR_MAX_BYTES", "10737418240")
+	GitMirrorRefreshSeconds = env.GetEnvAsIntOrDefault("REACTORCIDE_GIT_MIRROR_REFRESH_SECONDS", "300")
+
+	// Chunked log upload (worker). With LogUploadURL set (the coordinator's
+	// base URL), the worker ships job logs through the coordinator's chunk
+	// API, authenticating with LogUploadToken (an admin API token), instead
+	// of writing the object store itself.
+	LogUploadURL   = env.GetEnvOrDefault("REACTORCIDE_LOG_UPLOAD_URL", "")
+	LogUploadToken = env.GetEnvOrDefault("REACTORCIDE_LOG_UPLOAD_TOKEN", "")
+
+	// Log upload mutual TLS (worker). For a coordinator that requires
+	// worker client certificates (REACTORCIDE_WORKER_TLS_PORT), the worker
+	// presents LogUploadClientCertFile and LogUploadClientKeyFile, and
+	// trusts LogUploadCAFile for the coordinator's certificate on top of
+	// the system roots.
+	LogUploadClientCertFile = env.GetEnvOrDefault("REACTORCIDE_LOG_UPLOAD_CLIENT_CERT_FILE", "")
+	LogUploadClientKeyFile  = env.GetEnvOrDefault("REACTORCIDE_LOG_UPLOAD_CLIENT_KEY_FILE", "")
+	LogUploadCAFile         = env.GetEnvOrDefault("REACTORCIDE_LOG_UPLOAD_CA_FILE", "")
+
+	// Dynamic secrets (worker). Mints short-lived AWS and GCP credentials
+	// for the cloud roles a job's project maps, using the worker's own
+	// cloud identity.
+	DynamicSecretsEnabled = env.GetEnvAsBoolOrDefault("REACTORCIDE_DYNAMIC_SECRETS_ENABLED", "true")
+
+	// Master key startup check (coordinator). The coordinator checks its
+	// master keys against the database at startup and logs any problems;
+	// with MasterKeyStrictStartup it refuses to start instead.
+	MasterKeyStrictStartup = env.GetEnvAsBoolOrDefault("REACTORCIDE_MASTER_KEY_STRICT_STARTUP", "false")
+
+	// Secret token TTL (coordinator and worker). Secret values encrypted
+	// more than SecretMaxTokenAgeDays ago are re-encrypted when read, and
+	// the coordinator sweeps secrets and org keys every
+	// SecretReencryptIntervalMinutes to re-encrypt the ones nobody reads.
+	// Zero disables both.
+	SecretMaxTokenAgeDays          = env.GetEnvAsIntOrDefault("REACTORCIDE_SECRET_MAX_TOKEN_AGE_DAYS", "0")
+	SecretReencryptIntervalMinutes = env.GetEnvAsIntOrDefault("REACTORCIDE_SECRET_REENCRYPT_INTERVAL_MINUTES", "360")
+
+	// Stuck job monitor (coordinator). Action is one of off, warn,
+	// kill_retry, kill_fail; projects can override the action and both
+	// thresholds. A zero threshold disables that check.
+	StuckJobAction          = env.GetEnvOrDefault("REACTORCIDE_STUCK_JOB_ACTION", "warn")
+	StuckJobNoLogMinutes    = env.GetEnvAsIntOrDefault("REACTORCIDE_STUCK_JOB_NO_LOG_MINUTES", "30")
+	StuckJobP95Multiplier   = env.GetEnvAsFloatOrDefault("REACTORCIDE_STUCK_JOB_P95_MULTIPLIER", "3")
+	StuckJobMinBaselineRuns = env.GetEnvAsIntOrDefault("REACTORCIDE_STUCK_JOB_MIN_BASELINE_RUNS", "5")
+	StuckJobIntervalSeconds = env.GetEnvAsIntOrDefault("REACTORCIDE_STUCK_JOB_INTERVAL_SECONDS", "60")
+
+	// Job deadlines (coordinator). Queued jobs are boosted linearly from
+	// priority 0 at DeadlineBoostWindowMinutes before their deadline to
+	// DeadlineBoostMaxPriority at it. Zero window or max disables boosting;
+	// zero interval disables the monitor.
+	DeadlineCheckIntervalSeconds = env.GetEnvAsIntOrDefault("REACTORCIDE_DEADLINE_CHECK_INTERVAL_SECONDS", "60")
+	DeadlineBoostWindowMinutes   = env.GetEnvAsIntOrDefault("REACTORCIDE_DEADLINE_BOOST_WINDOW_MINUTES", "60")
+	DeadlineBoostMaxPriority     = env.GetEnvAsIntOrDefault("REACTORCIDE_DEADLINE_BOOST_MAX_PRIORITY", "100")
+
+	// Job boosts (POST /api/v1/jobs/{id}/boost). A boost raises a queued
+	// job to JobBoostPriority, or to a lower priority the caller asks for.
+	// Each user may boost JobBoostsPerUserPerHour jobs an hour; zero turns
+	// the limit off.
+	JobBoostPriority        = env.GetEnvAsIntOrDefault("REACTORCIDE_JOB_BOOST_PRIORITY", "100")
+	JobBoostsPerUserPerHour = env.GetEnvAsIntOrDefault("REACTORCIDE_JOB_BOOSTS_PER_USER_PER_HOUR", "5")
+
+	// JobMaxDeliveryAttempts is how many times workers may claim a job
+	// whose claims keep ending without a result (a lost worker, a panic)
+	// before the next claim deadletters it. Zero turns the deadletter off.
+	JobMaxDeliveryAttempts = env.GetEnvAsIntOrDefault("REACTORCIDE_JOB_MAX_DELIVERY_ATTEMPTS", "5")
+
+	// FreezeSweepIntervalSeconds is how often the coordinator releases
+	// deploy jobs held by freeze windows that have ended. Zero disables
+	// the sweep; jobs are then released only when a window is changed or
+	// deleted.
+	FreezeSweepIntervalSeconds = env.GetEnvAsIntOrDefault("REACTORCIDE_FREEZE_SWEEP_INTERVAL_SECONDS", "60")
+
+	// MergeQueueIntervalSeconds is how often the coordinator advances
+	// projects' native merge queues. Zero disables the merge queue.
+	MergeQueueIntervalSeconds = env.GetEnvAsIntOrDefault("REACTORCIDE_MERGE_QUEUE_INTERVAL_SECONDS", "15")
+
+	// Lifecycle hook commands (API and worker), each a command and its
+	// arguments, run with the event as JSON on stdin (see internal/hooks).
+	// HookTimeoutSeconds bounds each run.
+	HookPreJobCreate     = env.GetEnvOrDefault("REACTORCIDE_HOOK_PRE_JOB_CREATE", "")
+	HookPostStatusChange = env.GetEnvOrDefault("REACTORCIDE_HOOK_POST_STATUS_CHANGE", "")
+	HookPreSecretInject  = env.GetEnvOrDefault("REACTORCIDE_HOOK_PRE_SECRET_INJECT", "")
+	HookTimeoutSeconds   = env.GetEnvAsIntOrDefault("REACTORCIDE_HOOK_TIMEOUT_SECONDS", "10")
+
+	// Chaos mode (API and worker), for testing reactorcide's own
+	// resilience paths; never enable it in production. With ChaosEnabled,
+	// each rate (0 to 1) is the fraction of the matching operations that
+	// fail, and ChaosDbLatencyRate of queries are delayed by
+	// ChaosDbLatencyMillis. ChaosSeed, if nonzero, makes the faults
+	// repeatable. See internal/chaos.
+	ChaosEnabled                     = env.GetEnvAsBoolOrDefault("REACTORCIDE_CHAOS_ENABLED", "false")
+	ChaosCorndogsSubmitErrorRate     = env.GetEnvAsFloatOrDefault("REACTORCIDE_CHAOS_CORNDOGS_SUBMIT_ERROR_RATE", "0")
+	ChaosDbLatencyRate               = env.GetEnvAsFloatOrDefault("REACTORCIDE_CHAOS_DB_LATENCY_RATE", "0")
+	ChaosDbLatencyMillis             = env.GetEnvAsIntOrDefault("REACTORCIDE_CHAOS_DB_LATENCY_MS", "0")
+	ChaosObjectStoreErrorRate        = env.GetEnvAsFloatOrDefault("REACTORCIDE_CHAOS_OBJECT_STORE_ERROR_RATE", "0")
+	ChaosWebhookSignatureFailureRate = env.GetEnvAsFloatOrDefault("REACTORCIDE_CHAOS_WEBHOOK_SIGNATURE_FAILURE_RATE", "0")
+	ChaosSeed                        = env.GetEnvAsIntOrDefault("REACTORCIDE_CHAOS_SEED", "0")
 )
+
+// AutomationUserID is the owner of jobs the coordinator starts itself:
+// WebhookUserID, or DefaultUserID if unset.
+func AutomationUserID() string {
+	if WebhookUserID != "" {
+		return WebhookUserID
+	}
+	return DefaultUserID
+}
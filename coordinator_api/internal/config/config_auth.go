@@ -70,6 +70,17 @@ var (
 	// implementation notes in UI_AUTH_PLAN.md. Required for begin-login to
 	// succeed in local-rp/rp mode; unused in mode none.
 	UICallbackURL = env.GetEnvOrDefault("REACTORCIDE_UI_CALLBACK_URL", "")
+
+	// SessionIdleTimeoutMinutes ends a login session after that long
+	// without use (zero: never); SessionAbsoluteTimeoutHours ends it that
+	// long after login however much it's used. Apply to UI sessions and
+	// the REST API's cookie sessions alike.
+	SessionIdleTimeoutMinutes   = env.GetEnvAsIntOrDefault("REACTORCIDE_SESSION_IDLE_TIMEOUT_MINUTES", "720")
+	SessionAbsoluteTimeoutHours = env.GetEnvAsIntOrDefault("REACTORCIDE_SESSION_ABSOLUTE_TIMEOUT_HOURS", "720")
+
+	// SessionCookieInsecure drops the Secure flag from the REST API's
+	// session cookie. Only for local plaintext-HTTP development.
+	SessionCookieInsecure = env.GetEnvAsBoolOrDefault("REACTORCIDE_SESSION_COOKIE_INSECURE", "false")
 )
 
 // ValidateUIAuthMode checks that REACTORCIDE_UI_AUTH_MODE holds one of the
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/catalystcommunity/app-utils-go/env"
+)
+
+var (
+	// CORSAllowedOrigins is the comma-separated list of origins browsers
+	// may call the API from: "*" for any, or scheme://host[:port], where
+	// the host may hold one "*" wildcard ("https://*.example.com").
+	CORSAllowedOrigins = env.GetEnvOrDefault("REACTORCIDE_CORS_ALLOWED_ORIGINS", "*")
+
+	// CORSAllowedMethods and CORSAllowedHeaders are the comma-separated
+	// methods and request headers cross-origin requests may use.
+	CORSAllowedMethods = env.GetEnvOrDefault("REACTORCIDE_CORS_ALLOWED_METHODS", "GET,POST,PUT,PATCH,DELETE,OPTIONS")
+	CORSAllowedHeaders = env.GetEnvOrDefault("REACTORCIDE_CORS_ALLOWED_HEADERS", "Content-Type,Authorization,X-CSRF-Token")
+
+	// CORSExposedHeaders are response headers scripts may read, on top of
+	// the maintenance, announcement and deprecation headers, which are
+	// always exposed.
+	CORSExposedHeaders = env.GetEnvOrDefault("REACTORCIDE_CORS_EXPOSED_HEADERS", "")
+
+	// CORSAllowCredentials lets cross-origin requests carry the session
+	// cookie. It needs explicit origins: browsers refuse credentials from
+	// a "*" origin, so ValidateCORS rejects the combination.
+	CORSAllowCredentials = env.GetEnvAsBoolOrDefault("REACTORCIDE_CORS_ALLOW_CREDENTIALS", "false")
+
+	// CORSMaxAgeSeconds is how long browsers may cache a preflight. Zero
+	// leaves it to the browser.
+	CORSMaxAgeSeconds = env.GetEnvAsIntOrDefault("REACTORCIDE_CORS_MAX_AGE_SECONDS", "600")
+
+	// CORSRoutes exposes routes to origins other than CORSAllowedOrigins:
+	// semicolon-separated "path prefix=origins" entries, origins being
+	// comma-separated as in CORSAllowedOrigins, or empty to keep the
+	// routes from cross-origin callers altogether, e.g.
+	// "/api/v1/admin/=;/api/v1/jobs=https://dash.example.com". The
+	// longest matching prefix wins.
+	CORSRoutes = env.GetEnvOrDefault("REACTORCIDE_CORS_ROUTES", "")
+)
+
+// CORSRoute is one REACTORCIDE_CORS_ROUTES entry: the routes under Prefix
+// are exposed to Origins, to none if it's empty.
+type CORSRoute struct {
+	Prefix  string
+	Origins []string
+}
+
+// ParseCORSRoutes parses REACTORCIDE_CORS_ROUTES.
+func ParseCORSRoutes(s string) ([]CORSRoute, error) {
+	var routes []CORSRoute
+	seen := map[string]bool{}
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		prefix, origins, ok := strings.Cut(entry, "=")
+		prefix = strings.TrimSpace(prefix)
+		if !ok || !strings.HasPrefix(prefix, "/") {
+			return nil, fmt.Errorf("REACTORCIDE_CORS_ROUTES entry %q must be \"/path/prefix=origins\"", entry)
+		}
+		if seen[prefix] {
+			return nil, fmt.Errorf("REACTORCIDE_CORS_ROUTES lists %s twice", prefix)
+		}
+		seen[prefix] = true
+		routes = append(routes, CORSRoute{Prefix: prefix, Origins: SplitCommaList(origins)})
+	}
+	return routes, nil
+}
+
+// ValidateCORS checks the REACTORCIDE_CORS_* settings: every origin is
+// well formed, REACTORCIDE_CORS_ROUTES parses, and credentials are only
+// allowed with explicit origins. Call this once at startup.
+func ValidateCORS() error {
+	routes, err := ParseCORSRoutes(CORSRoutes)
+	if err != nil {
+		return err
+	}
+	lists := []struct {
+		name    string
+		origins []string
+	}{{"REACTORCIDE_CORS_ALLOWED_ORIGINS", SplitCommaList(CORSAllowedOrigins)}}
+	for _, route := range routes {
+		lists = append(lists, struct {
+			name    string
+			origins []string
+		}{"REACTORCIDE_CORS_ROUTES " + route.Prefix, route.Origins})
+	}
+	for _, list := range lists {
+		for _, origin := range list.origins {
+			if err := validateCORSOrigin(origin); err != nil {
+				return fmt.Errorf("%s: %w", list.name, err)
+			}
+			if origin == "*" && CORSAllowCredentials {
+				return fmt.Errorf("%s: REACTORCIDE_CORS_ALLOW_CREDENTIALS needs explicit origins, not \"*\"", list.name)
+			}
+		}
+	}
+	if CORSMaxAgeSeconds < 0 {
+		return fmt.Errorf("REACTORCIDE_CORS_MAX_AGE_SECONDS must not be negative")
+	}
+	return nil
+}
+
+// validateCORSOrigin checks that origin is "*" or scheme://host[:port]
+// with at most one "*" in the host.
+func validateCORSOrigin(origin string) error {
+	if origin == "*" {
+		return nil
+	}
+	if strings.Count(origin, "*") > 1 {
+		return fmt.Errorf("origin %q may hold only one \"*\"", origin)
+	}
+	u, err := url.Parse(strings.Replace(origin, "*", "wildcard", 1))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+		u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return fmt.Errorf("origin %q must be scheme://host[:port], without a path or trailing slash", origin)
+	}
+	if strings.Contains(origin, "*") && !strings.Contains(u.Host, "wildcard") {
+		return fmt.Errorf("origin %q may only hold \"*\" in its host", origin)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package config
+
+import "testing"
+
+// withCORSConfig saves and restores the CORS config vars a test mutates.
+func withCORSConfig(t *testing.T) func() {
+	t.Helper()
+	origOrigins := CORSAllowedOrigins
+	origCredentials := CORSAllowCredentials
+	origRoutes := CORSRoutes
+	origMaxAge := CORSMaxAgeSeconds
+	return func() {
+		CORSAllowedOrigins = origOrigins
+		CORSAllowCredentials = origCredentials
+		CORSRoutes = origRoutes
+		CORSMaxAgeSeconds = origMaxAge
+	}
+}
+
+func TestValidateCORS(t *testing.T) {
+	defer withCORSConfig(t)()
+
+	tests := []struct {
+		name        string
+		origins     string
+		credentials bool
+		routes      string
+		wantErr     bool
+	}{
+		{name: "default any origin", origins: "*"},
+		{name: "explicit origins", origins: "https://ui.example.com, http://localhost:3000"},
+		{name: "wildcard subdomain", origins: "https://*.example.com"},
+		{name: "credentials with explicit origins", origins: "https://ui.example.com", credentials: true},
+		{name: "credentials with any origin", origins: "*", credentials: true, wantErr: true},
+		{name: "credentials with any origin on a route", origins: "https://ui.example.com", credentials: true, routes: "/api/v1/public/=*", wantErr: true},
+		{name: "origin with a path", origins: "https://ui.example.com/", wantErr: true},
+		{name: "origin without a scheme", origins: "ui.example.com", wantErr: true},
+		{name: "two wildcards", origins: "https://*.*.example.com", wantErr: true},
+		{name: "wildcard scheme", origins: "*://ui.example.com", wantErr: true},
+		{name: "routes", origins: "*", routes: "/api/v1/admin/=; /api/v1/jobs=https://dash.example.com,https://ops.example.com"},
+		{name: "route without a slash", origins: "*", routes: "api/v1/admin=", wantErr: true},
+		{name: "route without origins part", origins: "*", routes: "/api/v1/admin", wantErr: true},
+		{name: "route listed twice", origins: "*", routes: "/api/=*;/api/=", wantErr: true},
+		{name: "bad route origin", origins: "*", routes: "/api/v1/jobs=dash.example.com", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			CORSAllowedOrigins = tt.origins
+			CORSAllowCredentials = tt.credentials
+			CORSRoutes = tt.routes
+			err := ValidateCORS()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateCORS() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseCORSRoutes(t *testing.T) {
+	routes, err := ParseCORSRoutes(" /api/v1/admin/= ; /api/v1/jobs=https://a.example.com, https://b.example.com ;")
+	if err != nil {
+		t.Fatalf("ParseCORSRoutes: %v", err)
+	}
+	if len(routes) != 2 {
+		t.Fatalf("expected 2 routes, got %+v", routes)
+	}
+	if routes[0].Prefix != "/api/v1/admin/" || len(routes[0].Origins) != 0 {
+		t.Errorf("unexpected first route %+v", routes[0])
+	}
+	if routes[1].Prefix != "/api/v1/jobs" || len(routes[1].Origins) != 2 || routes[1].Origins[1] != "https://b.example.com" {
+		t.Errorf("unexpected second route %+v", routes[1])
+	}
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/catalystcommunity/app-utils-go/env"
+)
+
+var (
+	// LDAPURL turns on LDAP/Active Directory authentication for the API:
+	// alongside API tokens, requests may send HTTP Basic credentials,
+	// verified by binding to the directory. ldap://host[:port] or
+	// ldaps://host[:port]; empty (the default) disables LDAP.
+	LDAPURL = env.GetEnvOrDefault("REACTORCIDE_LDAP_URL", "")
+
+	// LDAPStartTLS upgrades an ldap:// connection with StartTLS.
+	LDAPStartTLS = env.GetEnvAsBoolOrDefault("REACTORCIDE_LDAP_START_TLS", "false")
+
+	// LDAPAllowInsecure permits a plaintext ldap:// URL without StartTLS,
+	// which sends users' passwords in the clear. Off by default.
+	LDAPAllowInsecure = env.GetEnvAsBoolOrDefault("REACTORCIDE_LDAP_ALLOW_INSECURE", "false")
+
+	// LDAPBindDN and LDAPBindPassword are the service account the
+	// coordinator binds as to look users up and sync their groups.
+	LDAPBindDN       = env.GetEnvOrDefault("REACTORCIDE_LDAP_BIND_DN", "")
+	LDAPBindPassword = env.GetEnvOrDefault("REACTORCIDE_LDAP_BIND_PASSWORD", "")
+
+	// LDAPUserBaseDN is the subtree users are searched in, by
+	// LDAPUserAttribute ("uid"; "sAMAccountName" for Active Directory).
+	LDAPUserBaseDN    = env.GetEnvOrDefault("REACTORCIDE_LDAP_USER_BASE_DN", "")
+	LDAPUserAttribute = env.GetEnvOrDefault("REACTORCIDE_LDAP_USER_ATTRIBUTE", "uid")
+
+	// LDAPGroupAttribute is the user attribute listing the DNs of their
+	// groups.
+	LDAPGroupAttribute = env.GetEnvOrDefault("REACTORCIDE_LDAP_GROUP_ATTRIBUTE", "memberOf")
+
+	// LDAPGroupRoles maps groups to user roles: semicolon-separated
+	// "group DN=role" entries, split at the last "=", e.g.
+	// "cn=ci-admins,ou=groups,dc=example,dc=com=admin".
+	LDAPGroupRoles = env.GetEnvOrDefault("REACTORCIDE_LDAP_GROUP_ROLES", "")
+
+	// LDAPSyncIntervalMinutes is how often LDAP users' roles are synced
+	// from their groups. Zero disables the sync; roles then only change
+	// when a user authenticates.
+	LDAPSyncIntervalMinutes = env.GetEnvAsIntOrDefault("REACTORCIDE_LDAP_SYNC_INTERVAL_MINUTES", "15")
+
+	// LDAPCacheSeconds is how long a successful LDAP authentication is
+	// remembered, so every request doesn't bind. Zero disables the cache.
+	LDAPCacheSeconds = env.GetEnvAsIntOrDefault("REACTORCIDE_LDAP_CACHE_SECONDS", "60")
+
+	// LDAPTimeoutSeconds bounds connecting to the directory and each
+	// operation.
+	LDAPTimeoutSeconds = env.GetEnvAsIntOrDefault("REACTORCIDE_LDAP_TIMEOUT_SECONDS", "10")
+)
+
+// ValidateLDAP checks that the config LDAP needs is present when
+// REACTORCIDE_LDAP_URL is set.
+func ValidateLDAP() error {
+	if LDAPURL == "" {
+		return nil
+	}
+	if !strings.HasPrefix(LDAPURL, "ldap://") && !strings.HasPrefix(LDAPURL, "ldaps://") {
+		return fmt.Errorf("REACTORCIDE_LDAP_URL must start with ldap:// or ldaps://")
+	}
+	if LDAPInsecure() && !LDAPAllowInsecure {
+		return fmt.Errorf("REACTORCIDE_LDAP_URL is plaintext ldap:// and would send passwords in the clear: use ldaps://, set REACTORCIDE_LDAP_START_TLS, or set REACTORCIDE_LDAP_ALLOW_INSECURE to accept that")
+	}
+	required := []struct{ name, value string }{
+		{"REACTORCIDE_LDAP_BIND_DN", LDAPBindDN},
+		{"REACTORCIDE_LDAP_BIND_PASSWORD", LDAPBindPassword},
+		{"REACTORCIDE_LDAP_USER_BASE_DN", LDAPUserBaseDN},
+		{"REACTORCIDE_LDAP_USER_ATTRIBUTE", LDAPUserAttribute},
+		{"REACTORCIDE_LDAP_GROUP_ATTRIBUTE", LDAPGroupAttribute},
+	}
+	for _, r := range required {
+		if strings.TrimSpace(r.value) == "" {
+			return fmt.Errorf("REACTORCIDE_LDAP_URL requires %s", r.name)
+		}
+	}
+	return nil
+}
+
+// LDAPInsecure reports whether the LDAP connection is plaintext: an
+// ldap:// URL without StartTLS.
+func LDAPInsecure() bool {
+	return strings.HasPrefix(LDAPURL, "ldap://") && !LDAPStartTLS
+}
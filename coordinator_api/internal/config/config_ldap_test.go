@@ -0,0 +1,34 @@
+package config
+
+import "testing"
+
+func TestValidateLDAP_PlaintextURL(t *testing.T) {
+	origURL, origStartTLS, origInsecure := LDAPURL, LDAPStartTLS, LDAPAllowInsecure
+	origBindDN, origBindPassword, origBaseDN := LDAPBindDN, LDAPBindPassword, LDAPUserBaseDN
+	defer func() {
+		LDAPURL, LDAPStartTLS, LDAPAllowInsecure = origURL, origStartTLS, origInsecure
+		LDAPBindDN, LDAPBindPassword, LDAPUserBaseDN = origBindDN, origBindPassword, origBaseDN
+	}()
+	LDAPBindDN, LDAPBindPassword, LDAPUserBaseDN = "cn=svc,dc=example,dc=com", "secret", "ou=people,dc=example,dc=com"
+
+	tests := []struct {
+		name          string
+		url           string
+		startTLS      bool
+		allowInsecure bool
+		wantErr       bool
+	}{
+		{name: "ldaps", url: "ldaps://ldap.example.com"},
+		{name: "ldap with StartTLS", url: "ldap://ldap.example.com", startTLS: true},
+		{name: "plaintext ldap", url: "ldap://ldap.example.com", wantErr: true},
+		{name: "plaintext ldap opted in", url: "ldap://ldap.example.com", allowInsecure: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			LDAPURL, LDAPStartTLS, LDAPAllowInsecure = tt.url, tt.startTLS, tt.allowInsecure
+			if err := ValidateLDAP(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateLDAP() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package config
+
+import "github.com/catalystcommunity/app-utils-go/env"
+
+var (
+	// ScanTrivyImage is the runner image of scan jobs using trivy.
+	ScanTrivyImage = env.GetEnvOrDefault("REACTORCIDE_SCAN_TRIVY_IMAGE", "aquasec/trivy:latest")
+
+	// ScanGrypeImage is the runner image of scan jobs using grype.
+	ScanGrypeImage = env.GetEnvOrDefault("REACTORCIDE_SCAN_GRYPE_IMAGE", "anchore/grype:latest")
+)
@@ -0,0 +1,11 @@
+package config
+
+import "github.com/catalystcommunity/app-utils-go/env"
+
+var (
+	// SCIMGroupRoles maps SCIM groups to user roles: semicolon-separated
+	// "group display name=role" entries, e.g.
+	// "reactorcide-admins=admin;reactorcide-support=support". Members of
+	// no mapped group are plain users.
+	SCIMGroupRoles = env.GetEnvOrDefault("REACTORCIDE_SCIM_GROUP_ROLES", "")
+)
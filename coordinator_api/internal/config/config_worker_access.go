@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/catalystcommunity/app-utils-go/env"
+)
+
+var (
+	// WorkerAllowedCIDRs is the comma-separated list of networks (CIDRs or
+	// single addresses) the worker-facing endpoints, the chunked log
+	// upload API, accept requests from. Empty accepts any. It applies on
+	// top of the worker's API token, so a stolen token is no use outside
+	// these networks.
+	WorkerAllowedCIDRs = env.GetEnvOrDefault("REACTORCIDE_WORKER_ALLOWED_CIDRS", "")
+
+	// WorkerTrustedProxies lists the load balancers and proxies in front
+	// of the coordinator (CIDRs or addresses). Requests from them are
+	// checked against WorkerAllowedCIDRs by the address they forwarded in
+	// X-Forwarded-For; without them the header is ignored.
+	WorkerTrustedProxies = env.GetEnvOrDefault("REACTORCIDE_WORKER_TRUSTED_PROXIES", "")
+
+	// Worker mutual TLS (coordinator). With WorkerTLSPort set, the
+	// coordinator serves the worker-facing endpoints on that port over
+	// TLS with WorkerTLSCertFile and WorkerTLSKeyFile, accepting only
+	// clients with a certificate signed by WorkerTLSClientCAFile, and
+	// refuses them on the main port.
+	WorkerTLSPort         = env.GetEnvAsIntOrDefault("REACTORCIDE_WORKER_TLS_PORT", "0")
+	WorkerTLSCertFile     = env.GetEnvOrDefault("REACTORCIDE_WORKER_TLS_CERT_FILE", "")
+	WorkerTLSKeyFile      = env.GetEnvOrDefault("REACTORCIDE_WORKER_TLS_KEY_FILE", "")
+	WorkerTLSClientCAFile = env.GetEnvOrDefault("REACTORCIDE_WORKER_TLS_CLIENT_CA_FILE", "")
+)
+
+// ParseCIDRList parses a comma-separated list of CIDRs and bare addresses,
+// a bare address standing for itself alone (a /32 or /128).
+func ParseCIDRList(s string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, entry := range SplitCommaList(s) {
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("%q is not an IP address or CIDR", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an IP address or CIDR", entry)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// ValidateWorkerAccess checks the REACTORCIDE_WORKER_* access settings:
+// the network lists parse, and a worker TLS port comes with its
+// certificate, key and client CA. Call this once at startup.
+func ValidateWorkerAccess() error {
+	if _, err := ParseCIDRList(WorkerAllowedCIDRs); err != nil {
+		return fmt.Errorf("REACTORCIDE_WORKER_ALLOWED_CIDRS: %w", err)
+	}
+	if _, err := ParseCIDRList(WorkerTrustedProxies); err != nil {
+		return fmt.Errorf("REACTORCIDE_WORKER_TRUSTED_PROXIES: %w", err)
+	}
+	if WorkerTLSPort < 0 || WorkerTLSPort > 65535 {
+		return fmt.Errorf("REACTORCIDE_WORKER_TLS_PORT must be between 1 and 65535, or 0 to disable")
+	}
+	if WorkerTLSPort == 0 {
+		return nil
+	}
+	if WorkerTLSPort == Port {
+		return fmt.Errorf("REACTORCIDE_WORKER_TLS_PORT must differ from the API port %d", Port)
+	}
+	for name, value := range map[string]string{
+		"REACTORCIDE_WORKER_TLS_CERT_FILE":      WorkerTLSCertFile,
+		"REACTORCIDE_WORKER_TLS_KEY_FILE":       WorkerTLSKeyFile,
+		"REACTORCIDE_WORKER_TLS_CLIENT_CA_FILE": WorkerTLSClientCAFile,
+	} {
+		if value == "" {
+			return fmt.Errorf("%s is required with REACTORCIDE_WORKER_TLS_PORT", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package config
+
+import "testing"
+
+// withWorkerAccessConfig saves and restores the worker access config vars
+// a test mutates.
+func withWorkerAccessConfig(t *testing.T) func() {
+	t.Helper()
+	origCIDRs, origProxies := WorkerAllowedCIDRs, WorkerTrustedProxies
+	origPort, origCert, origKey, origCA := WorkerTLSPort, WorkerTLSCertFile, WorkerTLSKeyFile, WorkerTLSClientCAFile
+	origAPIPort := Port
+	return func() {
+		WorkerAllowedCIDRs, WorkerTrustedProxies = origCIDRs, origProxies
+		WorkerTLSPort, WorkerTLSCertFile, WorkerTLSKeyFile, WorkerTLSClientCAFile = origPort, origCert, origKey, origCA
+		Port = origAPIPort
+	}
+}
+
+func TestValidateWorkerAccess(t *testing.T) {
+	defer withWorkerAccessConfig(t)()
+	Port = 6080
+
+	tests := []struct {
+		name    string
+		cidrs   string
+		proxies string
+		port    int
+		files   bool
+		wantErr bool
+	}{
+		{name: "defaults"},
+		{name: "networks and addresses", cidrs: "10.0.0.0/8, 192.168.1.5, fd00::/8", proxies: "172.16.0.1"},
+		{name: "bad network", cidrs: "10.0.0.0/33", wantErr: true},
+		{name: "bad proxy", proxies: "proxy.internal", wantErr: true},
+		{name: "tls with files", port: 6443, files: true},
+		{name: "tls without files", port: 6443, wantErr: true},
+		{name: "tls on the api port", port: 6080, files: true, wantErr: true},
+		{name: "port out of range", port: 70000, files: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			WorkerAllowedCIDRs, WorkerTrustedProxies, WorkerTLSPort = tt.cidrs, tt.proxies, tt.port
+			WorkerTLSCertFile, WorkerTLSKeyFile, WorkerTLSClientCAFile = "", "", ""
+			if tt.files {
+				WorkerTLSCertFile, WorkerTLSKeyFile, WorkerTLSClientCAFile = "server.crt", "server.key", "workers-ca.crt"
+			}
+			err := ValidateWorkerAccess()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateWorkerAccess() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseCIDRList(t *testing.T) {
+	networks, err := ParseCIDRList("10.1.0.0/16, 192.168.1.5,::1")
+	if err != nil {
+		t.Fatalf("ParseCIDRList: %v", err)
+	}
+	if len(networks) != 3 {
+		t.Fatalf("expected 3 networks, got %v", networks)
+	}
+	if networks[1].String() != "192.168.1.5/32" || networks[2].String() != "::1/128" {
+		t.Errorf("bare addresses should parse as single hosts, got %v and %v", networks[1], networks[2])
+	}
+}
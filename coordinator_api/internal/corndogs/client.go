@@ -24,6 +24,16 @@ type Config struct {
 	Timeout      time.Duration
 	MaxRetries   int
 	RetryBackoff time.Duration
+
+	// OS and Arch are the platform of the worker claiming tasks through
+	// this client: it works the PlatformQueue of QueueName for them. Left
+	// empty (the coordinator, and linux/amd64 workers) it works QueueName.
+	OS   string
+	Arch string
+
+	// PayloadSealer, if set, encrypts every payload submitted, so that
+	// only workers holding the private key can read them.
+	PayloadSealer *PayloadSealer
 }
 
 // NewClient creates a new Corndogs client
@@ -55,6 +65,11 @@ func normalizeBaseURL(baseURL string) string {
 	return "http://" + baseURL
 }
 
+// taskQueue is the queue this client claims and updates tasks in.
+func (c *Client) taskQueue() string {
+	return PlatformQueue(c.config.QueueName, c.config.OS, c.config.Arch)
+}
+
 // Close is retained for the client interface; CSIL-RPC uses per-request HTTP.
 func (c *Client) Close() error {
 	return nil
@@ -69,15 +84,34 @@ type TaskPayload struct {
 	Metadata map[string]interface{} `json:"metadata"`
 }
 
-// SubmitTask submits a new task to Corndogs
+// SubmitTask submits a new task to Corndogs, on the queue for the payload's
+// target platform (see PlatformQueue) under its target queue, or under the
+// client's queue when it has none.
 func (c *Client) SubmitTask(ctx context.Context, payload *TaskPayload, priority int64) (*pb.Task, error) {
+	base := c.config.QueueName
+	if queue := payload.TargetQueue(); queue != "" {
+		base = queue
+	}
+	targetOS, targetArch := payload.TargetPlatform()
+	return c.SubmitTaskToQueue(ctx, PlatformQueue(base, targetOS, targetArch), payload, priority)
+}
+
+// SubmitTaskToQueue submits a new task to Corndogs on the named queue,
+// whatever the payload's target platform. Only the workers of that queue's
+// platform will claim it.
+func (c *Client) SubmitTaskToQueue(ctx context.Context, queue string, payload *TaskPayload, priority int64) (*pb.Task, error) {
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal payload: %w", err)
 	}
+	if c.config.PayloadSealer != nil {
+		if payloadBytes, err = c.config.PayloadSealer.Seal(payloadBytes); err != nil {
+			return nil, err
+		}
+	}
 
 	req := csil.SubmitTaskRequest{
-		Queue:           c.config.QueueName,
+		Queue:           queue,
 		CurrentState:    "submitted",
 		AutoTargetState: "submitted-working",
 		Timeout:         int64(c.config.Timeout.Seconds()),
@@ -100,7 +134,7 @@ func (c *Client) GetNextTask(ctx context.Context, state string, timeout int64) (
 	}
 
 	req := csil.GetNextTaskRequest{
-		Queue:           c.config.QueueName,
+		Queue:           c.taskQueue(),
 		CurrentState:    state,
 		OverrideTimeout: timeout,
 	}
@@ -117,7 +151,7 @@ func (c *Client) GetNextTask(ctx context.Context, state string, timeout int64) (
 func (c *Client) UpdateTask(ctx context.Context, taskID string, currentState string, newState string, payload []byte) (*pb.Task, error) {
 	req := csil.UpdateTaskRequest{
 		Uuid:         taskID,
-		Queue:        c.config.QueueName,
+		Queue:        c.taskQueue(),
 		CurrentState: currentState,
 		NewState:     newState,
 		Payload:      payload,
@@ -135,7 +169,7 @@ func (c *Client) UpdateTask(ctx context.Context, taskID string, currentState str
 func (c *Client) CompleteTask(ctx context.Context, taskID string, currentState string) (*pb.Task, error) {
 	req := csil.CompleteTaskRequest{
 		Uuid:         taskID,
-		Queue:        c.config.QueueName,
+		Queue:        c.taskQueue(),
 		CurrentState: currentState,
 	}
 
@@ -151,7 +185,7 @@ func (c *Client) CompleteTask(ctx context.Context, taskID string, currentState s
 func (c *Client) CancelTask(ctx context.Context, taskID string, currentState string) (*pb.Task, error) {
 	req := csil.CancelTaskRequest{
 		Uuid:         taskID,
-		Queue:        c.config.QueueName,
+		Queue:        c.taskQueue(),
 		CurrentState: currentState,
 	}
 
@@ -167,7 +201,7 @@ func (c *Client) CancelTask(ctx context.Context, taskID string, currentState str
 func (c *Client) GetTaskByID(ctx context.Context, taskID string) (*pb.Task, error) {
 	req := csil.GetTaskStateByIDRequest{
 		Uuid:  taskID,
-		Queue: c.config.QueueName,
+		Queue: c.taskQueue(),
 	}
 
 	resp, err := c.client.GetTaskStateByID(ctx, req)
@@ -182,7 +216,7 @@ func (c *Client) GetTaskByID(ctx context.Context, taskID string) (*pb.Task, erro
 func (c *Client) CleanUpTimedOut(ctx context.Context) (int64, error) {
 	req := csil.CleanUpTimedOutRequest{
 		AtTime: time.Now().Unix(),
-		Queue:  c.config.QueueName,
+		Queue:  c.taskQueue(),
 	}
 
 	resp, err := c.client.CleanUpTimedOut(ctx, req)
@@ -220,7 +254,7 @@ func (c *Client) GetQueueTaskCounts(ctx context.Context) (map[string]int64, int6
 // GetTaskStateCounts gets task counts per state for a queue
 func (c *Client) GetTaskStateCounts(ctx context.Context) (int64, map[string]int64, error) {
 	req := csil.GetTaskStateCountsRequest{
-		Queue: c.config.QueueName,
+		Queue: c.taskQueue(),
 	}
 
 	resp, err := c.client.GetTaskStateCounts(ctx, req)
@@ -238,7 +272,7 @@ func (c *Client) SendHeartbeat(ctx context.Context, taskID string, currentState
 	// We keep the same state and just update the timeout
 	req := csil.UpdateTaskRequest{
 		Uuid:         taskID,
-		Queue:        c.config.QueueName,
+		Queue:        c.taskQueue(),
 		CurrentState: currentState,
 		NewState:     currentState, // Keep same state
 		Timeout:      timeoutExtensionSeconds,
@@ -270,10 +304,22 @@ func toPBTask(task *csil.Task) *pb.Task {
 	}
 }
 
-// ParseTaskPayload parses a task payload into a TaskPayload struct
+// ParseTaskPayload parses a task payload into a TaskPayload struct. An
+// encrypted payload can't be parsed without its key: see
+// OpenTaskPayload.
 func ParseTaskPayload(task *pb.Task) (*TaskPayload, error) {
+	return OpenTaskPayload(task, nil)
+}
+
+// OpenTaskPayload parses a task payload into a TaskPayload struct,
+// decrypting it with opener if it's encrypted.
+func OpenTaskPayload(task *pb.Task, opener *PayloadOpener) (*TaskPayload, error) {
+	data, err := opener.Open(task.Payload)
+	if err != nil {
+		return nil, err
+	}
 	var payload TaskPayload
-	if err := json.Unmarshal(task.Payload, &payload); err != nil {
+	if err := json.Unmarshal(data, &payload); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal task payload: %w", err)
 	}
 	return &payload, nil
@@ -15,15 +15,16 @@ type MockClient struct {
 	mu sync.Mutex
 
 	// Control behavior
-	SubmitTaskFunc      func(ctx context.Context, payload *TaskPayload, priority int64) (*pb.Task, error)
-	GetNextTaskFunc     func(ctx context.Context, state string, timeout int64) (*pb.Task, error)
-	UpdateTaskFunc      func(ctx context.Context, taskID string, currentState string, newState string, payload []byte) (*pb.Task, error)
-	SendHeartbeatFunc   func(ctx context.Context, taskID string, currentState string, timeoutExtensionSeconds int64) (*pb.Task, error)
-	CompleteTaskFunc    func(ctx context.Context, taskID string, currentState string) (*pb.Task, error)
-	CancelTaskFunc      func(ctx context.Context, taskID string, currentState string) (*pb.Task, error)
-	GetTaskByIDFunc     func(ctx context.Context, taskID string) (*pb.Task, error)
-	CleanUpTimedOutFunc func(ctx context.Context) (int64, error)
-	GetQueuesFunc       func(ctx context.Context) ([]string, int64, error)
+	SubmitTaskFunc         func(ctx context.Context, payload *TaskPayload, priority int64) (*pb.Task, error)
+	GetNextTaskFunc        func(ctx context.Context, state string, timeout int64) (*pb.Task, error)
+	UpdateTaskFunc         func(ctx context.Context, taskID string, currentState string, newState string, payload []byte) (*pb.Task, error)
+	SendHeartbeatFunc      func(ctx context.Context, taskID string, currentState string, timeoutExtensionSeconds int64) (*pb.Task, error)
+	CompleteTaskFunc       func(ctx context.Context, taskID string, currentState string) (*pb.Task, error)
+	CancelTaskFunc         func(ctx context.Context, taskID string, currentState string) (*pb.Task, error)
+	GetTaskByIDFunc        func(ctx context.Context, taskID string) (*pb.Task, error)
+	CleanUpTimedOutFunc    func(ctx context.Context) (int64, error)
+	GetQueuesFunc          func(ctx context.Context) ([]string, int64, error)
+	GetQueueTaskCountsFunc func(ctx context.Context) (map[string]int64, int64, error)
 
 	// Track calls for assertions
 	SubmitTaskCalls      []SubmitTaskCall
@@ -40,6 +41,8 @@ type MockClient struct {
 type SubmitTaskCall struct {
 	Payload  *TaskPayload
 	Priority int64
+	// Queue is set by SubmitTaskToQueue calls only.
+	Queue string
 }
 
 type GetNextTaskCall struct {
@@ -55,9 +58,9 @@ type UpdateTaskCall struct {
 }
 
 type SendHeartbeatCall struct {
-	TaskID                   string
-	CurrentState             string
-	TimeoutExtensionSeconds  int64
+	TaskID                  string
+	CurrentState            string
+	TimeoutExtensionSeconds int64
 }
 
 type CompleteTaskCall struct {
@@ -110,6 +113,35 @@ func (m *MockClient) SubmitTask(ctx context.Context, payload *TaskPayload, prior
 	}, nil
 }
 
+// SubmitTaskToQueue mock implementation. It is recorded in SubmitTaskCalls
+// and, like SubmitTask, answered by SubmitTaskFunc when set.
+func (m *MockClient) SubmitTaskToQueue(ctx context.Context, queue string, payload *TaskPayload, priority int64) (*pb.Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.SubmitTaskCalls = append(m.SubmitTaskCalls, SubmitTaskCall{
+		Payload:  payload,
+		Priority: priority,
+		Queue:    queue,
+	})
+
+	if m.SubmitTaskFunc != nil {
+		return m.SubmitTaskFunc(ctx, payload, priority)
+	}
+
+	// Default behavior
+	return &pb.Task{
+		Uuid:            uuid.New().String(),
+		Queue:           queue,
+		CurrentState:    "submitted",
+		AutoTargetState: "submitted-working",
+		SubmitTime:      time.Now().Unix(),
+		UpdateTime:      time.Now().Unix(),
+		Timeout:         3600,
+		Priority:        priority,
+	}, nil
+}
+
 // GetNextTask mock implementation
 func (m *MockClient) GetNextTask(ctx context.Context, state string, timeout int64) (*pb.Task, error) {
 	m.mu.Lock()
@@ -286,6 +318,10 @@ func (m *MockClient) GetQueueTaskCounts(ctx context.Context) (map[string]int64,
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if m.GetQueueTaskCountsFunc != nil {
+		return m.GetQueueTaskCountsFunc(ctx)
+	}
+
 	// Default behavior
 	return map[string]int64{"reactorcide-jobs": 1}, 1, nil
 }
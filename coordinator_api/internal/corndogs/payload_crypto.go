@@ -0,0 +1,191 @@
+package corndogs
+
+import (
+	"crypto/ecdh"
+	"crypto/hpke"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// PayloadEncryption names the scheme of encrypted task payloads: HPKE
+// (RFC 9180) with DHKEM(X25519, HKDF-SHA256), HKDF-SHA256 and AES-256-GCM.
+// Each payload is sealed under a fresh key, which is itself sealed to the
+// workers' public key, so only a holder of the private key can read it.
+const PayloadEncryption = "hpke-x25519-sha256-aes256gcm"
+
+// payloadInfo binds sealed payloads to their use.
+var payloadInfo = []byte("reactorcide task payload")
+
+// ErrPayloadEncrypted is returned when parsing an encrypted task payload
+// without a PayloadOpener holding its key.
+var ErrPayloadEncrypted = errors.New("task payload is encrypted")
+
+// ErrPayloadNotEncrypted is returned by a PayloadOpener that requires
+// encryption when it is given a plaintext task payload.
+var ErrPayloadNotEncrypted = errors.New("task payload is not encrypted")
+
+// sealedPayload is what Corndogs carries in place of an encrypted
+// TaskPayload.
+type sealedPayload struct {
+	Encryption string `json:"encryption"`
+	KeyID      string `json:"key_id"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// PayloadSealer encrypts task payloads to the workers' public key. A
+// Client with one submits only encrypted payloads.
+type PayloadSealer struct {
+	keyID string
+	key   hpke.PublicKey
+}
+
+// NewPayloadSealer returns a sealer for the X25519 public key in pemData,
+// a PEM "PUBLIC KEY" block such as `openssl pkey -pubout` writes.
+func NewPayloadSealer(pemData []byte) (*PayloadSealer, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, errors.New("payload public key: no PEM block found")
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("payload public key: %w", err)
+	}
+	pub, ok := parsed.(*ecdh.PublicKey)
+	if !ok || pub.Curve() != ecdh.X25519() {
+		return nil, errors.New("payload public key: not an X25519 key")
+	}
+	return newPayloadSealer(pub)
+}
+
+func newPayloadSealer(pub *ecdh.PublicKey) (*PayloadSealer, error) {
+	key, err := hpke.NewDHKEMPublicKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("payload public key: %w", err)
+	}
+	return &PayloadSealer{keyID: payloadKeyID(pub), key: key}, nil
+}
+
+// LoadPayloadSealer reads NewPayloadSealer's key from path.
+func LoadPayloadSealer(path string) (*PayloadSealer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read payload public key: %w", err)
+	}
+	return NewPayloadSealer(data)
+}
+
+// KeyID identifies the sealer's key in the payloads it seals.
+func (s *PayloadSealer) KeyID() string {
+	return s.keyID
+}
+
+// Seal encrypts a marshalled TaskPayload.
+func (s *PayloadSealer) Seal(payload []byte) ([]byte, error) {
+	ciphertext, err := hpke.Seal(s.key, hpke.HKDFSHA256(), hpke.AES256GCM(), payloadInfo, payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt payload: %w", err)
+	}
+	return json.Marshal(sealedPayload{Encryption: PayloadEncryption, KeyID: s.keyID, Ciphertext: ciphertext})
+}
+
+// PayloadOpener decrypts task payloads sealed to any of its private keys.
+type PayloadOpener struct {
+	keys    map[string]hpke.PrivateKey
+	sealer  *PayloadSealer
+	require bool
+}
+
+// NewPayloadOpener returns an opener for the X25519 private keys in
+// pemData, each a PEM "PRIVATE KEY" block such as `openssl genpkey
+// -algorithm X25519` writes. The first is the current key; the others are
+// kept to read payloads queued before a key rotation. If require is set,
+// plaintext payloads are refused.
+func NewPayloadOpener(require bool, pemData ...[]byte) (*PayloadOpener, error) {
+	if len(pemData) == 0 {
+		return nil, errors.New("payload private key: none given")
+	}
+	o := &PayloadOpener{keys: make(map[string]hpke.PrivateKey), require: require}
+	for i, data := range pemData {
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("payload private key %d: no PEM block found", i)
+		}
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("payload private key %d: %w", i, err)
+		}
+		priv, ok := parsed.(*ecdh.PrivateKey)
+		if !ok || priv.Curve() != ecdh.X25519() {
+			return nil, fmt.Errorf("payload private key %d: not an X25519 key", i)
+		}
+		key, err := hpke.NewDHKEMPrivateKey(priv)
+		if err != nil {
+			return nil, fmt.Errorf("payload private key %d: %w", i, err)
+		}
+		o.keys[payloadKeyID(priv.PublicKey())] = key
+		if i == 0 {
+			if o.sealer, err = newPayloadSealer(priv.PublicKey()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return o, nil
+}
+
+// LoadPayloadOpener reads NewPayloadOpener's keys from paths.
+func LoadPayloadOpener(require bool, paths ...string) (*PayloadOpener, error) {
+	pemData := make([][]byte, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read payload private key: %w", err)
+		}
+		pemData = append(pemData, data)
+	}
+	return NewPayloadOpener(require, pemData...)
+}
+
+// Sealer seals payloads to the opener's current key, for a worker's own
+// submissions (triggered jobs, retries).
+func (o *PayloadOpener) Sealer() *PayloadSealer {
+	return o.sealer
+}
+
+// Open returns the marshalled TaskPayload in data, decrypting it if it's
+// sealed. A nil opener can only open plaintext payloads.
+func (o *PayloadOpener) Open(data []byte) ([]byte, error) {
+	var sealed sealedPayload
+	if err := json.Unmarshal(data, &sealed); err != nil || sealed.Encryption == "" {
+		if o != nil && o.require {
+			return nil, ErrPayloadNotEncrypted
+		}
+		return data, nil
+	}
+	if o == nil {
+		return nil, ErrPayloadEncrypted
+	}
+	if sealed.Encryption != PayloadEncryption {
+		return nil, fmt.Errorf("unsupported payload encryption %q", sealed.Encryption)
+	}
+	key, ok := o.keys[sealed.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("%w with unknown key %q", ErrPayloadEncrypted, sealed.KeyID)
+	}
+	payload, err := hpke.Open(key, hpke.HKDFSHA256(), hpke.AES256GCM(), payloadInfo, sealed.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt payload: %w", err)
+	}
+	return payload, nil
+}
+
+// payloadKeyID is the first 8 bytes of the SHA-256 of pub, in hex.
+func payloadKeyID(pub *ecdh.PublicKey) string {
+	sum := sha256.Sum256(pub.Bytes())
+	return hex.EncodeToString(sum[:8])
+}
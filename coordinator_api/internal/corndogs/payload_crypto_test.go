@@ -0,0 +1,115 @@
+package corndogs
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+
+	pb "github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPayloadKey returns a fresh X25519 key pair as PEM, the way openssl
+// writes them.
+func testPayloadKey(t *testing.T) (privatePEM, publicPEM []byte) {
+	t.Helper()
+	priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	pubDER, err := x509.MarshalPKIXPublicKey(priv.PublicKey())
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})
+}
+
+func TestPayloadEncryption_RoundTrip(t *testing.T) {
+	oldPriv, oldPub := testPayloadKey(t)
+	newPriv, newPub := testPayloadKey(t)
+
+	payload := TaskPayload{
+		JobID:   "job-1",
+		JobType: "run",
+		Config:  map[string]interface{}{"environment": map[string]interface{}{"TOKEN": "s3cr3t-value"}},
+	}
+	plaintext, err := json.Marshal(payload)
+	require.NoError(t, err)
+
+	oldSealer, err := NewPayloadSealer(oldPub)
+	require.NoError(t, err)
+	sealed, err := oldSealer.Seal(plaintext)
+	require.NoError(t, err)
+	assert.NotContains(t, string(sealed), "s3cr3t-value")
+	assert.NotContains(t, string(sealed), "job-1")
+
+	t.Run("without the key", func(t *testing.T) {
+		_, err := ParseTaskPayload(&pb.Task{Payload: sealed})
+		assert.True(t, errors.Is(err, ErrPayloadEncrypted), "got %v", err)
+	})
+
+	t.Run("with a rotated-out key", func(t *testing.T) {
+		opener, err := NewPayloadOpener(true, newPriv, oldPriv)
+		require.NoError(t, err)
+		parsed, err := OpenTaskPayload(&pb.Task{Payload: sealed}, opener)
+		require.NoError(t, err)
+		assert.Equal(t, "job-1", parsed.JobID)
+		assert.Equal(t, "s3cr3t-value", parsed.Config["environment"].(map[string]interface{})["TOKEN"])
+
+		// The worker's own submissions use its current key.
+		newSealer, err := NewPayloadSealer(newPub)
+		require.NoError(t, err)
+		assert.Equal(t, newSealer.KeyID(), opener.Sealer().KeyID())
+	})
+
+	t.Run("with another key", func(t *testing.T) {
+		opener, err := NewPayloadOpener(false, newPriv)
+		require.NoError(t, err)
+		_, err = OpenTaskPayload(&pb.Task{Payload: sealed}, opener)
+		require.Error(t, err)
+		assert.True(t, strings.Contains(err.Error(), oldSealer.KeyID()), "got %v", err)
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		var envelope sealedPayload
+		require.NoError(t, json.Unmarshal(sealed, &envelope))
+		envelope.Ciphertext[len(envelope.Ciphertext)-1] ^= 1
+		tampered, err := json.Marshal(envelope)
+		require.NoError(t, err)
+		opener, err := NewPayloadOpener(false, oldPriv)
+		require.NoError(t, err)
+		_, err = OpenTaskPayload(&pb.Task{Payload: tampered}, opener)
+		assert.Error(t, err)
+	})
+
+	t.Run("plaintext", func(t *testing.T) {
+		lenient, err := NewPayloadOpener(false, newPriv)
+		require.NoError(t, err)
+		parsed, err := OpenTaskPayload(&pb.Task{Payload: plaintext}, lenient)
+		require.NoError(t, err)
+		assert.Equal(t, "job-1", parsed.JobID)
+
+		strict, err := NewPayloadOpener(true, newPriv)
+		require.NoError(t, err)
+		_, err = OpenTaskPayload(&pb.Task{Payload: plaintext}, strict)
+		assert.ErrorIs(t, err, ErrPayloadNotEncrypted)
+	})
+}
+
+func TestPayloadEncryption_RejectsBadKeys(t *testing.T) {
+	priv, pub := testPayloadKey(t)
+
+	_, err := NewPayloadSealer([]byte("not a key"))
+	assert.Error(t, err)
+	_, err = NewPayloadSealer(priv)
+	assert.Error(t, err, "a private key isn't a public key")
+	_, err = NewPayloadOpener(false, pub)
+	assert.Error(t, err, "a public key isn't a private key")
+	_, err = NewPayloadOpener(false)
+	assert.Error(t, err)
+}
@@ -0,0 +1,68 @@
+package corndogs
+
+// Task payload config keys carrying the job's target platform (see
+// TaskPayload.SetTargetPlatform) and queue (see TaskPayload.SetTargetQueue).
+const (
+	TargetOSKey    = "target_os"
+	TargetArchKey  = "target_arch"
+	TargetQueueKey = "target_queue"
+)
+
+// PlatformQueue returns the Corndogs queue for jobs targeting os/arch. Jobs
+// with no target and linux/amd64 jobs share base; every other platform gets
+// its own queue, "<base>-<os>-<arch>", claimed only by workers of that
+// platform. An empty arch means amd64.
+func PlatformQueue(base, os, arch string) string {
+	if arch == "" {
+		arch = "amd64"
+	}
+	if os == "" || (os == "linux" && arch == "amd64") {
+		return base
+	}
+	return base + "-" + os + "-" + arch
+}
+
+// SetTargetPlatform records the job's target platform in the payload, which
+// SubmitTask routes on. Empty values are left out.
+func (p *TaskPayload) SetTargetPlatform(os, arch string) {
+	if p.Config == nil {
+		p.Config = map[string]interface{}{}
+	}
+	if os != "" {
+		p.Config[TargetOSKey] = os
+	}
+	if arch != "" {
+		p.Config[TargetArchKey] = arch
+	}
+}
+
+// TargetPlatform returns the target platform SetTargetPlatform recorded.
+func (p *TaskPayload) TargetPlatform() (os, arch string) {
+	if p == nil || p.Config == nil {
+		return "", ""
+	}
+	os, _ = p.Config[TargetOSKey].(string)
+	arch, _ = p.Config[TargetArchKey].(string)
+	return os, arch
+}
+
+// SetTargetQueue records the base queue the job was routed to, which
+// SubmitTask uses in place of the client's own. Empty is left out.
+func (p *TaskPayload) SetTargetQueue(queue string) {
+	if queue == "" {
+		return
+	}
+	if p.Config == nil {
+		p.Config = map[string]interface{}{}
+	}
+	p.Config[TargetQueueKey] = queue
+}
+
+// TargetQueue returns the base queue SetTargetQueue recorded, or "".
+func (p *TaskPayload) TargetQueue() string {
+	if p == nil || p.Config == nil {
+		return ""
+	}
+	queue, _ := p.Config[TargetQueueKey].(string)
+	return queue
+}
@@ -0,0 +1,277 @@
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/secrets"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
+	"github.com/pressly/goose/v3"
+	"gorm.io/gorm"
+)
+
+// Names of the checks Checks returns.
+const (
+	CheckDatabase      = "database"
+	CheckMigrations    = "migrations"
+	CheckMasterKeys    = "master_keys"
+	CheckCorndogs      = "corndogs"
+	CheckObjectStore   = "object_store"
+	CheckWebhookSecret = "webhook_secret"
+	CheckClockSkew     = "clock_skew"
+)
+
+// DefaultMaxClockSkew is how far the coordinator's clock may drift from the
+// database's before the clock skew check warns; twice that fails it.
+const DefaultMaxClockSkew = 5 * time.Second
+
+// objectStoreProbePrefix is where the object store check writes its probe
+// objects. Each is deleted once read back.
+const objectStoreProbePrefix = "doctor/"
+
+// Deps is what the checks examine. A nil DB fails the database check and
+// skips the ones that need it.
+type Deps struct {
+	DB *gorm.DB
+	// ExpectedMigrationVersion is the latest migration this build ships;
+	// zero skips the migrations check.
+	ExpectedMigrationVersion int64
+	// KeyManager is nil when no master keys could be loaded.
+	KeyManager *secrets.MasterKeyManager
+	// Corndogs is nil when REACTORCIDE_CORNDOGS_BASE_URL isn't set or the
+	// client failed to start.
+	Corndogs    corndogs.ClientInterface
+	ObjectStore objects.ObjectStore
+	// Providers are the enabled VCS providers; FallbackWebhookSecret is
+	// REACTORCIDE_VCS_WEBHOOK_SECRET.
+	Providers             []vcs.ProviderSpec
+	FallbackWebhookSecret string
+	MaxClockSkew          time.Duration
+}
+
+// Checks returns the full battery of checks on deps, in the order they
+// are best read: the database first, since most others need it.
+func Checks(deps Deps) []Check {
+	dbNow := func(ctx context.Context) (time.Time, error) { return databaseNow(ctx, deps.DB) }
+	return []Check{
+		{Name: CheckDatabase, Run: func(ctx context.Context) Result { return checkDatabase(ctx, deps.DB) }},
+		{Name: CheckMigrations, Run: func(ctx context.Context) Result {
+			if deps.DB == nil {
+				return Skip("needs the database")
+			}
+			return checkMigrations(ctx, func(ctx context.Context) (int64, error) {
+				return databaseMigrationVersion(ctx, deps.DB)
+			}, deps.ExpectedMigrationVersion)
+		}},
+		{Name: CheckMasterKeys, Run: func(ctx context.Context) Result {
+			if deps.DB == nil {
+				return Skip("needs the database")
+			}
+			if deps.KeyManager == nil {
+				return checkMasterKeys(nil, nil)
+			}
+			health, err := deps.KeyManager.CheckHealth(deps.DB.WithContext(ctx))
+			return checkMasterKeys(health, err)
+		}},
+		{Name: CheckCorndogs, Run: func(ctx context.Context) Result { return checkCorndogs(ctx, deps.Corndogs) }},
+		{Name: CheckObjectStore, Run: func(ctx context.Context) Result { return checkObjectStore(ctx, deps.ObjectStore) }},
+		{Name: CheckWebhookSecret, Run: func(ctx context.Context) Result {
+			return checkWebhookSecret(deps.Providers, deps.FallbackWebhookSecret)
+		}},
+		{Name: CheckClockSkew, Run: func(ctx context.Context) Result {
+			if deps.DB == nil {
+				return Skip("needs the database")
+			}
+			return checkClockSkew(ctx, dbNow, deps.MaxClockSkew)
+		}},
+	}
+}
+
+func checkDatabase(ctx context.Context, db *gorm.DB) Result {
+	const remediation = "Check REACTORCIDE_DB_URI: the host must be reachable from the coordinator, and the user must be able to log in to the database."
+	if db == nil {
+		return Fail("not connected to the database", remediation)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return Fail(fmt.Sprintf("database connection unavailable: %v", err), remediation)
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return Fail(fmt.Sprintf("database ping failed: %v", err), remediation)
+	}
+	return Pass("connected")
+}
+
+// databaseMigrationVersion returns the latest migration applied to db, 0
+// if it has never been migrated.
+func databaseMigrationVersion(ctx context.Context, db *gorm.DB) (int64, error) {
+	var table *string
+	if err := db.WithContext(ctx).Raw("SELECT to_regclass('goose_db_version')::text").Scan(&table).Error; err != nil {
+		return 0, err
+	}
+	if table == nil {
+		return 0, nil
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return 0, err
+	}
+	return goose.GetDBVersionContext(ctx, sqlDB)
+}
+
+func checkMigrations(ctx context.Context, current func(context.Context) (int64, error), expected int64) Result {
+	if expected == 0 {
+		return Skip("the expected migration version is unknown")
+	}
+	version, err := current(ctx)
+	if err != nil {
+		return Fail(fmt.Sprintf("failed to read the migration version: %v", err),
+			"Check that the database user can read the goose_db_version table.")
+	}
+	switch {
+	case version == expected:
+		return Pass(fmt.Sprintf("at version %d", version))
+	case version < expected:
+		return Fail(fmt.Sprintf("at version %d, this build expects %d", version, expected),
+			"Run `reactorcide migrate` with the same REACTORCIDE_DB_URI; `reactorcide serve` also migrates on startup.")
+	default:
+		return Warn(fmt.Sprintf("at version %d, newer than this build's %d", version, expected),
+			"A newer coordinator migrated this database. Upgrade this coordinator to match.")
+	}
+}
+
+func checkMasterKeys(health *secrets.MasterKeyHealth, err error) Result {
+	if err != nil {
+		return Fail(fmt.Sprintf("failed to check master keys: %v", err),
+			"Check that the database user can read the master_keys and org_encryption_keys tables.")
+	}
+	if health == nil {
+		return Fail("no master keys are loaded, so secrets are unavailable",
+			"Set REACTORCIDE_MASTER_KEYS, or start the coordinator once against this database to generate keys.")
+	}
+	if !health.Healthy {
+		return Fail(strings.Join(health.Problems, "; "),
+			"Make REACTORCIDE_MASTER_KEYS hold every active master key, with the values they were registered with. GET /api/v1/admin/secrets/health has the details.")
+	}
+	return Pass(fmt.Sprintf("%d master keys decrypt their canaries", len(health.Keys)))
+}
+
+func checkCorndogs(ctx context.Context, client corndogs.ClientInterface) Result {
+	if client == nil {
+		return Fail("Corndogs is not configured or its client failed to start, so jobs are never queued",
+			"Set REACTORCIDE_CORNDOGS_BASE_URL to the Corndogs gRPC address, and check the coordinator's startup log for client errors.")
+	}
+	queues, _, err := client.GetQueues(ctx)
+	if err != nil {
+		return Fail(fmt.Sprintf("Corndogs round trip failed: %v", err),
+			"Check that Corndogs is running and reachable at REACTORCIDE_CORNDOGS_BASE_URL.")
+	}
+	return Pass(fmt.Sprintf("reachable, %d queues", len(queues)))
+}
+
+func checkObjectStore(ctx context.Context, store objects.ObjectStore) Result {
+	const remediation = "Check REACTORCIDE_OBJECT_STORE_TYPE and its settings: the bucket or base path must exist, and the coordinator's credentials must be allowed to read, write and delete in it."
+	if store == nil {
+		return Fail("no object store is configured, so logs and artifacts can't be stored", remediation)
+	}
+	nonce := make([]byte, 8)
+	if _, err := rand.Read(nonce); err != nil {
+		return Fail(fmt.Sprintf("failed to make a probe key: %v", err), remediation)
+	}
+	key := objectStoreProbePrefix + hex.EncodeToString(nonce)
+	payload := []byte("reactorcide doctor " + key)
+
+	if err := store.Put(ctx, key, bytes.NewReader(payload), "text/plain"); err != nil {
+		return Fail(fmt.Sprintf("write failed: %v", err), remediation)
+	}
+	deleted := false
+	defer func() {
+		if !deleted {
+			store.Delete(context.WithoutCancel(ctx), key)
+		}
+	}()
+	reader, err := store.Get(ctx, key)
+	if err != nil {
+		return Fail(fmt.Sprintf("read failed: %v", err), remediation)
+	}
+	got, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		return Fail(fmt.Sprintf("read failed: %v", err), remediation)
+	}
+	if !bytes.Equal(got, payload) {
+		return Fail("read back different bytes than were written", remediation)
+	}
+	deleted = true
+	if err := store.Delete(ctx, key); err != nil {
+		return Warn(fmt.Sprintf("delete failed: %v", err),
+			"Allow the coordinator's credentials to delete objects, or the log lifecycle and trash purge can't clean up.")
+	}
+	return Pass("write, read and delete succeeded")
+}
+
+func checkWebhookSecret(providers []vcs.ProviderSpec, fallback string) Result {
+	if len(providers) == 0 {
+		return Skip("no VCS providers are enabled")
+	}
+	var with, without []string
+	for _, spec := range providers {
+		if (spec.WebhookSecret != nil && spec.WebhookSecret() != "") || fallback != "" {
+			with = append(with, string(spec.Provider))
+		} else {
+			without = append(without, string(spec.Provider))
+		}
+	}
+	const remediation = "Set REACTORCIDE_VCS_WEBHOOK_SECRET (or REACTORCIDE_VCS_<PROVIDER>_SECRET) to the secret the VCS signs webhooks with, or give each project its own webhook secret."
+	switch {
+	case len(without) == 0:
+		return Pass(fmt.Sprintf("global webhook secret set for %s", strings.Join(with, ", ")))
+	case len(with) == 0:
+		return Warn("no global webhook secret is set: webhooks are rejected except for projects and orgs with their own secret", remediation)
+	default:
+		return Warn(fmt.Sprintf("no global webhook secret for %s: their webhooks are rejected except for projects and orgs with their own secret",
+			strings.Join(without, ", ")), remediation)
+	}
+}
+
+// databaseNow returns the database's clock.
+func databaseNow(ctx context.Context, db *gorm.DB) (time.Time, error) {
+	var now time.Time
+	err := db.WithContext(ctx).Raw("SELECT now()").Scan(&now).Error
+	return now, err
+}
+
+// checkClockSkew compares the local clock with dbNow's, allowing for half
+// the round trip.
+func checkClockSkew(ctx context.Context, dbNow func(context.Context) (time.Time, error), max time.Duration) Result {
+	if max <= 0 {
+		max = DefaultMaxClockSkew
+	}
+	start := time.Now()
+	remote, err := dbNow(ctx)
+	if err != nil {
+		return Fail(fmt.Sprintf("failed to read the database clock: %v", err), "Check the database check first.")
+	}
+	elapsed := time.Since(start)
+	skew := remote.Sub(start.Add(elapsed / 2))
+	if skew < 0 {
+		skew = -skew
+	}
+	skew = skew.Round(time.Millisecond)
+	const remediation = "Run NTP (chrony or systemd-timesyncd) on the coordinator and database hosts. Skewed clocks break token expiry, job deadlines and stuck job detection."
+	switch {
+	case skew > 2*max:
+		return Fail(fmt.Sprintf("clock is %s off the database's", skew), remediation)
+	case skew > max:
+		return Warn(fmt.Sprintf("clock is %s off the database's", skew), remediation)
+	}
+	return Pass(fmt.Sprintf("within %s of the database's clock", skew))
+}
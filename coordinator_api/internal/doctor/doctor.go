@@ -0,0 +1,119 @@
+// Package doctor runs self-diagnostics on a coordinator install: checks of
+// what new installs most often get wrong, each saying how to fix what it
+// finds, rolled up into a health score.
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Check statuses. A skipped check couldn't run, usually because one it
+// depends on failed, and doesn't count towards the score.
+const (
+	StatusPass = "pass"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+	StatusSkip = "skip"
+)
+
+// DefaultCheckTimeout bounds each check Run runs.
+const DefaultCheckTimeout = 10 * time.Second
+
+// Check is one diagnostic. Run returns its Result; Run fills in Name and
+// Duration.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) Result
+}
+
+// Result is the outcome of a check. Remediation, set for anything but a
+// pass, is what to do about it.
+type Result struct {
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	Message     string `json:"message"`
+	Remediation string `json:"remediation,omitempty"`
+	DurationMs  int64  `json:"duration_ms"`
+}
+
+// Report is the outcome of a run of checks. Score runs from 0 to 100: the
+// average over the checks that ran of 100 for a pass, 50 for a warning and
+// 0 for a failure. Healthy is whether no check failed.
+type Report struct {
+	Score     int       `json:"score"`
+	Healthy   bool      `json:"healthy"`
+	CheckedAt time.Time `json:"checked_at"`
+	Checks    []Result  `json:"checks"`
+}
+
+// Run runs checks in order, each bounded by timeout (DefaultCheckTimeout
+// if zero), and reports on them. A check that panics fails.
+func Run(ctx context.Context, checks []Check, timeout time.Duration) Report {
+	if timeout <= 0 {
+		timeout = DefaultCheckTimeout
+	}
+	report := Report{CheckedAt: time.Now().UTC(), Checks: make([]Result, 0, len(checks))}
+	for _, check := range checks {
+		report.Checks = append(report.Checks, runCheck(ctx, check, timeout))
+	}
+	report.Score, report.Healthy = score(report.Checks)
+	return report
+}
+
+func runCheck(ctx context.Context, check Check, timeout time.Duration) (result Result) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			result = Fail(fmt.Sprintf("check panicked: %v", r), "This is a bug in the check; report it with the message.")
+		}
+		result.Name = check.Name
+		result.DurationMs = time.Since(start).Milliseconds()
+	}()
+	return check.Run(ctx)
+}
+
+func score(results []Result) (int, bool) {
+	healthy := true
+	total, ran := 0, 0
+	for _, result := range results {
+		switch result.Status {
+		case StatusPass:
+			total += 100
+		case StatusWarn:
+			total += 50
+		case StatusFail:
+			healthy = false
+		default:
+			continue
+		}
+		ran++
+	}
+	if ran == 0 {
+		return 100, healthy
+	}
+	return (total + ran/2) / ran, healthy
+}
+
+// Pass returns a passing Result.
+func Pass(message string) Result {
+	return Result{Status: StatusPass, Message: message}
+}
+
+// Warn returns a warning Result.
+func Warn(message, remediation string) Result {
+	return Result{Status: StatusWarn, Message: message, Remediation: remediation}
+}
+
+// Fail returns a failing Result.
+func Fail(message, remediation string) Result {
+	return Result{Status: StatusFail, Message: message, Remediation: remediation}
+}
+
+// Skip returns the Result of a check that couldn't run.
+func Skip(message string) Result {
+	return Result{Status: StatusSkip, Message: message}
+}
@@ -0,0 +1,133 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fixed(result Result) func(context.Context) Result {
+	return func(context.Context) Result { return result }
+}
+
+func TestRun(t *testing.T) {
+	report := Run(context.Background(), []Check{
+		{Name: "a", Run: fixed(Pass("ok"))},
+		{Name: "b", Run: fixed(Warn("meh", "fix it"))},
+		{Name: "c", Run: fixed(Skip("no"))},
+		{Name: "d", Run: func(context.Context) Result { panic("boom") }},
+	}, 0)
+
+	require.Len(t, report.Checks, 4)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, []string{report.Checks[0].Name, report.Checks[1].Name, report.Checks[2].Name, report.Checks[3].Name})
+	assert.Equal(t, StatusFail, report.Checks[3].Status, "a panic fails the check")
+	assert.Contains(t, report.Checks[3].Message, "boom")
+	assert.Equal(t, 50, report.Score, "pass, warn and fail average to 50; the skip doesn't count")
+	assert.False(t, report.Healthy)
+
+	report = Run(context.Background(), []Check{{Name: "a", Run: fixed(Pass("ok"))}, {Name: "b", Run: fixed(Warn("meh", ""))}}, 0)
+	assert.Equal(t, 75, report.Score)
+	assert.True(t, report.Healthy, "warnings don't make an install unhealthy")
+
+	assert.Equal(t, 100, Run(context.Background(), nil, 0).Score)
+}
+
+func TestRun_TimesOutChecks(t *testing.T) {
+	report := Run(context.Background(), []Check{{Name: "slow", Run: func(ctx context.Context) Result {
+		<-ctx.Done()
+		return Fail(ctx.Err().Error(), "")
+	}}}, 10*time.Millisecond)
+	assert.Equal(t, StatusFail, report.Checks[0].Status)
+	assert.Contains(t, report.Checks[0].Message, "deadline")
+}
+
+func TestChecks_WithoutDatabase(t *testing.T) {
+	report := Run(context.Background(), Checks(Deps{ExpectedMigrationVersion: 1}), 0)
+	statuses := map[string]string{}
+	for _, result := range report.Checks {
+		statuses[result.Name] = result.Status
+		if result.Status == StatusFail {
+			assert.NotEmpty(t, result.Remediation, result.Name)
+		}
+	}
+	assert.Equal(t, map[string]string{
+		CheckDatabase:      StatusFail,
+		CheckMigrations:    StatusSkip,
+		CheckMasterKeys:    StatusSkip,
+		CheckCorndogs:      StatusFail,
+		CheckObjectStore:   StatusFail,
+		CheckWebhookSecret: StatusSkip,
+		CheckClockSkew:     StatusSkip,
+	}, statuses)
+}
+
+func TestCheckMigrations(t *testing.T) {
+	at := func(version int64, err error) func(context.Context) (int64, error) {
+		return func(context.Context) (int64, error) { return version, err }
+	}
+	ctx := context.Background()
+	assert.Equal(t, StatusPass, checkMigrations(ctx, at(79, nil), 79).Status)
+	assert.Equal(t, StatusFail, checkMigrations(ctx, at(12, nil), 79).Status)
+	assert.Equal(t, StatusWarn, checkMigrations(ctx, at(80, nil), 79).Status)
+	assert.Equal(t, StatusFail, checkMigrations(ctx, at(0, errors.New("denied")), 79).Status)
+	assert.Equal(t, StatusSkip, checkMigrations(ctx, at(79, nil), 0).Status)
+}
+
+func TestCheckCorndogs(t *testing.T) {
+	ctx := context.Background()
+	client := corndogs.NewMockClient()
+	assert.Equal(t, StatusPass, checkCorndogs(ctx, client).Status)
+
+	client.GetQueuesFunc = func(ctx context.Context) ([]string, int64, error) {
+		return nil, 0, errors.New("connection refused")
+	}
+	result := checkCorndogs(ctx, client)
+	assert.Equal(t, StatusFail, result.Status)
+	assert.Contains(t, result.Message, "connection refused")
+}
+
+func TestCheckObjectStore(t *testing.T) {
+	store := objects.NewMemoryObjectStore()
+	result := checkObjectStore(context.Background(), store)
+	assert.Equal(t, StatusPass, result.Status, result.Message)
+
+	left, err := store.List(context.Background(), objectStoreProbePrefix)
+	require.NoError(t, err)
+	assert.Empty(t, left, "the probe object is deleted")
+}
+
+func TestCheckWebhookSecret(t *testing.T) {
+	secret := func(s string) func() string { return func() string { return s } }
+	github := vcs.ProviderSpec{Provider: vcs.GitHub, WebhookSecret: secret("s3cret")}
+	gitlab := vcs.ProviderSpec{Provider: vcs.GitLab, WebhookSecret: secret("")}
+
+	assert.Equal(t, StatusPass, checkWebhookSecret([]vcs.ProviderSpec{github}, "").Status)
+	assert.Equal(t, StatusPass, checkWebhookSecret([]vcs.ProviderSpec{github, gitlab}, "fallback").Status)
+
+	result := checkWebhookSecret([]vcs.ProviderSpec{github, gitlab}, "")
+	assert.Equal(t, StatusWarn, result.Status)
+	assert.Contains(t, result.Message, "gitlab")
+	assert.NotContains(t, result.Message, "github")
+	assert.Equal(t, StatusWarn, checkWebhookSecret([]vcs.ProviderSpec{gitlab}, "").Status)
+}
+
+func TestCheckClockSkew(t *testing.T) {
+	ctx := context.Background()
+	offBy := func(d time.Duration) func(context.Context) (time.Time, error) {
+		return func(context.Context) (time.Time, error) { return time.Now().Add(d), nil }
+	}
+	assert.Equal(t, StatusPass, checkClockSkew(ctx, offBy(time.Second), 0).Status)
+	assert.Equal(t, StatusWarn, checkClockSkew(ctx, offBy(-7*time.Second), 0).Status)
+	assert.Equal(t, StatusFail, checkClockSkew(ctx, offBy(time.Minute), 0).Status)
+	assert.Equal(t, StatusPass, checkClockSkew(ctx, offBy(time.Minute), 2*time.Minute).Status)
+	assert.Equal(t, StatusFail, checkClockSkew(ctx, func(context.Context) (time.Time, error) {
+		return time.Time{}, errors.New("down")
+	}, 0).Status)
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/authz"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// Page sizes and window of GET /api/v1/activity.
+const (
+	defaultActivityLimit  = 50
+	maxActivityLimit      = 100
+	defaultActivityWindow = 7 * 24 * time.Hour
+)
+
+// activityStore is the narrow store capability behind GET /api/v1/activity.
+// See postgres_store/activity_operations.go.
+type activityStore interface {
+	ListActivityVisibleTo(ctx context.Context, viewerID string, isGlobalAdmin bool, filter store.ActivityFilter) ([]models.ActivityEvent, error)
+}
+
+// ActivityHandler serves the activity feed.
+type ActivityHandler struct {
+	BaseHandler
+	store store.Store
+	// visibility resolves whether the caller is a global admin; nil falls
+	// back to the legacy admin role check (see roleStoreResolver).
+	visibility *authz.Resolver
+}
+
+// NewActivityHandler creates a new activity handler.
+func NewActivityHandler(store store.Store) *ActivityHandler {
+	return &ActivityHandler{store: store, visibility: roleStoreResolver(store, "ActivityHandler")}
+}
+
+// ActivityResponse is a page of the activity feed.
+type ActivityResponse struct {
+	Events []models.ActivityEvent `json:"events"`
+	// NextCursor, when set, fetches the next page as ?cursor=.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ListActivity handles GET /api/v1/activity: recent jobs started and
+// failed, project changes, credential and secret rotations and promotion
+// approvals the caller can see, newest first. kind (comma separated) and
+// project_id narrow it; since (RFC 3339, default a week ago) bounds it, so
+// a daily digest asks for the last day. A full page of limit events
+// (default 50, at most 100) sets next_cursor.
+func (h *ActivityHandler) ListActivity(w http.ResponseWriter, r *http.Request) {
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return
+	}
+	as, ok := h.store.(activityStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("activity feed not available"))
+		return
+	}
+
+	query := r.URL.Query()
+	filter := store.ActivityFilter{
+		ProjectID: query.Get("project_id"),
+		Since:     time.Now().UTC().Add(-defaultActivityWindow),
+		Limit:     defaultActivityLimit,
+	}
+	if raw := query.Get("kind"); raw != "" {
+		for _, kind := range strings.Split(raw, ",") {
+			kind = strings.TrimSpace(kind)
+			if !models.IsValidActivityKind(kind) {
+				h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{
+					Error:   "invalid_input",
+					Message: fmt.Sprintf("kind must be one of %s", strings.Join(models.ActivityKinds, ", ")),
+				})
+				return
+			}
+			filter.Kinds = append(filter.Kinds, kind)
+		}
+	}
+	var err error
+	if raw := query.Get("since"); raw != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, raw); err != nil {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: "since must be an RFC 3339 time"})
+			return
+		}
+	}
+	if raw := query.Get("cursor"); raw != "" {
+		cursor, err := store.ParseActivityCursor(raw)
+		if err != nil {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: "Invalid cursor"})
+			return
+		}
+		filter.After = &cursor
+	}
+	if raw := query.Get("limit"); raw != "" {
+		if filter.Limit, err = strconv.Atoi(raw); err != nil || filter.Limit < 1 || filter.Limit > maxActivityLimit {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_input",
+				Message: fmt.Sprintf("limit must be between 1 and %d", maxActivityLimit),
+			})
+			return
+		}
+	}
+
+	isGlobalAdmin := h.isAdmin(user)
+	if h.visibility != nil {
+		isGlobalAdmin, err = h.visibility.IsGlobalAdmin(r.Context(), authz.IdentityFromUser(user))
+		if err != nil {
+			h.respondWithError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	events, err := as.ListActivityVisibleTo(r.Context(), user.UserID, isGlobalAdmin, filter)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	response := ActivityResponse{Events: events}
+	if response.Events == nil {
+		response.Events = []models.ActivityEvent{}
+	}
+	if len(events) == filter.Limit {
+		last := events[len(events)-1]
+		response.NextCursor = store.ActivityCursor{OccurredAt: last.OccurredAt, EventID: last.EventID}.Encode()
+	}
+	h.respondWithJSON(w, http.StatusOK, response)
+}
+
+func (h *ActivityHandler) isAdmin(user *models.User) bool {
+	for _, role := range user.Roles {
+		if role == "admin" || role == "system_admin" {
+			return true
+		}
+	}
+	return false
+}
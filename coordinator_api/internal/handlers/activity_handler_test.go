@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// activityMockStore serves a fixed feed and records the last list call.
+type activityMockStore struct {
+	ProjectMockStore
+	events   []models.ActivityEvent
+	viewerID string
+	admin    bool
+	filter   store.ActivityFilter
+}
+
+func (m *activityMockStore) ListActivityVisibleTo(ctx context.Context, viewerID string, isGlobalAdmin bool, filter store.ActivityFilter) ([]models.ActivityEvent, error) {
+	m.viewerID, m.admin, m.filter = viewerID, isGlobalAdmin, filter
+	if len(m.events) > filter.Limit {
+		return m.events[:filter.Limit], nil
+	}
+	return m.events, nil
+}
+
+func TestActivityHandler_ListActivity(t *testing.T) {
+	base := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	var events []models.ActivityEvent
+	for i := 0; i < 3; i++ {
+		events = append(events, models.ActivityEvent{
+			EventID:    fmt.Sprintf("job_started:%d", i),
+			Kind:       models.ActivityJobStarted,
+			OccurredAt: base.Add(-time.Duration(i) * time.Minute),
+		})
+	}
+
+	t.Run("pages with a cursor", func(t *testing.T) {
+		m := &activityMockStore{events: events}
+		h := NewActivityHandler(m)
+		req := withUser(httptest.NewRequest(http.MethodGet, "/api/v1/activity?limit=2&kind=job_started,job_failed&project_id=p1&since=2026-05-31T00:00:00Z", nil))
+		w := httptest.NewRecorder()
+		h.ListActivity(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var resp ActivityResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Len(t, resp.Events, 2)
+		assert.Equal(t, "test-user-id", m.viewerID)
+		assert.False(t, m.admin)
+		assert.Equal(t, []string{models.ActivityJobStarted, models.ActivityJobFailed}, m.filter.Kinds)
+		assert.Equal(t, "p1", m.filter.ProjectID)
+		assert.Equal(t, time.Date(2026, 5, 31, 0, 0, 0, 0, time.UTC), m.filter.Since)
+
+		cursor, err := store.ParseActivityCursor(resp.NextCursor)
+		require.NoError(t, err)
+		assert.Equal(t, "job_started:1", cursor.EventID)
+		assert.True(t, cursor.OccurredAt.Equal(events[1].OccurredAt))
+
+		req = withUser(httptest.NewRequest(http.MethodGet, "/api/v1/activity?cursor="+resp.NextCursor, nil))
+		w = httptest.NewRecorder()
+		h.ListActivity(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NotNil(t, m.filter.After)
+		assert.Equal(t, "job_started:1", m.filter.After.EventID)
+		assert.Equal(t, defaultActivityLimit, m.filter.Limit)
+		assert.WithinDuration(t, time.Now().Add(-defaultActivityWindow), m.filter.Since, time.Minute)
+	})
+
+	t.Run("last page has no cursor", func(t *testing.T) {
+		h := NewActivityHandler(&activityMockStore{events: events})
+		w := httptest.NewRecorder()
+		h.ListActivity(w, withUser(httptest.NewRequest(http.MethodGet, "/api/v1/activity", nil)))
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp ActivityResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Len(t, resp.Events, 3)
+		assert.Empty(t, resp.NextCursor)
+	})
+
+	t.Run("admins see everything", func(t *testing.T) {
+		m := &activityMockStore{}
+		h := NewActivityHandler(m)
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/activity", nil)
+		req = req.WithContext(checkauth.SetUserContext(req.Context(), &models.User{UserID: "admin-id", Roles: []string{"admin"}}))
+		w := httptest.NewRecorder()
+		h.ListActivity(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, m.admin)
+		assert.JSONEq(t, `{"events":[]}`, w.Body.String())
+	})
+
+	for name, query := range map[string]string{
+		"unknown kind":     "kind=job_started,job_exploded",
+		"malformed cursor": "cursor=not-a-cursor",
+		"malformed since":  "since=yesterday",
+		"limit too large":  "limit=101",
+	} {
+		t.Run(name, func(t *testing.T) {
+			h := NewActivityHandler(&activityMockStore{})
+			w := httptest.NewRecorder()
+			h.ListActivity(w, withUser(httptest.NewRequest(http.MethodGet, "/api/v1/activity?"+query, nil)))
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+
+	t.Run("requires a user", func(t *testing.T) {
+		h := NewActivityHandler(&activityMockStore{})
+		w := httptest.NewRecorder()
+		h.ListActivity(w, httptest.NewRequest(http.MethodGet, "/api/v1/activity", nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/admission"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// admissionPolicyStore is the narrow store capability behind the admission
+// policy endpoints. See postgres_store/admission_operations.go.
+type admissionPolicyStore interface {
+	ListAdmissionPolicies(ctx context.Context) ([]models.AdmissionPolicy, error)
+	GetAdmissionPolicy(ctx context.Context, policyID string) (*models.AdmissionPolicy, error)
+	CreateAdmissionPolicy(ctx context.Context, policy *models.AdmissionPolicy) error
+	UpdateAdmissionPolicy(ctx context.Context, policy *models.AdmissionPolicy) error
+	DeleteAdmissionPolicy(ctx context.Context, policyID string) error
+}
+
+// AdmissionPolicyHandler serves the admission policies admins write.
+type AdmissionPolicyHandler struct {
+	BaseHandler
+	store store.Store
+}
+
+// NewAdmissionPolicyHandler creates a new admission policy handler.
+func NewAdmissionPolicyHandler(store store.Store) *AdmissionPolicyHandler {
+	return &AdmissionPolicyHandler{store: store}
+}
+
+// AdmissionPolicyRequest is the JSON body of the admission policy create
+// and update endpoints. An update changes only the fields it sets.
+type AdmissionPolicyRequest struct {
+	Name        *string             `json:"name,omitempty"`
+	Description *string             `json:"description,omitempty"`
+	Target      *string             `json:"target,omitempty"`
+	Enforcement *string             `json:"enforcement,omitempty"`
+	ProjectID   *string             `json:"project_id,omitempty"`
+	Rules       []models.PolicyRule `json:"rules,omitempty"`
+}
+
+// ListAdmissionPoliciesResponse is the JSON body of GET
+// /api/v1/admin/admission-policies.
+type ListAdmissionPoliciesResponse struct {
+	Policies []models.AdmissionPolicy `json:"policies"`
+}
+
+// EvaluateAdmissionRequest is the JSON body of POST
+// /api/v1/admin/admission-policies/evaluate: a document to check against
+// the saved policies for Target in ProjectID, or against Policy alone, a
+// draft, if given.
+type EvaluateAdmissionRequest struct {
+	Target    string                  `json:"target"`
+	ProjectID string                  `json:"project_id,omitempty"`
+	Input     map[string]interface{}  `json:"input"`
+	Policy    *models.AdmissionPolicy `json:"policy,omitempty"`
+}
+
+// EvaluateAdmissionResponse is the result of an evaluation: whether the
+// document would be admitted, the "deny" violations that would reject it
+// and the "warn" ones that would be logged.
+type EvaluateAdmissionResponse struct {
+	Allowed  bool                     `json:"allowed"`
+	Denials  []models.PolicyViolation `json:"denials"`
+	Warnings []models.PolicyViolation `json:"warnings"`
+}
+
+func (h *AdmissionPolicyHandler) policyStore(w http.ResponseWriter) (admissionPolicyStore, bool) {
+	ps, ok := h.store.(admissionPolicyStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("admission policies not available"))
+	}
+	return ps, ok
+}
+
+// ListAdmissionPolicies handles GET /api/v1/admin/admission-policies.
+func (h *AdmissionPolicyHandler) ListAdmissionPolicies(w http.ResponseWriter, r *http.Request) {
+	ps, ok := h.policyStore(w)
+	if !ok {
+		return
+	}
+	policies, err := ps.ListAdmissionPolicies(r.Context())
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if policies == nil {
+		policies = []models.AdmissionPolicy{}
+	}
+	h.respondWithJSON(w, http.StatusOK, ListAdmissionPoliciesResponse{Policies: policies})
+}
+
+// GetAdmissionPolicy handles GET /api/v1/admin/admission-policies/{policy_id}.
+func (h *AdmissionPolicyHandler) GetAdmissionPolicy(w http.ResponseWriter, r *http.Request) {
+	ps, ok := h.policyStore(w)
+	if !ok {
+		return
+	}
+	policy, err := ps.GetAdmissionPolicy(r.Context(), h.getID(r, "policy_id"))
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, policy)
+}
+
+// CreateAdmissionPolicy handles POST /api/v1/admin/admission-policies.
+func (h *AdmissionPolicyHandler) CreateAdmissionPolicy(w http.ResponseWriter, r *http.Request) {
+	ps, ok := h.policyStore(w)
+	if !ok {
+		return
+	}
+	var req AdmissionPolicyRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	policy := &models.AdmissionPolicy{Enforcement: models.PolicyEnforceDeny}
+	if user := checkauth.GetUserFromContext(r.Context()); user != nil {
+		policy.CreatedBy = &user.UserID
+	}
+	if !h.applyAdmissionPolicyRequest(w, r, policy, req) {
+		return
+	}
+	if err := ps.CreateAdmissionPolicy(r.Context(), policy); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusCreated, policy)
+}
+
+// UpdateAdmissionPolicy handles PUT
+// /api/v1/admin/admission-policies/{policy_id}, e.g. {"enforcement":
+// "deny"} once a "warn" policy has been tried out.
+func (h *AdmissionPolicyHandler) UpdateAdmissionPolicy(w http.ResponseWriter, r *http.Request) {
+	ps, ok := h.policyStore(w)
+	if !ok {
+		return
+	}
+	policy, err := ps.GetAdmissionPolicy(r.Context(), h.getID(r, "policy_id"))
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	var req AdmissionPolicyRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	if !h.applyAdmissionPolicyRequest(w, r, policy, req) {
+		return
+	}
+	if err := ps.UpdateAdmissionPolicy(r.Context(), policy); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, policy)
+}
+
+// DeleteAdmissionPolicy handles DELETE
+// /api/v1/admin/admission-policies/{policy_id}.
+func (h *AdmissionPolicyHandler) DeleteAdmissionPolicy(w http.ResponseWriter, r *http.Request) {
+	ps, ok := h.policyStore(w)
+	if !ok {
+		return
+	}
+	if err := ps.DeleteAdmissionPolicy(r.Context(), h.getID(r, "policy_id")); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// EvaluateAdmission handles POST /api/v1/admin/admission-policies/evaluate:
+// it reports what the policies would make of a job, trigger spec or project
+// without creating anything, to try a policy out before saving it.
+func (h *AdmissionPolicyHandler) EvaluateAdmission(w http.ResponseWriter, r *http.Request) {
+	ps, ok := h.policyStore(w)
+	if !ok {
+		return
+	}
+	var req EvaluateAdmissionRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Input == nil {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: "input is required"})
+		return
+	}
+
+	var policies []models.AdmissionPolicy
+	if req.Policy != nil {
+		draft := *req.Policy
+		if draft.Target == "" {
+			draft.Target = req.Target
+		}
+		if draft.Enforcement == "" {
+			draft.Enforcement = models.PolicyEnforceDeny
+		}
+		if draft.Name == "" {
+			draft.Name = "draft"
+		}
+		if err := draft.Validate(); err != nil {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: "policy: " + err.Error()})
+			return
+		}
+		req.Target = draft.Target
+		policies = []models.AdmissionPolicy{draft}
+	} else {
+		var err error
+		if policies, err = ps.ListAdmissionPolicies(r.Context()); err != nil {
+			h.respondWithError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	switch req.Target {
+	case models.PolicyTargetJob, models.PolicyTargetTrigger, models.PolicyTargetProject:
+	default:
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: `target must be "job", "trigger" or "project"`})
+		return
+	}
+
+	denials, warnings := admission.Evaluate(policies, req.Target, req.ProjectID, req.Input)
+	resp := EvaluateAdmissionResponse{Allowed: len(denials) == 0, Denials: denials, Warnings: warnings}
+	if resp.Denials == nil {
+		resp.Denials = []models.PolicyViolation{}
+	}
+	if resp.Warnings == nil {
+		resp.Warnings = []models.PolicyViolation{}
+	}
+	h.respondWithJSON(w, http.StatusOK, resp)
+}
+
+func (h *AdmissionPolicyHandler) applyAdmissionPolicyRequest(w http.ResponseWriter, r *http.Request, policy *models.AdmissionPolicy, req AdmissionPolicyRequest) bool {
+	if req.ProjectID != nil {
+		policy.ProjectID = nil
+		if *req.ProjectID != "" {
+			if _, err := h.store.GetProjectByID(r.Context(), *req.ProjectID); err != nil {
+				h.respondWithError(w, http.StatusNotFound, err)
+				return false
+			}
+			policy.ProjectID = req.ProjectID
+		}
+	}
+	for dst, src := range map[*string]*string{
+		&policy.Name:        req.Name,
+		&policy.Description: req.Description,
+		&policy.Target:      req.Target,
+		&policy.Enforcement: req.Enforcement,
+	} {
+		if src != nil {
+			*dst = *src
+		}
+	}
+	if req.Rules != nil {
+		policy.Rules = req.Rules
+	}
+	if err := policy.Validate(); err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/admission"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// admissionMockStore keeps admission policies in memory on top of
+// ProjectMockStore.
+type admissionMockStore struct {
+	ProjectMockStore
+	policies []models.AdmissionPolicy
+}
+
+func (m *admissionMockStore) ListAdmissionPolicies(ctx context.Context) ([]models.AdmissionPolicy, error) {
+	return m.policies, nil
+}
+
+func (m *admissionMockStore) GetAdmissionPolicy(ctx context.Context, policyID string) (*models.AdmissionPolicy, error) {
+	for i := range m.policies {
+		if m.policies[i].PolicyID == policyID {
+			policy := m.policies[i]
+			return &policy, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (m *admissionMockStore) CreateAdmissionPolicy(ctx context.Context, policy *models.AdmissionPolicy) error {
+	policy.PolicyID = "policy-new"
+	m.policies = append(m.policies, *policy)
+	return nil
+}
+
+func (m *admissionMockStore) UpdateAdmissionPolicy(ctx context.Context, policy *models.AdmissionPolicy) error {
+	return nil
+}
+
+func (m *admissionMockStore) DeleteAdmissionPolicy(ctx context.Context, policyID string) error {
+	return nil
+}
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
+
+func imagePolicy() models.AdmissionPolicy {
+	return models.AdmissionPolicy{
+		PolicyID:    "policy-1",
+		Name:        "approved-images",
+		Target:      models.PolicyTargetJob,
+		Enforcement: models.PolicyEnforceDeny,
+		Rules: models.PolicyRules{
+			{Field: "runner_image", Op: models.PolicyOpIn, Values: []string{"registry.example.com/*/*"}},
+			{Field: "timeout_seconds", Op: models.PolicyOpMax, Limit: float64Ptr(7200)},
+		},
+	}
+}
+
+func TestAdmissionPolicyHandler_CreateAdmissionPolicy(t *testing.T) {
+	s := &admissionMockStore{}
+	h := NewAdmissionPolicyHandler(s)
+	do := func(body string) *httptest.ResponseRecorder {
+		req := withUser(httptest.NewRequest(http.MethodPost, "/api/v1/admin/admission-policies", strings.NewReader(body)))
+		w := httptest.NewRecorder()
+		h.CreateAdmissionPolicy(w, req)
+		return w
+	}
+
+	w := do(`{"name":"team-label","target":"job","rules":[{"field":"job_env_vars.TEAM","op":"required"}]}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	require.Len(t, s.policies, 1)
+	assert.Equal(t, models.PolicyEnforceDeny, s.policies[0].Enforcement)
+	require.NotNil(t, s.policies[0].CreatedBy)
+	assert.Equal(t, "test-user-id", *s.policies[0].CreatedBy)
+
+	w = do(`{"name":"broken","target":"job","rules":[{"field":"timeout_seconds","op":"max"}]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "rules[0]: max needs a limit")
+}
+
+func TestAdmissionPolicyHandler_EvaluateAdmission(t *testing.T) {
+	s := &admissionMockStore{policies: []models.AdmissionPolicy{imagePolicy()}}
+	h := NewAdmissionPolicyHandler(s)
+	do := func(body string) EvaluateAdmissionResponse {
+		req := withUser(httptest.NewRequest(http.MethodPost, "/api/v1/admin/admission-policies/evaluate", strings.NewReader(body)))
+		w := httptest.NewRecorder()
+		h.EvaluateAdmission(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp EvaluateAdmissionResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		return resp
+	}
+
+	resp := do(`{"target":"job","input":{"runner_image":"docker.io/library/alpine","timeout_seconds":3600}}`)
+	assert.False(t, resp.Allowed)
+	require.Len(t, resp.Denials, 1)
+	assert.Equal(t, "approved-images", resp.Denials[0].Policy)
+	assert.Equal(t, "runner_image", resp.Denials[0].Field)
+
+	resp = do(`{"target":"job","input":{"runner_image":"registry.example.com/team/app:1.0","timeout_seconds":3600}}`)
+	assert.True(t, resp.Allowed)
+
+	// A draft policy is evaluated on its own, instead of the saved ones.
+	resp = do(`{"input":{"container_image":"docker.io/library/alpine"},"policy":{"target":"job","enforcement":"warn","rules":[{"field":"container_image","op":"not_matches","pattern":"^docker\\.io/"}]}}`)
+	assert.True(t, resp.Allowed)
+	require.Len(t, resp.Warnings, 1)
+	assert.Equal(t, "draft", resp.Warnings[0].Policy)
+}
+
+func TestCreateJob_AdmissionPolicyDenied(t *testing.T) {
+	defer admission.RegisterJobHook(&admissionMockStore{policies: []models.AdmissionPolicy{imagePolicy()}})()
+	handler := NewJobHandler(&MockStore{}, nil)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"name":            "build",
+		"job_command":     "make",
+		"source_type":     "copy",
+		"source_path":     "/src",
+		"runner_image":    "docker.io/library/alpine",
+		"timeout_seconds": 86400,
+	})
+	req := withUser(httptest.NewRequest(http.MethodPost, "/api/v1/jobs", bytes.NewReader(body)))
+	w := httptest.NewRecorder()
+	handler.CreateJob(w, req)
+
+	require.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	var resp PolicyDeniedResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, "policy_denied", resp.Error)
+	require.Len(t, resp.Violations, 2)
+	assert.Equal(t, "runner_image", resp.Violations[0].Field)
+	assert.Equal(t, "timeout_seconds", resp.Violations[1].Field)
+	assert.Equal(t, "timeout_seconds 86400 is over the maximum of 7200", resp.Violations[1].Message)
+}
+
+func TestProjectHandler_CreateProject_AdmissionPolicy(t *testing.T) {
+	s := &admissionMockStore{policies: []models.AdmissionPolicy{{
+		PolicyID:    "policy-2",
+		Name:        "short-timeouts",
+		Target:      models.PolicyTargetProject,
+		Enforcement: models.PolicyEnforceDeny,
+		Rules: models.PolicyRules{{
+			Field: "default_timeout_seconds", Op: models.PolicyOpMax, Limit: float64Ptr(3600),
+			Message: "projects may not default to more than an hour",
+		}},
+	}}}
+	h := NewProjectHandler(s)
+	do := func(body string) *httptest.ResponseRecorder {
+		req := withUser(httptest.NewRequest(http.MethodPost, "/api/v1/projects", strings.NewReader(body)))
+		w := httptest.NewRecorder()
+		h.CreateProject(w, req)
+		return w
+	}
+
+	w := do(`{"name":"slow","repo_url":"github.com/org/slow","default_timeout_seconds":7200}`)
+	require.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "projects may not default to more than an hour")
+	assert.Empty(t, s.CreateProjectCalls)
+
+	w = do(`{"name":"fast","repo_url":"github.com/org/fast","default_timeout_seconds":600}`)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+}
@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// AnnouncementHeader carries the most severe active announcement's message
+// on every API response, and AnnouncementSeverityHeader its severity.
+const (
+	AnnouncementHeader         = "X-Reactorcide-Announcement"
+	AnnouncementSeverityHeader = "X-Reactorcide-Announcement-Severity"
+)
+
+// announcementTTL bounds how stale the announcements may be on replicas
+// that didn't handle the change.
+const announcementTTL = 10 * time.Second
+
+// announcementStore is the narrow store capability behind the
+// announcement endpoints. See postgres_store/announcement_operations.go.
+type announcementStore interface {
+	ListAnnouncements(ctx context.Context) ([]models.Announcement, error)
+	GetAnnouncement(ctx context.Context, announcementID string) (*models.Announcement, error)
+	CreateAnnouncement(ctx context.Context, announcement *models.Announcement) error
+	UpdateAnnouncement(ctx context.Context, announcement *models.Announcement) error
+	DeleteAnnouncement(ctx context.Context, announcementID string) error
+}
+
+// announcementCache caches the announcements so the header doesn't cost a
+// query per request. Which are active is worked out on every read, so
+// they start and end on time between refreshes.
+type announcementCache struct {
+	store         store.Store
+	now           func() time.Time
+	mu            sync.Mutex
+	fetched       time.Time
+	announcements []models.Announcement
+}
+
+func newAnnouncementCache(st store.Store) *announcementCache {
+	return &announcementCache{store: st, now: time.Now}
+}
+
+// active returns the announcements shown now, most severe first. Lookup
+// failures keep the last ones fetched rather than failing requests.
+func (c *announcementCache) active(ctx context.Context) []models.Announcement {
+	as, ok := c.store.(announcementStore)
+	if !ok {
+		return []models.Announcement{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if now.Sub(c.fetched) > announcementTTL {
+		if announcements, err := as.ListAnnouncements(ctx); err == nil {
+			c.announcements = announcements
+			c.fetched = now
+		}
+	}
+	return models.ActiveAnnouncements(c.announcements, now)
+}
+
+func (c *announcementCache) invalidate() {
+	c.mu.Lock()
+	c.fetched = time.Time{}
+	c.mu.Unlock()
+}
+
+// headerMiddleware sets AnnouncementHeader and AnnouncementSeverityHeader
+// on API responses while an announcement is active.
+func (c *announcementCache) headerMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/") {
+			if active := c.active(r.Context()); len(active) > 0 {
+				w.Header().Set(AnnouncementHeader, active[0].Message)
+				w.Header().Set(AnnouncementSeverityHeader, active[0].Severity)
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// AnnouncementHandler serves announcements: the active ones to anyone, and
+// all of them to admins to manage.
+type AnnouncementHandler struct {
+	BaseHandler
+	store store.Store
+	cache *announcementCache
+}
+
+// NewAnnouncementHandler creates a new announcement handler. cache may be
+// nil.
+func NewAnnouncementHandler(store store.Store, cache *announcementCache) *AnnouncementHandler {
+	if cache == nil {
+		cache = newAnnouncementCache(store)
+	}
+	return &AnnouncementHandler{store: store, cache: cache}
+}
+
+// AnnouncementRequest is the JSON body of the announcement create and
+// update endpoints. An update changes only the fields it sets; StartsAt
+// and EndsAt are RFC 3339 times, or "" to clear them.
+type AnnouncementRequest struct {
+	Message  *string `json:"message,omitempty"`
+	Severity *string `json:"severity,omitempty"`
+	StartsAt *string `json:"starts_at,omitempty"`
+	EndsAt   *string `json:"ends_at,omitempty"`
+}
+
+// ListAnnouncementsResponse is the JSON body of GET /api/v1/announcements
+// and GET /api/v1/admin/announcements.
+type ListAnnouncementsResponse struct {
+	Announcements []models.Announcement `json:"announcements"`
+}
+
+func (h *AnnouncementHandler) announcementStore(w http.ResponseWriter) (announcementStore, bool) {
+	as, ok := h.store.(announcementStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("announcements not available"))
+	}
+	return as, ok
+}
+
+// ListActiveAnnouncements handles GET /api/v1/announcements. It needs no
+// authentication, so clients can show planned maintenance before login.
+func (h *AnnouncementHandler) ListActiveAnnouncements(w http.ResponseWriter, r *http.Request) {
+	active := h.cache.active(r.Context())
+	for i := range active {
+		// Who wrote it is for admins only.
+		active[i].CreatedBy = nil
+	}
+	h.respondWithJSON(w, http.StatusOK, ListAnnouncementsResponse{Announcements: active})
+}
+
+// ListAnnouncements handles GET /api/v1/admin/announcements: every
+// announcement, including scheduled and ended ones.
+func (h *AnnouncementHandler) ListAnnouncements(w http.ResponseWriter, r *http.Request) {
+	as, ok := h.announcementStore(w)
+	if !ok {
+		return
+	}
+	announcements, err := as.ListAnnouncements(r.Context())
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if announcements == nil {
+		announcements = []models.Announcement{}
+	}
+	h.respondWithJSON(w, http.StatusOK, ListAnnouncementsResponse{Announcements: announcements})
+}
+
+// GetAnnouncement handles GET /api/v1/admin/announcements/{announcement_id}.
+func (h *AnnouncementHandler) GetAnnouncement(w http.ResponseWriter, r *http.Request) {
+	as, ok := h.announcementStore(w)
+	if !ok {
+		return
+	}
+	announcement, err := as.GetAnnouncement(r.Context(), h.getID(r, "announcement_id"))
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, announcement)
+}
+
+// CreateAnnouncement handles POST /api/v1/admin/announcements.
+func (h *AnnouncementHandler) CreateAnnouncement(w http.ResponseWriter, r *http.Request) {
+	as, ok := h.announcementStore(w)
+	if !ok {
+		return
+	}
+	var req AnnouncementRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	announcement := &models.Announcement{Severity: models.AnnouncementInfo}
+	if user := checkauth.GetUserFromContext(r.Context()); user != nil {
+		announcement.CreatedBy = &user.UserID
+	}
+	if !h.applyAnnouncementRequest(w, announcement, req) {
+		return
+	}
+	if err := as.CreateAnnouncement(r.Context(), announcement); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.cache.invalidate()
+	h.respondWithJSON(w, http.StatusCreated, announcement)
+}
+
+// UpdateAnnouncement handles PUT
+// /api/v1/admin/announcements/{announcement_id}, e.g. {"ends_at": ...} to
+// end one early.
+func (h *AnnouncementHandler) UpdateAnnouncement(w http.ResponseWriter, r *http.Request) {
+	as, ok := h.announcementStore(w)
+	if !ok {
+		return
+	}
+	announcement, err := as.GetAnnouncement(r.Context(), h.getID(r, "announcement_id"))
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	var req AnnouncementRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	if !h.applyAnnouncementRequest(w, announcement, req) {
+		return
+	}
+	if err := as.UpdateAnnouncement(r.Context(), announcement); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.cache.invalidate()
+	h.respondWithJSON(w, http.StatusOK, announcement)
+}
+
+// DeleteAnnouncement handles DELETE
+// /api/v1/admin/announcements/{announcement_id}.
+func (h *AnnouncementHandler) DeleteAnnouncement(w http.ResponseWriter, r *http.Request) {
+	as, ok := h.announcementStore(w)
+	if !ok {
+		return
+	}
+	if err := as.DeleteAnnouncement(r.Context(), h.getID(r, "announcement_id")); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.cache.invalidate()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AnnouncementHandler) applyAnnouncementRequest(w http.ResponseWriter, announcement *models.Announcement, req AnnouncementRequest) bool {
+	if req.Message != nil {
+		announcement.Message = strings.TrimSpace(*req.Message)
+	}
+	if req.Severity != nil {
+		announcement.Severity = *req.Severity
+	}
+	for dst, src := range map[**time.Time]*string{
+		&announcement.StartsAt: req.StartsAt,
+		&announcement.EndsAt:   req.EndsAt,
+	} {
+		if src == nil {
+			continue
+		}
+		if *src == "" {
+			*dst = nil
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, *src)
+		if err != nil {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: "starts_at and ends_at must be RFC 3339 times"})
+			return false
+		}
+		t = t.UTC()
+		*dst = &t
+	}
+	if err := announcement.Validate(); err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+		return false
+	}
+	return true
+}
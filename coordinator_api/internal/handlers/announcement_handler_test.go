@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// announcementMockStore keeps announcements in memory on top of
+// ProjectMockStore.
+type announcementMockStore struct {
+	ProjectMockStore
+	announcements []models.Announcement
+}
+
+func (m *announcementMockStore) ListAnnouncements(ctx context.Context) ([]models.Announcement, error) {
+	return append([]models.Announcement(nil), m.announcements...), nil
+}
+
+func (m *announcementMockStore) GetAnnouncement(ctx context.Context, announcementID string) (*models.Announcement, error) {
+	for _, a := range m.announcements {
+		if a.AnnouncementID == announcementID {
+			return &a, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (m *announcementMockStore) CreateAnnouncement(ctx context.Context, announcement *models.Announcement) error {
+	announcement.AnnouncementID = "announcement-" + announcement.Severity
+	m.announcements = append(m.announcements, *announcement)
+	return nil
+}
+
+func (m *announcementMockStore) UpdateAnnouncement(ctx context.Context, announcement *models.Announcement) error {
+	for i := range m.announcements {
+		if m.announcements[i].AnnouncementID == announcement.AnnouncementID {
+			m.announcements[i] = *announcement
+			return nil
+		}
+	}
+	return store.ErrNotFound
+}
+
+func (m *announcementMockStore) DeleteAnnouncement(ctx context.Context, announcementID string) error {
+	for i := range m.announcements {
+		if m.announcements[i].AnnouncementID == announcementID {
+			m.announcements = append(m.announcements[:i], m.announcements[i+1:]...)
+			return nil
+		}
+	}
+	return store.ErrNotFound
+}
+
+func TestAnnouncementHandler(t *testing.T) {
+	s := &announcementMockStore{}
+	cache := newAnnouncementCache(s)
+	h := NewAnnouncementHandler(s, cache)
+	create := func(body string) *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.CreateAnnouncement(w, withUser(httptest.NewRequest(http.MethodPost, "/api/v1/admin/announcements", strings.NewReader(body))))
+		return w
+	}
+	header := func() (string, string) {
+		rec := httptest.NewRecorder()
+		cache.headerMiddleware(http.NotFoundHandler()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/jobs", nil))
+		return rec.Header().Get(AnnouncementHeader), rec.Header().Get(AnnouncementSeverityHeader)
+	}
+
+	assert.Equal(t, http.StatusBadRequest, create(`{"message":"line one\nline two"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, create(`{"message":"x","severity":"urgent"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, create(`{"message":"x","ends_at":"tomorrow"}`).Code)
+
+	w := create(`{"message":"New runners are available"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var info models.Announcement
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&info))
+	assert.Equal(t, models.AnnouncementInfo, info.Severity)
+	assert.Equal(t, "test-user-id", *info.CreatedBy)
+
+	w = create(`{"message":"Maintenance Saturday 02:00-04:00 UTC","severity":"warning"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	msg, severity := header()
+	assert.Equal(t, "Maintenance Saturday 02:00-04:00 UTC", msg, "the most severe one wins")
+	assert.Equal(t, models.AnnouncementWarning, severity)
+
+	// The public endpoint lists both, without who wrote them.
+	w = httptest.NewRecorder()
+	h.ListActiveAnnouncements(w, httptest.NewRequest(http.MethodGet, "/api/v1/announcements", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var list ListAnnouncementsResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&list))
+	require.Len(t, list.Announcements, 2)
+	assert.Nil(t, list.Announcements[0].CreatedBy)
+	assert.NotContains(t, w.Body.String(), "test-user-id")
+
+	// Ending the warning early takes it out of the header at once.
+	ended := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	req := withUser(httptest.NewRequest(http.MethodPut, "/api/v1/admin/announcements/announcement-warning", strings.NewReader(`{"ends_at":"`+ended+`"}`)))
+	req = req.WithContext(setIDContext(req.Context(), "announcement_id", "announcement-warning"))
+	w = httptest.NewRecorder()
+	h.UpdateAnnouncement(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	msg, severity = header()
+	assert.Equal(t, "New runners are available", msg)
+	assert.Equal(t, models.AnnouncementInfo, severity)
+
+	// Only API responses carry the header.
+	rec := httptest.NewRecorder()
+	cache.headerMiddleware(http.NotFoundHandler()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Empty(t, rec.Header().Get(AnnouncementHeader))
+}
+
+func TestAnnouncementHandler_WithoutStoreSupport(t *testing.T) {
+	h := NewAnnouncementHandler(&ProjectMockStore{}, nil)
+	w := httptest.NewRecorder()
+	h.ListActiveAnnouncements(w, httptest.NewRequest(http.MethodGet, "/api/v1/announcements", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"announcements":[]}`, w.Body.String())
+}
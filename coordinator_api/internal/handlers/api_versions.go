@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/metrics"
+)
+
+// API versions served under /api/<version>. v1 is the original API, whose
+// routes are registered directly in createAppMux; later versions register
+// theirs through apiRoutes.
+const (
+	APIVersion1 = "v1"
+	APIVersion2 = "v2"
+)
+
+// Headers a deprecated route answers with: Deprecation (RFC 9745) is when
+// it was deprecated, Sunset (RFC 8594) when it may stop being served, and
+// Link names its successor with rel="successor-version".
+const (
+	DeprecationHeader = "Deprecation"
+	SunsetHeader      = "Sunset"
+	LinkHeader        = "Link"
+)
+
+// v1JobsDeprecatedAt is when the v1 job read routes were superseded by
+// their /api/v2 versions.
+var v1JobsDeprecatedAt = time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+
+// apiRoutes registers one API version's routes on the app mux, so each
+// version's handlers are wired together and can change shape without
+// touching the others.
+type apiRoutes struct {
+	mux     *http.ServeMux
+	version string
+}
+
+// Path returns route's path under this version, e.g. /api/v2/jobs.
+func (v apiRoutes) Path(route string) string {
+	return "/api/" + v.version + route
+}
+
+// HandleFunc registers handler for route under this version.
+func (v apiRoutes) HandleFunc(route string, handler func(http.ResponseWriter, *http.Request)) {
+	v.mux.HandleFunc(v.Path(route), handler)
+}
+
+// apiDeprecation marks a route as slated for change in a later API
+// version. The route keeps working unchanged; its responses just say so.
+type apiDeprecation struct {
+	// Route names the route in reactorcide_api_deprecated_requests_total.
+	Route string
+	// Since is when the route was deprecated.
+	Since time.Time
+	// Sunset, when set, is the earliest the route may be removed.
+	Sunset time.Time
+	// Successor returns the path replacing the requested one, or "".
+	Successor func(r *http.Request) string
+}
+
+// wrap adds the deprecation headers to next's responses.
+func (d apiDeprecation) wrap(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		header := w.Header()
+		header.Set(DeprecationHeader, fmt.Sprintf("@%d", d.Since.Unix()))
+		if !d.Sunset.IsZero() {
+			header.Set(SunsetHeader, d.Sunset.UTC().Format(http.TimeFormat))
+		}
+		if d.Successor != nil {
+			if successor := d.Successor(r); successor != "" {
+				header.Add(LinkHeader, fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+			}
+		}
+		metrics.RecordAPIDeprecatedRequest(d.Route)
+		next(w, r)
+	}
+}
+
+// apiV1Sunset parses config.APIV1Sunset, returning the zero time (no Sunset
+// header) when it's unset or invalid.
+func apiV1Sunset() time.Time {
+	if config.APIV1Sunset == "" {
+		return time.Time{}
+	}
+	sunset, err := time.Parse(time.DateOnly, config.APIV1Sunset)
+	if err != nil {
+		logging.Log.WithError(err).WithField("sunset", config.APIV1Sunset).Warn("Ignoring invalid REACTORCIDE_API_V1_SUNSET; expected YYYY-MM-DD")
+		return time.Time{}
+	}
+	return sunset
+}
+
+// v1JobDeprecations are the v1 job read routes whose responses change shape
+// in v2 (see JobResponseV2).
+type v1JobDeprecations struct {
+	getJob   apiDeprecation
+	listJobs apiDeprecation
+}
+
+func newV1JobDeprecations(sunset time.Time) v1JobDeprecations {
+	v2 := apiRoutes{version: APIVersion2}
+	return v1JobDeprecations{
+		getJob: apiDeprecation{
+			Route:  "GET /api/v1/jobs/{job_id}",
+			Since:  v1JobsDeprecatedAt,
+			Sunset: sunset,
+			Successor: func(r *http.Request) string {
+				return v2.Path("/jobs/" + GetIDFromContext(r, "job_id"))
+			},
+		},
+		listJobs: apiDeprecation{
+			Route:  "GET /api/v1/jobs",
+			Since:  v1JobsDeprecatedAt,
+			Sunset: sunset,
+			Successor: func(r *http.Request) string {
+				successor := v2.Path("/jobs")
+				if r.URL.RawQuery != "" {
+					successor += "?" + r.URL.RawQuery
+				}
+				return successor
+			},
+		},
+	}
+}
+
+// registerV2Routes registers the /api/v2 routes. middleware is the
+// transaction and auth chain the v1 routes run behind.
+func registerV2Routes(v2 apiRoutes, jobHandler *JobHandler, middleware func(http.Handler) http.Handler) {
+	v2.HandleFunc("/jobs", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		middleware(http.HandlerFunc(jobHandler.ListJobsV2)).ServeHTTP(w, r)
+	})
+
+	v2.HandleFunc("/jobs/", func(w http.ResponseWriter, r *http.Request) {
+		jobID := strings.TrimPrefix(r.URL.Path, v2.Path("/jobs/"))
+		if jobID == "" {
+			http.Error(w, "Invalid path", http.StatusBadRequest)
+			return
+		}
+		// The job's other routes haven't changed shape and are only
+		// served under /api/v1.
+		if strings.Contains(jobID, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		r = r.WithContext(setIDContext(r.Context(), "job_id", jobID))
+		middleware(http.HandlerFunc(jobHandler.GetJobV2)).ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// v1JobResponseKeys are the JSON keys of a v1 job that workers and scripts
+// read. Adding keys to JobResponse is compatible; renaming or removing any
+// of these is not, and belongs in JobResponseV2.
+var v1JobResponseKeys = []string{
+	"job_id", "name", "description", "job_file", "status", "last_error",
+	"created_at", "updated_at", "source_url", "source_ref", "source_type",
+	"source_path", "ci_source_type", "ci_source_url", "ci_source_ref",
+	"code_dir", "job_dir", "job_command", "runner_image", "job_env_vars",
+	"job_env_file", "run_as_user", "target_os", "target_arch", "affinity",
+	"avoid_workers", "worker_id", "environment", "freeze_window_id", "scan",
+	"coalesced_shas", "timeout_seconds", "priority", "queue_name",
+	"started_at", "completed_at", "exit_code", "deadline",
+	"deadline_missed_at", "logs_object_key", "artifacts_object_key",
+	"project_id", "parent_job_id", "workflow_id", "workflow_node_id",
+	"workflow_run_id", "workflow_node_name", "trigger_validation",
+	"gate_results", "resources", "await_children", "debug_on_failure",
+	"upstream_job_id", "downstream_job_ids", "archived",
+}
+
+func TestJobResponseV1Compatibility(t *testing.T) {
+	keys := map[string]bool{}
+	typ := reflect.TypeOf(JobResponse{})
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		keys[name] = true
+	}
+	for _, key := range v1JobResponseKeys {
+		assert.True(t, keys[key], "v1 job key %q was renamed or removed", key)
+	}
+}
+
+// nonZero returns a non-zero value of typ.
+func nonZero(t *testing.T, typ reflect.Type) reflect.Value {
+	v := reflect.New(typ).Elem()
+	switch typ.Kind() {
+	case reflect.String:
+		v.SetString("x")
+	case reflect.Int, reflect.Int64:
+		v.SetInt(1)
+	case reflect.Uint64:
+		v.SetUint(1)
+	case reflect.Float64:
+		v.SetFloat(1)
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Ptr:
+		v.Set(reflect.New(typ.Elem()))
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(typ, 1, 1))
+		v.Index(0).Set(nonZero(t, typ.Elem()))
+	case reflect.Map:
+		v.Set(reflect.MakeMap(typ))
+		v.SetMapIndex(nonZero(t, typ.Key()), reflect.ValueOf("x"))
+	case reflect.Interface:
+		v.Set(reflect.ValueOf("x"))
+	case reflect.Struct:
+		if typ == reflect.TypeOf(time.Time{}) {
+			v.Set(reflect.ValueOf(time.Unix(1, 0).UTC()))
+			break
+		}
+		for i := 0; i < typ.NumField(); i++ {
+			if typ.Field(i).IsExported() {
+				v.Field(i).Set(nonZero(t, typ.Field(i).Type))
+			}
+		}
+	default:
+		t.Fatalf("nonZero: unhandled kind %s", typ.Kind())
+	}
+	return v
+}
+
+func TestJobResponseV2CarriesEveryV1Field(t *testing.T) {
+	empty, err := json.Marshal(jobResponseV2(JobResponse{}))
+	require.NoError(t, err)
+
+	typ := reflect.TypeOf(JobResponse{})
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		var v1 JobResponse
+		reflect.ValueOf(&v1).Elem().Field(i).Set(nonZero(t, field.Type))
+		v2, err := json.Marshal(jobResponseV2(v1))
+		require.NoError(t, err)
+		assert.NotEqual(t, string(empty), string(v2), "JobResponse.%s is dropped from JobResponseV2", field.Name)
+	}
+}
+
+func TestAPIVersionRoutes(t *testing.T) {
+	owner := &models.User{UserID: "owner", Roles: []string{"user"}}
+	exitCode := 1
+	sourceType := models.SourceTypeGit
+	job := models.Job{
+		JobID:       "job-1",
+		UserID:      "owner",
+		Name:        "build",
+		Status:      "failed",
+		LastError:   "exit status 1",
+		SourceType:  &sourceType,
+		SourceURL:   strPtrH("https://example.com/repo.git"),
+		QueueName:   "reactorcide-jobs",
+		ExitCode:    &exitCode,
+		CISourceURL: strPtrH("https://example.com/ci.git"),
+	}
+	ms := &MockStore{
+		GetJobByIDFunc: func(ctx context.Context, jobID string) (*models.Job, error) {
+			if jobID == job.JobID {
+				return &job, nil
+			}
+			return nil, store.ErrNotFound
+		},
+		ListJobsFunc: func(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]models.Job, error) {
+			if _, ok := filters["upstream_job_id"]; ok {
+				return nil, nil
+			}
+			return []models.Job{job}, nil
+		},
+	}
+	jobHandler := NewJobHandler(ms, nil)
+
+	sunset := time.Date(2027, time.April, 1, 0, 0, 0, 0, time.UTC)
+	deprecations := newV1JobDeprecations(sunset)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/jobs", deprecations.listJobs.wrap(jobHandler.ListJobs))
+	mux.HandleFunc("/api/v1/jobs/", func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(setIDContext(r.Context(), "job_id", strings.TrimPrefix(r.URL.Path, "/api/v1/jobs/")))
+		deprecations.getJob.wrap(jobHandler.GetJob)(w, r)
+	})
+	registerV2Routes(apiRoutes{mux: mux, version: APIVersion2}, jobHandler, func(next http.Handler) http.Handler {
+		return next
+	})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req = req.WithContext(checkauth.SetUserContext(req.Context(), owner))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("v1 job is deprecated and unchanged", func(t *testing.T) {
+		w := get("/api/v1/jobs/job-1")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "@1792281600", w.Header().Get(DeprecationHeader))
+		assert.Equal(t, "Thu, 01 Apr 2027 00:00:00 GMT", w.Header().Get(SunsetHeader))
+		assert.Equal(t, `</api/v2/jobs/job-1>; rel="successor-version"`, w.Header().Get(LinkHeader))
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+		assert.Equal(t, "exit status 1", body["last_error"])
+		assert.Equal(t, "reactorcide-jobs", body["queue_name"])
+		assert.Equal(t, "https://example.com/repo.git", body["source_url"])
+	})
+
+	t.Run("v2 job is v1 regrouped", func(t *testing.T) {
+		v1 := get("/api/v1/jobs/job-1")
+		var v1Job JobResponse
+		require.NoError(t, json.NewDecoder(v1.Body).Decode(&v1Job))
+
+		w := get("/api/v2/jobs/job-1")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Empty(t, w.Header().Get(DeprecationHeader))
+		assert.Empty(t, w.Header().Get(SunsetHeader))
+
+		var v2Job JobResponseV2
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&v2Job))
+		assert.Equal(t, jobResponseV2(v1Job), v2Job)
+		assert.Equal(t, "exit status 1", v2Job.Error)
+		assert.Equal(t, "reactorcide-jobs", v2Job.Schedule.Queue)
+		assert.Equal(t, "https://example.com/repo.git", v2Job.Source.URL)
+		require.NotNil(t, v2Job.CISource)
+		assert.Equal(t, "https://example.com/ci.git", v2Job.CISource.URL)
+		require.NotNil(t, v2Job.Result.ExitCode)
+		assert.Equal(t, 1, *v2Job.Result.ExitCode)
+	})
+
+	t.Run("job lists", func(t *testing.T) {
+		w := get("/api/v1/jobs?status=failed")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, `</api/v2/jobs?status=failed>; rel="successor-version"`, w.Header().Get(LinkHeader))
+		var v1List ListJobsResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&v1List))
+		require.Len(t, v1List.Jobs, 1)
+
+		w = get("/api/v2/jobs?status=failed")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Empty(t, w.Header().Get(DeprecationHeader))
+		var v2List ListJobsResponseV2
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&v2List))
+		require.Len(t, v2List.Jobs, 1)
+		assert.Equal(t, jobResponseV2(v1List.Jobs[0]), v2List.Jobs[0])
+		assert.Equal(t, v1List.Total, v2List.Total)
+		assert.Equal(t, v1List.Limit, v2List.Limit)
+	})
+
+	t.Run("v2 serves only job reads", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, get("/api/v2/jobs/job-1/logs").Code)
+		req := httptest.NewRequest(http.MethodDelete, "/api/v2/jobs/job-1", nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+
+	t.Run("no sunset until scheduled", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		newV1JobDeprecations(time.Time{}).listJobs.wrap(func(w http.ResponseWriter, r *http.Request) {})(w, httptest.NewRequest(http.MethodGet, "/api/v1/jobs", nil))
+		assert.NotEmpty(t, w.Header().Get(DeprecationHeader))
+		assert.Empty(t, w.Header().Get(SunsetHeader))
+	})
+}
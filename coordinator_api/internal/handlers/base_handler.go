@@ -1,11 +1,17 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"log"
 	"net/http"
 
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/admission"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/hooks"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/worker"
 )
 
 // ErrorResponse represents a standard error response
@@ -14,6 +20,14 @@ type ErrorResponse struct {
 	Message string `json:"message,omitempty"`
 }
 
+// PolicyDeniedResponse is the 403 body when an admission policy rejects a
+// request: every "deny" rule it broke.
+type PolicyDeniedResponse struct {
+	Error      string                   `json:"error"`
+	Message    string                   `json:"message"`
+	Violations []models.PolicyViolation `json:"violations"`
+}
+
 // BaseHandler provides common functionality for all handlers
 type BaseHandler struct{}
 
@@ -49,6 +63,14 @@ func (h *BaseHandler) respondWithError(w http.ResponseWriter, code int, err erro
 		errType = "already_exists"
 		message = "Resource already exists"
 		code = http.StatusConflict
+	case errors.Is(err, store.ErrReferenceViolation):
+		errType = "reference_violation"
+		message = "A referenced resource does not exist or is still in use"
+		code = http.StatusConflict
+	case errors.Is(err, store.ErrConflict):
+		errType = "conflict"
+		message = "Request conflicted with a concurrent update; retry it"
+		code = http.StatusConflict
 	case errors.Is(err, store.ErrForbidden):
 		errType = "forbidden"
 		message = "Permission denied"
@@ -57,6 +79,10 @@ func (h *BaseHandler) respondWithError(w http.ResponseWriter, code int, err erro
 		errType = "unauthorized"
 		message = "Unauthorized"
 		code = http.StatusUnauthorized
+	case errors.Is(err, context.DeadlineExceeded):
+		errType = "timeout"
+		message = "Request timed out"
+		code = http.StatusServiceUnavailable
 	case errors.Is(err, store.ErrServiceUnavailable):
 		errType = "service_unavailable"
 		message = "Service temporarily unavailable"
@@ -77,3 +103,91 @@ func (h *BaseHandler) respondWithError(w http.ResponseWriter, code int, err erro
 func (h *BaseHandler) getID(r *http.Request, key string) string {
 	return GetIDFromContext(r, key)
 }
+
+// decodeJSON decodes the request body into v, responding 413 if the body is
+// over the request body limit (see limitRequestBody) and 400 if it's not
+// valid JSON. It reports whether decoding succeeded.
+func (h *BaseHandler) decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err == nil {
+		return true
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		respondPayloadTooLarge(w, tooLarge.Limit)
+		return false
+	}
+	var envErr *models.EnvVarError
+	if errors.As(err, &envErr) {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_input",
+			Message: envErr.Error(),
+		})
+		return false
+	}
+	h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+	return false
+}
+
+// checkJobHooks runs the pre_job_create hooks for job, created from
+// source, responding 403 with the reason if one rejects it. It reports
+// whether the job may be created.
+func (h *BaseHandler) checkJobHooks(w http.ResponseWriter, r *http.Request, job *models.Job, source string) bool {
+	err := hooks.CheckJobCreate(r.Context(), job, source)
+	if err == nil {
+		return true
+	}
+	if h.respondPolicyDenied(w, err) {
+		return false
+	}
+	h.respondWithJSON(w, http.StatusForbidden, ErrorResponse{
+		Error:   "forbidden",
+		Message: err.Error(),
+	})
+	return false
+}
+
+// checkAdmission checks subject against the admission policies for target
+// in projectID, responding 403 with the violations if it breaks a "deny"
+// policy. It reports whether the request may go ahead.
+func (h *BaseHandler) checkAdmission(w http.ResponseWriter, r *http.Request, st store.Store, target, projectID string, subject interface{}) bool {
+	err := admission.Check(r.Context(), st, target, projectID, subject)
+	if err == nil {
+		return true
+	}
+	if !h.respondPolicyDenied(w, err) {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+	}
+	return false
+}
+
+// respondPolicyDenied responds 403 with the violations if err is an
+// admission policy denial, and reports whether it was.
+func (h *BaseHandler) respondPolicyDenied(w http.ResponseWriter, err error) bool {
+	var denied *admission.DeniedError
+	if !errors.As(err, &denied) {
+		return false
+	}
+	h.respondWithJSON(w, http.StatusForbidden, PolicyDeniedResponse{
+		Error:      "policy_denied",
+		Message:    denied.Error(),
+		Violations: denied.Violations,
+	})
+	return true
+}
+
+// checkSourceURLs checks rawURLs against the source URL policy (see
+// worker.SourceURLPolicy), responding 403 with the reason if it rejects
+// one. It reports whether every URL is allowed.
+func (h *BaseHandler) checkSourceURLs(w http.ResponseWriter, r *http.Request, st store.Store, rawURLs ...string) bool {
+	err := worker.CheckSourceURLs(r.Context(), st, rawURLs...)
+	if err == nil {
+		return true
+	}
+	log.Printf("SECURITY: %v", err)
+	h.respondWithJSON(w, http.StatusForbidden, ErrorResponse{
+		Error:   "forbidden",
+		Message: err.Error(),
+	})
+	return false
+}
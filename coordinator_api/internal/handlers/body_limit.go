@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/worker"
+)
+
+// PayloadTooLargeResponse is the 413 response for a request body over the
+// limit.
+type PayloadTooLargeResponse struct {
+	Error    string `json:"error"`
+	Message  string `json:"message"`
+	MaxBytes int64  `json:"max_bytes"`
+}
+
+// PayloadLimitErrorResponse is the 400 response for fields over the
+// payload limits (see worker.CheckJobPayloadLimits).
+type PayloadLimitErrorResponse struct {
+	Error   string                      `json:"error"`
+	Message string                      `json:"message"`
+	Limits  []*worker.PayloadLimitError `json:"limits"`
+}
+
+// limitRequestBody caps request bodies at config.MaxRequestBodyBytes, or
+// config.MaxWebhookBodyBytes for webhooks and config.MaxSourceUploadBodyBytes
+// for source uploads. A request that declares a larger
+// Content-Length is rejected up front; otherwise reading past the limit
+// fails with *http.MaxBytesError, which decodeJSON turns into a 413. Log
+// chunk uploads enforce their own limit.
+func limitRequestBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit := requestBodyLimit(r.URL.Path)
+		if limit <= 0 || r.Body == nil || r.Body == http.NoBody {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if r.ContentLength > limit {
+			respondPayloadTooLarge(w, limit)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestBodyLimit returns the body limit for a request path; zero means
+// none.
+func requestBodyLimit(path string) int64 {
+	switch {
+	case strings.Contains(path, "/logs/chunks/"):
+		return 0
+	case strings.HasPrefix(path, "/api/v1/webhooks/"):
+		return int64(config.MaxWebhookBodyBytes)
+	case path == SourceUploadPath:
+		return int64(config.MaxSourceUploadBodyBytes)
+	default:
+		return int64(config.MaxRequestBodyBytes)
+	}
+}
+
+// checkPayloadLimits responds 400 listing limitErrs, if there are any. It
+// reports whether the payload is within its limits.
+func (h *BaseHandler) checkPayloadLimits(w http.ResponseWriter, limitErrs []*worker.PayloadLimitError) bool {
+	if len(limitErrs) == 0 {
+		return true
+	}
+	h.respondWithJSON(w, http.StatusBadRequest, PayloadLimitErrorResponse{
+		Error:   "invalid_input",
+		Message: "Request exceeds payload limits",
+		Limits:  limitErrs,
+	})
+	return false
+}
+
+// respondPayloadTooLarge writes the structured 413 response.
+func respondPayloadTooLarge(w http.ResponseWriter, limit int64) {
+	(&BaseHandler{}).respondWithJSON(w, http.StatusRequestEntityTooLarge, PayloadTooLargeResponse{
+		Error:    "payload_too_large",
+		Message:  fmt.Sprintf("Request body is larger than the limit of %d bytes", limit),
+		MaxBytes: limit,
+	})
+}
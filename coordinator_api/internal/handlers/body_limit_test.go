@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
+)
+
+func withBodyLimits(t *testing.T, request, webhook int) {
+	oldRequest, oldWebhook := config.MaxRequestBodyBytes, config.MaxWebhookBodyBytes
+	config.MaxRequestBodyBytes, config.MaxWebhookBodyBytes = request, webhook
+	t.Cleanup(func() { config.MaxRequestBodyBytes, config.MaxWebhookBodyBytes = oldRequest, oldWebhook })
+}
+
+func TestLimitRequestBody(t *testing.T) {
+	withBodyLimits(t, 16, 64)
+	h := &BaseHandler{}
+	handler := limitRequestBody(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		if h.decodeJSON(w, r, &req) {
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+
+	tests := []struct {
+		name          string
+		path          string
+		body          string
+		unknownLength bool
+		expected      int
+	}{
+		{name: "within limit", path: "/api/v1/projects", body: `{"a":"b"}`, expected: http.StatusNoContent},
+		{name: "declared length over limit", path: "/api/v1/projects", body: `{"a":"` + strings.Repeat("b", 32) + `"}`, expected: http.StatusRequestEntityTooLarge},
+		{name: "streamed body over limit", path: "/api/v1/projects", body: `{"a":"` + strings.Repeat("b", 32) + `"}`, unknownLength: true, expected: http.StatusRequestEntityTooLarge},
+		{name: "webhooks get their own limit", path: "/api/v1/webhooks/github", body: `{"a":"` + strings.Repeat("b", 32) + `"}`, expected: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			if tt.unknownLength {
+				req.ContentLength = -1
+				req.Body = io.NopCloser(req.Body)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			require.Equal(t, tt.expected, rr.Code, rr.Body.String())
+
+			if tt.expected == http.StatusRequestEntityTooLarge {
+				var resp PayloadTooLargeResponse
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+				assert.Equal(t, "payload_too_large", resp.Error)
+				assert.EqualValues(t, 16, resp.MaxBytes)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// canaryStore is the narrow store capability behind the project canary
+// comparison. See postgres_store/canary_operations.go.
+type canaryStore interface {
+	CompareCanaryJobs(ctx context.Context, projectID string, since time.Time) (canary, baseline models.JobRunStats, err error)
+}
+
+// CanaryRequest is the JSON body of PUT /api/v1/projects/{project_id}/canary.
+type CanaryRequest struct {
+	Percent     int    `json:"percent"`
+	RunnerImage string `json:"runner_image,omitempty"`
+	QueueName   string `json:"queue_name,omitempty"`
+}
+
+// CanaryResponse is a project's canary and how its jobs compare with the
+// project's other jobs since it started. Both are nil without a canary.
+type CanaryResponse struct {
+	Canary     *models.ProjectCanary    `json:"canary"`
+	Comparison *models.CanaryComparison `json:"comparison,omitempty"`
+}
+
+// canaryProject loads the project a canary endpoint is for. With manage
+// set, the user must be able to manage the project.
+func (h *ProjectHandler) canaryProject(w http.ResponseWriter, r *http.Request, manage bool) (*models.Project, *models.User, bool) {
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return nil, nil, false
+	}
+	project, _, ok := h.projectAndOwner(w, r, user.UserID)
+	if !ok {
+		return nil, nil, false
+	}
+	if manage && !h.canManageProject(r.Context(), user, project) {
+		h.respondWithError(w, http.StatusForbidden, store.ErrForbidden)
+		return nil, nil, false
+	}
+	return project, user, true
+}
+
+// GetCanary handles GET /api/v1/projects/{project_id}/canary: the project's
+// canary and how its jobs compare with the project's other jobs since it
+// started.
+func (h *ProjectHandler) GetCanary(w http.ResponseWriter, r *http.Request) {
+	project, _, ok := h.canaryProject(w, r, false)
+	if !ok {
+		return
+	}
+	response := CanaryResponse{Canary: project.Canary}
+	if project.Canary != nil {
+		cs, ok := h.store.(canaryStore)
+		if !ok {
+			h.respondWithError(w, http.StatusNotImplemented, errors.New("canary comparison not available"))
+			return
+		}
+		canary, baseline, err := cs.CompareCanaryJobs(r.Context(), project.ProjectID, project.Canary.StartedAt)
+		if err != nil {
+			h.respondWithError(w, http.StatusInternalServerError, err)
+			return
+		}
+		comparison := models.NewCanaryComparison(canary, baseline)
+		response.Comparison = &comparison
+	}
+	h.respondWithJSON(w, http.StatusOK, response)
+}
+
+// SetCanary handles PUT /api/v1/projects/{project_id}/canary, e.g.
+// {"percent": 10, "runner_image": "runner:2.0"}, starting the project's
+// canary or replacing it. Changing only the percentage keeps the canary's
+// start, and so its comparison; changing its image or queue restarts it.
+func (h *ProjectHandler) SetCanary(w http.ResponseWriter, r *http.Request) {
+	project, user, ok := h.canaryProject(w, r, true)
+	if !ok {
+		return
+	}
+	var req CanaryRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	canary := &models.ProjectCanary{
+		Percent:     req.Percent,
+		RunnerImage: strings.TrimSpace(req.RunnerImage),
+		QueueName:   strings.TrimSpace(req.QueueName),
+		StartedAt:   time.Now().UTC(),
+		StartedBy:   &user.UserID,
+	}
+	if err := canary.Validate(project); err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+		return
+	}
+	if old := project.Canary; old != nil && old.RunnerImage == canary.RunnerImage && old.QueueName == canary.QueueName {
+		canary.StartedAt = old.StartedAt
+		canary.StartedBy = old.StartedBy
+	}
+	project.Canary = canary
+	if !h.saveCanaryProject(w, r, project) {
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, CanaryResponse{Canary: canary})
+}
+
+// DeleteCanary handles DELETE /api/v1/projects/{project_id}/canary,
+// aborting the project's canary: new jobs use the project's defaults again.
+func (h *ProjectHandler) DeleteCanary(w http.ResponseWriter, r *http.Request) {
+	project, _, ok := h.canaryProject(w, r, true)
+	if !ok {
+		return
+	}
+	if project.Canary == nil {
+		h.respondWithError(w, http.StatusNotFound, store.ErrNotFound)
+		return
+	}
+	project.Canary = nil
+	if !h.saveCanaryProject(w, r, project) {
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PromoteCanary handles POST /api/v1/projects/{project_id}/canary/promote,
+// rolling the canary out to every job: its runner image and queue become
+// the project's defaults, overriding its group's, and the canary ends.
+func (h *ProjectHandler) PromoteCanary(w http.ResponseWriter, r *http.Request) {
+	project, _, ok := h.canaryProject(w, r, true)
+	if !ok {
+		return
+	}
+	canary := project.Canary
+	if canary == nil {
+		h.respondWithError(w, http.StatusNotFound, store.ErrNotFound)
+		return
+	}
+	if canary.RunnerImage != "" {
+		project.DefaultRunnerImage = canary.RunnerImage
+	}
+	if canary.QueueName != "" {
+		project.DefaultQueueName = canary.QueueName
+	}
+	trackGroupOverrides(project, map[string]bool{
+		models.ProjectFieldRunnerImage: canary.RunnerImage != "",
+		models.ProjectFieldQueueName:   canary.QueueName != "",
+	}, nil)
+	project.Canary = nil
+	if !h.saveCanaryProject(w, r, project) {
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, projectToResponse(h.withGroupDefaults(r.Context(), project)))
+}
+
+// saveCanaryProject saves a project whose canary changed, subject to the
+// project admission policies.
+func (h *ProjectHandler) saveCanaryProject(w http.ResponseWriter, r *http.Request, project *models.Project) bool {
+	if !h.checkAdmission(w, r, h.store, models.PolicyTargetProject, project.ProjectID, projectToResponse(project)) {
+		return false
+	}
+	if err := h.store.UpdateProject(r.Context(), project); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return false
+	}
+	return true
+}
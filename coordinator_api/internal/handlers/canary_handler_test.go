@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// canaryMockStore keeps one project in memory on top of ProjectMockStore,
+// with fixed canary and baseline stats.
+type canaryMockStore struct {
+	ProjectMockStore
+	project          models.Project
+	canary, baseline models.JobRunStats
+	since            time.Time
+}
+
+func (m *canaryMockStore) CompareCanaryJobs(ctx context.Context, projectID string, since time.Time) (models.JobRunStats, models.JobRunStats, error) {
+	m.since = since
+	return m.canary, m.baseline, nil
+}
+
+func canaryRequest(method, path, body string) *http.Request {
+	r := httptest.NewRequest(method, "/api/v1/projects/project-1/canary"+path, strings.NewReader(body))
+	return withUser(r.WithContext(setIDContext(r.Context(), "project_id", "project-1")))
+}
+
+func TestCanaryHandler(t *testing.T) {
+	owner := "test-user-id"
+	s := &canaryMockStore{
+		project:  models.Project{ProjectID: "project-1", UserID: &owner, DefaultRunnerImage: "runner:1", DefaultQueueName: "reactorcide-jobs"},
+		canary:   models.JobRunStats{Runs: 20, Succeeded: 12, Failed: 8, FailureRate: 0.4},
+		baseline: models.JobRunStats{Runs: 100, Succeeded: 95, Failed: 5, FailureRate: 0.05},
+	}
+	s.GetProjectByIDFunc = func(ctx context.Context, projectID string) (*models.Project, error) {
+		project := s.project
+		return &project, nil
+	}
+	s.UpdateProjectFunc = func(ctx context.Context, project *models.Project) error {
+		s.project = *project
+		return nil
+	}
+	h := NewProjectHandler(s)
+	set := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.SetCanary(w, canaryRequest(http.MethodPut, "", body))
+		return w
+	}
+
+	assert.Equal(t, http.StatusBadRequest, set(`{"percent":0,"runner_image":"runner:2"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, set(`{"percent":10}`).Code)
+	assert.Equal(t, http.StatusBadRequest, set(`{"percent":10,"runner_image":"runner:1"}`).Code)
+
+	w := set(`{"percent":10,"runner_image":" runner:2 "}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NotNil(t, s.project.Canary)
+	assert.Equal(t, "runner:2", s.project.Canary.RunnerImage)
+	assert.Equal(t, owner, *s.project.Canary.StartedBy)
+	started := s.project.Canary.StartedAt
+
+	t.Run("changing the percentage keeps the start", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, set(`{"percent":25,"runner_image":"runner:2"}`).Code)
+		assert.Equal(t, 25, s.project.Canary.Percent)
+		assert.Equal(t, started, s.project.Canary.StartedAt)
+	})
+
+	t.Run("the comparison covers jobs since the start", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.GetCanary(w, canaryRequest(http.MethodGet, "", ""))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response CanaryResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		require.NotNil(t, response.Comparison)
+		assert.Equal(t, models.CanaryRegressed, response.Comparison.Verdict)
+		assert.Equal(t, 20, response.Comparison.Canary.Runs)
+		assert.True(t, s.since.Equal(started))
+	})
+
+	t.Run("promoting makes the canary the default", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.PromoteCanary(w, canaryRequest(http.MethodPost, "/promote", ""))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "runner:2", s.project.DefaultRunnerImage)
+		assert.Equal(t, "reactorcide-jobs", s.project.DefaultQueueName)
+		assert.Nil(t, s.project.Canary)
+
+		w = httptest.NewRecorder()
+		h.PromoteCanary(w, canaryRequest(http.MethodPost, "/promote", ""))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("deleting aborts the canary", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, set(`{"percent":5,"queue_name":"canary-workers"}`).Code)
+		w := httptest.NewRecorder()
+		h.DeleteCanary(w, canaryRequest(http.MethodDelete, "", ""))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Nil(t, s.project.Canary)
+		assert.Equal(t, "reactorcide-jobs", s.project.DefaultQueueName)
+	})
+}
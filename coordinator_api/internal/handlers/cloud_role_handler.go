@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/secrets"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+type projectCloudRoleStore interface {
+	CreateProjectCloudRole(ctx context.Context, role *models.ProjectCloudRole) error
+	ListProjectCloudRoles(ctx context.Context, projectID string) ([]models.ProjectCloudRole, error)
+	GetProjectCloudRole(ctx context.Context, projectID, ref string) (*models.ProjectCloudRole, error)
+	UpdateProjectCloudRole(ctx context.Context, role *models.ProjectCloudRole) error
+	DeleteProjectCloudRole(ctx context.Context, projectID, ref string) error
+}
+
+// CloudRoleRequest is the body of POST and PUT
+// /api/v1/projects/{project_id}/cloud-roles. On update, empty fields keep
+// their current value.
+type CloudRoleRequest struct {
+	Name            string   `json:"name,omitempty"`
+	Provider        string   `json:"provider,omitempty"`
+	Role            string   `json:"role,omitempty"`
+	JobNameMatch    string   `json:"job_name_match,omitempty"`
+	JobNamePattern  string   `json:"job_name_pattern,omitempty"`
+	DurationSeconds int      `json:"duration_seconds,omitempty"`
+	Region          string   `json:"region,omitempty"`
+	ExternalID      string   `json:"external_id,omitempty"`
+	Scopes          []string `json:"scopes,omitempty"`
+	EnvPrefix       string   `json:"env_prefix,omitempty"`
+	Description     string   `json:"description,omitempty"`
+}
+
+type ListCloudRolesResponse struct {
+	Roles []models.ProjectCloudRole `json:"roles"`
+	Total int                       `json:"total"`
+}
+
+// ListCloudRoles handles GET /api/v1/projects/{project_id}/cloud-roles
+func (h *ProjectHandler) ListCloudRoles(w http.ResponseWriter, r *http.Request) {
+	roleStore, project, ok := h.cloudRoleScope(w, r)
+	if !ok {
+		return
+	}
+	roles, err := roleStore.ListProjectCloudRoles(r.Context(), project.ProjectID)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, ListCloudRolesResponse{Roles: roles, Total: len(roles)})
+}
+
+// CreateCloudRole handles POST /api/v1/projects/{project_id}/cloud-roles
+func (h *ProjectHandler) CreateCloudRole(w http.ResponseWriter, r *http.Request) {
+	roleStore, project, ok := h.cloudRoleScope(w, r)
+	if !ok {
+		return
+	}
+	var req CloudRoleRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	role := &models.ProjectCloudRole{ProjectID: project.ProjectID}
+	if !h.applyCloudRoleRequest(w, role, req) {
+		return
+	}
+	if existing, err := roleStore.GetProjectCloudRole(r.Context(), project.ProjectID, role.Name); err == nil && existing != nil {
+		h.respondWithError(w, http.StatusConflict, store.ErrAlreadyExists)
+		return
+	}
+	if err := roleStore.CreateProjectCloudRole(r.Context(), role); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusCreated, role)
+}
+
+// GetCloudRole handles GET /api/v1/projects/{project_id}/cloud-roles/{name-or-id}
+func (h *ProjectHandler) GetCloudRole(w http.ResponseWriter, r *http.Request) {
+	roleStore, project, ok := h.cloudRoleScope(w, r)
+	if !ok {
+		return
+	}
+	role, err := roleStore.GetProjectCloudRole(r.Context(), project.ProjectID, h.getID(r, "role_id"))
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, role)
+}
+
+// UpdateCloudRole handles PUT /api/v1/projects/{project_id}/cloud-roles/{name-or-id}
+func (h *ProjectHandler) UpdateCloudRole(w http.ResponseWriter, r *http.Request) {
+	roleStore, project, ok := h.cloudRoleScope(w, r)
+	if !ok {
+		return
+	}
+	var req CloudRoleRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	role, err := roleStore.GetProjectCloudRole(r.Context(), project.ProjectID, h.getID(r, "role_id"))
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return
+	}
+	if !h.applyCloudRoleRequest(w, role, req) {
+		return
+	}
+	if err := roleStore.UpdateProjectCloudRole(r.Context(), role); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, role)
+}
+
+// DeleteCloudRole handles DELETE /api/v1/projects/{project_id}/cloud-roles/{name-or-id}
+func (h *ProjectHandler) DeleteCloudRole(w http.ResponseWriter, r *http.Request) {
+	roleStore, project, ok := h.cloudRoleScope(w, r)
+	if !ok {
+		return
+	}
+	if err := roleStore.DeleteProjectCloudRole(r.Context(), project.ProjectID, h.getID(r, "role_id")); err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ProjectHandler) cloudRoleScope(w http.ResponseWriter, r *http.Request) (projectCloudRoleStore, *models.Project, bool) {
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return nil, nil, false
+	}
+	roleStore, ok := h.store.(projectCloudRoleStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("cloud role store not available"))
+		return nil, nil, false
+	}
+	project, _, ok := h.projectAndOwner(w, r, user.UserID)
+	if !ok {
+		return nil, nil, false
+	}
+	return roleStore, project, true
+}
+
+// applyCloudRoleRequest applies req to role and validates the result,
+// responding 400 if it's invalid.
+func (h *ProjectHandler) applyCloudRoleRequest(w http.ResponseWriter, role *models.ProjectCloudRole, req CloudRoleRequest) bool {
+	set := func(dst *string, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			*dst = value
+		}
+	}
+	set(&role.Name, req.Name)
+	set(&role.Provider, req.Provider)
+	set(&role.Role, req.Role)
+	set(&role.JobNameMatch, req.JobNameMatch)
+	set(&role.JobNamePattern, req.JobNamePattern)
+	set(&role.Region, req.Region)
+	set(&role.ExternalID, req.ExternalID)
+	set(&role.EnvPrefix, req.EnvPrefix)
+	set(&role.Description, req.Description)
+	if req.DurationSeconds != 0 {
+		role.DurationSeconds = req.DurationSeconds
+	}
+	if req.Scopes != nil {
+		role.Scopes = req.Scopes
+	}
+
+	if role.JobNameMatch == "" {
+		role.JobNameMatch = models.SecretGrantMatchAny
+	}
+	if role.JobNameMatch == models.SecretGrantMatchAny {
+		role.JobNamePattern = ""
+	}
+	err := secrets.ValidateCloudRole(role)
+	if err == nil && role.JobNameMatch != models.SecretGrantMatchAny && role.JobNamePattern == "" {
+		err = errors.New("job_name_pattern is required when job_name_match is not any")
+	}
+	if err == nil && !validSecretGrantMatch(role.JobNameMatch, true) {
+		err = errors.New("invalid job_name_match " + role.JobNameMatch)
+	}
+	if err == nil {
+		err = validateSecretGrantPattern(role.JobNameMatch, role.JobNamePattern)
+	}
+	if err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_input",
+			Message: err.Error(),
+		})
+		return false
+	}
+	return true
+}
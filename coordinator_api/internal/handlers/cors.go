@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
+	"github.com/rs/cors"
+)
+
+// alwaysExposedHeaders are the response headers browser clients always
+// need to read: the maintenance banner, announcements and deprecations.
+var alwaysExposedHeaders = []string{
+	MaintenanceBannerHeader,
+	AnnouncementHeader,
+	AnnouncementSeverityHeader,
+	DeprecationHeader,
+	SunsetHeader,
+	LinkHeader,
+}
+
+// corsPolicy is the API's CORS configuration (see config/config_cors.go).
+// Routes override Origins for the paths under their prefix.
+type corsPolicy struct {
+	Origins          []string
+	Methods          []string
+	Headers          []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           int
+	Routes           []config.CORSRoute
+}
+
+// corsPolicyFromConfig reads the CORS policy from the REACTORCIDE_CORS_*
+// settings. Invalid settings are logged and leave cross-origin calls
+// blocked, rather than open; Serve refuses to start with them (see
+// config.ValidateCORS).
+func corsPolicyFromConfig() corsPolicy {
+	policy := corsPolicy{
+		Origins:          config.SplitCommaList(config.CORSAllowedOrigins),
+		Methods:          config.SplitCommaList(strings.ToUpper(config.CORSAllowedMethods)),
+		Headers:          config.SplitCommaList(config.CORSAllowedHeaders),
+		ExposedHeaders:   append(append([]string{}, alwaysExposedHeaders...), config.SplitCommaList(config.CORSExposedHeaders)...),
+		AllowCredentials: config.CORSAllowCredentials,
+		MaxAge:           config.CORSMaxAgeSeconds,
+	}
+	if err := config.ValidateCORS(); err != nil {
+		log.Printf("WARNING: invalid CORS configuration, blocking cross-origin requests: %v", err)
+		policy.Origins = nil
+		return policy
+	}
+	policy.Routes, _ = config.ParseCORSRoutes(config.CORSRoutes)
+	return policy
+}
+
+// corsRouteHandler is the CORS handling of the paths under prefix.
+type corsRouteHandler struct {
+	prefix  string
+	handler http.Handler
+}
+
+// newCORSHandler applies policy to next's responses: each request gets
+// the CORS headers of the longest route prefix its path matches, or of
+// policy.Origins if none does. Origins that aren't allowed get no CORS
+// headers, so browsers keep their scripts from reading the response.
+func newCORSHandler(policy corsPolicy, next http.Handler) http.Handler {
+	fallback := policy.cors(policy.Origins).Handler(next)
+	routes := make([]corsRouteHandler, 0, len(policy.Routes))
+	for _, route := range policy.Routes {
+		routes = append(routes, corsRouteHandler{prefix: route.Prefix, handler: policy.cors(route.Origins).Handler(next)})
+	}
+	sort.SliceStable(routes, func(i, j int) bool { return len(routes[i].prefix) > len(routes[j].prefix) })
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, route := range routes {
+			if strings.HasPrefix(r.URL.Path, route.prefix) {
+				route.handler.ServeHTTP(w, r)
+				return
+			}
+		}
+		fallback.ServeHTTP(w, r)
+	})
+}
+
+// cors returns the policy's CORS handling for origins. No origins allows
+// none, where rs/cors would take it to mean any.
+func (p corsPolicy) cors(origins []string) *cors.Cors {
+	options := cors.Options{
+		AllowedOrigins:   origins,
+		AllowedMethods:   p.Methods,
+		AllowedHeaders:   p.Headers,
+		ExposedHeaders:   p.ExposedHeaders,
+		AllowCredentials: p.AllowCredentials,
+		MaxAge:           p.MaxAge,
+	}
+	if len(origins) == 0 {
+		options.AllowOriginFunc = func(string) bool { return false }
+	}
+	return cors.New(options)
+}
+
+// checkWebSocketOrigin is the WebSocket upgraders' CheckOrigin. Browsers
+// send the session cookie on cross-site upgrades without applying CORS,
+// so an upgrade authenticated by the session needs an Origin that is the
+// API's own host or one the CORS policy lists for the path; "*" doesn't
+// count. Upgrades authenticated by an Authorization header can't be made
+// from another site's scripts and are accepted from any origin.
+func checkWebSocketOrigin(r *http.Request) bool {
+	if checkauth.GetSessionFromContext(r.Context()) == nil {
+		return true
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return false
+	}
+	if u, err := url.Parse(origin); err == nil && u.Host != "" && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	return corsPolicyFromConfig().listsOrigin(r.URL.Path, origin)
+}
+
+// listsOrigin reports whether origin is one of the origins the policy
+// names explicitly for path, matching host wildcards the way rs/cors
+// does but ignoring "*".
+func (p corsPolicy) listsOrigin(path, origin string) bool {
+	origins, longest := p.Origins, -1
+	for _, route := range p.Routes {
+		if strings.HasPrefix(path, route.Prefix) && len(route.Prefix) > longest {
+			origins, longest = route.Origins, len(route.Prefix)
+		}
+	}
+	origin = strings.ToLower(origin)
+	for _, allowed := range origins {
+		allowed = strings.ToLower(allowed)
+		if allowed == "*" {
+			continue
+		}
+		if prefix, suffix, ok := strings.Cut(allowed, "*"); ok {
+			if len(origin) >= len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+				return true
+			}
+		} else if allowed == origin {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/pubsub"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCORSHandler(t *testing.T) {
+	policy := corsPolicy{
+		Origins:          []string{"https://ui.example.com"},
+		Methods:          []string{http.MethodGet, http.MethodPatch},
+		Headers:          []string{"Authorization", "X-CSRF-Token"},
+		ExposedHeaders:   alwaysExposedHeaders,
+		AllowCredentials: true,
+		MaxAge:           600,
+		Routes: []config.CORSRoute{
+			{Prefix: "/api/v1/admin/"},
+			{Prefix: "/api/v1/admin/doctor", Origins: []string{"https://ops.example.com"}},
+			{Prefix: "/api/v1/jobs", Origins: []string{"https://*.dash.example.com"}},
+		},
+	}
+	handler := newCORSHandler(policy, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	call := func(method, path, origin string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Origin", origin)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+	allowed := func(w *httptest.ResponseRecorder) string { return w.Header().Get("Access-Control-Allow-Origin") }
+
+	t.Run("default origins", func(t *testing.T) {
+		w := call(http.MethodGet, "/api/v1/projects", "https://ui.example.com")
+		assert.Equal(t, "https://ui.example.com", allowed(w))
+		assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Contains(t, w.Header().Get("Access-Control-Expose-Headers"), AnnouncementHeader)
+		assert.Empty(t, allowed(call(http.MethodGet, "/api/v1/projects", "https://evil.example.com")))
+	})
+
+	t.Run("preflight", func(t *testing.T) {
+		w := call(http.MethodOptions, "/api/v1/projects/p1", "https://ui.example.com",
+			"Access-Control-Request-Method", http.MethodPatch, "Access-Control-Request-Headers", "x-csrf-token")
+		assert.Equal(t, "https://ui.example.com", allowed(w))
+		assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+
+		w = call(http.MethodOptions, "/api/v1/projects/p1", "https://ui.example.com",
+			"Access-Control-Request-Method", http.MethodDelete)
+		assert.Empty(t, allowed(w), "DELETE isn't an allowed method")
+	})
+
+	t.Run("route without origins", func(t *testing.T) {
+		w := call(http.MethodGet, "/api/v1/admin/trash/projects/p1", "https://ui.example.com")
+		assert.Equal(t, http.StatusOK, w.Code, "same-origin and non-browser callers are unaffected")
+		assert.Empty(t, allowed(w))
+	})
+
+	t.Run("longest prefix wins", func(t *testing.T) {
+		assert.Equal(t, "https://ops.example.com", allowed(call(http.MethodGet, "/api/v1/admin/doctor", "https://ops.example.com")))
+		assert.Empty(t, allowed(call(http.MethodGet, "/api/v1/admin/doctor", "https://ui.example.com")))
+	})
+
+	t.Run("wildcard route origin", func(t *testing.T) {
+		assert.Equal(t, "https://team.dash.example.com", allowed(call(http.MethodGet, "/api/v1/jobs/j1", "https://team.dash.example.com")))
+		assert.Empty(t, allowed(call(http.MethodGet, "/api/v1/jobs/j1", "https://ui.example.com")), "the route replaces the default origins")
+	})
+}
+
+func TestCORSPolicyFromConfig_InvalidBlocksCrossOrigin(t *testing.T) {
+	orig := config.CORSAllowedOrigins
+	defer func() { config.CORSAllowedOrigins = orig }()
+	config.CORSAllowedOrigins = "ui.example.com"
+
+	policy := corsPolicyFromConfig()
+	assert.Empty(t, policy.Origins)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/projects", nil)
+	req.Header.Set("Origin", "https://ui.example.com")
+	w := httptest.NewRecorder()
+	newCORSHandler(policy, http.NotFoundHandler()).ServeHTTP(w, req)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCheckWebSocketOrigin(t *testing.T) {
+	origOrigins, origRoutes := config.CORSAllowedOrigins, config.CORSRoutes
+	defer func() { config.CORSAllowedOrigins, config.CORSRoutes = origOrigins, origRoutes }()
+	config.CORSAllowedOrigins = "*"
+	config.CORSRoutes = "/api/v1/jobs=https://*.dash.example.com"
+
+	check := func(path, origin string, session bool) bool {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Host = "ci.example.com"
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if session {
+			req = req.WithContext(checkauth.SetSessionContext(req.Context(), &models.UISession{UserID: "user-1"}))
+		}
+		return checkWebSocketOrigin(req)
+	}
+
+	assert.True(t, check("/api/v1/jobs/stream", "https://evil.example.com", false), "Authorization header requests")
+	assert.True(t, check("/api/v1/jobs/stream", "https://ci.example.com", true), "same host")
+	assert.True(t, check("/api/v1/jobs/stream", "https://team.dash.example.com", true), "listed for the route")
+	assert.False(t, check("/api/v1/jobs/stream", "https://evil.example.com", true))
+	assert.False(t, check("/api/v1/jobs/stream", "", true), "missing Origin")
+	assert.False(t, check("/api/v1/projects", "https://evil.example.com", true), "\"*\" doesn't count")
+}
+
+func TestWSHandler_CrossOriginSession(t *testing.T) {
+	wsHandler := NewWSHandler(pubsub.NewBus(logrus.New(), 16), nil)
+	user := &models.User{UserID: "user-1"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := checkauth.SetUserContext(r.Context(), user)
+		ctx = checkauth.SetSessionContext(ctx, &models.UISession{UserID: user.UserID})
+		wsHandler.StreamAllJobs(w, r.WithContext(ctx))
+	}))
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/jobs/stream"
+	_, resp, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Origin": {"https://evil.example.com"}})
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	client, _, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Origin": {server.URL}})
+	require.NoError(t, err, "same-host origin")
+	client.Close()
+}
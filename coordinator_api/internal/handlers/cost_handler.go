@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+const (
+	costMonthLayout = "2006-01"
+	maxCostMonths   = 36
+)
+
+// costRollupStore is the narrow store capability behind GET /api/v1/costs.
+// See postgres_store/cost_operations.go.
+type costRollupStore interface {
+	ListCostRollups(ctx context.Context, groupBy string, since, until time.Time, orgID, projectID string) ([]models.CostRollup, error)
+}
+
+// CostHandler serves per-project and per-org cost rollups built from the
+// job usage workers record.
+type CostHandler struct {
+	BaseHandler
+	store store.Store
+}
+
+// NewCostHandler creates a new cost handler
+func NewCostHandler(store store.Store) *CostHandler {
+	return &CostHandler{
+		store: store,
+	}
+}
+
+// CostRollupsResponse is the JSON body of GET /api/v1/costs.
+type CostRollupsResponse struct {
+	GroupBy string              `json:"group_by"`
+	From    string              `json:"from"`
+	To      string              `json:"to"`
+	Rollups []models.CostRollup `json:"rollups"`
+}
+
+// ListCostRollups handles GET /api/v1/costs
+//
+// Query parameters:
+//   - group_by: project (default) or org.
+//   - from, to: inclusive range of months as YYYY-MM. Both default to the
+//     current month; at most 36 months per request.
+//   - project_id, org_id: restrict the rollup. Non-admins only ever see
+//     their own org.
+//   - format: json (default) or csv.
+func (h *CostHandler) ListCostRollups(w http.ResponseWriter, r *http.Request) {
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return
+	}
+	costStore, ok := h.store.(costRollupStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("cost accounting not available"))
+		return
+	}
+
+	query := r.URL.Query()
+	groupBy := query.Get("group_by")
+	if groupBy == "" {
+		groupBy = "project"
+	}
+	if groupBy != "project" && groupBy != "org" {
+		h.respondWithError(w, http.StatusBadRequest, fmt.Errorf("%w: group_by must be project or org", store.ErrInvalidInput))
+		return
+	}
+	format := query.Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		h.respondWithError(w, http.StatusBadRequest, fmt.Errorf("%w: format must be json or csv", store.ErrInvalidInput))
+		return
+	}
+
+	from, to, err := parseCostMonths(query.Get("from"), query.Get("to"), time.Now().UTC())
+	if err != nil {
+		h.respondWithError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	orgID := query.Get("org_id")
+	if !h.isAdmin(user) {
+		if orgID != "" && orgID != user.UserID {
+			h.respondWithError(w, http.StatusForbidden, store.ErrForbidden)
+			return
+		}
+		orgID = user.UserID
+	}
+
+	rollups, err := costStore.ListCostRollups(r.Context(), groupBy, from, to.AddDate(0, 1, 0), orgID, query.Get("project_id"))
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if format == "csv" {
+		h.writeCostCSV(w, groupBy, rollups)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, CostRollupsResponse{
+		GroupBy: groupBy,
+		From:    from.Format(costMonthLayout),
+		To:      to.Format(costMonthLayout),
+		Rollups: rollups,
+	})
+}
+
+func (h *CostHandler) writeCostCSV(w http.ResponseWriter, groupBy string, rollups []models.CostRollup) {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="costs.csv"`)
+	w.WriteHeader(http.StatusOK)
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"month", groupBy + "_id", "jobs", "cpu_seconds", "memory_gb_seconds", "storage_bytes", "cost", "currency"})
+	for _, rollup := range rollups {
+		groupID := rollup.OrgID
+		if groupBy == "project" {
+			groupID = ""
+			if rollup.ProjectID != nil {
+				groupID = *rollup.ProjectID
+			}
+		}
+		cw.Write([]string{
+			rollup.Month.Format(costMonthLayout),
+			groupID,
+			strconv.Itoa(rollup.Jobs),
+			strconv.FormatFloat(rollup.CPUSeconds, 'f', 3, 64),
+			strconv.FormatFloat(rollup.MemoryGBSeconds, 'f', 3, 64),
+			strconv.FormatInt(rollup.StorageBytes, 10),
+			strconv.FormatFloat(rollup.Cost, 'f', 4, 64),
+			rollup.Currency,
+		})
+	}
+	cw.Flush()
+}
+
+func (h *CostHandler) isAdmin(user *models.User) bool {
+	for _, role := range user.Roles {
+		if role == "admin" || role == "system_admin" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCostMonths parses the from/to month range, defaulting either end to
+// now's month. Returns the first instant of each month.
+func parseCostMonths(rawFrom, rawTo string, now time.Time) (from, to time.Time, err error) {
+	current := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	from, to = current, current
+	if rawFrom != "" {
+		if from, err = time.Parse(costMonthLayout, rawFrom); err != nil {
+			return from, to, fmt.Errorf("%w: from must be YYYY-MM", store.ErrInvalidInput)
+		}
+	}
+	if rawTo != "" {
+		if to, err = time.Parse(costMonthLayout, rawTo); err != nil {
+			return from, to, fmt.Errorf("%w: to must be YYYY-MM", store.ErrInvalidInput)
+		}
+	}
+	if to.Before(from) {
+		return from, to, fmt.Errorf("%w: to is before from", store.ErrInvalidInput)
+	}
+	if months := (to.Year()-from.Year())*12 + int(to.Month()-from.Month()) + 1; months > maxCostMonths {
+		return from, to, fmt.Errorf("%w: at most %d months per request", store.ErrInvalidInput, maxCostMonths)
+	}
+	return from, to, nil
+}
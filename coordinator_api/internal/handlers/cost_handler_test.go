@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// costMockStore adds ListCostRollups to ProjectMockStore.
+type costMockStore struct {
+	ProjectMockStore
+	rollups []models.CostRollup
+	calls   []struct {
+		GroupBy        string
+		Since, Until   time.Time
+		OrgID, Project string
+	}
+}
+
+func (m *costMockStore) ListCostRollups(ctx context.Context, groupBy string, since, until time.Time, orgID, projectID string) ([]models.CostRollup, error) {
+	m.calls = append(m.calls, struct {
+		GroupBy        string
+		Since, Until   time.Time
+		OrgID, Project string
+	}{groupBy, since, until, orgID, projectID})
+	return m.rollups, nil
+}
+
+func TestCostHandler_ListCostRollups(t *testing.T) {
+	get := func(s *costMockStore, user *models.User, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/costs"+query, nil)
+		req = req.WithContext(checkauth.SetUserContext(req.Context(), user))
+		w := httptest.NewRecorder()
+		NewCostHandler(s).ListCostRollups(w, req)
+		return w
+	}
+	member := &models.User{UserID: "member-id"}
+	admin := &models.User{UserID: "admin-id", Roles: []string{"admin"}}
+
+	t.Run("defaults to current month by project", func(t *testing.T) {
+		s := &costMockStore{}
+		w := get(s, member, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Len(t, s.calls, 1)
+		now := time.Now().UTC()
+		assert.Equal(t, "project", s.calls[0].GroupBy)
+		assert.Equal(t, time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC), s.calls[0].Since)
+		assert.Equal(t, s.calls[0].Since.AddDate(0, 1, 0), s.calls[0].Until)
+		assert.Equal(t, "member-id", s.calls[0].OrgID, "non-admins are scoped to their own org")
+	})
+
+	t.Run("month range", func(t *testing.T) {
+		s := &costMockStore{}
+		w := get(s, admin, "?group_by=org&from=2026-01&to=2026-03")
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp CostRollupsResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, "org", resp.GroupBy)
+		assert.Equal(t, "2026-01", resp.From)
+		assert.Equal(t, "2026-03", resp.To)
+		assert.Equal(t, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), s.calls[0].Until)
+		assert.Empty(t, s.calls[0].OrgID, "admins see every org by default")
+	})
+
+	t.Run("non-admin cannot read another org", func(t *testing.T) {
+		s := &costMockStore{}
+		w := get(s, member, "?org_id=someone-else")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Empty(t, s.calls)
+	})
+
+	t.Run("csv export", func(t *testing.T) {
+		projectID := "11111111-1111-1111-1111-111111111111"
+		s := &costMockStore{rollups: []models.CostRollup{{
+			Month:        time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+			ProjectID:    &projectID,
+			Jobs:         3,
+			CPUSeconds:   120,
+			StorageBytes: 2048,
+			Cost:         1.5,
+			Currency:     "USD",
+		}}}
+		w := get(s, member, "?format=csv")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+		records, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, "project_id", records[0][1])
+		assert.Equal(t, []string{"2026-09", projectID, "3", "120.000", "0.000", "2048", "1.5000", "USD"}, records[1])
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		for _, query := range []string{"?group_by=user", "?format=xml", "?from=2026-13", "?from=2026-05&to=2026-01", "?from=2020-01&to=2026-01"} {
+			s := &costMockStore{}
+			w := get(s, admin, query)
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+			assert.Empty(t, s.calls, query)
+		}
+	})
+
+	t.Run("store without cost accounting", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/costs", nil)
+		req = withUser(req)
+		w := httptest.NewRecorder()
+		NewCostHandler(&ProjectMockStore{}).ListCostRollups(w, req)
+		assert.NotEqual(t, http.StatusOK, w.Code)
+	})
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/jobcontrol"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// deadletterStore is the narrow store capability behind the admin
+// deadletter endpoints. See postgres_store/deadletter_operations.go.
+type deadletterStore interface {
+	ListDeadletteredJobs(ctx context.Context, limit, offset int) ([]models.Job, error)
+	ListJobDeliveryFailures(ctx context.Context, jobID string) ([]models.JobDeliveryFailure, error)
+}
+
+// DeadletteredJob is a deadlettered job and the claims of it that ended
+// without a result, oldest first.
+type DeadletteredJob struct {
+	JobID            string                      `json:"job_id"`
+	JobName          string                      `json:"job_name"`
+	ProjectID        *string                     `json:"project_id,omitempty"`
+	UserID           string                      `json:"user_id"`
+	Queue            string                      `json:"queue"`
+	Status           string                      `json:"status"`
+	DeliveryAttempts int                         `json:"delivery_attempts"`
+	DeadletteredAt   *time.Time                  `json:"deadlettered_at,omitempty"`
+	LastError        string                      `json:"last_error,omitempty"`
+	Failures         []models.JobDeliveryFailure `json:"failures"`
+}
+
+// ListDeadletteredJobsResponse is the JSON body of GET /api/v1/admin/deadletter.
+type ListDeadletteredJobsResponse struct {
+	Jobs   []DeadletteredJob `json:"jobs"`
+	Limit  int               `json:"limit"`
+	Offset int               `json:"offset"`
+}
+
+// FailDeadletteredJobRequest is the body of
+// POST /api/v1/admin/deadletter/{job_id}/fail. Reason is optional.
+type FailDeadletteredJobRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ListDeadletteredJobs handles GET /api/v1/admin/deadletter: deadlettered
+// jobs, most recently set aside first, each with its failure history.
+func (h *QueueHandler) ListDeadletteredJobs(w http.ResponseWriter, r *http.Request) {
+	ds, ok := h.store.(deadletterStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("deadletter not available"))
+		return
+	}
+	limit, offset := parseDeadletterPagination(r)
+	jobs, err := ds.ListDeadletteredJobs(r.Context(), limit, offset)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	resp := ListDeadletteredJobsResponse{Jobs: []DeadletteredJob{}, Limit: limit, Offset: offset}
+	for i := range jobs {
+		entry, err := deadletteredJob(r.Context(), ds, &jobs[i])
+		if err != nil {
+			h.respondWithError(w, http.StatusInternalServerError, err)
+			return
+		}
+		resp.Jobs = append(resp.Jobs, entry)
+	}
+	h.respondWithJSON(w, http.StatusOK, resp)
+}
+
+// GetDeadletteredJob handles GET /api/v1/admin/deadletter/{job_id}. The
+// failure history is shown for any job, so it can still be read after the
+// job was requeued or failed.
+func (h *QueueHandler) GetDeadletteredJob(w http.ResponseWriter, r *http.Request) {
+	ds, ok := h.store.(deadletterStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("deadletter not available"))
+		return
+	}
+	job, ok := h.loadQueuedJob(w, r)
+	if !ok {
+		return
+	}
+	entry, err := deadletteredJob(r.Context(), ds, job)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, entry)
+}
+
+// RequeueDeadletteredJob handles POST /api/v1/admin/deadletter/{job_id}/requeue.
+func (h *QueueHandler) RequeueDeadletteredJob(w http.ResponseWriter, r *http.Request) {
+	if !h.requireCorndogs(w) {
+		return
+	}
+	job, ok := h.loadQueuedJob(w, r)
+	if !ok {
+		return
+	}
+	updated, err := jobcontrol.RequeueDeadletteredJob(r.Context(), h.store, h.corndogsClient, job)
+	h.respondWithDeadletteredJob(w, r, "requeue", updated, err)
+}
+
+// FailDeadletteredJob handles POST /api/v1/admin/deadletter/{job_id}/fail.
+func (h *QueueHandler) FailDeadletteredJob(w http.ResponseWriter, r *http.Request) {
+	var req FailDeadletteredJobRequest
+	if r.ContentLength != 0 && !h.decodeJSON(w, r, &req) {
+		return
+	}
+	job, ok := h.loadQueuedJob(w, r)
+	if !ok {
+		return
+	}
+	updated, err := jobcontrol.FailDeadletteredJob(r.Context(), h.store, job, req.Reason)
+	h.respondWithDeadletteredJob(w, r, "fail", updated, err)
+}
+
+// respondWithDeadletteredJob answers a requeue or fail, logging the change.
+func (h *QueueHandler) respondWithDeadletteredJob(w http.ResponseWriter, r *http.Request, action string, job *models.Job, err error) {
+	if errors.Is(err, jobcontrol.ErrNotDeadlettered) {
+		h.respondWithJSON(w, http.StatusConflict, ErrorResponse{
+			Error:   "conflict",
+			Message: "job is not deadlettered",
+		})
+		return
+	}
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	logging.Log.WithFields(map[string]interface{}{
+		"job_id": job.JobID,
+		"admin":  adminUserID(r),
+	}).Warnf("ADMIN: %s deadlettered job", action)
+	h.respondWithJSON(w, http.StatusOK, QueuedJobResponse{
+		JobID:          job.JobID,
+		Status:         job.Status,
+		Priority:       job.Priority,
+		Queue:          job.QueueName,
+		CorndogsTaskID: job.CorndogsTaskID,
+	})
+}
+
+func deadletteredJob(ctx context.Context, ds deadletterStore, job *models.Job) (DeadletteredJob, error) {
+	failures, err := ds.ListJobDeliveryFailures(ctx, job.JobID)
+	if err != nil {
+		return DeadletteredJob{}, err
+	}
+	if failures == nil {
+		failures = []models.JobDeliveryFailure{}
+	}
+	return DeadletteredJob{
+		JobID:            job.JobID,
+		JobName:          job.Name,
+		ProjectID:        job.ProjectID,
+		UserID:           job.UserID,
+		Queue:            job.QueueName,
+		Status:           job.Status,
+		DeliveryAttempts: job.DeliveryAttempts,
+		DeadletteredAt:   job.DeadletteredAt,
+		LastError:        job.LastError,
+		Failures:         failures,
+	}, nil
+}
+
+// parseDeadletterPagination reads limit (default 20, at most 100) and
+// offset, as the job listing does.
+func parseDeadletterPagination(r *http.Request) (limit, offset int) {
+	limit = 20
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+	if o, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && o >= 0 {
+		offset = o
+	}
+	return limit, offset
+}
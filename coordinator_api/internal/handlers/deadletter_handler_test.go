@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// deadletterMockStore adds the deadletterStore capability to
+// queueMockStore.
+type deadletterMockStore struct {
+	*queueMockStore
+	failures map[string][]models.JobDeliveryFailure
+}
+
+func (m *deadletterMockStore) ListDeadletteredJobs(ctx context.Context, limit, offset int) ([]models.Job, error) {
+	var jobs []models.Job
+	for _, id := range []string{"job-a", "job-b", "job-c", "job-d"} {
+		if j, ok := m.jobs[id]; ok && j.Status == models.JobStatusDeadlettered {
+			jobs = append(jobs, *j)
+		}
+	}
+	return jobs, nil
+}
+
+func (m *deadletterMockStore) ListJobDeliveryFailures(ctx context.Context, jobID string) ([]models.JobDeliveryFailure, error) {
+	return m.failures[jobID], nil
+}
+
+// newDeadletterFixture adds job-d, deadlettered after three lost
+// deliveries, to the queue fixture.
+func newDeadletterFixture() (*deadletterMockStore, *corndogs.MockClient) {
+	qs, cd := newQueueFixture()
+	at := time.Now().UTC()
+	oldTask := "task-job-d"
+	qs.jobs["job-d"] = &models.Job{
+		JobID:            "job-d",
+		Name:             "job-d",
+		UserID:           "user-1",
+		Status:           models.JobStatusDeadlettered,
+		QueueName:        "reactorcide-jobs",
+		CorndogsTaskID:   &oldTask,
+		DeliveryAttempts: 3,
+		DeadletteredAt:   &at,
+		LastError:        "deadlettered after 3 delivery attempts",
+	}
+	st := &deadletterMockStore{queueMockStore: qs, failures: map[string][]models.JobDeliveryFailure{}}
+	for attempt := 1; attempt <= 3; attempt++ {
+		st.failures["job-d"] = append(st.failures["job-d"], models.JobDeliveryFailure{
+			JobID: "job-d", Attempt: attempt, WorkerID: "worker-1", Reason: models.DeliveryFailureWorkerLost,
+		})
+	}
+	return st, cd
+}
+
+func TestQueueHandler_ListDeadletteredJobs(t *testing.T) {
+	st, cd := newDeadletterFixture()
+	h := NewQueueHandler(st, cd)
+
+	w := httptest.NewRecorder()
+	h.ListDeadletteredJobs(w, queueRequest(http.MethodGet, "/api/v1/admin/deadletter", "", "", ""))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp ListDeadletteredJobsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Jobs, 1)
+	assert.Equal(t, "job-d", resp.Jobs[0].JobID)
+	assert.Equal(t, 3, resp.Jobs[0].DeliveryAttempts)
+	require.Len(t, resp.Jobs[0].Failures, 3)
+	assert.Equal(t, models.DeliveryFailureWorkerLost, resp.Jobs[0].Failures[2].Reason)
+}
+
+func TestQueueHandler_RequeueDeadletteredJob(t *testing.T) {
+	requeue := func(h *QueueHandler, jobID string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.RequeueDeadletteredJob(w, queueRequest(http.MethodPost, "/api/v1/admin/deadletter/"+jobID+"/requeue", "job_id", jobID, ""))
+		return w
+	}
+
+	t.Run("submits the job again with fresh attempts", func(t *testing.T) {
+		st, cd := newDeadletterFixture()
+		w := requeue(NewQueueHandler(st, cd), "job-d")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.Len(t, cd.SubmitTaskCalls, 1)
+
+		job := st.jobs["job-d"]
+		assert.Equal(t, "submitted", job.Status)
+		assert.Equal(t, 0, job.DeliveryAttempts)
+		assert.Nil(t, job.DeadletteredAt)
+		assert.Empty(t, job.LastError)
+		assert.NotEqual(t, "task-job-d", *job.CorndogsTaskID)
+		assert.Len(t, st.failures["job-d"], 3, "failure history is kept")
+	})
+
+	t.Run("only deadlettered jobs", func(t *testing.T) {
+		st, cd := newDeadletterFixture()
+		w := requeue(NewQueueHandler(st, cd), "job-a")
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Empty(t, cd.SubmitTaskCalls)
+	})
+}
+
+func TestQueueHandler_FailDeadletteredJob(t *testing.T) {
+	st, cd := newDeadletterFixture()
+	h := NewQueueHandler(st, cd)
+
+	w := httptest.NewRecorder()
+	h.FailDeadletteredJob(w, queueRequest(http.MethodPost, "/api/v1/admin/deadletter/job-d/fail", "job_id", "job-d", `{"reason":"image no longer exists"}`))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	job := st.jobs["job-d"]
+	assert.Equal(t, "failed", job.Status)
+	assert.Equal(t, "deadlettered after 3 delivery attempts: image no longer exists", job.LastError)
+	assert.NotNil(t, job.CompletedAt)
+	assert.Empty(t, cd.SubmitTaskCalls)
+
+	w = httptest.NewRecorder()
+	h.FailDeadletteredJob(w, queueRequest(http.MethodPost, "/api/v1/admin/deadletter/job-d/fail", "job_id", "job-d", ""))
+	assert.Equal(t, http.StatusConflict, w.Code, "already failed")
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/doctor"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
+)
+
+// DoctorHandler serves the coordinator's self-diagnostics.
+type DoctorHandler struct {
+	BaseHandler
+	deps func() doctor.Deps
+}
+
+// NewDoctorHandler creates a doctor handler checking what deps returns
+// at each request.
+func NewDoctorHandler(deps func() doctor.Deps) *DoctorHandler {
+	return &DoctorHandler{deps: deps}
+}
+
+// Diagnose handles GET /api/v1/admin/doctor: runs every doctor check
+// against this coordinator and returns the report. Failed checks don't
+// fail the request; the report's healthy and score say how it went.
+func (h *DoctorHandler) Diagnose(w http.ResponseWriter, r *http.Request) {
+	h.respondWithJSON(w, http.StatusOK, doctor.Run(r.Context(), doctor.Checks(h.deps()), 0))
+}
+
+// DoctorDeps returns what the doctor checks examine in this coordinator:
+// its database, master keys, Corndogs client, object store and webhook
+// secrets. Must be called after GetAppMux (or NewRouter).
+func DoctorDeps() doctor.Deps {
+	return doctor.Deps{
+		DB:                       store.GetDB(),
+		ExpectedMigrationVersion: singletonExpectedMigrationVersion,
+		KeyManager:               singletonKeyManager,
+		Corndogs:                 singletoncorndogsClient,
+		ObjectStore:              singletonObjectStore,
+		Providers:                vcs.EnabledProviders(),
+		FallbackWebhookSecret:    config.VCSWebhookSecret,
+	}
+}
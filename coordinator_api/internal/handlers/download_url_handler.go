@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/jobcontrol"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+const (
+	defaultDownloadURLExpiry = 15 * time.Minute
+	maxDownloadURLExpiry     = 24 * time.Hour
+)
+
+// DownloadURLResponse is the JSON body of
+// GET /api/v1/jobs/{job_id}/artifacts/{name}/url.
+type DownloadURLResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// LogURLsResponse is the JSON body of GET /api/v1/jobs/{job_id}/logs/url:
+// a URL per log stream ("stdout", "stderr") the job has.
+type LogURLsResponse struct {
+	URLs      map[string]string `json:"urls"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// SetURLSigner sets the signer for download URLs on object stores that
+// can't presign their own (see downloadURL).
+func (h *JobHandler) SetURLSigner(signer *objects.URLSigner) {
+	h.urlSigner = signer
+}
+
+// GetJobLogURLs handles GET /api/v1/jobs/{job_id}/logs/url: time-limited
+// URLs that download the job's logs straight from storage, for logs too
+// large to fetch through GetJobLogs.
+// Query parameters:
+//   - stream: "stdout" or "stderr" (default: both)
+//   - expires_in: seconds the URLs stay valid (default 900, max 86400)
+func (h *JobHandler) GetJobLogURLs(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.loadDownloadableJob(w, r)
+	if !ok {
+		return
+	}
+	expires, ok := h.parseDownloadURLExpiry(w, r)
+	if !ok {
+		return
+	}
+
+	streams := []string{"stdout", "stderr"}
+	switch stream := r.URL.Query().Get("stream"); stream {
+	case "":
+	case "stdout", "stderr":
+		streams = []string{stream}
+	default:
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+
+	response := LogURLsResponse{URLs: map[string]string{}}
+	for _, stream := range streams {
+		url, expiresAt, err := h.downloadURL(r.Context(), jobcontrol.LogObjectKey(job, stream), expires)
+		if errors.Is(err, objects.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			h.respondWithError(w, http.StatusInternalServerError, err)
+			return
+		}
+		response.URLs[stream] = url
+		response.ExpiresAt = expiresAt
+	}
+	if len(response.URLs) == 0 {
+		h.respondWithError(w, http.StatusNotFound, store.ErrNotFound)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, response)
+}
+
+// GetJobArtifactURL handles GET /api/v1/jobs/{job_id}/artifacts/{name}/url:
+// a time-limited URL that downloads one of the job's artifacts straight
+// from storage. expires_in is as for GetJobLogURLs.
+func (h *JobHandler) GetJobArtifactURL(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.loadDownloadableJob(w, r)
+	if !ok {
+		return
+	}
+	expires, ok := h.parseDownloadURLExpiry(w, r)
+	if !ok {
+		return
+	}
+	key, err := artifactObjectKey(job, h.getID(r, "artifact_name"))
+	if err != nil {
+		h.respondWithError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	url, expiresAt, err := h.downloadURL(r.Context(), key, expires)
+	if err != nil {
+		if errors.Is(err, objects.ErrNotFound) {
+			h.respondWithError(w, http.StatusNotFound, store.ErrNotFound)
+			return
+		}
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, DownloadURLResponse{URL: url, ExpiresAt: expiresAt})
+}
+
+// loadDownloadableJob loads the job named in the path for the download URL
+// endpoints and checks the caller may read it, writing the error response
+// itself if not. Same access as GetJobLogs.
+func (h *JobHandler) loadDownloadableJob(w http.ResponseWriter, r *http.Request) (*models.Job, bool) {
+	jobID := h.getID(r, "job_id")
+	if jobID == "" {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return nil, false
+	}
+	job, err := h.store.GetJobByID(r.Context(), jobID)
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return nil, false
+	}
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return nil, false
+	}
+	if !h.canUserViewJob(r.Context(), user, job) {
+		h.respondWithError(w, http.StatusForbidden, store.ErrForbidden)
+		return nil, false
+	}
+	if h.objectStore == nil {
+		h.respondWithError(w, http.StatusServiceUnavailable, store.ErrServiceUnavailable)
+		return nil, false
+	}
+	return job, true
+}
+
+func (h *JobHandler) parseDownloadURLExpiry(w http.ResponseWriter, r *http.Request) (time.Duration, bool) {
+	raw := r.URL.Query().Get("expires_in")
+	if raw == "" {
+		return defaultDownloadURLExpiry, true
+	}
+	seconds, err := strconv.Atoi(raw)
+	if err != nil || seconds <= 0 || time.Duration(seconds)*time.Second > maxDownloadURLExpiry {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+// downloadURL returns a URL that downloads key until the returned time:
+// the object store's own presigned URL where it has them (S3), else one
+// the coordinator signs and serves itself.
+func (h *JobHandler) downloadURL(ctx context.Context, key string, expires time.Duration) (string, time.Time, error) {
+	exists, err := h.objectStore.Exists(ctx, key)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if !exists {
+		return "", time.Time{}, objects.ErrNotFound
+	}
+
+	// The filesystem store's file:// URLs are only meaningful on the
+	// coordinator's host, so they get signed URLs too.
+	url, err := h.objectStore.GetURL(ctx, key, expires)
+	if err == nil && (strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "http://")) {
+		return url, time.Now().UTC().Add(expires).Truncate(time.Second), nil
+	}
+	if err != nil && !errors.Is(err, objects.ErrNotSupported) {
+		return "", time.Time{}, err
+	}
+	if h.urlSigner == nil {
+		return "", time.Time{}, errors.New("download URLs not available")
+	}
+	url, expiresAt := h.urlSigner.SignURL(key, expires)
+	return url, expiresAt, nil
+}
+
+// artifactObjectKey returns the key of the job's artifact name: under the
+// job's ArtifactsObjectKey if the worker set one, else artifacts/{job_id}/.
+func artifactObjectKey(job *models.Job, name string) (string, error) {
+	if name == "" || strings.HasPrefix(name, "/") || path.Clean(name) != name || name == ".." || strings.HasPrefix(name, "../") {
+		return "", store.ErrInvalidInput
+	}
+	prefix := strings.TrimSuffix(job.ArtifactsObjectKey, "/")
+	if prefix == "" {
+		prefix = "artifacts/" + job.JobID
+	}
+	return prefix + "/" + name, nil
+}
+
+// SignedObjectHandler serves objects by URLSigner URL. It needs no other
+// auth: the signature is the grant.
+type SignedObjectHandler struct {
+	BaseHandler
+	objectStore objects.ObjectStore
+	signer      *objects.URLSigner
+}
+
+// NewSignedObjectHandler creates a new signed object handler.
+func NewSignedObjectHandler(objectStore objects.ObjectStore, signer *objects.URLSigner) *SignedObjectHandler {
+	return &SignedObjectHandler{
+		objectStore: objectStore,
+		signer:      signer,
+	}
+}
+
+// ServeSignedObject handles GET /api/v1/objects/signed, streaming the
+// object named by a valid, unexpired signed URL.
+func (h *SignedObjectHandler) ServeSignedObject(w http.ResponseWriter, r *http.Request) {
+	if h.objectStore == nil || h.signer == nil {
+		h.respondWithError(w, http.StatusServiceUnavailable, store.ErrServiceUnavailable)
+		return
+	}
+	key, err := h.signer.Verify(r.URL.Query())
+	if err != nil {
+		h.respondWithError(w, http.StatusForbidden, store.ErrForbidden)
+		return
+	}
+	reader, err := h.objectStore.Get(r.Context(), key)
+	if err != nil {
+		if errors.Is(err, objects.ErrNotFound) {
+			h.respondWithError(w, http.StatusNotFound, store.ErrNotFound)
+			return
+		}
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer reader.Close()
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", path.Base(key)))
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, reader)
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+func TestDownloadURLs(t *testing.T) {
+	testJobID := "test-job-url-123"
+	testUser := &models.User{UserID: "test-user-url-456", Username: "testuser"}
+	otherUser := &models.User{UserID: "someone-else", Username: "other"}
+	testJob := &models.Job{JobID: testJobID, UserID: testUser.UserID, Status: "completed"}
+
+	mockStoreInstance := &MockStore{
+		GetJobByIDFunc: func(ctx context.Context, jobID string) (*models.Job, error) {
+			if jobID == testJobID {
+				return testJob, nil
+			}
+			return nil, store.ErrNotFound
+		},
+	}
+
+	setup := func(t *testing.T) (*JobHandler, *SignedObjectHandler) {
+		t.Helper()
+		objectStore := objects.NewFilesystemObjectStore(t.TempDir())
+		require.NoError(t, objectStore.Put(context.Background(), "logs/"+testJobID+"/stdout.json", strings.NewReader(`[{"message":"hi"}]`), "application/json"))
+		require.NoError(t, objectStore.Put(context.Background(), "artifacts/"+testJobID+"/dist/app.tar.gz", bytes.NewReader([]byte("tarball")), "application/gzip"))
+		signer := objects.NewURLSigner([]byte("test-signing-key"), "https://ci.example.com/")
+		handler := NewJobHandlerWithObjectStore(mockStoreInstance, nil, objectStore)
+		handler.SetURLSigner(signer)
+		return handler, NewSignedObjectHandler(objectStore, signer)
+	}
+
+	request := func(target string, user *models.User, ids map[string]string) *http.Request {
+		req := httptest.NewRequest("GET", target, nil)
+		ctx := checkauth.SetUserContext(req.Context(), user)
+		for key, value := range ids {
+			ctx = context.WithValue(ctx, GetContextKey(key), value)
+		}
+		return req.WithContext(ctx)
+	}
+
+	// fetch follows a signed URL through the signed object handler.
+	fetch := func(t *testing.T, signed *SignedObjectHandler, rawURL string) *httptest.ResponseRecorder {
+		t.Helper()
+		u, err := url.Parse(rawURL)
+		require.NoError(t, err)
+		assert.Equal(t, "ci.example.com", u.Host)
+		assert.Equal(t, objects.SignedObjectPath, u.Path)
+		rr := httptest.NewRecorder()
+		signed.ServeSignedObject(rr, httptest.NewRequest("GET", u.RequestURI(), nil))
+		return rr
+	}
+
+	t.Run("log URLs for the streams the job has", func(t *testing.T) {
+		handler, signed := setup(t)
+		rr := httptest.NewRecorder()
+		handler.GetJobLogURLs(rr, request("/api/v1/jobs/"+testJobID+"/logs/url", testUser, map[string]string{"job_id": testJobID}))
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		var response LogURLsResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Contains(t, response.URLs, "stdout")
+		assert.NotContains(t, response.URLs, "stderr")
+		assert.WithinDuration(t, time.Now().Add(defaultDownloadURLExpiry), response.ExpiresAt, time.Minute)
+
+		download := fetch(t, signed, response.URLs["stdout"])
+		assert.Equal(t, http.StatusOK, download.Code)
+		assert.Equal(t, `[{"message":"hi"}]`, download.Body.String())
+	})
+
+	t.Run("artifact URL", func(t *testing.T) {
+		handler, signed := setup(t)
+		rr := httptest.NewRecorder()
+		handler.GetJobArtifactURL(rr, request("/api/v1/jobs/"+testJobID+"/artifacts/dist/app.tar.gz/url?expires_in=60", testUser,
+			map[string]string{"job_id": testJobID, "artifact_name": "dist/app.tar.gz"}))
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		var response DownloadURLResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.WithinDuration(t, time.Now().Add(time.Minute), response.ExpiresAt, 5*time.Second)
+
+		download := fetch(t, signed, response.URL)
+		assert.Equal(t, http.StatusOK, download.Code)
+		body, _ := io.ReadAll(download.Body)
+		assert.Equal(t, "tarball", string(body))
+		assert.Contains(t, download.Header().Get("Content-Disposition"), "app.tar.gz")
+	})
+
+	t.Run("rejects bad requests", func(t *testing.T) {
+		handler, _ := setup(t)
+		tests := []struct {
+			name     string
+			user     *models.User
+			artifact string
+			query    string
+			want     int
+		}{
+			{"missing artifact", testUser, "nope.txt", "", http.StatusNotFound},
+			{"path traversal", testUser, "../other/secret", "", http.StatusBadRequest},
+			{"expiry too long", testUser, "dist/app.tar.gz", "?expires_in=999999", http.StatusBadRequest},
+			{"other user", otherUser, "dist/app.tar.gz", "", http.StatusForbidden},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				rr := httptest.NewRecorder()
+				handler.GetJobArtifactURL(rr, request("/api/v1/jobs/"+testJobID+"/artifacts/x/url"+tt.query, tt.user,
+					map[string]string{"job_id": testJobID, "artifact_name": tt.artifact}))
+				assert.Equal(t, tt.want, rr.Code, rr.Body.String())
+			})
+		}
+	})
+
+	t.Run("signed URLs can't be tampered with or used after expiry", func(t *testing.T) {
+		_, signed := setup(t)
+		signer := objects.NewURLSigner([]byte("test-signing-key"), "https://ci.example.com")
+
+		rawURL, _ := signer.SignURL("logs/"+testJobID+"/stdout.json", time.Minute)
+		tampered := strings.Replace(rawURL, "stdout.json", "stderr.json", 1)
+		assert.Equal(t, http.StatusForbidden, fetch(t, signed, tampered).Code)
+
+		otherKey := objects.NewURLSigner([]byte("another-key"), "https://ci.example.com")
+		forged, _ := otherKey.SignURL("logs/"+testJobID+"/stdout.json", time.Minute)
+		assert.Equal(t, http.StatusForbidden, fetch(t, signed, forged).Code)
+
+		expired, _ := signer.SignURL("logs/"+testJobID+"/stdout.json", -time.Minute)
+		assert.Equal(t, http.StatusForbidden, fetch(t, signed, expired).Code)
+	})
+}
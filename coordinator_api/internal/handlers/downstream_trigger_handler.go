@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/authz"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+type downstreamTriggerStore interface {
+	CreateDownstreamTrigger(ctx context.Context, trigger *models.DownstreamTrigger) error
+	ListDownstreamTriggers(ctx context.Context, projectID string) ([]models.DownstreamTrigger, error)
+	GetDownstreamTrigger(ctx context.Context, projectID, triggerID string) (*models.DownstreamTrigger, error)
+	DeleteDownstreamTrigger(ctx context.Context, projectID, triggerID string) error
+	DownstreamTriggerGraph(ctx context.Context) (map[string][]string, error)
+}
+
+// DownstreamTriggerRequest is the body of POST
+// /api/v1/projects/{project_id}/downstream-triggers.
+type DownstreamTriggerRequest struct {
+	DownstreamProjectID string `json:"downstream_project_id"`
+	JobNameMatch        string `json:"job_name_match,omitempty"`
+	JobNamePattern      string `json:"job_name_pattern"`
+	On                  string `json:"on,omitempty"`
+	Ref                 string `json:"ref,omitempty"`
+	Description         string `json:"description,omitempty"`
+}
+
+type ListDownstreamTriggersResponse struct {
+	Triggers []models.DownstreamTrigger `json:"triggers"`
+	Total    int                        `json:"total"`
+}
+
+// ListDownstreamTriggers handles GET /api/v1/projects/{project_id}/downstream-triggers
+func (h *ProjectHandler) ListDownstreamTriggers(w http.ResponseWriter, r *http.Request) {
+	triggerStore, project, _, ok := h.downstreamTriggerScope(w, r)
+	if !ok {
+		return
+	}
+	triggers, err := triggerStore.ListDownstreamTriggers(r.Context(), project.ProjectID)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, ListDownstreamTriggersResponse{Triggers: triggers, Total: len(triggers)})
+}
+
+// CreateDownstreamTrigger handles POST /api/v1/projects/{project_id}/downstream-triggers
+//
+// The caller must own both projects: the trigger spends the downstream
+// project's resources on the upstream project's say-so. A trigger that
+// would close a cycle of projects is rejected with 409.
+func (h *ProjectHandler) CreateDownstreamTrigger(w http.ResponseWriter, r *http.Request) {
+	triggerStore, project, user, ok := h.downstreamTriggerScope(w, r)
+	if !ok {
+		return
+	}
+	var req DownstreamTriggerRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	trigger := &models.DownstreamTrigger{
+		ProjectID:           project.ProjectID,
+		DownstreamProjectID: strings.TrimSpace(req.DownstreamProjectID),
+		JobNameMatch:        strings.TrimSpace(req.JobNameMatch),
+		JobNamePattern:      strings.TrimSpace(req.JobNamePattern),
+		On:                  strings.TrimSpace(req.On),
+		Ref:                 strings.TrimSpace(req.Ref),
+		CreatedBy:           &user.UserID,
+		Description:         strings.TrimSpace(req.Description),
+	}
+	if !h.validateDownstreamTrigger(w, trigger) {
+		return
+	}
+
+	downstream, err := h.store.GetProjectByID(r.Context(), trigger.DownstreamProjectID)
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return
+	}
+	for _, p := range []*models.Project{project, downstream} {
+		if !h.canManageProject(r.Context(), user, p) {
+			h.respondWithError(w, http.StatusForbidden, store.ErrForbidden)
+			return
+		}
+	}
+
+	edges, err := triggerStore.DownstreamTriggerGraph(r.Context())
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if models.DownstreamCycle(edges, project.ProjectID, downstream.ProjectID) {
+		h.respondWithJSON(w, http.StatusConflict, ErrorResponse{
+			Error:   "conflict",
+			Message: "project " + downstream.Name + " already triggers " + project.Name + ", directly or through other projects",
+		})
+		return
+	}
+
+	if err := triggerStore.CreateDownstreamTrigger(r.Context(), trigger); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusCreated, trigger)
+}
+
+// GetDownstreamTrigger handles GET /api/v1/projects/{project_id}/downstream-triggers/{trigger_id}
+func (h *ProjectHandler) GetDownstreamTrigger(w http.ResponseWriter, r *http.Request) {
+	triggerStore, project, _, ok := h.downstreamTriggerScope(w, r)
+	if !ok {
+		return
+	}
+	trigger, err := triggerStore.GetDownstreamTrigger(r.Context(), project.ProjectID, h.getID(r, "trigger_id"))
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, trigger)
+}
+
+// DeleteDownstreamTrigger handles DELETE /api/v1/projects/{project_id}/downstream-triggers/{trigger_id}
+//
+// Owning either project is enough to remove a trigger between them.
+func (h *ProjectHandler) DeleteDownstreamTrigger(w http.ResponseWriter, r *http.Request) {
+	triggerStore, project, user, ok := h.downstreamTriggerScope(w, r)
+	if !ok {
+		return
+	}
+	trigger, err := triggerStore.GetDownstreamTrigger(r.Context(), project.ProjectID, h.getID(r, "trigger_id"))
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return
+	}
+	if !h.canManageProject(r.Context(), user, project) {
+		downstream, err := h.store.GetProjectByID(r.Context(), trigger.DownstreamProjectID)
+		if err != nil || !h.canManageProject(r.Context(), user, downstream) {
+			h.respondWithError(w, http.StatusForbidden, store.ErrForbidden)
+			return
+		}
+	}
+	if err := triggerStore.DeleteDownstreamTrigger(r.Context(), project.ProjectID, trigger.TriggerID); err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ProjectHandler) downstreamTriggerScope(w http.ResponseWriter, r *http.Request) (downstreamTriggerStore, *models.Project, *models.User, bool) {
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return nil, nil, nil, false
+	}
+	triggerStore, ok := h.store.(downstreamTriggerStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("downstream trigger store not available"))
+		return nil, nil, nil, false
+	}
+	project, _, ok := h.projectAndOwner(w, r, user.UserID)
+	if !ok {
+		return nil, nil, nil, false
+	}
+	return triggerStore, project, user, true
+}
+
+// canManageProject reports whether user owns project: as an admin, as the
+// project's owner, or through an owner role assignment when the store
+// supports role lookups.
+func (h *ProjectHandler) canManageProject(ctx context.Context, user *models.User, project *models.Project) bool {
+	for _, role := range user.Roles {
+		if role == "admin" || role == "system_admin" {
+			return true
+		}
+	}
+	if project.UserID != nil && *project.UserID == user.UserID {
+		return true
+	}
+	roleStore, ok := h.store.(authz.RoleStore)
+	if !ok {
+		return false
+	}
+	owner, err := authz.NewResolver(roleStore).IsProjectOwner(ctx, authz.IdentityFromUser(user), project.ProjectID)
+	return err == nil && owner
+}
+
+// validateDownstreamTrigger fills in trigger's defaults and validates it,
+// responding 400 if it's invalid.
+func (h *ProjectHandler) validateDownstreamTrigger(w http.ResponseWriter, trigger *models.DownstreamTrigger) bool {
+	if trigger.JobNameMatch == "" {
+		trigger.JobNameMatch = models.SecretGrantMatchExact
+	}
+	if trigger.On == "" {
+		trigger.On = models.DownstreamOnSuccess
+	}
+	if trigger.Ref == "" {
+		trigger.Ref = "main"
+	}
+
+	var err error
+	switch {
+	case trigger.DownstreamProjectID == "":
+		err = errors.New("downstream_project_id is required")
+	case trigger.DownstreamProjectID == trigger.ProjectID:
+		err = errors.New("a project can't trigger itself")
+	case trigger.JobNamePattern == "":
+		err = errors.New("job_name_pattern is required")
+	case !validSecretGrantMatch(trigger.JobNameMatch, false):
+		err = errors.New("invalid job_name_match " + trigger.JobNameMatch)
+	case strings.ContainsAny(trigger.Ref, " \t\n") || strings.HasPrefix(trigger.Ref, "-"):
+		err = errors.New("invalid ref " + trigger.Ref)
+	}
+	if err == nil {
+		err = validateSecretGrantPattern(trigger.JobNameMatch, trigger.JobNamePattern)
+	}
+	if err == nil {
+		err = models.ValidateDownstreamOn(trigger.On)
+	}
+	if err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_input",
+			Message: err.Error(),
+		})
+		return false
+	}
+	return true
+}
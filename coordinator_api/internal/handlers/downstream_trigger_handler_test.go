@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// downstreamTriggerMockStore keeps triggers in memory on top of
+// ProjectMockStore, with the downstreamTriggerStore capability.
+type downstreamTriggerMockStore struct {
+	ProjectMockStore
+	triggers []models.DownstreamTrigger
+}
+
+func newDownstreamTriggerMockStore(owners map[string]string) *downstreamTriggerMockStore {
+	m := &downstreamTriggerMockStore{}
+	m.GetProjectByIDFunc = func(ctx context.Context, projectID string) (*models.Project, error) {
+		owner, ok := owners[projectID]
+		if !ok {
+			return nil, store.ErrNotFound
+		}
+		return &models.Project{ProjectID: projectID, Name: projectID, UserID: &owner}, nil
+	}
+	return m
+}
+
+func (m *downstreamTriggerMockStore) CreateDownstreamTrigger(ctx context.Context, trigger *models.DownstreamTrigger) error {
+	trigger.TriggerID = "trigger-new"
+	m.triggers = append(m.triggers, *trigger)
+	return nil
+}
+
+func (m *downstreamTriggerMockStore) ListDownstreamTriggers(ctx context.Context, projectID string) ([]models.DownstreamTrigger, error) {
+	var out []models.DownstreamTrigger
+	for _, t := range m.triggers {
+		if t.ProjectID == projectID {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (m *downstreamTriggerMockStore) GetDownstreamTrigger(ctx context.Context, projectID, triggerID string) (*models.DownstreamTrigger, error) {
+	for _, t := range m.triggers {
+		if t.ProjectID == projectID && t.TriggerID == triggerID {
+			return &t, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (m *downstreamTriggerMockStore) DeleteDownstreamTrigger(ctx context.Context, projectID, triggerID string) error {
+	for i, t := range m.triggers {
+		if t.ProjectID == projectID && t.TriggerID == triggerID {
+			m.triggers = append(m.triggers[:i], m.triggers[i+1:]...)
+			return nil
+		}
+	}
+	return store.ErrNotFound
+}
+
+func (m *downstreamTriggerMockStore) DownstreamTriggerGraph(ctx context.Context) (map[string][]string, error) {
+	edges := map[string][]string{}
+	for _, t := range m.triggers {
+		edges[t.ProjectID] = append(edges[t.ProjectID], t.DownstreamProjectID)
+	}
+	return edges, nil
+}
+
+func TestProjectHandler_CreateDownstreamTrigger(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		existing       []models.DownstreamTrigger
+		expectedStatus int
+	}{
+		{
+			name:           "owner of both projects",
+			body:           `{"downstream_project_id": "svc", "job_name_pattern": "release"}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "downstream project owned by someone else",
+			body:           `{"downstream_project_id": "other", "job_name_pattern": "release"}`,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "unknown downstream project",
+			body:           `{"downstream_project_id": "missing", "job_name_pattern": "release"}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "cycle",
+			body:           `{"downstream_project_id": "svc", "job_name_pattern": "release"}`,
+			existing:       []models.DownstreamTrigger{{TriggerID: "t1", ProjectID: "svc", DownstreamProjectID: "lib"}},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "self",
+			body:           `{"downstream_project_id": "lib", "job_name_pattern": "release"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "any job name",
+			body:           `{"downstream_project_id": "svc", "job_name_match": "any", "job_name_pattern": "release"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "bad on",
+			body:           `{"downstream_project_id": "svc", "job_name_pattern": "release", "on": "cancelled"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := newDownstreamTriggerMockStore(map[string]string{
+				"lib":   "test-user-id",
+				"svc":   "test-user-id",
+				"other": "other-user-id",
+			})
+			mockStore.triggers = tt.existing
+			handler := NewProjectHandler(mockStore)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/projects/lib/downstream-triggers", strings.NewReader(tt.body))
+			req = withProjectID(withUser(req), "lib")
+			w := httptest.NewRecorder()
+			handler.CreateDownstreamTrigger(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedStatus == http.StatusCreated {
+				require.Len(t, mockStore.triggers, 1)
+				trigger := mockStore.triggers[0]
+				assert.Equal(t, models.SecretGrantMatchExact, trigger.JobNameMatch)
+				assert.Equal(t, models.DownstreamOnSuccess, trigger.On)
+				assert.Equal(t, "main", trigger.Ref)
+				assert.Equal(t, "test-user-id", *trigger.CreatedBy)
+			}
+		})
+	}
+}
+
+func TestProjectHandler_DeleteDownstreamTrigger(t *testing.T) {
+	mockStore := newDownstreamTriggerMockStore(map[string]string{
+		"lib": "other-user-id",
+		"svc": "test-user-id",
+	})
+	mockStore.triggers = []models.DownstreamTrigger{{TriggerID: "t1", ProjectID: "lib", DownstreamProjectID: "svc"}}
+	handler := NewProjectHandler(mockStore)
+
+	// Owning the downstream project is enough to stop being triggered.
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/projects/lib/downstream-triggers/t1", nil)
+	req = withProjectID(withUser(req), "lib")
+	req = req.WithContext(context.WithValue(req.Context(), GetContextKey("trigger_id"), "t1"))
+	w := httptest.NewRecorder()
+	handler.DeleteDownstreamTrigger(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, mockStore.triggers)
+}
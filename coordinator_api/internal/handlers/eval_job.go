@@ -73,6 +73,21 @@ func BuildEvalJob(project *models.Project, event *vcs.WebhookEvent) *models.Job
 
 		envVars["REACTORCIDE_SHA"] = push.After
 		envVars["REACTORCIDE_BRANCH"] = branch
+	} else if event.MergeGroup != nil {
+		mg := event.MergeGroup
+		sourceRef = mg.HeadSHA
+		branch = mg.BaseRef
+		if mg.PRNumber > 0 {
+			jobName = fmt.Sprintf("eval: merge queue PR #%d into %s on %s", mg.PRNumber, branch, event.Repository.FullName)
+			envVars["REACTORCIDE_PR_NUMBER"] = fmt.Sprintf("%d", mg.PRNumber)
+		} else {
+			jobName = fmt.Sprintf("eval: merge group into %s (%.7s) on %s", branch, mg.HeadSHA, event.Repository.FullName)
+		}
+
+		envVars["REACTORCIDE_SHA"] = mg.HeadSHA
+		envVars["REACTORCIDE_BRANCH"] = mg.BaseRef
+		envVars["REACTORCIDE_MERGE_GROUP_REF"] = mg.HeadRef
+		envVars["REACTORCIDE_DIFF_BASE"] = mg.BaseSHA
 	}
 
 	// CI source: trusted repo with job definitions
@@ -101,9 +116,13 @@ func BuildEvalJob(project *models.Project, event *vcs.WebhookEvent) *models.Job
 		st := models.SourceTypeGit
 		ciSourceType = &st
 		ciSourceURL = &upstreamURL
+		// Merge groups contain unmerged PR changes too, so they're anchored
+		// at the base as well.
 		var ciRef string
 		if event.PullRequest != nil {
 			ciRef = event.PullRequest.BaseSHA
+		} else if event.MergeGroup != nil {
+			ciRef = event.MergeGroup.BaseSHA
 		} else {
 			ciRef = sourceRef
 		}
@@ -112,6 +131,17 @@ func BuildEvalJob(project *models.Project, event *vcs.WebhookEvent) *models.Job
 		envVars["REACTORCIDE_CI_SOURCE_REF"] = ciRef
 	}
 
+	// Monorepo projects: runnerlib eval reads job definitions from the
+	// project's config path, and skips the event when no changed file
+	// matches its path filters.
+	if project.ConfigPath != "" {
+		envVars["REACTORCIDE_CONFIG_PATH"] = project.ConfigPath
+		jobName = fmt.Sprintf("%s [%s]", jobName, project.ConfigPath)
+	}
+	if len(project.PathFilters) > 0 {
+		envVars["REACTORCIDE_PATH_FILTERS"] = strings.Join(project.PathFilters, ",")
+	}
+
 	// Determine job command
 	jobCommand := project.DefaultJobCommand
 	if jobCommand == "" {
@@ -120,12 +150,12 @@ func BuildEvalJob(project *models.Project, event *vcs.WebhookEvent) *models.Job
 
 	// Determine priority: PRs get higher priority
 	priority := 5
-	if event.PullRequest != nil {
+	if event.PullRequest != nil || event.MergeGroup != nil {
 		priority = 10
 	}
 
 	job := &models.Job{
-		UserID:       config.DefaultUserID,
+		UserID:       config.AutomationUserID(),
 		ProjectID:    &project.ProjectID,
 		Name:         jobName,
 		Description:  fmt.Sprintf("Eval job for %s event on %s", event.GenericEvent, event.Repository.FullName),
@@ -140,6 +170,10 @@ func BuildEvalJob(project *models.Project, event *vcs.WebhookEvent) *models.Job
 		JobEnvVars:   envVars,
 		Priority:     priority,
 		QueueName:    project.DefaultQueueName,
+
+		// A merge group's eval job must cover every job it triggers, since
+		// its status decides whether the merge lands.
+		AwaitChildren: project.AwaitChildJobs || event.MergeGroup != nil,
 	}
 
 	if project.DefaultTimeoutSeconds > 0 {
@@ -0,0 +1,408 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/worker"
+)
+
+// freezeStore is the narrow store capability behind the freeze window
+// endpoints. See postgres_store/freeze_operations.go.
+type freezeStore interface {
+	ListFreezeWindows(ctx context.Context) ([]models.FreezeWindow, error)
+	GetFreezeWindow(ctx context.Context, windowID string) (*models.FreezeWindow, error)
+	CreateFreezeWindow(ctx context.Context, window *models.FreezeWindow) error
+	UpdateFreezeWindow(ctx context.Context, window *models.FreezeWindow) error
+	DeleteFreezeWindow(ctx context.Context, windowID string) error
+	CreateFreezeOverride(ctx context.Context, override *models.FreezeOverride) error
+	ListFreezeOverrides(ctx context.Context, limit, offset int) ([]models.FreezeOverride, error)
+}
+
+// FreezeHandler serves deploy freeze windows and their overrides.
+type FreezeHandler struct {
+	BaseHandler
+	store          store.Store
+	corndogsClient corndogs.ClientInterface
+	now            func() time.Time
+}
+
+// NewFreezeHandler creates a new freeze window handler. corndogsClient may
+// be nil, in which case released jobs are marked submitted only.
+func NewFreezeHandler(store store.Store, corndogsClient corndogs.ClientInterface) *FreezeHandler {
+	return &FreezeHandler{store: store, corndogsClient: corndogsClient, now: time.Now}
+}
+
+// FreezeWindowRequest is the JSON body of the freeze window create and
+// update endpoints. An update changes only the fields it sets.
+type FreezeWindowRequest struct {
+	ProjectID       *string `json:"project_id,omitempty"`
+	Environment     *string `json:"environment,omitempty"`
+	Name            *string `json:"name,omitempty"`
+	Reason          *string `json:"reason,omitempty"`
+	Mode            *string `json:"mode,omitempty"`
+	Schedule        *string `json:"schedule,omitempty"`
+	DurationMinutes *int    `json:"duration_minutes,omitempty"`
+	Timezone        *string `json:"timezone,omitempty"`
+	Manual          *string `json:"manual,omitempty"`
+}
+
+// FreezeWindowStatus is a freeze window and whether it's in effect now.
+// ActiveUntil is when the current scheduled occurrence ends.
+type FreezeWindowStatus struct {
+	models.FreezeWindow
+	Active      bool       `json:"active"`
+	ActiveUntil *time.Time `json:"active_until,omitempty"`
+}
+
+// ListFreezeWindowsResponse is the JSON body of GET
+// /api/v1/admin/freeze-windows.
+type ListFreezeWindowsResponse struct {
+	Windows []FreezeWindowStatus `json:"windows"`
+}
+
+// FreezeStatusResponse is the JSON body of GET
+// /api/v1/projects/{project_id}/freeze: whether deploys of the project, to
+// Environment if given, are frozen now, and by which windows.
+type FreezeStatusResponse struct {
+	ProjectID   string               `json:"project_id"`
+	Environment string               `json:"environment,omitempty"`
+	Frozen      bool                 `json:"frozen"`
+	Windows     []FreezeWindowStatus `json:"windows"`
+}
+
+// FreezeOverrideRequest is the JSON body of POST
+// /api/v1/admin/freeze-overrides.
+type FreezeOverrideRequest struct {
+	JobID  string `json:"job_id"`
+	Reason string `json:"reason"`
+}
+
+// FreezeOverrideResponse is the JSON body of POST
+// /api/v1/admin/freeze-overrides: the recorded override and the released
+// job's status.
+type FreezeOverrideResponse struct {
+	Override  models.FreezeOverride `json:"override"`
+	JobStatus string                `json:"job_status"`
+}
+
+// ListFreezeOverridesResponse is the JSON body of GET
+// /api/v1/admin/freeze-overrides.
+type ListFreezeOverridesResponse struct {
+	Overrides []models.FreezeOverride `json:"overrides"`
+	Limit     int                     `json:"limit"`
+	Offset    int                     `json:"offset"`
+}
+
+func (h *FreezeHandler) freezeStore(w http.ResponseWriter) (freezeStore, bool) {
+	fs, ok := h.store.(freezeStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("freeze windows not available"))
+	}
+	return fs, ok
+}
+
+// ListFreezeWindows handles GET /api/v1/admin/freeze-windows.
+func (h *FreezeHandler) ListFreezeWindows(w http.ResponseWriter, r *http.Request) {
+	fs, ok := h.freezeStore(w)
+	if !ok {
+		return
+	}
+	windows, err := fs.ListFreezeWindows(r.Context())
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	now := h.now()
+	statuses := []FreezeWindowStatus{}
+	for _, window := range windows {
+		statuses = append(statuses, freezeWindowStatus(window, now))
+	}
+	h.respondWithJSON(w, http.StatusOK, ListFreezeWindowsResponse{Windows: statuses})
+}
+
+// GetFreezeWindow handles GET /api/v1/admin/freeze-windows/{window_id}.
+func (h *FreezeHandler) GetFreezeWindow(w http.ResponseWriter, r *http.Request) {
+	fs, ok := h.freezeStore(w)
+	if !ok {
+		return
+	}
+	window, err := fs.GetFreezeWindow(r.Context(), h.getID(r, "window_id"))
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, freezeWindowStatus(*window, h.now()))
+}
+
+// CreateFreezeWindow handles POST /api/v1/admin/freeze-windows.
+func (h *FreezeHandler) CreateFreezeWindow(w http.ResponseWriter, r *http.Request) {
+	fs, ok := h.freezeStore(w)
+	if !ok {
+		return
+	}
+	var req FreezeWindowRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	window := &models.FreezeWindow{Mode: models.FreezeModeHold, Timezone: "UTC"}
+	if user := checkauth.GetUserFromContext(r.Context()); user != nil {
+		window.CreatedBy = &user.UserID
+	}
+	if !h.applyFreezeWindowRequest(w, r, window, req) {
+		return
+	}
+	if err := fs.CreateFreezeWindow(r.Context(), window); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusCreated, freezeWindowStatus(*window, h.now()))
+}
+
+// UpdateFreezeWindow handles PUT /api/v1/admin/freeze-windows/{window_id},
+// e.g. {"manual": "on"} to freeze now. Jobs the change no longer holds are
+// released.
+func (h *FreezeHandler) UpdateFreezeWindow(w http.ResponseWriter, r *http.Request) {
+	fs, ok := h.freezeStore(w)
+	if !ok {
+		return
+	}
+	window, err := fs.GetFreezeWindow(r.Context(), h.getID(r, "window_id"))
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	var req FreezeWindowRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	if !h.applyFreezeWindowRequest(w, r, window, req) {
+		return
+	}
+	if err := fs.UpdateFreezeWindow(r.Context(), window); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.releaseThawed(r.Context())
+	h.respondWithJSON(w, http.StatusOK, freezeWindowStatus(*window, h.now()))
+}
+
+// DeleteFreezeWindow handles DELETE /api/v1/admin/freeze-windows/{window_id}.
+// Jobs it held are released unless another window holds them.
+func (h *FreezeHandler) DeleteFreezeWindow(w http.ResponseWriter, r *http.Request) {
+	fs, ok := h.freezeStore(w)
+	if !ok {
+		return
+	}
+	if err := fs.DeleteFreezeWindow(r.Context(), h.getID(r, "window_id")); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.releaseThawed(r.Context())
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetProjectFreezeStatus handles GET /api/v1/projects/{project_id}/freeze,
+// optionally for one environment (?environment=production).
+func (h *FreezeHandler) GetProjectFreezeStatus(w http.ResponseWriter, r *http.Request) {
+	fs, ok := h.freezeStore(w)
+	if !ok {
+		return
+	}
+	project, err := h.store.GetProjectByID(r.Context(), h.getID(r, "project_id"))
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return
+	}
+	environment := r.URL.Query().Get("environment")
+	windows, err := fs.ListFreezeWindows(r.Context())
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	now := h.now()
+	resp := FreezeStatusResponse{ProjectID: project.ProjectID, Environment: environment, Windows: []FreezeWindowStatus{}}
+	for _, window := range windows {
+		// With no environment given, report windows covering any of the
+		// project's environments.
+		covers := window.ProjectID == nil || *window.ProjectID == project.ProjectID
+		if environment != "" {
+			covers = window.Covers(&models.Job{ProjectID: &project.ProjectID, Environment: environment})
+		}
+		if !covers || !window.ActiveAt(now) {
+			continue
+		}
+		resp.Frozen = true
+		resp.Windows = append(resp.Windows, freezeWindowStatus(window, now))
+	}
+	h.respondWithJSON(w, http.StatusOK, resp)
+}
+
+// CreateFreezeOverride handles POST /api/v1/admin/freeze-overrides: it
+// records why an admin let a held deploy job through the freeze, and
+// releases the job.
+func (h *FreezeHandler) CreateFreezeOverride(w http.ResponseWriter, r *http.Request) {
+	fs, ok := h.freezeStore(w)
+	if !ok {
+		return
+	}
+	var req FreezeOverrideRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.JobID == "" || req.Reason == "" {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: "job_id and reason are required"})
+		return
+	}
+	job, err := h.store.GetJobByID(r.Context(), req.JobID)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if job.Status != models.JobStatusHeld || job.FreezeWindowID == nil {
+		h.respondWithJSON(w, http.StatusConflict, ErrorResponse{Error: "conflict", Message: "job is not held by a freeze window"})
+		return
+	}
+	if is, ok := h.store.(intakeStore); ok {
+		pauses, err := is.ListIntakePauses(r.Context())
+		if err != nil {
+			h.respondWithError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if models.MatchIntakePause(pauses, job) != nil {
+			h.respondWithJSON(w, http.StatusConflict, ErrorResponse{Error: "conflict", Message: "intake is paused for this job; resume intake first"})
+			return
+		}
+	}
+
+	override := &models.FreezeOverride{JobID: job.JobID, WindowID: job.FreezeWindowID, Reason: req.Reason}
+	if user := checkauth.GetUserFromContext(r.Context()); user != nil {
+		override.UserID = &user.UserID
+	}
+	if err := fs.CreateFreezeOverride(r.Context(), override); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if _, err := worker.ReleaseHeldJob(r.Context(), h.store, h.corndogsClient, job); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	status := job.Status
+	if current, err := h.store.GetJobByID(r.Context(), job.JobID); err == nil {
+		status = current.Status
+	}
+	h.respondWithJSON(w, http.StatusCreated, FreezeOverrideResponse{Override: *override, JobStatus: status})
+}
+
+// ListFreezeOverrides handles GET /api/v1/admin/freeze-overrides: the
+// override audit trail, newest first.
+func (h *FreezeHandler) ListFreezeOverrides(w http.ResponseWriter, r *http.Request) {
+	fs, ok := h.freezeStore(w)
+	if !ok {
+		return
+	}
+	limit, offset := 50, 0
+	if v, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && v > 0 && v <= 500 {
+		limit = v
+	}
+	if v, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && v > 0 {
+		offset = v
+	}
+	overrides, err := fs.ListFreezeOverrides(r.Context(), limit, offset)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if overrides == nil {
+		overrides = []models.FreezeOverride{}
+	}
+	h.respondWithJSON(w, http.StatusOK, ListFreezeOverridesResponse{Overrides: overrides, Limit: limit, Offset: offset})
+}
+
+// applyFreezeWindowRequest applies req to window and validates the
+// result, responding 400 if it's invalid.
+func (h *FreezeHandler) applyFreezeWindowRequest(w http.ResponseWriter, r *http.Request, window *models.FreezeWindow, req FreezeWindowRequest) bool {
+	if req.ProjectID != nil {
+		window.ProjectID = nil
+		if *req.ProjectID != "" {
+			if _, err := h.store.GetProjectByID(r.Context(), *req.ProjectID); err != nil {
+				h.respondWithError(w, http.StatusNotFound, err)
+				return false
+			}
+			window.ProjectID = req.ProjectID
+		}
+	}
+	for dst, src := range map[*string]*string{
+		&window.Environment: req.Environment,
+		&window.Name:        req.Name,
+		&window.Reason:      req.Reason,
+		&window.Mode:        req.Mode,
+		&window.Schedule:    req.Schedule,
+		&window.Timezone:    req.Timezone,
+		&window.Manual:      req.Manual,
+	} {
+		if src != nil {
+			*dst = *src
+		}
+	}
+	if req.DurationMinutes != nil {
+		window.DurationMinutes = *req.DurationMinutes
+	}
+	if err := window.Validate(); err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+		return false
+	}
+	return true
+}
+
+// releaseThawed releases the held jobs no freeze window or intake pause
+// holds any more. Failures are left to the periodic sweep.
+func (h *FreezeHandler) releaseThawed(ctx context.Context) {
+	if _, err := worker.ReleaseHeldJobs(ctx, h.store, h.corndogsClient); err != nil {
+		log.Printf("WARNING: Failed to release jobs after a freeze window change: %v", err)
+	}
+}
+
+func freezeWindowStatus(window models.FreezeWindow, now time.Time) FreezeWindowStatus {
+	status := FreezeWindowStatus{FreezeWindow: window, Active: window.ActiveAt(now)}
+	if status.Active {
+		status.ActiveUntil = window.ActiveUntil(now)
+	}
+	return status
+}
+
+// StartFreezeWindowSweep releases held deploy jobs every interval once the
+// freeze windows holding them end. Must be called after GetAppMux (or
+// NewRouter).
+func StartFreezeWindowSweep(ctx context.Context, interval time.Duration) {
+	if _, ok := store.AppStore.(freezeStore); !ok || interval <= 0 {
+		return
+	}
+	corndogsClient := singletoncorndogsClient
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			released, err := worker.ReleaseHeldJobs(ctx, store.AppStore, corndogsClient)
+			if err != nil {
+				log.Printf("WARNING: Failed to release jobs held by ended freeze windows: %v", err)
+			} else if released > 0 {
+				log.Printf("Released %d held jobs", released)
+			}
+		}
+	}()
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// freezeMockStore keeps jobs and freeze overrides in memory on top of
+// ProjectMockStore.
+type freezeMockStore struct {
+	ProjectMockStore
+	jobs      map[string]*models.Job
+	windows   []models.FreezeWindow
+	overrides []models.FreezeOverride
+}
+
+func (m *freezeMockStore) GetJobByID(ctx context.Context, jobID string) (*models.Job, error) {
+	job, ok := m.jobs[jobID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	copied := *job
+	return &copied, nil
+}
+
+func (m *freezeMockStore) UpdateJob(ctx context.Context, job *models.Job) error {
+	copied := *job
+	m.jobs[job.JobID] = &copied
+	return nil
+}
+
+func (m *freezeMockStore) ListFreezeWindows(ctx context.Context) ([]models.FreezeWindow, error) {
+	return m.windows, nil
+}
+
+func (m *freezeMockStore) GetFreezeWindow(ctx context.Context, windowID string) (*models.FreezeWindow, error) {
+	return nil, store.ErrNotFound
+}
+
+func (m *freezeMockStore) CreateFreezeWindow(ctx context.Context, window *models.FreezeWindow) error {
+	return nil
+}
+
+func (m *freezeMockStore) UpdateFreezeWindow(ctx context.Context, window *models.FreezeWindow) error {
+	return nil
+}
+
+func (m *freezeMockStore) DeleteFreezeWindow(ctx context.Context, windowID string) error {
+	return nil
+}
+
+func (m *freezeMockStore) CreateFreezeOverride(ctx context.Context, override *models.FreezeOverride) error {
+	m.overrides = append(m.overrides, *override)
+	return nil
+}
+
+func (m *freezeMockStore) ListFreezeOverrides(ctx context.Context, limit, offset int) ([]models.FreezeOverride, error) {
+	return m.overrides, nil
+}
+
+func TestFreezeHandler_CreateFreezeOverride(t *testing.T) {
+	windowID := "window-1"
+	s := &freezeMockStore{jobs: map[string]*models.Job{
+		"deploy-job": {JobID: "deploy-job", Environment: "production", Status: models.JobStatusHeld, FreezeWindowID: &windowID},
+		"build-job":  {JobID: "build-job", Status: "queued"},
+	}}
+	h := NewFreezeHandler(s, nil)
+	do := func(body string) *httptest.ResponseRecorder {
+		req := withUser(httptest.NewRequest(http.MethodPost, "/api/v1/admin/freeze-overrides", strings.NewReader(body)))
+		w := httptest.NewRecorder()
+		h.CreateFreezeOverride(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusBadRequest, do(`{"job_id":"deploy-job"}`).Code)
+	assert.Equal(t, http.StatusNotFound, do(`{"job_id":"missing-job","reason":"hotfix"}`).Code)
+	assert.Equal(t, http.StatusConflict, do(`{"job_id":"build-job","reason":"hotfix"}`).Code)
+
+	w := do(`{"job_id":"deploy-job","reason":"hotfix for outage"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var resp FreezeOverrideResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, "submitted", resp.JobStatus)
+	require.NotNil(t, resp.Override.WindowID)
+	assert.Equal(t, windowID, *resp.Override.WindowID)
+	require.NotNil(t, resp.Override.UserID)
+	assert.Equal(t, "test-user-id", *resp.Override.UserID)
+
+	require.Len(t, s.overrides, 1)
+	assert.Equal(t, "hotfix for outage", s.overrides[0].Reason)
+	assert.Nil(t, s.jobs["deploy-job"].FreezeWindowID)
+
+	// Already released.
+	assert.Equal(t, http.StatusConflict, do(`{"job_id":"deploy-job","reason":"again"}`).Code)
+}
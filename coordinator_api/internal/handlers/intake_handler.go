@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/worker"
+)
+
+// MaintenanceBannerHeader carries the active maintenance banner on every
+// API response while an intake pause with a banner is in effect.
+const MaintenanceBannerHeader = "X-Reactorcide-Banner"
+
+// intakeStatusTTL bounds how stale the banner header and health status may
+// be on replicas that didn't handle the pause/resume request.
+const intakeStatusTTL = 10 * time.Second
+
+// intakeStore is the narrow store capability behind the admin intake
+// endpoints. See postgres_store/intake_operations.go.
+type intakeStore interface {
+	ListIntakePauses(ctx context.Context) ([]models.IntakePause, error)
+	SetIntakePause(ctx context.Context, pause *models.IntakePause) error
+	DeleteIntakePause(ctx context.Context, scope, target string) error
+}
+
+// MaintenanceStatus is the intake state surfaced to every client: in the
+// health response, and (Banner only) in the MaintenanceBannerHeader.
+type MaintenanceStatus struct {
+	IntakePaused bool   `json:"intake_paused"`
+	Banner       string `json:"banner,omitempty"`
+}
+
+// intakeStatusCache caches the active pauses so the banner header doesn't
+// cost a query per request.
+type intakeStatusCache struct {
+	store   store.Store
+	mu      sync.Mutex
+	fetched time.Time
+	pauses  []models.IntakePause
+}
+
+func newIntakeStatusCache(st store.Store) *intakeStatusCache {
+	return &intakeStatusCache{store: st}
+}
+
+// status returns the cached maintenance status, refreshing it when stale.
+// Lookup failures report no maintenance rather than failing requests.
+func (c *intakeStatusCache) status(ctx context.Context) MaintenanceStatus {
+	is, ok := c.store.(intakeStore)
+	if !ok {
+		return MaintenanceStatus{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Since(c.fetched) > intakeStatusTTL {
+		if pauses, err := is.ListIntakePauses(ctx); err == nil {
+			c.pauses = pauses
+			c.fetched = time.Now()
+		}
+	}
+	return MaintenanceStatus{
+		IntakePaused: len(c.pauses) > 0,
+		Banner:       models.IntakeBanner(c.pauses),
+	}
+}
+
+func (c *intakeStatusCache) invalidate() {
+	c.mu.Lock()
+	c.fetched = time.Time{}
+	c.mu.Unlock()
+}
+
+// bannerMiddleware sets MaintenanceBannerHeader on responses while a banner
+// is active.
+func (c *intakeStatusCache) bannerMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/") {
+			if banner := c.status(r.Context()).Banner; banner != "" {
+				w.Header().Set(MaintenanceBannerHeader, banner)
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// IntakeHandler serves the admin controls for pausing and resuming job
+// intake. Paused jobs are recorded as "held" rather than submitted to
+// Corndogs (see worker.HoldIfPaused).
+type IntakeHandler struct {
+	BaseHandler
+	store          store.Store
+	corndogsClient corndogs.ClientInterface
+	cache          *intakeStatusCache
+}
+
+// NewIntakeHandler creates a new intake handler. cache may be nil.
+func NewIntakeHandler(store store.Store, corndogsClient corndogs.ClientInterface, cache *intakeStatusCache) *IntakeHandler {
+	return &IntakeHandler{
+		store:          store,
+		corndogsClient: corndogsClient,
+		cache:          cache,
+	}
+}
+
+// IntakePauseRequest identifies a pause. Reason and Banner are only read by
+// PauseIntake.
+type IntakePauseRequest struct {
+	Scope  string `json:"scope"`
+	Target string `json:"target,omitempty"`
+	Reason string `json:"reason,omitempty"`
+	Banner string `json:"banner,omitempty"`
+}
+
+// IntakeStatusResponse is the JSON body of the admin intake endpoints.
+type IntakeStatusResponse struct {
+	MaintenanceStatus
+	Pauses []models.IntakePause `json:"pauses"`
+	// Released is the number of held jobs submitted by a resume.
+	Released *int `json:"released,omitempty"`
+}
+
+// GetIntakeStatus handles GET /api/v1/admin/intake
+func (h *IntakeHandler) GetIntakeStatus(w http.ResponseWriter, r *http.Request) {
+	is, ok := h.store.(intakeStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("intake pausing not available"))
+		return
+	}
+	h.respondWithStatus(w, r, is, nil)
+}
+
+// PauseIntake handles POST /api/v1/admin/intake/pause. Pausing an already
+// paused scope replaces its reason and banner.
+func (h *IntakeHandler) PauseIntake(w http.ResponseWriter, r *http.Request) {
+	is, ok := h.store.(intakeStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("intake pausing not available"))
+		return
+	}
+	req, err := h.decodePauseRequest(r)
+	if err != nil {
+		h.respondWithError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Scope == models.IntakeScopeProject {
+		if _, err := h.store.GetProjectByID(r.Context(), req.Target); err != nil {
+			h.respondWithError(w, http.StatusNotFound, err)
+			return
+		}
+	}
+
+	pause := &models.IntakePause{
+		Scope:    req.Scope,
+		Target:   req.Target,
+		Reason:   req.Reason,
+		Banner:   req.Banner,
+		PausedAt: time.Now().UTC(),
+	}
+	if user := checkauth.GetUserFromContext(r.Context()); user != nil {
+		pause.PausedBy = &user.UserID
+	}
+	if err := is.SetIntakePause(r.Context(), pause); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.invalidate()
+	h.respondWithStatus(w, r, is, nil)
+}
+
+// ResumeIntake handles POST /api/v1/admin/intake/resume: it lifts the pause
+// and submits the held jobs no other pause still covers.
+func (h *IntakeHandler) ResumeIntake(w http.ResponseWriter, r *http.Request) {
+	is, ok := h.store.(intakeStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("intake pausing not available"))
+		return
+	}
+	req, err := h.decodePauseRequest(r)
+	if err != nil {
+		h.respondWithError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := is.DeleteIntakePause(r.Context(), req.Scope, req.Target); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.invalidate()
+
+	released, err := worker.ReleaseHeldJobs(r.Context(), h.store, h.corndogsClient)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithStatus(w, r, is, &released)
+}
+
+func (h *IntakeHandler) decodePauseRequest(r *http.Request) (*IntakePauseRequest, error) {
+	var req IntakePauseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, store.ErrInvalidInput
+	}
+	if req.Scope == "" {
+		req.Scope = models.IntakeScopeGlobal
+	}
+	if !models.IsValidIntakeScope(req.Scope) {
+		return nil, store.ErrInvalidInput
+	}
+	// A global pause has no target; the others need one.
+	if (req.Scope == models.IntakeScopeGlobal) != (req.Target == "") {
+		return nil, store.ErrInvalidInput
+	}
+	return &req, nil
+}
+
+func (h *IntakeHandler) invalidate() {
+	if h.cache != nil {
+		h.cache.invalidate()
+	}
+}
+
+func (h *IntakeHandler) respondWithStatus(w http.ResponseWriter, r *http.Request, is intakeStore, released *int) {
+	pauses, err := is.ListIntakePauses(r.Context())
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if pauses == nil {
+		pauses = []models.IntakePause{}
+	}
+	h.respondWithJSON(w, http.StatusOK, IntakeStatusResponse{
+		MaintenanceStatus: MaintenanceStatus{
+			IntakePaused: len(pauses) > 0,
+			Banner:       models.IntakeBanner(pauses),
+		},
+		Pauses:   pauses,
+		Released: released,
+	})
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// intakeMockStore keeps intake pauses in memory on top of ProjectMockStore.
+type intakeMockStore struct {
+	ProjectMockStore
+	pauses []models.IntakePause
+}
+
+func (m *intakeMockStore) ListIntakePauses(ctx context.Context) ([]models.IntakePause, error) {
+	return m.pauses, nil
+}
+
+func (m *intakeMockStore) SetIntakePause(ctx context.Context, pause *models.IntakePause) error {
+	for i := range m.pauses {
+		if m.pauses[i].Scope == pause.Scope && m.pauses[i].Target == pause.Target {
+			m.pauses[i] = *pause
+			return nil
+		}
+	}
+	m.pauses = append(m.pauses, *pause)
+	return nil
+}
+
+func (m *intakeMockStore) DeleteIntakePause(ctx context.Context, scope, target string) error {
+	for i := range m.pauses {
+		if m.pauses[i].Scope == scope && m.pauses[i].Target == target {
+			m.pauses = append(m.pauses[:i], m.pauses[i+1:]...)
+			return nil
+		}
+	}
+	return store.ErrNotFound
+}
+
+func TestIntakeHandler(t *testing.T) {
+	post := func(h *IntakeHandler, action, body string) *httptest.ResponseRecorder {
+		req := withUser(httptest.NewRequest(http.MethodPost, "/api/v1/admin/intake/"+action, strings.NewReader(body)))
+		w := httptest.NewRecorder()
+		if action == "pause" {
+			h.PauseIntake(w, req)
+		} else {
+			h.ResumeIntake(w, req)
+		}
+		return w
+	}
+
+	t.Run("pause and resume", func(t *testing.T) {
+		s := &intakeMockStore{}
+		cache := newIntakeStatusCache(s)
+		h := NewIntakeHandler(s, nil, cache)
+
+		w := post(h, "pause", `{"reason":"pg upgrade","banner":"Maintenance until 14:00 UTC"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp IntakeStatusResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.True(t, resp.IntakePaused)
+		assert.Equal(t, "Maintenance until 14:00 UTC", resp.Banner)
+		require.Len(t, resp.Pauses, 1)
+		assert.Equal(t, models.IntakeScopeGlobal, resp.Pauses[0].Scope)
+		assert.Equal(t, "test-user-id", *resp.Pauses[0].PausedBy)
+
+		rec := httptest.NewRecorder()
+		cache.bannerMiddleware(http.NotFoundHandler()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/jobs", nil))
+		assert.Equal(t, "Maintenance until 14:00 UTC", rec.Header().Get(MaintenanceBannerHeader))
+
+		w = post(h, "resume", `{}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		resp = IntakeStatusResponse{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.False(t, resp.IntakePaused)
+		require.NotNil(t, resp.Released)
+		assert.Empty(t, cache.status(context.Background()).Banner)
+	})
+
+	t.Run("project pause needs an existing project", func(t *testing.T) {
+		s := &intakeMockStore{}
+		w := post(NewIntakeHandler(s, nil, nil), "pause", `{"scope":"project","target":"missing"}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Empty(t, s.pauses)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		for _, body := range []string{`{"scope":"org"}`, `{"scope":"queue"}`, `{"target":"builds"}`, `not json`} {
+			s := &intakeMockStore{}
+			w := post(NewIntakeHandler(s, nil, nil), "pause", body)
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+	})
+
+	t.Run("resume without a pause", func(t *testing.T) {
+		w := post(NewIntakeHandler(&intakeMockStore{}, nil, nil), "resume", `{"scope":"queue","target":"builds"}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("store without intake pausing", func(t *testing.T) {
+		w := post(NewIntakeHandler(&ProjectMockStore{}, nil, nil), "pause", `{}`)
+		assert.NotEqual(t, http.StatusOK, w.Code)
+	})
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/authz"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/jobcontrol"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/metrics"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// BoostJobRequest is the body of POST /api/v1/jobs/{job_id}/boost. The
+// body is optional; without a priority the job is boosted to
+// config.JobBoostPriority, which is also the most a boost can ask for.
+type BoostJobRequest struct {
+	Priority *int `json:"priority,omitempty"`
+}
+
+// BoostJob handles POST /api/v1/jobs/{job_id}/boost: it raises the
+// priority of a job still waiting in Corndogs, so an urgent build stops
+// waiting behind a long queue, and records who boosted it.
+//
+// Authz: boosting jumps a job ahead of everyone else's, so plain ownership
+// isn't enough — see canUserBoostJob. Each user may boost
+// config.JobBoostsPerUserPerHour jobs an hour (per coordinator); a boost
+// that fails doesn't count.
+func (h *JobHandler) BoostJob(w http.ResponseWriter, r *http.Request) {
+	if h.corndogsClient == nil {
+		h.respondWithError(w, http.StatusServiceUnavailable, store.ErrServiceUnavailable)
+		return
+	}
+	jobID := h.getID(r, "job_id")
+	if jobID == "" {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+	var req BoostJobRequest
+	if r.ContentLength != 0 && !h.decodeJSON(w, r, &req) {
+		return
+	}
+	priority := config.JobBoostPriority
+	if req.Priority != nil {
+		if *req.Priority < 0 || *req.Priority > config.JobBoostPriority {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_input",
+				Message: fmt.Sprintf("priority must be between 0 and %d", config.JobBoostPriority),
+			})
+			return
+		}
+		priority = *req.Priority
+	}
+
+	job, err := h.store.GetJobByID(r.Context(), jobID)
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return
+	}
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return
+	}
+	if !h.canUserBoostJob(r.Context(), user, job) {
+		h.respondWithError(w, http.StatusForbidden, store.ErrForbidden)
+		return
+	}
+
+	if retryAfter, ok := h.boostLimiter.take(user.UserID); !ok {
+		w.Header().Set("Retry-After", strconv.Itoa(max(int(retryAfter.Round(time.Second)/time.Second), 1)))
+		h.respondWithJSON(w, http.StatusTooManyRequests, ErrorResponse{
+			Error:   "rate_limited",
+			Message: fmt.Sprintf("at most %d boosts an hour", config.JobBoostsPerUserPerHour),
+		})
+		return
+	}
+	updated, err := jobcontrol.BoostJob(r.Context(), h.store, h.corndogsClient, job, priority, user.UserID)
+	if err != nil {
+		h.boostLimiter.refund(user.UserID)
+	}
+	switch {
+	case errors.Is(err, jobcontrol.ErrNotQueued):
+		h.respondWithJSON(w, http.StatusConflict, ErrorResponse{
+			Error:   "conflict",
+			Message: "job is not waiting in a queue",
+		})
+		return
+	case errors.Is(err, jobcontrol.ErrNoBoost):
+		h.respondWithJSON(w, http.StatusConflict, ErrorResponse{
+			Error:   "conflict",
+			Message: fmt.Sprintf("job is already at priority %d", job.Priority),
+		})
+		return
+	case err != nil:
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	logging.Log.WithFields(map[string]interface{}{
+		"job_id":   updated.JobID,
+		"priority": updated.Priority,
+		"user_id":  user.UserID,
+	}).Info("Boosted queued job")
+	metrics.RecordJobBoost(updated.QueueName)
+	h.respondWithJSON(w, http.StatusOK, h.jobToResponse(updated))
+}
+
+// canUserBoostJob reports whether user may boost job: a global admin, an
+// owner of the job's project, or, for a job outside any project, an admin
+// of the job's org (which, as for kill, covers a user's own jobs). Like
+// canUserKillJob this fails closed to the legacy admin check when
+// h.visibility is nil.
+func (h *JobHandler) canUserBoostJob(ctx context.Context, user *models.User, job *models.Job) bool {
+	if h.isAdmin(user) {
+		return true
+	}
+	if h.visibility == nil {
+		return false
+	}
+	id := authz.IdentityFromUser(user)
+	if job.ProjectID != nil {
+		owner, err := h.visibility.IsProjectOwner(ctx, id, *job.ProjectID)
+		return err == nil && owner
+	}
+	return h.visibility.RequireOrgAdmin(ctx, id, job.UserID) == nil
+}
+
+// userRateLimiter allows each user limit actions per window. A limit of
+// zero or less allows everything.
+type userRateLimiter struct {
+	limit  int
+	window time.Duration
+	now    func() time.Time
+
+	mu    sync.Mutex
+	taken map[string][]time.Time // Per user, oldest first
+}
+
+func newUserRateLimiter(limit int, window time.Duration) *userRateLimiter {
+	return &userRateLimiter{
+		limit:  limit,
+		window: window,
+		now:    time.Now,
+		taken:  make(map[string][]time.Time),
+	}
+}
+
+// take counts an action by userID, if it's allowed. If not, retryAfter is
+// how long until it will be.
+func (l *userRateLimiter) take(userID string) (retryAfter time.Duration, ok bool) {
+	if l == nil || l.limit <= 0 {
+		return 0, true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	recent := l.taken[userID]
+	for len(recent) > 0 && !recent[0].After(now.Add(-l.window)) {
+		recent = recent[1:]
+	}
+	if len(recent) >= l.limit {
+		l.taken[userID] = recent
+		return recent[0].Add(l.window).Sub(now), false
+	}
+	l.taken[userID] = append(recent, now)
+	return 0, true
+}
+
+// refund uncounts userID's latest action.
+func (l *userRateLimiter) refund(userID string) {
+	if l == nil || l.limit <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if recent := l.taken[userID]; len(recent) > 0 {
+		l.taken[userID] = recent[:len(recent)-1]
+	}
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobHandler_BoostJob(t *testing.T) {
+	admin := &models.User{UserID: "admin-1", Roles: []string{"admin"}}
+	boost := func(h *JobHandler, user *models.User, jobID, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/jobs/"+jobID+"/boost", strings.NewReader(body))
+		ctx := checkauth.SetUserContext(req.Context(), user)
+		req = req.WithContext(setIDContext(ctx, "job_id", jobID))
+		w := httptest.NewRecorder()
+		h.BoostJob(w, req)
+		return w
+	}
+
+	t.Run("boosts to the default priority and records who", func(t *testing.T) {
+		st, cd := newQueueFixture()
+		w := boost(NewJobHandler(st, cd), admin, "job-c", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		require.Len(t, cd.SubmitTaskCalls, 1)
+		assert.Equal(t, "reactorcide-jobs-linux-arm64", cd.SubmitTaskCalls[0].Queue)
+		assert.Equal(t, int64(config.JobBoostPriority), cd.SubmitTaskCalls[0].Priority)
+
+		var resp JobResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, config.JobBoostPriority, resp.Priority)
+		require.NotNil(t, resp.BoostedBy)
+		assert.Equal(t, "admin-1", *resp.BoostedBy)
+		assert.NotNil(t, resp.BoostedAt)
+		assert.Equal(t, "admin-1", *st.jobs["job-c"].BoostedBy)
+	})
+
+	t.Run("owner without a resolver is denied", func(t *testing.T) {
+		st, cd := newQueueFixture()
+		w := boost(NewJobHandler(st, cd), &models.User{UserID: "user-1"}, "job-c", "")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Empty(t, cd.SubmitTaskCalls)
+	})
+
+	t.Run("priority above the boost ceiling rejected", func(t *testing.T) {
+		st, cd := newQueueFixture()
+		body, _ := json.Marshal(BoostJobRequest{Priority: intPtr(config.JobBoostPriority + 1)})
+		assert.Equal(t, http.StatusBadRequest, boost(NewJobHandler(st, cd), admin, "job-c", string(body)).Code)
+		assert.Empty(t, cd.SubmitTaskCalls)
+	})
+
+	t.Run("no higher than the job's priority conflicts", func(t *testing.T) {
+		st, cd := newQueueFixture()
+		st.jobs["job-c"].Priority = 50
+		assert.Equal(t, http.StatusConflict, boost(NewJobHandler(st, cd), admin, "job-c", `{"priority":50}`).Code)
+		assert.Empty(t, cd.SubmitTaskCalls)
+	})
+
+	t.Run("rate limited per user", func(t *testing.T) {
+		st, cd := newQueueFixture()
+		h := NewJobHandler(st, cd)
+		h.boostLimiter = newUserRateLimiter(1, time.Hour)
+
+		// A failed boost isn't counted.
+		st.jobs["job-a"].Status = "running"
+		assert.Equal(t, http.StatusConflict, boost(h, admin, "job-a", "").Code)
+
+		require.Equal(t, http.StatusOK, boost(h, admin, "job-b", "").Code)
+		w := boost(h, admin, "job-c", "")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.NotEmpty(t, w.Header().Get("Retry-After"))
+		assert.Len(t, cd.SubmitTaskCalls, 1)
+
+		other := &models.User{UserID: "admin-2", Roles: []string{"admin"}}
+		assert.Equal(t, http.StatusOK, boost(h, other, "job-c", "").Code)
+	})
+}
+
+func TestUserRateLimiter_Window(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := newUserRateLimiter(2, time.Hour)
+	l.now = func() time.Time { return now }
+
+	_, ok := l.take("u")
+	require.True(t, ok)
+	now = now.Add(10 * time.Minute)
+	_, ok = l.take("u")
+	require.True(t, ok)
+	retryAfter, ok := l.take("u")
+	require.False(t, ok)
+	assert.Equal(t, 50*time.Minute, retryAfter)
+
+	now = now.Add(50 * time.Minute)
+	_, ok = l.take("u")
+	assert.True(t, ok, "the first action has left the window")
+}
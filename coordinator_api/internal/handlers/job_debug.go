@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/gorilla/websocket"
+)
+
+// debugSessionStore is the store capability behind the job debug endpoints.
+// See postgres_store/debug_session_operations.go.
+type debugSessionStore interface {
+	GetActiveDebugSession(ctx context.Context, jobID string) (*models.DebugSession, error)
+	EndDebugSession(ctx context.Context, sessionID, status string) (bool, error)
+}
+
+// debugBridgeDialTimeout bounds connecting to a worker's exec bridge.
+const debugBridgeDialTimeout = 10 * time.Second
+
+// debugUpgrader refuses session-cookie upgrades from origins the API
+// doesn't know (see checkWebSocketOrigin).
+var debugUpgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+	CheckOrigin:     checkWebSocketOrigin,
+}
+
+// GetJobDebugSession handles GET /api/v1/jobs/{job_id}/debug: the job's
+// active debug session, or 404 if it has none.
+func (h *JobHandler) GetJobDebugSession(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.activeDebugSession(w, r)
+	if !ok {
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, session)
+}
+
+// CloseJobDebugSession handles DELETE /api/v1/jobs/{job_id}/debug. The
+// worker holding the session notices on its next sweep and releases the
+// debug container and workspace.
+func (h *JobHandler) CloseJobDebugSession(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.activeDebugSession(w, r)
+	if !ok {
+		return
+	}
+	if _, err := h.store.(debugSessionStore).EndDebugSession(r.Context(), session.SessionID, models.DebugSessionClosed); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AttachJobDebugSession handles GET /api/v1/jobs/{job_id}/debug/attach. It
+// upgrades to a WebSocket and proxies it to a shell in the job's debug
+// container via the worker's exec bridge: binary frames carry terminal
+// input and output, and a text frame {"type":"resize","rows":N,"cols":M}
+// resizes the terminal.
+func (h *JobHandler) AttachJobDebugSession(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.activeDebugSession(w, r)
+	if !ok {
+		return
+	}
+
+	bridgeURL := strings.TrimSuffix(session.BridgeURL, "/") + "/debug/sessions/" + session.SessionID + "/attach"
+	if strings.HasPrefix(bridgeURL, "https://") {
+		bridgeURL = "wss://" + strings.TrimPrefix(bridgeURL, "https://")
+	} else {
+		bridgeURL = "ws://" + strings.TrimPrefix(bridgeURL, "http://")
+	}
+	dialCtx, cancel := context.WithTimeout(r.Context(), debugBridgeDialTimeout)
+	defer cancel()
+	upstream, resp, err := websocket.DefaultDialer.DialContext(dialCtx, bridgeURL, http.Header{
+		"Authorization": []string{"Bearer " + session.BridgeToken},
+	})
+	if resp != nil && resp.Body != nil {
+		resp.Body.Close()
+	}
+	if err != nil {
+		h.respondWithError(w, http.StatusBadGateway, errors.New("failed to reach debug session"))
+		return
+	}
+	defer upstream.Close()
+
+	client, err := debugUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer client.Close()
+
+	var once sync.Once
+	done := make(chan struct{})
+	pump := func(dst, src *websocket.Conn) {
+		defer once.Do(func() { close(done) })
+		for {
+			msgType, data, err := src.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := dst.WriteMessage(msgType, data); err != nil {
+				return
+			}
+		}
+	}
+	go pump(upstream, client)
+	go pump(client, upstream)
+	<-done
+}
+
+// activeDebugSession loads the job's active debug session for the debug
+// endpoints, writing the error response itself if it can't. A shell in a
+// job's environment is at least as sensitive as killing it, so these share
+// KillJob's authz (canUserKillJob).
+func (h *JobHandler) activeDebugSession(w http.ResponseWriter, r *http.Request) (*models.DebugSession, bool) {
+	sessions, ok := h.store.(debugSessionStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("debug sessions not available"))
+		return nil, false
+	}
+
+	jobID := h.getID(r, "job_id")
+	if jobID == "" {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return nil, false
+	}
+
+	job, err := h.store.GetJobByID(r.Context(), jobID)
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return nil, false
+	}
+
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return nil, false
+	}
+	if !h.canUserKillJob(r.Context(), user, job) {
+		h.respondWithError(w, http.StatusForbidden, store.ErrForbidden)
+		return nil, false
+	}
+
+	session, err := sessions.GetActiveDebugSession(r.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			h.respondWithError(w, http.StatusNotFound, err)
+		} else {
+			h.respondWithError(w, http.StatusInternalServerError, err)
+		}
+		return nil, false
+	}
+	if !session.IsLive(time.Now().UTC()) {
+		h.respondWithError(w, http.StatusNotFound, store.ErrNotFound)
+		return nil, false
+	}
+	return session, true
+}
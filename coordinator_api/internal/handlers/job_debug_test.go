@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type debugMockStore struct {
+	MockStore
+	session *models.DebugSession
+	ended   []string
+}
+
+func (m *debugMockStore) GetActiveDebugSession(ctx context.Context, jobID string) (*models.DebugSession, error) {
+	if m.session == nil || m.session.JobID != jobID || m.session.Status != models.DebugSessionActive {
+		return nil, store.ErrNotFound
+	}
+	return m.session, nil
+}
+
+func (m *debugMockStore) EndDebugSession(ctx context.Context, sessionID, status string) (bool, error) {
+	if m.session == nil || m.session.SessionID != sessionID || m.session.Status != models.DebugSessionActive {
+		return false, nil
+	}
+	m.session.Status = status
+	m.ended = append(m.ended, status)
+	return true, nil
+}
+
+func newDebugMockStore(bridgeURL string) *debugMockStore {
+	m := &debugMockStore{
+		session: &models.DebugSession{
+			SessionID:   "debug-session-id",
+			JobID:       "test-job-id",
+			WorkerID:    "worker-1",
+			Status:      models.DebugSessionActive,
+			BridgeURL:   bridgeURL,
+			BridgeToken: "bridge-token",
+			ExpiresAt:   time.Now().UTC().Add(time.Hour),
+		},
+	}
+	m.GetJobByIDFunc = func(ctx context.Context, jobID string) (*models.Job, error) {
+		return &models.Job{JobID: jobID, Status: "failed", UserID: "test-user-id", DebugOnFailure: true}, nil
+	}
+	return m
+}
+
+func debugRequest(method, path string, user *models.User) *http.Request {
+	req := httptest.NewRequest(method, path, nil)
+	ctx := checkauth.SetUserContext(req.Context(), user)
+	ctx = context.WithValue(ctx, GetContextKey("job_id"), "test-job-id")
+	return req.WithContext(ctx)
+}
+
+func TestJobHandler_GetJobDebugSession(t *testing.T) {
+	admin := &models.User{UserID: "test-user-id", Roles: []string{"admin"}}
+
+	t.Run("active session", func(t *testing.T) {
+		handler := NewJobHandler(newDebugMockStore("http://worker:7070"), nil)
+		w := httptest.NewRecorder()
+		handler.GetJobDebugSession(w, debugRequest("GET", "/api/v1/jobs/test-job-id/debug", admin))
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), `"session_id":"debug-session-id"`)
+		assert.NotContains(t, w.Body.String(), "bridge-token")
+		assert.NotContains(t, w.Body.String(), "worker:7070")
+	})
+
+	t.Run("no session", func(t *testing.T) {
+		mockStore := newDebugMockStore("http://worker:7070")
+		mockStore.session = nil
+		handler := NewJobHandler(mockStore, nil)
+		w := httptest.NewRecorder()
+		handler.GetJobDebugSession(w, debugRequest("GET", "/api/v1/jobs/test-job-id/debug", admin))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("expired session", func(t *testing.T) {
+		mockStore := newDebugMockStore("http://worker:7070")
+		mockStore.session.ExpiresAt = time.Now().UTC().Add(-time.Minute)
+		handler := NewJobHandler(mockStore, nil)
+		w := httptest.NewRecorder()
+		handler.GetJobDebugSession(w, debugRequest("GET", "/api/v1/jobs/test-job-id/debug", admin))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("owner without admin is denied", func(t *testing.T) {
+		handler := NewJobHandler(newDebugMockStore("http://worker:7070"), nil)
+		w := httptest.NewRecorder()
+		owner := &models.User{UserID: "test-user-id"}
+		handler.GetJobDebugSession(w, debugRequest("GET", "/api/v1/jobs/test-job-id/debug", owner))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("store without debug sessions", func(t *testing.T) {
+		handler := NewJobHandler(&MockStore{}, nil)
+		w := httptest.NewRecorder()
+		handler.GetJobDebugSession(w, debugRequest("GET", "/api/v1/jobs/test-job-id/debug", admin))
+		assert.NotEqual(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestJobHandler_CloseJobDebugSession(t *testing.T) {
+	mockStore := newDebugMockStore("http://worker:7070")
+	handler := NewJobHandler(mockStore, nil)
+	admin := &models.User{UserID: "test-user-id", Roles: []string{"admin"}}
+
+	w := httptest.NewRecorder()
+	handler.CloseJobDebugSession(w, debugRequest("DELETE", "/api/v1/jobs/test-job-id/debug", admin))
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	assert.Equal(t, []string{models.DebugSessionClosed}, mockStore.ended)
+
+	w = httptest.NewRecorder()
+	handler.CloseJobDebugSession(w, debugRequest("DELETE", "/api/v1/jobs/test-job-id/debug", admin))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestJobHandler_AttachJobDebugSession(t *testing.T) {
+	// Fake worker bridge: echoes frames back, prefixed, once the session's
+	// bearer token checks out.
+	var bridgePath, bridgeAuth string
+	upgrader := websocket.Upgrader{}
+	bridge := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bridgePath = r.URL.Path
+		bridgeAuth = r.Header.Get("Authorization")
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		for {
+			msgType, data, err := ws.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := ws.WriteMessage(msgType, append([]byte("echo:"), data...)); err != nil {
+				return
+			}
+		}
+	}))
+	defer bridge.Close()
+
+	handler := NewJobHandler(newDebugMockStore(bridge.URL), nil)
+	admin := &models.User{UserID: "test-user-id", Roles: []string{"admin"}}
+	coordinator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := checkauth.SetUserContext(r.Context(), admin)
+		ctx = context.WithValue(ctx, GetContextKey("job_id"), "test-job-id")
+		handler.AttachJobDebugSession(w, r.WithContext(ctx))
+	}))
+	defer coordinator.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(coordinator.URL, "http") + "/api/v1/jobs/test-job-id/debug/attach"
+	client, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	require.NoError(t, err)
+	defer client.Close()
+
+	require.NoError(t, client.WriteMessage(websocket.BinaryMessage, []byte("ls\n")))
+	client.SetReadDeadline(time.Now().Add(5 * time.Second))
+	msgType, data, err := client.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, websocket.BinaryMessage, msgType)
+	assert.Equal(t, "echo:ls\n", string(data))
+
+	assert.Equal(t, "/debug/sessions/debug-session-id/attach", bridgePath)
+	assert.Equal(t, "Bearer bridge-token", bridgeAuth)
+}
+
+func TestJobHandler_AttachJobDebugSession_CrossOriginSession(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	bridge := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		ws.ReadMessage()
+	}))
+	defer bridge.Close()
+
+	handler := NewJobHandler(newDebugMockStore(bridge.URL), nil)
+	admin := &models.User{UserID: "test-user-id", Roles: []string{"admin"}}
+	coordinator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := checkauth.SetUserContext(r.Context(), admin)
+		ctx = checkauth.SetSessionContext(ctx, &models.UISession{UserID: admin.UserID})
+		ctx = context.WithValue(ctx, GetContextKey("job_id"), "test-job-id")
+		handler.AttachJobDebugSession(w, r.WithContext(ctx))
+	}))
+	defer coordinator.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(coordinator.URL, "http") + "/api/v1/jobs/test-job-id/debug/attach"
+	_, resp, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Origin": {"https://evil.example.com"}})
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	client, _, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Origin": {coordinator.URL}})
+	require.NoError(t, err, "same-host origin")
+	client.Close()
+}
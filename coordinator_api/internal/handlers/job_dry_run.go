@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/hooks"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/secrets"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/worker"
+)
+
+// How a dry-run job's secret reference would fare when the worker
+// resolves it.
+const (
+	DryRunSecretOK        = "ok"
+	DryRunSecretMissing   = "missing"
+	DryRunSecretDenied    = "denied"
+	DryRunSecretUnchecked = "unchecked"
+)
+
+// DryRunSecretRef is a ${secret:path:key} or secretref:// reference in a
+// dry-run job's environment and whether the job could read it. It never
+// carries the secret's value.
+type DryRunSecretRef struct {
+	Env     string `json:"env"`
+	Path    string `json:"path"`
+	Key     string `json:"key"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// JobDryRunResponse is the JSON body of POST /api/v1/jobs?dry_run=true:
+// the job that would be saved, without a job ID, and the Corndogs task
+// that would be submitted for it.
+type JobDryRunResponse struct {
+	DryRun          bool                  `json:"dry_run"`
+	Job             JobResponse           `json:"job"`
+	CorndogsPayload *corndogs.TaskPayload `json:"corndogs_payload"`
+	Priority        int64                 `json:"priority"`
+	SecretRefs      []DryRunSecretRef     `json:"secret_refs"`
+	// Warnings are what would fail the job once it runs, though the
+	// submission itself would be accepted.
+	Warnings []string `json:"warnings"`
+}
+
+// dryRunJob describes what creating job would do, for a request that
+// passed every check a real one goes through. Secret references are
+// resolved as far as they can be without reading a value: the
+// pre_secret_inject hooks, the job's secret grants and whether the secret
+// exists.
+func (h *JobHandler) dryRunJob(ctx context.Context, job *models.Job) JobDryRunResponse {
+	now := time.Now()
+	job.CreatedAt, job.UpdatedAt = now, now
+
+	refs := h.dryRunSecretRefs(ctx, job)
+	warnings := []string{}
+	for _, ref := range refs {
+		if ref.Status == DryRunSecretMissing || ref.Status == DryRunSecretDenied {
+			warnings = append(warnings, "env "+ref.Env+": "+ref.Message)
+		}
+	}
+	return JobDryRunResponse{
+		DryRun:          true,
+		Job:             h.jobToResponse(job),
+		CorndogsPayload: jobTaskPayload(job),
+		Priority:        int64(job.Priority),
+		SecretRefs:      refs,
+		Warnings:        warnings,
+	}
+}
+
+// dryRunSecretRefs checks each secret reference in job's environment the
+// way the worker would resolve it.
+func (h *JobHandler) dryRunSecretRefs(ctx context.Context, job *models.Job) []DryRunSecretRef {
+	hookRefs := worker.SecretRefs(job.JobEnvVars.EnvStrings())
+	refs := make([]DryRunSecretRef, len(hookRefs))
+	for i, ref := range hookRefs {
+		refs[i] = DryRunSecretRef{Env: ref.Env, Path: ref.Path, Key: ref.Key}
+	}
+	if len(refs) == 0 {
+		return refs
+	}
+
+	if err := hooks.CheckSecretInject(ctx, job, hookRefs); err != nil {
+		for i := range refs {
+			refs[i].Status, refs[i].Message = DryRunSecretDenied, err.Error()
+		}
+		return refs
+	}
+
+	provider, status, message := dryRunSecretsProvider(ctx, h.store, job)
+	for i := range refs {
+		ref := &refs[i]
+		if strings.HasPrefix(ref.Path, "jobs/") {
+			// Job-scoped secrets are written for a job once it exists.
+			ref.Status, ref.Message = DryRunSecretUnchecked, "job-scoped secrets can't exist before the job"
+			continue
+		}
+		if err := worker.AuthorizeSecretAccess(ctx, h.store, job, ref.Path, ref.Key); err != nil {
+			ref.Status, ref.Message = DryRunSecretDenied, err.Error()
+			continue
+		}
+		if provider == nil {
+			ref.Status, ref.Message = status, message
+			continue
+		}
+		keys, err := provider.ListKeys(ctx, ref.Path)
+		switch {
+		case err != nil:
+			ref.Status, ref.Message = DryRunSecretUnchecked, err.Error()
+		case slices.Contains(keys, ref.Key):
+			ref.Status = DryRunSecretOK
+		default:
+			ref.Status, ref.Message = DryRunSecretMissing, "secret not found: "+ref.Path+":"+ref.Key
+		}
+	}
+	return refs
+}
+
+// dryRunSecretsProvider returns the provider the worker would read job's
+// secrets from, or nil and the status and reason every reference gets
+// without one.
+func dryRunSecretsProvider(ctx context.Context, st store.Store, job *models.Job) (secrets.Provider, string, string) {
+	switch config.SecretsStorageType {
+	case "", "database":
+	case "none", "disabled":
+		return nil, DryRunSecretMissing, "secrets are disabled"
+	default:
+		return nil, DryRunSecretUnchecked, "secrets in " + config.SecretsStorageType + " storage are only checked by the worker"
+	}
+	db := store.GetDBFromContext(ctx)
+	if singletonKeyManager == nil || db == nil {
+		return nil, DryRunSecretUnchecked, "secrets are not configured on the coordinator"
+	}
+	orgKey, err := singletonKeyManager.GetOrgEncryptionKey(db, job.UserID)
+	if errors.Is(err, secrets.ErrNotInitialized) {
+		return nil, DryRunSecretMissing, "secrets are not initialized for the job's owner"
+	}
+	if err != nil {
+		return nil, DryRunSecretUnchecked, err.Error()
+	}
+	provider, err := secrets.NewDatabaseProvider(db, job.UserID, orgKey)
+	if err != nil {
+		return nil, DryRunSecretUnchecked, err.Error()
+	}
+	access, err := worker.JobSecretAccessPolicy(ctx, st, job)
+	if err != nil {
+		return nil, DryRunSecretUnchecked, err.Error()
+	}
+	provider.RestrictTo(access)
+	return provider, "", ""
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	pb "github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs/v1alpha1"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/hooks"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dryRunGrantStore grants its jobs the secrets under deploy/.
+type dryRunGrantStore struct {
+	*MockStore
+}
+
+func (dryRunGrantStore) ListSecretGrantsForJob(ctx context.Context, userID string, projectID *string, jobName string) ([]models.SecretGrant, error) {
+	return []models.SecretGrant{{Name: "deploy", SecretPathMatch: models.SecretGrantMatchPrefix, SecretPathPattern: "deploy"}}, nil
+}
+
+func TestJobHandler_CreateJob_DryRun(t *testing.T) {
+	mockStore := &MockStore{
+		CreateJobFunc: func(ctx context.Context, job *models.Job) error {
+			t.Fatal("a dry run never saves the job")
+			return nil
+		},
+	}
+	mockCorndogs := &corndogs.MockClient{
+		SubmitTaskFunc: func(ctx context.Context, payload *corndogs.TaskPayload, priority int64) (*pb.Task, error) {
+			t.Fatal("a dry run never submits the job")
+			return nil, nil
+		},
+	}
+	handler := NewJobHandler(dryRunGrantStore{mockStore}, mockCorndogs)
+	var hookDryRun bool
+	defer hooks.Register(hooks.PreJobCreate, "observe", hooks.HookFunc(func(ctx context.Context, event *hooks.Event) error {
+		hookDryRun = event.DryRun
+		return nil
+	}))()
+
+	body := `{"name":"deploy","job_command":"make deploy","source_type":"git","source_url":"https://github.com/test/repo.git",
+		"priority":7,"job_env_vars":{"DB_PASSWORD":"${secret:deploy/db:password}","API_KEY":"secretref://other/api/key","PLAIN":"x"}}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/jobs?dry_run=true", strings.NewReader(body))
+	req = req.WithContext(checkauth.SetUserContext(req.Context(), &models.User{UserID: "test-user-id"}))
+	w := httptest.NewRecorder()
+	handler.CreateJob(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.True(t, hookDryRun, "hooks are told it's a dry run")
+
+	var resp JobDryRunResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, resp.DryRun)
+	assert.Empty(t, resp.Job.JobID)
+	assert.Equal(t, "deploy", resp.Job.Name)
+	assert.Equal(t, "reactorcide-jobs", resp.Job.QueueName)
+	require.NotNil(t, resp.CorndogsPayload)
+	assert.Equal(t, "run", resp.CorndogsPayload.JobType)
+	assert.Equal(t, "make deploy", resp.CorndogsPayload.Config["command"])
+	assert.Equal(t, "https://github.com/test/repo.git", resp.CorndogsPayload.Source["url"])
+	assert.EqualValues(t, 7, resp.Priority)
+
+	require.Len(t, resp.SecretRefs, 2)
+	assert.Equal(t, DryRunSecretRef{Env: "API_KEY", Path: "other/api", Key: "key", Status: DryRunSecretDenied, Message: "secret access denied for other/api:key"}, resp.SecretRefs[0])
+	assert.Equal(t, "DB_PASSWORD", resp.SecretRefs[1].Env)
+	assert.Equal(t, DryRunSecretUnchecked, resp.SecretRefs[1].Status, "without a key manager secrets can't be looked up")
+	assert.Equal(t, []string{"env API_KEY: secret access denied for other/api:key"}, resp.Warnings)
+}
+
+func TestJobHandler_CreateJob_DryRunRejects(t *testing.T) {
+	handler := NewJobHandler(&MockStore{}, nil)
+	defer hooks.Register(hooks.PreJobCreate, "ticket", hooks.HookFunc(func(ctx context.Context, event *hooks.Event) error {
+		return fmt.Errorf("job name needs a ticket number")
+	}))()
+
+	for body, want := range map[string]int{
+		`{"name":"deploy","job_command":"make","source_type":"copy","source_path":"/src"}`: http.StatusForbidden,
+		`{"name":"deploy","source_type":"copy","source_path":"/src"}`:                      http.StatusBadRequest,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/jobs?dry_run=true", strings.NewReader(body))
+		req = req.WithContext(checkauth.SetUserContext(req.Context(), &models.User{UserID: "test-user-id"}))
+		w := httptest.NewRecorder()
+		handler.CreateJob(w, req)
+		assert.Equal(t, want, w.Code, body)
+	}
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/jobcontrol"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// Page sizes of GET /api/v1/admin/exports/jobs.
+const (
+	defaultJobExportLimit = 1000
+	maxJobExportLimit     = 10000
+)
+
+// jobExportStore is the narrow store capability behind
+// GET /api/v1/admin/exports/jobs. See
+// postgres_store/job_export_operations.go.
+type jobExportStore interface {
+	ListJobsForExport(ctx context.Context, filter store.JobExportFilter, limit int) ([]models.Job, error)
+}
+
+// ExportJobs handles GET /api/v1/admin/exports/jobs: job records for a
+// data warehouse, archived jobs included, oldest first. format is ndjson
+// (the default) or csv, one jobcontrol.JobExportRow per line; rows carry
+// their schema_version. since and until (RFC 3339) bound the jobs'
+// created_at, since inclusive and until exclusive; project_id narrows it
+// to one project. A full page of limit rows (default 1000, at most 10000)
+// sets the X-Next-Cursor header, to pass as ?cursor= for the next page.
+// Admin only; the route applies the role check.
+func (h *JobHandler) ExportJobs(w http.ResponseWriter, r *http.Request) {
+	exportStore, ok := h.store.(jobExportStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("job exports not available"))
+		return
+	}
+
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = jobcontrol.JobExportNDJSON
+	}
+	if format != jobcontrol.JobExportNDJSON && format != jobcontrol.JobExportCSV {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: "format must be ndjson or csv"})
+		return
+	}
+	filter := store.JobExportFilter{ProjectID: query.Get("project_id")}
+	limit := defaultJobExportLimit
+	var err error
+	if raw := query.Get("since"); raw != "" {
+		if filter.CreatedFrom, err = time.Parse(time.RFC3339, raw); err != nil {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: "since must be an RFC 3339 time"})
+			return
+		}
+	}
+	if raw := query.Get("until"); raw != "" {
+		if filter.CreatedBefore, err = time.Parse(time.RFC3339, raw); err != nil {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: "until must be an RFC 3339 time"})
+			return
+		}
+	}
+	if raw := query.Get("cursor"); raw != "" {
+		cursor, err := store.ParseJobCursor(raw)
+		if err != nil {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: "Invalid cursor"})
+			return
+		}
+		filter.After = &cursor
+	}
+	if raw := query.Get("limit"); raw != "" {
+		if limit, err = strconv.Atoi(raw); err != nil || limit < 1 || limit > maxJobExportLimit {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_input",
+				Message: fmt.Sprintf("limit must be between 1 and %d", maxJobExportLimit),
+			})
+			return
+		}
+	}
+
+	jobs, err := exportStore.ListJobsForExport(r.Context(), filter, limit)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if format == jobcontrol.JobExportCSV {
+		w.Header().Set("Content-Type", "text/csv")
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	w.Header().Set("X-Export-Schema-Version", strconv.Itoa(jobcontrol.JobExportSchemaVersion))
+	if next := nextJobCursor(jobs, limit); next != "" {
+		w.Header().Set("X-Next-Cursor", next)
+	}
+	w.WriteHeader(http.StatusOK)
+
+	writer, err := jobcontrol.NewJobExportWriter(w, format)
+	if err == nil {
+		for i := range jobs {
+			if err = writer.Write(&jobs[i]); err != nil {
+				break
+			}
+		}
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err != nil {
+		logging.Log.WithError(err).Warn("Failed to write job export")
+	}
+}
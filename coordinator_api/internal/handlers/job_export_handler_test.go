@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/jobcontrol"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// jobExportMockStore serves export pages on top of MockStore, recording
+// the filter it was asked for.
+type jobExportMockStore struct {
+	MockStore
+	jobs   []models.Job
+	filter store.JobExportFilter
+	limit  int
+}
+
+func (m *jobExportMockStore) ListJobsForExport(ctx context.Context, filter store.JobExportFilter, limit int) ([]models.Job, error) {
+	m.filter, m.limit = filter, limit
+	if len(m.jobs) > limit {
+		return m.jobs[:limit], nil
+	}
+	return m.jobs, nil
+}
+
+func TestJobHandler_ExportJobs(t *testing.T) {
+	created := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	completed := created.Add(time.Minute)
+	s := &jobExportMockStore{jobs: []models.Job{
+		{JobID: "11111111-1111-1111-1111-111111111111", Name: "build", Status: "completed", CreatedAt: created, StartedAt: &created, CompletedAt: &completed},
+		{JobID: "22222222-2222-2222-2222-222222222222", Name: "test", Status: "running", CreatedAt: created.Add(time.Second)},
+	}}
+	handler := NewJobHandler(s, nil)
+	export := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/exports/jobs"+query, nil)
+		w := httptest.NewRecorder()
+		handler.ExportJobs(w, withUser(req))
+		return w
+	}
+
+	t.Run("ndjson with filters", func(t *testing.T) {
+		w := export("?project_id=p1&since=2026-03-01T00:00:00Z&until=2026-03-02T00:00:00Z")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+		assert.Empty(t, w.Header().Get("X-Next-Cursor"), "a short page is the last")
+		assert.Equal(t, store.JobExportFilter{
+			ProjectID:     "p1",
+			CreatedFrom:   time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+			CreatedBefore: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+		}, s.filter)
+		assert.Equal(t, defaultJobExportLimit, s.limit)
+
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		require.Len(t, lines, 2)
+		var row jobcontrol.JobExportRow
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &row))
+		assert.Equal(t, jobcontrol.JobExportSchemaVersion, row.SchemaVersion)
+		assert.Equal(t, "build", row.Name)
+		require.NotNil(t, row.RunSeconds)
+		assert.Equal(t, 60.0, *row.RunSeconds)
+	})
+
+	t.Run("csv pages", func(t *testing.T) {
+		w := export("?format=csv&limit=1")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+		records, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, jobcontrol.JobExportColumns, records[0])
+
+		next := w.Header().Get("X-Next-Cursor")
+		require.NotEmpty(t, next)
+		w = export("?format=csv&cursor=" + next)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NotNil(t, s.filter.After)
+		assert.Equal(t, "11111111-1111-1111-1111-111111111111", s.filter.After.JobID)
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		for _, query := range []string{"?format=xml", "?since=yesterday", "?until=2026-03-01", "?cursor=nope", "?limit=0", "?limit=100000"} {
+			assert.Equal(t, http.StatusBadRequest, export(query).Code, query)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// scanFindingStore is the store support for scan jobs' findings.
+type scanFindingStore interface {
+	ListScanFindings(ctx context.Context, jobID string) ([]models.ScanFinding, error)
+}
+
+// JobFindingsResponse is the JSON body of GET /api/v1/jobs/{job_id}/findings.
+// Counts covers every finding, whatever the severity filter.
+type JobFindingsResponse struct {
+	JobID    string               `json:"job_id"`
+	Scanner  string               `json:"scanner"`
+	Counts   map[string]int       `json:"counts"`
+	Findings []models.ScanFinding `json:"findings"`
+}
+
+// ListJobFindings handles GET /api/v1/jobs/{job_id}/findings
+//
+// Returns the vulnerabilities a scan job found, most severe first,
+// optionally only those of ?severity=. Access is as for GetJob.
+func (h *JobHandler) ListJobFindings(w http.ResponseWriter, r *http.Request) {
+	jobID := h.getID(r, "job_id")
+	if jobID == "" {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+
+	job, err := h.store.GetJobByID(r.Context(), jobID)
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return
+	}
+
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return
+	}
+	if !h.canUserViewJob(r.Context(), user, job) {
+		h.respondWithError(w, http.StatusForbidden, store.ErrForbidden)
+		return
+	}
+	if job.Scan == nil {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: "job is not a scan job"})
+		return
+	}
+
+	severity := r.URL.Query().Get("severity")
+	if severity != "" && models.NormalizeSeverity(severity) != severity {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: "severity must be one of critical, high, medium, low, unknown"})
+		return
+	}
+
+	fs, ok := h.store.(scanFindingStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("scan findings not available"))
+		return
+	}
+	findings, err := fs.ListScanFindings(r.Context(), job.JobID)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response := JobFindingsResponse{
+		JobID:    job.JobID,
+		Scanner:  job.Scan.Scanner,
+		Counts:   models.CountFindings(findings),
+		Findings: []models.ScanFinding{},
+	}
+	for _, f := range findings {
+		if severity == "" || f.Severity == severity {
+			response.Findings = append(response.Findings, f)
+		}
+	}
+	h.respondWithJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// findingsMockStore serves a scan job and its findings.
+type findingsMockStore struct {
+	MockStore
+	findings []models.ScanFinding
+}
+
+func (m *findingsMockStore) ListScanFindings(ctx context.Context, jobID string) ([]models.ScanFinding, error) {
+	return m.findings, nil
+}
+
+func TestJobHandler_ListJobFindings(t *testing.T) {
+	m := &findingsMockStore{findings: []models.ScanFinding{
+		{VulnerabilityID: "CVE-1", Severity: models.SeverityCritical, Package: "openssl"},
+		{VulnerabilityID: "CVE-2", Severity: models.SeverityHigh, Package: "zlib"},
+		{VulnerabilityID: "CVE-3", Severity: models.SeverityHigh, Package: "curl"},
+	}}
+	scan := &models.ScanSpec{Scanner: models.ScannerTrivy, Target: models.ScanTargetImage, Image: "app:1"}
+	m.GetJobByIDFunc = func(ctx context.Context, jobID string) (*models.Job, error) {
+		job := &models.Job{JobID: jobID, UserID: "test-user-id"}
+		if jobID == "scan-job" {
+			job.Scan = scan
+		}
+		return job, nil
+	}
+	h := NewJobHandler(m, nil)
+	get := func(jobID, query string, user *models.User) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/jobs/"+jobID+"/findings"+query, nil)
+		ctx := checkauth.SetUserContext(req.Context(), user)
+		req = req.WithContext(setIDContext(ctx, "job_id", jobID))
+		w := httptest.NewRecorder()
+		h.ListJobFindings(w, req)
+		return w
+	}
+	owner := &models.User{UserID: "test-user-id"}
+
+	w := get("scan-job", "?severity=high", owner)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp JobFindingsResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, models.ScannerTrivy, resp.Scanner)
+	assert.Equal(t, 1, resp.Counts[models.SeverityCritical], "counts cover every finding")
+	assert.Equal(t, 2, resp.Counts[models.SeverityHigh])
+	require.Len(t, resp.Findings, 2)
+	assert.Equal(t, "CVE-2", resp.Findings[0].VulnerabilityID)
+
+	assert.Equal(t, http.StatusBadRequest, get("scan-job", "?severity=severe", owner).Code)
+	assert.Equal(t, http.StatusBadRequest, get("build-job", "", owner).Code, "not a scan job")
+	assert.Equal(t, http.StatusForbidden, get("scan-job", "", &models.User{UserID: "someone-else"}).Code)
+}
@@ -17,9 +17,11 @@ import (
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/hooks"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/jobcontrol"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/metrics"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/repourl"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
@@ -28,10 +30,13 @@ import (
 
 // LogEntry represents a single log line in JSON format (matches worker.LogEntry)
 type LogEntry struct {
-	Timestamp string `json:"timestamp"`
-	Stream    string `json:"stream"`
-	Level     string `json:"level,omitempty"`
-	Message   string `json:"message"`
+	Timestamp  string                `json:"timestamp"`
+	Stream     string                `json:"stream"`
+	Level      string                `json:"level,omitempty"`
+	Message    string                `json:"message"`
+	Group      string                `json:"group,omitempty"`
+	Section    string                `json:"section,omitempty"`
+	Annotation *worker.LogAnnotation `json:"annotation,omitempty"`
 }
 
 // JobHandler handles job-related HTTP requests
@@ -56,6 +61,11 @@ type JobHandler struct {
 	// org or a global admin, which is a NARROWER grant than owner-or-admin,
 	// not a wider one. See UI_AUTH_PLAN.md task D.
 	visibility *authz.Resolver
+	// urlSigner signs download URLs for object stores that can't presign
+	// (see SetURLSigner).
+	urlSigner *objects.URLSigner
+	// boostLimiter limits how many jobs each user boosts an hour.
+	boostLimiter *userRateLimiter
 }
 
 // NewJobHandler creates a new job handler
@@ -65,6 +75,7 @@ func NewJobHandler(store store.Store, corndogsClient corndogs.ClientInterface) *
 		corndogsClient:   corndogsClient,
 		triggerProcessor: worker.NewTriggerProcessor(store, corndogsClient),
 		visibility:       roleStoreResolver(store, "JobHandler"),
+		boostLimiter:     newUserRateLimiter(config.JobBoostsPerUserPerHour, time.Hour),
 	}
 }
 
@@ -76,6 +87,7 @@ func NewJobHandlerWithObjectStore(store store.Store, corndogsClient corndogs.Cli
 		objectStore:      objectStore,
 		triggerProcessor: worker.NewTriggerProcessor(store, corndogsClient),
 		visibility:       roleStoreResolver(store, "JobHandler"),
+		boostLimiter:     newUserRateLimiter(config.JobBoostsPerUserPerHour, time.Hour),
 	}
 }
 
@@ -94,11 +106,16 @@ type CreateJobRequest struct {
 	Description string `json:"description,omitempty"`
 	JobFile     string `json:"job_file,omitempty"`
 
+	// ProjectID is set for jobs submitted with a project-bound API token,
+	// whose jobs belong to the token's project. Other callers run a
+	// project with POST /api/v1/projects/{project_id}/run instead.
+	ProjectID string `json:"project_id,omitempty"`
+
 	// Source configuration (VCS-agnostic: works with git, mercurial, svn, etc.)
 	// This is the untrusted source code being tested (e.g., PR code)
 	SourceURL  string `json:"source_url,omitempty"`
 	SourceRef  string `json:"source_ref,omitempty"`
-	SourceType string `json:"source_type" validate:"required,oneof=git copy"`
+	SourceType string `json:"source_type" validate:"required,oneof=git copy upload oci"`
 	SourcePath string `json:"source_path,omitempty"`
 
 	// CI Source configuration (trusted CI pipeline code - optional)
@@ -114,14 +131,46 @@ type CreateJobRequest struct {
 	RunnerImage string `json:"runner_image,omitempty"`
 
 	// Environment configuration
-	JobEnvVars map[string]string `json:"job_env_vars,omitempty"`
-	JobEnvFile string            `json:"job_env_file,omitempty"`
+	JobEnvVars models.EnvVars `json:"job_env_vars,omitempty"`
+	JobEnvFile string         `json:"job_env_file,omitempty"`
 
 	// Execution settings
 	TimeoutSeconds *int   `json:"timeout_seconds,omitempty"`
 	Priority       *int   `json:"priority,omitempty"`
 	RunAsUser      string `json:"run_as_user,omitempty"`
 	QueueName      string `json:"queue_name,omitempty"`
+
+	// TargetOS and TargetArch route the job to workers of that platform,
+	// e.g. "windows"/"amd64" or "darwin"/"arm64". Omitted, it runs on the
+	// default linux workers.
+	TargetOS   string `json:"target_os,omitempty"`
+	TargetArch string `json:"target_arch,omitempty"`
+
+	// Affinity "project" prefers a worker that recently ran the project;
+	// AvoidWorkers lists worker IDs or globs the job must not run on.
+	Affinity     string   `json:"affinity,omitempty"`
+	AvoidWorkers []string `json:"avoid_workers,omitempty"`
+
+	// Environment makes the job a deploy to that environment, which freeze
+	// windows covering it hold.
+	Environment string `json:"environment,omitempty"`
+
+	// Scan makes the job a built-in vulnerability scan; its scanner's
+	// image and command replace runner_image and job_command, which may
+	// then be left out.
+	Scan *models.ScanSpec `json:"scan,omitempty"`
+
+	// AwaitChildren keeps the job "running" until every job it triggers
+	// has finished, then lands on their aggregate result.
+	AwaitChildren bool `json:"await_children,omitempty"`
+
+	// DebugOnFailure keeps the job's environment alive for a bounded time
+	// if it fails, for an interactive debug session (GET .../debug/attach).
+	DebugOnFailure bool `json:"debug_on_failure,omitempty"`
+
+	// Deadline is when the job must have completed. As it nears, the job's
+	// priority is raised while it's queued; missing it raises an alert.
+	Deadline *time.Time `json:"deadline,omitempty"`
 }
 
 // JobResponse represents the response for job operations
@@ -154,14 +203,33 @@ type JobResponse struct {
 	JobEnvVars  map[string]string `json:"job_env_vars,omitempty"`
 	JobEnvFile  string            `json:"job_env_file,omitempty"`
 	RunAsUser   string            `json:"run_as_user,omitempty"`
+	TargetOS    string            `json:"target_os,omitempty"`
+	TargetArch  string            `json:"target_arch,omitempty"`
+
+	Affinity     string   `json:"affinity,omitempty"`
+	AvoidWorkers []string `json:"avoid_workers,omitempty"`
+	WorkerID     *string  `json:"worker_id,omitempty"`
+
+	Environment    string  `json:"environment,omitempty"`
+	FreezeWindowID *string `json:"freeze_window_id,omitempty"`
+
+	Scan *models.ScanSpec `json:"scan,omitempty"`
+
+	// CoalescedSHAs are the earlier pushes' commits a push eval job
+	// superseded.
+	CoalescedSHAs []string `json:"coalesced_shas,omitempty"`
 
 	// Execution info
-	TimeoutSeconds int        `json:"timeout_seconds"`
-	Priority       int        `json:"priority"`
-	QueueName      string     `json:"queue_name"`
-	StartedAt      *time.Time `json:"started_at,omitempty"`
-	CompletedAt    *time.Time `json:"completed_at,omitempty"`
-	ExitCode       *int       `json:"exit_code,omitempty"`
+	TimeoutSeconds   int        `json:"timeout_seconds"`
+	Priority         int        `json:"priority"`
+	QueueName        string     `json:"queue_name"`
+	StartedAt        *time.Time `json:"started_at,omitempty"`
+	CompletedAt      *time.Time `json:"completed_at,omitempty"`
+	ExitCode         *int       `json:"exit_code,omitempty"`
+	Deadline         *time.Time `json:"deadline,omitempty"`
+	DeadlineMissedAt *time.Time `json:"deadline_missed_at,omitempty"`
+	BoostedBy        *string    `json:"boosted_by,omitempty"`
+	BoostedAt        *time.Time `json:"boosted_at,omitempty"`
 
 	// Object store references
 	LogsObjectKey      string `json:"logs_object_key,omitempty"`
@@ -173,6 +241,48 @@ type JobResponse struct {
 	WorkflowNodeID   *string `json:"workflow_node_id,omitempty"`
 	WorkflowRunID    *string `json:"workflow_run_id,omitempty"`
 	WorkflowNodeName string  `json:"workflow_node_name,omitempty"`
+
+	// TriggerValidation is the structured result of this job's last
+	// rejected triggers document, if any. See worker.TriggerValidationRecord.
+	TriggerValidation map[string]interface{} `json:"trigger_validation,omitempty"`
+
+	// GateResults is how the job's outputs fared against its project's
+	// quality gates. See models.QualityGate.
+	GateResults models.GateResults `json:"gate_results,omitempty"`
+
+	// PublishedArtifacts is where the job's artifacts were published, or
+	// why they weren't. See models.ArtifactPublishTarget.
+	PublishedArtifacts models.PublishedArtifacts `json:"published_artifacts,omitempty"`
+
+	// Resources is what the job's container consumed (CPU time, peak
+	// memory, network bytes), when its worker measured it.
+	Resources *models.JobResources `json:"resources,omitempty"`
+
+	AwaitChildren  bool `json:"await_children,omitempty"`
+	DebugOnFailure bool `json:"debug_on_failure,omitempty"`
+
+	// UpstreamJobID is the job in another project whose completion started
+	// this one; DownstreamJobIDs are the jobs this one started (GET only).
+	UpstreamJobID    *string  `json:"upstream_job_id,omitempty"`
+	DownstreamJobIDs []string `json:"downstream_job_ids,omitempty"`
+
+	// Archived is set when the job has been moved to jobs_archive.
+	Archived bool `json:"archived,omitempty"`
+
+	// LatestNote is the newest note on the job (list responses only).
+	LatestNote *models.Note `json:"latest_note,omitempty"`
+}
+
+// ListChildJobsResponse is the response for GET /api/v1/jobs/{id}/children.
+// Aggregate and Total cover every child, while Jobs pages through the newest
+// worker.MaxChildJobs of them.
+type ListChildJobsResponse struct {
+	ParentJobID string                  `json:"parent_job_id"`
+	Aggregate   models.ChildJobsSummary `json:"aggregate"`
+	Jobs        []JobResponse           `json:"jobs"`
+	Total       int                     `json:"total"`
+	Limit       int                     `json:"limit"`
+	Offset      int                     `json:"offset"`
 }
 
 // ListJobsResponse represents the response for listing jobs
@@ -181,162 +291,313 @@ type ListJobsResponse struct {
 	Total  int           `json:"total"`
 	Limit  int           `json:"limit"`
 	Offset int           `json:"offset"`
+	// NextCursor, when set, fetches the next page as ?cursor=. Cursor
+	// pages stay fast however deep they go, and report a Total of -1.
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // CreateJob handles POST /api/v1/jobs
+//
+// With ?dry_run=true the job is validated as it would be for real, but
+// neither saved nor submitted; see dryRunJob for the response.
 func (h *JobHandler) CreateJob(w http.ResponseWriter, r *http.Request) {
-	var req CreateJobRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+	if dryRun {
+		r = r.WithContext(hooks.WithDryRun(r.Context()))
+	}
+	job, ok := h.jobFromCreateRequest(w, r)
+	if !ok {
+		return
+	}
+	if dryRun {
+		h.respondWithJSON(w, http.StatusOK, h.dryRunJob(r.Context(), job))
+		return
+	}
+
+	// Create job in database
+	if err := h.store.CreateJob(r.Context(), job); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
 		return
 	}
 
+	// Record job submission metric
+	sourceTypeStr := ""
+	if job.SourceType != nil {
+		sourceTypeStr = string(*job.SourceType)
+	}
+	metrics.RecordJobSubmission(job.QueueName, sourceTypeStr)
+
+	h.submitJob(r.Context(), job)
+
+	// Return created job
+	response := h.jobToResponse(job)
+	h.respondWithJSON(w, http.StatusCreated, response)
+}
+
+// jobFromCreateRequest decodes and checks a POST /api/v1/jobs request and
+// builds the job it asks for: the request's validation, CI code
+// allowlist, payload limits, source URL policy and pre_job_create hooks,
+// admission policies among them. On failure it has responded and returns
+// false.
+func (h *JobHandler) jobFromCreateRequest(w http.ResponseWriter, r *http.Request) (*models.Job, bool) {
+	var req CreateJobRequest
+	if !h.decodeJSON(w, r, &req) {
+		return nil, false
+	}
+
 	// Get user from context
 	user := checkauth.GetUserFromContext(r.Context())
 	if user == nil {
 		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
-		return
+		return nil, false
+	}
+
+	// A project-bound token's jobs belong to its project, and only to it.
+	if token := checkauth.GetAPITokenFromContext(r.Context()); token != nil && token.ProjectID != nil {
+		if req.ProjectID != "" && req.ProjectID != *token.ProjectID {
+			h.respondWithJSON(w, http.StatusForbidden, ErrorResponse{
+				Error:   "forbidden",
+				Message: "This token can only create jobs for its own project",
+			})
+			return nil, false
+		}
+		req.ProjectID = *token.ProjectID
+	} else if req.ProjectID != "" {
+		h.respondWithJSON(w, http.StatusForbidden, ErrorResponse{
+			Error:   "forbidden",
+			Message: "Only project-bound API tokens can set project_id; use POST /api/v1/projects/{project_id}/run",
+		})
+		return nil, false
 	}
 
 	// Validate required fields
 	if err := h.validateCreateJobRequest(&req); err != nil {
 		// Check if this is a forbidden error (e.g., CI code URL not in allowlist)
-		if err == store.ErrForbidden {
+		if errors.Is(err, store.ErrForbidden) {
 			h.respondWithError(w, http.StatusForbidden, err)
 		} else {
 			h.respondWithError(w, http.StatusBadRequest, err)
 		}
-		return
+		return nil, false
+	}
+
+	if !h.checkPayloadLimits(w, worker.CheckJobPayloadLimits(req.Name, req.Description, req.JobCommand, req.JobEnvVars)) {
+		return nil, false
+	}
+	if !h.checkSourceURLs(w, r, h.store, worker.SourcePolicyURL(req.SourceType, req.SourceURL), req.CISourceURL) {
+		return nil, false
+	}
+	if !h.checkUploadedSource(w, r, &req, user.UserID) {
+		return nil, false
 	}
 
 	// Convert request to job model
 	job := h.createJobFromRequest(&req, user.UserID)
+	// The job's secrets are limited to what the submitting token may read.
+	job.SecretScopes = secretsPrincipal(r).TokenScopes
+	if !h.checkJobHooks(w, r, job, hooks.SourceAPI) {
+		return nil, false
+	}
+	return job, true
+}
 
-	// Create job in database
-	if err := h.store.CreateJob(r.Context(), job); err != nil {
-		h.respondWithError(w, http.StatusInternalServerError, err)
+// submitJob submits a just-created job to Corndogs, unless intake is
+// paused for it, and saves the task ID and state. A failed submission
+// marks the job failed; the job stays in the database either way.
+func (h *JobHandler) submitJob(ctx context.Context, job *models.Job) {
+	if worker.HoldIfPaused(ctx, h.store, job) || h.corndogsClient == nil {
 		return
 	}
 
-	// Record job submission metric
+	task, err := h.corndogsClient.SubmitTask(ctx, jobTaskPayload(job), int64(job.Priority))
+	if err != nil {
+		// Log error but don't fail the request - job is in DB
+		log.Printf("ERROR: Failed to submit task to Corndogs - job_id=%s job_name=%s queue=%s error=%v",
+			job.JobID, job.Name, job.QueueName, err)
+		job.Status = "failed"
+		job.LastError = fmt.Sprintf("failed to submit to Corndogs: %v", err)
+		// Record failed submission metric
+		metrics.RecordCornDogsTaskSubmission(job.QueueName, false)
+	} else {
+		// Record successful submission metric
+		metrics.RecordCornDogsTaskSubmission(job.QueueName, true)
+		taskID := task.Uuid
+		job.CorndogsTaskID = &taskID
+		job.Status = task.CurrentState
+	}
+
+	// Update job with Corndogs task ID and status
+	if err := h.store.UpdateJob(ctx, job); err != nil {
+		// Log error but continue - job was created
+	}
+}
+
+// jobTaskPayload is the Corndogs task submitJob submits for job.
+func jobTaskPayload(job *models.Job) *corndogs.TaskPayload {
+	// Dereference pointer fields for payload
 	sourceTypeStr := ""
 	if job.SourceType != nil {
 		sourceTypeStr = string(*job.SourceType)
 	}
-	metrics.RecordJobSubmission(job.QueueName, sourceTypeStr)
+	sourceURL := ""
+	if job.SourceURL != nil {
+		sourceURL = *job.SourceURL
+	}
+	sourceRef := ""
+	if job.SourceRef != nil {
+		sourceRef = *job.SourceRef
+	}
+	sourcePath := ""
+	if job.SourcePath != nil {
+		sourcePath = *job.SourcePath
+	}
 
-	// Submit job to Corndogs queue
-	if h.corndogsClient != nil {
-		// Dereference pointer fields for payload
-		sourceTypeStr := ""
-		if job.SourceType != nil {
-			sourceTypeStr = string(*job.SourceType)
-		}
-		sourceURL := ""
-		if job.SourceURL != nil {
-			sourceURL = *job.SourceURL
-		}
-		sourceRef := ""
-		if job.SourceRef != nil {
-			sourceRef = *job.SourceRef
-		}
-		sourcePath := ""
-		if job.SourcePath != nil {
-			sourcePath = *job.SourcePath
-		}
+	taskPayload := &corndogs.TaskPayload{
+		JobID:   job.JobID,
+		JobType: "run",
+		Config: map[string]interface{}{
+			"image":       job.RunnerImage,
+			"command":     job.JobCommand,
+			"working_dir": job.JobDir,
+			"timeout":     job.TimeoutSeconds,
+			"code_dir":    job.CodeDir,
+			"job_dir":     job.JobDir,
+		},
+		Source: map[string]interface{}{
+			"type":        sourceTypeStr,
+			"url":         sourceURL,
+			"ref":         sourceRef,
+			"source_path": sourcePath,
+		},
+		Metadata: map[string]interface{}{
+			"user_id":      job.UserID,
+			"submitted_at": job.CreatedAt,
+			"name":         job.Name,
+			"description":  job.Description,
+		},
+	}
+
+	// Add environment variables if present
+	if job.JobEnvVars != nil {
+		taskPayload.Config["environment"] = job.JobEnvVars
+	}
+	if job.JobEnvFile != "" {
+		taskPayload.Config["env_file"] = job.JobEnvFile
+	}
+	taskPayload.SetTargetPlatform(job.TargetOS, job.TargetArch)
+	if job.QueueRouted {
+		taskPayload.SetTargetQueue(job.QueueName)
+	}
+	return taskPayload
+}
 
-		taskPayload := &corndogs.TaskPayload{
-			JobID:   job.JobID,
-			JobType: "run",
-			Config: map[string]interface{}{
-				"image":       job.RunnerImage,
-				"command":     job.JobCommand,
-				"working_dir": job.JobDir,
-				"timeout":     job.TimeoutSeconds,
-				"code_dir":    job.CodeDir,
-				"job_dir":     job.JobDir,
-			},
-			Source: map[string]interface{}{
-				"type":        sourceTypeStr,
-				"url":         sourceURL,
-				"ref":         sourceRef,
-				"source_path": sourcePath,
-			},
-			Metadata: map[string]interface{}{
-				"user_id":      job.UserID,
-				"submitted_at": job.CreatedAt,
-				"name":         job.Name,
-				"description":  job.Description,
-			},
-		}
+// GetJob handles GET /api/v1/jobs/{job_id}
+func (h *JobHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	if response, ok := h.getJobResponse(w, r); ok {
+		h.respondWithJSON(w, http.StatusOK, response)
+	}
+}
 
-		// Add environment variables if present
-		if job.JobEnvVars != nil {
-			taskPayload.Config["environment"] = job.JobEnvVars
-		}
-		if job.JobEnvFile != "" {
-			taskPayload.Config["env_file"] = job.JobEnvFile
-		}
+// getJobResponse loads the job GET /api/v1/jobs/{job_id} and its /api/v2
+// successor return. On failure it has responded and returns false.
+func (h *JobHandler) getJobResponse(w http.ResponseWriter, r *http.Request) (JobResponse, bool) {
+	jobID := h.getID(r, "job_id")
+	if jobID == "" {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return JobResponse{}, false
+	}
 
-		task, err := h.corndogsClient.SubmitTask(r.Context(), taskPayload, int64(job.Priority))
-		if err != nil {
-			// Log error but don't fail the request - job is in DB
-			log.Printf("ERROR: Failed to submit task to Corndogs - job_id=%s job_name=%s queue=%s error=%v",
-				job.JobID, job.Name, job.QueueName, err)
-			job.Status = "failed"
-			job.LastError = fmt.Sprintf("failed to submit to Corndogs: %v", err)
-			// Record failed submission metric
-			metrics.RecordCornDogsTaskSubmission(job.QueueName, false)
-		} else {
-			// Record successful submission metric
-			metrics.RecordCornDogsTaskSubmission(job.QueueName, true)
-			taskID := task.Uuid
-			job.CorndogsTaskID = &taskID
-			job.Status = task.CurrentState
-		}
+	job, err := h.store.GetJobByID(r.Context(), jobID)
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return JobResponse{}, false
+	}
 
-		// Update job with Corndogs task ID and status
-		if err := h.store.UpdateJob(r.Context(), job); err != nil {
-			// Log error but continue - job was created
-		}
+	// Check if user can access this job. GetJob is a read endpoint, so it
+	// additionally grants access when the job is publicly visible (see
+	// canUserViewJob) — unlike the mutation endpoints below, which keep the
+	// original owner-or-admin-only canUserAccessJob.
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return JobResponse{}, false
+	}
+
+	if !h.canUserViewJob(r.Context(), user, job) {
+		h.respondWithError(w, http.StatusForbidden, store.ErrForbidden)
+		return JobResponse{}, false
 	}
 
-	// Return created job
 	response := h.jobToResponse(job)
-	h.respondWithJSON(w, http.StatusCreated, response)
+	downstream, err := h.store.ListJobs(r.Context(), map[string]interface{}{"upstream_job_id": job.JobID}, worker.MaxDownstreamJobs, 0)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return JobResponse{}, false
+	}
+	for _, d := range downstream {
+		response.DownstreamJobIDs = append(response.DownstreamJobIDs, d.JobID)
+	}
+	return response, true
 }
 
-// GetJob handles GET /api/v1/jobs/{job_id}
-func (h *JobHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+// ListChildJobs handles GET /api/v1/jobs/{job_id}/children
+//
+// Returns a page of the jobs the parent spawned plus the aggregate status of
+// all of them. Children share their parent's owner and project, so being
+// able to view the parent is what grants access to the list.
+func (h *JobHandler) ListChildJobs(w http.ResponseWriter, r *http.Request) {
 	jobID := h.getID(r, "job_id")
 	if jobID == "" {
 		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
 		return
 	}
 
-	job, err := h.store.GetJobByID(r.Context(), jobID)
+	parent, err := h.store.GetJobByID(r.Context(), jobID)
 	if err != nil {
 		h.respondWithError(w, http.StatusNotFound, err)
 		return
 	}
 
-	// Check if user can access this job. GetJob is a read endpoint, so it
-	// additionally grants access when the job is publicly visible (see
-	// canUserViewJob) — unlike the mutation endpoints below, which keep the
-	// original owner-or-admin-only canUserAccessJob.
 	user := checkauth.GetUserFromContext(r.Context())
 	if user == nil {
 		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
 		return
 	}
 
-	if !h.canUserViewJob(r.Context(), user, job) {
+	if !h.canUserViewJob(r.Context(), user, parent) {
 		h.respondWithError(w, http.StatusForbidden, store.ErrForbidden)
 		return
 	}
 
-	response := h.jobToResponse(job)
-	h.respondWithJSON(w, http.StatusOK, response)
+	children, err := worker.ListChildJobs(r.Context(), h.store, parent.JobID)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	aggregate, err := h.triggerProcessor.SummarizeChildJobs(r.Context(), parent, children)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	limit, offset := h.parsePagination(r)
+	page := []models.Job{}
+	if offset < len(children) {
+		page = children[offset:min(offset+limit, len(children))]
+	}
+	jobResponses := make([]JobResponse, len(page))
+	for i := range page {
+		jobResponses[i] = h.jobToResponse(&page[i])
+	}
+
+	h.respondWithJSON(w, http.StatusOK, ListChildJobsResponse{
+		ParentJobID: parent.JobID,
+		Aggregate:   aggregate,
+		Jobs:        jobResponses,
+		Total:       aggregate.Total,
+		Limit:       limit,
+		Offset:      offset,
+	})
 }
 
 // jobsVisibleToStore is the narrow store capability that lets ListJobs push
@@ -366,13 +627,33 @@ type jobsVisibleToStore interface {
 
 // ListJobs handles GET /api/v1/jobs
 func (h *JobHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	if response, ok := h.listJobs(w, r); ok {
+		h.respondWithJSON(w, http.StatusOK, response)
+	}
+}
+
+// listJobs lists the page of jobs GET /api/v1/jobs and its /api/v2
+// successor return. On failure it has responded and returns false.
+func (h *JobHandler) listJobs(w http.ResponseWriter, r *http.Request) (ListJobsResponse, bool) {
 	user := checkauth.GetUserFromContext(r.Context())
 	if user == nil {
 		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
-		return
+		return ListJobsResponse{}, false
 	}
 
 	limit, offset := h.parsePagination(r)
+	var cursor *store.JobCursor
+	if raw := r.URL.Query().Get("cursor"); raw != "" {
+		c, err := store.ParseJobCursor(raw)
+		if err != nil {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_input",
+				Message: "Invalid cursor",
+			})
+			return ListJobsResponse{}, false
+		}
+		cursor, offset = &c, 0
+	}
 
 	// Primary path: SQL-side visibility filtering with exact pagination and
 	// Total (see jobsVisibleToStore's doc comment).
@@ -381,27 +662,26 @@ func (h *JobHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
 		isGlobalAdmin, err := h.visibility.IsGlobalAdmin(r.Context(), id)
 		if err != nil {
 			h.respondWithError(w, http.StatusInternalServerError, err)
-			return
+			return ListJobsResponse{}, false
 		}
 
 		filters := h.parseFilters(r, user)
+		if cursor != nil {
+			filters[store.JobCursorFilter] = *cursor
+		}
 		jobs, total, err := jvs.ListJobsVisibleTo(r.Context(), user.UserID, isGlobalAdmin, filters, limit, offset)
 		if err != nil {
 			h.respondWithError(w, http.StatusInternalServerError, err)
-			return
+			return ListJobsResponse{}, false
 		}
 
-		jobResponses := make([]JobResponse, len(jobs))
-		for i, job := range jobs {
-			jobResponses[i] = h.jobToResponse(&job)
-		}
-		h.respondWithJSON(w, http.StatusOK, ListJobsResponse{
-			Jobs:   jobResponses,
-			Total:  int(total),
-			Limit:  limit,
-			Offset: offset,
-		})
-		return
+		return ListJobsResponse{
+			Jobs:       h.jobListResponses(r.Context(), jobs),
+			Total:      int(total),
+			Limit:      limit,
+			Offset:     offset,
+			NextCursor: nextJobCursor(jobs, limit),
+		}, true
 	}
 
 	// Fallback: the wired store doesn't support SQL-side visibility (or
@@ -411,22 +691,50 @@ func (h *JobHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
 	// length, same as always in this fallback) are at least self-consistent
 	// again instead of silently short-paging.
 	filters := h.parseFiltersStrict(r, user)
+	if cursor != nil {
+		filters[store.JobCursorFilter] = *cursor
+	}
 	jobs, err := h.store.ListJobs(r.Context(), filters, limit, offset)
 	if err != nil {
 		h.respondWithError(w, http.StatusInternalServerError, err)
-		return
+		return ListJobsResponse{}, false
 	}
 
-	jobResponses := make([]JobResponse, len(jobs))
-	for i, job := range jobs {
-		jobResponses[i] = h.jobToResponse(&job)
+	return ListJobsResponse{
+		Jobs:       h.jobListResponses(r.Context(), jobs),
+		Total:      len(jobs),
+		Limit:      limit,
+		Offset:     offset,
+		NextCursor: nextJobCursor(jobs, limit),
+	}, true
+}
+
+// jobListResponses converts a page of listed jobs, with each one's latest
+// note.
+func (h *JobHandler) jobListResponses(ctx context.Context, jobs []models.Job) []JobResponse {
+	jobIDs := make([]string, len(jobs))
+	for i := range jobs {
+		jobIDs[i] = jobs[i].JobID
+	}
+	notes := latestNotes(ctx, h.store, models.NoteTargetJob, jobIDs)
+	responses := make([]JobResponse, len(jobs))
+	for i := range jobs {
+		responses[i] = h.jobToResponse(&jobs[i])
+		if note, ok := notes[jobs[i].JobID]; ok {
+			responses[i].LatestNote = &note
+		}
 	}
-	h.respondWithJSON(w, http.StatusOK, ListJobsResponse{
-		Jobs:   jobResponses,
-		Total:  len(jobResponses),
-		Limit:  limit,
-		Offset: offset,
-	})
+	return responses
+}
+
+// nextJobCursor returns the cursor of the page after jobs, or "" when jobs
+// is the last page.
+func nextJobCursor(jobs []models.Job, limit int) string {
+	if len(jobs) == 0 || len(jobs) < limit {
+		return ""
+	}
+	last := jobs[len(jobs)-1]
+	return store.JobCursor{CreatedAt: last.CreatedAt, JobID: last.JobID}.Encode()
 }
 
 // CancelJob handles PUT /api/v1/jobs/{job_id}/cancel
@@ -584,7 +892,8 @@ func (h *JobHandler) RetryJob(w http.ResponseWriter, r *http.Request) {
 	h.respondWithJSON(w, http.StatusCreated, response)
 }
 
-// DeleteJob handles DELETE /api/v1/jobs/{job_id}
+// DeleteJob handles DELETE /api/v1/jobs/{job_id}, moving the job to the
+// trash, from which RestoreJob can bring it back until it's purged.
 func (h *JobHandler) DeleteJob(w http.ResponseWriter, r *http.Request) {
 	jobID := h.getID(r, "job_id")
 	if jobID == "" {
@@ -622,6 +931,12 @@ func (h *JobHandler) DeleteJob(w http.ResponseWriter, r *http.Request) {
 // GetJobLogs handles GET /api/v1/jobs/{job_id}/logs
 // Query parameters:
 //   - stream: "stdout", "stderr", or "combined" (default: "combined")
+//   - offset: return the entries from this one on
+//   - tail: return the last this many entries
+//
+// The X-Log-Next-Offset header is the offset to poll from next and
+// X-Log-Complete whether the job has finished. A Range header gets the
+// requested bytes of the response with a 206.
 func (h *JobHandler) GetJobLogs(w http.ResponseWriter, r *http.Request) {
 	jobID := h.getID(r, "job_id")
 	if jobID == "" {
@@ -665,76 +980,26 @@ func (h *JobHandler) GetJobLogs(w http.ResponseWriter, r *http.Request) {
 		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
 		return
 	}
+	window, err := parseLogWindow(r.URL.Query())
+	if err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_input",
+			Message: err.Error(),
+		})
+		return
+	}
 
-	// Build log object keys based on job ID
-	// Log format: logs/{job_id}/{stdout|stderr}.json (JSON array format)
-	var logContent []byte
-
-	switch stream {
-	case "stdout":
-		key := fmt.Sprintf("logs/%s/stdout.json", jobID)
-		content, err := h.fetchLogContent(r.Context(), key)
-		if err != nil {
-			if err == objects.ErrNotFound {
-				h.respondWithError(w, http.StatusNotFound, store.ErrNotFound)
-				return
-			}
-			h.respondWithError(w, http.StatusInternalServerError, err)
-			return
-		}
-		logContent = content
-
-	case "stderr":
-		key := fmt.Sprintf("logs/%s/stderr.json", jobID)
-		content, err := h.fetchLogContent(r.Context(), key)
-		if err != nil {
-			if err == objects.ErrNotFound {
-				h.respondWithError(w, http.StatusNotFound, store.ErrNotFound)
-				return
-			}
-			h.respondWithError(w, http.StatusInternalServerError, err)
-			return
-		}
-		logContent = content
-
-	case "combined":
-		// Fetch both stdout and stderr, combine them into a single sorted array
-		stdoutKey := fmt.Sprintf("logs/%s/stdout.json", jobID)
-		stderrKey := fmt.Sprintf("logs/%s/stderr.json", jobID)
-
-		stdoutContent, stdoutErr := h.fetchLogContent(r.Context(), stdoutKey)
-		stderrContent, stderrErr := h.fetchLogContent(r.Context(), stderrKey)
-
-		// If both are not found, return 404
-		if stdoutErr == objects.ErrNotFound && stderrErr == objects.ErrNotFound {
+	logContent, err := h.fetchJobLog(r.Context(), job, stream)
+	if err != nil {
+		if err == objects.ErrNotFound {
 			h.respondWithError(w, http.StatusNotFound, store.ErrNotFound)
 			return
 		}
-
-		// Handle other errors
-		if stdoutErr != nil && stdoutErr != objects.ErrNotFound {
-			h.respondWithError(w, http.StatusInternalServerError, stdoutErr)
-			return
-		}
-		if stderrErr != nil && stderrErr != objects.ErrNotFound {
-			h.respondWithError(w, http.StatusInternalServerError, stderrErr)
-			return
-		}
-
-		// Merge JSON arrays and sort by timestamp
-		combined, err := h.mergeAndSortLogArrays(stdoutContent, stderrContent)
-		if err != nil {
-			h.respondWithError(w, http.StatusInternalServerError, err)
-			return
-		}
-		logContent = combined
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
 	}
 
-	// Return logs as JSON
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(logContent)))
-	w.WriteHeader(http.StatusOK)
-	w.Write(logContent)
+	h.serveJobLogs(w, r, job, logContent, window)
 }
 
 // SubmitTriggersResponse represents the response for trigger submission
@@ -743,6 +1008,16 @@ type SubmitTriggersResponse struct {
 	Count         int      `json:"count"`
 }
 
+// TriggerValidationErrorResponse is SubmitTriggers' 400 body when the
+// triggers document fails schema validation: the standard error envelope
+// plus the structured per-field errors, so callers can point at the exact
+// offending field instead of a generic "invalid input".
+type TriggerValidationErrorResponse struct {
+	Error            string                          `json:"error"`
+	Message          string                          `json:"message,omitempty"`
+	ValidationErrors []worker.TriggerValidationError `json:"validation_errors"`
+}
+
 // SubmitTriggers handles POST /api/v1/jobs/{job_id}/triggers
 func (h *JobHandler) SubmitTriggers(w http.ResponseWriter, r *http.Request) {
 	jobID := h.getID(r, "job_id")
@@ -771,6 +1046,11 @@ func (h *JobHandler) SubmitTriggers(w http.ResponseWriter, r *http.Request) {
 
 	// Read request body
 	body, err := io.ReadAll(r.Body)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		respondPayloadTooLarge(w, tooLarge.Limit)
+		return
+	}
 	if err != nil {
 		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
 		return
@@ -779,6 +1059,15 @@ func (h *JobHandler) SubmitTriggers(w http.ResponseWriter, r *http.Request) {
 	// Process triggers via TriggerProcessor
 	createdJobIDs, err := h.triggerProcessor.ProcessTriggersFromData(r.Context(), body, "", parentJob)
 	if err != nil {
+		var verrs worker.TriggerValidationErrors
+		if errors.As(err, &verrs) {
+			h.respondWithJSON(w, http.StatusBadRequest, TriggerValidationErrorResponse{
+				Error:            "invalid_input",
+				Message:          "Triggers document failed validation",
+				ValidationErrors: verrs,
+			})
+			return
+		}
 		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
 		return
 	}
@@ -809,6 +1098,45 @@ func (h *JobHandler) fetchLogContent(ctx context.Context, key string) ([]byte, e
 	return content, nil
 }
 
+// fetchJobLog fetches the job's log of stream: stdout, stderr, or both
+// combined into a single array sorted by timestamp. Log format:
+// logs/{job_id}/{stdout|stderr}.json (JSON array format), moved elsewhere
+// once the logs are cold (see jobcontrol.LogObjectKey) or still in chunks
+// while they're uploaded. Returns objects.ErrNotFound if there's no log.
+func (h *JobHandler) fetchJobLog(ctx context.Context, job *models.Job, stream string) ([]byte, error) {
+	if stream != "combined" {
+		return h.fetchStreamLog(ctx, job, stream)
+	}
+
+	stdoutContent, stdoutErr := h.fetchStreamLog(ctx, job, "stdout")
+	stderrContent, stderrErr := h.fetchStreamLog(ctx, job, "stderr")
+
+	// If both are not found, there's no log
+	if stdoutErr == objects.ErrNotFound && stderrErr == objects.ErrNotFound {
+		return nil, objects.ErrNotFound
+	}
+	if stdoutErr != nil && stdoutErr != objects.ErrNotFound {
+		return nil, stdoutErr
+	}
+	if stderrErr != nil && stderrErr != objects.ErrNotFound {
+		return nil, stderrErr
+	}
+
+	// Merge JSON arrays and sort by timestamp
+	return h.mergeAndSortLogArrays(stdoutContent, stderrContent)
+}
+
+// fetchStreamLog fetches one stream of the job's log. A stream still being
+// uploaded in chunks (see jobcontrol.PutLogChunk) returns the chunks so
+// far.
+func (h *JobHandler) fetchStreamLog(ctx context.Context, job *models.Job, stream string) ([]byte, error) {
+	content, err := h.fetchLogContent(ctx, jobcontrol.LogObjectKey(job, stream))
+	if err == objects.ErrNotFound {
+		return jobcontrol.ReadLogChunks(ctx, h.objectStore, job.JobID, stream)
+	}
+	return content, err
+}
+
 // mergeAndSortLogArrays merges two JSON log arrays and sorts by timestamp
 func (h *JobHandler) mergeAndSortLogArrays(stdoutContent, stderrContent []byte) ([]byte, error) {
 	var allEntries []LogEntry
@@ -831,8 +1159,9 @@ func (h *JobHandler) mergeAndSortLogArrays(stdoutContent, stderrContent []byte)
 		allEntries = append(allEntries, stderrEntries...)
 	}
 
-	// Sort by timestamp
-	sort.Slice(allEntries, func(i, j int) bool {
+	// Sort by timestamp, keeping each stream's order for equal timestamps
+	// so entry offsets stay put as the streams grow
+	sort.SliceStable(allEntries, func(i, j int) bool {
 		return allEntries[i].Timestamp < allEntries[j].Timestamp
 	})
 
@@ -852,11 +1181,14 @@ func (h *JobHandler) validateCreateJobRequest(req *CreateJobRequest) error {
 		return store.ErrInvalidInput
 	}
 
-	if req.JobCommand == "" {
+	if req.JobCommand == "" && req.Scan == nil {
+		return store.ErrInvalidInput
+	}
+	if req.Scan != nil && req.Scan.Validate() != nil {
 		return store.ErrInvalidInput
 	}
 
-	if req.SourceType != "git" && req.SourceType != "copy" {
+	if req.SourceType != "git" && req.SourceType != "copy" && req.SourceType != "upload" && req.SourceType != "oci" {
 		return store.ErrInvalidInput
 	}
 
@@ -867,9 +1199,30 @@ func (h *JobHandler) validateCreateJobRequest(req *CreateJobRequest) error {
 	if req.SourceType == "copy" && req.SourcePath == "" {
 		return store.ErrInvalidInput
 	}
+	if req.SourceType == "upload" && worker.ValidateUploadID(req.SourcePath) != nil {
+		return store.ErrInvalidInput
+	}
+	if req.SourceType == "oci" && worker.ValidateOCISource(req.SourceURL, req.SourceRef) != nil {
+		return store.ErrInvalidInput
+	}
 	if _, err := worker.NormalizeRunAsUser(req.RunAsUser); err != nil {
 		return store.ErrInvalidInput
 	}
+	if _, err := worker.NormalizePlatform(req.TargetOS, req.TargetArch); err != nil {
+		return store.ErrInvalidInput
+	}
+	if err := worker.ValidateAffinity(req.Affinity, req.AvoidWorkers); err != nil {
+		return store.ErrInvalidInput
+	}
+	if req.Environment != "" && models.ValidateEnvironmentName(req.Environment) != nil {
+		return store.ErrInvalidInput
+	}
+	if req.JobEnvFile != "" && worker.ValidateJobEnvFile(req.JobEnvFile) != nil {
+		return store.ErrInvalidInput
+	}
+	if req.Deadline != nil && !req.Deadline.After(time.Now()) {
+		return store.ErrInvalidInput
+	}
 
 	// Validate CI source fields if provided
 	if req.CISourceType != "" {
@@ -911,28 +1264,28 @@ func (h *JobHandler) validateCiCodeURL(ciSourceURL string) error {
 		return nil
 	}
 
-	// Parse the comma-separated allowlist
-	allowedURLs := strings.Split(allowlist, ",")
+	if ciCodeURLAllowed(allowlist, ciSourceURL) {
+		return nil
+	}
 
-	// Normalize the CI source URL
-	normalizedCIURL := vcs.NormalizeRepoURL(ciSourceURL)
+	// URL not in allowlist - log and return forbidden error
+	log.Printf("SECURITY: Rejected CI source URL not in allowlist: %s (normalized: %s)", ciSourceURL, repourl.Normalize(ciSourceURL))
+	return store.ErrForbidden
+}
 
-	// Check if the URL is in the allowlist
-	for _, allowedURL := range allowedURLs {
+// ciCodeURLAllowed reports whether ciSourceURL matches an entry of the
+// comma-separated allowlist, in any of the forms a repository URL takes.
+func ciCodeURLAllowed(allowlist, ciSourceURL string) bool {
+	for _, allowedURL := range strings.Split(allowlist, ",") {
 		allowedURL = strings.TrimSpace(allowedURL)
 		if allowedURL == "" {
 			continue
 		}
-
-		// Use URL matching to handle different formats
-		if vcs.MatchRepoURL(ciSourceURL, allowedURL) {
-			return nil
+		if repourl.Match(ciSourceURL, allowedURL) {
+			return true
 		}
 	}
-
-	// URL not in allowlist - log and return forbidden error
-	log.Printf("SECURITY: Rejected CI source URL not in allowlist: %s (normalized: %s)", ciSourceURL, normalizedCIURL)
-	return store.ErrForbidden
+	return false
 }
 
 func (h *JobHandler) createJobFromRequest(req *CreateJobRequest, userID string) *models.Job {
@@ -943,6 +1296,10 @@ func (h *JobHandler) createJobFromRequest(req *CreateJobRequest, userID string)
 		sourceType = models.SourceTypeGit
 	case "copy":
 		sourceType = models.SourceTypeCopy
+	case "upload":
+		sourceType = models.SourceTypeUpload
+	case "oci":
+		sourceType = models.SourceTypeOCI
 	default:
 		sourceType = models.SourceTypeNone
 	}
@@ -967,6 +1324,12 @@ func (h *JobHandler) createJobFromRequest(req *CreateJobRequest, userID string)
 		RunAsUser:   req.RunAsUser,
 
 		QueueName: req.QueueName,
+
+		AwaitChildren:  req.AwaitChildren,
+		DebugOnFailure: req.DebugOnFailure,
+	}
+	if req.ProjectID != "" {
+		job.ProjectID = &req.ProjectID
 	}
 
 	// Handle CI source fields with defaults if not provided
@@ -1011,6 +1374,15 @@ func (h *JobHandler) createJobFromRequest(req *CreateJobRequest, userID string)
 	if job.QueueName == "" {
 		job.QueueName = "reactorcide-jobs"
 	}
+	// Validated by validateCreateJobRequest.
+	platform, _ := worker.NormalizePlatform(req.TargetOS, req.TargetArch)
+	job.TargetOS = platform.OS
+	job.TargetArch = platform.Arch
+	job.Affinity = req.Affinity
+	job.AvoidWorkers = req.AvoidWorkers
+	job.Environment = req.Environment
+	job.Scan = req.Scan
+	worker.ApplyScanSpec(job)
 
 	// Set timeout and priority
 	if req.TimeoutSeconds != nil {
@@ -1022,6 +1394,10 @@ func (h *JobHandler) createJobFromRequest(req *CreateJobRequest, userID string)
 	if req.Priority != nil {
 		job.Priority = *req.Priority
 	}
+	if req.Deadline != nil {
+		deadline := req.Deadline.UTC()
+		job.Deadline = &deadline
+	}
 
 	// Convert env vars
 	if req.JobEnvVars != nil {
@@ -1092,13 +1468,26 @@ func (h *JobHandler) jobToResponse(job *models.Job) JobResponse {
 		RunnerImage:    job.RunnerImage,
 		JobEnvFile:     job.JobEnvFile,
 		RunAsUser:      job.RunAsUser,
+		TargetOS:       job.TargetOS,
+		TargetArch:     job.TargetArch,
+		Affinity:       job.Affinity,
+		AvoidWorkers:   job.AvoidWorkers,
+		WorkerID:       job.WorkerID,
+		Environment:    job.Environment,
+		Scan:           job.Scan,
+		FreezeWindowID: job.FreezeWindowID,
+		CoalescedSHAs:  job.CoalescedSHAs,
 		TimeoutSeconds: job.TimeoutSeconds,
 		Priority:       job.Priority,
 		QueueName:      job.QueueName,
 
-		StartedAt:   job.StartedAt,
-		CompletedAt: job.CompletedAt,
-		ExitCode:    job.ExitCode,
+		StartedAt:        job.StartedAt,
+		CompletedAt:      job.CompletedAt,
+		ExitCode:         job.ExitCode,
+		Deadline:         job.Deadline,
+		DeadlineMissedAt: job.DeadlineMissedAt,
+		BoostedBy:        job.BoostedBy,
+		BoostedAt:        job.BoostedAt,
 
 		LogsObjectKey:      job.LogsObjectKey,
 		ArtifactsObjectKey: job.ArtifactsObjectKey,
@@ -1109,16 +1498,20 @@ func (h *JobHandler) jobToResponse(job *models.Job) JobResponse {
 		WorkflowNodeID:   job.WorkflowNodeID,
 		WorkflowRunID:    job.WorkflowRunID,
 		WorkflowNodeName: job.WorkflowNodeName,
+
+		TriggerValidation:  job.TriggerValidation,
+		GateResults:        job.GateResults,
+		PublishedArtifacts: job.PublishedArtifacts,
+		Resources:          job.Resources,
+		AwaitChildren:      job.AwaitChildren,
+		DebugOnFailure:     job.DebugOnFailure,
+		UpstreamJobID:      job.UpstreamJobID,
+		Archived:           job.Archived,
 	}
 
 	// Convert env vars
 	if job.JobEnvVars != nil {
-		response.JobEnvVars = make(map[string]string)
-		for k, v := range job.JobEnvVars {
-			if str, ok := v.(string); ok {
-				response.JobEnvVars[k] = str
-			}
-		}
+		response.JobEnvVars = job.JobEnvVars.EnvStrings()
 	}
 
 	return response
@@ -1205,14 +1598,15 @@ func (h *JobHandler) parsePagination(r *http.Request) (limit, offset int) {
 
 // commonJobQueryFilters parses the filter query parameters ListJobs honors
 // regardless of user-scoping policy (status, queue_name, source_type,
-// project_id, workflow_id). user_id scoping is decided separately by
+// project_id, group_id, workflow_id, parent_job_id, upstream_job_id,
+// include_archived). user_id scoping is decided separately by
 // parseFilters/parseFiltersStrict since the two callers apply different
 // policies there.
 func (h *JobHandler) commonJobQueryFilters(r *http.Request) map[string]interface{} {
 	filters := make(map[string]interface{})
 
 	if status := r.URL.Query().Get("status"); status != "" {
-		validStatuses := []string{"submitted", "queued", "running", "cancelling", "completed", "failed", "cancelled", "timeout"}
+		validStatuses := []string{"held", "submitted", "queued", "running", "cancelling", "completed", "failed", "cancelled", "timeout", "deadlettered"}
 		for _, validStatus := range validStatuses {
 			if status == validStatus {
 				filters["status"] = status
@@ -1226,7 +1620,7 @@ func (h *JobHandler) commonJobQueryFilters(r *http.Request) map[string]interface
 	}
 
 	if sourceType := r.URL.Query().Get("source_type"); sourceType != "" {
-		if sourceType == "git" || sourceType == "copy" {
+		if sourceType == "git" || sourceType == "copy" || sourceType == "upload" || sourceType == "oci" {
 			filters["source_type"] = sourceType
 		}
 	}
@@ -1234,9 +1628,22 @@ func (h *JobHandler) commonJobQueryFilters(r *http.Request) map[string]interface
 	if projectID := r.URL.Query().Get("project_id"); projectID != "" {
 		filters["project_id"] = projectID
 	}
+
+	if groupID := r.URL.Query().Get("group_id"); groupID != "" {
+		filters["group_id"] = groupID
+	}
 	if workflowID := r.URL.Query().Get("workflow_id"); workflowID != "" {
 		filters["workflow_id"] = workflowID
 	}
+	if parentJobID := r.URL.Query().Get("parent_job_id"); parentJobID != "" {
+		filters["parent_job_id"] = parentJobID
+	}
+	if upstreamJobID := r.URL.Query().Get("upstream_job_id"); upstreamJobID != "" {
+		filters["upstream_job_id"] = upstreamJobID
+	}
+	if r.URL.Query().Get("include_archived") == "true" {
+		filters[store.IncludeArchivedFilter] = true
+	}
 
 	return filters
 }
@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -16,10 +17,12 @@ import (
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
 	pb "github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs/v1alpha1"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/hooks"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -139,7 +142,7 @@ func (m *MockStore) CreateProject(ctx context.Context, project *models.Project)
 func (m *MockStore) GetProjectByID(ctx context.Context, projectID string) (*models.Project, error) {
 	return nil, nil
 }
-func (m *MockStore) GetProjectByRepoURL(ctx context.Context, repoURL string) (*models.Project, error) {
+func (m *MockStore) GetProjectsByRepoURL(ctx context.Context, repoURL string) ([]models.Project, error) {
 	return nil, nil
 }
 func (m *MockStore) UpdateProject(ctx context.Context, project *models.Project) error { return nil }
@@ -227,6 +230,54 @@ func TestJobHandler_CreateJob_WithCorndogs(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "job targeting windows is routed to its platform queue",
+			request: CreateJobRequest{
+				Name:       "Windows Job",
+				JobCommand: "Write-Output hello",
+				SourceType: "git",
+				SourceURL:  "https://github.com/test/repo.git",
+				TargetOS:   "Windows",
+			},
+			setupMockStore: func(m *MockStore) {
+				m.CreateJobFunc = func(ctx context.Context, job *models.Job) error {
+					job.JobID = "test-job-id"
+					return nil
+				}
+				m.UpdateJobFunc = func(ctx context.Context, job *models.Job) error {
+					return nil
+				}
+			},
+			setupMockCorndogs: func(m *corndogs.MockClient) {
+				m.SubmitTaskFunc = func(ctx context.Context, payload *corndogs.TaskPayload, priority int64) (*pb.Task, error) {
+					targetOS, targetArch := payload.TargetPlatform()
+					if queue := corndogs.PlatformQueue("reactorcide-jobs", targetOS, targetArch); queue != "reactorcide-jobs-windows-amd64" {
+						t.Errorf("expected windows/amd64 queue, got %s", queue)
+					}
+					return &pb.Task{Uuid: "corndogs-task-id", CurrentState: "submitted"}, nil
+				}
+			},
+			expectedStatus:        http.StatusCreated,
+			expectedCorndogsCalls: 1,
+			checkResponse: func(t *testing.T, resp JobResponse) {
+				if resp.TargetOS != "windows" || resp.TargetArch != "amd64" {
+					t.Errorf("expected normalized windows/amd64 target, got %s/%s", resp.TargetOS, resp.TargetArch)
+				}
+			},
+		},
+		{
+			name: "job targeting an unsupported OS is rejected",
+			request: CreateJobRequest{
+				Name:       "Bad Job",
+				JobCommand: "echo hello",
+				SourceType: "git",
+				SourceURL:  "https://github.com/test/repo.git",
+				TargetOS:   "plan9",
+			},
+			setupMockCorndogs:     func(m *corndogs.MockClient) {},
+			expectedStatus:        http.StatusBadRequest,
+			expectedCorndogsCalls: 0,
+		},
 		{
 			name: "job creation without Corndogs client",
 			request: CreateJobRequest{
@@ -306,6 +357,145 @@ func TestJobHandler_CreateJob_WithCorndogs(t *testing.T) {
 	}
 }
 
+func TestJobHandler_CreateJob_EnvVarValues(t *testing.T) {
+	var created *models.Job
+	mockStore := &MockStore{
+		CreateJobFunc: func(ctx context.Context, job *models.Job) error {
+			job.JobID = "test-job-id"
+			created = job
+			return nil
+		},
+	}
+	handler := NewJobHandler(mockStore, nil)
+	user := &models.User{UserID: "test-user-id"}
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/jobs", strings.NewReader(body))
+		req = req.WithContext(checkauth.SetUserContext(req.Context(), user))
+		w := httptest.NewRecorder()
+		handler.CreateJob(w, req)
+		return w
+	}
+
+	w := post(`{"name":"env","job_command":"env","source_type":"copy","source_path":"/src","job_env_vars":{"RETRIES":3,"DEBUG":true,"NAME":"x"}}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	for key, want := range map[string]string{"RETRIES": "3", "DEBUG": "true", "NAME": "x"} {
+		if got := created.JobEnvVars[key]; got != want {
+			t.Errorf("%s stored as %#v, want %q", key, got, want)
+		}
+	}
+
+	w = post(`{"name":"env","job_command":"env","source_type":"copy","source_path":"/src","job_env_vars":{"CONFIG":{"a":1}}}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an object value, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "CONFIG") {
+		t.Errorf("expected the error to name the variable, got %s", w.Body.String())
+	}
+}
+
+func TestJobHandler_CreateJob_RecordsTokenScopes(t *testing.T) {
+	var created *models.Job
+	mockStore := &MockStore{
+		CreateJobFunc: func(ctx context.Context, job *models.Job) error {
+			job.JobID = "test-job-id"
+			created = job
+			return nil
+		},
+	}
+	handler := NewJobHandler(mockStore, nil)
+
+	req := httptest.NewRequest("POST", "/api/v1/jobs", strings.NewReader(`{"name":"deploy","job_command":"make deploy","source_type":"copy","source_path":"/src"}`))
+	ctx := checkauth.SetUserContext(req.Context(), &models.User{UserID: "test-user-id"})
+	req = req.WithContext(checkauth.SetAPITokenContext(ctx, &models.APIToken{Scopes: pq.StringArray{"deploy"}}))
+	w := httptest.NewRecorder()
+	handler.CreateJob(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := []string(created.SecretScopes); len(got) != 1 || got[0] != "deploy" {
+		t.Errorf("SecretScopes = %v, want the token's scopes", got)
+	}
+}
+
+func TestJobHandler_CreateJob_ProjectToken(t *testing.T) {
+	var created *models.Job
+	mockStore := &MockStore{
+		CreateJobFunc: func(ctx context.Context, job *models.Job) error {
+			job.JobID = "test-job-id"
+			created = job
+			return nil
+		},
+	}
+	handler := NewJobHandler(mockStore, nil)
+	projectID := "project-1"
+	create := func(body string, token *models.APIToken) *httptest.ResponseRecorder {
+		created = nil
+		req := httptest.NewRequest("POST", "/api/v1/jobs", strings.NewReader(body))
+		ctx := checkauth.SetUserContext(req.Context(), &models.User{UserID: "test-user-id"})
+		if token != nil {
+			ctx = checkauth.SetAPITokenContext(ctx, token)
+		}
+		w := httptest.NewRecorder()
+		handler.CreateJob(w, req.WithContext(ctx))
+		return w
+	}
+	deployToken := &models.APIToken{ProjectID: &projectID}
+
+	for _, body := range []string{
+		`{"name":"docs","job_command":"make docs","source_type":"copy","source_path":"/src"}`,
+		`{"name":"docs","job_command":"make docs","source_type":"copy","source_path":"/src","project_id":"project-1"}`,
+	} {
+		w := create(body, deployToken)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+		}
+		if created.ProjectID == nil || *created.ProjectID != projectID {
+			t.Errorf("ProjectID = %v, want the token's project", created.ProjectID)
+		}
+	}
+
+	other := `{"name":"docs","job_command":"make docs","source_type":"copy","source_path":"/src","project_id":"project-2"}`
+	if w := create(other, deployToken); w.Code != http.StatusForbidden || created != nil {
+		t.Errorf("expected another project to be refused, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := create(other, &models.APIToken{}); w.Code != http.StatusForbidden || created != nil {
+		t.Errorf("expected project_id to be refused without a project-bound token, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestJobHandler_CreateJob_PreJobCreateHook(t *testing.T) {
+	created := false
+	mockStore := &MockStore{
+		CreateJobFunc: func(ctx context.Context, job *models.Job) error {
+			created = true
+			return nil
+		},
+	}
+	handler := NewJobHandler(mockStore, nil)
+	defer hooks.Register(hooks.PreJobCreate, "ticket", hooks.HookFunc(func(ctx context.Context, event *hooks.Event) error {
+		if event.Source != hooks.SourceAPI {
+			t.Errorf("expected source %q, got %q", hooks.SourceAPI, event.Source)
+		}
+		if !strings.Contains(event.Job.Name, "PROJ-") {
+			return fmt.Errorf("job name needs a ticket number")
+		}
+		return nil
+	}))()
+
+	req := httptest.NewRequest("POST", "/api/v1/jobs", strings.NewReader(`{"name":"deploy","job_command":"make","source_type":"copy","source_path":"/src"}`))
+	req = req.WithContext(checkauth.SetUserContext(req.Context(), &models.User{UserID: "test-user-id"}))
+	w := httptest.NewRecorder()
+	handler.CreateJob(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "job name needs a ticket number")
+	assert.False(t, created, "a rejected job is never saved")
+}
+
 func TestJobHandler_CancelJob_WithCorndogs(t *testing.T) {
 	tests := []struct {
 		name                  string
@@ -964,6 +1154,30 @@ func TestJobHandler_CICodeAllowlist(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			errorContains:  "invalid_input",
 		},
+		{
+			name: "file source URL - rejected by source URL policy",
+			request: CreateJobRequest{
+				Name:       "Test Job",
+				JobCommand: "echo hello",
+				SourceType: "git",
+				SourceURL:  "file:///etc",
+			},
+			expectedStatus: http.StatusForbidden,
+			errorContains:  "scheme",
+		},
+		{
+			name: "metadata service CI source URL - rejected by source URL policy",
+			request: CreateJobRequest{
+				Name:         "Test Job",
+				JobCommand:   "echo hello",
+				SourceType:   "git",
+				SourceURL:    "https://github.com/test/repo.git",
+				CISourceType: "git",
+				CISourceURL:  "http://169.254.169.254/ci.git",
+			},
+			expectedStatus: http.StatusForbidden,
+			errorContains:  "internal",
+		},
 	}
 
 	for _, tt := range tests {
@@ -1558,3 +1772,94 @@ func TestGetJobLogsObjectStoreNotConfigured(t *testing.T) {
 		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
 	})
 }
+
+func TestJobHandler_ListChildJobs(t *testing.T) {
+	parentJobID := "parent-job-123"
+	testUser := &models.User{UserID: "test-user-id"}
+	parentJob := &models.Job{JobID: parentJobID, UserID: testUser.UserID, Status: "running"}
+
+	var gotFilters map[string]interface{}
+	mockStore := &MockStore{
+		GetJobByIDFunc: func(ctx context.Context, jobID string) (*models.Job, error) {
+			if jobID == parentJobID {
+				return parentJob, nil
+			}
+			return nil, store.ErrNotFound
+		},
+		ListJobsFunc: func(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]models.Job, error) {
+			gotFilters = filters
+			return []models.Job{
+				{JobID: "c1", UserID: testUser.UserID, Status: "completed", ParentJobID: &parentJobID},
+				{JobID: "c2", UserID: testUser.UserID, Status: "failed", ParentJobID: &parentJobID},
+				{JobID: "c3", UserID: testUser.UserID, Status: "running", ParentJobID: &parentJobID},
+			}, nil
+		},
+	}
+	handler := NewJobHandler(mockStore, nil)
+
+	req := httptest.NewRequest("GET", "/api/v1/jobs/"+parentJobID+"/children?limit=2", nil)
+	ctx := checkauth.SetUserContext(req.Context(), testUser)
+	ctx = context.WithValue(ctx, GetContextKey("job_id"), parentJobID)
+	req = req.WithContext(ctx)
+	rr := httptest.NewRecorder()
+
+	handler.ListChildJobs(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, parentJobID, gotFilters["parent_job_id"])
+
+	var resp ListChildJobsResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Equal(t, models.ChildJobsSummary{Status: models.ChildStatusRunning, Total: 3, Running: 1, Succeeded: 1, Failed: 1}, resp.Aggregate)
+	assert.Equal(t, 3, resp.Total)
+	assert.Len(t, resp.Jobs, 2)
+}
+
+func TestJobHandler_ListJobs_Cursor(t *testing.T) {
+	created := time.Date(2026, 3, 1, 12, 0, 0, 123456000, time.UTC)
+	jobs := []models.Job{
+		{JobID: uuid.NewString(), UserID: "caller", CreatedAt: created.Add(time.Minute)},
+		{JobID: uuid.NewString(), UserID: "caller", CreatedAt: created},
+	}
+	var gotFilters map[string]interface{}
+	var gotOffset int
+	ms := &MockStore{
+		ListJobsFunc: func(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]models.Job, error) {
+			gotFilters, gotOffset = filters, offset
+			return jobs, nil
+		},
+	}
+	h := NewJobHandler(ms, nil)
+	caller := &models.User{UserID: "caller", Roles: []string{"user"}}
+	list := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/jobs"+query, nil)
+		req = req.WithContext(checkauth.SetUserContext(req.Context(), caller))
+		rr := httptest.NewRecorder()
+		h.ListJobs(rr, req)
+		return rr
+	}
+
+	// A full page links to the next one, from its last job.
+	rr := list("?limit=2")
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var resp ListJobsResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.NotEmpty(t, resp.NextCursor)
+	assert.NotContains(t, gotFilters, store.JobCursorFilter)
+
+	rr = list("?limit=2&offset=40&cursor=" + resp.NextCursor)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, store.JobCursor{CreatedAt: created, JobID: jobs[1].JobID}, gotFilters[store.JobCursorFilter])
+	assert.Zero(t, gotOffset, "a cursor replaces the offset")
+
+	// A short page is the last.
+	rr = list("?limit=3")
+	resp = ListJobsResponse{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Empty(t, resp.NextCursor)
+
+	for _, bad := range []string{"not-base64!", store.JobCursor{CreatedAt: created, JobID: "not-a-uuid"}.Encode()} {
+		rr = list("?cursor=" + bad)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, bad)
+	}
+}
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// JobResponseV2 is a job as /api/v2 returns it: JobResponse's fields
+// grouped by what they describe, with LastError renamed Error. It is always
+// built from a JobResponse (see jobResponseV2), so the two versions can't
+// disagree about a job.
+type JobResponseV2 struct {
+	JobID       string  `json:"job_id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Status      string  `json:"status"`
+	Error       string  `json:"error,omitempty"`
+	ProjectID   *string `json:"project_id,omitempty"`
+	Archived    bool    `json:"archived,omitempty"`
+
+	Source   JobSourceV2   `json:"source"`
+	CISource *JobSourceV2  `json:"ci_source,omitempty"`
+	Run      JobRunV2      `json:"run"`
+	Schedule JobScheduleV2 `json:"schedule"`
+	Timing   JobTimingV2   `json:"timing"`
+	Result   JobResultV2   `json:"result"`
+	Lineage  JobLineageV2  `json:"lineage"`
+
+	// LatestNote is the newest note on the job (list responses only).
+	LatestNote *models.Note `json:"latest_note,omitempty"`
+}
+
+// JobSourceV2 is where a job's code (or, as CISource, its trusted CI
+// pipeline code) comes from.
+type JobSourceV2 struct {
+	Type string `json:"type,omitempty"`
+	URL  string `json:"url,omitempty"`
+	Ref  string `json:"ref,omitempty"`
+	Path string `json:"path,omitempty"`
+
+	// CoalescedSHAs are the earlier pushes' commits a push eval job
+	// superseded.
+	CoalescedSHAs []string `json:"coalesced_shas,omitempty"`
+}
+
+// JobRunV2 is how a job's container is run.
+type JobRunV2 struct {
+	Image          string            `json:"image"`
+	Command        string            `json:"command"`
+	JobFile        string            `json:"job_file,omitempty"`
+	CodeDir        string            `json:"code_dir"`
+	JobDir         string            `json:"job_dir"`
+	Env            map[string]string `json:"env,omitempty"`
+	EnvFile        string            `json:"env_file,omitempty"`
+	RunAsUser      string            `json:"run_as_user,omitempty"`
+	OS             string            `json:"os,omitempty"`
+	Arch           string            `json:"arch,omitempty"`
+	TimeoutSeconds int               `json:"timeout_seconds"`
+	Scan           *models.ScanSpec  `json:"scan,omitempty"`
+	AwaitChildren  bool              `json:"await_children,omitempty"`
+	DebugOnFailure bool              `json:"debug_on_failure,omitempty"`
+}
+
+// JobScheduleV2 is where and when a job runs.
+type JobScheduleV2 struct {
+	Queue            string     `json:"queue"`
+	Priority         int        `json:"priority"`
+	Affinity         string     `json:"affinity,omitempty"`
+	AvoidWorkers     []string   `json:"avoid_workers,omitempty"`
+	WorkerID         *string    `json:"worker_id,omitempty"`
+	Environment      string     `json:"environment,omitempty"`
+	FreezeWindowID   *string    `json:"freeze_window_id,omitempty"`
+	Deadline         *time.Time `json:"deadline,omitempty"`
+	DeadlineMissedAt *time.Time `json:"deadline_missed_at,omitempty"`
+	BoostedBy        *string    `json:"boosted_by,omitempty"`
+	BoostedAt        *time.Time `json:"boosted_at,omitempty"`
+}
+
+// JobTimingV2 is a job's lifecycle timestamps.
+type JobTimingV2 struct {
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// JobResultV2 is what a job produced.
+type JobResultV2 struct {
+	ExitCode           *int                      `json:"exit_code,omitempty"`
+	LogsObjectKey      string                    `json:"logs_object_key,omitempty"`
+	ArtifactsObjectKey string                    `json:"artifacts_object_key,omitempty"`
+	GateResults        models.GateResults        `json:"gate_results,omitempty"`
+	PublishedArtifacts models.PublishedArtifacts `json:"published_artifacts,omitempty"`
+	Resources          *models.JobResources      `json:"resources,omitempty"`
+	TriggerValidation  map[string]interface{}    `json:"trigger_validation,omitempty"`
+}
+
+// JobLineageV2 is the jobs and workflow a job is related to.
+type JobLineageV2 struct {
+	ParentJobID      *string  `json:"parent_job_id,omitempty"`
+	WorkflowID       *string  `json:"workflow_id,omitempty"`
+	WorkflowNodeID   *string  `json:"workflow_node_id,omitempty"`
+	WorkflowRunID    *string  `json:"workflow_run_id,omitempty"`
+	WorkflowNodeName string   `json:"workflow_node_name,omitempty"`
+	UpstreamJobID    *string  `json:"upstream_job_id,omitempty"`
+	DownstreamJobIDs []string `json:"downstream_job_ids,omitempty"`
+}
+
+// ListJobsResponseV2 is the response for GET /api/v2/jobs.
+type ListJobsResponseV2 struct {
+	Jobs       []JobResponseV2 `json:"jobs"`
+	Total      int             `json:"total"`
+	Limit      int             `json:"limit"`
+	Offset     int             `json:"offset"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+// jobResponseV2 regroups a v1 job response as v2.
+func jobResponseV2(job JobResponse) JobResponseV2 {
+	response := JobResponseV2{
+		JobID:       job.JobID,
+		Name:        job.Name,
+		Description: job.Description,
+		Status:      job.Status,
+		Error:       job.LastError,
+		ProjectID:   job.ProjectID,
+		Archived:    job.Archived,
+		LatestNote:  job.LatestNote,
+		Source: JobSourceV2{
+			Type:          job.SourceType,
+			URL:           job.SourceURL,
+			Ref:           job.SourceRef,
+			Path:          job.SourcePath,
+			CoalescedSHAs: job.CoalescedSHAs,
+		},
+		Run: JobRunV2{
+			Image:          job.RunnerImage,
+			Command:        job.JobCommand,
+			JobFile:        job.JobFile,
+			CodeDir:        job.CodeDir,
+			JobDir:         job.JobDir,
+			Env:            job.JobEnvVars,
+			EnvFile:        job.JobEnvFile,
+			RunAsUser:      job.RunAsUser,
+			OS:             job.TargetOS,
+			Arch:           job.TargetArch,
+			TimeoutSeconds: job.TimeoutSeconds,
+			Scan:           job.Scan,
+			AwaitChildren:  job.AwaitChildren,
+			DebugOnFailure: job.DebugOnFailure,
+		},
+		Schedule: JobScheduleV2{
+			Queue:            job.QueueName,
+			Priority:         job.Priority,
+			Affinity:         job.Affinity,
+			AvoidWorkers:     job.AvoidWorkers,
+			WorkerID:         job.WorkerID,
+			Environment:      job.Environment,
+			FreezeWindowID:   job.FreezeWindowID,
+			Deadline:         job.Deadline,
+			DeadlineMissedAt: job.DeadlineMissedAt,
+			BoostedBy:        job.BoostedBy,
+			BoostedAt:        job.BoostedAt,
+		},
+		Timing: JobTimingV2{
+			CreatedAt:   job.CreatedAt,
+			UpdatedAt:   job.UpdatedAt,
+			StartedAt:   job.StartedAt,
+			CompletedAt: job.CompletedAt,
+		},
+		Result: JobResultV2{
+			ExitCode:           job.ExitCode,
+			LogsObjectKey:      job.LogsObjectKey,
+			ArtifactsObjectKey: job.ArtifactsObjectKey,
+			GateResults:        job.GateResults,
+			PublishedArtifacts: job.PublishedArtifacts,
+			Resources:          job.Resources,
+			TriggerValidation:  job.TriggerValidation,
+		},
+		Lineage: JobLineageV2{
+			ParentJobID:      job.ParentJobID,
+			WorkflowID:       job.WorkflowID,
+			WorkflowNodeID:   job.WorkflowNodeID,
+			WorkflowRunID:    job.WorkflowRunID,
+			WorkflowNodeName: job.WorkflowNodeName,
+			UpstreamJobID:    job.UpstreamJobID,
+			DownstreamJobIDs: job.DownstreamJobIDs,
+		},
+	}
+	if job.CISourceType != "" || job.CISourceURL != "" || job.CISourceRef != "" {
+		response.CISource = &JobSourceV2{
+			Type: job.CISourceType,
+			URL:  job.CISourceURL,
+			Ref:  job.CISourceRef,
+		}
+	}
+	return response
+}
+
+// GetJobV2 handles GET /api/v2/jobs/{job_id}
+func (h *JobHandler) GetJobV2(w http.ResponseWriter, r *http.Request) {
+	if response, ok := h.getJobResponse(w, r); ok {
+		h.respondWithJSON(w, http.StatusOK, jobResponseV2(response))
+	}
+}
+
+// ListJobsV2 handles GET /api/v2/jobs. It takes the same filters,
+// pagination and cursor as GET /api/v1/jobs.
+func (h *JobHandler) ListJobsV2(w http.ResponseWriter, r *http.Request) {
+	list, ok := h.listJobs(w, r)
+	if !ok {
+		return
+	}
+	jobs := make([]JobResponseV2, len(list.Jobs))
+	for i, job := range list.Jobs {
+		jobs[i] = jobResponseV2(job)
+	}
+	h.respondWithJSON(w, http.StatusOK, ListJobsResponseV2{
+		Jobs:       jobs,
+		Total:      list.Total,
+		Limit:      list.Limit,
+		Offset:     list.Offset,
+		NextCursor: list.NextCursor,
+	})
+}
@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/worker"
+)
+
+// Formats of GET /api/v1/jobs/{job_id}/logs/archive.
+const (
+	logArchiveZip   = "zip"
+	logArchiveTarGz = "tar.gz"
+)
+
+// LogTimelineEvent is an entry of a log archive's timeline.json.
+type LogTimelineEvent struct {
+	Time   string `json:"time"`
+	Event  string `json:"event"`
+	Stream string `json:"stream,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// GetJobLogArchive handles GET /api/v1/jobs/{job_id}/logs/archive: a job's
+// logs as one download to attach to an incident ticket. format is zip (the
+// default) or tar.gz. The archive holds, under a directory named for the
+// job:
+//
+//   - stdout.log and stderr.log, one "timestamp message" line per entry;
+//   - steps/NN-title.log, the combined log of each of the log's sections;
+//   - timeline.json, the job's lifecycle and its sections' starts and ends;
+//   - metadata.json, the job as GET /api/v1/jobs/{job_id} returns it.
+//
+// Each file is written to the response as soon as it's built from the
+// object store, one stream at a time. Same access as GetJobLogs.
+func (h *JobHandler) GetJobLogArchive(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.loadDownloadableJob(w, r)
+	if !ok {
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = logArchiveZip
+	}
+	if format != logArchiveZip && format != logArchiveTarGz {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_input",
+			Message: "format must be zip or tar.gz",
+		})
+		return
+	}
+
+	streams := map[string][]LogEntry{}
+	for _, stream := range []string{"stdout", "stderr"} {
+		entries, err := h.fetchLogEntries(r.Context(), job, stream)
+		if err == objects.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			h.respondWithError(w, http.StatusInternalServerError, err)
+			return
+		}
+		streams[stream] = entries
+	}
+	if len(streams) == 0 {
+		h.respondWithError(w, http.StatusNotFound, store.ErrNotFound)
+		return
+	}
+
+	name := "job-" + job.JobID + "-logs." + format
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, name))
+	if format == logArchiveZip {
+		w.Header().Set("Content-Type", "application/zip")
+	} else {
+		w.Header().Set("Content-Type", "application/gzip")
+	}
+	w.WriteHeader(http.StatusOK)
+
+	archive := newLogArchive(w, format)
+	if err := h.writeLogArchive(archive, job, streams); err != nil {
+		logging.Log.WithError(err).WithField("job_id", job.JobID).Warn("Failed to write job log archive")
+		return
+	}
+	if err := archive.Close(); err != nil {
+		logging.Log.WithError(err).WithField("job_id", job.JobID).Warn("Failed to finish job log archive")
+	}
+}
+
+// fetchLogEntries fetches and parses one stream of the job's log.
+func (h *JobHandler) fetchLogEntries(ctx context.Context, job *models.Job, stream string) ([]LogEntry, error) {
+	content, err := h.fetchStreamLog(ctx, job, stream)
+	if err != nil {
+		return nil, err
+	}
+	var entries []LogEntry
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse %s logs: %w", stream, err)
+	}
+	return entries, nil
+}
+
+// writeLogArchive writes the archive's files, described at
+// GetJobLogArchive.
+func (h *JobHandler) writeLogArchive(archive logArchive, job *models.Job, streams map[string][]LogEntry) error {
+	dir := job.JobID + "/"
+	modTime := job.UpdatedAt
+	for _, stream := range []string{"stdout", "stderr"} {
+		if entries, ok := streams[stream]; ok {
+			if err := archive.Add(dir+stream+".log", modTime, formatLogLines(entries, false)); err != nil {
+				return err
+			}
+		}
+	}
+
+	combined := append(append([]LogEntry(nil), streams["stdout"]...), streams["stderr"]...)
+	sortLogEntries(combined)
+	outline := outlineLog(combined)
+	for i, section := range outline.Sections {
+		end := section.EndOffset
+		if section.Open {
+			end = len(combined)
+		}
+		var entries []LogEntry
+		for _, entry := range combined[section.StartOffset:end] {
+			if entry.Stream == section.Stream {
+				entries = append(entries, entry)
+			}
+		}
+		name := fmt.Sprintf("%ssteps/%02d-%s.log", dir, i+1, logArchiveFileName(section.Title))
+		if err := archive.Add(name, modTime, formatLogLines(entries, true)); err != nil {
+			return err
+		}
+	}
+
+	timeline, err := json.MarshalIndent(logTimeline(job, combined), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := archive.Add(dir+"timeline.json", modTime, timeline); err != nil {
+		return err
+	}
+	metadata, err := json.MarshalIndent(h.jobToResponse(job), "", "  ")
+	if err != nil {
+		return err
+	}
+	return archive.Add(dir+"metadata.json", modTime, metadata)
+}
+
+// sortLogEntries sorts entries by timestamp as the combined log does.
+func sortLogEntries(entries []LogEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp < entries[j].Timestamp
+	})
+}
+
+// formatLogLines renders entries as text, one "timestamp message" line
+// each, with the stream when withStream is set.
+func formatLogLines(entries []LogEntry, withStream bool) []byte {
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		buf.WriteString(entry.Timestamp)
+		if withStream {
+			buf.WriteString(" [" + entry.Stream + "]")
+		}
+		buf.WriteString(" " + strings.TrimRight(entry.Message, "\n") + "\n")
+	}
+	return buf.Bytes()
+}
+
+// logTimeline is the job's lifecycle, with the starts and ends of the
+// sections of its combined log, in order.
+func logTimeline(job *models.Job, combined []LogEntry) []LogTimelineEvent {
+	events := []LogTimelineEvent{{Time: job.CreatedAt.UTC().Format(time.RFC3339Nano), Event: "created"}}
+	if job.StartedAt != nil {
+		events = append(events, LogTimelineEvent{Time: job.StartedAt.UTC().Format(time.RFC3339Nano), Event: "started"})
+	}
+	for _, entry := range combined {
+		switch entry.Section {
+		case worker.LogSectionStart:
+			events = append(events, LogTimelineEvent{Time: entry.Timestamp, Event: "step_started", Stream: entry.Stream, Detail: entry.Message})
+		case worker.LogSectionEnd:
+			events = append(events, LogTimelineEvent{Time: entry.Timestamp, Event: "step_finished", Stream: entry.Stream})
+		}
+	}
+	if job.DeadlineMissedAt != nil {
+		events = append(events, LogTimelineEvent{Time: job.DeadlineMissedAt.UTC().Format(time.RFC3339Nano), Event: "deadline_missed"})
+	}
+	if job.CompletedAt != nil {
+		events = append(events, LogTimelineEvent{Time: job.CompletedAt.UTC().Format(time.RFC3339Nano), Event: job.Status, Detail: job.LastError})
+	}
+	return events
+}
+
+// logArchiveFileName turns a section title into a file name: lower case
+// letters, digits and dashes, at most 50 characters.
+func logArchiveFileName(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, c := range strings.ToLower(title) {
+		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') {
+			b.WriteRune(c)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+		if b.Len() >= 50 {
+			break
+		}
+	}
+	name := strings.TrimRight(b.String(), "-")
+	if name == "" {
+		return "step"
+	}
+	return name
+}
+
+// logArchive writes the files of a log archive.
+type logArchive interface {
+	Add(name string, modTime time.Time, content []byte) error
+	Close() error
+}
+
+func newLogArchive(w io.Writer, format string) logArchive {
+	if format == logArchiveTarGz {
+		gz := gzip.NewWriter(w)
+		return &tarLogArchive{gz: gz, tw: tar.NewWriter(gz)}
+	}
+	return &zipLogArchive{zw: zip.NewWriter(w)}
+}
+
+type zipLogArchive struct {
+	zw *zip.Writer
+}
+
+func (a *zipLogArchive) Add(name string, modTime time.Time, content []byte) error {
+	f, err := a.zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modTime})
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(content)
+	return err
+}
+
+func (a *zipLogArchive) Close() error {
+	return a.zw.Close()
+}
+
+type tarLogArchive struct {
+	gz *gzip.Writer
+	tw *tar.Writer
+}
+
+func (a *tarLogArchive) Add(name string, modTime time.Time, content []byte) error {
+	if err := a.tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), ModTime: modTime}); err != nil {
+		return err
+	}
+	_, err := a.tw.Write(content)
+	return err
+}
+
+func (a *tarLogArchive) Close() error {
+	if err := a.tw.Close(); err != nil {
+		return err
+	}
+	return a.gz.Close()
+}
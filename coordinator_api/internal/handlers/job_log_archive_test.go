@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/worker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetJobLogArchive(t *testing.T) {
+	started := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	completed := started.Add(10 * time.Second)
+	job := &models.Job{
+		JobID: "archive-job", UserID: "test-user-id", Name: "build", Status: "failed", LastError: "exit code 1",
+		CreatedAt: started.Add(-time.Minute), StartedAt: &started, CompletedAt: &completed,
+	}
+	s := &MockStore{GetJobByIDFunc: func(ctx context.Context, jobID string) (*models.Job, error) {
+		return job, nil
+	}}
+	memStore := objects.NewMemoryObjectStore()
+	put := func(stream string, entries []LogEntry) {
+		content, _ := json.Marshal(entries)
+		require.NoError(t, memStore.Put(context.Background(), "logs/archive-job/"+stream+".json", bytes.NewReader(content), "application/json"))
+	}
+	put("stdout", []LogEntry{
+		{Timestamp: "2026-01-01T10:00:00Z", Stream: "stdout", Message: "Build & package", Group: "Build & package", Section: worker.LogSectionStart},
+		{Timestamp: "2026-01-01T10:00:01Z", Stream: "stdout", Message: "compiling", Group: "Build & package"},
+		{Timestamp: "2026-01-01T10:00:03Z", Stream: "stdout", Message: "Build & package", Group: "Build & package", Section: worker.LogSectionEnd},
+		{Timestamp: "2026-01-01T10:00:04Z", Stream: "stdout", Message: "Test", Group: "Test", Section: worker.LogSectionStart},
+		{Timestamp: "2026-01-01T10:00:05Z", Stream: "stdout", Message: "running", Group: "Test"},
+	})
+	put("stderr", []LogEntry{
+		{Timestamp: "2026-01-01T10:00:02Z", Stream: "stderr", Message: "deprecated flag"},
+	})
+	handler := NewJobHandlerWithObjectStore(s, nil, memStore)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/jobs/archive-job/logs/archive"+query, nil)
+		req = req.WithContext(context.WithValue(withUser(req).Context(), GetContextKey("job_id"), "archive-job"))
+		w := httptest.NewRecorder()
+		handler.GetJobLogArchive(w, req)
+		return w
+	}
+	wantFiles := []string{
+		"archive-job/stdout.log",
+		"archive-job/stderr.log",
+		"archive-job/steps/01-build-package.log",
+		"archive-job/steps/02-test.log",
+		"archive-job/timeline.json",
+		"archive-job/metadata.json",
+	}
+	check := func(t *testing.T, files map[string]string, names []string) {
+		assert.Equal(t, wantFiles, names)
+		assert.Equal(t, "2026-01-01T10:00:02Z deprecated flag\n", files["archive-job/stderr.log"])
+		assert.Equal(t, "2026-01-01T10:00:00Z [stdout] Build & package\n"+
+			"2026-01-01T10:00:01Z [stdout] compiling\n"+
+			"2026-01-01T10:00:03Z [stdout] Build & package\n", files["archive-job/steps/01-build-package.log"])
+
+		var timeline []LogTimelineEvent
+		require.NoError(t, json.Unmarshal([]byte(files["archive-job/timeline.json"]), &timeline))
+		var events []string
+		for _, e := range timeline {
+			events = append(events, e.Event)
+		}
+		assert.Equal(t, []string{"created", "started", "step_started", "step_finished", "step_started", "failed"}, events)
+		assert.Equal(t, "exit code 1", timeline[len(timeline)-1].Detail)
+
+		var metadata JobResponse
+		require.NoError(t, json.Unmarshal([]byte(files["archive-job/metadata.json"]), &metadata))
+		assert.Equal(t, "archive-job", metadata.JobID)
+	}
+
+	t.Run("zip", func(t *testing.T) {
+		w := get("")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "job-archive-job-logs.zip")
+
+		zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+		require.NoError(t, err)
+		files := map[string]string{}
+		var names []string
+		for _, f := range zr.File {
+			rc, err := f.Open()
+			require.NoError(t, err)
+			content, _ := io.ReadAll(rc)
+			rc.Close()
+			files[f.Name] = string(content)
+			names = append(names, f.Name)
+		}
+		check(t, files, names)
+	})
+
+	t.Run("tar.gz", func(t *testing.T) {
+		w := get("?format=tar.gz")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "application/gzip", w.Header().Get("Content-Type"))
+
+		gz, err := gzip.NewReader(w.Body)
+		require.NoError(t, err)
+		tr := tar.NewReader(gz)
+		files := map[string]string{}
+		var names []string
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			content, _ := io.ReadAll(tr)
+			files[hdr.Name] = string(content)
+			names = append(names, hdr.Name)
+		}
+		check(t, files, names)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, get("?format=rar").Code)
+	})
+
+	t.Run("no logs", func(t *testing.T) {
+		handler = NewJobHandlerWithObjectStore(s, nil, objects.NewMemoryObjectStore())
+		assert.Equal(t, http.StatusNotFound, get("").Code)
+	})
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/worker"
+)
+
+// LogSection is a ::group:: section of a job's log. Offsets are entry
+// offsets in the stream requested, as taken by GET /api/v1/jobs/{job_id}/logs?offset=.
+type LogSection struct {
+	Title       string `json:"title"`
+	Stream      string `json:"stream"`
+	StartOffset int    `json:"start_offset"`
+	// EndOffset is the offset after the section's last entry. Open
+	// sections, never closed with ::endgroup::, run to the log's end.
+	EndOffset int  `json:"end_offset"`
+	Open      bool `json:"open,omitempty"`
+	Errors    int  `json:"errors"`
+	Warnings  int  `json:"warnings"`
+}
+
+// LogProblem is an ::error::, ::warning:: or ::notice:: annotation and the
+// entry it's on.
+type LogProblem struct {
+	worker.LogAnnotation
+	Offset  int    `json:"offset"`
+	Stream  string `json:"stream"`
+	Group   string `json:"group,omitempty"`
+	Message string `json:"message"`
+}
+
+// LogSectionsResponse is the JSON body of
+// GET /api/v1/jobs/{job_id}/logs/sections.
+type LogSectionsResponse struct {
+	Sections    []LogSection `json:"sections"`
+	Annotations []LogProblem `json:"annotations"`
+}
+
+// GetJobLogSections handles GET /api/v1/jobs/{job_id}/logs/sections: the
+// outline of a job's log, its sections and problem annotations, as parsed
+// from the job's workflow commands when the worker shipped the log.
+// stream is as for GetJobLogs. Same access as GetJobLogs.
+func (h *JobHandler) GetJobLogSections(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.loadDownloadableJob(w, r)
+	if !ok {
+		return
+	}
+	stream := r.URL.Query().Get("stream")
+	if stream == "" {
+		stream = "combined"
+	}
+	if stream != "stdout" && stream != "stderr" && stream != "combined" {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+
+	content, err := h.fetchJobLog(r.Context(), job, stream)
+	if err != nil {
+		if err == objects.ErrNotFound {
+			h.respondWithError(w, http.StatusNotFound, store.ErrNotFound)
+			return
+		}
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	var entries []LogEntry
+	if err := json.Unmarshal(content, &entries); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, fmt.Errorf("failed to parse logs: %w", err))
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, outlineLog(entries))
+}
+
+// outlineLog collects the sections and annotations of a log's entries.
+// Each stream's sections are tracked separately, since a combined log
+// interleaves them.
+func outlineLog(entries []LogEntry) LogSectionsResponse {
+	resp := LogSectionsResponse{Sections: []LogSection{}, Annotations: []LogProblem{}}
+	open := map[string]int{} // stream -> index into resp.Sections
+	closeSection := func(stream string, end int) {
+		if i, ok := open[stream]; ok {
+			resp.Sections[i].EndOffset = end
+			resp.Sections[i].Open = false
+			delete(open, stream)
+		}
+	}
+
+	for offset, entry := range entries {
+		switch entry.Section {
+		case worker.LogSectionStart:
+			closeSection(entry.Stream, offset)
+			open[entry.Stream] = len(resp.Sections)
+			resp.Sections = append(resp.Sections, LogSection{
+				Title:       entry.Message,
+				Stream:      entry.Stream,
+				StartOffset: offset,
+				Open:        true,
+			})
+		case worker.LogSectionEnd:
+			closeSection(entry.Stream, offset+1)
+		}
+
+		if entry.Annotation == nil {
+			continue
+		}
+		resp.Annotations = append(resp.Annotations, LogProblem{
+			LogAnnotation: *entry.Annotation,
+			Offset:        offset,
+			Stream:        entry.Stream,
+			Group:         entry.Group,
+			Message:       entry.Message,
+		})
+		if i, ok := open[entry.Stream]; ok {
+			switch entry.Annotation.Level {
+			case "error":
+				resp.Sections[i].Errors++
+			case "warning":
+				resp.Sections[i].Warnings++
+			}
+		}
+	}
+
+	for _, i := range open {
+		resp.Sections[i].EndOffset = len(entries)
+	}
+	return resp
+}
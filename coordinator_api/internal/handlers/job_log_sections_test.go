@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/worker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetJobLogSections(t *testing.T) {
+	job := &models.Job{JobID: "sections-job", UserID: "test-user-id", Status: "running"}
+	s := &MockStore{GetJobByIDFunc: func(ctx context.Context, jobID string) (*models.Job, error) {
+		return job, nil
+	}}
+	memStore := objects.NewMemoryObjectStore()
+	put := func(stream string, entries []LogEntry) {
+		content, _ := json.Marshal(entries)
+		require.NoError(t, memStore.Put(context.Background(), "logs/sections-job/"+stream+".json", bytes.NewReader(content), "application/json"))
+	}
+	put("stdout", []LogEntry{
+		{Timestamp: "2026-01-01T10:00:00Z", Stream: "stdout", Message: "Build", Group: "Build", Section: worker.LogSectionStart},
+		{Timestamp: "2026-01-01T10:00:01Z", Stream: "stdout", Message: "compiling", Group: "Build"},
+		{Timestamp: "2026-01-01T10:00:03Z", Stream: "stdout", Message: "Build", Group: "Build", Section: worker.LogSectionEnd},
+		{Timestamp: "2026-01-01T10:00:04Z", Stream: "stdout", Message: "Test", Group: "Test", Section: worker.LogSectionStart},
+		{Timestamp: "2026-01-01T10:00:05Z", Stream: "stdout", Message: "running", Group: "Test"},
+	})
+	put("stderr", []LogEntry{
+		{Timestamp: "2026-01-01T10:00:02Z", Stream: "stderr", Level: "warning", Message: "deprecated flag", Annotation: &worker.LogAnnotation{Level: "warning", File: "main.go", Line: 4}},
+	})
+	handler := NewJobHandlerWithObjectStore(s, nil, memStore)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/jobs/sections-job/logs/sections"+query, nil)
+		req = req.WithContext(context.WithValue(withUser(req).Context(), GetContextKey("job_id"), "sections-job"))
+		w := httptest.NewRecorder()
+		handler.GetJobLogSections(w, req)
+		return w
+	}
+
+	w := get("")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp LogSectionsResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+
+	// Combined: the stderr warning sorts in at offset 2, inside no stderr
+	// section, and moves the rest of stdout along.
+	assert.Equal(t, []LogSection{
+		{Title: "Build", Stream: "stdout", StartOffset: 0, EndOffset: 4},
+		{Title: "Test", Stream: "stdout", StartOffset: 4, EndOffset: 6, Open: true},
+	}, resp.Sections)
+	require.Len(t, resp.Annotations, 1)
+	assert.Equal(t, 2, resp.Annotations[0].Offset)
+	assert.Equal(t, "main.go", resp.Annotations[0].File)
+	assert.Equal(t, "deprecated flag", resp.Annotations[0].Message)
+
+	resp = LogSectionsResponse{}
+	w = get("?stream=stdout")
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, 3, resp.Sections[0].EndOffset)
+	assert.Empty(t, resp.Annotations)
+
+	assert.Equal(t, http.StatusBadRequest, get("?stream=bogus").Code)
+}
+
+func TestOutlineLog_CountsProblems(t *testing.T) {
+	resp := outlineLog([]LogEntry{
+		{Stream: "stdout", Message: "Lint", Group: "Lint", Section: worker.LogSectionStart},
+		{Stream: "stdout", Message: "x", Group: "Lint", Annotation: &worker.LogAnnotation{Level: "error"}},
+		{Stream: "stdout", Message: "y", Group: "Lint", Annotation: &worker.LogAnnotation{Level: "warning"}},
+		{Stream: "stdout", Message: "Vet", Group: "Vet", Section: worker.LogSectionStart},
+	})
+	require.Len(t, resp.Sections, 2)
+	assert.Equal(t, LogSection{Title: "Lint", Stream: "stdout", StartOffset: 0, EndOffset: 3, Errors: 1, Warnings: 1}, resp.Sections[0])
+	assert.Equal(t, "Lint", resp.Annotations[0].Group)
+	assert.True(t, resp.Sections[1].Open)
+}
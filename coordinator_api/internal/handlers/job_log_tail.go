@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// LogNextOffsetHeader is the follow token of GET /api/v1/jobs/{job_id}/logs:
+// the entry offset to pass as ?offset= to get the entries after this
+// response's.
+const LogNextOffsetHeader = "X-Log-Next-Offset"
+
+// LogCompleteHeader is "true" once the job has finished, so following its
+// logs can stop, and "false" while more entries may come.
+const LogCompleteHeader = "X-Log-Complete"
+
+// logWindow selects the entries of a log to return: from offset on, or the
+// last tail of them (tail is -1 when not given). Neither set means all of
+// them.
+type logWindow struct {
+	offset int
+	tail   int
+	set    bool
+}
+
+// parseLogWindow reads the offset and tail query parameters.
+func parseLogWindow(query url.Values) (logWindow, error) {
+	window := logWindow{tail: -1}
+	offset, tail := query.Get("offset"), query.Get("tail")
+	if offset != "" && tail != "" {
+		return window, fmt.Errorf("offset and tail can't be used together")
+	}
+	for _, p := range []struct {
+		name  string
+		value string
+		dest  *int
+	}{{"offset", offset, &window.offset}, {"tail", tail, &window.tail}} {
+		if p.value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(p.value)
+		if err != nil || n < 0 {
+			return window, fmt.Errorf("%s must be a non-negative integer", p.name)
+		}
+		*p.dest = n
+		window.set = true
+	}
+	return window, nil
+}
+
+// bounds returns the [start, end) entries of a log of total entries the
+// window selects.
+func (lw logWindow) bounds(total int) (int, int) {
+	if lw.tail >= 0 {
+		return max(total-lw.tail, 0), total
+	}
+	return min(lw.offset, total), total
+}
+
+// jobFinished reports whether the job has reached a final status, after
+// which its logs don't grow.
+func jobFinished(job *models.Job) bool {
+	switch job.Status {
+	case "completed", "failed", "cancelled", "timeout":
+		return true
+	default:
+		return false
+	}
+}
+
+// serveJobLogs writes the entries of a JSON array log the window selects,
+// with the follow token and whether the log is complete in the headers.
+// Range headers are served against the selected entries' bytes.
+func (h *JobHandler) serveJobLogs(w http.ResponseWriter, r *http.Request, job *models.Job, content []byte, window logWindow) {
+	var entries []json.RawMessage
+	if err := json.Unmarshal(content, &entries); err != nil {
+		if window.set {
+			h.respondWithError(w, http.StatusInternalServerError, fmt.Errorf("failed to parse logs: %w", err))
+			return
+		}
+		// Not a JSON array; serve it as stored, without a follow token.
+		entries = nil
+	} else {
+		w.Header().Set(LogNextOffsetHeader, strconv.Itoa(len(entries)))
+	}
+
+	if window.set {
+		start, end := window.bounds(len(entries))
+		selected := make([]json.RawMessage, 0, end-start)
+		selected = append(selected, entries[start:end]...)
+		var err error
+		if content, err = json.Marshal(selected); err != nil {
+			h.respondWithError(w, http.StatusInternalServerError, fmt.Errorf("failed to marshal logs: %w", err))
+			return
+		}
+	}
+
+	w.Header().Set(LogCompleteHeader, strconv.FormatBool(jobFinished(job)))
+	w.Header().Set("Content-Type", "application/json")
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+}
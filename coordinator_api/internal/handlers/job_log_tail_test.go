@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetJobLogs_Tail(t *testing.T) {
+	job := &models.Job{JobID: "tail-job", UserID: "test-user-id", Status: "running"}
+	s := &MockStore{GetJobByIDFunc: func(ctx context.Context, jobID string) (*models.Job, error) {
+		return job, nil
+	}}
+	memStore := objects.NewMemoryObjectStore()
+	var stdout []LogEntry
+	for i := 0; i < 5; i++ {
+		stdout = append(stdout, LogEntry{Timestamp: fmt.Sprintf("2026-01-01T10:00:0%dZ", i), Stream: "stdout", Message: fmt.Sprintf("line %d", i)})
+	}
+	content, _ := json.Marshal(stdout)
+	require.NoError(t, memStore.Put(context.Background(), "logs/tail-job/stdout.json", bytes.NewReader(content), "application/json"))
+	handler := NewJobHandlerWithObjectStore(s, nil, memStore)
+
+	get := func(query string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/jobs/tail-job/logs?stream=stdout"+query, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		req = req.WithContext(context.WithValue(withUser(req).Context(), GetContextKey("job_id"), "tail-job"))
+		w := httptest.NewRecorder()
+		handler.GetJobLogs(w, req)
+		return w
+	}
+	messages := func(t *testing.T, w *httptest.ResponseRecorder) []string {
+		var entries []LogEntry
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &entries))
+		out := []string{}
+		for _, e := range entries {
+			out = append(out, e.Message)
+		}
+		return out
+	}
+
+	t.Run("full log carries the follow token", func(t *testing.T) {
+		w := get("", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Len(t, messages(t, w), 5)
+		assert.Equal(t, "5", w.Header().Get(LogNextOffsetHeader))
+		assert.Equal(t, "false", w.Header().Get(LogCompleteHeader))
+	})
+
+	t.Run("offset returns the entries after it", func(t *testing.T) {
+		w := get("&offset=3", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []string{"line 3", "line 4"}, messages(t, w))
+		assert.Equal(t, "5", w.Header().Get(LogNextOffsetHeader))
+
+		w = get("&offset=5", nil)
+		assert.Equal(t, []string{}, messages(t, w))
+		assert.Equal(t, "5", w.Header().Get(LogNextOffsetHeader))
+	})
+
+	t.Run("tail returns the last entries", func(t *testing.T) {
+		w := get("&tail=2", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []string{"line 3", "line 4"}, messages(t, w))
+		assert.Equal(t, []string{"line 0", "line 1", "line 2", "line 3", "line 4"}, messages(t, get("&tail=10", nil)))
+		assert.Equal(t, []string{}, messages(t, get("&tail=0", nil)))
+	})
+
+	t.Run("byte range", func(t *testing.T) {
+		w := get("", http.Header{"Range": []string{"bytes=0-9"}})
+		require.Equal(t, http.StatusPartialContent, w.Code)
+		assert.Equal(t, content[:10], w.Body.Bytes())
+		assert.Equal(t, fmt.Sprintf("bytes 0-9/%d", len(content)), w.Header().Get("Content-Range"))
+
+		w = get("", http.Header{"Range": []string{fmt.Sprintf("bytes=%d-", len(content)+10)}})
+		assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, w.Code)
+	})
+
+	t.Run("complete once the job finished", func(t *testing.T) {
+		job.Status = "completed"
+		defer func() { job.Status = "running" }()
+		assert.Equal(t, "true", get("&offset=5", nil).Header().Get(LogCompleteHeader))
+	})
+
+	t.Run("invalid window", func(t *testing.T) {
+		for _, query := range []string{"&offset=-1", "&tail=x", "&offset=1&tail=1"} {
+			assert.Equal(t, http.StatusBadRequest, get(query, nil).Code, query)
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// jobConfigSnapshotReader is the store support for jobs' config snapshots.
+// See postgres_store/job_config_snapshot_operations.go.
+type jobConfigSnapshotReader interface {
+	GetJobConfigSnapshot(ctx context.Context, jobID string) (*models.JobConfigSnapshot, error)
+}
+
+// GetJobResolvedConfig handles GET /api/v1/jobs/{job_id}/resolved-config
+//
+// Returns the configuration the job ran with, as the worker resolved it
+// when it started the job: its image and digest, command, environment
+// (secret values masked) and source, and the project defaults, job
+// template and trigger behind them. It doesn't change when the project
+// does. 404 if the job hasn't started. Access is as for GetJob.
+func (h *JobHandler) GetJobResolvedConfig(w http.ResponseWriter, r *http.Request) {
+	jobID := h.getID(r, "job_id")
+	if jobID == "" {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+
+	job, err := h.store.GetJobByID(r.Context(), jobID)
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return
+	}
+
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return
+	}
+	if !h.canUserViewJob(r.Context(), user, job) {
+		h.respondWithError(w, http.StatusForbidden, store.ErrForbidden)
+		return
+	}
+
+	sr, ok := h.store.(jobConfigSnapshotReader)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("job config snapshots not available"))
+		return
+	}
+	snapshot, err := sr.GetJobConfigSnapshot(r.Context(), job.JobID)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, snapshot)
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// snapshotMockStore serves jobs and the config snapshots of some.
+type snapshotMockStore struct {
+	MockStore
+	snapshots map[string]*models.JobConfigSnapshot
+}
+
+func (m *snapshotMockStore) GetJobConfigSnapshot(ctx context.Context, jobID string) (*models.JobConfigSnapshot, error) {
+	if snapshot, ok := m.snapshots[jobID]; ok {
+		return snapshot, nil
+	}
+	return nil, store.ErrNotFound
+}
+
+func TestJobHandler_GetJobResolvedConfig(t *testing.T) {
+	m := &snapshotMockStore{snapshots: map[string]*models.JobConfigSnapshot{
+		"ran-job": {JobID: "ran-job", Config: models.ResolvedJobConfig{
+			JobName:     "test",
+			Image:       "app:1",
+			ImageDigest: "sha256:abc",
+			Env:         map[string]string{"TOKEN": "${secret:ci:token}", "REACTORCIDE_API_TOKEN": models.ResolvedConfigMasked},
+			Template:    &models.JobTemplate{Name: "test", Command: "make test"},
+		}},
+	}}
+	m.GetJobByIDFunc = func(ctx context.Context, jobID string) (*models.Job, error) {
+		return &models.Job{JobID: jobID, UserID: "test-user-id"}, nil
+	}
+	h := NewJobHandler(m, nil)
+	get := func(jobID string, user *models.User) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/jobs/"+jobID+"/resolved-config", nil)
+		ctx := checkauth.SetUserContext(req.Context(), user)
+		req = req.WithContext(setIDContext(ctx, "job_id", jobID))
+		w := httptest.NewRecorder()
+		h.GetJobResolvedConfig(w, req)
+		return w
+	}
+	owner := &models.User{UserID: "test-user-id"}
+
+	w := get("ran-job", owner)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp models.JobConfigSnapshot
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, "ran-job", resp.JobID)
+	assert.Equal(t, "sha256:abc", resp.Config.ImageDigest)
+	assert.Equal(t, "${secret:ci:token}", resp.Config.Env["TOKEN"], "secret references stay references")
+	require.NotNil(t, resp.Config.Template)
+	assert.Equal(t, "make test", resp.Config.Template.Command)
+
+	assert.Equal(t, http.StatusNotFound, get("queued-job", owner).Code, "no snapshot before the job starts")
+	assert.Equal(t, http.StatusForbidden, get("ran-job", &models.User{UserID: "someone-else"}).Code)
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/jobcontrol"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// maxLogChunkBytes caps one uploaded log chunk.
+const maxLogChunkBytes = 8 << 20
+
+// LogChunkChecksumHeader carries a log chunk's SHA-256, hex encoded.
+const LogChunkChecksumHeader = "X-Checksum-Sha256"
+
+// logActivityStore is the narrow store capability for stamping a job's
+// last log output time. See postgres_store/stuck_job_operations.go.
+type logActivityStore interface {
+	TouchJobLogActivity(ctx context.Context, jobID string, at time.Time) error
+}
+
+// CommitLogChunksRequest is the body of
+// POST /api/v1/jobs/{job_id}/logs/chunks/{stream}/commit.
+type CommitLogChunksRequest struct {
+	FinalSequence int `json:"final_sequence"`
+}
+
+// LogChunkConflictResponse is the 409 body of the chunk endpoints: what
+// went wrong and where the upload stands, so the worker can resume.
+type LogChunkConflictResponse struct {
+	Error   string                    `json:"error"`
+	Message string                    `json:"message"`
+	State   *jobcontrol.LogChunkState `json:"state"`
+}
+
+// PutLogChunk handles PUT /api/v1/jobs/{job_id}/logs/chunks/{stream}/{sequence}.
+// The body is the chunk, a JSON array of log entries, and the
+// X-Checksum-Sha256 header its checksum. Responds with the stream's
+// upload state. Uploading an existing chunk again with the same content
+// succeeds without change, so retries are safe; a chunk out of sequence
+// or with different content gets a 409 with the state to resume from.
+//
+// Authz: same tier as SubmitTriggers (the job's owner or an admin), since
+// it's the worker running the job calling, plus service accounts with a
+// worker-scoped token.
+func (h *JobHandler) PutLogChunk(w http.ResponseWriter, r *http.Request) {
+	job, stream, ok := h.loadLogChunkJob(w, r)
+	if !ok {
+		return
+	}
+	sequence, err := strconv.Atoi(h.getID(r, "log_sequence"))
+	if err != nil || sequence < 0 {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxLogChunkBytes))
+	if err != nil {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+
+	state, err := jobcontrol.PutLogChunk(r.Context(), h.objectStore, job.JobID, stream, sequence, data, r.Header.Get(LogChunkChecksumHeader))
+	if err != nil {
+		h.respondWithLogChunkError(w, state, err)
+		return
+	}
+	h.touchLogActivity(r, job)
+	h.respondWithJSON(w, http.StatusOK, state)
+}
+
+// GetLogChunkStatus handles GET /api/v1/jobs/{job_id}/logs/chunks/{stream}:
+// where the stream's upload stands. A worker resuming a job calls it to
+// learn the next sequence to upload.
+func (h *JobHandler) GetLogChunkStatus(w http.ResponseWriter, r *http.Request) {
+	job, stream, ok := h.loadLogChunkJob(w, r)
+	if !ok {
+		return
+	}
+	state, err := jobcontrol.LogChunkStatus(r.Context(), h.objectStore, job.JobID, stream)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, state)
+}
+
+// CommitLogChunks handles POST /api/v1/jobs/{job_id}/logs/chunks/{stream}/commit:
+// the final marker of a stream's upload. final_sequence is the last chunk
+// uploaded (-1 for none); if chunks are missing, it gets a 409. Committing
+// again succeeds without change.
+func (h *JobHandler) CommitLogChunks(w http.ResponseWriter, r *http.Request) {
+	job, stream, ok := h.loadLogChunkJob(w, r)
+	if !ok {
+		return
+	}
+	var req CommitLogChunksRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.FinalSequence < -1 {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+
+	state, err := jobcontrol.CommitLogChunks(r.Context(), h.objectStore, job.JobID, stream, req.FinalSequence)
+	if err != nil {
+		h.respondWithLogChunkError(w, state, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, state)
+}
+
+// loadLogChunkJob loads the job and stream named in the path for the chunk
+// endpoints and checks the caller may upload its logs, writing the error
+// response itself if not.
+func (h *JobHandler) loadLogChunkJob(w http.ResponseWriter, r *http.Request) (*models.Job, string, bool) {
+	jobID := h.getID(r, "job_id")
+	stream := h.getID(r, "log_stream")
+	if jobID == "" || !jobcontrol.IsLogStream(stream) {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return nil, "", false
+	}
+	job, err := h.store.GetJobByID(r.Context(), jobID)
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return nil, "", false
+	}
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return nil, "", false
+	}
+	if !h.canUserAccessJob(user, job) && !isWorkerServiceAccount(r.Context(), user) {
+		h.respondWithError(w, http.StatusForbidden, store.ErrForbidden)
+		return nil, "", false
+	}
+	if h.objectStore == nil {
+		h.respondWithError(w, http.StatusServiceUnavailable, store.ErrServiceUnavailable)
+		return nil, "", false
+	}
+	return job, stream, true
+}
+
+// isWorkerServiceAccount reports whether the caller is a service account
+// using a token with the worker scope, which may upload any job's logs.
+func isWorkerServiceAccount(ctx context.Context, user *models.User) bool {
+	if !user.IsServiceAccount {
+		return false
+	}
+	token := checkauth.GetAPITokenFromContext(ctx)
+	if token == nil {
+		return false
+	}
+	for _, scope := range token.Scopes {
+		if scope == models.ServiceAccountWorkerScope {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *JobHandler) respondWithLogChunkError(w http.ResponseWriter, state *jobcontrol.LogChunkState, err error) {
+	switch {
+	case errors.Is(err, jobcontrol.ErrChunkChecksum), errors.Is(err, jobcontrol.ErrChunkInvalid):
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+	case errors.Is(err, jobcontrol.ErrChunkConflict), errors.Is(err, jobcontrol.ErrChunkOutOfOrder), errors.Is(err, jobcontrol.ErrLogCommitted):
+		h.respondWithJSON(w, http.StatusConflict, LogChunkConflictResponse{Error: "conflict", Message: err.Error(), State: state})
+	default:
+		h.respondWithError(w, http.StatusInternalServerError, err)
+	}
+}
+
+// touchLogActivity records the upload as log output for the stuck-job
+// monitor, as the worker's own log shipping does.
+func (h *JobHandler) touchLogActivity(r *http.Request, job *models.Job) {
+	if ts, ok := h.store.(logActivityStore); ok {
+		ts.TouchJobLogActivity(r.Context(), job.JobID, time.Now().UTC())
+	}
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"os"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/worker"
+)
+
+// testSourceHosts are the source URL hosts the handler tests resolve,
+// instead of asking DNS.
+var testSourceHosts = map[string]string{
+	"github.com":  "140.82.112.3",
+	"example.com": "93.184.215.14",
+	"ghcr.io":     "140.82.112.34",
+}
+
+func TestMain(m *testing.M) {
+	worker.LookupSourceHost = func(ctx context.Context, network, host string) ([]netip.Addr, error) {
+		addr, ok := testSourceHosts[host]
+		if !ok {
+			return nil, errors.New("no such host")
+		}
+		return []netip.Addr{netip.MustParseAddr(addr)}, nil
+	}
+	os.Exit(m.Run())
+}
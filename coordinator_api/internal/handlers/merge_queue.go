@@ -0,0 +1,535 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/hooks"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/repourl"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/worker"
+	"github.com/sirupsen/logrus"
+)
+
+// mergeQueueStatusContext is the commit status the native merge queue
+// reports on a queued PR's head commit.
+const mergeQueueStatusContext = "reactorcide/merge-queue"
+
+// mergeQueueStartGrace is how long a testing entry may go without a job
+// before it's assumed its coordinator died between claiming it and creating
+// the job, and it's queued again.
+const mergeQueueStartGrace = time.Minute
+
+// mergeQueueMaxLookupFailures is how many sweeps in a row may fail to load
+// an entry's pull request before the entry is failed, so one PR the
+// provider can't return doesn't stall the rest of the queue.
+const mergeQueueMaxLookupFailures = 5
+
+// mergeQueueHistoryLimit caps GET .../merge-queue?history=true.
+const mergeQueueHistoryLimit = 50
+
+// mergeQueueStore is the narrow store capability behind the native merge
+// queue. See postgres_store/merge_queue_operations.go.
+type mergeQueueStore interface {
+	EnqueueMergeQueueEntry(ctx context.Context, entry *models.MergeQueueEntry) error
+	ListMergeQueueEntries(ctx context.Context, projectID string, activeOnly bool, limit int) ([]models.MergeQueueEntry, error)
+	ListMergeQueueProjects(ctx context.Context) ([]string, error)
+	UpdateMergeQueueEntry(ctx context.Context, entry *models.MergeQueueEntry, fromStatus string) (bool, error)
+}
+
+// processMergeGroupEvent builds a GitHub merge queue group: an eval job
+// (awaiting every job it triggers) on the group's speculative merge commit,
+// whose reactorcide/eval status GitHub waits on before landing the group.
+func (h *WebhookHandler) processMergeGroupEvent(event *vcs.WebhookEvent, client vcs.Client, project *models.Project, record *models.WebhookEvent) error {
+	mg := event.MergeGroup
+
+	record.ProjectID = &project.ProjectID
+
+	if reason := project.EventFilterReason(string(event.GenericEvent), mg.BaseRef); reason != "" {
+		h.logger.WithFields(logrus.Fields{
+			"project":     project.Name,
+			"base_branch": mg.BaseRef,
+			"reason":      reason,
+		}).Debug("Event filtered out by project configuration")
+		record.Filter(reason)
+		return nil
+	}
+
+	job, err := h.createMergeGroupJob(context.Background(), project, event)
+	if err != nil {
+		return err
+	}
+	record.Status = models.WebhookEventProcessed
+	record.JobID = &job.JobID
+
+	statusClient := h.getStatusClient(context.Background(), project, event.Provider, client)
+	if err := statusClient.UpdateCommitStatus(context.Background(), event.Repository.FullName, vcs.StatusUpdate{
+		SHA:         mg.HeadSHA,
+		State:       vcs.StatusPending,
+		TargetURL:   h.getJobURL(job.JobID),
+		Description: "CI build queued",
+		Context:     evalStatusContext(project),
+	}); err != nil {
+		h.logger.WithError(err).Warn("Failed to update commit status")
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"job_id":   job.JobID,
+		"project":  project.Name,
+		"head_sha": mg.HeadSHA,
+		"base_ref": mg.BaseRef,
+	}).Info("Created eval job for merge group")
+	return nil
+}
+
+// createMergeGroupJob creates and submits the eval job for a merge group
+// event. Its commit status goes to the speculative merge commit.
+func (h *WebhookHandler) createMergeGroupJob(ctx context.Context, project *models.Project, event *vcs.WebhookEvent) (*models.Job, error) {
+	mg := event.MergeGroup
+	job := BuildEvalJob(project, event)
+	metadata := vcs.JobMetadata{
+		VCSProvider: string(event.Provider),
+		Repo:        event.Repository.FullName,
+		Branch:      mg.BaseRef,
+		CommitSHA:   mg.HeadSHA,
+		IsEval:      true,
+		StatusKey:   models.EvalStatusKey,
+	}
+	metadata.UseStatusContext(project, evalStatusContext(project))
+	if err := metadata.ApplyToJob(job); err != nil {
+		return nil, fmt.Errorf("applying VCS metadata: %w", err)
+	}
+	worker.RouteProjectQueue(ctx, h.store, project, job)
+	if err := hooks.CheckJobCreate(ctx, job, hooks.SourceMergeQueue); err != nil {
+		return nil, err
+	}
+	if err := h.store.CreateJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("creating job: %w", err)
+	}
+	h.submitJobToCorndogs(job)
+	return job, nil
+}
+
+// MergeQueue advances projects' native merge queues. Each sweep, for every
+// project with queued PRs, it either checks the entry under test or starts
+// testing the next one, so merges into a project are serialized:
+//
+//  1. The PR's speculative merge commit (GitHub's test merge of the head
+//     into the current base) gets an eval job that awaits every job it
+//     triggers.
+//  2. If that job succeeds and the PR's head and test merge are unchanged,
+//     the PR is merged; if either changed, the entry is retested.
+//  3. A failed job fails the entry, leaving the PR unmerged.
+//
+// Progress is reported as the reactorcide/merge-queue status on the PR head.
+type MergeQueue struct {
+	webhook *WebhookHandler
+	logger  *logrus.Entry
+}
+
+// NewMergeQueue creates a merge queue that resolves VCS clients and submits
+// jobs through webhook.
+func NewMergeQueue(webhook *WebhookHandler) *MergeQueue {
+	return &MergeQueue{
+		webhook: webhook,
+		logger:  logging.Log.WithField("component", "merge_queue"),
+	}
+}
+
+// Start sweeps the merge queues every interval until ctx is done.
+func (q *MergeQueue) Start(ctx context.Context, interval time.Duration) {
+	if _, ok := q.webhook.store.(mergeQueueStore); !ok {
+		q.logger.Warn("Store does not support the merge queue; merge queue disabled")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := q.Sweep(ctx); err != nil {
+					q.logger.WithError(err).Warn("Merge queue sweep failed")
+				}
+			}
+		}
+	}()
+}
+
+// Sweep advances every project's merge queue by at most one step.
+func (q *MergeQueue) Sweep(ctx context.Context) error {
+	ms, ok := q.webhook.store.(mergeQueueStore)
+	if !ok {
+		return nil
+	}
+	projectIDs, err := ms.ListMergeQueueProjects(ctx)
+	if err != nil {
+		return err
+	}
+	for _, projectID := range projectIDs {
+		q.advance(ctx, ms, projectID)
+	}
+	return nil
+}
+
+// advance checks the project's testing entry, or starts the next one.
+func (q *MergeQueue) advance(ctx context.Context, ms mergeQueueStore, projectID string) {
+	entries, err := ms.ListMergeQueueEntries(ctx, projectID, true, 0)
+	if err != nil || len(entries) == 0 {
+		return
+	}
+	project, err := q.webhook.store.GetProjectByID(ctx, projectID)
+	if err != nil {
+		q.logger.WithError(err).WithField("project_id", projectID).Warn("Failed to load merge queue project")
+		return
+	}
+	for i := range entries {
+		if entries[i].Status == models.MergeQueueTesting {
+			q.checkTesting(ctx, ms, project, &entries[i])
+			return
+		}
+	}
+	q.startTesting(ctx, ms, project, &entries[0])
+}
+
+// startTesting claims the entry and creates the eval job for its
+// speculative merge commit.
+func (q *MergeQueue) startTesting(ctx context.Context, ms mergeQueueStore, project *models.Project, entry *models.MergeQueueEntry) {
+	client, merger, repo := q.clientFor(ctx, project, entry)
+	if client == nil || merger == nil {
+		q.finish(ctx, ms, entry, models.MergeQueueFailed, fmt.Sprintf("no %s client that can merge pull requests", entry.Provider))
+		return
+	}
+	pr, err := client.GetPRInfo(ctx, repo, entry.PRNumber)
+	if err != nil {
+		q.lookupFailed(ctx, ms, entry, err)
+		return
+	}
+	entry.LookupFailures = 0
+	switch {
+	case pr.Merged:
+		q.finish(ctx, ms, entry, models.MergeQueueMerged, "merged outside the merge queue")
+		return
+	case pr.State != "open":
+		q.finish(ctx, ms, entry, models.MergeQueueCancelled, "pull request is "+pr.State)
+		return
+	case pr.Mergeable == nil:
+		return // the provider is still computing the test merge
+	case pr.MergeCommitSHA == "":
+		q.finish(ctx, ms, entry, models.MergeQueueFailed, "pull request conflicts with "+pr.BaseRef)
+		q.reportStatus(ctx, client, repo, pr.HeadSHA, vcs.StatusFailure, "Merge conflict with "+pr.BaseRef, nil)
+		return
+	}
+
+	from := entry.Status
+	entry.Status = models.MergeQueueTesting
+	entry.HeadSHA = pr.HeadSHA
+	entry.TestedSHA = pr.MergeCommitSHA
+	entry.JobID = nil
+	entry.Error = ""
+	claimed, err := ms.UpdateMergeQueueEntry(ctx, entry, from)
+	if err != nil || !claimed {
+		return // another replica is testing an entry of this project
+	}
+
+	event := &vcs.WebhookEvent{
+		Provider:     vcs.Provider(entry.Provider),
+		EventType:    "merge_queue",
+		GenericEvent: vcs.EventMergeGroup,
+		Repository: vcs.RepositoryInfo{
+			FullName: repo,
+			CloneURL: repourl.CloneURL(project.RepoURL),
+		},
+		MergeGroup: &vcs.MergeGroupInfo{
+			HeadSHA:  pr.MergeCommitSHA,
+			HeadRef:  fmt.Sprintf("refs/pull/%d/merge", pr.Number),
+			BaseSHA:  pr.BaseSHA,
+			BaseRef:  pr.BaseRef,
+			PRNumber: pr.Number,
+		},
+	}
+	job, err := q.webhook.createMergeGroupJob(ctx, project, event)
+	if err != nil {
+		q.logger.WithError(err).WithField("pr_number", entry.PRNumber).Error("Failed to create merge queue job")
+		entry.Status = models.MergeQueueQueued
+		_, _ = ms.UpdateMergeQueueEntry(ctx, entry, models.MergeQueueTesting)
+		return
+	}
+	entry.JobID = &job.JobID
+	if _, err := ms.UpdateMergeQueueEntry(ctx, entry, models.MergeQueueTesting); err != nil {
+		q.logger.WithError(err).WithField("pr_number", entry.PRNumber).Warn("Failed to record merge queue job")
+	}
+	q.reportStatus(ctx, client, repo, pr.HeadSHA, vcs.StatusPending, "Testing merge into "+pr.BaseRef, entry.JobID)
+}
+
+// checkTesting merges the testing entry once its job succeeds, or fails it
+// if the job didn't.
+func (q *MergeQueue) checkTesting(ctx context.Context, ms mergeQueueStore, project *models.Project, entry *models.MergeQueueEntry) {
+	if entry.JobID == nil {
+		if time.Since(entry.UpdatedAt) > mergeQueueStartGrace {
+			entry.Status = models.MergeQueueQueued
+			_, _ = ms.UpdateMergeQueueEntry(ctx, entry, models.MergeQueueTesting)
+		}
+		return
+	}
+	job, err := q.webhook.store.GetJobByID(ctx, *entry.JobID)
+	if err != nil {
+		q.logger.WithError(err).WithField("job_id", *entry.JobID).Warn("Failed to load merge queue job")
+		return
+	}
+
+	client, merger, repo := q.clientFor(ctx, project, entry)
+	switch job.Status {
+	case "completed":
+	case "failed", "cancelled", "timeout":
+		q.finish(ctx, ms, entry, models.MergeQueueFailed, "eval job "+job.Status)
+		if client != nil {
+			q.reportStatus(ctx, client, repo, entry.HeadSHA, vcs.StatusFailure, "Merge checks "+job.Status, entry.JobID)
+		}
+		return
+	default:
+		return
+	}
+
+	if client == nil || merger == nil {
+		q.finish(ctx, ms, entry, models.MergeQueueFailed, fmt.Sprintf("no %s client that can merge pull requests", entry.Provider))
+		return
+	}
+	pr, err := client.GetPRInfo(ctx, repo, entry.PRNumber)
+	if err != nil {
+		q.lookupFailed(ctx, ms, entry, err)
+		return
+	}
+	entry.LookupFailures = 0
+	switch {
+	case pr.Merged:
+		q.finish(ctx, ms, entry, models.MergeQueueMerged, "merged outside the merge queue")
+		return
+	case pr.State != "open":
+		q.finish(ctx, ms, entry, models.MergeQueueCancelled, "pull request is "+pr.State)
+		return
+	case pr.Mergeable == nil:
+		return
+	case pr.HeadSHA != entry.HeadSHA || pr.MergeCommitSHA != entry.TestedSHA:
+		// The PR or its base moved since the test started, so what would
+		// land isn't what was tested.
+		entry.Status = models.MergeQueueQueued
+		entry.Error = "pull request or base changed during testing; retesting"
+		_, _ = ms.UpdateMergeQueueEntry(ctx, entry, models.MergeQueueTesting)
+		return
+	}
+
+	if err := merger.MergePullRequest(ctx, repo, entry.PRNumber, entry.HeadSHA); err != nil {
+		q.finish(ctx, ms, entry, models.MergeQueueFailed, "merge failed: "+err.Error())
+		q.reportStatus(ctx, client, repo, entry.HeadSHA, vcs.StatusFailure, "Merge failed", entry.JobID)
+		return
+	}
+	q.finish(ctx, ms, entry, models.MergeQueueMerged, "")
+	q.reportStatus(ctx, client, repo, entry.HeadSHA, vcs.StatusSuccess, "Merged into "+pr.BaseRef, entry.JobID)
+	q.logger.WithFields(logrus.Fields{
+		"project":   project.Name,
+		"pr_number": entry.PRNumber,
+		"job_id":    *entry.JobID,
+	}).Info("Merged pull request from merge queue")
+}
+
+// lookupFailed records a failure to load the entry's pull request, and
+// fails the entry once mergeQueueMaxLookupFailures have happened in a row.
+func (q *MergeQueue) lookupFailed(ctx context.Context, ms mergeQueueStore, entry *models.MergeQueueEntry, err error) {
+	entry.LookupFailures++
+	logger := q.logger.WithError(err).WithFields(logrus.Fields{"pr_number": entry.PRNumber, "failures": entry.LookupFailures})
+	if entry.LookupFailures >= mergeQueueMaxLookupFailures {
+		logger.Error("Failed to load merge queue pull request; failing entry")
+		q.finish(ctx, ms, entry, models.MergeQueueFailed, fmt.Sprintf("failed to load pull request %d times: %v", entry.LookupFailures, err))
+		return
+	}
+	logger.Warn("Failed to load merge queue pull request")
+	entry.Error = "failed to load pull request: " + err.Error()
+	if _, err := ms.UpdateMergeQueueEntry(ctx, entry, entry.Status); err != nil {
+		q.logger.WithError(err).WithField("entry_id", entry.EntryID).Warn("Failed to update merge queue entry")
+	}
+}
+
+// finish moves the entry to a final status.
+func (q *MergeQueue) finish(ctx context.Context, ms mergeQueueStore, entry *models.MergeQueueEntry, status, reason string) {
+	from := entry.Status
+	now := time.Now().UTC()
+	entry.Status = status
+	entry.Error = reason
+	entry.FinishedAt = &now
+	if _, err := ms.UpdateMergeQueueEntry(ctx, entry, from); err != nil {
+		q.logger.WithError(err).WithField("entry_id", entry.EntryID).Warn("Failed to update merge queue entry")
+	}
+}
+
+// clientFor resolves the project's VCS client for the entry's provider,
+// the merge capability of that client, and the repository's full name.
+func (q *MergeQueue) clientFor(ctx context.Context, project *models.Project, entry *models.MergeQueueEntry) (vcs.Client, vcs.PullRequestMerger, string) {
+	repo := repourl.FullName(project.RepoURL)
+	provider := vcs.Provider(entry.Provider)
+	client := q.webhook.getStatusClient(ctx, project, provider, q.webhook.vcsClients[provider])
+	if client == nil {
+		return nil, nil, repo
+	}
+	merger, _ := client.(vcs.PullRequestMerger)
+	return client, merger, repo
+}
+
+// reportStatus posts the merge queue status on the PR head. Best-effort.
+func (q *MergeQueue) reportStatus(ctx context.Context, client vcs.Client, repo, sha string, state vcs.StatusState, description string, jobID *string) {
+	update := vcs.StatusUpdate{
+		SHA:         sha,
+		State:       state,
+		Description: description,
+		Context:     mergeQueueStatusContext,
+	}
+	if jobID != nil {
+		update.TargetURL = q.webhook.getJobURL(*jobID)
+	}
+	if err := client.UpdateCommitStatus(ctx, repo, update); err != nil {
+		q.logger.WithError(err).WithField("sha", sha).Warn("Failed to update merge queue status")
+	}
+}
+
+// EnqueueMergeRequest is the JSON body of POST
+// /api/v1/projects/{project_id}/merge-queue.
+type EnqueueMergeRequest struct {
+	PRNumber int    `json:"pr_number"`
+	Provider string `json:"provider,omitempty"` // default "github"
+}
+
+// MergeQueueResponse lists a project's merge queue entries.
+type MergeQueueResponse struct {
+	Entries []models.MergeQueueEntry `json:"entries"`
+}
+
+// GetMergeQueue handles GET /api/v1/projects/{project_id}/merge-queue:
+// the queued and testing PRs in queue order, or with history=true the
+// most recent entries of any status.
+func (h *ProjectHandler) GetMergeQueue(w http.ResponseWriter, r *http.Request) {
+	ms, project, ok := h.mergeQueueProject(w, r)
+	if !ok {
+		return
+	}
+	history := r.URL.Query().Get("history") == "true"
+	entries, err := ms.ListMergeQueueEntries(r.Context(), project.ProjectID, !history, mergeQueueHistoryLimit)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if entries == nil {
+		entries = []models.MergeQueueEntry{}
+	}
+	h.respondWithJSON(w, http.StatusOK, MergeQueueResponse{Entries: entries})
+}
+
+// EnqueueMerge handles POST /api/v1/projects/{project_id}/merge-queue.
+func (h *ProjectHandler) EnqueueMerge(w http.ResponseWriter, r *http.Request) {
+	ms, project, ok := h.mergeQueueProject(w, r)
+	if !ok {
+		return
+	}
+	var req EnqueueMergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.PRNumber <= 0 {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+	if req.Provider == "" {
+		req.Provider = string(vcs.GitHub)
+	}
+	if !vcs.Providers.Capabilities(vcs.Provider(req.Provider)).MergeQueue {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+
+	user := checkauth.GetUserFromContext(r.Context())
+	entry := &models.MergeQueueEntry{
+		ProjectID:  project.ProjectID,
+		Provider:   req.Provider,
+		PRNumber:   req.PRNumber,
+		EnqueuedBy: &user.UserID,
+	}
+	if err := ms.EnqueueMergeQueueEntry(r.Context(), entry); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusCreated, entry)
+}
+
+// DequeueMerge handles DELETE
+// /api/v1/projects/{project_id}/merge-queue/{pr_number}: it cancels the PR's
+// active entry. A job already testing it keeps running but can't merge it.
+func (h *ProjectHandler) DequeueMerge(w http.ResponseWriter, r *http.Request) {
+	ms, project, ok := h.mergeQueueProject(w, r)
+	if !ok {
+		return
+	}
+	prNumber, err := strconv.Atoi(h.getID(r, "pr_number"))
+	if err != nil {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+	entries, err := ms.ListMergeQueueEntries(r.Context(), project.ProjectID, true, 0)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	for i := range entries {
+		entry := &entries[i]
+		if entry.PRNumber != prNumber {
+			continue
+		}
+		from := entry.Status
+		now := time.Now().UTC()
+		entry.Status = models.MergeQueueCancelled
+		entry.Error = "removed from the queue"
+		entry.FinishedAt = &now
+		updated, err := ms.UpdateMergeQueueEntry(r.Context(), entry, from)
+		if err != nil {
+			h.respondWithError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if !updated {
+			break // finished while we were cancelling it
+		}
+		h.respondWithJSON(w, http.StatusOK, entry)
+		return
+	}
+	h.respondWithError(w, http.StatusNotFound, store.ErrNotFound)
+}
+
+// mergeQueueProject authenticates the request and loads its project.
+func (h *ProjectHandler) mergeQueueProject(w http.ResponseWriter, r *http.Request) (mergeQueueStore, *models.Project, bool) {
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return nil, nil, false
+	}
+	ms, ok := h.store.(mergeQueueStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("merge queue not available"))
+		return nil, nil, false
+	}
+	projectID := h.getID(r, "project_id")
+	if projectID == "" {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return nil, nil, false
+	}
+	project, err := h.store.GetProjectByID(r.Context(), projectID)
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return nil, nil, false
+	}
+	return ms, project, true
+}
@@ -0,0 +1,376 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mergeQueueMockStore keeps merge queue entries and jobs in memory on top
+// of WebhookMockStore.
+type mergeQueueMockStore struct {
+	WebhookMockStore
+	project *models.Project
+	entries []*models.MergeQueueEntry
+	jobs    map[string]*models.Job
+}
+
+func (m *mergeQueueMockStore) GetProjectByID(ctx context.Context, projectID string) (*models.Project, error) {
+	if m.project != nil && m.project.ProjectID == projectID {
+		return m.project, nil
+	}
+	return nil, store.ErrNotFound
+}
+
+func (m *mergeQueueMockStore) GetJobByID(ctx context.Context, jobID string) (*models.Job, error) {
+	if job, ok := m.jobs[jobID]; ok {
+		return job, nil
+	}
+	return nil, store.ErrNotFound
+}
+
+func (m *mergeQueueMockStore) EnqueueMergeQueueEntry(ctx context.Context, entry *models.MergeQueueEntry) error {
+	for _, e := range m.entries {
+		if e.ProjectID == entry.ProjectID && e.PRNumber == entry.PRNumber && e.IsActive() {
+			return store.ErrAlreadyExists
+		}
+	}
+	entry.EntryID = uuid.New().String()
+	entry.Status = models.MergeQueueQueued
+	entry.CreatedAt = time.Now().UTC()
+	entry.UpdatedAt = entry.CreatedAt
+	stored := *entry
+	m.entries = append(m.entries, &stored)
+	return nil
+}
+
+func (m *mergeQueueMockStore) ListMergeQueueEntries(ctx context.Context, projectID string, activeOnly bool, limit int) ([]models.MergeQueueEntry, error) {
+	var entries []models.MergeQueueEntry
+	for _, e := range m.entries {
+		if e.ProjectID == projectID && (!activeOnly || e.IsActive()) {
+			entries = append(entries, *e)
+		}
+	}
+	return entries, nil
+}
+
+func (m *mergeQueueMockStore) ListMergeQueueProjects(ctx context.Context) ([]string, error) {
+	seen := map[string]bool{}
+	var projectIDs []string
+	for _, e := range m.entries {
+		if e.IsActive() && !seen[e.ProjectID] {
+			seen[e.ProjectID] = true
+			projectIDs = append(projectIDs, e.ProjectID)
+		}
+	}
+	return projectIDs, nil
+}
+
+func (m *mergeQueueMockStore) UpdateMergeQueueEntry(ctx context.Context, entry *models.MergeQueueEntry, fromStatus string) (bool, error) {
+	for _, e := range m.entries {
+		if e.EntryID != entry.EntryID {
+			continue
+		}
+		if e.Status != fromStatus {
+			return false, nil
+		}
+		*e = *entry
+		return true, nil
+	}
+	return false, nil
+}
+
+// mergingVCSClient is a MockVCSClient that can merge pull requests.
+type mergingVCSClient struct {
+	MockVCSClient
+	merged   []int
+	mergeErr error
+	statuses []vcs.StatusUpdate
+}
+
+func (c *mergingVCSClient) MergePullRequest(ctx context.Context, repo string, prNumber int, headSHA string) error {
+	if c.mergeErr != nil {
+		return c.mergeErr
+	}
+	c.merged = append(c.merged, prNumber)
+	return nil
+}
+
+func (c *mergingVCSClient) UpdateCommitStatus(ctx context.Context, repo string, update vcs.StatusUpdate) error {
+	c.statuses = append(c.statuses, update)
+	return nil
+}
+
+func TestWebhookHandler_MergeGroup(t *testing.T) {
+	project := webhookTestProject()
+	project.AllowedEventTypes = append(project.AllowedEventTypes, "merge_group")
+	s := &WebhookMockStore{
+		GetProjectByRepoURLFunc: func(ctx context.Context, repoURL string) (*models.Project, error) {
+			return project, nil
+		},
+	}
+	client := &mergingVCSClient{}
+	client.ParseWebhookFunc = func(r *http.Request) (*vcs.WebhookEvent, error) {
+		return &vcs.WebhookEvent{
+			Provider:     vcs.GitHub,
+			EventType:    "merge_group",
+			GenericEvent: vcs.EventMergeGroup,
+			Repository: vcs.RepositoryInfo{
+				FullName: "test-org/test-repo",
+				CloneURL: "https://github.com/test-org/test-repo.git",
+			},
+			MergeGroup: &vcs.MergeGroupInfo{
+				HeadSHA: "group-sha-1234567",
+				HeadRef: "refs/heads/gh-readonly-queue/main/pr-7-base",
+				BaseSHA: "base-sha",
+				BaseRef: "main",
+			},
+		}, nil
+	}
+	handler := NewWebhookHandler(s, corndogs.NewMockClient())
+	handler.SetTokenResolver(testTokenResolver())
+	handler.AddVCSClient(vcs.GitHub, client)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/github", bytes.NewReader([]byte(`{"repository": {"clone_url": "https://github.com/test-org/test-repo.git"}}`)))
+	req.Header.Set("X-GitHub-Event", "merge_group")
+	w := httptest.NewRecorder()
+	handler.HandleGitHubWebhook(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.Len(t, s.CreateJobCalls, 1)
+	job := s.CreateJobCalls[0]
+	assert.True(t, job.AwaitChildren)
+	require.NotNil(t, job.SourceRef)
+	assert.Equal(t, "group-sha-1234567", *job.SourceRef)
+	assert.Equal(t, "main", job.JobEnvVars["REACTORCIDE_BRANCH"])
+	assert.Equal(t, "group-sha-1234567", job.JobEnvVars["REACTORCIDE_SHA"])
+
+	require.Len(t, client.statuses, 1)
+	assert.Equal(t, "group-sha-1234567", client.statuses[0].SHA)
+	assert.Equal(t, "reactorcide/eval", client.statuses[0].Context)
+	assert.Equal(t, vcs.StatusPending, client.statuses[0].State)
+}
+
+func TestMergeQueue_Sweep(t *testing.T) {
+	mergeable := true
+	setup := func(pr *vcs.PullRequestInfo) (*mergeQueueMockStore, *mergingVCSClient, *MergeQueue) {
+		project := webhookTestProject()
+		s := &mergeQueueMockStore{project: project, jobs: map[string]*models.Job{}}
+		s.CreateJobFunc = func(ctx context.Context, job *models.Job) error {
+			job.JobID = uuid.New().String()
+			job.Status = "submitted"
+			s.jobs[job.JobID] = job
+			return nil
+		}
+		require.NoError(t, s.EnqueueMergeQueueEntry(context.Background(), &models.MergeQueueEntry{
+			ProjectID: project.ProjectID,
+			Provider:  string(vcs.GitHub),
+			PRNumber:  pr.Number,
+		}))
+
+		client := &mergingVCSClient{}
+		client.GetPRInfoFunc = func(ctx context.Context, repo string, prNumber int) (*vcs.PullRequestInfo, error) {
+			assert.Equal(t, "test-org/test-repo", repo)
+			copied := *pr
+			return &copied, nil
+		}
+		handler := NewWebhookHandler(s, corndogs.NewMockClient())
+		handler.AddVCSClient(vcs.GitHub, client)
+		return s, client, NewMergeQueue(handler)
+	}
+	openPR := func() *vcs.PullRequestInfo {
+		return &vcs.PullRequestInfo{
+			Number:         7,
+			State:          "open",
+			HeadSHA:        "head-sha",
+			BaseSHA:        "base-sha",
+			BaseRef:        "main",
+			MergeCommitSHA: "merge-sha",
+			Mergeable:      &mergeable,
+		}
+	}
+	ctx := context.Background()
+
+	t.Run("tests the merge commit then merges", func(t *testing.T) {
+		pr := openPR()
+		s, client, q := setup(pr)
+
+		require.NoError(t, q.Sweep(ctx))
+		entry := s.entries[0]
+		assert.Equal(t, models.MergeQueueTesting, entry.Status)
+		assert.Equal(t, "merge-sha", entry.TestedSHA)
+		require.NotNil(t, entry.JobID)
+		require.Len(t, s.CreateJobCalls, 1)
+		job := s.CreateJobCalls[0]
+		require.NotNil(t, job.SourceRef)
+		assert.Equal(t, "merge-sha", *job.SourceRef)
+		assert.True(t, job.AwaitChildren)
+		assert.Equal(t, "7", job.JobEnvVars["REACTORCIDE_PR_NUMBER"])
+		require.Len(t, client.statuses, 1)
+		assert.Equal(t, mergeQueueStatusContext, client.statuses[0].Context)
+		assert.Equal(t, "head-sha", client.statuses[0].SHA)
+
+		// Still running: nothing happens.
+		require.NoError(t, q.Sweep(ctx))
+		assert.Empty(t, client.merged)
+
+		s.jobs[*entry.JobID].Status = "completed"
+		require.NoError(t, q.Sweep(ctx))
+		assert.Equal(t, []int{7}, client.merged)
+		assert.Equal(t, models.MergeQueueMerged, entry.Status)
+		assert.NotNil(t, entry.FinishedAt)
+		assert.Equal(t, vcs.StatusSuccess, client.statuses[len(client.statuses)-1].State)
+	})
+
+	t.Run("failed job fails the entry", func(t *testing.T) {
+		s, client, q := setup(openPR())
+		require.NoError(t, q.Sweep(ctx))
+		s.jobs[*s.entries[0].JobID].Status = "failed"
+
+		require.NoError(t, q.Sweep(ctx))
+		assert.Empty(t, client.merged)
+		assert.Equal(t, models.MergeQueueFailed, s.entries[0].Status)
+		assert.Equal(t, vcs.StatusFailure, client.statuses[len(client.statuses)-1].State)
+	})
+
+	t.Run("head change requeues for retesting", func(t *testing.T) {
+		pr := openPR()
+		s, client, q := setup(pr)
+		require.NoError(t, q.Sweep(ctx))
+		s.jobs[*s.entries[0].JobID].Status = "completed"
+
+		pr.HeadSHA = "new-head"
+		pr.MergeCommitSHA = "new-merge"
+		require.NoError(t, q.Sweep(ctx))
+		assert.Empty(t, client.merged)
+		assert.Equal(t, models.MergeQueueQueued, s.entries[0].Status)
+
+		require.NoError(t, q.Sweep(ctx))
+		assert.Equal(t, models.MergeQueueTesting, s.entries[0].Status)
+		assert.Equal(t, "new-merge", s.entries[0].TestedSHA)
+		assert.Len(t, s.CreateJobCalls, 2)
+	})
+
+	t.Run("conflicting PR fails without a job", func(t *testing.T) {
+		conflicted := false
+		pr := openPR()
+		pr.Mergeable = &conflicted
+		pr.MergeCommitSHA = ""
+		s, _, q := setup(pr)
+
+		require.NoError(t, q.Sweep(ctx))
+		assert.Empty(t, s.CreateJobCalls)
+		assert.Equal(t, models.MergeQueueFailed, s.entries[0].Status)
+	})
+
+	t.Run("waits while mergeability is unknown", func(t *testing.T) {
+		pr := openPR()
+		pr.Mergeable = nil
+		s, _, q := setup(pr)
+
+		require.NoError(t, q.Sweep(ctx))
+		assert.Empty(t, s.CreateJobCalls)
+		assert.Equal(t, models.MergeQueueQueued, s.entries[0].Status)
+	})
+
+	t.Run("fails the entry after repeated lookup failures", func(t *testing.T) {
+		s, client, q := setup(openPR())
+		client.GetPRInfoFunc = func(ctx context.Context, repo string, prNumber int) (*vcs.PullRequestInfo, error) {
+			return nil, errors.New("not found")
+		}
+
+		for i := 1; i < mergeQueueMaxLookupFailures; i++ {
+			require.NoError(t, q.Sweep(ctx))
+			assert.Equal(t, models.MergeQueueQueued, s.entries[0].Status)
+			assert.Equal(t, i, s.entries[0].LookupFailures)
+		}
+		require.NoError(t, q.Sweep(ctx))
+		assert.Empty(t, s.CreateJobCalls)
+		assert.Equal(t, models.MergeQueueFailed, s.entries[0].Status)
+		assert.Contains(t, s.entries[0].Error, "not found")
+		assert.NotNil(t, s.entries[0].FinishedAt)
+	})
+
+	t.Run("serializes a project's entries", func(t *testing.T) {
+		s, _, q := setup(openPR())
+		require.NoError(t, s.EnqueueMergeQueueEntry(ctx, &models.MergeQueueEntry{
+			ProjectID: s.project.ProjectID,
+			Provider:  string(vcs.GitHub),
+			PRNumber:  8,
+		}))
+
+		require.NoError(t, q.Sweep(ctx))
+		require.NoError(t, q.Sweep(ctx))
+		assert.Len(t, s.CreateJobCalls, 1)
+		assert.Equal(t, models.MergeQueueTesting, s.entries[0].Status)
+		assert.Equal(t, models.MergeQueueQueued, s.entries[1].Status)
+	})
+}
+
+func TestProjectHandler_MergeQueue(t *testing.T) {
+	project := webhookTestProject()
+	s := &mergeQueueMockStore{project: project}
+	h := NewProjectHandler(s)
+	path := "/api/v1/projects/" + project.ProjectID + "/merge-queue"
+
+	enqueue := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		req = withProjectID(withUser(req), project.ProjectID)
+		w := httptest.NewRecorder()
+		h.EnqueueMerge(w, req)
+		return w
+	}
+
+	w := enqueue(`{"pr_number": 7}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var entry models.MergeQueueEntry
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&entry))
+	assert.Equal(t, models.MergeQueueQueued, entry.Status)
+	assert.Equal(t, "github", entry.Provider)
+	require.NotNil(t, entry.EnqueuedBy)
+	assert.Equal(t, "test-user-id", *entry.EnqueuedBy)
+
+	assert.Equal(t, http.StatusConflict, enqueue(`{"pr_number": 7}`).Code)
+	assert.Equal(t, http.StatusBadRequest, enqueue(`{"pr_number": 0}`).Code)
+	assert.Equal(t, http.StatusBadRequest, enqueue(`{"pr_number": 8, "provider": "gitlab"}`).Code)
+
+	req := withProjectID(withUser(httptest.NewRequest(http.MethodGet, path, nil)), project.ProjectID)
+	w = httptest.NewRecorder()
+	h.GetMergeQueue(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp MergeQueueResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	require.Len(t, resp.Entries, 1)
+	assert.Equal(t, 7, resp.Entries[0].PRNumber)
+
+	dequeue := func(prNumber string) *httptest.ResponseRecorder {
+		req := withProjectID(withUser(httptest.NewRequest(http.MethodDelete, path+"/"+prNumber, nil)), project.ProjectID)
+		req = req.WithContext(context.WithValue(req.Context(), GetContextKey("pr_number"), prNumber))
+		w := httptest.NewRecorder()
+		h.DequeueMerge(w, req)
+		return w
+	}
+	require.Equal(t, http.StatusOK, dequeue("7").Code)
+	assert.Equal(t, models.MergeQueueCancelled, s.entries[0].Status)
+	assert.Equal(t, http.StatusNotFound, dequeue("7").Code)
+
+	t.Run("store without merge queue", func(t *testing.T) {
+		req := withProjectID(withUser(httptest.NewRequest(http.MethodGet, path, nil)), project.ProjectID)
+		w := httptest.NewRecorder()
+		NewProjectHandler(&ProjectMockStore{}).GetMergeQueue(w, req)
+		assert.NotEqual(t, http.StatusOK, w.Code)
+	})
+}
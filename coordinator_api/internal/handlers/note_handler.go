@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// noteStore is the narrow store capability behind the job and project note
+// endpoints. See postgres_store/note_operations.go.
+type noteStore interface {
+	ListNotes(ctx context.Context, target, targetID string) ([]models.Note, error)
+	LatestNotes(ctx context.Context, target string, targetIDs []string) (map[string]models.Note, error)
+	GetNote(ctx context.Context, noteID string) (*models.Note, error)
+	CreateNote(ctx context.Context, note *models.Note) error
+	UpdateNote(ctx context.Context, note *models.Note) error
+	DeleteNote(ctx context.Context, noteID string) error
+}
+
+// NoteRequest is the JSON body of the note create and update endpoints.
+// Body is markdown.
+type NoteRequest struct {
+	Body string `json:"body"`
+}
+
+// ListNotesResponse is the JSON body of GET /api/v1/jobs/{job_id}/notes and
+// GET /api/v1/projects/{project_id}/notes, newest first.
+type ListNotesResponse struct {
+	Notes []models.Note `json:"notes"`
+}
+
+// latestNotes returns the newest note on each of targetIDs that has one.
+// Notes are an aside in list responses, so a store without them or a
+// failed lookup leaves them out rather than failing the list.
+func latestNotes(ctx context.Context, st store.Store, target string, targetIDs []string) map[string]models.Note {
+	ns, ok := st.(noteStore)
+	if !ok || len(targetIDs) == 0 {
+		return nil
+	}
+	latest, err := ns.LatestNotes(ctx, target, targetIDs)
+	if err != nil {
+		logging.Log.WithError(err).WithField("target", target).Warn("Failed to get latest notes")
+		return nil
+	}
+	return latest
+}
+
+func (h *BaseHandler) noteStore(w http.ResponseWriter, st store.Store) (noteStore, bool) {
+	ns, ok := st.(noteStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("notes not available"))
+	}
+	return ns, ok
+}
+
+// listNotes responds with the notes on a job or project.
+func (h *BaseHandler) listNotes(w http.ResponseWriter, r *http.Request, st store.Store, target, targetID string) {
+	ns, ok := h.noteStore(w, st)
+	if !ok {
+		return
+	}
+	notes, err := ns.ListNotes(r.Context(), target, targetID)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if notes == nil {
+		notes = []models.Note{}
+	}
+	h.respondWithJSON(w, http.StatusOK, ListNotesResponse{Notes: notes})
+}
+
+// createNote adds the request's note to note's job or project, written by
+// user.
+func (h *BaseHandler) createNote(w http.ResponseWriter, r *http.Request, st store.Store, user *models.User, note *models.Note) {
+	ns, ok := h.noteStore(w, st)
+	if !ok {
+		return
+	}
+	var req NoteRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	note.Body = req.Body
+	note.AuthorID = &user.UserID
+	if err := note.Validate(); err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+		return
+	}
+	if err := ns.CreateNote(r.Context(), note); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusCreated, note)
+}
+
+// editNote updates the request's note or, when remove is set, deletes it.
+// The note must be on the given job or project, and only its author or an
+// admin may change it.
+func (h *BaseHandler) editNote(w http.ResponseWriter, r *http.Request, st store.Store, user *models.User, isAdmin bool, target, targetID string, remove bool) {
+	ns, ok := h.noteStore(w, st)
+	if !ok {
+		return
+	}
+	note, err := ns.GetNote(r.Context(), h.getID(r, "note_id"))
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return
+	}
+	if kind, id := note.Target(); kind != target || id != targetID {
+		h.respondWithError(w, http.StatusNotFound, store.ErrNotFound)
+		return
+	}
+	if !isAdmin && (note.AuthorID == nil || *note.AuthorID != user.UserID) {
+		h.respondWithError(w, http.StatusForbidden, store.ErrForbidden)
+		return
+	}
+
+	if remove {
+		if err := ns.DeleteNote(r.Context(), note.NoteID); err != nil {
+			h.respondWithError(w, http.StatusInternalServerError, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var req NoteRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	note.Body = req.Body
+	if err := note.Validate(); err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+		return
+	}
+	if err := ns.UpdateNote(r.Context(), note); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, note)
+}
+
+// noteJob loads the job a note endpoint is for. Anyone who can view a job
+// can read and add its notes.
+func (h *JobHandler) noteJob(w http.ResponseWriter, r *http.Request) (*models.Job, *models.User, bool) {
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return nil, nil, false
+	}
+	jobID := h.getID(r, "job_id")
+	if jobID == "" {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return nil, nil, false
+	}
+	job, err := h.store.GetJobByID(r.Context(), jobID)
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return nil, nil, false
+	}
+	if !h.canUserViewJob(r.Context(), user, job) {
+		h.respondWithError(w, http.StatusForbidden, store.ErrForbidden)
+		return nil, nil, false
+	}
+	return job, user, true
+}
+
+// ListJobNotes handles GET /api/v1/jobs/{job_id}/notes
+func (h *JobHandler) ListJobNotes(w http.ResponseWriter, r *http.Request) {
+	if job, _, ok := h.noteJob(w, r); ok {
+		h.listNotes(w, r, h.store, models.NoteTargetJob, job.JobID)
+	}
+}
+
+// CreateJobNote handles POST /api/v1/jobs/{job_id}/notes, e.g.
+// {"body": "Known infra outage, ignore failures"}.
+func (h *JobHandler) CreateJobNote(w http.ResponseWriter, r *http.Request) {
+	if job, user, ok := h.noteJob(w, r); ok {
+		h.createNote(w, r, h.store, user, &models.Note{JobID: &job.JobID})
+	}
+}
+
+// UpdateJobNote handles PUT /api/v1/jobs/{job_id}/notes/{note_id}
+func (h *JobHandler) UpdateJobNote(w http.ResponseWriter, r *http.Request) {
+	if job, user, ok := h.noteJob(w, r); ok {
+		h.editNote(w, r, h.store, user, h.isAdmin(user), models.NoteTargetJob, job.JobID, false)
+	}
+}
+
+// DeleteJobNote handles DELETE /api/v1/jobs/{job_id}/notes/{note_id}
+func (h *JobHandler) DeleteJobNote(w http.ResponseWriter, r *http.Request) {
+	if job, user, ok := h.noteJob(w, r); ok {
+		h.editNote(w, r, h.store, user, h.isAdmin(user), models.NoteTargetJob, job.JobID, true)
+	}
+}
+
+// noteProject loads the project a note endpoint is for. Projects are
+// visible to every user, and so are their notes.
+func (h *ProjectHandler) noteProject(w http.ResponseWriter, r *http.Request) (*models.Project, *models.User, bool) {
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return nil, nil, false
+	}
+	project, _, ok := h.projectAndOwner(w, r, user.UserID)
+	return project, user, ok
+}
+
+func (h *ProjectHandler) isAdmin(user *models.User) bool {
+	for _, role := range user.Roles {
+		if role == "admin" || role == "system_admin" {
+			return true
+		}
+	}
+	return false
+}
+
+// ListProjectNotes handles GET /api/v1/projects/{project_id}/notes
+func (h *ProjectHandler) ListProjectNotes(w http.ResponseWriter, r *http.Request) {
+	if project, _, ok := h.noteProject(w, r); ok {
+		h.listNotes(w, r, h.store, models.NoteTargetProject, project.ProjectID)
+	}
+}
+
+// CreateProjectNote handles POST /api/v1/projects/{project_id}/notes
+func (h *ProjectHandler) CreateProjectNote(w http.ResponseWriter, r *http.Request) {
+	if project, user, ok := h.noteProject(w, r); ok {
+		h.createNote(w, r, h.store, user, &models.Note{ProjectID: &project.ProjectID})
+	}
+}
+
+// UpdateProjectNote handles PUT /api/v1/projects/{project_id}/notes/{note_id}
+func (h *ProjectHandler) UpdateProjectNote(w http.ResponseWriter, r *http.Request) {
+	if project, user, ok := h.noteProject(w, r); ok {
+		h.editNote(w, r, h.store, user, h.isAdmin(user), models.NoteTargetProject, project.ProjectID, false)
+	}
+}
+
+// DeleteProjectNote handles DELETE /api/v1/projects/{project_id}/notes/{note_id}
+func (h *ProjectHandler) DeleteProjectNote(w http.ResponseWriter, r *http.Request) {
+	if project, user, ok := h.noteProject(w, r); ok {
+		h.editNote(w, r, h.store, user, h.isAdmin(user), models.NoteTargetProject, project.ProjectID, true)
+	}
+}
@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memNotes keeps notes in memory, in the order they were written.
+type memNotes struct {
+	notes []models.Note
+}
+
+func (m *memNotes) ListNotes(ctx context.Context, target, targetID string) ([]models.Note, error) {
+	notes := []models.Note{}
+	for i := len(m.notes) - 1; i >= 0; i-- {
+		if kind, id := m.notes[i].Target(); kind == target && id == targetID {
+			notes = append(notes, m.notes[i])
+		}
+	}
+	return notes, nil
+}
+
+func (m *memNotes) LatestNotes(ctx context.Context, target string, targetIDs []string) (map[string]models.Note, error) {
+	latest := make(map[string]models.Note)
+	for _, id := range targetIDs {
+		if notes, _ := m.ListNotes(ctx, target, id); len(notes) > 0 {
+			latest[id] = notes[0]
+		}
+	}
+	return latest, nil
+}
+
+func (m *memNotes) GetNote(ctx context.Context, noteID string) (*models.Note, error) {
+	for _, n := range m.notes {
+		if n.NoteID == noteID {
+			return &n, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (m *memNotes) CreateNote(ctx context.Context, note *models.Note) error {
+	note.NoteID = fmt.Sprintf("note-%d", len(m.notes)+1)
+	note.CreatedAt = time.Date(2026, 1, 1, 0, len(m.notes), 0, 0, time.UTC)
+	note.UpdatedAt = note.CreatedAt
+	m.notes = append(m.notes, *note)
+	return nil
+}
+
+func (m *memNotes) UpdateNote(ctx context.Context, note *models.Note) error {
+	for i := range m.notes {
+		if m.notes[i].NoteID == note.NoteID {
+			m.notes[i] = *note
+			return nil
+		}
+	}
+	return store.ErrNotFound
+}
+
+func (m *memNotes) DeleteNote(ctx context.Context, noteID string) error {
+	for i := range m.notes {
+		if m.notes[i].NoteID == noteID {
+			m.notes = append(m.notes[:i], m.notes[i+1:]...)
+			return nil
+		}
+	}
+	return store.ErrNotFound
+}
+
+type jobNoteMockStore struct {
+	MockStore
+	memNotes
+}
+
+type projectNoteMockStore struct {
+	ProjectMockStore
+	memNotes
+}
+
+// noteRequest is a request to a note endpoint of the job or project
+// targetID.
+func noteRequest(method, path, body, targetID, noteID string) *http.Request {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	r = r.WithContext(setIDContext(r.Context(), "job_id", targetID))
+	r = r.WithContext(setIDContext(r.Context(), "project_id", targetID))
+	return r.WithContext(setIDContext(r.Context(), "note_id", noteID))
+}
+
+func TestJobNotes(t *testing.T) {
+	jobs := map[string]*models.Job{
+		"job-1": {JobID: "job-1", UserID: "test-user-id"},
+		"job-2": {JobID: "job-2", UserID: "test-user-id"},
+		"other": {JobID: "other", UserID: "someone-else"},
+	}
+	s := &jobNoteMockStore{}
+	s.GetJobByIDFunc = func(ctx context.Context, jobID string) (*models.Job, error) {
+		if job, ok := jobs[jobID]; ok {
+			return job, nil
+		}
+		return nil, store.ErrNotFound
+	}
+	s.ListJobsFunc = func(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]models.Job, error) {
+		return []models.Job{*jobs["job-1"], *jobs["job-2"]}, nil
+	}
+	h := NewJobHandler(s, nil)
+
+	create := func(jobID, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.CreateJobNote(w, withUser(noteRequest(http.MethodPost, "/api/v1/jobs/"+jobID+"/notes", body, jobID, "")))
+		return w
+	}
+
+	assert.Equal(t, http.StatusBadRequest, create("job-1", `{"body":"  "}`).Code)
+	assert.Equal(t, http.StatusForbidden, create("other", `{"body":"mine now"}`).Code)
+	assert.Equal(t, http.StatusNotFound, create("missing", `{"body":"x"}`).Code)
+
+	w := create("job-1", `{"body":"Flaky runner, retrying"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w = create("job-1", `{"body":"Known infra outage, **ignore failures**"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var note models.Note
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&note))
+	assert.Equal(t, "test-user-id", *note.AuthorID)
+	assert.Equal(t, "job-1", *note.JobID)
+
+	t.Run("lists newest first", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ListJobNotes(w, withUser(noteRequest(http.MethodGet, "/api/v1/jobs/job-1/notes", "", "job-1", "")))
+		require.Equal(t, http.StatusOK, w.Code)
+		var list ListNotesResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&list))
+		require.Len(t, list.Notes, 2)
+		assert.Equal(t, note.NoteID, list.Notes[0].NoteID)
+	})
+
+	t.Run("job lists carry the latest note", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ListJobs(w, withUser(httptest.NewRequest(http.MethodGet, "/api/v1/jobs", nil)))
+		require.Equal(t, http.StatusOK, w.Code)
+		var list ListJobsResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&list))
+		require.Len(t, list.Jobs, 2)
+		require.NotNil(t, list.Jobs[0].LatestNote)
+		assert.Equal(t, "Known infra outage, **ignore failures**", list.Jobs[0].LatestNote.Body)
+		assert.Nil(t, list.Jobs[1].LatestNote)
+
+		w = httptest.NewRecorder()
+		h.ListJobsV2(w, withUser(httptest.NewRequest(http.MethodGet, "/api/v2/jobs", nil)))
+		require.Equal(t, http.StatusOK, w.Code)
+		var v2 ListJobsResponseV2
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&v2))
+		require.NotNil(t, v2.Jobs[0].LatestNote)
+		assert.Equal(t, note.NoteID, v2.Jobs[0].LatestNote.NoteID)
+	})
+
+	t.Run("only the author or an admin edits", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.UpdateJobNote(w, withUser(noteRequest(http.MethodPut, "/", `{"body":"Outage over"}`, "job-1", note.NoteID)))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		got, _ := s.GetNote(context.Background(), note.NoteID)
+		assert.Equal(t, "Outage over", got.Body)
+
+		viewer := &models.User{UserID: "viewer", Roles: []string{"user"}}
+		jobs["job-1"].UserID = "viewer"
+		defer func() { jobs["job-1"].UserID = "test-user-id" }()
+		r := noteRequest(http.MethodPut, "/", `{"body":"mine now"}`, "job-1", note.NoteID)
+		w = httptest.NewRecorder()
+		h.UpdateJobNote(w, r.WithContext(checkauth.SetUserContext(r.Context(), viewer)))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = httptest.NewRecorder()
+		h.UpdateJobNote(w, withAdmin(noteRequest(http.MethodPut, "/", `{"body":"Resolved"}`, "job-1", note.NoteID)))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("notes are edited through their own job", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.DeleteJobNote(w, withUser(noteRequest(http.MethodDelete, "/", "", "job-2", note.NoteID)))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = httptest.NewRecorder()
+		h.DeleteJobNote(w, withUser(noteRequest(http.MethodDelete, "/", "", "job-1", note.NoteID)))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		notes, _ := s.ListNotes(context.Background(), models.NoteTargetJob, "job-1")
+		assert.Len(t, notes, 1)
+	})
+}
+
+func TestProjectNotes(t *testing.T) {
+	s := &projectNoteMockStore{}
+	s.GetProjectByIDFunc = func(ctx context.Context, projectID string) (*models.Project, error) {
+		if projectID != "project-1" {
+			return nil, store.ErrNotFound
+		}
+		return &models.Project{ProjectID: projectID}, nil
+	}
+	s.ListProjectsFunc = func(ctx context.Context, limit, offset int) ([]models.Project, error) {
+		return []models.Project{{ProjectID: "project-1"}, {ProjectID: "project-2"}}, nil
+	}
+	h := NewProjectHandler(s)
+
+	w := httptest.NewRecorder()
+	h.CreateProjectNote(w, withUser(noteRequest(http.MethodPost, "/api/v1/projects/project-1/notes", `{"body":"Deploys paused for the migration"}`, "project-1", "")))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = httptest.NewRecorder()
+	h.ListProjects(w, withUser(httptest.NewRequest(http.MethodGet, "/api/v1/projects", nil)))
+	require.Equal(t, http.StatusOK, w.Code)
+	var list ListProjectsResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&list))
+	require.Len(t, list.Projects, 2)
+	require.NotNil(t, list.Projects[0].LatestNote)
+	assert.Equal(t, "Deploys paused for the migration", list.Projects[0].LatestNote.Body)
+	assert.Nil(t, list.Projects[1].LatestNote)
+}
+
+func TestNotes_WithoutStoreSupport(t *testing.T) {
+	h := NewProjectHandler(&ProjectMockStore{GetProjectByIDFunc: func(ctx context.Context, projectID string) (*models.Project, error) {
+		return &models.Project{ProjectID: projectID}, nil
+	}})
+	w := httptest.NewRecorder()
+	h.ListProjectNotes(w, withUser(noteRequest(http.MethodGet, "/", "", "project-1", "")))
+	assert.NotEqual(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	h.ListProjects(w, withUser(httptest.NewRequest(http.MethodGet, "/api/v1/projects", nil)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "latest_note")
+}
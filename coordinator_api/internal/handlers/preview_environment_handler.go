@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/jobcontrol"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// defaultPreviewEnvironmentName is used when a deploy job registers an
+// environment without naming it.
+const defaultPreviewEnvironmentName = "preview"
+
+var previewEnvironmentNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,62}$`)
+
+// previewEnvironmentStore is the narrow store capability behind the
+// preview environment endpoints. See
+// postgres_store/preview_environment_operations.go.
+type previewEnvironmentStore interface {
+	RegisterPreviewEnvironment(ctx context.Context, env *models.PreviewEnvironment) error
+	GetPreviewEnvironment(ctx context.Context, environmentID string) (*models.PreviewEnvironment, error)
+	ListProjectPreviewEnvironments(ctx context.Context, projectID string, prNumber int, status string, limit, offset int) ([]models.PreviewEnvironment, error)
+}
+
+// PreviewEnvironmentHandler serves preview environments: deploy jobs
+// register them, and they are listed per project and torn down on demand.
+type PreviewEnvironmentHandler struct {
+	BaseHandler
+	store          store.Store
+	corndogsClient corndogs.ClientInterface
+}
+
+// NewPreviewEnvironmentHandler creates a new preview environment handler.
+func NewPreviewEnvironmentHandler(store store.Store, corndogsClient corndogs.ClientInterface) *PreviewEnvironmentHandler {
+	return &PreviewEnvironmentHandler{
+		store:          store,
+		corndogsClient: corndogsClient,
+	}
+}
+
+// RegisterPreviewEnvironmentRequest is the body of
+// POST /api/v1/jobs/{job_id}/environment.
+type RegisterPreviewEnvironmentRequest struct {
+	Name            string `json:"name,omitempty"`
+	URL             string `json:"url"`
+	TeardownCommand string `json:"teardown_command,omitempty"`
+}
+
+// ListPreviewEnvironmentsResponse is the response for
+// GET /api/v1/projects/{project_id}/environments.
+type ListPreviewEnvironmentsResponse struct {
+	Environments []models.PreviewEnvironment `json:"environments"`
+	Limit        int                         `json:"limit"`
+	Offset       int                         `json:"offset"`
+}
+
+// RegisterEnvironment handles POST /api/v1/jobs/{job_id}/environment. A
+// deploy job calls it with the URL of the preview environment it deployed
+// for its PR, and the command that tears the environment down; it's run
+// when the PR is closed or merged. Registering again (e.g. on the next PR
+// update) refreshes the environment of the same name.
+//
+// Authz: same tier as SubmitTriggers (the job's owner or an admin), since
+// it's the job itself calling with its API token.
+func (h *PreviewEnvironmentHandler) RegisterEnvironment(w http.ResponseWriter, r *http.Request) {
+	ps, ok := h.store.(previewEnvironmentStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("preview environments not available"))
+		return
+	}
+
+	jobID := h.getID(r, "job_id")
+	if jobID == "" {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+	job, err := h.store.GetJobByID(r.Context(), jobID)
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return
+	}
+
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return
+	}
+	if !h.isAdmin(user) && job.UserID != user.UserID {
+		h.respondWithError(w, http.StatusForbidden, store.ErrForbidden)
+		return
+	}
+
+	// Environments belong to a PR; only jobs built for one can register.
+	if job.VCSRepo == nil || job.PRNumber == nil {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+
+	var req RegisterPreviewEnvironmentRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Name == "" {
+		req.Name = defaultPreviewEnvironmentName
+	}
+	if !previewEnvironmentNamePattern.MatchString(req.Name) || !isHTTPURL(req.URL) {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+
+	env := &models.PreviewEnvironment{
+		ProjectID:       job.ProjectID,
+		VCSRepo:         *job.VCSRepo,
+		PRNumber:        *job.PRNumber,
+		Name:            req.Name,
+		URL:             req.URL,
+		DeployJobID:     &job.JobID,
+		TeardownCommand: req.TeardownCommand,
+	}
+	if job.CommitSHA != nil {
+		env.HeadSHA = *job.CommitSHA
+	}
+	if err := ps.RegisterPreviewEnvironment(r.Context(), env); err != nil {
+		if errors.Is(err, store.ErrInvalidInput) {
+			h.respondWithError(w, http.StatusBadRequest, err)
+			return
+		}
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, env)
+}
+
+// ListProjectEnvironments handles GET /api/v1/projects/{project_id}/environments.
+// pr_number and status narrow the list; limit/offset page through it
+// (default 20, max 100).
+func (h *PreviewEnvironmentHandler) ListProjectEnvironments(w http.ResponseWriter, r *http.Request) {
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return
+	}
+	ps, ok := h.store.(previewEnvironmentStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("preview environments not available"))
+		return
+	}
+
+	projectID := h.getID(r, "project_id")
+	if projectID == "" {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+	if _, err := h.store.GetProjectByID(r.Context(), projectID); err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return
+	}
+
+	query := r.URL.Query()
+	status := query.Get("status")
+	switch status {
+	case "", models.PreviewEnvironmentActive, models.PreviewEnvironmentTearingDown, models.PreviewEnvironmentTornDown, models.PreviewEnvironmentTeardownFailed:
+	default:
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+	prNumber := 0
+	if raw := query.Get("pr_number"); raw != "" {
+		var err error
+		prNumber, err = strconv.Atoi(raw)
+		if err != nil || prNumber <= 0 {
+			h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+			return
+		}
+	}
+	limit := 20
+	offset := 0
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+	if o, err := strconv.Atoi(query.Get("offset")); err == nil && o >= 0 {
+		offset = o
+	}
+
+	envs, err := ps.ListProjectPreviewEnvironments(r.Context(), projectID, prNumber, status, limit, offset)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if envs == nil {
+		envs = []models.PreviewEnvironment{}
+	}
+	h.respondWithJSON(w, http.StatusOK, ListPreviewEnvironmentsResponse{Environments: envs, Limit: limit, Offset: offset})
+}
+
+// GetEnvironment handles GET /api/v1/environments/{environment_id}.
+func (h *PreviewEnvironmentHandler) GetEnvironment(w http.ResponseWriter, r *http.Request) {
+	env, ok := h.loadEnvironment(w, r)
+	if !ok {
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, env)
+}
+
+// TeardownEnvironment handles DELETE /api/v1/environments/{environment_id}:
+// tears the environment down now rather than waiting for its PR to close,
+// or retries a failed teardown. Responds 202 with the environment, whose
+// teardown_job_id is the job doing it, or 400 if it's already torn down or
+// being torn down.
+//
+// Authz: the deploy job's owner or an admin.
+func (h *PreviewEnvironmentHandler) TeardownEnvironment(w http.ResponseWriter, r *http.Request) {
+	env, ok := h.loadEnvironment(w, r)
+	if !ok {
+		return
+	}
+
+	user := checkauth.GetUserFromContext(r.Context())
+	if !h.isAdmin(user) {
+		allowed := false
+		if env.DeployJobID != nil {
+			if deployJob, err := h.store.GetJobByID(r.Context(), *env.DeployJobID); err == nil {
+				allowed = deployJob.UserID == user.UserID
+			}
+		}
+		if !allowed {
+			h.respondWithError(w, http.StatusForbidden, store.ErrForbidden)
+			return
+		}
+	}
+
+	if _, err := jobcontrol.TeardownPreviewEnvironment(r.Context(), h.store, h.corndogsClient, env, "manual"); err != nil {
+		if errors.Is(err, jobcontrol.ErrNotTearable) {
+			h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+			return
+		}
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusAccepted, env)
+}
+
+// loadEnvironment loads the environment named in the path for the
+// single-environment endpoints, writing the error response itself if it
+// can't.
+func (h *PreviewEnvironmentHandler) loadEnvironment(w http.ResponseWriter, r *http.Request) (*models.PreviewEnvironment, bool) {
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return nil, false
+	}
+	ps, ok := h.store.(previewEnvironmentStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("preview environments not available"))
+		return nil, false
+	}
+	environmentID := h.getID(r, "environment_id")
+	if environmentID == "" {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return nil, false
+	}
+	env, err := ps.GetPreviewEnvironment(r.Context(), environmentID)
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return nil, false
+	}
+	return env, true
+}
+
+func (h *PreviewEnvironmentHandler) isAdmin(user *models.User) bool {
+	for _, role := range user.Roles {
+		if role == "admin" || role == "system_admin" {
+			return true
+		}
+	}
+	return false
+}
+
+// isHTTPURL reports whether raw is an absolute http(s) URL.
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
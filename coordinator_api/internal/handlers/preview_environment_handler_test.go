@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/worker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// previewEnvironments is an in-memory set of preview environments with the
+// postgres store's guarded-update semantics, shared by the handler and
+// webhook test stores below.
+type previewEnvironments struct {
+	envs []*models.PreviewEnvironment
+}
+
+func (p *previewEnvironments) RegisterPreviewEnvironment(ctx context.Context, env *models.PreviewEnvironment) error {
+	env.Status = models.PreviewEnvironmentActive
+	for _, existing := range p.envs {
+		if existing.VCSRepo == env.VCSRepo && existing.PRNumber == env.PRNumber && existing.Name == env.Name && existing.Status == models.PreviewEnvironmentActive {
+			env.EnvironmentID = existing.EnvironmentID
+			*existing = *env
+			return nil
+		}
+	}
+	env.EnvironmentID = "env-" + env.Name
+	copied := *env
+	p.envs = append(p.envs, &copied)
+	return nil
+}
+
+func (p *previewEnvironments) GetPreviewEnvironment(ctx context.Context, environmentID string) (*models.PreviewEnvironment, error) {
+	for _, env := range p.envs {
+		if env.EnvironmentID == environmentID {
+			copied := *env
+			return &copied, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (p *previewEnvironments) ListProjectPreviewEnvironments(ctx context.Context, projectID string, prNumber int, status string, limit, offset int) ([]models.PreviewEnvironment, error) {
+	var out []models.PreviewEnvironment
+	for _, env := range p.envs {
+		if env.ProjectID != nil && *env.ProjectID == projectID && (status == "" || env.Status == status) {
+			out = append(out, *env)
+		}
+	}
+	return out, nil
+}
+
+func (p *previewEnvironments) ListPreviewEnvironmentsForPR(ctx context.Context, repo string, prNumber int) ([]models.PreviewEnvironment, error) {
+	var out []models.PreviewEnvironment
+	for _, env := range p.envs {
+		if env.VCSRepo == repo && env.PRNumber == prNumber && env.CanTearDown() {
+			out = append(out, *env)
+		}
+	}
+	return out, nil
+}
+
+func (p *previewEnvironments) UpdatePreviewEnvironment(ctx context.Context, env *models.PreviewEnvironment, fromStatus string) (bool, error) {
+	for _, stored := range p.envs {
+		if stored.EnvironmentID == env.EnvironmentID && stored.Status == fromStatus {
+			stored.Status = env.Status
+			stored.TeardownJobID = env.TeardownJobID
+			stored.TornDownAt = env.TornDownAt
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type previewMockStore struct {
+	MockStore
+	previewEnvironments
+}
+
+func newPreviewMockStore() *previewMockStore {
+	m := &previewMockStore{}
+	repo := "test-org/test-repo"
+	pr := 7
+	sha := "sha-head"
+	projectID := "project-1"
+	m.GetJobByIDFunc = func(ctx context.Context, jobID string) (*models.Job, error) {
+		return &models.Job{
+			JobID:      jobID,
+			UserID:     "test-user-id",
+			ProjectID:  &projectID,
+			Status:     "running",
+			JobCommand: "make deploy-preview",
+			VCSRepo:    &repo,
+			PRNumber:   &pr,
+			CommitSHA:  &sha,
+		}, nil
+	}
+	return m
+}
+
+func previewRequest(method, path, body string, user *models.User, ids map[string]string) *http.Request {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	ctx := checkauth.SetUserContext(req.Context(), user)
+	for key, value := range ids {
+		ctx = context.WithValue(ctx, GetContextKey(key), value)
+	}
+	return req.WithContext(ctx)
+}
+
+func TestPreviewEnvironmentHandler_RegisterEnvironment(t *testing.T) {
+	owner := &models.User{UserID: "test-user-id"}
+	jobIDs := map[string]string{"job_id": "deploy-job"}
+
+	t.Run("registers and refreshes", func(t *testing.T) {
+		mockStore := newPreviewMockStore()
+		handler := NewPreviewEnvironmentHandler(mockStore, nil)
+
+		w := httptest.NewRecorder()
+		handler.RegisterEnvironment(w, previewRequest("POST", "/api/v1/jobs/deploy-job/environment",
+			`{"url":"https://pr-7.preview.example.com","teardown_command":"make destroy-preview"}`, owner, jobIDs))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var env models.PreviewEnvironment
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+		assert.Equal(t, "preview", env.Name)
+		assert.Equal(t, "test-org/test-repo", env.VCSRepo)
+		assert.Equal(t, 7, env.PRNumber)
+		assert.Equal(t, "sha-head", env.HeadSHA)
+		assert.Equal(t, models.PreviewEnvironmentActive, env.Status)
+		require.NotNil(t, env.DeployJobID)
+		assert.Equal(t, "deploy-job", *env.DeployJobID)
+
+		w = httptest.NewRecorder()
+		handler.RegisterEnvironment(w, previewRequest("POST", "/api/v1/jobs/deploy-job/environment",
+			`{"url":"https://pr-7b.preview.example.com"}`, owner, jobIDs))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.Len(t, mockStore.envs, 1)
+		assert.Equal(t, "https://pr-7b.preview.example.com", mockStore.envs[0].URL)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		for _, body := range []string{
+			`{}`,
+			`{"url":"not a url"}`,
+			`{"url":"ftp://example.com"}`,
+			`{"url":"https://example.com","name":"bad name"}`,
+		} {
+			handler := NewPreviewEnvironmentHandler(newPreviewMockStore(), nil)
+			w := httptest.NewRecorder()
+			handler.RegisterEnvironment(w, previewRequest("POST", "/api/v1/jobs/deploy-job/environment", body, owner, jobIDs))
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+	})
+
+	t.Run("job without a PR", func(t *testing.T) {
+		mockStore := newPreviewMockStore()
+		mockStore.GetJobByIDFunc = func(ctx context.Context, jobID string) (*models.Job, error) {
+			return &models.Job{JobID: jobID, UserID: "test-user-id"}, nil
+		}
+		handler := NewPreviewEnvironmentHandler(mockStore, nil)
+		w := httptest.NewRecorder()
+		handler.RegisterEnvironment(w, previewRequest("POST", "/api/v1/jobs/deploy-job/environment",
+			`{"url":"https://example.com"}`, owner, jobIDs))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("other user is denied", func(t *testing.T) {
+		handler := NewPreviewEnvironmentHandler(newPreviewMockStore(), nil)
+		w := httptest.NewRecorder()
+		handler.RegisterEnvironment(w, previewRequest("POST", "/api/v1/jobs/deploy-job/environment",
+			`{"url":"https://example.com"}`, &models.User{UserID: "someone-else"}, jobIDs))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("store without preview environments", func(t *testing.T) {
+		handler := NewPreviewEnvironmentHandler(&MockStore{}, nil)
+		w := httptest.NewRecorder()
+		handler.RegisterEnvironment(w, previewRequest("POST", "/api/v1/jobs/deploy-job/environment",
+			`{"url":"https://example.com"}`, owner, jobIDs))
+		assert.NotEqual(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestPreviewEnvironmentHandler_ListAndTeardown(t *testing.T) {
+	owner := &models.User{UserID: "test-user-id"}
+	mockStore := newPreviewMockStore()
+	mockCorndogs := corndogs.NewMockClient()
+	handler := NewPreviewEnvironmentHandler(mockStore, mockCorndogs)
+
+	w := httptest.NewRecorder()
+	handler.RegisterEnvironment(w, previewRequest("POST", "/api/v1/jobs/deploy-job/environment",
+		`{"url":"https://pr-7.preview.example.com","teardown_command":"make destroy-preview"}`, owner, map[string]string{"job_id": "deploy-job"}))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = httptest.NewRecorder()
+	handler.ListProjectEnvironments(w, previewRequest("GET", "/api/v1/projects/project-1/environments?status=active", "", owner, map[string]string{"project_id": "project-1"}))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var list ListPreviewEnvironmentsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Environments, 1)
+	assert.Equal(t, "https://pr-7.preview.example.com", list.Environments[0].URL)
+
+	w = httptest.NewRecorder()
+	handler.ListProjectEnvironments(w, previewRequest("GET", "/api/v1/projects/project-1/environments?status=bogus", "", owner, map[string]string{"project_id": "project-1"}))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	envIDs := map[string]string{"environment_id": "env-preview"}
+	w = httptest.NewRecorder()
+	handler.TeardownEnvironment(w, previewRequest("DELETE", "/api/v1/environments/env-preview", "", &models.User{UserID: "someone-else"}, envIDs))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.TeardownEnvironment(w, previewRequest("DELETE", "/api/v1/environments/env-preview", "", owner, envIDs))
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	require.Len(t, mockStore.CreateJobCalls, 1)
+	assert.Equal(t, "make destroy-preview", mockStore.CreateJobCalls[0].JobCommand)
+	assert.Equal(t, "manual", mockStore.CreateJobCalls[0].JobEnvVars["REACTORCIDE_EVENT_TYPE"])
+	assert.Equal(t, models.PreviewEnvironmentTearingDown, mockStore.envs[0].Status)
+	assert.Equal(t, 1, mockCorndogs.GetSubmitTaskCallCount())
+
+	w = httptest.NewRecorder()
+	handler.TeardownEnvironment(w, previewRequest("DELETE", "/api/v1/environments/env-preview", "", owner, envIDs))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// previewWebhookMockStore adds preview environments and a deploy job to
+// WebhookMockStore.
+type previewWebhookMockStore struct {
+	WebhookMockStore
+	previewEnvironments
+}
+
+func (m *previewWebhookMockStore) GetJobByID(ctx context.Context, jobID string) (*models.Job, error) {
+	return &models.Job{JobID: jobID, Status: "completed", JobCommand: "make deploy-preview"}, nil
+}
+
+func TestWebhookHandler_PRClosed_TearsDownPreviewEnvironments(t *testing.T) {
+	deployJobID := "deploy-job"
+	mockStore := &previewWebhookMockStore{}
+	mockStore.GetProjectByRepoURLFunc = func(ctx context.Context, repoURL string) (*models.Project, error) {
+		return webhookTestProject(), nil
+	}
+	mockStore.envs = []*models.PreviewEnvironment{{
+		EnvironmentID:   "env-1",
+		VCSRepo:         "test-org/test-repo",
+		PRNumber:        7,
+		Name:            "preview",
+		URL:             "https://pr-7.preview.example.com",
+		Status:          models.PreviewEnvironmentActive,
+		DeployJobID:     &deployJobID,
+		TeardownCommand: "make destroy-preview",
+	}}
+	mockCorndogs := corndogs.NewMockClient()
+	handler := NewWebhookHandler(mockStore, mockCorndogs)
+	handler.SetTokenResolver(testTokenResolver())
+
+	prEvent := &vcs.WebhookEvent{
+		Provider:     vcs.GitHub,
+		EventType:    "pull_request",
+		GenericEvent: vcs.EventPullRequestClosed,
+		Repository: vcs.RepositoryInfo{
+			FullName: "test-org/test-repo",
+			CloneURL: "https://github.com/test-org/test-repo.git",
+		},
+		PullRequest: &vcs.PullRequestInfo{
+			Number:  7,
+			Title:   "Closed PR",
+			Action:  "closed",
+			HeadSHA: "sha-closed",
+			HeadRef: "feature",
+			BaseRef: "main",
+		},
+	}
+	handler.AddVCSClient(vcs.GitHub, &MockVCSClient{
+		ParseWebhookFunc: func(r *http.Request) (*vcs.WebhookEvent, error) {
+			return prEvent, nil
+		},
+	})
+
+	body := makePRWebhookBody("test-org/test-repo", "https://github.com/test-org/test-repo.git", "sha-closed", "feature", "main", 7)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/github", bytes.NewReader(body))
+	req.Header.Set("X-GitHub-Event", "pull_request")
+	w := httptest.NewRecorder()
+	handler.HandleGitHubWebhook(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	// The project doesn't build on pull_request_closed, so the only job is
+	// the teardown.
+	require.Len(t, mockStore.CreateJobCalls, 1)
+	teardown := mockStore.CreateJobCalls[0]
+	assert.Equal(t, "make destroy-preview", teardown.JobCommand)
+	assert.Equal(t, worker.PreviewActionTeardown, teardown.JobEnvVars[worker.PreviewActionEnv])
+	assert.Equal(t, "pull_request_closed", teardown.JobEnvVars["REACTORCIDE_EVENT_TYPE"])
+	assert.Equal(t, models.PreviewEnvironmentTearingDown, mockStore.envs[0].Status)
+	assert.Equal(t, 1, mockCorndogs.GetSubmitTaskCallCount())
+}
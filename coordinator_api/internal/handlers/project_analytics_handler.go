@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+const (
+	defaultAnalyticsWindow = 30 * 24 * time.Hour
+	maxAnalyticsWindow     = 365 * 24 * time.Hour
+	defaultAnalyticsBucket = "day"
+)
+
+// projectAnalyticsStore is the narrow store capability behind
+// GET /api/v1/projects/{id}/analytics. See
+// postgres_store/analytics_operations.go.
+type projectAnalyticsStore interface {
+	GetProjectAnalytics(ctx context.Context, projectID string, since, until time.Time, bucket, jobName string) (*models.ProjectAnalytics, error)
+}
+
+// GetProjectAnalytics handles GET /api/v1/projects/{project_id}/analytics
+//
+// Query parameters:
+//   - window: how far back to look, as a Go duration or a whole number of
+//     days ("7d"). Defaults to 30d, capped at 365d.
+//   - bucket: trend granularity, one of hour, day, week. Defaults to day.
+//   - job_name: restrict the report to one job name.
+func (h *ProjectHandler) GetProjectAnalytics(w http.ResponseWriter, r *http.Request) {
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return
+	}
+	analyticsStore, ok := h.store.(projectAnalyticsStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("project analytics not available"))
+		return
+	}
+
+	projectID := h.getID(r, "project_id")
+	if projectID == "" {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+	project, err := h.store.GetProjectByID(r.Context(), projectID)
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return
+	}
+
+	query := r.URL.Query()
+	window := defaultAnalyticsWindow
+	if raw := query.Get("window"); raw != "" {
+		window, err = parseAnalyticsWindow(raw)
+		if err != nil {
+			h.respondWithError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	bucket := defaultAnalyticsBucket
+	if raw := query.Get("bucket"); raw != "" {
+		if raw != "hour" && raw != "day" && raw != "week" {
+			h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+			return
+		}
+		bucket = raw
+	}
+
+	until := time.Now().UTC()
+	analytics, err := analyticsStore.GetProjectAnalytics(r.Context(), project.ProjectID, until.Add(-window), until, bucket, query.Get("job_name"))
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, analytics)
+}
+
+// parseAnalyticsWindow accepts a Go duration ("36h") or a whole number of
+// days ("7d"), which time.ParseDuration doesn't support.
+func parseAnalyticsWindow(raw string) (time.Duration, error) {
+	var window time.Duration
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("%w: window %q", store.ErrInvalidInput, raw)
+		}
+		window = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return 0, fmt.Errorf("%w: window %q", store.ErrInvalidInput, raw)
+		}
+		window = d
+	}
+	if window <= 0 || window > maxAnalyticsWindow {
+		return 0, fmt.Errorf("%w: window must be between 0 and %s", store.ErrInvalidInput, maxAnalyticsWindow)
+	}
+	return window, nil
+}
+
+// projectDeadlineStore is the narrow store capability behind
+// GET /api/v1/projects/{id}/deadlines. See
+// postgres_store/deadline_operations.go.
+type projectDeadlineStore interface {
+	GetProjectDeadlineReport(ctx context.Context, projectID string, since, until time.Time) (*models.DeadlineReport, error)
+}
+
+// GetProjectDeadlines handles GET /api/v1/projects/{project_id}/deadlines
+//
+// Query parameters:
+//   - window: how far back to look, as for GetProjectAnalytics. Defaults
+//     to 30d, capped at 365d.
+func (h *ProjectHandler) GetProjectDeadlines(w http.ResponseWriter, r *http.Request) {
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return
+	}
+	deadlineStore, ok := h.store.(projectDeadlineStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("deadline reports not available"))
+		return
+	}
+
+	projectID := h.getID(r, "project_id")
+	if projectID == "" {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+	project, err := h.store.GetProjectByID(r.Context(), projectID)
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return
+	}
+
+	window := defaultAnalyticsWindow
+	if raw := r.URL.Query().Get("window"); raw != "" {
+		window, err = parseAnalyticsWindow(raw)
+		if err != nil {
+			h.respondWithError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	until := time.Now().UTC()
+	report, err := deadlineStore.GetProjectDeadlineReport(r.Context(), project.ProjectID, until.Add(-window), until)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, report)
+}
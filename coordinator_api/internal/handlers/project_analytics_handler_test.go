@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// analyticsMockStore adds GetProjectAnalytics to ProjectMockStore.
+type analyticsMockStore struct {
+	ProjectMockStore
+	calls []struct {
+		Since, Until    time.Time
+		Bucket, JobName string
+	}
+}
+
+func (m *analyticsMockStore) GetProjectAnalytics(ctx context.Context, projectID string, since, until time.Time, bucket, jobName string) (*models.ProjectAnalytics, error) {
+	m.calls = append(m.calls, struct {
+		Since, Until    time.Time
+		Bucket, JobName string
+	}{since, until, bucket, jobName})
+	return &models.ProjectAnalytics{ProjectID: projectID, Since: since, Until: until, Bucket: bucket, JobName: jobName}, nil
+}
+
+func TestProjectHandler_GetProjectAnalytics(t *testing.T) {
+	projectID := uuid.New().String()
+	newStore := func() *analyticsMockStore {
+		s := &analyticsMockStore{}
+		s.GetProjectByIDFunc = func(ctx context.Context, id string) (*models.Project, error) {
+			return testProject(id), nil
+		}
+		return s
+	}
+	get := func(h *ProjectHandler, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/projects/"+projectID+"/analytics"+query, nil)
+		req = withProjectID(withUser(req), projectID)
+		w := httptest.NewRecorder()
+		h.GetProjectAnalytics(w, req)
+		return w
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		s := newStore()
+		w := get(NewProjectHandler(s), "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Len(t, s.calls, 1)
+		assert.Equal(t, "day", s.calls[0].Bucket)
+		assert.InDelta(t, (30 * 24 * time.Hour).Seconds(), s.calls[0].Until.Sub(s.calls[0].Since).Seconds(), 1)
+	})
+
+	t.Run("window bucket and job name", func(t *testing.T) {
+		s := newStore()
+		w := get(NewProjectHandler(s), "?window=7d&bucket=hour&job_name=build")
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp models.ProjectAnalytics
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, "hour", resp.Bucket)
+		assert.Equal(t, "build", resp.JobName)
+		assert.InDelta(t, (7 * 24 * time.Hour).Seconds(), resp.Until.Sub(resp.Since).Seconds(), 1)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		for _, query := range []string{"?window=forever", "?window=400d", "?window=-1h", "?bucket=minute"} {
+			s := newStore()
+			w := get(NewProjectHandler(s), query)
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+			assert.Empty(t, s.calls, query)
+		}
+	})
+
+	t.Run("store without analytics", func(t *testing.T) {
+		w := get(NewProjectHandler(&ProjectMockStore{}), "")
+		assert.NotEqual(t, http.StatusOK, w.Code)
+	})
+}
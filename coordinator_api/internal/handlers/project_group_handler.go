@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/worker"
+	"github.com/lib/pq"
+)
+
+// projectGroupStore is the narrow store capability behind the project group
+// endpoints. See postgres_store/project_group_operations.go.
+type projectGroupStore interface {
+	CreateProjectGroup(ctx context.Context, group *models.ProjectGroup) error
+	GetProjectGroup(ctx context.Context, groupID string) (*models.ProjectGroup, error)
+	ListProjectGroups(ctx context.Context, limit, offset int) ([]models.ProjectGroup, error)
+	UpdateProjectGroup(ctx context.Context, group *models.ProjectGroup) error
+	DeleteProjectGroup(ctx context.Context, groupID string) error
+	ListProjectsInGroup(ctx context.Context, groupID string, limit, offset int) ([]models.Project, error)
+}
+
+// ProjectGroupRequest is the body of POST /api/v1/project-groups and
+// PUT /api/v1/project-groups/{group_id}. On update, omitted fields are left
+// alone.
+type ProjectGroupRequest struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+
+	DefaultRunnerImage    *string  `json:"default_runner_image,omitempty"`
+	DefaultTimeoutSeconds *int     `json:"default_timeout_seconds,omitempty"`
+	DefaultQueueName      *string  `json:"default_queue_name,omitempty"`
+	AllowedEventTypes     []string `json:"allowed_event_types,omitempty"`
+	VCSTokenSecret        *string  `json:"vcs_token_secret,omitempty"`
+	WebhookSecret         *string  `json:"webhook_secret,omitempty"`
+
+	// ClearDefaults unsets defaults by field name, so members go back to
+	// their own values.
+	ClearDefaults []string `json:"clear_defaults,omitempty"`
+}
+
+// ListProjectGroupsResponse is the JSON body of GET /api/v1/project-groups.
+type ListProjectGroupsResponse struct {
+	Groups []models.ProjectGroup `json:"groups"`
+	Total  int                   `json:"total"`
+	Limit  int                   `json:"limit"`
+	Offset int                   `json:"offset"`
+}
+
+// CreateProjectGroup handles POST /api/v1/project-groups
+func (h *ProjectHandler) CreateProjectGroup(w http.ResponseWriter, r *http.Request) {
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return
+	}
+	gs, ok := h.store.(projectGroupStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("project groups not available"))
+		return
+	}
+	var req ProjectGroupRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Name == nil || *req.Name == "" {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+
+	group := &models.ProjectGroup{UserID: &user.UserID}
+	if !h.applyProjectGroupRequest(w, group, &req) {
+		return
+	}
+	if err := gs.CreateProjectGroup(r.Context(), group); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusCreated, group)
+}
+
+// ListProjectGroups handles GET /api/v1/project-groups
+func (h *ProjectHandler) ListProjectGroups(w http.ResponseWriter, r *http.Request) {
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return
+	}
+	gs, ok := h.store.(projectGroupStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("project groups not available"))
+		return
+	}
+	limit, offset := parseListPagination(r)
+	groups, err := gs.ListProjectGroups(r.Context(), limit, offset)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if groups == nil {
+		groups = []models.ProjectGroup{}
+	}
+	h.respondWithJSON(w, http.StatusOK, ListProjectGroupsResponse{
+		Groups: groups,
+		Total:  len(groups),
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+// GetProjectGroup handles GET /api/v1/project-groups/{group_id}
+func (h *ProjectHandler) GetProjectGroup(w http.ResponseWriter, r *http.Request) {
+	_, group, ok := h.loadProjectGroup(w, r)
+	if !ok {
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, group)
+}
+
+// UpdateProjectGroup handles PUT /api/v1/project-groups/{group_id}. Member
+// projects inherit the new defaults at once.
+func (h *ProjectHandler) UpdateProjectGroup(w http.ResponseWriter, r *http.Request) {
+	gs, group, ok := h.loadProjectGroup(w, r)
+	if !ok {
+		return
+	}
+	var req ProjectGroupRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Name != nil && *req.Name == "" {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+	if !h.applyProjectGroupRequest(w, group, &req) {
+		return
+	}
+	if err := gs.UpdateProjectGroup(r.Context(), group); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, group)
+}
+
+// DeleteProjectGroup handles DELETE /api/v1/project-groups/{group_id}.
+// Member projects leave the group, keeping the values they last inherited.
+func (h *ProjectHandler) DeleteProjectGroup(w http.ResponseWriter, r *http.Request) {
+	gs, group, ok := h.loadProjectGroup(w, r)
+	if !ok {
+		return
+	}
+	if err := gs.DeleteProjectGroup(r.Context(), group.GroupID); err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListProjectGroupProjects handles GET /api/v1/project-groups/{group_id}/projects
+func (h *ProjectHandler) ListProjectGroupProjects(w http.ResponseWriter, r *http.Request) {
+	gs, group, ok := h.loadProjectGroup(w, r)
+	if !ok {
+		return
+	}
+	limit, offset := parseListPagination(r)
+	projects, err := gs.ListProjectsInGroup(r.Context(), group.GroupID, limit, offset)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	responses := make([]ProjectResponse, len(projects))
+	for i := range projects {
+		responses[i] = projectToResponse(&projects[i])
+	}
+	h.respondWithJSON(w, http.StatusOK, ListProjectsResponse{
+		Projects: responses,
+		Total:    len(responses),
+		Limit:    limit,
+		Offset:   offset,
+	})
+}
+
+// ListProjectGroupJobs handles GET /api/v1/project-groups/{group_id}/jobs:
+// the jobs of every project in the group, as GET /api/v1/jobs?group_id=
+// lists them, with the same filters and visibility rules.
+func (h *JobHandler) ListProjectGroupJobs(w http.ResponseWriter, r *http.Request) {
+	groupID := h.getID(r, "group_id")
+	if groupID == "" {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+	query := r.URL.Query()
+	query.Set("group_id", groupID)
+	r.URL.RawQuery = query.Encode()
+	h.ListJobs(w, r)
+}
+
+func (h *ProjectHandler) loadProjectGroup(w http.ResponseWriter, r *http.Request) (projectGroupStore, *models.ProjectGroup, bool) {
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return nil, nil, false
+	}
+	gs, ok := h.store.(projectGroupStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("project groups not available"))
+		return nil, nil, false
+	}
+	groupID := h.getID(r, "group_id")
+	if groupID == "" {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return nil, nil, false
+	}
+	group, err := gs.GetProjectGroup(r.Context(), groupID)
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return nil, nil, false
+	}
+	return gs, group, true
+}
+
+// applyProjectGroupRequest validates req and applies it to group.
+func (h *ProjectHandler) applyProjectGroupRequest(w http.ResponseWriter, group *models.ProjectGroup, req *ProjectGroupRequest) bool {
+	if req.Name != nil {
+		group.Name = *req.Name
+	}
+	if req.Description != nil {
+		group.Description = *req.Description
+	}
+	if !h.checkPayloadLimits(w, worker.CheckJobPayloadLimits(group.Name, group.Description, "", nil)) {
+		return false
+	}
+	if req.DefaultTimeoutSeconds != nil && *req.DefaultTimeoutSeconds <= 0 {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return false
+	}
+	for _, field := range req.ClearDefaults {
+		if !models.IsInheritableProjectField(field) {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_input",
+				Message: "unknown project field " + field,
+			})
+			return false
+		}
+	}
+
+	if req.DefaultRunnerImage != nil {
+		group.DefaultRunnerImage = req.DefaultRunnerImage
+	}
+	if req.DefaultTimeoutSeconds != nil {
+		group.DefaultTimeoutSeconds = req.DefaultTimeoutSeconds
+	}
+	if req.DefaultQueueName != nil {
+		group.DefaultQueueName = req.DefaultQueueName
+	}
+	if req.AllowedEventTypes != nil {
+		group.AllowedEventTypes = pq.StringArray(req.AllowedEventTypes)
+	}
+	if req.VCSTokenSecret != nil {
+		group.VCSTokenSecret = req.VCSTokenSecret
+	}
+	if req.WebhookSecret != nil {
+		group.WebhookSecret = req.WebhookSecret
+	}
+	for _, field := range req.ClearDefaults {
+		switch field {
+		case models.ProjectFieldRunnerImage:
+			group.DefaultRunnerImage = nil
+		case models.ProjectFieldTimeoutSeconds:
+			group.DefaultTimeoutSeconds = nil
+		case models.ProjectFieldQueueName:
+			group.DefaultQueueName = nil
+		case models.ProjectFieldAllowedEventTypes:
+			group.AllowedEventTypes = nil
+		case models.ProjectFieldVCSTokenSecret:
+			group.VCSTokenSecret = nil
+		case models.ProjectFieldWebhookSecret:
+			group.WebhookSecret = nil
+		}
+	}
+	return true
+}
+
+// setProjectGroup moves project into the group groupID, or out of its group
+// when groupID is empty. Leaving a group drops the project's overrides,
+// which only mean something inside one.
+func (h *ProjectHandler) setProjectGroup(w http.ResponseWriter, r *http.Request, project *models.Project, groupID string) bool {
+	if groupID == "" {
+		project.GroupID = nil
+		project.GroupOverrides = pq.StringArray{}
+		return true
+	}
+	gs, ok := h.store.(projectGroupStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("project groups not available"))
+		return false
+	}
+	if _, err := gs.GetProjectGroup(r.Context(), groupID); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return false
+	}
+	project.GroupID = &groupID
+	return true
+}
+
+// trackGroupOverrides records the inheritable fields a request set on a
+// grouped project as overrides, so the group's defaults no longer replace
+// them, and drops the overrides named in inherit.
+func trackGroupOverrides(project *models.Project, set map[string]bool, inherit []string) {
+	if project.GroupID == nil {
+		return
+	}
+	for _, field := range models.InheritableProjectFields {
+		if set[field] {
+			project.SetGroupOverride(field, true)
+		}
+	}
+	for _, field := range inherit {
+		project.SetGroupOverride(field, false)
+	}
+}
+
+// withGroupDefaults returns project as the store now reads it, with its
+// group's defaults applied, or project itself if it isn't grouped or can't
+// be reread.
+func (h *ProjectHandler) withGroupDefaults(ctx context.Context, project *models.Project) *models.Project {
+	if project.GroupID == nil {
+		return project
+	}
+	reread, err := h.store.GetProjectByID(ctx, project.ProjectID)
+	if err != nil || reread == nil {
+		return project
+	}
+	return reread
+}
+
+// parseListPagination parses limit (default 20, at most 100) and offset.
+func parseListPagination(r *http.Request) (limit, offset int) {
+	limit = 20
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+	if o, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && o >= 0 {
+		offset = o
+	}
+	return limit, offset
+}
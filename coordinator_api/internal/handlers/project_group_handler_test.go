@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// projectGroupMockStore keeps groups in memory on top of ProjectMockStore,
+// with the projectGroupStore capability.
+type projectGroupMockStore struct {
+	ProjectMockStore
+	groups map[string]*models.ProjectGroup
+}
+
+func newProjectGroupMockStore() *projectGroupMockStore {
+	image := "golang:1.22"
+	return &projectGroupMockStore{groups: map[string]*models.ProjectGroup{
+		"group-1": {GroupID: "group-1", Name: "services", DefaultRunnerImage: &image},
+	}}
+}
+
+func (m *projectGroupMockStore) CreateProjectGroup(ctx context.Context, group *models.ProjectGroup) error {
+	for _, g := range m.groups {
+		if g.Name == group.Name {
+			return store.ErrAlreadyExists
+		}
+	}
+	group.GroupID = "group-new"
+	m.groups[group.GroupID] = group
+	return nil
+}
+
+func (m *projectGroupMockStore) GetProjectGroup(ctx context.Context, groupID string) (*models.ProjectGroup, error) {
+	g, ok := m.groups[groupID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	cp := *g
+	return &cp, nil
+}
+
+func (m *projectGroupMockStore) ListProjectGroups(ctx context.Context, limit, offset int) ([]models.ProjectGroup, error) {
+	var groups []models.ProjectGroup
+	for _, g := range m.groups {
+		groups = append(groups, *g)
+	}
+	return groups, nil
+}
+
+func (m *projectGroupMockStore) UpdateProjectGroup(ctx context.Context, group *models.ProjectGroup) error {
+	m.groups[group.GroupID] = group
+	return nil
+}
+
+func (m *projectGroupMockStore) DeleteProjectGroup(ctx context.Context, groupID string) error {
+	if _, ok := m.groups[groupID]; !ok {
+		return store.ErrNotFound
+	}
+	delete(m.groups, groupID)
+	return nil
+}
+
+func (m *projectGroupMockStore) ListProjectsInGroup(ctx context.Context, groupID string, limit, offset int) ([]models.Project, error) {
+	return nil, nil
+}
+
+func groupRequest(method, target, groupID, body string) *http.Request {
+	req := withUser(httptest.NewRequest(method, target, strings.NewReader(body)))
+	if groupID != "" {
+		req = req.WithContext(setIDContext(req.Context(), "group_id", groupID))
+	}
+	return req
+}
+
+func TestProjectHandler_CreateProjectGroup(t *testing.T) {
+	create := func(st *projectGroupMockStore, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		NewProjectHandler(st).CreateProjectGroup(w, groupRequest(http.MethodPost, "/api/v1/project-groups", "", body))
+		return w
+	}
+
+	t.Run("success", func(t *testing.T) {
+		st := newProjectGroupMockStore()
+		w := create(st, `{"name":"frontends","default_timeout_seconds":600,"allowed_event_types":["push"]}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var group models.ProjectGroup
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &group))
+		assert.Equal(t, "frontends", group.Name)
+		require.NotNil(t, group.DefaultTimeoutSeconds)
+		assert.Equal(t, 600, *group.DefaultTimeoutSeconds)
+		assert.Equal(t, []string{models.ProjectFieldTimeoutSeconds, models.ProjectFieldAllowedEventTypes}, group.SetFields())
+	})
+
+	t.Run("name required", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, create(newProjectGroupMockStore(), `{"description":"x"}`).Code)
+	})
+
+	t.Run("non-positive timeout rejected", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, create(newProjectGroupMockStore(), `{"name":"x","default_timeout_seconds":0}`).Code)
+	})
+
+	t.Run("duplicate name conflicts", func(t *testing.T) {
+		assert.Equal(t, http.StatusConflict, create(newProjectGroupMockStore(), `{"name":"services"}`).Code)
+	})
+}
+
+func TestProjectHandler_UpdateProjectGroup(t *testing.T) {
+	update := func(st *projectGroupMockStore, groupID, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		NewProjectHandler(st).UpdateProjectGroup(w, groupRequest(http.MethodPut, "/api/v1/project-groups/"+groupID, groupID, body))
+		return w
+	}
+
+	t.Run("clear_defaults unsets a default", func(t *testing.T) {
+		st := newProjectGroupMockStore()
+		w := update(st, "group-1", `{"default_queue_name":"reactorcide-jobs-linux-arm64","clear_defaults":["default_runner_image"]}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Nil(t, st.groups["group-1"].DefaultRunnerImage)
+		require.NotNil(t, st.groups["group-1"].DefaultQueueName)
+		assert.Equal(t, "reactorcide-jobs-linux-arm64", *st.groups["group-1"].DefaultQueueName)
+	})
+
+	t.Run("unknown clear_defaults field rejected", func(t *testing.T) {
+		st := newProjectGroupMockStore()
+		w := update(st, "group-1", `{"clear_defaults":["repo_url"]}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.NotNil(t, st.groups["group-1"].DefaultRunnerImage)
+	})
+
+	t.Run("unknown group", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, update(newProjectGroupMockStore(), "group-2", `{}`).Code)
+	})
+}
+
+func TestProjectHandler_DeleteProjectGroup(t *testing.T) {
+	st := newProjectGroupMockStore()
+	w := httptest.NewRecorder()
+	NewProjectHandler(st).DeleteProjectGroup(w, groupRequest(http.MethodDelete, "/api/v1/project-groups/group-1", "group-1", ""))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, st.groups)
+}
+
+func TestProjectHandler_UpdateProject_GroupOverrides(t *testing.T) {
+	newStore := func(project *models.Project) *projectGroupMockStore {
+		st := newProjectGroupMockStore()
+		st.GetProjectByIDFunc = func(ctx context.Context, id string) (*models.Project, error) {
+			p := *project
+			return &p, nil
+		}
+		st.UpdateProjectFunc = func(ctx context.Context, p *models.Project) error {
+			*project = *p
+			return nil
+		}
+		return st
+	}
+	update := func(st *projectGroupMockStore, projectID, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := withProjectID(withUser(httptest.NewRequest(http.MethodPut, "/api/v1/projects/"+projectID, strings.NewReader(body))), projectID)
+		NewProjectHandler(st).UpdateProject(w, req)
+		return w
+	}
+
+	t.Run("joining a group", func(t *testing.T) {
+		project := testProject("project-1")
+		st := newStore(project)
+		w := update(st, "project-1", `{"group_id":"group-1","default_timeout_seconds":120}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NotNil(t, project.GroupID)
+		assert.Equal(t, "group-1", *project.GroupID)
+		assert.Equal(t, []string{models.ProjectFieldTimeoutSeconds}, []string(project.GroupOverrides))
+	})
+
+	t.Run("unknown group rejected", func(t *testing.T) {
+		project := testProject("project-1")
+		w := update(newStore(project), "project-1", `{"group_id":"group-2"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Nil(t, project.GroupID)
+	})
+
+	t.Run("inherit_fields drops an override", func(t *testing.T) {
+		groupID := "group-1"
+		project := testProject("project-1")
+		project.GroupID = &groupID
+		project.GroupOverrides = []string{models.ProjectFieldRunnerImage, models.ProjectFieldQueueName}
+		w := update(newStore(project), "project-1", `{"inherit_fields":["default_runner_image"]}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []string{models.ProjectFieldQueueName}, []string(project.GroupOverrides))
+	})
+
+	t.Run("unknown inherit_fields field rejected", func(t *testing.T) {
+		groupID := "group-1"
+		project := testProject("project-1")
+		project.GroupID = &groupID
+		assert.Equal(t, http.StatusBadRequest, update(newStore(project), "project-1", `{"inherit_fields":["name"]}`).Code)
+	})
+
+	t.Run("leaving a group clears overrides", func(t *testing.T) {
+		groupID := "group-1"
+		project := testProject("project-1")
+		project.GroupID = &groupID
+		project.GroupOverrides = []string{models.ProjectFieldQueueName}
+		w := update(newStore(project), "project-1", `{"group_id":""}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Nil(t, project.GroupID)
+		assert.Empty(t, project.GroupOverrides)
+	})
+}
@@ -2,15 +2,17 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/repourl"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/worker"
 )
 
 // ProjectHandler handles project CRUD operations
@@ -37,10 +39,18 @@ type CreateProjectRequest struct {
 	Name        string `json:"name"`
 	Description string `json:"description,omitempty"`
 	RepoURL     string `json:"repo_url"`
+	// ConfigPath and PathFilters let several projects share a repository.
+	// See models.Project.
+	ConfigPath  string   `json:"config_path,omitempty"`
+	PathFilters []string `json:"path_filters,omitempty"`
 
 	Enabled           *bool    `json:"enabled,omitempty"`
 	TargetBranches    []string `json:"target_branches,omitempty"`
+	TagPatterns       []string `json:"tag_patterns,omitempty"`
 	AllowedEventTypes []string `json:"allowed_event_types,omitempty"`
+	DraftPRPolicy     *string  `json:"draft_pr_policy,omitempty"`
+	WIPTitlePatterns  []string `json:"wip_title_patterns,omitempty"`
+	RunParameters     []string `json:"run_parameters,omitempty"`
 
 	DefaultCISourceType string `json:"default_ci_source_type,omitempty"`
 	DefaultCISourceURL  string `json:"default_ci_source_url,omitempty"`
@@ -50,22 +60,79 @@ type CreateProjectRequest struct {
 	DefaultJobCommand     string `json:"default_job_command,omitempty"`
 	DefaultTimeoutSeconds *int   `json:"default_timeout_seconds,omitempty"`
 	DefaultQueueName      string `json:"default_queue_name,omitempty"`
+	// SpilloverQueues take the project's jobs, in order, when its default
+	// queue has no free worker.
+	SpilloverQueues []string `json:"spillover_queues,omitempty"`
 
 	VCSTokenSecret       string            `json:"vcs_token_secret,omitempty"`
 	VCSCredentialSecrets map[string]string `json:"vcs_token_secrets,omitempty"`
 	WebhookSecret        string            `json:"webhook_secret,omitempty"`
 	WebhookSecrets       map[string]string `json:"webhook_secrets,omitempty"`
+
+	StrictTriggerValidation *bool `json:"strict_trigger_validation,omitempty"`
+	AwaitChildJobs          *bool `json:"await_child_jobs,omitempty"`
+
+	// CoalesceWindowSeconds coalesces pushes to a branch this close
+	// together; 0 turns it off.
+	CoalesceWindowSeconds *int `json:"coalesce_window_seconds,omitempty"`
+
+	// Stuck-job policy overrides; see models.StuckJobPolicy.
+	StuckJobAction        *string  `json:"stuck_job_action,omitempty"`
+	StuckJobNoLogMinutes  *int     `json:"stuck_job_no_log_minutes,omitempty"`
+	StuckJobP95Multiplier *float64 `json:"stuck_job_p95_multiplier,omitempty"`
+
+	// JobTemplates are created directly for the project's VCS events,
+	// with no eval job; see models.JobTemplate.
+	JobTemplates models.JobTemplates `json:"job_templates,omitempty"`
+
+	// QualityGates are checked against the outputs of the project's jobs;
+	// see models.QualityGate.
+	QualityGates models.QualityGates `json:"quality_gates,omitempty"`
+
+	// ArtifactPublishTargets are where the project's completed jobs push
+	// their artifacts; see models.ArtifactPublishTarget.
+	ArtifactPublishTargets models.ArtifactPublishTargets `json:"artifact_publish_targets,omitempty"`
+
+	// ScanThresholds limits the findings of the project's scan jobs by
+	// severity; see models.ScanThresholds.
+	ScanThresholds models.ScanThresholds `json:"scan_thresholds,omitempty"`
+
+	// ReleaseConfig publishes releases for the project's tags; see
+	// models.ReleaseConfig.
+	ReleaseConfig *models.ReleaseConfig `json:"release_config,omitempty"`
+
+	// PromotionRules governs promoting the project's deploys between
+	// environments; see models.PromotionRules.
+	PromotionRules models.PromotionRules `json:"promotion_rules,omitempty"`
+
+	// StatusContextTemplate names the commit statuses of the project's
+	// jobs; see models.ValidateStatusContextTemplate.
+	StatusContextTemplate string `json:"status_context_template,omitempty"`
+
+	// StatusContextAliases are old status contexts still reported; see
+	// models.StatusContextAliases.
+	StatusContextAliases models.StatusContextAliases `json:"status_context_aliases,omitempty"`
+
+	// GroupID puts the project in a project group. The inheritable fields
+	// set here override the group's defaults.
+	GroupID string `json:"group_id,omitempty"`
 }
 
 // UpdateProjectRequest represents the request body for updating a project
 type UpdateProjectRequest struct {
-	Name        *string `json:"name,omitempty"`
-	Description *string `json:"description,omitempty"`
-	RepoURL     *string `json:"repo_url,omitempty"`
+	Name        *string  `json:"name,omitempty"`
+	Description *string  `json:"description,omitempty"`
+	RepoURL     *string  `json:"repo_url,omitempty"`
+	ConfigPath  *string  `json:"config_path,omitempty"`
+	PathFilters []string `json:"path_filters,omitempty"`
 
 	Enabled           *bool    `json:"enabled,omitempty"`
 	TargetBranches    []string `json:"target_branches,omitempty"`
+	TagPatterns       []string `json:"tag_patterns,omitempty"`
 	AllowedEventTypes []string `json:"allowed_event_types,omitempty"`
+	DraftPRPolicy     *string  `json:"draft_pr_policy,omitempty"`
+	WIPTitlePatterns  []string `json:"wip_title_patterns,omitempty"`
+	RunParameters     []string `json:"run_parameters,omitempty"`
 
 	DefaultCISourceType *string `json:"default_ci_source_type,omitempty"`
 	DefaultCISourceURL  *string `json:"default_ci_source_url,omitempty"`
@@ -75,11 +142,61 @@ type UpdateProjectRequest struct {
 	DefaultJobCommand     *string `json:"default_job_command,omitempty"`
 	DefaultTimeoutSeconds *int    `json:"default_timeout_seconds,omitempty"`
 	DefaultQueueName      *string `json:"default_queue_name,omitempty"`
+	// SpilloverQueues replaces the project's spillover queues; [] removes
+	// them.
+	SpilloverQueues *[]string `json:"spillover_queues,omitempty"`
 
 	VCSTokenSecret       *string           `json:"vcs_token_secret,omitempty"`
 	VCSCredentialSecrets map[string]string `json:"vcs_token_secrets,omitempty"`
 	WebhookSecret        *string           `json:"webhook_secret,omitempty"`
 	WebhookSecrets       map[string]string `json:"webhook_secrets,omitempty"`
+
+	StrictTriggerValidation *bool `json:"strict_trigger_validation,omitempty"`
+	AwaitChildJobs          *bool `json:"await_child_jobs,omitempty"`
+
+	// CoalesceWindowSeconds coalesces pushes to a branch this close
+	// together; 0 turns it off.
+	CoalesceWindowSeconds *int `json:"coalesce_window_seconds,omitempty"`
+
+	// Stuck-job policy overrides; see models.StuckJobPolicy.
+	StuckJobAction        *string  `json:"stuck_job_action,omitempty"`
+	StuckJobNoLogMinutes  *int     `json:"stuck_job_no_log_minutes,omitempty"`
+	StuckJobP95Multiplier *float64 `json:"stuck_job_p95_multiplier,omitempty"`
+
+	// JobTemplates replaces the project's job templates; [] removes them,
+	// going back to eval jobs.
+	JobTemplates models.JobTemplates `json:"job_templates,omitempty"`
+
+	// QualityGates replaces the project's quality gates; [] removes them.
+	QualityGates models.QualityGates `json:"quality_gates,omitempty"`
+
+	// ArtifactPublishTargets replaces the project's artifact publish
+	// targets; [] removes them.
+	ArtifactPublishTargets models.ArtifactPublishTargets `json:"artifact_publish_targets,omitempty"`
+
+	// ScanThresholds replaces the project's scan thresholds; {} removes them.
+	ScanThresholds models.ScanThresholds `json:"scan_thresholds,omitempty"`
+
+	// ReleaseConfig replaces the project's release settings.
+	ReleaseConfig *models.ReleaseConfig `json:"release_config,omitempty"`
+
+	// PromotionRules replaces the project's promotion rules; {} removes them.
+	PromotionRules models.PromotionRules `json:"promotion_rules,omitempty"`
+
+	// StatusContextTemplate replaces the project's status context
+	// template; "" goes back to the built-in names.
+	StatusContextTemplate *string `json:"status_context_template,omitempty"`
+
+	// StatusContextAliases replaces the project's status context aliases;
+	// {} removes them.
+	StatusContextAliases models.StatusContextAliases `json:"status_context_aliases,omitempty"`
+
+	// GroupID moves the project into a project group, or out of its group
+	// when "". Inheritable fields set in a grouped project's update
+	// override the group's defaults; InheritFields drops overrides, by
+	// field name, going back to the group's value.
+	GroupID       *string  `json:"group_id,omitempty"`
+	InheritFields []string `json:"inherit_fields,omitempty"`
 }
 
 // ProjectResponse represents the response body for a project
@@ -91,24 +208,63 @@ type ProjectResponse struct {
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
 	RepoURL     string    `json:"repo_url"`
+	ConfigPath  string    `json:"config_path"`
+	PathFilters []string  `json:"path_filters"`
 
 	Enabled           bool     `json:"enabled"`
 	TargetBranches    []string `json:"target_branches"`
+	TagPatterns       []string `json:"tag_patterns"`
 	AllowedEventTypes []string `json:"allowed_event_types"`
+	DraftPRPolicy     string   `json:"draft_pr_policy"`
+	WIPTitlePatterns  []string `json:"wip_title_patterns"`
+	RunParameters     []string `json:"run_parameters"`
 
 	DefaultCISourceType string `json:"default_ci_source_type"`
 	DefaultCISourceURL  string `json:"default_ci_source_url,omitempty"`
 	DefaultCISourceRef  string `json:"default_ci_source_ref"`
 
-	DefaultRunnerImage    string `json:"default_runner_image"`
-	DefaultJobCommand     string `json:"default_job_command,omitempty"`
-	DefaultTimeoutSeconds int    `json:"default_timeout_seconds"`
-	DefaultQueueName      string `json:"default_queue_name"`
+	DefaultRunnerImage    string   `json:"default_runner_image"`
+	DefaultJobCommand     string   `json:"default_job_command,omitempty"`
+	DefaultTimeoutSeconds int      `json:"default_timeout_seconds"`
+	DefaultQueueName      string   `json:"default_queue_name"`
+	SpilloverQueues       []string `json:"spillover_queues,omitempty"`
+
+	Canary *models.ProjectCanary `json:"canary,omitempty"`
 
 	VCSTokenSecret       string            `json:"vcs_token_secret,omitempty"`
 	VCSCredentialSecrets map[string]string `json:"vcs_token_secrets,omitempty"`
 	WebhookSecret        string            `json:"webhook_secret,omitempty"`
 	WebhookSecrets       map[string]string `json:"webhook_secrets,omitempty"`
+
+	StrictTriggerValidation bool `json:"strict_trigger_validation"`
+	AwaitChildJobs          bool `json:"await_child_jobs"`
+	CoalesceWindowSeconds   int  `json:"coalesce_window_seconds"`
+
+	StuckJobAction        *string  `json:"stuck_job_action,omitempty"`
+	StuckJobNoLogMinutes  *int     `json:"stuck_job_no_log_minutes,omitempty"`
+	StuckJobP95Multiplier *float64 `json:"stuck_job_p95_multiplier,omitempty"`
+
+	JobTemplates   models.JobTemplates   `json:"job_templates"`
+	QualityGates   models.QualityGates   `json:"quality_gates"`
+	ScanThresholds models.ScanThresholds `json:"scan_thresholds"`
+	ReleaseConfig  models.ReleaseConfig  `json:"release_config"`
+	PromotionRules models.PromotionRules `json:"promotion_rules"`
+
+	ArtifactPublishTargets models.ArtifactPublishTargets `json:"artifact_publish_targets"`
+
+	StatusContextTemplate string                      `json:"status_context_template"`
+	StatusContextAliases  models.StatusContextAliases `json:"status_context_aliases"`
+
+	GroupID         *string  `json:"group_id,omitempty"`
+	GroupOverrides  []string `json:"group_overrides,omitempty"`
+	InheritedFields []string `json:"inherited_fields,omitempty"`
+
+	// LintWarnings are what the last create or update found wrong in the
+	// project's configuration (see lintProject). They don't stop a save.
+	LintWarnings models.ProjectLintWarnings `json:"lint_warnings"`
+
+	// LatestNote is the newest note on the project (list responses only).
+	LatestNote *models.Note `json:"latest_note,omitempty"`
 }
 
 // ListProjectsResponse represents the response body for listing projects
@@ -161,8 +317,14 @@ func projectToResponse(p *models.Project) ProjectResponse {
 		Name:                  p.Name,
 		Description:           p.Description,
 		RepoURL:               p.RepoURL,
+		ConfigPath:            p.ConfigPath,
+		PathFilters:           p.PathFilters,
 		Enabled:               p.Enabled,
 		TargetBranches:        p.TargetBranches,
+		TagPatterns:           p.TagPatterns,
+		DraftPRPolicy:         p.DraftPRPolicy,
+		WIPTitlePatterns:      p.WIPTitlePatterns,
+		RunParameters:         p.RunParameters,
 		AllowedEventTypes:     p.AllowedEventTypes,
 		DefaultCISourceType:   string(p.DefaultCISourceType),
 		DefaultCISourceURL:    p.DefaultCISourceURL,
@@ -171,13 +333,49 @@ func projectToResponse(p *models.Project) ProjectResponse {
 		DefaultJobCommand:     p.DefaultJobCommand,
 		DefaultTimeoutSeconds: p.DefaultTimeoutSeconds,
 		DefaultQueueName:      p.DefaultQueueName,
+		SpilloverQueues:       p.SpilloverQueues,
+		Canary:                p.Canary,
 		VCSTokenSecret:        p.VCSTokenSecret,
 		VCSCredentialSecrets:  jsonbStringMap(p.VCSCredentialSecrets),
 		WebhookSecret:         p.WebhookSecret,
 		WebhookSecrets:        jsonbStringMap(p.WebhookSecrets),
+
+		StrictTriggerValidation: p.StrictTriggerValidation,
+		AwaitChildJobs:          p.AwaitChildJobs,
+		CoalesceWindowSeconds:   p.CoalesceWindowSeconds,
+
+		StuckJobAction:        p.StuckJobAction,
+		StuckJobNoLogMinutes:  p.StuckJobNoLogMinutes,
+		StuckJobP95Multiplier: p.StuckJobP95Multiplier,
+
+		JobTemplates:   p.JobTemplates,
+		QualityGates:   p.QualityGates,
+		ScanThresholds: p.ScanThresholds,
+		ReleaseConfig:  p.ReleaseConfig,
+		PromotionRules: p.PromotionRules,
+
+		ArtifactPublishTargets: p.ArtifactPublishTargets,
+
+		StatusContextTemplate: p.StatusContextTemplate,
+		StatusContextAliases:  p.StatusContextAliases,
+
+		GroupID:         p.GroupID,
+		GroupOverrides:  p.GroupOverrides,
+		InheritedFields: p.InheritedFields,
+
+		LintWarnings: lintWarningsOrEmpty(p.LintWarnings),
 	}
 }
 
+// lintWarningsOrEmpty returns warnings, or an empty list for nil, so
+// responses always carry a list.
+func lintWarningsOrEmpty(warnings models.ProjectLintWarnings) models.ProjectLintWarnings {
+	if warnings == nil {
+		return models.ProjectLintWarnings{}
+	}
+	return warnings
+}
+
 // CreateProject handles POST /api/v1/projects
 func (h *ProjectHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
 	user := checkauth.GetUserFromContext(r.Context())
@@ -187,8 +385,7 @@ func (h *ProjectHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req CreateProjectRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+	if !h.decodeJSON(w, r, &req) {
 		return
 	}
 
@@ -196,23 +393,124 @@ func (h *ProjectHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
 		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
 		return
 	}
+	if !h.checkPayloadLimits(w, worker.CheckJobPayloadLimits(req.Name, req.Description, "", nil)) {
+		return
+	}
+	if !h.checkSourceURLs(w, r, h.store, req.DefaultCISourceURL) {
+		return
+	}
+
+	configPath, err := models.ValidateConfigPath(req.ConfigPath)
+	if err != nil {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+	if models.ValidatePathFilters(req.PathFilters) != nil {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
 
 	project := &models.Project{
 		Name:        req.Name,
 		Description: req.Description,
-		RepoURL:     req.RepoURL,
+		RepoURL:     repourl.Normalize(req.RepoURL),
+		ConfigPath:  configPath,
+		PathFilters: req.PathFilters,
 		UserID:      &user.UserID,
 	}
+	if project.PathFilters == nil {
+		project.PathFilters = []string{}
+	}
 
 	if req.Enabled != nil {
 		project.Enabled = *req.Enabled
 	}
+	if err := validateRefFilters(req.TargetBranches, req.TagPatterns); err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+		return
+	}
 	if req.TargetBranches != nil {
 		project.TargetBranches = req.TargetBranches
 	}
+	if req.TagPatterns != nil {
+		project.TagPatterns = req.TagPatterns
+	}
 	if req.AllowedEventTypes != nil {
 		project.AllowedEventTypes = req.AllowedEventTypes
 	}
+	if err := validateDraftPRPolicy(req.DraftPRPolicy, req.WIPTitlePatterns); err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+		return
+	}
+	if req.DraftPRPolicy != nil {
+		project.DraftPRPolicy = *req.DraftPRPolicy
+	}
+	if req.WIPTitlePatterns != nil {
+		project.WIPTitlePatterns = req.WIPTitlePatterns
+	}
+	if req.RunParameters != nil {
+		if err := models.ValidateRunParameters(req.RunParameters); err != nil {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+			return
+		}
+		project.RunParameters = req.RunParameters
+	}
+	if req.JobTemplates != nil {
+		if err := req.JobTemplates.Validate(); err != nil {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+			return
+		}
+		project.JobTemplates = req.JobTemplates
+	}
+	if req.QualityGates != nil {
+		if err := req.QualityGates.Validate(); err != nil {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+			return
+		}
+		project.QualityGates = req.QualityGates
+	}
+	if req.ArtifactPublishTargets != nil {
+		if err := req.ArtifactPublishTargets.Validate(); err != nil {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+			return
+		}
+		project.ArtifactPublishTargets = req.ArtifactPublishTargets
+	}
+	if req.ScanThresholds != nil {
+		if err := req.ScanThresholds.Validate(); err != nil {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+			return
+		}
+		project.ScanThresholds = req.ScanThresholds
+	}
+	if req.ReleaseConfig != nil {
+		if err := req.ReleaseConfig.Validate(); err != nil {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+			return
+		}
+		project.ReleaseConfig = *req.ReleaseConfig
+	}
+	if req.PromotionRules != nil {
+		if err := req.PromotionRules.Validate(); err != nil {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+			return
+		}
+		project.PromotionRules = req.PromotionRules
+	}
+	if req.StatusContextTemplate != "" {
+		if err := models.ValidateStatusContextTemplate(req.StatusContextTemplate); err != nil {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+			return
+		}
+		project.StatusContextTemplate = req.StatusContextTemplate
+	}
+	if req.StatusContextAliases != nil {
+		if err := req.StatusContextAliases.Validate(); err != nil {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+			return
+		}
+		project.StatusContextAliases = req.StatusContextAliases
+	}
 	if req.DefaultCISourceType != "" {
 		project.DefaultCISourceType = models.SourceType(req.DefaultCISourceType)
 	}
@@ -234,6 +532,13 @@ func (h *ProjectHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
 	if req.DefaultQueueName != "" {
 		project.DefaultQueueName = req.DefaultQueueName
 	}
+	if len(req.SpilloverQueues) > 0 {
+		if err := models.ValidateSpilloverQueues(project.DefaultQueueName, req.SpilloverQueues); err != nil {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+			return
+		}
+		project.SpilloverQueues = req.SpilloverQueues
+	}
 	if req.VCSTokenSecret != "" {
 		project.VCSTokenSecret = req.VCSTokenSecret
 	}
@@ -246,13 +551,52 @@ func (h *ProjectHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
 	if req.WebhookSecrets != nil {
 		project.WebhookSecrets = stringMapJSONB(req.WebhookSecrets)
 	}
+	if req.StrictTriggerValidation != nil {
+		project.StrictTriggerValidation = *req.StrictTriggerValidation
+	}
+	if req.AwaitChildJobs != nil {
+		project.AwaitChildJobs = *req.AwaitChildJobs
+	}
+	if req.CoalesceWindowSeconds != nil {
+		if *req.CoalesceWindowSeconds < 0 || *req.CoalesceWindowSeconds > models.MaxCoalesceWindowSeconds {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: fmt.Sprintf("coalesce_window_seconds must be between 0 and %d", models.MaxCoalesceWindowSeconds)})
+			return
+		}
+		project.CoalesceWindowSeconds = *req.CoalesceWindowSeconds
+	}
+	if err := validateStuckJobOverrides(req.StuckJobAction, req.StuckJobNoLogMinutes, req.StuckJobP95Multiplier); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, err)
+		return
+	}
+	project.StuckJobAction = req.StuckJobAction
+	project.StuckJobNoLogMinutes = req.StuckJobNoLogMinutes
+	project.StuckJobP95Multiplier = req.StuckJobP95Multiplier
+	if req.GroupID != "" {
+		if !h.setProjectGroup(w, r, project, req.GroupID) {
+			return
+		}
+		trackGroupOverrides(project, map[string]bool{
+			models.ProjectFieldRunnerImage:       req.DefaultRunnerImage != "",
+			models.ProjectFieldTimeoutSeconds:    req.DefaultTimeoutSeconds != nil,
+			models.ProjectFieldQueueName:         req.DefaultQueueName != "",
+			models.ProjectFieldAllowedEventTypes: req.AllowedEventTypes != nil,
+			models.ProjectFieldVCSTokenSecret:    req.VCSTokenSecret != "",
+			models.ProjectFieldWebhookSecret:     req.WebhookSecret != "",
+		}, nil)
+	}
+
+	if !h.checkAdmission(w, r, h.store, models.PolicyTargetProject, project.ProjectID, projectToResponse(project)) {
+		return
+	}
 
 	if err := h.store.CreateProject(r.Context(), project); err != nil {
 		h.respondWithError(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	h.respondWithJSON(w, http.StatusCreated, projectToResponse(project))
+	saved := h.withGroupDefaults(r.Context(), project)
+	h.lintAndStoreProject(r.Context(), saved)
+	h.respondWithJSON(w, http.StatusCreated, projectToResponse(saved))
 }
 
 // GetProject handles GET /api/v1/projects/{project_id}
@@ -302,9 +646,17 @@ func (h *ProjectHandler) ListProjects(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	projectIDs := make([]string, len(projects))
+	for i := range projects {
+		projectIDs[i] = projects[i].ProjectID
+	}
+	notes := latestNotes(r.Context(), h.store, models.NoteTargetProject, projectIDs)
 	responses := make([]ProjectResponse, len(projects))
 	for i := range projects {
 		responses[i] = projectToResponse(&projects[i])
+		if note, ok := notes[projects[i].ProjectID]; ok {
+			responses[i].LatestNote = &note
+		}
 	}
 
 	h.respondWithJSON(w, http.StatusOK, ListProjectsResponse{
@@ -336,8 +688,7 @@ func (h *ProjectHandler) UpdateProject(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req UpdateProjectRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+	if !h.decodeJSON(w, r, &req) {
 		return
 	}
 
@@ -348,21 +699,124 @@ func (h *ProjectHandler) UpdateProject(w http.ResponseWriter, r *http.Request) {
 		project.Description = *req.Description
 	}
 	if req.RepoURL != nil {
-		project.RepoURL = *req.RepoURL
+		project.RepoURL = repourl.Normalize(*req.RepoURL)
+	}
+	if req.ConfigPath != nil {
+		configPath, err := models.ValidateConfigPath(*req.ConfigPath)
+		if err != nil {
+			h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+			return
+		}
+		project.ConfigPath = configPath
+	}
+	if req.PathFilters != nil {
+		if models.ValidatePathFilters(req.PathFilters) != nil {
+			h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+			return
+		}
+		project.PathFilters = req.PathFilters
 	}
 	if req.Enabled != nil {
 		project.Enabled = *req.Enabled
 	}
+	if !h.checkPayloadLimits(w, worker.CheckJobPayloadLimits(project.Name, project.Description, "", nil)) {
+		return
+	}
+	if err := validateRefFilters(req.TargetBranches, req.TagPatterns); err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+		return
+	}
 	if req.TargetBranches != nil {
 		project.TargetBranches = req.TargetBranches
 	}
+	if req.TagPatterns != nil {
+		project.TagPatterns = req.TagPatterns
+	}
 	if req.AllowedEventTypes != nil {
 		project.AllowedEventTypes = req.AllowedEventTypes
 	}
+	if err := validateDraftPRPolicy(req.DraftPRPolicy, req.WIPTitlePatterns); err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+		return
+	}
+	if req.DraftPRPolicy != nil {
+		project.DraftPRPolicy = *req.DraftPRPolicy
+	}
+	if req.WIPTitlePatterns != nil {
+		project.WIPTitlePatterns = req.WIPTitlePatterns
+	}
+	if req.RunParameters != nil {
+		if err := models.ValidateRunParameters(req.RunParameters); err != nil {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+			return
+		}
+		project.RunParameters = req.RunParameters
+	}
+	if req.JobTemplates != nil {
+		if err := req.JobTemplates.Validate(); err != nil {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+			return
+		}
+		project.JobTemplates = req.JobTemplates
+	}
+	if req.QualityGates != nil {
+		if err := req.QualityGates.Validate(); err != nil {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+			return
+		}
+		project.QualityGates = req.QualityGates
+	}
+	if req.ArtifactPublishTargets != nil {
+		if err := req.ArtifactPublishTargets.Validate(); err != nil {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+			return
+		}
+		project.ArtifactPublishTargets = req.ArtifactPublishTargets
+	}
+	if req.ScanThresholds != nil {
+		if err := req.ScanThresholds.Validate(); err != nil {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+			return
+		}
+		project.ScanThresholds = req.ScanThresholds
+	}
+	if req.ReleaseConfig != nil {
+		if err := req.ReleaseConfig.Validate(); err != nil {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+			return
+		}
+		project.ReleaseConfig = *req.ReleaseConfig
+	}
+	if req.PromotionRules != nil {
+		if err := req.PromotionRules.Validate(); err != nil {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+			return
+		}
+		project.PromotionRules = req.PromotionRules
+	}
+	if req.StatusContextTemplate != nil {
+		if *req.StatusContextTemplate != "" {
+			if err := models.ValidateStatusContextTemplate(*req.StatusContextTemplate); err != nil {
+				h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+				return
+			}
+		}
+		project.StatusContextTemplate = *req.StatusContextTemplate
+	}
+	if req.StatusContextAliases != nil {
+		if err := req.StatusContextAliases.Validate(); err != nil {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+			return
+		}
+		project.StatusContextAliases = req.StatusContextAliases
+	}
 	if req.DefaultCISourceType != nil {
 		project.DefaultCISourceType = models.SourceType(*req.DefaultCISourceType)
 	}
 	if req.DefaultCISourceURL != nil {
+		if !h.checkSourceURLs(w, r, h.store, *req.DefaultCISourceURL) {
+			return
+		}
 		project.DefaultCISourceURL = *req.DefaultCISourceURL
 	}
 	if req.DefaultCISourceRef != nil {
@@ -380,6 +834,13 @@ func (h *ProjectHandler) UpdateProject(w http.ResponseWriter, r *http.Request) {
 	if req.DefaultQueueName != nil {
 		project.DefaultQueueName = *req.DefaultQueueName
 	}
+	if req.SpilloverQueues != nil {
+		project.SpilloverQueues = *req.SpilloverQueues
+	}
+	if err := models.ValidateSpilloverQueues(project.DefaultQueueName, project.SpilloverQueues); err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+		return
+	}
 	if req.VCSTokenSecret != nil {
 		project.VCSTokenSecret = *req.VCSTokenSecret
 	}
@@ -392,13 +853,93 @@ func (h *ProjectHandler) UpdateProject(w http.ResponseWriter, r *http.Request) {
 	if req.WebhookSecrets != nil {
 		project.WebhookSecrets = stringMapJSONB(req.WebhookSecrets)
 	}
+	if req.StrictTriggerValidation != nil {
+		project.StrictTriggerValidation = *req.StrictTriggerValidation
+	}
+	if req.AwaitChildJobs != nil {
+		project.AwaitChildJobs = *req.AwaitChildJobs
+	}
+	if req.CoalesceWindowSeconds != nil {
+		if *req.CoalesceWindowSeconds < 0 || *req.CoalesceWindowSeconds > models.MaxCoalesceWindowSeconds {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: fmt.Sprintf("coalesce_window_seconds must be between 0 and %d", models.MaxCoalesceWindowSeconds)})
+			return
+		}
+		project.CoalesceWindowSeconds = *req.CoalesceWindowSeconds
+	}
+	if req.StuckJobAction != nil && *req.StuckJobAction == "" {
+		// An empty action clears the override back to the global policy.
+		project.StuckJobAction = nil
+		req.StuckJobAction = nil
+	} else if req.StuckJobAction != nil {
+		project.StuckJobAction = req.StuckJobAction
+	}
+	if err := validateStuckJobOverrides(req.StuckJobAction, req.StuckJobNoLogMinutes, req.StuckJobP95Multiplier); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.StuckJobNoLogMinutes != nil {
+		project.StuckJobNoLogMinutes = req.StuckJobNoLogMinutes
+	}
+	if req.StuckJobP95Multiplier != nil {
+		project.StuckJobP95Multiplier = req.StuckJobP95Multiplier
+	}
+	for _, field := range req.InheritFields {
+		if !models.IsInheritableProjectField(field) {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_input",
+				Message: "unknown project field " + field,
+			})
+			return
+		}
+	}
+	if req.GroupID != nil && !h.setProjectGroup(w, r, project, *req.GroupID) {
+		return
+	}
+	trackGroupOverrides(project, map[string]bool{
+		models.ProjectFieldRunnerImage:       req.DefaultRunnerImage != nil,
+		models.ProjectFieldTimeoutSeconds:    req.DefaultTimeoutSeconds != nil,
+		models.ProjectFieldQueueName:         req.DefaultQueueName != nil,
+		models.ProjectFieldAllowedEventTypes: req.AllowedEventTypes != nil,
+		models.ProjectFieldVCSTokenSecret:    req.VCSTokenSecret != nil,
+		models.ProjectFieldWebhookSecret:     req.WebhookSecret != nil,
+	}, req.InheritFields)
+
+	if !h.checkAdmission(w, r, h.store, models.PolicyTargetProject, project.ProjectID, projectToResponse(project)) {
+		return
+	}
 
 	if err := h.store.UpdateProject(r.Context(), project); err != nil {
 		h.respondWithError(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	h.respondWithJSON(w, http.StatusOK, projectToResponse(project))
+	saved := h.withGroupDefaults(r.Context(), project)
+	h.lintAndStoreProject(r.Context(), saved)
+	h.respondWithJSON(w, http.StatusOK, projectToResponse(saved))
+}
+
+// validateRefFilters checks a project's branch and tag patterns. The error
+// names the field and the pattern at fault.
+func validateRefFilters(targetBranches, tagPatterns []string) error {
+	if err := models.ValidateRefPatterns(targetBranches); err != nil {
+		return fmt.Errorf("target_branches: %w", err)
+	}
+	if err := models.ValidateRefPatterns(tagPatterns); err != nil {
+		return fmt.Errorf("tag_patterns: %w", err)
+	}
+	return nil
+}
+
+// validateDraftPRPolicy checks a project's draft PR policy and WIP title
+// patterns.
+func validateDraftPRPolicy(policy *string, wipTitlePatterns []string) error {
+	if policy != nil && !models.IsValidDraftPRPolicy(*policy) {
+		return fmt.Errorf("draft_pr_policy: must be %s, %s or %s, not %q", models.DraftPRPolicyRun, models.DraftPRPolicySkip, models.DraftPRPolicyDefer, *policy)
+	}
+	if err := models.ValidateWIPTitlePatterns(wipTitlePatterns); err != nil {
+		return fmt.Errorf("wip_title_patterns: %w", err)
+	}
+	return nil
 }
 
 func stringMapJSONB(values map[string]string) models.JSONB {
@@ -425,7 +966,9 @@ func jsonbStringMap(values models.JSONB) map[string]string {
 	return result
 }
 
-// DeleteProject handles DELETE /api/v1/projects/{project_id}
+// DeleteProject handles DELETE /api/v1/projects/{project_id}, moving the
+// project to the trash, from which RestoreProject can bring it back until
+// it's purged.
 func (h *ProjectHandler) DeleteProject(w http.ResponseWriter, r *http.Request) {
 	user := checkauth.GetUserFromContext(r.Context())
 	if user == nil {
@@ -526,8 +1069,7 @@ func (h *ProjectHandler) UpdateSecretGrant(w http.ResponseWriter, r *http.Reques
 		return
 	}
 	var req SecretGrantRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+	if !h.decodeJSON(w, r, &req) {
 		return
 	}
 	grant, err := grantStore.GetSecretGrant(r.Context(), ownerID, &project.ProjectID, ref)
@@ -65,7 +65,7 @@ func (m *ProjectMockStore) GetProjectByID(ctx context.Context, projectID string)
 	return nil, store.ErrNotFound
 }
 
-func (m *ProjectMockStore) GetProjectByRepoURL(ctx context.Context, repoURL string) (*models.Project, error) {
+func (m *ProjectMockStore) GetProjectsByRepoURL(ctx context.Context, repoURL string) ([]models.Project, error) {
 	return nil, store.ErrNotFound
 }
 
@@ -241,6 +241,20 @@ func TestProjectHandler_CreateProject(t *testing.T) {
 				assert.NotEmpty(t, resp.ProjectID)
 			},
 		},
+		{
+			name: "clone URL is stored in canonical form",
+			request: CreateProjectRequest{
+				Name:    "cloned-project",
+				RepoURL: "https://GitHub.com/org/repo.git",
+			},
+			withAuth:       true,
+			expectedStatus: http.StatusCreated,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var resp ProjectResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				assert.Equal(t, "github.com/org/repo", resp.RepoURL)
+			},
+		},
 		{
 			name: "success with all fields",
 			request: CreateProjectRequest{
@@ -286,6 +300,69 @@ func TestProjectHandler_CreateProject(t *testing.T) {
 			withAuth:       true,
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name: "branch and tag patterns",
+			request: CreateProjectRequest{
+				Name:           "patterns",
+				RepoURL:        "github.com/org/patterns",
+				TargetBranches: []string{"main", "release/*", "!release/old"},
+				TagPatterns:    []string{`/v\d+\.\d+\.\d+/`},
+			},
+			withAuth:       true,
+			expectedStatus: http.StatusCreated,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var resp ProjectResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				assert.Equal(t, []string{"main", "release/*", "!release/old"}, resp.TargetBranches)
+				assert.Equal(t, []string{`/v\d+\.\d+\.\d+/`}, resp.TagPatterns)
+			},
+		},
+		{
+			name: "invalid tag pattern",
+			request: CreateProjectRequest{
+				Name:        "bad-patterns",
+				RepoURL:     "github.com/org/bad",
+				TagPatterns: []string{"/(v/"},
+			},
+			withAuth:       true,
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var resp ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				assert.Equal(t, "invalid_input", resp.Error)
+				assert.Contains(t, resp.Message, `tag_patterns: invalid ref pattern "/(v/"`)
+			},
+		},
+		{
+			name: "invalid draft PR policy",
+			request: CreateProjectRequest{
+				Name:          "bad-policy",
+				RepoURL:       "github.com/org/bad",
+				DraftPRPolicy: strPtr("sometimes"),
+			},
+			withAuth:       true,
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var resp ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				assert.Contains(t, resp.Message, "draft_pr_policy: must be run, skip or defer, not \"sometimes\"")
+			},
+		},
+		{
+			name: "invalid WIP title pattern",
+			request: CreateProjectRequest{
+				Name:             "bad-wip",
+				RepoURL:          "github.com/org/bad",
+				WIPTitlePatterns: []string{"(wip"},
+			},
+			withAuth:       true,
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var resp ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				assert.Contains(t, resp.Message, "wip_title_patterns: invalid WIP title pattern \"(wip\"")
+			},
+		},
 		{
 			name: "store conflict error",
 			request: CreateProjectRequest{
@@ -551,6 +628,21 @@ func TestProjectHandler_UpdateProject(t *testing.T) {
 				assert.Equal(t, "github.com/org/repo", resp.RepoURL)
 			},
 		},
+		{
+			name:      "invalid branch pattern",
+			projectID: projectID,
+			request: UpdateProjectRequest{
+				TargetBranches: []string{"release/["},
+			},
+			setupMock: func(m *ProjectMockStore) {
+				m.GetProjectByIDFunc = func(ctx context.Context, id string) (*models.Project, error) {
+					p := *project
+					return &p, nil
+				}
+			},
+			withAuth:       true,
+			expectedStatus: http.StatusBadRequest,
+		},
 		{
 			name:      "success update enabled",
 			projectID: projectID,
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// projectHistoryStore is the narrow store capability behind project config
+// history. See postgres_store/project_history_operations.go.
+type projectHistoryStore interface {
+	ListProjectHistory(ctx context.Context, projectID string, limit, offset int) ([]models.ProjectConfigVersion, int64, error)
+	GetProjectVersion(ctx context.Context, projectID string, version int) (*models.ProjectConfigVersion, error)
+	RevertProject(ctx context.Context, project *models.Project, version int) error
+}
+
+// ListProjectHistoryResponse is the JSON body of
+// GET /api/v1/projects/{project_id}/history.
+type ListProjectHistoryResponse struct {
+	Versions []models.ProjectConfigVersion `json:"versions"`
+	Total    int64                         `json:"total"`
+	Limit    int                           `json:"limit"`
+	Offset   int                           `json:"offset"`
+}
+
+// historyProject loads the project a history endpoint is for and the
+// history store. With manage set, the user must be able to manage the
+// project.
+func (h *ProjectHandler) historyProject(w http.ResponseWriter, r *http.Request, manage bool) (projectHistoryStore, *models.Project, bool) {
+	hs, ok := h.store.(projectHistoryStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("project history not available"))
+		return nil, nil, false
+	}
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return nil, nil, false
+	}
+	project, _, ok := h.projectAndOwner(w, r, user.UserID)
+	if !ok {
+		return nil, nil, false
+	}
+	if manage && !h.canManageProject(r.Context(), user, project) {
+		h.respondWithError(w, http.StatusForbidden, store.ErrForbidden)
+		return nil, nil, false
+	}
+	return hs, project, true
+}
+
+// historyVersion returns the version number in the request's path,
+// responding 404 if it isn't one.
+func (h *ProjectHandler) historyVersion(w http.ResponseWriter, r *http.Request) (int, bool) {
+	version, err := strconv.Atoi(h.getID(r, "version"))
+	if err != nil || version < 1 {
+		h.respondWithError(w, http.StatusNotFound, store.ErrNotFound)
+		return 0, false
+	}
+	return version, true
+}
+
+// ListProjectHistory handles GET /api/v1/projects/{project_id}/history:
+// the project's config versions, newest first.
+func (h *ProjectHandler) ListProjectHistory(w http.ResponseWriter, r *http.Request) {
+	hs, project, ok := h.historyProject(w, r, false)
+	if !ok {
+		return
+	}
+	limit, offset := parseListPagination(r)
+	versions, total, err := hs.ListProjectHistory(r.Context(), project.ProjectID, limit, offset)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, ListProjectHistoryResponse{
+		Versions: versions,
+		Total:    total,
+		Limit:    limit,
+		Offset:   offset,
+	})
+}
+
+// GetProjectVersion handles
+// GET /api/v1/projects/{project_id}/history/{version}.
+func (h *ProjectHandler) GetProjectVersion(w http.ResponseWriter, r *http.Request) {
+	hs, project, ok := h.historyProject(w, r, false)
+	if !ok {
+		return
+	}
+	version, ok := h.historyVersion(w, r)
+	if !ok {
+		return
+	}
+	v, err := hs.GetProjectVersion(r.Context(), project.ProjectID, version)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, v)
+}
+
+// RevertProject handles
+// POST /api/v1/projects/{project_id}/history/{version}/revert: it restores
+// the project's configuration to the version's, subject to the project
+// admission policies, and records that as a new version. The project's
+// owner isn't reverted.
+func (h *ProjectHandler) RevertProject(w http.ResponseWriter, r *http.Request) {
+	hs, project, ok := h.historyProject(w, r, true)
+	if !ok {
+		return
+	}
+	version, ok := h.historyVersion(w, r)
+	if !ok {
+		return
+	}
+	v, err := hs.GetProjectVersion(r.Context(), project.ProjectID, version)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	currentGroup := ""
+	if project.GroupID != nil {
+		currentGroup = *project.GroupID
+	}
+	if err := models.ApplyProjectConfig(project, v.Config); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	// The version's group may have been deleted since.
+	if project.GroupID != nil && *project.GroupID != currentGroup && !h.setProjectGroup(w, r, project, *project.GroupID) {
+		return
+	}
+
+	if !h.checkAdmission(w, r, h.store, models.PolicyTargetProject, project.ProjectID, projectToResponse(project)) {
+		return
+	}
+	if err := hs.RevertProject(r.Context(), project, version); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	saved := h.withGroupDefaults(r.Context(), project)
+	h.lintAndStoreProject(r.Context(), saved)
+	h.respondWithJSON(w, http.StatusOK, projectToResponse(saved))
+}
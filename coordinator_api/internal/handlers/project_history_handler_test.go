@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// historyMockStore keeps one project and its history in memory on top of
+// ProjectMockStore, recording a version on every save like the postgres
+// store does.
+type historyMockStore struct {
+	ProjectMockStore
+	project  models.Project
+	versions []models.ProjectConfigVersion
+}
+
+func (m *historyMockStore) record(project *models.Project, action string, revertedTo *int) error {
+	config, err := models.ProjectConfig(project)
+	if err != nil {
+		return err
+	}
+	var before models.JSONB
+	if len(m.versions) > 0 {
+		before = m.versions[len(m.versions)-1].Config
+	}
+	m.project = *project
+	m.versions = append(m.versions, models.ProjectConfigVersion{
+		ProjectID:  project.ProjectID,
+		Version:    len(m.versions) + 1,
+		Action:     action,
+		RevertedTo: revertedTo,
+		Config:     config,
+		Changes:    models.DiffProjectConfigs(before, config),
+	})
+	return nil
+}
+
+func (m *historyMockStore) ListProjectHistory(ctx context.Context, projectID string, limit, offset int) ([]models.ProjectConfigVersion, int64, error) {
+	newest := []models.ProjectConfigVersion{}
+	for i := len(m.versions) - 1 - offset; i >= 0 && len(newest) < limit; i-- {
+		newest = append(newest, m.versions[i])
+	}
+	return newest, int64(len(m.versions)), nil
+}
+
+func (m *historyMockStore) GetProjectVersion(ctx context.Context, projectID string, version int) (*models.ProjectConfigVersion, error) {
+	if version > len(m.versions) {
+		return nil, store.ErrNotFound
+	}
+	return &m.versions[version-1], nil
+}
+
+func (m *historyMockStore) RevertProject(ctx context.Context, project *models.Project, version int) error {
+	return m.record(project, models.ProjectHistoryReverted, &version)
+}
+
+func historyRequest(method, version string) *http.Request {
+	r := httptest.NewRequest(method, "/api/v1/projects/project-1/history", nil)
+	r = withUser(r.WithContext(setIDContext(r.Context(), "project_id", "project-1")))
+	if version != "" {
+		r = r.WithContext(setIDContext(r.Context(), "version", version))
+	}
+	return r
+}
+
+func TestProjectHistoryHandler(t *testing.T) {
+	owner := "test-user-id"
+	s := &historyMockStore{}
+	s.GetProjectByIDFunc = func(ctx context.Context, projectID string) (*models.Project, error) {
+		project := s.project
+		return &project, nil
+	}
+	h := NewProjectHandler(s)
+
+	require.NoError(t, s.record(&models.Project{ProjectID: "project-1", UserID: &owner, Name: "api", DefaultQueueName: "reactorcide-jobs"}, models.ProjectHistoryCreated, nil))
+	changed := s.project
+	changed.DefaultQueueName = "big-workers"
+	require.NoError(t, s.record(&changed, models.ProjectHistoryUpdated, nil))
+
+	t.Run("lists versions newest first", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ListProjectHistory(w, historyRequest(http.MethodGet, ""))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response ListProjectHistoryResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.EqualValues(t, 2, response.Total)
+		require.Len(t, response.Versions, 2)
+		assert.Equal(t, 2, response.Versions[0].Version)
+		assert.Equal(t, []string{"default_queue_name"}, response.Versions[0].Changes.Fields())
+	})
+
+	t.Run("an unknown version is not found", func(t *testing.T) {
+		for _, version := range []string{"3", "0", "latest"} {
+			w := httptest.NewRecorder()
+			h.GetProjectVersion(w, historyRequest(http.MethodGet, version))
+			assert.Equal(t, http.StatusNotFound, w.Code, version)
+		}
+	})
+
+	t.Run("only managers revert", func(t *testing.T) {
+		r := historyRequest(http.MethodPost, "1")
+		r = r.WithContext(checkauth.SetUserContext(r.Context(), &models.User{UserID: "someone-else"}))
+		w := httptest.NewRecorder()
+		h.RevertProject(w, r)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Len(t, s.versions, 2)
+	})
+
+	t.Run("reverting restores the version as a new one", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.RevertProject(w, historyRequest(http.MethodPost, "1"))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "reactorcide-jobs", s.project.DefaultQueueName)
+		require.Len(t, s.versions, 3)
+		assert.Equal(t, models.ProjectHistoryReverted, s.versions[2].Action)
+		assert.Equal(t, 1, *s.versions[2].RevertedTo)
+		assert.Equal(t, []string{"default_queue_name"}, s.versions[2].Changes.Fields())
+	})
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/admission"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
+)
+
+// projectLintStore is the narrow store capability for keeping projects'
+// lint warnings. See postgres_store/project_operations.go.
+type projectLintStore interface {
+	SetProjectLintWarnings(ctx context.Context, projectID string, warnings models.ProjectLintWarnings) error
+}
+
+// projectLintPolicyLister lists the admission policies runner images are
+// linted against. See postgres_store/admission_operations.go.
+type projectLintPolicyLister interface {
+	ListAdmissionPolicies(ctx context.Context) ([]models.AdmissionPolicy, error)
+}
+
+// filteredEventTypes are the event types a project's AllowedEventTypes is
+// checked for. Draft PRs marked ready for review or retitled reach the
+// filter as pull_request_opened (see applyDraftPRPolicy), and the other
+// types never reach it.
+var filteredEventTypes = map[string]bool{
+	string(vcs.EventPush):               true,
+	string(vcs.EventPullRequestOpened):  true,
+	string(vcs.EventPullRequestUpdated): true,
+	string(vcs.EventPullRequestMerged):  true,
+	string(vcs.EventPullRequestClosed):  true,
+	string(vcs.EventTagCreated):         true,
+	string(vcs.EventMergeGroup):         true,
+	string(vcs.EventManual):             true,
+}
+
+// policyFieldRunnerImage is the field of a job's admission policy
+// document holding its runner image.
+const policyFieldRunnerImage = "runner_image"
+
+// lintAndStoreProject lints project, as saved, and stores the warnings
+// with it. Linting never fails a save: a store that can't keep the
+// warnings only logs it, and the response still carries them.
+func (h *ProjectHandler) lintAndStoreProject(ctx context.Context, project *models.Project) {
+	var policies []models.AdmissionPolicy
+	if pl, ok := h.store.(projectLintPolicyLister); ok {
+		var err error
+		policies, err = pl.ListAdmissionPolicies(ctx)
+		if err != nil {
+			log.Printf("Failed to load admission policies to lint project %s: %v", project.ProjectID, err)
+		}
+	}
+	project.LintWarnings = lintProject(project, policies, config.CiCodeAllowlist)
+
+	ls, ok := h.store.(projectLintStore)
+	if !ok {
+		return
+	}
+	if err := ls.SetProjectLintWarnings(ctx, project.ProjectID, project.LintWarnings); err != nil {
+		log.Printf("Failed to store lint warnings of project %s: %v", project.ProjectID, err)
+	}
+}
+
+// lintProject returns what looks wrong in project's configuration: event
+// types nothing sends it, branch and tag patterns that never match,
+// runner images the job admission policies reject and a default CI
+// source outside ciCodeAllowlist (if one is configured).
+func lintProject(project *models.Project, policies []models.AdmissionPolicy, ciCodeAllowlist string) models.ProjectLintWarnings {
+	warnings := models.ProjectLintWarnings{}
+	warn := func(code, field, value, message string) {
+		warnings = append(warnings, models.ProjectLintWarning{Code: code, Field: field, Value: value, Message: message})
+	}
+
+	if len(project.AllowedEventTypes) == 0 {
+		warn(models.LintNoEventTypes, "allowed_event_types", "", "no event types are allowed, so no event builds the project")
+	}
+	for _, eventType := range project.AllowedEventTypes {
+		switch {
+		case filteredEventTypes[eventType]:
+		case eventType == string(vcs.EventPullRequestReadyForReview) || eventType == string(vcs.EventPullRequestRetitled):
+			warn(models.LintUnreachableEventType, "allowed_event_types", eventType,
+				fmt.Sprintf("%s events reach the project as pull_request_opened under the defer draft PR policy; allow pull_request_opened instead", eventType))
+		default:
+			warn(models.LintUnreachableEventType, "allowed_event_types", eventType,
+				fmt.Sprintf("%q is not an event type projects are sent", eventType))
+		}
+	}
+	for i, template := range project.JobTemplates {
+		for _, eventType := range template.Events {
+			if !project.AllowsEventType(eventType) {
+				warn(models.LintUnreachableEventType, fmt.Sprintf("job_templates[%d].events", i), eventType,
+					fmt.Sprintf("job template %q never runs for %s events: the project doesn't allow them", template.Name, eventType))
+			}
+		}
+	}
+
+	for _, filter := range []struct {
+		field    string
+		patterns []string
+	}{
+		{"target_branches", project.TargetBranches},
+		{"tag_patterns", project.TagPatterns},
+	} {
+		for _, pattern := range filter.patterns {
+			if reason := models.UnmatchableRefPattern(filter.patterns, pattern); reason != "" {
+				warn(models.LintUnmatchableRefPattern, filter.field, pattern,
+					fmt.Sprintf("pattern %q never matches: %s", pattern, reason))
+			}
+		}
+	}
+
+	type lintedImage struct{ field, image string }
+	images := []lintedImage{{"default_runner_image", project.DefaultRunnerImage}}
+	if project.Canary != nil {
+		images = append(images, lintedImage{"canary.runner_image", project.Canary.RunnerImage})
+	}
+	for i, template := range project.JobTemplates {
+		images = append(images, lintedImage{fmt.Sprintf("job_templates[%d].image", i), template.Image})
+	}
+	for _, image := range images {
+		if image.image == "" {
+			continue
+		}
+		for _, v := range runnerImageViolations(policies, project.ProjectID, image.image) {
+			warn(models.LintRunnerImageNotAllowed, image.field, image.image,
+				fmt.Sprintf("admission policy %q: %s", v.Policy, v.Message))
+		}
+	}
+
+	if ciCodeAllowlist != "" && project.DefaultCISourceURL != "" && !ciCodeURLAllowed(ciCodeAllowlist, project.DefaultCISourceURL) {
+		warn(models.LintCISourceNotAllowed, "default_ci_source_url", project.DefaultCISourceURL,
+			"the CI source is not in REACTORCIDE_CI_CODE_ALLOWLIST, so jobs can't use it")
+	}
+	return warnings
+}
+
+// runnerImageViolations returns the violations of the job admission
+// policies' unconditional runner_image rules by image, the ones every job
+// running it breaks whatever else it sets.
+func runnerImageViolations(policies []models.AdmissionPolicy, projectID, image string) []models.PolicyViolation {
+	var imagePolicies []models.AdmissionPolicy
+	for _, policy := range policies {
+		var rules models.PolicyRules
+		for _, rule := range policy.Rules {
+			if rule.Field == policyFieldRunnerImage && len(rule.When) == 0 {
+				rules = append(rules, rule)
+			}
+		}
+		if len(rules) > 0 {
+			policy.Rules = rules
+			imagePolicies = append(imagePolicies, policy)
+		}
+	}
+	if len(imagePolicies) == 0 {
+		return nil
+	}
+	denials, warnings := admission.Evaluate(imagePolicies, models.PolicyTargetJob, projectID, map[string]interface{}{
+		policyFieldRunnerImage: image,
+	})
+	return append(denials, warnings...)
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lintMockStore keeps the lint warnings stored for projects, and serves
+// admission policies.
+type lintMockStore struct {
+	ProjectMockStore
+	policies []models.AdmissionPolicy
+	stored   map[string]models.ProjectLintWarnings
+}
+
+func (m *lintMockStore) ListAdmissionPolicies(ctx context.Context) ([]models.AdmissionPolicy, error) {
+	return m.policies, nil
+}
+
+func (m *lintMockStore) SetProjectLintWarnings(ctx context.Context, projectID string, warnings models.ProjectLintWarnings) error {
+	m.stored[projectID] = warnings
+	return nil
+}
+
+func lintCodes(warnings models.ProjectLintWarnings) map[string][]string {
+	codes := map[string][]string{}
+	for _, w := range warnings {
+		codes[w.Code] = append(codes[w.Code], w.Value)
+	}
+	return codes
+}
+
+func TestLintProject(t *testing.T) {
+	policies := []models.AdmissionPolicy{{
+		Name:        "company registry",
+		Target:      models.PolicyTargetJob,
+		Enforcement: models.PolicyEnforceDeny,
+		Rules: models.PolicyRules{
+			{Field: "runner_image", Op: models.PolicyOpIn, Values: []string{"registry.example.com/*"}},
+			{Field: "runner_image", Op: models.PolicyOpIn, Values: []string{"other/*"}, When: []models.PolicyRule{{Field: "environment", Op: models.PolicyOpIn, Values: []string{"prod"}}}},
+			{Field: "timeout_seconds", Op: models.PolicyOpMax, Limit: func() *float64 { f := 60.0; return &f }()},
+		},
+	}}
+
+	t.Run("clean project", func(t *testing.T) {
+		project := &models.Project{
+			TargetBranches:     []string{"main", "release/*", "!release/old"},
+			AllowedEventTypes:  []string{"push", "pull_request_opened", "manual"},
+			DefaultRunnerImage: "registry.example.com/runner:1",
+			DefaultCISourceURL: "https://github.com/org/ci.git",
+			JobTemplates:       models.JobTemplates{{Name: "test", Command: "make test", Events: []string{"push"}}},
+		}
+		assert.Empty(t, lintProject(project, policies, "github.com/org/ci"))
+	})
+
+	t.Run("every check", func(t *testing.T) {
+		project := &models.Project{
+			TargetBranches:     []string{"refs/heads/main", "develop", "!develop"},
+			TagPatterns:        []string{"v*"},
+			AllowedEventTypes:  []string{"push", "pull_request", "pull_request_ready_for_review"},
+			DefaultRunnerImage: "docker.io/library/alpine",
+			DefaultCISourceURL: "https://github.com/someone/ci",
+			Canary:             &models.ProjectCanary{Percent: 10, RunnerImage: "registry.example.com/runner:2"},
+			JobTemplates: models.JobTemplates{
+				{Name: "lint", Command: "make lint", Events: []string{"tag_created"}, Image: "golang:1.22"},
+			},
+		}
+		warnings := lintProject(project, policies, "github.com/org/ci")
+		codes := lintCodes(warnings)
+		assert.Equal(t, []string{"pull_request", "pull_request_ready_for_review", "tag_created"}, codes[models.LintUnreachableEventType])
+		assert.Equal(t, []string{"refs/heads/main", "develop"}, codes[models.LintUnmatchableRefPattern])
+		assert.Equal(t, []string{"docker.io/library/alpine", "golang:1.22"}, codes[models.LintRunnerImageNotAllowed], "the canary image is allowed")
+		assert.Equal(t, []string{"https://github.com/someone/ci"}, codes[models.LintCISourceNotAllowed])
+		for _, w := range warnings {
+			assert.NotEmpty(t, w.Field)
+			assert.NotEmpty(t, w.Message)
+		}
+	})
+
+	t.Run("no event types, no allowlist", func(t *testing.T) {
+		project := &models.Project{DefaultCISourceURL: "https://github.com/someone/ci"}
+		assert.Equal(t, map[string][]string{models.LintNoEventTypes: {""}}, lintCodes(lintProject(project, nil, "")))
+	})
+}
+
+func TestProjectHandler_UpdateProject_StoresLintWarnings(t *testing.T) {
+	projectID := uuid.New().String()
+	m := &lintMockStore{stored: map[string]models.ProjectLintWarnings{}}
+	m.GetProjectByIDFunc = func(ctx context.Context, id string) (*models.Project, error) {
+		return testProject(projectID), nil
+	}
+	h := NewProjectHandler(m)
+
+	body, err := json.Marshal(UpdateProjectRequest{TargetBranches: []string{"refs/heads/main"}, AllowedEventTypes: []string{"push"}})
+	require.NoError(t, err)
+	req := withProjectID(withUser(httptest.NewRequest(http.MethodPut, "/api/v1/projects/"+projectID, bytes.NewReader(body))), projectID)
+	w := httptest.NewRecorder()
+	h.UpdateProject(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp ProjectResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	require.Len(t, resp.LintWarnings, 1)
+	assert.Equal(t, models.LintUnmatchableRefPattern, resp.LintWarnings[0].Code)
+	assert.Equal(t, "target_branches", resp.LintWarnings[0].Field)
+	assert.Equal(t, resp.LintWarnings, m.stored[projectID], "the warnings are kept for the UI")
+}
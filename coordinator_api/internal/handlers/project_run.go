@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/hooks"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/metrics"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/repourl"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/worker"
+)
+
+// RunProjectRequest is the JSON body of POST /api/v1/projects/{project_id}/run.
+type RunProjectRequest struct {
+	// Branch is the branch to run on; it must match the project's
+	// target_branches.
+	Branch string `json:"branch"`
+	// Ref is the commit or ref to check out, the branch's head by default.
+	Ref string `json:"ref,omitempty"`
+	// Env sets the project's run_parameters for every job of the run.
+	Env models.EnvVars `json:"env,omitempty"`
+	// Jobs restricts the run to these job definitions. Only definitions
+	// triggered by manual events run either way.
+	Jobs []string `json:"jobs,omitempty"`
+}
+
+// RunProject handles POST /api/v1/projects/{project_id}/run: it starts the
+// project's pipeline by hand, as an eval job for the manual event. The
+// project must allow manual events and the branch, and env may only set
+// the project's run_parameters.
+func (h *JobHandler) RunProject(w http.ResponseWriter, r *http.Request) {
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return
+	}
+	projectID := h.getID(r, "project_id")
+	if projectID == "" {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+	project, err := h.store.GetProjectByID(r.Context(), projectID)
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return
+	}
+
+	var req RunProjectRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	if err := validateRunProjectRequest(project, &req); err != nil {
+		status, errType := http.StatusBadRequest, "invalid_input"
+		if errors.Is(err, store.ErrForbidden) {
+			status, errType = http.StatusForbidden, "forbidden"
+		}
+		h.respondWithJSON(w, status, ErrorResponse{Error: errType, Message: err.Error()})
+		return
+	}
+
+	job := buildManualRunJob(project, &req, user.UserID)
+	job.SecretScopes = secretsPrincipal(r).TokenScopes
+	if !h.checkPayloadLimits(w, worker.CheckJobPayloadLimits(job.Name, job.Description, job.JobCommand, job.JobEnvVars.EnvStrings())) {
+		return
+	}
+	if !h.checkSourceURLs(w, r, h.store, *job.SourceURL, *job.CISourceURL) {
+		return
+	}
+	if err := h.validateCiCodeURL(*job.CISourceURL); err != nil {
+		h.respondWithError(w, http.StatusForbidden, err)
+		return
+	}
+	if !h.checkJobHooks(w, r, job, hooks.SourceProjectRun) {
+		return
+	}
+
+	worker.RouteProjectQueue(r.Context(), h.store, project, job)
+	if err := h.store.CreateJob(r.Context(), job); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	metrics.RecordJobSubmission(job.QueueName, string(*job.SourceType))
+	h.submitJob(r.Context(), job)
+
+	h.respondWithJSON(w, http.StatusCreated, h.jobToResponse(job))
+}
+
+// validateRunProjectRequest checks req against the project's allowlists,
+// whose errors wrap store.ErrForbidden.
+func validateRunProjectRequest(project *models.Project, req *RunProjectRequest) error {
+	if req.Branch == "" {
+		return fmt.Errorf("branch is required")
+	}
+	for _, name := range req.Jobs {
+		if name == "" || strings.Contains(name, ",") {
+			return fmt.Errorf("invalid job name %q", name)
+		}
+	}
+	switch project.EventFilterReason(string(vcs.EventManual), req.Branch) {
+	case models.EventFilterProjectDisabled:
+		return fmt.Errorf("%w: project is disabled", store.ErrForbidden)
+	case models.EventFilterEventType:
+		return fmt.Errorf("%w: project does not allow manual runs (add %q to allowed_event_types)", store.ErrForbidden, vcs.EventManual)
+	case models.EventFilterBranch:
+		return fmt.Errorf("%w: branch %q does not match the project's target_branches", store.ErrForbidden, req.Branch)
+	}
+	var denied []string
+	for name := range req.Env {
+		if !project.AllowsRunParameter(name) {
+			denied = append(denied, name)
+		}
+	}
+	if len(denied) > 0 {
+		sort.Strings(denied)
+		return fmt.Errorf("%w: %s not in the project's run_parameters", store.ErrForbidden, strings.Join(denied, ", "))
+	}
+	return nil
+}
+
+// buildManualRunJob constructs the eval job of a manual run. Like a push
+// eval job it runs runnerlib eval on the branch, here with the manual event,
+// the selected jobs in REACTORCIDE_JOBS, and the parameters set in its
+// environment and named in REACTORCIDE_RUN_PARAMS, which runnerlib passes on
+// to the jobs it triggers.
+func buildManualRunJob(project *models.Project, req *RunProjectRequest, userID string) *models.Job {
+	sourceType := models.SourceTypeGit
+	sourceURL := repourl.CloneURL(project.RepoURL)
+	ref := req.Ref
+	if ref == "" {
+		ref = req.Branch
+	}
+
+	envVars := models.JSONB{
+		"REACTORCIDE_CI":         "true",
+		"REACTORCIDE_EVENT_TYPE": string(vcs.EventManual),
+		"REACTORCIDE_SOURCE_URL": sourceURL,
+		"REACTORCIDE_BRANCH":     req.Branch,
+		"REACTORCIDE_SHA":        ref,
+	}
+	params := make([]string, 0, len(req.Env))
+	for name, value := range req.Env {
+		envVars[name] = value
+		params = append(params, name)
+	}
+	if len(params) > 0 {
+		sort.Strings(params)
+		envVars["REACTORCIDE_RUN_PARAMS"] = strings.Join(params, ",")
+	}
+	if len(req.Jobs) > 0 {
+		envVars["REACTORCIDE_JOBS"] = strings.Join(req.Jobs, ",")
+	}
+
+	ciSourceType := models.SourceTypeGit
+	ciSourceURL := sourceURL
+	ciSourceRef := ref
+	if project.DefaultCISourceURL != "" {
+		if project.DefaultCISourceType != "" {
+			ciSourceType = project.DefaultCISourceType
+		}
+		ciSourceURL = project.DefaultCISourceURL
+		ciSourceRef = project.DefaultCISourceRef
+	}
+	envVars["REACTORCIDE_CI_SOURCE_URL"] = ciSourceURL
+	envVars["REACTORCIDE_CI_SOURCE_REF"] = ciSourceRef
+
+	jobName := fmt.Sprintf("eval: manual run on %s of %s", req.Branch, project.Name)
+	if len(req.Jobs) > 0 {
+		jobName = fmt.Sprintf("eval: manual run of %s on %s of %s", strings.Join(req.Jobs, ", "), req.Branch, project.Name)
+	}
+	if project.ConfigPath != "" {
+		envVars["REACTORCIDE_CONFIG_PATH"] = project.ConfigPath
+		jobName = fmt.Sprintf("%s [%s]", jobName, project.ConfigPath)
+	}
+
+	jobCommand := project.DefaultJobCommand
+	if jobCommand == "" {
+		jobCommand = "runnerlib eval --event-type $REACTORCIDE_EVENT_TYPE --branch $REACTORCIDE_BRANCH"
+	}
+
+	job := &models.Job{
+		UserID:        userID,
+		ProjectID:     &project.ProjectID,
+		Name:          jobName,
+		Description:   fmt.Sprintf("Eval job for %s on %s", vcs.EventManual, project.Name),
+		Status:        "submitted",
+		SourceURL:     &sourceURL,
+		SourceRef:     &ref,
+		SourceType:    &sourceType,
+		CISourceType:  &ciSourceType,
+		CISourceURL:   &ciSourceURL,
+		CISourceRef:   &ciSourceRef,
+		JobCommand:    jobCommand,
+		RunnerImage:   project.DefaultRunnerImage,
+		JobEnvVars:    envVars,
+		Priority:      5,
+		QueueName:     project.DefaultQueueName,
+		AwaitChildren: project.AwaitChildJobs,
+	}
+	if project.DefaultTimeoutSeconds > 0 {
+		job.TimeoutSeconds = project.DefaultTimeoutSeconds
+	}
+	return job
+}
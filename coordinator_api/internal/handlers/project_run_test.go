@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runProjectMockStore serves one project to RunProject.
+type runProjectMockStore struct {
+	MockStore
+	project *models.Project
+}
+
+func (m *runProjectMockStore) GetProjectByID(ctx context.Context, projectID string) (*models.Project, error) {
+	return m.project, nil
+}
+
+func TestJobHandler_RunProject(t *testing.T) {
+	newProject := func() *models.Project {
+		project := webhookTestProject()
+		project.AllowedEventTypes = append(project.AllowedEventTypes, "manual")
+		project.RunParameters = []string{"TARGET", "VERSION"}
+		return project
+	}
+	run := func(project *models.Project, body string) (*httptest.ResponseRecorder, *runProjectMockStore) {
+		s := &runProjectMockStore{project: project}
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/projects/"+project.ProjectID+"/run", bytes.NewBufferString(body))
+		req = withProjectID(withUser(req), project.ProjectID)
+		w := httptest.NewRecorder()
+		NewJobHandler(s, corndogs.NewMockClient()).RunProject(w, req)
+		return w, s
+	}
+
+	t.Run("starts a manual eval job", func(t *testing.T) {
+		w, s := run(newProject(), `{"branch":"main","ref":"abc123","env":{"TARGET":"production","VERSION":2},"jobs":["deploy"]}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		require.Len(t, s.CreateJobCalls, 1)
+
+		job := s.CreateJobCalls[0]
+		assert.Equal(t, "test-user-id", job.UserID)
+		assert.Equal(t, "abc123", *job.SourceRef)
+		env := job.JobEnvVars.EnvStrings()
+		assert.Equal(t, "manual", env["REACTORCIDE_EVENT_TYPE"])
+		assert.Equal(t, "main", env["REACTORCIDE_BRANCH"])
+		assert.Equal(t, "deploy", env["REACTORCIDE_JOBS"])
+		assert.Equal(t, "TARGET,VERSION", env["REACTORCIDE_RUN_PARAMS"])
+		assert.Equal(t, "production", env["TARGET"])
+		assert.Equal(t, "2", env["VERSION"])
+
+		var resp JobResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.NotEmpty(t, resp.JobID)
+	})
+
+	t.Run("ref defaults to the branch", func(t *testing.T) {
+		w, s := run(newProject(), `{"branch":"main"}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		assert.Equal(t, "main", *s.CreateJobCalls[0].SourceRef)
+		assert.NotContains(t, s.CreateJobCalls[0].JobEnvVars, "REACTORCIDE_JOBS")
+	})
+
+	for _, tc := range []struct {
+		name    string
+		project func(*models.Project)
+		body    string
+		status  int
+	}{
+		{"manual runs not allowed", func(p *models.Project) { p.AllowedEventTypes = []string{"push"} }, `{"branch":"main"}`, http.StatusForbidden},
+		{"project disabled", func(p *models.Project) { p.Enabled = false }, `{"branch":"main"}`, http.StatusForbidden},
+		{"branch not allowed", nil, `{"branch":"feature"}`, http.StatusForbidden},
+		{"parameter not allowed", nil, `{"branch":"main","env":{"SECRET_TOKEN":"x"}}`, http.StatusForbidden},
+		{"missing branch", nil, `{}`, http.StatusBadRequest},
+		{"invalid job name", nil, `{"branch":"main","jobs":["a,b"]}`, http.StatusBadRequest},
+		{"non-scalar parameter", nil, `{"branch":"main","env":{"TARGET":{"a":1}}}`, http.StatusBadRequest},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			project := newProject()
+			if tc.project != nil {
+				tc.project(project)
+			}
+			w, s := run(project, tc.body)
+			assert.Equal(t, tc.status, w.Code, w.Body.String())
+			assert.Empty(t, s.CreateJobCalls)
+		})
+	}
+}
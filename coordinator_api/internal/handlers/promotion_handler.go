@@ -0,0 +1,350 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/hooks"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/jobcontrol"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// maxPromotionChain caps the promotions a promotion chain reports.
+const maxPromotionChain = 100
+
+// promotionStore is the store support for environment promotions.
+type promotionStore interface {
+	GetPromotion(ctx context.Context, promotionID string) (*models.Promotion, error)
+	ListPromotions(ctx context.Context, projectID, artifactDigest, environment string, limit, offset int) ([]models.Promotion, int64, error)
+}
+
+// CreatePromotionRequest is the JSON body of
+// POST /api/v1/projects/{project_id}/promotions.
+type CreatePromotionRequest struct {
+	// JobID is the completed deploy job to promote.
+	JobID string `json:"job_id"`
+	// Environment is the environment to promote it to.
+	Environment string `json:"environment"`
+	// ArtifactDigest identifies what the job deployed. By default it's the
+	// digest the job was itself promoted with, or its commit.
+	ArtifactDigest string `json:"artifact_digest,omitempty"`
+}
+
+// RejectPromotionRequest is the JSON body of
+// POST /api/v1/projects/{project_id}/promotions/{promotion_id}/reject.
+type RejectPromotionRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// PromotionResponse is a promotion and, once it has run, its job.
+type PromotionResponse struct {
+	Promotion *models.Promotion `json:"promotion"`
+	Job       *JobResponse      `json:"job,omitempty"`
+}
+
+// ListPromotionsResponse is the JSON body of
+// GET /api/v1/projects/{project_id}/promotions.
+type ListPromotionsResponse struct {
+	Promotions []models.Promotion `json:"promotions"`
+	Total      int64              `json:"total"`
+	Limit      int                `json:"limit"`
+	Offset     int                `json:"offset"`
+}
+
+// PromotionChainEntry is one environment an artifact reached, or is
+// waiting to. The first entry is the deploy the first promotion started
+// from and has no promotion.
+type PromotionChainEntry struct {
+	Environment     string     `json:"environment"`
+	PromotionID     string     `json:"promotion_id,omitempty"`
+	PromotionStatus string     `json:"promotion_status,omitempty"`
+	JobID           *string    `json:"job_id,omitempty"`
+	JobStatus       string     `json:"job_status,omitempty"`
+	RequestedAt     *time.Time `json:"requested_at,omitempty"`
+	PromotedAt      *time.Time `json:"promoted_at,omitempty"`
+	DeployedAt      *time.Time `json:"deployed_at,omitempty"`
+}
+
+// PromotionChainResponse is the JSON body of
+// GET /api/v1/projects/{project_id}/promotions/chain: where an artifact
+// digest was deployed, in order.
+type PromotionChainResponse struct {
+	ArtifactDigest string                `json:"artifact_digest"`
+	Environments   []PromotionChainEntry `json:"environments"`
+}
+
+// CreatePromotion handles POST /api/v1/projects/{project_id}/promotions:
+// it requests that a completed deploy job of the project be rerun against
+// another environment. The caller must be able to access the job. A
+// promotion needing no approvals runs straight away.
+func (h *JobHandler) CreatePromotion(w http.ResponseWriter, r *http.Request) {
+	user, project, ok := h.promotionScope(w, r)
+	if !ok {
+		return
+	}
+	var req CreatePromotionRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.JobID == "" || req.Environment == "" {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: "job_id and environment are required"})
+		return
+	}
+	source, err := h.store.GetJobByID(r.Context(), req.JobID)
+	if err == nil && (source.ProjectID == nil || *source.ProjectID != project.ProjectID) {
+		err = store.ErrNotFound
+	}
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return
+	}
+	if !h.canUserAccessJob(user, source) {
+		h.respondWithError(w, http.StatusForbidden, store.ErrForbidden)
+		return
+	}
+
+	promotion, job, err := jobcontrol.RequestPromotion(r.Context(), h.store, h.corndogsClient, project, source, req.Environment, req.ArtifactDigest, user.UserID)
+	if err != nil {
+		h.respondWithPromotionError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusCreated, h.promotionResponse(promotion, job))
+}
+
+// ListPromotions handles GET /api/v1/projects/{project_id}/promotions,
+// optionally for one artifact digest (?artifact_digest=) or environment
+// (?environment=, promotions from or to it).
+func (h *JobHandler) ListPromotions(w http.ResponseWriter, r *http.Request) {
+	_, project, ok := h.promotionScope(w, r)
+	if !ok {
+		return
+	}
+	limit := 20
+	offset := 0
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+	if o, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && o >= 0 {
+		offset = o
+	}
+	query := r.URL.Query()
+	promotions, total, err := h.store.(promotionStore).ListPromotions(r.Context(), project.ProjectID, query.Get("artifact_digest"), query.Get("environment"), limit, offset)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if promotions == nil {
+		promotions = []models.Promotion{}
+	}
+	h.respondWithJSON(w, http.StatusOK, ListPromotionsResponse{Promotions: promotions, Total: total, Limit: limit, Offset: offset})
+}
+
+// GetPromotion handles
+// GET /api/v1/projects/{project_id}/promotions/{promotion_id}
+func (h *JobHandler) GetPromotion(w http.ResponseWriter, r *http.Request) {
+	_, project, ok := h.promotionScope(w, r)
+	if !ok {
+		return
+	}
+	promotion, ok := h.loadPromotion(w, r, project)
+	if !ok {
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, h.promotionResponse(promotion, nil))
+}
+
+// ApprovePromotion handles
+// POST /api/v1/projects/{project_id}/promotions/{promotion_id}/approve.
+// Admins and the project's owner may approve, but not the promotion's
+// requester. The approval that completes a promotion runs it.
+func (h *JobHandler) ApprovePromotion(w http.ResponseWriter, r *http.Request) {
+	user, promotion, ok := h.decidePromotionScope(w, r)
+	if !ok {
+		return
+	}
+	job, err := jobcontrol.ApprovePromotion(r.Context(), h.store, h.corndogsClient, promotion, user.UserID)
+	if err != nil {
+		h.respondWithPromotionError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, h.promotionResponse(promotion, job))
+}
+
+// RejectPromotion handles
+// POST /api/v1/projects/{project_id}/promotions/{promotion_id}/reject.
+// Whoever may approve a promotion, or its requester, may reject it.
+func (h *JobHandler) RejectPromotion(w http.ResponseWriter, r *http.Request) {
+	user, promotion, ok := h.decidePromotionScope(w, r)
+	if !ok {
+		return
+	}
+	var req RejectPromotionRequest
+	if r.ContentLength != 0 && !h.decodeJSON(w, r, &req) {
+		return
+	}
+	if err := jobcontrol.RejectPromotion(r.Context(), h.store, promotion, user.UserID, req.Reason); err != nil {
+		h.respondWithPromotionError(w, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, h.promotionResponse(promotion, nil))
+}
+
+// GetPromotionChain handles
+// GET /api/v1/projects/{project_id}/promotions/chain?artifact_digest=: the
+// environments the digest was deployed to, oldest first, starting from the
+// deploy it was first promoted from, with when each was requested,
+// promoted and deployed.
+func (h *JobHandler) GetPromotionChain(w http.ResponseWriter, r *http.Request) {
+	_, project, ok := h.promotionScope(w, r)
+	if !ok {
+		return
+	}
+	digest := r.URL.Query().Get("artifact_digest")
+	if digest == "" {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: "artifact_digest is required"})
+		return
+	}
+	promotions, _, err := h.store.(promotionStore).ListPromotions(r.Context(), project.ProjectID, digest, "", maxPromotionChain, 0)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	resp := PromotionChainResponse{ArtifactDigest: digest, Environments: []PromotionChainEntry{}}
+	if len(promotions) == 0 {
+		h.respondWithJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	first := promotions[0]
+	origin := PromotionChainEntry{Environment: first.FromEnvironment, JobID: &first.SourceJobID}
+	h.addJobToChainEntry(r.Context(), &origin, first.SourceJobID)
+	resp.Environments = append(resp.Environments, origin)
+	for i := range promotions {
+		p := &promotions[i]
+		createdAt := p.CreatedAt
+		entry := PromotionChainEntry{
+			Environment:     p.ToEnvironment,
+			PromotionID:     p.PromotionID,
+			PromotionStatus: p.Status,
+			JobID:           p.JobID,
+			RequestedAt:     &createdAt,
+		}
+		if p.Status == models.PromotionPromoted {
+			entry.PromotedAt = p.DecidedAt
+		}
+		if p.JobID != nil {
+			h.addJobToChainEntry(r.Context(), &entry, *p.JobID)
+		}
+		resp.Environments = append(resp.Environments, entry)
+	}
+	h.respondWithJSON(w, http.StatusOK, resp)
+}
+
+// addJobToChainEntry fills in entry's job status and, for a completed job,
+// when it deployed. A job that's gone leaves them empty.
+func (h *JobHandler) addJobToChainEntry(ctx context.Context, entry *PromotionChainEntry, jobID string) {
+	job, err := h.store.GetJobByID(ctx, jobID)
+	if err != nil {
+		return
+	}
+	entry.JobStatus = job.Status
+	if job.Status == "completed" {
+		entry.DeployedAt = job.CompletedAt
+	}
+}
+
+// promotionScope resolves the caller and the request's project,
+// responding with an error when either is missing or the store has no
+// promotions.
+func (h *JobHandler) promotionScope(w http.ResponseWriter, r *http.Request) (*models.User, *models.Project, bool) {
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return nil, nil, false
+	}
+	if _, ok := h.store.(promotionStore); !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("promotions not available"))
+		return nil, nil, false
+	}
+	projectID := h.getID(r, "project_id")
+	if projectID == "" {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return nil, nil, false
+	}
+	project, err := h.store.GetProjectByID(r.Context(), projectID)
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return nil, nil, false
+	}
+	return user, project, true
+}
+
+// decidePromotionScope is promotionScope plus the promotion, for callers
+// who may approve or reject it: admins, the project's owner and, for
+// rejections, the promotion's requester (jobcontrol refuses their
+// approvals).
+func (h *JobHandler) decidePromotionScope(w http.ResponseWriter, r *http.Request) (*models.User, *models.Promotion, bool) {
+	user, project, ok := h.promotionScope(w, r)
+	if !ok {
+		return nil, nil, false
+	}
+	promotion, ok := h.loadPromotion(w, r, project)
+	if !ok {
+		return nil, nil, false
+	}
+	owner := project.UserID != nil && *project.UserID == user.UserID
+	if !h.isAdmin(user) && !owner && promotion.RequestedBy != user.UserID {
+		h.respondWithError(w, http.StatusForbidden, store.ErrForbidden)
+		return nil, nil, false
+	}
+	return user, promotion, true
+}
+
+// loadPromotion gets the request's promotion, responding 404 if it isn't
+// the project's.
+func (h *JobHandler) loadPromotion(w http.ResponseWriter, r *http.Request, project *models.Project) (*models.Promotion, bool) {
+	promotion, err := h.store.(promotionStore).GetPromotion(r.Context(), h.getID(r, "promotion_id"))
+	if err == nil && promotion.ProjectID != project.ProjectID {
+		err = store.ErrNotFound
+	}
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return nil, false
+	}
+	return promotion, true
+}
+
+// respondWithPromotionError maps jobcontrol's promotion errors to
+// responses.
+func (h *JobHandler) respondWithPromotionError(w http.ResponseWriter, err error) {
+	if h.respondPolicyDenied(w, err) {
+		return
+	}
+	var rejected *hooks.RejectedError
+	switch {
+	case errors.Is(err, store.ErrInvalidInput):
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+	case errors.Is(err, jobcontrol.ErrNotPromotable), errors.Is(err, jobcontrol.ErrPromotionClosed), errors.Is(err, jobcontrol.ErrAlreadyApproved):
+		h.respondWithJSON(w, http.StatusConflict, ErrorResponse{Error: "conflict", Message: err.Error()})
+	case errors.Is(err, jobcontrol.ErrSelfApproval):
+		h.respondWithJSON(w, http.StatusForbidden, ErrorResponse{Error: "forbidden", Message: err.Error()})
+	case errors.As(err, &rejected):
+		h.respondWithJSON(w, http.StatusForbidden, ErrorResponse{Error: "forbidden", Message: err.Error()})
+	default:
+		h.respondWithError(w, http.StatusInternalServerError, err)
+	}
+}
+
+func (h *JobHandler) promotionResponse(promotion *models.Promotion, job *models.Job) PromotionResponse {
+	resp := PromotionResponse{Promotion: promotion}
+	if job != nil {
+		jobResp := h.jobToResponse(job)
+		resp.Job = &jobResp
+	}
+	return resp
+}
@@ -0,0 +1,450 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/jobcontrol"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/worker"
+)
+
+// maxQueueScan caps how many waiting jobs one queue request inspects.
+// Corndogs can't list a queue's tasks, so each is looked up in turn.
+const maxQueueScan = 500
+
+// maxCommandSummary caps the command shown in a task summary.
+const maxCommandSummary = 200
+
+// queuedJobStore is the narrow store capability behind the admin queue
+// endpoints. See postgres_store/queue_operations.go.
+type queuedJobStore interface {
+	ListQueuedJobs(ctx context.Context, limit int) ([]models.Job, error)
+}
+
+// QueueHandler serves the admin endpoints for inspecting and managing
+// Corndogs queues. Tasks are always changed through their jobs, so a job's
+// record stays in step with its task.
+type QueueHandler struct {
+	BaseHandler
+	store          store.Store
+	corndogsClient corndogs.ClientInterface
+}
+
+// NewQueueHandler creates a new queue handler.
+func NewQueueHandler(store store.Store, corndogsClient corndogs.ClientInterface) *QueueHandler {
+	return &QueueHandler{
+		store:          store,
+		corndogsClient: corndogsClient,
+	}
+}
+
+// QueueSummary is one Corndogs queue and its task count.
+type QueueSummary struct {
+	Name      string `json:"name"`
+	TaskCount int64  `json:"task_count"`
+}
+
+// ListQueuesResponse is the JSON body of GET /api/v1/admin/queues.
+type ListQueuesResponse struct {
+	Queues         []QueueSummary `json:"queues"`
+	TotalTaskCount int64          `json:"total_task_count"`
+}
+
+// QueuedTaskSummary describes a waiting task and its job. It summarizes the
+// payload rather than returning it: env var values may be secrets, so only
+// their count is shown.
+type QueuedTaskSummary struct {
+	TaskID      string     `json:"task_id"`
+	JobID       string     `json:"job_id"`
+	JobName     string     `json:"job_name"`
+	ProjectID   *string    `json:"project_id,omitempty"`
+	UserID      string     `json:"user_id"`
+	Queue       string     `json:"queue"`
+	State       string     `json:"state"`
+	Priority    int64      `json:"priority"`
+	SubmittedAt *time.Time `json:"submitted_at,omitempty"`
+	JobType     string     `json:"job_type,omitempty"`
+	Image       string     `json:"image,omitempty"`
+	Command     string     `json:"command,omitempty"`
+	SourceURL   string     `json:"source_url,omitempty"`
+	SourceRef   string     `json:"source_ref,omitempty"`
+	TargetOS    string     `json:"target_os,omitempty"`
+	TargetArch  string     `json:"target_arch,omitempty"`
+	EnvVarCount int        `json:"env_var_count"`
+	// Encrypted is set when the task's payload is encrypted to the workers'
+	// key; the summary then shows the job's payload as the coordinator
+	// would submit it now.
+	Encrypted bool `json:"encrypted,omitempty"`
+}
+
+// ListQueuedTasksResponse is the JSON body of GET /api/v1/admin/queues/{queue}.
+type ListQueuedTasksResponse struct {
+	Queue string              `json:"queue"`
+	Tasks []QueuedTaskSummary `json:"tasks"`
+	// Truncated is set when more jobs were waiting than one request
+	// inspects, so the queue may hold tasks not listed.
+	Truncated bool `json:"truncated"`
+}
+
+// MoveQueuedJobRequest is the body of POST /api/v1/admin/queued-jobs/{job_id}/move.
+type MoveQueuedJobRequest struct {
+	Queue string `json:"queue"`
+}
+
+// ReprioritizeQueuedJobRequest is the body of
+// POST /api/v1/admin/queued-jobs/{job_id}/priority.
+type ReprioritizeQueuedJobRequest struct {
+	Priority *int `json:"priority"`
+}
+
+// QueuedJobResponse is the JSON body of the move and priority endpoints.
+type QueuedJobResponse struct {
+	JobID          string  `json:"job_id"`
+	Status         string  `json:"status"`
+	Priority       int     `json:"priority"`
+	Queue          string  `json:"queue,omitempty"`
+	CorndogsTaskID *string `json:"corndogs_task_id,omitempty"`
+}
+
+// PurgeQueueRequest is the body of POST /api/v1/admin/queues/{queue}/purge.
+// Confirm must repeat the queue's name.
+type PurgeQueueRequest struct {
+	Confirm string `json:"confirm"`
+	DryRun  bool   `json:"dry_run"`
+}
+
+// PurgeQueueResponse is the JSON body of POST /api/v1/admin/queues/{queue}/purge.
+type PurgeQueueResponse struct {
+	Queue  string `json:"queue"`
+	DryRun bool   `json:"dry_run"`
+	// Cancelled lists the jobs cancelled, or on a dry run that would be.
+	Cancelled []string `json:"cancelled"`
+	// Failed lists the jobs that couldn't be cancelled, usually because a
+	// worker claimed them first.
+	Failed    []string `json:"failed"`
+	Truncated bool     `json:"truncated"`
+}
+
+// ListQueues handles GET /api/v1/admin/queues
+func (h *QueueHandler) ListQueues(w http.ResponseWriter, r *http.Request) {
+	if !h.requireCorndogs(w) {
+		return
+	}
+	counts, total, err := h.corndogsClient.GetQueueTaskCounts(r.Context())
+	if err != nil {
+		h.respondWithError(w, http.StatusServiceUnavailable, store.ErrServiceUnavailable)
+		return
+	}
+	queues := make([]QueueSummary, 0, len(counts))
+	for name, count := range counts {
+		queues = append(queues, QueueSummary{Name: name, TaskCount: count})
+	}
+	sort.Slice(queues, func(i, j int) bool { return queues[i].Name < queues[j].Name })
+	h.respondWithJSON(w, http.StatusOK, ListQueuesResponse{Queues: queues, TotalTaskCount: total})
+}
+
+// ListQueuedTasks handles GET /api/v1/admin/queues/{queue}: the tasks
+// waiting in the queue, in the order workers will claim them.
+func (h *QueueHandler) ListQueuedTasks(w http.ResponseWriter, r *http.Request) {
+	if !h.requireCorndogs(w) {
+		return
+	}
+	qs, ok := h.store.(queuedJobStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("queue inspection not available"))
+		return
+	}
+	queue := h.getID(r, "queue")
+	if queue == "" {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+
+	tasks, truncated, err := h.queuedTasks(r.Context(), qs, queue)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	summaries := make([]QueuedTaskSummary, 0, len(tasks))
+	for _, t := range tasks {
+		summaries = append(summaries, t.summary())
+	}
+	sort.SliceStable(summaries, func(i, j int) bool { return summaries[i].Priority > summaries[j].Priority })
+	h.respondWithJSON(w, http.StatusOK, ListQueuedTasksResponse{Queue: queue, Tasks: summaries, Truncated: truncated})
+}
+
+// MoveQueuedJob handles POST /api/v1/admin/queued-jobs/{job_id}/move. The
+// target must be a queue Corndogs already knows, so a typo can't strand a
+// job on a queue no worker claims from.
+func (h *QueueHandler) MoveQueuedJob(w http.ResponseWriter, r *http.Request) {
+	if !h.requireCorndogs(w) {
+		return
+	}
+	var req MoveQueuedJobRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Queue == "" {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+	queues, _, err := h.corndogsClient.GetQueues(r.Context())
+	if err != nil {
+		h.respondWithError(w, http.StatusServiceUnavailable, store.ErrServiceUnavailable)
+		return
+	}
+	if !containsString(queues, req.Queue) {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_input",
+			Message: "unknown queue " + req.Queue,
+		})
+		return
+	}
+
+	job, ok := h.loadQueuedJob(w, r)
+	if !ok {
+		return
+	}
+	updated, err := jobcontrol.MoveJobToQueue(r.Context(), h.store, h.corndogsClient, job, req.Queue)
+	h.respondWithQueuedJob(w, r, "move", updated, req.Queue, err)
+}
+
+// ReprioritizeQueuedJob handles POST /api/v1/admin/queued-jobs/{job_id}/priority
+func (h *QueueHandler) ReprioritizeQueuedJob(w http.ResponseWriter, r *http.Request) {
+	if !h.requireCorndogs(w) {
+		return
+	}
+	var req ReprioritizeQueuedJobRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Priority == nil || *req.Priority < 0 {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+
+	job, ok := h.loadQueuedJob(w, r)
+	if !ok {
+		return
+	}
+	updated, err := jobcontrol.ReprioritizeJob(r.Context(), h.store, h.corndogsClient, job, *req.Priority)
+	h.respondWithQueuedJob(w, r, "reprioritize", updated, "", err)
+}
+
+// PurgeQueue handles POST /api/v1/admin/queues/{queue}/purge: it cancels
+// every job waiting in the queue. Jobs already claimed are left running.
+func (h *QueueHandler) PurgeQueue(w http.ResponseWriter, r *http.Request) {
+	if !h.requireCorndogs(w) {
+		return
+	}
+	qs, ok := h.store.(queuedJobStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("queue inspection not available"))
+		return
+	}
+	queue := h.getID(r, "queue")
+	if queue == "" {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+	var req PurgeQueueRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Confirm != queue {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_input",
+			Message: "confirm must repeat the queue name",
+		})
+		return
+	}
+
+	tasks, truncated, err := h.queuedTasks(r.Context(), qs, queue)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	resp := PurgeQueueResponse{Queue: queue, DryRun: req.DryRun, Cancelled: []string{}, Failed: []string{}, Truncated: truncated}
+	for _, t := range tasks {
+		if req.DryRun {
+			resp.Cancelled = append(resp.Cancelled, t.job.JobID)
+			continue
+		}
+		updated, err := jobcontrol.CancelJob(r.Context(), h.store, h.corndogsClient, &t.job)
+		if err != nil || updated.Status != "cancelled" {
+			resp.Failed = append(resp.Failed, t.job.JobID)
+			continue
+		}
+		resp.Cancelled = append(resp.Cancelled, t.job.JobID)
+	}
+	if !req.DryRun {
+		logging.Log.WithFields(map[string]interface{}{
+			"queue":     queue,
+			"cancelled": len(resp.Cancelled),
+			"failed":    len(resp.Failed),
+			"admin":     adminUserID(r),
+		}).Warn("ADMIN: purged Corndogs queue")
+	}
+	h.respondWithJSON(w, http.StatusOK, resp)
+}
+
+// queuedTask is a waiting job and its live Corndogs task.
+type queuedTask struct {
+	job  models.Job
+	task *corndogsTask
+}
+
+// corndogsTask is the part of a Corndogs task a summary shows.
+type corndogsTask struct {
+	ID          string
+	Queue       string
+	State       string
+	Priority    int64
+	SubmittedAt *time.Time
+	Payload     *corndogs.TaskPayload
+	Encrypted   bool
+}
+
+// queuedTasks returns the jobs waiting in queue, looking each waiting job's
+// task up in Corndogs: the job records don't say which queue a task is on.
+// Tasks Corndogs no longer has waiting are skipped.
+func (h *QueueHandler) queuedTasks(ctx context.Context, qs queuedJobStore, queue string) ([]queuedTask, bool, error) {
+	jobs, err := qs.ListQueuedJobs(ctx, maxQueueScan+1)
+	if err != nil {
+		return nil, false, err
+	}
+	truncated := len(jobs) > maxQueueScan
+	if truncated {
+		jobs = jobs[:maxQueueScan]
+	}
+
+	var tasks []queuedTask
+	for _, job := range jobs {
+		task, err := h.corndogsClient.GetTaskByID(ctx, *job.CorndogsTaskID)
+		if err != nil || task == nil || task.Queue != queue || task.CurrentState != "submitted" {
+			continue
+		}
+		t := &corndogsTask{ID: task.Uuid, Queue: task.Queue, State: task.CurrentState, Priority: task.Priority}
+		if task.SubmitTime > 0 {
+			submitted := time.Unix(task.SubmitTime, 0).UTC()
+			t.SubmittedAt = &submitted
+		}
+		if payload, err := corndogs.ParseTaskPayload(task); err == nil {
+			t.Payload = payload
+		} else if errors.Is(err, corndogs.ErrPayloadEncrypted) {
+			t.Payload = worker.BuildTaskPayload(&job)
+			t.Encrypted = true
+		}
+		tasks = append(tasks, queuedTask{job: job, task: t})
+	}
+	return tasks, truncated, nil
+}
+
+func (t queuedTask) summary() QueuedTaskSummary {
+	s := QueuedTaskSummary{
+		TaskID:      t.task.ID,
+		JobID:       t.job.JobID,
+		JobName:     t.job.Name,
+		ProjectID:   t.job.ProjectID,
+		UserID:      t.job.UserID,
+		Queue:       t.task.Queue,
+		State:       t.task.State,
+		Priority:    t.task.Priority,
+		SubmittedAt: t.task.SubmittedAt,
+		Encrypted:   t.task.Encrypted,
+	}
+	p := t.task.Payload
+	if p == nil {
+		return s
+	}
+	s.JobType = p.JobType
+	s.Image, _ = p.Config["image"].(string)
+	s.Command, _ = p.Config["command"].(string)
+	if len(s.Command) > maxCommandSummary {
+		s.Command = s.Command[:maxCommandSummary] + "..."
+	}
+	s.SourceURL, _ = p.Source["url"].(string)
+	s.SourceRef, _ = p.Source["ref"].(string)
+	s.TargetOS, s.TargetArch = p.TargetPlatform()
+	switch env := p.Config["environment"].(type) {
+	case map[string]interface{}:
+		s.EnvVarCount = len(env)
+	case models.JSONB:
+		s.EnvVarCount = len(env)
+	}
+	return s
+}
+
+func (h *QueueHandler) requireCorndogs(w http.ResponseWriter) bool {
+	if h.corndogsClient == nil {
+		h.respondWithError(w, http.StatusServiceUnavailable, store.ErrServiceUnavailable)
+		return false
+	}
+	return true
+}
+
+func (h *QueueHandler) loadQueuedJob(w http.ResponseWriter, r *http.Request) (*models.Job, bool) {
+	jobID := h.getID(r, "job_id")
+	if jobID == "" {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return nil, false
+	}
+	job, err := h.store.GetJobByID(r.Context(), jobID)
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return nil, false
+	}
+	return job, true
+}
+
+// respondWithQueuedJob answers a move or reprioritize, logging the change.
+func (h *QueueHandler) respondWithQueuedJob(w http.ResponseWriter, r *http.Request, action string, job *models.Job, queue string, err error) {
+	if errors.Is(err, jobcontrol.ErrNotQueued) {
+		h.respondWithJSON(w, http.StatusConflict, ErrorResponse{
+			Error:   "conflict",
+			Message: "job is not waiting in a queue",
+		})
+		return
+	}
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	logging.Log.WithFields(map[string]interface{}{
+		"job_id":   job.JobID,
+		"priority": job.Priority,
+		"queue":    queue,
+		"admin":    adminUserID(r),
+	}).Warnf("ADMIN: %s queued job", action)
+	h.respondWithJSON(w, http.StatusOK, QueuedJobResponse{
+		JobID:          job.JobID,
+		Status:         job.Status,
+		Priority:       job.Priority,
+		Queue:          queue,
+		CorndogsTaskID: job.CorndogsTaskID,
+	})
+}
+
+func adminUserID(r *http.Request) string {
+	if user := checkauth.GetUserFromContext(r.Context()); user != nil {
+		return user.UserID
+	}
+	return ""
+}
+
+func containsString(list []string, s string) bool {
+	for _, entry := range list {
+		if entry == s {
+			return true
+		}
+	}
+	return false
+}
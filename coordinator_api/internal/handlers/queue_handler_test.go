@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	pb "github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs/v1alpha1"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// queueMockStore keeps jobs in memory on top of MockStore, with the
+// queuedJobStore capability and the guarded transitions jobcontrol uses.
+type queueMockStore struct {
+	MockStore
+	jobs map[string]*models.Job
+}
+
+func (m *queueMockStore) GetJobByID(ctx context.Context, jobID string) (*models.Job, error) {
+	j, ok := m.jobs[jobID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	cp := *j
+	return &cp, nil
+}
+
+func (m *queueMockStore) ListQueuedJobs(ctx context.Context, limit int) ([]models.Job, error) {
+	var jobs []models.Job
+	for _, id := range []string{"job-a", "job-b", "job-c"} {
+		if j, ok := m.jobs[id]; ok && (j.Status == "submitted" || j.Status == "queued") {
+			jobs = append(jobs, *j)
+		}
+	}
+	return jobs, nil
+}
+
+func (m *queueMockStore) UpdateJobStatusGuarded(ctx context.Context, jobID string, fromStatuses []string, apply func(*models.Job)) (*models.Job, bool, error) {
+	j, ok := m.jobs[jobID]
+	if !ok {
+		return nil, false, store.ErrNotFound
+	}
+	for _, s := range fromStatuses {
+		if j.Status == s {
+			apply(j)
+			cp := *j
+			return &cp, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// newQueueFixture returns two jobs waiting on reactorcide-jobs, one on the
+// arm64 queue, and a Corndogs mock that knows where each task is.
+func newQueueFixture() (*queueMockStore, *corndogs.MockClient) {
+	st := &queueMockStore{jobs: map[string]*models.Job{}}
+	tasks := map[string]*pb.Task{}
+	for _, spec := range []struct{ job, queue string }{
+		{"job-a", "reactorcide-jobs"},
+		{"job-b", "reactorcide-jobs"},
+		{"job-c", "reactorcide-jobs-linux-arm64"},
+	} {
+		taskID := "task-" + spec.job
+		st.jobs[spec.job] = &models.Job{JobID: spec.job, Name: spec.job, UserID: "user-1", Status: "submitted", CorndogsTaskID: &taskID}
+		payload, _ := json.Marshal(corndogs.TaskPayload{
+			JobID:   spec.job,
+			JobType: "run",
+			Config: map[string]interface{}{
+				"image":       "alpine:3",
+				"command":     strings.Repeat("x", 300),
+				"environment": map[string]interface{}{"TOKEN": "s3cr3t-value"},
+			},
+			Source: map[string]interface{}{"url": "https://github.com/org/repo", "ref": "main"},
+		})
+		tasks[taskID] = &pb.Task{Uuid: taskID, Queue: spec.queue, CurrentState: "submitted", Payload: payload, SubmitTime: time.Now().Unix()}
+	}
+
+	cd := corndogs.NewMockClient()
+	cd.GetTaskByIDFunc = func(ctx context.Context, taskID string) (*pb.Task, error) {
+		if t, ok := tasks[taskID]; ok {
+			return t, nil
+		}
+		return nil, store.ErrNotFound
+	}
+	cd.CancelTaskFunc = func(ctx context.Context, taskID string, currentState string) (*pb.Task, error) {
+		if t, ok := tasks[taskID]; ok {
+			return t, nil
+		}
+		return nil, store.ErrNotFound
+	}
+	cd.GetQueuesFunc = func(ctx context.Context) ([]string, int64, error) {
+		return []string{"reactorcide-jobs", "reactorcide-jobs-linux-arm64"}, 3, nil
+	}
+	cd.GetQueueTaskCountsFunc = func(ctx context.Context) (map[string]int64, int64, error) {
+		return map[string]int64{"reactorcide-jobs-linux-arm64": 1, "reactorcide-jobs": 2}, 3, nil
+	}
+	return st, cd
+}
+
+func queueRequest(method, target, key, id, body string) *http.Request {
+	req := withUser(httptest.NewRequest(method, target, strings.NewReader(body)))
+	if key != "" {
+		req = req.WithContext(setIDContext(req.Context(), key, id))
+	}
+	return req
+}
+
+func TestQueueHandler_ListQueues(t *testing.T) {
+	st, cd := newQueueFixture()
+	h := NewQueueHandler(st, cd)
+
+	w := httptest.NewRecorder()
+	h.ListQueues(w, queueRequest(http.MethodGet, "/api/v1/admin/queues", "", "", ""))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp ListQueuesResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Queues, 2)
+	assert.Equal(t, "reactorcide-jobs", resp.Queues[0].Name)
+	assert.Equal(t, int64(2), resp.Queues[0].TaskCount)
+	assert.Equal(t, int64(3), resp.TotalTaskCount)
+}
+
+func TestQueueHandler_ListQueuedTasks(t *testing.T) {
+	st, cd := newQueueFixture()
+	h := NewQueueHandler(st, cd)
+
+	w := httptest.NewRecorder()
+	h.ListQueuedTasks(w, queueRequest(http.MethodGet, "/api/v1/admin/queues/reactorcide-jobs", "queue", "reactorcide-jobs", ""))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "s3cr3t-value", "env var values must not be listed")
+
+	var resp ListQueuedTasksResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Tasks, 2)
+	task := resp.Tasks[0]
+	assert.Equal(t, "job-a", task.JobID)
+	assert.Equal(t, "alpine:3", task.Image)
+	assert.Equal(t, 1, task.EnvVarCount)
+	assert.Equal(t, maxCommandSummary+3, len(task.Command))
+	assert.Equal(t, "https://github.com/org/repo", task.SourceURL)
+}
+
+func TestQueueHandler_ListQueuedTasks_EncryptedPayload(t *testing.T) {
+	st, cd := newQueueFixture()
+	sealed := []byte(`{"encryption":"` + corndogs.PayloadEncryption + `","key_id":"0123456789abcdef","ciphertext":"AAAA"}`)
+	getTask := cd.GetTaskByIDFunc
+	cd.GetTaskByIDFunc = func(ctx context.Context, taskID string) (*pb.Task, error) {
+		task, err := getTask(ctx, taskID)
+		if task != nil {
+			task.Payload = sealed // each fixture has its own tasks
+		}
+		return task, err
+	}
+	st.jobs["job-a"].RunnerImage = "alpine:3"
+	st.jobs["job-a"].JobEnvVars = models.JSONB{"TOKEN": "s3cr3t-value"}
+	h := NewQueueHandler(st, cd)
+
+	w := httptest.NewRecorder()
+	h.ListQueuedTasks(w, queueRequest(http.MethodGet, "/api/v1/admin/queues/reactorcide-jobs", "queue", "reactorcide-jobs", ""))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "s3cr3t-value", "env var values must not be listed")
+
+	var resp ListQueuedTasksResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Tasks, 2)
+	task := resp.Tasks[0]
+	assert.True(t, task.Encrypted)
+	assert.Equal(t, "alpine:3", task.Image, "summarized from the job record")
+	assert.Equal(t, 1, task.EnvVarCount)
+}
+
+func TestQueueHandler_PurgeQueue(t *testing.T) {
+	purge := func(h *QueueHandler, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.PurgeQueue(w, queueRequest(http.MethodPost, "/api/v1/admin/queues/reactorcide-jobs/purge", "queue", "reactorcide-jobs", body))
+		return w
+	}
+
+	t.Run("requires confirmation", func(t *testing.T) {
+		st, cd := newQueueFixture()
+		w := purge(NewQueueHandler(st, cd), `{"confirm":"reactorcide-jobs-linux-arm64"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, 0, cd.GetCancelTaskCallCount())
+	})
+
+	t.Run("dry run cancels nothing", func(t *testing.T) {
+		st, cd := newQueueFixture()
+		w := purge(NewQueueHandler(st, cd), `{"confirm":"reactorcide-jobs","dry_run":true}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp PurgeQueueResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.ElementsMatch(t, []string{"job-a", "job-b"}, resp.Cancelled)
+		assert.Equal(t, 0, cd.GetCancelTaskCallCount())
+		assert.Equal(t, "submitted", st.jobs["job-a"].Status)
+	})
+
+	t.Run("cancels only that queue", func(t *testing.T) {
+		st, cd := newQueueFixture()
+		w := purge(NewQueueHandler(st, cd), `{"confirm":"reactorcide-jobs"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp PurgeQueueResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.ElementsMatch(t, []string{"job-a", "job-b"}, resp.Cancelled)
+		assert.Empty(t, resp.Failed)
+		assert.Equal(t, "cancelled", st.jobs["job-a"].Status)
+		assert.Equal(t, "cancelled", st.jobs["job-b"].Status)
+		assert.Equal(t, "submitted", st.jobs["job-c"].Status)
+	})
+}
+
+func TestQueueHandler_MoveQueuedJob(t *testing.T) {
+	move := func(h *QueueHandler, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.MoveQueuedJob(w, queueRequest(http.MethodPost, "/api/v1/admin/queued-jobs/job-a/move", "job_id", "job-a", body))
+		return w
+	}
+
+	t.Run("unknown queue rejected", func(t *testing.T) {
+		st, cd := newQueueFixture()
+		w := move(NewQueueHandler(st, cd), `{"queue":"reactorcide-jbos"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, 0, cd.GetCancelTaskCallCount())
+	})
+
+	t.Run("resubmits to the target queue", func(t *testing.T) {
+		st, cd := newQueueFixture()
+		w := move(NewQueueHandler(st, cd), `{"queue":"reactorcide-jobs-linux-arm64"}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Len(t, cd.SubmitTaskCalls, 1)
+		assert.Equal(t, "reactorcide-jobs-linux-arm64", cd.SubmitTaskCalls[0].Queue)
+		assert.NotEqual(t, "task-job-a", *st.jobs["job-a"].CorndogsTaskID)
+	})
+}
+
+func TestQueueHandler_ReprioritizeQueuedJob(t *testing.T) {
+	reprioritize := func(h *QueueHandler, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ReprioritizeQueuedJob(w, queueRequest(http.MethodPost, "/api/v1/admin/queued-jobs/job-c/priority", "job_id", "job-c", body))
+		return w
+	}
+
+	t.Run("negative priority rejected", func(t *testing.T) {
+		st, cd := newQueueFixture()
+		assert.Equal(t, http.StatusBadRequest, reprioritize(NewQueueHandler(st, cd), `{"priority":-1}`).Code)
+	})
+
+	t.Run("keeps the task's queue", func(t *testing.T) {
+		st, cd := newQueueFixture()
+		w := reprioritize(NewQueueHandler(st, cd), `{"priority":75}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Len(t, cd.SubmitTaskCalls, 1)
+		assert.Equal(t, "reactorcide-jobs-linux-arm64", cd.SubmitTaskCalls[0].Queue)
+		assert.Equal(t, int64(75), cd.SubmitTaskCalls[0].Priority)
+		assert.Equal(t, 75, st.jobs["job-c"].Priority)
+	})
+
+	t.Run("running job conflicts", func(t *testing.T) {
+		st, cd := newQueueFixture()
+		st.jobs["job-c"].Status = "running"
+		assert.Equal(t, http.StatusConflict, reprioritize(NewQueueHandler(st, cd), `{"priority":75}`).Code)
+		assert.Empty(t, cd.SubmitTaskCalls)
+	})
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
+)
+
+// releaseStore is the store support for project releases.
+type releaseStore interface {
+	CreateRelease(ctx context.Context, release *models.Release) error
+	GetRelease(ctx context.Context, releaseID string) (*models.Release, error)
+	ListReleases(ctx context.Context, projectID string, limit, offset int) ([]models.Release, int64, error)
+}
+
+// ListReleasesResponse is the JSON body of
+// GET /api/v1/projects/{project_id}/releases.
+type ListReleasesResponse struct {
+	Releases []models.Release `json:"releases"`
+	Total    int64            `json:"total"`
+	Limit    int              `json:"limit"`
+	Offset   int              `json:"offset"`
+}
+
+// createRelease records the pending release of a tag_created event's
+// pipeline, which job evaluates. The worker that finishes the pipeline
+// publishes it. A tag that already has a release keeps it.
+func (h *WebhookHandler) createRelease(ctx context.Context, project *models.Project, event *vcs.WebhookEvent, job *models.Job) {
+	rs, ok := h.store.(releaseStore)
+	if !ok {
+		return
+	}
+	release := &models.Release{
+		ProjectID: project.ProjectID,
+		Tag:       extractBranchOrTag(event.Push.Ref),
+		CommitSHA: event.Push.After,
+		Provider:  string(event.Provider),
+		Repo:      event.Repository.FullName,
+		EvalJobID: job.JobID,
+	}
+	fields := logrus.Fields{"project": project.Name, "tag": release.Tag, "job_id": job.JobID}
+	if err := rs.CreateRelease(ctx, release); err != nil {
+		if errors.Is(err, store.ErrAlreadyExists) {
+			h.logger.WithFields(fields).Info("Tag already has a release; not releasing it again")
+			return
+		}
+		h.logger.WithError(err).WithFields(fields).Error("Failed to record release")
+		return
+	}
+	h.logger.WithFields(fields).WithField("release_id", release.ReleaseID).Info("Release pending on the tag's pipeline")
+}
+
+// ListReleases handles GET /api/v1/projects/{project_id}/releases
+func (h *ProjectHandler) ListReleases(w http.ResponseWriter, r *http.Request) {
+	rs, project, ok := h.releaseScope(w, r)
+	if !ok {
+		return
+	}
+	limit := 20
+	offset := 0
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+	if o, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && o >= 0 {
+		offset = o
+	}
+	releases, total, err := rs.ListReleases(r.Context(), project.ProjectID, limit, offset)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if releases == nil {
+		releases = []models.Release{}
+	}
+	h.respondWithJSON(w, http.StatusOK, ListReleasesResponse{Releases: releases, Total: total, Limit: limit, Offset: offset})
+}
+
+// GetRelease handles GET /api/v1/projects/{project_id}/releases/{release_id}
+func (h *ProjectHandler) GetRelease(w http.ResponseWriter, r *http.Request) {
+	rs, project, ok := h.releaseScope(w, r)
+	if !ok {
+		return
+	}
+	release, err := rs.GetRelease(r.Context(), h.getID(r, "release_id"))
+	if err == nil && release.ProjectID != project.ProjectID {
+		err = store.ErrNotFound
+	}
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, release)
+}
+
+// releaseScope resolves the release store and the request's project,
+// responding with an error when either is missing.
+func (h *ProjectHandler) releaseScope(w http.ResponseWriter, r *http.Request) (releaseStore, *models.Project, bool) {
+	if checkauth.GetUserFromContext(r.Context()) == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return nil, nil, false
+	}
+	rs, ok := h.store.(releaseStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("releases not available"))
+		return nil, nil, false
+	}
+	project, _, ok := h.projectAndOwner(w, r, "")
+	if !ok {
+		return nil, nil, false
+	}
+	return rs, project, true
+}
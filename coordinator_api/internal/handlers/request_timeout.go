@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+)
+
+// limitRequestTime puts a deadline on each request's context, by route
+// class (see requestTimeout). Store, Corndogs and object store calls all
+// take the request context, so a slow dependency fails the request with
+// context.DeadlineExceeded, which respondWithError turns into a 503,
+// instead of holding the connection open.
+func limitRequestTime(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timeout := requestTimeout(r.URL.Path)
+		if timeout <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// requestTimeout returns the timeout for a request path; zero means none.
+func requestTimeout(path string) time.Duration {
+	seconds := config.RequestTimeoutSeconds
+	switch {
+	case isStreamPath(path):
+		return 0
+	case strings.HasPrefix(path, "/api/v1/webhooks/"):
+		seconds = config.WebhookRequestTimeoutSeconds
+	case strings.HasPrefix(path, objects.SignedObjectPath),
+		strings.HasPrefix(path, "/api/v1/jobs/") && (strings.HasSuffix(path, "/logs") || strings.Contains(path, "/logs/chunks/")),
+		path == SourceUploadPath:
+		seconds = config.LogRequestTimeoutSeconds
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// isStreamPath reports whether path is a long-lived WebSocket route.
+func isStreamPath(path string) bool {
+	return path == "/api/v1/jobs/stream" ||
+		strings.HasPrefix(path, "/api/v1/jobs/stream/") ||
+		strings.HasSuffix(path, "/debug/attach")
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
+)
+
+func withRequestTimeouts(t *testing.T, request, webhook, logs int) {
+	oldRequest, oldWebhook, oldLogs := config.RequestTimeoutSeconds, config.WebhookRequestTimeoutSeconds, config.LogRequestTimeoutSeconds
+	config.RequestTimeoutSeconds, config.WebhookRequestTimeoutSeconds, config.LogRequestTimeoutSeconds = request, webhook, logs
+	t.Cleanup(func() {
+		config.RequestTimeoutSeconds, config.WebhookRequestTimeoutSeconds, config.LogRequestTimeoutSeconds = oldRequest, oldWebhook, oldLogs
+	})
+}
+
+func TestRequestTimeout(t *testing.T) {
+	withRequestTimeouts(t, 60, 10, 600)
+
+	tests := []struct {
+		path     string
+		expected time.Duration
+	}{
+		{"/api/v1/projects", time.Minute},
+		{"/api/v1/jobs/job-1", time.Minute},
+		{"/api/v1/webhooks/github", 10 * time.Second},
+		{"/api/v1/jobs/job-1/logs", 10 * time.Minute},
+		{"/api/v1/jobs/job-1/logs/chunks/stdout/3", 10 * time.Minute},
+		{"/api/v1/objects/signed/logs/job-1", 10 * time.Minute},
+		{"/api/v1/jobs/stream", 0},
+		{"/api/v1/jobs/stream/job-1", 0},
+		{"/api/v1/jobs/job-1/debug/attach", 0},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, requestTimeout(tt.path), tt.path)
+	}
+
+	withRequestTimeouts(t, 0, 10, 600)
+	assert.Zero(t, requestTimeout("/api/v1/projects"))
+}
+
+func TestLimitRequestTime(t *testing.T) {
+	withRequestTimeouts(t, 1, 1, 1)
+	h := &BaseHandler{}
+	handler := limitRequestTime(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A dependency that takes longer than the timeout.
+		select {
+		case <-r.Context().Done():
+			h.respondWithError(w, http.StatusInternalServerError, r.Context().Err())
+		case <-time.After(5 * time.Second):
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+
+	start := time.Now()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/projects", nil))
+	assert.Less(t, time.Since(start), 5*time.Second)
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	var resp ErrorResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.Equal(t, "timeout", resp.Error)
+
+	// Streams keep the request's own context.
+	handler = limitRequestTime(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, hasDeadline := r.Context().Deadline()
+		assert.False(t, hasDeadline)
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/jobs/stream", nil).WithContext(context.Background())
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+}
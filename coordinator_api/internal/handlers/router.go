@@ -2,16 +2,19 @@ package handlers
 
 import (
 	"context"
+	"crypto/rand"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/auth"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/jobcontrol"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/metrics"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/middleware"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
@@ -21,8 +24,6 @@ import (
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/uiapi"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/uiapi/csilapi"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
-
-	"github.com/rs/cors"
 )
 
 var (
@@ -36,6 +37,20 @@ var (
 	singletonObjectStore objects.ObjectStore
 	// Pub/sub bus for live updates — optional; nil disables the WS endpoints.
 	singletonBus *pubsub.Bus
+	// Cached intake pause state behind the maintenance banner
+	singletonIntakeStatus *intakeStatusCache
+	// Cached announcements behind the announcement header
+	singletonAnnouncements *announcementCache
+	// Webhook handler, whose asynchronous processing the coordinator starts
+	singletonWebhookHandler *WebhookHandler
+	// Native merge queue, sharing the webhook handler's VCS clients
+	singletonMergeQueue *MergeQueue
+	// VCS status updater, whose outbox the coordinator dispatches (singleton)
+	singletonStatusUpdater *vcs.JobStatusUpdater
+	// LDAP authenticator for Basic auth, when LDAP is configured (singleton)
+	singletonLDAP *auth.LDAPAuthenticator
+	// Latest migration this build ships, for the doctor's migrations check
+	singletonExpectedMigrationVersion int64
 )
 
 // SetPubSubBus sets the bus used by the WebSocket endpoints. Must be called
@@ -44,6 +59,12 @@ func SetPubSubBus(b *pubsub.Bus) {
 	singletonBus = b
 }
 
+// SetExpectedMigrationVersion sets the migration version the doctor
+// expects the database to be at; unset, its migrations check is skipped.
+func SetExpectedMigrationVersion(version int64) {
+	singletonExpectedMigrationVersion = version
+}
+
 // GetAppMux returns the application's HTTP ServeMux for both API and tests
 // This ensures all tests use the same router configuration as the actual application
 func GetAppMux() *http.ServeMux {
@@ -70,6 +91,125 @@ func GetObjectStore() objects.ObjectStore {
 	return singletonObjectStore
 }
 
+// StartMergeQueue starts sweeping the native merge queues every interval.
+// Must be called after GetAppMux (or NewRouter).
+func StartMergeQueue(ctx context.Context, interval time.Duration) {
+	if singletonMergeQueue != nil {
+		singletonMergeQueue.Start(ctx, interval)
+	}
+}
+
+// StartWebhookQueue makes webhooks acknowledged once stored and processed
+// in the background. Must be called after GetAppMux (or NewRouter) and
+// before serving requests.
+func StartWebhookQueue(ctx context.Context, config WebhookQueueConfig) {
+	if singletonWebhookHandler != nil {
+		singletonWebhookHandler.StartAsync(ctx, config)
+	}
+}
+
+// StartWebhookLoadShedding makes the webhook routes answer 503 while the
+// coordinator is overloaded. Must be called after GetAppMux (or NewRouter)
+// and StartWebhookQueue.
+func StartWebhookLoadShedding(ctx context.Context, config LoadSheddingConfig) {
+	if singletonWebhookHandler != nil {
+		singletonWebhookHandler.StartLoadShedding(ctx, config)
+	}
+}
+
+// StartStatusOutbox starts delivering queued VCS status updates every
+// interval. Must be called after GetAppMux (or NewRouter).
+func StartStatusOutbox(ctx context.Context, interval time.Duration) {
+	if singletonStatusUpdater != nil {
+		singletonStatusUpdater.StartOutboxDispatcher(ctx, interval)
+	}
+}
+
+// StartLogLifecycle starts applying the log lifecycle rules to the object
+// store's logs. Must be called after GetAppMux (or NewRouter); without an
+// object store it does nothing.
+func StartLogLifecycle(ctx context.Context, config jobcontrol.LogLifecycleConfig) {
+	if singletonObjectStore != nil {
+		jobcontrol.NewLogLifecycleManager(store.AppStore, singletonObjectStore, config).Start(ctx)
+	}
+}
+
+// StartJobExport starts exporting each day's jobs to the object store.
+// Must be called after GetAppMux (or NewRouter); without an object store
+// it does nothing.
+func StartJobExport(ctx context.Context, config jobcontrol.JobExportConfig) {
+	if singletonObjectStore != nil {
+		jobcontrol.NewJobExporter(store.AppStore, singletonObjectStore, config).Start(ctx)
+	}
+}
+
+// StartLDAPSync starts syncing LDAP users' roles from their groups every
+// interval. Must be called after GetAppMux (or NewRouter); without LDAP it
+// does nothing.
+func StartLDAPSync(ctx context.Context, interval time.Duration) {
+	if singletonLDAP == nil || interval <= 0 {
+		return
+	}
+	ldapAuth := singletonLDAP
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			changed, err := ldapAuth.Sync(ctx)
+			if err != nil {
+				log.Printf("WARNING: Failed to sync LDAP groups: %v", err)
+			} else if changed > 0 {
+				log.Printf("Updated the roles of %d LDAP users from their groups", changed)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// CheckMasterKeys checks the health of the master keys the secrets routes
+// use (see secrets.MasterKeyManager.CheckHealth). Must be called after
+// GetAppMux (or NewRouter); returns nil if secrets aren't available.
+func CheckMasterKeys() (*secrets.MasterKeyHealth, error) {
+	db := store.GetDB()
+	if singletonKeyManager == nil || db == nil {
+		return nil, nil
+	}
+	return singletonKeyManager.CheckHealth(db)
+}
+
+// StartSecretReencryption re-encrypts secrets and org keys older than maxAge
+// (see secrets.MasterKeyManager.ReencryptStale) every interval. Must be
+// called after GetAppMux (or NewRouter); without secrets it does nothing.
+func StartSecretReencryption(ctx context.Context, interval, maxAge time.Duration) {
+	db := store.GetDB()
+	if singletonKeyManager == nil || db == nil || maxAge <= 0 || interval <= 0 {
+		return
+	}
+	keyManager := singletonKeyManager
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			result, err := keyManager.ReencryptStale(db.WithContext(ctx), maxAge)
+			if err != nil {
+				log.Printf("WARNING: Failed to re-encrypt stale secrets: %v", err)
+			} else if result.OrgKeys > 0 || result.Secrets > 0 || len(result.SkippedOrgs) > 0 {
+				log.Printf("Re-encrypted %d stale org keys and %d stale secrets; skipped orgs without a usable key: %v",
+					result.OrgKeys, result.Secrets, result.SkippedOrgs)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
 // ResetAppMux resets the app mux singleton (useful for testing)
 func ResetAppMux() {
 	appMux = nil
@@ -77,6 +217,11 @@ func ResetAppMux() {
 	singletonObjectStore = nil
 	singletonKeyManager = nil
 	singletonBus = nil
+	singletonIntakeStatus = nil
+	singletonAnnouncements = nil
+	singletonMergeQueue = nil
+	singletonStatusUpdater = nil
+	singletonLDAP = nil
 }
 
 // createAppMux creates and configures the application ServeMux with all routes
@@ -93,6 +238,20 @@ func createAppMux() *http.ServeMux {
 				"prefix":    config.ObjectStorePrefix,
 			},
 		}
+		if config.ObjectStoreSecondaryType != "" {
+			objectStoreConfig.Secondary = &objects.ObjectStoreConfig{
+				Type: config.ObjectStoreSecondaryType,
+				Config: map[string]string{
+					"base_path": config.ObjectStoreSecondaryBasePath,
+					"bucket":    config.ObjectStoreSecondaryBucket,
+					"prefix":    config.ObjectStoreSecondaryPrefix,
+					"region":    config.ObjectStoreSecondaryRegion,
+					"endpoint":  config.ObjectStoreSecondaryEndpoint,
+				},
+			}
+			objectStoreConfig.FailoverThreshold = config.ObjectStoreFailoverThreshold
+			objectStoreConfig.FailoverRetryAfter = time.Duration(config.ObjectStoreFailoverRetrySeconds) * time.Second
+		}
 		var err error
 		singletonObjectStore, err = objects.NewObjectStore(objectStoreConfig)
 		if err != nil {
@@ -106,6 +265,25 @@ func createAppMux() *http.ServeMux {
 	webhookHandler := NewWebhookHandler(store.AppStore, singletoncorndogsClient)
 	projectHandler := NewProjectHandler(store.AppStore)
 	workflowHandler := NewWorkflowHandlerWithCorndogs(store.AppStore, singletoncorndogsClient)
+	costHandler := NewCostHandler(store.AppStore)
+	trashHandler := NewTrashHandler(store.AppStore)
+	activityHandler := NewActivityHandler(store.AppStore)
+	doctorHandler := NewDoctorHandler(DoctorDeps)
+	singletonIntakeStatus = newIntakeStatusCache(store.AppStore)
+	intakeHandler := NewIntakeHandler(store.AppStore, singletoncorndogsClient, singletonIntakeStatus)
+	previewHandler := NewPreviewEnvironmentHandler(store.AppStore, singletoncorndogsClient)
+	queueHandler := NewQueueHandler(store.AppStore, singletoncorndogsClient)
+	runnerImageHandler := NewRunnerImageHandler(store.AppStore)
+	workerHandler := NewWorkerHandler(store.AppStore)
+	freezeHandler := NewFreezeHandler(store.AppStore, singletoncorndogsClient)
+	admissionPolicyHandler := NewAdmissionPolicyHandler(store.AppStore)
+	singletonAnnouncements = newAnnouncementCache(store.AppStore)
+	announcementHandler := NewAnnouncementHandler(store.AppStore, singletonAnnouncements)
+	scimHandler := newSCIMHandler()
+
+	// Worker-facing endpoints take worker tokens only from the worker
+	// network, and only over mutual TLS when it's configured.
+	workerAccess := middleware.WorkerAccessMiddleware(workerAccessPolicyFromConfig())
 
 	// Wire VCS clients into the webhook handler and the job handler's trigger
 	// processor, so jobs submitted via /api/v1/jobs/{id}/triggers register as
@@ -115,6 +293,21 @@ func createAppMux() *http.ServeMux {
 		webhookHandler.AddVCSClient(provider, client)
 	}
 	jobHandler.SetStatusUpdater(vcsManager.GetStatusUpdater())
+	singletonStatusUpdater = vcsManager.GetStatusUpdater()
+
+	// Download URLs for object stores that can't presign their own are
+	// signed by the coordinator and served at objects.SignedObjectPath.
+	signingKey := []byte(config.URLSigningKey)
+	if len(signingKey) == 0 {
+		signingKey = make([]byte, 32)
+		if _, err := rand.Read(signingKey); err != nil {
+			log.Fatalf("Failed to generate URL signing key: %v", err)
+		}
+		log.Printf("WARNING: REACTORCIDE_URL_SIGNING_KEY not set - signed download URLs only work on this replica until it restarts")
+	}
+	urlSigner := objects.NewURLSigner(signingKey, config.PublicAPIURL)
+	jobHandler.SetURLSigner(urlSigner)
+	signedObjectHandler := NewSignedObjectHandler(singletonObjectStore, urlSigner)
 	webhookHandler.SetStatusUpdater(vcsManager.GetStatusUpdater())
 
 	// Wire per-project VCS token resolution into webhook handler.
@@ -152,10 +345,23 @@ func createAppMux() *http.ServeMux {
 		secretsHandler = NewSecretsHandler(store.AppStore, singletonKeyManager)
 		wireWebhookTokenResolver(singletonKeyManager)
 	}
+	singletonWebhookHandler = webhookHandler
+	singletonMergeQueue = NewMergeQueue(webhookHandler)
 
 	// Apply middleware to all handlers
 	transactionMiddleware := middleware.TransactionMiddleware
-	authMiddleware := middleware.APITokenMiddleware(store.AppStore)
+	var basicAuth middleware.BasicAuthenticator
+	if singletonLDAP = newLDAPAuthenticator(); singletonLDAP != nil {
+		basicAuth = singletonLDAP
+	}
+	// Cookie login sessions for browser clients, on the UI's sessions.
+	var sessions *auth.Sessions
+	var sessionResolver middleware.SessionResolver
+	if ss, ok := store.AppStore.(auth.SessionStore); ok {
+		sessions = auth.NewSessions(ss)
+		sessionResolver = sessions
+	}
+	authMiddleware := middleware.AuthMiddleware(store.AppStore, basicAuth, sessionResolver)
 
 	// Health check endpoint
 	mux.HandleFunc("/api/health", func(w http.ResponseWriter, r *http.Request) {
@@ -168,6 +374,29 @@ func createAppMux() *http.ServeMux {
 
 	// API v1 routes with API token authentication
 
+	// Cookie login sessions (require auth)
+	// POST /api/v1/session - Log in with the request's credentials
+	// GET /api/v1/session - Show the cookie session and its CSRF token
+	// DELETE /api/v1/session - Log out
+	if sessions != nil {
+		sessionHandler := NewSessionHandler(sessions)
+		mux.HandleFunc("/api/v1/session", func(w http.ResponseWriter, r *http.Request) {
+			handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case http.MethodPost:
+					sessionHandler.CreateSession(w, r)
+				case http.MethodGet:
+					sessionHandler.GetSession(w, r)
+				case http.MethodDelete:
+					sessionHandler.DeleteSession(w, r)
+				default:
+					http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				}
+			})))
+			handler.ServeHTTP(w, r)
+		})
+	}
+
 	// Workflow routes (require auth)
 	mux.HandleFunc("/api/v1/workflows", func(w http.ResponseWriter, r *http.Request) {
 		handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -256,15 +485,26 @@ func createAppMux() *http.ServeMux {
 		transactionMiddleware(http.HandlerFunc(healthHandler)).ServeHTTP(w, r)
 	})
 
+	// Active announcements, e.g. planned maintenance (v1, no auth required)
+	mux.HandleFunc("/api/v1/announcements", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		transactionMiddleware(http.HandlerFunc(announcementHandler.ListActiveAnnouncements)).ServeHTTP(w, r)
+	})
+
 	// Metrics endpoint (v1, no auth required)
 	mux.Handle("/api/v1/metrics", metrics.Handler())
 
-	// Job routes (require auth)
+	// Job routes (require auth). The job reads have /api/v2 successors and
+	// are deprecated here.
+	v1Deprecations := newV1JobDeprecations(apiV1Sunset())
 	mux.HandleFunc("/api/v1/jobs", func(w http.ResponseWriter, r *http.Request) {
 		handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			switch r.Method {
 			case http.MethodGet:
-				jobHandler.ListJobs(w, r)
+				v1Deprecations.listJobs.wrap(jobHandler.ListJobs)(w, r)
 			case http.MethodPost:
 				jobHandler.CreateJob(w, r)
 			default:
@@ -274,6 +514,25 @@ func createAppMux() *http.ServeMux {
 		handler.ServeHTTP(w, r)
 	})
 
+	// Source uploads for `reactorcide run` (require auth). No transaction:
+	// the upload only touches the object store, and can take a while.
+	mux.HandleFunc(SourceUploadPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		authMiddleware(http.HandlerFunc(jobHandler.UploadSource)).ServeHTTP(w, r)
+	})
+
+	// Signed download URLs (no auth: the signature is the grant)
+	mux.HandleFunc(objects.SignedObjectPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		signedObjectHandler.ServeSignedObject(w, r)
+	})
+
 	mux.HandleFunc("/api/v1/jobs/", func(w http.ResponseWriter, r *http.Request) {
 		path := strings.TrimPrefix(r.URL.Path, "/api/v1/jobs/")
 		if path == "" {
@@ -281,6 +540,19 @@ func createAppMux() *http.ServeMux {
 			return
 		}
 
+		// job_id/debug/attach is a long-lived WebSocket proxy, so like the
+		// /api/v1/ws routes it runs outside the transaction middleware.
+		if strings.HasSuffix(path, "/debug/attach") {
+			jobID := strings.TrimSuffix(path, "/debug/attach")
+			r = r.WithContext(setIDContext(r.Context(), "job_id", jobID))
+			if r.Method != http.MethodGet {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			authMiddleware(http.HandlerFunc(jobHandler.AttachJobDebugSession)).ServeHTTP(w, r)
+			return
+		}
+
 		handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Handle the special case for job_id/cancel
 			if strings.HasSuffix(path, "/cancel") {
@@ -306,6 +578,30 @@ func createAppMux() *http.ServeMux {
 				return
 			}
 
+			// Handle the special case for job_id/restore
+			if strings.HasSuffix(path, "/restore") {
+				jobID := strings.TrimSuffix(path, "/restore")
+				r = r.WithContext(setIDContext(r.Context(), "job_id", jobID))
+				if r.Method == http.MethodPost {
+					jobHandler.RestoreJob(w, r)
+					return
+				}
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+
+			// Handle the special case for job_id/boost
+			if strings.HasSuffix(path, "/boost") {
+				jobID := strings.TrimSuffix(path, "/boost")
+				r = r.WithContext(setIDContext(r.Context(), "job_id", jobID))
+				if r.Method == http.MethodPost {
+					jobHandler.BoostJob(w, r)
+					return
+				}
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+
 			// Handle the special case for job_id/retry
 			if strings.HasSuffix(path, "/retry") {
 				jobID := strings.TrimSuffix(path, "/retry")
@@ -318,6 +614,75 @@ func createAppMux() *http.ServeMux {
 				return
 			}
 
+			// Handle the special case for job_id/artifacts/{name}/url
+			if jobID, rest, ok := strings.Cut(path, "/artifacts/"); ok && strings.HasSuffix(rest, "/url") {
+				r = r.WithContext(setIDContext(r.Context(), "job_id", jobID))
+				r = r.WithContext(setIDContext(r.Context(), "artifact_name", strings.TrimSuffix(rest, "/url")))
+				if r.Method == http.MethodGet {
+					jobHandler.GetJobArtifactURL(w, r)
+					return
+				}
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+
+			// Handle the special cases for job_id/logs/chunks/{stream}[/{sequence}|/commit]
+			if jobID, rest, ok := strings.Cut(path, "/logs/chunks/"); ok {
+				stream, action, _ := strings.Cut(rest, "/")
+				r = r.WithContext(setIDContext(r.Context(), "job_id", jobID))
+				r = r.WithContext(setIDContext(r.Context(), "log_stream", stream))
+				workerAccess(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					switch {
+					case action == "" && r.Method == http.MethodGet:
+						jobHandler.GetLogChunkStatus(w, r)
+					case action == "commit" && r.Method == http.MethodPost:
+						jobHandler.CommitLogChunks(w, r)
+					case action != "" && action != "commit" && r.Method == http.MethodPut:
+						r = r.WithContext(setIDContext(r.Context(), "log_sequence", action))
+						jobHandler.PutLogChunk(w, r)
+					default:
+						http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+					}
+				})).ServeHTTP(w, r)
+				return
+			}
+
+			// Handle the special case for job_id/logs/sections
+			if strings.HasSuffix(path, "/logs/sections") {
+				jobID := strings.TrimSuffix(path, "/logs/sections")
+				r = r.WithContext(setIDContext(r.Context(), "job_id", jobID))
+				if r.Method == http.MethodGet {
+					jobHandler.GetJobLogSections(w, r)
+					return
+				}
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+
+			// Handle the special case for job_id/logs/archive
+			if strings.HasSuffix(path, "/logs/archive") {
+				jobID := strings.TrimSuffix(path, "/logs/archive")
+				r = r.WithContext(setIDContext(r.Context(), "job_id", jobID))
+				if r.Method == http.MethodGet {
+					jobHandler.GetJobLogArchive(w, r)
+					return
+				}
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+
+			// Handle the special case for job_id/logs/url
+			if strings.HasSuffix(path, "/logs/url") {
+				jobID := strings.TrimSuffix(path, "/logs/url")
+				r = r.WithContext(setIDContext(r.Context(), "job_id", jobID))
+				if r.Method == http.MethodGet {
+					jobHandler.GetJobLogURLs(w, r)
+					return
+				}
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+
 			// Handle the special case for job_id/logs
 			if strings.HasSuffix(path, "/logs") {
 				jobID := strings.TrimSuffix(path, "/logs")
@@ -330,6 +695,89 @@ func createAppMux() *http.ServeMux {
 				return
 			}
 
+			// Handle the special cases for job_id/notes[/{note_id}]
+			if jobID, rest, ok := strings.Cut(path, "/notes"); ok && (rest == "" || strings.HasPrefix(rest, "/")) {
+				noteID := strings.TrimPrefix(rest, "/")
+				r = r.WithContext(setIDContext(r.Context(), "job_id", jobID))
+				r = r.WithContext(setIDContext(r.Context(), "note_id", noteID))
+				switch {
+				case noteID == "" && r.Method == http.MethodGet:
+					jobHandler.ListJobNotes(w, r)
+				case noteID == "" && r.Method == http.MethodPost:
+					jobHandler.CreateJobNote(w, r)
+				case noteID != "" && (r.Method == http.MethodPatch || r.Method == http.MethodPut):
+					jobHandler.UpdateJobNote(w, r)
+				case noteID != "" && r.Method == http.MethodDelete:
+					jobHandler.DeleteJobNote(w, r)
+				default:
+					http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				}
+				return
+			}
+
+			// Handle the special case for job_id/children
+			if strings.HasSuffix(path, "/children") {
+				jobID := strings.TrimSuffix(path, "/children")
+				r = r.WithContext(setIDContext(r.Context(), "job_id", jobID))
+				if r.Method == http.MethodGet {
+					jobHandler.ListChildJobs(w, r)
+					return
+				}
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+
+			// Handle the special case for job_id/resolved-config
+			if strings.HasSuffix(path, "/resolved-config") {
+				jobID := strings.TrimSuffix(path, "/resolved-config")
+				r = r.WithContext(setIDContext(r.Context(), "job_id", jobID))
+				if r.Method == http.MethodGet {
+					jobHandler.GetJobResolvedConfig(w, r)
+					return
+				}
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+
+			// Handle the special case for job_id/debug
+			if strings.HasSuffix(path, "/debug") {
+				jobID := strings.TrimSuffix(path, "/debug")
+				r = r.WithContext(setIDContext(r.Context(), "job_id", jobID))
+				switch r.Method {
+				case http.MethodGet:
+					jobHandler.GetJobDebugSession(w, r)
+				case http.MethodDelete:
+					jobHandler.CloseJobDebugSession(w, r)
+				default:
+					http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				}
+				return
+			}
+
+			// Handle the special case for job_id/environment
+			if strings.HasSuffix(path, "/environment") {
+				jobID := strings.TrimSuffix(path, "/environment")
+				r = r.WithContext(setIDContext(r.Context(), "job_id", jobID))
+				if r.Method == http.MethodPost {
+					previewHandler.RegisterEnvironment(w, r)
+					return
+				}
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+
+			// Handle the special case for job_id/findings
+			if strings.HasSuffix(path, "/findings") {
+				jobID := strings.TrimSuffix(path, "/findings")
+				r = r.WithContext(setIDContext(r.Context(), "job_id", jobID))
+				if r.Method == http.MethodGet {
+					jobHandler.ListJobFindings(w, r)
+					return
+				}
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+
 			// Handle the special case for job_id/triggers
 			if strings.HasSuffix(path, "/triggers") {
 				jobID := strings.TrimSuffix(path, "/triggers")
@@ -346,7 +794,7 @@ func createAppMux() *http.ServeMux {
 			r = r.WithContext(setIDContext(r.Context(), "job_id", path))
 			switch r.Method {
 			case http.MethodGet:
-				jobHandler.GetJob(w, r)
+				v1Deprecations.getJob.wrap(jobHandler.GetJob)(w, r)
 			case http.MethodDelete:
 				jobHandler.DeleteJob(w, r)
 			default:
@@ -356,6 +804,31 @@ func createAppMux() *http.ServeMux {
 		handler.ServeHTTP(w, r)
 	})
 
+	registerV2Routes(apiRoutes{mux: mux, version: APIVersion2}, jobHandler, func(next http.Handler) http.Handler {
+		return transactionMiddleware(authMiddleware(next))
+	})
+
+	// Preview environment routes (require auth)
+	mux.HandleFunc("/api/v1/environments/", func(w http.ResponseWriter, r *http.Request) {
+		environmentID := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/environments/"), "/")
+		if environmentID == "" || strings.Contains(environmentID, "/") {
+			http.Error(w, "Invalid path", http.StatusBadRequest)
+			return
+		}
+		r = r.WithContext(setIDContext(r.Context(), "environment_id", environmentID))
+		handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				previewHandler.GetEnvironment(w, r)
+			case http.MethodDelete:
+				previewHandler.TeardownEnvironment(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		})))
+		handler.ServeHTTP(w, r)
+	})
+
 	// Token management routes (require auth)
 	mux.HandleFunc("/api/v1/tokens", func(w http.ResponseWriter, r *http.Request) {
 		handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -371,19 +844,12 @@ func createAppMux() *http.ServeMux {
 		handler.ServeHTTP(w, r)
 	})
 
-	mux.HandleFunc("/api/v1/tokens/", func(w http.ResponseWriter, r *http.Request) {
-		path := strings.TrimPrefix(r.URL.Path, "/api/v1/tokens/")
-		if path == "" {
-			http.Error(w, "Invalid path", http.StatusBadRequest)
-			return
-		}
-
-		r = r.WithContext(setIDContext(r.Context(), "token_id", path))
-
+	// Cost rollups (require auth)
+	mux.HandleFunc("/api/v1/costs", func(w http.ResponseWriter, r *http.Request) {
 		handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			switch r.Method {
-			case http.MethodDelete:
-				tokenHandler.DeleteToken(w, r)
+			case http.MethodGet:
+				costHandler.ListCostRollups(w, r)
 			default:
 				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			}
@@ -391,140 +857,1189 @@ func createAppMux() *http.ServeMux {
 		handler.ServeHTTP(w, r)
 	})
 
-	// WebSocket streams for live job/log updates. Auth same as REST. The
-	// upgrade handshake itself runs through the standard middleware stack;
-	// everything after the upgrade is long-lived.
-	if singletonBus != nil {
-		wsHandler := NewWSHandler(singletonBus, store.AppStore)
-
-		mux.HandleFunc("/api/v1/jobs/stream", func(w http.ResponseWriter, r *http.Request) {
-			if r.Method != http.MethodGet {
+	// GET /api/v1/trash - Deleted projects and jobs that can still be restored
+	mux.HandleFunc("/api/v1/trash", func(w http.ResponseWriter, r *http.Request) {
+		handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				trashHandler.ListTrash(w, r)
+			default:
 				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-				return
 			}
-			authMiddleware(http.HandlerFunc(wsHandler.StreamAllJobs)).ServeHTTP(w, r)
-		})
+		})))
+		handler.ServeHTTP(w, r)
+	})
 
-		mux.HandleFunc("/api/v1/jobs/stream/", func(w http.ResponseWriter, r *http.Request) {
-			// /api/v1/jobs/stream/{job_id}
-			if r.Method != http.MethodGet {
+	// GET /api/v1/activity - Recent jobs, project changes, rotations and approvals the caller can see
+	mux.HandleFunc("/api/v1/activity", func(w http.ResponseWriter, r *http.Request) {
+		handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				activityHandler.ListActivity(w, r)
+			default:
 				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-				return
 			}
-			jobID := strings.TrimPrefix(r.URL.Path, "/api/v1/jobs/stream/")
-			if jobID == "" {
-				http.Error(w, "Invalid path", http.StatusBadRequest)
-				return
-			}
-			r = r.WithContext(setIDContext(r.Context(), "job_id", jobID))
-			authMiddleware(http.HandlerFunc(wsHandler.StreamJob)).ServeHTTP(w, r)
-		})
-	}
+		})))
+		handler.ServeHTTP(w, r)
+	})
 
-	// Webhook routes (no auth required but validated by signature)
-	mux.HandleFunc("/api/v1/webhooks/github", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	// Hard purge from the trash (require admin role)
+	// DELETE /api/v1/admin/trash/projects/{project_id} - Permanently delete a trashed project
+	// DELETE /api/v1/admin/trash/jobs/{job_id} - Permanently delete a trashed job
+	mux.HandleFunc("/api/v1/admin/trash/", func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/api/v1/admin/trash/")
+		parts := strings.Split(path, "/")
+		if len(parts) != 2 || parts[1] == "" || (parts[0] != "projects" && parts[0] != "jobs") {
+			http.Error(w, "Not found", http.StatusNotFound)
 			return
 		}
-		transactionMiddleware(http.HandlerFunc(webhookHandler.HandleGitHubWebhook)).ServeHTTP(w, r)
-	})
+		if parts[0] == "projects" {
+			r = r.WithContext(setIDContext(r.Context(), "project_id", parts[1]))
+		} else {
+			r = r.WithContext(setIDContext(r.Context(), "job_id", parts[1]))
+		}
 
-	mux.HandleFunc("/api/v1/webhooks/gitlab", func(w http.ResponseWriter, r *http.Request) {
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method != http.MethodDelete:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			case parts[0] == "projects":
+				trashHandler.PurgeProject(w, r)
+			default:
+				trashHandler.PurgeJob(w, r)
+			}
+		}))))
+		handler.ServeHTTP(w, r)
+	})
+
+	// Intake pause controls for maintenance windows (require admin role)
+	mux.HandleFunc("/api/v1/admin/intake", func(w http.ResponseWriter, r *http.Request) {
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				intakeHandler.GetIntakeStatus(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		}))))
+		handler.ServeHTTP(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/admin/intake/", func(w http.ResponseWriter, r *http.Request) {
+		action := strings.TrimPrefix(r.URL.Path, "/api/v1/admin/intake/")
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			switch action {
+			case "pause":
+				intakeHandler.PauseIntake(w, r)
+			case "resume":
+				intakeHandler.ResumeIntake(w, r)
+			default:
+				http.Error(w, "Not found", http.StatusNotFound)
+			}
+		}))))
+		handler.ServeHTTP(w, r)
+	})
+
+	// Announcements (require admin role)
+	// GET /api/v1/admin/announcements - List all announcements, including scheduled and ended ones
+	// POST /api/v1/admin/announcements - Create an announcement
+	mux.HandleFunc("/api/v1/admin/announcements", func(w http.ResponseWriter, r *http.Request) {
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				announcementHandler.ListAnnouncements(w, r)
+			case http.MethodPost:
+				announcementHandler.CreateAnnouncement(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		}))))
+		handler.ServeHTTP(w, r)
+	})
+
+	// GET /api/v1/admin/announcements/{announcement_id} - Get an announcement
+	// PUT /api/v1/admin/announcements/{announcement_id} - Update an announcement, e.g. end it early
+	// DELETE /api/v1/admin/announcements/{announcement_id} - Delete an announcement
+	mux.HandleFunc("/api/v1/admin/announcements/", func(w http.ResponseWriter, r *http.Request) {
+		announcementID := strings.TrimPrefix(r.URL.Path, "/api/v1/admin/announcements/")
+		if announcementID == "" || strings.Contains(announcementID, "/") {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		r = r.WithContext(setIDContext(r.Context(), "announcement_id", announcementID))
+
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				announcementHandler.GetAnnouncement(w, r)
+			case http.MethodPut, http.MethodPatch:
+				announcementHandler.UpdateAnnouncement(w, r)
+			case http.MethodDelete:
+				announcementHandler.DeleteAnnouncement(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		}))))
+		handler.ServeHTTP(w, r)
+	})
+
+	// Log storage usage per project (require admin role)
+	mux.HandleFunc("/api/v1/admin/storage", func(w http.ResponseWriter, r *http.Request) {
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				jobHandler.ListStorageUsage(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		}))))
+		handler.ServeHTTP(w, r)
+	})
+
+	// Job data exports for a data warehouse (require admin role)
+	mux.HandleFunc("/api/v1/admin/exports/jobs", func(w http.ResponseWriter, r *http.Request) {
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				jobHandler.ExportJobs(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		}))))
+		handler.ServeHTTP(w, r)
+	})
+
+	// Self-diagnostics (require admin role)
+	// GET /api/v1/admin/doctor - Run the doctor checks against this coordinator
+	mux.HandleFunc("/api/v1/admin/doctor", func(w http.ResponseWriter, r *http.Request) {
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				doctorHandler.Diagnose(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		}))))
+		handler.ServeHTTP(w, r)
+	})
+
+	// Stuck job reports (require admin role)
+	mux.HandleFunc("/api/v1/admin/stuck-jobs", func(w http.ResponseWriter, r *http.Request) {
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				jobHandler.ListStuckJobReports(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		}))))
+		handler.ServeHTTP(w, r)
+	})
+
+	// Runner images workers pre-pull (require admin role)
+	// GET /api/v1/admin/runner-images - The images and each worker's cache
+	// PUT /api/v1/admin/runner-images - Replace the images
+	mux.HandleFunc("/api/v1/admin/runner-images", func(w http.ResponseWriter, r *http.Request) {
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				runnerImageHandler.GetRunnerImages(w, r)
+			case http.MethodPut:
+				runnerImageHandler.SetRunnerImages(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		}))))
+		handler.ServeHTTP(w, r)
+	})
+
+	// POST /api/v1/admin/runner-images/pull - Have every worker pull them again
+	mux.HandleFunc("/api/v1/admin/runner-images/pull", func(w http.ResponseWriter, r *http.Request) {
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			runnerImageHandler.PullRunnerImages(w, r)
+		}))))
+		handler.ServeHTTP(w, r)
+	})
+
+	// Admission policies (require admin role)
+	// GET /api/v1/admin/admission-policies - List policies
+	// POST /api/v1/admin/admission-policies - Create a policy
+	mux.HandleFunc("/api/v1/admin/admission-policies", func(w http.ResponseWriter, r *http.Request) {
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				admissionPolicyHandler.ListAdmissionPolicies(w, r)
+			case http.MethodPost:
+				admissionPolicyHandler.CreateAdmissionPolicy(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		}))))
+		handler.ServeHTTP(w, r)
+	})
+
+	// POST /api/v1/admin/admission-policies/evaluate - Check a document against the policies, or a draft policy
+	mux.HandleFunc("/api/v1/admin/admission-policies/evaluate", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
-		transactionMiddleware(http.HandlerFunc(webhookHandler.HandleGitLabWebhook)).ServeHTTP(w, r)
-	})
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(admissionPolicyHandler.EvaluateAdmission))))
+		handler.ServeHTTP(w, r)
+	})
+
+	// GET /api/v1/admin/admission-policies/{policy_id} - Get a policy
+	// PUT /api/v1/admin/admission-policies/{policy_id} - Update a policy
+	// DELETE /api/v1/admin/admission-policies/{policy_id} - Delete a policy
+	mux.HandleFunc("/api/v1/admin/admission-policies/", func(w http.ResponseWriter, r *http.Request) {
+		policyID := strings.TrimPrefix(r.URL.Path, "/api/v1/admin/admission-policies/")
+		if policyID == "" || strings.Contains(policyID, "/") {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		r = r.WithContext(setIDContext(r.Context(), "policy_id", policyID))
+
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				admissionPolicyHandler.GetAdmissionPolicy(w, r)
+			case http.MethodPut, http.MethodPatch:
+				admissionPolicyHandler.UpdateAdmissionPolicy(w, r)
+			case http.MethodDelete:
+				admissionPolicyHandler.DeleteAdmissionPolicy(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		}))))
+		handler.ServeHTTP(w, r)
+	})
+
+	// SCIM 2.0 provisioning (require admin role and an API token with the scim scope)
+	// GET /scim/v2/ServiceProviderConfig - What this SCIM service supports (no auth, per RFC 7644)
+	mux.HandleFunc("/scim/v2/ServiceProviderConfig", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		scimHandler.ServiceProviderConfig(w, r)
+	})
+
+	// GET /scim/v2/Users - List users, filtered by userName eq "..."
+	// POST /scim/v2/Users - Provision a user
+	mux.HandleFunc("/scim/v2/Users", func(w http.ResponseWriter, r *http.Request) {
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				scimHandler.ListUsers(w, r)
+			case http.MethodPost:
+				scimHandler.CreateUser(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		}))))
+		handler.ServeHTTP(w, r)
+	})
+
+	// GET /scim/v2/Users/{user_id} - Get a user
+	// PUT /scim/v2/Users/{user_id} - Replace a user
+	// PATCH /scim/v2/Users/{user_id} - Patch a user, e.g. deprovision it with active=false
+	// DELETE /scim/v2/Users/{user_id} - Deprovision a user and stop managing it over SCIM
+	mux.HandleFunc("/scim/v2/Users/", func(w http.ResponseWriter, r *http.Request) {
+		userID := strings.TrimPrefix(r.URL.Path, "/scim/v2/Users/")
+		if userID == "" || strings.Contains(userID, "/") {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		r = r.WithContext(setIDContext(r.Context(), "user_id", userID))
+
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				scimHandler.GetUser(w, r)
+			case http.MethodPut:
+				scimHandler.ReplaceUser(w, r)
+			case http.MethodPatch:
+				scimHandler.PatchUser(w, r)
+			case http.MethodDelete:
+				scimHandler.DeleteUser(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		}))))
+		handler.ServeHTTP(w, r)
+	})
+
+	// GET /scim/v2/Groups - List groups, filtered by displayName eq "..."
+	// POST /scim/v2/Groups - Create a group
+	mux.HandleFunc("/scim/v2/Groups", func(w http.ResponseWriter, r *http.Request) {
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				scimHandler.ListGroups(w, r)
+			case http.MethodPost:
+				scimHandler.CreateGroup(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		}))))
+		handler.ServeHTTP(w, r)
+	})
+
+	// GET /scim/v2/Groups/{group_id} - Get a group and its members
+	// PUT /scim/v2/Groups/{group_id} - Replace a group
+	// PATCH /scim/v2/Groups/{group_id} - Add or remove members, rename the group
+	// DELETE /scim/v2/Groups/{group_id} - Delete a group
+	mux.HandleFunc("/scim/v2/Groups/", func(w http.ResponseWriter, r *http.Request) {
+		groupID := strings.TrimPrefix(r.URL.Path, "/scim/v2/Groups/")
+		if groupID == "" || strings.Contains(groupID, "/") {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		r = r.WithContext(setIDContext(r.Context(), "group_id", groupID))
+
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				scimHandler.GetGroup(w, r)
+			case http.MethodPut:
+				scimHandler.ReplaceGroup(w, r)
+			case http.MethodPatch:
+				scimHandler.PatchGroup(w, r)
+			case http.MethodDelete:
+				scimHandler.DeleteGroup(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		}))))
+		handler.ServeHTTP(w, r)
+	})
+
+	// Deploy freeze windows (require admin role)
+	// GET /api/v1/admin/freeze-windows - List windows and whether they're active
+	// POST /api/v1/admin/freeze-windows - Create a window
+	mux.HandleFunc("/api/v1/admin/freeze-windows", func(w http.ResponseWriter, r *http.Request) {
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				freezeHandler.ListFreezeWindows(w, r)
+			case http.MethodPost:
+				freezeHandler.CreateFreezeWindow(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		}))))
+		handler.ServeHTTP(w, r)
+	})
+
+	// GET /api/v1/admin/freeze-windows/{window_id} - Get a window
+	// PUT /api/v1/admin/freeze-windows/{window_id} - Update a window, e.g. turn it on or off
+	// DELETE /api/v1/admin/freeze-windows/{window_id} - Delete a window
+	mux.HandleFunc("/api/v1/admin/freeze-windows/", func(w http.ResponseWriter, r *http.Request) {
+		windowID := strings.TrimPrefix(r.URL.Path, "/api/v1/admin/freeze-windows/")
+		if windowID == "" || strings.Contains(windowID, "/") {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		r = r.WithContext(setIDContext(r.Context(), "window_id", windowID))
+
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				freezeHandler.GetFreezeWindow(w, r)
+			case http.MethodPut, http.MethodPatch:
+				freezeHandler.UpdateFreezeWindow(w, r)
+			case http.MethodDelete:
+				freezeHandler.DeleteFreezeWindow(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		}))))
+		handler.ServeHTTP(w, r)
+	})
+
+	// GET /api/v1/admin/freeze-overrides - The override audit trail
+	// POST /api/v1/admin/freeze-overrides - Release a held deploy job through the freeze
+	mux.HandleFunc("/api/v1/admin/freeze-overrides", func(w http.ResponseWriter, r *http.Request) {
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				freezeHandler.ListFreezeOverrides(w, r)
+			case http.MethodPost:
+				freezeHandler.CreateFreezeOverride(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		}))))
+		handler.ServeHTTP(w, r)
+	})
+
+	// Registered workers (require admin role)
+	// GET /api/v1/admin/workers - List workers and their states
+	mux.HandleFunc("/api/v1/admin/workers", func(w http.ResponseWriter, r *http.Request) {
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			workerHandler.ListWorkers(w, r)
+		}))))
+		handler.ServeHTTP(w, r)
+	})
+
+	// GET /api/v1/admin/workers/{worker_id} - Get a worker
+	// POST /api/v1/admin/workers/{worker_id}/drain - Finish running jobs, take no new ones
+	// POST /api/v1/admin/workers/{worker_id}/quarantine - Drain, and stay out across restarts
+	// POST /api/v1/admin/workers/{worker_id}/resume - Take jobs again
+	// GET /api/v1/admin/workers/{worker_id}/diagnostics - Jobs, executor, disk, as last reported
+	// GET /api/v1/admin/workers/{worker_id}/logs - The worker's own recent log lines
+	mux.HandleFunc("/api/v1/admin/workers/", func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/api/v1/admin/workers/")
+		parts := strings.Split(path, "/")
+		if parts[0] == "" || len(parts) > 2 {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		r = r.WithContext(setIDContext(r.Context(), "worker_id", parts[0]))
+
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(parts) == 1 {
+				if r.Method != http.MethodGet {
+					http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+					return
+				}
+				workerHandler.GetWorker(w, r)
+				return
+			}
+			if parts[1] == "diagnostics" || parts[1] == "logs" {
+				if r.Method != http.MethodGet {
+					http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+					return
+				}
+				if parts[1] == "logs" {
+					workerHandler.GetWorkerLogs(w, r)
+				} else {
+					workerHandler.GetWorkerDiagnostics(w, r)
+				}
+				return
+			}
+			if r.Method != http.MethodPost {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			switch parts[1] {
+			case "drain":
+				workerHandler.DrainWorker(w, r)
+			case "quarantine":
+				workerHandler.QuarantineWorker(w, r)
+			case "resume":
+				workerHandler.ResumeWorker(w, r)
+			default:
+				http.Error(w, "Not found", http.StatusNotFound)
+			}
+		}))))
+		handler.ServeHTTP(w, r)
+	})
+
+	// Service accounts (require admin role)
+	// GET /api/v1/admin/service-accounts - List service accounts
+	// POST /api/v1/admin/service-accounts - Create a service account
+	mux.HandleFunc("/api/v1/admin/service-accounts", func(w http.ResponseWriter, r *http.Request) {
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				tokenHandler.ListServiceAccounts(w, r)
+			case http.MethodPost:
+				tokenHandler.CreateServiceAccount(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		}))))
+		handler.ServeHTTP(w, r)
+	})
+
+	// GET /api/v1/admin/service-accounts/{user_id} - Get a service account
+	// DELETE /api/v1/admin/service-accounts/{user_id} - Deactivate its tokens
+	// GET /api/v1/admin/service-accounts/{user_id}/tokens - List its tokens
+	// POST /api/v1/admin/service-accounts/{user_id}/tokens - Create a token
+	mux.HandleFunc("/api/v1/admin/service-accounts/", func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/api/v1/admin/service-accounts/")
+		parts := strings.Split(path, "/")
+		if parts[0] == "" || len(parts) > 2 || (len(parts) == 2 && parts[1] != "tokens") {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		r = r.WithContext(setIDContext(r.Context(), "user_id", parts[0]))
+
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case len(parts) == 1 && r.Method == http.MethodGet:
+				tokenHandler.GetServiceAccount(w, r)
+			case len(parts) == 1 && r.Method == http.MethodDelete:
+				tokenHandler.DisableServiceAccount(w, r)
+			case len(parts) == 2 && r.Method == http.MethodGet:
+				tokenHandler.ListServiceAccountTokens(w, r)
+			case len(parts) == 2 && r.Method == http.MethodPost:
+				tokenHandler.CreateServiceAccountToken(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		}))))
+		handler.ServeHTTP(w, r)
+	})
+
+	// User data erasure (require admin role)
+	// POST /api/v1/admin/users/{user_id}/erase - Anonymize or delete a user
+	mux.HandleFunc("/api/v1/admin/users/", func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/api/v1/admin/users/")
+		parts := strings.Split(path, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] != "erase" {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		r = r.WithContext(setIDContext(r.Context(), "user_id", parts[0]))
+
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPost:
+				jobHandler.EraseUser(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		}))))
+		handler.ServeHTTP(w, r)
+	})
+
+	// GET /api/v1/admin/user-erasures - List erasure reports
+	mux.HandleFunc("/api/v1/admin/user-erasures", func(w http.ResponseWriter, r *http.Request) {
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				jobHandler.ListUserErasures(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		}))))
+		handler.ServeHTTP(w, r)
+	})
+
+	// Corndogs queue inspection and management (require admin role)
+	// GET /api/v1/admin/queues - List queues with task counts
+	mux.HandleFunc("/api/v1/admin/queues", func(w http.ResponseWriter, r *http.Request) {
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				queueHandler.ListQueues(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		}))))
+		handler.ServeHTTP(w, r)
+	})
+
+	// GET /api/v1/admin/queues/{queue} - List a queue's waiting tasks
+	// POST /api/v1/admin/queues/{queue}/purge - Cancel a queue's waiting jobs
+	mux.HandleFunc("/api/v1/admin/queues/", func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/api/v1/admin/queues/")
+		parts := strings.Split(path, "/")
+		if parts[0] == "" || len(parts) > 2 {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		r = r.WithContext(setIDContext(r.Context(), "queue", parts[0]))
+
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case len(parts) == 1 && r.Method == http.MethodGet:
+				queueHandler.ListQueuedTasks(w, r)
+			case len(parts) == 2 && parts[1] == "purge" && r.Method == http.MethodPost:
+				queueHandler.PurgeQueue(w, r)
+			case len(parts) == 2 && parts[1] != "purge":
+				http.Error(w, "Not found", http.StatusNotFound)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		}))))
+		handler.ServeHTTP(w, r)
+	})
+
+	// POST /api/v1/admin/queued-jobs/{job_id}/move - Move a waiting job to another queue
+	// POST /api/v1/admin/queued-jobs/{job_id}/priority - Change a waiting job's priority
+	mux.HandleFunc("/api/v1/admin/queued-jobs/", func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/api/v1/admin/queued-jobs/")
+		parts := strings.Split(path, "/")
+		if len(parts) != 2 || parts[0] == "" {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		r = r.WithContext(setIDContext(r.Context(), "job_id", parts[0]))
+
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			switch parts[1] {
+			case "move":
+				queueHandler.MoveQueuedJob(w, r)
+			case "priority":
+				queueHandler.ReprioritizeQueuedJob(w, r)
+			default:
+				http.Error(w, "Not found", http.StatusNotFound)
+			}
+		}))))
+		handler.ServeHTTP(w, r)
+	})
+
+	// GET /api/v1/admin/deadletter - List deadlettered jobs with their failure history
+	mux.HandleFunc("/api/v1/admin/deadletter", func(w http.ResponseWriter, r *http.Request) {
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				queueHandler.ListDeadletteredJobs(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		}))))
+		handler.ServeHTTP(w, r)
+	})
+
+	// GET /api/v1/admin/deadletter/{job_id} - Show a job's failure history
+	// POST /api/v1/admin/deadletter/{job_id}/requeue - Submit a deadlettered job again
+	// POST /api/v1/admin/deadletter/{job_id}/fail - Fail a deadlettered job for good
+	mux.HandleFunc("/api/v1/admin/deadletter/", func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/api/v1/admin/deadletter/")
+		parts := strings.Split(path, "/")
+		if parts[0] == "" || len(parts) > 2 {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		r = r.WithContext(setIDContext(r.Context(), "job_id", parts[0]))
+
+		handler := transactionMiddleware(authMiddleware(middleware.RequireRoleMiddleware("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case len(parts) == 1 && r.Method == http.MethodGet:
+				queueHandler.GetDeadletteredJob(w, r)
+			case len(parts) == 2 && parts[1] == "requeue" && r.Method == http.MethodPost:
+				queueHandler.RequeueDeadletteredJob(w, r)
+			case len(parts) == 2 && parts[1] == "fail" && r.Method == http.MethodPost:
+				queueHandler.FailDeadletteredJob(w, r)
+			case len(parts) == 2 && parts[1] != "requeue" && parts[1] != "fail":
+				http.Error(w, "Not found", http.StatusNotFound)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		}))))
+		handler.ServeHTTP(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/tokens/", func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/api/v1/tokens/")
+		if path == "" {
+			http.Error(w, "Invalid path", http.StatusBadRequest)
+			return
+		}
+
+		r = r.WithContext(setIDContext(r.Context(), "token_id", path))
+
+		handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodDelete:
+				tokenHandler.DeleteToken(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		})))
+		handler.ServeHTTP(w, r)
+	})
+
+	// WebSocket streams for live job/log updates. Auth same as REST. The
+	// upgrade handshake itself runs through the standard middleware stack;
+	// everything after the upgrade is long-lived.
+	if singletonBus != nil {
+		wsHandler := NewWSHandler(singletonBus, store.AppStore)
+
+		mux.HandleFunc("/api/v1/jobs/stream", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			authMiddleware(http.HandlerFunc(wsHandler.StreamAllJobs)).ServeHTTP(w, r)
+		})
+
+		mux.HandleFunc("/api/v1/jobs/stream/", func(w http.ResponseWriter, r *http.Request) {
+			// /api/v1/jobs/stream/{job_id}
+			if r.Method != http.MethodGet {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			jobID := strings.TrimPrefix(r.URL.Path, "/api/v1/jobs/stream/")
+			if jobID == "" {
+				http.Error(w, "Invalid path", http.StatusBadRequest)
+				return
+			}
+			r = r.WithContext(setIDContext(r.Context(), "job_id", jobID))
+			authMiddleware(http.HandlerFunc(wsHandler.StreamJob)).ServeHTTP(w, r)
+		})
+	}
+
+	// Webhook routes (no auth required but validated by signature), one per
+	// enabled provider
+	for _, spec := range vcs.EnabledProviders() {
+		provider := spec.Provider
+		mux.HandleFunc("/api/v1/webhooks/"+spec.WebhookPath, func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			webhookHandler.shedLoad(provider, transactionMiddleware(webhookHandler.HandleWebhook(provider))).ServeHTTP(w, r)
+		})
+	}
+
+	// Project routes (require auth)
+	mux.HandleFunc("/api/v1/projects", func(w http.ResponseWriter, r *http.Request) {
+		handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				projectHandler.ListProjects(w, r)
+			case http.MethodPost:
+				projectHandler.CreateProject(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		})))
+		handler.ServeHTTP(w, r)
+	})
+
+	// Project groups: shared defaults for their member projects
+	mux.HandleFunc("/api/v1/project-groups", func(w http.ResponseWriter, r *http.Request) {
+		handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				projectHandler.ListProjectGroups(w, r)
+			case http.MethodPost:
+				projectHandler.CreateProjectGroup(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		})))
+		handler.ServeHTTP(w, r)
+	})
+
+	// GET/PUT/DELETE /api/v1/project-groups/{group_id}
+	// GET /api/v1/project-groups/{group_id}/projects - Member projects
+	// GET /api/v1/project-groups/{group_id}/jobs - Member projects' jobs
+	mux.HandleFunc("/api/v1/project-groups/", func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/api/v1/project-groups/")
+		parts := strings.Split(strings.Trim(path, "/"), "/")
+		if parts[0] == "" || len(parts) > 2 {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		r = r.WithContext(setIDContext(r.Context(), "group_id", parts[0]))
+
+		handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(parts) == 2 {
+				if r.Method != http.MethodGet {
+					http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+					return
+				}
+				switch parts[1] {
+				case "projects":
+					projectHandler.ListProjectGroupProjects(w, r)
+				case "jobs":
+					jobHandler.ListProjectGroupJobs(w, r)
+				default:
+					http.Error(w, "Not found", http.StatusNotFound)
+				}
+				return
+			}
+			switch r.Method {
+			case http.MethodGet:
+				projectHandler.GetProjectGroup(w, r)
+			case http.MethodPut, http.MethodPatch:
+				projectHandler.UpdateProjectGroup(w, r)
+			case http.MethodDelete:
+				projectHandler.DeleteProjectGroup(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		})))
+		handler.ServeHTTP(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/secret-grants", func(w http.ResponseWriter, r *http.Request) {
+		handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				projectHandler.ListGlobalSecretGrants(w, r)
+			case http.MethodPost:
+				projectHandler.CreateGlobalSecretGrant(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		})))
+		handler.ServeHTTP(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/secret-grants/apply", func(w http.ResponseWriter, r *http.Request) {
+		handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			projectHandler.ApplySecretGrants(w, r)
+		})))
+		handler.ServeHTTP(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/secret-grants/", func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/api/v1/secret-grants/")
+		if path == "" || strings.Contains(path, "/") {
+			http.Error(w, "Invalid path", http.StatusBadRequest)
+			return
+		}
+		r = r.WithContext(setIDContext(r.Context(), "grant_id", path))
+		handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				projectHandler.GetGlobalSecretGrant(w, r)
+			case http.MethodPatch, http.MethodPut:
+				projectHandler.UpdateGlobalSecretGrant(w, r)
+			case http.MethodDelete:
+				projectHandler.DeleteGlobalSecretGrant(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		})))
+		handler.ServeHTTP(w, r)
+	})
+
+	mux.HandleFunc("/api/v1/projects/", func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/api/v1/projects/")
+		if path == "" {
+			http.Error(w, "Invalid path", http.StatusBadRequest)
+			return
+		}
+
+		parts := strings.Split(strings.Trim(path, "/"), "/")
+		if len(parts) >= 2 && parts[1] == "secret-grants" {
+			r = r.WithContext(setIDContext(r.Context(), "project_id", parts[0]))
+			if len(parts) == 3 {
+				r = r.WithContext(setIDContext(r.Context(), "grant_id", parts[2]))
+			}
+			handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case len(parts) == 2 && r.Method == http.MethodGet:
+					projectHandler.ListSecretGrants(w, r)
+				case len(parts) == 2 && r.Method == http.MethodPost:
+					projectHandler.CreateSecretGrant(w, r)
+				case len(parts) == 3 && r.Method == http.MethodGet:
+					projectHandler.GetSecretGrant(w, r)
+				case len(parts) == 3 && (r.Method == http.MethodPatch || r.Method == http.MethodPut):
+					projectHandler.UpdateSecretGrant(w, r)
+				case len(parts) == 3 && r.Method == http.MethodDelete:
+					projectHandler.DeleteSecretGrant(w, r)
+				default:
+					http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				}
+			})))
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		if len(parts) >= 2 && parts[1] == "test-quarantines" {
+			r = r.WithContext(setIDContext(r.Context(), "project_id", parts[0]))
+			if len(parts) == 3 {
+				r = r.WithContext(setIDContext(r.Context(), "quarantine_id", parts[2]))
+			}
+			handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case len(parts) == 2 && r.Method == http.MethodGet:
+					projectHandler.ListTestQuarantines(w, r)
+				case len(parts) == 2 && r.Method == http.MethodPost:
+					projectHandler.CreateTestQuarantine(w, r)
+				case len(parts) == 3 && (r.Method == http.MethodPatch || r.Method == http.MethodPut):
+					projectHandler.UpdateTestQuarantine(w, r)
+				case len(parts) == 3 && r.Method == http.MethodDelete:
+					projectHandler.DeleteTestQuarantine(w, r)
+				default:
+					http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				}
+			})))
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		if len(parts) >= 2 && parts[1] == "notes" {
+			r = r.WithContext(setIDContext(r.Context(), "project_id", parts[0]))
+			if len(parts) == 3 {
+				r = r.WithContext(setIDContext(r.Context(), "note_id", parts[2]))
+			}
+			handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case len(parts) == 2 && r.Method == http.MethodGet:
+					projectHandler.ListProjectNotes(w, r)
+				case len(parts) == 2 && r.Method == http.MethodPost:
+					projectHandler.CreateProjectNote(w, r)
+				case len(parts) == 3 && (r.Method == http.MethodPatch || r.Method == http.MethodPut):
+					projectHandler.UpdateProjectNote(w, r)
+				case len(parts) == 3 && r.Method == http.MethodDelete:
+					projectHandler.DeleteProjectNote(w, r)
+				default:
+					http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				}
+			})))
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		if len(parts) >= 2 && parts[1] == "cloud-roles" {
+			r = r.WithContext(setIDContext(r.Context(), "project_id", parts[0]))
+			if len(parts) == 3 {
+				r = r.WithContext(setIDContext(r.Context(), "role_id", parts[2]))
+			}
+			handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case len(parts) == 2 && r.Method == http.MethodGet:
+					projectHandler.ListCloudRoles(w, r)
+				case len(parts) == 2 && r.Method == http.MethodPost:
+					projectHandler.CreateCloudRole(w, r)
+				case len(parts) == 3 && r.Method == http.MethodGet:
+					projectHandler.GetCloudRole(w, r)
+				case len(parts) == 3 && (r.Method == http.MethodPatch || r.Method == http.MethodPut):
+					projectHandler.UpdateCloudRole(w, r)
+				case len(parts) == 3 && r.Method == http.MethodDelete:
+					projectHandler.DeleteCloudRole(w, r)
+				default:
+					http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				}
+			})))
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		if len(parts) >= 2 && len(parts) <= 3 && parts[1] == "downstream-triggers" {
+			r = r.WithContext(setIDContext(r.Context(), "project_id", parts[0]))
+			if len(parts) == 3 {
+				r = r.WithContext(setIDContext(r.Context(), "trigger_id", parts[2]))
+			}
+			handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case len(parts) == 2 && r.Method == http.MethodGet:
+					projectHandler.ListDownstreamTriggers(w, r)
+				case len(parts) == 2 && r.Method == http.MethodPost:
+					projectHandler.CreateDownstreamTrigger(w, r)
+				case len(parts) == 3 && r.Method == http.MethodGet:
+					projectHandler.GetDownstreamTrigger(w, r)
+				case len(parts) == 3 && r.Method == http.MethodDelete:
+					projectHandler.DeleteDownstreamTrigger(w, r)
+				default:
+					http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				}
+			})))
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		if len(parts) >= 2 && len(parts) <= 4 && parts[1] == "promotions" {
+			r = r.WithContext(setIDContext(r.Context(), "project_id", parts[0]))
+			if len(parts) >= 3 && parts[2] != "chain" {
+				r = r.WithContext(setIDContext(r.Context(), "promotion_id", parts[2]))
+			}
+			handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case len(parts) == 2 && r.Method == http.MethodGet:
+					jobHandler.ListPromotions(w, r)
+				case len(parts) == 2 && r.Method == http.MethodPost:
+					jobHandler.CreatePromotion(w, r)
+				case len(parts) == 3 && parts[2] == "chain" && r.Method == http.MethodGet:
+					jobHandler.GetPromotionChain(w, r)
+				case len(parts) == 3 && r.Method == http.MethodGet:
+					jobHandler.GetPromotion(w, r)
+				case len(parts) == 4 && parts[3] == "approve" && r.Method == http.MethodPost:
+					jobHandler.ApprovePromotion(w, r)
+				case len(parts) == 4 && parts[3] == "reject" && r.Method == http.MethodPost:
+					jobHandler.RejectPromotion(w, r)
+				default:
+					http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				}
+			})))
+			handler.ServeHTTP(w, r)
+			return
+		}
 
-	// Project routes (require auth)
-	mux.HandleFunc("/api/v1/projects", func(w http.ResponseWriter, r *http.Request) {
-		handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			switch r.Method {
-			case http.MethodGet:
-				projectHandler.ListProjects(w, r)
-			case http.MethodPost:
-				projectHandler.CreateProject(w, r)
-			default:
-				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		if len(parts) >= 2 && len(parts) <= 3 && parts[1] == "releases" {
+			r = r.WithContext(setIDContext(r.Context(), "project_id", parts[0]))
+			if len(parts) == 3 {
+				r = r.WithContext(setIDContext(r.Context(), "release_id", parts[2]))
 			}
-		})))
-		handler.ServeHTTP(w, r)
-	})
+			handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case len(parts) == 2 && r.Method == http.MethodGet:
+					projectHandler.ListReleases(w, r)
+				case len(parts) == 3 && r.Method == http.MethodGet:
+					projectHandler.GetRelease(w, r)
+				default:
+					http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				}
+			})))
+			handler.ServeHTTP(w, r)
+			return
+		}
 
-	mux.HandleFunc("/api/v1/secret-grants", func(w http.ResponseWriter, r *http.Request) {
-		handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			switch r.Method {
-			case http.MethodGet:
-				projectHandler.ListGlobalSecretGrants(w, r)
-			case http.MethodPost:
-				projectHandler.CreateGlobalSecretGrant(w, r)
-			default:
+		if len(parts) == 2 && parts[1] == "restore" {
+			r = r.WithContext(setIDContext(r.Context(), "project_id", parts[0]))
+			handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPost {
+					projectHandler.RestoreProject(w, r)
+					return
+				}
 				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			}
-		})))
-		handler.ServeHTTP(w, r)
-	})
+			})))
+			handler.ServeHTTP(w, r)
+			return
+		}
 
-	mux.HandleFunc("/api/v1/secret-grants/apply", func(w http.ResponseWriter, r *http.Request) {
-		handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if r.Method != http.MethodPost {
+		if len(parts) == 2 && parts[1] == "run" {
+			r = r.WithContext(setIDContext(r.Context(), "project_id", parts[0]))
+			handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPost {
+					jobHandler.RunProject(w, r)
+					return
+				}
 				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-				return
-			}
-			projectHandler.ApplySecretGrants(w, r)
-		})))
-		handler.ServeHTTP(w, r)
-	})
+			})))
+			handler.ServeHTTP(w, r)
+			return
+		}
 
-	mux.HandleFunc("/api/v1/secret-grants/", func(w http.ResponseWriter, r *http.Request) {
-		path := strings.TrimPrefix(r.URL.Path, "/api/v1/secret-grants/")
-		if path == "" || strings.Contains(path, "/") {
-			http.Error(w, "Invalid path", http.StatusBadRequest)
+		if len(parts) == 2 && parts[1] == "freeze" {
+			r = r.WithContext(setIDContext(r.Context(), "project_id", parts[0]))
+			handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					freezeHandler.GetProjectFreezeStatus(w, r)
+					return
+				}
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			})))
+			handler.ServeHTTP(w, r)
 			return
 		}
-		r = r.WithContext(setIDContext(r.Context(), "grant_id", path))
-		handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			switch r.Method {
-			case http.MethodGet:
-				projectHandler.GetGlobalSecretGrant(w, r)
-			case http.MethodPatch, http.MethodPut:
-				projectHandler.UpdateGlobalSecretGrant(w, r)
-			case http.MethodDelete:
-				projectHandler.DeleteGlobalSecretGrant(w, r)
-			default:
+
+		if len(parts) == 2 && parts[1] == "simulate-event" {
+			r = r.WithContext(setIDContext(r.Context(), "project_id", parts[0]))
+			handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPost {
+					projectHandler.SimulateEvent(w, r)
+					return
+				}
 				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			}
-		})))
-		handler.ServeHTTP(w, r)
-	})
+			})))
+			handler.ServeHTTP(w, r)
+			return
+		}
 
-	mux.HandleFunc("/api/v1/projects/", func(w http.ResponseWriter, r *http.Request) {
-		path := strings.TrimPrefix(r.URL.Path, "/api/v1/projects/")
-		if path == "" {
-			http.Error(w, "Invalid path", http.StatusBadRequest)
+		if len(parts) == 3 && parts[1] == "status-contexts" && parts[2] == "migrate" {
+			r = r.WithContext(setIDContext(r.Context(), "project_id", parts[0]))
+			handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPost {
+					projectHandler.MigrateStatusContexts(w, r)
+					return
+				}
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			})))
+			handler.ServeHTTP(w, r)
 			return
 		}
 
-		parts := strings.Split(strings.Trim(path, "/"), "/")
-		if len(parts) >= 2 && parts[1] == "secret-grants" {
+		if len(parts) == 2 && parts[1] == "events" {
+			r = r.WithContext(setIDContext(r.Context(), "project_id", parts[0]))
+			handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					projectHandler.ListProjectEvents(w, r)
+					return
+				}
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			})))
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		if len(parts) >= 2 && parts[1] == "merge-queue" {
 			r = r.WithContext(setIDContext(r.Context(), "project_id", parts[0]))
 			if len(parts) == 3 {
-				r = r.WithContext(setIDContext(r.Context(), "grant_id", parts[2]))
+				r = r.WithContext(setIDContext(r.Context(), "pr_number", parts[2]))
 			}
 			handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				switch {
 				case len(parts) == 2 && r.Method == http.MethodGet:
-					projectHandler.ListSecretGrants(w, r)
+					projectHandler.GetMergeQueue(w, r)
 				case len(parts) == 2 && r.Method == http.MethodPost:
-					projectHandler.CreateSecretGrant(w, r)
-				case len(parts) == 3 && r.Method == http.MethodGet:
-					projectHandler.GetSecretGrant(w, r)
-				case len(parts) == 3 && (r.Method == http.MethodPatch || r.Method == http.MethodPut):
-					projectHandler.UpdateSecretGrant(w, r)
+					projectHandler.EnqueueMerge(w, r)
 				case len(parts) == 3 && r.Method == http.MethodDelete:
-					projectHandler.DeleteSecretGrant(w, r)
+					projectHandler.DequeueMerge(w, r)
+				default:
+					http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				}
+			})))
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		if len(parts) == 2 && parts[1] == "environments" {
+			r = r.WithContext(setIDContext(r.Context(), "project_id", parts[0]))
+			handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					previewHandler.ListProjectEnvironments(w, r)
+					return
+				}
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			})))
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		if len(parts) >= 2 && len(parts) <= 3 && parts[1] == "canary" {
+			r = r.WithContext(setIDContext(r.Context(), "project_id", parts[0]))
+			handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case len(parts) == 2 && r.Method == http.MethodGet:
+					projectHandler.GetCanary(w, r)
+				case len(parts) == 2 && r.Method == http.MethodPut:
+					projectHandler.SetCanary(w, r)
+				case len(parts) == 2 && r.Method == http.MethodDelete:
+					projectHandler.DeleteCanary(w, r)
+				case len(parts) == 3 && parts[2] == "promote" && r.Method == http.MethodPost:
+					projectHandler.PromoteCanary(w, r)
+				default:
+					http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				}
+			})))
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		if len(parts) >= 2 && len(parts) <= 4 && parts[1] == "history" {
+			r = r.WithContext(setIDContext(r.Context(), "project_id", parts[0]))
+			if len(parts) >= 3 {
+				r = r.WithContext(setIDContext(r.Context(), "version", parts[2]))
+			}
+			handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case len(parts) == 2 && r.Method == http.MethodGet:
+					projectHandler.ListProjectHistory(w, r)
+				case len(parts) == 3 && r.Method == http.MethodGet:
+					projectHandler.GetProjectVersion(w, r)
+				case len(parts) == 4 && parts[3] == "revert" && r.Method == http.MethodPost:
+					projectHandler.RevertProject(w, r)
 				default:
 					http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 				}
@@ -533,6 +2048,32 @@ func createAppMux() *http.ServeMux {
 			return
 		}
 
+		if len(parts) == 2 && parts[1] == "analytics" {
+			r = r.WithContext(setIDContext(r.Context(), "project_id", parts[0]))
+			handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					projectHandler.GetProjectAnalytics(w, r)
+					return
+				}
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			})))
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		if len(parts) == 2 && parts[1] == "deadlines" {
+			r = r.WithContext(setIDContext(r.Context(), "project_id", parts[0]))
+			handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					projectHandler.GetProjectDeadlines(w, r)
+					return
+				}
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			})))
+			handler.ServeHTTP(w, r)
+			return
+		}
+
 		if len(parts) != 1 {
 			http.Error(w, "Invalid path", http.StatusBadRequest)
 			return
@@ -636,6 +2177,39 @@ func createAppMux() *http.ServeMux {
 			handler.ServeHTTP(w, r)
 		})
 
+		// GET /api/v1/secrets/policies - List secret access policies
+		// POST /api/v1/secrets/policies - Create or update a policy
+		mux.HandleFunc("/api/v1/secrets/policies", func(w http.ResponseWriter, r *http.Request) {
+			handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case http.MethodGet:
+					secretsHandler.ListAccessPolicies(w, r)
+				case http.MethodPost:
+					secretsHandler.PutAccessPolicy(w, r)
+				default:
+					http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				}
+			})))
+			handler.ServeHTTP(w, r)
+		})
+
+		// DELETE /api/v1/secrets/policies/{policy_id} - Delete a policy
+		mux.HandleFunc("/api/v1/secrets/policies/", func(w http.ResponseWriter, r *http.Request) {
+			policyID := strings.TrimPrefix(r.URL.Path, "/api/v1/secrets/policies/")
+			if policyID == "" || strings.Contains(policyID, "/") {
+				http.NotFound(w, r)
+				return
+			}
+			handler := transactionMiddleware(authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodDelete {
+					secretsHandler.DeleteAccessPolicy(w, r, policyID)
+				} else {
+					http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				}
+			})))
+			handler.ServeHTTP(w, r)
+		})
+
 		// Admin endpoints for master key management (require admin role)
 		adminMiddleware := middleware.RequireRoleMiddleware("admin")
 
@@ -699,6 +2273,18 @@ func createAppMux() *http.ServeMux {
 			}))))
 			handler.ServeHTTP(w, r)
 		})
+
+		// GET /api/v1/admin/secrets/health - Check master key health
+		mux.HandleFunc("/api/v1/admin/secrets/health", func(w http.ResponseWriter, r *http.Request) {
+			handler := transactionMiddleware(authMiddleware(adminMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					secretsHandler.MasterKeyHealth(w, r)
+				} else {
+					http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				}
+			}))))
+			handler.ServeHTTP(w, r)
+		})
 	}
 
 	// CSIL-RPC UI/Auth endpoint (webapp <-> coordinator management surface,
@@ -721,6 +2307,45 @@ func createAppMux() *http.ServeMux {
 	return mux
 }
 
+// newLDAPAuthenticator builds the LDAP authenticator from the
+// REACTORCIDE_LDAP_* config, or returns nil if LDAP is off or misconfigured.
+func newLDAPAuthenticator() *auth.LDAPAuthenticator {
+	if config.LDAPURL == "" {
+		return nil
+	}
+	ldapStore, ok := store.AppStore.(auth.LDAPStore)
+	if !ok {
+		log.Printf("WARNING: store does not support LDAP users; LDAP authentication disabled")
+		return nil
+	}
+	cfg, err := auth.LDAPConfigFromEnv()
+	if err != nil {
+		log.Printf("WARNING: LDAP is misconfigured, LDAP authentication disabled: %v", err)
+		return nil
+	}
+	if config.LDAPInsecure() {
+		log.Printf("SECURITY WARNING: REACTORCIDE_LDAP_ALLOW_INSECURE is set; LDAP passwords are sent in the clear to %s", config.LDAPURL)
+	}
+	return auth.NewLDAPAuthenticator(cfg, ldapStore, auth.DialLDAPFromEnv)
+}
+
+// newSCIMHandler builds the SCIM handler, mapping groups to roles per
+// REACTORCIDE_SCIM_GROUP_ROLES. SCIM is unavailable (501) if the store
+// doesn't support it or the mapping is misconfigured, rather than
+// provisioning users with the wrong roles.
+func newSCIMHandler() *SCIMHandler {
+	scimStore, ok := store.AppStore.(auth.SCIMStore)
+	if !ok {
+		return NewSCIMHandler(nil, nil)
+	}
+	provisioner, err := auth.SCIMProvisionerFromEnv(scimStore)
+	if err != nil {
+		log.Printf("WARNING: SCIM is misconfigured, SCIM provisioning disabled: %v", err)
+		return NewSCIMHandler(scimStore, nil)
+	}
+	return NewSCIMHandler(scimStore, provisioner)
+}
+
 // buildUIAPIDeps wires the CSIL UI service's dependencies (Task G): seeds
 // the trusted-identity admission list from config, selects a LoginBackend
 // matching auth.CurrentMode() (falling back to the none-mode sentinel
@@ -801,15 +2426,14 @@ func GetContextKey(key string) contextKey {
 func NewRouter(corndogsClient corndogs.ClientInterface) http.Handler {
 	mux := GetAppMuxWithClient(corndogsClient)
 
-	// Set up CORS
-	c := cors.New(cors.Options{
-		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization"},
-		AllowCredentials: true,
-	})
-
-	return c.Handler(mux)
+	var handler http.Handler = limitRequestTime(limitRequestBody(mux))
+	if singletonIntakeStatus != nil {
+		handler = singletonIntakeStatus.bannerMiddleware(handler)
+	}
+	if singletonAnnouncements != nil {
+		handler = singletonAnnouncements.headerMiddleware(handler)
+	}
+	return newCORSHandler(corsPolicyFromConfig(), handler)
 }
 
 // Add a health endpoint that includes verification info
@@ -833,6 +2457,30 @@ func healthHandler(w http.ResponseWriter, r *http.Request) {
 		response["verification"].(map[string]interface{})["user_id"] = user.UserID
 	}
 
+	if singletonIntakeStatus != nil {
+		response["maintenance"] = singletonIntakeStatus.status(r.Context())
+	}
+	if singletonAnnouncements != nil {
+		if active := singletonAnnouncements.active(r.Context()); len(active) > 0 {
+			response["announcement"] = map[string]string{"message": active[0].Message, "severity": active[0].Severity}
+		}
+	}
+
+	// Report regional failover, if configured, so operators can see which
+	// side is serving.
+	failover := map[string]interface{}{}
+	if dbFailover, ok := store.AppStore.(interface{ DatabaseFailover() (bool, bool) }); ok {
+		if configured, failedOver := dbFailover.DatabaseFailover(); configured {
+			failover["database"] = failedOver
+		}
+	}
+	if objectFailover, ok := singletonObjectStore.(interface{ FailedOver() bool }); ok {
+		failover["object_store"] = objectFailover.FailedOver()
+	}
+	if len(failover) > 0 {
+		response["failed_over"] = failover
+	}
+
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// runnerImageStore is the narrow store capability behind the runner image
+// pre-pull endpoints. See postgres_store/settings_operations.go and
+// postgres_store/worker_image_operations.go.
+type runnerImageStore interface {
+	GetGlobalSetting(ctx context.Context, key string) (*models.GlobalSetting, error)
+	SetGlobalSetting(ctx context.Context, key string, value models.JSONValue) error
+	ListWorkerImages(ctx context.Context) ([]models.WorkerImage, error)
+}
+
+// RunnerImageHandler serves the admin controls for the runner images
+// workers pre-pull (see worker.ImagePrePuller).
+type RunnerImageHandler struct {
+	BaseHandler
+	store store.Store
+}
+
+// NewRunnerImageHandler creates a new runner image handler.
+func NewRunnerImageHandler(store store.Store) *RunnerImageHandler {
+	return &RunnerImageHandler{store: store}
+}
+
+// SetRunnerImagesRequest is the JSON body of PUT /api/v1/admin/runner-images.
+type SetRunnerImagesRequest struct {
+	Images          []string `json:"images"`
+	IntervalMinutes int      `json:"interval_minutes,omitempty"`
+}
+
+// WorkerImageCache is one worker's pre-pulled images. Ready is true when
+// it has pulled every configured image for the current generation.
+type WorkerImageCache struct {
+	WorkerID string               `json:"worker_id"`
+	Ready    bool                 `json:"ready"`
+	Images   []models.WorkerImage `json:"images"`
+}
+
+// RunnerImagesResponse is the JSON body of the runner image endpoints: the
+// pre-pull setting and each worker that has reported on it.
+type RunnerImagesResponse struct {
+	models.RunnerImagePrePull
+	Workers []WorkerImageCache `json:"workers"`
+}
+
+// GetRunnerImages handles GET /api/v1/admin/runner-images.
+func (h *RunnerImageHandler) GetRunnerImages(w http.ResponseWriter, r *http.Request) {
+	rs, ok := h.store.(runnerImageStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("runner image pre-pull not available"))
+		return
+	}
+	prePull, err := loadRunnerImagePrePull(r.Context(), rs)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithImages(w, r, rs, prePull)
+}
+
+// SetRunnerImages handles PUT /api/v1/admin/runner-images: it replaces the
+// images workers pre-pull. Workers pull the new list within a minute.
+func (h *RunnerImageHandler) SetRunnerImages(w http.ResponseWriter, r *http.Request) {
+	rs, ok := h.store.(runnerImageStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("runner image pre-pull not available"))
+		return
+	}
+	var req SetRunnerImagesRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	if err := models.ValidateRunnerImages(req.Images); err != nil || req.IntervalMinutes < 0 {
+		msg := "interval_minutes must not be negative"
+		if err != nil {
+			msg = err.Error()
+		}
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: msg})
+		return
+	}
+
+	prePull, err := loadRunnerImagePrePull(r.Context(), rs)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	prePull.Images = req.Images
+	if prePull.Images == nil {
+		prePull.Images = []string{}
+	}
+	prePull.IntervalMinutes = req.IntervalMinutes
+	h.saveAndRespond(w, r, rs, prePull)
+}
+
+// PullRunnerImages handles POST /api/v1/admin/runner-images/pull: every
+// worker pulls the images again within a minute, for example after a tag
+// was pushed.
+func (h *RunnerImageHandler) PullRunnerImages(w http.ResponseWriter, r *http.Request) {
+	rs, ok := h.store.(runnerImageStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("runner image pre-pull not available"))
+		return
+	}
+	prePull, err := loadRunnerImagePrePull(r.Context(), rs)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.saveAndRespond(w, r, rs, prePull)
+}
+
+// saveAndRespond bumps the generation, so workers act on it, and saves the
+// setting.
+func (h *RunnerImageHandler) saveAndRespond(w http.ResponseWriter, r *http.Request, rs runnerImageStore, prePull *models.RunnerImagePrePull) {
+	prePull.Generation++
+	prePull.UpdatedAt = time.Now().UTC()
+	value, err := json.Marshal(prePull)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err := rs.SetGlobalSetting(r.Context(), models.GlobalSettingRunnerImagePrePull, value); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithImages(w, r, rs, prePull)
+}
+
+func (h *RunnerImageHandler) respondWithImages(w http.ResponseWriter, r *http.Request, rs runnerImageStore, prePull *models.RunnerImagePrePull) {
+	images, err := rs.ListWorkerImages(r.Context())
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, RunnerImagesResponse{
+		RunnerImagePrePull: *prePull,
+		Workers:            workerImageCaches(prePull, images),
+	})
+}
+
+// loadRunnerImagePrePull returns the pre-pull setting, empty if unset.
+func loadRunnerImagePrePull(ctx context.Context, rs runnerImageStore) (*models.RunnerImagePrePull, error) {
+	prePull := &models.RunnerImagePrePull{Images: []string{}}
+	setting, err := rs.GetGlobalSetting(ctx, models.GlobalSettingRunnerImagePrePull)
+	if errors.Is(err, store.ErrNotFound) {
+		return prePull, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(setting.Value, prePull); err != nil {
+		return nil, err
+	}
+	return prePull, nil
+}
+
+// workerImageCaches groups the image records, sorted by worker, by worker.
+func workerImageCaches(prePull *models.RunnerImagePrePull, images []models.WorkerImage) []WorkerImageCache {
+	caches := []WorkerImageCache{}
+	for _, image := range images {
+		if len(caches) == 0 || caches[len(caches)-1].WorkerID != image.WorkerID {
+			caches = append(caches, WorkerImageCache{WorkerID: image.WorkerID, Images: []models.WorkerImage{}})
+		}
+		cache := &caches[len(caches)-1]
+		cache.Images = append(cache.Images, image)
+	}
+	for i := range caches {
+		pulled := map[string]bool{}
+		for _, image := range caches[i].Images {
+			if image.Status == models.WorkerImagePulled && image.Generation == prePull.Generation {
+				pulled[image.Image] = true
+			}
+		}
+		caches[i].Ready = true
+		for _, image := range prePull.Images {
+			if !pulled[image] {
+				caches[i].Ready = false
+			}
+		}
+	}
+	return caches
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runnerImageMockStore keeps global settings and worker image records in
+// memory on top of ProjectMockStore.
+type runnerImageMockStore struct {
+	ProjectMockStore
+	settings map[string]models.JSONValue
+	images   []models.WorkerImage
+}
+
+func (m *runnerImageMockStore) GetGlobalSetting(ctx context.Context, key string) (*models.GlobalSetting, error) {
+	value, ok := m.settings[key]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &models.GlobalSetting{Key: key, Value: value}, nil
+}
+
+func (m *runnerImageMockStore) SetGlobalSetting(ctx context.Context, key string, value models.JSONValue) error {
+	m.settings[key] = value
+	return nil
+}
+
+func (m *runnerImageMockStore) ListWorkerImages(ctx context.Context) ([]models.WorkerImage, error) {
+	return m.images, nil
+}
+
+func TestRunnerImageHandler(t *testing.T) {
+	s := &runnerImageMockStore{settings: map[string]models.JSONValue{}}
+	h := NewRunnerImageHandler(s)
+	do := func(method, path, body string) RunnerImagesResponse {
+		req := withUser(httptest.NewRequest(method, path, strings.NewReader(body)))
+		w := httptest.NewRecorder()
+		switch {
+		case strings.HasSuffix(path, "/pull"):
+			h.PullRunnerImages(w, req)
+		case method == http.MethodPut:
+			h.SetRunnerImages(w, req)
+		default:
+			h.GetRunnerImages(w, req)
+		}
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp RunnerImagesResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		return resp
+	}
+
+	resp := do(http.MethodGet, "/api/v1/admin/runner-images", "")
+	assert.Empty(t, resp.Images)
+	assert.Equal(t, 0, resp.Generation)
+	assert.Empty(t, resp.Workers)
+
+	resp = do(http.MethodPut, "/api/v1/admin/runner-images", `{"images":["runner:latest","node:22"],"interval_minutes":30}`)
+	assert.Equal(t, []string{"runner:latest", "node:22"}, resp.Images)
+	assert.Equal(t, 30, resp.IntervalMinutes)
+	assert.Equal(t, 1, resp.Generation)
+
+	s.images = []models.WorkerImage{
+		{WorkerID: "worker-a", Image: "node:22", Status: models.WorkerImagePulled, Generation: 1},
+		{WorkerID: "worker-a", Image: "runner:latest", Status: models.WorkerImagePulled, Generation: 1},
+		{WorkerID: "worker-b", Image: "node:22", Status: models.WorkerImageFailed, Generation: 1},
+		{WorkerID: "worker-b", Image: "runner:latest", Status: models.WorkerImagePulled, Generation: 1},
+	}
+	resp = do(http.MethodGet, "/api/v1/admin/runner-images", "")
+	require.Len(t, resp.Workers, 2)
+	assert.True(t, resp.Workers[0].Ready)
+	assert.Len(t, resp.Workers[0].Images, 2)
+	assert.False(t, resp.Workers[1].Ready)
+
+	// A pull request moves the generation, so no worker is ready until it
+	// has pulled again.
+	resp = do(http.MethodPost, "/api/v1/admin/runner-images/pull", "")
+	assert.Equal(t, 2, resp.Generation)
+	assert.Equal(t, []string{"runner:latest", "node:22"}, resp.Images)
+	assert.False(t, resp.Workers[0].Ready)
+
+	for _, body := range []string{`{"images":["bad image"]}`, `{"images":["a","a"]}`, `{"images":[],"interval_minutes":-1}`} {
+		req := withUser(httptest.NewRequest(http.MethodPut, "/api/v1/admin/runner-images", strings.NewReader(body)))
+		w := httptest.NewRecorder()
+		h.SetRunnerImages(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+}
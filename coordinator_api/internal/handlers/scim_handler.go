@@ -0,0 +1,840 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/admission"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/auth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// SCIM 2.0 schema URNs (RFC 7643, RFC 7644).
+const (
+	scimUserSchema     = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimGroupSchema    = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	scimListSchema     = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	scimErrorSchema    = "urn:ietf:params:scim:api:messages:2.0:Error"
+	scimSPConfigSchema = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+)
+
+// scimContentType is the media type of SCIM requests and responses.
+const scimContentType = "application/scim+json"
+
+// scimMaxResults is the most resources a SCIM list returns at once.
+const scimMaxResults = 200
+
+// scimFilterPattern is the one filter form SCIM clients provision with:
+// `attribute eq "value"`.
+var scimFilterPattern = regexp.MustCompile(`(?i)^\s*([a-z.]+)\s+eq\s+"((?:[^"\\]|\\.)*)"\s*$`)
+
+// SCIMHandler serves SCIM 2.0 Users and Groups under /scim/v2, for
+// identity providers such as Okta to provision users and map their groups
+// to roles.
+type SCIMHandler struct {
+	BaseHandler
+	store       auth.SCIMStore
+	provisioner *auth.SCIMProvisioner
+}
+
+// NewSCIMHandler creates a new SCIM handler. A nil provisioner makes every
+// endpoint respond 501.
+func NewSCIMHandler(st auth.SCIMStore, provisioner *auth.SCIMProvisioner) *SCIMHandler {
+	return &SCIMHandler{store: st, provisioner: provisioner}
+}
+
+// SCIMMeta is the meta attribute of a SCIM resource.
+type SCIMMeta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+	Location     string    `json:"location"`
+}
+
+// SCIMName is the name attribute of a SCIM user.
+type SCIMName struct {
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+	Formatted  string `json:"formatted,omitempty"`
+}
+
+// SCIMMultiValue is an entry of a multi-valued SCIM attribute: an email,
+// a group of a user, a member of a group.
+type SCIMMultiValue struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// SCIMUserResource is a SCIM User.
+type SCIMUserResource struct {
+	Schemas     []string         `json:"schemas"`
+	ID          string           `json:"id,omitempty"`
+	ExternalID  string           `json:"externalId,omitempty"`
+	UserName    string           `json:"userName"`
+	Name        *SCIMName        `json:"name,omitempty"`
+	DisplayName string           `json:"displayName,omitempty"`
+	Emails      []SCIMMultiValue `json:"emails,omitempty"`
+	Active      *bool            `json:"active,omitempty"`
+	Groups      []SCIMMultiValue `json:"groups,omitempty"`
+	Meta        *SCIMMeta        `json:"meta,omitempty"`
+}
+
+// SCIMGroupResource is a SCIM Group.
+type SCIMGroupResource struct {
+	Schemas     []string         `json:"schemas"`
+	ID          string           `json:"id,omitempty"`
+	ExternalID  string           `json:"externalId,omitempty"`
+	DisplayName string           `json:"displayName"`
+	Members     []SCIMMultiValue `json:"members,omitempty"`
+	Meta        *SCIMMeta        `json:"meta,omitempty"`
+}
+
+// SCIMListResponse is a page of SCIM resources.
+type SCIMListResponse struct {
+	Schemas      []string    `json:"schemas"`
+	TotalResults int64       `json:"totalResults"`
+	StartIndex   int         `json:"startIndex"`
+	ItemsPerPage int         `json:"itemsPerPage"`
+	Resources    interface{} `json:"Resources"`
+}
+
+// SCIMError is a SCIM error response.
+type SCIMError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	SCIMType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+// SCIMPatchRequest is the body of a SCIM PATCH.
+type SCIMPatchRequest struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []SCIMPatchOperation `json:"Operations"`
+}
+
+// SCIMPatchOperation is one operation of a SCIM PATCH. Op is matched
+// case-insensitively, since Azure AD sends "Replace".
+type SCIMPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+func (h *SCIMHandler) respondSCIM(w http.ResponseWriter, code int, payload interface{}) {
+	response, err := json.Marshal(payload)
+	if err != nil {
+		h.respondSCIMError(w, http.StatusInternalServerError, "", "failed to marshal response")
+		return
+	}
+	w.Header().Set("Content-Type", scimContentType)
+	w.WriteHeader(code)
+	w.Write(response)
+}
+
+func (h *SCIMHandler) respondSCIMError(w http.ResponseWriter, code int, scimType, detail string) {
+	response, _ := json.Marshal(SCIMError{
+		Schemas:  []string{scimErrorSchema},
+		Status:   strconv.Itoa(code),
+		SCIMType: scimType,
+		Detail:   detail,
+	})
+	w.Header().Set("Content-Type", scimContentType)
+	w.WriteHeader(code)
+	w.Write(response)
+}
+
+// respondSCIMStoreError maps a store or provisioning error to a SCIM error.
+func (h *SCIMHandler) respondSCIMStoreError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		h.respondSCIMError(w, http.StatusNotFound, "", "resource not found")
+	case errors.Is(err, store.ErrAlreadyExists):
+		h.respondSCIMError(w, http.StatusConflict, "uniqueness", "a resource with that name already exists")
+	case errors.Is(err, store.ErrInvalidInput):
+		h.respondSCIMError(w, http.StatusBadRequest, "invalidValue", err.Error())
+	default:
+		h.respondSCIMError(w, http.StatusInternalServerError, "", "internal server error")
+	}
+}
+
+// begin checks SCIM is available and the caller's API token has the scim
+// scope. It reports whether the request may go ahead.
+func (h *SCIMHandler) begin(w http.ResponseWriter, r *http.Request) bool {
+	if h.provisioner == nil {
+		h.respondSCIMError(w, http.StatusNotImplemented, "", "SCIM provisioning is not available")
+		return false
+	}
+	token := checkauth.GetAPITokenFromContext(r.Context())
+	if token == nil || !containsString(token.Scopes, models.SCIMScope) {
+		h.respondSCIMError(w, http.StatusForbidden, "", fmt.Sprintf("an API token with the %q scope is required", models.SCIMScope))
+		return false
+	}
+	return true
+}
+
+func (h *SCIMHandler) decodeSCIM(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.respondSCIMError(w, http.StatusRequestEntityTooLarge, "", "request body too large")
+			return false
+		}
+		h.respondSCIMError(w, http.StatusBadRequest, "invalidSyntax", "request body is not valid JSON")
+		return false
+	}
+	return true
+}
+
+// page parses startIndex (1-based) and count.
+func (h *SCIMHandler) page(w http.ResponseWriter, r *http.Request) (startIndex, count int, ok bool) {
+	startIndex, count = 1, scimMaxResults
+	query := r.URL.Query()
+	if s := query.Get("startIndex"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			h.respondSCIMError(w, http.StatusBadRequest, "invalidValue", "startIndex must be a number")
+			return 0, 0, false
+		}
+		if n > 1 {
+			startIndex = n
+		}
+	}
+	if s := query.Get("count"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			h.respondSCIMError(w, http.StatusBadRequest, "invalidValue", "count must be a number")
+			return 0, 0, false
+		}
+		if n < 0 {
+			n = 0
+		}
+		if n < count {
+			count = n
+		}
+	}
+	return startIndex, count, true
+}
+
+// filterValue parses r's filter, which may only compare attribute, and
+// returns the value it's compared to ("" with no filter).
+func (h *SCIMHandler) filterValue(w http.ResponseWriter, r *http.Request, attribute string) (string, bool) {
+	filter := r.URL.Query().Get("filter")
+	if filter == "" {
+		return "", true
+	}
+	m := scimFilterPattern.FindStringSubmatch(filter)
+	if m == nil || !strings.EqualFold(m[1], attribute) {
+		h.respondSCIMError(w, http.StatusBadRequest, "invalidFilter", fmt.Sprintf(`only filters of the form %s eq "value" are supported`, attribute))
+		return "", false
+	}
+	value, err := strconv.Unquote(`"` + m[2] + `"`)
+	if err != nil {
+		h.respondSCIMError(w, http.StatusBadRequest, "invalidFilter", "invalid filter value")
+		return "", false
+	}
+	return value, true
+}
+
+// ServiceProviderConfig handles GET /scim/v2/ServiceProviderConfig.
+func (h *SCIMHandler) ServiceProviderConfig(w http.ResponseWriter, r *http.Request) {
+	supported := func(ok bool) map[string]interface{} {
+		return map[string]interface{}{"supported": ok}
+	}
+	h.respondSCIM(w, http.StatusOK, map[string]interface{}{
+		"schemas":        []string{scimSPConfigSchema},
+		"patch":          supported(true),
+		"bulk":           map[string]interface{}{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]interface{}{"supported": true, "maxResults": scimMaxResults},
+		"changePassword": supported(false),
+		"sort":           supported(false),
+		"etag":           supported(false),
+		"authenticationSchemes": []map[string]interface{}{{
+			"type":        "oauthbearertoken",
+			"name":        "API token",
+			"description": fmt.Sprintf("A reactorcide API token of an admin, with the %q scope", models.SCIMScope),
+			"primary":     true,
+		}},
+	})
+}
+
+// ListUsers handles GET /scim/v2/Users, filtered by userName eq "...".
+func (h *SCIMHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	if !h.begin(w, r) {
+		return
+	}
+	userName, ok := h.filterValue(w, r, "userName")
+	if !ok {
+		return
+	}
+	startIndex, count, ok := h.page(w, r)
+	if !ok {
+		return
+	}
+	links, total, err := h.store.ListSCIMUsers(r.Context(), userName, count, startIndex-1)
+	if err != nil {
+		h.respondSCIMStoreError(w, err)
+		return
+	}
+	resources := make([]SCIMUserResource, 0, len(links))
+	for i := range links {
+		resource, err := h.userResource(r, &links[i], nil)
+		if err != nil {
+			h.respondSCIMStoreError(w, err)
+			return
+		}
+		resources = append(resources, *resource)
+	}
+	h.respondSCIM(w, http.StatusOK, SCIMListResponse{
+		Schemas:      []string{scimListSchema},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+// GetUser handles GET /scim/v2/Users/{user_id}.
+func (h *SCIMHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	if !h.begin(w, r) {
+		return
+	}
+	link, err := h.store.GetSCIMUser(r.Context(), h.getID(r, "user_id"))
+	if err != nil {
+		h.respondSCIMStoreError(w, err)
+		return
+	}
+	resource, err := h.userResource(r, link, nil)
+	if err != nil {
+		h.respondSCIMStoreError(w, err)
+		return
+	}
+	h.respondSCIM(w, http.StatusOK, resource)
+}
+
+// CreateUser handles POST /scim/v2/Users.
+func (h *SCIMHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	if !h.begin(w, r) {
+		return
+	}
+	var req SCIMUserResource
+	if !h.decodeSCIM(w, r, &req) {
+		return
+	}
+	link, user, err := h.provisioner.CreateUser(r.Context(), scimUserInput(req))
+	if err != nil {
+		h.respondSCIMStoreError(w, err)
+		return
+	}
+	resource, err := h.userResource(r, link, user)
+	if err != nil {
+		h.respondSCIMStoreError(w, err)
+		return
+	}
+	h.respondSCIM(w, http.StatusCreated, resource)
+}
+
+// ReplaceUser handles PUT /scim/v2/Users/{user_id}.
+func (h *SCIMHandler) ReplaceUser(w http.ResponseWriter, r *http.Request) {
+	if !h.begin(w, r) {
+		return
+	}
+	var req SCIMUserResource
+	if !h.decodeSCIM(w, r, &req) {
+		return
+	}
+	h.replaceUser(w, r, req)
+}
+
+// PatchUser handles PATCH /scim/v2/Users/{user_id}: replacing active
+// deprovisions or reactivates the user; userName, externalId,
+// displayName, name and emails may change too.
+func (h *SCIMHandler) PatchUser(w http.ResponseWriter, r *http.Request) {
+	if !h.begin(w, r) {
+		return
+	}
+	var req SCIMPatchRequest
+	if !h.decodeSCIM(w, r, &req) {
+		return
+	}
+	link, err := h.store.GetSCIMUser(r.Context(), h.getID(r, "user_id"))
+	if err != nil {
+		h.respondSCIMStoreError(w, err)
+		return
+	}
+	current, err := h.userResource(r, link, nil)
+	if err != nil {
+		h.respondSCIMStoreError(w, err)
+		return
+	}
+	doc, err := admission.Document(current)
+	if err != nil {
+		h.respondSCIMStoreError(w, err)
+		return
+	}
+	for _, op := range req.Operations {
+		if err := applySCIMPatch(doc, op); err != nil {
+			h.respondSCIMError(w, http.StatusBadRequest, "invalidValue", err.Error())
+			return
+		}
+	}
+	// Azure AD sends active as the string "True" or "False".
+	if s, ok := doc["active"].(string); ok {
+		doc["active"] = strings.EqualFold(s, "true")
+	}
+	var patched SCIMUserResource
+	data, _ := json.Marshal(doc)
+	if err := json.Unmarshal(data, &patched); err != nil {
+		h.respondSCIMError(w, http.StatusBadRequest, "invalidValue", "patched user is invalid")
+		return
+	}
+	h.replaceUser(w, r, patched)
+}
+
+func (h *SCIMHandler) replaceUser(w http.ResponseWriter, r *http.Request, req SCIMUserResource) {
+	link, user, err := h.provisioner.ReplaceUser(r.Context(), h.getID(r, "user_id"), scimUserInput(req))
+	if err != nil {
+		h.respondSCIMStoreError(w, err)
+		return
+	}
+	resource, err := h.userResource(r, link, user)
+	if err != nil {
+		h.respondSCIMStoreError(w, err)
+		return
+	}
+	h.respondSCIM(w, http.StatusOK, resource)
+}
+
+// DeleteUser handles DELETE /scim/v2/Users/{user_id}: the user is
+// deprovisioned and no longer managed over SCIM, but not deleted.
+func (h *SCIMHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	if !h.begin(w, r) {
+		return
+	}
+	if err := h.provisioner.DeleteUser(r.Context(), h.getID(r, "user_id")); err != nil {
+		h.respondSCIMStoreError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListGroups handles GET /scim/v2/Groups, filtered by displayName eq "...".
+func (h *SCIMHandler) ListGroups(w http.ResponseWriter, r *http.Request) {
+	if !h.begin(w, r) {
+		return
+	}
+	displayName, ok := h.filterValue(w, r, "displayName")
+	if !ok {
+		return
+	}
+	startIndex, count, ok := h.page(w, r)
+	if !ok {
+		return
+	}
+	groups, total, err := h.store.ListSCIMGroups(r.Context(), displayName, count, startIndex-1)
+	if err != nil {
+		h.respondSCIMStoreError(w, err)
+		return
+	}
+	resources := make([]SCIMGroupResource, 0, len(groups))
+	for i := range groups {
+		resource, err := h.groupResource(r, &groups[i])
+		if err != nil {
+			h.respondSCIMStoreError(w, err)
+			return
+		}
+		resources = append(resources, *resource)
+	}
+	h.respondSCIM(w, http.StatusOK, SCIMListResponse{
+		Schemas:      []string{scimListSchema},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+// GetGroup handles GET /scim/v2/Groups/{group_id}.
+func (h *SCIMHandler) GetGroup(w http.ResponseWriter, r *http.Request) {
+	if !h.begin(w, r) {
+		return
+	}
+	group, err := h.store.GetSCIMGroup(r.Context(), h.getID(r, "group_id"))
+	if err != nil {
+		h.respondSCIMStoreError(w, err)
+		return
+	}
+	h.respondGroup(w, r, http.StatusOK, group)
+}
+
+// CreateGroup handles POST /scim/v2/Groups.
+func (h *SCIMHandler) CreateGroup(w http.ResponseWriter, r *http.Request) {
+	if !h.begin(w, r) {
+		return
+	}
+	var req SCIMGroupResource
+	if !h.decodeSCIM(w, r, &req) {
+		return
+	}
+	group := &models.SCIMGroup{DisplayName: strings.TrimSpace(req.DisplayName), ExternalID: req.ExternalID}
+	if err := h.provisioner.CreateGroup(r.Context(), group, memberIDs(req.Members)); err != nil {
+		h.respondSCIMStoreError(w, err)
+		return
+	}
+	h.respondGroup(w, r, http.StatusCreated, group)
+}
+
+// ReplaceGroup handles PUT /scim/v2/Groups/{group_id}.
+func (h *SCIMHandler) ReplaceGroup(w http.ResponseWriter, r *http.Request) {
+	if !h.begin(w, r) {
+		return
+	}
+	var req SCIMGroupResource
+	if !h.decodeSCIM(w, r, &req) {
+		return
+	}
+	group, err := h.store.GetSCIMGroup(r.Context(), h.getID(r, "group_id"))
+	if err != nil {
+		h.respondSCIMStoreError(w, err)
+		return
+	}
+	group.DisplayName = strings.TrimSpace(req.DisplayName)
+	group.ExternalID = req.ExternalID
+	if err := h.provisioner.ReplaceGroup(r.Context(), group, memberIDs(req.Members)); err != nil {
+		h.respondSCIMStoreError(w, err)
+		return
+	}
+	h.respondGroup(w, r, http.StatusOK, group)
+}
+
+// PatchGroup handles PATCH /scim/v2/Groups/{group_id}: adding and
+// removing members, replacing them all, and renaming the group.
+func (h *SCIMHandler) PatchGroup(w http.ResponseWriter, r *http.Request) {
+	if !h.begin(w, r) {
+		return
+	}
+	var req SCIMPatchRequest
+	if !h.decodeSCIM(w, r, &req) {
+		return
+	}
+	group, err := h.store.GetSCIMGroup(r.Context(), h.getID(r, "group_id"))
+	if err != nil {
+		h.respondSCIMStoreError(w, err)
+		return
+	}
+
+	ctx := r.Context()
+	current, err := h.store.ListSCIMGroupMembers(ctx, group.GroupID)
+	if err != nil {
+		h.respondSCIMStoreError(w, err)
+		return
+	}
+	members := append([]string(nil), current...)
+	renamed := false
+	for _, op := range req.Operations {
+		path := strings.TrimSpace(op.Path)
+		switch strings.ToLower(op.Op) {
+		case "add", "remove":
+			var ids []string
+			if m := scimMemberPathPattern.FindStringSubmatch(path); m != nil {
+				ids = []string{m[1]}
+			} else if strings.EqualFold(path, "members") {
+				var values []SCIMMultiValue
+				if err := json.Unmarshal(op.Value, &values); err != nil {
+					h.respondSCIMError(w, http.StatusBadRequest, "invalidValue", "members must be a list")
+					return
+				}
+				ids = memberIDs(values)
+			} else {
+				h.respondSCIMError(w, http.StatusBadRequest, "invalidPath", fmt.Sprintf("can't %s %q", op.Op, path))
+				return
+			}
+			if strings.EqualFold(op.Op, "add") {
+				members = applyMemberChanges(members, ids, nil)
+			} else {
+				members = applyMemberChanges(members, nil, ids)
+			}
+		case "replace":
+			var value SCIMGroupResource
+			var err error
+			switch {
+			case path == "":
+				err = json.Unmarshal(op.Value, &value)
+			case strings.EqualFold(path, "displayName"):
+				err = json.Unmarshal(op.Value, &value.DisplayName)
+			case strings.EqualFold(path, "externalId"):
+				err = json.Unmarshal(op.Value, &value.ExternalID)
+			case strings.EqualFold(path, "members"):
+				err = json.Unmarshal(op.Value, &value.Members)
+				if value.Members == nil {
+					value.Members = []SCIMMultiValue{}
+				}
+			default:
+				h.respondSCIMError(w, http.StatusBadRequest, "invalidPath", fmt.Sprintf("can't replace %q", path))
+				return
+			}
+			if err != nil {
+				h.respondSCIMError(w, http.StatusBadRequest, "invalidValue", fmt.Sprintf("invalid value for %q", path))
+				return
+			}
+			if name := strings.TrimSpace(value.DisplayName); name != "" && name != group.DisplayName {
+				group.DisplayName, renamed = name, true
+			}
+			if value.ExternalID != "" && value.ExternalID != group.ExternalID {
+				group.ExternalID, renamed = value.ExternalID, true
+			}
+			if value.Members != nil {
+				members = applyMemberChanges(nil, memberIDs(value.Members), nil)
+			}
+		default:
+			h.respondSCIMError(w, http.StatusBadRequest, "invalidSyntax", fmt.Sprintf("unknown op %q", op.Op))
+			return
+		}
+	}
+
+	if renamed {
+		// A rename can change the role the group maps to, so
+		// ReplaceGroup syncs every member.
+		err = h.provisioner.ReplaceGroup(ctx, group, members)
+	} else if err = h.provisioner.AddMembers(ctx, group.GroupID, subtractStrings(members, current)); err == nil {
+		err = h.provisioner.RemoveMembers(ctx, group.GroupID, subtractStrings(current, members))
+	}
+	if err != nil {
+		h.respondSCIMStoreError(w, err)
+		return
+	}
+	h.respondGroup(w, r, http.StatusOK, group)
+}
+
+// DeleteGroup handles DELETE /scim/v2/Groups/{group_id}.
+func (h *SCIMHandler) DeleteGroup(w http.ResponseWriter, r *http.Request) {
+	if !h.begin(w, r) {
+		return
+	}
+	if err := h.provisioner.DeleteGroup(r.Context(), h.getID(r, "group_id")); err != nil {
+		h.respondSCIMStoreError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// scimMemberPathPattern matches `members[value eq "id"]`, the path Okta
+// removes a single member with.
+var scimMemberPathPattern = regexp.MustCompile(`(?i)^members\[\s*value\s+eq\s+"([^"]+)"\s*\]$`)
+
+func (h *SCIMHandler) respondGroup(w http.ResponseWriter, r *http.Request, code int, group *models.SCIMGroup) {
+	resource, err := h.groupResource(r, group)
+	if err != nil {
+		h.respondSCIMStoreError(w, err)
+		return
+	}
+	h.respondSCIM(w, code, resource)
+}
+
+// userResource renders a SCIM user, loading user if it's nil.
+func (h *SCIMHandler) userResource(r *http.Request, link *models.SCIMUser, user *models.User) (*SCIMUserResource, error) {
+	if user == nil {
+		var err error
+		if user, err = h.store.GetUserByID(r.Context(), link.UserID); err != nil {
+			return nil, err
+		}
+	}
+	groups, err := h.store.ListSCIMGroupsByUser(r.Context(), link.UserID)
+	if err != nil {
+		return nil, err
+	}
+	active := !user.IsDeactivated()
+	resource := &SCIMUserResource{
+		Schemas:     []string{scimUserSchema},
+		ID:          link.UserID,
+		ExternalID:  link.ExternalID,
+		UserName:    link.UserName,
+		DisplayName: link.DisplayName,
+		Active:      &active,
+		Meta: &SCIMMeta{
+			ResourceType: "User",
+			Created:      link.CreatedAt,
+			LastModified: link.UpdatedAt,
+			Location:     "/scim/v2/Users/" + link.UserID,
+		},
+	}
+	if link.GivenName != "" || link.FamilyName != "" {
+		resource.Name = &SCIMName{
+			GivenName:  link.GivenName,
+			FamilyName: link.FamilyName,
+			Formatted:  strings.TrimSpace(link.GivenName + " " + link.FamilyName),
+		}
+	}
+	if user.Email != "" {
+		resource.Emails = []SCIMMultiValue{{Value: user.Email, Type: "work", Primary: true}}
+	}
+	for _, group := range groups {
+		resource.Groups = append(resource.Groups, SCIMMultiValue{Value: group.GroupID, Display: group.DisplayName})
+	}
+	return resource, nil
+}
+
+func (h *SCIMHandler) groupResource(r *http.Request, group *models.SCIMGroup) (*SCIMGroupResource, error) {
+	members, err := h.store.ListSCIMGroupMembers(r.Context(), group.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	resource := &SCIMGroupResource{
+		Schemas:     []string{scimGroupSchema},
+		ID:          group.GroupID,
+		ExternalID:  group.ExternalID,
+		DisplayName: group.DisplayName,
+		Members:     []SCIMMultiValue{},
+		Meta: &SCIMMeta{
+			ResourceType: "Group",
+			Created:      group.CreatedAt,
+			LastModified: group.UpdatedAt,
+			Location:     "/scim/v2/Groups/" + group.GroupID,
+		},
+	}
+	for _, userID := range members {
+		resource.Members = append(resource.Members, SCIMMultiValue{Value: userID})
+	}
+	return resource, nil
+}
+
+// scimUserInput picks what the provisioner needs out of a SCIM user. A
+// user is active unless it says otherwise.
+func scimUserInput(req SCIMUserResource) auth.SCIMUserInput {
+	in := auth.SCIMUserInput{
+		UserName:    req.UserName,
+		ExternalID:  req.ExternalID,
+		DisplayName: req.DisplayName,
+		Active:      req.Active == nil || *req.Active,
+	}
+	if req.Name != nil {
+		in.GivenName, in.FamilyName = req.Name.GivenName, req.Name.FamilyName
+	}
+	for i, email := range req.Emails {
+		if i == 0 || email.Primary {
+			in.Email = email.Value
+		}
+		if email.Primary {
+			break
+		}
+	}
+	return in
+}
+
+// applySCIMPatch applies a PATCH operation to a user document. Paths are
+// an attribute, "name.<sub-attribute>", or an emails filter such as
+// `emails[type eq "work"].value`, which sets the one email kept.
+func applySCIMPatch(doc map[string]interface{}, op SCIMPatchOperation) error {
+	var value interface{}
+	if len(op.Value) > 0 {
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return fmt.Errorf("invalid value: %w", err)
+		}
+	}
+	path := strings.TrimSpace(op.Path)
+	switch strings.ToLower(op.Op) {
+	case "add", "replace":
+		if path == "" {
+			attrs, ok := value.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("value must be an object when there's no path")
+			}
+			for key, v := range attrs {
+				if err := setSCIMAttribute(doc, key, v); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return setSCIMAttribute(doc, path, value)
+	case "remove":
+		if path == "" {
+			return fmt.Errorf("remove needs a path")
+		}
+		return setSCIMAttribute(doc, path, nil)
+	default:
+		return fmt.Errorf("unknown op %q", op.Op)
+	}
+}
+
+func setSCIMAttribute(doc map[string]interface{}, path string, value interface{}) error {
+	lower := strings.ToLower(path)
+	switch {
+	case strings.HasPrefix(lower, "emails"):
+		if s, ok := value.(string); ok {
+			value = []interface{}{map[string]interface{}{"value": s, "type": "work", "primary": true}}
+		}
+		doc["emails"] = value
+	case strings.HasPrefix(lower, "name."):
+		name, _ := doc["name"].(map[string]interface{})
+		if name == nil {
+			name = map[string]interface{}{}
+		}
+		name[path[len("name."):]] = value
+		doc["name"] = name
+	case strings.Contains(path, ".") || strings.Contains(path, "["):
+		return fmt.Errorf("unsupported path %q", path)
+	default:
+		// Attribute names are case-insensitive; store them under the
+		// spelling SCIMUserResource decodes.
+		for _, key := range []string{"userName", "externalId", "displayName", "name", "active", "emails"} {
+			if strings.EqualFold(key, path) {
+				path = key
+			}
+		}
+		if value == nil {
+			delete(doc, path)
+		} else {
+			doc[path] = value
+		}
+	}
+	return nil
+}
+
+func memberIDs(members []SCIMMultiValue) []string {
+	ids := make([]string, 0, len(members))
+	for _, m := range members {
+		if m.Value != "" {
+			ids = append(ids, m.Value)
+		}
+	}
+	return ids
+}
+
+// subtractStrings returns the elements of a that aren't in b.
+func subtractStrings(a, b []string) []string {
+	var out []string
+	for _, s := range a {
+		if !containsString(b, s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// applyMemberChanges returns members with added appended and removed left
+// out, without duplicates.
+func applyMemberChanges(members, added, removed []string) []string {
+	drop := make(map[string]bool, len(removed))
+	for _, id := range removed {
+		drop[id] = true
+	}
+	seen := map[string]bool{}
+	var out []string
+	for _, id := range append(append([]string(nil), members...), added...) {
+		if !drop[id] && !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
@@ -0,0 +1,306 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/auth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scimMockStore keeps users, SCIM links and groups in memory.
+type scimMockStore struct {
+	nextID          int
+	users           map[string]models.User
+	links           map[string]models.SCIMUser
+	groups          map[string]models.SCIMGroup
+	members         map[string]map[string]bool
+	tokensOff       map[string]bool
+	sessionsRevoked map[string]bool
+}
+
+func newSCIMMockStore() *scimMockStore {
+	return &scimMockStore{
+		users:           map[string]models.User{},
+		links:           map[string]models.SCIMUser{},
+		groups:          map[string]models.SCIMGroup{},
+		members:         map[string]map[string]bool{},
+		tokensOff:       map[string]bool{},
+		sessionsRevoked: map[string]bool{},
+	}
+}
+
+func (m *scimMockStore) id(prefix string) string {
+	m.nextID++
+	return fmt.Sprintf("%s-%d", prefix, m.nextID)
+}
+
+func (m *scimMockStore) CreateUser(ctx context.Context, user *models.User) error {
+	user.UserID = m.id("user")
+	user.Roles = pq.StringArray{"user"}
+	m.users[user.UserID] = *user
+	return nil
+}
+
+func (m *scimMockStore) GetUserByID(ctx context.Context, userID string) (*models.User, error) {
+	user, ok := m.users[userID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &user, nil
+}
+
+func (m *scimMockStore) UpdateUser(ctx context.Context, user *models.User) error {
+	m.users[user.UserID] = *user
+	return nil
+}
+
+func (m *scimMockStore) SetUserRoles(ctx context.Context, userID string, roles []string) error {
+	user := m.users[userID]
+	user.Roles = roles
+	m.users[userID] = user
+	return nil
+}
+
+func (m *scimMockStore) SetUserDeactivated(ctx context.Context, userID string, deactivatedAt *time.Time) error {
+	user := m.users[userID]
+	user.DeactivatedAt = deactivatedAt
+	m.users[userID] = user
+	return nil
+}
+
+func (m *scimMockStore) DeactivateAPITokensByUser(ctx context.Context, userID string) (int64, error) {
+	m.tokensOff[userID] = true
+	return 1, nil
+}
+
+func (m *scimMockStore) RevokeUISessionsByUser(ctx context.Context, userID string) (int64, error) {
+	m.sessionsRevoked[userID] = true
+	return 1, nil
+}
+
+func (m *scimMockStore) GetSCIMUser(ctx context.Context, userID string) (*models.SCIMUser, error) {
+	link, ok := m.links[userID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &link, nil
+}
+
+func (m *scimMockStore) GetSCIMUserByUserName(ctx context.Context, userName string) (*models.SCIMUser, error) {
+	for _, link := range m.links {
+		if link.UserName == strings.ToLower(userName) {
+			return &link, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (m *scimMockStore) ListSCIMUsers(ctx context.Context, userName string, limit, offset int) ([]models.SCIMUser, int64, error) {
+	var out []models.SCIMUser
+	for _, link := range m.links {
+		if userName == "" || link.UserName == strings.ToLower(userName) {
+			out = append(out, link)
+		}
+	}
+	return out, int64(len(out)), nil
+}
+
+func (m *scimMockStore) CreateSCIMUser(ctx context.Context, link *models.SCIMUser) error {
+	m.links[link.UserID] = *link
+	return nil
+}
+
+func (m *scimMockStore) UpdateSCIMUser(ctx context.Context, link *models.SCIMUser) error {
+	m.links[link.UserID] = *link
+	return nil
+}
+
+func (m *scimMockStore) DeleteSCIMUser(ctx context.Context, userID string) error {
+	delete(m.links, userID)
+	return nil
+}
+
+func (m *scimMockStore) GetSCIMGroup(ctx context.Context, groupID string) (*models.SCIMGroup, error) {
+	group, ok := m.groups[groupID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &group, nil
+}
+
+func (m *scimMockStore) ListSCIMGroups(ctx context.Context, displayName string, limit, offset int) ([]models.SCIMGroup, int64, error) {
+	var out []models.SCIMGroup
+	for _, group := range m.groups {
+		if displayName == "" || strings.EqualFold(group.DisplayName, displayName) {
+			out = append(out, group)
+		}
+	}
+	return out, int64(len(out)), nil
+}
+
+func (m *scimMockStore) ListSCIMGroupsByUser(ctx context.Context, userID string) ([]models.SCIMGroup, error) {
+	var out []models.SCIMGroup
+	for groupID, members := range m.members {
+		if members[userID] {
+			out = append(out, m.groups[groupID])
+		}
+	}
+	return out, nil
+}
+
+func (m *scimMockStore) CreateSCIMGroup(ctx context.Context, group *models.SCIMGroup) error {
+	group.GroupID = m.id("group")
+	m.groups[group.GroupID] = *group
+	m.members[group.GroupID] = map[string]bool{}
+	return nil
+}
+
+func (m *scimMockStore) UpdateSCIMGroup(ctx context.Context, group *models.SCIMGroup) error {
+	m.groups[group.GroupID] = *group
+	return nil
+}
+
+func (m *scimMockStore) DeleteSCIMGroup(ctx context.Context, groupID string) error {
+	delete(m.groups, groupID)
+	delete(m.members, groupID)
+	return nil
+}
+
+func (m *scimMockStore) ListSCIMGroupMembers(ctx context.Context, groupID string) ([]string, error) {
+	var out []string
+	for userID := range m.members[groupID] {
+		out = append(out, userID)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func (m *scimMockStore) AddSCIMGroupMembers(ctx context.Context, groupID string, userIDs []string) error {
+	for _, userID := range userIDs {
+		m.members[groupID][userID] = true
+	}
+	return nil
+}
+
+func (m *scimMockStore) RemoveSCIMGroupMembers(ctx context.Context, groupID string, userIDs []string) error {
+	for _, userID := range userIDs {
+		delete(m.members[groupID], userID)
+	}
+	return nil
+}
+
+// withSCIMToken authenticates r with an API token carrying scopes.
+func withSCIMToken(r *http.Request, scopes ...string) *http.Request {
+	ctx := checkauth.SetUserContext(r.Context(), &models.User{UserID: "admin-id", Roles: pq.StringArray{"admin"}})
+	ctx = checkauth.SetAPITokenContext(ctx, &models.APIToken{Scopes: scopes})
+	return r.WithContext(ctx)
+}
+
+func TestSCIMHandler_RequiresSCIMScope(t *testing.T) {
+	s := newSCIMMockStore()
+	h := NewSCIMHandler(s, auth.NewSCIMProvisioner(s, nil))
+
+	w := httptest.NewRecorder()
+	h.ListUsers(w, withSCIMToken(httptest.NewRequest(http.MethodGet, "/scim/v2/Users", nil)))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, scimContentType, w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), scimErrorSchema)
+
+	w = httptest.NewRecorder()
+	h.ListUsers(w, withSCIMToken(httptest.NewRequest(http.MethodGet, "/scim/v2/Users", nil), models.SCIMScope))
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestSCIMHandler_ProvisionAndDeprovision(t *testing.T) {
+	s := newSCIMMockStore()
+	h := NewSCIMHandler(s, auth.NewSCIMProvisioner(s, map[string]string{"reactorcide-admins": "admin"}))
+	do := func(method, path, id, idKey, body string, fn http.HandlerFunc) *httptest.ResponseRecorder {
+		t.Helper()
+		req := withSCIMToken(httptest.NewRequest(method, path, strings.NewReader(body)), models.SCIMScope)
+		if id != "" {
+			req = req.WithContext(setIDContext(req.Context(), idKey, id))
+		}
+		w := httptest.NewRecorder()
+		fn(w, req)
+		return w
+	}
+
+	// Okta creates the user...
+	w := do(http.MethodPost, "/scim/v2/Users", "", "", `{
+		"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+		"userName": "Alice@Example.com",
+		"name": {"givenName": "Alice", "familyName": "Smith"},
+		"emails": [{"value": "alice@example.com", "primary": true, "type": "work"}],
+		"active": true
+	}`, h.CreateUser)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created SCIMUserResource
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&created))
+	assert.Equal(t, "alice@example.com", created.UserName)
+	require.NotNil(t, created.Active)
+	assert.True(t, *created.Active)
+
+	// ...finds it again by userName...
+	w = do(http.MethodGet, `/scim/v2/Users?filter=userName+eq+%22alice%40example.com%22`, "", "", "", h.ListUsers)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var list SCIMListResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&list))
+	assert.Equal(t, int64(1), list.TotalResults)
+
+	w = do(http.MethodGet, `/scim/v2/Users?filter=emails+co+%22example%22`, "", "", "", h.ListUsers)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalidFilter")
+
+	// ...puts it in a group mapped to admin...
+	w = do(http.MethodPost, "/scim/v2/Groups", "", "", `{"displayName": "reactorcide-admins", "members": []}`, h.CreateGroup)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var group SCIMGroupResource
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&group))
+	w = do(http.MethodPatch, "/scim/v2/Groups/"+group.ID, group.ID, "group_id", `{
+		"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+		"Operations": [{"op": "add", "path": "members", "value": [{"value": "`+created.ID+`"}]}]
+	}`, h.PatchGroup)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, []string{"admin", "user"}, []string(s.users[created.ID].Roles))
+
+	// ...and deactivates it.
+	w = do(http.MethodPatch, "/scim/v2/Users/"+created.ID, created.ID, "user_id", `{
+		"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+		"Operations": [{"op": "replace", "value": {"active": false}}]
+	}`, h.PatchUser)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var patched SCIMUserResource
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&patched))
+	require.NotNil(t, patched.Active)
+	assert.False(t, *patched.Active)
+	assert.Equal(t, "Alice", patched.Name.GivenName)
+	assert.True(t, s.tokensOff[created.ID])
+	assert.True(t, s.sessionsRevoked[created.ID])
+	assert.Equal(t, []string{"user"}, []string(s.users[created.ID].Roles))
+
+	// Removing the member with Okta's filter path keeps the group.
+	w = do(http.MethodPatch, "/scim/v2/Groups/"+group.ID, group.ID, "group_id", `{
+		"Operations": [{"op": "remove", "path": "members[value eq \"`+created.ID+`\"]"}]
+	}`, h.PatchGroup)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Empty(t, s.members[group.ID])
+
+	w = do(http.MethodDelete, "/scim/v2/Users/"+created.ID, created.ID, "user_id", "", h.DeleteUser)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	_, linked := s.links[created.ID]
+	assert.False(t, linked)
+	assert.NotNil(t, s.users[created.ID].DeactivatedAt)
+}
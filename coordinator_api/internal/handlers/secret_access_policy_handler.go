@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/secrets"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// SecretAccessPolicyRequest is the body of POST /api/v1/secrets/policies.
+type SecretAccessPolicyRequest struct {
+	SubjectType string `json:"subject_type"`
+	Subject     string `json:"subject"`
+	PathPattern string `json:"path_pattern"`
+	Access      string `json:"access"`
+	Description string `json:"description,omitempty"`
+}
+
+// ListSecretAccessPoliciesResponse is the response of
+// GET /api/v1/secrets/policies.
+type ListSecretAccessPoliciesResponse struct {
+	Policies []models.SecretAccessPolicy `json:"policies"`
+	Total    int                         `json:"total"`
+}
+
+// ListAccessPolicies handles GET /api/v1/secrets/policies
+func (h *SecretsHandler) ListAccessPolicies(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := h.accessPolicyOrg(w, r)
+	if !ok {
+		return
+	}
+	policies, err := secrets.ListAccessPolicies(r.Context(), store.GetDBFromContext(r.Context()), orgID)
+	if err != nil {
+		h.respondWithJSON(w, http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "failed to list secret access policies",
+		})
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, ListSecretAccessPoliciesResponse{Policies: policies, Total: len(policies)})
+}
+
+// PutAccessPolicy handles POST /api/v1/secrets/policies: creates the
+// policy, or replaces the access of the existing policy for the same
+// subject and path pattern.
+func (h *SecretsHandler) PutAccessPolicy(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := h.accessPolicyOrg(w, r)
+	if !ok {
+		return
+	}
+	var req SecretAccessPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_input",
+			Message: "invalid request body",
+		})
+		return
+	}
+
+	policy := &models.SecretAccessPolicy{
+		OrgID:       orgID,
+		SubjectType: req.SubjectType,
+		Subject:     req.Subject,
+		PathPattern: req.PathPattern,
+		Access:      req.Access,
+		Description: req.Description,
+	}
+	if err := secrets.PutAccessPolicy(r.Context(), store.GetDBFromContext(r.Context()), policy); err != nil {
+		if errors.Is(err, secrets.ErrInvalidAccessPolicy) {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error:   "invalid_input",
+				Message: err.Error(),
+			})
+			return
+		}
+		h.respondWithJSON(w, http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "failed to save secret access policy",
+		})
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, policy)
+}
+
+// DeleteAccessPolicy handles DELETE /api/v1/secrets/policies/{policy_id}
+func (h *SecretsHandler) DeleteAccessPolicy(w http.ResponseWriter, r *http.Request, policyID string) {
+	orgID, ok := h.accessPolicyOrg(w, r)
+	if !ok {
+		return
+	}
+	deleted, err := secrets.DeleteAccessPolicy(r.Context(), store.GetDBFromContext(r.Context()), orgID, policyID)
+	if err != nil {
+		h.respondWithJSON(w, http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "failed to delete secret access policy",
+		})
+		return
+	}
+	if !deleted {
+		h.respondWithJSON(w, http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: "secret access policy not found",
+		})
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// accessPolicyOrg returns the org whose policies the caller manages. A
+// scoped API token can't manage policies, or it could widen its own
+// access.
+func (h *SecretsHandler) accessPolicyOrg(w http.ResponseWriter, r *http.Request) (string, bool) {
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithJSON(w, http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "user not authenticated",
+		})
+		return "", false
+	}
+	if token := checkauth.GetAPITokenFromContext(r.Context()); token != nil && len(token.Scopes) > 0 {
+		h.respondWithJSON(w, http.StatusForbidden, ErrorResponse{
+			Error:   "forbidden",
+			Message: "scoped API tokens cannot manage secret access policies",
+		})
+		return "", false
+	}
+	orgID := user.UserID
+	if err := secrets.NewOrgAuthorizer(store.GetDBFromContext(r.Context())).CanAccessOrg(r.Context(), user.UserID, orgID); err != nil {
+		h.respondWithJSON(w, http.StatusForbidden, ErrorResponse{
+			Error:   "forbidden",
+			Message: err.Error(),
+		})
+		return "", false
+	}
+	return orgID, true
+}
@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -10,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/repourl"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
 )
@@ -65,8 +65,7 @@ func (h *ProjectHandler) ApplySecretGrants(w http.ResponseWriter, r *http.Reques
 		return
 	}
 	var req SecretGrantApplyRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+	if !h.decodeJSON(w, r, &req) {
 		return
 	}
 
@@ -178,8 +177,7 @@ func (h *ProjectHandler) createSecretGrantWithScope(w http.ResponseWriter, r *ht
 		return
 	}
 	var req SecretGrantRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+	if !h.decodeJSON(w, r, &req) {
 		return
 	}
 	if !projectRoute {
@@ -262,8 +260,7 @@ func (h *ProjectHandler) updateSecretGrantWithScope(w http.ResponseWriter, r *ht
 		return
 	}
 	var req SecretGrantRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+	if !h.decodeJSON(w, r, &req) {
 		return
 	}
 	grant, err := grantStore.GetSecretGrant(r.Context(), ownerID, projectID, ref)
@@ -357,7 +354,7 @@ func (h *ProjectHandler) resolveSecretGrantProject(r *http.Request, ref string)
 		return nil, err
 	}
 	for _, project := range projects {
-		if project.ProjectID == ref || project.Name == ref || project.RepoURL == ref {
+		if project.ProjectID == ref || project.Name == ref || repourl.Match(project.RepoURL, ref) {
 			return &project, nil
 		}
 	}
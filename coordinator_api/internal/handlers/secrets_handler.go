@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
-	"strings"
 
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/secrets"
@@ -94,7 +93,41 @@ func (h *SecretsHandler) getProvider(r *http.Request) (secrets.Provider, error)
 		return nil, err
 	}
 
-	return secrets.NewDatabaseProvider(db, orgID, orgKey)
+	provider, err := secrets.NewDatabaseProvider(db, orgID, orgKey)
+	if err != nil {
+		return nil, err
+	}
+	access, err := secrets.LoadAccessPolicy(r.Context(), db, orgID, secretsPrincipal(r))
+	if err != nil {
+		return nil, err
+	}
+	provider.RestrictTo(access)
+	return provider, nil
+}
+
+// secretsPrincipal is the caller as secret access policies see them.
+func secretsPrincipal(r *http.Request) secrets.Principal {
+	principal := secrets.Principal{}
+	if user := checkauth.GetUserFromContext(r.Context()); user != nil {
+		principal.UserID = user.UserID
+		principal.Roles = user.Roles
+	}
+	if token := checkauth.GetAPITokenFromContext(r.Context()); token != nil {
+		principal.TokenScopes = token.Scopes
+	}
+	return principal
+}
+
+// respondIfDenied responds 403 if err is an access policy denial.
+func (h *SecretsHandler) respondIfDenied(w http.ResponseWriter, err error) bool {
+	if !secrets.IsAuthorizationError(err) {
+		return false
+	}
+	h.respondWithJSON(w, http.StatusForbidden, ErrorResponse{
+		Error:   "forbidden",
+		Message: err.Error(),
+	})
+	return true
 }
 
 // GetSecret handles GET /api/v1/secrets/value?path=...&key=...
@@ -135,6 +168,9 @@ func (h *SecretsHandler) GetSecret(w http.ResponseWriter, r *http.Request) {
 
 	value, err := provider.Get(r.Context(), path, key)
 	if err != nil {
+		if h.respondIfDenied(w, err) {
+			return
+		}
 		if errors.Is(err, secrets.ErrInvalidPath) || errors.Is(err, secrets.ErrInvalidKey) {
 			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{
 				Error:   "invalid_input",
@@ -206,6 +242,9 @@ func (h *SecretsHandler) SetSecret(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := provider.Set(r.Context(), path, key, req.Value); err != nil {
+		if h.respondIfDenied(w, err) {
+			return
+		}
 		if errors.Is(err, secrets.ErrInvalidPath) || errors.Is(err, secrets.ErrInvalidKey) {
 			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{
 				Error:   "invalid_input",
@@ -261,6 +300,9 @@ func (h *SecretsHandler) DeleteSecret(w http.ResponseWriter, r *http.Request) {
 
 	deleted, err := provider.Delete(r.Context(), path, key)
 	if err != nil {
+		if h.respondIfDenied(w, err) {
+			return
+		}
 		if errors.Is(err, secrets.ErrInvalidPath) || errors.Is(err, secrets.ErrInvalidKey) {
 			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{
 				Error:   "invalid_input",
@@ -323,6 +365,9 @@ func (h *SecretsHandler) ListKeys(w http.ResponseWriter, r *http.Request) {
 
 	keys, err := provider.ListKeys(r.Context(), path)
 	if err != nil {
+		if h.respondIfDenied(w, err) {
+			return
+		}
 		if errors.Is(err, secrets.ErrInvalidPath) {
 			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{
 				Error:   "invalid_input",
@@ -367,6 +412,9 @@ func (h *SecretsHandler) ListPaths(w http.ResponseWriter, r *http.Request) {
 
 	paths, err := provider.ListPaths(r.Context())
 	if err != nil {
+		if h.respondIfDenied(w, err) {
+			return
+		}
 		h.respondWithJSON(w, http.StatusInternalServerError, ErrorResponse{
 			Error:   "internal_error",
 			Message: "failed to list paths",
@@ -413,6 +461,9 @@ func (h *SecretsHandler) BatchGet(w http.ResponseWriter, r *http.Request) {
 
 	results, err := provider.GetMulti(r.Context(), req.Refs)
 	if err != nil {
+		if h.respondIfDenied(w, err) {
+			return
+		}
 		h.respondWithJSON(w, http.StatusInternalServerError, ErrorResponse{
 			Error:   "internal_error",
 			Message: "failed to get secrets",
@@ -459,6 +510,9 @@ func (h *SecretsHandler) BatchSet(w http.ResponseWriter, r *http.Request) {
 
 	for _, s := range req.Secrets {
 		if err := provider.Set(r.Context(), s.Path, s.Key, s.Value); err != nil {
+			if h.respondIfDenied(w, err) {
+				return
+			}
 			if errors.Is(err, secrets.ErrInvalidPath) || errors.Is(err, secrets.ErrInvalidKey) {
 				h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{
 					Error:   "invalid_input",
@@ -596,9 +650,7 @@ func (h *SecretsHandler) CreateMasterKey(w http.ResponseWriter, r *http.Request)
 
 	mk, err := h.keyManager.RegisterMasterKey(store.GetDBFromContext(r.Context()), req.Name, req.Description)
 	if err != nil {
-		if err.Error() == "UNIQUE constraint violation" ||
-		   (err != nil && (err.Error() == "master key "+req.Name+" not found in REACTORCIDE_MASTER_KEYS" ||
-		                   strings.Contains(err.Error(), "duplicate key value"))) {
+		if errors.Is(err, store.ErrAlreadyExists) || errors.Is(err, secrets.ErrMasterKeyNotFound) {
 			h.respondWithJSON(w, http.StatusConflict, ErrorResponse{
 				Error:   "conflict",
 				Message: err.Error(),
@@ -664,7 +716,7 @@ func (h *SecretsHandler) RotateMasterKey(w http.ResponseWriter, r *http.Request)
 	}
 
 	if err := h.keyManager.RotateToKey(store.GetDBFromContext(r.Context()), keyName); err != nil {
-		if strings.Contains(err.Error(), "not found") {
+		if errors.Is(err, secrets.ErrMasterKeyNotFound) {
 			h.respondWithJSON(w, http.StatusNotFound, ErrorResponse{
 				Error:   "not_found",
 				Message: err.Error(),
@@ -704,7 +756,7 @@ func (h *SecretsHandler) DecommissionMasterKey(w http.ResponseWriter, r *http.Re
 			})
 			return
 		}
-		if strings.Contains(err.Error(), "not found") {
+		if errors.Is(err, secrets.ErrMasterKeyNotFound) {
 			h.respondWithJSON(w, http.StatusNotFound, ErrorResponse{
 				Error:   "not_found",
 				Message: err.Error(),
@@ -741,3 +793,22 @@ func (h *SecretsHandler) SyncPrimary(w http.ResponseWriter, r *http.Request) {
 		"primary": primaryName,
 	})
 }
+
+// MasterKeyHealth handles GET /api/v1/admin/secrets/health
+// Checks the master keys in the database against the ones this process
+// holds, responding 503 with the problems found if any.
+func (h *SecretsHandler) MasterKeyHealth(w http.ResponseWriter, r *http.Request) {
+	health, err := h.keyManager.CheckHealth(store.GetDBFromContext(r.Context()))
+	if err != nil {
+		h.respondWithJSON(w, http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "failed to check master key health",
+		})
+		return
+	}
+	code := http.StatusOK
+	if !health.Healthy {
+		code = http.StatusServiceUnavailable
+	}
+	h.respondWithJSON(w, code, health)
+}
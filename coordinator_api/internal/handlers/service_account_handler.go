@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/lib/pq"
+)
+
+// serviceAccountStore is the narrow store capability behind the service
+// account endpoints. See postgres_store/service_account_operations.go.
+type serviceAccountStore interface {
+	CreateServiceAccount(ctx context.Context, user *models.User) error
+	ListServiceAccounts(ctx context.Context) ([]models.User, error)
+	DeactivateAPITokensByUser(ctx context.Context, userID string) (int64, error)
+}
+
+// CreateServiceAccountRequest is the body of POST /api/v1/admin/service-accounts.
+type CreateServiceAccountRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// ServiceAccountResponse is a service account in API responses.
+type ServiceAccountResponse struct {
+	UserID      string    `json:"user_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Roles       []string  `json:"roles"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ListServiceAccountsResponse is the JSON body of
+// GET /api/v1/admin/service-accounts.
+type ListServiceAccountsResponse struct {
+	ServiceAccounts []ServiceAccountResponse `json:"service_accounts"`
+	Total           int                      `json:"total"`
+}
+
+// DisableServiceAccountResponse is the JSON body of
+// DELETE /api/v1/admin/service-accounts/{user_id}.
+type DisableServiceAccountResponse struct {
+	UserID            string `json:"user_id"`
+	TokensDeactivated int64  `json:"tokens_deactivated"`
+}
+
+// CreateServiceAccount handles POST /api/v1/admin/service-accounts
+func (h *TokenHandler) CreateServiceAccount(w http.ResponseWriter, r *http.Request) {
+	ss, ok := h.serviceAccountStore(w, r)
+	if !ok {
+		return
+	}
+	var req CreateServiceAccountRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	if !tokenScopePattern.MatchString(req.Name) {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_input",
+			Message: "name must be letters, digits and _.:- only",
+		})
+		return
+	}
+
+	// Service accounts never log in, so there's no password; the random
+	// salt keeps the columns non-empty like every other user's.
+	salt, err := generateSecureToken()
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	account := &models.User{
+		Username:    req.Name,
+		Description: req.Description,
+		Password:    []byte{},
+		Salt:        []byte(salt),
+		Roles:       pq.StringArray{"user"},
+	}
+	if err := ss.CreateServiceAccount(r.Context(), account); err != nil {
+		if errors.Is(err, store.ErrAlreadyExists) {
+			h.respondWithError(w, http.StatusConflict, err)
+			return
+		}
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusCreated, serviceAccountToResponse(account))
+}
+
+// ListServiceAccounts handles GET /api/v1/admin/service-accounts
+func (h *TokenHandler) ListServiceAccounts(w http.ResponseWriter, r *http.Request) {
+	ss, ok := h.serviceAccountStore(w, r)
+	if !ok {
+		return
+	}
+	accounts, err := ss.ListServiceAccounts(r.Context())
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	response := ListServiceAccountsResponse{ServiceAccounts: make([]ServiceAccountResponse, len(accounts))}
+	for i := range accounts {
+		response.ServiceAccounts[i] = serviceAccountToResponse(&accounts[i])
+	}
+	response.Total = len(response.ServiceAccounts)
+	h.respondWithJSON(w, http.StatusOK, response)
+}
+
+// GetServiceAccount handles GET /api/v1/admin/service-accounts/{user_id}
+func (h *TokenHandler) GetServiceAccount(w http.ResponseWriter, r *http.Request) {
+	account, ok := h.loadServiceAccount(w, r)
+	if !ok {
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, serviceAccountToResponse(account))
+}
+
+// DisableServiceAccount handles DELETE /api/v1/admin/service-accounts/{user_id}.
+// It deactivates the account's tokens; the user row stays, since its jobs
+// still reference it.
+func (h *TokenHandler) DisableServiceAccount(w http.ResponseWriter, r *http.Request) {
+	ss, ok := h.serviceAccountStore(w, r)
+	if !ok {
+		return
+	}
+	account, ok := h.loadServiceAccount(w, r)
+	if !ok {
+		return
+	}
+	n, err := ss.DeactivateAPITokensByUser(r.Context(), account.UserID)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, DisableServiceAccountResponse{UserID: account.UserID, TokensDeactivated: n})
+}
+
+// ListServiceAccountTokens handles GET /api/v1/admin/service-accounts/{user_id}/tokens
+func (h *TokenHandler) ListServiceAccountTokens(w http.ResponseWriter, r *http.Request) {
+	account, ok := h.loadServiceAccount(w, r)
+	if !ok {
+		return
+	}
+	tokens, err := h.store.GetAPITokensByUser(r.Context(), account.UserID)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	tokenResponses := make([]TokenResponse, len(tokens))
+	for i, token := range tokens {
+		tokenResponses[i] = h.tokenToResponse(&token)
+	}
+	h.respondWithJSON(w, http.StatusOK, ListTokensResponse{Tokens: tokenResponses, Total: len(tokenResponses)})
+}
+
+// CreateServiceAccountToken handles POST /api/v1/admin/service-accounts/{user_id}/tokens.
+// The body is a CreateTokenRequest; the "worker" scope lets the account
+// upload any job's logs.
+func (h *TokenHandler) CreateServiceAccountToken(w http.ResponseWriter, r *http.Request) {
+	account, ok := h.loadServiceAccount(w, r)
+	if !ok {
+		return
+	}
+	var req CreateTokenRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Name == "" {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+	scopes, ok := normalizeTokenScopes(req.Scopes)
+	if !ok {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+	projectID, ok := h.tokenProject(w, r, req.ProjectID)
+	if !ok {
+		return
+	}
+
+	tokenString, err := generateSecureToken()
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	apiToken := &models.APIToken{
+		UserID:    account.UserID,
+		TokenHash: checkauth.HashAPIToken(tokenString),
+		Name:      req.Name,
+		ExpiresAt: req.ExpiresAt,
+		IsActive:  true,
+		Scopes:    scopes,
+		ProjectID: projectID,
+	}
+	if err := h.store.CreateAPIToken(r.Context(), apiToken); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusCreated, CreateTokenResponse{
+		TokenID:   apiToken.TokenID,
+		Token:     tokenString,
+		Name:      apiToken.Name,
+		CreatedAt: apiToken.CreatedAt,
+		ExpiresAt: apiToken.ExpiresAt,
+		Scopes:    apiToken.Scopes,
+		ProjectID: apiToken.ProjectID,
+	})
+}
+
+// serviceAccountStore checks the caller is an admin and returns the
+// service account capability, writing the error response itself if not.
+func (h *TokenHandler) serviceAccountStore(w http.ResponseWriter, r *http.Request) (serviceAccountStore, bool) {
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return nil, false
+	}
+	if !h.isAdmin(user) {
+		h.respondWithError(w, http.StatusForbidden, store.ErrForbidden)
+		return nil, false
+	}
+	ss, ok := h.store.(serviceAccountStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("service accounts not available"))
+		return nil, false
+	}
+	return ss, true
+}
+
+// loadServiceAccount loads the service account named in the path, 404ing
+// for users that aren't service accounts.
+func (h *TokenHandler) loadServiceAccount(w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	if _, ok := h.serviceAccountStore(w, r); !ok {
+		return nil, false
+	}
+	userID := h.getID(r, "user_id")
+	if userID == "" {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return nil, false
+	}
+	account, err := h.store.GetUserByID(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			h.respondWithError(w, http.StatusNotFound, err)
+			return nil, false
+		}
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	if !account.IsServiceAccount {
+		h.respondWithError(w, http.StatusNotFound, store.ErrNotFound)
+		return nil, false
+	}
+	return account, true
+}
+
+func serviceAccountToResponse(account *models.User) ServiceAccountResponse {
+	return ServiceAccountResponse{
+		UserID:      account.UserID,
+		Name:        account.Username,
+		Description: account.Description,
+		Roles:       account.Roles,
+		CreatedAt:   account.CreatedAt,
+	}
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serviceAccountMockStore keeps users and tokens in memory on top of
+// ProjectMockStore, with the serviceAccountStore capability.
+type serviceAccountMockStore struct {
+	ProjectMockStore
+	users  map[string]*models.User
+	tokens []models.APIToken
+}
+
+func newServiceAccountMockStore() *serviceAccountMockStore {
+	return &serviceAccountMockStore{users: map[string]*models.User{
+		"human": {UserID: "human", Username: "alice", Email: "alice@example.com"},
+	}}
+}
+
+func (m *serviceAccountMockStore) CreateServiceAccount(ctx context.Context, user *models.User) error {
+	for _, u := range m.users {
+		if u.Username == user.Username {
+			return store.ErrAlreadyExists
+		}
+	}
+	user.UserID = "sa-" + user.Username
+	user.IsServiceAccount = true
+	m.users[user.UserID] = user
+	return nil
+}
+
+func (m *serviceAccountMockStore) ListServiceAccounts(ctx context.Context) ([]models.User, error) {
+	var out []models.User
+	for _, u := range m.users {
+		if u.IsServiceAccount {
+			out = append(out, *u)
+		}
+	}
+	return out, nil
+}
+
+func (m *serviceAccountMockStore) DeactivateAPITokensByUser(ctx context.Context, userID string) (int64, error) {
+	var n int64
+	for i := range m.tokens {
+		if m.tokens[i].UserID == userID && m.tokens[i].IsActive {
+			m.tokens[i].IsActive = false
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *serviceAccountMockStore) GetUserByID(ctx context.Context, userID string) (*models.User, error) {
+	u, ok := m.users[userID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return u, nil
+}
+
+func (m *serviceAccountMockStore) CreateAPIToken(ctx context.Context, token *models.APIToken) error {
+	token.TokenID = "token-new"
+	m.tokens = append(m.tokens, *token)
+	return nil
+}
+
+func withAdmin(r *http.Request) *http.Request {
+	user := &models.User{UserID: "admin-user-id", Roles: []string{"admin"}}
+	return r.WithContext(checkauth.SetUserContext(r.Context(), user))
+}
+
+func TestTokenHandler_CreateServiceAccount(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		admin          bool
+		expectedStatus int
+	}{
+		{"admin", `{"name": "webhooks", "description": "Owns webhook jobs"}`, true, http.StatusCreated},
+		{"not admin", `{"name": "webhooks"}`, false, http.StatusForbidden},
+		{"username taken", `{"name": "alice"}`, true, http.StatusConflict},
+		{"bad name", `{"name": "web hooks"}`, true, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := newServiceAccountMockStore()
+			handler := NewTokenHandler(mockStore)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/service-accounts", strings.NewReader(tt.body))
+			if tt.admin {
+				req = withAdmin(req)
+			} else {
+				req = withUser(req)
+			}
+			w := httptest.NewRecorder()
+			handler.CreateServiceAccount(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedStatus == http.StatusCreated {
+				account := mockStore.users["sa-webhooks"]
+				require.NotNil(t, account)
+				assert.True(t, account.IsServiceAccount)
+				assert.Empty(t, account.Email)
+				assert.Equal(t, []string{"user"}, []string(account.Roles))
+				assert.Equal(t, "Owns webhook jobs", account.Description)
+			}
+		})
+	}
+}
+
+func TestTokenHandler_ServiceAccountTokens(t *testing.T) {
+	mockStore := newServiceAccountMockStore()
+	mockStore.users["sa-worker"] = &models.User{UserID: "sa-worker", Username: "worker", IsServiceAccount: true}
+	handler := NewTokenHandler(mockStore)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/service-accounts/sa-worker/tokens",
+		strings.NewReader(`{"name": "pool-a", "scopes": ["worker"]}`))
+	req = withAdmin(req)
+	req = req.WithContext(context.WithValue(req.Context(), GetContextKey("user_id"), "sa-worker"))
+	w := httptest.NewRecorder()
+	handler.CreateServiceAccountToken(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var resp CreateTokenResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.NotEmpty(t, resp.Token)
+	require.Len(t, mockStore.tokens, 1)
+	assert.Equal(t, "sa-worker", mockStore.tokens[0].UserID)
+	assert.Equal(t, checkauth.HashAPIToken(resp.Token), mockStore.tokens[0].TokenHash)
+
+	// Disabling the account deactivates the token.
+	req = httptest.NewRequest(http.MethodDelete, "/api/v1/admin/service-accounts/sa-worker", nil)
+	req = withAdmin(req)
+	req = req.WithContext(context.WithValue(req.Context(), GetContextKey("user_id"), "sa-worker"))
+	w = httptest.NewRecorder()
+	handler.DisableServiceAccount(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.False(t, mockStore.tokens[0].IsActive)
+
+	// Human users aren't reachable through the service account endpoints.
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/admin/service-accounts/human/tokens",
+		strings.NewReader(`{"name": "sneaky"}`))
+	req = withAdmin(req)
+	req = req.WithContext(context.WithValue(req.Context(), GetContextKey("user_id"), "human"))
+	w = httptest.NewRecorder()
+	handler.CreateServiceAccountToken(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestTokenHandler_DeployTokens(t *testing.T) {
+	mockStore := newServiceAccountMockStore()
+	mockStore.users["sa-docs"] = &models.User{UserID: "sa-docs", Username: "docs", IsServiceAccount: true}
+	mockStore.GetProjectByIDFunc = func(ctx context.Context, projectID string) (*models.Project, error) {
+		if projectID != "project-1" {
+			return nil, store.ErrNotFound
+		}
+		return &models.Project{ProjectID: projectID}, nil
+	}
+	handler := NewTokenHandler(mockStore)
+	create := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/service-accounts/sa-docs/tokens", strings.NewReader(body))
+		req = withAdmin(req)
+		req = req.WithContext(context.WithValue(req.Context(), GetContextKey("user_id"), "sa-docs"))
+		w := httptest.NewRecorder()
+		handler.CreateServiceAccountToken(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusBadRequest, create(`{"name": "docs-rebuild", "project_id": "project-2"}`).Code)
+	assert.Empty(t, mockStore.tokens)
+
+	w := create(`{"name": "docs-rebuild", "project_id": "project-1"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var resp CreateTokenResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotNil(t, resp.ProjectID)
+	assert.Equal(t, "project-1", *resp.ProjectID)
+	require.Len(t, mockStore.tokens, 1)
+	assert.Equal(t, "project-1", *mockStore.tokens[0].ProjectID)
+}
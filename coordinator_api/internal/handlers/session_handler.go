@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/auth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/middleware"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// SessionHandler handles cookie login sessions for browser clients of the
+// REST API, such as the embedded UI. Programmatic clients keep using
+// Bearer tokens.
+type SessionHandler struct {
+	BaseHandler
+	sessions *auth.Sessions
+}
+
+// NewSessionHandler creates a new session handler
+func NewSessionHandler(sessions *auth.Sessions) *SessionHandler {
+	return &SessionHandler{sessions: sessions}
+}
+
+// SessionResponse is the JSON body of the /api/v1/session endpoints.
+type SessionResponse struct {
+	UserID    string    `json:"user_id"`
+	Username  string    `json:"username"`
+	Roles     []string  `json:"roles"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// CSRFToken goes in the X-CSRF-Token header of every request that
+	// changes state with the session cookie.
+	CSRFToken string `json:"csrf_token,omitempty"`
+}
+
+// CreateSession handles POST /api/v1/session: it logs in with whatever
+// credentials the request authenticated with (an API token, or LDAP Basic
+// credentials) and sets the session cookie.
+func (h *SessionHandler) CreateSession(w http.ResponseWriter, r *http.Request) {
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return
+	}
+	if user.IsServiceAccount {
+		h.respondWithJSON(w, http.StatusForbidden, ErrorResponse{
+			Error:   "forbidden",
+			Message: "service accounts cannot log in interactively",
+		})
+		return
+	}
+	// A session carries the user's full access, so a restricted API token
+	// can't be traded for one.
+	if apiToken := checkauth.GetAPITokenFromContext(r.Context()); apiToken != nil && (len(apiToken.Scopes) > 0 || apiToken.ProjectID != nil) {
+		h.respondWithJSON(w, http.StatusForbidden, ErrorResponse{
+			Error:   "forbidden",
+			Message: "scoped or project-bound API tokens cannot create sessions",
+		})
+		return
+	}
+
+	token, session, err := h.sessions.Mint(r.Context(), user.UserID)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     middleware.SessionCookieName,
+		Value:    token,
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		HttpOnly: true,
+		Secure:   !config.SessionCookieInsecure,
+		SameSite: http.SameSiteLaxMode,
+	})
+	h.respondWithJSON(w, http.StatusCreated, sessionToResponse(user, session, auth.CSRFToken(token)))
+}
+
+// GetSession handles GET /api/v1/session: the cookie session's user and
+// CSRF token, so a reloaded page can pick the session back up.
+func (h *SessionHandler) GetSession(w http.ResponseWriter, r *http.Request) {
+	user := checkauth.GetUserFromContext(r.Context())
+	session := checkauth.GetSessionFromContext(r.Context())
+	cookie, err := r.Cookie(middleware.SessionCookieName)
+	if user == nil || session == nil || err != nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, sessionToResponse(user, session, auth.CSRFToken(cookie.Value)))
+}
+
+// DeleteSession handles DELETE /api/v1/session: it revokes the cookie
+// session and clears the cookie.
+func (h *SessionHandler) DeleteSession(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(middleware.SessionCookieName); err == nil {
+		if err := h.sessions.RevokeSession(r.Context(), cookie.Value); err != nil {
+			h.respondWithError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     middleware.SessionCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   !config.SessionCookieInsecure,
+		SameSite: http.SameSiteLaxMode,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func sessionToResponse(user *models.User, session *models.UISession, csrfToken string) SessionResponse {
+	return SessionResponse{
+		UserID:    user.UserID,
+		Username:  user.Username,
+		Roles:     user.Roles,
+		ExpiresAt: session.ExpiresAt,
+		CSRFToken: csrfToken,
+	}
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/auth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/middleware"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sessionMockStore keeps UI sessions in memory on top of ProjectMockStore,
+// with the auth.SessionStore capability.
+type sessionMockStore struct {
+	ProjectMockStore
+	users    map[string]*models.User
+	sessions map[string]*models.UISession
+}
+
+func newSessionMockStore() *sessionMockStore {
+	return &sessionMockStore{
+		users: map[string]*models.User{
+			"human": {UserID: "human", Username: "alice", Roles: []string{"user"}},
+			"robot": {UserID: "robot", Username: "deployer", IsServiceAccount: true},
+		},
+		sessions: map[string]*models.UISession{},
+	}
+}
+
+func (m *sessionMockStore) CreateUISession(ctx context.Context, session *models.UISession) error {
+	session.SessionID = "session-" + session.UserID
+	session.LastSeenAt = time.Now()
+	m.sessions[string(session.TokenHash)] = session
+	return nil
+}
+
+func (m *sessionMockStore) GetActiveUISessionByTokenHash(ctx context.Context, tokenHash []byte) (*models.UISession, error) {
+	session, ok := m.sessions[string(tokenHash)]
+	if !ok || session.RevokedAt != nil {
+		return nil, store.ErrNotFound
+	}
+	return session, nil
+}
+
+func (m *sessionMockStore) TouchUISessionLastSeen(ctx context.Context, sessionID string) error {
+	return nil
+}
+
+func (m *sessionMockStore) RevokeUISession(ctx context.Context, sessionID string) error {
+	for _, session := range m.sessions {
+		if session.SessionID == sessionID {
+			now := time.Now()
+			session.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (m *sessionMockStore) GetUserByID(ctx context.Context, userID string) (*models.User, error) {
+	if user, ok := m.users[userID]; ok {
+		return user, nil
+	}
+	return nil, store.ErrNotFound
+}
+
+func newSessionTest(t *testing.T) (*sessionMockStore, http.Handler) {
+	t.Helper()
+	ms := newSessionMockStore()
+	sessions := auth.NewSessions(ms)
+	h := NewSessionHandler(sessions)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/session", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			h.CreateSession(w, r)
+		case http.MethodGet:
+			h.GetSession(w, r)
+		case http.MethodDelete:
+			h.DeleteSession(w, r)
+		}
+	})
+	return ms, middleware.AuthMiddleware(ms, nil, sessions)(mux)
+}
+
+// login creates a session for userID, standing in for whatever credentials
+// the POST authenticated with.
+func login(t *testing.T, ms *sessionMockStore, userID string) *httptest.ResponseRecorder {
+	t.Helper()
+	h := NewSessionHandler(auth.NewSessions(ms))
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/session", nil)
+	req = req.WithContext(checkauth.SetUserContext(req.Context(), ms.users[userID]))
+	rec := httptest.NewRecorder()
+	h.CreateSession(rec, req)
+	return rec
+}
+
+func TestSessionHandler_CreateSession(t *testing.T) {
+	ms := newSessionMockStore()
+	rec := login(t, ms, "human")
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var resp SessionResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.Equal(t, "alice", resp.Username)
+	assert.NotEmpty(t, resp.CSRFToken)
+
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, middleware.SessionCookieName, cookies[0].Name)
+	assert.True(t, cookies[0].HttpOnly)
+	assert.True(t, cookies[0].Secure)
+	assert.Equal(t, auth.CSRFToken(cookies[0].Value), resp.CSRFToken)
+
+	rec = login(t, ms, "robot")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Empty(t, rec.Result().Cookies())
+}
+
+func TestSessionHandler_CreateSession_RestrictedToken(t *testing.T) {
+	ms := newSessionMockStore()
+	h := NewSessionHandler(auth.NewSessions(ms))
+	projectID := "project-1"
+
+	for name, token := range map[string]*models.APIToken{
+		"scoped":        {Scopes: pq.StringArray{"deploy"}},
+		"project-bound": {ProjectID: &projectID},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/session", nil)
+			ctx := checkauth.SetUserContext(req.Context(), ms.users["human"])
+			req = req.WithContext(checkauth.SetAPITokenContext(ctx, token))
+			rec := httptest.NewRecorder()
+			h.CreateSession(rec, req)
+
+			assert.Equal(t, http.StatusForbidden, rec.Code)
+			assert.Empty(t, rec.Result().Cookies())
+		})
+	}
+}
+
+func TestSessionHandler_CookieAuth(t *testing.T) {
+	ms, handler := newSessionTest(t)
+	rec := login(t, ms, "human")
+	require.Equal(t, http.StatusCreated, rec.Code)
+	cookie := rec.Result().Cookies()[0]
+
+	do := func(method, csrf string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v1/session", bytes.NewReader(nil))
+		req.AddCookie(cookie)
+		if csrf != "" {
+			req.Header.Set(middleware.CSRFHeader, csrf)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Safe methods need only the cookie.
+	rec = do(http.MethodGet, "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var resp SessionResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.Equal(t, "human", resp.UserID)
+	assert.Equal(t, auth.CSRFToken(cookie.Value), resp.CSRFToken)
+
+	// Unsafe methods also need the CSRF token.
+	assert.Equal(t, http.StatusForbidden, do(http.MethodDelete, "").Code)
+	assert.Equal(t, http.StatusForbidden, do(http.MethodDelete, "not-the-token").Code)
+	rec = do(http.MethodDelete, resp.CSRFToken)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	cleared := rec.Result().Cookies()
+	require.Len(t, cleared, 1)
+	assert.Equal(t, -1, cleared[0].MaxAge)
+
+	// The revoked session no longer authenticates.
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "").Code)
+}
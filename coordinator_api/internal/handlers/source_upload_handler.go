@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/worker"
+)
+
+// SourceUploadPath is the route sources are uploaded to.
+const SourceUploadPath = "/api/v1/sources"
+
+// SourceUploadResponse is the 201 body of POST /api/v1/sources. A job runs
+// against the upload with source_type "upload" and source_path UploadID.
+type SourceUploadResponse struct {
+	UploadID string `json:"upload_id"`
+	Size     int64  `json:"size"`
+}
+
+// UploadSource handles POST /api/v1/sources. The body is a gzipped tar of
+// the job's source, as `reactorcide run` sends, stored in the object store
+// under the uploading user. Bodies are capped at
+// REACTORCIDE_MAX_SOURCE_UPLOAD_BYTES.
+func (h *JobHandler) UploadSource(w http.ResponseWriter, r *http.Request) {
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return
+	}
+	if h.objectStore == nil {
+		h.respondWithError(w, http.StatusServiceUnavailable, store.ErrServiceUnavailable)
+		return
+	}
+
+	body := bufio.NewReader(r.Body)
+	if magic, err := body.Peek(2); err != nil || magic[0] != 0x1f || magic[1] != 0x8b {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			respondPayloadTooLarge(w, maxErr.Limit)
+			return
+		}
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_input",
+			Message: "source upload must be a gzipped tar",
+		})
+		return
+	}
+
+	uploadID, err := newUploadID()
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	counted := &countingReader{r: body}
+	key := worker.UploadedSourceKey(user.UserID, uploadID)
+	if err := h.objectStore.Put(r.Context(), key, counted, "application/gzip"); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			respondPayloadTooLarge(w, maxErr.Limit)
+			return
+		}
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusCreated, SourceUploadResponse{UploadID: uploadID, Size: counted.n})
+}
+
+// checkUploadedSource points an "upload" job request at the requesting
+// user's upload, responding 400 if there is no such upload. Other source
+// types pass through unchanged. It reports whether the request may go on.
+func (h *JobHandler) checkUploadedSource(w http.ResponseWriter, r *http.Request, req *CreateJobRequest, userID string) bool {
+	if req.SourceType != "upload" {
+		return true
+	}
+	if h.objectStore == nil {
+		h.respondWithError(w, http.StatusServiceUnavailable, store.ErrServiceUnavailable)
+		return false
+	}
+	key := worker.UploadedSourceKey(userID, req.SourcePath)
+	exists, err := h.objectStore.Exists(r.Context(), key)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return false
+	}
+	if !exists {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_input",
+			Message: "uploaded source not found",
+		})
+		return false
+	}
+	req.SourcePath = key
+	return true
+}
+
+func newUploadID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
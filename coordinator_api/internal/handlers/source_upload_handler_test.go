@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/worker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sourceArchive(t *testing.T) []byte {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Makefile"), []byte("test:\n\ttrue\n"), 0644))
+	var buf bytes.Buffer
+	require.NoError(t, worker.WriteSourceArchive(&buf, dir, false))
+	return buf.Bytes()
+}
+
+func TestJobHandler_UploadSource(t *testing.T) {
+	memStore := objects.NewMemoryObjectStore()
+	handler := NewJobHandlerWithObjectStore(&MockStore{}, nil, memStore)
+	upload := func(body []byte) *httptest.ResponseRecorder {
+		req := withUser(httptest.NewRequest(http.MethodPost, SourceUploadPath, bytes.NewReader(body)))
+		w := httptest.NewRecorder()
+		handler.UploadSource(w, req)
+		return w
+	}
+
+	t.Run("stores the archive under the user", func(t *testing.T) {
+		archive := sourceArchive(t)
+		w := upload(archive)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var resp SourceUploadResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.NoError(t, worker.ValidateUploadID(resp.UploadID))
+		assert.Equal(t, int64(len(archive)), resp.Size)
+		exists, err := memStore.Exists(context.Background(), worker.UploadedSourceKey("test-user-id", resp.UploadID))
+		require.NoError(t, err)
+		assert.True(t, exists)
+	})
+
+	t.Run("rejects anything but a gzipped tar", func(t *testing.T) {
+		w := upload([]byte("plain text"))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestJobHandler_CreateJob_UploadedSource(t *testing.T) {
+	memStore := objects.NewMemoryObjectStore()
+	uploadID := "0123456789abcdef0123456789abcdef"
+	key := worker.UploadedSourceKey("test-user-id", uploadID)
+	require.NoError(t, memStore.Put(context.Background(), key, bytes.NewReader(sourceArchive(t)), "application/gzip"))
+
+	create := func(sourcePath string) (*httptest.ResponseRecorder, *MockStore) {
+		st := &MockStore{CreateJobFunc: func(ctx context.Context, job *models.Job) error {
+			job.JobID = "upload-job"
+			return nil
+		}}
+		body, _ := json.Marshal(CreateJobRequest{Name: "run-app", JobCommand: "make test", SourceType: "upload", SourcePath: sourcePath})
+		req := withUser(httptest.NewRequest(http.MethodPost, "/api/v1/jobs", bytes.NewReader(body)))
+		w := httptest.NewRecorder()
+		NewJobHandlerWithObjectStore(st, nil, memStore).CreateJob(w, req)
+		return w, st
+	}
+
+	t.Run("runs against the user's upload", func(t *testing.T) {
+		w, st := create(uploadID)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		require.Len(t, st.CreateJobCalls, 1)
+		job := st.CreateJobCalls[0]
+		assert.Equal(t, models.SourceTypeUpload, *job.SourceType)
+		assert.Equal(t, key, *job.SourcePath)
+	})
+
+	t.Run("unknown upload", func(t *testing.T) {
+		w, st := create("ffffffffffffffffffffffffffffffff")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Empty(t, st.CreateJobCalls)
+	})
+
+	t.Run("upload ID that is not one", func(t *testing.T) {
+		w, st := create("../other-user/" + uploadID)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Empty(t, st.CreateJobCalls)
+	})
+}
+
+func TestJobHandler_CreateJob_OCISource(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	create := func(sourceURL, sourceRef string) (*httptest.ResponseRecorder, *MockStore) {
+		st := &MockStore{CreateJobFunc: func(ctx context.Context, job *models.Job) error {
+			job.JobID = "oci-job"
+			return nil
+		}}
+		body, _ := json.Marshal(CreateJobRequest{Name: "build-ctx", JobCommand: "make", SourceType: "oci", SourceURL: sourceURL, SourceRef: sourceRef})
+		req := withUser(httptest.NewRequest(http.MethodPost, "/api/v1/jobs", bytes.NewReader(body)))
+		w := httptest.NewRecorder()
+		NewJobHandler(st, nil).CreateJob(w, req)
+		return w, st
+	}
+
+	t.Run("reference with a digest pin", func(t *testing.T) {
+		w, st := create("ghcr.io/org/ctx:v1", digest)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		require.Len(t, st.CreateJobCalls, 1)
+		job := st.CreateJobCalls[0]
+		assert.Equal(t, models.SourceTypeOCI, *job.SourceType)
+		assert.Equal(t, digest, *job.SourceRef)
+	})
+
+	t.Run("invalid reference", func(t *testing.T) {
+		w, st := create("ghcr.io/Org/ctx", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Empty(t, st.CreateJobCalls)
+	})
+
+	t.Run("ref that is not a digest", func(t *testing.T) {
+		w, st := create("ghcr.io/org/ctx:v1", "main")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Empty(t, st.CreateJobCalls)
+	})
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
+)
+
+// statusContextMigrationJobs is how many of a project's most recent jobs a
+// status context migration looks at for the contexts in use.
+const statusContextMigrationJobs = 500
+
+// StatusContextMigrationRequest is the JSON body of
+// POST /api/v1/projects/{project_id}/status-contexts/migrate.
+type StatusContextMigrationRequest struct {
+	// StatusContextTemplate is the project's new template; "" goes back to
+	// the built-in names.
+	StatusContextTemplate string `json:"status_context_template"`
+	// DryRun returns the renames without changing the project.
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// StatusContextRename is a status context the migration replaces.
+type StatusContextRename struct {
+	StatusKey string `json:"status_key"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+}
+
+// StatusContextMigrationResponse lists a migration's renames, by status
+// key, and the project's status context aliases after it.
+type StatusContextMigrationResponse struct {
+	StatusContextTemplate string                      `json:"status_context_template"`
+	Renames               []StatusContextRename       `json:"renames"`
+	StatusContextAliases  models.StatusContextAliases `json:"status_context_aliases"`
+	DryRun                bool                        `json:"dry_run"`
+}
+
+// MigrateStatusContexts handles
+// POST /api/v1/projects/{project_id}/status-contexts/migrate: it changes
+// the project's status context template and maps each context its recent
+// jobs reported to the one the new template gives the same status key.
+// The old contexts become aliases of the new ones, so branch protection
+// rules requiring them keep passing until they're moved over.
+func (h *ProjectHandler) MigrateStatusContexts(w http.ResponseWriter, r *http.Request) {
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return
+	}
+	projectID := h.getID(r, "project_id")
+	if projectID == "" {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+	project, err := h.store.GetProjectByID(r.Context(), projectID)
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return
+	}
+
+	var req StatusContextMigrationRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.StatusContextTemplate != "" {
+		if err := models.ValidateStatusContextTemplate(req.StatusContextTemplate); err != nil {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+			return
+		}
+	}
+	jobs, err := h.store.ListJobs(r.Context(), map[string]interface{}{"project_id": project.ProjectID}, statusContextMigrationJobs, 0)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	migrated := *project
+	migrated.StatusContextTemplate = req.StatusContextTemplate
+	renames := statusContextRenames(project, &migrated, jobs)
+	contexts := make(map[string]string, len(renames))
+	for _, rename := range renames {
+		contexts[rename.From] = rename.To
+	}
+	migrated.StatusContextAliases = project.StatusContextAliases.Migrate(contexts)
+
+	if !req.DryRun {
+		project.StatusContextTemplate = migrated.StatusContextTemplate
+		project.StatusContextAliases = migrated.StatusContextAliases
+		if err := h.store.UpdateProject(r.Context(), project); err != nil {
+			h.respondWithError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	h.respondWithJSON(w, http.StatusOK, StatusContextMigrationResponse{
+		StatusContextTemplate: migrated.StatusContextTemplate,
+		Renames:               renames,
+		StatusContextAliases:  migrated.StatusContextAliases,
+		DryRun:                req.DryRun,
+	})
+}
+
+// statusContextRenames maps the eval context and each context jobs last
+// reported to the context migrated gives the same status key, newest job
+// first, leaving out the unchanged ones. Jobs from before status keys were
+// recorded are keyed by their context, their job name then.
+func statusContextRenames(project, migrated *models.Project, jobs []models.Job) []StatusContextRename {
+	var renames []StatusContextRename
+	seen := map[string]bool{}
+	add := func(key, from, to string) {
+		if seen[from] {
+			return
+		}
+		seen[from] = true
+		if from != to {
+			renames = append(renames, StatusContextRename{StatusKey: key, From: from, To: to})
+		}
+	}
+
+	add(models.EvalStatusKey, evalStatusContext(project), evalStatusContext(migrated))
+	for i := range jobs {
+		metadata, err := vcs.MetadataFromJob(&jobs[i])
+		if err != nil || metadata == nil || metadata.StatusContext == "" {
+			continue
+		}
+		if metadata.IsEval {
+			add(models.EvalStatusKey, metadata.StatusContext, evalStatusContext(migrated))
+			continue
+		}
+		key := metadata.StatusKey
+		if key == "" {
+			key = metadata.StatusContext
+		}
+		add(key, metadata.StatusContext, migrated.StatusContext(key, key))
+	}
+	sort.SliceStable(renames, func(i, j int) bool { return renames[i].StatusKey < renames[j].StatusKey })
+	return renames
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// statusContextMockStore serves a project's recent jobs on top of
+// ProjectMockStore.
+type statusContextMockStore struct {
+	ProjectMockStore
+	jobs []models.Job
+}
+
+func (m *statusContextMockStore) ListJobs(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]models.Job, error) {
+	return m.jobs, nil
+}
+
+func TestProjectHandler_MigrateStatusContexts(t *testing.T) {
+	newStore := func() (*statusContextMockStore, *models.Project) {
+		project := webhookTestProject()
+		project.Name = "api"
+		project.StatusContextAliases = models.StatusContextAliases{"compile": "build"}
+		s := &statusContextMockStore{jobs: []models.Job{
+			{Notes: `{"repo":"org/repo","commit_sha":"abc","status_context":"reactorcide/eval","is_eval":true,"status_key":"eval"}`},
+			{Notes: `{"repo":"org/repo","commit_sha":"abc","status_context":"build","status_key":"build"}`},
+			{Notes: `{"repo":"org/repo","commit_sha":"abc","status_context":"Unit tests"}`},
+			{Notes: `{"repo":"org/repo","commit_sha":"old","status_context":"build"}`},
+			{},
+		}}
+		s.GetProjectByIDFunc = func(ctx context.Context, projectID string) (*models.Project, error) {
+			return project, nil
+		}
+		return s, project
+	}
+	migrate := func(s *statusContextMockStore, project *models.Project, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/projects/"+project.ProjectID+"/status-contexts/migrate", bytes.NewBufferString(body))
+		req = withProjectID(withUser(req), project.ProjectID)
+		w := httptest.NewRecorder()
+		NewProjectHandler(s).MigrateStatusContexts(w, req)
+		return w
+	}
+
+	t.Run("maps old contexts to the new template", func(t *testing.T) {
+		s, project := newStore()
+		w := migrate(s, project, `{"status_context_template":"ci/{project}/{job}"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var resp StatusContextMigrationResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, []StatusContextRename{
+			{StatusKey: "Unit tests", From: "Unit tests", To: "ci/api/Unit tests"},
+			{StatusKey: "build", From: "build", To: "ci/api/build"},
+			{StatusKey: "eval", From: "reactorcide/eval", To: "ci/api/eval"},
+		}, resp.Renames)
+		want := models.StatusContextAliases{
+			"compile":          "ci/api/build",
+			"build":            "ci/api/build",
+			"Unit tests":       "ci/api/Unit tests",
+			"reactorcide/eval": "ci/api/eval",
+		}
+		assert.Equal(t, want, resp.StatusContextAliases)
+
+		require.Len(t, s.UpdateProjectCalls, 1)
+		assert.Equal(t, "ci/{project}/{job}", s.UpdateProjectCalls[0].StatusContextTemplate)
+		assert.Equal(t, want, s.UpdateProjectCalls[0].StatusContextAliases)
+	})
+
+	t.Run("dry run changes nothing", func(t *testing.T) {
+		s, project := newStore()
+		w := migrate(s, project, `{"status_context_template":"ci/{job}","dry_run":true}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), `"to":"ci/build"`)
+		assert.Empty(t, s.UpdateProjectCalls)
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		s, project := newStore()
+		w := migrate(s, project, `{"status_context_template":"ci/{project}"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Empty(t, s.UpdateProjectCalls)
+	})
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// storageUsageStore is the narrow store capability behind
+// GET /api/v1/admin/storage. See postgres_store/log_lifecycle_operations.go.
+type storageUsageStore interface {
+	ListProjectStorageUsage(ctx context.Context, projectID string) ([]models.ProjectStorageUsage, error)
+}
+
+// ListStorageUsageResponse is the JSON body of GET /api/v1/admin/storage.
+type ListStorageUsageResponse struct {
+	Projects   []models.ProjectStorageUsage `json:"projects"`
+	TotalBytes int64                        `json:"total_bytes"`
+}
+
+// ListStorageUsage handles GET /api/v1/admin/storage: the log storage each
+// project's jobs use, hot and cold, largest first. project_id narrows it
+// to one project. Jobs count once the log lifecycle manager has measured
+// them, within one sweep of finishing. Admin only; the route applies the
+// role check.
+func (h *JobHandler) ListStorageUsage(w http.ResponseWriter, r *http.Request) {
+	usageStore, ok := h.store.(storageUsageStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("storage usage not available"))
+		return
+	}
+
+	usage, err := usageStore.ListProjectStorageUsage(r.Context(), r.URL.Query().Get("project_id"))
+	if err != nil {
+		if errors.Is(err, store.ErrInvalidInput) {
+			h.respondWithError(w, http.StatusBadRequest, err)
+			return
+		}
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if usage == nil {
+		usage = []models.ProjectStorageUsage{}
+	}
+	response := ListStorageUsageResponse{Projects: usage}
+	for _, u := range usage {
+		response.TotalBytes += u.HotBytes + u.ColdBytes
+	}
+	h.respondWithJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+const defaultStuckJobReportWindow = 7 * 24 * time.Hour
+
+// stuckJobReportStore is the narrow store capability behind
+// GET /api/v1/admin/stuck-jobs. See postgres_store/stuck_job_operations.go.
+type stuckJobReportStore interface {
+	ListStuckJobReports(ctx context.Context, since time.Time, limit, offset int) ([]models.StuckJobReport, error)
+}
+
+// ListStuckJobReportsResponse is the JSON body of GET /api/v1/admin/stuck-jobs.
+type ListStuckJobReportsResponse struct {
+	Reports []models.StuckJobReport `json:"reports"`
+	Since   time.Time               `json:"since"`
+	Limit   int                     `json:"limit"`
+	Offset  int                     `json:"offset"`
+}
+
+// ListStuckJobReports handles GET /api/v1/admin/stuck-jobs: the jobs the
+// stuck-job monitor flagged, newest first, and what it did about each.
+// window (Go duration or "Nd", default 7d) bounds how far back to look.
+// Admin only; the route applies the role check.
+func (h *JobHandler) ListStuckJobReports(w http.ResponseWriter, r *http.Request) {
+	reportStore, ok := h.store.(stuckJobReportStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("stuck job reports not available"))
+		return
+	}
+
+	window := defaultStuckJobReportWindow
+	if raw := r.URL.Query().Get("window"); raw != "" {
+		var err error
+		if window, err = parseAnalyticsWindow(raw); err != nil {
+			h.respondWithError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	limit, offset := h.parsePagination(r)
+	since := time.Now().UTC().Add(-window)
+
+	reports, err := reportStore.ListStuckJobReports(r.Context(), since, limit, offset)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if reports == nil {
+		reports = []models.StuckJobReport{}
+	}
+	h.respondWithJSON(w, http.StatusOK, ListStuckJobReportsResponse{
+		Reports: reports,
+		Since:   since,
+		Limit:   limit,
+		Offset:  offset,
+	})
+}
+
+// validateStuckJobOverrides checks a project's stuck-job policy overrides.
+func validateStuckJobOverrides(action *string, noLogMinutes *int, p95Multiplier *float64) error {
+	if action != nil && !models.IsValidStuckJobAction(*action) {
+		return store.ErrInvalidInput
+	}
+	if noLogMinutes != nil && *noLogMinutes < 0 {
+		return store.ErrInvalidInput
+	}
+	if p95Multiplier != nil && *p95Multiplier != 0 && *p95Multiplier < 1 {
+		return store.ErrInvalidInput
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// testQuarantineStore is the narrow store capability behind the test
+// quarantine endpoints. See postgres_store/test_quarantine_operations.go.
+type testQuarantineStore interface {
+	ListTestQuarantines(ctx context.Context, projectID string) ([]models.TestQuarantine, error)
+	GetTestQuarantine(ctx context.Context, projectID, quarantineID string) (*models.TestQuarantine, error)
+	SaveTestQuarantine(ctx context.Context, quarantine *models.TestQuarantine) error
+	UpdateTestQuarantine(ctx context.Context, quarantine *models.TestQuarantine) error
+	DeleteTestQuarantine(ctx context.Context, projectID, quarantineID string) error
+	TestPassRates(ctx context.Context, projectID string, testNames []string, since time.Time) (map[string]models.TestPassRate, error)
+}
+
+// TestQuarantineRequest is the JSON body of the test quarantine create and
+// update endpoints. ExpiresAt is an RFC 3339 time; creating a quarantine
+// without it quarantines the test for models.DefaultTestQuarantine.
+type TestQuarantineRequest struct {
+	Test      *string `json:"test,omitempty"`
+	Reason    *string `json:"reason,omitempty"`
+	ExpiresAt *string `json:"expires_at,omitempty"`
+}
+
+// TestQuarantineResponse is a test quarantine with how the test fared over
+// the last WindowDays days. PassRate is nil when it hasn't run.
+type TestQuarantineResponse struct {
+	models.TestQuarantine
+	Active   bool     `json:"active"`
+	Runs     int      `json:"runs"`
+	Passed   int      `json:"passed"`
+	PassRate *float64 `json:"pass_rate"`
+}
+
+// ListTestQuarantinesResponse is the JSON body of GET
+// /api/v1/projects/{project_id}/test-quarantines.
+type ListTestQuarantinesResponse struct {
+	Quarantines []TestQuarantineResponse `json:"quarantines"`
+	WindowDays  int                      `json:"window_days"`
+}
+
+// testQuarantines loads the project a test quarantine endpoint is for and
+// checks the store supports quarantines. With manage set, the user must be
+// able to manage the project.
+func (h *ProjectHandler) testQuarantines(w http.ResponseWriter, r *http.Request, manage bool) (testQuarantineStore, *models.Project, *models.User, bool) {
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return nil, nil, nil, false
+	}
+	qs, ok := h.store.(testQuarantineStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("test quarantine not available"))
+		return nil, nil, nil, false
+	}
+	project, _, ok := h.projectAndOwner(w, r, user.UserID)
+	if !ok {
+		return nil, nil, nil, false
+	}
+	if manage && !h.canManageProject(r.Context(), user, project) {
+		h.respondWithError(w, http.StatusForbidden, store.ErrForbidden)
+		return nil, nil, nil, false
+	}
+	return qs, project, user, true
+}
+
+// ListTestQuarantines handles GET
+// /api/v1/projects/{project_id}/test-quarantines: the project's quarantined
+// tests, expired ones included, with their recent pass rates.
+func (h *ProjectHandler) ListTestQuarantines(w http.ResponseWriter, r *http.Request) {
+	qs, project, _, ok := h.testQuarantines(w, r, false)
+	if !ok {
+		return
+	}
+	quarantines, err := qs.ListTestQuarantines(r.Context(), project.ProjectID)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	names := make([]string, len(quarantines))
+	for i := range quarantines {
+		names[i] = quarantines[i].TestName
+	}
+	now := time.Now().UTC()
+	window := config.TestResultRetentionDays
+	rates, err := qs.TestPassRates(r.Context(), project.ProjectID, names, now.AddDate(0, 0, -window))
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	response := ListTestQuarantinesResponse{Quarantines: make([]TestQuarantineResponse, len(quarantines)), WindowDays: window}
+	for i, q := range quarantines {
+		rate := rates[q.TestName]
+		response.Quarantines[i] = TestQuarantineResponse{
+			TestQuarantine: q,
+			Active:         q.ActiveAt(now),
+			Runs:           rate.Runs,
+			Passed:         rate.Passed,
+			PassRate:       rate.Rate(),
+		}
+	}
+	h.respondWithJSON(w, http.StatusOK, response)
+}
+
+// CreateTestQuarantine handles POST
+// /api/v1/projects/{project_id}/test-quarantines, e.g. {"test":
+// "pkg.TestFlaky", "reason": "races on CI", "expires_at": "..."}. A test
+// already quarantined gets the new reason and expiry.
+func (h *ProjectHandler) CreateTestQuarantine(w http.ResponseWriter, r *http.Request) {
+	qs, project, user, ok := h.testQuarantines(w, r, true)
+	if !ok {
+		return
+	}
+	var req TestQuarantineRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	now := time.Now().UTC()
+	quarantine := &models.TestQuarantine{
+		ProjectID: project.ProjectID,
+		ExpiresAt: now.Add(models.DefaultTestQuarantine),
+		CreatedBy: &user.UserID,
+	}
+	if req.Test != nil {
+		quarantine.TestName = strings.TrimSpace(*req.Test)
+	}
+	if !h.applyTestQuarantineRequest(w, quarantine, req, now) {
+		return
+	}
+	if err := qs.SaveTestQuarantine(r.Context(), quarantine); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusCreated, quarantine)
+}
+
+// UpdateTestQuarantine handles PUT
+// /api/v1/projects/{project_id}/test-quarantines/{quarantine_id}, e.g.
+// {"expires_at": "..."} to extend it. The test can't be changed.
+func (h *ProjectHandler) UpdateTestQuarantine(w http.ResponseWriter, r *http.Request) {
+	qs, project, _, ok := h.testQuarantines(w, r, true)
+	if !ok {
+		return
+	}
+	quarantine, err := qs.GetTestQuarantine(r.Context(), project.ProjectID, h.getID(r, "quarantine_id"))
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return
+	}
+	var req TestQuarantineRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Test != nil && strings.TrimSpace(*req.Test) != quarantine.TestName {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: "a quarantine's test can't be changed"})
+		return
+	}
+	if !h.applyTestQuarantineRequest(w, quarantine, req, time.Now().UTC()) {
+		return
+	}
+	if err := qs.UpdateTestQuarantine(r.Context(), quarantine); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, quarantine)
+}
+
+// DeleteTestQuarantine handles DELETE
+// /api/v1/projects/{project_id}/test-quarantines/{quarantine_id}: the
+// test's failures fail jobs again.
+func (h *ProjectHandler) DeleteTestQuarantine(w http.ResponseWriter, r *http.Request) {
+	qs, project, _, ok := h.testQuarantines(w, r, true)
+	if !ok {
+		return
+	}
+	if err := qs.DeleteTestQuarantine(r.Context(), project.ProjectID, h.getID(r, "quarantine_id")); err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ProjectHandler) applyTestQuarantineRequest(w http.ResponseWriter, quarantine *models.TestQuarantine, req TestQuarantineRequest, now time.Time) bool {
+	if req.Reason != nil {
+		quarantine.Reason = strings.TrimSpace(*req.Reason)
+	}
+	if req.ExpiresAt != nil {
+		t, err := time.Parse(time.RFC3339, *req.ExpiresAt)
+		if err != nil {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: "expires_at must be an RFC 3339 time"})
+			return false
+		}
+		quarantine.ExpiresAt = t.UTC()
+	}
+	if err := quarantine.Validate(now); err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+		return false
+	}
+	return true
+}
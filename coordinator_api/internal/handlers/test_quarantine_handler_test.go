@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// quarantineMockStore keeps test quarantines in memory on top of
+// ProjectMockStore, with fixed pass rates.
+type quarantineMockStore struct {
+	ProjectMockStore
+	quarantines []models.TestQuarantine
+	rates       map[string]models.TestPassRate
+}
+
+func (m *quarantineMockStore) ListTestQuarantines(ctx context.Context, projectID string) ([]models.TestQuarantine, error) {
+	return append([]models.TestQuarantine(nil), m.quarantines...), nil
+}
+
+func (m *quarantineMockStore) GetTestQuarantine(ctx context.Context, projectID, quarantineID string) (*models.TestQuarantine, error) {
+	for _, q := range m.quarantines {
+		if q.QuarantineID == quarantineID {
+			return &q, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (m *quarantineMockStore) SaveTestQuarantine(ctx context.Context, quarantine *models.TestQuarantine) error {
+	for i := range m.quarantines {
+		if m.quarantines[i].TestName == quarantine.TestName {
+			quarantine.QuarantineID = m.quarantines[i].QuarantineID
+			m.quarantines[i] = *quarantine
+			return nil
+		}
+	}
+	quarantine.QuarantineID = "quarantine-" + quarantine.TestName
+	m.quarantines = append(m.quarantines, *quarantine)
+	return nil
+}
+
+func (m *quarantineMockStore) UpdateTestQuarantine(ctx context.Context, quarantine *models.TestQuarantine) error {
+	for i := range m.quarantines {
+		if m.quarantines[i].QuarantineID == quarantine.QuarantineID {
+			m.quarantines[i] = *quarantine
+			return nil
+		}
+	}
+	return store.ErrNotFound
+}
+
+func (m *quarantineMockStore) DeleteTestQuarantine(ctx context.Context, projectID, quarantineID string) error {
+	for i := range m.quarantines {
+		if m.quarantines[i].QuarantineID == quarantineID {
+			m.quarantines = append(m.quarantines[:i], m.quarantines[i+1:]...)
+			return nil
+		}
+	}
+	return store.ErrNotFound
+}
+
+func (m *quarantineMockStore) TestPassRates(ctx context.Context, projectID string, testNames []string, since time.Time) (map[string]models.TestPassRate, error) {
+	return m.rates, nil
+}
+
+func quarantineRequest(method, body, quarantineID string) *http.Request {
+	r := httptest.NewRequest(method, "/api/v1/projects/project-1/test-quarantines", strings.NewReader(body))
+	r = r.WithContext(setIDContext(r.Context(), "project_id", "project-1"))
+	return r.WithContext(setIDContext(r.Context(), "quarantine_id", quarantineID))
+}
+
+func TestTestQuarantineHandler(t *testing.T) {
+	owner := "test-user-id"
+	s := &quarantineMockStore{rates: map[string]models.TestPassRate{"pkg.TestFlaky": {Runs: 10, Passed: 7}}}
+	s.GetProjectByIDFunc = func(ctx context.Context, projectID string) (*models.Project, error) {
+		return &models.Project{ProjectID: projectID, UserID: &owner}, nil
+	}
+	h := NewProjectHandler(s)
+	create := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.CreateTestQuarantine(w, withUser(quarantineRequest(http.MethodPost, body, "")))
+		return w
+	}
+
+	assert.Equal(t, http.StatusBadRequest, create(`{"reason":"no test"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, create(`{"test":"pkg.TestFlaky","expires_at":"2020-01-01T00:00:00Z"}`).Code)
+	farAhead := time.Now().Add(models.MaxTestQuarantine + 24*time.Hour).UTC().Format(time.RFC3339)
+	assert.Equal(t, http.StatusBadRequest, create(`{"test":"pkg.TestFlaky","expires_at":"`+farAhead+`"}`).Code)
+
+	w := create(`{"test":" pkg.TestFlaky ","reason":"races on CI"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var quarantine models.TestQuarantine
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&quarantine))
+	assert.Equal(t, "pkg.TestFlaky", quarantine.TestName)
+	assert.Equal(t, owner, *quarantine.CreatedBy)
+	assert.WithinDuration(t, time.Now().Add(models.DefaultTestQuarantine), quarantine.ExpiresAt, time.Minute)
+
+	t.Run("the report has pass rates", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ListTestQuarantines(w, withUser(quarantineRequest(http.MethodGet, "", "")))
+		require.Equal(t, http.StatusOK, w.Code)
+		var list ListTestQuarantinesResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&list))
+		require.Len(t, list.Quarantines, 1)
+		q := list.Quarantines[0]
+		assert.True(t, q.Active)
+		assert.Equal(t, 10, q.Runs)
+		assert.InDelta(t, 0.7, *q.PassRate, 1e-9)
+		assert.Equal(t, "races on CI", q.Reason)
+		assert.Equal(t, 14, list.WindowDays)
+	})
+
+	t.Run("extending keeps the test", func(t *testing.T) {
+		later := time.Now().Add(30 * 24 * time.Hour).UTC().Format(time.RFC3339)
+		w := httptest.NewRecorder()
+		h.UpdateTestQuarantine(w, withUser(quarantineRequest(http.MethodPut, `{"expires_at":"`+later+`"}`, quarantine.QuarantineID)))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, later, s.quarantines[0].ExpiresAt.Format(time.RFC3339))
+
+		w = httptest.NewRecorder()
+		h.UpdateTestQuarantine(w, withUser(quarantineRequest(http.MethodPut, `{"test":"pkg.Other"}`, quarantine.QuarantineID)))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("only project managers change quarantines", func(t *testing.T) {
+		r := quarantineRequest(http.MethodDelete, "", quarantine.QuarantineID)
+		r = r.WithContext(checkauth.SetUserContext(r.Context(), &models.User{UserID: "someone-else"}))
+		w := httptest.NewRecorder()
+		h.DeleteTestQuarantine(w, r)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = httptest.NewRecorder()
+		h.DeleteTestQuarantine(w, withUser(quarantineRequest(http.MethodDelete, "", quarantine.QuarantineID)))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Empty(t, s.quarantines)
+	})
+}
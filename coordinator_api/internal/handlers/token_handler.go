@@ -3,8 +3,9 @@ package handlers
 import (
 	"crypto/rand"
 	"encoding/hex"
-	"encoding/json"
 	"net/http"
+	"regexp"
+	"sort"
 	"time"
 
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
@@ -29,6 +30,11 @@ func NewTokenHandler(store store.Store) *TokenHandler {
 type CreateTokenRequest struct {
 	Name      string     `json:"name" validate:"required"`
 	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// Scopes limit the token to the secrets token_scope access policies
+	// grant those scopes.
+	Scopes []string `json:"scopes,omitempty"`
+	// ProjectID binds the token to one project, as a deploy token.
+	ProjectID string `json:"project_id,omitempty"`
 }
 
 // CreateTokenResponse represents the response for creating an API token
@@ -38,6 +44,8 @@ type CreateTokenResponse struct {
 	Name      string     `json:"name"`
 	CreatedAt time.Time  `json:"created_at"`
 	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Scopes    []string   `json:"scopes,omitempty"`
+	ProjectID *string    `json:"project_id,omitempty"`
 }
 
 // TokenResponse represents the response for token operations (without the actual token)
@@ -49,6 +57,8 @@ type TokenResponse struct {
 	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
 	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
 	IsActive   bool       `json:"is_active"`
+	Scopes     []string   `json:"scopes,omitempty"`
+	ProjectID  *string    `json:"project_id,omitempty"`
 }
 
 // ListTokensResponse represents the response for listing tokens
@@ -60,8 +70,7 @@ type ListTokensResponse struct {
 // CreateToken handles POST /api/v1/tokens
 func (h *TokenHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
 	var req CreateTokenRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+	if !h.decodeJSON(w, r, &req) {
 		return
 	}
 
@@ -84,6 +93,24 @@ func (h *TokenHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	scopes, ok := normalizeTokenScopes(req.Scopes)
+	if !ok {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+	// A scoped token can only create tokens with a subset of its scopes,
+	// or it could escape them.
+	if caller := checkauth.GetAPITokenFromContext(r.Context()); caller != nil && len(caller.Scopes) > 0 {
+		if len(scopes) == 0 || !isSubset(scopes, caller.Scopes) {
+			h.respondWithError(w, http.StatusForbidden, store.ErrForbidden)
+			return
+		}
+	}
+	projectID, ok := h.tokenProject(w, r, req.ProjectID)
+	if !ok {
+		return
+	}
+
 	// Generate secure token
 	tokenString, err := generateSecureToken()
 	if err != nil {
@@ -101,6 +128,8 @@ func (h *TokenHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
 		Name:      req.Name,
 		ExpiresAt: req.ExpiresAt,
 		IsActive:  true,
+		Scopes:    scopes,
+		ProjectID: projectID,
 	}
 
 	// Save to database
@@ -116,6 +145,8 @@ func (h *TokenHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
 		Name:      apiToken.Name,
 		CreatedAt: apiToken.CreatedAt,
 		ExpiresAt: apiToken.ExpiresAt,
+		Scopes:    apiToken.Scopes,
+		ProjectID: apiToken.ProjectID,
 	}
 
 	h.respondWithJSON(w, http.StatusCreated, response)
@@ -205,9 +236,25 @@ func (h *TokenHandler) tokenToResponse(token *models.APIToken) TokenResponse {
 		ExpiresAt:  token.ExpiresAt,
 		LastUsedAt: token.LastUsedAt,
 		IsActive:   token.IsActive,
+		Scopes:     token.Scopes,
+		ProjectID:  token.ProjectID,
 	}
 }
 
+// tokenProject checks the project a new token is bound to exists. An
+// empty projectID binds it to none.
+func (h *TokenHandler) tokenProject(w http.ResponseWriter, r *http.Request, projectID string) (*string, bool) {
+	if projectID == "" {
+		return nil, true
+	}
+	project, err := h.store.GetProjectByID(r.Context(), projectID)
+	if err != nil || project == nil {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: "project_id is not a project"})
+		return nil, false
+	}
+	return &project.ProjectID, true
+}
+
 func (h *TokenHandler) isAdmin(user *models.User) bool {
 	for _, role := range user.Roles {
 		if role == "admin" || role == "system_admin" {
@@ -228,3 +275,37 @@ func generateSecureToken() (string, error) {
 	// Convert to hex string (64 characters)
 	return hex.EncodeToString(bytes), nil
 }
+
+// tokenScopePattern is what a token scope may contain.
+var tokenScopePattern = regexp.MustCompile(`^[a-zA-Z0-9_.:-]+$`)
+
+// normalizeTokenScopes validates scopes and returns them sorted without
+// duplicates.
+func normalizeTokenScopes(scopes []string) ([]string, bool) {
+	seen := make(map[string]bool, len(scopes))
+	normalized := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !tokenScopePattern.MatchString(scope) {
+			return nil, false
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			normalized = append(normalized, scope)
+		}
+	}
+	sort.Strings(normalized)
+	return normalized, true
+}
+
+func isSubset(values, of []string) bool {
+	allowed := make(map[string]bool, len(of))
+	for _, v := range of {
+		allowed[v] = true
+	}
+	for _, v := range values {
+		if !allowed[v] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// trashStore is the narrow store capability behind the trash endpoints.
+// See postgres_store/trash_operations.go.
+type trashStore interface {
+	ListTrash(ctx context.Context, userID string, since time.Time) ([]models.Project, []models.Job, error)
+	GetTrashedProject(ctx context.Context, projectID string) (*models.Project, error)
+	GetTrashedJob(ctx context.Context, jobID string) (*models.Job, error)
+	RestoreProject(ctx context.Context, projectID string) error
+	RestoreJob(ctx context.Context, jobID string) error
+	PurgeProject(ctx context.Context, projectID string) error
+	PurgeJob(ctx context.Context, jobID string) error
+}
+
+// TrashHandler lists the trash and purges from it.
+type TrashHandler struct {
+	BaseHandler
+	store store.Store
+}
+
+// NewTrashHandler creates a new trash handler
+func NewTrashHandler(store store.Store) *TrashHandler {
+	return &TrashHandler{
+		store: store,
+	}
+}
+
+// TrashedProjectResponse is a project in the trash.
+type TrashedProjectResponse struct {
+	ProjectID string     `json:"project_id"`
+	Name      string     `json:"name"`
+	RepoURL   string     `json:"repo_url"`
+	UserID    *string    `json:"user_id,omitempty"`
+	DeletedAt time.Time  `json:"deleted_at"`
+	PurgeAt   *time.Time `json:"purge_at,omitempty"`
+}
+
+// TrashedJobResponse is a job in the trash.
+type TrashedJobResponse struct {
+	JobID     string     `json:"job_id"`
+	Name      string     `json:"name"`
+	ProjectID *string    `json:"project_id,omitempty"`
+	UserID    string     `json:"user_id"`
+	Status    string     `json:"status"`
+	Archived  bool       `json:"archived,omitempty"`
+	DeletedAt time.Time  `json:"deleted_at"`
+	PurgeAt   *time.Time `json:"purge_at,omitempty"`
+}
+
+// TrashResponse is the JSON body of GET /api/v1/trash.
+type TrashResponse struct {
+	Projects []TrashedProjectResponse `json:"projects"`
+	Jobs     []TrashedJobResponse     `json:"jobs"`
+}
+
+// trashRetention is how long deleted projects and jobs stay restorable,
+// zero if they are never purged.
+func trashRetention() time.Duration {
+	return time.Duration(config.TrashRetentionDays) * 24 * time.Hour
+}
+
+// trashPurgeAt is when something deleted at deletedAt will be purged.
+func trashPurgeAt(deletedAt time.Time) *time.Time {
+	if trashRetention() <= 0 {
+		return nil
+	}
+	purgeAt := deletedAt.Add(trashRetention())
+	return &purgeAt
+}
+
+// ListTrash handles GET /api/v1/trash: the projects and jobs the user
+// deleted that can still be restored, most recently deleted first. Admins
+// see everyone's.
+func (h *TrashHandler) ListTrash(w http.ResponseWriter, r *http.Request) {
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return
+	}
+	ts, ok := h.store.(trashStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("trash not available"))
+		return
+	}
+	userID := user.UserID
+	if h.isAdmin(user) {
+		userID = ""
+	}
+	var since time.Time
+	if retention := trashRetention(); retention > 0 {
+		since = time.Now().UTC().Add(-retention)
+	}
+	projects, jobs, err := ts.ListTrash(r.Context(), userID, since)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response := TrashResponse{
+		Projects: make([]TrashedProjectResponse, 0, len(projects)),
+		Jobs:     make([]TrashedJobResponse, 0, len(jobs)),
+	}
+	for _, p := range projects {
+		response.Projects = append(response.Projects, TrashedProjectResponse{
+			ProjectID: p.ProjectID,
+			Name:      p.Name,
+			RepoURL:   p.RepoURL,
+			UserID:    p.UserID,
+			DeletedAt: p.DeletedAt.Time,
+			PurgeAt:   trashPurgeAt(p.DeletedAt.Time),
+		})
+	}
+	for _, j := range jobs {
+		response.Jobs = append(response.Jobs, TrashedJobResponse{
+			JobID:     j.JobID,
+			Name:      j.Name,
+			ProjectID: j.ProjectID,
+			UserID:    j.UserID,
+			Status:    j.Status,
+			Archived:  j.Archived,
+			DeletedAt: j.DeletedAt.Time,
+			PurgeAt:   trashPurgeAt(j.DeletedAt.Time),
+		})
+	}
+	h.respondWithJSON(w, http.StatusOK, response)
+}
+
+// PurgeProject handles DELETE /api/v1/admin/trash/projects/{project_id},
+// permanently deleting a project in the trash.
+func (h *TrashHandler) PurgeProject(w http.ResponseWriter, r *http.Request) {
+	h.purge(w, r, "project_id", func(ts trashStore, id string) error {
+		return ts.PurgeProject(r.Context(), id)
+	})
+}
+
+// PurgeJob handles DELETE /api/v1/admin/trash/jobs/{job_id}, permanently
+// deleting a job in the trash.
+func (h *TrashHandler) PurgeJob(w http.ResponseWriter, r *http.Request) {
+	h.purge(w, r, "job_id", func(ts trashStore, id string) error {
+		return ts.PurgeJob(r.Context(), id)
+	})
+}
+
+func (h *TrashHandler) purge(w http.ResponseWriter, r *http.Request, idKey string, purge func(trashStore, string) error) {
+	ts, ok := h.store.(trashStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("trash not available"))
+		return
+	}
+	id := h.getID(r, idKey)
+	if id == "" {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+	if err := purge(ts, id); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TrashHandler) isAdmin(user *models.User) bool {
+	for _, role := range user.Roles {
+		if role == "admin" || role == "system_admin" {
+			return true
+		}
+	}
+	return false
+}
+
+// RestoreProject handles POST /api/v1/projects/{project_id}/restore,
+// taking a deleted project out of the trash. Restoring fails with 409 if
+// another project has since taken its repository and config path.
+func (h *ProjectHandler) RestoreProject(w http.ResponseWriter, r *http.Request) {
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return
+	}
+	ts, ok := h.store.(trashStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("trash not available"))
+		return
+	}
+	project, err := ts.GetTrashedProject(r.Context(), h.getID(r, "project_id"))
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !h.canManageProject(r.Context(), user, project) {
+		h.respondWithError(w, http.StatusForbidden, store.ErrForbidden)
+		return
+	}
+	if err := ts.RestoreProject(r.Context(), project.ProjectID); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	project.DeletedAt.Valid = false
+	h.respondWithJSON(w, http.StatusOK, projectToResponse(h.withGroupDefaults(r.Context(), project)))
+}
+
+// RestoreJob handles POST /api/v1/jobs/{job_id}/restore, taking a deleted
+// job out of the trash. Like deleting, it's for the job's owner or an
+// admin.
+func (h *JobHandler) RestoreJob(w http.ResponseWriter, r *http.Request) {
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return
+	}
+	ts, ok := h.store.(trashStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("trash not available"))
+		return
+	}
+	job, err := ts.GetTrashedJob(r.Context(), h.getID(r, "job_id"))
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !h.isAdmin(user) && job.UserID != user.UserID {
+		h.respondWithError(w, http.StatusForbidden, store.ErrForbidden)
+		return
+	}
+	if err := ts.RestoreJob(r.Context(), job.JobID); err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	job.DeletedAt.Valid = false
+	h.respondWithJSON(w, http.StatusOK, h.jobToResponse(job))
+}
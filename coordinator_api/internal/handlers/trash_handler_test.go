@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// trashMockStore keeps trashed projects and jobs in memory on top of
+// ProjectMockStore.
+type trashMockStore struct {
+	ProjectMockStore
+	projects  map[string]*models.Project
+	jobs      map[string]*models.Job
+	listedFor string
+	conflict  bool
+}
+
+func (m *trashMockStore) ListTrash(ctx context.Context, userID string, since time.Time) ([]models.Project, []models.Job, error) {
+	m.listedFor = userID
+	var projects []models.Project
+	for _, p := range m.projects {
+		if userID == "" || (p.UserID != nil && *p.UserID == userID) {
+			projects = append(projects, *p)
+		}
+	}
+	var jobs []models.Job
+	for _, j := range m.jobs {
+		if userID == "" || j.UserID == userID {
+			jobs = append(jobs, *j)
+		}
+	}
+	return projects, jobs, nil
+}
+
+func (m *trashMockStore) GetTrashedProject(ctx context.Context, projectID string) (*models.Project, error) {
+	if p, ok := m.projects[projectID]; ok {
+		return p, nil
+	}
+	return nil, store.ErrNotFound
+}
+
+func (m *trashMockStore) GetTrashedJob(ctx context.Context, jobID string) (*models.Job, error) {
+	if j, ok := m.jobs[jobID]; ok {
+		return j, nil
+	}
+	return nil, store.ErrNotFound
+}
+
+func (m *trashMockStore) RestoreProject(ctx context.Context, projectID string) error {
+	if m.conflict {
+		return store.ErrAlreadyExists
+	}
+	delete(m.projects, projectID)
+	return nil
+}
+
+func (m *trashMockStore) RestoreJob(ctx context.Context, jobID string) error {
+	delete(m.jobs, jobID)
+	return nil
+}
+
+func (m *trashMockStore) PurgeProject(ctx context.Context, projectID string) error {
+	if _, ok := m.projects[projectID]; !ok {
+		return store.ErrNotFound
+	}
+	delete(m.projects, projectID)
+	return nil
+}
+
+func (m *trashMockStore) PurgeJob(ctx context.Context, jobID string) error {
+	if _, ok := m.jobs[jobID]; !ok {
+		return store.ErrNotFound
+	}
+	delete(m.jobs, jobID)
+	return nil
+}
+
+func newTrashMockStore() *trashMockStore {
+	owner, other := "test-user-id", "other-user-id"
+	deleted := gorm.DeletedAt{Time: time.Now().UTC().Add(-time.Hour), Valid: true}
+	return &trashMockStore{
+		projects: map[string]*models.Project{
+			"project-1": {ProjectID: "project-1", Name: "mine", UserID: &owner, DeletedAt: deleted},
+			"project-2": {ProjectID: "project-2", Name: "theirs", UserID: &other, DeletedAt: deleted},
+		},
+		jobs: map[string]*models.Job{
+			"job-1": {JobID: "job-1", UserID: owner, Status: "completed", DeletedAt: deleted},
+			"job-2": {JobID: "job-2", UserID: other, Status: "failed", DeletedAt: deleted},
+		},
+	}
+}
+
+func TestTrashHandler_ListTrash(t *testing.T) {
+	s := newTrashMockStore()
+	h := NewTrashHandler(s)
+
+	w := httptest.NewRecorder()
+	h.ListTrash(w, withUser(httptest.NewRequest(http.MethodGet, "/api/v1/trash", nil)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response TrashResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, "test-user-id", s.listedFor)
+	require.Len(t, response.Projects, 1)
+	assert.Equal(t, "project-1", response.Projects[0].ProjectID)
+	require.NotNil(t, response.Projects[0].PurgeAt)
+	assert.True(t, response.Projects[0].PurgeAt.After(response.Projects[0].DeletedAt))
+	require.Len(t, response.Jobs, 1)
+	assert.Equal(t, "job-1", response.Jobs[0].JobID)
+
+	w = httptest.NewRecorder()
+	h.ListTrash(w, withAdmin(httptest.NewRequest(http.MethodGet, "/api/v1/trash", nil)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, "", s.listedFor)
+	assert.Len(t, response.Projects, 2)
+	assert.Len(t, response.Jobs, 2)
+}
+
+func TestTrashHandler_Restore(t *testing.T) {
+	restoreProject := func(s *trashMockStore, projectID string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/projects/"+projectID+"/restore", nil)
+		w := httptest.NewRecorder()
+		NewProjectHandler(s).RestoreProject(w, withProjectID(withUser(r), projectID))
+		return w
+	}
+
+	t.Run("owner restores a project", func(t *testing.T) {
+		s := newTrashMockStore()
+		w := restoreProject(s, "project-1")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.NotContains(t, s.projects, "project-1")
+	})
+
+	t.Run("someone else's project is forbidden", func(t *testing.T) {
+		s := newTrashMockStore()
+		assert.Equal(t, http.StatusForbidden, restoreProject(s, "project-2").Code)
+		assert.Contains(t, s.projects, "project-2")
+	})
+
+	t.Run("a project not in the trash is not found", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, restoreProject(newTrashMockStore(), "project-3").Code)
+	})
+
+	t.Run("a project whose repository was taken conflicts", func(t *testing.T) {
+		s := newTrashMockStore()
+		s.conflict = true
+		assert.Equal(t, http.StatusConflict, restoreProject(s, "project-1").Code)
+	})
+
+	t.Run("owner restores a job, others can't", func(t *testing.T) {
+		s := newTrashMockStore()
+		h := NewJobHandler(s, nil)
+		for jobID, want := range map[string]int{"job-1": http.StatusOK, "job-2": http.StatusForbidden} {
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/jobs/"+jobID+"/restore", nil)
+			r = r.WithContext(setIDContext(r.Context(), "job_id", jobID))
+			w := httptest.NewRecorder()
+			h.RestoreJob(w, withUser(r))
+			assert.Equal(t, want, w.Code, jobID)
+		}
+		assert.NotContains(t, s.jobs, "job-1")
+		assert.Contains(t, s.jobs, "job-2")
+	})
+}
+
+func TestTrashHandler_Purge(t *testing.T) {
+	s := newTrashMockStore()
+	h := NewTrashHandler(s)
+	purgeJob := func(jobID string) int {
+		r := httptest.NewRequest(http.MethodDelete, "/api/v1/admin/trash/jobs/"+jobID, nil)
+		r = r.WithContext(setIDContext(r.Context(), "job_id", jobID))
+		w := httptest.NewRecorder()
+		h.PurgeJob(w, withAdmin(r))
+		return w.Code
+	}
+	assert.Equal(t, http.StatusNoContent, purgeJob("job-2"))
+	assert.NotContains(t, s.jobs, "job-2")
+	assert.Equal(t, http.StatusNotFound, purgeJob("job-2"))
+
+	r := httptest.NewRequest(http.MethodDelete, "/api/v1/admin/trash/projects/project-1", nil)
+	w := httptest.NewRecorder()
+	h.PurgeProject(w, withAdmin(withProjectID(r, "project-1")))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.NotContains(t, s.projects, "project-1")
+}
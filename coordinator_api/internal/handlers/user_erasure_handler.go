@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/jobcontrol"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// userErasureStore is the narrow store capability behind the user erasure
+// endpoints. See postgres_store/user_erasure_operations.go.
+type userErasureStore interface {
+	EraseUser(ctx context.Context, userID string, opts models.UserErasureOptions) (*models.UserErasureReport, error)
+	SetUserErasureReport(ctx context.Context, report *models.UserErasureReport) error
+	ListUserErasures(ctx context.Context, userID string) ([]models.UserErasure, error)
+	SetJobLogsTier(ctx context.Context, jobID, tier string, bytes int64, logsObjectKey string, at time.Time) error
+}
+
+// EraseUserRequest is the body of POST /api/v1/admin/users/{user_id}/erase.
+type EraseUserRequest struct {
+	// Mode is "anonymize" (the default) or "delete".
+	Mode       string `json:"mode"`
+	ReassignTo string `json:"reassign_to,omitempty"`
+	DryRun     bool   `json:"dry_run,omitempty"`
+	// DeleteLogs deletes the logs of the user's jobs from the object store.
+	DeleteLogs bool `json:"delete_logs,omitempty"`
+}
+
+// ListUserErasuresResponse is the JSON body of
+// GET /api/v1/admin/user-erasures.
+type ListUserErasuresResponse struct {
+	Erasures []models.UserErasure `json:"erasures"`
+	Total    int                  `json:"total"`
+}
+
+// EraseUser handles POST /api/v1/admin/users/{user_id}/erase: offboard a
+// user's personal data. Anonymizing keeps the user, deactivated, under a
+// placeholder name, so its jobs, projects and secrets stay put; deleting
+// hands its jobs, workflows and projects to reassign_to and removes it,
+// and is refused (409) while it owns secrets. Either way its tokens,
+// sessions, SSO identities and memberships go, and its names and email
+// are scrubbed from jobs. The response is the erasure report, also kept
+// for GET /api/v1/admin/user-erasures; dry_run only reports. Admin only;
+// the route applies the role check.
+func (h *JobHandler) EraseUser(w http.ResponseWriter, r *http.Request) {
+	erasureStore, ok := h.store.(userErasureStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("user erasure not available"))
+		return
+	}
+	userID, _ := r.Context().Value(GetContextKey("user_id")).(string)
+	admin := checkauth.GetUserFromContext(r.Context())
+	if admin == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return
+	}
+	var req EraseUserRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = models.UserErasureAnonymize
+	}
+	if userID == admin.UserID {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: "Admins can't erase themselves"})
+		return
+	}
+
+	report, err := erasureStore.EraseUser(r.Context(), userID, models.UserErasureOptions{
+		Mode:        req.Mode,
+		ReassignTo:  req.ReassignTo,
+		DryRun:      req.DryRun,
+		RequestedBy: admin.UserID,
+	})
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		h.respondWithError(w, http.StatusNotFound, err)
+		return
+	case errors.Is(err, store.ErrInvalidInput):
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: err.Error()})
+		return
+	case errors.Is(err, store.ErrReferenceViolation):
+		h.respondWithJSON(w, http.StatusConflict, ErrorResponse{Error: "conflict", Message: err.Error()})
+		return
+	case err != nil:
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if len(report.JobIDs) > 0 {
+		switch {
+		case !req.DeleteLogs:
+			report.Notes = append(report.Notes, fmt.Sprintf("The logs of the user's %d jobs were kept; erase again with delete_logs to delete them.", len(report.JobIDs)))
+		case h.objectStore == nil:
+			report.Notes = append(report.Notes, "No object store is configured, so no logs were deleted.")
+		case !req.DryRun:
+			if err := h.deleteErasedUserLogs(r.Context(), erasureStore, report); err != nil {
+				h.respondWithError(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+		if !req.DryRun {
+			if err := erasureStore.SetUserErasureReport(r.Context(), report); err != nil {
+				h.respondWithError(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+	}
+	h.respondWithJSON(w, http.StatusOK, report)
+}
+
+// deleteErasedUserLogs deletes the logs of an erased user's jobs, counting
+// them in the report.
+func (h *JobHandler) deleteErasedUserLogs(ctx context.Context, erasureStore userErasureStore, report *models.UserErasureReport) error {
+	for _, jobID := range report.JobIDs {
+		job, err := h.store.GetJobByID(ctx, jobID)
+		if errors.Is(err, store.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		deleted, err := jobcontrol.DeleteJobLogs(ctx, h.objectStore, job)
+		report.LogsDeleted += int64(deleted)
+		if err != nil {
+			return fmt.Errorf("failed to delete the logs of job %s: %w", jobID, err)
+		}
+		// Archived jobs aren't in jobs; their tier stays as it was.
+		if err := erasureStore.SetJobLogsTier(ctx, jobID, models.LogsTierDeleted, 0, "", report.CreatedAt); err != nil && !errors.Is(err, store.ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListUserErasures handles GET /api/v1/admin/user-erasures: the recorded
+// erasure reports, newest first, only those of user_id if it's set. Admin
+// only; the route applies the role check.
+func (h *JobHandler) ListUserErasures(w http.ResponseWriter, r *http.Request) {
+	erasureStore, ok := h.store.(userErasureStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("user erasure not available"))
+		return
+	}
+	erasures, err := erasureStore.ListUserErasures(r.Context(), r.URL.Query().Get("user_id"))
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, ListUserErasuresResponse{Erasures: erasures, Total: len(erasures)})
+}
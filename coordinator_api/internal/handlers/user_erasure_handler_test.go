@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// userErasureMockStore erases users on top of MockStore, recording what it
+// was asked to do.
+type userErasureMockStore struct {
+	MockStore
+	opts      models.UserErasureOptions
+	eraseErr  error
+	saved     *models.UserErasureReport
+	tiers     map[string]string
+	erasures  []models.UserErasure
+	listedFor string
+}
+
+func (m *userErasureMockStore) EraseUser(ctx context.Context, userID string, opts models.UserErasureOptions) (*models.UserErasureReport, error) {
+	m.opts = opts
+	if m.eraseErr != nil {
+		return nil, m.eraseErr
+	}
+	return &models.UserErasureReport{
+		ErasureID: "erasure-1", UserID: userID, Mode: opts.Mode, DryRun: opts.DryRun,
+		AnonymizedUsername: models.AnonymizedUsername(userID), JobsScrubbed: 2,
+		JobIDs: []string{"job-1", "job-2"},
+	}, nil
+}
+
+func (m *userErasureMockStore) SetUserErasureReport(ctx context.Context, report *models.UserErasureReport) error {
+	m.saved = report
+	return nil
+}
+
+func (m *userErasureMockStore) ListUserErasures(ctx context.Context, userID string) ([]models.UserErasure, error) {
+	m.listedFor = userID
+	return m.erasures, nil
+}
+
+func (m *userErasureMockStore) SetJobLogsTier(ctx context.Context, jobID, tier string, bytes int64, logsObjectKey string, at time.Time) error {
+	m.tiers[jobID] = tier
+	return nil
+}
+
+func TestJobHandler_EraseUser(t *testing.T) {
+	newHandler := func() (*JobHandler, *userErasureMockStore, objects.ObjectStore) {
+		s := &userErasureMockStore{tiers: map[string]string{}}
+		s.GetJobByIDFunc = func(ctx context.Context, jobID string) (*models.Job, error) {
+			return &models.Job{JobID: jobID}, nil
+		}
+		objectStore := objects.NewMemoryObjectStore()
+		for _, key := range []string{"logs/job-1/stdout.json", "logs/job-1/stderr.json", "logs/job-2/stdout.json", "logs/job-3/stdout.json"} {
+			require.NoError(t, objectStore.Put(context.Background(), key, bytes.NewReader([]byte("[]")), "application/json"))
+		}
+		return NewJobHandlerWithObjectStore(s, nil, objectStore), s, objectStore
+	}
+	erase := func(h *JobHandler, userID, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/users/"+userID+"/erase", bytes.NewBufferString(body))
+		req = withUser(req)
+		req = req.WithContext(context.WithValue(req.Context(), GetContextKey("user_id"), userID))
+		w := httptest.NewRecorder()
+		h.EraseUser(w, req)
+		return w
+	}
+
+	t.Run("anonymizes by default and keeps logs", func(t *testing.T) {
+		h, s, objectStore := newHandler()
+		w := erase(h, "user-1", `{}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, models.UserErasureOptions{Mode: models.UserErasureAnonymize, RequestedBy: "test-user-id"}, s.opts)
+
+		var report models.UserErasureReport
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+		assert.Equal(t, int64(2), report.JobsScrubbed)
+		assert.Empty(t, report.JobIDs, "job ids stay out of the response")
+		require.NotNil(t, s.saved)
+		assert.Contains(t, s.saved.Notes, "The logs of the user's 2 jobs were kept; erase again with delete_logs to delete them.")
+		exists, _ := objectStore.Exists(context.Background(), "logs/job-1/stdout.json")
+		assert.True(t, exists)
+	})
+
+	t.Run("deletes logs on request", func(t *testing.T) {
+		h, s, objectStore := newHandler()
+		w := erase(h, "user-1", `{"mode":"delete","reassign_to":"user-2","delete_logs":true}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "user-2", s.opts.ReassignTo)
+		assert.Equal(t, int64(3), s.saved.LogsDeleted)
+		assert.Equal(t, map[string]string{"job-1": models.LogsTierDeleted, "job-2": models.LogsTierDeleted}, s.tiers)
+		for key, want := range map[string]bool{"logs/job-1/stdout.json": false, "logs/job-2/stdout.json": false, "logs/job-3/stdout.json": true} {
+			exists, _ := objectStore.Exists(context.Background(), key)
+			assert.Equal(t, want, exists, key)
+		}
+	})
+
+	t.Run("dry run deletes and saves nothing", func(t *testing.T) {
+		h, s, objectStore := newHandler()
+		w := erase(h, "user-1", `{"dry_run":true,"delete_logs":true}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.True(t, s.opts.DryRun)
+		assert.Nil(t, s.saved)
+		exists, _ := objectStore.Exists(context.Background(), "logs/job-1/stdout.json")
+		assert.True(t, exists)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		h, s, _ := newHandler()
+		assert.Equal(t, http.StatusBadRequest, erase(h, "test-user-id", `{}`).Code, "admins can't erase themselves")
+
+		for err, want := range map[error]int{
+			store.ErrNotFound: http.StatusNotFound,
+			fmt.Errorf("%w: reassign_to is required", store.ErrInvalidInput):       http.StatusBadRequest,
+			fmt.Errorf("%w: the user owns 3 secrets", store.ErrReferenceViolation): http.StatusConflict,
+		} {
+			s.eraseErr = err
+			assert.Equal(t, want, erase(h, "user-1", `{"mode":"delete"}`).Code, err.Error())
+		}
+	})
+}
+
+func TestJobHandler_ListUserErasures(t *testing.T) {
+	s := &userErasureMockStore{erasures: []models.UserErasure{{ErasureID: "erasure-1", UserID: "user-1", Mode: models.UserErasureAnonymize}}}
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/user-erasures?user_id=user-1", nil)
+	w := httptest.NewRecorder()
+	NewJobHandler(s, nil).ListUserErasures(w, withUser(req))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp ListUserErasuresResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, 1, resp.Total)
+	assert.Equal(t, "user-1", s.listedFor)
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/jobcontrol"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/worker"
+)
+
+// pushCoalescingStore is the store support for push coalescing. See
+// postgres_store/coalesce_operations.go.
+type pushCoalescingStore interface {
+	ListRecentPushEvalJobs(ctx context.Context, projectID, branch string, since time.Time) ([]models.Job, error)
+}
+
+// coalescePushes cancels the unfinished pipelines of the project's pushes
+// to branch in its coalesce window, so only job, the latest push's eval
+// job, runs to the end. The commits whose pipelines it cancelled, and the
+// ones those had coalesced in turn, are recorded in job.CoalescedSHAs,
+// newest first.
+func (h *WebhookHandler) coalescePushes(ctx context.Context, project *models.Project, branch string, job *models.Job) {
+	if project.CoalesceWindowSeconds <= 0 || job.CommitSHA == nil {
+		return
+	}
+	cs, ok := h.store.(pushCoalescingStore)
+	if !ok {
+		return
+	}
+	since := time.Now().UTC().Add(-time.Duration(project.CoalesceWindowSeconds) * time.Second)
+	evalJobs, err := cs.ListRecentPushEvalJobs(ctx, project.ProjectID, branch, since)
+	if err != nil {
+		h.logger.WithError(err).WithField("project", project.Name).Warn("Failed to list recent pushes to coalesce")
+		return
+	}
+
+	seen := map[string]bool{*job.CommitSHA: true}
+	for i := range evalJobs {
+		older := &evalJobs[i]
+		if older.CommitSHA == nil || seen[*older.CommitSHA] {
+			continue
+		}
+		cancelled := h.cancelPipeline(ctx, older)
+		if cancelled == 0 {
+			continue
+		}
+		h.logger.WithFields(logrus.Fields{
+			"project":   project.Name,
+			"branch":    branch,
+			"job_id":    older.JobID,
+			"sha":       *older.CommitSHA,
+			"cancelled": cancelled,
+			"latest":    *job.CommitSHA,
+		}).Info("Coalesced push into a newer one")
+		for _, sha := range append([]string{*older.CommitSHA}, older.CoalescedSHAs...) {
+			if !seen[sha] {
+				seen[sha] = true
+				job.CoalescedSHAs = append(job.CoalescedSHAs, sha)
+			}
+		}
+	}
+}
+
+// cancelPipeline cancels the unfinished jobs of evalJob's pipeline, the
+// jobs it spawned first, and returns how many it cancelled. An eval job
+// awaiting its children isn't cancelled itself: it settles once they have.
+func (h *WebhookHandler) cancelPipeline(ctx context.Context, evalJob *models.Job) int {
+	jobs := []models.Job{*evalJob}
+	for i := 0; i < len(jobs) && len(jobs) < worker.MaxChildJobs; i++ {
+		children, err := worker.ListChildJobs(ctx, h.store, jobs[i].JobID)
+		if err != nil {
+			h.logger.WithError(err).WithField("job_id", jobs[i].JobID).Warn("Failed to list child jobs to coalesce")
+			continue
+		}
+		jobs = append(jobs, children...)
+	}
+
+	cancelled := 0
+	for i := len(jobs) - 1; i >= 0; i-- {
+		job := &jobs[i]
+		if !job.CanBeCancelled() || job.IsAwaitingChildren() {
+			continue
+		}
+		updated, err := jobcontrol.CancelJob(ctx, h.store, h.corndogsClient, job)
+		if err != nil {
+			continue
+		}
+		cancelled++
+		// A job cancelled before any worker claimed it is finished here;
+		// the worker reports the rest.
+		if h.statusUpdater != nil && updated.Status == "cancelled" {
+			if err := h.statusUpdater.UpdateJobStatus(ctx, updated); err != nil {
+				h.logger.WithError(err).WithField("job_id", updated.JobID).Warn("Failed to update status of coalesced job")
+			}
+		}
+	}
+	return cancelled
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// coalescingWebhookStore serves a branch's recent push eval jobs and their
+// children on top of WebhookMockStore.
+type coalescingWebhookStore struct {
+	WebhookMockStore
+	recent   []models.Job
+	children map[string][]models.Job
+}
+
+func (m *coalescingWebhookStore) ListRecentPushEvalJobs(ctx context.Context, projectID, branch string, since time.Time) ([]models.Job, error) {
+	if branch != "main" {
+		return nil, nil
+	}
+	return m.recent, nil
+}
+
+func (m *coalescingWebhookStore) ListJobs(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]models.Job, error) {
+	parentID, _ := filters["parent_job_id"].(string)
+	return m.children[parentID], nil
+}
+
+func (m *coalescingWebhookStore) updatedStatus(jobID string) string {
+	status := ""
+	for _, job := range m.UpdateJobCalls {
+		if job.JobID == jobID {
+			status = job.Status
+		}
+	}
+	return status
+}
+
+func newCoalescingWebhookStore(window int) *coalescingWebhookStore {
+	project := webhookTestProject()
+	project.CoalesceWindowSeconds = window
+	sha := func(s string) *string { return &s }
+	parentID := "eval-2"
+	return &coalescingWebhookStore{
+		WebhookMockStore: WebhookMockStore{
+			GetProjectByRepoURLFunc: func(ctx context.Context, repoURL string) (*models.Project, error) {
+				return project, nil
+			},
+		},
+		recent: []models.Job{
+			{JobID: "redelivered", Status: "running", CommitSHA: sha("after-sha-1234")},
+			{JobID: "eval-2", Status: "running", CommitSHA: sha("sha-2"), CoalescedSHAs: []string{"sha-1"}},
+			{JobID: "eval-0", Status: "completed", CommitSHA: sha("sha-0")},
+		},
+		children: map[string][]models.Job{
+			"eval-2": {{JobID: "child-2", Status: "queued", ParentJobID: &parentID}},
+		},
+	}
+}
+
+func postCoalescedPush(t *testing.T, s *coalescingWebhookStore) *httptest.ResponseRecorder {
+	t.Helper()
+	handler := NewWebhookHandler(s, corndogs.NewMockClient())
+	handler.SetTokenResolver(testTokenResolver())
+	handler.AddVCSClient(vcs.GitHub, &MockVCSClient{
+		ParseWebhookFunc: func(r *http.Request) (*vcs.WebhookEvent, error) {
+			return &vcs.WebhookEvent{
+				Provider:     vcs.GitHub,
+				EventType:    "push",
+				GenericEvent: vcs.EventPush,
+				Repository: vcs.RepositoryInfo{
+					FullName: "test-org/test-repo",
+					CloneURL: "https://github.com/test-org/test-repo.git",
+				},
+				Push: &vcs.PushInfo{Ref: "refs/heads/main", Before: "sha-2", After: "after-sha-1234"},
+			}, nil
+		},
+	})
+
+	body := makePushWebhookBody("test-org/test-repo", "https://github.com/test-org/test-repo.git", "after-sha-1234", "refs/heads/main")
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/github", bytes.NewReader(body))
+	req.Header.Set("X-GitHub-Event", "push")
+	w := httptest.NewRecorder()
+	handler.HandleGitHubWebhook(w, req)
+	return w
+}
+
+func TestWebhookHandler_CoalescePushes(t *testing.T) {
+	t.Run("cancels older pipelines and records their commits", func(t *testing.T) {
+		s := newCoalescingWebhookStore(60)
+		w := postCoalescedPush(t, s)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Len(t, s.CreateJobCalls, 1)
+
+		assert.Equal(t, "cancelled", s.updatedStatus("child-2"))
+		assert.Equal(t, "cancelling", s.updatedStatus("eval-2"))
+		assert.Empty(t, s.updatedStatus("eval-0"), "a finished pipeline is left alone")
+		assert.Empty(t, s.updatedStatus("redelivered"), "a job for the same commit is left alone")
+
+		assert.Equal(t, []string{"sha-2", "sha-1"}, []string(s.CreateJobCalls[0].CoalescedSHAs))
+	})
+
+	t.Run("disabled without a window", func(t *testing.T) {
+		s := newCoalescingWebhookStore(0)
+		w := postCoalescedPush(t, s)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Len(t, s.CreateJobCalls, 1)
+
+		assert.Empty(t, s.updatedStatus("eval-2"))
+		assert.Empty(t, s.CreateJobCalls[0].CoalescedSHAs)
+	})
+}
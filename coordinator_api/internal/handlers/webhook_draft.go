@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
+)
+
+// applyDraftPRPolicy enforces the project's draft PR policy on a pull
+// request event and returns a filter reason if it shouldn't build.
+//
+// Under the defer policy, a PR marked ready for review, or retitled from a
+// WIP title, is rewritten to pull_request_opened so it builds (and is
+// filtered) like a newly opened PR. Those transitions never build under
+// the other policies: run already built the PR, and skip waits for the
+// next push.
+func applyDraftPRPolicy(event *vcs.WebhookEvent, project *models.Project) string {
+	pr := event.PullRequest
+	isDraft := pr.Draft || project.IsWIPTitle(pr.Title)
+
+	switch event.GenericEvent {
+	case vcs.EventPullRequestReadyForReview, vcs.EventPullRequestRetitled:
+		if project.DraftPRPolicy != models.DraftPRPolicyDefer {
+			return models.EventFilterEventType
+		}
+		if event.GenericEvent == vcs.EventPullRequestRetitled && !project.IsWIPTitle(pr.PreviousTitle) {
+			return models.EventFilterEventType
+		}
+		if isDraft {
+			return models.EventFilterDraftPR
+		}
+		event.GenericEvent = vcs.EventPullRequestOpened
+	case vcs.EventPullRequestOpened, vcs.EventPullRequestUpdated:
+		if isDraft && project.DefersDraftPRs() {
+			return models.EventFilterDraftPR
+		}
+	}
+	return ""
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyDraftPRPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     string
+		event      vcs.EventType
+		pr         vcs.PullRequestInfo
+		wantReason string
+		wantEvent  vcs.EventType
+	}{
+		{
+			name:      "run builds drafts",
+			policy:    models.DraftPRPolicyRun,
+			event:     vcs.EventPullRequestOpened,
+			pr:        vcs.PullRequestInfo{Title: "Add login", Draft: true},
+			wantEvent: vcs.EventPullRequestOpened,
+		},
+		{
+			name:      "empty policy builds drafts",
+			event:     vcs.EventPullRequestUpdated,
+			pr:        vcs.PullRequestInfo{Title: "Add login", Draft: true},
+			wantEvent: vcs.EventPullRequestUpdated,
+		},
+		{
+			name:       "skip holds drafts",
+			policy:     models.DraftPRPolicySkip,
+			event:      vcs.EventPullRequestUpdated,
+			pr:         vcs.PullRequestInfo{Title: "Add login", Draft: true},
+			wantReason: models.EventFilterDraftPR,
+		},
+		{
+			name:       "defer holds WIP titles",
+			policy:     models.DraftPRPolicyDefer,
+			event:      vcs.EventPullRequestOpened,
+			pr:         vcs.PullRequestInfo{Title: "[wip] Add login"},
+			wantReason: models.EventFilterDraftPR,
+		},
+		{
+			name:      "defer builds ready PRs",
+			policy:    models.DraftPRPolicyDefer,
+			event:     vcs.EventPullRequestOpened,
+			pr:        vcs.PullRequestInfo{Title: "Add login"},
+			wantEvent: vcs.EventPullRequestOpened,
+		},
+		{
+			name:      "defer builds on ready for review",
+			policy:    models.DraftPRPolicyDefer,
+			event:     vcs.EventPullRequestReadyForReview,
+			pr:        vcs.PullRequestInfo{Title: "Add login"},
+			wantEvent: vcs.EventPullRequestOpened,
+		},
+		{
+			name:       "ready for review with a WIP title stays held",
+			policy:     models.DraftPRPolicyDefer,
+			event:      vcs.EventPullRequestReadyForReview,
+			pr:         vcs.PullRequestInfo{Title: "WIP: Add login"},
+			wantReason: models.EventFilterDraftPR,
+		},
+		{
+			name:      "defer builds when the WIP title is dropped",
+			policy:    models.DraftPRPolicyDefer,
+			event:     vcs.EventPullRequestRetitled,
+			pr:        vcs.PullRequestInfo{Title: "Add login", PreviousTitle: "WIP: Add login"},
+			wantEvent: vcs.EventPullRequestOpened,
+		},
+		{
+			name:       "other retitles don't build",
+			policy:     models.DraftPRPolicyDefer,
+			event:      vcs.EventPullRequestRetitled,
+			pr:         vcs.PullRequestInfo{Title: "Add login page", PreviousTitle: "Add login"},
+			wantReason: models.EventFilterEventType,
+		},
+		{
+			name:       "skip doesn't build on ready for review",
+			policy:     models.DraftPRPolicySkip,
+			event:      vcs.EventPullRequestReadyForReview,
+			pr:         vcs.PullRequestInfo{Title: "Add login"},
+			wantReason: models.EventFilterEventType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := &models.Project{DraftPRPolicy: tt.policy, WIPTitlePatterns: []string{`^\[?wip\b`}}
+			pr := tt.pr
+			event := &vcs.WebhookEvent{GenericEvent: tt.event, PullRequest: &pr}
+
+			assert.Equal(t, tt.wantReason, applyDraftPRPolicy(event, project))
+			if tt.wantReason == "" {
+				assert.Equal(t, tt.wantEvent, event.GenericEvent)
+			}
+		})
+	}
+}
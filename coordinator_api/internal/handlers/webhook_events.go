@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
+	"github.com/sirupsen/logrus"
+)
+
+// maxEventChildJobs caps how many triggered jobs are listed per event.
+const maxEventChildJobs = 100
+
+// webhookEventStore is the narrow store capability behind the webhook
+// event ledger. See postgres_store/webhook_event_operations.go. Without
+// it, webhooks are processed as before, just unrecorded and without
+// redelivery deduplication.
+type webhookEventStore interface {
+	ClaimWebhookEvent(ctx context.Context, event *models.WebhookEvent) (bool, *models.WebhookEvent, error)
+	UpdateWebhookEvent(ctx context.Context, event *models.WebhookEvent) error
+	ListWebhookEvents(ctx context.Context, projectID, status string, limit, offset int) ([]models.WebhookEvent, error)
+}
+
+// archivedWebhookEventStore lists ledger entries including those moved to
+// webhook_events_archive, for ?include_archived=true.
+type archivedWebhookEventStore interface {
+	ListWebhookEventsWithArchive(ctx context.Context, projectID, status string, limit, offset int) ([]models.WebhookEvent, error)
+}
+
+// newWebhookEventRecord builds the ledger entry for a parsed event.
+// project is the project resolved from the payload, if any.
+func newWebhookEventRecord(event *vcs.WebhookEvent, project *models.Project) *models.WebhookEvent {
+	record := &models.WebhookEvent{
+		Provider:     string(event.Provider),
+		EventType:    event.EventType,
+		GenericEvent: string(event.GenericEvent),
+		Repo:         event.Repository.FullName,
+		ReceivedAt:   time.Now().UTC(),
+	}
+	if event.DeliveryID != "" {
+		record.DeliveryID = &event.DeliveryID
+	}
+	if project != nil {
+		record.ProjectID = &project.ProjectID
+	}
+	switch {
+	case event.PullRequest != nil:
+		record.Ref = event.PullRequest.BaseRef
+		record.CommitSHA = event.PullRequest.HeadSHA
+		record.PRNumber = &event.PullRequest.Number
+	case event.Push != nil:
+		record.Ref = strings.TrimPrefix(event.Push.Ref, "refs/heads/")
+		record.CommitSHA = event.Push.After
+	}
+	return record
+}
+
+// claimWebhookEvent records the delivery and reports whether this request
+// should process it: false means it's a redelivery of an event that was
+// already handled. Ledger errors don't block processing.
+func (h *WebhookHandler) claimWebhookEvent(record *models.WebhookEvent) bool {
+	es, ok := h.store.(webhookEventStore)
+	if !ok {
+		return true
+	}
+	claimed, existing, err := es.ClaimWebhookEvent(context.Background(), record)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to record webhook event in ledger")
+		return true
+	}
+	if !claimed {
+		h.logger.WithFields(logrus.Fields{
+			"delivery_id": *record.DeliveryID,
+			"event_id":    existing.EventID,
+			"status":      existing.Status,
+		}).Info("Ignoring redelivered webhook event")
+	}
+	return claimed
+}
+
+// failWebhookEvent records a processing error, which lets a redelivery of
+// the same event be processed again.
+func (h *WebhookHandler) failWebhookEvent(record *models.WebhookEvent, err error) {
+	record.Status = models.WebhookEventFailed
+	record.Error = err.Error()
+	h.finishWebhookEvent(record)
+}
+
+// finishWebhookEvent saves the outcome of processing. An event that was
+// neither filtered nor failed and produced no job is recorded as
+// processed. Nothing is saved if the record's lease (NextAttemptAt) has
+// been taken over.
+func (h *WebhookHandler) finishWebhookEvent(record *models.WebhookEvent) {
+	es, ok := h.store.(webhookEventStore)
+	if !ok || record.EventID == "" {
+		return
+	}
+	if record.Status == models.WebhookEventProcessing {
+		record.Status = models.WebhookEventProcessed
+	}
+	now := time.Now().UTC()
+	record.ProcessedAt = &now
+	err := es.UpdateWebhookEvent(context.Background(), record)
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		h.logger.WithField("event_id", record.EventID).Warn("Webhook event lease lost before its outcome was recorded")
+	case err != nil:
+		h.logger.WithError(err).WithField("event_id", record.EventID).Warn("Failed to update webhook event in ledger")
+	}
+}
+
+// ProjectEventJob is a job produced by a webhook event: its eval job, or a
+// job the eval job triggered.
+type ProjectEventJob struct {
+	JobID  string `json:"job_id"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// ProjectEventResponse is one ledger entry with the jobs it produced.
+type ProjectEventResponse struct {
+	models.WebhookEvent
+	Jobs []ProjectEventJob `json:"jobs"`
+}
+
+// ListProjectEventsResponse is the JSON body of
+// GET /api/v1/projects/{project_id}/events.
+type ListProjectEventsResponse struct {
+	Events []ProjectEventResponse `json:"events"`
+	Limit  int                    `json:"limit"`
+	Offset int                    `json:"offset"`
+}
+
+// ListProjectEvents handles GET /api/v1/projects/{project_id}/events: the
+// VCS events received for the project, newest first, with whether each was
+// filtered (and why) and the jobs it produced. status restricts the list
+// to processed, filtered, failed or processing events.
+func (h *ProjectHandler) ListProjectEvents(w http.ResponseWriter, r *http.Request) {
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return
+	}
+	eventStore, ok := h.store.(webhookEventStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("webhook event ledger not available"))
+		return
+	}
+
+	projectID := h.getID(r, "project_id")
+	if projectID == "" {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+	project, err := h.store.GetProjectByID(r.Context(), projectID)
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", models.WebhookEventProcessing, models.WebhookEventProcessed, models.WebhookEventFiltered, models.WebhookEventFailed:
+	default:
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+	limit := 20
+	offset := 0
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+	if o, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && o >= 0 {
+		offset = o
+	}
+
+	list := eventStore.ListWebhookEvents
+	if r.URL.Query().Get("include_archived") == "true" {
+		archiveStore, ok := h.store.(archivedWebhookEventStore)
+		if !ok {
+			h.respondWithError(w, http.StatusNotImplemented, errors.New("webhook event archive not available"))
+			return
+		}
+		list = archiveStore.ListWebhookEventsWithArchive
+	}
+	events, err := list(r.Context(), project.ProjectID, status, limit, offset)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response := ListProjectEventsResponse{Events: make([]ProjectEventResponse, 0, len(events)), Limit: limit, Offset: offset}
+	for _, event := range events {
+		response.Events = append(response.Events, ProjectEventResponse{
+			WebhookEvent: event,
+			Jobs:         h.eventJobs(r.Context(), event.JobID),
+		})
+	}
+	h.respondWithJSON(w, http.StatusOK, response)
+}
+
+// eventJobs returns the eval job and the jobs it triggered.
+func (h *ProjectHandler) eventJobs(ctx context.Context, evalJobID *string) []ProjectEventJob {
+	jobs := []ProjectEventJob{}
+	if evalJobID == nil {
+		return jobs
+	}
+	evalJob, err := h.store.GetJobByID(ctx, *evalJobID)
+	if err != nil {
+		return jobs
+	}
+	jobs = append(jobs, ProjectEventJob{JobID: evalJob.JobID, Name: evalJob.Name, Status: evalJob.Status})
+	filters := map[string]interface{}{"parent_job_id": evalJob.JobID}
+	if evalJob.Archived {
+		filters[store.IncludeArchivedFilter] = true
+	}
+	children, err := h.store.ListJobs(ctx, filters, maxEventChildJobs, 0)
+	if err != nil {
+		return jobs
+	}
+	for _, child := range children {
+		jobs = append(jobs, ProjectEventJob{JobID: child.JobID, Name: child.Name, Status: child.Status})
+	}
+	return jobs
+}
@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ledgerWebhookStore keeps the webhook event ledger in memory on top of
+// WebhookMockStore.
+type ledgerWebhookStore struct {
+	WebhookMockStore
+	events []*models.WebhookEvent
+}
+
+func (m *ledgerWebhookStore) ClaimWebhookEvent(ctx context.Context, event *models.WebhookEvent) (bool, *models.WebhookEvent, error) {
+	for _, existing := range m.events {
+		if event.DeliveryID != nil && existing.DeliveryID != nil && *existing.DeliveryID == *event.DeliveryID {
+			if existing.Status != models.WebhookEventFailed {
+				return false, existing, nil
+			}
+			event.EventID = existing.EventID
+			existing.Status = models.WebhookEventProcessing
+			existing.NextAttemptAt = event.NextAttemptAt
+			event.ProjectOutcomes = existing.ProjectOutcomes
+			return true, nil, nil
+		}
+	}
+	event.EventID = uuid.New().String()
+	event.Status = models.WebhookEventProcessing
+	stored := *event
+	m.events = append(m.events, &stored)
+	return true, nil, nil
+}
+
+func (m *ledgerWebhookStore) UpdateWebhookEvent(ctx context.Context, event *models.WebhookEvent) error {
+	for _, existing := range m.events {
+		if existing.EventID == event.EventID {
+			if existing.Status != models.WebhookEventProcessing || !sameLease(existing.NextAttemptAt, event.NextAttemptAt) {
+				return store.ErrNotFound
+			}
+			*existing = *event
+		}
+	}
+	return nil
+}
+
+// sameLease reports whether two webhook event leases (NextAttemptAt) are
+// the same, as the store compares them.
+func sameLease(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func (m *ledgerWebhookStore) ListWebhookEvents(ctx context.Context, projectID, status string, limit, offset int) ([]models.WebhookEvent, error) {
+	return nil, nil
+}
+
+func postPushDelivery(t *testing.T, s *ledgerWebhookStore, deliveryID, ref string) *httptest.ResponseRecorder {
+	t.Helper()
+	handler := NewWebhookHandler(s, corndogs.NewMockClient())
+	handler.SetTokenResolver(testTokenResolver())
+	handler.AddVCSClient(vcs.GitHub, &MockVCSClient{
+		ParseWebhookFunc: func(r *http.Request) (*vcs.WebhookEvent, error) {
+			return &vcs.WebhookEvent{
+				Provider:     vcs.GitHub,
+				EventType:    "push",
+				GenericEvent: vcs.EventPush,
+				DeliveryID:   deliveryID,
+				Repository: vcs.RepositoryInfo{
+					FullName: "test-org/test-repo",
+					CloneURL: "https://github.com/test-org/test-repo.git",
+				},
+				Push: &vcs.PushInfo{Ref: ref, Before: "before-sha", After: "after-sha-1234"},
+			}, nil
+		},
+	})
+
+	body := makePushWebhookBody("test-org/test-repo", "https://github.com/test-org/test-repo.git", "after-sha-1234", ref)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/github", bytes.NewReader(body))
+	req.Header.Set("X-GitHub-Event", "push")
+	w := httptest.NewRecorder()
+	handler.HandleGitHubWebhook(w, req)
+	return w
+}
+
+func TestWebhookHandler_EventLedger(t *testing.T) {
+	project := webhookTestProject()
+	newStore := func() *ledgerWebhookStore {
+		return &ledgerWebhookStore{WebhookMockStore: WebhookMockStore{
+			GetProjectByRepoURLFunc: func(ctx context.Context, repoURL string) (*models.Project, error) {
+				return project, nil
+			},
+		}}
+	}
+
+	t.Run("redelivery does not create a second job", func(t *testing.T) {
+		s := newStore()
+		w := postPushDelivery(t, s, "delivery-1", "refs/heads/main")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Len(t, s.CreateJobCalls, 1)
+
+		w = postPushDelivery(t, s, "delivery-1", "refs/heads/main")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "duplicate")
+		assert.Len(t, s.CreateJobCalls, 1)
+
+		require.Len(t, s.events, 1)
+		event := s.events[0]
+		assert.Equal(t, models.WebhookEventProcessed, event.Status)
+		require.NotNil(t, event.JobID)
+		assert.Equal(t, s.CreateJobCalls[0].JobID, *event.JobID)
+		assert.Equal(t, project.ProjectID, *event.ProjectID)
+		assert.Equal(t, "main", event.Ref)
+		assert.NotNil(t, event.ProcessedAt)
+	})
+
+	t.Run("filtered branch is recorded with its reason", func(t *testing.T) {
+		s := newStore()
+		w := postPushDelivery(t, s, "delivery-2", "refs/heads/feature")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, s.CreateJobCalls)
+
+		require.Len(t, s.events, 1)
+		assert.Equal(t, models.WebhookEventFiltered, s.events[0].Status)
+		assert.Equal(t, models.EventFilterBranch, s.events[0].FilterReason)
+		assert.Nil(t, s.events[0].JobID)
+	})
+
+	t.Run("failed delivery is processed again", func(t *testing.T) {
+		s := newStore()
+		s.CreateJobFunc = func(ctx context.Context, job *models.Job) error {
+			return assert.AnError
+		}
+		postPushDelivery(t, s, "delivery-3", "refs/heads/main")
+		require.Len(t, s.events, 1)
+		assert.Equal(t, models.WebhookEventFailed, s.events[0].Status)
+		assert.NotEmpty(t, s.events[0].Error)
+
+		s.CreateJobFunc = nil
+		postPushDelivery(t, s, "delivery-3", "refs/heads/main")
+		assert.Len(t, s.CreateJobCalls, 2)
+		require.Len(t, s.events, 1)
+		assert.Equal(t, models.WebhookEventProcessed, s.events[0].Status)
+	})
+}
+
+// eventsMockStore serves ledger entries and their jobs on top of
+// ProjectMockStore.
+type eventsMockStore struct {
+	ProjectMockStore
+	events []models.WebhookEvent
+	jobs   []models.Job
+}
+
+func (m *eventsMockStore) ClaimWebhookEvent(ctx context.Context, event *models.WebhookEvent) (bool, *models.WebhookEvent, error) {
+	return true, nil, nil
+}
+
+func (m *eventsMockStore) UpdateWebhookEvent(ctx context.Context, event *models.WebhookEvent) error {
+	return nil
+}
+
+func (m *eventsMockStore) ListWebhookEvents(ctx context.Context, projectID, status string, limit, offset int) ([]models.WebhookEvent, error) {
+	var events []models.WebhookEvent
+	for _, e := range m.events {
+		if status == "" || e.Status == status {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func (m *eventsMockStore) GetJobByID(ctx context.Context, jobID string) (*models.Job, error) {
+	for i := range m.jobs {
+		if m.jobs[i].JobID == jobID {
+			return &m.jobs[i], nil
+		}
+	}
+	return nil, assert.AnError
+}
+
+func (m *eventsMockStore) ListJobs(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]models.Job, error) {
+	var jobs []models.Job
+	for _, j := range m.jobs {
+		if j.ParentJobID != nil && *j.ParentJobID == filters["parent_job_id"] {
+			jobs = append(jobs, j)
+		}
+	}
+	return jobs, nil
+}
+
+func TestProjectHandler_ListProjectEvents(t *testing.T) {
+	project := webhookTestProject()
+	evalJobID := "eval-job"
+	newStore := func() *eventsMockStore {
+		s := &eventsMockStore{
+			events: []models.WebhookEvent{
+				{EventID: "event-1", ProjectID: &project.ProjectID, EventType: "push", Status: models.WebhookEventProcessed, JobID: &evalJobID},
+				{EventID: "event-2", ProjectID: &project.ProjectID, EventType: "push", Status: models.WebhookEventFiltered, FilterReason: models.EventFilterBranch},
+			},
+			jobs: []models.Job{
+				{JobID: evalJobID, Name: "eval: push to main", Status: "completed"},
+				{JobID: "build-job", Name: "build", Status: "running", ParentJobID: &evalJobID},
+			},
+		}
+		s.GetProjectByIDFunc = func(ctx context.Context, projectID string) (*models.Project, error) {
+			return project, nil
+		}
+		return s
+	}
+	list := func(s store.Store, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/projects/"+project.ProjectID+"/events"+query, nil)
+		req = withProjectID(withUser(req), project.ProjectID)
+		w := httptest.NewRecorder()
+		NewProjectHandler(s).ListProjectEvents(w, req)
+		return w
+	}
+
+	t.Run("lists events with their jobs", func(t *testing.T) {
+		w := list(newStore(), "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp ListProjectEventsResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.Len(t, resp.Events, 2)
+
+		assert.Equal(t, "event-1", resp.Events[0].EventID)
+		require.Len(t, resp.Events[0].Jobs, 2)
+		assert.Equal(t, evalJobID, resp.Events[0].Jobs[0].JobID)
+		assert.Equal(t, "build-job", resp.Events[0].Jobs[1].JobID)
+
+		assert.Equal(t, models.EventFilterBranch, resp.Events[1].FilterReason)
+		assert.Empty(t, resp.Events[1].Jobs)
+	})
+
+	t.Run("status filter", func(t *testing.T) {
+		w := list(newStore(), "?status=filtered")
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp ListProjectEventsResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.Len(t, resp.Events, 1)
+		assert.Equal(t, "event-2", resp.Events[0].EventID)
+	})
+
+	t.Run("invalid status", func(t *testing.T) {
+		w := list(newStore(), "?status=bogus")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("store without event ledger", func(t *testing.T) {
+		w := list(&ProjectMockStore{}, "")
+		assert.NotEqual(t, http.StatusOK, w.Code)
+	})
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/sirupsen/logrus"
+)
+
+// processForProjects runs process for each of a webhook event's projects,
+// each with its own copy of the ledger record, records each project's
+// outcome in record.ProjectOutcomes and folds them into record: processed
+// with the first project's eval job if any project built one, otherwise
+// filtered with the last project's reason. Projects with an outcome from
+// an earlier attempt other than failed aren't processed again, so a retry
+// or redelivery doesn't duplicate the jobs that were built. A project that
+// fails doesn't stop the rest, but any failure is returned, so the event
+// is retried for the failed projects.
+func (h *WebhookHandler) processForProjects(projects []*models.Project, record *models.WebhookEvent, process func(*models.Project, *models.WebhookEvent) error) error {
+	if len(projects) == 0 {
+		h.logger.WithField("repository", record.Repo).Debug("No project found for repository - skipping event")
+		record.Filter(models.EventFilterNoProject)
+		return nil
+	}
+	if record.ProjectOutcomes == nil {
+		record.ProjectOutcomes = models.WebhookProjectOutcomes{}
+	}
+
+	var firstErr error
+	failed := 0
+	for _, project := range projects {
+		if outcome, ok := record.ProjectOutcomes[project.ProjectID]; ok && outcome.Status != models.WebhookEventFailed {
+			foldProjectOutcome(record, project, outcome)
+			continue
+		}
+		projectRecord := *record
+		if err := process(project, &projectRecord); err != nil {
+			h.logger.WithError(err).WithFields(logrus.Fields{
+				"project":    project.Name,
+				"project_id": project.ProjectID,
+			}).Error("Failed to process event for project")
+			record.ProjectOutcomes[project.ProjectID] = models.WebhookProjectOutcome{Status: models.WebhookEventFailed, Error: err.Error()}
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed++
+			continue
+		}
+		outcome := models.WebhookProjectOutcome{
+			Status:       projectRecord.Status,
+			FilterReason: projectRecord.FilterReason,
+			JobID:        projectRecord.JobID,
+		}
+		if outcome.Status == models.WebhookEventProcessing {
+			outcome.Status = models.WebhookEventProcessed
+		}
+		record.ProjectOutcomes[project.ProjectID] = outcome
+		foldProjectOutcome(record, project, outcome)
+	}
+	if firstErr != nil && len(projects) > 1 {
+		return fmt.Errorf("%d of %d projects failed: %w", failed, len(projects), firstErr)
+	}
+	return firstErr
+}
+
+// foldProjectOutcome folds one project's outcome into the event's record,
+// unless an earlier project's job already made the record processed.
+func foldProjectOutcome(record *models.WebhookEvent, project *models.Project, outcome models.WebhookProjectOutcome) {
+	if record.Status == models.WebhookEventProcessed {
+		return
+	}
+	record.ProjectID = &project.ProjectID
+	switch {
+	case outcome.Status == models.WebhookEventFiltered:
+		record.Filter(outcome.FilterReason)
+	case outcome.JobID != nil:
+		record.Status, record.FilterReason, record.JobID = models.WebhookEventProcessed, "", outcome.JobID
+	default:
+		record.Status, record.FilterReason = models.WebhookEventProcessing, ""
+	}
+}
+
+// evalStatusContext is the commit status context of a project's eval jobs,
+// named by the project's status context template when it has one.
+// Otherwise projects sharing a repository each report their own, named by
+// their config path.
+func evalStatusContext(project *models.Project) string {
+	if project == nil || project.ConfigPath == "" {
+		return project.StatusContext(models.EvalStatusKey, "reactorcide/eval")
+	}
+	return project.StatusContext(models.EvalStatusKey, "reactorcide/eval/"+project.ConfigPath)
+}
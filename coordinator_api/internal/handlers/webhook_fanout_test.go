@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// monorepoProjects returns three projects sharing test-org/test-repo: two
+// scoped to their service's subtree and one for the whole repository.
+func monorepoProjects() []models.Project {
+	api := webhookTestProject()
+	api.Name = "api"
+	api.ConfigPath = "services/api"
+	api.PathFilters = []string{"services/api/**"}
+	web := webhookTestProject()
+	web.Name = "web"
+	web.ConfigPath = "services/web"
+	web.PathFilters = []string{"services/web/**"}
+	root := webhookTestProject()
+	root.Name = "root"
+	return []models.Project{*root, *api, *web}
+}
+
+func monorepoPushEvent(files ...string) *vcs.WebhookEvent {
+	return &vcs.WebhookEvent{
+		Provider:     vcs.GitHub,
+		EventType:    "push",
+		GenericEvent: vcs.EventPush,
+		Repository: vcs.RepositoryInfo{
+			FullName: "test-org/test-repo",
+			CloneURL: "https://github.com/test-org/test-repo.git",
+		},
+		Push: &vcs.PushInfo{
+			Ref:     "refs/heads/main",
+			After:   "def456",
+			Commits: []vcs.Commit{{ID: "def456", Modified: files}},
+		},
+	}
+}
+
+func sendMonorepoWebhook(t *testing.T, mockStore store.Store, mockVCS vcs.Client) *httptest.ResponseRecorder {
+	t.Helper()
+	handler := NewWebhookHandler(mockStore, nil)
+	handler.SetTokenResolver(func(ctx context.Context, secretRef string) (string, error) {
+		switch secretRef {
+		case "test/project:webhook_secret":
+			return "test-secret", nil
+		case "other/project:webhook_secret":
+			return "other-secret", nil
+		}
+		return "", errors.New("unknown secret")
+	})
+	handler.AddVCSClient(vcs.GitHub, mockVCS)
+
+	body := makePushWebhookBody("test-org/test-repo", "https://github.com/test-org/test-repo.git", "def456", "refs/heads/main")
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/github", bytes.NewReader(body))
+	req.Header.Set("X-GitHub-Event", "push")
+	w := httptest.NewRecorder()
+	handler.HandleGitHubWebhook(w, req)
+	return w
+}
+
+func TestWebhookHandler_MonorepoFanOut(t *testing.T) {
+	mockStore := &WebhookMockStore{
+		GetProjectsByRepoURLFunc: func(ctx context.Context, repoURL string) ([]models.Project, error) {
+			return monorepoProjects(), nil
+		},
+	}
+	var contexts []string
+	mockVCS := &MockVCSClient{
+		ParseWebhookFunc: func(r *http.Request) (*vcs.WebhookEvent, error) {
+			return monorepoPushEvent("services/api/main.go", "README.md"), nil
+		},
+		UpdateCommitStatusFunc: func(ctx context.Context, repo string, update vcs.StatusUpdate) error {
+			contexts = append(contexts, update.Context)
+			return nil
+		},
+	}
+
+	w := sendMonorepoWebhook(t, mockStore, mockVCS)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// The web project's paths weren't touched.
+	require.Len(t, mockStore.CreateJobCalls, 2)
+	rootJob, apiJob := mockStore.CreateJobCalls[0], mockStore.CreateJobCalls[1]
+	assert.NotContains(t, rootJob.JobEnvVars, "REACTORCIDE_CONFIG_PATH")
+	assert.Equal(t, "services/api", apiJob.JobEnvVars["REACTORCIDE_CONFIG_PATH"])
+	assert.Equal(t, "services/api/**", apiJob.JobEnvVars["REACTORCIDE_PATH_FILTERS"])
+	assert.Contains(t, apiJob.Name, "[services/api]")
+	assert.Equal(t, []string{"reactorcide/eval", "reactorcide/eval/services/api"}, contexts)
+}
+
+func TestWebhookHandler_MonorepoNoMatchingPaths(t *testing.T) {
+	mockStore := &WebhookMockStore{
+		GetProjectsByRepoURLFunc: func(ctx context.Context, repoURL string) ([]models.Project, error) {
+			projects := monorepoProjects()
+			return projects[1:], nil
+		},
+	}
+	mockVCS := &MockVCSClient{
+		ParseWebhookFunc: func(r *http.Request) (*vcs.WebhookEvent, error) {
+			return monorepoPushEvent("docs/index.md"), nil
+		},
+	}
+
+	w := sendMonorepoWebhook(t, mockStore, mockVCS)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, mockStore.CreateJobCalls)
+}
+
+func TestWebhookHandler_MonorepoPerProjectSecrets(t *testing.T) {
+	mockStore := &WebhookMockStore{
+		GetProjectsByRepoURLFunc: func(ctx context.Context, repoURL string) ([]models.Project, error) {
+			projects := monorepoProjects()
+			projects[1].WebhookSecret = "other/project:webhook_secret"
+			return projects[:2], nil
+		},
+	}
+	mockVCS := &MockVCSClient{
+		ParseWebhookFunc: func(r *http.Request) (*vcs.WebhookEvent, error) {
+			return monorepoPushEvent("services/api/main.go"), nil
+		},
+		ValidateWebhookFunc: func(r *http.Request, secret string) error {
+			if secret != "test-secret" {
+				return errors.New("bad signature")
+			}
+			return nil
+		},
+	}
+
+	w := sendMonorepoWebhook(t, mockStore, mockVCS)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// Only the project whose secret signed the webhook builds.
+	require.Len(t, mockStore.CreateJobCalls, 1)
+	assert.NotContains(t, mockStore.CreateJobCalls[0].JobEnvVars, "REACTORCIDE_CONFIG_PATH")
+}
+
+func TestWebhookHandler_MonorepoPartialFailure(t *testing.T) {
+	projects := monorepoProjects()
+	root, api := projects[0], projects[1]
+	failAPI := true
+	s := &ledgerWebhookStore{WebhookMockStore: WebhookMockStore{
+		GetProjectsByRepoURLFunc: func(ctx context.Context, repoURL string) ([]models.Project, error) {
+			return projects, nil
+		},
+		CreateJobFunc: func(ctx context.Context, job *models.Job) error {
+			if failAPI && job.JobEnvVars["REACTORCIDE_CONFIG_PATH"] == "services/api" {
+				return assert.AnError
+			}
+			job.JobID = uuid.New().String()
+			return nil
+		},
+	}}
+	mockVCS := &MockVCSClient{
+		ParseWebhookFunc: func(r *http.Request) (*vcs.WebhookEvent, error) {
+			event := monorepoPushEvent("services/api/main.go")
+			event.DeliveryID = "delivery-mixed"
+			return event, nil
+		},
+	}
+
+	// The root project builds and the api project fails: the event fails,
+	// so the provider redelivers it, with both outcomes recorded.
+	w := sendMonorepoWebhook(t, s, mockVCS)
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.Len(t, s.events, 1)
+	event := s.events[0]
+	assert.Equal(t, models.WebhookEventFailed, event.Status)
+	assert.Equal(t, models.WebhookEventProcessed, event.ProjectOutcomes[root.ProjectID].Status)
+	require.NotNil(t, event.ProjectOutcomes[root.ProjectID].JobID)
+	rootJobID := *event.ProjectOutcomes[root.ProjectID].JobID
+	assert.Equal(t, models.WebhookEventFailed, event.ProjectOutcomes[api.ProjectID].Status)
+	assert.NotEmpty(t, event.ProjectOutcomes[api.ProjectID].Error)
+	require.Len(t, s.CreateJobCalls, 2)
+
+	// The redelivery only processes the api project.
+	failAPI = false
+	w = sendMonorepoWebhook(t, s, mockVCS)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, s.CreateJobCalls, 3, "the root project's job isn't built again")
+	assert.Equal(t, "services/api", s.CreateJobCalls[2].JobEnvVars["REACTORCIDE_CONFIG_PATH"])
+	event = s.events[0]
+	assert.Equal(t, models.WebhookEventProcessed, event.Status)
+	assert.Equal(t, rootJobID, *event.JobID)
+	assert.Equal(t, models.WebhookEventProcessed, event.ProjectOutcomes[api.ProjectID].Status)
+}
+
+// filesVCSClient is a MockVCSClient that lists pull request files.
+type filesVCSClient struct {
+	MockVCSClient
+	files    []string
+	complete bool
+}
+
+func (c *filesVCSClient) ListPRFiles(ctx context.Context, repo string, prNumber int) ([]string, bool, error) {
+	return c.files, c.complete, nil
+}
+
+func TestWebhookHandler_MonorepoPullRequestPathFilters(t *testing.T) {
+	prEvent := &vcs.WebhookEvent{
+		Provider:     vcs.GitHub,
+		EventType:    "pull_request",
+		GenericEvent: vcs.EventPullRequestOpened,
+		Repository: vcs.RepositoryInfo{
+			FullName: "test-org/test-repo",
+			CloneURL: "https://github.com/test-org/test-repo.git",
+		},
+		PullRequest: &vcs.PullRequestInfo{
+			Number:  7,
+			Action:  "opened",
+			HeadSHA: "abc123",
+			HeadRef: "feature",
+			BaseRef: "main",
+		},
+	}
+
+	tests := []struct {
+		name     string
+		complete bool
+		wantJobs int
+	}{
+		{"complete list filters", true, 2},
+		{"truncated list leaves filtering to eval", false, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := &WebhookMockStore{
+				GetProjectsByRepoURLFunc: func(ctx context.Context, repoURL string) ([]models.Project, error) {
+					return monorepoProjects(), nil
+				},
+			}
+			client := &filesVCSClient{files: []string{"services/web/app.ts"}, complete: tt.complete}
+			client.ParseWebhookFunc = func(r *http.Request) (*vcs.WebhookEvent, error) {
+				return prEvent, nil
+			}
+
+			w := sendMonorepoWebhook(t, mockStore, client)
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Len(t, mockStore.CreateJobCalls, tt.wantJobs)
+		})
+	}
+}
@@ -4,18 +4,24 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
 
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/chaos"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/hooks"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/jobcontrol"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/metrics"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/repourl"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/worker"
 	"github.com/sirupsen/logrus"
 )
 
@@ -27,6 +33,8 @@ type WebhookHandler struct {
 	tokenResolver  vcs.TokenResolverFunc         // optional: per-project secret resolution
 	clientFactory  vcs.ClientFactoryFunc         // optional: create client with per-project token
 	statusUpdater  vcs.JobStatusUpdaterInterface // optional: used to refresh comments for in-flight jobs on merge
+	queue          *webhookQueue                 // optional: processes events after acknowledging them (see StartAsync)
+	shedder        *webhookLoadShedder           // optional: turns deliveries away while overloaded (see StartLoadShedding)
 	logger         *logrus.Logger
 }
 
@@ -65,6 +73,14 @@ func (h *WebhookHandler) SetStatusUpdater(u vcs.JobStatusUpdaterInterface) {
 	h.statusUpdater = u
 }
 
+// HandleWebhook returns the handler for provider's webhook events, served
+// at its vcs.ProviderSpec's WebhookPath.
+func (h *WebhookHandler) HandleWebhook(provider vcs.Provider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h.handleWebhook(w, r, provider)
+	}
+}
+
 // HandleGitHubWebhook handles GitHub webhook events
 func (h *WebhookHandler) HandleGitHubWebhook(w http.ResponseWriter, r *http.Request) {
 	h.handleWebhook(w, r, vcs.GitHub)
@@ -215,6 +231,55 @@ func (h *WebhookHandler) resolveWebhookSecretCandidates(ctx context.Context, pro
 	return nil
 }
 
+// authenticateWebhook validates the webhook's signature against each
+// project's secret candidates (see resolveWebhookSecretCandidates) and
+// returns the projects it validated for. With no projects, the org and env
+// fallbacks are tried on their own. valid reports whether any candidate
+// matched, and configured whether there was any candidate at all.
+func (h *WebhookHandler) authenticateWebhook(r *http.Request, body []byte, client vcs.Client, provider vcs.Provider, projects []models.Project) (matched []*models.Project, valid, configured bool) {
+	ctx := context.Background()
+	// Projects often share a secret, so each one is checked once.
+	checked := map[string]bool{}
+	validates := func(candidates []webhookSecretCandidate) bool {
+		for _, candidate := range candidates {
+			ok, seen := checked[candidate.Secret]
+			if !seen {
+				// Each attempt needs its own fresh body reader: some
+				// ValidateWebhook implementations (e.g. GitHub's) consume
+				// the request body.
+				validateReq, _ := http.NewRequest(r.Method, r.URL.String(), bytes.NewReader(body))
+				validateReq.Header = r.Header
+				ok = client.ValidateWebhook(validateReq, candidate.Secret) == nil && !chaos.WebhookSignatureFails()
+				checked[candidate.Secret] = ok
+			}
+			if !ok {
+				continue
+			}
+			if candidate.RotationID != "" {
+				h.touchWebhookSecretLastUsed(ctx, candidate.RotationID)
+			}
+			return true
+		}
+		return false
+	}
+
+	if len(projects) == 0 {
+		candidates := h.resolveWebhookSecretCandidates(ctx, nil, provider)
+		return nil, validates(candidates), len(candidates) > 0
+	}
+	for i := range projects {
+		candidates := h.resolveWebhookSecretCandidates(ctx, &projects[i], provider)
+		if len(candidates) == 0 {
+			continue
+		}
+		configured = true
+		if validates(candidates) {
+			matched = append(matched, &projects[i])
+		}
+	}
+	return matched, len(matched) > 0, configured
+}
+
 // touchWebhookSecretLastUsed stamps last_used_at for the rotation row that
 // successfully validated a webhook signature. Best-effort: a stamp failure
 // must never fail the webhook request itself.
@@ -240,6 +305,12 @@ func (h *WebhookHandler) handleWebhook(w http.ResponseWriter, r *http.Request, p
 
 	// Read the request body first for validation
 	body, err := io.ReadAll(r.Body)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		h.logger.WithField("provider", provider).WithField("limit", tooLarge.Limit).Warn("Rejected oversized webhook body")
+		respondPayloadTooLarge(w, tooLarge.Limit)
+		return
+	}
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to read webhook body")
 		http.Error(w, "Failed to read request body", http.StatusBadRequest)
@@ -258,50 +329,37 @@ func (h *WebhookHandler) handleWebhook(w http.ResponseWriter, r *http.Request, p
 		return
 	}
 
-	// Extract the repo clone URL from the raw payload and look up the project.
-	// This enables per-project webhook secrets: we identify which project the
-	// webhook is for, resolve its secret, then validate the HMAC signature.
-	var project *models.Project
-	repoCloneURL, extractErr := extractRepoCloneURL(body, r.Header.Get("Content-Type"))
+	// Extract the repo clone URL from the raw payload and look up its
+	// projects. This enables per-project webhook secrets: we identify which
+	// projects the webhook is for, resolve their secrets, then validate the
+	// HMAC signature. A monorepo has several projects; the event goes to
+	// each one whose secrets validate it.
+	var projects []models.Project
+	var repoCloneURL string
+	var extractErr error
+	if spec, ok := vcs.Providers.Lookup(provider); ok && spec.RepoURL != nil {
+		repoCloneURL, extractErr = spec.RepoURL(body)
+	} else {
+		repoCloneURL, extractErr = extractRepoCloneURL(body, r.Header.Get("Content-Type"))
+	}
 	if extractErr != nil {
 		h.logger.WithError(extractErr).Warn("Could not extract repo clone URL from webhook payload")
 	} else {
-		normalizedURL := vcs.NormalizeRepoURL(repoCloneURL)
-		if p, err := h.store.GetProjectByRepoURL(context.Background(), normalizedURL); err == nil {
-			project = p
+		normalizedURL := repourl.Normalize(repoCloneURL)
+		if ps, err := h.store.GetProjectsByRepoURL(context.Background(), normalizedURL); err == nil {
+			projects = ps
 		} else {
 			h.logger.WithError(err).WithField("normalized_url", normalizedURL).Warn("Failed to look up project by repo URL")
 		}
 	}
 
-	// Resolve webhook secret candidates: active rotation rows first (newest
-	// first), then legacy project/org/env fallbacks. Try each until one
-	// validates the signature; constant-time comparison happens inside
-	// client.ValidateWebhook, same as before rotation support existed.
-	candidates := h.resolveWebhookSecretCandidates(context.Background(), project, provider)
-	if len(candidates) == 0 {
-		h.logger.WithField("project_found", project != nil).Error("Webhook secret not configured — rejecting request")
+	matchedProjects, valid, configured := h.authenticateWebhook(r, body, client, provider, projects)
+	if !configured {
+		h.logger.WithField("project_found", len(projects) > 0).Error("Webhook secret not configured — rejecting request")
 		http.Error(w, "Webhook secret not configured", http.StatusInternalServerError)
 		return
 	}
-
-	matched := false
-	for _, candidate := range candidates {
-		// Each attempt needs its own fresh body reader: some ValidateWebhook
-		// implementations (e.g. GitHub's) consume the request body.
-		validateReq, _ := http.NewRequest(r.Method, r.URL.String(), bytes.NewReader(body))
-		validateReq.Header = r.Header
-
-		if err := client.ValidateWebhook(validateReq, candidate.Secret); err != nil {
-			continue
-		}
-		matched = true
-		if candidate.RotationID != "" {
-			h.touchWebhookSecretLastUsed(context.Background(), candidate.RotationID)
-		}
-		break
-	}
-	if !matched {
+	if !valid {
 		h.logger.Warn("Invalid webhook signature")
 		http.Error(w, "Invalid webhook signature", http.StatusUnauthorized)
 		return
@@ -322,45 +380,90 @@ func (h *WebhookHandler) handleWebhook(w http.ResponseWriter, r *http.Request, p
 		"repository": event.Repository.FullName,
 	}).Info("Received webhook event")
 
+	// Record the delivery in the event ledger. A redelivery of an event
+	// that was already handled is acknowledged without creating jobs.
+	var project *models.Project
+	if len(matchedProjects) > 0 {
+		project = matchedProjects[0]
+	}
+	record := newWebhookEventRecord(event, project)
+	if h.queue != nil {
+		h.queue.prepare(record, r, body, matchedProjects)
+	}
+	if !h.claimWebhookEvent(record) {
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"status": "duplicate"})
+		return
+	}
+
+	// With the delivery stored, acknowledge it now and process it in the
+	// background, so slow processing can't outlast the provider's delivery
+	// timeout. If the ledger write failed, fall back to processing inline.
+	if h.queue != nil && record.EventID != "" {
+		h.queue.enqueue(record)
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]string{"status": "accepted", "event_id": record.EventID})
+		return
+	}
+
+	if err := h.processEvent(event, client, matchedProjects, record); err != nil {
+		h.failWebhookEvent(record, err)
+		http.Error(w, "Failed to process event", http.StatusInternalServerError)
+		return
+	}
+	h.finishWebhookEvent(record)
+
+	// Send success response
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// processEvent processes a parsed event, once for each of its projects,
+// recording the outcome on record.
+func (h *WebhookHandler) processEvent(event *vcs.WebhookEvent, client vcs.Client, matchedProjects []*models.Project, record *models.WebhookEvent) error {
 	// Skip events that don't map to a known generic event type
 	if event.GenericEvent == vcs.EventUnknown {
 		h.logger.WithFields(logrus.Fields{
 			"event_type": event.EventType,
-			"provider":   provider,
+			"provider":   event.Provider,
 		}).Debug("Ignoring unsupported event type")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
-		return
+		record.Filter(models.EventFilterUnsupportedEvent)
+		return nil
 	}
 
-	// Process the event based on type, passing the already-fetched project
-	// to avoid a duplicate database lookup.
 	switch {
 	case event.PullRequest != nil:
-		if err := h.processPullRequestEvent(event, client, project); err != nil {
+		h.handlePullRequestLifecycle(event)
+		if err := h.processForProjects(matchedProjects, record, func(project *models.Project, record *models.WebhookEvent) error {
+			return h.processPullRequestEvent(event, client, project, record)
+		}); err != nil {
 			h.logger.WithError(err).Error("Failed to process pull request event")
-			http.Error(w, "Failed to process event", http.StatusInternalServerError)
-			return
+			return err
 		}
 	case event.Push != nil:
-		if err := h.processPushEvent(event, client, project); err != nil {
+		if err := h.processForProjects(matchedProjects, record, func(project *models.Project, record *models.WebhookEvent) error {
+			return h.processPushEvent(event, client, project, record)
+		}); err != nil {
 			h.logger.WithError(err).Error("Failed to process push event")
-			http.Error(w, "Failed to process event", http.StatusInternalServerError)
-			return
+			return err
+		}
+	case event.MergeGroup != nil:
+		if err := h.processForProjects(matchedProjects, record, func(project *models.Project, record *models.WebhookEvent) error {
+			return h.processMergeGroupEvent(event, client, project, record)
+		}); err != nil {
+			h.logger.WithError(err).Error("Failed to process merge group event")
+			return err
 		}
 	default:
 		h.logger.WithField("event_type", event.EventType).Debug("Ignoring event with no PR or push info")
+		record.Filter(models.EventFilterUnsupportedEvent)
 	}
-
-	// Send success response
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	return nil
 }
 
-// processPullRequestEvent processes a pull request event.
-// The project parameter may be non-nil if it was already looked up during
-// webhook secret resolution. If nil, the project is fetched by repo URL.
-func (h *WebhookHandler) processPullRequestEvent(event *vcs.WebhookEvent, client vcs.Client, project *models.Project) error {
+// handlePullRequestLifecycle runs the once-per-event side effects of a
+// pull request event, whichever projects it builds for.
+func (h *WebhookHandler) handlePullRequestLifecycle(event *vcs.WebhookEvent) {
 	// On merge, record the merge state and refresh any in-flight jobs so
 	// their next status change uses the per-job comment flow. This runs
 	// alongside (not instead of) normal event processing — projects that
@@ -368,67 +471,108 @@ func (h *WebhookHandler) processPullRequestEvent(event *vcs.WebhookEvent, client
 	if event.GenericEvent == vcs.EventPullRequestMerged {
 		h.handlePRMerged(event)
 	}
+	// Closing or merging a PR tears down its preview environments, whether
+	// or not the project builds on these events.
+	if event.GenericEvent == vcs.EventPullRequestClosed || event.GenericEvent == vcs.EventPullRequestMerged {
+		h.teardownPreviewEnvironments(event)
+	}
+}
 
+// processPullRequestEvent processes a pull request event for one of its
+// projects.
+func (h *WebhookHandler) processPullRequestEvent(event *vcs.WebhookEvent, client vcs.Client, project *models.Project, record *models.WebhookEvent) error {
 	pr := event.PullRequest
+	record.ProjectID = &project.ProjectID
 
-	// Use the pre-fetched project or look it up now
-	if project == nil {
-		normalizedRepoURL := vcs.NormalizeRepoURL(event.Repository.CloneURL)
-		var err error
-		project, err = h.store.GetProjectByRepoURL(context.Background(), normalizedRepoURL)
-		if err != nil {
-			h.logger.WithFields(logrus.Fields{
-				"repo_url":   event.Repository.CloneURL,
-				"normalized": normalizedRepoURL,
-				"error":      err.Error(),
-			}).Debug("No project found for repository - skipping event")
-			return nil // Not an error - just no project configured
-		}
+	// Hold back draft/WIP PRs, and release deferred ones once they're ready.
+	if reason := applyDraftPRPolicy(event, project); reason != "" {
+		h.logger.WithFields(logrus.Fields{
+			"project":       project.Name,
+			"generic_event": string(event.GenericEvent),
+			"pr_number":     pr.Number,
+			"reason":        reason,
+		}).Debug("Event filtered out by draft PR policy")
+		record.Filter(reason)
+		return nil
 	}
 
 	// Apply event filtering using the generic event type
-	if !project.ShouldProcessEvent(string(event.GenericEvent), pr.BaseRef) {
+	if reason := project.EventFilterReason(string(event.GenericEvent), pr.BaseRef); reason != "" {
 		h.logger.WithFields(logrus.Fields{
 			"project":       project.Name,
 			"generic_event": string(event.GenericEvent),
 			"base_branch":   pr.BaseRef,
+			"reason":        reason,
 		}).Debug("Event filtered out by project configuration")
+		record.Filter(reason)
 		return nil
 	}
 
+	// Skip projects none of the PR's files touch, when the provider can
+	// list them. Otherwise the eval job applies the path filters itself.
+	statusClient := h.getStatusClient(context.Background(), project, event.Provider, client)
+	if len(project.PathFilters) > 0 {
+		if lister, ok := statusClient.(vcs.PullRequestFilesLister); ok {
+			files, complete, err := lister.ListPRFiles(context.Background(), event.Repository.FullName, pr.Number)
+			if err != nil {
+				h.logger.WithError(err).WithFields(logrus.Fields{
+					"project":   project.Name,
+					"pr_number": pr.Number,
+				}).Warn("Failed to list pull request files, leaving path filters to the eval job")
+			} else if complete && !models.MatchPathFilters(project.PathFilters, files) {
+				h.logger.WithFields(logrus.Fields{
+					"project":   project.Name,
+					"pr_number": pr.Number,
+				}).Debug("Event filtered out by project path filters")
+				record.Filter(models.EventFilterPaths)
+				return nil
+			}
+		}
+	}
+
 	// Build eval job using the shared builder
 	job := BuildEvalJob(project, event)
 
 	// Store VCS metadata for status updates.
 	metadata := vcs.JobMetadata{
-		VCSProvider:   string(event.Provider),
-		Repo:          event.Repository.FullName,
-		PRNumber:      pr.Number,
-		CommitSHA:     pr.HeadSHA,
-		StatusContext: "reactorcide/eval",
-		IsEval:        true,
-	}
+		VCSProvider: string(event.Provider),
+		Repo:        event.Repository.FullName,
+		PRNumber:    pr.Number,
+		CommitSHA:   pr.HeadSHA,
+		IsEval:      true,
+		StatusKey:   models.EvalStatusKey,
+	}
+	metadata.UseStatusContext(project, evalStatusContext(project))
 	if err := metadata.ApplyToJob(job); err != nil {
 		return fmt.Errorf("applying VCS metadata: %w", err)
 	}
+	if len(project.JobTemplates) > 0 {
+		return h.createTemplateJobs(context.Background(), event, statusClient, project, job, record)
+	}
+
+	worker.RouteProjectQueue(context.Background(), h.store, project, job)
 
 	// Create the job in the database
+	if err := hooks.CheckJobCreate(context.Background(), job, hooks.SourceWebhook); err != nil {
+		return err
+	}
 	if err := h.store.CreateJob(context.Background(), job); err != nil {
 		return fmt.Errorf("creating job: %w", err)
 	}
+	record.Status = models.WebhookEventProcessed
+	record.JobID = &job.JobID
 
 	// Submit job to Corndogs task queue
 	h.submitJobToCorndogs(job)
 
 	// Register the job as a pending check on the commit so branch protection
 	// sees it immediately — don't wait for the worker to pick it up.
-	statusClient := h.getStatusClient(context.Background(), project, event.Provider, client)
 	statusUpdate := vcs.StatusUpdate{
 		SHA:         pr.HeadSHA,
 		State:       vcs.StatusPending,
 		TargetURL:   h.getJobURL(job.JobID),
 		Description: "CI build queued",
-		Context:     "reactorcide/eval",
+		Context:     evalStatusContext(project),
 	}
 
 	if err := statusClient.UpdateCommitStatus(context.Background(), event.Repository.FullName, statusUpdate); err != nil {
@@ -446,43 +590,44 @@ func (h *WebhookHandler) processPullRequestEvent(event *vcs.WebhookEvent, client
 	return nil
 }
 
-// processPushEvent processes a push event.
-// The project parameter may be non-nil if it was already looked up during
-// webhook secret resolution. If nil, the project is fetched by repo URL.
-func (h *WebhookHandler) processPushEvent(event *vcs.WebhookEvent, client vcs.Client, project *models.Project) error {
+// processPushEvent processes a push event for one of its projects.
+func (h *WebhookHandler) processPushEvent(event *vcs.WebhookEvent, client vcs.Client, project *models.Project, record *models.WebhookEvent) error {
 	push := event.Push
 
 	// Skip deleted branches
 	if push.Deleted {
 		h.logger.WithField("ref", push.Ref).Debug("Ignoring branch deletion")
+		record.Filter(models.EventFilterBranchDeleted)
 		return nil
 	}
 
 	// Extract branch name from ref
 	branch := strings.TrimPrefix(push.Ref, "refs/heads/")
 
-	// Use the pre-fetched project or look it up now
-	if project == nil {
-		normalizedRepoURL := vcs.NormalizeRepoURL(event.Repository.CloneURL)
-		var err error
-		project, err = h.store.GetProjectByRepoURL(context.Background(), normalizedRepoURL)
-		if err != nil {
-			h.logger.WithFields(logrus.Fields{
-				"repo_url":   event.Repository.CloneURL,
-				"normalized": normalizedRepoURL,
-				"error":      err.Error(),
-			}).Debug("No project found for repository - skipping event")
-			return nil // Not an error - just no project configured
-		}
-	}
+	record.ProjectID = &project.ProjectID
 
-	// Apply event filtering using the generic event type
-	if !project.ShouldProcessEvent(string(event.GenericEvent), branch) {
+	// Apply event filtering using the generic event type. Tags are
+	// filtered by tag name.
+	filterRef := strings.TrimPrefix(branch, "refs/tags/")
+	if reason := project.EventFilterReason(string(event.GenericEvent), filterRef); reason != "" {
 		h.logger.WithFields(logrus.Fields{
 			"project":       project.Name,
 			"generic_event": string(event.GenericEvent),
 			"branch":        branch,
+			"reason":        reason,
 		}).Debug("Event filtered out by project configuration")
+		record.Filter(reason)
+		return nil
+	}
+
+	// When the push lists its changed files, skip projects none of them
+	// touch. Otherwise the eval job applies the path filters itself.
+	if files, ok := push.ChangedFiles(); ok && !models.MatchPathFilters(project.PathFilters, files) {
+		h.logger.WithFields(logrus.Fields{
+			"project": project.Name,
+			"branch":  branch,
+		}).Debug("Event filtered out by project path filters")
+		record.Filter(models.EventFilterPaths)
 		return nil
 	}
 
@@ -491,21 +636,47 @@ func (h *WebhookHandler) processPushEvent(event *vcs.WebhookEvent, client vcs.Cl
 
 	// Store VCS metadata for status updates.
 	metadata := vcs.JobMetadata{
-		VCSProvider:   string(event.Provider),
-		Repo:          event.Repository.FullName,
-		Branch:        branch,
-		CommitSHA:     push.After,
-		StatusContext: "reactorcide/eval",
-		IsEval:        true,
-	}
+		VCSProvider: string(event.Provider),
+		Repo:        event.Repository.FullName,
+		Branch:      branch,
+		CommitSHA:   push.After,
+		IsEval:      true,
+		StatusKey:   models.EvalStatusKey,
+	}
+	metadata.UseStatusContext(project, evalStatusContext(project))
 	if err := metadata.ApplyToJob(job); err != nil {
 		return fmt.Errorf("applying VCS metadata: %w", err)
 	}
 
+	// A release waits for the tag's whole pipeline.
+	releasing := event.GenericEvent == vcs.EventTagCreated && project.ReleaseConfig.Enabled
+	if releasing {
+		job.AwaitChildren = true
+	}
+	// A release needs an eval job to await the tag's pipeline, so tags
+	// of an eval-less project that releases still get one.
+	if len(project.JobTemplates) > 0 && !releasing {
+		statusClient := h.getStatusClient(context.Background(), project, event.Provider, client)
+		return h.createTemplateJobs(context.Background(), event, statusClient, project, job, record)
+	}
+
+	worker.RouteProjectQueue(context.Background(), h.store, project, job)
+
 	// Create the job in the database
+	if err := hooks.CheckJobCreate(context.Background(), job, hooks.SourceWebhook); err != nil {
+		return err
+	}
+	if event.GenericEvent == vcs.EventPush {
+		h.coalescePushes(context.Background(), project, branch, job)
+	}
 	if err := h.store.CreateJob(context.Background(), job); err != nil {
 		return fmt.Errorf("creating job: %w", err)
 	}
+	record.Status = models.WebhookEventProcessed
+	record.JobID = &job.JobID
+	if releasing {
+		h.createRelease(context.Background(), project, event, job)
+	}
 
 	// Submit job to Corndogs task queue
 	h.submitJobToCorndogs(job)
@@ -517,7 +688,7 @@ func (h *WebhookHandler) processPushEvent(event *vcs.WebhookEvent, client vcs.Cl
 		State:       vcs.StatusPending,
 		TargetURL:   h.getJobURL(job.JobID),
 		Description: "CI build queued",
-		Context:     "reactorcide/eval",
+		Context:     evalStatusContext(project),
 	}
 
 	if err := statusClient.UpdateCommitStatus(context.Background(), event.Repository.FullName, statusUpdate); err != nil {
@@ -572,6 +743,56 @@ func (h *WebhookHandler) handlePRMerged(event *vcs.WebhookEvent) {
 	}
 }
 
+// previewEnvironmentListStore is the narrow store interface needed to find a
+// PR's preview environments. See postgres_store/preview_environment_operations.go.
+type previewEnvironmentListStore interface {
+	ListPreviewEnvironmentsForPR(ctx context.Context, repo string, prNumber int) ([]models.PreviewEnvironment, error)
+}
+
+// teardownPreviewEnvironments starts teardown of a closed or merged PR's
+// preview environments. Environments already being torn down (e.g. on a
+// redelivered webhook) are skipped.
+func (h *WebhookHandler) teardownPreviewEnvironments(event *vcs.WebhookEvent) {
+	ps, ok := h.store.(previewEnvironmentListStore)
+	if !ok {
+		return
+	}
+	ctx := context.Background()
+	repo := event.Repository.FullName
+	prNumber := event.PullRequest.Number
+
+	envs, err := ps.ListPreviewEnvironmentsForPR(ctx, repo, prNumber)
+	if err != nil {
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"repo":      repo,
+			"pr_number": prNumber,
+		}).Warn("Failed to list preview environments for closed PR")
+		return
+	}
+
+	for i := range envs {
+		env := &envs[i]
+		logger := h.logger.WithFields(logrus.Fields{
+			"repo":           repo,
+			"pr_number":      prNumber,
+			"environment_id": env.EnvironmentID,
+			"environment":    env.Name,
+		})
+		job, err := jobcontrol.TeardownPreviewEnvironment(ctx, h.store, h.corndogsClient, env, string(event.GenericEvent))
+		if errors.Is(err, jobcontrol.ErrNotTearable) {
+			continue
+		}
+		if err != nil {
+			logger.WithError(err).Error("Failed to tear down preview environment")
+			continue
+		}
+		if job != nil {
+			logger = logger.WithField("job_id", job.JobID)
+		}
+		logger.Info("Tearing down preview environment")
+	}
+}
+
 // vcsCredentialRotationStore is the narrow store interface needed to list
 // active rotatable VCS credentials and stamp last-used timestamps. Defined
 // on the consumer side per the repo's narrow-interface + type-assertion
@@ -711,14 +932,9 @@ func (h *WebhookHandler) projectOwner(ctx context.Context, project *models.Proje
 }
 
 func globalWebhookSecret(provider vcs.Provider) string {
-	switch provider {
-	case vcs.GitHub:
-		if config.VCSGitHubSecret != "" {
-			return config.VCSGitHubSecret
-		}
-	case vcs.GitLab:
-		if config.VCSGitLabSecret != "" {
-			return config.VCSGitLabSecret
+	if spec, ok := vcs.Providers.Lookup(provider); ok && spec.WebhookSecret != nil {
+		if secret := spec.WebhookSecret(); secret != "" {
+			return secret
 		}
 	}
 	return config.VCSWebhookSecret
@@ -726,7 +942,7 @@ func globalWebhookSecret(provider vcs.Provider) string {
 
 // submitJobToCorndogs submits a job to the Corndogs task queue
 func (h *WebhookHandler) submitJobToCorndogs(job *models.Job) {
-	if h.corndogsClient == nil {
+	if worker.HoldIfPaused(context.Background(), h.store, job) || h.corndogsClient == nil {
 		return
 	}
 
@@ -779,6 +995,10 @@ func (h *WebhookHandler) submitJobToCorndogs(job *models.Job) {
 	if job.JobEnvFile != "" {
 		taskPayload.Config["env_file"] = job.JobEnvFile
 	}
+	taskPayload.SetTargetPlatform(job.TargetOS, job.TargetArch)
+	if job.QueueRouted {
+		taskPayload.SetTargetQueue(job.QueueName)
+	}
 
 	task, err := h.corndogsClient.SubmitTask(context.Background(), taskPayload, int64(job.Priority))
 	if err != nil {
@@ -809,12 +1029,8 @@ func (h *WebhookHandler) submitJobToCorndogs(job *models.Job) {
 // isPingEvent returns true if the incoming request is a connectivity-check ping
 // from the VCS provider. Each provider uses a different mechanism to signal this.
 func isPingEvent(r *http.Request, provider vcs.Provider) bool {
-	switch provider {
-	case vcs.GitHub:
-		return r.Header.Get("X-GitHub-Event") == "ping"
-	default:
-		return false
-	}
+	spec, ok := vcs.Providers.Lookup(provider)
+	return ok && spec.IsPing != nil && spec.IsPing(r)
 }
 
 // getJobURL returns the URL for a job
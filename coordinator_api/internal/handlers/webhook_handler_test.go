@@ -24,12 +24,15 @@ import (
 type WebhookMockStore struct {
 	CreateJobFunc           func(ctx context.Context, job *models.Job) error
 	UpdateJobFunc           func(ctx context.Context, job *models.Job) error
-	GetProjectByRepoURLFunc func(ctx context.Context, repoURL string) (*models.Project, error)
-	GetUserByIDFunc         func(ctx context.Context, userID string) (*models.User, error)
-
-	CreateJobCalls           []*models.Job
-	UpdateJobCalls           []*models.Job
-	GetProjectByRepoURLCalls []string
+	// GetProjectByRepoURLFunc stubs a repository with a single project;
+	// GetProjectsByRepoURLFunc, when set, takes precedence.
+	GetProjectByRepoURLFunc  func(ctx context.Context, repoURL string) (*models.Project, error)
+	GetProjectsByRepoURLFunc func(ctx context.Context, repoURL string) ([]models.Project, error)
+	GetUserByIDFunc          func(ctx context.Context, userID string) (*models.User, error)
+
+	CreateJobCalls            []*models.Job
+	UpdateJobCalls            []*models.Job
+	GetProjectsByRepoURLCalls []string
 }
 
 func (m *WebhookMockStore) CreateJob(ctx context.Context, job *models.Job) error {
@@ -53,10 +56,17 @@ func (m *WebhookMockStore) UpdateJob(ctx context.Context, job *models.Job) error
 	return nil
 }
 
-func (m *WebhookMockStore) GetProjectByRepoURL(ctx context.Context, repoURL string) (*models.Project, error) {
-	m.GetProjectByRepoURLCalls = append(m.GetProjectByRepoURLCalls, repoURL)
+func (m *WebhookMockStore) GetProjectsByRepoURL(ctx context.Context, repoURL string) ([]models.Project, error) {
+	m.GetProjectsByRepoURLCalls = append(m.GetProjectsByRepoURLCalls, repoURL)
+	if m.GetProjectsByRepoURLFunc != nil {
+		return m.GetProjectsByRepoURLFunc(ctx, repoURL)
+	}
 	if m.GetProjectByRepoURLFunc != nil {
-		return m.GetProjectByRepoURLFunc(ctx, repoURL)
+		p, err := m.GetProjectByRepoURLFunc(ctx, repoURL)
+		if err != nil {
+			return nil, err
+		}
+		return []models.Project{*p}, nil
 	}
 	return nil, store.ErrNotFound
 }
@@ -834,6 +844,54 @@ func TestWebhookHandler_TagCreated_WithEmptyTargetBranches(t *testing.T) {
 	assert.Equal(t, "tag_created", mockStore.CreateJobCalls[0].JobEnvVars["REACTORCIDE_EVENT_TYPE"])
 }
 
+func TestWebhookHandler_TagCreated_WithTagPatterns(t *testing.T) {
+	project := webhookTestProject()
+	project.TagPatterns = []string{"v*"} // tags no longer checked against TargetBranches
+	mockStore := &WebhookMockStore{
+		GetProjectByRepoURLFunc: func(ctx context.Context, repoURL string) (*models.Project, error) {
+			return project, nil
+		},
+	}
+	handler := NewWebhookHandler(mockStore, nil)
+	handler.SetTokenResolver(testTokenResolver())
+
+	pushEvent := &vcs.WebhookEvent{
+		Provider:     vcs.GitHub,
+		EventType:    "push",
+		GenericEvent: vcs.EventTagCreated,
+		Repository: vcs.RepositoryInfo{
+			FullName: "test-org/test-repo",
+			CloneURL: "https://github.com/test-org/test-repo.git",
+		},
+		Push: &vcs.PushInfo{
+			Ref:    "refs/tags/v1.0.0",
+			Before: "0000000000000000000000000000000000000000",
+			After:  "tag-sha-1234",
+			Commits: []vcs.Commit{
+				{ID: "tag-sha-1234", Message: "Release v1.0.0"},
+			},
+		},
+	}
+
+	mockVCS := &MockVCSClient{
+		ParseWebhookFunc: func(r *http.Request) (*vcs.WebhookEvent, error) {
+			return pushEvent, nil
+		},
+	}
+	handler.AddVCSClient(vcs.GitHub, mockVCS)
+
+	body := makePushWebhookBody("test-org/test-repo", "https://github.com/test-org/test-repo.git", "tag-sha-1234", "refs/tags/v1.0.0")
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/github", bytes.NewReader(body))
+	req.Header.Set("X-GitHub-Event", "push")
+	w := httptest.NewRecorder()
+
+	handler.HandleGitHubWebhook(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, mockStore.CreateJobCalls, 1)
+	assert.Equal(t, "tag_created", mockStore.CreateJobCalls[0].JobEnvVars["REACTORCIDE_EVENT_TYPE"])
+}
+
 func TestWebhookHandler_PRSynchronize_CreatesJob(t *testing.T) {
 	project := webhookTestProject()
 	mockStore := &WebhookMockStore{
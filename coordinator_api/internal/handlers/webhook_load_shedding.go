@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/metrics"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
+)
+
+// Reasons a webhook delivery is shed, as counted in
+// reactorcide_webhooks_shed_total.
+const (
+	shedReasonQueueFull = "queue_full"
+	shedReasonBacklog   = "backlog"
+	shedReasonDBLatency = "db_latency"
+)
+
+// webhookBacklogStore is the narrow store capability for measuring the
+// queued webhook backlog. See postgres_store/webhook_event_operations.go.
+type webhookBacklogStore interface {
+	CountDueWebhookEvents(ctx context.Context, now time.Time) (int64, error)
+}
+
+// LoadSheddingConfig configures webhook load shedding. A zero threshold
+// disables its check.
+type LoadSheddingConfig struct {
+	// QueueFullPercent sheds deliveries while the async webhook queue's
+	// buffer is at least this full.
+	QueueFullPercent int
+	// BacklogThreshold sheds deliveries while at least this many queued
+	// events are due and waiting for any coordinator's workers.
+	BacklogThreshold int64
+	// DBLatencyThreshold sheds deliveries while the smoothed database
+	// round trip takes at least this long.
+	DBLatencyThreshold time.Duration
+	// SampleInterval is how often the backlog and database latency are
+	// measured.
+	SampleInterval time.Duration
+	// RetryAfter is sent in the Retry-After header of a shed delivery.
+	RetryAfter time.Duration
+}
+
+// webhookLoadShedder turns webhook deliveries away with a 503 while the
+// coordinator is overloaded, before any work is done on them, so the
+// provider retries later. The queue is checked on each delivery; the
+// backlog and database latency are sampled in the background.
+type webhookLoadShedder struct {
+	h       *WebhookHandler
+	config  LoadSheddingConfig
+	probeDB func(ctx context.Context) error
+	now     func() time.Time
+
+	mu        sync.Mutex
+	dbLatency time.Duration // Smoothed; zero until sampled
+	backlog   int64
+	reason    string // Why the last sample was overloaded, if it was
+}
+
+// StartLoadShedding makes the handler shed deliveries while its queue,
+// the backlog or the database is saturated (see LoadSheddingConfig), and
+// samples the backlog and database until ctx is done. Must be called
+// before the handler serves requests, after StartAsync.
+func (h *WebhookHandler) StartLoadShedding(ctx context.Context, config LoadSheddingConfig) {
+	if config.QueueFullPercent <= 0 && config.BacklogThreshold <= 0 && config.DBLatencyThreshold <= 0 {
+		return
+	}
+	if config.SampleInterval <= 0 {
+		config.SampleInterval = 5 * time.Second
+	}
+	if config.RetryAfter <= 0 {
+		config.RetryAfter = time.Minute
+	}
+	s := &webhookLoadShedder{
+		h:      h,
+		config: config,
+		probeDB: func(ctx context.Context) error {
+			db := store.GetDBFromContext(ctx)
+			if db == nil {
+				return fmt.Errorf("database not available")
+			}
+			return db.Exec("SELECT 1").Error
+		},
+		now: func() time.Time { return time.Now().UTC() },
+	}
+	s.sample(ctx)
+	go s.sampleLoop(ctx)
+	h.shedder = s
+}
+
+func (s *webhookLoadShedder) sampleLoop(ctx context.Context) {
+	ticker := time.NewTicker(s.config.SampleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sample(ctx)
+		}
+	}
+}
+
+// sample measures the database latency and the backlog. A database that
+// doesn't answer within the sample interval counts as that slow.
+func (s *webhookLoadShedder) sample(ctx context.Context) {
+	var latency time.Duration
+	if s.config.DBLatencyThreshold > 0 {
+		probeCtx, cancel := context.WithTimeout(ctx, s.config.SampleInterval)
+		start := time.Now()
+		err := s.probeDB(probeCtx)
+		latency = time.Since(start)
+		cancel()
+		if err != nil {
+			latency = s.config.SampleInterval
+		}
+	}
+	backlog := int64(-1)
+	if bs, ok := s.h.store.(webhookBacklogStore); ok && s.config.BacklogThreshold > 0 {
+		count, err := bs.CountDueWebhookEvents(ctx, s.now())
+		if err != nil {
+			s.h.logger.WithError(err).Warn("Failed to measure the webhook backlog")
+		} else {
+			backlog = count
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.config.DBLatencyThreshold > 0 {
+		if s.dbLatency == 0 {
+			s.dbLatency = latency
+		} else {
+			// Smooth out single slow round trips.
+			s.dbLatency = (s.dbLatency + latency) / 2
+		}
+		metrics.WebhookDBLatency.Set(s.dbLatency.Seconds())
+	}
+	if backlog >= 0 {
+		s.backlog = backlog
+		metrics.WebhookBacklog.Set(float64(backlog))
+	}
+
+	reason := ""
+	switch {
+	case s.config.DBLatencyThreshold > 0 && s.dbLatency >= s.config.DBLatencyThreshold:
+		reason = shedReasonDBLatency
+	case s.config.BacklogThreshold > 0 && s.backlog >= s.config.BacklogThreshold:
+		reason = shedReasonBacklog
+	}
+	if reason != s.reason {
+		log := s.h.logger.WithField("db_latency", s.dbLatency.String()).WithField("backlog", s.backlog)
+		if reason != "" {
+			log.WithField("reason", reason).Warn("Coordinator overloaded; shedding webhook deliveries")
+		} else {
+			log.Info("Coordinator no longer overloaded; accepting webhook deliveries")
+		}
+		s.reason = reason
+	}
+}
+
+// overloaded returns why deliveries should be shed right now, or "".
+func (s *webhookLoadShedder) overloaded() string {
+	if q := s.h.queue; q != nil && s.config.QueueFullPercent > 0 {
+		if len(q.events)*100 >= cap(q.events)*s.config.QueueFullPercent {
+			return shedReasonQueueFull
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reason
+}
+
+// shedLoad wraps a webhook route, answering 503 with Retry-After instead
+// of calling next while the coordinator is overloaded. Without load
+// shedding it calls next.
+func (h *WebhookHandler) shedLoad(provider vcs.Provider, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.shedder == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		reason := h.shedder.overloaded()
+		if reason == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		metrics.RecordWebhookShed(string(provider), reason)
+		retryAfter := max(int(h.shedder.config.RetryAfter.Round(time.Second)/time.Second), 1)
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "service_unavailable",
+			Message: fmt.Sprintf("Coordinator overloaded (%s); retry in %d seconds", reason, retryAfter),
+		})
+	})
+}
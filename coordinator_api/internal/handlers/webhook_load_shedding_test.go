@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// backlogWebhookStore reports a fixed webhook backlog.
+type backlogWebhookStore struct {
+	WebhookMockStore
+	backlog int64
+}
+
+func (m *backlogWebhookStore) CountDueWebhookEvents(ctx context.Context, now time.Time) (int64, error) {
+	return m.backlog, nil
+}
+
+func TestWebhookLoadShedding(t *testing.T) {
+	s := &backlogWebhookStore{}
+	handler := NewWebhookHandler(s, nil)
+	handler.queue = &webhookQueue{h: handler, events: make(chan *models.WebhookEvent, 10)}
+	probeLatency, probeErr := time.Millisecond, error(nil)
+	shedder := &webhookLoadShedder{
+		h: handler,
+		config: LoadSheddingConfig{
+			QueueFullPercent:   80,
+			BacklogThreshold:   100,
+			DBLatencyThreshold: 500 * time.Millisecond,
+			SampleInterval:     time.Second,
+			RetryAfter:         30 * time.Second,
+		},
+		probeDB: func(ctx context.Context) error {
+			time.Sleep(probeLatency)
+			return probeErr
+		},
+		now: time.Now,
+	}
+	handler.shedder = shedder
+
+	served := 0
+	route := handler.shedLoad(vcs.GitHub, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served++
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	deliver := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		route.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/github", nil))
+		return w
+	}
+	assertShed := func(reason string) {
+		t.Helper()
+		w := deliver()
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "30", w.Header().Get("Retry-After"))
+		var resp ErrorResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, "service_unavailable", resp.Error)
+		assert.Contains(t, resp.Message, reason)
+	}
+
+	shedder.sample(context.Background())
+	assert.Equal(t, http.StatusAccepted, deliver().Code)
+
+	t.Run("full queue", func(t *testing.T) {
+		for i := 0; i < 8; i++ {
+			handler.queue.events <- &models.WebhookEvent{}
+		}
+		assertShed(shedReasonQueueFull)
+		<-handler.queue.events
+		assert.Equal(t, http.StatusAccepted, deliver().Code)
+		for len(handler.queue.events) > 0 {
+			<-handler.queue.events
+		}
+	})
+
+	t.Run("backlog", func(t *testing.T) {
+		s.backlog = 100
+		shedder.sample(context.Background())
+		assertShed(shedReasonBacklog)
+		s.backlog = 0
+		shedder.sample(context.Background())
+		assert.Equal(t, http.StatusAccepted, deliver().Code)
+	})
+
+	t.Run("database latency", func(t *testing.T) {
+		// An unreachable database counts as slow as the sample interval;
+		// the smoothed latency recovers over a few samples.
+		probeErr = errors.New("connection refused")
+		shedder.sample(context.Background())
+		shedder.sample(context.Background())
+		assertShed(shedReasonDBLatency)
+		probeErr = nil
+		for i := 0; i < 3; i++ {
+			shedder.sample(context.Background())
+		}
+		assert.Equal(t, http.StatusAccepted, deliver().Code)
+	})
+
+	assert.Equal(t, 4, served)
+}
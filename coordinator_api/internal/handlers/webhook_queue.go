@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
+	"github.com/sirupsen/logrus"
+)
+
+// webhookQueueStore is the store capability behind asynchronous webhook
+// processing: events stored with their raw delivery are leased when due
+// and rescheduled when processing fails. See
+// postgres_store/webhook_event_operations.go. Without it (or the event
+// ledger) webhooks are processed in the request, as before.
+type webhookQueueStore interface {
+	ClaimDueWebhookEvents(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookEvent, error)
+	RetryWebhookEvent(ctx context.Context, eventID string, lease *time.Time, attempts int, nextAttemptAt time.Time, errMsg string, outcomes models.WebhookProjectOutcomes) error
+	RenewWebhookEventLease(ctx context.Context, eventID string, lease, renewed time.Time) error
+}
+
+// webhookDeliveryHeaders are the request headers the VCS parsers read,
+// kept with a queued delivery. Secret-bearing headers (X-Gitlab-Token) and
+// signatures are deliberately left out: the delivery was authenticated
+// before it was queued.
+var webhookDeliveryHeaders = []string{
+	"Content-Type",
+	"X-GitHub-Event",
+	"X-GitHub-Delivery",
+	"X-Gitlab-Event",
+	"X-Gitlab-Event-UUID",
+}
+
+// errWebhookUnprocessable marks a queued event that can never be
+// processed, so it fails without retries.
+var errWebhookUnprocessable = errors.New("webhook event cannot be processed")
+
+// WebhookQueueConfig configures asynchronous webhook processing.
+type WebhookQueueConfig struct {
+	// Workers process events concurrently, taking them from a buffer of
+	// QueueSize. An event that doesn't fit waits for the next sweep.
+	Workers   int
+	QueueSize int
+	// MaxAttempts is how many times an event is tried before it's
+	// recorded as failed; attempt n waits RetryBackoff * 2^(n-1), at most
+	// MaxRetryBackoff.
+	MaxAttempts     int
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+	// SweepInterval is how often due events (retries, and events whose
+	// coordinator died) are picked up from the store, and Lease how long
+	// a picked up event is reserved for the coordinator processing it.
+	// The lease is renewed every third of Lease while processing runs.
+	SweepInterval time.Duration
+	Lease         time.Duration
+}
+
+// webhookQueue processes stored webhook deliveries in a worker pool.
+type webhookQueue struct {
+	h      *WebhookHandler
+	store  webhookQueueStore
+	config WebhookQueueConfig
+	events chan *models.WebhookEvent
+	now    func() time.Time
+}
+
+// StartAsync makes the handler acknowledge webhooks once they're validated
+// and stored, and process them in a pool of workers with retries, until
+// ctx is done. Without the store capabilities it needs, webhooks stay
+// synchronous. Must be called before the handler serves requests.
+func (h *WebhookHandler) StartAsync(ctx context.Context, config WebhookQueueConfig) {
+	qs, ok := h.store.(webhookQueueStore)
+	if _, ledger := h.store.(webhookEventStore); !ok || !ledger {
+		h.logger.Warn("Store does not support queued webhook events; webhooks are processed synchronously")
+		return
+	}
+	if config.Workers <= 0 {
+		config.Workers = 4
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 256
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 5
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = 30 * time.Second
+	}
+	if config.MaxRetryBackoff <= 0 {
+		config.MaxRetryBackoff = 10 * time.Minute
+	}
+	if config.SweepInterval <= 0 {
+		config.SweepInterval = 10 * time.Second
+	}
+	if config.Lease <= 0 {
+		config.Lease = 5 * time.Minute
+	}
+	q := &webhookQueue{
+		h:      h,
+		store:  qs,
+		config: config,
+		events: make(chan *models.WebhookEvent, config.QueueSize),
+		now:    func() time.Time { return time.Now().UTC() },
+	}
+	for i := 0; i < config.Workers; i++ {
+		go q.work(ctx)
+	}
+	go q.sweepLoop(ctx)
+	h.queue = q
+}
+
+// prepare stores the raw delivery on record, leased to this coordinator,
+// so it can be processed after the request returns.
+func (q *webhookQueue) prepare(record *models.WebhookEvent, r *http.Request, body []byte, projects []*models.Project) {
+	record.Payload = body
+	record.Headers = models.JSONB{}
+	for _, name := range webhookDeliveryHeaders {
+		if value := r.Header.Get(name); value != "" {
+			record.Headers[name] = value
+		}
+	}
+	record.ProjectIDs = make([]string, 0, len(projects))
+	for _, project := range projects {
+		record.ProjectIDs = append(record.ProjectIDs, project.ProjectID)
+	}
+	lease := q.now().Add(q.config.Lease).Truncate(time.Microsecond)
+	record.NextAttemptAt = &lease
+}
+
+// enqueue hands a stored event to the workers. When they're backed up, the
+// event is made due at once, for the next sweep of any coordinator.
+func (q *webhookQueue) enqueue(record *models.WebhookEvent) {
+	select {
+	case q.events <- record:
+	default:
+		q.h.logger.WithField("event_id", record.EventID).Warn("Webhook queue full; deferring event to the next sweep")
+		if err := q.store.RetryWebhookEvent(context.Background(), record.EventID, record.NextAttemptAt, record.Attempts, q.now(), record.Error, record.ProjectOutcomes); err != nil {
+			q.h.logger.WithError(err).WithField("event_id", record.EventID).Warn("Failed to defer webhook event")
+		}
+	}
+}
+
+func (q *webhookQueue) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case record := <-q.events:
+			q.process(record)
+		}
+	}
+}
+
+func (q *webhookQueue) sweepLoop(ctx context.Context) {
+	ticker := time.NewTicker(q.config.SweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := q.Sweep(ctx); err != nil {
+				q.h.logger.WithError(err).Warn("Webhook queue sweep failed")
+			}
+		}
+	}
+}
+
+// Sweep leases the events that are due, as many as the workers have room
+// for, and queues them.
+func (q *webhookQueue) Sweep(ctx context.Context) error {
+	room := cap(q.events) - len(q.events)
+	if room <= 0 {
+		return nil
+	}
+	events, err := q.store.ClaimDueWebhookEvents(ctx, q.now(), q.config.Lease, room)
+	if err != nil {
+		return err
+	}
+	for i := range events {
+		q.enqueue(&events[i])
+	}
+	return nil
+}
+
+// process runs one attempt at a queued event, recording the outcome or
+// scheduling a retry. The event's lease is renewed while it runs, and the
+// outcome is only recorded under the lease, so an event a sweep handed to
+// another coordinator meanwhile isn't recorded twice.
+func (q *webhookQueue) process(record *models.WebhookEvent) {
+	release := q.holdLease(record)
+	err := q.h.processStoredEvent(record)
+	record.NextAttemptAt = release()
+	if err == nil {
+		q.h.finishWebhookEvent(record)
+		return
+	}
+	record.Attempts++
+	log := q.h.logger.WithError(err).WithFields(logrus.Fields{
+		"event_id": record.EventID,
+		"attempt":  record.Attempts,
+	})
+	if errors.Is(err, errWebhookUnprocessable) || record.Attempts >= q.config.MaxAttempts {
+		log.Error("Webhook event failed; giving up")
+		q.h.failWebhookEvent(record, err)
+		return
+	}
+	backoff := q.config.RetryBackoff << (record.Attempts - 1)
+	if backoff <= 0 || backoff > q.config.MaxRetryBackoff {
+		backoff = q.config.MaxRetryBackoff
+	}
+	log.WithField("retry_in", backoff.String()).Warn("Webhook event failed; will retry")
+	if err := q.store.RetryWebhookEvent(context.Background(), record.EventID, record.NextAttemptAt, record.Attempts, q.now().Add(backoff), err.Error(), record.ProjectOutcomes); err != nil {
+		q.h.logger.WithError(err).WithField("event_id", record.EventID).Warn("Failed to schedule webhook event retry")
+	}
+}
+
+// holdLease renews record's lease every third of the lease period until
+// the returned func is called, which stops renewing and returns the lease
+// now held. A renewal that finds the lease gone stops renewing: another
+// coordinator has the event, and this one's outcome won't be recorded.
+func (q *webhookQueue) holdLease(record *models.WebhookEvent) func() *time.Time {
+	eventID, lease := record.EventID, record.NextAttemptAt
+	if lease == nil {
+		return func() *time.Time { return nil }
+	}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(q.config.Lease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				renewed := q.now().Add(q.config.Lease).Truncate(time.Microsecond)
+				if err := q.store.RenewWebhookEventLease(context.Background(), eventID, *lease, renewed); err != nil {
+					q.h.logger.WithError(err).WithField("event_id", eventID).Warn("Failed to renew webhook event lease")
+					if errors.Is(err, store.ErrNotFound) {
+						return
+					}
+					continue
+				}
+				lease = &renewed
+			}
+		}
+	}()
+	return func() *time.Time {
+		close(done)
+		<-stopped
+		return lease
+	}
+}
+
+// processStoredEvent processes an event from its stored delivery: it
+// parses the payload again and loads the projects it authenticated for.
+func (h *WebhookHandler) processStoredEvent(record *models.WebhookEvent) error {
+	provider := vcs.Provider(record.Provider)
+	client, ok := h.vcsClients[provider]
+	if !ok {
+		return fmt.Errorf("%w: VCS provider %s not configured", errWebhookUnprocessable, provider)
+	}
+	r, err := http.NewRequest(http.MethodPost, "/", bytes.NewReader(record.Payload))
+	if err != nil {
+		return fmt.Errorf("%w: %v", errWebhookUnprocessable, err)
+	}
+	for name, value := range record.Headers {
+		if s, ok := value.(string); ok {
+			r.Header.Set(name, s)
+		}
+	}
+	event, err := client.ParseWebhook(r)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errWebhookUnprocessable, err)
+	}
+
+	var projects []*models.Project
+	for _, projectID := range record.ProjectIDs {
+		project, err := h.store.GetProjectByID(context.Background(), projectID)
+		if errors.Is(err, store.ErrNotFound) {
+			h.logger.WithField("project_id", projectID).Warn("Skipping deleted project of queued webhook event")
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load project %s: %w", projectID, err)
+		}
+		projects = append(projects, project)
+	}
+	return h.processEvent(event, client, projects, record)
+}
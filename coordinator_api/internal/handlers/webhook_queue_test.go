@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// queueWebhookStore adds the webhookQueueStore capability, and project
+// lookup by id, to ledgerWebhookStore.
+type queueWebhookStore struct {
+	ledgerWebhookStore
+	project *models.Project
+	// mu guards the ledger against lease renewals, which run alongside
+	// processing.
+	mu sync.Mutex
+}
+
+func (m *queueWebhookStore) GetProjectByID(ctx context.Context, projectID string) (*models.Project, error) {
+	return m.project, nil
+}
+
+func (m *queueWebhookStore) ClaimDueWebhookEvents(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var due []models.WebhookEvent
+	for _, event := range m.events {
+		if event.Status == models.WebhookEventProcessing && event.NextAttemptAt != nil && !event.NextAttemptAt.After(now) && len(due) < limit {
+			next := now.Add(lease)
+			event.NextAttemptAt = &next
+			due = append(due, *event)
+		}
+	}
+	return due, nil
+}
+
+func (m *queueWebhookStore) RetryWebhookEvent(ctx context.Context, eventID string, lease *time.Time, attempts int, nextAttemptAt time.Time, errMsg string, outcomes models.WebhookProjectOutcomes) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, event := range m.events {
+		if event.EventID == eventID {
+			if event.Status != models.WebhookEventProcessing || !sameLease(event.NextAttemptAt, lease) {
+				return store.ErrNotFound
+			}
+			event.Attempts = attempts
+			event.NextAttemptAt = &nextAttemptAt
+			event.Error = errMsg
+			event.ProjectOutcomes = outcomes
+		}
+	}
+	return nil
+}
+
+func (m *queueWebhookStore) RenewWebhookEventLease(ctx context.Context, eventID string, lease, renewed time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, event := range m.events {
+		if event.EventID == eventID {
+			if event.Status != models.WebhookEventProcessing || !sameLease(event.NextAttemptAt, &lease) {
+				return store.ErrNotFound
+			}
+			event.NextAttemptAt = &renewed
+		}
+	}
+	return nil
+}
+
+func (m *queueWebhookStore) UpdateWebhookEvent(ctx context.Context, event *models.WebhookEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ledgerWebhookStore.UpdateWebhookEvent(ctx, event)
+}
+
+// newQueuedWebhookHandler returns a handler whose queue is driven by the
+// test rather than by worker goroutines. parsedHeaders collects the
+// X-GitHub-Event header of every parse.
+func newQueuedWebhookHandler(s *queueWebhookStore, parsedHeaders *[]string) (*WebhookHandler, *webhookQueue, *time.Time) {
+	handler := NewWebhookHandler(s, corndogs.NewMockClient())
+	handler.SetTokenResolver(testTokenResolver())
+	handler.AddVCSClient(vcs.GitHub, &MockVCSClient{
+		ParseWebhookFunc: func(r *http.Request) (*vcs.WebhookEvent, error) {
+			*parsedHeaders = append(*parsedHeaders, r.Header.Get("X-GitHub-Event"))
+			return &vcs.WebhookEvent{
+				Provider:     vcs.GitHub,
+				EventType:    "push",
+				GenericEvent: vcs.EventPush,
+				DeliveryID:   "delivery-q",
+				Repository: vcs.RepositoryInfo{
+					FullName: "test-org/test-repo",
+					CloneURL: "https://github.com/test-org/test-repo.git",
+				},
+				Push: &vcs.PushInfo{Ref: "refs/heads/main", Before: "before-sha", After: "after-sha-1234"},
+			}, nil
+		},
+	})
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	q := &webhookQueue{
+		h:      handler,
+		store:  s,
+		config: WebhookQueueConfig{QueueSize: 1, MaxAttempts: 2, RetryBackoff: time.Minute, MaxRetryBackoff: time.Hour, Lease: 5 * time.Minute},
+		events: make(chan *models.WebhookEvent, 1),
+		now:    func() time.Time { return now },
+	}
+	handler.queue = q
+	return handler, q, &now
+}
+
+func postQueuedPush(handler *WebhookHandler) *httptest.ResponseRecorder {
+	body := makePushWebhookBody("test-org/test-repo", "https://github.com/test-org/test-repo.git", "after-sha-1234", "refs/heads/main")
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/github", bytes.NewReader(body))
+	req.Header.Set("X-GitHub-Event", "push")
+	req.Header.Set("X-Hub-Signature-256", "sha256=not-kept")
+	w := httptest.NewRecorder()
+	handler.HandleGitHubWebhook(w, req)
+	return w
+}
+
+func TestWebhookQueue(t *testing.T) {
+	project := webhookTestProject()
+	newStore := func() *queueWebhookStore {
+		return &queueWebhookStore{
+			ledgerWebhookStore: ledgerWebhookStore{WebhookMockStore: WebhookMockStore{
+				GetProjectByRepoURLFunc: func(ctx context.Context, repoURL string) (*models.Project, error) {
+					return project, nil
+				},
+			}},
+			project: project,
+		}
+	}
+
+	t.Run("delivery is acknowledged before processing", func(t *testing.T) {
+		s := newStore()
+		var parsed []string
+		handler, q, _ := newQueuedWebhookHandler(s, &parsed)
+
+		w := postQueuedPush(handler)
+		require.Equal(t, http.StatusAccepted, w.Code)
+		var resp map[string]string
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, "accepted", resp["status"])
+		assert.Empty(t, s.CreateJobCalls, "no job before the worker runs")
+
+		require.Len(t, s.events, 1)
+		stored := s.events[0]
+		assert.Equal(t, resp["event_id"], stored.EventID)
+		assert.NotEmpty(t, stored.Payload)
+		assert.Equal(t, "push", stored.Headers["X-GitHub-Event"])
+		assert.NotContains(t, stored.Headers, "X-Hub-Signature-256")
+		assert.Equal(t, []string{project.ProjectID}, []string(stored.ProjectIDs))
+
+		q.process(<-q.events)
+		require.Len(t, s.CreateJobCalls, 1)
+		assert.Equal(t, models.WebhookEventProcessed, s.events[0].Status)
+		// Parsed once in the request and again, from the stored delivery,
+		// by the worker.
+		assert.Equal(t, []string{"push", "push"}, parsed)
+	})
+
+	t.Run("failed processing is retried from the store", func(t *testing.T) {
+		s := newStore()
+		var parsed []string
+		handler, q, now := newQueuedWebhookHandler(s, &parsed)
+		s.CreateJobFunc = func(ctx context.Context, job *models.Job) error {
+			return assert.AnError
+		}
+
+		require.Equal(t, http.StatusAccepted, postQueuedPush(handler).Code)
+		q.process(<-q.events)
+		event := s.events[0]
+		assert.Equal(t, models.WebhookEventProcessing, event.Status)
+		assert.Equal(t, 1, event.Attempts)
+		require.NotNil(t, event.NextAttemptAt)
+		assert.Equal(t, now.Add(time.Minute), *event.NextAttemptAt)
+
+		// Not due yet.
+		require.NoError(t, q.Sweep(context.Background()))
+		assert.Empty(t, q.events)
+
+		*now = now.Add(time.Minute)
+		s.CreateJobFunc = nil
+		require.NoError(t, q.Sweep(context.Background()))
+		require.Len(t, q.events, 1)
+		q.process(<-q.events)
+		assert.Equal(t, models.WebhookEventProcessed, s.events[0].Status)
+	})
+
+	t.Run("event fails after its last attempt", func(t *testing.T) {
+		s := newStore()
+		var parsed []string
+		handler, q, now := newQueuedWebhookHandler(s, &parsed)
+		s.CreateJobFunc = func(ctx context.Context, job *models.Job) error {
+			return assert.AnError
+		}
+
+		require.Equal(t, http.StatusAccepted, postQueuedPush(handler).Code)
+		q.process(<-q.events)
+		*now = now.Add(time.Hour)
+		require.NoError(t, q.Sweep(context.Background()))
+		q.process(<-q.events)
+
+		assert.Equal(t, models.WebhookEventFailed, s.events[0].Status)
+		assert.Equal(t, 2, s.events[0].Attempts)
+		assert.NotEmpty(t, s.events[0].Error)
+	})
+	t.Run("sweep during slow processing doesn't duplicate the event", func(t *testing.T) {
+		s := newStore()
+		var parsed []string
+		handler, q, _ := newQueuedWebhookHandler(s, &parsed)
+		q.config.Lease = 30 * time.Millisecond
+		q.now = func() time.Time { return time.Now().UTC() }
+		creating, release := make(chan struct{}), make(chan struct{})
+		s.CreateJobFunc = func(ctx context.Context, job *models.Job) error {
+			close(creating)
+			<-release
+			return nil
+		}
+
+		require.Equal(t, http.StatusAccepted, postQueuedPush(handler).Code)
+		done := make(chan struct{})
+		go func() {
+			q.process(<-q.events)
+			close(done)
+		}()
+		<-creating
+
+		// Well past the original lease, the event is still held.
+		for i := 0; i < 5; i++ {
+			time.Sleep(q.config.Lease)
+			require.NoError(t, q.Sweep(context.Background()))
+			assert.Empty(t, q.events, "the event was handed out again while being processed")
+		}
+		close(release)
+		<-done
+
+		assert.Len(t, s.CreateJobCalls, 1)
+		assert.Equal(t, models.WebhookEventProcessed, s.events[0].Status)
+	})
+
+	t.Run("outcome isn't recorded once the lease is lost", func(t *testing.T) {
+		s := newStore()
+		var parsed []string
+		handler, q, now := newQueuedWebhookHandler(s, &parsed)
+		s.CreateJobFunc = func(ctx context.Context, job *models.Job) error {
+			return assert.AnError
+		}
+
+		require.Equal(t, http.StatusAccepted, postQueuedPush(handler).Code)
+		record := <-q.events
+		// The lease runs out and another coordinator's sweep takes the
+		// event over before this one finishes.
+		*now = now.Add(q.config.Lease)
+		_, err := s.ClaimDueWebhookEvents(context.Background(), *now, q.config.Lease, 1)
+		require.NoError(t, err)
+		taken := *s.events[0].NextAttemptAt
+
+		q.process(record)
+		assert.Equal(t, 0, s.events[0].Attempts, "the retry wasn't recorded over the new holder's lease")
+		assert.Equal(t, taken, *s.events[0].NextAttemptAt)
+		assert.Equal(t, models.WebhookEventProcessing, s.events[0].Status)
+	})
+}
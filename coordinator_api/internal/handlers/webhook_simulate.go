@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/repourl"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/worker"
+)
+
+// simulatedCommitSHA stands in for the commit of a simulated event.
+const simulatedCommitSHA = "0000000000000000000000000000000000000000"
+
+// SimulateEventRequest is the JSON body of
+// POST /api/v1/projects/{project_id}/simulate-event: a synthetic VCS event.
+type SimulateEventRequest struct {
+	// EventType is a generic event type: push, tag_created,
+	// pull_request_opened, pull_request_updated, ...
+	EventType string `json:"event_type"`
+	// Branch is the pushed branch, a PR's base branch, or the tag name
+	// for tag_created.
+	Branch string `json:"branch"`
+	// Paths are the changed files. Without them the path filters aren't
+	// checked, as for a push whose files the provider doesn't list.
+	Paths []string `json:"paths,omitempty"`
+	// Provider defaults to github.
+	Provider string `json:"provider,omitempty"`
+	// Pull request events only.
+	HeadBranch    string `json:"head_branch,omitempty"`
+	Title         string `json:"title,omitempty"`
+	PreviousTitle string `json:"previous_title,omitempty"`
+	Draft         bool   `json:"draft,omitempty"`
+}
+
+// SimulatedFilterCheck is the outcome of one of a project's filters. Reason
+// is the filter_reason the event would be recorded with when it fails.
+type SimulatedFilterCheck struct {
+	Filter string `json:"filter"`
+	Result string `json:"result"` // pass, fail or skipped
+	Reason string `json:"reason,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// SimulatedJob is a job the event would create.
+type SimulatedJob struct {
+	Name           string            `json:"name"`
+	Description    string            `json:"description"`
+	SourceURL      string            `json:"source_url,omitempty"`
+	SourceRef      string            `json:"source_ref,omitempty"`
+	CISourceURL    string            `json:"ci_source_url,omitempty"`
+	CISourceRef    string            `json:"ci_source_ref,omitempty"`
+	JobCommand     string            `json:"job_command"`
+	RunnerImage    string            `json:"runner_image"`
+	QueueName      string            `json:"queue_name"`
+	Priority       int               `json:"priority"`
+	TimeoutSeconds int               `json:"timeout_seconds,omitempty"`
+	EnvVars        map[string]string `json:"env_vars"`
+}
+
+// SimulateEventResponse says whether a simulated event would build. Checks
+// lists every filter in the order webhooks apply them; FilterReason is the
+// first failure's reason, the one the event ledger would record.
+type SimulateEventResponse struct {
+	WouldRun     bool                   `json:"would_run"`
+	FilterReason string                 `json:"filter_reason,omitempty"`
+	Checks       []SimulatedFilterCheck `json:"checks"`
+	Jobs         []SimulatedJob         `json:"jobs"`
+}
+
+// SimulateEvent handles POST /api/v1/projects/{project_id}/simulate-event:
+// it runs a synthetic event through the project's filters and returns which
+// pass and fail and the eval job it would create, creating nothing. The
+// jobs the eval job goes on to trigger depend on the repository's job
+// definitions, which aren't read here; an eval-less project's jobs come
+// from its templates, so they're listed instead.
+func (h *ProjectHandler) SimulateEvent(w http.ResponseWriter, r *http.Request) {
+	user := checkauth.GetUserFromContext(r.Context())
+	if user == nil {
+		h.respondWithError(w, http.StatusUnauthorized, store.ErrUnauthorized)
+		return
+	}
+	projectID := h.getID(r, "project_id")
+	if projectID == "" {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+	project, err := h.store.GetProjectByID(r.Context(), projectID)
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, err)
+		return
+	}
+
+	var req SimulateEventRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+	event, err := simulatedEvent(project, req)
+	if err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "invalid_input",
+			Message: err.Error(),
+		})
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, simulateEvent(project, event, req.Paths))
+}
+
+// simulatedEvent builds the webhook event req describes.
+func simulatedEvent(project *models.Project, req SimulateEventRequest) (*vcs.WebhookEvent, error) {
+	if req.Branch == "" {
+		return nil, fmt.Errorf("branch is required")
+	}
+	provider := vcs.Provider(req.Provider)
+	if provider == "" {
+		provider = vcs.GitHub
+	}
+	if _, ok := vcs.Providers.Lookup(provider); !ok {
+		return nil, fmt.Errorf("unknown provider %q", req.Provider)
+	}
+	event := &vcs.WebhookEvent{
+		Provider:     provider,
+		EventType:    req.EventType,
+		GenericEvent: vcs.EventType(req.EventType),
+		Repository: vcs.RepositoryInfo{
+			FullName: repourl.FullName(project.RepoURL),
+			CloneURL: repourl.CloneURL(project.RepoURL),
+		},
+	}
+
+	switch event.GenericEvent {
+	case vcs.EventPush:
+		event.Push = &vcs.PushInfo{Ref: "refs/heads/" + req.Branch, After: simulatedCommitSHA}
+	case vcs.EventTagCreated:
+		event.Push = &vcs.PushInfo{Ref: "refs/tags/" + req.Branch, After: simulatedCommitSHA}
+	case vcs.EventPullRequestOpened, vcs.EventPullRequestUpdated, vcs.EventPullRequestMerged,
+		vcs.EventPullRequestClosed, vcs.EventPullRequestReadyForReview, vcs.EventPullRequestRetitled:
+		event.PullRequest = &vcs.PullRequestInfo{
+			Number:        1,
+			Title:         req.Title,
+			PreviousTitle: req.PreviousTitle,
+			Draft:         req.Draft,
+			HeadSHA:       simulatedCommitSHA,
+			HeadRef:       req.HeadBranch,
+			BaseSHA:       simulatedCommitSHA,
+			BaseRef:       req.Branch,
+		}
+	case vcs.EventMergeGroup:
+		event.MergeGroup = &vcs.MergeGroupInfo{
+			HeadSHA: simulatedCommitSHA,
+			HeadRef: "refs/heads/gh-readonly-queue/" + req.Branch + "/simulated",
+			BaseSHA: simulatedCommitSHA,
+			BaseRef: req.Branch,
+		}
+	default:
+		return nil, fmt.Errorf("unsupported event type %q", req.EventType)
+	}
+	return event, nil
+}
+
+// simulateEvent applies the project's filters to event as
+// processPushEvent, processPullRequestEvent and processMergeGroupEvent do,
+// but evaluates every filter rather than stopping at the first failure.
+func simulateEvent(project *models.Project, event *vcs.WebhookEvent, paths []string) SimulateEventResponse {
+	resp := SimulateEventResponse{Checks: []SimulatedFilterCheck{}, Jobs: []SimulatedJob{}}
+	add := func(filter, reason, detail string) {
+		check := SimulatedFilterCheck{Filter: filter, Result: "pass", Detail: detail}
+		if reason != "" {
+			check.Result = "fail"
+			check.Reason = reason
+			if resp.FilterReason == "" {
+				resp.FilterReason = reason
+			}
+		}
+		resp.Checks = append(resp.Checks, check)
+	}
+
+	// Draft and WIP PRs are held back before any other filter, and a
+	// deferred PR that's ready is filtered as pull_request_opened.
+	if event.PullRequest != nil {
+		reason := applyDraftPRPolicy(event, project)
+		add("draft_pr_policy", reason, fmt.Sprintf("policy %q", project.DraftPRPolicy))
+	}
+
+	eventType := string(event.GenericEvent)
+	var ref string
+	switch {
+	case event.PullRequest != nil:
+		ref = event.PullRequest.BaseRef
+	case event.MergeGroup != nil:
+		ref = event.MergeGroup.BaseRef
+	default:
+		ref = strings.TrimPrefix(strings.TrimPrefix(event.Push.Ref, "refs/heads/"), "refs/tags/")
+	}
+
+	enabled := ""
+	if !project.Enabled {
+		enabled = models.EventFilterProjectDisabled
+	}
+	add("enabled", enabled, "")
+
+	eventTypeReason := ""
+	if !project.AllowsEventType(eventType) {
+		eventTypeReason = models.EventFilterEventType
+	}
+	add("allowed_event_types", eventTypeReason, fmt.Sprintf("%s in %v", eventType, []string(project.AllowedEventTypes)))
+
+	if eventType == string(vcs.EventTagCreated) && len(project.TagPatterns) > 0 {
+		add("tag_patterns", project.RefFilterReason(eventType, ref), fmt.Sprintf("%s in %v", ref, []string(project.TagPatterns)))
+	} else {
+		add("target_branches", project.RefFilterReason(eventType, ref), fmt.Sprintf("%s in %v", ref, []string(project.TargetBranches)))
+	}
+
+	// Merge groups aren't path filtered.
+	if event.MergeGroup == nil {
+		switch {
+		case len(project.PathFilters) == 0:
+			add("path_filters", "", "no path filters")
+		case len(paths) == 0:
+			resp.Checks = append(resp.Checks, SimulatedFilterCheck{
+				Filter: "path_filters",
+				Result: "skipped",
+				Detail: "no changed files given; the eval job applies the path filters",
+			})
+		case !models.MatchPathFilters(project.PathFilters, paths):
+			add("path_filters", models.EventFilterPaths, fmt.Sprintf("no changed file matches %v", []string(project.PathFilters)))
+		default:
+			add("path_filters", "", fmt.Sprintf("a changed file matches %v", []string(project.PathFilters)))
+		}
+	}
+
+	if resp.FilterReason != "" {
+		return resp
+	}
+	evalJob := BuildEvalJob(project, event)
+	releasing := event.GenericEvent == vcs.EventTagCreated && project.ReleaseConfig.Enabled
+	if len(project.JobTemplates) > 0 && !releasing {
+		for _, template := range project.JobTemplates.For(string(event.GenericEvent)) {
+			if job, err := worker.BuildTemplateJob(template, evalJob); err == nil {
+				resp.Jobs = append(resp.Jobs, simulatedJob(job))
+			}
+		}
+		if len(resp.Jobs) == 0 {
+			add("job_templates", models.EventFilterNoJobTemplates, "no job template runs for "+string(event.GenericEvent))
+			return resp
+		}
+		add("job_templates", "", fmt.Sprintf("%d job templates run", len(resp.Jobs)))
+		resp.WouldRun = true
+		return resp
+	}
+	resp.WouldRun = true
+	resp.Jobs = append(resp.Jobs, simulatedJob(evalJob))
+	return resp
+}
+
+// simulatedJob describes job, which was built but not created.
+func simulatedJob(job *models.Job) SimulatedJob {
+	sj := SimulatedJob{
+		Name:           job.Name,
+		Description:    job.Description,
+		JobCommand:     job.JobCommand,
+		RunnerImage:    job.RunnerImage,
+		QueueName:      job.QueueName,
+		Priority:       job.Priority,
+		TimeoutSeconds: job.TimeoutSeconds,
+		EnvVars:        job.JobEnvVars.EnvStrings(),
+	}
+	if job.SourceURL != nil {
+		sj.SourceURL = *job.SourceURL
+	}
+	if job.SourceRef != nil {
+		sj.SourceRef = *job.SourceRef
+	}
+	if job.CISourceURL != nil {
+		sj.CISourceURL = *job.CISourceURL
+	}
+	if job.CISourceRef != nil {
+		sj.CISourceRef = *job.CISourceRef
+	}
+	return sj
+}
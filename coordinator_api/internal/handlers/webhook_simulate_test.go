@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectHandler_SimulateEvent(t *testing.T) {
+	simulate := func(project *models.Project, body string) *httptest.ResponseRecorder {
+		s := &ProjectMockStore{GetProjectByIDFunc: func(ctx context.Context, projectID string) (*models.Project, error) {
+			return project, nil
+		}}
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/projects/"+project.ProjectID+"/simulate-event", bytes.NewBufferString(body))
+		req = withProjectID(withUser(req), project.ProjectID)
+		w := httptest.NewRecorder()
+		NewProjectHandler(s).SimulateEvent(w, req)
+		return w
+	}
+	decode := func(t *testing.T, w *httptest.ResponseRecorder) SimulateEventResponse {
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp SimulateEventResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		return resp
+	}
+	results := func(resp SimulateEventResponse) map[string]string {
+		m := map[string]string{}
+		for _, c := range resp.Checks {
+			m[c.Filter] = c.Result
+		}
+		return m
+	}
+
+	t.Run("matching push builds an eval job", func(t *testing.T) {
+		project := webhookTestProject()
+		project.PathFilters = []string{"services/api/**"}
+		resp := decode(t, simulate(project, `{"event_type":"push","branch":"main","paths":["services/api/main.go"]}`))
+
+		assert.True(t, resp.WouldRun)
+		assert.Empty(t, resp.FilterReason)
+		assert.Equal(t, map[string]string{
+			"enabled":             "pass",
+			"allowed_event_types": "pass",
+			"target_branches":     "pass",
+			"path_filters":        "pass",
+		}, results(resp))
+		require.Len(t, resp.Jobs, 1)
+		assert.Contains(t, resp.Jobs[0].Name, "eval: push to main")
+		assert.Equal(t, "main", resp.Jobs[0].EnvVars["REACTORCIDE_BRANCH"])
+		assert.Equal(t, "test-org/test-repo", resp.Jobs[0].EnvVars["REACTORCIDE_REPO"])
+	})
+
+	t.Run("every failing filter is reported", func(t *testing.T) {
+		project := webhookTestProject()
+		project.PathFilters = []string{"services/api/**"}
+		resp := decode(t, simulate(project, `{"event_type":"pull_request_merged","branch":"feature","paths":["docs/README.md"]}`))
+
+		assert.False(t, resp.WouldRun)
+		assert.Equal(t, models.EventFilterEventType, resp.FilterReason)
+		assert.Equal(t, map[string]string{
+			"draft_pr_policy":     "pass",
+			"enabled":             "pass",
+			"allowed_event_types": "fail",
+			"target_branches":     "fail",
+			"path_filters":        "fail",
+		}, results(resp))
+		assert.Empty(t, resp.Jobs)
+	})
+
+	t.Run("draft PR held back", func(t *testing.T) {
+		project := webhookTestProject()
+		project.DraftPRPolicy = models.DraftPRPolicySkip
+		resp := decode(t, simulate(project, `{"event_type":"pull_request_opened","branch":"main","draft":true}`))
+
+		assert.False(t, resp.WouldRun)
+		assert.Equal(t, models.EventFilterDraftPR, resp.FilterReason)
+	})
+
+	t.Run("tags use tag patterns", func(t *testing.T) {
+		project := webhookTestProject()
+		project.TagPatterns = []string{"v*"}
+		resp := decode(t, simulate(project, `{"event_type":"tag_created","branch":"v1.2.0"}`))
+		assert.True(t, resp.WouldRun)
+		assert.Equal(t, "pass", results(resp)["tag_patterns"])
+
+		resp = decode(t, simulate(project, `{"event_type":"tag_created","branch":"nightly"}`))
+		assert.Equal(t, models.EventFilterTag, resp.FilterReason)
+	})
+
+	t.Run("path filters without paths are skipped", func(t *testing.T) {
+		project := webhookTestProject()
+		project.PathFilters = []string{"services/api/**"}
+		resp := decode(t, simulate(project, `{"event_type":"push","branch":"main"}`))
+		assert.True(t, resp.WouldRun)
+		assert.Equal(t, "skipped", results(resp)["path_filters"])
+	})
+
+	t.Run("eval-less project lists its template jobs", func(t *testing.T) {
+		project := webhookTestProject()
+		project.JobTemplates = models.JobTemplates{
+			{Name: "test", Command: "make test"},
+			{Name: "deploy", Command: "make deploy", Events: []string{"tag_created"}},
+		}
+		resp := decode(t, simulate(project, `{"event_type":"push","branch":"main"}`))
+		assert.True(t, resp.WouldRun)
+		assert.Equal(t, "pass", results(resp)["job_templates"])
+		require.Len(t, resp.Jobs, 1)
+		assert.Equal(t, "test", resp.Jobs[0].Name)
+		assert.Equal(t, "make test", resp.Jobs[0].JobCommand)
+		assert.Empty(t, resp.Jobs[0].CISourceURL)
+
+		project.JobTemplates = project.JobTemplates[1:]
+		resp = decode(t, simulate(project, `{"event_type":"push","branch":"main"}`))
+		assert.False(t, resp.WouldRun)
+		assert.Equal(t, models.EventFilterNoJobTemplates, resp.FilterReason)
+	})
+
+	t.Run("invalid event", func(t *testing.T) {
+		project := webhookTestProject()
+		assert.Equal(t, http.StatusBadRequest, simulate(project, `{"event_type":"ping","branch":"main"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, simulate(project, `{"event_type":"push"}`).Code)
+	})
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/worker"
+)
+
+// createTemplateJobs creates the jobs of an eval-less project (one with
+// JobTemplates) for event, instead of the eval job evalJob. evalJob is
+// built and carries its VCS metadata, but is never stored: the templates'
+// jobs inherit its source and event variables, so they run as if it had
+// triggered them, without waiting for an eval container to start.
+func (h *WebhookHandler) createTemplateJobs(ctx context.Context, event *vcs.WebhookEvent, statusClient vcs.Client, project *models.Project, evalJob *models.Job, record *models.WebhookEvent) error {
+	templates := project.JobTemplates.For(string(event.GenericEvent))
+	if len(templates) == 0 {
+		h.logger.WithFields(logrus.Fields{
+			"project":       project.Name,
+			"generic_event": string(event.GenericEvent),
+		}).Debug("No job template runs for event")
+		record.Filter(models.EventFilterNoJobTemplates)
+		return nil
+	}
+
+	tp := worker.NewTriggerProcessor(h.store, h.corndogsClient)
+	if h.statusUpdater != nil {
+		tp.SetStatusUpdater(h.statusUpdater)
+	}
+	jobs, err := tp.CreateTemplateJobs(ctx, templates, evalJob)
+	if len(jobs) > 0 {
+		record.Status = models.WebhookEventProcessed
+		record.JobID = &jobs[0].JobID
+	}
+	if err != nil {
+		return fmt.Errorf("creating jobs from templates: %w", err)
+	}
+
+	for _, job := range jobs {
+		// Without a status updater the trigger processor can't register
+		// the jobs as pending checks, so do it here as for eval jobs.
+		if h.statusUpdater == nil {
+			h.registerPendingStatus(ctx, event, statusClient, job)
+		}
+		h.logger.WithFields(logrus.Fields{
+			"job_id":   job.JobID,
+			"job_name": job.Name,
+			"project":  project.Name,
+		}).Info("Created job from template")
+	}
+	return nil
+}
+
+// registerPendingStatus reports job as queued under its status context.
+func (h *WebhookHandler) registerPendingStatus(ctx context.Context, event *vcs.WebhookEvent, statusClient vcs.Client, job *models.Job) {
+	metadata, err := vcs.MetadataFromJob(job)
+	if err != nil || metadata == nil {
+		return
+	}
+	statusUpdate := vcs.StatusUpdate{
+		SHA:         metadata.CommitSHA,
+		State:       vcs.StatusPending,
+		TargetURL:   h.getJobURL(job.JobID),
+		Description: "CI build queued",
+		Context:     metadata.StatusContext,
+	}
+	if err := statusClient.UpdateCommitStatus(ctx, event.Repository.FullName, statusUpdate); err != nil {
+		h.logger.WithError(err).Warn("Failed to update commit status")
+	}
+}
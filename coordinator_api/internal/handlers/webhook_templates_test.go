@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookHandler_JobTemplates_ReplaceEvalJob(t *testing.T) {
+	project := webhookTestProject()
+	project.JobTemplates = models.JobTemplates{
+		{Name: "test", Command: "make test", Env: map[string]string{"GOFLAGS": "-race"}},
+		{Name: "deploy", Command: "make deploy", Image: "deploy:1", Events: []string{"push"}},
+	}
+	mockStore := &WebhookMockStore{
+		GetProjectByRepoURLFunc: func(ctx context.Context, repoURL string) (*models.Project, error) {
+			return project, nil
+		},
+	}
+	mockCorndogs := corndogs.NewMockClient()
+	handler := NewWebhookHandler(mockStore, mockCorndogs)
+	handler.SetTokenResolver(testTokenResolver())
+
+	var statuses []vcs.StatusUpdate
+	event := &vcs.WebhookEvent{
+		Provider:     vcs.GitHub,
+		EventType:    "pull_request",
+		GenericEvent: vcs.EventPullRequestOpened,
+		Repository: vcs.RepositoryInfo{
+			FullName: "test-org/test-repo",
+			CloneURL: "https://github.com/test-org/test-repo.git",
+		},
+		PullRequest: &vcs.PullRequestInfo{
+			Number:  42,
+			Action:  "opened",
+			HeadSHA: "abc123",
+			HeadRef: "feature-branch",
+			BaseRef: "main",
+		},
+	}
+	handler.AddVCSClient(vcs.GitHub, &MockVCSClient{
+		ParseWebhookFunc: func(r *http.Request) (*vcs.WebhookEvent, error) {
+			return event, nil
+		},
+		UpdateCommitStatusFunc: func(ctx context.Context, repo string, update vcs.StatusUpdate) error {
+			statuses = append(statuses, update)
+			return nil
+		},
+	})
+
+	send := func() {
+		body := makePRWebhookBody("test-org/test-repo", "https://github.com/test-org/test-repo.git", "abc123", "feature-branch", "main", 42)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/github", bytes.NewReader(body))
+		req.Header.Set("X-GitHub-Event", "pull_request")
+		w := httptest.NewRecorder()
+		handler.HandleGitHubWebhook(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+
+	t.Run("pull request runs the templates for it", func(t *testing.T) {
+		send()
+
+		require.Len(t, mockStore.CreateJobCalls, 1, "no eval job, and only the PR template")
+		job := mockStore.CreateJobCalls[0]
+		assert.Equal(t, "test", job.Name)
+		assert.Equal(t, "make test", job.JobCommand)
+		assert.Nil(t, job.ParentJobID)
+		assert.Nil(t, job.CISourceURL, "no CI source repo")
+		require.NotNil(t, job.SourceURL)
+		assert.Equal(t, "https://github.com/test-org/test-repo.git", *job.SourceURL)
+		require.NotNil(t, job.SourceRef)
+		assert.Equal(t, "abc123", *job.SourceRef)
+		assert.Equal(t, 10, job.Priority)
+		assert.Equal(t, "-race", job.JobEnvVars["GOFLAGS"])
+		assert.Equal(t, "42", job.JobEnvVars["REACTORCIDE_PR_NUMBER"])
+		assert.Equal(t, project.DefaultRunnerImage, job.RunnerImage)
+
+		metadata, err := vcs.MetadataFromJob(job)
+		require.NoError(t, err)
+		assert.False(t, metadata.IsEval)
+		assert.Equal(t, "test", metadata.StatusContext)
+		assert.Equal(t, 42, metadata.PRNumber)
+
+		require.Equal(t, 1, mockCorndogs.GetSubmitTaskCallCount())
+		require.Len(t, statuses, 1)
+		assert.Equal(t, "test", statuses[0].Context)
+		assert.Equal(t, vcs.StatusPending, statuses[0].State)
+	})
+
+	t.Run("push runs every template for it", func(t *testing.T) {
+		mockStore.CreateJobCalls = nil
+		event.GenericEvent = vcs.EventPush
+		event.EventType = "push"
+		event.PullRequest = nil
+		event.Push = &vcs.PushInfo{Ref: "refs/heads/main", After: "def456"}
+		send()
+
+		require.Len(t, mockStore.CreateJobCalls, 2)
+		assert.Equal(t, "test", mockStore.CreateJobCalls[0].Name)
+		assert.Equal(t, "deploy", mockStore.CreateJobCalls[1].Name)
+		assert.Equal(t, "deploy:1", mockStore.CreateJobCalls[1].RunnerImage)
+		assert.Equal(t, "def456", *mockStore.CreateJobCalls[1].SourceRef)
+	})
+
+	t.Run("no template runs for the event", func(t *testing.T) {
+		mockStore.CreateJobCalls = nil
+		project.JobTemplates = models.JobTemplates{{Name: "deploy", Command: "make deploy", Events: []string{"tag_created"}}}
+		send()
+		assert.Empty(t, mockStore.CreateJobCalls)
+	})
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/middleware"
+)
+
+// workerAccessPolicyFromConfig reads the worker endpoint restrictions from
+// the REACTORCIDE_WORKER_* settings. With a worker TLS port, the endpoints
+// need a verified client certificate, which only that listener asks for.
+// Invalid settings are logged and close the endpoints, rather than open
+// them; Serve refuses to start with them (see config.ValidateWorkerAccess).
+func workerAccessPolicyFromConfig() middleware.WorkerAccessPolicy {
+	if err := config.ValidateWorkerAccess(); err != nil {
+		log.Printf("WARNING: invalid worker access configuration, refusing worker requests: %v", err)
+		return middleware.WorkerAccessPolicy{RequireClientCert: true}
+	}
+	allowed, _ := config.ParseCIDRList(config.WorkerAllowedCIDRs)
+	proxies, _ := config.ParseCIDRList(config.WorkerTrustedProxies)
+	return middleware.WorkerAccessPolicy{
+		AllowedNetworks:   allowed,
+		TrustedProxies:    proxies,
+		RequireClientCert: config.WorkerTLSPort > 0,
+	}
+}
+
+// isWorkerEndpoint reports whether path is one of the endpoints workers
+// call: the chunked log upload API under /api/v1/jobs/{job_id}/logs/chunks/.
+func isWorkerEndpoint(path string) bool {
+	rest, ok := strings.CutPrefix(path, "/api/v1/jobs/")
+	return ok && strings.Contains(rest, "/logs/chunks/")
+}
+
+// WorkerEndpointsOnly serves the worker endpoints and the health check
+// through handler and answers 404 to everything else, for the worker TLS
+// listener: a client certificate gets a worker into its endpoints, not
+// the rest of the API.
+func WorkerEndpointsOnly(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/health" && !isWorkerEndpoint(r.URL.Path) {
+			http.NotFound(w, r)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerEndpointsOnly(t *testing.T) {
+	handler := WorkerEndpointsOnly(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	for path, want := range map[string]int{
+		"/api/v1/health":                               http.StatusOK,
+		"/api/v1/jobs/job-1/logs/chunks/stdout":        http.StatusOK,
+		"/api/v1/jobs/job-1/logs/chunks/stdout/3":      http.StatusOK,
+		"/api/v1/jobs/job-1/logs/chunks/stdout/commit": http.StatusOK,
+		"/api/v1/jobs/job-1/logs":                      http.StatusNotFound,
+		"/api/v1/jobs/job-1":                           http.StatusNotFound,
+		"/api/v1/admin/doctor":                         http.StatusNotFound,
+	} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, want, w.Code, path)
+	}
+}
+
+func TestWorkerAccessPolicyFromConfig(t *testing.T) {
+	origCIDRs, origPort := config.WorkerAllowedCIDRs, config.WorkerTLSPort
+	origCert, origKey, origCA := config.WorkerTLSCertFile, config.WorkerTLSKeyFile, config.WorkerTLSClientCAFile
+	defer func() {
+		config.WorkerAllowedCIDRs, config.WorkerTLSPort = origCIDRs, origPort
+		config.WorkerTLSCertFile, config.WorkerTLSKeyFile, config.WorkerTLSClientCAFile = origCert, origKey, origCA
+	}()
+
+	config.WorkerAllowedCIDRs, config.WorkerTLSPort = "", 0
+	assert.False(t, workerAccessPolicyFromConfig().Enabled())
+
+	config.WorkerAllowedCIDRs = "10.0.0.0/8"
+	policy := workerAccessPolicyFromConfig()
+	assert.Len(t, policy.AllowedNetworks, 1)
+	assert.False(t, policy.RequireClientCert)
+
+	config.WorkerTLSPort = 16443
+	config.WorkerTLSCertFile, config.WorkerTLSKeyFile, config.WorkerTLSClientCAFile = "server.crt", "server.key", "ca.crt"
+	assert.True(t, workerAccessPolicyFromConfig().RequireClientCert)
+
+	// Invalid settings close the endpoints.
+	config.WorkerTLSPort, config.WorkerAllowedCIDRs = 0, "not-a-network"
+	policy = workerAccessPolicyFromConfig()
+	assert.True(t, policy.RequireClientCert)
+}
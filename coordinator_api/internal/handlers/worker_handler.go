@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// workerStore is the narrow store capability behind the worker control
+// endpoints. See postgres_store/worker_operations.go.
+type workerStore interface {
+	ListWorkers(ctx context.Context) ([]models.Worker, error)
+	GetWorker(ctx context.Context, workerID string) (*models.Worker, error)
+	SetWorkerDesiredState(ctx context.Context, workerID, desiredState, reason string, changedBy *string) (*models.Worker, error)
+}
+
+// WorkerHandler serves the admin controls for registered workers (see
+// worker.WorkerControl).
+type WorkerHandler struct {
+	BaseHandler
+	store store.Store
+}
+
+// NewWorkerHandler creates a new worker handler.
+func NewWorkerHandler(store store.Store) *WorkerHandler {
+	return &WorkerHandler{store: store}
+}
+
+// WorkerStateRequest is the optional JSON body of the drain, quarantine and
+// resume endpoints.
+type WorkerStateRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// ListWorkersResponse is the JSON body of GET /api/v1/admin/workers.
+type ListWorkersResponse struct {
+	Workers []models.Worker `json:"workers"`
+}
+
+// ListWorkers handles GET /api/v1/admin/workers.
+func (h *WorkerHandler) ListWorkers(w http.ResponseWriter, r *http.Request) {
+	ws, ok := h.store.(workerStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("worker control not available"))
+		return
+	}
+	workers, err := ws.ListWorkers(r.Context())
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if workers == nil {
+		workers = []models.Worker{}
+	}
+	h.respondWithJSON(w, http.StatusOK, ListWorkersResponse{Workers: workers})
+}
+
+// GetWorker handles GET /api/v1/admin/workers/{worker_id}.
+func (h *WorkerHandler) GetWorker(w http.ResponseWriter, r *http.Request) {
+	ws, ok := h.store.(workerStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("worker control not available"))
+		return
+	}
+	worker, err := ws.GetWorker(r.Context(), h.getID(r, "worker_id"))
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, worker)
+}
+
+// DrainWorker handles POST /api/v1/admin/workers/{worker_id}/drain: the
+// worker finishes its running jobs and takes no new ones until it restarts
+// or is resumed.
+func (h *WorkerHandler) DrainWorker(w http.ResponseWriter, r *http.Request) {
+	h.setDesiredState(w, r, models.WorkerStateDraining)
+}
+
+// QuarantineWorker handles POST /api/v1/admin/workers/{worker_id}/quarantine:
+// like a drain, but it lasts across restarts until the worker is resumed.
+func (h *WorkerHandler) QuarantineWorker(w http.ResponseWriter, r *http.Request) {
+	h.setDesiredState(w, r, models.WorkerStateQuarantined)
+}
+
+// ResumeWorker handles POST /api/v1/admin/workers/{worker_id}/resume: the
+// worker takes jobs again.
+func (h *WorkerHandler) ResumeWorker(w http.ResponseWriter, r *http.Request) {
+	h.setDesiredState(w, r, models.WorkerStateActive)
+}
+
+func (h *WorkerHandler) setDesiredState(w http.ResponseWriter, r *http.Request, state string) {
+	ws, ok := h.store.(workerStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("worker control not available"))
+		return
+	}
+	var req WorkerStateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.respondWithError(w, http.StatusBadRequest, store.ErrInvalidInput)
+		return
+	}
+
+	var changedBy *string
+	if user := checkauth.GetUserFromContext(r.Context()); user != nil {
+		changedBy = &user.UserID
+	}
+	worker, err := ws.SetWorkerDesiredState(r.Context(), h.getID(r, "worker_id"), state, req.Reason, changedBy)
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, worker)
+}
+
+// workerDiagnosticsStaleAfter is how old a worker's diagnostics may be
+// before they're flagged stale. Workers report every 15 seconds, so a
+// stale report means the worker stopped reporting.
+const workerDiagnosticsStaleAfter = time.Minute
+
+// workerDiagnosticsStore is the narrow store capability behind the worker
+// diagnostics endpoints.
+type workerDiagnosticsStore interface {
+	GetWorkerDiagnostics(ctx context.Context, workerID string) (*models.WorkerDiagnostics, error)
+}
+
+// WorkerDiagnosticsResponse is the JSON body of GET
+// /api/v1/admin/workers/{worker_id}/diagnostics: the worker's last report,
+// without its log lines.
+type WorkerDiagnosticsResponse struct {
+	WorkerID   string                       `json:"worker_id"`
+	ReportedAt time.Time                    `json:"reported_at"`
+	Stale      bool                         `json:"stale"`
+	Jobs       []models.WorkerJobAssignment `json:"jobs"`
+	Executor   models.WorkerExecutorStatus  `json:"executor"`
+	Disk       *models.WorkerDiskUsage      `json:"disk,omitempty"`
+	Goroutines int                          `json:"goroutines"`
+}
+
+// WorkerLogsResponse is the JSON body of GET
+// /api/v1/admin/workers/{worker_id}/logs.
+type WorkerLogsResponse struct {
+	WorkerID   string                 `json:"worker_id"`
+	ReportedAt time.Time              `json:"reported_at"`
+	Stale      bool                   `json:"stale"`
+	Logs       []models.WorkerLogLine `json:"logs"`
+}
+
+func (h *WorkerHandler) workerDiagnostics(w http.ResponseWriter, r *http.Request) (*models.WorkerDiagnostics, bool) {
+	ds, ok := h.store.(workerDiagnosticsStore)
+	if !ok {
+		h.respondWithError(w, http.StatusNotImplemented, errors.New("worker diagnostics not available"))
+		return nil, false
+	}
+	diagnostics, err := ds.GetWorkerDiagnostics(r.Context(), h.getID(r, "worker_id"))
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	return diagnostics, true
+}
+
+// GetWorkerDiagnostics handles GET
+// /api/v1/admin/workers/{worker_id}/diagnostics: the jobs the worker runs,
+// its executor and image cache, and its disk, as of its last report.
+func (h *WorkerHandler) GetWorkerDiagnostics(w http.ResponseWriter, r *http.Request) {
+	diagnostics, ok := h.workerDiagnostics(w, r)
+	if !ok {
+		return
+	}
+	report := diagnostics.Report
+	if report.Jobs == nil {
+		report.Jobs = []models.WorkerJobAssignment{}
+	}
+	h.respondWithJSON(w, http.StatusOK, WorkerDiagnosticsResponse{
+		WorkerID:   diagnostics.WorkerID,
+		ReportedAt: diagnostics.ReportedAt,
+		Stale:      time.Since(diagnostics.ReportedAt) > workerDiagnosticsStaleAfter,
+		Jobs:       report.Jobs,
+		Executor:   report.Executor,
+		Disk:       report.Disk,
+		Goroutines: report.Goroutines,
+	})
+}
+
+// GetWorkerLogs handles GET /api/v1/admin/workers/{worker_id}/logs: the
+// worker's own most recent log lines as of its last report, oldest first.
+// ?lines=N returns only the last N.
+func (h *WorkerHandler) GetWorkerLogs(w http.ResponseWriter, r *http.Request) {
+	lines := 0
+	if v := r.URL.Query().Get("lines"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			h.respondWithJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid_input", Message: "lines must be a positive integer"})
+			return
+		}
+		lines = n
+	}
+	diagnostics, ok := h.workerDiagnostics(w, r)
+	if !ok {
+		return
+	}
+	logs := diagnostics.Report.Logs
+	if lines > 0 && len(logs) > lines {
+		logs = logs[len(logs)-lines:]
+	}
+	if logs == nil {
+		logs = []models.WorkerLogLine{}
+	}
+	h.respondWithJSON(w, http.StatusOK, WorkerLogsResponse{
+		WorkerID:   diagnostics.WorkerID,
+		ReportedAt: diagnostics.ReportedAt,
+		Stale:      time.Since(diagnostics.ReportedAt) > workerDiagnosticsStaleAfter,
+		Logs:       logs,
+	})
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// workerMockStore keeps registered workers in memory on top of
+// ProjectMockStore.
+type workerMockStore struct {
+	ProjectMockStore
+	workers map[string]*models.Worker
+}
+
+func (m *workerMockStore) ListWorkers(ctx context.Context) ([]models.Worker, error) {
+	var workers []models.Worker
+	for _, w := range m.workers {
+		workers = append(workers, *w)
+	}
+	return workers, nil
+}
+
+func (m *workerMockStore) GetWorker(ctx context.Context, workerID string) (*models.Worker, error) {
+	w, ok := m.workers[workerID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return w, nil
+}
+
+func (m *workerMockStore) SetWorkerDesiredState(ctx context.Context, workerID, desiredState, reason string, changedBy *string) (*models.Worker, error) {
+	w, ok := m.workers[workerID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	w.DesiredState, w.Reason, w.ChangedBy = desiredState, reason, changedBy
+	return w, nil
+}
+
+func (m *workerMockStore) GetWorkerDiagnostics(ctx context.Context, workerID string) (*models.WorkerDiagnostics, error) {
+	if _, ok := m.workers[workerID]; !ok {
+		return nil, store.ErrNotFound
+	}
+	return &models.WorkerDiagnostics{
+		WorkerID:   workerID,
+		ReportedAt: time.Now().UTC(),
+		Report: models.WorkerDiagnosticsReport{
+			Jobs:     []models.WorkerJobAssignment{{JobID: "job-1", Name: "build"}},
+			Executor: models.WorkerExecutorStatus{Runtime: "docker", Error: "Cannot connect to the Docker daemon"},
+			Logs: []models.WorkerLogLine{
+				{Level: "info", Message: "Processing task from Corndogs"},
+				{Level: "error", Message: "Failed to spawn job container"},
+			},
+		},
+	}, nil
+}
+
+func TestWorkerHandler_SetState(t *testing.T) {
+	s := &workerMockStore{workers: map[string]*models.Worker{
+		"build-1": {WorkerID: "build-1", State: models.WorkerStateActive, DesiredState: models.WorkerStateActive},
+	}}
+	h := NewWorkerHandler(s)
+	do := func(action func(http.ResponseWriter, *http.Request), workerID, body string) *httptest.ResponseRecorder {
+		req := withUser(httptest.NewRequest(http.MethodPost, "/api/v1/admin/workers/"+workerID, strings.NewReader(body)))
+		req = req.WithContext(setIDContext(req.Context(), "worker_id", workerID))
+		w := httptest.NewRecorder()
+		action(w, req)
+		return w
+	}
+
+	w := do(h.QuarantineWorker, "build-1", `{"reason":"disk errors"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var worker models.Worker
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&worker))
+	assert.Equal(t, models.WorkerStateQuarantined, worker.DesiredState)
+	assert.Equal(t, "disk errors", worker.Reason)
+	require.NotNil(t, worker.ChangedBy)
+	assert.Equal(t, "test-user-id", *worker.ChangedBy)
+
+	// The body is optional.
+	w = do(h.DrainWorker, "build-1", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, models.WorkerStateDraining, s.workers["build-1"].DesiredState)
+
+	w = do(h.ResumeWorker, "build-1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.WorkerStateActive, s.workers["build-1"].DesiredState)
+
+	assert.Equal(t, http.StatusNotFound, do(h.DrainWorker, "build-9", "").Code)
+	assert.Equal(t, http.StatusBadRequest, do(h.DrainWorker, "build-1", "{").Code)
+}
+
+func TestWorkerHandler_Diagnostics(t *testing.T) {
+	s := &workerMockStore{workers: map[string]*models.Worker{"build-1": {WorkerID: "build-1"}}}
+	h := NewWorkerHandler(s)
+	do := func(action func(http.ResponseWriter, *http.Request), workerID, query string) *httptest.ResponseRecorder {
+		req := withUser(httptest.NewRequest(http.MethodGet, "/api/v1/admin/workers/"+workerID+"/diagnostics"+query, nil))
+		req = req.WithContext(setIDContext(req.Context(), "worker_id", workerID))
+		w := httptest.NewRecorder()
+		action(w, req)
+		return w
+	}
+
+	w := do(h.GetWorkerDiagnostics, "build-1", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var diagnostics WorkerDiagnosticsResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&diagnostics))
+	assert.False(t, diagnostics.Stale)
+	assert.Equal(t, "build", diagnostics.Jobs[0].Name)
+	assert.Equal(t, "Cannot connect to the Docker daemon", diagnostics.Executor.Error)
+	assert.NotContains(t, w.Body.String(), "Processing task")
+
+	w = do(h.GetWorkerLogs, "build-1", "?lines=1")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var logs WorkerLogsResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&logs))
+	require.Len(t, logs.Logs, 1)
+	assert.Equal(t, "Failed to spawn job container", logs.Logs[0].Message)
+
+	assert.Equal(t, http.StatusBadRequest, do(h.GetWorkerLogs, "build-1", "?lines=-1").Code)
+	assert.Equal(t, http.StatusNotFound, do(h.GetWorkerDiagnostics, "build-9", "").Code)
+}
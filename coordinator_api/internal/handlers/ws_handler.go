@@ -20,9 +20,10 @@ type WSHandler struct {
 	upgrader websocket.Upgrader
 }
 
-// NewWSHandler constructs a WSHandler. The upgrader accepts any origin
-// because the webapp reverse-proxies browser WS connections via its own
-// origin — the coordinator only receives requests from the webapp.
+// NewWSHandler constructs a WSHandler. The upgrader refuses session-cookie
+// upgrades from origins the API doesn't know (see checkWebSocketOrigin);
+// a webapp that reverse-proxies browser WS connections from its own
+// origin must list it in REACTORCIDE_CORS_ALLOWED_ORIGINS.
 func NewWSHandler(bus *pubsub.Bus, s store.Store) *WSHandler {
 	return &WSHandler{
 		bus:    bus,
@@ -31,9 +32,7 @@ func NewWSHandler(bus *pubsub.Bus, s store.Store) *WSHandler {
 		upgrader: websocket.Upgrader{
 			ReadBufferSize:  1024,
 			WriteBufferSize: 1024,
-			CheckOrigin: func(r *http.Request) bool {
-				return true
-			},
+			CheckOrigin:     checkWebSocketOrigin,
 		},
 	}
 }
@@ -46,8 +45,8 @@ const (
 	wsWriteWait   = 10 * time.Second
 )
 
-// StreamAllJobs upgrades to WebSocket and sends every job-status event to
-// the client. No initial snapshot — the caller is expected to have fetched
+// StreamAllJobs upgrades to WebSocket and sends every job-status,
+// stuck-job and missed-deadline event to the client. No initial snapshot — the caller is expected to have fetched
 // the list via REST first and then uses this stream for updates.
 func (h *WSHandler) StreamAllJobs(w http.ResponseWriter, r *http.Request) {
 	ws, err := h.upgrader.Upgrade(w, r, nil)
@@ -57,9 +56,10 @@ func (h *WSHandler) StreamAllJobs(w http.ResponseWriter, r *http.Request) {
 	}
 	defer ws.Close()
 
-	// Listen for all job_update events (no per-job filter).
+	// Listen for all job_update, job_stuck and job_deadline_missed events
+	// (no per-job filter).
 	sub := h.bus.Subscribe(func(evt pubsub.Event) bool {
-		return evt.Type == pubsub.EventJobUpdate
+		return evt.Type == pubsub.EventJobUpdate || evt.Type == pubsub.EventJobStuck || evt.Type == pubsub.EventJobDeadlineMissed
 	})
 	defer h.bus.Unsubscribe(sub)
 
@@ -0,0 +1,104 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// DefaultCommandTimeout bounds a command hook's run.
+const DefaultCommandTimeout = 10 * time.Second
+
+// maxCommandReason caps how much of a command's output becomes the reason
+// for a veto.
+const maxCommandReason = 500
+
+// CommandHook runs an external command with the event as JSON on stdin. It
+// succeeds if the command exits 0; otherwise its output, stdout or else
+// stderr, is the error. A command that can't be started or that runs past
+// Timeout fails too, so at a pre_ point a broken hook vetoes rather than
+// letting everything through.
+type CommandHook struct {
+	Command string
+	Args    []string
+	Timeout time.Duration
+}
+
+// Run runs the command.
+func (c *CommandHook) Run(ctx context.Context, event *Event) error {
+	input, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultCommandTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, c.Command, c.Args...)
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if err == nil {
+		return nil
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("hook command timed out after %s", timeout)
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return fmt.Errorf("hook command failed to run: %w", err)
+	}
+	reason := strings.TrimSpace(stdout.String())
+	if reason == "" {
+		reason = strings.TrimSpace(stderr.String())
+	}
+	if reason == "" {
+		reason = fmt.Sprintf("hook command exited with status %d", exitErr.ExitCode())
+	}
+	if len(reason) > maxCommandReason {
+		reason = reason[:maxCommandReason]
+	}
+	return errors.New(reason)
+}
+
+// RegisterCommand registers commandLine, a command and its arguments
+// separated by spaces, as a hook at point.
+func RegisterCommand(point Point, commandLine string, timeout time.Duration) (func(), error) {
+	known := false
+	for _, p := range Points {
+		known = known || p == point
+	}
+	if !known {
+		return nil, fmt.Errorf("unknown hook point %q", point)
+	}
+	fields := strings.Fields(commandLine)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty hook command for %s", point)
+	}
+	hook := &CommandHook{Command: fields[0], Args: fields[1:], Timeout: timeout}
+	return Register(point, fields[0], hook), nil
+}
+
+// RegisterCommands registers the command hooks configured for each point
+// in commands, skipping points with no command.
+func RegisterCommands(commands map[Point]string, timeout time.Duration) error {
+	for _, point := range Points {
+		if strings.TrimSpace(commands[point]) == "" {
+			continue
+		}
+		if _, err := RegisterCommand(point, commands[point], timeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+// Package hooks lets a site add its own policy at points in a job's
+// lifecycle without forking: hooks compiled in and registered with
+// Register, or external commands (see RegisterCommand) that get the event
+// as JSON on stdin.
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// Point is where in a job's lifecycle a hook runs.
+type Point string
+
+// Hook points. A pre_ hook can veto what's about to happen by returning an
+// error; a post_ hook is told after the fact and its errors are only
+// logged.
+const (
+	// PreJobCreate runs before a job is saved, wherever it comes from.
+	PreJobCreate Point = "pre_job_create"
+	// PostStatusChange runs after a worker moves a job to another status.
+	PostStatusChange Point = "post_status_change"
+	// PreSecretInject runs before a job's secret references are resolved.
+	PreSecretInject Point = "pre_secret_inject"
+)
+
+// Points lists every hook point.
+var Points = []Point{PreJobCreate, PostStatusChange, PreSecretInject}
+
+// Job sources, reported in Event.Source for PreJobCreate.
+const (
+	SourceAPI        = "api"
+	SourceProjectRun = "project_run"
+	SourceWebhook    = "webhook"
+	SourceTrigger    = "trigger"
+	SourceDownstream = "downstream"
+	SourceWorkflow   = "workflow"
+	SourceRetry      = "retry"
+	SourceMergeQueue = "merge_queue"
+	SourcePreview    = "preview"
+	SourcePromotion  = "promotion"
+)
+
+// Event is what a hook is given. A compiled-in PreJobCreate hook may change
+// Job before it's saved.
+type Event struct {
+	Point Point       `json:"point"`
+	Job   *models.Job `json:"job"`
+	// Source is where a job being created comes from (PreJobCreate).
+	Source string `json:"source,omitempty"`
+	// OldStatus and NewStatus are set for PostStatusChange.
+	OldStatus string `json:"old_status,omitempty"`
+	NewStatus string `json:"new_status,omitempty"`
+	// Secrets are the references about to be resolved (PreSecretInject).
+	// They never carry secret values.
+	Secrets []SecretRef `json:"secrets,omitempty"`
+	// DryRun is set when the job is only being validated (see
+	// WithDryRun): nothing will be saved or run, so a hook shouldn't act
+	// on it beyond deciding.
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+type dryRunKey struct{}
+
+// WithDryRun marks ctx as a dry run: the events Run is given under it
+// have DryRun set.
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// IsDryRun reports whether ctx was marked with WithDryRun.
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
+
+// SecretRef is a ${secret:path:key} reference in a job's environment.
+type SecretRef struct {
+	Env  string `json:"env"`
+	Path string `json:"path"`
+	Key  string `json:"key"`
+}
+
+// Hook handles an event.
+type Hook interface {
+	Run(ctx context.Context, event *Event) error
+}
+
+// HookFunc adapts a function to a Hook.
+type HookFunc func(ctx context.Context, event *Event) error
+
+// Run calls f.
+func (f HookFunc) Run(ctx context.Context, event *Event) error {
+	return f(ctx, event)
+}
+
+// RejectedError is returned when a pre_ hook vetoes an action. It wraps
+// store.ErrForbidden and Err, the error the hook returned, so callers can
+// look for a hook's own error type.
+type RejectedError struct {
+	Point  Point
+	Hook   string
+	Reason string
+	Err    error
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("rejected by %s hook %q: %s", e.Point, e.Hook, e.Reason)
+}
+
+func (e *RejectedError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{store.ErrForbidden}
+	}
+	return []error{store.ErrForbidden, e.Err}
+}
+
+type registeredHook struct {
+	id   int
+	name string
+	hook Hook
+}
+
+var (
+	mu       sync.RWMutex
+	registry = map[Point][]registeredHook{}
+	nextID   int
+)
+
+// Register adds a hook at point, run after the ones already there. name
+// identifies it in logs and rejections. It returns a function that removes
+// the hook again.
+func Register(point Point, name string, hook Hook) func() {
+	mu.Lock()
+	defer mu.Unlock()
+	nextID++
+	id := nextID
+	registry[point] = append(registry[point], registeredHook{id: id, name: name, hook: hook})
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		hooks := registry[point]
+		for i, h := range hooks {
+			if h.id == id {
+				registry[point] = append(hooks[:i:i], hooks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Enabled reports whether any hook is registered at point.
+func Enabled(point Point) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return len(registry[point]) > 0
+}
+
+func registered(point Point) []registeredHook {
+	mu.RLock()
+	defer mu.RUnlock()
+	return append([]registeredHook(nil), registry[point]...)
+}
+
+// Run runs event's hooks in the order they were registered. At a pre_
+// point the first hook to return an error stops the rest and Run returns
+// a *RejectedError; at a post_ point every hook runs and errors are
+// logged.
+func Run(ctx context.Context, event *Event) error {
+	event.DryRun = event.DryRun || IsDryRun(ctx)
+	for _, h := range registered(event.Point) {
+		err := h.hook.Run(ctx, event)
+		if err == nil {
+			continue
+		}
+		if event.Point == PostStatusChange {
+			logging.Log.WithError(err).WithFields(map[string]interface{}{
+				"hook":   h.name,
+				"point":  event.Point,
+				"job_id": jobID(event),
+			}).Warn("Hook failed")
+			continue
+		}
+		var rejected *RejectedError
+		if errors.As(err, &rejected) {
+			return rejected
+		}
+		return &RejectedError{Point: event.Point, Hook: h.name, Reason: err.Error(), Err: err}
+	}
+	return nil
+}
+
+// CheckJobCreate runs the PreJobCreate hooks for job, created from source.
+func CheckJobCreate(ctx context.Context, job *models.Job, source string) error {
+	if !Enabled(PreJobCreate) {
+		return nil
+	}
+	return Run(ctx, &Event{Point: PreJobCreate, Job: job, Source: source})
+}
+
+// NotifyStatusChange runs the PostStatusChange hooks for job, which moved
+// from oldStatus to its current status, in the background so a slow hook
+// doesn't hold up the caller.
+func NotifyStatusChange(job *models.Job, oldStatus string) {
+	if !Enabled(PostStatusChange) || job == nil || job.Status == oldStatus {
+		return
+	}
+	snapshot := *job
+	go func() {
+		_ = Run(context.Background(), &Event{Point: PostStatusChange, Job: &snapshot, OldStatus: oldStatus, NewStatus: snapshot.Status})
+	}()
+}
+
+// CheckSecretInject runs the PreSecretInject hooks for the secret
+// references about to be resolved for job.
+func CheckSecretInject(ctx context.Context, job *models.Job, refs []SecretRef) error {
+	if !Enabled(PreSecretInject) || len(refs) == 0 {
+		return nil
+	}
+	return Run(ctx, &Event{Point: PreSecretInject, Job: job, Secrets: refs})
+}
+
+func jobID(event *Event) string {
+	if event.Job == nil {
+		return ""
+	}
+	return event.Job.JobID
+}
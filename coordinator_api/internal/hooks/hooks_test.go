@@ -0,0 +1,146 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	var calls []string
+	record := func(name string, err error) Hook {
+		return HookFunc(func(ctx context.Context, event *Event) error {
+			calls = append(calls, name)
+			return err
+		})
+	}
+
+	t.Run("pre hooks run in order and the first error vetoes", func(t *testing.T) {
+		calls = nil
+		defer Register(PreJobCreate, "first", record("first", nil))()
+		defer Register(PreJobCreate, "ticket", record("ticket", errors.New("title needs a ticket number")))()
+		defer Register(PreJobCreate, "last", record("last", nil))()
+
+		err := CheckJobCreate(context.Background(), &models.Job{JobID: "job-1"}, SourceAPI)
+		var rejected *RejectedError
+		require.True(t, errors.As(err, &rejected), err)
+		assert.Equal(t, "ticket", rejected.Hook)
+		assert.Equal(t, "title needs a ticket number", rejected.Reason)
+		assert.True(t, errors.Is(err, store.ErrForbidden))
+		assert.Equal(t, []string{"first", "ticket"}, calls)
+	})
+
+	t.Run("unregistered hooks no longer run", func(t *testing.T) {
+		calls = nil
+		unregister := Register(PreJobCreate, "gone", record("gone", errors.New("no")))
+		unregister()
+		assert.False(t, Enabled(PreJobCreate))
+		assert.NoError(t, CheckJobCreate(context.Background(), &models.Job{}, SourceAPI))
+		assert.Empty(t, calls)
+	})
+
+	t.Run("post hook errors are only logged", func(t *testing.T) {
+		calls = nil
+		defer Register(PostStatusChange, "broken", record("broken", errors.New("boom")))()
+		defer Register(PostStatusChange, "after", record("after", nil))()
+
+		assert.NoError(t, Run(context.Background(), &Event{Point: PostStatusChange, Job: &models.Job{Status: "completed"}}))
+		assert.Equal(t, []string{"broken", "after"}, calls)
+	})
+
+	t.Run("status change hooks see the transition", func(t *testing.T) {
+		events := make(chan *Event, 1)
+		defer Register(PostStatusChange, "watch", HookFunc(func(ctx context.Context, event *Event) error {
+			events <- event
+			return nil
+		}))()
+
+		job := &models.Job{JobID: "job-1", Status: "completed"}
+		NotifyStatusChange(job, "running")
+		job.Status = "archived"
+		select {
+		case event := <-events:
+			assert.Equal(t, "running", event.OldStatus)
+			assert.Equal(t, "completed", event.NewStatus)
+			assert.Equal(t, "completed", event.Job.Status, "hooks get a snapshot")
+		case <-time.After(5 * time.Second):
+			t.Fatal("status change hook never ran")
+		}
+	})
+
+	t.Run("secret hooks only run with references", func(t *testing.T) {
+		calls = nil
+		defer Register(PreSecretInject, "secrets", record("secrets", errors.New("not for this project")))()
+
+		assert.NoError(t, CheckSecretInject(context.Background(), &models.Job{}, nil))
+		err := CheckSecretInject(context.Background(), &models.Job{}, []SecretRef{{Env: "TOKEN", Path: "deploy", Key: "token"}})
+		assert.True(t, errors.Is(err, store.ErrForbidden))
+		assert.Equal(t, []string{"secrets"}, calls)
+	})
+}
+
+func TestCommandHook(t *testing.T) {
+	event := &Event{Point: PreJobCreate, Job: &models.Job{Name: "deploy"}, Source: SourceAPI}
+
+	t.Run("exit 0 allows", func(t *testing.T) {
+		hook := &CommandHook{Command: "sh", Args: []string{"-c", `grep -q '"source":"api"'`}}
+		assert.NoError(t, hook.Run(context.Background(), event))
+	})
+
+	t.Run("a failing command's output is the reason", func(t *testing.T) {
+		hook := &CommandHook{Command: "sh", Args: []string{"-c", `cat >/dev/null; echo "missing ticket number"; exit 1`}}
+		assert.EqualError(t, hook.Run(context.Background(), event), "missing ticket number")
+
+		hook = &CommandHook{Command: "sh", Args: []string{"-c", `echo "to stderr" >&2; exit 1`}}
+		assert.EqualError(t, hook.Run(context.Background(), event), "to stderr")
+
+		hook = &CommandHook{Command: "sh", Args: []string{"-c", `exit 3`}}
+		assert.EqualError(t, hook.Run(context.Background(), event), "hook command exited with status 3")
+	})
+
+	t.Run("timeouts and missing commands fail", func(t *testing.T) {
+		hook := &CommandHook{Command: "sleep", Args: []string{"5"}, Timeout: 50 * time.Millisecond}
+		err := hook.Run(context.Background(), event)
+		require.Error(t, err)
+		assert.True(t, strings.Contains(err.Error(), "timed out"), err)
+
+		hook = &CommandHook{Command: "/nonexistent/hook"}
+		assert.Error(t, hook.Run(context.Background(), event))
+	})
+}
+
+func TestRegisterCommand(t *testing.T) {
+	_, err := RegisterCommand("pre_deploy", "true", 0)
+	assert.EqualError(t, err, `unknown hook point "pre_deploy"`)
+
+	_, err = RegisterCommand(PreJobCreate, "  ", 0)
+	assert.Error(t, err)
+
+	unregister, err := RegisterCommand(PreJobCreate, "sh -c false", time.Second)
+	require.NoError(t, err)
+	defer unregister()
+	err = CheckJobCreate(context.Background(), &models.Job{}, SourceWebhook)
+	var rejected *RejectedError
+	require.True(t, errors.As(err, &rejected), err)
+	assert.Equal(t, "sh", rejected.Hook)
+}
+
+func TestRunMarksDryRuns(t *testing.T) {
+	var dryRuns []bool
+	defer Register(PreJobCreate, "observe", HookFunc(func(ctx context.Context, event *Event) error {
+		dryRuns = append(dryRuns, event.DryRun)
+		return nil
+	}))()
+
+	job := &models.Job{Name: "deploy"}
+	require.NoError(t, CheckJobCreate(context.Background(), job, SourceAPI))
+	require.NoError(t, CheckJobCreate(WithDryRun(context.Background()), job, SourceAPI))
+	assert.Equal(t, []bool{false, true}, dryRuns)
+}
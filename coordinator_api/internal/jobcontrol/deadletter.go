@@ -0,0 +1,91 @@
+package jobcontrol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/worker"
+)
+
+// ErrNotDeadlettered is returned by RequeueDeadletteredJob and
+// FailDeadletteredJob for a job that isn't (or is no longer) deadlettered.
+var ErrNotDeadlettered = errors.New("job is not deadlettered")
+
+// RequeueDeadletteredJob submits a deadlettered job to Corndogs again with
+// its delivery attempts reset, so workers claim it as if it were new. Its
+// failure history is kept. If the submission fails the job stays
+// deadlettered.
+func RequeueDeadletteredJob(ctx context.Context, st store.Store, corndogsClient corndogs.ClientInterface, job *models.Job) (*models.Job, error) {
+	if corndogsClient == nil {
+		return job, errors.New("corndogs not configured")
+	}
+	gs, ok := st.(guardedJobStore)
+	if !ok {
+		return job, errors.New("store does not support guarded job status transitions")
+	}
+	if job.Status != models.JobStatusDeadlettered {
+		return job, ErrNotDeadlettered
+	}
+
+	task, err := corndogsClient.SubmitTask(ctx, worker.BuildTaskPayload(job), int64(job.Priority))
+	if err != nil {
+		return job, fmt.Errorf("failed to resubmit to Corndogs: %w", err)
+	}
+	updated, matched, err := gs.UpdateJobStatusGuarded(ctx, job.JobID, []string{models.JobStatusDeadlettered}, func(j *models.Job) {
+		taskID := task.Uuid
+		j.Status = "submitted"
+		j.CorndogsTaskID = &taskID
+		j.DeliveryAttempts = 0
+		j.DeadletteredAt = nil
+		j.LastError = ""
+		j.WorkerID = nil
+		j.StartedAt = nil
+	})
+	if err == nil && matched {
+		return updated, nil
+	}
+	// Requeued, failed or cancelled meanwhile; take the new task back out.
+	if _, cancelErr := corndogsClient.CancelTask(ctx, task.Uuid, "submitted"); cancelErr != nil {
+		logging.Log.WithError(cancelErr).WithField("job_id", job.JobID).Warn("Failed to dequeue resubmitted task of a deadlettered job")
+	}
+	if err != nil {
+		return job, fmt.Errorf("failed to record requeued job: %w", err)
+	}
+	return job, ErrNotDeadlettered
+}
+
+// FailDeadletteredJob fails a deadlettered job for good. reason, if set, is
+// recorded in its last error.
+func FailDeadletteredJob(ctx context.Context, st store.Store, job *models.Job, reason string) (*models.Job, error) {
+	gs, ok := st.(guardedJobStore)
+	if !ok {
+		return job, errors.New("store does not support guarded job status transitions")
+	}
+	if job.Status != models.JobStatusDeadlettered {
+		return job, ErrNotDeadlettered
+	}
+
+	lastError := fmt.Sprintf("deadlettered after %d delivery attempts", job.DeliveryAttempts)
+	if reason != "" {
+		lastError += ": " + reason
+	}
+	updated, matched, err := gs.UpdateJobStatusGuarded(ctx, job.JobID, []string{models.JobStatusDeadlettered}, func(j *models.Job) {
+		now := time.Now().UTC()
+		j.Status = "failed"
+		j.LastError = lastError
+		j.CompletedAt = &now
+	})
+	if err != nil {
+		return job, fmt.Errorf("failed to fail deadlettered job: %w", err)
+	}
+	if !matched {
+		return job, ErrNotDeadlettered
+	}
+	return updated, nil
+}
@@ -0,0 +1,200 @@
+// Job deadlines. The monitor runs on every coordinator replica:
+// MarkJobDeadlineMissed's set-once semantics make exactly one replica alert
+// on each miss, and ReprioritizeJob's guarded dequeue makes exactly one
+// replica's boost stick.
+package jobcontrol
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/metrics"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/pubsub"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// maxDeadlineScan caps how many jobs one sweep inspects per pass.
+const maxDeadlineScan = 1000
+
+// deadlineStore is the narrow store capability the deadline monitor needs.
+// See postgres_store/deadline_operations.go.
+type deadlineStore interface {
+	ListMissedDeadlineJobs(ctx context.Context, since, now time.Time, limit int) ([]models.Job, error)
+	MarkJobDeadlineMissed(ctx context.Context, jobID string, at time.Time) (bool, error)
+	ListQueuedJobsNearDeadline(ctx context.Context, now, until time.Time, limit int) ([]models.Job, error)
+}
+
+// ErrNotQueued is returned by ReprioritizeJob for a job a worker has
+// already claimed, or that otherwise left the queue.
+var ErrNotQueued = errors.New("job is not queued")
+
+// DeadlineMonitorConfig configures a DeadlineMonitor.
+type DeadlineMonitorConfig struct {
+	// Interval between sweeps.
+	Interval time.Duration
+	// BoostWindow is how long before its deadline a queued job starts
+	// being boosted; zero disables boosting.
+	BoostWindow time.Duration
+	// MaxBoostPriority is the priority a queued job reaches at its
+	// deadline. Boosts rise linearly across BoostWindow and never lower a
+	// job's priority.
+	MaxBoostPriority int
+	// Lookback bounds how long after its deadline a job is still checked
+	// for a miss, e.g. across coordinator downtime.
+	Lookback time.Duration
+}
+
+// DeadlineMonitor raises the priority of queued jobs as their deadlines
+// approach, and flags jobs that haven't completed successfully by their
+// deadline: it records the miss, publishes a job_deadline_missed event,
+// and counts it in reactorcide_job_deadline_misses_total.
+type DeadlineMonitor struct {
+	store          store.Store
+	corndogsClient corndogs.ClientInterface
+	publisher      *pubsub.Publisher
+	config         DeadlineMonitorConfig
+	now            func() time.Time
+}
+
+// NewDeadlineMonitor creates a monitor. corndogsClient and publisher may be
+// nil; without Corndogs nothing is boosted.
+func NewDeadlineMonitor(st store.Store, corndogsClient corndogs.ClientInterface, publisher *pubsub.Publisher, config DeadlineMonitorConfig) *DeadlineMonitor {
+	if config.Interval <= 0 {
+		config.Interval = time.Minute
+	}
+	if config.Lookback <= 0 {
+		config.Lookback = 24 * time.Hour
+	}
+	return &DeadlineMonitor{
+		store:          st,
+		corndogsClient: corndogsClient,
+		publisher:      publisher,
+		config:         config,
+		now:            func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Start runs Sweep every Interval until ctx is done. It returns
+// immediately; a store without deadline support makes it a no-op.
+func (m *DeadlineMonitor) Start(ctx context.Context) {
+	if _, ok := m.store.(deadlineStore); !ok {
+		logging.Log.Warn("Store does not support job deadlines; deadline monitor disabled")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(m.config.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := m.Sweep(ctx); err != nil {
+					logging.Log.WithError(err).Warn("Deadline sweep failed")
+				}
+			}
+		}
+	}()
+}
+
+// Sweep flags missed deadlines, then boosts queued jobs nearing theirs.
+func (m *DeadlineMonitor) Sweep(ctx context.Context) error {
+	ds, ok := m.store.(deadlineStore)
+	if !ok {
+		return errors.New("store does not support job deadlines")
+	}
+	if err := m.flagMissed(ctx, ds); err != nil {
+		return err
+	}
+	return m.boost(ctx, ds)
+}
+
+func (m *DeadlineMonitor) flagMissed(ctx context.Context, ds deadlineStore) error {
+	now := m.now()
+	jobs, err := ds.ListMissedDeadlineJobs(ctx, now.Add(-m.config.Lookback), now, maxDeadlineScan)
+	if err != nil {
+		return err
+	}
+	for i := range jobs {
+		job := &jobs[i]
+		marked, err := ds.MarkJobDeadlineMissed(ctx, job.JobID, now)
+		if err != nil {
+			logging.Log.WithError(err).WithField("job_id", job.JobID).Warn("Failed to record missed deadline")
+			continue
+		}
+		if !marked {
+			continue
+		}
+		logging.Log.WithFields(map[string]interface{}{
+			"job_id":   job.JobID,
+			"job_name": job.Name,
+			"status":   job.Status,
+			"deadline": job.Deadline,
+		}).Warn("Job missed its deadline")
+		metrics.RecordJobDeadlineMiss(job.QueueName)
+		m.publisher.PublishJobDeadlineMissed(ctx, job.JobID, job.Status)
+	}
+	return nil
+}
+
+func (m *DeadlineMonitor) boost(ctx context.Context, ds deadlineStore) error {
+	if m.corndogsClient == nil || m.config.BoostWindow <= 0 || m.config.MaxBoostPriority <= 0 {
+		return nil
+	}
+	now := m.now()
+	jobs, err := ds.ListQueuedJobsNearDeadline(ctx, now, now.Add(m.config.BoostWindow), maxDeadlineScan)
+	if err != nil {
+		return err
+	}
+	for i := range jobs {
+		job := &jobs[i]
+		priority, ok := DeadlineBoostPriority(job, now, m.config.BoostWindow, m.config.MaxBoostPriority)
+		if !ok {
+			continue
+		}
+		logger := logging.Log.WithField("job_id", job.JobID).WithField("priority", priority)
+		if _, err := ReprioritizeJob(ctx, m.store, m.corndogsClient, job, priority); err != nil {
+			if !errors.Is(err, ErrNotQueued) {
+				logger.WithError(err).Warn("Failed to boost job nearing its deadline")
+			}
+			continue
+		}
+		logger.Info("Boosted priority of job nearing its deadline")
+		metrics.RecordJobDeadlineBoost(job.QueueName)
+	}
+	return nil
+}
+
+// DeadlineBoostPriority returns the priority a queued job should have at
+// now: rising linearly from zero at the start of window to maxPriority at
+// its deadline. ok is false unless that beats the job's priority by at
+// least a tenth of maxPriority, so a job is resubmitted at most about ten
+// times.
+func DeadlineBoostPriority(job *models.Job, now time.Time, window time.Duration, maxPriority int) (priority int, ok bool) {
+	if job.Deadline == nil || window <= 0 {
+		return 0, false
+	}
+	remaining := job.Deadline.Sub(now)
+	if remaining > window {
+		return 0, false
+	}
+	if remaining < 0 {
+		remaining = 0
+	}
+	priority = int(float64(maxPriority) * (1 - float64(remaining)/float64(window)))
+	step := maxPriority / 10
+	if step < 1 {
+		step = 1
+	}
+	return priority, priority >= job.Priority+step
+}
+
+// ReprioritizeJob moves a job still waiting in Corndogs to a new priority,
+// on the queue it is waiting in. See resubmitJob.
+func ReprioritizeJob(ctx context.Context, st store.Store, corndogsClient corndogs.ClientInterface, job *models.Job, priority int) (*models.Job, error) {
+	return resubmitJob(ctx, st, corndogsClient, job, "", priority, nil)
+}
@@ -0,0 +1,252 @@
+package jobcontrol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	pb "github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs/v1alpha1"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// deadlineMockStore layers the deadlineStore capability over
+// jobControlMockStore, selecting jobs the way deadline_operations.go does.
+type deadlineMockStore struct {
+	*jobControlMockStore
+}
+
+func (m *deadlineMockStore) ListMissedDeadlineJobs(ctx context.Context, since, now time.Time, limit int) ([]models.Job, error) {
+	var jobs []models.Job
+	for _, j := range m.jobs {
+		if j.Deadline == nil || j.DeadlineMissedAt != nil || j.Deadline.Before(since) || !j.Deadline.Before(now) {
+			continue
+		}
+		if j.Status == "completed" && j.CompletedAt != nil && !j.CompletedAt.After(*j.Deadline) {
+			continue
+		}
+		jobs = append(jobs, *j)
+	}
+	return jobs, nil
+}
+
+func (m *deadlineMockStore) MarkJobDeadlineMissed(ctx context.Context, jobID string, at time.Time) (bool, error) {
+	j, ok := m.jobs[jobID]
+	if !ok || j.DeadlineMissedAt != nil {
+		return false, nil
+	}
+	j.DeadlineMissedAt = &at
+	return true, nil
+}
+
+func (m *deadlineMockStore) ListQueuedJobsNearDeadline(ctx context.Context, now, until time.Time, limit int) ([]models.Job, error) {
+	var jobs []models.Job
+	for _, j := range m.jobs {
+		if j.Deadline == nil || j.Deadline.After(until) || (j.Status != "submitted" && j.Status != "queued") {
+			continue
+		}
+		jobs = append(jobs, *j)
+	}
+	return jobs, nil
+}
+
+var _ deadlineStore = (*deadlineMockStore)(nil)
+
+func timePtr(t time.Time) *time.Time { return &t }
+
+func TestDeadlineBoostPriority(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	window := time.Hour
+
+	tests := []struct {
+		name         string
+		deadline     *time.Time
+		priority     int
+		wantPriority int
+		wantOK       bool
+	}{
+		{name: "no deadline", deadline: nil},
+		{name: "outside window", deadline: timePtr(now.Add(2 * time.Hour))},
+		{name: "window start", deadline: timePtr(now.Add(time.Hour)), wantPriority: 0},
+		{name: "halfway", deadline: timePtr(now.Add(30 * time.Minute)), wantPriority: 50, wantOK: true},
+		{name: "past deadline", deadline: timePtr(now.Add(-time.Minute)), wantPriority: 100, wantOK: true},
+		{name: "already at priority", deadline: timePtr(now.Add(30 * time.Minute)), priority: 50, wantPriority: 50},
+		{name: "below step", deadline: timePtr(now.Add(30 * time.Minute)), priority: 45, wantPriority: 50},
+		{name: "at step", deadline: timePtr(now.Add(30 * time.Minute)), priority: 40, wantPriority: 50, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := &models.Job{Deadline: tt.deadline, Priority: tt.priority}
+			priority, ok := DeadlineBoostPriority(job, now, window, 100)
+			if ok != tt.wantOK {
+				t.Errorf("expected ok=%v, got %v", tt.wantOK, ok)
+			}
+			if tt.deadline != nil && tt.deadline.Sub(now) <= window && priority != tt.wantPriority {
+				t.Errorf("expected priority %d, got %d", tt.wantPriority, priority)
+			}
+		})
+	}
+}
+
+func TestReprioritizeJob_Resubmits(t *testing.T) {
+	taskID := "task-old"
+	job := &models.Job{JobID: "job-1", Status: "submitted", CorndogsTaskID: &taskID, Priority: 10}
+	st := newJobControlMockStore(job)
+	mockCorndogs := corndogs.NewMockClient()
+
+	var cancelled []string
+	mockCorndogs.CancelTaskFunc = func(ctx context.Context, taskID string, currentState string) (*pb.Task, error) {
+		cancelled = append(cancelled, taskID)
+		return &pb.Task{Uuid: taskID}, nil
+	}
+
+	updated, err := ReprioritizeJob(context.Background(), st, mockCorndogs, job, 80)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cancelled) != 1 || cancelled[0] != "task-old" {
+		t.Errorf("expected old task dequeued, got %v", cancelled)
+	}
+	if len(mockCorndogs.SubmitTaskCalls) != 1 || mockCorndogs.SubmitTaskCalls[0].Priority != 80 {
+		t.Fatalf("expected one resubmission at priority 80, got %+v", mockCorndogs.SubmitTaskCalls)
+	}
+	if updated.Priority != 80 {
+		t.Errorf("expected priority 80, got %d", updated.Priority)
+	}
+	if updated.CorndogsTaskID == nil || *updated.CorndogsTaskID == "task-old" {
+		t.Errorf("expected a new Corndogs task ID, got %v", updated.CorndogsTaskID)
+	}
+	if updated.Status != "submitted" {
+		t.Errorf("expected status left 'submitted', got %q", updated.Status)
+	}
+}
+
+func TestReprioritizeJob_ClaimedLeavesJobAlone(t *testing.T) {
+	taskID := "task-old"
+	job := &models.Job{JobID: "job-1", Status: "submitted", CorndogsTaskID: &taskID, Priority: 10}
+	st := newJobControlMockStore(job)
+	mockCorndogs := corndogs.NewMockClient()
+	mockCorndogs.CancelTaskFunc = func(ctx context.Context, taskID string, currentState string) (*pb.Task, error) {
+		return nil, fmt.Errorf("task already claimed")
+	}
+
+	_, err := ReprioritizeJob(context.Background(), st, mockCorndogs, job, 80)
+	if !errors.Is(err, ErrNotQueued) {
+		t.Fatalf("expected ErrNotQueued, got %v", err)
+	}
+	if len(mockCorndogs.SubmitTaskCalls) != 0 {
+		t.Errorf("expected no resubmission, got %d", len(mockCorndogs.SubmitTaskCalls))
+	}
+	stored, _ := st.GetJobByID(context.Background(), job.JobID)
+	if stored.Priority != 10 || *stored.CorndogsTaskID != "task-old" {
+		t.Errorf("expected job unchanged, got priority %d task %s", stored.Priority, *stored.CorndogsTaskID)
+	}
+}
+
+func TestReprioritizeJob_ResubmitFailureFailsJob(t *testing.T) {
+	taskID := "task-old"
+	job := &models.Job{JobID: "job-1", Status: "queued", CorndogsTaskID: &taskID}
+	st := newJobControlMockStore(job)
+	mockCorndogs := corndogs.NewMockClient()
+	mockCorndogs.CancelTaskFunc = func(ctx context.Context, taskID string, currentState string) (*pb.Task, error) {
+		return &pb.Task{Uuid: taskID}, nil
+	}
+	mockCorndogs.SubmitTaskFunc = func(ctx context.Context, payload *corndogs.TaskPayload, priority int64) (*pb.Task, error) {
+		return nil, fmt.Errorf("corndogs unavailable")
+	}
+
+	updated, err := ReprioritizeJob(context.Background(), st, mockCorndogs, job, 80)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if updated.Status != "failed" {
+		t.Errorf("expected status 'failed', got %q", updated.Status)
+	}
+}
+
+func TestDeadlineMonitorSweep(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	taskID := "task-old"
+	st := &deadlineMockStore{newJobControlMockStore(
+		// Running past its deadline: missed.
+		&models.Job{JobID: "late", Status: "running", Deadline: timePtr(now.Add(-time.Minute))},
+		// Finished before its deadline: met.
+		&models.Job{JobID: "met", Status: "completed", Deadline: timePtr(now.Add(-time.Minute)), CompletedAt: timePtr(now.Add(-time.Hour))},
+		// Queued and due in ten minutes: boosted.
+		&models.Job{JobID: "near", Status: "submitted", CorndogsTaskID: &taskID, Deadline: timePtr(now.Add(10 * time.Minute))},
+		// Queued, due tomorrow: left alone.
+		&models.Job{JobID: "far", Status: "submitted", CorndogsTaskID: &taskID, Deadline: timePtr(now.Add(24 * time.Hour))},
+	)}
+	mockCorndogs := corndogs.NewMockClient()
+	mockCorndogs.CancelTaskFunc = func(ctx context.Context, taskID string, currentState string) (*pb.Task, error) {
+		return &pb.Task{Uuid: taskID}, nil
+	}
+
+	monitor := NewDeadlineMonitor(st, mockCorndogs, nil, DeadlineMonitorConfig{
+		BoostWindow:      time.Hour,
+		MaxBoostPriority: 100,
+	})
+	monitor.now = func() time.Time { return now }
+
+	if err := monitor.Sweep(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if st.jobs["late"].DeadlineMissedAt == nil {
+		t.Error("expected late job flagged as missed")
+	}
+	if st.jobs["met"].DeadlineMissedAt != nil {
+		t.Error("expected met job not flagged")
+	}
+	if p := st.jobs["near"].Priority; p < 80 {
+		t.Errorf("expected near job boosted to at least 80, got %d", p)
+	}
+	if p := st.jobs["far"].Priority; p != 0 {
+		t.Errorf("expected far job untouched, got priority %d", p)
+	}
+
+	// A second sweep alerts on nothing new and doesn't resubmit again.
+	submits := len(mockCorndogs.SubmitTaskCalls)
+	missedAt := *st.jobs["late"].DeadlineMissedAt
+	monitor.now = func() time.Time { return now.Add(time.Second) }
+	if err := monitor.Sweep(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !st.jobs["late"].DeadlineMissedAt.Equal(missedAt) {
+		t.Error("expected miss recorded once")
+	}
+	if len(mockCorndogs.SubmitTaskCalls) != submits {
+		t.Errorf("expected no further resubmission, got %d", len(mockCorndogs.SubmitTaskCalls)-submits)
+	}
+}
+
+func TestBoostJob_RecordsBooster(t *testing.T) {
+	taskID := "task-old"
+	job := &models.Job{JobID: "job-1", Status: "queued", CorndogsTaskID: &taskID, Priority: 10}
+	st := newJobControlMockStore(job)
+	mockCorndogs := corndogs.NewMockClient()
+
+	if _, err := BoostJob(context.Background(), st, mockCorndogs, job, 10, "user-1"); !errors.Is(err, ErrNoBoost) {
+		t.Fatalf("expected ErrNoBoost at the job's own priority, got %v", err)
+	}
+	if len(mockCorndogs.SubmitTaskCalls) != 0 {
+		t.Fatalf("expected no resubmission, got %d", len(mockCorndogs.SubmitTaskCalls))
+	}
+
+	updated, err := BoostJob(context.Background(), st, mockCorndogs, job, 90, "user-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mockCorndogs.SubmitTaskCalls) != 1 || mockCorndogs.SubmitTaskCalls[0].Priority != 90 {
+		t.Fatalf("expected one resubmission at priority 90, got %+v", mockCorndogs.SubmitTaskCalls)
+	}
+	if updated.Priority != 90 {
+		t.Errorf("expected priority 90, got %d", updated.Priority)
+	}
+	if updated.BoostedBy == nil || *updated.BoostedBy != "user-1" || updated.BoostedAt == nil {
+		t.Errorf("expected boost recorded for user-1, got by %v at %v", updated.BoostedBy, updated.BoostedAt)
+	}
+}
@@ -0,0 +1,128 @@
+// Job archiving. The archiver runs on every coordinator replica; the store
+// skips rows another replica is already moving, so sweeps don't collide.
+package jobcontrol
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+)
+
+// maxJobArchiveBatches caps how many batches one sweep moves per table, so
+// a large first backlog is worked off over several sweeps.
+const maxJobArchiveBatches = 20
+
+// jobArchiveStore is the narrow store capability the job archiver needs.
+// See postgres_store/archive_operations.go.
+type jobArchiveStore interface {
+	ArchiveJobs(ctx context.Context, completedBefore time.Time, limit int) (int64, error)
+	ArchiveWebhookEvents(ctx context.Context, receivedBefore time.Time, limit int) (int64, error)
+}
+
+// JobArchiveConfig configures a JobArchiver.
+type JobArchiveConfig struct {
+	// Interval between sweeps.
+	Interval time.Duration
+	// ArchiveAfter is how long after a job finishes (or a webhook event
+	// is received) it moves to the archive tables.
+	ArchiveAfter time.Duration
+	// BatchSize is how many rows each archive statement moves.
+	BatchSize int
+}
+
+// JobArchiver periodically moves finished jobs older than ArchiveAfter
+// from jobs to jobs_archive, and old webhook events from webhook_events to
+// webhook_events_archive, keeping the hot tables small. Archived rows stay
+// readable: GetJobByID falls back to the archive, and listings include it
+// on request.
+type JobArchiver struct {
+	store  store.Store
+	config JobArchiveConfig
+	now    func() time.Time
+}
+
+// NewJobArchiver creates an archiver.
+func NewJobArchiver(st store.Store, config JobArchiveConfig) *JobArchiver {
+	if config.Interval <= 0 {
+		config.Interval = time.Hour
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 1000
+	}
+	return &JobArchiver{
+		store:  st,
+		config: config,
+		now:    func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Start runs Sweep every Interval until ctx is done. It returns
+// immediately; a store without archive support makes it a no-op.
+func (a *JobArchiver) Start(ctx context.Context) {
+	if _, ok := a.store.(jobArchiveStore); !ok {
+		logging.Log.Warn("Store does not support job archiving; job archiver disabled")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(a.config.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := a.Sweep(ctx); err != nil {
+					logging.Log.WithError(err).Warn("Job archive sweep failed")
+				}
+			}
+		}
+	}()
+}
+
+// Sweep archives jobs and then webhook events, in batches, until a batch
+// comes back short or maxJobArchiveBatches is reached.
+func (a *JobArchiver) Sweep(ctx context.Context) error {
+	as, ok := a.store.(jobArchiveStore)
+	if !ok {
+		return errors.New("store does not support job archiving")
+	}
+	if a.config.ArchiveAfter <= 0 {
+		return nil
+	}
+	cutoff := a.now().Add(-a.config.ArchiveAfter)
+
+	jobs, err := a.drain(ctx, cutoff, as.ArchiveJobs)
+	if err != nil {
+		return err
+	}
+	events, err := a.drain(ctx, cutoff, as.ArchiveWebhookEvents)
+	if err != nil {
+		return err
+	}
+	if jobs > 0 || events > 0 {
+		logging.Log.WithField("jobs", jobs).WithField("webhook_events", events).Info("Archived old jobs")
+	}
+	return nil
+}
+
+// drain calls archive in batches and returns how many rows it moved.
+func (a *JobArchiver) drain(ctx context.Context, cutoff time.Time, archive func(context.Context, time.Time, int) (int64, error)) (int64, error) {
+	var total int64
+	for i := 0; i < maxJobArchiveBatches; i++ {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		moved, err := archive(ctx, cutoff, a.config.BatchSize)
+		if err != nil {
+			return total, err
+		}
+		total += moved
+		if moved < int64(a.config.BatchSize) {
+			break
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,91 @@
+package jobcontrol
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// jobArchiveMockStore layers the jobArchiveStore capability over
+// jobControlMockStore, moving matching jobs into archived.
+type jobArchiveMockStore struct {
+	*jobControlMockStore
+	archived    map[string]*models.Job
+	jobBatches  int
+	eventCutoff time.Time
+}
+
+func (m *jobArchiveMockStore) ArchiveJobs(ctx context.Context, completedBefore time.Time, limit int) (int64, error) {
+	m.jobBatches++
+	var moved int64
+	for id, j := range m.jobs {
+		if moved == int64(limit) {
+			break
+		}
+		if j.CompletedAt == nil || !j.CompletedAt.Before(completedBefore) {
+			continue
+		}
+		m.archived[id] = j
+		delete(m.jobs, id)
+		moved++
+	}
+	return moved, nil
+}
+
+func (m *jobArchiveMockStore) ArchiveWebhookEvents(ctx context.Context, receivedBefore time.Time, limit int) (int64, error) {
+	m.eventCutoff = receivedBefore
+	return 0, nil
+}
+
+func TestJobArchiver_Sweep(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	old := now.Add(-40 * 24 * time.Hour)
+	recent := now.Add(-time.Hour)
+	ms := &jobArchiveMockStore{
+		jobControlMockStore: newJobControlMockStore(
+			&models.Job{JobID: "old-1", Status: "completed", CompletedAt: &old},
+			&models.Job{JobID: "old-2", Status: "failed", CompletedAt: &old},
+			&models.Job{JobID: "old-3", Status: "cancelled", CompletedAt: &old},
+			&models.Job{JobID: "recent", Status: "completed", CompletedAt: &recent},
+			&models.Job{JobID: "running", Status: "running"},
+		),
+		archived: map[string]*models.Job{},
+	}
+	a := NewJobArchiver(ms, JobArchiveConfig{ArchiveAfter: 30 * 24 * time.Hour, BatchSize: 2})
+	a.now = func() time.Time { return now }
+
+	if err := a.Sweep(context.Background()); err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+	if len(ms.archived) != 3 {
+		t.Errorf("expected 3 archived jobs, got %d", len(ms.archived))
+	}
+	for _, id := range []string{"recent", "running"} {
+		if _, ok := ms.jobs[id]; !ok {
+			t.Errorf("job %s should not have been archived", id)
+		}
+	}
+	// 2 + 1: the short second batch ends the sweep.
+	if ms.jobBatches != 2 {
+		t.Errorf("expected 2 batches, got %d", ms.jobBatches)
+	}
+	if want := now.Add(-30 * 24 * time.Hour); !ms.eventCutoff.Equal(want) {
+		t.Errorf("webhook event cutoff = %v, want %v", ms.eventCutoff, want)
+	}
+}
+
+func TestJobArchiver_Disabled(t *testing.T) {
+	old := time.Now().Add(-400 * 24 * time.Hour)
+	ms := &jobArchiveMockStore{
+		jobControlMockStore: newJobControlMockStore(&models.Job{JobID: "old", Status: "completed", CompletedAt: &old}),
+		archived:            map[string]*models.Job{},
+	}
+	if err := NewJobArchiver(ms, JobArchiveConfig{}).Sweep(context.Background()); err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+	if ms.jobBatches != 0 || len(ms.archived) != 0 {
+		t.Errorf("archiver with no ArchiveAfter should do nothing")
+	}
+}
@@ -0,0 +1,357 @@
+// Job data exports, for loading CI metrics into a data warehouse. The
+// exporter runs on every coordinator replica; a day's file is only written
+// when it doesn't exist yet, and replicas racing on it write the same rows.
+package jobcontrol
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// JobExportSchemaVersion is the version of JobExportRow. Columns are only
+// ever added, at the end; anything else bumps the version.
+const JobExportSchemaVersion = 1
+
+// Formats of a job export.
+const (
+	JobExportNDJSON = "ndjson"
+	JobExportCSV    = "csv"
+)
+
+// jobExportPageSize is how many jobs the exporter reads at a time.
+const jobExportPageSize = 1000
+
+// JobExportRow is one job of an export: an NDJSON line, or a CSV record
+// with JobExportColumns as its header. Times are RFC 3339 in UTC and
+// durations seconds; both are null (empty in CSV) until they apply.
+type JobExportRow struct {
+	SchemaVersion  int      `json:"schema_version"`
+	JobID          string   `json:"job_id"`
+	ProjectID      *string  `json:"project_id"`
+	Name           string   `json:"name"`
+	Status         string   `json:"status"`
+	QueueName      string   `json:"queue_name"`
+	Priority       int      `json:"priority"`
+	Environment    string   `json:"environment"`
+	VCSRepo        *string  `json:"vcs_repo"`
+	SourceRef      *string  `json:"source_ref"`
+	CommitSHA      *string  `json:"commit_sha"`
+	PRNumber       *int     `json:"pr_number"`
+	WorkerID       *string  `json:"worker_id"`
+	ExitCode       *int     `json:"exit_code"`
+	RetryCount     int      `json:"retry_count"`
+	CreatedAt      string   `json:"created_at"`
+	StartedAt      *string  `json:"started_at"`
+	CompletedAt    *string  `json:"completed_at"`
+	QueueSeconds   *float64 `json:"queue_seconds"`
+	RunSeconds     *float64 `json:"run_seconds"`
+	TotalSeconds   *float64 `json:"total_seconds"`
+	DeadlineMissed bool     `json:"deadline_missed"`
+	ParentJobID    *string  `json:"parent_job_id"`
+	WorkflowRunID  *string  `json:"workflow_run_id"`
+	UpstreamJobID  *string  `json:"upstream_job_id"`
+	Archived       bool     `json:"archived"`
+}
+
+// JobExportColumns is the CSV header of a job export, in JobExportRow's
+// field order.
+var JobExportColumns = []string{
+	"schema_version", "job_id", "project_id", "name", "status", "queue_name", "priority", "environment",
+	"vcs_repo", "source_ref", "commit_sha", "pr_number", "worker_id", "exit_code", "retry_count",
+	"created_at", "started_at", "completed_at", "queue_seconds", "run_seconds", "total_seconds",
+	"deadline_missed", "parent_job_id", "workflow_run_id", "upstream_job_id", "archived",
+}
+
+// NewJobExportRow returns the export row of job.
+func NewJobExportRow(job *models.Job) JobExportRow {
+	row := JobExportRow{
+		SchemaVersion:  JobExportSchemaVersion,
+		JobID:          job.JobID,
+		ProjectID:      job.ProjectID,
+		Name:           job.Name,
+		Status:         job.Status,
+		QueueName:      job.QueueName,
+		Priority:       job.Priority,
+		Environment:    job.Environment,
+		VCSRepo:        job.VCSRepo,
+		SourceRef:      job.SourceRef,
+		CommitSHA:      job.CommitSHA,
+		PRNumber:       job.PRNumber,
+		WorkerID:       job.WorkerID,
+		ExitCode:       job.ExitCode,
+		RetryCount:     job.RetryCount,
+		CreatedAt:      exportTime(job.CreatedAt),
+		DeadlineMissed: job.DeadlineMissedAt != nil,
+		ParentJobID:    job.ParentJobID,
+		WorkflowRunID:  job.WorkflowRunID,
+		UpstreamJobID:  job.UpstreamJobID,
+		Archived:       job.Archived,
+	}
+	if job.StartedAt != nil {
+		started := exportTime(*job.StartedAt)
+		row.StartedAt = &started
+		row.QueueSeconds = exportSeconds(job.StartedAt.Sub(job.CreatedAt))
+	}
+	if job.CompletedAt != nil {
+		completed := exportTime(*job.CompletedAt)
+		row.CompletedAt = &completed
+		row.TotalSeconds = exportSeconds(job.CompletedAt.Sub(job.CreatedAt))
+		if job.StartedAt != nil {
+			row.RunSeconds = exportSeconds(job.CompletedAt.Sub(*job.StartedAt))
+		}
+	}
+	return row
+}
+
+// Record returns the row as a CSV record, in JobExportColumns order.
+func (r JobExportRow) Record() []string {
+	return []string{
+		strconv.Itoa(r.SchemaVersion), r.JobID, exportString(r.ProjectID), r.Name, r.Status, r.QueueName,
+		strconv.Itoa(r.Priority), r.Environment, exportString(r.VCSRepo), exportString(r.SourceRef),
+		exportString(r.CommitSHA), exportInt(r.PRNumber), exportString(r.WorkerID), exportInt(r.ExitCode),
+		strconv.Itoa(r.RetryCount), r.CreatedAt, exportString(r.StartedAt), exportString(r.CompletedAt),
+		exportFloat(r.QueueSeconds), exportFloat(r.RunSeconds), exportFloat(r.TotalSeconds),
+		strconv.FormatBool(r.DeadlineMissed), exportString(r.ParentJobID), exportString(r.WorkflowRunID),
+		exportString(r.UpstreamJobID), strconv.FormatBool(r.Archived),
+	}
+}
+
+func exportTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func exportSeconds(d time.Duration) *float64 {
+	seconds := d.Seconds()
+	return &seconds
+}
+
+func exportString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func exportInt(i *int) string {
+	if i == nil {
+		return ""
+	}
+	return strconv.Itoa(*i)
+}
+
+func exportFloat(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'f', -1, 64)
+}
+
+// JobExportWriter writes jobs as export rows in one of the export formats.
+type JobExportWriter struct {
+	json *json.Encoder
+	csv  *csv.Writer
+}
+
+// NewJobExportWriter returns a writer of format, JobExportNDJSON or
+// JobExportCSV. A CSV export starts with its header.
+func NewJobExportWriter(w io.Writer, format string) (*JobExportWriter, error) {
+	switch format {
+	case JobExportNDJSON:
+		return &JobExportWriter{json: json.NewEncoder(w)}, nil
+	case JobExportCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(JobExportColumns); err != nil {
+			return nil, err
+		}
+		return &JobExportWriter{csv: cw}, nil
+	}
+	return nil, fmt.Errorf("%w: export format must be %s or %s", store.ErrInvalidInput, JobExportNDJSON, JobExportCSV)
+}
+
+// Write writes the row of job.
+func (w *JobExportWriter) Write(job *models.Job) error {
+	row := NewJobExportRow(job)
+	if w.csv != nil {
+		return w.csv.Write(row.Record())
+	}
+	return w.json.Encode(row)
+}
+
+// Flush writes out anything buffered.
+func (w *JobExportWriter) Flush() error {
+	if w.csv != nil {
+		w.csv.Flush()
+		return w.csv.Error()
+	}
+	return nil
+}
+
+// jobExportStore is the narrow store capability the job exporter needs.
+// See postgres_store/job_export_operations.go.
+type jobExportStore interface {
+	ListJobsForExport(ctx context.Context, filter store.JobExportFilter, limit int) ([]models.Job, error)
+}
+
+// JobExportConfig configures a JobExporter.
+type JobExportConfig struct {
+	// Interval between sweeps.
+	Interval time.Duration
+	// Prefix is the object store prefix exports are written under.
+	Prefix string
+	// Format is JobExportNDJSON or JobExportCSV.
+	Format string
+	// SettleAfter is how long after a (UTC) day ends its jobs are
+	// exported, so they've mostly finished by then.
+	SettleAfter time.Duration
+	// BackfillDays is how many days back each sweep looks for days not
+	// exported yet.
+	BackfillDays int
+}
+
+// JobExporter periodically exports each day's jobs, by creation time, to
+// {Prefix}jobs/date={YYYY-MM-DD}/jobs.{format} in the object store, for a
+// data warehouse to load. A day is exported once, SettleAfter after it
+// ends; a job still running then is exported as it was.
+type JobExporter struct {
+	store       store.Store
+	objectStore objects.ObjectStore
+	config      JobExportConfig
+	now         func() time.Time
+}
+
+// NewJobExporter creates an exporter.
+func NewJobExporter(st store.Store, objectStore objects.ObjectStore, config JobExportConfig) *JobExporter {
+	if config.Interval <= 0 {
+		config.Interval = time.Hour
+	}
+	if config.Format == "" {
+		config.Format = JobExportNDJSON
+	}
+	if config.BackfillDays <= 0 {
+		config.BackfillDays = 7
+	}
+	return &JobExporter{
+		store:       st,
+		objectStore: objectStore,
+		config:      config,
+		now:         func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Start runs Sweep every Interval until ctx is done. It returns
+// immediately; a store without export support makes it a no-op.
+func (e *JobExporter) Start(ctx context.Context) {
+	if _, ok := e.store.(jobExportStore); !ok {
+		logging.Log.Warn("Store does not support job exports; job exporter disabled")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(e.config.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := e.Sweep(ctx); err != nil {
+					logging.Log.WithError(err).Warn("Job export sweep failed")
+				}
+			}
+		}
+	}()
+}
+
+// Sweep exports the settled days of the last BackfillDays that haven't
+// been exported yet, oldest first.
+func (e *JobExporter) Sweep(ctx context.Context) error {
+	es, ok := e.store.(jobExportStore)
+	if !ok {
+		return errors.New("store does not support job exports")
+	}
+	if _, err := NewJobExportWriter(io.Discard, e.config.Format); err != nil {
+		return err
+	}
+	// The last settled day is the one that ended SettleAfter ago.
+	last := e.now().Add(-e.config.SettleAfter).Truncate(24*time.Hour).AddDate(0, 0, -1)
+	for i := e.config.BackfillDays - 1; i >= 0; i-- {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		day := last.AddDate(0, 0, -i)
+		key := e.Key(day)
+		exists, err := e.objectStore.Exists(ctx, key)
+		if err != nil {
+			return fmt.Errorf("failed to check job export %s: %w", key, err)
+		}
+		if exists {
+			continue
+		}
+		count, err := e.exportDay(ctx, es, day, key)
+		if err != nil {
+			return fmt.Errorf("failed to export jobs of %s: %w", day.Format("2006-01-02"), err)
+		}
+		logging.Log.WithField("key", key).WithField("jobs", count).Info("Exported jobs")
+	}
+	return nil
+}
+
+// Key returns the object key of day's export.
+func (e *JobExporter) Key(day time.Time) string {
+	return path.Join(e.config.Prefix, "jobs", "date="+day.UTC().Format("2006-01-02"), "jobs."+e.config.Format)
+}
+
+// exportDay writes the jobs created on day to key and returns how many
+// there were. The jobs are read a page at a time and the export is spooled
+// to a temporary file, so a busy day's export never sits in memory; the
+// file is also what lets a failed-over Put start again.
+func (e *JobExporter) exportDay(ctx context.Context, es jobExportStore, day time.Time, key string) (int, error) {
+	spool, err := os.CreateTemp("", "reactorcide-job-export-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create job export spool: %w", err)
+	}
+	defer func() {
+		spool.Close()
+		os.Remove(spool.Name())
+	}()
+
+	writer, err := NewJobExportWriter(spool, e.config.Format)
+	if err != nil {
+		return 0, err
+	}
+	filter := store.JobExportFilter{CreatedFrom: day, CreatedBefore: day.AddDate(0, 0, 1)}
+	it := store.NewJobIterator(store.ExportJobsPager(es, filter), jobExportPageSize)
+	count := 0
+	for it.Next(ctx) {
+		if err := writer.Write(it.Job()); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := it.Err(); err != nil {
+		return count, err
+	}
+	if err := writer.Flush(); err != nil {
+		return count, err
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return count, fmt.Errorf("failed to rewind job export spool: %w", err)
+	}
+	contentType := "application/x-ndjson"
+	if e.config.Format == JobExportCSV {
+		contentType = "text/csv"
+	}
+	return count, e.objectStore.Put(ctx, key, spool, contentType)
+}
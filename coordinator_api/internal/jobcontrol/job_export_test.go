@@ -0,0 +1,161 @@
+package jobcontrol
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// jobExportMockStore layers the jobExportStore capability over
+// jobControlMockStore.
+type jobExportMockStore struct {
+	*jobControlMockStore
+	pages int
+}
+
+func (m *jobExportMockStore) ListJobsForExport(ctx context.Context, filter store.JobExportFilter, limit int) ([]models.Job, error) {
+	m.pages++
+	var jobs []models.Job
+	for _, j := range m.jobs {
+		if j.CreatedAt.Before(filter.CreatedFrom) || !j.CreatedAt.Before(filter.CreatedBefore) {
+			continue
+		}
+		if filter.After != nil && !(j.CreatedAt.After(filter.After.CreatedAt) ||
+			(j.CreatedAt.Equal(filter.After.CreatedAt) && j.JobID > filter.After.JobID)) {
+			continue
+		}
+		jobs = append(jobs, *j)
+	}
+	sort.Slice(jobs, func(a, b int) bool {
+		if !jobs[a].CreatedAt.Equal(jobs[b].CreatedAt) {
+			return jobs[a].CreatedAt.Before(jobs[b].CreatedAt)
+		}
+		return jobs[a].JobID < jobs[b].JobID
+	})
+	if len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+	return jobs, nil
+}
+
+func TestNewJobExportRow(t *testing.T) {
+	created := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	started := created.Add(30 * time.Second)
+	completed := started.Add(90 * time.Second)
+	project, exitCode := "project-1", 1
+	row := NewJobExportRow(&models.Job{
+		JobID: "job-1", ProjectID: &project, Name: "build", Status: "failed", QueueName: "default",
+		CreatedAt: created, StartedAt: &started, CompletedAt: &completed, ExitCode: &exitCode,
+	})
+	if row.SchemaVersion != JobExportSchemaVersion || row.StartedAt == nil || *row.StartedAt != "2026-05-01T12:00:30Z" {
+		t.Fatalf("unexpected row: %+v", row)
+	}
+	if *row.QueueSeconds != 30 || *row.RunSeconds != 90 || *row.TotalSeconds != 120 {
+		t.Errorf("durations = %v/%v/%v, want 30/90/120", *row.QueueSeconds, *row.RunSeconds, *row.TotalSeconds)
+	}
+
+	record := row.Record()
+	if len(record) != len(JobExportColumns) {
+		t.Fatalf("record has %d fields, header %d", len(record), len(JobExportColumns))
+	}
+	fields := map[string]string{}
+	for i, column := range JobExportColumns {
+		fields[column] = record[i]
+	}
+	if fields["exit_code"] != "1" || fields["run_seconds"] != "90" || fields["pr_number"] != "" || fields["project_id"] != "project-1" {
+		t.Errorf("unexpected record: %v", fields)
+	}
+
+	// The CSV columns must follow the JSON field names, in order.
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(row); err != nil {
+		t.Fatal(err)
+	}
+	decoder := json.NewDecoder(&buf)
+	decoder.Token()
+	for i := 0; decoder.More(); i++ {
+		key, _ := decoder.Token()
+		if key != JobExportColumns[i] {
+			t.Fatalf("JSON field %d is %v, CSV column %s", i, key, JobExportColumns[i])
+		}
+		var value json.RawMessage
+		decoder.Decode(&value)
+	}
+
+	running := NewJobExportRow(&models.Job{JobID: "job-2", Status: "queued", CreatedAt: created})
+	if running.StartedAt != nil || running.QueueSeconds != nil || running.TotalSeconds != nil {
+		t.Errorf("unstarted job has times: %+v", running)
+	}
+}
+
+func TestJobExporter_Sweep(t *testing.T) {
+	now := time.Date(2026, 5, 4, 6, 0, 0, 0, time.UTC)
+	day := func(d, hour int) time.Time { return time.Date(2026, 5, d, hour, 0, 0, 0, time.UTC) }
+	ms := &jobExportMockStore{jobControlMockStore: newJobControlMockStore(
+		&models.Job{JobID: "a", Name: "build", Status: "completed", CreatedAt: day(1, 10)},
+		&models.Job{JobID: "b", Name: "test", Status: "failed", CreatedAt: day(2, 9)},
+		&models.Job{JobID: "c", Name: "lint", Status: "completed", CreatedAt: day(2, 9)},
+		&models.Job{JobID: "d", Name: "deploy", Status: "running", CreatedAt: day(3, 23)},
+	)}
+	objectStore := objects.NewMemoryObjectStore()
+	e := NewJobExporter(ms, objectStore, JobExportConfig{Prefix: "exports/", Format: JobExportCSV, SettleAfter: 12 * time.Hour, BackfillDays: 3})
+	e.now = func() time.Time { return now }
+
+	if err := e.Sweep(context.Background()); err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+	read := func(key string) [][]string {
+		rc, err := objectStore.Get(context.Background(), key)
+		if err != nil {
+			t.Fatalf("Get %s: %v", key, err)
+		}
+		defer rc.Close()
+		records, err := csv.NewReader(rc).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return records
+	}
+	// May 3 ended only 6h ago, under SettleAfter.
+	if ok, _ := objectStore.Exists(context.Background(), "exports/jobs/date=2026-05-03/jobs.csv"); ok {
+		t.Error("exported a day that hasn't settled")
+	}
+	records := read("exports/jobs/date=2026-05-02/jobs.csv")
+	if len(records) != 3 || strings.Join(records[0], ",") != strings.Join(JobExportColumns, ",") {
+		t.Fatalf("unexpected export: %v", records)
+	}
+	if records[1][1] != "b" || records[2][1] != "c" {
+		t.Errorf("rows out of order: %v", records[1:])
+	}
+	if records := read("exports/jobs/date=2026-05-01/jobs.csv"); len(records) != 2 || records[1][1] != "a" {
+		t.Errorf("unexpected export: %v", records)
+	}
+	if len(read("exports/jobs/date=2026-04-30/jobs.csv")) != 1 {
+		t.Error("an empty day still gets its header")
+	}
+
+	// Exported days aren't exported again.
+	ms.pages = 0
+	if err := e.Sweep(context.Background()); err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+	if ms.pages != 0 {
+		t.Errorf("re-exported days: %d pages read", ms.pages)
+	}
+}
+
+func TestNewJobExportWriter_UnknownFormat(t *testing.T) {
+	if _, err := NewJobExportWriter(io.Discard, "xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -112,9 +112,9 @@ func KillJob(ctx context.Context, st store.Store, corndogsClient corndogs.Client
 // "cancelling" itself, to allow escalating a stuck graceful cancel.
 func cancellableFromStatuses(kill bool) []string {
 	if kill {
-		return []string{"submitted", "queued", "running", "cancelling"}
+		return []string{models.JobStatusHeld, models.JobStatusDeadlettered, "submitted", "queued", "running", "cancelling"}
 	}
-	return []string{"submitted", "queued", "running"}
+	return []string{models.JobStatusHeld, models.JobStatusDeadlettered, "submitted", "queued", "running"}
 }
 
 // transitionJob drives a job into (or through) the cancel/kill flow. It
@@ -169,7 +169,7 @@ func transitionJob(ctx context.Context, st store.Store, corndogsClient corndogs.
 		return job, ErrNotCancellable
 	}
 
-	if priorStatus != "submitted" && priorStatus != "queued" {
+	if priorStatus != models.JobStatusHeld && priorStatus != models.JobStatusDeadlettered && priorStatus != "submitted" && priorStatus != "queued" {
 		// Running (or already-"cancelling", for a kill escalation): hand
 		// off to the worker. job_processor.go's cancel-poll (or, for a
 		// worker that hasn't claimed the task yet, corndogs_worker.go's
@@ -180,8 +180,9 @@ func transitionJob(ctx context.Context, st store.Store, corndogsClient corndogs.
 	// No container exists yet — try to dequeue the Corndogs task before any
 	// worker claims it. The current_state passed here is always "submitted":
 	// that's Corndogs' own pre-claim task state, independent of whether the
-	// job's DB status was "submitted" or "queued".
-	if corndogsClient != nil && updated.CorndogsTaskID != nil && *updated.CorndogsTaskID != "" {
+	// job's DB status was "submitted" or "queued". A deadlettered job's task
+	// was already failed when it was set aside.
+	if corndogsClient != nil && priorStatus != models.JobStatusDeadlettered && updated.CorndogsTaskID != nil && *updated.CorndogsTaskID != "" {
 		if _, err := corndogsClient.CancelTask(ctx, *updated.CorndogsTaskID, "submitted"); err != nil {
 			// A worker already claimed the task (or some other state change
 			// beat us to it) — leave the job "cancelling"; the claiming
@@ -218,11 +219,11 @@ func transitionJob(ctx context.Context, st store.Store, corndogsClient corndogs.
 // mocks); production always runs against postgres_store, which does.
 func transitionJobBestEffort(ctx context.Context, st store.Store, corndogsClient corndogs.ClientInterface, job *models.Job, kill bool) (*models.Job, error) {
 	switch job.Status {
-	case "submitted", "queued":
+	case models.JobStatusHeld, models.JobStatusDeadlettered, "submitted", "queued":
 		// Never started a container — nothing for the worker to do. Cancel
 		// the Corndogs task (if any was ever submitted) and land directly on
 		// the terminal "cancelled" status.
-		if corndogsClient != nil && job.CorndogsTaskID != nil && *job.CorndogsTaskID != "" {
+		if corndogsClient != nil && job.Status != models.JobStatusDeadlettered && job.CorndogsTaskID != nil && *job.CorndogsTaskID != "" {
 			if _, err := corndogsClient.CancelTask(ctx, *job.CorndogsTaskID, "submitted"); err != nil {
 				logging.Log.WithError(err).WithField("job_id", job.JobID).
 					Warn("Failed to cancel corndogs task for a not-yet-started job")
@@ -79,7 +79,7 @@ func (m *jobControlMockStore) CreateProject(ctx context.Context, project *models
 func (m *jobControlMockStore) GetProjectByID(ctx context.Context, projectID string) (*models.Project, error) {
 	return nil, nil
 }
-func (m *jobControlMockStore) GetProjectByRepoURL(ctx context.Context, repoURL string) (*models.Project, error) {
+func (m *jobControlMockStore) GetProjectsByRepoURL(ctx context.Context, repoURL string) ([]models.Project, error) {
 	return nil, nil
 }
 func (m *jobControlMockStore) UpdateProject(ctx context.Context, project *models.Project) error {
@@ -165,6 +165,27 @@ func TestCancelJob_SubmittedSuccess(t *testing.T) {
 	}
 }
 
+// TestCancelJob_Deadlettered lands a deadlettered job directly on
+// "cancelled": its task was failed when it was set aside, so there is
+// nothing in Corndogs to take back out.
+func TestCancelJob_Deadlettered(t *testing.T) {
+	taskID := "task-1"
+	job := &models.Job{JobID: "job-1", Status: models.JobStatusDeadlettered, CorndogsTaskID: &taskID}
+	st := newJobControlMockStore(job)
+	mockCorndogs := corndogs.NewMockClient()
+
+	updated, err := CancelJob(context.Background(), st, mockCorndogs, job)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Status != "cancelled" {
+		t.Errorf("expected status 'cancelled', got %q", updated.Status)
+	}
+	if mockCorndogs.GetCancelTaskCallCount() != 0 {
+		t.Errorf("expected no CancelTask call, got %d", mockCorndogs.GetCancelTaskCallCount())
+	}
+}
+
 // TestCancelJob_SubmittedClaimedRace_LeavesCancelling covers the "lost the
 // race" branch of Finding 1b: the Corndogs task was already claimed by a
 // worker (simulated by CancelTask failing), so the job must be left
@@ -0,0 +1,217 @@
+package jobcontrol
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+)
+
+// Chunked log uploads. A worker uploads each stream's log as numbered
+// chunks (JSON arrays of log entries), starting at sequence 0, then
+// commits the stream, which joins the chunks into the stream's log object
+// (logs/{job_id}/{stream}.json). Until it's committed, the chunks uploaded
+// so far serve as the job's partial log. Each chunk carries a SHA-256
+// checksum, and re-uploading a chunk with the same content is a no-op, so
+// a worker that restarts mid-job asks for LogChunkStatus and carries on
+// from NextSequence without losing or duplicating output.
+
+var (
+	ErrChunkChecksum   = errors.New("log chunk checksum mismatch")
+	ErrChunkInvalid    = errors.New("log chunk is not a JSON array")
+	ErrChunkConflict   = errors.New("log chunk already uploaded with different content")
+	ErrChunkOutOfOrder = errors.New("log chunk out of sequence")
+	ErrLogCommitted    = errors.New("log stream already committed")
+)
+
+// LogChunkState is where a stream's chunked upload stands.
+type LogChunkState struct {
+	Stream       string `json:"stream"`
+	NextSequence int    `json:"next_sequence"`
+	Bytes        int64  `json:"bytes"`
+	Committed    bool   `json:"committed"`
+}
+
+// IsLogStream reports whether stream is a log stream jobs have.
+func IsLogStream(stream string) bool {
+	return stream == "stdout" || stream == "stderr"
+}
+
+// ChunkChecksum returns the checksum uploads carry for data: its SHA-256,
+// hex encoded.
+func ChunkChecksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// PutLogChunk stores chunk sequence of the job's stream. sequence must be
+// the stream's NextSequence, or an already uploaded chunk with the same
+// content.
+func PutLogChunk(ctx context.Context, objectStore objects.ObjectStore, jobID, stream string, sequence int, data []byte, checksum string) (*LogChunkState, error) {
+	if !strings.EqualFold(ChunkChecksum(data), checksum) {
+		return nil, ErrChunkChecksum
+	}
+	var entries []json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, ErrChunkInvalid
+	}
+
+	state, chunks, err := logChunkState(ctx, objectStore, jobID, stream)
+	if err != nil {
+		return nil, err
+	}
+	if state.Committed {
+		return state, ErrLogCommitted
+	}
+	if sequence < state.NextSequence {
+		existing, err := readObject(ctx, objectStore, chunks[sequence].Key)
+		if err != nil {
+			return nil, err
+		}
+		if ChunkChecksum(existing) != ChunkChecksum(data) {
+			return state, ErrChunkConflict
+		}
+		return state, nil
+	}
+	if sequence != state.NextSequence {
+		return state, ErrChunkOutOfOrder
+	}
+
+	if err := objectStore.Put(ctx, logChunkKey(jobID, stream, sequence), bytes.NewReader(data), "application/json"); err != nil {
+		return nil, fmt.Errorf("failed to store log chunk: %w", err)
+	}
+	state.NextSequence++
+	state.Bytes += int64(len(data))
+	return state, nil
+}
+
+// LogChunkStatus returns where the job's stream upload stands.
+func LogChunkStatus(ctx context.Context, objectStore objects.ObjectStore, jobID, stream string) (*LogChunkState, error) {
+	state, _, err := logChunkState(ctx, objectStore, jobID, stream)
+	return state, err
+}
+
+// CommitLogChunks joins the stream's chunks 0..finalSequence into its log
+// object and removes them. Committing again is a no-op. finalSequence -1
+// commits a stream with no chunks as an empty log.
+func CommitLogChunks(ctx context.Context, objectStore objects.ObjectStore, jobID, stream string, finalSequence int) (*LogChunkState, error) {
+	state, chunks, err := logChunkState(ctx, objectStore, jobID, stream)
+	if err != nil {
+		return nil, err
+	}
+	if state.Committed {
+		return state, nil
+	}
+	if finalSequence != state.NextSequence-1 {
+		return state, ErrChunkOutOfOrder
+	}
+
+	joined, err := joinLogChunks(ctx, objectStore, chunks)
+	if err != nil {
+		return nil, err
+	}
+	if err := objectStore.Put(ctx, hotLogPrefix(jobID)+stream+".json", bytes.NewReader(joined), "application/json"); err != nil {
+		return nil, fmt.Errorf("failed to store log: %w", err)
+	}
+	for _, chunk := range chunks {
+		if err := objectStore.Delete(ctx, chunk.Key); err != nil && !errors.Is(err, objects.ErrNotFound) {
+			return nil, fmt.Errorf("failed to remove log chunk: %w", err)
+		}
+	}
+	return &LogChunkState{Stream: stream, NextSequence: state.NextSequence, Bytes: int64(len(joined)), Committed: true}, nil
+}
+
+// ReadLogChunks returns the stream's uncommitted chunks joined into one
+// JSON array: the partial log of a job still running. objects.ErrNotFound
+// means there are none.
+func ReadLogChunks(ctx context.Context, objectStore objects.ObjectStore, jobID, stream string) ([]byte, error) {
+	chunks, err := listLogChunks(ctx, objectStore, jobID, stream)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) == 0 {
+		return nil, objects.ErrNotFound
+	}
+	return joinLogChunks(ctx, objectStore, chunks)
+}
+
+// logChunkState works out the stream's state from its objects: its
+// contiguous chunks from 0 and whether its log object exists.
+func logChunkState(ctx context.Context, objectStore objects.ObjectStore, jobID, stream string) (*LogChunkState, []objects.ObjectInfo, error) {
+	chunks, err := listLogChunks(ctx, objectStore, jobID, stream)
+	if err != nil {
+		return nil, nil, err
+	}
+	state := &LogChunkState{Stream: stream, NextSequence: len(chunks)}
+	for _, chunk := range chunks {
+		state.Bytes += chunk.Size
+	}
+	if len(chunks) == 0 {
+		committed, err := objectStore.Exists(ctx, hotLogPrefix(jobID)+stream+".json")
+		if err != nil {
+			return nil, nil, err
+		}
+		state.Committed = committed
+	}
+	return state, chunks, nil
+}
+
+// listLogChunks returns the stream's chunks in sequence order.
+func listLogChunks(ctx context.Context, objectStore objects.ObjectStore, jobID, stream string) ([]objects.ObjectInfo, error) {
+	prefix := logChunkPrefix(jobID, stream)
+	objs, err := objectStore.List(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list log chunks: %w", err)
+	}
+	chunks := make([]objects.ObjectInfo, 0, len(objs))
+	for _, obj := range objs {
+		if _, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(obj.Key, prefix), ".json")); err == nil {
+			chunks = append(chunks, obj)
+		}
+	}
+	// Keys are zero-padded, so they sort in sequence order.
+	sort.Slice(chunks, func(i, j int) bool { return chunks[i].Key < chunks[j].Key })
+	return chunks, nil
+}
+
+func joinLogChunks(ctx context.Context, objectStore objects.ObjectStore, chunks []objects.ObjectInfo) ([]byte, error) {
+	entries := []json.RawMessage{}
+	for _, chunk := range chunks {
+		data, err := readObject(ctx, objectStore, chunk.Key)
+		if err != nil {
+			return nil, err
+		}
+		var chunkEntries []json.RawMessage
+		if err := json.Unmarshal(data, &chunkEntries); err != nil {
+			return nil, fmt.Errorf("failed to parse log chunk %s: %w", chunk.Key, err)
+		}
+		entries = append(entries, chunkEntries...)
+	}
+	return json.Marshal(entries)
+}
+
+func readObject(ctx context.Context, objectStore objects.ObjectStore, key string) ([]byte, error) {
+	reader, err := objectStore.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+func logChunkPrefix(jobID, stream string) string {
+	return hotLogPrefix(jobID) + "chunks/" + stream + "/"
+}
+
+func logChunkKey(jobID, stream string, sequence int) string {
+	return fmt.Sprintf("%s%08d.json", logChunkPrefix(jobID, stream), sequence)
+}
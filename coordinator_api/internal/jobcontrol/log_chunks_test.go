@@ -0,0 +1,115 @@
+package jobcontrol
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+)
+
+func putTestChunk(t *testing.T, objectStore objects.ObjectStore, sequence int, data string) (*LogChunkState, error) {
+	t.Helper()
+	return PutLogChunk(context.Background(), objectStore, "job-1", "stdout", sequence, []byte(data), ChunkChecksum([]byte(data)))
+}
+
+func TestLogChunks_UploadResumeAndCommit(t *testing.T) {
+	ctx := context.Background()
+	objectStore := objects.NewMemoryObjectStore()
+
+	if _, err := putTestChunk(t, objectStore, 0, `[{"message":"one"}]`); err != nil {
+		t.Fatalf("put 0: %v", err)
+	}
+	state, err := putTestChunk(t, objectStore, 1, `[{"message":"two"}]`)
+	if err != nil {
+		t.Fatalf("put 1: %v", err)
+	}
+	if state.NextSequence != 2 || state.Committed {
+		t.Errorf("expected next sequence 2, uncommitted, got %+v", state)
+	}
+
+	// A worker retrying a chunk it already uploaded gets the same state back.
+	state, err = putTestChunk(t, objectStore, 1, `[{"message":"two"}]`)
+	if err != nil || state.NextSequence != 2 {
+		t.Errorf("expected idempotent re-upload, got %+v, %v", state, err)
+	}
+
+	// The chunks so far are the job's partial log.
+	partial, err := ReadLogChunks(ctx, objectStore, "job-1", "stdout")
+	if err != nil {
+		t.Fatalf("ReadLogChunks: %v", err)
+	}
+	if string(partial) != `[{"message":"one"},{"message":"two"}]` {
+		t.Errorf("unexpected partial log %s", partial)
+	}
+
+	// A restarted worker learns where to carry on.
+	status, err := LogChunkStatus(ctx, objectStore, "job-1", "stdout")
+	if err != nil || status.NextSequence != 2 {
+		t.Fatalf("expected status next sequence 2, got %+v, %v", status, err)
+	}
+	if _, err := putTestChunk(t, objectStore, status.NextSequence, `[{"message":"three"}]`); err != nil {
+		t.Fatalf("put 2: %v", err)
+	}
+
+	state, err = CommitLogChunks(ctx, objectStore, "job-1", "stdout", 2)
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if !state.Committed {
+		t.Errorf("expected committed, got %+v", state)
+	}
+	if got := readTestObject(t, objectStore, "logs/job-1/stdout.json"); got != `[{"message":"one"},{"message":"two"},{"message":"three"}]` {
+		t.Errorf("unexpected committed log %s", got)
+	}
+	if _, err := ReadLogChunks(ctx, objectStore, "job-1", "stdout"); !errors.Is(err, objects.ErrNotFound) {
+		t.Errorf("expected chunks removed after commit, got %v", err)
+	}
+
+	// Committing again is a no-op; uploading more is refused.
+	if state, err := CommitLogChunks(ctx, objectStore, "job-1", "stdout", 2); err != nil || !state.Committed {
+		t.Errorf("expected idempotent commit, got %+v, %v", state, err)
+	}
+	if _, err := putTestChunk(t, objectStore, 3, `[]`); !errors.Is(err, ErrLogCommitted) {
+		t.Errorf("expected ErrLogCommitted, got %v", err)
+	}
+}
+
+func TestLogChunks_Rejects(t *testing.T) {
+	ctx := context.Background()
+	objectStore := objects.NewMemoryObjectStore()
+	if _, err := putTestChunk(t, objectStore, 0, `[{"message":"one"}]`); err != nil {
+		t.Fatalf("put 0: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		sequence int
+		data     string
+		checksum string
+		want     error
+	}{
+		{"checksum mismatch", 1, `[]`, ChunkChecksum([]byte(`[1]`)), ErrChunkChecksum},
+		{"not a JSON array", 1, `{"message":"x"}`, "", ErrChunkInvalid},
+		{"different content", 0, `[{"message":"changed"}]`, "", ErrChunkConflict},
+		{"gap in sequence", 5, `[]`, "", ErrChunkOutOfOrder},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checksum := tt.checksum
+			if checksum == "" {
+				checksum = ChunkChecksum([]byte(tt.data))
+			}
+			_, err := PutLogChunk(ctx, objectStore, "job-1", "stdout", tt.sequence, []byte(tt.data), checksum)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	// Committing before every chunk is uploaded is refused.
+	state, err := CommitLogChunks(ctx, objectStore, "job-1", "stdout", 3)
+	if !errors.Is(err, ErrChunkOutOfOrder) || state.NextSequence != 1 {
+		t.Errorf("expected out of order with next sequence 1, got %+v, %v", state, err)
+	}
+}
@@ -0,0 +1,276 @@
+// Log lifecycle management. The manager runs on every coordinator replica;
+// each step is idempotent, so replicas racing on the same job converge.
+package jobcontrol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// maxLogLifecycleBatch is how many jobs each step of a sweep loads at a
+// time.
+const maxLogLifecycleBatch = 500
+
+// maxLogLifecycleJobs caps how many jobs each step of one sweep handles,
+// so a large first backlog is worked off over several sweeps.
+const maxLogLifecycleJobs = 20 * maxLogLifecycleBatch
+
+// logLifecycleStore is the narrow store capability the log lifecycle
+// manager needs. See postgres_store/log_lifecycle_operations.go.
+type logLifecycleStore interface {
+	ListJobsForLogLifecycle(ctx context.Context, tiers []string, completedBefore time.Time, after *store.JobCursor, limit int) ([]models.Job, error)
+	SetJobLogsTier(ctx context.Context, jobID, tier string, bytes int64, logsObjectKey string, at time.Time) error
+}
+
+// LogLifecycleConfig configures a LogLifecycleManager.
+type LogLifecycleConfig struct {
+	// Interval between sweeps.
+	Interval time.Duration
+	// ColdAfter is how long after a job finishes its logs move to cold
+	// storage, and DeleteAfter how long until they're deleted. Zero
+	// disables the step.
+	ColdAfter   time.Duration
+	DeleteAfter time.Duration
+	// ColdStorageClass, when the object store supports storage classes
+	// (objects.StorageClassTransitioner), is the class cold logs move to,
+	// keeping their keys. Otherwise cold logs move under ColdPrefix.
+	ColdStorageClass string
+	ColdPrefix       string
+}
+
+// LogLifecycleManager periodically applies the log lifecycle rules to
+// finished jobs' logs (logs/{job_id}/ in the object store):
+//
+//   - record the size of newly finished jobs' logs, for storage usage;
+//   - move logs older than ColdAfter to cold storage;
+//   - delete logs older than DeleteAfter.
+type LogLifecycleManager struct {
+	store       store.Store
+	objectStore objects.ObjectStore
+	config      LogLifecycleConfig
+	now         func() time.Time
+}
+
+// NewLogLifecycleManager creates a manager.
+func NewLogLifecycleManager(st store.Store, objectStore objects.ObjectStore, config LogLifecycleConfig) *LogLifecycleManager {
+	if config.Interval <= 0 {
+		config.Interval = time.Hour
+	}
+	if config.ColdPrefix == "" {
+		config.ColdPrefix = "cold/"
+	}
+	return &LogLifecycleManager{
+		store:       st,
+		objectStore: objectStore,
+		config:      config,
+		now:         func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Start runs Sweep every Interval until ctx is done. It returns
+// immediately; a store without log lifecycle support makes it a no-op.
+func (m *LogLifecycleManager) Start(ctx context.Context) {
+	if _, ok := m.store.(logLifecycleStore); !ok {
+		logging.Log.Warn("Store does not support log lifecycle; log lifecycle manager disabled")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(m.config.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := m.Sweep(ctx); err != nil {
+					logging.Log.WithError(err).Warn("Log lifecycle sweep failed")
+				}
+			}
+		}
+	}()
+}
+
+// Sweep runs one pass of each lifecycle step. Deletion goes first so logs
+// due for both aren't moved only to be deleted.
+func (m *LogLifecycleManager) Sweep(ctx context.Context) error {
+	ls, ok := m.store.(logLifecycleStore)
+	if !ok {
+		return errors.New("store does not support log lifecycle")
+	}
+	now := m.now()
+
+	if m.config.DeleteAfter > 0 {
+		if err := m.step(ctx, ls, []string{"", models.LogsTierHot, models.LogsTierCold}, now.Add(-m.config.DeleteAfter), m.deleteLogs); err != nil {
+			return err
+		}
+	}
+	if m.config.ColdAfter > 0 {
+		if err := m.step(ctx, ls, []string{"", models.LogsTierHot}, now.Add(-m.config.ColdAfter), m.coolLogs); err != nil {
+			return err
+		}
+	}
+	return m.step(ctx, ls, []string{""}, now, m.measureLogs)
+}
+
+// step applies apply to the jobs in tiers that finished before cutoff,
+// recording the tier, size and key it returns. Jobs apply fails on stay
+// behind the iterator's cursor, so they don't hold up the rest.
+func (m *LogLifecycleManager) step(ctx context.Context, ls logLifecycleStore, tiers []string, cutoff time.Time, apply func(context.Context, *models.Job) (string, int64, string, error)) error {
+	it := store.NewJobIterator(func(ctx context.Context, after *store.JobCursor, limit int) ([]models.Job, error) {
+		return ls.ListJobsForLogLifecycle(ctx, tiers, cutoff, after, limit)
+	}, maxLogLifecycleBatch)
+	for handled := 0; handled < maxLogLifecycleJobs && it.Next(ctx); handled++ {
+		job := it.Job()
+		tier, bytes, key, err := apply(ctx, job)
+		if err != nil {
+			logging.Log.WithError(err).WithField("job_id", job.JobID).Warn("Failed to apply log lifecycle")
+			continue
+		}
+		if err := ls.SetJobLogsTier(ctx, job.JobID, tier, bytes, key, m.now()); err != nil && !errors.Is(err, store.ErrNotFound) {
+			return err
+		}
+	}
+	return it.Err()
+}
+
+// measureLogs records a newly finished job's logs as hot.
+func (m *LogLifecycleManager) measureLogs(ctx context.Context, job *models.Job) (string, int64, string, error) {
+	objs, err := m.objectStore.List(ctx, hotLogPrefix(job.JobID))
+	if err != nil {
+		return "", 0, "", err
+	}
+	return models.LogsTierHot, totalSize(objs), job.LogsObjectKey, nil
+}
+
+// coolLogs moves the job's logs to cold storage: another storage class if
+// configured and supported, else under ColdPrefix.
+func (m *LogLifecycleManager) coolLogs(ctx context.Context, job *models.Job) (string, int64, string, error) {
+	objs, err := m.objectStore.List(ctx, hotLogPrefix(job.JobID))
+	if err != nil {
+		return "", 0, "", err
+	}
+
+	if transitioner, ok := m.objectStore.(objects.StorageClassTransitioner); ok && m.config.ColdStorageClass != "" {
+		for _, obj := range objs {
+			if obj.StorageClass == m.config.ColdStorageClass {
+				continue
+			}
+			if err := transitioner.SetStorageClass(ctx, obj.Key, m.config.ColdStorageClass); err != nil && !errors.Is(err, objects.ErrNotFound) {
+				return "", 0, "", err
+			}
+		}
+		return models.LogsTierCold, totalSize(objs), job.LogsObjectKey, nil
+	}
+
+	for _, obj := range objs {
+		if err := m.moveObject(ctx, obj.Key, m.config.ColdPrefix+obj.Key); err != nil {
+			return "", 0, "", err
+		}
+	}
+	// Another replica may have moved them first; size what's there.
+	coldObjs, err := m.objectStore.List(ctx, m.config.ColdPrefix+hotLogPrefix(job.JobID))
+	if err != nil {
+		return "", 0, "", err
+	}
+	key := job.LogsObjectKey
+	if key != "" {
+		key = m.config.ColdPrefix + key
+	}
+	return models.LogsTierCold, totalSize(coldObjs), key, nil
+}
+
+// deleteLogs deletes the job's logs from whichever tier they're in.
+func (m *LogLifecycleManager) deleteLogs(ctx context.Context, job *models.Job) (string, int64, string, error) {
+	for _, prefix := range []string{hotLogPrefix(job.JobID), m.config.ColdPrefix + hotLogPrefix(job.JobID)} {
+		objs, err := m.objectStore.List(ctx, prefix)
+		if err != nil {
+			return "", 0, "", err
+		}
+		for _, obj := range objs {
+			if err := m.objectStore.Delete(ctx, obj.Key); err != nil && !errors.Is(err, objects.ErrNotFound) {
+				return "", 0, "", err
+			}
+		}
+	}
+	return models.LogsTierDeleted, 0, "", nil
+}
+
+// moveObject copies src to dst, then deletes src. A missing src was moved
+// already.
+func (m *LogLifecycleManager) moveObject(ctx context.Context, src, dst string) error {
+	reader, err := m.objectStore.Get(ctx, src)
+	if errors.Is(err, objects.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	if err := m.objectStore.Put(ctx, dst, reader, logContentType(src)); err != nil {
+		return fmt.Errorf("failed to copy %s: %w", src, err)
+	}
+	if err := m.objectStore.Delete(ctx, src); err != nil && !errors.Is(err, objects.ErrNotFound) {
+		return fmt.Errorf("failed to delete %s: %w", src, err)
+	}
+	return nil
+}
+
+// LogObjectKey returns the key of a stream's log ("stdout" or "stderr")
+// for job, wherever in its lifecycle the logs are.
+func LogObjectKey(job *models.Job, stream string) string {
+	key := hotLogPrefix(job.JobID) + stream + ".json"
+	if job.LogsTier == models.LogsTierCold && job.LogsObjectKey != "" {
+		key = path.Dir(job.LogsObjectKey) + "/" + stream + ".json"
+	}
+	return key
+}
+
+// DeleteJobLogs deletes the job's logs wherever in their lifecycle they
+// are, and returns how many objects it deleted.
+func DeleteJobLogs(ctx context.Context, objectStore objects.ObjectStore, job *models.Job) (int, error) {
+	prefixes := []string{hotLogPrefix(job.JobID)}
+	if job.LogsTier == models.LogsTierCold && job.LogsObjectKey != "" {
+		prefixes = append(prefixes, path.Dir(job.LogsObjectKey)+"/")
+	}
+	deleted := 0
+	for _, prefix := range prefixes {
+		objs, err := objectStore.List(ctx, prefix)
+		if err != nil {
+			return deleted, err
+		}
+		for _, obj := range objs {
+			if err := objectStore.Delete(ctx, obj.Key); err != nil && !errors.Is(err, objects.ErrNotFound) {
+				return deleted, err
+			}
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func hotLogPrefix(jobID string) string {
+	return "logs/" + jobID + "/"
+}
+
+func logContentType(key string) string {
+	if path.Ext(key) == ".json" {
+		return "application/json"
+	}
+	return "application/octet-stream"
+}
+
+func totalSize(objs []objects.ObjectInfo) int64 {
+	var size int64
+	for _, obj := range objs {
+		size += obj.Size
+	}
+	return size
+}
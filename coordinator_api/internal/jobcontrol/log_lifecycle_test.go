@@ -0,0 +1,182 @@
+package jobcontrol
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// logLifecycleMockStore layers the logLifecycleStore capability over
+// jobControlMockStore.
+type logLifecycleMockStore struct {
+	*jobControlMockStore
+}
+
+func (m *logLifecycleMockStore) ListJobsForLogLifecycle(ctx context.Context, tiers []string, completedBefore time.Time, after *store.JobCursor, limit int) ([]models.Job, error) {
+	var jobs []models.Job
+	for _, j := range m.jobs {
+		if j.CompletedAt == nil || !j.CompletedAt.Before(completedBefore) {
+			continue
+		}
+		for _, tier := range tiers {
+			if j.LogsTier == tier {
+				jobs = append(jobs, *j)
+				break
+			}
+		}
+	}
+	return jobs, nil
+}
+
+func (m *logLifecycleMockStore) SetJobLogsTier(ctx context.Context, jobID, tier string, bytes int64, logsObjectKey string, at time.Time) error {
+	j := m.jobs[jobID]
+	j.LogsTier = tier
+	j.LogsBytes = bytes
+	j.LogsObjectKey = logsObjectKey
+	j.LogsTieredAt = &at
+	return nil
+}
+
+// storageClassObjectStore adds storage classes to the memory store.
+type storageClassObjectStore struct {
+	*objects.MemoryObjectStore
+	classes map[string]string
+}
+
+func (s *storageClassObjectStore) SetStorageClass(ctx context.Context, key, storageClass string) error {
+	s.classes[key] = storageClass
+	return nil
+}
+
+func newLogLifecycleFixture(t *testing.T, objectStore objects.ObjectStore, ages map[string]time.Duration) (*logLifecycleMockStore, time.Time) {
+	t.Helper()
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	ms := &logLifecycleMockStore{newJobControlMockStore()}
+	for jobID, age := range ages {
+		completedAt := now.Add(-age)
+		ms.jobs[jobID] = &models.Job{
+			JobID:         jobID,
+			Status:        "completed",
+			CompletedAt:   &completedAt,
+			LogsObjectKey: "logs/" + jobID + "/stdout.json",
+		}
+		for _, stream := range []string{"stdout", "stderr"} {
+			if err := objectStore.Put(context.Background(), "logs/"+jobID+"/"+stream+".json", strings.NewReader(`["`+stream+`"]`), "application/json"); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return ms, now
+}
+
+func readTestObject(t *testing.T, objectStore objects.ObjectStore, key string) string {
+	t.Helper()
+	r, err := objectStore.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("get %s: %v", key, err)
+	}
+	defer r.Close()
+	data, _ := io.ReadAll(r)
+	return string(data)
+}
+
+func TestLogLifecycle_TiersByAge(t *testing.T) {
+	objectStore := objects.NewMemoryObjectStore()
+	ms, now := newLogLifecycleFixture(t, objectStore, map[string]time.Duration{
+		"fresh": time.Hour,
+		"old":   10 * 24 * time.Hour,
+		"stale": 100 * 24 * time.Hour,
+	})
+	m := NewLogLifecycleManager(ms, objectStore, LogLifecycleConfig{
+		ColdAfter:   7 * 24 * time.Hour,
+		DeleteAfter: 90 * 24 * time.Hour,
+	})
+	m.now = func() time.Time { return now }
+
+	if err := m.Sweep(context.Background()); err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+
+	fresh, old, stale := ms.jobs["fresh"], ms.jobs["old"], ms.jobs["stale"]
+	if fresh.LogsTier != models.LogsTierHot || fresh.LogsBytes != int64(len(`["stdout"]`)+len(`["stderr"]`)) {
+		t.Errorf("fresh: expected hot with both logs measured, got %q %d", fresh.LogsTier, fresh.LogsBytes)
+	}
+	if got := readTestObject(t, objectStore, LogObjectKey(fresh, "stdout")); got != `["stdout"]` {
+		t.Errorf("fresh: expected stdout readable in place, got %q", got)
+	}
+
+	if old.LogsTier != models.LogsTierCold || old.LogsObjectKey != "cold/logs/old/stdout.json" {
+		t.Errorf("old: expected cold under cold/, got %q %q", old.LogsTier, old.LogsObjectKey)
+	}
+	if exists, _ := objectStore.Exists(context.Background(), "logs/old/stderr.json"); exists {
+		t.Error("old: expected hot copy removed")
+	}
+	if got := readTestObject(t, objectStore, LogObjectKey(old, "stderr")); got != `["stderr"]` {
+		t.Errorf("old: expected stderr readable from cold storage, got %q", got)
+	}
+
+	if stale.LogsTier != models.LogsTierDeleted || stale.LogsBytes != 0 {
+		t.Errorf("stale: expected deleted, got %q %d", stale.LogsTier, stale.LogsBytes)
+	}
+	if objs, _ := objectStore.List(context.Background(), "logs/stale/"); len(objs) != 0 {
+		t.Errorf("stale: expected logs deleted, got %v", objs)
+	}
+
+	// A second sweep has nothing left to do and deletes cold logs as they age.
+	m.now = func() time.Time { return now.Add(95 * 24 * time.Hour) }
+	if err := m.Sweep(context.Background()); err != nil {
+		t.Fatalf("second Sweep: %v", err)
+	}
+	if old.LogsTier != models.LogsTierDeleted {
+		t.Errorf("old: expected deleted after 90 days, got %q", old.LogsTier)
+	}
+	if objs, _ := objectStore.List(context.Background(), "cold/logs/old/"); len(objs) != 0 {
+		t.Errorf("old: expected cold logs deleted, got %v", objs)
+	}
+}
+
+func TestLogLifecycle_StorageClassKeepsKeys(t *testing.T) {
+	objectStore := &storageClassObjectStore{MemoryObjectStore: objects.NewMemoryObjectStore(), classes: map[string]string{}}
+	ms, now := newLogLifecycleFixture(t, objectStore, map[string]time.Duration{"old": 10 * 24 * time.Hour})
+	m := NewLogLifecycleManager(ms, objectStore, LogLifecycleConfig{
+		ColdAfter:        7 * 24 * time.Hour,
+		ColdStorageClass: "STANDARD_IA",
+	})
+	m.now = func() time.Time { return now }
+
+	if err := m.Sweep(context.Background()); err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+	old := ms.jobs["old"]
+	if old.LogsTier != models.LogsTierCold || old.LogsObjectKey != "logs/old/stdout.json" {
+		t.Errorf("expected cold in place, got %q %q", old.LogsTier, old.LogsObjectKey)
+	}
+	for _, key := range []string{"logs/old/stdout.json", "logs/old/stderr.json"} {
+		if objectStore.classes[key] != "STANDARD_IA" {
+			t.Errorf("expected %s moved to STANDARD_IA, got %q", key, objectStore.classes[key])
+		}
+	}
+	if got := LogObjectKey(old, "stdout"); got != "logs/old/stdout.json" {
+		t.Errorf("expected key unchanged, got %q", got)
+	}
+}
+
+func TestLogLifecycle_DisabledStepsOnlyMeasure(t *testing.T) {
+	objectStore := objects.NewMemoryObjectStore()
+	ms, now := newLogLifecycleFixture(t, objectStore, map[string]time.Duration{"ancient": 1000 * 24 * time.Hour})
+	m := NewLogLifecycleManager(ms, objectStore, LogLifecycleConfig{})
+	m.now = func() time.Time { return now }
+
+	if err := m.Sweep(context.Background()); err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+	if tier := ms.jobs["ancient"].LogsTier; tier != models.LogsTierHot {
+		t.Errorf("expected logs kept hot with no rules, got %q", tier)
+	}
+}
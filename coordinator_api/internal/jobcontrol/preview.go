@@ -0,0 +1,149 @@
+package jobcontrol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/hooks"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/worker"
+)
+
+// ErrNotTearable is returned when a preview environment is already torn
+// down, or its teardown is still in flight.
+var ErrNotTearable = errors.New("preview environment cannot be torn down in its current state")
+
+// previewEnvironmentStore is the narrow store capability behind preview
+// environment teardown. See postgres_store/preview_environment_operations.go.
+type previewEnvironmentStore interface {
+	UpdatePreviewEnvironment(ctx context.Context, env *models.PreviewEnvironment, fromStatus string) (bool, error)
+}
+
+// TeardownPreviewEnvironment tears down a preview environment: it claims
+// the environment (active or teardown_failed → tearing_down, so concurrent
+// callers and webhook redeliveries tear it down once), creates a teardown
+// job running env.TeardownCommand, cloned from the environment's last
+// deploy job, and submits it. The worker marks the environment torn_down or
+// teardown_failed when the job finishes. An environment registered without
+// a teardown command is marked torn_down directly and no job is returned.
+//
+// A tearing_down environment whose teardown job already finished without
+// settling it (e.g. it was cancelled before it ran) may be torn down again.
+// reason becomes the teardown job's REACTORCIDE_EVENT_TYPE.
+func TeardownPreviewEnvironment(ctx context.Context, st store.Store, corndogsClient corndogs.ClientInterface, env *models.PreviewEnvironment, reason string) (*models.Job, error) {
+	ps, ok := st.(previewEnvironmentStore)
+	if !ok {
+		return nil, errors.New("preview environments not available")
+	}
+
+	fromStatus := env.Status
+	if !env.CanTearDown() && !teardownAbandoned(ctx, st, env) {
+		return nil, ErrNotTearable
+	}
+
+	if env.TeardownCommand == "" {
+		now := time.Now().UTC()
+		env.Status = models.PreviewEnvironmentTornDown
+		env.TornDownAt = &now
+		if err := claimPreviewEnvironment(ctx, ps, env, fromStatus); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	if env.DeployJobID == nil {
+		return nil, fmt.Errorf("preview environment %s has no deploy job to tear down from", env.EnvironmentID)
+	}
+	deployJob, err := st.GetJobByID(ctx, *env.DeployJobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deploy job: %w", err)
+	}
+
+	env.Status = models.PreviewEnvironmentTearingDown
+	env.TeardownJobID = nil
+	if err := claimPreviewEnvironment(ctx, ps, env, fromStatus); err != nil {
+		return nil, err
+	}
+
+	job := buildTeardownJob(deployJob, env, reason)
+	err = hooks.CheckJobCreate(ctx, job, hooks.SourcePreview)
+	if err == nil {
+		err = st.CreateJob(ctx, job)
+	}
+	if err != nil {
+		env.Status = models.PreviewEnvironmentTeardownFailed
+		_, _ = ps.UpdatePreviewEnvironment(ctx, env, models.PreviewEnvironmentTearingDown)
+		return nil, fmt.Errorf("failed to create teardown job: %w", err)
+	}
+	env.TeardownJobID = &job.JobID
+	if _, err := ps.UpdatePreviewEnvironment(ctx, env, models.PreviewEnvironmentTearingDown); err != nil {
+		return job, fmt.Errorf("failed to link teardown job: %w", err)
+	}
+
+	if err := submitNewJob(ctx, st, corndogsClient, job); err != nil {
+		return job, fmt.Errorf("failed to update teardown job after Corndogs submission: %w", err)
+	}
+	if job.Status == "failed" {
+		env.Status = models.PreviewEnvironmentTeardownFailed
+		_, _ = ps.UpdatePreviewEnvironment(ctx, env, models.PreviewEnvironmentTearingDown)
+	}
+	return job, nil
+}
+
+// claimPreviewEnvironment saves env's new status if it is still fromStatus.
+func claimPreviewEnvironment(ctx context.Context, ps previewEnvironmentStore, env *models.PreviewEnvironment, fromStatus string) error {
+	claimed, err := ps.UpdatePreviewEnvironment(ctx, env, fromStatus)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return ErrNotTearable
+	}
+	return nil
+}
+
+// teardownAbandoned reports whether env is tearing_down but its teardown
+// job reached a terminal status without the worker settling it.
+func teardownAbandoned(ctx context.Context, st store.Store, env *models.PreviewEnvironment) bool {
+	if env.Status != models.PreviewEnvironmentTearingDown || env.TeardownJobID == nil {
+		return false
+	}
+	job, err := st.GetJobByID(ctx, *env.TeardownJobID)
+	return err == nil && job.IsCompleted()
+}
+
+// buildTeardownJob clones deployJob's spec — source, CI source, image,
+// queue, env and VCS linkage — into a job that runs the environment's
+// teardown command. It's a one-off job: not part of the deploy job's
+// workflow, not awaiting children, and it posts no commit status.
+func buildTeardownJob(deployJob *models.Job, env *models.PreviewEnvironment, reason string) *models.Job {
+	job := cloneJobForRetry(deployJob)
+	job.Name = fmt.Sprintf("teardown: %s for PR #%d on %s", env.Name, env.PRNumber, env.VCSRepo)
+	job.Description = fmt.Sprintf("Tears down preview environment %s (%s)", env.Name, env.URL)
+	job.JobFile = ""
+	job.JobCommand = env.TeardownCommand
+	job.Notes = ""
+	job.RetryCount = 0
+	job.AwaitChildren = false
+	job.DebugOnFailure = false
+	job.WorkflowID = nil
+	job.WorkflowNodeID = nil
+	job.WorkflowNodeName = ""
+	job.WorkflowRunID = nil
+
+	if job.JobEnvVars == nil {
+		job.JobEnvVars = models.JSONB{}
+	}
+	job.JobEnvVars[worker.PreviewActionEnv] = worker.PreviewActionTeardown
+	job.JobEnvVars[worker.PreviewEnvironmentIDEnv] = env.EnvironmentID
+	job.JobEnvVars[worker.PreviewEnvironmentNameEnv] = env.Name
+	job.JobEnvVars[worker.PreviewEnvironmentURLEnv] = env.URL
+	if reason != "" {
+		job.JobEnvVars["REACTORCIDE_EVENT_TYPE"] = reason
+	}
+	return job
+}
@@ -0,0 +1,183 @@
+package jobcontrol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	pb "github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs/v1alpha1"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/worker"
+)
+
+// previewMockStore adds preview environments to retryMockStore, with the
+// same guarded-update semantics as the postgres store.
+type previewMockStore struct {
+	*retryMockStore
+	envs map[string]*models.PreviewEnvironment
+}
+
+func newPreviewMockStore() *previewMockStore {
+	return &previewMockStore{retryMockStore: newRetryMockStore(), envs: map[string]*models.PreviewEnvironment{}}
+}
+
+func (m *previewMockStore) addEnv(env *models.PreviewEnvironment) *models.PreviewEnvironment {
+	cp := *env
+	m.envs[env.EnvironmentID] = &cp
+	return env
+}
+
+func (m *previewMockStore) UpdatePreviewEnvironment(ctx context.Context, env *models.PreviewEnvironment, fromStatus string) (bool, error) {
+	stored, ok := m.envs[env.EnvironmentID]
+	if !ok || stored.Status != fromStatus {
+		return false, nil
+	}
+	stored.Status = env.Status
+	stored.TeardownJobID = env.TeardownJobID
+	stored.TornDownAt = env.TornDownAt
+	return true, nil
+}
+
+func newPreviewFixture() (*previewMockStore, *models.PreviewEnvironment) {
+	st := newPreviewMockStore()
+	repo := "org/repo"
+	pr := 7
+	st.addJob(&models.Job{
+		JobID:      "deploy-job",
+		UserID:     "user-1",
+		Status:     "completed",
+		JobCommand: "make deploy-preview",
+		Notes:      `{"vcs_provider":"github"}`,
+		VCSRepo:    &repo,
+		PRNumber:   &pr,
+		JobEnvVars: models.JSONB{"STAGE": "preview"},
+	})
+	deployJobID := "deploy-job"
+	env := st.addEnv(&models.PreviewEnvironment{
+		EnvironmentID:   "env-1",
+		VCSRepo:         repo,
+		PRNumber:        pr,
+		Name:            "preview",
+		URL:             "https://pr-7.preview.example.com",
+		Status:          models.PreviewEnvironmentActive,
+		DeployJobID:     &deployJobID,
+		TeardownCommand: "make destroy-preview",
+	})
+	return st, env
+}
+
+func TestTeardownPreviewEnvironment_CreatesTeardownJob(t *testing.T) {
+	st, env := newPreviewFixture()
+	mockCorndogs := corndogs.NewMockClient()
+
+	job, err := TeardownPreviewEnvironment(context.Background(), st, mockCorndogs, env, "pull_request_closed")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job == nil {
+		t.Fatal("expected a teardown job")
+	}
+	if job.JobCommand != "make destroy-preview" {
+		t.Errorf("expected teardown command, got %q", job.JobCommand)
+	}
+	if job.Notes != "" {
+		t.Errorf("expected teardown job to post no commit status, got notes %q", job.Notes)
+	}
+	if job.JobEnvVars["STAGE"] != "preview" {
+		t.Errorf("expected deploy job env to be carried over, got %v", job.JobEnvVars)
+	}
+	if job.JobEnvVars[worker.PreviewActionEnv] != worker.PreviewActionTeardown ||
+		job.JobEnvVars[worker.PreviewEnvironmentIDEnv] != "env-1" ||
+		job.JobEnvVars[worker.PreviewEnvironmentURLEnv] != "https://pr-7.preview.example.com" ||
+		job.JobEnvVars["REACTORCIDE_EVENT_TYPE"] != "pull_request_closed" {
+		t.Errorf("unexpected teardown env vars: %v", job.JobEnvVars)
+	}
+	if mockCorndogs.GetSubmitTaskCallCount() != 1 {
+		t.Errorf("expected 1 SubmitTask call, got %d", mockCorndogs.GetSubmitTaskCallCount())
+	}
+
+	stored := st.envs["env-1"]
+	if stored.Status != models.PreviewEnvironmentTearingDown {
+		t.Errorf("expected environment tearing_down, got %q", stored.Status)
+	}
+	if stored.TeardownJobID == nil || *stored.TeardownJobID != job.JobID {
+		t.Errorf("expected environment linked to teardown job %s, got %v", job.JobID, stored.TeardownJobID)
+	}
+
+	// A redelivered close webhook doesn't tear it down twice.
+	again := *stored
+	if _, err := TeardownPreviewEnvironment(context.Background(), st, mockCorndogs, &again, "pull_request_closed"); !errors.Is(err, ErrNotTearable) {
+		t.Errorf("expected ErrNotTearable on second teardown, got %v", err)
+	}
+	if mockCorndogs.GetSubmitTaskCallCount() != 1 {
+		t.Errorf("expected no further SubmitTask calls, got %d", mockCorndogs.GetSubmitTaskCallCount())
+	}
+}
+
+func TestTeardownPreviewEnvironment_NoCommand(t *testing.T) {
+	st, env := newPreviewFixture()
+	env.TeardownCommand = ""
+	mockCorndogs := corndogs.NewMockClient()
+
+	job, err := TeardownPreviewEnvironment(context.Background(), st, mockCorndogs, env, "manual")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job != nil {
+		t.Errorf("expected no teardown job, got %+v", job)
+	}
+	if st.envs["env-1"].Status != models.PreviewEnvironmentTornDown || st.envs["env-1"].TornDownAt == nil {
+		t.Errorf("expected environment torn_down, got %+v", st.envs["env-1"])
+	}
+	if mockCorndogs.GetSubmitTaskCallCount() != 0 {
+		t.Errorf("expected no SubmitTask calls, got %d", mockCorndogs.GetSubmitTaskCallCount())
+	}
+}
+
+func TestTeardownPreviewEnvironment_SubmitFailure(t *testing.T) {
+	st, env := newPreviewFixture()
+	mockCorndogs := corndogs.NewMockClient()
+	mockCorndogs.SubmitTaskFunc = func(ctx context.Context, payload *corndogs.TaskPayload, priority int64) (*pb.Task, error) {
+		return nil, fmt.Errorf("queue unavailable")
+	}
+
+	job, err := TeardownPreviewEnvironment(context.Background(), st, mockCorndogs, env, "manual")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job.Status != "failed" {
+		t.Errorf("expected teardown job failed, got %q", job.Status)
+	}
+	if st.envs["env-1"].Status != models.PreviewEnvironmentTeardownFailed {
+		t.Errorf("expected environment teardown_failed, got %q", st.envs["env-1"].Status)
+	}
+
+	// A failed teardown can be retried.
+	retry := *st.envs["env-1"]
+	mockCorndogs.SubmitTaskFunc = nil
+	if _, err := TeardownPreviewEnvironment(context.Background(), st, mockCorndogs, &retry, "manual"); err != nil {
+		t.Fatalf("expected retried teardown to succeed, got %v", err)
+	}
+	if st.envs["env-1"].Status != models.PreviewEnvironmentTearingDown {
+		t.Errorf("expected environment tearing_down after retry, got %q", st.envs["env-1"].Status)
+	}
+}
+
+func TestTeardownPreviewEnvironment_Abandoned(t *testing.T) {
+	st, env := newPreviewFixture()
+	st.addJob(&models.Job{JobID: "old-teardown", Status: "cancelled"})
+	oldTeardown := "old-teardown"
+	env.Status = models.PreviewEnvironmentTearingDown
+	env.TeardownJobID = &oldTeardown
+	st.addEnv(env)
+
+	job, err := TeardownPreviewEnvironment(context.Background(), st, corndogs.NewMockClient(), env, "manual")
+	if err != nil {
+		t.Fatalf("expected abandoned teardown to be restartable, got %v", err)
+	}
+	if job == nil || job.JobID == oldTeardown {
+		t.Fatalf("expected a new teardown job, got %+v", job)
+	}
+}
@@ -0,0 +1,235 @@
+package jobcontrol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/hooks"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// Env vars set on promoted jobs.
+const (
+	PromotionIDEnv    = "REACTORCIDE_PROMOTION_ID"
+	PromotedFromEnv   = "REACTORCIDE_PROMOTED_FROM"
+	ArtifactDigestEnv = "REACTORCIDE_ARTIFACT_DIGEST"
+)
+
+// ErrNotPromotable is returned when a job can't be promoted: it isn't a
+// completed deploy job, or the project's promotion rules don't allow its
+// environment to be promoted to the target.
+var ErrNotPromotable = errors.New("job cannot be promoted")
+
+// ErrPromotionClosed is returned when approving or rejecting a promotion
+// that is no longer pending.
+var ErrPromotionClosed = errors.New("promotion is no longer pending")
+
+// ErrSelfApproval is returned when a promotion's requester approves it.
+var ErrSelfApproval = errors.New("a promotion can't be approved by its requester")
+
+// ErrAlreadyApproved is returned when a user approves a promotion twice.
+var ErrAlreadyApproved = errors.New("promotion already approved by this user")
+
+// promotionStore is the narrow store capability behind promotions. See
+// postgres_store/promotion_operations.go.
+type promotionStore interface {
+	CreatePromotion(ctx context.Context, promotion *models.Promotion) error
+	GetPromotion(ctx context.Context, promotionID string) (*models.Promotion, error)
+	GetPromotionByJob(ctx context.Context, jobID string) (*models.Promotion, error)
+	AddPromotionApproval(ctx context.Context, promotionID string, approval models.PromotionApproval) (bool, error)
+	UpdatePromotion(ctx context.Context, promotion *models.Promotion, fromStatus string) (bool, error)
+}
+
+// RequestPromotion asks for source, a completed deploy job of project, to
+// be rerun against environment. The artifact digest, when not given, is
+// the one source was itself promoted with, or else its commit. The
+// promotion needs the approvals the project's rule for environment asks
+// for; with none it runs straight away, and the promoted job is returned.
+func RequestPromotion(ctx context.Context, st store.Store, corndogsClient corndogs.ClientInterface, project *models.Project, source *models.Job, environment, artifactDigest, userID string) (*models.Promotion, *models.Job, error) {
+	ps, ok := st.(promotionStore)
+	if !ok {
+		return nil, nil, errors.New("promotions not available")
+	}
+	if err := models.ValidateEnvironmentName(environment); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", store.ErrInvalidInput, err)
+	}
+	switch {
+	case source.Environment == "":
+		return nil, nil, fmt.Errorf("%w: it isn't a deploy job", ErrNotPromotable)
+	case source.Status != "completed":
+		return nil, nil, fmt.Errorf("%w: it is %s, not completed", ErrNotPromotable, source.Status)
+	case source.Environment == environment:
+		return nil, nil, fmt.Errorf("%w: it already deployed to %s", ErrNotPromotable, environment)
+	}
+	rule := project.PromotionRules.Rule(environment)
+	if !rule.AllowsFrom(source.Environment) {
+		return nil, nil, fmt.Errorf("%w: %s can't be promoted to %s", ErrNotPromotable, source.Environment, environment)
+	}
+
+	if artifactDigest == "" {
+		if previous, err := ps.GetPromotionByJob(ctx, source.JobID); err == nil {
+			artifactDigest = previous.ArtifactDigest
+		} else if source.CommitSHA != nil {
+			artifactDigest = *source.CommitSHA
+		}
+	}
+	if artifactDigest == "" {
+		return nil, nil, fmt.Errorf("%w: artifact_digest is required", store.ErrInvalidInput)
+	}
+
+	promotion := &models.Promotion{
+		ProjectID:         project.ProjectID,
+		SourceJobID:       source.JobID,
+		FromEnvironment:   source.Environment,
+		ToEnvironment:     environment,
+		ArtifactDigest:    artifactDigest,
+		Status:            models.PromotionPending,
+		ApprovalsRequired: rule.Approvals,
+		Approvals:         models.PromotionApprovals{},
+		RequestedBy:       userID,
+		CreatedAt:         time.Now().UTC(),
+	}
+	if err := ps.CreatePromotion(ctx, promotion); err != nil {
+		return nil, nil, err
+	}
+	if !promotion.Approved() {
+		return promotion, nil, nil
+	}
+	job, err := runPromotion(ctx, st, ps, corndogsClient, promotion, source, userID)
+	return promotion, job, err
+}
+
+// ApprovePromotion records userID's approval of a pending promotion, other
+// than by its requester. The approval that completes it runs it, and the
+// promoted job is returned.
+func ApprovePromotion(ctx context.Context, st store.Store, corndogsClient corndogs.ClientInterface, promotion *models.Promotion, userID string) (*models.Job, error) {
+	ps, ok := st.(promotionStore)
+	if !ok {
+		return nil, errors.New("promotions not available")
+	}
+	switch {
+	case promotion.Status != models.PromotionPending:
+		return nil, ErrPromotionClosed
+	case promotion.RequestedBy == userID:
+		return nil, ErrSelfApproval
+	case promotion.Approvals.Includes(userID):
+		return nil, ErrAlreadyApproved
+	}
+	added, err := ps.AddPromotionApproval(ctx, promotion.PromotionID, models.PromotionApproval{UserID: userID, ApprovedAt: time.Now().UTC()})
+	if err != nil {
+		return nil, err
+	}
+	reloaded, err := ps.GetPromotion(ctx, promotion.PromotionID)
+	if err != nil {
+		return nil, err
+	}
+	*promotion = *reloaded
+	if !added {
+		if promotion.Approvals.Includes(userID) {
+			return nil, ErrAlreadyApproved
+		}
+		return nil, ErrPromotionClosed
+	}
+	if !promotion.Approved() {
+		return nil, nil
+	}
+	source, err := st.GetJobByID(ctx, promotion.SourceJobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source job: %w", err)
+	}
+	return runPromotion(ctx, st, ps, corndogsClient, promotion, source, userID)
+}
+
+// RejectPromotion closes a pending promotion without running it.
+func RejectPromotion(ctx context.Context, st store.Store, promotion *models.Promotion, userID, reason string) error {
+	ps, ok := st.(promotionStore)
+	if !ok {
+		return errors.New("promotions not available")
+	}
+	if promotion.Status != models.PromotionPending {
+		return ErrPromotionClosed
+	}
+	now := time.Now().UTC()
+	promotion.Status = models.PromotionRejected
+	promotion.DecidedBy = &userID
+	promotion.DecidedAt = &now
+	promotion.Reason = reason
+	updated, err := ps.UpdatePromotion(ctx, promotion, models.PromotionPending)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return ErrPromotionClosed
+	}
+	return nil
+}
+
+// runPromotion claims an approved promotion (pending → promoted, so it
+// runs once however many approvals race), creates its job from source and
+// submits it. A job that can't be created marks the promotion failed.
+func runPromotion(ctx context.Context, st store.Store, ps promotionStore, corndogsClient corndogs.ClientInterface, promotion *models.Promotion, source *models.Job, decidedBy string) (*models.Job, error) {
+	now := time.Now().UTC()
+	promotion.Status = models.PromotionPromoted
+	promotion.DecidedBy = &decidedBy
+	promotion.DecidedAt = &now
+	claimed, err := ps.UpdatePromotion(ctx, promotion, models.PromotionPending)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrPromotionClosed
+	}
+
+	job := buildPromotedJob(source, promotion)
+	err = hooks.CheckJobCreate(ctx, job, hooks.SourcePromotion)
+	if err == nil {
+		err = st.CreateJob(ctx, job)
+	}
+	if err != nil {
+		promotion.Status = models.PromotionFailed
+		promotion.LastError = err.Error()
+		_, _ = ps.UpdatePromotion(ctx, promotion, models.PromotionPromoted)
+		return nil, fmt.Errorf("failed to create promoted job: %w", err)
+	}
+	promotion.JobID = &job.JobID
+	if _, err := ps.UpdatePromotion(ctx, promotion, models.PromotionPromoted); err != nil {
+		return job, fmt.Errorf("failed to link promoted job: %w", err)
+	}
+
+	if err := submitNewJob(ctx, st, corndogsClient, job); err != nil {
+		return job, fmt.Errorf("failed to update promoted job after Corndogs submission: %w", err)
+	}
+	return job, nil
+}
+
+// buildPromotedJob clones source's spec — the same source, command, image
+// and env — into a job deploying to the promotion's environment, owned by
+// the promotion's requester. Like a preview teardown job it's a one-off:
+// not part of source's workflow and not a retry of it. Its env says what
+// it promotes.
+func buildPromotedJob(source *models.Job, promotion *models.Promotion) *models.Job {
+	job := cloneJobForRetry(source)
+	job.UserID = promotion.RequestedBy
+	job.Name = fmt.Sprintf("%s (promoted to %s)", source.Name, promotion.ToEnvironment)
+	job.Environment = promotion.ToEnvironment
+	job.ParentJobID = nil
+	job.RetryCount = 0
+	job.Deadline = nil
+	job.DebugOnFailure = false
+	job.WorkflowID = nil
+	job.WorkflowNodeID = nil
+	job.WorkflowNodeName = ""
+	job.WorkflowRunID = nil
+
+	if job.JobEnvVars == nil {
+		job.JobEnvVars = models.JSONB{}
+	}
+	job.JobEnvVars[PromotionIDEnv] = promotion.PromotionID
+	job.JobEnvVars[PromotedFromEnv] = promotion.FromEnvironment
+	job.JobEnvVars[ArtifactDigestEnv] = promotion.ArtifactDigest
+	return job
+}
@@ -0,0 +1,231 @@
+package jobcontrol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// promotionMockStore adds promotions to retryMockStore, with the same
+// guarded-update semantics as the postgres store.
+type promotionMockStore struct {
+	*retryMockStore
+	promotions map[string]*models.Promotion
+}
+
+func newPromotionMockStore() *promotionMockStore {
+	return &promotionMockStore{retryMockStore: newRetryMockStore(), promotions: map[string]*models.Promotion{}}
+}
+
+func (m *promotionMockStore) CreatePromotion(ctx context.Context, promotion *models.Promotion) error {
+	promotion.PromotionID = fmt.Sprintf("promotion-%d", len(m.promotions)+1)
+	cp := *promotion
+	m.promotions[promotion.PromotionID] = &cp
+	return nil
+}
+
+func (m *promotionMockStore) GetPromotion(ctx context.Context, promotionID string) (*models.Promotion, error) {
+	p, ok := m.promotions[promotionID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	cp := *p
+	cp.Approvals = append(models.PromotionApprovals(nil), p.Approvals...)
+	return &cp, nil
+}
+
+func (m *promotionMockStore) GetPromotionByJob(ctx context.Context, jobID string) (*models.Promotion, error) {
+	for _, p := range m.promotions {
+		if p.JobID != nil && *p.JobID == jobID {
+			return m.GetPromotion(ctx, p.PromotionID)
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (m *promotionMockStore) AddPromotionApproval(ctx context.Context, promotionID string, approval models.PromotionApproval) (bool, error) {
+	p, ok := m.promotions[promotionID]
+	if !ok || p.Status != models.PromotionPending || p.Approvals.Includes(approval.UserID) {
+		return false, nil
+	}
+	p.Approvals = append(p.Approvals, approval)
+	return true, nil
+}
+
+func (m *promotionMockStore) UpdatePromotion(ctx context.Context, promotion *models.Promotion, fromStatus string) (bool, error) {
+	p, ok := m.promotions[promotion.PromotionID]
+	if !ok || p.Status != fromStatus {
+		return false, nil
+	}
+	p.Status = promotion.Status
+	p.JobID = promotion.JobID
+	p.DecidedBy = promotion.DecidedBy
+	p.DecidedAt = promotion.DecidedAt
+	p.Reason = promotion.Reason
+	p.LastError = promotion.LastError
+	return true, nil
+}
+
+func newPromotionFixture(rules models.PromotionRules) (*promotionMockStore, *models.Project, *models.Job) {
+	st := newPromotionMockStore()
+	projectID := "proj-1"
+	sha := "abc123"
+	source := st.addJob(&models.Job{
+		JobID:       "deploy-staging",
+		UserID:      "user-1",
+		ProjectID:   &projectID,
+		Name:        "deploy",
+		Status:      "completed",
+		Environment: "staging",
+		JobCommand:  "make deploy",
+		CommitSHA:   &sha,
+		JobEnvVars:  models.JSONB{"STAGE": "deploy"},
+		RetryCount:  1,
+	})
+	return st, &models.Project{ProjectID: projectID, PromotionRules: rules}, source
+}
+
+func TestRequestPromotion_Validation(t *testing.T) {
+	tests := []struct {
+		name   string
+		rules  models.PromotionRules
+		mutate func(*models.Job)
+		env    string
+		want   error
+	}{
+		{"not a deploy job", nil, func(j *models.Job) { j.Environment = "" }, "prod", ErrNotPromotable},
+		{"not completed", nil, func(j *models.Job) { j.Status = "failed" }, "prod", ErrNotPromotable},
+		{"same environment", nil, nil, "staging", ErrNotPromotable},
+		{"invalid environment", nil, nil, "prod env", store.ErrInvalidInput},
+		{"rule disallows source", models.PromotionRules{"prod": {From: []string{"qa"}, Approvals: 1}}, nil, "prod", ErrNotPromotable},
+		{"no digest", nil, func(j *models.Job) { j.CommitSHA = nil }, "prod", store.ErrInvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, project, source := newPromotionFixture(tt.rules)
+			if tt.mutate != nil {
+				tt.mutate(source)
+			}
+			_, _, err := RequestPromotion(context.Background(), st, corndogs.NewMockClient(), project, source, tt.env, "", "user-1")
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+			if len(st.promotions) != 0 {
+				t.Errorf("expected no promotion recorded, got %d", len(st.promotions))
+			}
+		})
+	}
+}
+
+func TestPromotion_ApprovalsRunIt(t *testing.T) {
+	st, project, source := newPromotionFixture(models.PromotionRules{"prod": {From: []string{"staging"}, Approvals: 2}})
+	mockCorndogs := corndogs.NewMockClient()
+	ctx := context.Background()
+
+	promotion, job, err := RequestPromotion(ctx, st, mockCorndogs, project, source, "prod", "", "user-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job != nil || promotion.Status != models.PromotionPending || promotion.ApprovalsRequired != 2 {
+		t.Fatalf("expected a pending promotion needing 2 approvals, got %+v (job %v)", promotion, job)
+	}
+	if promotion.ArtifactDigest != "abc123" {
+		t.Errorf("expected the commit as the digest, got %q", promotion.ArtifactDigest)
+	}
+
+	if _, err := ApprovePromotion(ctx, st, mockCorndogs, promotion, "user-1"); !errors.Is(err, ErrSelfApproval) {
+		t.Fatalf("expected ErrSelfApproval, got %v", err)
+	}
+	job, err = ApprovePromotion(ctx, st, mockCorndogs, promotion, "approver-1")
+	if err != nil || job != nil {
+		t.Fatalf("expected the first approval to leave it pending, got job %v, err %v", job, err)
+	}
+	if _, err := ApprovePromotion(ctx, st, mockCorndogs, promotion, "approver-1"); !errors.Is(err, ErrAlreadyApproved) {
+		t.Fatalf("expected ErrAlreadyApproved, got %v", err)
+	}
+	job, err = ApprovePromotion(ctx, st, mockCorndogs, promotion, "approver-2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job == nil {
+		t.Fatal("expected the second approval to run the promotion")
+	}
+
+	if job.Environment != "prod" || job.JobCommand != "make deploy" || job.UserID != "user-1" {
+		t.Errorf("expected a prod rerun of the deploy for the requester, got %+v", job)
+	}
+	if job.ParentJobID != nil || job.RetryCount != 0 {
+		t.Errorf("expected a promoted job not to be a retry, got parent %v, retry count %d", job.ParentJobID, job.RetryCount)
+	}
+	for key, want := range map[string]string{
+		"STAGE":           "deploy",
+		PromotionIDEnv:    promotion.PromotionID,
+		PromotedFromEnv:   "staging",
+		ArtifactDigestEnv: "abc123",
+	} {
+		if got, _ := job.JobEnvVars.String(key); got != want {
+			t.Errorf("expected %s=%q, got %q", key, want, got)
+		}
+	}
+	if mockCorndogs.GetSubmitTaskCallCount() != 1 {
+		t.Errorf("expected 1 SubmitTask call, got %d", mockCorndogs.GetSubmitTaskCallCount())
+	}
+
+	stored, _ := st.GetPromotion(ctx, promotion.PromotionID)
+	if stored.Status != models.PromotionPromoted || stored.JobID == nil || *stored.JobID != job.JobID || stored.DecidedAt == nil {
+		t.Errorf("expected the promotion promoted and linked to its job, got %+v", stored)
+	}
+	if _, err := ApprovePromotion(ctx, st, mockCorndogs, stored, "approver-3"); !errors.Is(err, ErrPromotionClosed) {
+		t.Errorf("expected ErrPromotionClosed after it ran, got %v", err)
+	}
+
+	// Promoting the promoted job carries its digest along.
+	st.jobs[job.JobID].Status = "completed"
+	next, _, err := RequestPromotion(ctx, st, mockCorndogs, project, st.jobs[job.JobID], "dr", "", "user-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if next.ArtifactDigest != "abc123" || next.FromEnvironment != "prod" {
+		t.Errorf("expected prod → dr carrying abc123, got %+v", next)
+	}
+}
+
+func TestRequestPromotion_NoApprovalsRunsStraightAway(t *testing.T) {
+	st, project, source := newPromotionFixture(models.PromotionRules{"qa": {Approvals: 0}})
+
+	promotion, job, err := RequestPromotion(context.Background(), st, corndogs.NewMockClient(), project, source, "qa", "sha256:feed", "user-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job == nil || promotion.Status != models.PromotionPromoted {
+		t.Fatalf("expected the promotion to run, got %+v (job %v)", promotion, job)
+	}
+	if got, _ := job.JobEnvVars.String(ArtifactDigestEnv); got != "sha256:feed" {
+		t.Errorf("expected the given digest, got %q", got)
+	}
+}
+
+func TestRejectPromotion(t *testing.T) {
+	st, project, source := newPromotionFixture(nil)
+	ctx := context.Background()
+
+	promotion, _, err := RequestPromotion(ctx, st, corndogs.NewMockClient(), project, source, "prod", "", "user-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := RejectPromotion(ctx, st, promotion, "approver-1", "not this week"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stored, _ := st.GetPromotion(ctx, promotion.PromotionID)
+	if stored.Status != models.PromotionRejected || stored.Reason != "not this week" || stored.JobID != nil {
+		t.Errorf("expected a rejected promotion without a job, got %+v", stored)
+	}
+	if _, err := ApprovePromotion(ctx, st, corndogs.NewMockClient(), stored, "approver-2"); !errors.Is(err, ErrPromotionClosed) {
+		t.Errorf("expected ErrPromotionClosed, got %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package jobcontrol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	pb "github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs/v1alpha1"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/worker"
+)
+
+// queueSubmitter is the narrow Corndogs capability for submitting to a
+// named queue rather than the payload's platform queue. See
+// corndogs.Client.SubmitTaskToQueue.
+type queueSubmitter interface {
+	SubmitTaskToQueue(ctx context.Context, queue string, payload *corndogs.TaskPayload, priority int64) (*pb.Task, error)
+}
+
+// MoveJobToQueue moves a job still waiting in Corndogs to another queue, at
+// its current priority. See resubmitJob.
+func MoveJobToQueue(ctx context.Context, st store.Store, corndogsClient corndogs.ClientInterface, job *models.Job, queue string) (*models.Job, error) {
+	if queue == "" {
+		return job, store.ErrInvalidInput
+	}
+	return resubmitJob(ctx, st, corndogsClient, job, queue, job.Priority, nil)
+}
+
+// ErrNoBoost is returned by BoostJob when the job already waits at or
+// above the priority it would be boosted to.
+var ErrNoBoost = errors.New("job is already at or above that priority")
+
+// BoostJob raises the priority of a job still waiting in Corndogs, on the
+// queue it is waiting in, and records userID as having boosted it. See
+// resubmitJob.
+func BoostJob(ctx context.Context, st store.Store, corndogsClient corndogs.ClientInterface, job *models.Job, priority int, userID string) (*models.Job, error) {
+	if priority <= job.Priority {
+		return job, ErrNoBoost
+	}
+	return resubmitJob(ctx, st, corndogsClient, job, "", priority, func(j *models.Job) {
+		now := time.Now().UTC()
+		j.BoostedBy = &userID
+		j.BoostedAt = &now
+	})
+}
+
+// resubmitJob dequeues a job's Corndogs task and submits it again at
+// priority, on queue, or on the queue it was dequeued from when queue is
+// empty. Corndogs can't change a waiting task, so this is how a job is
+// reprioritized or moved. If a worker claims the task first the job is left
+// alone and ErrNotQueued returned. If the resubmission fails the job is
+// failed, as when its first submission fails. record, if set, makes further
+// changes to the job along with its new task.
+func resubmitJob(ctx context.Context, st store.Store, corndogsClient corndogs.ClientInterface, job *models.Job, queue string, priority int, record func(j *models.Job)) (*models.Job, error) {
+	if corndogsClient == nil {
+		return job, errors.New("corndogs not configured")
+	}
+	gs, ok := st.(guardedJobStore)
+	if !ok {
+		return job, errors.New("store does not support guarded job status transitions")
+	}
+	qs, canTargetQueue := corndogsClient.(queueSubmitter)
+	if queue != "" && !canTargetQueue {
+		return job, errors.New("corndogs client cannot submit to a named queue")
+	}
+	if job.CorndogsTaskID == nil || *job.CorndogsTaskID == "" || (job.Status != "submitted" && job.Status != "queued") {
+		return job, ErrNotQueued
+	}
+
+	// As in transitionJob, "submitted" is Corndogs' own pre-claim state.
+	old, err := corndogsClient.CancelTask(ctx, *job.CorndogsTaskID, "submitted")
+	if err != nil {
+		return job, ErrNotQueued
+	}
+	if queue == "" && old != nil {
+		queue = old.Queue
+	}
+
+	next := *job
+	next.Priority = priority
+	payload := worker.BuildTaskPayload(&next)
+	var task *pb.Task
+	var submitErr error
+	if queue != "" && canTargetQueue {
+		task, submitErr = qs.SubmitTaskToQueue(ctx, queue, payload, int64(priority))
+	} else {
+		task, submitErr = corndogsClient.SubmitTask(ctx, payload, int64(priority))
+	}
+	updated, matched, err := gs.UpdateJobStatusGuarded(ctx, job.JobID, []string{"submitted", "queued"}, func(j *models.Job) {
+		if submitErr != nil {
+			j.Status = "failed"
+			j.LastError = fmt.Sprintf("failed to resubmit to Corndogs: %v", submitErr)
+			return
+		}
+		taskID := task.Uuid
+		j.CorndogsTaskID = &taskID
+		j.Priority = priority
+		if record != nil {
+			record(j)
+		}
+	})
+	if err != nil {
+		return job, fmt.Errorf("failed to record resubmitted job: %w", err)
+	}
+	if !matched {
+		// Cancelled while it was being resubmitted; the old task is gone,
+		// so take the new one back out too.
+		if submitErr == nil {
+			if _, err := corndogsClient.CancelTask(ctx, task.Uuid, "submitted"); err != nil {
+				logging.Log.WithError(err).WithField("job_id", job.JobID).Warn("Failed to dequeue resubmitted task of a cancelled job")
+			}
+		}
+		return job, ErrNotQueued
+	}
+	if submitErr != nil {
+		return updated, fmt.Errorf("failed to resubmit to Corndogs: %w", submitErr)
+	}
+	return updated, nil
+}
@@ -41,6 +41,7 @@ import (
 
 	"github.com/catalystcommunity/app-utils-go/logging"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/hooks"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/worker"
@@ -94,26 +95,15 @@ func RetryJob(ctx context.Context, st store.Store, corndogsClient corndogs.Clien
 	}
 
 	newJob := cloneJobForRetry(job)
+	if err := hooks.CheckJobCreate(ctx, newJob, hooks.SourceRetry); err != nil {
+		return nil, err
+	}
 	if err := st.CreateJob(ctx, newJob); err != nil {
 		return nil, fmt.Errorf("failed to create retried job: %w", err)
 	}
 
-	if corndogsClient != nil {
-		payload := worker.BuildTaskPayload(newJob)
-		task, err := corndogsClient.SubmitTask(ctx, payload, int64(newJob.Priority))
-		if err != nil {
-			logging.Log.WithError(err).WithField("job_id", newJob.JobID).
-				Error("Failed to submit retried job to Corndogs")
-			newJob.Status = "failed"
-			newJob.LastError = fmt.Sprintf("failed to submit to Corndogs: %v", err)
-		} else {
-			taskID := task.Uuid
-			newJob.CorndogsTaskID = &taskID
-			newJob.Status = task.CurrentState
-		}
-		if err := st.UpdateJob(ctx, newJob); err != nil {
-			return newJob, fmt.Errorf("failed to update retried job after Corndogs submission: %w", err)
-		}
+	if err := submitNewJob(ctx, st, corndogsClient, newJob); err != nil {
+		return newJob, fmt.Errorf("failed to update retried job after Corndogs submission: %w", err)
 	}
 
 	if job.WorkflowNodeID != nil && *job.WorkflowNodeID != "" {
@@ -377,17 +367,33 @@ func cloneJobForRetry(original *models.Job) *models.Job {
 
 		TimeoutSeconds: original.TimeoutSeconds,
 		Priority:       original.Priority,
+		Deadline:       cloneTimePtr(original.Deadline),
 		Capabilities:   append(pq.StringArray(nil), original.Capabilities...),
 		RunAsUser:      original.RunAsUser,
+		TargetOS:       original.TargetOS,
+		TargetArch:     original.TargetArch,
+		Affinity:       original.Affinity,
+		AvoidWorkers:   append(pq.StringArray(nil), original.AvoidWorkers...),
+		Environment:    original.Environment,
+		Scan:           cloneScanSpec(original.Scan),
+
+		SharedWorkspace:      original.SharedWorkspace,
+		SharedWorkspaceScope: original.SharedWorkspaceScope,
+		Cache:                original.Cache,
 
 		QueueName:       original.QueueName,
+		QueueRouted:     original.QueueRouted,
+		Canary:          original.Canary,
 		AutoTargetState: original.AutoTargetState,
 
 		Status: "submitted",
 
-		EventMetadata: cloneJSONB(original.EventMetadata),
-		ParentJobID:   &parentJobID,
-		RetryCount:    original.RetryCount + 1,
+		EventMetadata:  cloneJSONB(original.EventMetadata),
+		ParentJobID:    &parentJobID,
+		RetryCount:     original.RetryCount + 1,
+		AwaitChildren:  original.AwaitChildren,
+		DebugOnFailure: original.DebugOnFailure,
+		SecretScopes:   append(pq.StringArray(nil), original.SecretScopes...),
 
 		WorkflowID:       original.WorkflowID,
 		WorkflowNodeID:   original.WorkflowNodeID,
@@ -406,6 +412,29 @@ func cloneJobForRetry(original *models.Job) *models.Job {
 	return newJob
 }
 
+// submitNewJob submits a just-created job to Corndogs, unless intake is
+// paused for it (see worker.HoldIfPaused), and saves the task ID and state.
+// A failed submission marks the job failed rather than returning an error;
+// the returned error is the save's.
+func submitNewJob(ctx context.Context, st store.Store, corndogsClient corndogs.ClientInterface, newJob *models.Job) error {
+	if worker.HoldIfPaused(ctx, st, newJob) || corndogsClient == nil {
+		return nil
+	}
+	payload := worker.BuildTaskPayload(newJob)
+	task, err := corndogsClient.SubmitTask(ctx, payload, int64(newJob.Priority))
+	if err != nil {
+		logging.Log.WithError(err).WithField("job_id", newJob.JobID).
+			Error("Failed to submit job to Corndogs")
+		newJob.Status = "failed"
+		newJob.LastError = fmt.Sprintf("failed to submit to Corndogs: %v", err)
+	} else {
+		taskID := task.Uuid
+		newJob.CorndogsTaskID = &taskID
+		newJob.Status = task.CurrentState
+	}
+	return st.UpdateJob(ctx, newJob)
+}
+
 func cloneJSONB(in models.JSONB) models.JSONB {
 	if in == nil {
 		return nil
@@ -425,6 +454,14 @@ func cloneStringPtr(in *string) *string {
 	return &cp
 }
 
+func cloneTimePtr(in *time.Time) *time.Time {
+	if in == nil {
+		return nil
+	}
+	cp := *in
+	return &cp
+}
+
 func cloneIntPtr(in *int) *int {
 	if in == nil {
 		return nil
@@ -433,6 +470,14 @@ func cloneIntPtr(in *int) *int {
 	return &cp
 }
 
+func cloneScanSpec(in *models.ScanSpec) *models.ScanSpec {
+	if in == nil {
+		return nil
+	}
+	cp := *in
+	return &cp
+}
+
 func cloneSourceTypePtr(in *models.SourceType) *models.SourceType {
 	if in == nil {
 		return nil
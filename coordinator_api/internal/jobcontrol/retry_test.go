@@ -195,7 +195,7 @@ func (m *retryMockStore) CreateProject(ctx context.Context, project *models.Proj
 func (m *retryMockStore) GetProjectByID(ctx context.Context, projectID string) (*models.Project, error) {
 	return nil, nil
 }
-func (m *retryMockStore) GetProjectByRepoURL(ctx context.Context, repoURL string) (*models.Project, error) {
+func (m *retryMockStore) GetProjectsByRepoURL(ctx context.Context, repoURL string) ([]models.Project, error) {
 	return nil, nil
 }
 func (m *retryMockStore) UpdateProject(ctx context.Context, project *models.Project) error {
@@ -344,6 +344,7 @@ func TestRetryJob_ClonesSpecFields(t *testing.T) {
 		VCSRepo:            strPtr("owner/repo"),
 		PRNumber:           intPtr(42),
 		CommitSHA:          strPtr("abc123"),
+		Scan:               &models.ScanSpec{Scanner: models.ScannerTrivy, Target: models.ScanTargetSource},
 	}
 	st.addJob(original)
 	mockCorndogs := corndogs.NewMockClient()
@@ -397,6 +398,9 @@ func TestRetryJob_ClonesSpecFields(t *testing.T) {
 	if newJob.PRNumber == nil || *newJob.PRNumber != 42 {
 		t.Errorf("expected PRNumber 42, got %v", newJob.PRNumber)
 	}
+	if newJob.Scan == nil || *newJob.Scan != *original.Scan || newJob.Scan == original.Scan {
+		t.Errorf("expected a copy of the scan spec, got %+v", newJob.Scan)
+	}
 
 	// Lineage fields.
 	if newJob.JobID == original.JobID {
@@ -0,0 +1,290 @@
+// Stuck-job detection and remediation. The monitor runs on every
+// coordinator replica; CreateStuckJobReport's insert-once semantics make
+// exactly one replica act on each stuck job.
+package jobcontrol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/pubsub"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// maxStuckJobScan caps how many pending kill follow-ups one sweep
+// processes, and is how many running jobs it loads at a time; it inspects
+// every running job.
+const maxStuckJobScan = 1000
+
+// stuckJobStore is the narrow store capability the stuck-job monitor
+// needs. See postgres_store/stuck_job_operations.go.
+type stuckJobStore interface {
+	GetJobDurationP95(ctx context.Context, projectID, name string, since time.Time, minRuns int) (time.Duration, error)
+	CreateStuckJobReport(ctx context.Context, report *models.StuckJobReport) (bool, error)
+	UpdateStuckJobReport(ctx context.Context, report *models.StuckJobReport) error
+	ListPendingStuckJobReports(ctx context.Context, limit int) ([]models.StuckJobReport, error)
+}
+
+// StuckJobMonitorConfig configures a StuckJobMonitor.
+type StuckJobMonitorConfig struct {
+	// Interval between sweeps.
+	Interval time.Duration
+	// Policy is the global policy; projects can override it (see
+	// models.StuckJobPolicy.ForProject).
+	Policy models.StuckJobPolicy
+	// BaselineWindow is how far back successful runs count toward a job
+	// name's p95, and MinBaselineRuns how many are needed before the p95
+	// check applies at all.
+	BaselineWindow  time.Duration
+	MinBaselineRuns int
+}
+
+// StuckJobMonitor periodically flags running jobs that look hung and
+// applies the effective policy's action:
+//
+//   - warn: publish a job_stuck event and record the report.
+//   - kill_retry: kill the job, then retry it once it lands terminal.
+//   - kill_fail: kill the job, then mark it failed instead of cancelled.
+//
+// The kill follow-ups happen on later sweeps, since the kill itself is
+// carried out asynchronously by the worker's cancel-poll.
+type StuckJobMonitor struct {
+	store          store.Store
+	corndogsClient corndogs.ClientInterface
+	publisher      *pubsub.Publisher
+	config         StuckJobMonitorConfig
+	now            func() time.Time
+}
+
+// NewStuckJobMonitor creates a monitor. publisher may be nil.
+func NewStuckJobMonitor(st store.Store, corndogsClient corndogs.ClientInterface, publisher *pubsub.Publisher, config StuckJobMonitorConfig) *StuckJobMonitor {
+	if config.Interval <= 0 {
+		config.Interval = time.Minute
+	}
+	if config.BaselineWindow <= 0 {
+		config.BaselineWindow = 30 * 24 * time.Hour
+	}
+	return &StuckJobMonitor{
+		store:          st,
+		corndogsClient: corndogsClient,
+		publisher:      publisher,
+		config:         config,
+		now:            func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Start runs Sweep every Interval until ctx is done. It returns
+// immediately; a store without stuck-job support makes it a no-op.
+func (m *StuckJobMonitor) Start(ctx context.Context) {
+	if _, ok := m.store.(stuckJobStore); !ok {
+		logging.Log.Warn("Store does not support stuck job reports; stuck job monitor disabled")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(m.config.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := m.Sweep(ctx); err != nil {
+					logging.Log.WithError(err).Warn("Stuck job sweep failed")
+				}
+			}
+		}
+	}()
+}
+
+// Sweep runs one detection pass over running jobs, then one pass over the
+// kill follow-ups earlier sweeps left pending.
+func (m *StuckJobMonitor) Sweep(ctx context.Context) error {
+	ss, ok := m.store.(stuckJobStore)
+	if !ok {
+		return errors.New("store does not support stuck job reports")
+	}
+	if err := m.detect(ctx, ss); err != nil {
+		return err
+	}
+	return m.followUp(ctx, ss)
+}
+
+func (m *StuckJobMonitor) detect(ctx context.Context, ss stuckJobStore) error {
+	it := store.NewJobIterator(store.ListJobsPager(m.store, map[string]interface{}{"status": "running"}), maxStuckJobScan)
+
+	now := m.now()
+	projects := map[string]*models.Project{}
+	baselines := map[string]time.Duration{}
+	for it.Next(ctx) {
+		job := it.Job()
+		if job.IsAwaitingChildren() {
+			continue
+		}
+
+		var project *models.Project
+		projectID := ""
+		if job.ProjectID != nil {
+			projectID = *job.ProjectID
+			if _, seen := projects[projectID]; !seen {
+				if p, err := m.store.GetProjectByID(ctx, projectID); err == nil {
+					projects[projectID] = p
+				} else {
+					projects[projectID] = nil
+				}
+			}
+			project = projects[projectID]
+		}
+		policy := m.config.Policy.ForProject(project)
+		if !policy.Enabled() {
+			continue
+		}
+
+		var p95 time.Duration
+		if projectID != "" && policy.P95Multiplier > 0 {
+			key := projectID + "\x00" + job.Name
+			if cached, seen := baselines[key]; seen {
+				p95 = cached
+			} else {
+				var err error
+				p95, err = ss.GetJobDurationP95(ctx, projectID, job.Name, now.Add(-m.config.BaselineWindow), m.config.MinBaselineRuns)
+				if err != nil {
+					return err
+				}
+				baselines[key] = p95
+			}
+		}
+
+		reason, detail := policy.Evaluate(job, p95, now)
+		if reason == "" {
+			continue
+		}
+		m.flag(ctx, ss, job, policy.Action, reason, detail)
+	}
+	if err := it.Err(); err != nil {
+		return fmt.Errorf("failed to list running jobs: %w", err)
+	}
+	return nil
+}
+
+// flag records job as stuck and applies action, unless another sweep (on
+// this or another replica) already reported it.
+func (m *StuckJobMonitor) flag(ctx context.Context, ss stuckJobStore, job *models.Job, action, reason, detail string) {
+	logger := logging.Log.WithField("job_id", job.JobID).WithField("reason", reason).WithField("action", action)
+	report := &models.StuckJobReport{
+		JobID:      job.JobID,
+		ProjectID:  job.ProjectID,
+		Reason:     reason,
+		Action:     action,
+		Detail:     detail,
+		DetectedAt: m.now(),
+	}
+	if action == models.StuckJobActionWarn {
+		report.RemediatedAt = &report.DetectedAt
+	}
+	created, err := ss.CreateStuckJobReport(ctx, report)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to record stuck job")
+		return
+	}
+	if !created {
+		return
+	}
+
+	logger.WithField("detail", detail).Warn("Stuck job detected")
+	m.publisher.PublishJobStuck(ctx, job.JobID, job.Status, reason)
+	if action == models.StuckJobActionWarn {
+		return
+	}
+
+	// ErrNotCancellable means the job finished on its own since the scan;
+	// followUp treats it like any other terminal job.
+	if _, err := KillJob(ctx, m.store, m.corndogsClient, job); err != nil && !errors.Is(err, ErrNotCancellable) {
+		report.LastError = fmt.Sprintf("kill failed: %v", err)
+		if err := ss.UpdateStuckJobReport(ctx, report); err != nil {
+			logger.WithError(err).Warn("Failed to update stuck job report")
+		}
+	}
+}
+
+// followUp finishes the kill actions once the killed jobs are terminal.
+func (m *StuckJobMonitor) followUp(ctx context.Context, ss stuckJobStore) error {
+	reports, err := ss.ListPendingStuckJobReports(ctx, maxStuckJobScan)
+	if err != nil {
+		return err
+	}
+	for i := range reports {
+		report := &reports[i]
+		job, err := m.store.GetJobByID(ctx, report.JobID)
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				m.finishReport(ctx, ss, report, "job no longer exists")
+			}
+			continue
+		}
+		if !job.IsCompleted() {
+			continue
+		}
+		if job.Status == "completed" {
+			m.finishReport(ctx, ss, report, "job completed before it was killed")
+			continue
+		}
+
+		switch report.Action {
+		case models.StuckJobActionKillRetry:
+			retried, err := RetryJob(ctx, m.store, m.corndogsClient, job)
+			if err != nil {
+				m.finishReport(ctx, ss, report, fmt.Sprintf("retry failed: %v", err))
+				continue
+			}
+			report.RetryJobID = &retried.JobID
+			m.finishReport(ctx, ss, report, "")
+		case models.StuckJobActionKillFail:
+			m.failKilledJob(ctx, job, report)
+			m.finishReport(ctx, ss, report, report.LastError)
+		}
+	}
+	return nil
+}
+
+// failKilledJob moves a job the monitor killed from "cancelled" to
+// "failed", so a hang reads as a failure rather than a user cancel.
+func (m *StuckJobMonitor) failKilledJob(ctx context.Context, job *models.Job, report *models.StuckJobReport) {
+	if job.Status != "cancelled" {
+		return
+	}
+	lastError := "stuck job killed: " + report.Detail
+	gs, ok := m.store.(guardedJobStore)
+	if !ok {
+		job.Status = "failed"
+		job.LastError = lastError
+		if err := m.store.UpdateJob(ctx, job); err != nil {
+			report.LastError = fmt.Sprintf("failed to mark job failed: %v", err)
+		}
+		return
+	}
+	updated, matched, err := gs.UpdateJobStatusGuarded(ctx, job.JobID, []string{"cancelled"}, func(j *models.Job) {
+		j.Status = "failed"
+		j.LastError = lastError
+	})
+	if err != nil {
+		report.LastError = fmt.Sprintf("failed to mark job failed: %v", err)
+		return
+	}
+	if matched {
+		m.publisher.PublishJobUpdate(ctx, updated.JobID, updated.Status, updated.UpdatedAt.Format(time.RFC3339Nano))
+	}
+}
+
+func (m *StuckJobMonitor) finishReport(ctx context.Context, ss stuckJobStore, report *models.StuckJobReport, lastError string) {
+	now := m.now()
+	report.RemediatedAt = &now
+	report.LastError = lastError
+	if err := ss.UpdateStuckJobReport(ctx, report); err != nil {
+		logging.Log.WithError(err).WithField("job_id", report.JobID).Warn("Failed to update stuck job report")
+	}
+}
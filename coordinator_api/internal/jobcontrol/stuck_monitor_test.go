@@ -0,0 +1,215 @@
+package jobcontrol
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// stuckMockStore layers the stuckJobStore capability, running-job listing
+// and project lookup over retryMockStore, so kill_retry's follow-up runs
+// through the real RetryJob path.
+type stuckMockStore struct {
+	*retryMockStore
+	projects map[string]*models.Project
+	p95      time.Duration
+	reports  map[string]*models.StuckJobReport
+}
+
+func newStuckMockStore() *stuckMockStore {
+	return &stuckMockStore{
+		retryMockStore: newRetryMockStore(),
+		projects:       map[string]*models.Project{},
+		reports:        map[string]*models.StuckJobReport{},
+	}
+}
+
+func (m *stuckMockStore) ListJobs(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]models.Job, error) {
+	var jobs []models.Job
+	for _, j := range m.jobs {
+		if status, ok := filters["status"]; ok && j.Status != status {
+			continue
+		}
+		jobs = append(jobs, *j)
+	}
+	return jobs, nil
+}
+
+func (m *stuckMockStore) GetProjectByID(ctx context.Context, projectID string) (*models.Project, error) {
+	return m.projects[projectID], nil
+}
+
+func (m *stuckMockStore) GetJobDurationP95(ctx context.Context, projectID, name string, since time.Time, minRuns int) (time.Duration, error) {
+	return m.p95, nil
+}
+
+func (m *stuckMockStore) CreateStuckJobReport(ctx context.Context, report *models.StuckJobReport) (bool, error) {
+	if _, ok := m.reports[report.JobID]; ok {
+		return false, nil
+	}
+	cp := *report
+	m.reports[report.JobID] = &cp
+	return true, nil
+}
+
+func (m *stuckMockStore) UpdateStuckJobReport(ctx context.Context, report *models.StuckJobReport) error {
+	cp := *report
+	m.reports[report.JobID] = &cp
+	return nil
+}
+
+func (m *stuckMockStore) ListPendingStuckJobReports(ctx context.Context, limit int) ([]models.StuckJobReport, error) {
+	var reports []models.StuckJobReport
+	for _, r := range m.reports {
+		if r.RemediatedAt == nil && r.Action != models.StuckJobActionWarn {
+			reports = append(reports, *r)
+		}
+	}
+	return reports, nil
+}
+
+var _ stuckJobStore = (*stuckMockStore)(nil)
+
+// newStuckFixture returns a store holding one running job that started an
+// hour ago in a project with the given action override, and a monitor
+// whose global policy flags jobs silent for ten minutes.
+func newStuckFixture(t *testing.T, action string) (*stuckMockStore, *StuckJobMonitor, time.Time) {
+	t.Helper()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	started := now.Add(-time.Hour)
+	lastLog := now.Add(-time.Minute)
+
+	st := newStuckMockStore()
+	st.projects["project-1"] = &models.Project{ProjectID: "project-1", StuckJobAction: &action}
+	st.addJob(&models.Job{
+		JobID:      "job-1",
+		UserID:     "user-1",
+		ProjectID:  strPtr("project-1"),
+		Name:       "build",
+		Status:     "running",
+		JobCommand: "make",
+		StartedAt:  &started,
+		LastLogAt:  &lastLog,
+	})
+
+	monitor := NewStuckJobMonitor(st, corndogs.NewMockClient(), nil, StuckJobMonitorConfig{
+		Policy: models.StuckJobPolicy{
+			Action:        models.StuckJobActionWarn,
+			NoLogAfter:    10 * time.Minute,
+			P95Multiplier: 3,
+		},
+	})
+	monitor.now = func() time.Time { return now }
+	return st, monitor, now
+}
+
+func TestStuckJobMonitor_HealthyJobNotFlagged(t *testing.T) {
+	st, monitor, _ := newStuckFixture(t, models.StuckJobActionKillFail)
+	st.p95 = 30 * time.Minute
+
+	if err := monitor.Sweep(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(st.reports) != 0 {
+		t.Fatalf("expected no reports for a job within 3x p95 that is still logging, got %+v", st.reports)
+	}
+}
+
+func TestStuckJobMonitor_Warn(t *testing.T) {
+	st, monitor, now := newStuckFixture(t, models.StuckJobActionWarn)
+	st.p95 = 10 * time.Minute
+
+	if err := monitor.Sweep(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	report := st.reports["job-1"]
+	if report == nil {
+		t.Fatal("expected job-1 to be reported")
+	}
+	if report.Reason != models.StuckReasonExceededP95 {
+		t.Errorf("expected reason %q, got %q", models.StuckReasonExceededP95, report.Reason)
+	}
+	if report.RemediatedAt == nil || !report.RemediatedAt.Equal(now) {
+		t.Errorf("expected a warn report to be remediated at detection, got %v", report.RemediatedAt)
+	}
+	if job, _ := st.GetJobByID(context.Background(), "job-1"); job.Status != "running" {
+		t.Errorf("expected warn to leave the job running, got %q", job.Status)
+	}
+}
+
+func TestStuckJobMonitor_KillRetry(t *testing.T) {
+	st, monitor, _ := newStuckFixture(t, models.StuckJobActionKillRetry)
+	silentSince := time.Date(2026, 10, 1, 11, 30, 0, 0, time.UTC)
+	st.jobs["job-1"].LastLogAt = &silentSince
+
+	if err := monitor.Sweep(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := st.reports["job-1"]; got == nil || got.Reason != models.StuckReasonNoLogOutput {
+		t.Fatalf("expected a no_log_output report, got %+v", got)
+	}
+	if job := st.jobs["job-1"]; job.Status != "cancelling" || job.CancelMode != "kill" {
+		t.Fatalf("expected job to be killed, got status %q cancel_mode %q", job.Status, job.CancelMode)
+	}
+
+	// Still cancelling: the follow-up waits.
+	if err := monitor.Sweep(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if st.reports["job-1"].RemediatedAt != nil {
+		t.Fatal("expected the follow-up to wait for the killed job to finish")
+	}
+
+	// The worker finishes the kill; the next sweep retries.
+	st.jobs["job-1"].Status = "cancelled"
+	if err := monitor.Sweep(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	report := st.reports["job-1"]
+	if report.RemediatedAt == nil || report.RetryJobID == nil {
+		t.Fatalf("expected the report to record the retry, got %+v", report)
+	}
+	retried := st.jobs[*report.RetryJobID]
+	if retried == nil || derefStr(retried.ParentJobID) != "job-1" {
+		t.Fatalf("expected a retry of job-1, got %+v", retried)
+	}
+}
+
+func TestStuckJobMonitor_KillFail(t *testing.T) {
+	st, monitor, _ := newStuckFixture(t, models.StuckJobActionKillFail)
+	st.p95 = 5 * time.Minute
+
+	if err := monitor.Sweep(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	st.jobs["job-1"].Status = "cancelled"
+	if err := monitor.Sweep(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	job := st.jobs["job-1"]
+	if job.Status != "failed" {
+		t.Errorf("expected the killed job to be marked failed, got %q", job.Status)
+	}
+	if job.LastError == "" {
+		t.Error("expected LastError to explain the kill")
+	}
+	if report := st.reports["job-1"]; report.RemediatedAt == nil || report.RetryJobID != nil {
+		t.Errorf("expected a remediated report without a retry, got %+v", report)
+	}
+}
+
+func TestStuckJobMonitor_ProjectOptOut(t *testing.T) {
+	st, monitor, _ := newStuckFixture(t, models.StuckJobActionOff)
+	st.p95 = time.Minute
+
+	if err := monitor.Sweep(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(st.reports) != 0 {
+		t.Fatalf("expected the project override to disable detection, got %+v", st.reports)
+	}
+}
@@ -0,0 +1,88 @@
+// Trash purging. Deleted projects and jobs sit in the trash, restorable,
+// until the purger deletes them for good.
+package jobcontrol
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+)
+
+// trashPurgeStore is the narrow store capability the trash purger needs.
+// See postgres_store/trash_operations.go.
+type trashPurgeStore interface {
+	PurgeTrash(ctx context.Context, before time.Time) (projects, jobs int64, err error)
+}
+
+// TrashPurgeConfig configures a TrashPurger.
+type TrashPurgeConfig struct {
+	// Interval between sweeps.
+	Interval time.Duration
+	// Retention is how long a deleted project or job stays in the trash.
+	Retention time.Duration
+}
+
+// TrashPurger periodically deletes, permanently, the projects and jobs
+// that have been in the trash longer than Retention.
+type TrashPurger struct {
+	store  store.Store
+	config TrashPurgeConfig
+	now    func() time.Time
+}
+
+// NewTrashPurger creates a purger.
+func NewTrashPurger(st store.Store, config TrashPurgeConfig) *TrashPurger {
+	if config.Interval <= 0 {
+		config.Interval = time.Hour
+	}
+	return &TrashPurger{
+		store:  st,
+		config: config,
+		now:    func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Start runs Sweep every Interval until ctx is done. It returns
+// immediately; a store without a trash makes it a no-op.
+func (p *TrashPurger) Start(ctx context.Context) {
+	if _, ok := p.store.(trashPurgeStore); !ok {
+		logging.Log.Warn("Store does not support the trash; trash purger disabled")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(p.config.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := p.Sweep(ctx); err != nil {
+					logging.Log.WithError(err).Warn("Trash purge sweep failed")
+				}
+			}
+		}
+	}()
+}
+
+// Sweep purges everything trashed more than Retention ago.
+func (p *TrashPurger) Sweep(ctx context.Context) error {
+	ts, ok := p.store.(trashPurgeStore)
+	if !ok {
+		return errors.New("store does not support the trash")
+	}
+	if p.config.Retention <= 0 {
+		return nil
+	}
+	projects, jobs, err := ts.PurgeTrash(ctx, p.now().Add(-p.config.Retention))
+	if err != nil {
+		return err
+	}
+	if projects > 0 || jobs > 0 {
+		logging.Log.WithField("projects", projects).WithField("jobs", jobs).Info("Purged trash")
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package jobcontrol
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// trashPurgeMockStore layers the trashPurgeStore capability over
+// jobControlMockStore, recording the cutoff it was asked to purge before.
+type trashPurgeMockStore struct {
+	*jobControlMockStore
+	cutoff time.Time
+	purges int
+}
+
+func (m *trashPurgeMockStore) PurgeTrash(ctx context.Context, before time.Time) (int64, int64, error) {
+	m.purges++
+	m.cutoff = before
+	return 1, 2, nil
+}
+
+func TestTrashPurger_Sweep(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	ms := &trashPurgeMockStore{jobControlMockStore: newJobControlMockStore()}
+	p := NewTrashPurger(ms, TrashPurgeConfig{Retention: 30 * 24 * time.Hour})
+	p.now = func() time.Time { return now }
+
+	if err := p.Sweep(context.Background()); err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+	if want := now.Add(-30 * 24 * time.Hour); ms.purges != 1 || !ms.cutoff.Equal(want) {
+		t.Errorf("purges = %d, cutoff = %v; want 1 purge before %v", ms.purges, ms.cutoff, want)
+	}
+}
+
+func TestTrashPurger_Disabled(t *testing.T) {
+	ms := &trashPurgeMockStore{jobControlMockStore: newJobControlMockStore()}
+	if err := NewTrashPurger(ms, TrashPurgeConfig{}).Sweep(context.Background()); err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+	if ms.purges != 0 {
+		t.Errorf("purger with no Retention should do nothing")
+	}
+}
+
+func TestTrashPurger_UnsupportedStore(t *testing.T) {
+	if err := NewTrashPurger(newJobControlMockStore(), TrashPurgeConfig{Retention: time.Hour}).Sweep(context.Background()); err == nil {
+		t.Error("expected an error from a store without a trash")
+	}
+}
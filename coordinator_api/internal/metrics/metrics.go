@@ -1,10 +1,13 @@
 package metrics
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
 	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
@@ -38,6 +41,40 @@ var (
 		[]string{"queue", "status"},
 	)
 
+	// Job resource metrics, when the worker records them
+	JobCPUSeconds = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "reactorcide_job_cpu_seconds",
+			Help:    "CPU time used by a job's container",
+			Buckets: prometheus.ExponentialBuckets(1, 4, 10), // 1s to ~73 hours
+		},
+		[]string{"queue"},
+	)
+	JobPeakMemory = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "reactorcide_job_peak_memory_bytes",
+			Help:    "Peak memory used by a job's container",
+			Buckets: prometheus.ExponentialBuckets(32<<20, 2, 11), // 32MiB to 32GiB
+		},
+		[]string{"queue"},
+	)
+	JobNetworkBytes = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "reactorcide_job_network_bytes",
+			Help:    "Network bytes received or sent by a job's container",
+			Buckets: prometheus.ExponentialBuckets(1<<20, 4, 10), // 1MiB to 256GiB
+		},
+		[]string{"queue", "direction"},
+	)
+
+	QueueSpillovers = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "reactorcide_queue_spillovers_total",
+			Help: "Total number of jobs sent to a spillover queue because their project's default queue had no free worker",
+		},
+		[]string{"queue", "spillover_queue"},
+	)
+
 	JobRetries = promauto.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "reactorcide_job_retries_total",
@@ -132,6 +169,170 @@ var (
 		},
 		[]string{"queue", "error_type", "retryable"},
 	)
+
+	// Deadline metrics
+	JobDeadlineMisses = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "reactorcide_job_deadline_misses_total",
+			Help: "Total number of jobs that missed their deadline",
+		},
+		[]string{"queue"},
+	)
+
+	JobDeadlineBoosts = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "reactorcide_job_deadline_boosts_total",
+			Help: "Total number of priority boosts given to queued jobs nearing their deadline",
+		},
+		[]string{"queue"},
+	)
+
+	JobBoosts = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "reactorcide_job_boosts_total",
+			Help: "Total number of priority boosts users gave queued jobs",
+		},
+		[]string{"queue"},
+	)
+
+	JobDeliveryFailures = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "reactorcide_job_delivery_failures_total",
+			Help: "Total number of job claims that ended without a result, by reason",
+		},
+		[]string{"queue", "reason"},
+	)
+
+	JobsDeadlettered = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "reactorcide_jobs_deadlettered_total",
+			Help: "Total number of jobs set aside after using up their delivery attempts",
+		},
+		[]string{"queue"},
+	)
+
+	JobAffinityDecisions = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "reactorcide_job_affinity_decisions_total",
+			Help: "Total number of scheduling affinity decisions by workers, by result (hit, miss, cold, deferred, avoided)",
+		},
+		[]string{"queue", "result"},
+	)
+
+	// VCS API metrics
+	VCSRateLimitRemaining = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "reactorcide_vcs_rate_limit_remaining",
+			Help: "Requests remaining in the last observed VCS API rate limit window",
+		},
+		[]string{"host", "resource"},
+	)
+
+	VCSRequestRetries = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "reactorcide_vcs_request_retries_total",
+			Help: "Total number of VCS API requests retried",
+		},
+		[]string{"host", "reason"},
+	)
+
+	VCSCacheLookups = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "reactorcide_vcs_cache_lookups_total",
+			Help: "Total number of VCS API GET requests by response cache result",
+		},
+		[]string{"host", "result"},
+	)
+
+	VCSStatusDeliveries = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "reactorcide_vcs_status_deliveries_total",
+			Help: "Total number of VCS status outbox delivery attempts",
+		},
+		[]string{"kind", "result"},
+	)
+
+	// Database metrics
+	DBSlowQueries = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "reactorcide_db_slow_queries_total",
+			Help: "Total number of database queries slower than the slow query threshold",
+		},
+		[]string{"operation"},
+	)
+
+	// Webhook metrics
+	WebhooksShed = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "reactorcide_webhooks_shed_total",
+			Help: "Total number of webhook deliveries turned away with a 503 while the coordinator was overloaded",
+		},
+		[]string{"provider", "reason"},
+	)
+	WebhookDBLatency = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "reactorcide_webhook_db_latency_seconds",
+			Help: "Smoothed database round-trip latency measured for webhook load shedding",
+		},
+	)
+	WebhookBacklog = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "reactorcide_webhook_backlog",
+			Help: "Queued webhook events due for processing that no coordinator has taken yet",
+		},
+	)
+
+	// Chaos mode metrics
+	ChaosFaultsInjected = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "reactorcide_chaos_faults_injected_total",
+			Help: "Total number of faults injected by chaos mode",
+		},
+		[]string{"fault"},
+	)
+
+	APIDeprecatedRequests = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "reactorcide_api_deprecated_requests_total",
+			Help: "Total number of requests to API routes slated for change in a later API version",
+		},
+		[]string{"route"},
+	)
+
+	// Git mirror cache metrics (worker)
+	GitMirrorRequests = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "reactorcide_git_mirror_requests_total",
+			Help: "Total number of jobs' git mirror lookups by result (hit, refresh, miss, error)",
+		},
+		[]string{"result"},
+	)
+	GitMirrorFetchDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "reactorcide_git_mirror_fetch_duration_seconds",
+			Help:    "Time taken to clone or fetch a git mirror",
+			Buckets: prometheus.ExponentialBuckets(0.25, 2, 14), // 250ms to ~68 minutes
+		},
+		[]string{"operation"},
+	)
+	GitMirrorEvictions = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "reactorcide_git_mirror_evictions_total",
+			Help: "Total number of git mirrors removed to keep the cache under its size limit",
+		},
+	)
+	GitMirrors = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "reactorcide_git_mirrors",
+			Help: "Number of git mirrors in the worker's cache",
+		},
+	)
+	GitMirrorBytes = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "reactorcide_git_mirror_bytes",
+			Help: "Disk space used by the worker's git mirrors",
+		},
+	)
 )
 
 // Handler returns the Prometheus metrics handler
@@ -155,6 +356,19 @@ func RecordJobProcessed(queue, status, workerID string, duration float64) {
 	JobDuration.WithLabelValues(queue, status).Observe(duration)
 }
 
+// RecordJobResources records the resources a job's container used
+func RecordJobResources(queue string, cpuSeconds, peakMemoryBytes, rxBytes, txBytes float64) {
+	JobCPUSeconds.WithLabelValues(queue).Observe(cpuSeconds)
+	JobPeakMemory.WithLabelValues(queue).Observe(peakMemoryBytes)
+	JobNetworkBytes.WithLabelValues(queue, "rx").Observe(rxBytes)
+	JobNetworkBytes.WithLabelValues(queue, "tx").Observe(txBytes)
+}
+
+// RecordQueueSpillover records a job spilled over from queue
+func RecordQueueSpillover(queue, spilloverQueue string) {
+	QueueSpillovers.WithLabelValues(queue, spilloverQueue).Inc()
+}
+
 // RecordJobRetry records a job retry attempt
 func RecordJobRetry(queue, workerID string) {
 	JobRetries.WithLabelValues(queue, workerID).Inc()
@@ -212,3 +426,106 @@ func SetWorkersActive(queue string, count float64) {
 func SetWorkerJobsActive(workerID string, count float64) {
 	WorkerJobsActive.WithLabelValues(workerID).Set(count)
 }
+
+// RecordJobDeadlineMiss records a job missing its deadline
+func RecordJobDeadlineMiss(queue string) {
+	JobDeadlineMisses.WithLabelValues(queue).Inc()
+}
+
+// RecordJobDeadlineBoost records a deadline priority boost
+func RecordJobDeadlineBoost(queue string) {
+	JobDeadlineBoosts.WithLabelValues(queue).Inc()
+}
+
+// RecordJobBoost records a user's priority boost of a queued job
+func RecordJobBoost(queue string) {
+	JobBoosts.WithLabelValues(queue).Inc()
+}
+
+// RecordJobDeliveryFailure records a job claim that ended without a result
+func RecordJobDeliveryFailure(queue, reason string) {
+	JobDeliveryFailures.WithLabelValues(queue, reason).Inc()
+}
+
+// RecordJobDeadlettered records a job moved to the deadletter
+func RecordJobDeadlettered(queue string) {
+	JobsDeadlettered.WithLabelValues(queue).Inc()
+}
+
+// RecordJobAffinity records a worker's scheduling affinity decision for a job
+func RecordJobAffinity(queue, result string) {
+	JobAffinityDecisions.WithLabelValues(queue, result).Inc()
+}
+
+// SetVCSRateLimitRemaining records the remaining VCS API quota
+func SetVCSRateLimitRemaining(host, resource string, remaining float64) {
+	VCSRateLimitRemaining.WithLabelValues(host, resource).Set(remaining)
+}
+
+// RecordVCSRequestRetry records a retried VCS API request
+func RecordVCSRequestRetry(host, reason string) {
+	VCSRequestRetries.WithLabelValues(host, reason).Inc()
+}
+
+// RecordVCSCacheLookup records a VCS API response cache lookup
+func RecordVCSCacheLookup(host, result string) {
+	VCSCacheLookups.WithLabelValues(host, result).Inc()
+}
+
+// RecordVCSStatusDelivery records a VCS status outbox delivery attempt
+func RecordVCSStatusDelivery(kind, result string) {
+	VCSStatusDeliveries.WithLabelValues(kind, result).Inc()
+}
+
+// RecordDBSlowQuery records a slow database query
+func RecordDBSlowQuery(operation string) {
+	DBSlowQueries.WithLabelValues(operation).Inc()
+}
+
+// RecordWebhookShed records a webhook delivery turned away by load shedding
+func RecordWebhookShed(provider, reason string) {
+	WebhooksShed.WithLabelValues(provider, reason).Inc()
+}
+
+// RecordChaosFault records a fault injected by chaos mode
+func RecordChaosFault(fault string) {
+	ChaosFaultsInjected.WithLabelValues(fault).Inc()
+}
+
+// RecordAPIDeprecatedRequest records a request to a deprecated API route
+func RecordAPIDeprecatedRequest(route string) {
+	APIDeprecatedRequests.WithLabelValues(route).Inc()
+}
+
+// RecordGitMirrorRequest records a job's git mirror lookup
+func RecordGitMirrorRequest(result string) {
+	GitMirrorRequests.WithLabelValues(result).Inc()
+}
+
+// RecordGitMirrorFetch records a git mirror clone or fetch
+func RecordGitMirrorFetch(operation string, duration float64) {
+	GitMirrorFetchDuration.WithLabelValues(operation).Observe(duration)
+}
+
+// RecordGitMirrorEviction records a git mirror removed from the cache
+func RecordGitMirrorEviction() {
+	GitMirrorEvictions.Inc()
+}
+
+// SetGitMirrorUsage sets the git mirror cache's size
+func SetGitMirrorUsage(mirrors, bytes float64) {
+	GitMirrors.Set(mirrors)
+	GitMirrorBytes.Set(bytes)
+}
+
+// RegisterDBStats exports a database connection pool's stats (open, in use
+// and idle connections, and waits for a free one) as go_sql_* metrics
+// labelled db_name. Registering the same name twice is a no-op.
+func RegisterDBStats(db *sql.DB, name string) error {
+	err := prometheus.Register(collectors.NewDBStatsCollector(db, name))
+	var already prometheus.AlreadyRegisteredError
+	if errors.As(err, &already) {
+		return nil
+	}
+	return err
+}
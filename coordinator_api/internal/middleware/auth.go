@@ -1,18 +1,76 @@
 package middleware
 
 import (
+	"context"
+	"crypto/subtle"
+	"errors"
 	"net/http"
 	"strings"
 
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/auth"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
 )
 
+// BasicAuthenticator verifies HTTP Basic credentials, e.g. against LDAP
+// (see auth.LDAPAuthenticator).
+type BasicAuthenticator interface {
+	AuthenticateBasic(ctx context.Context, username, password string) (*models.User, error)
+}
+
+// SessionResolver resolves login session tokens (see auth.Sessions).
+type SessionResolver interface {
+	ResolveSession(ctx context.Context, token string) (*models.User, *models.UISession, error)
+}
+
+// SessionCookieName is the cookie holding a REST API login session token.
+const SessionCookieName = "reactorcide_session"
+
+// CSRFHeader carries the session's CSRF token (auth.CSRFToken) on
+// requests that change state with a session cookie.
+const CSRFHeader = "X-CSRF-Token"
+
 // APITokenMiddleware creates middleware that validates API tokens
 func APITokenMiddleware(appStore store.Store) func(http.Handler) http.Handler {
+	return AuthMiddleware(appStore, nil, nil)
+}
+
+// AuthMiddleware is APITokenMiddleware that also accepts HTTP Basic
+// credentials when basic is non-nil, and, when sessions is non-nil, a
+// session cookie from requests without an Authorization header. Cookie
+// requests other than GET, HEAD and OPTIONS must carry the CSRF token.
+func AuthMiddleware(appStore store.Store, basic BasicAuthenticator, sessions SessionResolver) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
+			if authHeader == "" && sessions != nil {
+				if cookie, err := r.Cookie(SessionCookieName); err == nil && cookie.Value != "" {
+					user, session, err := sessions.ResolveSession(r.Context(), cookie.Value)
+					if err != nil {
+						w.Header().Set("Content-Type", "application/json")
+						w.WriteHeader(http.StatusUnauthorized)
+						w.Write([]byte(`{"error":"unauthorized","message":"Session expired or invalid"}`))
+						return
+					}
+					if !isSafeMethod(r.Method) {
+						want := auth.CSRFToken(cookie.Value)
+						if subtle.ConstantTimeCompare([]byte(r.Header.Get(CSRFHeader)), []byte(want)) != 1 {
+							w.Header().Set("Content-Type", "application/json")
+							w.WriteHeader(http.StatusForbidden)
+							w.Write([]byte(`{"error":"forbidden","message":"Missing or invalid CSRF token"}`))
+							return
+						}
+					}
+					ctx := checkauth.SetUserContext(r.Context(), user)
+					ctx = checkauth.SetSessionContext(ctx, session)
+					ctx = checkauth.SetVerifiedContext(ctx, true)
+					next.ServeHTTP(w, r.WithContext(ctx))
+					return
+				}
+			}
+
 			if authHeader == "" {
 				w.Header().Set("Content-Type", "application/json")
 				w.WriteHeader(http.StatusUnauthorized)
@@ -20,6 +78,39 @@ func APITokenMiddleware(appStore store.Store) func(http.Handler) http.Handler {
 				return
 			}
 
+			if basic != nil && strings.HasPrefix(authHeader, "Basic ") {
+				username, password, ok := r.BasicAuth()
+				if !ok {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusUnauthorized)
+					w.Write([]byte(`{"error":"unauthorized","message":"Invalid Basic credentials"}`))
+					return
+				}
+				user, err := basic.AuthenticateBasic(r.Context(), username, password)
+				switch {
+				case errors.Is(err, auth.ErrUserDeactivated):
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusForbidden)
+					w.Write([]byte(`{"error":"forbidden","message":"User has been deactivated"}`))
+					return
+				case err != nil && !errors.Is(err, auth.ErrInvalidCredentials) && !errors.Is(err, auth.ErrServiceAccountLogin):
+					logging.Log.WithError(err).Error("Basic authentication failed")
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusServiceUnavailable)
+					w.Write([]byte(`{"error":"service_unavailable","message":"Authentication service unavailable"}`))
+					return
+				case err != nil:
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusUnauthorized)
+					w.Write([]byte(`{"error":"unauthorized","message":"Invalid username or password"}`))
+					return
+				}
+				ctx := checkauth.SetUserContext(r.Context(), user)
+				ctx = checkauth.SetVerifiedContext(ctx, true)
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
 			if !strings.HasPrefix(authHeader, "Bearer ") {
 				w.Header().Set("Content-Type", "application/json")
 				w.WriteHeader(http.StatusUnauthorized)
@@ -44,12 +135,22 @@ func APITokenMiddleware(appStore store.Store) func(http.Handler) http.Handler {
 				return
 			}
 
+			if apiToken.ProjectID != nil {
+				var allowed bool
+				if r, allowed = projectTokenAllows(r, appStore, *apiToken.ProjectID); !allowed {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusForbidden)
+					w.Write([]byte(`{"error":"forbidden","message":"This token is bound to a project and can only run it and read its jobs"}`))
+					return
+				}
+			}
+
 			// TODO: Update last used timestamp asynchronously
 			// Disabled for now to avoid transaction conflicts in tests
-			_ = apiToken
 
-			// Add user and verification status to context
+			// Add user, token and verification status to context
 			ctx := checkauth.SetUserContext(r.Context(), user)
+			ctx = checkauth.SetAPITokenContext(ctx, apiToken)
 			ctx = checkauth.SetVerifiedContext(ctx, true)
 
 			next.ServeHTTP(w, r.WithContext(ctx))
@@ -57,6 +158,12 @@ func APITokenMiddleware(appStore store.Store) func(http.Handler) http.Handler {
 	}
 }
 
+// isSafeMethod reports whether method doesn't change state, so needs no
+// CSRF token.
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
 // VerificationMiddleware is a placeholder that was referenced in the existing code
 // For now, it just passes through to the next handler since we're using API tokens
 func VerificationMiddleware(next http.Handler) http.Handler {
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/auth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// basicAuthenticatorFunc adapts a function to BasicAuthenticator.
+type basicAuthenticatorFunc func(ctx context.Context, username, password string) (*models.User, error)
+
+func (f basicAuthenticatorFunc) AuthenticateBasic(ctx context.Context, username, password string) (*models.User, error) {
+	return f(ctx, username, password)
+}
+
+func TestAuthMiddleware_Basic(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"valid", nil, http.StatusOK},
+		{"invalid credentials", auth.ErrInvalidCredentials, http.StatusUnauthorized},
+		{"service account", auth.ErrServiceAccountLogin, http.StatusUnauthorized},
+		{"deactivated ldap user", auth.ErrUserDeactivated, http.StatusForbidden},
+		{"ldap unavailable", errors.New("auth: ldap: connection refused"), http.StatusServiceUnavailable},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			basic := basicAuthenticatorFunc(func(ctx context.Context, username, password string) (*models.User, error) {
+				if tc.err != nil {
+					return nil, tc.err
+				}
+				return &models.User{UserID: "user-1"}, nil
+			})
+			handler := AuthMiddleware(nil, basic, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/jobs", nil)
+			r.SetBasicAuth("alice", "secret")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			assert.Equal(t, tc.want, w.Code)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+)
+
+// projectTokenAllows reports whether a token bound to projectID (a deploy
+// token, see models.APIToken.ProjectID) may make r. Such a token can only:
+//
+//   - run the project: POST /api/v1/projects/{project_id}/run
+//   - create jobs in the project: POST /api/v1/jobs, whose handler sets
+//     the job's project_id to the token's and refuses any other
+//   - read the project: GET /api/v1/projects/{project_id}
+//   - list the project's jobs: GET /api/v1/jobs and /api/v2/jobs, which
+//     are limited to the project
+//   - read the project's jobs and their logs: GET /api/v1/jobs/{job_id},
+//     /api/v2/jobs/{job_id} and /api/v1/jobs/{job_id}/logs[/...]
+//
+// The request is returned with the job list's project filter applied.
+// Everything else the token's user could do is refused, so the token can
+// be handed to an external system without exposing the account.
+func projectTokenAllows(r *http.Request, appStore store.Store, projectID string) (*http.Request, bool) {
+	if rest, ok := strings.CutPrefix(r.URL.Path, "/api/v1/projects/"); ok {
+		id, action, _ := strings.Cut(strings.TrimSuffix(rest, "/"), "/")
+		if id != projectID {
+			return r, false
+		}
+		return r, (action == "" && r.Method == http.MethodGet) || (action == "run" && r.Method == http.MethodPost)
+	}
+
+	if r.Method == http.MethodPost && (r.URL.Path == "/api/v1/jobs" || r.URL.Path == "/api/v1/jobs/") {
+		return r, true
+	}
+	if r.Method != http.MethodGet {
+		return r, false
+	}
+	var rest string
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/api/v1/jobs"):
+		rest = strings.TrimPrefix(r.URL.Path, "/api/v1/jobs")
+	case strings.HasPrefix(r.URL.Path, "/api/v2/jobs"):
+		rest = strings.TrimPrefix(r.URL.Path, "/api/v2/jobs")
+	default:
+		return r, false
+	}
+	if rest == "" || rest == "/" {
+		query := r.URL.Query()
+		if filter := query.Get("project_id"); filter != "" && filter != projectID {
+			return r, false
+		}
+		query.Set("project_id", projectID)
+		r = r.Clone(r.Context())
+		r.URL.RawQuery = query.Encode()
+		return r, true
+	}
+
+	jobID, action, _ := strings.Cut(strings.TrimPrefix(rest, "/"), "/")
+	if action != "" && action != "logs" && !strings.HasPrefix(action, "logs/") {
+		return r, false
+	}
+	if action != "" && strings.HasPrefix(r.URL.Path, "/api/v2/") {
+		return r, false
+	}
+	job, err := appStore.GetJobByID(r.Context(), jobID)
+	if err != nil || job == nil || job.ProjectID == nil || *job.ProjectID != projectID {
+		return r, false
+	}
+	return r, true
+}
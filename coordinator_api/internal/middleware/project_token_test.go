@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// deployTokenStore validates one deploy token and knows two jobs, one of
+// them in the token's project.
+type deployTokenStore struct {
+	store.Store
+}
+
+func (deployTokenStore) ValidateAPIToken(ctx context.Context, token string) (*models.APIToken, *models.User, error) {
+	projectID := "project-1"
+	return &models.APIToken{TokenID: "token-1", UserID: "user-1", ProjectID: &projectID}, &models.User{UserID: "user-1"}, nil
+}
+
+func (deployTokenStore) GetJobByID(ctx context.Context, jobID string) (*models.Job, error) {
+	projectID := map[string]string{"job-1": "project-1", "job-2": "project-2"}[jobID]
+	if projectID == "" {
+		return nil, store.ErrNotFound
+	}
+	return &models.Job{JobID: jobID, ProjectID: &projectID}, nil
+}
+
+func TestDeployTokens(t *testing.T) {
+	var query string
+	handler := AuthMiddleware(deployTokenStore{}, nil, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.WriteHeader(http.StatusOK)
+	}))
+	do := func(method, target string) int {
+		r := httptest.NewRequest(method, target, nil)
+		r.Header.Set("Authorization", "Bearer deploy-token")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	for _, allowed := range []struct{ method, target string }{
+		{http.MethodPost, "/api/v1/projects/project-1/run"},
+		{http.MethodPost, "/api/v1/jobs"},
+		{http.MethodGet, "/api/v1/projects/project-1"},
+		{http.MethodGet, "/api/v1/jobs/job-1"},
+		{http.MethodGet, "/api/v2/jobs/job-1"},
+		{http.MethodGet, "/api/v1/jobs/job-1/logs"},
+		{http.MethodGet, "/api/v1/jobs/job-1/logs/chunks/stdout"},
+	} {
+		assert.Equal(t, http.StatusOK, do(allowed.method, allowed.target), "%s %s", allowed.method, allowed.target)
+	}
+	for _, refused := range []struct{ method, target string }{
+		{http.MethodPost, "/api/v1/projects/project-2/run"},
+		{http.MethodPut, "/api/v1/projects/project-1"},
+		{http.MethodGet, "/api/v1/projects/project-1/canary"},
+		{http.MethodGet, "/api/v1/projects"},
+		{http.MethodPost, "/api/v2/jobs"},
+		{http.MethodPost, "/api/v1/jobs/job-1/retry"},
+		{http.MethodGet, "/api/v1/jobs/job-2"},
+		{http.MethodGet, "/api/v1/jobs/job-2/logs"},
+		{http.MethodGet, "/api/v1/jobs/job-1/triggers"},
+		{http.MethodPut, "/api/v1/jobs/job-1/cancel"},
+		{http.MethodGet, "/api/v1/jobs?project_id=project-2"},
+		{http.MethodGet, "/api/v1/tokens"},
+		{http.MethodGet, "/api/v1/secrets/values"},
+	} {
+		assert.Equal(t, http.StatusForbidden, do(refused.method, refused.target), "%s %s", refused.method, refused.target)
+	}
+
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/v1/jobs?status=failed"))
+	assert.Equal(t, "project_id=project-1&status=failed", query, "job lists are limited to the project")
+}
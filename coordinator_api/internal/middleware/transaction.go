@@ -33,8 +33,9 @@ func TransactionMiddleware(next http.Handler) http.Handler {
 				return
 			}
 
-			// Begin a transaction
-			tx = db.Begin()
+			// Begin a transaction bound to the request, so a cancelled or
+			// timed out request stops waiting on the database
+			tx = db.WithContext(r.Context()).Begin()
 			if tx.Error != nil {
 				http.Error(w, "Failed to begin transaction", http.StatusInternalServerError)
 				return
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// WorkerAccessPolicy restricts who can reach the worker-facing endpoints,
+// on top of their API token: which networks requests may come from, and
+// whether they must arrive over mutual TLS.
+type WorkerAccessPolicy struct {
+	// AllowedNetworks are the networks requests may come from. Empty
+	// allows any.
+	AllowedNetworks []*net.IPNet
+	// TrustedProxies are the proxies whose X-Forwarded-For is believed
+	// for the client address.
+	TrustedProxies []*net.IPNet
+	// RequireClientCert refuses requests that didn't present a verified
+	// client certificate.
+	RequireClientCert bool
+}
+
+// Enabled reports whether the policy restricts anything.
+func (p WorkerAccessPolicy) Enabled() bool {
+	return len(p.AllowedNetworks) > 0 || p.RequireClientCert
+}
+
+// WorkerAccessMiddleware creates middleware that refuses requests the
+// policy doesn't allow with a 403, before the handler sees them.
+func WorkerAccessMiddleware(policy WorkerAccessPolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !policy.Enabled() {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if policy.RequireClientCert && (r.TLS == nil || len(r.TLS.VerifiedChains) == 0) {
+				writeWorkerAccessDenied(w, "Worker endpoints require a client certificate; use the worker TLS port")
+				return
+			}
+			if len(policy.AllowedNetworks) > 0 {
+				ip := ClientIP(r, policy.TrustedProxies)
+				if ip == nil || !containsIP(policy.AllowedNetworks, ip) {
+					writeWorkerAccessDenied(w, "Worker endpoints are not reachable from this network")
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func writeWorkerAccessDenied(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	w.Write([]byte(`{"error":"forbidden","message":"` + message + `"}`))
+}
+
+// ClientIP returns the address r came from. When the peer is one of
+// trustedProxies, it's the right-most X-Forwarded-For address that isn't
+// a trusted proxy itself, since proxies append the address they saw and
+// anything left of that is the client's to forge.
+func ClientIP(r *http.Request, trustedProxies []*net.IPNet) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !containsIP(trustedProxies, ip) {
+		return ip
+	}
+	values := r.Header.Values("X-Forwarded-For")
+	if len(values) == 0 {
+		return ip
+	}
+	forwarded := strings.Split(strings.Join(values, ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop := net.ParseIP(strings.TrimSpace(forwarded[i]))
+		if hop == nil {
+			return nil
+		}
+		if !containsIP(trustedProxies, hop) {
+			return hop
+		}
+		ip = hop
+	}
+	return ip
+}
+
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mustCIDRs(t *testing.T, cidrs ...string) []*net.IPNet {
+	t.Helper()
+	var networks []*net.IPNet
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			t.Fatalf("ParseCIDR(%q): %v", cidr, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+func TestWorkerAccessMiddlewareAllowlist(t *testing.T) {
+	handler := WorkerAccessMiddleware(WorkerAccessPolicy{
+		AllowedNetworks: mustCIDRs(t, "10.20.0.0/16"),
+		TrustedProxies:  mustCIDRs(t, "192.168.1.0/24"),
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	do := func(remoteAddr, forwardedFor string) int {
+		r := httptest.NewRequest(http.MethodPut, "/api/v1/jobs/job-1/logs/chunks/stdout/0", nil)
+		r.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			r.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, do("10.20.3.4:5000", ""))
+	assert.Equal(t, http.StatusForbidden, do("203.0.113.9:5000", ""))
+	// Forwarded addresses count only from trusted proxies.
+	assert.Equal(t, http.StatusForbidden, do("203.0.113.9:5000", "10.20.3.4"))
+	assert.Equal(t, http.StatusOK, do("192.168.1.10:5000", "10.20.3.4"))
+	assert.Equal(t, http.StatusOK, do("192.168.1.10:5000", "10.20.3.4, 192.168.1.11"))
+	// The client can't get around the check by prepending an address.
+	assert.Equal(t, http.StatusForbidden, do("192.168.1.10:5000", "10.20.3.4, 203.0.113.9"))
+	assert.Equal(t, http.StatusForbidden, do("192.168.1.10:5000", "not-an-ip"))
+}
+
+func TestWorkerAccessMiddlewareRequiresClientCert(t *testing.T) {
+	handler := WorkerAccessMiddleware(WorkerAccessPolicy{RequireClientCert: true})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/jobs/job-1/logs/chunks/stdout", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "client certificate")
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/jobs/job-1/logs/chunks/stdout", nil)
+	r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{}}}}
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestWorkerAccessMiddlewareDisabled(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	assert.False(t, WorkerAccessPolicy{TrustedProxies: mustCIDRs(t, "10.0.0.0/8")}.Enabled())
+	w := httptest.NewRecorder()
+	WorkerAccessMiddleware(WorkerAccessPolicy{})(next).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
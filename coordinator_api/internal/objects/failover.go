@@ -0,0 +1,252 @@
+package objects
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+)
+
+// Failover defaults.
+const (
+	DefaultFailoverThreshold  = 3
+	DefaultFailoverRetryAfter = time.Minute
+)
+
+// FailoverObjectStore writes to a primary object store and fails over to a
+// secondary one, e.g. a bucket in another region, when the primary keeps
+// failing. Writes go to exactly one store at a time: the primary, until
+// Threshold operations in a row fail on it, then the secondary. After
+// RetryAfter the next operation tries the primary again, and a success
+// fails back.
+//
+// Objects may live in either store, so reads, existence checks and
+// listings fall through to the other store, and deletes apply to both.
+type FailoverObjectStore struct {
+	primary    ObjectStore
+	secondary  ObjectStore
+	threshold  int
+	retryAfter time.Duration
+	now        func() time.Time
+
+	mu           sync.Mutex
+	failures     int
+	failedOverAt time.Time // Zero while writing to the primary
+}
+
+// failoverObjectStoreWithStorageClass is a FailoverObjectStore over two
+// stores that both support storage classes.
+type failoverObjectStoreWithStorageClass struct {
+	*FailoverObjectStore
+}
+
+// NewFailoverObjectStore wraps primary and secondary. A threshold or
+// retryAfter of zero takes the default. The result implements
+// StorageClassTransitioner if both stores do.
+func NewFailoverObjectStore(primary, secondary ObjectStore, threshold int, retryAfter time.Duration) ObjectStore {
+	if threshold <= 0 {
+		threshold = DefaultFailoverThreshold
+	}
+	if retryAfter <= 0 {
+		retryAfter = DefaultFailoverRetryAfter
+	}
+	store := &FailoverObjectStore{
+		primary:    primary,
+		secondary:  secondary,
+		threshold:  threshold,
+		retryAfter: retryAfter,
+		now:        time.Now,
+	}
+	_, primaryOK := primary.(StorageClassTransitioner)
+	_, secondaryOK := secondary.(StorageClassTransitioner)
+	if primaryOK && secondaryOK {
+		return failoverObjectStoreWithStorageClass{store}
+	}
+	return store
+}
+
+// FailedOver reports whether writes currently go to the secondary store.
+func (f *FailoverObjectStore) FailedOver() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.failedOverLocked()
+}
+
+func (f *FailoverObjectStore) failedOverLocked() bool {
+	return !f.failedOverAt.IsZero() && f.now().Sub(f.failedOverAt) < f.retryAfter
+}
+
+// stores returns the store writes go to, then the other one.
+func (f *FailoverObjectStore) stores() (active, other ObjectStore) {
+	if f.FailedOver() {
+		return f.secondary, f.primary
+	}
+	return f.primary, f.secondary
+}
+
+// record notes the outcome of an operation on the active store, failing
+// over or back as needed. Only the primary's outcomes while it's active
+// count, so reads falling through to it don't end a failover early; errors
+// about the request rather than the store don't count either.
+func (f *FailoverObjectStore) record(store ObjectStore, err error) {
+	if store != f.primary || isRequestError(err) {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err == nil {
+		if !f.failedOverAt.IsZero() {
+			logging.Log.Info("Primary object store recovered; failing back")
+		}
+		f.failures = 0
+		f.failedOverAt = time.Time{}
+		return
+	}
+	f.failures++
+	if f.failures >= f.threshold {
+		if !f.failedOverLocked() {
+			logging.Log.WithError(err).Warnf("Primary object store failed %d times in a row; failing over to the secondary", f.failures)
+		}
+		f.failedOverAt = f.now()
+	}
+}
+
+// isRequestError reports whether err says nothing about the store's health.
+func isRequestError(err error) bool {
+	return errors.Is(err, ErrNotFound) ||
+		errors.Is(err, ErrInvalidKey) ||
+		errors.Is(err, ErrNotSupported) ||
+		errors.Is(err, ErrAlreadyExists) ||
+		errors.Is(err, context.Canceled)
+}
+
+// Put writes to the active store. If the primary fails and data can be
+// rewound, the write is retried on the secondary.
+func (f *FailoverObjectStore) Put(ctx context.Context, key string, data io.Reader, contentType string) error {
+	active, other := f.stores()
+	err := active.Put(ctx, key, data, contentType)
+	f.record(active, err)
+	if err == nil || active != f.primary || isRequestError(err) {
+		return err
+	}
+	seeker, ok := data.(io.Seeker)
+	if !ok {
+		return err
+	}
+	if _, seekErr := seeker.Seek(0, io.SeekStart); seekErr != nil {
+		return err
+	}
+	return other.Put(ctx, key, data, contentType)
+}
+
+// Get reads from the active store, falling through to the other.
+func (f *FailoverObjectStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	active, other := f.stores()
+	reader, err := active.Get(ctx, key)
+	f.record(active, err)
+	if err == nil {
+		return reader, nil
+	}
+	reader, otherErr := other.Get(ctx, key)
+	if otherErr == nil {
+		return reader, nil
+	}
+	return nil, firstFailure(err, otherErr)
+}
+
+// GetURL returns a URL from whichever store holds the object.
+func (f *FailoverObjectStore) GetURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	store, err := f.holder(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	return store.GetURL(ctx, key, expires)
+}
+
+// Delete deletes from both stores.
+func (f *FailoverObjectStore) Delete(ctx context.Context, key string) error {
+	active, _ := f.stores()
+	primaryErr := f.primary.Delete(ctx, key)
+	f.record(active, primaryErr)
+	secondaryErr := f.secondary.Delete(ctx, key)
+	if primaryErr == nil || secondaryErr == nil {
+		return nil
+	}
+	return firstFailure(primaryErr, secondaryErr)
+}
+
+// Exists checks the active store, then the other.
+func (f *FailoverObjectStore) Exists(ctx context.Context, key string) (bool, error) {
+	store, err := f.holder(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	return store != nil, err
+}
+
+// List merges both stores' listings; the active store's entry wins for a
+// key in both. It fails only if both stores do.
+func (f *FailoverObjectStore) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	active, other := f.stores()
+	activeObjs, activeErr := active.List(ctx, prefix)
+	f.record(active, activeErr)
+	otherObjs, otherErr := other.List(ctx, prefix)
+	if activeErr != nil && otherErr != nil {
+		return nil, activeErr
+	}
+
+	seen := make(map[string]bool, len(activeObjs))
+	merged := append([]ObjectInfo(nil), activeObjs...)
+	for _, obj := range activeObjs {
+		seen[obj.Key] = true
+	}
+	for _, obj := range otherObjs {
+		if !seen[obj.Key] {
+			merged = append(merged, obj)
+		}
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Key < merged[j].Key })
+	return merged, nil
+}
+
+// holder returns the store holding key, checking the active store first.
+func (f *FailoverObjectStore) holder(ctx context.Context, key string) (ObjectStore, error) {
+	active, other := f.stores()
+	exists, err := active.Exists(ctx, key)
+	f.record(active, err)
+	if err == nil && exists {
+		return active, nil
+	}
+	otherExists, otherErr := other.Exists(ctx, key)
+	if otherErr == nil && otherExists {
+		return other, nil
+	}
+	if err == nil && otherErr == nil {
+		return nil, ErrNotFound
+	}
+	return nil, firstFailure(err, otherErr)
+}
+
+// firstFailure returns the first error that isn't ErrNotFound, or
+// ErrNotFound if both are.
+func firstFailure(errs ...error) error {
+	for _, err := range errs {
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+	return ErrNotFound
+}
+
+// SetStorageClass changes the storage class in whichever store holds key.
+func (f failoverObjectStoreWithStorageClass) SetStorageClass(ctx context.Context, key, storageClass string) error {
+	store, err := f.holder(ctx, key)
+	if err != nil {
+		return err
+	}
+	return store.(StorageClassTransitioner).SetStorageClass(ctx, key, storageClass)
+}
@@ -0,0 +1,133 @@
+package objects
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// flakyObjectStore fails every operation while down.
+type flakyObjectStore struct {
+	*MemoryObjectStore
+	down bool
+}
+
+var errStoreDown = errors.New("store unreachable")
+
+func (s *flakyObjectStore) Put(ctx context.Context, key string, data io.Reader, contentType string) error {
+	if s.down {
+		return errStoreDown
+	}
+	return s.MemoryObjectStore.Put(ctx, key, data, contentType)
+}
+
+func (s *flakyObjectStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if s.down {
+		return nil, errStoreDown
+	}
+	return s.MemoryObjectStore.Get(ctx, key)
+}
+
+func (s *flakyObjectStore) Exists(ctx context.Context, key string) (bool, error) {
+	if s.down {
+		return false, errStoreDown
+	}
+	return s.MemoryObjectStore.Exists(ctx, key)
+}
+
+func (s *flakyObjectStore) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	if s.down {
+		return nil, errStoreDown
+	}
+	return s.MemoryObjectStore.List(ctx, prefix)
+}
+
+func readObject(t *testing.T, store ObjectStore, key string) string {
+	t.Helper()
+	reader, err := store.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Get(%s) failed: %v", key, err)
+	}
+	defer reader.Close()
+	data, _ := io.ReadAll(reader)
+	return string(data)
+}
+
+func TestFailoverObjectStore_FailsOverAndBack(t *testing.T) {
+	ctx := context.Background()
+	primary := &flakyObjectStore{MemoryObjectStore: NewMemoryObjectStore()}
+	secondary := NewMemoryObjectStore()
+	store := NewFailoverObjectStore(primary, secondary, 2, time.Minute).(*FailoverObjectStore)
+	now := time.Unix(1700000000, 0)
+	store.now = func() time.Time { return now }
+
+	if err := store.Put(ctx, "logs/a", strings.NewReader("a"), "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+
+	primary.down = true
+	// The first failure retries on the secondary but doesn't fail over.
+	if err := store.Put(ctx, "logs/b", strings.NewReader("b"), "text/plain"); err != nil {
+		t.Fatalf("expected the write retried on the secondary, got %v", err)
+	}
+	if store.FailedOver() {
+		t.Fatal("expected no failover after one failure")
+	}
+	if err := store.Put(ctx, "logs/c", strings.NewReader("c"), "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+	if !store.FailedOver() {
+		t.Fatal("expected failover after two failures")
+	}
+
+	// Reads fall through to whichever store holds the object.
+	primary.down = false
+	if got := readObject(t, store, "logs/a"); got != "a" {
+		t.Errorf("expected a, got %q", got)
+	}
+	if got := readObject(t, store, "logs/c"); got != "c" {
+		t.Errorf("expected c, got %q", got)
+	}
+	objs, err := store.List(ctx, "logs/")
+	if err != nil || len(objs) != 3 {
+		t.Errorf("expected 3 objects across both stores, got %v (%v)", objs, err)
+	}
+
+	// Until RetryAfter passes, writes keep going to the secondary.
+	if err := store.Put(ctx, "logs/d", strings.NewReader("d"), "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+	if exists, _ := primary.MemoryObjectStore.Exists(ctx, "logs/d"); exists {
+		t.Error("expected the write to go to the secondary while failed over")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if store.FailedOver() {
+		t.Fatal("expected the primary retried after RetryAfter")
+	}
+	if err := store.Put(ctx, "logs/e", strings.NewReader("e"), "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+	if exists, _ := primary.MemoryObjectStore.Exists(ctx, "logs/e"); !exists {
+		t.Error("expected the write to go to the recovered primary")
+	}
+}
+
+func TestFailoverObjectStore_NotFoundDoesNotFailOver(t *testing.T) {
+	store := NewFailoverObjectStore(NewMemoryObjectStore(), NewMemoryObjectStore(), 1, time.Minute).(*FailoverObjectStore)
+	if _, err := store.Get(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if exists, err := store.Exists(context.Background(), "missing"); exists || err != nil {
+		t.Errorf("expected not found without error, got %v, %v", exists, err)
+	}
+	if err := store.Delete(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if store.FailedOver() {
+		t.Error("expected missing objects not to count as failures")
+	}
+}
@@ -0,0 +1,95 @@
+package objects
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// FaultFunc decides whether an object store operation fails, returning
+// the error to fail it with. op is the method name ("Put", "Get", ...).
+type FaultFunc func(op string) error
+
+// faultInjector, if set, wraps every primary store NewObjectStore builds.
+// See SetFaultInjector.
+var faultInjector FaultFunc
+
+// SetFaultInjector makes NewObjectStore wrap the primary store of every
+// store it builds from then on with NewFaultyObjectStore, so injected
+// failures also exercise failover. For chaos testing only (see
+// internal/chaos); nil turns it off.
+func SetFaultInjector(fault FaultFunc) {
+	faultInjector = fault
+}
+
+// FaultyObjectStore fails operations of the store it wraps when its
+// FaultFunc says so, before they reach the store.
+type FaultyObjectStore struct {
+	store ObjectStore
+	fault FaultFunc
+}
+
+// faultyObjectStoreWithStorageClass is a FaultyObjectStore over a store
+// that supports storage classes.
+type faultyObjectStoreWithStorageClass struct {
+	*FaultyObjectStore
+}
+
+// NewFaultyObjectStore wraps store. The result implements
+// StorageClassTransitioner if store does.
+func NewFaultyObjectStore(store ObjectStore, fault FaultFunc) ObjectStore {
+	faulty := &FaultyObjectStore{store: store, fault: fault}
+	if _, ok := store.(StorageClassTransitioner); ok {
+		return faultyObjectStoreWithStorageClass{faulty}
+	}
+	return faulty
+}
+
+func (f *FaultyObjectStore) Put(ctx context.Context, key string, data io.Reader, contentType string) error {
+	if err := f.fault("Put"); err != nil {
+		return err
+	}
+	return f.store.Put(ctx, key, data, contentType)
+}
+
+func (f *FaultyObjectStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := f.fault("Get"); err != nil {
+		return nil, err
+	}
+	return f.store.Get(ctx, key)
+}
+
+func (f *FaultyObjectStore) GetURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	if err := f.fault("GetURL"); err != nil {
+		return "", err
+	}
+	return f.store.GetURL(ctx, key, expires)
+}
+
+func (f *FaultyObjectStore) Delete(ctx context.Context, key string) error {
+	if err := f.fault("Delete"); err != nil {
+		return err
+	}
+	return f.store.Delete(ctx, key)
+}
+
+func (f *FaultyObjectStore) Exists(ctx context.Context, key string) (bool, error) {
+	if err := f.fault("Exists"); err != nil {
+		return false, err
+	}
+	return f.store.Exists(ctx, key)
+}
+
+func (f *FaultyObjectStore) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	if err := f.fault("List"); err != nil {
+		return nil, err
+	}
+	return f.store.List(ctx, prefix)
+}
+
+func (f faultyObjectStoreWithStorageClass) SetStorageClass(ctx context.Context, key, storageClass string) error {
+	if err := f.fault("SetStorageClass"); err != nil {
+		return err
+	}
+	return f.store.(StorageClassTransitioner).SetStorageClass(ctx, key, storageClass)
+}
@@ -40,7 +40,10 @@ func (f *FilesystemObjectStore) Put(ctx context.Context, key string, data io.Rea
 	}
 	defer file.Close()
 
-	_, err = io.Copy(file, data)
+	if _, err = io.Copy(file, contextReader{ctx: ctx, r: data}); err != nil {
+		// Don't leave a truncated object behind a cancelled upload.
+		os.Remove(fullPath)
+	}
 	return err
 }
 
@@ -120,6 +123,9 @@ func (f *FilesystemObjectStore) List(ctx context.Context, prefix string) ([]Obje
 		if err != nil {
 			return err
 		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 
 		// Skip directories
 		if info.IsDir() {
@@ -198,3 +204,17 @@ func (f *FilesystemObjectStore) guessContentType(key string) string {
 		return "application/octet-stream"
 	}
 }
+
+// contextReader stops reading once ctx is done, so copying a large object
+// gives up with the request.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
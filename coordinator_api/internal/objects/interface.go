@@ -3,6 +3,7 @@ package objects
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"time"
 )
@@ -41,16 +42,50 @@ type ObjectInfo struct {
 	Size         int64     `json:"size"`
 	LastModified time.Time `json:"last_modified"`
 	ContentType  string    `json:"content_type"`
+	// StorageClass is the object's storage class, for stores that have
+	// them (see StorageClassTransitioner). Empty means the default.
+	StorageClass string `json:"storage_class,omitempty"`
+}
+
+// StorageClassTransitioner is implemented by object stores that can move
+// an object to another storage class in place, keeping its key.
+type StorageClassTransitioner interface {
+	SetStorageClass(ctx context.Context, key, storageClass string) error
 }
 
 // ObjectStoreConfig contains configuration for object store implementations
 type ObjectStoreConfig struct {
 	Type   string            `json:"type"` // "s3", "gcs", "filesystem", "memory"
 	Config map[string]string `json:"config"`
+
+	// Secondary, if set, is the store to fail over to (see
+	// FailoverObjectStore), after FailoverThreshold consecutive failures,
+	// retrying the primary after FailoverRetryAfter.
+	Secondary          *ObjectStoreConfig `json:"secondary,omitempty"`
+	FailoverThreshold  int                `json:"failover_threshold,omitempty"`
+	FailoverRetryAfter time.Duration      `json:"failover_retry_after,omitempty"`
 }
 
 // NewObjectStore creates a new object store based on the provided configuration
 func NewObjectStore(config ObjectStoreConfig) (ObjectStore, error) {
+	primary, err := newObjectStore(config)
+	if err != nil {
+		return nil, err
+	}
+	if faultInjector != nil {
+		primary = NewFaultyObjectStore(primary, faultInjector)
+	}
+	if config.Secondary == nil {
+		return primary, nil
+	}
+	secondary, err := newObjectStore(*config.Secondary)
+	if err != nil {
+		return nil, fmt.Errorf("secondary object store: %w", err)
+	}
+	return NewFailoverObjectStore(primary, secondary, config.FailoverThreshold, config.FailoverRetryAfter), nil
+}
+
+func newObjectStore(config ObjectStoreConfig) (ObjectStore, error) {
 	switch config.Type {
 	case "filesystem":
 		basePath := config.Config["base_path"]
@@ -66,7 +101,16 @@ func NewObjectStore(config ObjectStoreConfig) (ObjectStore, error) {
 			return nil, errors.New("S3 bucket is required")
 		}
 		prefix := config.Config["prefix"]
-		return NewS3ObjectStoreFromEnv(bucket, prefix)
+		cfg := s3ConfigFromEnv(bucket, prefix)
+		// A secondary store in another region needs its own region and
+		// endpoint; the primary's come from the environment.
+		if region := config.Config["region"]; region != "" {
+			cfg.Region = region
+		}
+		if endpoint := config.Config["endpoint"]; endpoint != "" {
+			cfg.Endpoint = endpoint
+		}
+		return NewS3ObjectStore(cfg)
 	case "gcs":
 		return nil, errors.New("GCS object store not implemented yet")
 	default:
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -202,9 +203,10 @@ func (s *S3ObjectStore) List(ctx context.Context, prefix string) ([]ObjectInfo,
 
 		for _, obj := range page.Contents {
 			objects = append(objects, ObjectInfo{
-				Key:          aws.ToString(obj.Key),
+				Key:          strings.TrimPrefix(aws.ToString(obj.Key), s.prefix),
 				Size:         aws.ToInt64(obj.Size),
 				LastModified: aws.ToTime(obj.LastModified),
+				StorageClass: string(obj.StorageClass),
 			})
 		}
 	}
@@ -212,8 +214,33 @@ func (s *S3ObjectStore) List(ctx context.Context, prefix string) ([]ObjectInfo,
 	return objects, nil
 }
 
+// SetStorageClass moves an object to another storage class (e.g.
+// STANDARD_IA) by copying it onto itself.
+func (s *S3ObjectStore) SetStorageClass(ctx context.Context, key, storageClass string) error {
+	fullKey := s.fullKey(key)
+	_, err := s.client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:            aws.String(s.bucket),
+		Key:               aws.String(fullKey),
+		CopySource:        aws.String(s.bucket + "/" + fullKey),
+		StorageClass:      types.StorageClass(storageClass),
+		MetadataDirective: types.MetadataDirectiveCopy,
+	})
+	if err != nil {
+		if isS3NotFound(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to set storage class: %w", err)
+	}
+	return nil
+}
+
 // NewS3ObjectStoreFromEnv creates an S3 object store using environment variables
 func NewS3ObjectStoreFromEnv(bucket, prefix string) (*S3ObjectStore, error) {
+	return NewS3ObjectStore(s3ConfigFromEnv(bucket, prefix))
+}
+
+// s3ConfigFromEnv returns the S3 configuration from environment variables.
+func s3ConfigFromEnv(bucket, prefix string) S3Config {
 	cfg := S3Config{
 		Bucket:    bucket,
 		Prefix:    prefix,
@@ -227,5 +254,5 @@ func NewS3ObjectStoreFromEnv(bucket, prefix string) (*S3ObjectStore, error) {
 		cfg.Region = "us-east-1"
 	}
 
-	return NewS3ObjectStore(cfg)
+	return cfg
 }
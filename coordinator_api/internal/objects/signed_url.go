@@ -0,0 +1,74 @@
+package objects
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignedObjectPath is where the coordinator serves objects by signed URL.
+const SignedObjectPath = "/api/v1/objects/signed"
+
+var (
+	ErrURLExpired       = errors.New("signed URL expired")
+	ErrInvalidSignature = errors.New("invalid URL signature")
+)
+
+// URLSigner issues expiring download URLs that the coordinator serves
+// itself (at SignedObjectPath), for object stores that can't presign
+// their own. Every coordinator replica must share the signing key.
+type URLSigner struct {
+	key     []byte
+	baseURL string
+	now     func() time.Time
+}
+
+// NewURLSigner creates a signer. baseURL is the coordinator's public URL;
+// empty makes the URLs relative to the API.
+func NewURLSigner(key []byte, baseURL string) *URLSigner {
+	return &URLSigner{
+		key:     key,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		now:     time.Now,
+	}
+}
+
+// SignURL returns a URL for key that is valid until the returned time.
+func (s *URLSigner) SignURL(key string, expires time.Duration) (string, time.Time) {
+	expiresAt := s.now().Add(expires).UTC().Truncate(time.Second)
+	exp := strconv.FormatInt(expiresAt.Unix(), 10)
+	query := url.Values{}
+	query.Set("key", key)
+	query.Set("expires", exp)
+	query.Set("sig", s.sign(key, exp))
+	return s.baseURL + SignedObjectPath + "?" + query.Encode(), expiresAt
+}
+
+// Verify checks a signed URL's query parameters and returns its key.
+func (s *URLSigner) Verify(query url.Values) (string, error) {
+	key, exp, sig := query.Get("key"), query.Get("expires"), query.Get("sig")
+	if key == "" || !hmac.Equal([]byte(sig), []byte(s.sign(key, exp))) {
+		return "", ErrInvalidSignature
+	}
+	expiresAt, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil {
+		return "", ErrInvalidSignature
+	}
+	if s.now().Unix() >= expiresAt {
+		return "", ErrURLExpired
+	}
+	return key, nil
+}
+
+func (s *URLSigner) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(key))
+	mac.Write([]byte{0})
+	mac.Write([]byte(expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -27,6 +27,12 @@ const (
 	// EventLogAvailable fires when a new log chunk has been flushed to
 	// object storage and is ready to be read.
 	EventLogAvailable EventType = "log_available"
+	// EventJobStuck fires when the stuck-job monitor flags a running job.
+	// Reason is one of the models.StuckReason* values.
+	EventJobStuck EventType = "job_stuck"
+	// EventJobDeadlineMissed fires when the deadline monitor finds a job
+	// not completed by its deadline.
+	EventJobDeadlineMissed EventType = "job_deadline_missed"
 )
 
 // Event is the unit of work on the bus. Not all fields are meaningful for
@@ -39,6 +45,7 @@ type Event struct {
 	Stream    string    `json:"stream,omitempty"`
 	Offset    int64     `json:"offset,omitempty"`
 	Length    int64     `json:"length,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
 }
 
 // Subscription is the handle a caller holds onto while listening. Close
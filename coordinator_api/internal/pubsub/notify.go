@@ -75,6 +75,32 @@ func (p *Publisher) PublishLogAvailable(ctx context.Context, jobID, stream strin
 	})
 }
 
+// PublishJobStuck signals that the stuck-job monitor flagged a running
+// job, so UIs watching it can warn before (or instead of) remediation.
+func (p *Publisher) PublishJobStuck(ctx context.Context, jobID, status, reason string) {
+	if p == nil || p.pool == nil {
+		return
+	}
+	_ = Publish(ctx, p.pool, Event{
+		Type:   EventJobStuck,
+		JobID:  jobID,
+		Status: status,
+		Reason: reason,
+	})
+}
+
+// PublishJobDeadlineMissed signals that a job missed its deadline.
+func (p *Publisher) PublishJobDeadlineMissed(ctx context.Context, jobID, status string) {
+	if p == nil || p.pool == nil {
+		return
+	}
+	_ = Publish(ctx, p.pool, Event{
+		Type:   EventJobDeadlineMissed,
+		JobID:  jobID,
+		Status: status,
+	})
+}
+
 // NotifyListener holds a dedicated Postgres connection that LISTENs on
 // NotifyChannel and forwards every notification into the local Bus.
 //
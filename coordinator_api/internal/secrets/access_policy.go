@@ -0,0 +1,209 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidAccessPolicy is returned for a policy with an unknown subject
+// type or access level, an empty subject, or a malformed path pattern.
+var ErrInvalidAccessPolicy = errors.New("invalid secret access policy")
+
+// Principal is who is accessing secrets, as access policies see them.
+type Principal struct {
+	UserID string
+	Roles  []string
+	// TokenScopes are the scopes of the API token in use. Empty for
+	// unscoped tokens and sessions.
+	TokenScopes []string
+}
+
+// AccessPolicy decides a principal's access to an org's secret paths.
+//
+// A principal using a scoped API token is governed only by token_scope
+// policies for its scopes, and has no access where none applies. Anyone
+// else is governed by the user and role policies that apply to them, and
+// keeps the access the org already gave them where none applies. Among the
+// policies that apply to a path, the most specific pattern wins (an exact
+// path, then the longest /* prefix, then *); at equal specificity the
+// least access wins.
+type AccessPolicy struct {
+	principal Principal
+	policies  []models.SecretAccessPolicy
+}
+
+// NewAccessPolicy builds the principal's access policy from the org's
+// policies.
+func NewAccessPolicy(principal Principal, policies []models.SecretAccessPolicy) *AccessPolicy {
+	return &AccessPolicy{principal: principal, policies: policies}
+}
+
+// LoadAccessPolicy loads the org's policies and builds the principal's
+// access policy from them.
+func LoadAccessPolicy(ctx context.Context, db *gorm.DB, orgID string, principal Principal) (*AccessPolicy, error) {
+	policies, err := ListAccessPolicies(ctx, db, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return NewAccessPolicy(principal, policies), nil
+}
+
+// Access returns the principal's access to path: SecretAccessNone,
+// SecretAccessRead or SecretAccessWrite.
+func (a *AccessPolicy) Access(path string) string {
+	scoped := len(a.principal.TokenScopes) > 0
+	best, bestSpecificity := "", -1
+	for _, p := range a.policies {
+		if !a.applies(p, scoped) || !MatchSecretPath(p.PathPattern, path) {
+			continue
+		}
+		specificity := pathPatternSpecificity(p.PathPattern)
+		if specificity > bestSpecificity || (specificity == bestSpecificity && accessRank(p.Access) < accessRank(best)) {
+			best, bestSpecificity = p.Access, specificity
+		}
+	}
+	if best != "" {
+		return best
+	}
+	if scoped {
+		return models.SecretAccessNone
+	}
+	return models.SecretAccessWrite
+}
+
+// Allows reports whether the principal has at least access to path.
+func (a *AccessPolicy) Allows(path, access string) bool {
+	return accessRank(a.Access(path)) >= accessRank(access)
+}
+
+func (a *AccessPolicy) applies(p models.SecretAccessPolicy, scoped bool) bool {
+	switch p.SubjectType {
+	case models.SecretSubjectTokenScope:
+		return scoped && containsString(a.principal.TokenScopes, p.Subject)
+	case models.SecretSubjectUser:
+		return !scoped && p.Subject == a.principal.UserID
+	case models.SecretSubjectRole:
+		return !scoped && containsString(a.principal.Roles, p.Subject)
+	}
+	return false
+}
+
+// MatchSecretPath reports whether path matches pattern: * matches every
+// path, prefix/* matches prefix and every path below it, and anything else
+// only itself.
+func MatchSecretPath(pattern, path string) bool {
+	if pattern == "*" {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return path == prefix || strings.HasPrefix(path, prefix+"/")
+	}
+	return pattern == path
+}
+
+// pathPatternSpecificity ranks patterns so an exact path beats a /* prefix
+// of the same path, and longer prefixes beat shorter ones.
+func pathPatternSpecificity(pattern string) int {
+	if pattern == "*" {
+		return 0
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return 2 * len(prefix)
+	}
+	return 2*len(pattern) + 1
+}
+
+func accessRank(access string) int {
+	switch access {
+	case models.SecretAccessRead:
+		return 1
+	case models.SecretAccessWrite:
+		return 2
+	case models.SecretAccessNone:
+		return 0
+	}
+	// No access chosen yet ranks above everything, so any policy beats it.
+	return 3
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateAccessPolicy checks a policy's subject, access and path pattern.
+func ValidateAccessPolicy(p *models.SecretAccessPolicy) error {
+	switch p.SubjectType {
+	case models.SecretSubjectUser, models.SecretSubjectRole, models.SecretSubjectTokenScope:
+	default:
+		return fmt.Errorf("%w: subject_type must be user, role or token_scope", ErrInvalidAccessPolicy)
+	}
+	if strings.TrimSpace(p.Subject) == "" {
+		return fmt.Errorf("%w: subject is required", ErrInvalidAccessPolicy)
+	}
+	switch p.Access {
+	case models.SecretAccessNone, models.SecretAccessRead, models.SecretAccessWrite:
+	default:
+		return fmt.Errorf("%w: access must be read, write or none", ErrInvalidAccessPolicy)
+	}
+	if p.PathPattern != "*" {
+		if err := validatePath(strings.TrimSuffix(p.PathPattern, "/*")); err != nil {
+			return fmt.Errorf("%w: path_pattern must be a path, path/* or *", ErrInvalidAccessPolicy)
+		}
+	}
+	return nil
+}
+
+// ListAccessPolicies returns the org's secret access policies.
+func ListAccessPolicies(ctx context.Context, db *gorm.DB, orgID string) ([]models.SecretAccessPolicy, error) {
+	var policies []models.SecretAccessPolicy
+	err := db.WithContext(ctx).
+		Where("org_id = ?", orgID).
+		Order("subject_type, subject, path_pattern").
+		Find(&policies).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secret access policies: %w", err)
+	}
+	return policies, nil
+}
+
+// PutAccessPolicy creates the policy, or replaces the access and
+// description of the org's policy for the same subject and path pattern.
+func PutAccessPolicy(ctx context.Context, db *gorm.DB, p *models.SecretAccessPolicy) error {
+	if err := ValidateAccessPolicy(p); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	p.CreatedAt, p.UpdatedAt = now, now
+	err := db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "org_id"}, {Name: "subject_type"}, {Name: "subject"}, {Name: "path_pattern"}},
+		DoUpdates: clause.AssignmentColumns([]string{"access", "description", "updated_at"}),
+	}).Create(p).Error
+	if err != nil {
+		return fmt.Errorf("failed to save secret access policy: %w", err)
+	}
+	return nil
+}
+
+// DeleteAccessPolicy removes one of the org's policies. Returns true if it
+// existed.
+func DeleteAccessPolicy(ctx context.Context, db *gorm.DB, orgID, policyID string) (bool, error) {
+	result := db.WithContext(ctx).
+		Where("org_id = ? AND policy_id = ?", orgID, policyID).
+		Delete(&models.SecretAccessPolicy{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to delete secret access policy: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,153 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+func testPolicy(subjectType, subject, pattern, access string) models.SecretAccessPolicy {
+	return models.SecretAccessPolicy{SubjectType: subjectType, Subject: subject, PathPattern: pattern, Access: access}
+}
+
+func TestAccessPolicy_DeployTokenVersusDeveloperToken(t *testing.T) {
+	policies := []models.SecretAccessPolicy{
+		testPolicy(models.SecretSubjectTokenScope, "deploy", "deploy/prod/*", models.SecretAccessRead),
+		testPolicy(models.SecretSubjectTokenScope, "developer", "dev/*", models.SecretAccessWrite),
+	}
+
+	deploy := NewAccessPolicy(Principal{UserID: "org", TokenScopes: []string{"deploy"}}, policies)
+	if !deploy.Allows("deploy/prod/db", models.SecretAccessRead) {
+		t.Error("expected the deploy token to read deploy/prod/db")
+	}
+	if deploy.Allows("deploy/prod/db", models.SecretAccessWrite) {
+		t.Error("expected the deploy token not to write deploy/prod/db")
+	}
+	if deploy.Allows("dev/app", models.SecretAccessRead) {
+		t.Error("expected the deploy token not to read dev/app")
+	}
+
+	developer := NewAccessPolicy(Principal{UserID: "org", TokenScopes: []string{"developer"}}, policies)
+	if developer.Allows("deploy/prod/db", models.SecretAccessRead) {
+		t.Error("expected the developer token not to read deploy/prod/db")
+	}
+	if !developer.Allows("dev/app", models.SecretAccessWrite) {
+		t.Error("expected the developer token to write dev/app")
+	}
+}
+
+func TestAccessPolicy_Precedence(t *testing.T) {
+	policies := []models.SecretAccessPolicy{
+		testPolicy(models.SecretSubjectRole, "dev", "*", models.SecretAccessWrite),
+		testPolicy(models.SecretSubjectRole, "dev", "deploy/*", models.SecretAccessRead),
+		testPolicy(models.SecretSubjectRole, "dev", "deploy/prod/*", models.SecretAccessNone),
+		testPolicy(models.SecretSubjectRole, "dev", "deploy/prod/public", models.SecretAccessRead),
+		testPolicy(models.SecretSubjectUser, "alice", "shared/*", models.SecretAccessWrite),
+		testPolicy(models.SecretSubjectRole, "dev", "shared/*", models.SecretAccessRead),
+	}
+	access := NewAccessPolicy(Principal{UserID: "alice", Roles: []string{"dev"}}, policies)
+
+	tests := map[string]string{
+		"app":                models.SecretAccessWrite,
+		"deploy/staging":     models.SecretAccessRead,
+		"deploy/prod":        models.SecretAccessNone,
+		"deploy/prod/db":     models.SecretAccessNone,
+		"deploy/prod/public": models.SecretAccessRead,
+		"shared/config":      models.SecretAccessRead, // tie, least access wins
+	}
+	for path, want := range tests {
+		if got := access.Access(path); got != want {
+			t.Errorf("%s: expected %s, got %s", path, want, got)
+		}
+	}
+}
+
+func TestAccessPolicy_Defaults(t *testing.T) {
+	policies := []models.SecretAccessPolicy{
+		testPolicy(models.SecretSubjectTokenScope, "deploy", "deploy/*", models.SecretAccessRead),
+		testPolicy(models.SecretSubjectUser, "bob", "private/*", models.SecretAccessNone),
+	}
+
+	unscoped := NewAccessPolicy(Principal{UserID: "alice"}, policies)
+	if got := unscoped.Access("anything"); got != models.SecretAccessWrite {
+		t.Errorf("expected unscoped principals to keep write access, got %s", got)
+	}
+	if got := unscoped.Access("deploy/prod"); got != models.SecretAccessWrite {
+		t.Errorf("expected token_scope policies not to apply to unscoped principals, got %s", got)
+	}
+
+	scoped := NewAccessPolicy(Principal{UserID: "bob", TokenScopes: []string{"other"}}, policies)
+	if got := scoped.Access("anything"); got != models.SecretAccessNone {
+		t.Errorf("expected scoped tokens to default to none, got %s", got)
+	}
+	if got := scoped.Access("deploy/prod"); got != models.SecretAccessNone {
+		t.Errorf("expected no access from another scope's policy, got %s", got)
+	}
+}
+
+func TestMatchSecretPath(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"*", "anything/at/all", true},
+		{"deploy/*", "deploy", true},
+		{"deploy/*", "deploy/prod/db", true},
+		{"deploy/*", "deployment", false},
+		{"deploy/prod", "deploy/prod", true},
+		{"deploy/prod", "deploy/prod/db", false},
+	}
+	for _, tt := range tests {
+		if got := MatchSecretPath(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("MatchSecretPath(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestValidateAccessPolicy(t *testing.T) {
+	valid := testPolicy(models.SecretSubjectTokenScope, "deploy", "deploy/prod/*", models.SecretAccessRead)
+	if err := ValidateAccessPolicy(&valid); err != nil {
+		t.Errorf("expected valid policy, got %v", err)
+	}
+
+	invalid := []models.SecretAccessPolicy{
+		testPolicy("group", "deploy", "deploy/*", models.SecretAccessRead),
+		testPolicy(models.SecretSubjectRole, " ", "deploy/*", models.SecretAccessRead),
+		testPolicy(models.SecretSubjectRole, "dev", "deploy/*", "admin"),
+		testPolicy(models.SecretSubjectRole, "dev", "../etc/*", models.SecretAccessRead),
+		testPolicy(models.SecretSubjectRole, "dev", "", models.SecretAccessRead),
+	}
+	for _, p := range invalid {
+		if err := ValidateAccessPolicy(&p); !errors.Is(err, ErrInvalidAccessPolicy) {
+			t.Errorf("%+v: expected ErrInvalidAccessPolicy, got %v", p, err)
+		}
+	}
+}
+
+func TestDatabaseProvider_RestrictTo(t *testing.T) {
+	provider, err := NewDatabaseProvider(nil, "org", make([]byte, 32))
+	if err != nil {
+		t.Fatalf("NewDatabaseProvider failed: %v", err)
+	}
+	provider.RestrictTo(NewAccessPolicy(Principal{UserID: "org", TokenScopes: []string{"deploy"}}, []models.SecretAccessPolicy{
+		testPolicy(models.SecretSubjectTokenScope, "deploy", "deploy/prod/*", models.SecretAccessRead),
+	}))
+
+	// Denied operations fail before touching the database.
+	ctx := context.Background()
+	var authErr *AuthorizationError
+	if _, err := provider.Get(ctx, "dev/app", "KEY"); !errors.As(err, &authErr) {
+		t.Errorf("expected Get on dev/app to be denied, got %v", err)
+	}
+	if err := provider.Set(ctx, "deploy/prod/db", "KEY", "value"); !errors.As(err, &authErr) {
+		t.Errorf("expected Set on deploy/prod/db to be denied, got %v", err)
+	}
+	if _, err := provider.Delete(ctx, "deploy/prod/db", "KEY"); !errors.As(err, &authErr) {
+		t.Errorf("expected Delete on deploy/prod/db to be denied, got %v", err)
+	}
+	if _, err := provider.GetMulti(ctx, []SecretRef{{Path: "deploy/prod/db", Key: "A"}, {Path: "dev/app", Key: "B"}}); !errors.As(err, &authErr) {
+		t.Errorf("expected GetMulti touching dev/app to be denied, got %v", err)
+	}
+}
@@ -16,6 +16,8 @@ type DatabaseProvider struct {
 	db            *gorm.DB
 	orgID         string // User ID acting as org ID
 	encryptionKey []byte // 32-byte Fernet key for this org (already decoded)
+	access        *AccessPolicy
+	maxTokenAge   time.Duration // Re-encrypt values older than this on read; 0 disables
 }
 
 // NewDatabaseProvider creates a new DatabaseProvider.
@@ -28,9 +30,30 @@ func NewDatabaseProvider(db *gorm.DB, orgID string, encryptionKey []byte) (*Data
 		db:            db,
 		orgID:         orgID,
 		encryptionKey: encryptionKey,
+		maxTokenAge:   time.Duration(maxTokenAge.Load()),
 	}, nil
 }
 
+// RestrictTo limits the provider to what access allows. Operations on a
+// path it denies fail with an AuthorizationError, and ListPaths leaves out
+// paths it can't read.
+func (p *DatabaseProvider) RestrictTo(access *AccessPolicy) {
+	p.access = access
+}
+
+// checkAccess returns an AuthorizationError unless the provider's access
+// policy allows access to path.
+func (p *DatabaseProvider) checkAccess(path, access string) error {
+	if p.access == nil || p.access.Allows(path, access) {
+		return nil
+	}
+	return &AuthorizationError{
+		UserID: p.access.principal.UserID,
+		OrgID:  p.orgID,
+		Reason: fmt.Sprintf("no %s access to secret path %s", access, path),
+	}
+}
+
 // Get retrieves a secret value. Returns empty string if not found.
 func (p *DatabaseProvider) Get(ctx context.Context, path, key string) (string, error) {
 	if err := validatePath(path); err != nil {
@@ -39,6 +62,9 @@ func (p *DatabaseProvider) Get(ctx context.Context, path, key string) (string, e
 	if err := validateKey(key); err != nil {
 		return "", err
 	}
+	if err := p.checkAccess(path, models.SecretAccessRead); err != nil {
+		return "", err
+	}
 
 	var secret models.Secret
 	err := p.db.WithContext(ctx).
@@ -57,6 +83,7 @@ func (p *DatabaseProvider) Get(ctx context.Context, path, key string) (string, e
 	if err != nil {
 		return "", fmt.Errorf("failed to decrypt secret: %w", err)
 	}
+	p.refreshIfStale(p.db.WithContext(ctx), &secret, value)
 
 	return value, nil
 }
@@ -69,6 +96,9 @@ func (p *DatabaseProvider) Set(ctx context.Context, path, key, value string) err
 	if err := validateKey(key); err != nil {
 		return err
 	}
+	if err := p.checkAccess(path, models.SecretAccessWrite); err != nil {
+		return err
+	}
 
 	// Encrypt the value
 	encrypted, err := p.encrypt(value)
@@ -123,6 +153,9 @@ func (p *DatabaseProvider) Delete(ctx context.Context, path, key string) (bool,
 	if err := validateKey(key); err != nil {
 		return false, err
 	}
+	if err := p.checkAccess(path, models.SecretAccessWrite); err != nil {
+		return false, err
+	}
 
 	result := p.db.WithContext(ctx).
 		Where("user_id = ? AND path = ? AND key = ?", p.orgID, path, key).
@@ -140,6 +173,9 @@ func (p *DatabaseProvider) ListKeys(ctx context.Context, path string) ([]string,
 	if err := validatePath(path); err != nil {
 		return nil, err
 	}
+	if err := p.checkAccess(path, models.SecretAccessRead); err != nil {
+		return nil, err
+	}
 
 	var keys []string
 	err := p.db.WithContext(ctx).
@@ -167,6 +203,16 @@ func (p *DatabaseProvider) ListPaths(ctx context.Context) ([]string, error) {
 		return nil, fmt.Errorf("failed to list paths: %w", err)
 	}
 
+	if p.access != nil {
+		readable := paths[:0]
+		for _, path := range paths {
+			if p.access.Allows(path, models.SecretAccessRead) {
+				readable = append(readable, path)
+			}
+		}
+		paths = readable
+	}
+
 	return paths, nil
 }
 
@@ -181,6 +227,9 @@ func (p *DatabaseProvider) GetMulti(ctx context.Context, refs []SecretRef) (map[
 		if err := validateKey(ref.Key); err != nil {
 			return nil, fmt.Errorf("%s: %w", ref.Key, err)
 		}
+		if err := p.checkAccess(ref.Path, models.SecretAccessRead); err != nil {
+			return nil, err
+		}
 	}
 
 	if len(refs) == 0 {
@@ -223,6 +272,7 @@ func (p *DatabaseProvider) GetMulti(ctx context.Context, refs []SecretRef) (map[
 			if err != nil {
 				return nil, fmt.Errorf("failed to decrypt secret %s: %w", mapKey, err)
 			}
+			p.refreshIfStale(p.db.WithContext(ctx), secret, value)
 			results[mapKey] = value
 		} else {
 			results[mapKey] = "" // Not found
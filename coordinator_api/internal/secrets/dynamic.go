@@ -0,0 +1,145 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// ErrInvalidCloudRole is returned for a cloud role mapping with an unknown
+// provider, a malformed role, or a duration the provider doesn't allow.
+var ErrInvalidCloudRole = errors.New("invalid cloud role")
+
+// Session duration limits. AWS AssumeRole allows 15 minutes to 12 hours
+// (capped further by the role's own maximum); GCP access tokens live at most
+// an hour unless the org policy extends it.
+const (
+	AWSMinSessionDuration = 900
+	AWSMaxSessionDuration = 43200
+	GCPMinTokenLifetime   = 60
+	GCPMaxTokenLifetime   = 3600
+)
+
+var (
+	awsRoleARNPattern = regexp.MustCompile(`^arn:aws[a-z-]*:iam::\d{12}:role/[\w+=,.@/-]+$`)
+	envPrefixPattern  = regexp.MustCompile(`^[A-Z][A-Z0-9_]*_$`)
+)
+
+// DynamicCredential is a short-lived cloud credential minted for one job.
+type DynamicCredential struct {
+	// Role is the name of the cloud role mapping it was minted for.
+	Role     string
+	Provider string
+	// Env holds the variables to inject into the job.
+	Env map[string]string
+	// SecretEnvNames are the variables in Env whose values must be masked.
+	SecretEnvNames []string
+	ExpiresAt      time.Time
+
+	revoke func(ctx context.Context) error
+}
+
+// SecretValues returns the values of the credential's secret variables.
+func (c *DynamicCredential) SecretValues() []string {
+	values := make([]string, 0, len(c.SecretEnvNames))
+	for _, name := range c.SecretEnvNames {
+		values = append(values, c.Env[name])
+	}
+	return values
+}
+
+// Revoke revokes the credential where the provider supports it. Credentials
+// that can't be revoked (AWS STS sessions) are left to expire.
+func (c *DynamicCredential) Revoke(ctx context.Context) error {
+	if c.revoke == nil {
+		return nil
+	}
+	return c.revoke(ctx)
+}
+
+// DynamicSecretsEngine mints credentials for one cloud provider.
+type DynamicSecretsEngine interface {
+	Mint(ctx context.Context, role models.ProjectCloudRole, sessionName string) (*DynamicCredential, error)
+}
+
+// DynamicSecrets mints short-lived cloud credentials for jobs, dispatching
+// each cloud role mapping to the engine for its provider.
+type DynamicSecrets struct {
+	engines map[string]DynamicSecretsEngine
+}
+
+// NewDynamicSecrets creates a DynamicSecrets from engines keyed by provider
+// (models.CloudProviderAWS, models.CloudProviderGCP).
+func NewDynamicSecrets(engines map[string]DynamicSecretsEngine) *DynamicSecrets {
+	return &DynamicSecrets{engines: engines}
+}
+
+// Mint mints a credential for role. sessionName identifies the job to the
+// cloud provider's audit logs.
+func (d *DynamicSecrets) Mint(ctx context.Context, role models.ProjectCloudRole, sessionName string) (*DynamicCredential, error) {
+	engine, ok := d.engines[role.Provider]
+	if !ok {
+		return nil, fmt.Errorf("no dynamic secrets engine for provider %q", role.Provider)
+	}
+	cred, err := engine.Mint(ctx, role, sessionName)
+	if err != nil {
+		return nil, fmt.Errorf("minting %s credentials for cloud role %s: %w", role.Provider, role.Name, err)
+	}
+	cred.Role, cred.Provider = role.Name, role.Provider
+	if role.EnvPrefix != "" {
+		prefixed := make(map[string]string, len(cred.Env))
+		for name, value := range cred.Env {
+			prefixed[role.EnvPrefix+name] = value
+		}
+		cred.Env = prefixed
+		for i, name := range cred.SecretEnvNames {
+			cred.SecretEnvNames[i] = role.EnvPrefix + name
+		}
+	}
+	return cred, nil
+}
+
+// ValidateCloudRole checks a cloud role mapping's provider, role, duration
+// and env prefix, filling in the provider's default duration.
+func ValidateCloudRole(role *models.ProjectCloudRole) error {
+	if strings.TrimSpace(role.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidCloudRole)
+	}
+	if role.DurationSeconds == 0 {
+		role.DurationSeconds = 3600
+	}
+	switch role.Provider {
+	case models.CloudProviderAWS:
+		if !awsRoleARNPattern.MatchString(role.Role) {
+			return fmt.Errorf("%w: role must be an IAM role ARN", ErrInvalidCloudRole)
+		}
+		if role.DurationSeconds < AWSMinSessionDuration || role.DurationSeconds > AWSMaxSessionDuration {
+			return fmt.Errorf("%w: duration_seconds must be between %d and %d for aws", ErrInvalidCloudRole, AWSMinSessionDuration, AWSMaxSessionDuration)
+		}
+		if len(role.Scopes) > 0 {
+			return fmt.Errorf("%w: scopes only apply to gcp roles", ErrInvalidCloudRole)
+		}
+	case models.CloudProviderGCP:
+		if addr, err := mail.ParseAddress(role.Role); err != nil || addr.Address != role.Role {
+			return fmt.Errorf("%w: role must be a service account email", ErrInvalidCloudRole)
+		}
+		if role.DurationSeconds < GCPMinTokenLifetime || role.DurationSeconds > GCPMaxTokenLifetime {
+			return fmt.Errorf("%w: duration_seconds must be between %d and %d for gcp", ErrInvalidCloudRole, GCPMinTokenLifetime, GCPMaxTokenLifetime)
+		}
+		if role.Region != "" || role.ExternalID != "" {
+			return fmt.Errorf("%w: region and external_id only apply to aws roles", ErrInvalidCloudRole)
+		}
+	default:
+		return fmt.Errorf("%w: provider must be aws or gcp", ErrInvalidCloudRole)
+	}
+	if role.EnvPrefix != "" && !envPrefixPattern.MatchString(role.EnvPrefix) {
+		return fmt.Errorf("%w: env_prefix must be upper case and end in _", ErrInvalidCloudRole)
+	}
+	return nil
+}
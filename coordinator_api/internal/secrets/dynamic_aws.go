@@ -0,0 +1,74 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// stsAssumeRoleAPI is the part of the STS client AWSSTSEngine uses.
+type stsAssumeRoleAPI interface {
+	AssumeRole(ctx context.Context, params *sts.AssumeRoleInput, optFns ...func(*sts.Options)) (*sts.AssumeRoleOutput, error)
+}
+
+// AWSSTSEngine mints AWS credentials with STS AssumeRole, using the
+// worker's own AWS identity to assume each project's role.
+type AWSSTSEngine struct {
+	client stsAssumeRoleAPI
+}
+
+// NewAWSSTSEngine creates an AWSSTSEngine from the default AWS credential
+// chain (environment, shared config, instance or pod identity).
+func NewAWSSTSEngine(ctx context.Context) (*AWSSTSEngine, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	return &AWSSTSEngine{client: sts.NewFromConfig(cfg)}, nil
+}
+
+var invalidSessionNameChars = regexp.MustCompile(`[^\w+=,.@-]`)
+
+// Mint assumes role.Role for role.DurationSeconds. STS sessions can't be
+// revoked, so the credential expires on its own after the job.
+func (e *AWSSTSEngine) Mint(ctx context.Context, role models.ProjectCloudRole, sessionName string) (*DynamicCredential, error) {
+	sessionName = invalidSessionNameChars.ReplaceAllString(sessionName, "-")
+	if len(sessionName) > 64 {
+		sessionName = sessionName[:64]
+	}
+	input := &sts.AssumeRoleInput{
+		RoleArn:         aws.String(role.Role),
+		RoleSessionName: aws.String(sessionName),
+		DurationSeconds: aws.Int32(int32(role.DurationSeconds)),
+	}
+	if role.ExternalID != "" {
+		input.ExternalId = aws.String(role.ExternalID)
+	}
+	out, err := e.client.AssumeRole(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if out.Credentials == nil {
+		return nil, fmt.Errorf("AssumeRole returned no credentials")
+	}
+
+	cred := &DynamicCredential{
+		Env: map[string]string{
+			"AWS_ACCESS_KEY_ID":     aws.ToString(out.Credentials.AccessKeyId),
+			"AWS_SECRET_ACCESS_KEY": aws.ToString(out.Credentials.SecretAccessKey),
+			"AWS_SESSION_TOKEN":     aws.ToString(out.Credentials.SessionToken),
+		},
+		SecretEnvNames: []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN"},
+		ExpiresAt:      aws.ToTime(out.Credentials.Expiration),
+	}
+	if role.Region != "" {
+		cred.Env["AWS_REGION"] = role.Region
+		cred.Env["AWS_DEFAULT_REGION"] = role.Region
+	}
+	return cred, nil
+}
@@ -0,0 +1,134 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// Google endpoints GCPEngine talks to.
+const (
+	GCPMetadataTokenURL  = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+	GCPIAMCredentialsURL = "https://iamcredentials.googleapis.com/v1"
+	GCPRevokeURL         = "https://oauth2.googleapis.com/revoke"
+)
+
+const gcpDefaultScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// GCPEngine mints GCP access tokens by impersonating each project's service
+// account with the worker's own workload identity, read from the metadata
+// server. The worker's identity needs roles/iam.serviceAccountTokenCreator
+// on the service accounts it impersonates.
+type GCPEngine struct {
+	client            *http.Client
+	metadataTokenURL  string
+	iamCredentialsURL string
+	revokeURL         string
+}
+
+// NewGCPEngine creates a GCPEngine against Google's endpoints.
+func NewGCPEngine() *GCPEngine {
+	return &GCPEngine{
+		client:            &http.Client{Timeout: 30 * time.Second},
+		metadataTokenURL:  GCPMetadataTokenURL,
+		iamCredentialsURL: GCPIAMCredentialsURL,
+		revokeURL:         GCPRevokeURL,
+	}
+}
+
+// Mint generates an access token for the role's service account. The
+// token is revoked when the job ends.
+func (e *GCPEngine) Mint(ctx context.Context, role models.ProjectCloudRole, sessionName string) (*DynamicCredential, error) {
+	sourceToken, err := e.sourceToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := []string(role.Scopes)
+	if len(scopes) == 0 {
+		scopes = []string{gcpDefaultScope}
+	}
+	body, _ := json.Marshal(map[string]interface{}{
+		"scope":    scopes,
+		"lifetime": fmt.Sprintf("%ds", role.DurationSeconds),
+	})
+	endpoint := fmt.Sprintf("%s/projects/-/serviceAccounts/%s:generateAccessToken", e.iamCredentialsURL, url.PathEscape(role.Role))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+sourceToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	var out struct {
+		AccessToken string    `json:"accessToken"`
+		ExpireTime  time.Time `json:"expireTime"`
+	}
+	if err := e.doJSON(req, &out); err != nil {
+		return nil, fmt.Errorf("generating access token for %s: %w", role.Role, err)
+	}
+
+	token := out.AccessToken
+	return &DynamicCredential{
+		Env: map[string]string{
+			"GOOGLE_OAUTH_ACCESS_TOKEN":  token,
+			"CLOUDSDK_AUTH_ACCESS_TOKEN": token,
+		},
+		SecretEnvNames: []string{"GOOGLE_OAUTH_ACCESS_TOKEN", "CLOUDSDK_AUTH_ACCESS_TOKEN"},
+		ExpiresAt:      out.ExpireTime,
+		revoke: func(ctx context.Context) error {
+			return e.revokeToken(ctx, token)
+		},
+	}, nil
+}
+
+// sourceToken returns the worker's own access token from the metadata
+// server.
+func (e *GCPEngine) sourceToken(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.metadataTokenURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	var out struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := e.doJSON(req, &out); err != nil {
+		return "", fmt.Errorf("reading worker identity from metadata server: %w", err)
+	}
+	return out.AccessToken, nil
+}
+
+func (e *GCPEngine) revokeToken(ctx context.Context, token string) error {
+	form := url.Values{"token": {token}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.revokeURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return e.doJSON(req, nil)
+}
+
+func (e *GCPEngine) doJSON(req *http.Request, out interface{}) error {
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %d: %s", req.URL.Host, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(body, out)
+}
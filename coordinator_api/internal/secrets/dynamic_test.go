@@ -0,0 +1,166 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/sts/types"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+func TestValidateCloudRole(t *testing.T) {
+	valid := []models.ProjectCloudRole{
+		{Name: "deploy", Provider: models.CloudProviderAWS, Role: "arn:aws:iam::123456789012:role/deploy", Region: "us-east-1"},
+		{Name: "deploy", Provider: models.CloudProviderAWS, Role: "arn:aws-us-gov:iam::123456789012:role/ci/deploy", DurationSeconds: 900, EnvPrefix: "PROD_"},
+		{Name: "gcs", Provider: models.CloudProviderGCP, Role: "ci@my-project.iam.gserviceaccount.com", Scopes: []string{"https://www.googleapis.com/auth/devstorage.read_write"}},
+	}
+	for _, role := range valid {
+		if err := ValidateCloudRole(&role); err != nil {
+			t.Errorf("%+v: expected valid, got %v", role, err)
+		}
+		if role.DurationSeconds == 0 {
+			t.Errorf("%+v: expected a default duration", role)
+		}
+	}
+
+	invalid := []models.ProjectCloudRole{
+		{Provider: models.CloudProviderAWS, Role: "arn:aws:iam::123456789012:role/deploy"},
+		{Name: "deploy", Provider: "azure", Role: "x"},
+		{Name: "deploy", Provider: models.CloudProviderAWS, Role: "deploy"},
+		{Name: "deploy", Provider: models.CloudProviderAWS, Role: "arn:aws:iam::123456789012:role/deploy", DurationSeconds: 60},
+		{Name: "deploy", Provider: models.CloudProviderAWS, Role: "arn:aws:iam::123456789012:role/deploy", EnvPrefix: "prod"},
+		{Name: "gcs", Provider: models.CloudProviderGCP, Role: "Name <ci@my-project.iam.gserviceaccount.com>"},
+		{Name: "gcs", Provider: models.CloudProviderGCP, Role: "ci@my-project.iam.gserviceaccount.com", DurationSeconds: 7200},
+		{Name: "gcs", Provider: models.CloudProviderGCP, Role: "ci@my-project.iam.gserviceaccount.com", Region: "us-east-1"},
+	}
+	for _, role := range invalid {
+		if err := ValidateCloudRole(&role); !errors.Is(err, ErrInvalidCloudRole) {
+			t.Errorf("%+v: expected ErrInvalidCloudRole, got %v", role, err)
+		}
+	}
+}
+
+type fakeSTS struct {
+	input *sts.AssumeRoleInput
+}
+
+func (f *fakeSTS) AssumeRole(ctx context.Context, params *sts.AssumeRoleInput, optFns ...func(*sts.Options)) (*sts.AssumeRoleOutput, error) {
+	f.input = params
+	return &sts.AssumeRoleOutput{Credentials: &types.Credentials{
+		AccessKeyId:     aws.String("ASIAEXAMPLE"),
+		SecretAccessKey: aws.String("secret-key"),
+		SessionToken:    aws.String("session-token"),
+		Expiration:      aws.Time(time.Unix(1700000000, 0)),
+	}}, nil
+}
+
+func TestDynamicSecrets_AWS(t *testing.T) {
+	client := &fakeSTS{}
+	dynamic := NewDynamicSecrets(map[string]DynamicSecretsEngine{
+		models.CloudProviderAWS: &AWSSTSEngine{client: client},
+	})
+	cred, err := dynamic.Mint(context.Background(), models.ProjectCloudRole{
+		Name:            "deploy",
+		Provider:        models.CloudProviderAWS,
+		Role:            "arn:aws:iam::123456789012:role/deploy",
+		DurationSeconds: 900,
+		ExternalID:      "ext",
+		Region:          "eu-west-1",
+		EnvPrefix:       "PROD_",
+	}, "reactorcide-job/1")
+	if err != nil {
+		t.Fatalf("Mint failed: %v", err)
+	}
+
+	if aws.ToString(client.input.RoleSessionName) != "reactorcide-job-1" {
+		t.Errorf("expected a sanitized session name, got %q", aws.ToString(client.input.RoleSessionName))
+	}
+	if aws.ToInt32(client.input.DurationSeconds) != 900 || aws.ToString(client.input.ExternalId) != "ext" {
+		t.Errorf("unexpected AssumeRole input: %+v", client.input)
+	}
+	if cred.Env["PROD_AWS_SECRET_ACCESS_KEY"] != "secret-key" || cred.Env["PROD_AWS_REGION"] != "eu-west-1" {
+		t.Errorf("unexpected env: %v", cred.Env)
+	}
+	if len(cred.SecretEnvNames) != 3 || cred.SecretEnvNames[0] != "PROD_AWS_ACCESS_KEY_ID" {
+		t.Errorf("unexpected secret env names: %v", cred.SecretEnvNames)
+	}
+	if cred.Role != "deploy" || !cred.ExpiresAt.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("unexpected credential: %+v", cred)
+	}
+	if err := cred.Revoke(context.Background()); err != nil {
+		t.Errorf("expected AWS revoke to be a no-op, got %v", err)
+	}
+}
+
+func TestDynamicSecrets_GCP(t *testing.T) {
+	revoked := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			if r.Header.Get("Metadata-Flavor") != "Google" {
+				t.Errorf("missing Metadata-Flavor header")
+			}
+			_, _ = w.Write([]byte(`{"access_token":"worker-token"}`))
+		case strings.HasSuffix(r.URL.Path, ":generateAccessToken"):
+			if r.Header.Get("Authorization") != "Bearer worker-token" {
+				t.Errorf("expected the worker's token, got %q", r.Header.Get("Authorization"))
+			}
+			if !strings.Contains(r.URL.Path, "/serviceAccounts/ci@my-project.iam.gserviceaccount.com") {
+				t.Errorf("unexpected path %s", r.URL.Path)
+			}
+			var body struct {
+				Scope    []string
+				Lifetime string
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if body.Lifetime != "600s" || len(body.Scope) != 1 || body.Scope[0] != gcpDefaultScope {
+				t.Errorf("unexpected request %+v", body)
+			}
+			_, _ = w.Write([]byte(`{"accessToken":"job-token","expireTime":"2030-01-01T00:00:00Z"}`))
+		case r.URL.Path == "/revoke":
+			_ = r.ParseForm()
+			revoked = r.PostForm.Get("token")
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	engine := NewGCPEngine()
+	engine.metadataTokenURL = server.URL + "/token"
+	engine.iamCredentialsURL = server.URL
+	engine.revokeURL = server.URL + "/revoke"
+	cred, err := NewDynamicSecrets(map[string]DynamicSecretsEngine{models.CloudProviderGCP: engine}).Mint(context.Background(), models.ProjectCloudRole{
+		Name:            "gcs",
+		Provider:        models.CloudProviderGCP,
+		Role:            "ci@my-project.iam.gserviceaccount.com",
+		DurationSeconds: 600,
+	}, "reactorcide-job-1")
+	if err != nil {
+		t.Fatalf("Mint failed: %v", err)
+	}
+	if cred.Env["GOOGLE_OAUTH_ACCESS_TOKEN"] != "job-token" || cred.Env["CLOUDSDK_AUTH_ACCESS_TOKEN"] != "job-token" {
+		t.Errorf("unexpected env: %v", cred.Env)
+	}
+	if err := cred.Revoke(context.Background()); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	if revoked != "job-token" {
+		t.Errorf("expected the job token revoked, got %q", revoked)
+	}
+}
+
+func TestDynamicSecrets_UnknownProvider(t *testing.T) {
+	_, err := NewDynamicSecrets(nil).Mint(context.Background(), models.ProjectCloudRole{Provider: models.CloudProviderAWS}, "s")
+	if err == nil {
+		t.Error("expected an error without an engine for the provider")
+	}
+}
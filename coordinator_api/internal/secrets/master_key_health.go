@@ -0,0 +1,188 @@
+package secrets
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"gorm.io/gorm"
+)
+
+// masterKeyCanary is the plaintext of every master key's canary.
+var masterKeyCanary = []byte("reactorcide-master-key-canary")
+
+// Canary check results.
+const (
+	CanaryOK          = "ok"
+	CanaryMismatch    = "mismatch"
+	CanaryBackfilled  = "backfilled"
+	CanaryUnavailable = "unavailable"
+)
+
+// MasterKeyHealth is the result of checking the master keys in the database
+// against the ones this process holds.
+type MasterKeyHealth struct {
+	Healthy bool              `json:"healthy"`
+	Keys    []MasterKeyStatus `json:"keys"`
+	// OrphanedOrgKeys are org keys still encrypted by decommissioned master
+	// keys.
+	OrphanedOrgKeys []OrgKeyStatus `json:"orphaned_org_keys,omitempty"`
+	// UndecryptableOrgKeys are org keys an available master key fails to
+	// decrypt.
+	UndecryptableOrgKeys []OrgKeyStatus `json:"undecryptable_org_keys,omitempty"`
+	// UnreadableOrgs are orgs none of whose org keys this process can
+	// decrypt, so every secret read for them fails.
+	UnreadableOrgs []string `json:"unreadable_orgs,omitempty"`
+	Problems       []string `json:"problems,omitempty"`
+}
+
+// MasterKeyStatus is one master key's health.
+type MasterKeyStatus struct {
+	Name      string `json:"name"`
+	IsActive  bool   `json:"is_active"`
+	IsPrimary bool   `json:"is_primary"`
+	// Available is whether this process holds the key, from
+	// REACTORCIDE_MASTER_KEYS or the database's stored key material.
+	Available bool   `json:"available"`
+	Canary    string `json:"canary"`
+	OrgKeys   int    `json:"org_keys"`
+}
+
+// OrgKeyStatus identifies one org key row.
+type OrgKeyStatus struct {
+	OrgID         string `json:"org_id"`
+	MasterKeyName string `json:"master_key_name"`
+}
+
+// orgKeyRow is an org key with the name and state of its master key.
+type orgKeyRow struct {
+	models.OrgEncryptionKey
+	MasterKeyName     string
+	MasterKeyIsActive bool
+}
+
+// CheckHealth verifies that every active master key in the database is
+// available to this process and decrypts its canary, that every org key
+// decrypts, and reports org keys left encrypted by decommissioned keys.
+// Canaries missing from keys registered before they existed are written
+// for available keys.
+func (m *MasterKeyManager) CheckHealth(db *gorm.DB) (*MasterKeyHealth, error) {
+	var keys []models.MasterKey
+	if err := db.Order("is_primary DESC, name ASC").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to list master keys: %w", err)
+	}
+	var orgKeys []orgKeyRow
+	if err := db.Table("org_encryption_keys").
+		Select("org_encryption_keys.*, master_keys.name as master_key_name, master_keys.is_active as master_key_is_active").
+		Joins("JOIN master_keys ON master_keys.key_id = org_encryption_keys.master_key_id").
+		Find(&orgKeys).Error; err != nil {
+		return nil, fmt.Errorf("failed to list org encryption keys: %w", err)
+	}
+
+	health := m.evaluateHealth(keys, orgKeys)
+	for i, status := range health.Keys {
+		if status.Canary != CanaryBackfilled {
+			continue
+		}
+		if err := db.Model(&models.MasterKey{}).Where("name = ?", status.Name).Update("canary", keys[i].Canary).Error; err != nil {
+			return nil, fmt.Errorf("failed to store canary for master key %s: %w", status.Name, err)
+		}
+	}
+	return health, nil
+}
+
+// evaluateHealth checks keys and orgKeys without touching the database. A
+// key without a canary gets one in keys[i].Canary, reported as
+// CanaryBackfilled, for the caller to store.
+func (m *MasterKeyManager) evaluateHealth(keys []models.MasterKey, orgKeys []orgKeyRow) *MasterKeyHealth {
+	health := &MasterKeyHealth{Keys: make([]MasterKeyStatus, 0, len(keys))}
+	problem := func(format string, args ...interface{}) {
+		health.Problems = append(health.Problems, fmt.Sprintf(format, args...))
+	}
+
+	orgKeyCounts := map[string]int{}
+	for _, orgKey := range orgKeys {
+		orgKeyCounts[orgKey.MasterKeyName]++
+	}
+
+	primaries := 0
+	for i, mk := range keys {
+		status := MasterKeyStatus{
+			Name:      mk.Name,
+			IsActive:  mk.IsActive,
+			IsPrimary: mk.IsPrimary,
+			Available: m.HasKey(mk.Name),
+			Canary:    CanaryUnavailable,
+			OrgKeys:   orgKeyCounts[mk.Name],
+		}
+		if mk.IsPrimary {
+			primaries++
+		}
+		if status.Available {
+			if len(mk.Canary) == 0 {
+				if canary, err := encryptCanary(m.keys[mk.Name]); err == nil {
+					keys[i].Canary = canary
+					status.Canary = CanaryBackfilled
+				}
+			} else if plaintext, err := fernetDecrypt(m.fernetKey(mk.Name), mk.Canary); err == nil && bytes.Equal(plaintext, masterKeyCanary) {
+				status.Canary = CanaryOK
+			} else {
+				status.Canary = CanaryMismatch
+				problem("master key %s does not decrypt its canary: the key in REACTORCIDE_MASTER_KEYS differs from the one registered", mk.Name)
+			}
+		} else if mk.IsActive {
+			problem("active master key %s is missing from REACTORCIDE_MASTER_KEYS", mk.Name)
+		}
+		health.Keys = append(health.Keys, status)
+	}
+	if len(keys) > 0 && primaries != 1 {
+		problem("expected exactly one primary master key, found %d", primaries)
+	}
+
+	readable := map[string]bool{}
+	orgs := map[string]bool{}
+	for _, orgKey := range orgKeys {
+		orgs[orgKey.UserID] = true
+		row := OrgKeyStatus{OrgID: orgKey.UserID, MasterKeyName: orgKey.MasterKeyName}
+		if !orgKey.MasterKeyIsActive {
+			health.OrphanedOrgKeys = append(health.OrphanedOrgKeys, row)
+		}
+		if !m.HasKey(orgKey.MasterKeyName) {
+			continue
+		}
+		if _, err := fernetDecrypt(m.fernetKey(orgKey.MasterKeyName), orgKey.EncryptedKey); err != nil {
+			health.UndecryptableOrgKeys = append(health.UndecryptableOrgKeys, row)
+			continue
+		}
+		readable[orgKey.UserID] = true
+	}
+	for org := range orgs {
+		if !readable[org] {
+			health.UnreadableOrgs = append(health.UnreadableOrgs, org)
+		}
+	}
+	sort.Strings(health.UnreadableOrgs)
+
+	if n := len(health.OrphanedOrgKeys); n > 0 {
+		problem("%d org keys are encrypted by decommissioned master keys", n)
+	}
+	if n := len(health.UndecryptableOrgKeys); n > 0 {
+		problem("%d org keys fail to decrypt with their master key", n)
+	}
+	if n := len(health.UnreadableOrgs); n > 0 {
+		problem("%d orgs have no org key this process can decrypt; their secret reads fail", n)
+	}
+	health.Healthy = len(health.Problems) == 0
+	return health
+}
+
+// fernetKey returns the named master key encoded for Fernet.
+func (m *MasterKeyManager) fernetKey(name string) []byte {
+	return encodeFernetKey(m.keys[name])
+}
+
+// encryptCanary encrypts the canary with a 32-byte master key.
+func encryptCanary(key []byte) ([]byte, error) {
+	return fernetEncrypt(encodeFernetKey(key), masterKeyCanary)
+}
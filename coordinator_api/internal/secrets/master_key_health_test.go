@@ -0,0 +1,107 @@
+package secrets
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+func testMasterKey(fill byte) []byte {
+	return bytes.Repeat([]byte{fill}, 32)
+}
+
+func testOrgKeyRow(t *testing.T, orgID, masterKeyName string, masterKey []byte, active bool) orgKeyRow {
+	t.Helper()
+	encrypted, err := fernetEncrypt(encodeFernetKey(masterKey), testMasterKey(9))
+	if err != nil {
+		t.Fatalf("fernetEncrypt failed: %v", err)
+	}
+	return orgKeyRow{
+		OrgEncryptionKey:  models.OrgEncryptionKey{UserID: orgID, EncryptedKey: encrypted},
+		MasterKeyName:     masterKeyName,
+		MasterKeyIsActive: active,
+	}
+}
+
+func TestEvaluateHealth_Healthy(t *testing.T) {
+	mgr := &MasterKeyManager{keys: map[string][]byte{"primary": testMasterKey(1)}, primaryKey: "primary"}
+	canary, err := encryptCanary(testMasterKey(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []models.MasterKey{{Name: "primary", IsActive: true, IsPrimary: true, Canary: canary}}
+	orgKeys := []orgKeyRow{testOrgKeyRow(t, "org-1", "primary", testMasterKey(1), true)}
+
+	health := mgr.evaluateHealth(keys, orgKeys)
+	if !health.Healthy {
+		t.Fatalf("expected healthy, got problems %v", health.Problems)
+	}
+	if health.Keys[0].Canary != CanaryOK || !health.Keys[0].Available || health.Keys[0].OrgKeys != 1 {
+		t.Errorf("unexpected key status %+v", health.Keys[0])
+	}
+}
+
+func TestEvaluateHealth_BackfillsCanary(t *testing.T) {
+	mgr := &MasterKeyManager{keys: map[string][]byte{"primary": testMasterKey(1)}, primaryKey: "primary"}
+	keys := []models.MasterKey{{Name: "primary", IsActive: true, IsPrimary: true}}
+
+	health := mgr.evaluateHealth(keys, nil)
+	if !health.Healthy || health.Keys[0].Canary != CanaryBackfilled {
+		t.Fatalf("expected a backfilled canary, got %+v", health)
+	}
+	if plaintext, err := fernetDecrypt(encodeFernetKey(testMasterKey(1)), keys[0].Canary); err != nil || !bytes.Equal(plaintext, masterKeyCanary) {
+		t.Errorf("expected keys[0].Canary to hold the canary, got %v", err)
+	}
+}
+
+func TestEvaluateHealth_Problems(t *testing.T) {
+	// "primary" is in the environment but with different key material than
+	// it was registered with; "missing" is active but not in the
+	// environment; "old" was decommissioned.
+	mgr := &MasterKeyManager{keys: map[string][]byte{"primary": testMasterKey(2)}, primaryKey: "primary"}
+	canary, err := encryptCanary(testMasterKey(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []models.MasterKey{
+		{Name: "primary", IsActive: true, IsPrimary: true, Canary: canary},
+		{Name: "missing", IsActive: true},
+		{Name: "old", IsActive: false},
+	}
+	orgKeys := []orgKeyRow{
+		testOrgKeyRow(t, "org-1", "primary", testMasterKey(1), true),
+		testOrgKeyRow(t, "org-2", "missing", testMasterKey(3), true),
+		testOrgKeyRow(t, "org-3", "old", testMasterKey(4), false),
+	}
+
+	health := mgr.evaluateHealth(keys, orgKeys)
+	if health.Healthy {
+		t.Fatal("expected unhealthy")
+	}
+	if health.Keys[0].Canary != CanaryMismatch {
+		t.Errorf("expected a canary mismatch, got %s", health.Keys[0].Canary)
+	}
+	if health.Keys[1].Available || health.Keys[1].Canary != CanaryUnavailable {
+		t.Errorf("expected the missing key unavailable, got %+v", health.Keys[1])
+	}
+	if len(health.OrphanedOrgKeys) != 1 || health.OrphanedOrgKeys[0].OrgID != "org-3" {
+		t.Errorf("expected org-3's key orphaned, got %v", health.OrphanedOrgKeys)
+	}
+	if len(health.UndecryptableOrgKeys) != 1 || health.UndecryptableOrgKeys[0].OrgID != "org-1" {
+		t.Errorf("expected org-1's key undecryptable, got %v", health.UndecryptableOrgKeys)
+	}
+	want := []string{"org-1", "org-2", "org-3"}
+	if len(health.UnreadableOrgs) != len(want) {
+		t.Fatalf("expected unreadable orgs %v, got %v", want, health.UnreadableOrgs)
+	}
+	for i := range want {
+		if health.UnreadableOrgs[i] != want[i] {
+			t.Errorf("expected unreadable orgs %v, got %v", want, health.UnreadableOrgs)
+		}
+	}
+	// Canary mismatch, missing key, orphaned, undecryptable and unreadable.
+	if len(health.Problems) != 5 {
+		t.Errorf("expected 5 problems, got %v", health.Problems)
+	}
+}
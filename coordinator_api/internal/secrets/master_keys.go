@@ -8,6 +8,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
 	"gorm.io/gorm"
 )
@@ -120,9 +121,11 @@ func (m *MasterKeyManager) GetOrgEncryptionKey(db *gorm.DB, orgID string) ([]byt
 	}
 
 	// Try each org key row until one decrypts successfully
+	var missing []string
 	for _, orgKey := range orgKeys {
 		masterKey := m.keys[orgKey.MasterKeyName]
 		if masterKey == nil {
+			missing = append(missing, orgKey.MasterKeyName)
 			continue // We don't have this master key in environment
 		}
 
@@ -136,6 +139,9 @@ func (m *MasterKeyManager) GetOrgEncryptionKey(db *gorm.DB, orgID string) ([]byt
 		}
 	}
 
+	if len(missing) == len(orgKeys) {
+		return nil, fmt.Errorf("no valid master key available for org: its key is encrypted only by master keys missing from %s (%s)", MasterKeysEnvVar, strings.Join(missing, ", "))
+	}
 	return nil, errors.New("no valid master key available for org")
 }
 
@@ -144,7 +150,7 @@ func (m *MasterKeyManager) GetOrgEncryptionKey(db *gorm.DB, orgID string) ([]byt
 func (m *MasterKeyManager) RegisterMasterKey(db *gorm.DB, name, description string) (*models.MasterKey, error) {
 	// Verify key exists in environment
 	if !m.HasKey(name) {
-		return nil, fmt.Errorf("master key %s not found in REACTORCIDE_MASTER_KEYS", name)
+		return nil, fmt.Errorf("%w: %s is not in REACTORCIDE_MASTER_KEYS", ErrMasterKeyNotFound, name)
 	}
 
 	// Check if there's already a primary key in the database
@@ -154,12 +160,18 @@ func (m *MasterKeyManager) RegisterMasterKey(db *gorm.DB, name, description stri
 	// Only set as primary if this is the primary in the manager AND no primary exists yet
 	isPrimary := (name == m.primaryKey) && (primaryCount == 0)
 
+	canary, err := encryptCanary(m.keys[name])
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt canary: %w", err)
+	}
+
 	// Let database generate key_id via generate_ulid()
 	mk := &models.MasterKey{
 		Name:        name,
 		IsActive:    true, // Active since it's in environment
 		IsPrimary:   isPrimary,
 		Description: description,
+		Canary:      canary,
 	}
 
 	if err := db.Create(mk).Error; err != nil {
@@ -175,13 +187,16 @@ func (m *MasterKeyManager) RotateToKey(db *gorm.DB, keyName string) error {
 	// Verify we have the key in environment
 	newKey := m.keys[keyName]
 	if newKey == nil {
-		return fmt.Errorf("master key %s not found in environment", keyName)
+		return fmt.Errorf("%w: %s is not in the environment", ErrMasterKeyNotFound, keyName)
 	}
 
 	// Get the master key record
 	var mk models.MasterKey
 	if err := db.Where("name = ?", keyName).First(&mk).Error; err != nil {
-		return fmt.Errorf("master key %s not registered in database", keyName)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: %s is not registered in the database", ErrMasterKeyNotFound, keyName)
+		}
+		return fmt.Errorf("failed to load master key %s: %w", keyName, err)
 	}
 
 	// Get all unique org IDs
@@ -263,7 +278,10 @@ func (m *MasterKeyManager) DecommissionKey(db *gorm.DB, keyName string) error {
 	// Get the master key record
 	var mk models.MasterKey
 	if err := db.Where("name = ?", keyName).First(&mk).Error; err != nil {
-		return fmt.Errorf("master key %s not found", keyName)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: %s", ErrMasterKeyNotFound, keyName)
+		}
+		return fmt.Errorf("failed to load master key %s: %w", keyName, err)
 	}
 
 	if mk.IsPrimary {
@@ -433,7 +451,7 @@ func LoadOrCreateMasterKeys(db *gorm.DB) (*MasterKeyManager, error) {
 	if err := GenerateAndStoreMasterKeys(db, DefaultKeyCount); err != nil {
 		// If generation failed due to duplicate key (race condition with another service),
 		// retry loading from database - the other service may have just created the keys
-		if strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "unique constraint") {
+		if errors.Is(err, store.ErrAlreadyExists) {
 			if mgr, loadErr := LoadMasterKeysFromDB(db); loadErr == nil {
 				return mgr, nil
 			}
@@ -500,12 +518,18 @@ func GenerateAndStoreMasterKeys(db *gorm.DB, count int) error {
 				return fmt.Errorf("failed to generate key name: %w", err)
 			}
 
+			canary, err := encryptCanary(keyMaterial)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt canary: %w", err)
+			}
+
 			mk := &models.MasterKey{
 				Name:        name,
 				IsActive:    true,
 				IsPrimary:   i == 0, // First key is primary
 				Description: "Auto-generated on first startup",
 				KeyMaterial: keyMaterial,
+				Canary:      canary,
 			}
 
 			if err := tx.Create(mk).Error; err != nil {
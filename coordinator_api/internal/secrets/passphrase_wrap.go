@@ -0,0 +1,49 @@
+package secrets
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// wrapSaltLen is the length of the random scrypt salt that prefixes a
+// passphrase-wrapped value.
+const wrapSaltLen = 16
+
+// WrapWithPassphrase encrypts plaintext under a key derived from
+// passphrase, for material that has to leave the database, like master
+// keys in a backup. The result is the scrypt salt followed by a Fernet
+// token; UnwrapWithPassphrase reverses it.
+func WrapWithPassphrase(passphrase string, plaintext []byte) ([]byte, error) {
+	if passphrase == "" {
+		return nil, errors.New("passphrase is required")
+	}
+	salt := make([]byte, wrapSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	token, err := fernetEncrypt(encodeFernetKey(key), plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return append(salt, token...), nil
+}
+
+// UnwrapWithPassphrase decrypts a value produced by WrapWithPassphrase. A
+// wrong passphrase fails the Fernet HMAC check.
+func UnwrapWithPassphrase(passphrase string, wrapped []byte) ([]byte, error) {
+	if len(wrapped) <= wrapSaltLen {
+		return nil, errors.New("wrapped value is too short")
+	}
+	key, err := scrypt.Key([]byte(passphrase), wrapped[:wrapSaltLen], scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	return fernetDecrypt(encodeFernetKey(key), wrapped[wrapSaltLen:])
+}
@@ -497,3 +497,103 @@ func (s *Storage) GetMulti(refs []SecretRef, password string) (map[string]string
 
 	return results, nil
 }
+
+// GetPath retrieves every secret in a path.
+func (s *Storage) GetPath(path, password string) (map[string]string, error) {
+	if err := validatePath(path); err != nil {
+		return nil, err
+	}
+
+	data, err := s.loadAll(password)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(data[path]))
+	for k, v := range data[path] {
+		values[k] = v
+	}
+	return values, nil
+}
+
+// SetMulti stores several secrets in a path with a single key derivation.
+func (s *Storage) SetMulti(path string, values map[string]string, password string) error {
+	if err := validatePath(path); err != nil {
+		return err
+	}
+	for key := range values {
+		if err := validateKey(key); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+
+	data, err := s.loadAll(password)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := data[path]; !ok {
+		data[path] = make(map[string]string)
+	}
+	for key, value := range values {
+		data[path][key] = value
+	}
+
+	return s.saveAll(data, password)
+}
+
+// Move moves a secret, or every secret in a path when from.Key is empty,
+// to another path and key. An empty to.Key keeps the key name. Returns how
+// many secrets were moved; 0 if there was nothing to move.
+func (s *Storage) Move(from, to SecretRef, password string) (int, error) {
+	if err := validatePath(from.Path); err != nil {
+		return 0, err
+	}
+	if err := validatePath(to.Path); err != nil {
+		return 0, err
+	}
+	if from.Key == "" && to.Key != "" {
+		return 0, fmt.Errorf("%s: %w", to.Key, ErrInvalidKey)
+	}
+
+	data, err := s.loadAll(password)
+	if err != nil {
+		return 0, err
+	}
+
+	moved := make(map[string]string)
+	for key, value := range data[from.Path] {
+		if from.Key == "" || key == from.Key {
+			moved[key] = value
+		}
+	}
+	if len(moved) == 0 {
+		return 0, nil
+	}
+	if to.Key != "" {
+		if err := validateKey(to.Key); err != nil {
+			return 0, err
+		}
+		moved = map[string]string{to.Key: moved[from.Key]}
+	}
+
+	for key := range data[from.Path] {
+		if from.Key == "" || key == from.Key {
+			delete(data[from.Path], key)
+		}
+	}
+	if len(data[from.Path]) == 0 {
+		delete(data, from.Path)
+	}
+	if _, ok := data[to.Path]; !ok {
+		data[to.Path] = make(map[string]string)
+	}
+	for key, value := range moved {
+		data[to.Path][key] = value
+	}
+
+	if err := s.saveAll(data, password); err != nil {
+		return 0, err
+	}
+	return len(moved), nil
+}
@@ -358,6 +358,77 @@ func TestGetMultiNotFound(t *testing.T) {
 	}
 }
 
+func TestSetMultiAndGetPath(t *testing.T) {
+	storage, _, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	password := "testpassword"
+	if err := storage.Init(password, false); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+
+	values := map[string]string{"A": "one", "B": "two"}
+	if err := storage.SetMulti("project/test", values, password); err != nil {
+		t.Fatalf("SetMulti failed: %v", err)
+	}
+	if err := storage.SetMulti("project/test", map[string]string{"bad/key": "x"}, password); err == nil {
+		t.Error("SetMulti should reject an invalid key")
+	}
+
+	got, err := storage.GetPath("project/test", password)
+	if err != nil {
+		t.Fatalf("GetPath failed: %v", err)
+	}
+	if len(got) != 2 || got["A"] != "one" || got["B"] != "two" {
+		t.Errorf("unexpected path contents: %v", got)
+	}
+
+	empty, err := storage.GetPath("project/other", password)
+	if err != nil || len(empty) != 0 {
+		t.Errorf("expected empty path, got %v, %v", empty, err)
+	}
+}
+
+func TestMove(t *testing.T) {
+	storage, _, cleanup := setupTestStorage(t)
+	defer cleanup()
+
+	password := "testpassword"
+	if err := storage.Init(password, false); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	if err := storage.SetMulti("old", map[string]string{"A": "one", "B": "two"}, password); err != nil {
+		t.Fatalf("SetMulti failed: %v", err)
+	}
+
+	// Move and rename one key.
+	moved, err := storage.Move(SecretRef{Path: "old", Key: "A"}, SecretRef{Path: "new", Key: "RENAMED"}, password)
+	if err != nil || moved != 1 {
+		t.Fatalf("Move key = %d, %v", moved, err)
+	}
+	if value, _ := storage.Get("new", "RENAMED", password); value != "one" {
+		t.Errorf("expected moved value, got %q", value)
+	}
+	if value, _ := storage.Get("old", "A", password); value != "" {
+		t.Errorf("expected source removed, got %q", value)
+	}
+
+	// Move what's left of the path.
+	moved, err = storage.Move(SecretRef{Path: "old"}, SecretRef{Path: "new"}, password)
+	if err != nil || moved != 1 {
+		t.Fatalf("Move path = %d, %v", moved, err)
+	}
+	paths, _ := storage.ListPaths(password)
+	if len(paths) != 1 || paths[0] != "new" {
+		t.Errorf("expected only the new path left, got %v", paths)
+	}
+
+	moved, err = storage.Move(SecretRef{Path: "old"}, SecretRef{Path: "new"}, password)
+	if err != nil || moved != 0 {
+		t.Errorf("expected nothing to move, got %d, %v", moved, err)
+	}
+}
+
 func TestValidatePath(t *testing.T) {
 	tests := []struct {
 		path    string
@@ -0,0 +1,37 @@
+package secrets
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// SecretLookup returns the value of path:key, or "" if there is none.
+type SecretLookup func(path, key string) (string, error)
+
+// RenderTemplate renders text as a Go text/template in which
+// {{secret "path" "key"}} is replaced with that secret's value. A secret
+// that doesn't exist is an error rather than an empty substitution.
+func RenderTemplate(text string, lookup SecretLookup) (string, error) {
+	tmpl, err := template.New("secrets").Option("missingkey=error").Funcs(template.FuncMap{
+		"secret": func(path, key string) (string, error) {
+			value, err := lookup(path, key)
+			if err != nil {
+				return "", err
+			}
+			if value == "" {
+				return "", fmt.Errorf("secret not found: %s:%s", path, key)
+			}
+			return value, nil
+		},
+	}).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, nil); err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
+	}
+	return out.String(), nil
+}
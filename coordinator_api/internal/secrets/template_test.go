@@ -0,0 +1,28 @@
+package secrets
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	values := map[string]string{"app:DB_PASSWORD": "hunter2", "deploy:TOKEN": "abc"}
+	lookup := func(path, key string) (string, error) {
+		return values[path+":"+key], nil
+	}
+
+	out, err := RenderTemplate("db: {{secret \"app\" \"DB_PASSWORD\"}}\ntoken: {{ secret \"deploy\" \"TOKEN\" }}\n", lookup)
+	if err != nil {
+		t.Fatalf("RenderTemplate failed: %v", err)
+	}
+	if out != "db: hunter2\ntoken: abc\n" {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	if _, err := RenderTemplate(`{{secret "app" "MISSING"}}`, lookup); err == nil || !strings.Contains(err.Error(), "app:MISSING") {
+		t.Errorf("expected a missing secret error, got %v", err)
+	}
+	if _, err := RenderTemplate(`{{secret "app"`, lookup); err == nil {
+		t.Error("expected a parse error")
+	}
+}
@@ -0,0 +1,214 @@
+package secrets
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"gorm.io/gorm"
+)
+
+// reencryptBatchSize caps how many secrets one query of a sweep loads.
+const reencryptBatchSize = 500
+
+// maxTokenAge is the default maximum age of a stored secret's Fernet token
+// for new DatabaseProviders, in nanoseconds. Zero disables re-encryption.
+var maxTokenAge atomic.Int64
+
+// SetMaxTokenAge sets the maximum token age new DatabaseProviders enforce:
+// a secret value encrypted longer ago than maxAge is re-encrypted when it's
+// read. Zero disables re-encryption on read.
+func SetMaxTokenAge(maxAge time.Duration) {
+	maxTokenAge.Store(int64(maxAge))
+}
+
+// fernetTokenTime returns when a Fernet token was issued. The timestamp is
+// read without verifying the token.
+func fernetTokenTime(token []byte) (time.Time, error) {
+	if len(token) < 9 || token[0] != fernetVersion {
+		return time.Time{}, errors.New("invalid fernet token")
+	}
+	return time.Unix(int64(binary.BigEndian.Uint64(token[1:9])), 0).UTC(), nil
+}
+
+// tokenStale reports whether token was issued before cutoff. Tokens whose
+// timestamp can't be read aren't stale; decrypting them fails anyway.
+func tokenStale(token []byte, cutoff time.Time) bool {
+	issued, err := fernetTokenTime(token)
+	return err == nil && issued.Before(cutoff)
+}
+
+// ReencryptResult counts what a ReencryptStale sweep did.
+type ReencryptResult struct {
+	OrgKeys int `json:"org_keys"`
+	Secrets int `json:"secrets"`
+	// SkippedOrgs are orgs whose org key this process can't decrypt.
+	SkippedOrgs []string `json:"skipped_orgs,omitempty"`
+}
+
+// ReencryptStale re-encrypts everything encrypted more than maxAge ago:
+//
+//   - an org key encrypted by the primary master key is re-encrypted with
+//     it in place;
+//   - an org with no org key under the primary master key, whose newest org
+//     key is stale, gains one, so the older master keys can be
+//     decommissioned;
+//   - secret values are re-encrypted with their org's key.
+//
+// In-place updates only apply if the row is unchanged since it was read, so
+// a sweep racing a write never clobbers it.
+func (m *MasterKeyManager) ReencryptStale(db *gorm.DB, maxAge time.Duration) (*ReencryptResult, error) {
+	result := &ReencryptResult{}
+	if maxAge <= 0 {
+		return result, nil
+	}
+	cutoff := time.Now().UTC().Add(-maxAge)
+
+	var primary models.MasterKey
+	if err := db.Where("name = ?", m.primaryKey).First(&primary).Error; err != nil {
+		return nil, fmt.Errorf("primary master key %s not registered in database: %w", m.primaryKey, err)
+	}
+	var orgKeys []orgKeyRow
+	if err := db.Table("org_encryption_keys").
+		Select("org_encryption_keys.*, master_keys.name as master_key_name, master_keys.is_active as master_key_is_active").
+		Joins("JOIN master_keys ON master_keys.key_id = org_encryption_keys.master_key_id").
+		Order("org_encryption_keys.user_id, org_encryption_keys.created_at DESC").
+		Find(&orgKeys).Error; err != nil {
+		return nil, fmt.Errorf("failed to list org encryption keys: %w", err)
+	}
+
+	byOrg := map[string][]orgKeyRow{}
+	var orgIDs []string
+	for _, orgKey := range orgKeys {
+		if _, ok := byOrg[orgKey.UserID]; !ok {
+			orgIDs = append(orgIDs, orgKey.UserID)
+		}
+		byOrg[orgKey.UserID] = append(byOrg[orgKey.UserID], orgKey)
+	}
+
+	primaryFernetKey := m.fernetKey(m.primaryKey)
+	for _, orgID := range orgIDs {
+		orgKey, err := m.GetOrgEncryptionKey(db, orgID)
+		if err != nil {
+			result.SkippedOrgs = append(result.SkippedOrgs, orgID)
+			continue
+		}
+
+		rows := byOrg[orgID]
+		hasPrimary := false
+		for _, row := range rows {
+			if row.MasterKeyID != primary.KeyID {
+				continue
+			}
+			hasPrimary = true
+			if !tokenStale(row.EncryptedKey, cutoff) {
+				continue
+			}
+			encrypted, err := fernetEncrypt(primaryFernetKey, orgKey)
+			if err != nil {
+				return nil, err
+			}
+			update := db.Model(&models.OrgEncryptionKey{}).
+				Where("id = ? AND encrypted_key = ?", row.ID, row.EncryptedKey).
+				Update("encrypted_key", encrypted)
+			if update.Error != nil {
+				return nil, fmt.Errorf("failed to re-encrypt org key for %s: %w", orgID, update.Error)
+			}
+			result.OrgKeys += int(update.RowsAffected)
+		}
+		if !hasPrimary && tokenStale(rows[0].EncryptedKey, cutoff) {
+			encrypted, err := fernetEncrypt(primaryFernetKey, orgKey)
+			if err != nil {
+				return nil, err
+			}
+			if err := db.Create(&models.OrgEncryptionKey{
+				UserID:       orgID,
+				MasterKeyID:  primary.KeyID,
+				EncryptedKey: encrypted,
+				Salt:         rows[0].Salt,
+			}).Error; err != nil {
+				return nil, fmt.Errorf("failed to re-encrypt org key for %s: %w", orgID, err)
+			}
+			result.OrgKeys++
+		}
+
+		provider, err := NewDatabaseProvider(db, orgID, orgKey)
+		if err != nil {
+			return nil, err
+		}
+		n, err := provider.reencryptStale(db, cutoff)
+		result.Secrets += n
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// reencryptStale re-encrypts the org's secrets whose tokens were issued
+// before cutoff, returning how many it re-encrypted.
+func (p *DatabaseProvider) reencryptStale(db *gorm.DB, cutoff time.Time) (int, error) {
+	count := 0
+	after := ""
+	for {
+		var batch []models.Secret
+		query := db.Where("user_id = ?", p.orgID).Order("secret_id").Limit(reencryptBatchSize)
+		if after != "" {
+			query = query.Where("secret_id > ?", after)
+		}
+		if err := query.Find(&batch).Error; err != nil {
+			return count, fmt.Errorf("failed to list secrets for %s: %w", p.orgID, err)
+		}
+		for i := range batch {
+			if !tokenStale(batch[i].EncryptedValue, cutoff) {
+				continue
+			}
+			value, err := p.decrypt(batch[i].EncryptedValue)
+			if err != nil {
+				continue // Undecryptable values are left for the health check to report
+			}
+			ok, err := p.reencrypt(db, &batch[i], value)
+			if err != nil {
+				return count, err
+			}
+			if ok {
+				count++
+			}
+		}
+		if len(batch) < reencryptBatchSize {
+			return count, nil
+		}
+		after = batch[len(batch)-1].SecretID
+	}
+}
+
+// refreshIfStale re-encrypts secret with a fresh token if its token is older
+// than the provider's maximum token age. It's best effort: a secret that
+// fails to re-encrypt still reads, and the next read or sweep retries.
+func (p *DatabaseProvider) refreshIfStale(db *gorm.DB, secret *models.Secret, value string) {
+	if p.maxTokenAge <= 0 || !tokenStale(secret.EncryptedValue, time.Now().UTC().Add(-p.maxTokenAge)) {
+		return
+	}
+	_, _ = p.reencrypt(db, secret, value)
+}
+
+// reencrypt replaces secret's encrypted value with value encrypted afresh,
+// unless the row changed since secret was read. It reports whether the row
+// was updated.
+func (p *DatabaseProvider) reencrypt(db *gorm.DB, secret *models.Secret, value string) (bool, error) {
+	encrypted, err := p.encrypt(value)
+	if err != nil {
+		return false, fmt.Errorf("failed to encrypt secret: %w", err)
+	}
+	// updated_at is left alone: the value didn't change.
+	update := db.Model(&models.Secret{}).
+		Where("secret_id = ? AND encrypted_value = ?", secret.SecretID, secret.EncryptedValue).
+		Update("encrypted_value", encrypted)
+	if update.Error != nil {
+		return false, fmt.Errorf("failed to re-encrypt secret %s:%s: %w", secret.Path, secret.Key, update.Error)
+	}
+	return update.RowsAffected > 0, nil
+}
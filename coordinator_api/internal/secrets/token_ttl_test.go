@@ -0,0 +1,72 @@
+package secrets
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+func TestFernetTokenTime(t *testing.T) {
+	before := time.Now().Add(-time.Second)
+	token, err := fernetEncrypt(encodeFernetKey(testMasterKey(1)), []byte("value"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	issued, err := fernetTokenTime(token)
+	if err != nil {
+		t.Fatalf("fernetTokenTime failed: %v", err)
+	}
+	if issued.Before(before.Truncate(time.Second)) || issued.After(time.Now()) {
+		t.Errorf("unexpected issue time %v", issued)
+	}
+
+	if _, err := fernetTokenTime([]byte{fernetVersion, 1}); err == nil {
+		t.Error("expected an error for a truncated token")
+	}
+	if _, err := fernetTokenTime(make([]byte, 73)); err == nil {
+		t.Error("expected an error for a token with the wrong version")
+	}
+}
+
+func TestTokenStale(t *testing.T) {
+	token, err := fernetEncrypt(encodeFernetKey(testMasterKey(1)), []byte("value"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokenStale(token, time.Now().Add(-time.Hour)) {
+		t.Error("expected a fresh token not stale")
+	}
+
+	// Backdate the token; the timestamp isn't covered by anything
+	// tokenStale checks.
+	old := append([]byte(nil), token...)
+	binary.BigEndian.PutUint64(old[1:9], uint64(time.Now().Add(-48*time.Hour).Unix()))
+	if !tokenStale(old, time.Now().Add(-24*time.Hour)) {
+		t.Error("expected a backdated token stale")
+	}
+
+	if tokenStale([]byte("garbage"), time.Now()) {
+		t.Error("expected an unreadable token not stale")
+	}
+}
+
+func TestSetMaxTokenAge(t *testing.T) {
+	defer SetMaxTokenAge(0)
+
+	SetMaxTokenAge(30 * 24 * time.Hour)
+	provider, err := NewDatabaseProvider(nil, "org", testMasterKey(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if provider.maxTokenAge != 30*24*time.Hour {
+		t.Errorf("expected the provider to take the max token age, got %v", provider.maxTokenAge)
+	}
+
+	// Without a max token age, reads never write.
+	SetMaxTokenAge(0)
+	provider, err = NewDatabaseProvider(nil, "org", testMasterKey(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider.refreshIfStale(nil, nil, "value")
+}
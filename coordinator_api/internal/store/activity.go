@@ -0,0 +1,49 @@
+package store
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ActivityFilter selects the activity feed events a list returns, newest
+// first. Empty Kinds and ProjectID select every kind and project; After,
+// the last event of the previous page, continues a list.
+type ActivityFilter struct {
+	Kinds     []string
+	ProjectID string
+	Since     time.Time
+	After     *ActivityCursor
+	Limit     int
+}
+
+// ActivityCursor marks a position in the activity feed: the last event of
+// a page.
+type ActivityCursor struct {
+	OccurredAt time.Time
+	EventID    string
+}
+
+// Encode returns the cursor as an opaque string for API clients.
+func (c ActivityCursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.OccurredAt.UTC().Format(time.RFC3339Nano) + "|" + c.EventID))
+}
+
+// ParseActivityCursor decodes a cursor from ActivityCursor.Encode. A
+// malformed one returns ErrInvalidInput.
+func ParseActivityCursor(s string) (ActivityCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return ActivityCursor{}, fmt.Errorf("%w: malformed cursor", ErrInvalidInput)
+	}
+	occurredAt, eventID, ok := strings.Cut(string(raw), "|")
+	if !ok || eventID == "" {
+		return ActivityCursor{}, fmt.Errorf("%w: malformed cursor", ErrInvalidInput)
+	}
+	t, err := time.Parse(time.RFC3339Nano, occurredAt)
+	if err != nil {
+		return ActivityCursor{}, fmt.Errorf("%w: malformed cursor", ErrInvalidInput)
+	}
+	return ActivityCursor{OccurredAt: t, EventID: eventID}, nil
+}
@@ -0,0 +1,67 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DBError is a database error classified as one of the store's errors
+// (ErrAlreadyExists, ErrReferenceViolation, ErrConflict, ErrInvalidInput or
+// ErrServiceUnavailable). errors.Is matches both Kind and the driver's
+// error, so callers can test for either.
+type DBError struct {
+	Kind error
+	// Code is the SQLSTATE, and Constraint the violated constraint if
+	// any.
+	Code       string
+	Constraint string
+	Err        error
+}
+
+func (e *DBError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *DBError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// IsRetryable reports whether err is a transient conflict with another
+// transaction, which retrying the transaction may get past.
+func IsRetryable(err error) bool {
+	return errors.Is(err, ErrConflict)
+}
+
+// MaxTransactionAttempts is how many times RunInTransaction tries a
+// transaction that keeps failing with a retryable error.
+const MaxTransactionAttempts = 3
+
+// RunInTransaction runs fn in a transaction on db, retrying the whole
+// transaction when it fails with a retryable error. When db is already a
+// transaction, fn runs once in a savepoint: the outer transaction is
+// aborted by the failure, so only its owner can retry it.
+func RunInTransaction(ctx context.Context, db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	if _, inTx := db.Statement.ConnPool.(gorm.TxCommitter); inTx {
+		return db.Transaction(fn)
+	}
+	var err error
+	for attempt := 0; attempt < MaxTransactionAttempts; attempt++ {
+		if attempt > 0 {
+			backoff := time.Duration(10<<attempt)*time.Millisecond + time.Duration(rand.Int63n(int64(10*time.Millisecond)))
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(backoff):
+			}
+		}
+		err = db.Transaction(fn)
+		if !IsRetryable(err) {
+			return err
+		}
+	}
+	return err
+}
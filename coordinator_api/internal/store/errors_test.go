@@ -0,0 +1,102 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// txDriver is a database/sql driver whose connections only begin, commit
+// and roll back transactions, counting them.
+type txDriver struct{ begins, commits int }
+
+func (d *txDriver) Open(name string) (driver.Conn, error) { return &txConn{d}, nil }
+
+type txConn struct{ d *txDriver }
+
+func (c *txConn) Prepare(query string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *txConn) Close() error                              { return nil }
+func (c *txConn) Begin() (driver.Tx, error) {
+	c.d.begins++
+	return c, nil
+}
+func (c *txConn) Commit() error {
+	c.d.commits++
+	return nil
+}
+func (c *txConn) Rollback() error { return nil }
+
+func newTxTestDB(t *testing.T) (*gorm.DB, *txDriver) {
+	t.Helper()
+	d := &txDriver{}
+	sql.Register(t.Name(), d)
+	sqlDB, err := sql.Open(t.Name(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, d
+}
+
+func TestRunInTransaction_RetriesConflicts(t *testing.T) {
+	db, d := newTxTestDB(t)
+	conflict := &DBError{Kind: ErrConflict, Code: "40P01", Err: errors.New("deadlock detected")}
+
+	calls := 0
+	err := RunInTransaction(context.Background(), db, func(tx *gorm.DB) error {
+		calls++
+		if calls < 2 {
+			return conflict
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RunInTransaction: %v", err)
+	}
+	if calls != 2 || d.begins != 2 || d.commits != 1 {
+		t.Errorf("calls=%d begins=%d commits=%d; want 2, 2, 1", calls, d.begins, d.commits)
+	}
+
+	// A conflict on every attempt gives up after MaxTransactionAttempts.
+	calls = 0
+	err = RunInTransaction(context.Background(), db, func(tx *gorm.DB) error {
+		calls++
+		return conflict
+	})
+	if !errors.Is(err, ErrConflict) || calls != MaxTransactionAttempts {
+		t.Errorf("err=%v calls=%d; want ErrConflict after %d attempts", err, calls, MaxTransactionAttempts)
+	}
+
+	// Other errors aren't retried.
+	calls = 0
+	err = RunInTransaction(context.Background(), db, func(tx *gorm.DB) error {
+		calls++
+		return ErrNotFound
+	})
+	if !errors.Is(err, ErrNotFound) || calls != 1 {
+		t.Errorf("err=%v calls=%d; want ErrNotFound after 1 attempt", err, calls)
+	}
+}
+
+func TestDBError(t *testing.T) {
+	driverErr := errors.New("duplicate key value violates unique constraint")
+	err := error(&DBError{Kind: ErrAlreadyExists, Code: "23505", Constraint: "users_email_key", Err: driverErr})
+
+	if !errors.Is(err, ErrAlreadyExists) || !errors.Is(err, driverErr) {
+		t.Error("DBError should match both its kind and the driver error")
+	}
+	if IsRetryable(err) {
+		t.Error("a unique violation isn't retryable")
+	}
+	if err.Error() != driverErr.Error() {
+		t.Errorf("Error() = %q", err.Error())
+	}
+}
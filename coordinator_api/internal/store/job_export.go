@@ -0,0 +1,16 @@
+package store
+
+import "time"
+
+// JobExportFilter selects the jobs of a data export. Zero fields don't
+// restrict it.
+type JobExportFilter struct {
+	ProjectID string
+	// CreatedFrom and CreatedBefore bound the jobs' created_at: from is
+	// inclusive, before exclusive.
+	CreatedFrom   time.Time
+	CreatedBefore time.Time
+	// After restricts the export to jobs after the cursor in the
+	// (created_at, job_id) ascending order exports use.
+	After *JobCursor
+}
@@ -0,0 +1,138 @@
+package store
+
+import (
+	"context"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// DefaultJobBatchSize is how many jobs a JobIterator loads at a time when
+// its caller doesn't say.
+const DefaultJobBatchSize = 500
+
+// JobPager loads the page of up to limit jobs after cursor, or the first
+// page if cursor is nil. Pages must come in a stable order with the
+// cursor's job last, as keyset pages over (created_at, job_id) do.
+type JobPager func(ctx context.Context, after *JobCursor, limit int) ([]models.Job, error)
+
+// JobIterator walks a job result set of any size one batch at a time, so
+// only a batch is ever in memory. Each batch is loaded after the last job
+// of the one before, rather than at an offset, so jobs that leave the
+// result set mid-walk (released, archived, deleted) don't shift the rest.
+//
+//	it := store.NewJobIterator(store.ListJobsPager(st, filters), 0)
+//	for it.Next(ctx) {
+//		job := it.Job()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type JobIterator struct {
+	pager     JobPager
+	batchSize int
+	batch     []models.Job
+	index     int
+	after     *JobCursor
+	last      bool
+	err       error
+}
+
+// NewJobIterator creates an iterator over the pages pager loads,
+// batchSize jobs at a time (DefaultJobBatchSize if not positive).
+func NewJobIterator(pager JobPager, batchSize int) *JobIterator {
+	if batchSize <= 0 {
+		batchSize = DefaultJobBatchSize
+	}
+	return &JobIterator{pager: pager, batchSize: batchSize, index: -1}
+}
+
+// Next advances to the next job, loading the next batch when the current
+// one is used up. It returns false at the end of the result set, or on an
+// error, which Err then returns.
+func (it *JobIterator) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+	if it.index+1 < len(it.batch) {
+		it.index++
+		return true
+	}
+	if it.last {
+		return false
+	}
+	if err := ctx.Err(); err != nil {
+		it.err = err
+		return false
+	}
+	batch, err := it.pager(ctx, it.after, it.batchSize)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.batch, it.index = batch, 0
+	it.last = len(batch) < it.batchSize
+	if len(batch) == 0 {
+		return false
+	}
+	end := batch[len(batch)-1]
+	it.after = &JobCursor{CreatedAt: end.CreatedAt, JobID: end.JobID}
+	return true
+}
+
+// Job returns the current job. It's only valid after Next returns true,
+// and until the next call to Next.
+func (it *JobIterator) Job() *models.Job {
+	return &it.batch[it.index]
+}
+
+// Cursor returns the cursor after the current batch: a new iterator over
+// the same result set, given a pager starting there, picks up with the
+// next batch. Nil before the first batch.
+func (it *JobIterator) Cursor() *JobCursor {
+	return it.after
+}
+
+// Err returns the error that stopped the iterator, if any.
+func (it *JobIterator) Err() error {
+	return it.err
+}
+
+// JobLister is the store capability behind ListJobsPager; every Store
+// has it.
+type JobLister interface {
+	ListJobs(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]models.Job, error)
+}
+
+// ListJobsPager pages through ListJobs with filters, newest first, keyset
+// by JobCursorFilter.
+func ListJobsPager(st JobLister, filters map[string]interface{}) JobPager {
+	return func(ctx context.Context, after *JobCursor, limit int) ([]models.Job, error) {
+		page := make(map[string]interface{}, len(filters)+1)
+		for key, value := range filters {
+			page[key] = value
+		}
+		if after != nil {
+			page[JobCursorFilter] = *after
+		}
+		return st.ListJobs(ctx, page, limit, 0)
+	}
+}
+
+// JobExportLister is the store capability behind ExportJobsPager. See
+// postgres_store/job_export_operations.go.
+type JobExportLister interface {
+	ListJobsForExport(ctx context.Context, filter JobExportFilter, limit int) ([]models.Job, error)
+}
+
+// ExportJobsPager pages through ListJobsForExport with filter, oldest
+// first, archived jobs included, starting after filter.After if set.
+func ExportJobsPager(lister JobExportLister, filter JobExportFilter) JobPager {
+	return func(ctx context.Context, after *JobCursor, limit int) ([]models.Job, error) {
+		page := filter
+		if after != nil {
+			page.After = after
+		}
+		return lister.ListJobsForExport(ctx, page, limit)
+	}
+}
@@ -0,0 +1,109 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// pagedJobs serves jobs newest first, keyset by cursor, like ListJobs.
+func pagedJobs(jobs []models.Job, calls *int) JobPager {
+	return func(ctx context.Context, after *JobCursor, limit int) ([]models.Job, error) {
+		*calls++
+		start := 0
+		if after != nil {
+			for start < len(jobs) && jobs[start].JobID != after.JobID {
+				start++
+			}
+			start++
+		}
+		end := start + limit
+		if end > len(jobs) {
+			end = len(jobs)
+		}
+		if start > end {
+			start = end
+		}
+		return jobs[start:end], nil
+	}
+}
+
+func TestJobIterator(t *testing.T) {
+	base := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	var jobs []models.Job
+	for i := 0; i < 7; i++ {
+		jobs = append(jobs, models.Job{JobID: fmt.Sprintf("job-%d", i), CreatedAt: base.Add(-time.Duration(i) * time.Minute)})
+	}
+
+	for _, tc := range []struct {
+		batch, calls int
+	}{
+		{batch: 3, calls: 3}, // 3 + 3 + 1
+		{batch: 7, calls: 2}, // a full batch needs one more, empty, load
+		{batch: 10, calls: 1},
+	} {
+		calls := 0
+		it := NewJobIterator(pagedJobs(jobs, &calls), tc.batch)
+		var seen []string
+		for it.Next(context.Background()) {
+			seen = append(seen, it.Job().JobID)
+		}
+		if err := it.Err(); err != nil {
+			t.Fatalf("batch %d: %v", tc.batch, err)
+		}
+		if len(seen) != len(jobs) || seen[0] != "job-0" || seen[6] != "job-6" {
+			t.Errorf("batch %d: saw %v", tc.batch, seen)
+		}
+		if calls != tc.calls {
+			t.Errorf("batch %d: %d loads, want %d", tc.batch, calls, tc.calls)
+		}
+	}
+}
+
+func TestJobIterator_Error(t *testing.T) {
+	boom := errors.New("boom")
+	it := NewJobIterator(func(ctx context.Context, after *JobCursor, limit int) ([]models.Job, error) {
+		if after != nil {
+			return nil, boom
+		}
+		return []models.Job{{JobID: "job-1"}, {JobID: "job-2"}}, nil
+	}, 2)
+	n := 0
+	for it.Next(context.Background()) {
+		n++
+	}
+	if n != 2 || !errors.Is(it.Err(), boom) {
+		t.Errorf("got %d jobs and %v, want 2 and the pager's error", n, it.Err())
+	}
+	if it.Next(context.Background()) {
+		t.Error("Next after an error should stay false")
+	}
+}
+
+func TestListJobsPager_KeepsFilters(t *testing.T) {
+	filters := map[string]interface{}{"status": "running"}
+	cursor := &JobCursor{JobID: "job-1"}
+	var got map[string]interface{}
+	pager := ListJobsPager(listJobsFunc(func(f map[string]interface{}) { got = f }), filters)
+	if _, err := pager(context.Background(), cursor, 10); err != nil {
+		t.Fatal(err)
+	}
+	if got["status"] != "running" || got[JobCursorFilter] != *cursor {
+		t.Errorf("page filters = %v", got)
+	}
+	if _, ok := filters[JobCursorFilter]; ok {
+		t.Error("the caller's filters should not be modified")
+	}
+}
+
+// listJobsFunc is a JobLister that records the filters it's called with.
+type listJobsFunc func(filters map[string]interface{})
+
+func (f listJobsFunc) ListJobs(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]models.Job, error) {
+	f(filters)
+	return nil, nil
+}
@@ -0,0 +1,48 @@
+package store
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobCursorFilter is the job list filter key for keyset pagination: its
+// value, a JobCursor, restricts the list to jobs after the cursor in the
+// (created_at, job_id) descending order lists use.
+const JobCursorFilter = "cursor"
+
+// IncludeArchivedFilter is the job list filter key that, set to true,
+// lists archived jobs along with the rest.
+const IncludeArchivedFilter = "include_archived"
+
+// JobCursor marks a position in a job list: the last job of a page.
+type JobCursor struct {
+	CreatedAt time.Time
+	JobID     string
+}
+
+// Encode returns the cursor as an opaque string for API clients.
+func (c JobCursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.JobID))
+}
+
+// ParseJobCursor decodes a cursor from JobCursor.Encode. A malformed one
+// returns ErrInvalidInput.
+func ParseJobCursor(s string) (JobCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return JobCursor{}, fmt.Errorf("%w: malformed cursor", ErrInvalidInput)
+	}
+	createdAt, jobID, ok := strings.Cut(string(raw), "|")
+	if _, err := uuid.Parse(jobID); !ok || err != nil {
+		return JobCursor{}, fmt.Errorf("%w: malformed cursor", ErrInvalidInput)
+	}
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return JobCursor{}, fmt.Errorf("%w: malformed cursor", ErrInvalidInput)
+	}
+	return JobCursor{CreatedAt: t, JobID: jobID}, nil
+}
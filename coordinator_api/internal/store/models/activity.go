@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// Activity feed event kinds. See ActivityEvent.
+const (
+	// ActivityJobStarted is a job starting on a worker.
+	ActivityJobStarted = "job_started"
+	// ActivityJobFailed is a job finishing failed or timed out.
+	ActivityJobFailed = "job_failed"
+	// ActivityProjectCreated is a project being created.
+	ActivityProjectCreated = "project_created"
+	// ActivityProjectUpdated is a change to a project's configuration,
+	// from its config history (see ProjectConfigVersion), detailing the
+	// fields it changed. For projects not changed since the history was
+	// added, it's their last change, without details.
+	ActivityProjectUpdated = "project_updated"
+	// ActivityCredentialRotated is a webhook secret or VCS credential
+	// being added to a project, the first step of rotating one.
+	ActivityCredentialRotated = "credential_rotated"
+	// ActivitySecretRotated is a secret's value last being replaced.
+	ActivitySecretRotated = "secret_rotated"
+	// ActivityApprovalGranted is a user approving a promotion.
+	ActivityApprovalGranted = "approval_granted"
+)
+
+// ActivityKinds lists every activity event kind.
+var ActivityKinds = []string{
+	ActivityJobStarted,
+	ActivityJobFailed,
+	ActivityProjectCreated,
+	ActivityProjectUpdated,
+	ActivityCredentialRotated,
+	ActivitySecretRotated,
+	ActivityApprovalGranted,
+}
+
+// IsValidActivityKind reports whether kind is an activity event kind.
+func IsValidActivityKind(kind string) bool {
+	for _, k := range ActivityKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// ActivityEvent is one entry of the activity feed, read from the record
+// it describes rather than stored: a job, project, project credential,
+// secret or promotion approval. SubjectID is that record's ID and Summary
+// its name (the job's or project's name, the credential's name, the
+// secret's path:key, the promotion's target environment). ActorID is the
+// user who acted, where the record says. EventID is unique in the feed.
+type ActivityEvent struct {
+	EventID     string    `json:"event_id"`
+	Kind        string    `json:"kind"`
+	OccurredAt  time.Time `json:"occurred_at"`
+	ProjectID   *string   `json:"project_id,omitempty"`
+	ProjectName string    `json:"project_name,omitempty"`
+	SubjectID   string    `json:"subject_id"`
+	Summary     string    `json:"summary"`
+	Detail      string    `json:"detail,omitempty"`
+	ActorID     *string   `json:"actor_id,omitempty"`
+}
@@ -0,0 +1,391 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Admission policy targets: what a policy's rules are evaluated against.
+// "job" is every job about to be created, wherever it comes from;
+// "trigger" each job spec of a triggers document, before any of its jobs
+// is created; "project" a project being created or updated.
+const (
+	PolicyTargetJob     = "job"
+	PolicyTargetTrigger = "trigger"
+	PolicyTargetProject = "project"
+)
+
+// Admission policy enforcement. A "deny" policy's violations reject the
+// request, a "warn" policy's are only logged (to try a policy out), and an
+// "off" policy isn't evaluated.
+const (
+	PolicyEnforceDeny = "deny"
+	PolicyEnforceWarn = "warn"
+	PolicyEnforceOff  = "off"
+)
+
+// Admission policy rule operators.
+const (
+	PolicyOpRequired   = "required"
+	PolicyOpForbidden  = "forbidden"
+	PolicyOpIn         = "in"
+	PolicyOpNotIn      = "not_in"
+	PolicyOpMatches    = "matches"
+	PolicyOpNotMatches = "not_matches"
+	PolicyOpMax        = "max"
+	PolicyOpMin        = "min"
+)
+
+// maxPolicyRules caps the rules, conditions included, of one policy.
+const maxPolicyRules = 100
+
+var policyFieldPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+(\.[A-Za-z0-9_.-]+)?$`)
+
+// AdmissionPolicy is a set of rules an admin writes for what jobs, trigger
+// specs or projects are allowed: for example only images from the
+// company registry, a TEAM environment variable on every job, or no
+// timeout over two hours. A nil ProjectID applies to every project.
+type AdmissionPolicy struct {
+	PolicyID    string      `gorm:"primaryKey;type:uuid;default:generate_ulid()" json:"policy_id"`
+	Name        string      `gorm:"type:text;not null" json:"name"`
+	Description string      `gorm:"type:text;not null;default:''" json:"description,omitempty"`
+	Target      string      `gorm:"type:text;not null" json:"target"`
+	Enforcement string      `gorm:"type:text;not null;default:'deny'" json:"enforcement"`
+	ProjectID   *string     `gorm:"type:uuid" json:"project_id,omitempty"`
+	Rules       PolicyRules `gorm:"type:jsonb;not null;default:'[]'" json:"rules"`
+	CreatedBy   *string     `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt   time.Time   `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"created_at"`
+	UpdatedAt   time.Time   `gorm:"autoUpdateTime:false;default:timezone('utc', now())" json:"updated_at"`
+}
+
+// TableName specifies the table name for the model.
+func (AdmissionPolicy) TableName() string {
+	return "admission_policies"
+}
+
+// PolicyRule checks one field of the document a policy is evaluated
+// against, its JSON field name, or "<object>.<key>" for a key of an object
+// field such as job_env_vars. When lists conditions, rules that must all
+// hold for this one to apply. Message replaces the generated denial
+// message.
+//
+// Values holds the allowed (in) or disallowed (not_in) values, as
+// path.Match globs; Pattern the regular expression for matches and
+// not_matches; Limit the bound for max and min. in, not_in, matches and
+// not_matches check every element of a list field, and every key of an
+// object field. Apart from required and forbidden, a rule passes when its
+// field is empty.
+type PolicyRule struct {
+	Field   string       `json:"field"`
+	Op      string       `json:"op"`
+	Values  []string     `json:"values,omitempty"`
+	Pattern string       `json:"pattern,omitempty"`
+	Limit   *float64     `json:"limit,omitempty"`
+	Message string       `json:"message,omitempty"`
+	When    []PolicyRule `json:"when,omitempty"`
+}
+
+// PolicyRules is a policy's rules, stored in a jsonb column.
+type PolicyRules []PolicyRule
+
+// Value implements driver.Valuer interface for database storage.
+func (r PolicyRules) Value() (driver.Value, error) {
+	if r == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(r)
+}
+
+// Scan implements sql.Scanner interface for database retrieval.
+func (r *PolicyRules) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		*r = nil
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into PolicyRules", value)
+	}
+	return json.Unmarshal(bytes, r)
+}
+
+// PolicyViolation is a rule a document broke: the policy, the rule's
+// position in it and the field it checks.
+type PolicyViolation struct {
+	PolicyID    string `json:"policy_id"`
+	Policy      string `json:"policy"`
+	Enforcement string `json:"enforcement"`
+	Rule        int    `json:"rule"`
+	Field       string `json:"field"`
+	Message     string `json:"message"`
+}
+
+// Validate checks the policy's settings and rules.
+func (p *AdmissionPolicy) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return errors.New("name is required")
+	}
+	switch p.Target {
+	case PolicyTargetJob, PolicyTargetTrigger, PolicyTargetProject:
+	default:
+		return fmt.Errorf("target must be %q, %q or %q", PolicyTargetJob, PolicyTargetTrigger, PolicyTargetProject)
+	}
+	switch p.Enforcement {
+	case PolicyEnforceDeny, PolicyEnforceWarn, PolicyEnforceOff:
+	default:
+		return fmt.Errorf("enforcement must be %q, %q or %q", PolicyEnforceDeny, PolicyEnforceWarn, PolicyEnforceOff)
+	}
+	if len(p.Rules) == 0 {
+		return errors.New("at least one rule is required")
+	}
+	count := 0
+	for i, rule := range p.Rules {
+		if err := rule.validate(&count); err != nil {
+			return fmt.Errorf("rules[%d]: %w", i, err)
+		}
+	}
+	if count > maxPolicyRules {
+		return fmt.Errorf("at most %d rules, conditions included", maxPolicyRules)
+	}
+	return nil
+}
+
+func (r PolicyRule) validate(count *int) error {
+	*count++
+	if !policyFieldPattern.MatchString(r.Field) {
+		return fmt.Errorf("invalid field %q", r.Field)
+	}
+	switch r.Op {
+	case PolicyOpRequired, PolicyOpForbidden:
+	case PolicyOpIn, PolicyOpNotIn:
+		if len(r.Values) == 0 {
+			return fmt.Errorf("%s needs values", r.Op)
+		}
+		for _, v := range r.Values {
+			if _, err := path.Match(v, ""); err != nil {
+				return fmt.Errorf("invalid value pattern %q", v)
+			}
+		}
+	case PolicyOpMatches, PolicyOpNotMatches:
+		if r.Pattern == "" {
+			return fmt.Errorf("%s needs a pattern", r.Op)
+		}
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	case PolicyOpMax, PolicyOpMin:
+		if r.Limit == nil {
+			return fmt.Errorf("%s needs a limit", r.Op)
+		}
+	default:
+		return fmt.Errorf("unknown op %q", r.Op)
+	}
+	for i, cond := range r.When {
+		if cond.Message != "" || len(cond.When) > 0 {
+			return fmt.Errorf("when[%d]: a condition can't have a message or conditions", i)
+		}
+		if err := cond.validate(count); err != nil {
+			return fmt.Errorf("when[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// AppliesTo reports whether the policy is evaluated for target in
+// projectID (empty for no project).
+func (p *AdmissionPolicy) AppliesTo(target, projectID string) bool {
+	if p.Target != target || p.Enforcement == PolicyEnforceOff {
+		return false
+	}
+	return p.ProjectID == nil || *p.ProjectID == projectID
+}
+
+// Evaluate returns the rules of the policy doc breaks. doc is the JSON
+// object form of what's checked.
+func (p *AdmissionPolicy) Evaluate(doc map[string]interface{}) []PolicyViolation {
+	var violations []PolicyViolation
+	for i, rule := range p.Rules {
+		if !rule.applies(doc) {
+			continue
+		}
+		message, ok := rule.check(doc)
+		if ok {
+			continue
+		}
+		if rule.Message != "" {
+			message = rule.Message
+		}
+		violations = append(violations, PolicyViolation{
+			PolicyID:    p.PolicyID,
+			Policy:      p.Name,
+			Enforcement: p.Enforcement,
+			Rule:        i,
+			Field:       rule.Field,
+			Message:     message,
+		})
+	}
+	return violations
+}
+
+// applies reports whether doc meets every condition of the rule. Unlike a
+// rule, a condition using in, matches, max or min doesn't hold for an empty
+// field: "when environment is in prod*" means the job has one.
+func (r PolicyRule) applies(doc map[string]interface{}) bool {
+	for _, cond := range r.When {
+		switch cond.Op {
+		case PolicyOpIn, PolicyOpMatches, PolicyOpMax, PolicyOpMin:
+			if len(policyFieldValues(doc, cond.Field)) == 0 {
+				return false
+			}
+		}
+		if _, ok := cond.check(doc); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// check reports whether doc passes the rule and, if not, why.
+func (r PolicyRule) check(doc map[string]interface{}) (string, bool) {
+	values := policyFieldValues(doc, r.Field)
+	switch r.Op {
+	case PolicyOpRequired:
+		return fmt.Sprintf("%s is required", r.Field), len(values) > 0
+	case PolicyOpForbidden:
+		return fmt.Sprintf("%s is not allowed", r.Field), len(values) == 0
+	case PolicyOpIn, PolicyOpNotIn:
+		for _, v := range values {
+			s := policyValueString(v)
+			if matchesAnyGlob(r.Values, s) != (r.Op == PolicyOpIn) {
+				if r.Op == PolicyOpIn {
+					return fmt.Sprintf("%s %q is not one of %s", r.Field, s, strings.Join(r.Values, ", ")), false
+				}
+				return fmt.Sprintf("%s %q is not allowed", r.Field, s), false
+			}
+		}
+	case PolicyOpMatches, PolicyOpNotMatches:
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return fmt.Sprintf("invalid pattern for %s", r.Field), false
+		}
+		for _, v := range values {
+			s := policyValueString(v)
+			if re.MatchString(s) != (r.Op == PolicyOpMatches) {
+				if r.Op == PolicyOpMatches {
+					return fmt.Sprintf("%s %q must match %s", r.Field, s, r.Pattern), false
+				}
+				return fmt.Sprintf("%s %q must not match %s", r.Field, s, r.Pattern), false
+			}
+		}
+	case PolicyOpMax, PolicyOpMin:
+		for _, v := range values {
+			n, ok := policyValueNumber(v)
+			if !ok {
+				return fmt.Sprintf("%s is not a number", r.Field), false
+			}
+			if r.Op == PolicyOpMax && n > *r.Limit {
+				return fmt.Sprintf("%s %s is over the maximum of %s", r.Field, formatPolicyNumber(n), formatPolicyNumber(*r.Limit)), false
+			}
+			if r.Op == PolicyOpMin && n < *r.Limit {
+				return fmt.Sprintf("%s %s is under the minimum of %s", r.Field, formatPolicyNumber(n), formatPolicyNumber(*r.Limit)), false
+			}
+		}
+	default:
+		return fmt.Sprintf("unknown op %q", r.Op), false
+	}
+	return "", true
+}
+
+// policyFieldValues returns the non-empty values of field in doc: one for
+// a scalar, a list's elements, an object's keys, none when it's missing,
+// null, "" or empty.
+func policyFieldValues(doc map[string]interface{}, field string) []interface{} {
+	name, key, nested := strings.Cut(field, ".")
+	value, ok := doc[name]
+	if nested {
+		object, isObject := value.(map[string]interface{})
+		if !isObject {
+			return nil
+		}
+		value, ok = object[key]
+	}
+	if !ok || value == nil {
+		return nil
+	}
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+	case []interface{}:
+		var values []interface{}
+		for _, item := range v {
+			if item != nil && item != "" {
+				values = append(values, item)
+			}
+		}
+		return values
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		values := make([]interface{}, len(keys))
+		for i, k := range keys {
+			values[i] = k
+		}
+		return values
+	}
+	return []interface{}{value}
+}
+
+func policyValueString(v interface{}) string {
+	switch s := v.(type) {
+	case string:
+		return s
+	case float64:
+		return formatPolicyNumber(s)
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+func policyValueNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func formatPolicyNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+func matchesAnyGlob(patterns []string, s string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, s); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdmissionPolicy_Validate(t *testing.T) {
+	limit := 60.0
+	valid := AdmissionPolicy{Name: "p", Target: PolicyTargetJob, Enforcement: PolicyEnforceDeny, Rules: PolicyRules{
+		{Field: "timeout_seconds", Op: PolicyOpMax, Limit: &limit, When: []PolicyRule{{Field: "environment", Op: PolicyOpIn, Values: []string{"production"}}}},
+	}}
+	require.NoError(t, valid.Validate())
+
+	cases := map[string]PolicyRule{
+		"unknown op":          {Field: "name", Op: "equals"},
+		"bad field":           {Field: "a b", Op: PolicyOpRequired},
+		"in without values":   {Field: "name", Op: PolicyOpIn},
+		"bad glob":            {Field: "name", Op: PolicyOpIn, Values: []string{"["}},
+		"bad regexp":          {Field: "name", Op: PolicyOpMatches, Pattern: "("},
+		"min without limit":   {Field: "priority", Op: PolicyOpMin},
+		"nested conditions":   {Field: "name", Op: PolicyOpRequired, When: []PolicyRule{{Field: "x", Op: PolicyOpRequired, When: []PolicyRule{{Field: "y", Op: PolicyOpRequired}}}}},
+		"condition a message": {Field: "name", Op: PolicyOpRequired, When: []PolicyRule{{Field: "x", Op: PolicyOpRequired, Message: "m"}}},
+	}
+	for name, rule := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := valid
+			p.Rules = PolicyRules{rule}
+			assert.Error(t, p.Validate())
+		})
+	}
+
+	p := valid
+	p.Target = "webhook"
+	assert.Error(t, p.Validate())
+	p = valid
+	p.Rules = nil
+	assert.Error(t, p.Validate())
+}
+
+func TestAdmissionPolicy_Evaluate(t *testing.T) {
+	limit := 3600.0
+	policy := AdmissionPolicy{PolicyID: "p1", Name: "org-rules", Target: PolicyTargetJob, Enforcement: PolicyEnforceDeny, Rules: PolicyRules{
+		{Field: "runner_image", Op: PolicyOpIn, Values: []string{"registry.example.com/*/*"}},
+		{Field: "job_env_vars.TEAM", Op: PolicyOpRequired, Message: "every job needs a TEAM"},
+		{Field: "timeout_seconds", Op: PolicyOpMax, Limit: &limit, When: []PolicyRule{{Field: "environment", Op: PolicyOpIn, Values: []string{"prod*"}}}},
+		{Field: "capabilities", Op: PolicyOpNotIn, Values: []string{"docker"}},
+		{Field: "job_env_vars", Op: PolicyOpNotMatches, Pattern: "^AWS_"},
+	}}
+
+	ok := map[string]interface{}{
+		"runner_image":    "registry.example.com/ci/runner:1",
+		"job_env_vars":    map[string]interface{}{"TEAM": "payments"},
+		"timeout_seconds": float64(7200),
+		"capabilities":    []interface{}{"network"},
+	}
+	assert.Empty(t, policy.Evaluate(ok), "the timeout rule only applies to production")
+
+	bad := map[string]interface{}{
+		"runner_image":    "docker.io/library/alpine",
+		"job_env_vars":    map[string]interface{}{"AWS_SECRET_ACCESS_KEY": "x"},
+		"environment":     "production",
+		"timeout_seconds": float64(7200),
+		"capabilities":    []interface{}{"network", "docker"},
+	}
+	violations := policy.Evaluate(bad)
+	require.Len(t, violations, 5)
+	assert.Equal(t, PolicyViolation{PolicyID: "p1", Policy: "org-rules", Enforcement: PolicyEnforceDeny, Rule: 0, Field: "runner_image",
+		Message: `runner_image "docker.io/library/alpine" is not one of registry.example.com/*/*`}, violations[0])
+	assert.Equal(t, "every job needs a TEAM", violations[1].Message)
+	assert.Equal(t, "timeout_seconds 7200 is over the maximum of 3600", violations[2].Message)
+	assert.Equal(t, `capabilities "docker" is not allowed`, violations[3].Message)
+	assert.Equal(t, `job_env_vars "AWS_SECRET_ACCESS_KEY" must not match ^AWS_`, violations[4].Message)
+}
+
+func TestAdmissionPolicy_AppliesTo(t *testing.T) {
+	project := "project-1"
+	p := AdmissionPolicy{Target: PolicyTargetJob, Enforcement: PolicyEnforceWarn}
+	assert.True(t, p.AppliesTo(PolicyTargetJob, ""))
+	assert.False(t, p.AppliesTo(PolicyTargetProject, ""))
+	p.ProjectID = &project
+	assert.True(t, p.AppliesTo(PolicyTargetJob, project))
+	assert.False(t, p.AppliesTo(PolicyTargetJob, "project-2"))
+	p.Enforcement = PolicyEnforceOff
+	assert.False(t, p.AppliesTo(PolicyTargetJob, project))
+}
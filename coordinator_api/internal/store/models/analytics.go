@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// JobRunStats summarizes the terminal runs of a set of jobs (a project, one
+// job name, or one time bucket). Durations are execution time
+// (started_at → completed_at) of successful runs; queue wait is
+// created_at → started_at of every run that started. Percentiles are nil
+// when no run qualified.
+type JobRunStats struct {
+	Runs           int      `json:"runs"`
+	Succeeded      int      `json:"succeeded"`
+	Failed         int      `json:"failed"`
+	FailureRate    float64  `json:"failure_rate"`
+	DurationP50Ms  *float64 `json:"duration_p50_ms"`
+	DurationP95Ms  *float64 `json:"duration_p95_ms"`
+	QueueWaitP50Ms *float64 `json:"queue_wait_p50_ms"`
+	QueueWaitP95Ms *float64 `json:"queue_wait_p95_ms"`
+}
+
+// JobNameStats is JobRunStats for every run of one job name.
+type JobNameStats struct {
+	JobName string `json:"job_name"`
+	JobRunStats
+}
+
+// AnalyticsBucket is JobRunStats for the runs that completed within one
+// trend bucket starting at Start.
+type AnalyticsBucket struct {
+	Start time.Time `json:"start"`
+	JobRunStats
+}
+
+// ProjectAnalytics is the historical run summary for one project over
+// [Since, Until), returned by GET /api/v1/projects/{id}/analytics.
+type ProjectAnalytics struct {
+	ProjectID string            `json:"project_id"`
+	Since     time.Time         `json:"since"`
+	Until     time.Time         `json:"until"`
+	Bucket    string            `json:"bucket"`
+	JobName   string            `json:"job_name,omitempty"`
+	Overall   JobRunStats       `json:"overall"`
+	Jobs      []JobNameStats    `json:"jobs"`
+	Trend     []AnalyticsBucket `json:"trend"`
+}
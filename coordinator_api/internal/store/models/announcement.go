@@ -0,0 +1,97 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Announcement severities, from least to most severe.
+const (
+	AnnouncementInfo     = "info"
+	AnnouncementWarning  = "warning"
+	AnnouncementCritical = "critical"
+)
+
+// MaxAnnouncementLength caps an announcement's message, which is sent in a
+// response header.
+const MaxAnnouncementLength = 500
+
+// Announcement is a message admins show every API client, e.g. of planned
+// maintenance, from StartsAt until EndsAt. A nil StartsAt shows it from
+// creation and a nil EndsAt until it's deleted.
+type Announcement struct {
+	AnnouncementID string     `gorm:"primaryKey;type:uuid;default:generate_ulid()" json:"announcement_id"`
+	Message        string     `gorm:"type:text;not null" json:"message"`
+	Severity       string     `gorm:"type:text;not null;default:'info'" json:"severity"`
+	StartsAt       *time.Time `json:"starts_at,omitempty"`
+	EndsAt         *time.Time `json:"ends_at,omitempty"`
+	CreatedBy      *string    `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime:false;default:timezone('utc', now())" json:"updated_at"`
+}
+
+// TableName specifies the table name for the model.
+func (Announcement) TableName() string {
+	return "announcements"
+}
+
+// Validate checks the announcement's message, severity and schedule.
+func (a *Announcement) Validate() error {
+	if strings.TrimSpace(a.Message) == "" {
+		return errors.New("message is required")
+	}
+	if len(a.Message) > MaxAnnouncementLength {
+		return fmt.Errorf("message is longer than %d bytes", MaxAnnouncementLength)
+	}
+	if strings.ContainsAny(a.Message, "\r\n") {
+		return errors.New("message must be a single line")
+	}
+	if announcementRank(a.Severity) < 0 {
+		return fmt.Errorf("severity must be %q, %q or %q", AnnouncementInfo, AnnouncementWarning, AnnouncementCritical)
+	}
+	if a.StartsAt != nil && a.EndsAt != nil && !a.StartsAt.Before(*a.EndsAt) {
+		return errors.New("starts_at must be before ends_at")
+	}
+	return nil
+}
+
+// ActiveAt reports whether the announcement is shown at now.
+func (a *Announcement) ActiveAt(now time.Time) bool {
+	if a.StartsAt != nil && now.Before(*a.StartsAt) {
+		return false
+	}
+	return a.EndsAt == nil || now.Before(*a.EndsAt)
+}
+
+// ActiveAnnouncements returns the announcements shown at now, most severe
+// first and, within a severity, newest first.
+func ActiveAnnouncements(announcements []Announcement, now time.Time) []Announcement {
+	active := []Announcement{}
+	for _, a := range announcements {
+		if a.ActiveAt(now) {
+			active = append(active, a)
+		}
+	}
+	sort.SliceStable(active, func(i, j int) bool {
+		if ri, rj := announcementRank(active[i].Severity), announcementRank(active[j].Severity); ri != rj {
+			return ri > rj
+		}
+		return active[i].CreatedAt.After(active[j].CreatedAt)
+	})
+	return active
+}
+
+func announcementRank(severity string) int {
+	switch severity {
+	case AnnouncementInfo:
+		return 0
+	case AnnouncementWarning:
+		return 1
+	case AnnouncementCritical:
+		return 2
+	}
+	return -1
+}
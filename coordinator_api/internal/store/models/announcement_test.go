@@ -0,0 +1,47 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnouncement_Validate(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+
+	assert.NoError(t, (&Announcement{Message: "Maintenance Saturday 02:00 UTC", Severity: AnnouncementWarning}).Validate())
+	assert.NoError(t, (&Announcement{Message: "ok", Severity: AnnouncementInfo, StartsAt: &now, EndsAt: &later}).Validate())
+
+	for name, a := range map[string]Announcement{
+		"empty message":     {Message: "  ", Severity: AnnouncementInfo},
+		"too long":          {Message: strings.Repeat("x", MaxAnnouncementLength+1), Severity: AnnouncementInfo},
+		"multi-line":        {Message: "one\ntwo", Severity: AnnouncementInfo},
+		"unknown severity":  {Message: "ok", Severity: "urgent"},
+		"ends before start": {Message: "ok", Severity: AnnouncementInfo, StartsAt: &later, EndsAt: &now},
+	} {
+		assert.Error(t, a.Validate(), name)
+	}
+}
+
+func TestActiveAnnouncements(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	got := ActiveAnnouncements([]Announcement{
+		{AnnouncementID: "old-info", Severity: AnnouncementInfo, CreatedAt: past},
+		{AnnouncementID: "new-info", Severity: AnnouncementInfo, CreatedAt: now},
+		{AnnouncementID: "critical", Severity: AnnouncementCritical, CreatedAt: past, EndsAt: &future},
+		{AnnouncementID: "scheduled", Severity: AnnouncementCritical, StartsAt: &future},
+		{AnnouncementID: "ended", Severity: AnnouncementCritical, EndsAt: &now},
+	}, now)
+
+	var ids []string
+	for _, a := range got {
+		ids = append(ids, a.AnnouncementID)
+	}
+	assert.Equal(t, []string{"critical", "new-info", "old-info"}, ids)
+	assert.NotNil(t, ActiveAnnouncements(nil, now), "an empty list, not null, in JSON")
+}
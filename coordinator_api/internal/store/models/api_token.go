@@ -2,6 +2,8 @@ package models
 
 import (
 	"time"
+
+	"github.com/lib/pq"
 )
 
 // APIToken represents an API token for authentication
@@ -16,6 +18,16 @@ type APIToken struct {
 	LastUsedAt *time.Time `json:"last_used_at"`
 	IsActive   bool       `gorm:"not null" json:"is_active"`
 
+	// Scopes the token carries, matched by token_scope secret access
+	// policies. A token with scopes only reaches the secrets its scopes
+	// are granted.
+	Scopes pq.StringArray `gorm:"type:text[];not null;default:'{}'" json:"scopes"`
+
+	// ProjectID binds the token to one project: a deploy token, which can
+	// only run that project's jobs and read them and their logs. See
+	// middleware.AuthMiddleware.
+	ProjectID *string `gorm:"type:uuid" json:"project_id,omitempty"`
+
 	// Relationships
 	User User `gorm:"foreignKey:UserID" json:"user,omitempty"`
 }
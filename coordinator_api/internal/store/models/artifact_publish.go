@@ -0,0 +1,235 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// Artifact publish target types. See ArtifactPublishTarget.
+const (
+	PublishTargetS3   = "s3"
+	PublishTargetHTTP = "http"
+	PublishTargetOCI  = "oci"
+)
+
+// MaxArtifactPublishTargets caps a project's ArtifactPublishTargets.
+const MaxArtifactPublishTargets = 10
+
+// ArtifactPublishTarget is an external destination a project's completed
+// jobs push their artifacts to, e.g. {"name": "dist", "type": "s3",
+// "destination": "s3://releases/{project}/{commit}/", "artifacts":
+// ["dist/*"]}. Destination depends on Type:
+//
+//   - s3: s3://bucket/prefix/; each artifact is put under the prefix by
+//     its path. Region and Endpoint (for S3-compatible storage) are
+//     optional.
+//   - http: an http(s) URL; each artifact is PUT to the URL with its path
+//     appended.
+//   - oci: a registry/repository:tag reference; the artifacts are pushed
+//     as the layers of one OCI artifact, named by their paths.
+//
+// Destination may use {project}, {job_id}, {job_name}, {commit},
+// {short_commit} and {ref}, filled in from the job. Artifacts and Jobs are
+// globs like ReleaseConfig.Artifacts and QualityGate.Jobs; empty matches
+// every artifact and job.
+//
+// Credentials are "path:key" references into the project owner's
+// secrets: UsernameSecret and PasswordSecret are an S3 access key ID and
+// secret key, HTTP basic auth or a registry login; TokenSecret is an HTTP
+// bearer token. Without them, S3 uses the worker's AWS credentials, HTTP
+// sends none and OCI uses the worker's registry credentials.
+type ArtifactPublishTarget struct {
+	Name           string   `json:"name"`
+	Type           string   `json:"type"`
+	Destination    string   `json:"destination"`
+	Artifacts      []string `json:"artifacts,omitempty"`
+	Jobs           []string `json:"jobs,omitempty"`
+	Region         string   `json:"region,omitempty"`
+	Endpoint       string   `json:"endpoint,omitempty"`
+	UsernameSecret string   `json:"username_secret,omitempty"`
+	PasswordSecret string   `json:"password_secret,omitempty"`
+	TokenSecret    string   `json:"token_secret,omitempty"`
+}
+
+// AppliesTo reports whether the target publishes the artifacts of jobs
+// named jobName.
+func (t *ArtifactPublishTarget) AppliesTo(jobName string) bool {
+	if len(t.Jobs) == 0 {
+		return true
+	}
+	for _, pattern := range t.Jobs {
+		if ok, _ := path.Match(pattern, jobName); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// IncludesArtifact reports whether the target publishes the artifact
+// named name (its path under the job's artifacts).
+func (t *ArtifactPublishTarget) IncludesArtifact(name string) bool {
+	return (&ReleaseConfig{Artifacts: t.Artifacts}).IncludesArtifact(name)
+}
+
+// ArtifactPublishTargets is a project's publish targets, stored in a
+// jsonb column.
+type ArtifactPublishTargets []ArtifactPublishTarget
+
+// Value implements driver.Valuer interface for database storage.
+func (t ArtifactPublishTargets) Value() (driver.Value, error) {
+	if t == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(t)
+}
+
+// Scan implements sql.Scanner interface for database retrieval.
+func (t *ArtifactPublishTargets) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into ArtifactPublishTargets", value)
+	}
+	return json.Unmarshal(data, t)
+}
+
+// Validate checks every target's name, type, destination, globs and
+// secret references. Target names must be unique.
+func (t ArtifactPublishTargets) Validate() error {
+	if len(t) > MaxArtifactPublishTargets {
+		return fmt.Errorf("artifact_publish_targets: at most %d targets are allowed", MaxArtifactPublishTargets)
+	}
+	seen := make(map[string]bool, len(t))
+	for i, target := range t {
+		fail := func(format string, args ...interface{}) error {
+			return fmt.Errorf("artifact_publish_targets[%d]: "+format, append([]interface{}{i}, args...)...)
+		}
+		if strings.TrimSpace(target.Name) == "" {
+			return fail("name is required")
+		}
+		if seen[target.Name] {
+			return fail("duplicate target %q", target.Name)
+		}
+		seen[target.Name] = true
+		if err := validatePublishDestination(target); err != nil {
+			return fail("%v", err)
+		}
+		for _, pattern := range append(append([]string{}, target.Artifacts...), target.Jobs...) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fail("invalid pattern %q", pattern)
+			}
+		}
+		if (target.UsernameSecret == "") != (target.PasswordSecret == "") {
+			return fail("username_secret and password_secret go together")
+		}
+		if target.TokenSecret != "" && (target.Type != PublishTargetHTTP || target.UsernameSecret != "") {
+			return fail("token_secret is only for http targets without username_secret")
+		}
+		for _, ref := range []string{target.UsernameSecret, target.PasswordSecret, target.TokenSecret} {
+			if ref == "" {
+				continue
+			}
+			if secretPath, key, ok := strings.Cut(ref, ":"); !ok || secretPath == "" || key == "" {
+				return fail("secret reference %q must be path:key", ref)
+			}
+		}
+	}
+	return nil
+}
+
+func validatePublishDestination(target ArtifactPublishTarget) error {
+	if target.Destination == "" {
+		return fmt.Errorf("destination is required")
+	}
+	switch target.Type {
+	case PublishTargetS3:
+		u, err := url.Parse(target.Destination)
+		if err != nil || u.Scheme != "s3" || u.Host == "" {
+			return fmt.Errorf("s3 destination must be s3://bucket/prefix")
+		}
+	case PublishTargetHTTP:
+		u, err := url.Parse(target.Destination)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("http destination must be an http or https URL")
+		}
+		if u.User != nil {
+			return fmt.Errorf("http destination must not contain credentials; use username_secret and password_secret")
+		}
+	case PublishTargetOCI:
+		if strings.Contains(target.Destination, "://") || strings.Contains(target.Destination, "@") {
+			return fmt.Errorf("oci destination must be registry/repository:tag")
+		}
+	default:
+		return fmt.Errorf("type must be s3, http or oci")
+	}
+	if target.Type != PublishTargetS3 && (target.Region != "" || target.Endpoint != "") {
+		return fmt.Errorf("region and endpoint are only for s3 targets")
+	}
+	return nil
+}
+
+// PublishedArtifact is where a job's artifact was published, or, with
+// Error, why it wasn't. For OCI targets, Digest is the artifact's layer
+// digest and Location the pushed manifest, by digest.
+type PublishedArtifact struct {
+	Target      string     `json:"target"`
+	Type        string     `json:"type"`
+	Artifact    string     `json:"artifact"`
+	Size        int64      `json:"size"`
+	Location    string     `json:"location,omitempty"`
+	Digest      string     `json:"digest,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+}
+
+// PublishedArtifacts is a job's published artifacts, stored in a jsonb
+// column.
+type PublishedArtifacts []PublishedArtifact
+
+// Value implements driver.Valuer interface for database storage.
+func (a PublishedArtifacts) Value() (driver.Value, error) {
+	if a == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(a)
+}
+
+// Scan implements sql.Scanner interface for database retrieval.
+func (a *PublishedArtifacts) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into PublishedArtifacts", value)
+	}
+	return json.Unmarshal(data, a)
+}
+
+// Failed returns the artifacts that weren't published.
+func (a PublishedArtifacts) Failed() PublishedArtifacts {
+	var failed PublishedArtifacts
+	for _, artifact := range a {
+		if artifact.Error != "" {
+			failed = append(failed, artifact)
+		}
+	}
+	return failed
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Canary verdicts: how a project's canary jobs compare with its other jobs
+// since the canary started.
+const (
+	// CanaryInsufficientData means either side has finished fewer than
+	// CanaryMinRuns jobs.
+	CanaryInsufficientData = "insufficient_data"
+	// CanaryHealthy means the canary jobs fail and take no more than the
+	// baseline's, within the tolerances.
+	CanaryHealthy = "healthy"
+	// CanaryRegressed means they fail or take more.
+	CanaryRegressed = "regressed"
+)
+
+// Canary comparison tolerances. The canary regresses if its failure rate
+// is more than CanaryFailureRateTolerance above the baseline's, or its
+// median duration (of successful runs) more than CanaryDurationTolerance
+// times the baseline's.
+const (
+	CanaryMinRuns              = 10
+	CanaryFailureRateTolerance = 0.05
+	CanaryDurationTolerance    = 1.25
+)
+
+// ProjectCanary routes Percent of a project's new jobs to a new runner
+// image, a canary worker queue, or both, so their failure rate and
+// duration can be compared with the project's other jobs before the image
+// or queue becomes the project's default. Jobs routed to it are marked
+// Job.Canary.
+type ProjectCanary struct {
+	Percent     int       `json:"percent"`
+	RunnerImage string    `json:"runner_image,omitempty"`
+	QueueName   string    `json:"queue_name,omitempty"`
+	StartedAt   time.Time `json:"started_at"`
+	StartedBy   *string   `json:"started_by,omitempty"`
+}
+
+// Validate checks the canary routes 1-100% of jobs somewhere other than
+// the project's defaults.
+func (c *ProjectCanary) Validate(project *Project) error {
+	if c.Percent < 1 || c.Percent > 100 {
+		return errors.New("percent must be between 1 and 100")
+	}
+	if c.RunnerImage == "" && c.QueueName == "" {
+		return errors.New("runner_image or queue_name is required")
+	}
+	if strings.ContainsAny(c.RunnerImage, " \t\r\n") {
+		return fmt.Errorf("invalid runner_image %q", c.RunnerImage)
+	}
+	if strings.ContainsAny(c.QueueName, " \t\r\n") {
+		return fmt.Errorf("invalid queue_name %q", c.QueueName)
+	}
+	if c.RunnerImage != "" && c.RunnerImage == project.DefaultRunnerImage {
+		return errors.New("runner_image is already the project's default")
+	}
+	if c.QueueName != "" && c.QueueName == project.DefaultQueueName {
+		return errors.New("queue_name is already the project's default")
+	}
+	return nil
+}
+
+// Value implements driver.Valuer interface for database storage.
+func (c ProjectCanary) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+// Scan implements sql.Scanner interface for database retrieval.
+func (c *ProjectCanary) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into ProjectCanary", value)
+	}
+	return json.Unmarshal(bytes, c)
+}
+
+// CanaryComparison compares a project's canary jobs with its baseline, the
+// project's other jobs, over the runs that finished since the canary
+// started.
+type CanaryComparison struct {
+	Canary   JobRunStats `json:"canary"`
+	Baseline JobRunStats `json:"baseline"`
+	Verdict  string      `json:"verdict"`
+	// Reasons say why the canary regressed.
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// NewCanaryComparison compares canary with baseline and gives a verdict.
+func NewCanaryComparison(canary, baseline JobRunStats) CanaryComparison {
+	c := CanaryComparison{Canary: canary, Baseline: baseline, Verdict: CanaryHealthy}
+	if canary.Runs < CanaryMinRuns || baseline.Runs < CanaryMinRuns {
+		c.Verdict = CanaryInsufficientData
+		return c
+	}
+	if canary.FailureRate > baseline.FailureRate+CanaryFailureRateTolerance {
+		c.Reasons = append(c.Reasons, fmt.Sprintf("failure rate %.1f%% vs %.1f%%", canary.FailureRate*100, baseline.FailureRate*100))
+	}
+	if canary.DurationP50Ms != nil && baseline.DurationP50Ms != nil &&
+		*canary.DurationP50Ms > *baseline.DurationP50Ms*CanaryDurationTolerance {
+		c.Reasons = append(c.Reasons, fmt.Sprintf("median duration %.1fs vs %.1fs", *canary.DurationP50Ms/1000, *baseline.DurationP50Ms/1000))
+	}
+	if len(c.Reasons) > 0 {
+		c.Verdict = CanaryRegressed
+	}
+	return c
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectCanaryValidate(t *testing.T) {
+	project := &Project{DefaultRunnerImage: "runner:1", DefaultQueueName: "reactorcide-jobs"}
+	assert.NoError(t, (&ProjectCanary{Percent: 10, RunnerImage: "runner:2"}).Validate(project))
+	assert.NoError(t, (&ProjectCanary{Percent: 100, QueueName: "canary"}).Validate(project))
+	assert.Error(t, (&ProjectCanary{Percent: 0, RunnerImage: "runner:2"}).Validate(project))
+	assert.Error(t, (&ProjectCanary{Percent: 101, RunnerImage: "runner:2"}).Validate(project))
+	assert.Error(t, (&ProjectCanary{Percent: 10}).Validate(project))
+	assert.Error(t, (&ProjectCanary{Percent: 10, RunnerImage: "runner 2"}).Validate(project))
+	assert.Error(t, (&ProjectCanary{Percent: 10, RunnerImage: "runner:1"}).Validate(project))
+	assert.Error(t, (&ProjectCanary{Percent: 10, QueueName: "reactorcide-jobs"}).Validate(project))
+}
+
+func TestNewCanaryComparison(t *testing.T) {
+	ms := func(v float64) *float64 { return &v }
+	baseline := JobRunStats{Runs: 100, FailureRate: 0.05, DurationP50Ms: ms(60000)}
+
+	c := NewCanaryComparison(JobRunStats{Runs: 5, FailureRate: 1}, baseline)
+	assert.Equal(t, CanaryInsufficientData, c.Verdict)
+
+	c = NewCanaryComparison(JobRunStats{Runs: 20, FailureRate: 0.08, DurationP50Ms: ms(70000)}, baseline)
+	assert.Equal(t, CanaryHealthy, c.Verdict)
+	assert.Empty(t, c.Reasons)
+
+	c = NewCanaryComparison(JobRunStats{Runs: 20, FailureRate: 0.2, DurationP50Ms: ms(90000)}, baseline)
+	assert.Equal(t, CanaryRegressed, c.Verdict)
+	assert.Len(t, c.Reasons, 2)
+}
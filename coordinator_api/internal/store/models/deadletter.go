@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// JobStatusDeadlettered is the status of a job a worker set aside after it
+// used up its delivery attempts. It waits, off Corndogs, for an admin to
+// requeue or fail it.
+const JobStatusDeadlettered = "deadlettered"
+
+// Reasons a worker's claim of a job ended without a result.
+const (
+	// DeliveryFailureWorkerLost: the worker stopped while running the job
+	// and found it still running when it restarted.
+	DeliveryFailureWorkerLost = "worker_lost"
+	// DeliveryFailureWorkerPanic: the worker panicked while processing the
+	// job.
+	DeliveryFailureWorkerPanic = "worker_panic"
+)
+
+// JobDeliveryFailure records one claim of a job that ended without a
+// result. Attempt is the job's DeliveryAttempts at the time.
+type JobDeliveryFailure struct {
+	FailureID string    `gorm:"primaryKey;type:uuid;default:generate_ulid()" json:"failure_id"`
+	JobID     string    `gorm:"type:uuid;not null" json:"job_id"`
+	Attempt   int       `gorm:"not null" json:"attempt"`
+	WorkerID  string    `gorm:"type:text;not null;default:''" json:"worker_id,omitempty"`
+	Reason    string    `gorm:"type:text;not null" json:"reason"`
+	Detail    string    `gorm:"type:text;not null;default:''" json:"detail,omitempty"`
+	FailedAt  time.Time `gorm:"autoCreateTime:false;default:now()" json:"failed_at"`
+}
+
+// TableName specifies the table name for the model.
+func (JobDeliveryFailure) TableName() string {
+	return "job_delivery_failures"
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// DeadlineStats counts jobs with deadlines: Met completed successfully by
+// theirs, Missed were found not completed by theirs, and Open are neither
+// yet.
+type DeadlineStats struct {
+	Total  int `json:"total"`
+	Met    int `json:"met"`
+	Missed int `json:"missed"`
+	Open   int `json:"open"`
+}
+
+// DeadlineJobNameStats is DeadlineStats for one job name.
+type DeadlineJobNameStats struct {
+	JobName string `json:"job_name"`
+	DeadlineStats
+}
+
+// DeadlineMiss is one job that missed its deadline.
+type DeadlineMiss struct {
+	JobID            string     `json:"job_id"`
+	Name             string     `json:"name"`
+	Status           string     `json:"status"`
+	Deadline         time.Time  `json:"deadline"`
+	DeadlineMissedAt time.Time  `json:"deadline_missed_at"`
+	CompletedAt      *time.Time `json:"completed_at,omitempty"`
+}
+
+// DeadlineReport is the deadline summary for one project's jobs with
+// deadlines in [Since, Until), returned by
+// GET /api/v1/projects/{id}/deadlines.
+type DeadlineReport struct {
+	ProjectID string                 `json:"project_id"`
+	Since     time.Time              `json:"since"`
+	Until     time.Time              `json:"until"`
+	Overall   DeadlineStats          `json:"overall"`
+	Jobs      []DeadlineJobNameStats `json:"jobs"`
+	// RecentMisses lists the latest misses, newest deadline first.
+	RecentMisses []DeadlineMiss `json:"recent_misses"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Debug session statuses. Only active sessions accept connections.
+const (
+	DebugSessionActive  = "active"
+	DebugSessionClosed  = "closed"
+	DebugSessionExpired = "expired"
+)
+
+// DebugSession is a failed job's environment kept alive by the worker that
+// ran it (see Job.DebugOnFailure). The coordinator bridges authenticated
+// terminal connections to the worker at BridgeURL, presenting BridgeToken.
+type DebugSession struct {
+	SessionID   string     `gorm:"primaryKey;type:uuid;default:generate_ulid()" json:"session_id"`
+	JobID       string     `gorm:"type:uuid;not null" json:"job_id"`
+	WorkerID    string     `gorm:"type:text;not null;default:''" json:"worker_id,omitempty"`
+	Status      string     `gorm:"type:text;not null" json:"status"`
+	BridgeURL   string     `gorm:"type:text;not null" json:"-"`
+	BridgeToken string     `gorm:"type:text;not null" json:"-"`
+	ExpiresAt   time.Time  `gorm:"not null" json:"expires_at"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"created_at"`
+	ClosedAt    *time.Time `json:"closed_at,omitempty"`
+}
+
+// TableName specifies the table name for the model.
+func (DebugSession) TableName() string {
+	return "debug_sessions"
+}
+
+// IsLive reports whether the session is active and not yet past its expiry.
+func (s *DebugSession) IsLive(now time.Time) bool {
+	return s.Status == DebugSessionActive && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Results of an upstream job a DownstreamTrigger fires on.
+const (
+	DownstreamOnSuccess = "success"
+	DownstreamOnFailure = "failure"
+	DownstreamOnAlways  = "always"
+)
+
+// MaxDownstreamDepth is how many cross-project hops a chain of downstream
+// triggers may take from the job that started it. It stops runaway chains
+// that cycle detection alone wouldn't, such as a long chain of distinct
+// projects.
+const MaxDownstreamDepth = 8
+
+// DownstreamTrigger runs another project when one of a project's jobs
+// finishes: an eval job for DownstreamProjectID at Ref, with the finished
+// job recorded as its Job.UpstreamJobID. It's how, say, a library's release
+// job rebuilds the services that depend on it.
+type DownstreamTrigger struct {
+	TriggerID           string    `gorm:"primaryKey;type:uuid;default:generate_ulid()" json:"trigger_id"`
+	CreatedAt           time.Time `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"created_at"`
+	UpdatedAt           time.Time `gorm:"autoUpdateTime:false;default:timezone('utc', now())" json:"updated_at"`
+	ProjectID           string    `gorm:"type:uuid;not null" json:"project_id"`
+	DownstreamProjectID string    `gorm:"type:uuid;not null" json:"downstream_project_id"`
+	// JobNameMatch and JobNamePattern pick the upstream jobs that fire the
+	// trigger, like a SecretGrant's job match except that "any" isn't
+	// allowed: every job of a pipeline finishing would fire it.
+	JobNameMatch   string `gorm:"type:text;not null;default:'exact'" json:"job_name_match"`
+	JobNamePattern string `gorm:"type:text;not null" json:"job_name_pattern"`
+	// On is DownstreamOnSuccess, DownstreamOnFailure or DownstreamOnAlways.
+	On string `gorm:"column:on_status;type:text;not null;default:'success'" json:"on"`
+	// Ref is the downstream branch to build.
+	Ref         string  `gorm:"type:text;not null;default:'main'" json:"ref"`
+	CreatedBy   *string `gorm:"type:uuid" json:"created_by,omitempty"`
+	Description string  `gorm:"type:text" json:"description,omitempty"`
+}
+
+func (DownstreamTrigger) TableName() string {
+	return "project_downstream_triggers"
+}
+
+// FiresOn reports whether a job that finished with status fires the
+// trigger. Cancelled jobs never do.
+func (t *DownstreamTrigger) FiresOn(status string) bool {
+	switch status {
+	case "completed":
+		return t.On == DownstreamOnSuccess || t.On == DownstreamOnAlways
+	case "failed":
+		return t.On == DownstreamOnFailure || t.On == DownstreamOnAlways
+	}
+	return false
+}
+
+// ValidateDownstreamOn reports whether on is a known DownstreamTrigger.On.
+func ValidateDownstreamOn(on string) error {
+	switch on {
+	case DownstreamOnSuccess, DownstreamOnFailure, DownstreamOnAlways:
+		return nil
+	}
+	return fmt.Errorf("invalid on %q: must be success, failure or always", on)
+}
+
+// DownstreamCycle reports whether adding an edge from projectID to
+// downstreamID would close a cycle in the triggers graph edges (upstream
+// project ID to its downstream project IDs): whether projectID is
+// reachable from downstreamID, or they're the same project.
+func DownstreamCycle(edges map[string][]string, projectID, downstreamID string) bool {
+	seen := map[string]bool{}
+	stack := []string{downstreamID}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if current == projectID {
+			return true
+		}
+		if seen[current] {
+			continue
+		}
+		seen[current] = true
+		stack = append(stack, edges[current]...)
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package models
+
+import "testing"
+
+func TestDownstreamTrigger_FiresOn(t *testing.T) {
+	tests := []struct {
+		on, status string
+		want       bool
+	}{
+		{DownstreamOnSuccess, "completed", true},
+		{DownstreamOnSuccess, "failed", false},
+		{DownstreamOnFailure, "failed", true},
+		{DownstreamOnFailure, "completed", false},
+		{DownstreamOnAlways, "completed", true},
+		{DownstreamOnAlways, "failed", true},
+		{DownstreamOnAlways, "cancelled", false},
+		{DownstreamOnAlways, "running", false},
+	}
+	for _, tt := range tests {
+		trigger := &DownstreamTrigger{On: tt.on}
+		if got := trigger.FiresOn(tt.status); got != tt.want {
+			t.Errorf("on %s FiresOn(%q) = %v, want %v", tt.on, tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestDownstreamCycle(t *testing.T) {
+	edges := map[string][]string{
+		"lib":  {"svc", "tool"},
+		"svc":  {"app"},
+		"tool": {"svc"},
+	}
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{"app", "lib", true},
+		{"tool", "app", false},
+		{"app", "svc", true},
+		{"svc", "lib", true},
+		{"app", "tool", true},
+		{"lib", "app", false},
+		{"lib", "lib", true},
+	}
+	for _, tt := range tests {
+		if got := DownstreamCycle(edges, tt.from, tt.to); got != tt.want {
+			t.Errorf("DownstreamCycle(%s -> %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
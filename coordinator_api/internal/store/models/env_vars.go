@@ -0,0 +1,176 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// EnvVars is a job's environment as accepted at the API. Values must be
+// JSON strings; numbers and booleans are coerced to their JSON text
+// ("3", "1.5", "true"). Objects, arrays and null are rejected with an
+// *EnvVarError, so a job can't be submitted with an environment the runner
+// would receive as a stringified structure.
+type EnvVars map[string]string
+
+// EnvVarError reports a job environment variable whose value isn't a
+// string, number or boolean.
+type EnvVarError struct {
+	Key  string
+	Kind string
+}
+
+func (e *EnvVarError) Error() string {
+	return fmt.Sprintf("environment variable %q must be a string, number or boolean, not %s", e.Key, e.Kind)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, applying the coercion rules.
+func (e *EnvVars) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*e = nil
+		return nil
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	vars := make(EnvVars, len(raw))
+	for key, value := range raw {
+		value = bytes.TrimSpace(value)
+		switch {
+		case len(value) > 0 && value[0] == '"':
+			var s string
+			if err := json.Unmarshal(value, &s); err != nil {
+				return err
+			}
+			vars[key] = s
+		case string(value) == "true" || string(value) == "false":
+			vars[key] = string(value)
+		case len(value) > 0 && (value[0] == '-' || (value[0] >= '0' && value[0] <= '9')):
+			vars[key] = string(value)
+		case string(value) == "null":
+			return &EnvVarError{Key: key, Kind: "null"}
+		case len(value) > 0 && value[0] == '[':
+			return &EnvVarError{Key: key, Kind: "an array"}
+		default:
+			return &EnvVarError{Key: key, Kind: "an object"}
+		}
+	}
+	*e = vars
+	return nil
+}
+
+// EnvValueString converts a JSONB environment value to the string the
+// runner receives: strings as is, booleans as "true"/"false", numbers in
+// plain decimal (1000000, not 1e+06), and objects and arrays as compact
+// JSON. It returns false for nil.
+func EnvValueString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), true
+	case int:
+		return strconv.Itoa(v), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case int32:
+		return strconv.FormatInt(int64(v), 10), true
+	case json.Number:
+		return v.String(), true
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", false
+		}
+		return string(data), true
+	}
+}
+
+// String returns j[key] if it is a string.
+func (j JSONB) String(key string) (string, bool) {
+	s, ok := j[key].(string)
+	return s, ok
+}
+
+// Bool returns j[key] if it is a boolean, or a string strconv.ParseBool
+// accepts.
+func (j JSONB) Bool(key string) (bool, bool) {
+	switch v := j[key].(type) {
+	case bool:
+		return v, true
+	case string:
+		b, err := strconv.ParseBool(v)
+		return b, err == nil
+	}
+	return false, false
+}
+
+// Int returns j[key] if it is a whole number, or a string holding one.
+// JSON numbers scan as float64, so 3.0 counts and 3.5 doesn't.
+func (j JSONB) Int(key string) (int, bool) {
+	switch v := j[key].(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		if v == float64(int(v)) {
+			return int(v), true
+		}
+	case json.Number:
+		n, err := v.Int64()
+		return int(n), err == nil
+	case string:
+		n, err := strconv.Atoi(v)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// Map returns j[key] if it is a JSON object.
+func (j JSONB) Map(key string) (map[string]interface{}, bool) {
+	switch v := j[key].(type) {
+	case map[string]interface{}:
+		return v, true
+	case JSONB:
+		return v, true
+	}
+	return nil, false
+}
+
+// EnvStrings converts j, a job environment, to the strings the runner
+// receives (see EnvValueString). Keys with nil values are left out.
+func (j JSONB) EnvStrings() map[string]string {
+	env := make(map[string]string, len(j))
+	for key, value := range j {
+		if s, ok := EnvValueString(value); ok {
+			env[key] = s
+		}
+	}
+	return env
+}
+
+// NonScalarKeys returns, sorted, the keys of j whose values aren't
+// strings, numbers or booleans: values that only reach the runner as
+// JSON text.
+func (j JSONB) NonScalarKeys() []string {
+	var keys []string
+	for key, value := range j {
+		switch value.(type) {
+		case string, bool, float64, float32, int, int64, int32, json.Number:
+		default:
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestEnvVars_UnmarshalJSON(t *testing.T) {
+	var vars EnvVars
+	if err := json.Unmarshal([]byte(`{"S":"x","N":3,"F":1.5,"B":true,"BIG":1000000}`), &vars); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	want := EnvVars{"S": "x", "N": "3", "F": "1.5", "B": "true", "BIG": "1000000"}
+	if !reflect.DeepEqual(vars, want) {
+		t.Errorf("got %v, want %v", vars, want)
+	}
+
+	for body, kind := range map[string]string{
+		`{"M":{"a":1}}`: "an object",
+		`{"M":[1,2]}`:   "an array",
+		`{"M":null}`:    "null",
+	} {
+		var vars EnvVars
+		err := json.Unmarshal([]byte(body), &vars)
+		var envErr *EnvVarError
+		if !errors.As(err, &envErr) || envErr.Key != "M" || envErr.Kind != kind {
+			t.Errorf("%s: expected EnvVarError for M (%s), got %v", body, kind, err)
+		}
+	}
+}
+
+func TestJSONB_EnvStrings(t *testing.T) {
+	var env JSONB
+	if err := json.Unmarshal([]byte(`{"S":"x","N":1000000,"F":0.25,"B":false,"M":{"a":"b"},"L":[1],"Z":null}`), &env); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	want := map[string]string{"S": "x", "N": "1000000", "F": "0.25", "B": "false", "M": `{"a":"b"}`, "L": "[1]"}
+	if got := env.EnvStrings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("EnvStrings() = %v, want %v", got, want)
+	}
+	if got := env.NonScalarKeys(); !reflect.DeepEqual(got, []string{"L", "M", "Z"}) {
+		t.Errorf("NonScalarKeys() = %v", got)
+	}
+}
+
+func TestJSONB_TypedAccessors(t *testing.T) {
+	j := JSONB{"s": "x", "b": true, "bs": "false", "i": float64(3), "f": 3.5, "is": "7", "m": map[string]interface{}{"k": "v"}}
+
+	if s, ok := j.String("s"); !ok || s != "x" {
+		t.Errorf("String(s) = %q, %v", s, ok)
+	}
+	if _, ok := j.String("i"); ok {
+		t.Error("String(i) should not accept a number")
+	}
+	if b, ok := j.Bool("b"); !ok || !b {
+		t.Errorf("Bool(b) = %v, %v", b, ok)
+	}
+	if b, ok := j.Bool("bs"); !ok || b {
+		t.Errorf("Bool(bs) = %v, %v", b, ok)
+	}
+	if n, ok := j.Int("i"); !ok || n != 3 {
+		t.Errorf("Int(i) = %d, %v", n, ok)
+	}
+	if _, ok := j.Int("f"); ok {
+		t.Error("Int(f) should reject 3.5")
+	}
+	if n, ok := j.Int("is"); !ok || n != 7 {
+		t.Errorf("Int(is) = %d, %v", n, ok)
+	}
+	if m, ok := j.Map("m"); !ok || m["k"] != "v" {
+		t.Errorf("Map(m) = %v, %v", m, ok)
+	}
+	if _, ok := j.String("missing"); ok {
+		t.Error("String(missing) should report false")
+	}
+	var nilJSONB JSONB
+	if _, ok := nilJSONB.String("s"); ok {
+		t.Error("nil JSONB should report false")
+	}
+}
@@ -0,0 +1,188 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// Freeze window modes. Either way deploy jobs created during the window are
+// held. Under "hold" they're released when the window ends; under
+// "approval" each needs an admin's override, even after the window ends.
+const (
+	FreezeModeHold     = "hold"
+	FreezeModeApproval = "approval"
+)
+
+// Manual freeze states. "on" freezes regardless of the schedule, "off"
+// lifts the freeze regardless of it; empty follows the schedule.
+const (
+	FreezeManualOn  = "on"
+	FreezeManualOff = "off"
+)
+
+// MaxFreezeDuration caps each scheduled occurrence of a freeze window.
+const MaxFreezeDuration = 7 * 24 * time.Hour
+
+var environmentNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,62}$`)
+
+// ValidateEnvironmentName checks the environment a deploy job targets.
+func ValidateEnvironmentName(name string) error {
+	if !environmentNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid environment %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	return nil
+}
+
+// FreezeWindow restricts deploys: while it's active, new jobs that target
+// an environment it covers are held. A nil ProjectID covers every project
+// and an empty Environment every environment. A window is active during
+// each occurrence of its schedule, Schedule (cron, in Timezone) starting
+// one and DurationMinutes ending it, unless Manual turns it on or off.
+type FreezeWindow struct {
+	WindowID        string    `gorm:"primaryKey;type:uuid;default:generate_ulid()" json:"window_id"`
+	ProjectID       *string   `gorm:"type:uuid" json:"project_id,omitempty"`
+	Environment     string    `gorm:"type:text;not null;default:''" json:"environment,omitempty"`
+	Name            string    `gorm:"type:text;not null" json:"name"`
+	Reason          string    `gorm:"type:text;not null;default:''" json:"reason,omitempty"`
+	Mode            string    `gorm:"type:text;not null;default:'hold'" json:"mode"`
+	Schedule        string    `gorm:"type:text;not null;default:''" json:"schedule,omitempty"`
+	DurationMinutes int       `gorm:"not null;default:0" json:"duration_minutes,omitempty"`
+	Timezone        string    `gorm:"type:text;not null;default:'UTC'" json:"timezone"`
+	Manual          string    `gorm:"type:text;not null;default:''" json:"manual,omitempty"`
+	CreatedBy       *string   `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt       time.Time `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"created_at"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime:false;default:timezone('utc', now())" json:"updated_at"`
+}
+
+// TableName specifies the table name for the model.
+func (FreezeWindow) TableName() string {
+	return "freeze_windows"
+}
+
+// Validate checks the window's settings.
+func (f *FreezeWindow) Validate() error {
+	if f.Name == "" {
+		return errors.New("name is required")
+	}
+	if f.Mode != FreezeModeHold && f.Mode != FreezeModeApproval {
+		return fmt.Errorf("mode must be %q or %q", FreezeModeHold, FreezeModeApproval)
+	}
+	if f.Manual != "" && f.Manual != FreezeManualOn && f.Manual != FreezeManualOff {
+		return fmt.Errorf("manual must be %q, %q or empty", FreezeManualOn, FreezeManualOff)
+	}
+	if f.Environment != "" {
+		if err := ValidateEnvironmentName(f.Environment); err != nil {
+			return err
+		}
+	}
+	if _, err := time.LoadLocation(f.Timezone); err != nil {
+		return fmt.Errorf("unknown timezone %q", f.Timezone)
+	}
+	if f.Schedule == "" {
+		if f.Manual == "" {
+			return errors.New("a window needs a schedule or a manual state")
+		}
+		return nil
+	}
+	if _, err := ParseCronSchedule(f.Schedule); err != nil {
+		return err
+	}
+	if d := f.duration(); d <= 0 || d > MaxFreezeDuration {
+		return fmt.Errorf("duration_minutes must be between 1 and %d", int(MaxFreezeDuration/time.Minute))
+	}
+	return nil
+}
+
+func (f *FreezeWindow) duration() time.Duration {
+	return time.Duration(f.DurationMinutes) * time.Minute
+}
+
+// Covers reports whether the window applies to job: a deploy job, one with
+// an environment, of the window's project and environment.
+func (f *FreezeWindow) Covers(job *Job) bool {
+	if job.Environment == "" {
+		return false
+	}
+	if f.ProjectID != nil && (job.ProjectID == nil || *job.ProjectID != *f.ProjectID) {
+		return false
+	}
+	return f.Environment == "" || f.Environment == job.Environment
+}
+
+// ActiveAt reports whether the window is in effect at t.
+func (f *FreezeWindow) ActiveAt(t time.Time) bool {
+	switch f.Manual {
+	case FreezeManualOn:
+		return true
+	case FreezeManualOff:
+		return false
+	}
+	return f.scheduledEnd(t) != nil
+}
+
+// ActiveUntil returns when the occurrence active at t ends, or nil if the
+// window isn't active at t or is on until it's turned off.
+func (f *FreezeWindow) ActiveUntil(t time.Time) *time.Time {
+	if f.Manual != "" {
+		return nil
+	}
+	return f.scheduledEnd(t)
+}
+
+// scheduledEnd returns the end of the latest occurrence of the schedule
+// that started in the DurationMinutes up to t, or nil if none did.
+func (f *FreezeWindow) scheduledEnd(t time.Time) *time.Time {
+	if f.Schedule == "" || f.DurationMinutes <= 0 {
+		return nil
+	}
+	schedule, err := ParseCronSchedule(f.Schedule)
+	if err != nil {
+		return nil
+	}
+	loc, err := time.LoadLocation(f.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	minute := t.In(loc).Truncate(time.Minute)
+	for i := 0; i < f.DurationMinutes; i++ {
+		start := minute.Add(-time.Duration(i) * time.Minute)
+		if schedule.Matches(start) {
+			end := start.Add(f.duration()).UTC()
+			return &end
+		}
+	}
+	return nil
+}
+
+// MatchFreeze returns the window holding job at t, or nil. An approval
+// window wins over a hold window, since it's the stricter of the two.
+func MatchFreeze(windows []FreezeWindow, job *Job, t time.Time) *FreezeWindow {
+	var match *FreezeWindow
+	for i := range windows {
+		w := &windows[i]
+		if !w.Covers(job) || !w.ActiveAt(t) {
+			continue
+		}
+		if match == nil || (w.Mode == FreezeModeApproval && match.Mode != FreezeModeApproval) {
+			match = w
+		}
+	}
+	return match
+}
+
+// FreezeOverride records an admin releasing a job a freeze window held.
+type FreezeOverride struct {
+	OverrideID string    `gorm:"primaryKey;type:uuid;default:generate_ulid()" json:"override_id"`
+	JobID      string    `gorm:"type:uuid;not null" json:"job_id"`
+	WindowID   *string   `gorm:"type:uuid" json:"window_id,omitempty"`
+	UserID     *string   `gorm:"type:uuid" json:"user_id,omitempty"`
+	Reason     string    `gorm:"type:text;not null" json:"reason"`
+	CreatedAt  time.Time `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"created_at"`
+}
+
+// TableName specifies the table name for the model.
+func (FreezeOverride) TableName() string {
+	return "freeze_overrides"
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCronSchedule(t *testing.T) {
+	// Fridays from 18:00, and the first of each month at 00:00.
+	friday, err := ParseCronSchedule("0 18 * * 5")
+	require.NoError(t, err)
+	assert.True(t, friday.Matches(time.Date(2026, 6, 5, 18, 0, 0, 0, time.UTC)))
+	assert.False(t, friday.Matches(time.Date(2026, 6, 5, 18, 1, 0, 0, time.UTC)))
+	assert.False(t, friday.Matches(time.Date(2026, 6, 6, 18, 0, 0, 0, time.UTC)))
+
+	steps, err := ParseCronSchedule("*/15 9-17 * * 1-5")
+	require.NoError(t, err)
+	assert.True(t, steps.Matches(time.Date(2026, 6, 1, 9, 45, 0, 0, time.UTC)))
+	assert.False(t, steps.Matches(time.Date(2026, 6, 1, 9, 50, 0, 0, time.UTC)))
+	assert.False(t, steps.Matches(time.Date(2026, 6, 7, 9, 45, 0, 0, time.UTC)), "a Sunday")
+
+	// Both day fields restricted: either matches. Sunday is 0 or 7.
+	days, err := ParseCronSchedule("0 0 1 * 7")
+	require.NoError(t, err)
+	assert.True(t, days.Matches(time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, days.Matches(time.Date(2026, 6, 7, 0, 0, 0, 0, time.UTC)))
+	assert.False(t, days.Matches(time.Date(2026, 6, 2, 0, 0, 0, 0, time.UTC)))
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * * * 8", "*/0 * * * *", "a * * * *", "5-1 * * * *"} {
+		_, err := ParseCronSchedule(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestFreezeWindow(t *testing.T) {
+	projectID := "project-1"
+	// Weekends: Friday 18:00 New York time for 60 hours.
+	w := FreezeWindow{Name: "weekend", Mode: FreezeModeHold, Schedule: "0 18 * * 5", DurationMinutes: 60 * 60, Timezone: "America/New_York", ProjectID: &projectID, Environment: "production"}
+	require.NoError(t, w.Validate())
+
+	ny, _ := time.LoadLocation("America/New_York")
+	assert.False(t, w.ActiveAt(time.Date(2026, 6, 5, 17, 59, 0, 0, ny)))
+	assert.True(t, w.ActiveAt(time.Date(2026, 6, 5, 18, 0, 0, 0, ny)))
+	assert.True(t, w.ActiveAt(time.Date(2026, 6, 8, 5, 59, 0, 0, ny)))
+	assert.False(t, w.ActiveAt(time.Date(2026, 6, 8, 6, 0, 0, 0, ny)))
+	until := w.ActiveUntil(time.Date(2026, 6, 6, 12, 0, 0, 0, ny))
+	require.NotNil(t, until)
+	assert.True(t, until.Equal(time.Date(2026, 6, 8, 6, 0, 0, 0, ny)))
+
+	monday := time.Date(2026, 6, 8, 12, 0, 0, 0, ny)
+	w.Manual = FreezeManualOn
+	assert.True(t, w.ActiveAt(monday))
+	assert.Nil(t, w.ActiveUntil(monday))
+	w.Manual = FreezeManualOff
+	assert.False(t, w.ActiveAt(time.Date(2026, 6, 6, 12, 0, 0, 0, ny)))
+
+	other := "project-2"
+	assert.True(t, w.Covers(&Job{ProjectID: &projectID, Environment: "production"}))
+	assert.False(t, w.Covers(&Job{ProjectID: &projectID}))
+	assert.False(t, w.Covers(&Job{ProjectID: &projectID, Environment: "staging"}))
+	assert.False(t, w.Covers(&Job{ProjectID: &other, Environment: "production"}))
+
+	for _, bad := range []FreezeWindow{
+		{Mode: FreezeModeHold, Manual: FreezeManualOn, Timezone: "UTC"},
+		{Name: "x", Mode: "block", Manual: FreezeManualOn, Timezone: "UTC"},
+		{Name: "x", Mode: FreezeModeHold, Timezone: "UTC"},
+		{Name: "x", Mode: FreezeModeHold, Schedule: "0 18 * * 5", Timezone: "UTC"},
+		{Name: "x", Mode: FreezeModeHold, Manual: FreezeManualOn, Timezone: "Mars/Olympus"},
+	} {
+		assert.Error(t, bad.Validate(), bad)
+	}
+}
+
+func TestMatchFreeze(t *testing.T) {
+	job := &Job{Environment: "production"}
+	windows := []FreezeWindow{
+		{WindowID: "hold", Mode: FreezeModeHold, Manual: FreezeManualOn},
+		{WindowID: "approval", Mode: FreezeModeApproval, Manual: FreezeManualOn},
+		{WindowID: "off", Mode: FreezeModeApproval, Manual: FreezeManualOff},
+	}
+	match := MatchFreeze(windows, job, time.Now())
+	require.NotNil(t, match)
+	assert.Equal(t, "approval", match.WindowID)
+	assert.Nil(t, MatchFreeze(windows[2:], job, time.Now()))
+}
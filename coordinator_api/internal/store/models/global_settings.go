@@ -10,6 +10,8 @@ import (
 // Well-known global_settings keys.
 const (
 	GlobalSettingNewProjectsPrivate = "new_projects_private"
+	GlobalSettingSourceURLPolicy    = "source_url_policy"
+	GlobalSettingRunnerImagePrePull = "runner_image_prepull"
 )
 
 // JSONValue is a raw JSON value stored in a jsonb column. Unlike JSONB (which
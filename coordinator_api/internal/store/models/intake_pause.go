@@ -0,0 +1,82 @@
+package models
+
+import "time"
+
+// Intake pause scopes. A global pause has an empty Target; a queue pause
+// targets a queue name and a project pause a project ID.
+const (
+	IntakeScopeGlobal  = "global"
+	IntakeScopeQueue   = "queue"
+	IntakeScopeProject = "project"
+)
+
+// JobStatusHeld is the status of a job recorded while an intake pause
+// covered it. Held jobs were never submitted to Corndogs.
+const JobStatusHeld = "held"
+
+// IntakePause stops new jobs in its scope from being submitted to Corndogs,
+// e.g. for a Postgres maintenance window. Banner, when set, is shown to
+// API clients while the pause is active.
+type IntakePause struct {
+	Scope    string    `gorm:"primaryKey;type:text" json:"scope"`
+	Target   string    `gorm:"primaryKey;type:text" json:"target"`
+	Reason   string    `gorm:"type:text;not null;default:''" json:"reason,omitempty"`
+	Banner   string    `gorm:"type:text;not null;default:''" json:"banner,omitempty"`
+	PausedBy *string   `gorm:"type:uuid" json:"paused_by,omitempty"`
+	PausedAt time.Time `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"paused_at"`
+}
+
+// TableName specifies the table name for the model.
+func (IntakePause) TableName() string {
+	return "intake_pauses"
+}
+
+// IsValidIntakeScope reports whether scope is a known pause scope.
+func IsValidIntakeScope(scope string) bool {
+	switch scope {
+	case IntakeScopeGlobal, IntakeScopeQueue, IntakeScopeProject:
+		return true
+	}
+	return false
+}
+
+// Covers reports whether the pause applies to job.
+func (p IntakePause) Covers(job *Job) bool {
+	switch p.Scope {
+	case IntakeScopeGlobal:
+		return true
+	case IntakeScopeQueue:
+		return job.QueueName == p.Target
+	case IntakeScopeProject:
+		return job.ProjectID != nil && *job.ProjectID == p.Target
+	}
+	return false
+}
+
+// MatchIntakePause returns the first pause covering job, or nil.
+func MatchIntakePause(pauses []IntakePause, job *Job) *IntakePause {
+	for i := range pauses {
+		if pauses[i].Covers(job) {
+			return &pauses[i]
+		}
+	}
+	return nil
+}
+
+// IntakeBanner returns the banner to show clients for pauses: the global
+// pause's banner if it has one, otherwise the first non-empty banner.
+func IntakeBanner(pauses []IntakePause) string {
+	banner := ""
+	for _, p := range pauses {
+		if p.Banner == "" {
+			continue
+		}
+		if p.Scope == IntakeScopeGlobal {
+			return p.Banner
+		}
+		if banner == "" {
+			banner = p.Banner
+		}
+	}
+	return banner
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/lib/pq"
+	"gorm.io/gorm"
 )
 
 // JSONB represents a JSON field that can be stored in PostgreSQL JSONB column
@@ -45,8 +46,12 @@ type Job struct {
 	JobID     string    `gorm:"primaryKey;type:uuid;default:generate_ulid()" json:"job_id"`
 	CreatedAt time.Time `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"created_at"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime:false;default:timezone('utc', now())" json:"updated_at"`
-	UserID    string    `gorm:"type:uuid;not null" json:"user_id"`
-	ProjectID *string   `gorm:"type:uuid" json:"project_id"`
+	// DeletedAt is when the job was moved to the trash. Queries through
+	// the model leave out deleted jobs; see
+	// postgres_store/trash_operations.go.
+	DeletedAt gorm.DeletedAt `json:"-"`
+	UserID    string         `gorm:"type:uuid;not null" json:"user_id"`
+	ProjectID *string        `gorm:"type:uuid" json:"project_id"`
 
 	// Job metadata
 	Name        string `gorm:"type:text;not null" json:"name"`
@@ -82,6 +87,35 @@ type Job struct {
 	Capabilities   pq.StringArray `gorm:"type:text[]" json:"capabilities"`
 	RunAsUser      string         `gorm:"type:text" json:"run_as_user"`
 
+	// TargetOS and TargetArch pin the job to workers of one platform (e.g.
+	// "windows"/"amd64"). Empty means the default linux/amd64 workers. See
+	// worker.Platform.
+	TargetOS   string `gorm:"type:text;not null;default:''" json:"target_os,omitempty"`
+	TargetArch string `gorm:"type:text;not null;default:''" json:"target_arch,omitempty"`
+
+	// Affinity and AvoidWorkers are scheduling hints workers honor when
+	// they dequeue the job: Affinity "project" prefers a worker that
+	// recently ran the same project, AvoidWorkers lists worker IDs (or
+	// globs) the job must not run on. See worker.AffinityProject.
+	Affinity     string         `gorm:"type:text;not null;default:''" json:"affinity,omitempty"`
+	AvoidWorkers pq.StringArray `gorm:"type:text[];not null;default:'{}'" json:"avoid_workers,omitempty"`
+
+	// Environment names what a deploy job deploys to. Freeze windows hold
+	// deploy jobs; FreezeWindowID is the window holding a "held" one. See
+	// FreezeWindow.
+	Environment    string  `gorm:"type:text;not null;default:''" json:"environment,omitempty"`
+	FreezeWindowID *string `gorm:"type:uuid" json:"freeze_window_id,omitempty"`
+
+	// SharedWorkspace names a workspace the job shares with other jobs of
+	// its pipeline, SharedWorkspaceScope (the ID of the job whose triggers
+	// started the pipeline). See worker.SharedWorkspaces.
+	SharedWorkspace      string `gorm:"type:text;not null;default:''" json:"shared_workspace,omitempty"`
+	SharedWorkspaceScope string `gorm:"type:text;not null;default:''" json:"shared_workspace_scope,omitempty"`
+
+	// Cache names a cache the job keeps between runs, shared with the
+	// other jobs of its project that name it. See worker.JobCaches.
+	Cache string `gorm:"type:text;not null;default:''" json:"cache,omitempty"`
+
 	// Queue integration
 	QueueName       string `gorm:"type:text;not null;default:'reactorcide-jobs'" json:"queue_name"`
 	AutoTargetState string `gorm:"type:text;default:'running'" json:"auto_target_state"`
@@ -110,6 +144,25 @@ type Job struct {
 	// this Go-level enum is documentation-only, same caveat as Status above.
 	CancelMode string `gorm:"type:text;check:cancel_mode IN ('cancel', 'kill')" json:"cancel_mode,omitempty"`
 
+	// Deadline, if set, is when the job must have completed successfully.
+	// As it approaches, the deadline monitor raises the priority of the
+	// job while it's queued; DeadlineMissedAt is when the monitor found it
+	// not completed by then. See jobcontrol.DeadlineMonitor.
+	Deadline         *time.Time `json:"deadline,omitempty"`
+	DeadlineMissedAt *time.Time `json:"deadline_missed_at,omitempty"`
+
+	// BoostedBy is the user who last raised the job's priority while it
+	// was queued, at BoostedAt. See jobcontrol.BoostJob.
+	BoostedBy *string    `gorm:"type:text" json:"boosted_by,omitempty"`
+	BoostedAt *time.Time `json:"boosted_at,omitempty"`
+
+	// DeliveryAttempts counts the workers' claims of the job. A job whose
+	// claims keep ending without a result is moved to JobStatusDeadlettered,
+	// at DeadletteredAt, instead of being claimed again. See
+	// JobDeliveryFailure.
+	DeliveryAttempts int        `gorm:"not null;default:0" json:"delivery_attempts,omitempty"`
+	DeadletteredAt   *time.Time `json:"deadlettered_at,omitempty"`
+
 	// Execution metadata
 	StartedAt   *time.Time `json:"started_at"`
 	CompletedAt *time.Time `json:"completed_at"`
@@ -118,10 +171,19 @@ type Job struct {
 	Notes       string     `gorm:"type:text" json:"notes"`
 	RetryCount  int        `gorm:"default:0" json:"retry_count"`
 	LastError   string     `gorm:"type:text" json:"last_error"`
+	// LastLogAt is when the worker last shipped a log chunk for this job;
+	// the stuck-job monitor treats a long silence as a hang.
+	LastLogAt *time.Time `json:"last_log_at,omitempty"`
 
 	// Object store references
 	LogsObjectKey      string `gorm:"type:text" json:"logs_object_key"`
 	ArtifactsObjectKey string `gorm:"type:text" json:"artifacts_object_key"`
+	// LogsTier is where the job's logs are in their lifecycle (see
+	// LogsTierHot etc.); empty until the log lifecycle manager first sees
+	// the finished job. LogsBytes is their size then.
+	LogsTier     string     `gorm:"type:text;not null;default:''" json:"logs_tier,omitempty"`
+	LogsBytes    int64      `gorm:"not null;default:0" json:"logs_bytes,omitempty"`
+	LogsTieredAt *time.Time `json:"logs_tiered_at,omitempty"`
 
 	// Event metadata for webhook-triggered jobs
 	EventMetadata    JSONB   `gorm:"type:jsonb" json:"event_metadata"`
@@ -131,12 +193,78 @@ type Job struct {
 	WorkflowRunID    *string `gorm:"type:uuid" json:"workflow_run_id"`
 	WorkflowNodeName string  `gorm:"type:text" json:"workflow_node_name"`
 
+	// TriggerValidation holds the structured result of the most recent
+	// rejected triggers document this job emitted: {"schema_version",
+	// "strict", "errors": [{"path", "message"}]}. NULL when the job never
+	// emitted triggers or its last submission was accepted. See
+	// worker.TriggerValidationRecord.
+	TriggerValidation JSONB `gorm:"type:jsonb" json:"trigger_validation,omitempty"`
+
+	// GateResults is how the job's reported outputs fared against its
+	// project's quality gates, set when a job whose command exited 0 is
+	// checked. A failed gate fails the job. See QualityGate.
+	GateResults GateResults `gorm:"type:jsonb" json:"gate_results,omitempty"`
+
+	// PublishedArtifacts is where the worker pushed the job's artifacts
+	// after it completed, per its project's ArtifactPublishTargets.
+	PublishedArtifacts PublishedArtifacts `gorm:"type:jsonb;not null;default:'[]'" json:"published_artifacts,omitempty"`
+
+	// Resources is what the job's container consumed, when the worker that
+	// ran it measures resource metrics. See JobResources.
+	Resources *JobResources `gorm:"type:jsonb" json:"resources,omitempty"`
+
+	// Scan makes the job a built-in vulnerability scan job; its findings
+	// are stored in scan_findings and checked against its project's
+	// ScanThresholds. See ScanSpec.
+	Scan *ScanSpec `gorm:"type:jsonb" json:"scan,omitempty"`
+
+	// CoalescedSHAs are the commits of the earlier pushes to the branch
+	// whose unfinished pipelines this push eval job's push cancelled. See
+	// Project.CoalesceWindowSeconds.
+	CoalescedSHAs pq.StringArray `gorm:"type:text[]" json:"coalesced_shas,omitempty"`
+
+	// QueueRouted is set on the jobs of projects with spillover queues:
+	// they're submitted to QueueName's Corndogs queue, chosen by
+	// occupancy, rather than to the queue of whatever submits them. See
+	// Project.SpilloverQueues.
+	QueueRouted bool `gorm:"not null;default:false" json:"queue_routed,omitempty"`
+
+	// Canary is set on the jobs routed to their project's canary runner
+	// image or queue. See Project.Canary.
+	Canary bool `gorm:"not null;default:false" json:"canary,omitempty"`
+
+	// AwaitChildren keeps the job "running" after its own execution
+	// finishes until every job it spawned (ParentJobID == this job) is
+	// terminal, then lands on the children's aggregate result. See
+	// IsAwaitingChildren and worker/child_rollup.go.
+	AwaitChildren bool `gorm:"not null;default:false" json:"await_children"`
+
+	// UpstreamJobID is the job in another project whose completion started
+	// this one, through a DownstreamTrigger. The upstream job's downstream
+	// jobs are the jobs naming it here.
+	UpstreamJobID *string `gorm:"type:uuid" json:"upstream_job_id,omitempty"`
+
+	// DebugOnFailure asks the worker to keep the job's environment alive
+	// for a bounded time if it fails, for an interactive debug session.
+	// See DebugSession.
+	DebugOnFailure bool `gorm:"not null;default:false" json:"debug_on_failure"`
+
+	// SecretScopes are the scopes of the API token the job was submitted
+	// with, empty for unscoped tokens and sessions. The job's secrets are
+	// resolved under its user's access policies as that token would see
+	// them (see secrets.AccessPolicy), and jobs derived from it keep them.
+	SecretScopes pq.StringArray `gorm:"type:text[];not null;default:'{}'" json:"secret_scopes,omitempty"`
+
 	// Denormalized VCS metadata for fast lookup by (repo, pr, commit).
 	// Populated at job-creation time from Notes JSON; Notes remains authoritative.
 	VCSRepo   *string `gorm:"type:text" json:"vcs_repo,omitempty"`
 	PRNumber  *int    `gorm:"type:integer" json:"pr_number,omitempty"`
 	CommitSHA *string `gorm:"type:text" json:"commit_sha,omitempty"`
 
+	// Archived is set on jobs read from jobs_archive, where finished jobs
+	// move after REACTORCIDE_JOB_ARCHIVE_AFTER_DAYS. Never written.
+	Archived bool `gorm:"->" json:"archived,omitempty"`
+
 	// Relationships
 	User      User     `gorm:"foreignKey:UserID" json:"user,omitempty"`
 	Project   *Project `gorm:"foreignKey:ProjectID" json:"project,omitempty"`
@@ -170,12 +298,12 @@ func (j *Job) IsCompleted() bool {
 }
 
 // CanBeCancelled returns true if the job can be moved into the cancel flow.
-// Submitted/queued jobs haven't started a container yet, so cancellation is
-// immediate (handled entirely by the API layer). Running jobs transition to
+// Held/submitted/queued/deadlettered jobs haven't started a container yet, so
+// cancellation is immediate (handled entirely by the API layer). Running jobs transition to
 // "cancelling" so the worker can drive a graceful stop. Jobs already
 // cancelling, or in any terminal state, cannot be cancelled again.
 func (j *Job) CanBeCancelled() bool {
-	return j.Status == "submitted" || j.Status == "queued" || j.Status == "running"
+	return j.Status == JobStatusHeld || j.Status == JobStatusDeadlettered || j.Status == "submitted" || j.Status == "queued" || j.Status == "running"
 }
 
 // CanBeKilled returns true if the job can be moved into (or escalated
@@ -197,6 +325,14 @@ func (j *Job) IsKillRequested() bool {
 	return j.CancelMode == "kill"
 }
 
+// IsAwaitingChildren returns true if the job's own execution has finished
+// (ExitCode is set) but it is being held in "running" until its child jobs
+// reach a terminal status. No worker owns a job in this state, so worker
+// recovery must leave it alone.
+func (j *Job) IsAwaitingChildren() bool {
+	return j.AwaitChildren && j.Status == "running" && j.ExitCode != nil
+}
+
 // IsRetryable returns true if the job may be retried: status is "failed",
 // "cancelled", or "timeout" — every terminal-but-unsuccessful status. This is
 // deliberately narrower than IsCompleted (which also admits "completed") — a
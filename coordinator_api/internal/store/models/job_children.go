@@ -0,0 +1,64 @@
+package models
+
+// Aggregate statuses reported for a job's children by AggregateChildJobs.
+const (
+	ChildStatusNone      = "none"
+	ChildStatusRunning   = "running"
+	ChildStatusSucceeded = "succeeded"
+	ChildStatusFailed    = "failed"
+)
+
+// ChildJobsSummary rolls up the statuses of the jobs spawned by one parent
+// job (every job whose ParentJobID is the parent).
+type ChildJobsSummary struct {
+	Status    string `json:"status"`
+	Total     int    `json:"total"`
+	Running   int    `json:"running"`
+	Succeeded int    `json:"succeeded"`
+	Failed    int    `json:"failed"`
+}
+
+// AggregateChildJobs computes the roll-up for a parent's children. The
+// aggregate is "running" while any child is non-terminal, "failed" once all
+// are terminal and at least one did not complete successfully (failed,
+// cancelled, or timed out), "succeeded" when every child completed, and
+// "none" when there are no children. Failures don't short-circuit while
+// siblings are still running: a parent held on its children only finishes
+// once nothing it spawned is still using a worker.
+func AggregateChildJobs(children []Job) ChildJobsSummary {
+	counts := map[string]int{}
+	for i := range children {
+		counts[children[i].Status]++
+	}
+	return AggregateChildJobCounts(counts)
+}
+
+// AggregateChildJobCounts is AggregateChildJobs over a count of children by
+// status, for fan-outs too wide to load every child.
+func AggregateChildJobCounts(counts map[string]int) ChildJobsSummary {
+	var summary ChildJobsSummary
+	for status, n := range counts {
+		job := Job{Status: status}
+		summary.Total += n
+		switch {
+		case !job.IsCompleted():
+			summary.Running += n
+		case status == "completed":
+			summary.Succeeded += n
+		default:
+			summary.Failed += n
+		}
+	}
+
+	switch {
+	case summary.Total == 0:
+		summary.Status = ChildStatusNone
+	case summary.Running > 0:
+		summary.Status = ChildStatusRunning
+	case summary.Failed > 0:
+		summary.Status = ChildStatusFailed
+	default:
+		summary.Status = ChildStatusSucceeded
+	}
+	return summary
+}
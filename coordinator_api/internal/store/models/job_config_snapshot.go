@@ -0,0 +1,120 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ResolvedConfigMasked replaces the values of secret environment variables
+// in a ResolvedJobConfig.
+const ResolvedConfigMasked = "[REDACTED]"
+
+// JobConfigSnapshot is the configuration a job ran with, fully resolved,
+// recorded by the worker when it starts the job's container. It is written
+// once and never changed, so it answers "what exactly ran" after the
+// project's configuration has moved on. No foreign key to jobs, so it
+// survives archiving.
+type JobConfigSnapshot struct {
+	JobID     string            `gorm:"primaryKey;type:uuid" json:"job_id"`
+	Config    ResolvedJobConfig `gorm:"type:jsonb;not null" json:"config"`
+	CreatedAt time.Time         `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"created_at"`
+}
+
+// TableName specifies the table name for the model.
+func (JobConfigSnapshot) TableName() string {
+	return "job_config_snapshots"
+}
+
+// ResolvedJobConfig is a job's configuration after the worker merged its
+// project's defaults, template, trigger spec and env file into it. Env is
+// the environment before secret resolution: ${secret:...} references stay
+// references, and variables holding secret values (the worker's API token,
+// secrets an env file rendered) are ResolvedConfigMasked.
+type ResolvedJobConfig struct {
+	JobName     string `json:"job_name"`
+	Image       string `json:"image"`
+	ImageDigest string `json:"image_digest,omitempty"`
+	// Command is the job's command as the container runs it.
+	Command    []string          `json:"command"`
+	WorkingDir string            `json:"working_dir"`
+	Env        map[string]string `json:"env"`
+
+	Source   *ResolvedJobSource `json:"source,omitempty"`
+	CISource *ResolvedJobSource `json:"ci_source,omitempty"`
+
+	TimeoutSeconds int      `json:"timeout_seconds,omitempty"`
+	Priority       int      `json:"priority"`
+	QueueName      string   `json:"queue_name"`
+	Capabilities   []string `json:"capabilities,omitempty"`
+	RunAsUser      string   `json:"run_as_user,omitempty"`
+	TargetOS       string   `json:"target_os,omitempty"`
+	TargetArch     string   `json:"target_arch,omitempty"`
+	Environment    string   `json:"environment,omitempty"`
+	Canary         bool     `json:"canary,omitempty"`
+	WorkerID       string   `json:"worker_id,omitempty"`
+
+	// Project is the job's project's defaults when the job ran, Template
+	// the project job template the job was made from, if any.
+	Project  *ResolvedProjectDefaults `json:"project,omitempty"`
+	Template *JobTemplate             `json:"template,omitempty"`
+	Trigger  *ResolvedJobTrigger      `json:"trigger,omitempty"`
+}
+
+// ResolvedJobSource is where a job's source, or CI source, came from.
+type ResolvedJobSource struct {
+	Type string `json:"type"`
+	URL  string `json:"url,omitempty"`
+	Ref  string `json:"ref,omitempty"`
+	Path string `json:"path,omitempty"`
+}
+
+// ResolvedProjectDefaults is the part of a project's configuration that
+// fills in its jobs.
+type ResolvedProjectDefaults struct {
+	ProjectID             string         `json:"project_id"`
+	Name                  string         `json:"name"`
+	GroupID               *string        `json:"group_id,omitempty"`
+	DefaultRunnerImage    string         `json:"default_runner_image"`
+	DefaultJobCommand     string         `json:"default_job_command,omitempty"`
+	DefaultTimeoutSeconds int            `json:"default_timeout_seconds"`
+	DefaultQueueName      string         `json:"default_queue_name"`
+	DefaultCISourceType   SourceType     `json:"default_ci_source_type,omitempty"`
+	DefaultCISourceURL    string         `json:"default_ci_source_url,omitempty"`
+	DefaultCISourceRef    string         `json:"default_ci_source_ref,omitempty"`
+	Canary                *ProjectCanary `json:"canary,omitempty"`
+}
+
+// ResolvedJobTrigger is what started a job: the event, and the job,
+// upstream job or workflow node that created it.
+type ResolvedJobTrigger struct {
+	EventMetadata    JSONB   `json:"event_metadata,omitempty"`
+	ParentJobID      *string `json:"parent_job_id,omitempty"`
+	UpstreamJobID    *string `json:"upstream_job_id,omitempty"`
+	WorkflowID       *string `json:"workflow_id,omitempty"`
+	WorkflowRunID    *string `json:"workflow_run_id,omitempty"`
+	WorkflowNodeName string  `json:"workflow_node_name,omitempty"`
+}
+
+// Value implements driver.Valuer interface for database storage.
+func (c ResolvedJobConfig) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+// Scan implements sql.Scanner interface for database retrieval.
+func (c *ResolvedJobConfig) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		*c = ResolvedJobConfig{}
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into ResolvedJobConfig", value)
+	}
+	return json.Unmarshal(bytes, c)
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// JobResources is what a job's container consumed, measured by the worker
+// while the job ran. It is recorded when the worker has resource metrics
+// enabled and its runner can be sampled; stored in a jsonb column.
+type JobResources struct {
+	// CPUSeconds is the container's total CPU time, across all CPUs.
+	CPUSeconds float64 `json:"cpu_seconds"`
+	// PeakMemoryBytes is the highest memory use seen in any sample. Usage
+	// that peaks between samples (an OOM kill, say) may not be seen.
+	PeakMemoryBytes uint64 `json:"peak_memory_bytes"`
+	// NetworkRxBytes and NetworkTxBytes are the bytes the container
+	// received and sent on all its interfaces.
+	NetworkRxBytes uint64 `json:"network_rx_bytes"`
+	NetworkTxBytes uint64 `json:"network_tx_bytes"`
+	// Samples is how many samples the figures come from.
+	Samples int `json:"samples"`
+}
+
+// Value implements driver.Valuer interface for database storage.
+func (r JobResources) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+// Scan implements sql.Scanner interface for database retrieval.
+func (r *JobResources) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into JobResources", value)
+	}
+	return json.Unmarshal(bytes, r)
+}
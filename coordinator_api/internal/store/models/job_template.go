@@ -0,0 +1,116 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// MaxJobTemplates caps a project's JobTemplates.
+const MaxJobTemplates = 50
+
+// JobTemplate is a job a project runs for its VCS events without an eval
+// job, e.g. {"name": "test", "command": "make test", "events": ["push",
+// "pull_request_opened", "pull_request_updated"]}. The job checks out the
+// event's commit, like an eval job would, and gets the same REACTORCIDE_*
+// event variables. Events limits the template to those generic event
+// types; a template without Events runs for every event the project
+// allows. Image and TimeoutSeconds default to the project's.
+type JobTemplate struct {
+	Name string `json:"name"`
+	// StatusKey names the job's commit status in place of Name; see
+	// Project.StatusContext.
+	StatusKey      string            `json:"status_key,omitempty"`
+	Events         []string          `json:"events,omitempty"`
+	Image          string            `json:"image,omitempty"`
+	Command        string            `json:"command"`
+	Env            map[string]string `json:"env,omitempty"`
+	TimeoutSeconds int               `json:"timeout_seconds,omitempty"`
+	Priority       *int              `json:"priority,omitempty"`
+	TargetOS       string            `json:"target_os,omitempty"`
+	TargetArch     string            `json:"target_arch,omitempty"`
+}
+
+// RunsFor reports whether the template runs for eventType.
+func (t *JobTemplate) RunsFor(eventType string) bool {
+	if len(t.Events) == 0 {
+		return true
+	}
+	for _, event := range t.Events {
+		if event == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// JobTemplates is a project's job templates, stored in a jsonb column. A
+// project with templates runs them in place of an eval job.
+type JobTemplates []JobTemplate
+
+// Value implements driver.Valuer interface for database storage.
+func (t JobTemplates) Value() (driver.Value, error) {
+	if t == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(t)
+}
+
+// Scan implements sql.Scanner interface for database retrieval.
+func (t *JobTemplates) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into JobTemplates", value)
+	}
+	return json.Unmarshal(bytes, t)
+}
+
+// For returns the templates that run for eventType.
+func (t JobTemplates) For(eventType string) JobTemplates {
+	var matched JobTemplates
+	for _, template := range t {
+		if template.RunsFor(eventType) {
+			matched = append(matched, template)
+		}
+	}
+	return matched
+}
+
+// Validate checks every template's name, command and timeout. Names must
+// be unique.
+func (t JobTemplates) Validate() error {
+	if len(t) > MaxJobTemplates {
+		return fmt.Errorf("job_templates: at most %d templates", MaxJobTemplates)
+	}
+	seen := make(map[string]bool, len(t))
+	for i, template := range t {
+		if strings.TrimSpace(template.Name) == "" {
+			return fmt.Errorf("job_templates[%d]: name is required", i)
+		}
+		if seen[template.Name] {
+			return fmt.Errorf("job_templates[%d]: duplicate template %q", i, template.Name)
+		}
+		seen[template.Name] = true
+		if strings.TrimSpace(template.Command) == "" {
+			return fmt.Errorf("job_templates[%d]: command is required", i)
+		}
+		if template.TimeoutSeconds < 0 {
+			return fmt.Errorf("job_templates[%d]: timeout_seconds must not be negative", i)
+		}
+		for _, event := range template.Events {
+			if strings.TrimSpace(event) == "" {
+				return fmt.Errorf("job_templates[%d]: empty event type", i)
+			}
+		}
+	}
+	return nil
+}
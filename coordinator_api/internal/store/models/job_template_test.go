@@ -0,0 +1,34 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobTemplates_Validate(t *testing.T) {
+	assert.NoError(t, JobTemplates{
+		{Name: "test", Command: "make test"},
+		{Name: "deploy", Command: "make deploy", Events: []string{"push"}, TimeoutSeconds: 600},
+	}.Validate())
+
+	for name, templates := range map[string]JobTemplates{
+		"no name":        {{Command: "make"}},
+		"no command":     {{Name: "test"}},
+		"duplicate":      {{Name: "a", Command: "x"}, {Name: "a", Command: "y"}},
+		"negative limit": {{Name: "a", Command: "x", TimeoutSeconds: -1}},
+		"empty event":    {{Name: "a", Command: "x", Events: []string{""}}},
+	} {
+		assert.Error(t, templates.Validate(), name)
+	}
+}
+
+func TestJobTemplates_For(t *testing.T) {
+	templates := JobTemplates{
+		{Name: "test", Command: "make test"},
+		{Name: "deploy", Command: "make deploy", Events: []string{"push", "tag_created"}},
+	}
+	assert.Len(t, templates.For("pull_request_opened"), 1)
+	assert.Len(t, templates.For("push"), 2)
+	assert.Equal(t, "deploy", templates.For("tag_created")[1].Name)
+}
@@ -17,6 +17,7 @@ func TestJob_StatusHelpers(t *testing.T) {
 	}{
 		{status: "submitted", wantCanBeCancelled: true},
 		{status: "queued", wantCanBeCancelled: true},
+		{status: "deadlettered", wantCanBeCancelled: true},
 		{status: "running", wantRunning: true, wantCanBeCancelled: true},
 		{status: "cancelling", wantCancelling: true},
 		{status: "completed", wantCompleted: true},
@@ -132,3 +133,42 @@ func TestJob_IsKillRequested(t *testing.T) {
 		t.Error("expected IsKillRequested() to be false for empty CancelMode")
 	}
 }
+
+func TestAggregateChildJobs(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []string
+		want     ChildJobsSummary
+	}{
+		{name: "no children", want: ChildJobsSummary{Status: ChildStatusNone}},
+		{name: "all succeeded", statuses: []string{"completed", "completed"}, want: ChildJobsSummary{Status: ChildStatusSucceeded, Total: 2, Succeeded: 2}},
+		{name: "failure waits for running siblings", statuses: []string{"failed", "running", "queued"}, want: ChildJobsSummary{Status: ChildStatusRunning, Total: 3, Running: 2, Failed: 1}},
+		{name: "cancelling is still running", statuses: []string{"completed", "cancelling"}, want: ChildJobsSummary{Status: ChildStatusRunning, Total: 2, Running: 1, Succeeded: 1}},
+		{name: "any unsuccessful terminal fails", statuses: []string{"completed", "timeout", "cancelled"}, want: ChildJobsSummary{Status: ChildStatusFailed, Total: 3, Succeeded: 1, Failed: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			children := make([]Job, len(tt.statuses))
+			for i, status := range tt.statuses {
+				children[i].Status = status
+			}
+			if got := AggregateChildJobs(children); got != tt.want {
+				t.Errorf("AggregateChildJobs() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJob_IsAwaitingChildren(t *testing.T) {
+	exitCode := 0
+	if !(&Job{AwaitChildren: true, Status: "running", ExitCode: &exitCode}).IsAwaitingChildren() {
+		t.Error("expected a finished, held job to be awaiting children")
+	}
+	if (&Job{AwaitChildren: true, Status: "running"}).IsAwaitingChildren() {
+		t.Error("expected a still-executing job not to be awaiting children")
+	}
+	if (&Job{Status: "running", ExitCode: &exitCode}).IsAwaitingChildren() {
+		t.Error("expected a job without AwaitChildren not to be awaiting children")
+	}
+}
@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// bytesPerGB is the divisor for pricing StorageBytes at a per-GB rate.
+const bytesPerGB = 1 << 30
+
+// JobUsage is the resource consumption recorded for one finished job and
+// its cost at the rates in effect when it finished. OrgID is the job's
+// owning user (orgs are users until a separate org model exists).
+type JobUsage struct {
+	JobID              string    `gorm:"primaryKey;type:uuid" json:"job_id"`
+	ProjectID          *string   `gorm:"type:uuid" json:"project_id,omitempty"`
+	OrgID              string    `gorm:"type:uuid;not null" json:"org_id"`
+	CompletedAt        time.Time `gorm:"not null" json:"completed_at"`
+	CPUSeconds         float64   `gorm:"column:cpu_seconds;not null" json:"cpu_seconds"`
+	MemoryGBSeconds    float64   `gorm:"column:memory_gb_seconds;not null" json:"memory_gb_seconds"`
+	StorageBytes       int64     `gorm:"not null" json:"storage_bytes"`
+	Estimated          bool      `gorm:"not null;default:false" json:"estimated"`
+	CPUSecondRate      float64   `gorm:"column:cpu_second_rate;not null" json:"cpu_second_rate"`
+	MemoryGBSecondRate float64   `gorm:"column:memory_gb_second_rate;not null" json:"memory_gb_second_rate"`
+	StorageGBRate      float64   `gorm:"column:storage_gb_rate;not null" json:"storage_gb_rate"`
+	Cost               float64   `gorm:"not null" json:"cost"`
+	Currency           string    `gorm:"type:text;not null" json:"currency"`
+	CreatedAt          time.Time `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"created_at"`
+}
+
+// TableName specifies the table name for the model.
+func (JobUsage) TableName() string {
+	return "job_usage"
+}
+
+// CostRates are the per-unit prices applied to job usage.
+type CostRates struct {
+	CPUSecond      float64
+	MemoryGBSecond float64
+	StorageGB      float64
+	Currency       string
+}
+
+// Apply copies the rates onto u and prices its usage.
+func (r CostRates) Apply(u *JobUsage) {
+	u.CPUSecondRate = r.CPUSecond
+	u.MemoryGBSecondRate = r.MemoryGBSecond
+	u.StorageGBRate = r.StorageGB
+	u.Currency = r.Currency
+	u.Cost = u.CPUSeconds*r.CPUSecond +
+		u.MemoryGBSeconds*r.MemoryGBSecond +
+		float64(u.StorageBytes)/bytesPerGB*r.StorageGB
+}
+
+// CostRollup is the summed usage and cost of the jobs that finished in one
+// calendar month (UTC), grouped by project or org. Grouping by project
+// sets ProjectID (nil for jobs outside any project); grouping by org sets
+// OrgID.
+type CostRollup struct {
+	Month           time.Time `json:"month"`
+	ProjectID       *string   `json:"project_id,omitempty"`
+	OrgID           string    `json:"org_id,omitempty"`
+	Jobs            int       `json:"jobs"`
+	CPUSeconds      float64   `json:"cpu_seconds"`
+	MemoryGBSeconds float64   `json:"memory_gb_seconds"`
+	StorageBytes    int64     `json:"storage_bytes"`
+	Cost            float64   `json:"cost"`
+	Currency        string    `json:"currency"`
+}
@@ -0,0 +1,19 @@
+package models
+
+// Log lifecycle tiers (Job.LogsTier). Finished jobs' logs start hot, move to
+// cold storage after the configured number of days, and are deleted after
+// a longer one. See jobcontrol.LogLifecycleManager.
+const (
+	LogsTierHot     = "hot"
+	LogsTierCold    = "cold"
+	LogsTierDeleted = "deleted"
+)
+
+// ProjectStorageUsage is the log storage one project's jobs use, per tier.
+// ProjectID is nil for jobs outside any project.
+type ProjectStorageUsage struct {
+	ProjectID *string `json:"project_id"`
+	Jobs      int     `json:"jobs"`
+	HotBytes  int64   `json:"hot_bytes"`
+	ColdBytes int64   `json:"cold_bytes"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Merge queue entry statuses. Queued and testing entries are active; a
+// project has at most one testing entry at a time.
+const (
+	MergeQueueQueued    = "queued"
+	MergeQueueTesting   = "testing"
+	MergeQueueMerged    = "merged"
+	MergeQueueFailed    = "failed"
+	MergeQueueCancelled = "cancelled"
+)
+
+// MergeQueueEntry is a pull request waiting in a project's native merge
+// queue. Entries are tested one at a time, in order, against the provider's
+// speculative merge of the PR into its base (TestedSHA), and merged only if
+// the eval job and every job it triggered succeed.
+type MergeQueueEntry struct {
+	EntryID   string  `gorm:"primaryKey;type:uuid;default:generate_ulid()" json:"entry_id"`
+	ProjectID string  `gorm:"type:uuid;not null" json:"project_id"`
+	Provider  string  `gorm:"type:text;not null" json:"provider"`
+	PRNumber  int     `gorm:"not null" json:"pr_number"`
+	Status    string  `gorm:"type:text;not null" json:"status"`
+	HeadSHA   string  `gorm:"type:text;not null;default:''" json:"head_sha,omitempty"`
+	TestedSHA string  `gorm:"type:text;not null;default:''" json:"tested_sha,omitempty"`
+	JobID     *string `gorm:"type:uuid" json:"job_id,omitempty"`
+	Error     string  `gorm:"type:text;not null;default:''" json:"error,omitempty"`
+	// LookupFailures counts consecutive failures to load the PR from its
+	// provider; see handlers.mergeQueueMaxLookupFailures.
+	LookupFailures int        `gorm:"not null;default:0" json:"lookup_failures,omitempty"`
+	EnqueuedBy     *string    `gorm:"type:uuid" json:"enqueued_by,omitempty"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime:false;default:timezone('utc', now())" json:"updated_at"`
+	FinishedAt     *time.Time `json:"finished_at,omitempty"`
+}
+
+// TableName specifies the table name for the model.
+func (MergeQueueEntry) TableName() string {
+	return "merge_queue_entries"
+}
+
+// IsActive reports whether the entry is still waiting or being tested.
+func (e *MergeQueueEntry) IsActive() bool {
+	return e.Status == MergeQueueQueued || e.Status == MergeQueueTesting
+}
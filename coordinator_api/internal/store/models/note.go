@@ -0,0 +1,61 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Note targets: what a note is on.
+const (
+	NoteTargetJob     = "job"
+	NoteTargetProject = "project"
+)
+
+// MaxNoteLength caps a note's markdown body.
+const MaxNoteLength = 10000
+
+// Note is a markdown annotation on a job or a project, e.g. on-call's
+// "known infra outage, ignore failures". Exactly one of JobID and ProjectID
+// is set.
+type Note struct {
+	NoteID    string    `gorm:"primaryKey;type:uuid;default:generate_ulid()" json:"note_id"`
+	JobID     *string   `gorm:"type:uuid" json:"job_id,omitempty"`
+	ProjectID *string   `gorm:"type:uuid" json:"project_id,omitempty"`
+	AuthorID  *string   `gorm:"type:uuid" json:"author_id,omitempty"`
+	Body      string    `gorm:"type:text;not null" json:"body"`
+	CreatedAt time.Time `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime:false;default:timezone('utc', now())" json:"updated_at"`
+}
+
+// TableName specifies the table name for the model.
+func (Note) TableName() string {
+	return "notes"
+}
+
+// Target returns what the note is on: NoteTargetJob or NoteTargetProject,
+// and its ID.
+func (n *Note) Target() (string, string) {
+	if n.JobID != nil {
+		return NoteTargetJob, *n.JobID
+	}
+	if n.ProjectID != nil {
+		return NoteTargetProject, *n.ProjectID
+	}
+	return "", ""
+}
+
+// Validate checks the note's body and target.
+func (n *Note) Validate() error {
+	if strings.TrimSpace(n.Body) == "" {
+		return errors.New("body is required")
+	}
+	if len(n.Body) > MaxNoteLength {
+		return fmt.Errorf("body is longer than %d bytes", MaxNoteLength)
+	}
+	if (n.JobID == nil) == (n.ProjectID == nil) {
+		return errors.New("a note is on exactly one job or project")
+	}
+	return nil
+}
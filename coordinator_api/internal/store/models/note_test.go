@@ -0,0 +1,33 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNote_Validate(t *testing.T) {
+	jobID, projectID := "job-1", "project-1"
+
+	assert.NoError(t, (&Note{JobID: &jobID, Body: "Known infra outage, **ignore failures**"}).Validate())
+	assert.NoError(t, (&Note{ProjectID: &projectID, Body: "ok"}).Validate())
+
+	for name, n := range map[string]Note{
+		"empty body":  {JobID: &jobID, Body: " \n"},
+		"too long":    {JobID: &jobID, Body: strings.Repeat("x", MaxNoteLength+1)},
+		"no target":   {Body: "ok"},
+		"two targets": {JobID: &jobID, ProjectID: &projectID, Body: "ok"},
+	} {
+		assert.Error(t, n.Validate(), name)
+	}
+}
+
+func TestNote_Target(t *testing.T) {
+	jobID, projectID := "job-1", "project-1"
+
+	kind, id := (&Note{JobID: &jobID}).Target()
+	assert.Equal(t, []string{NoteTargetJob, jobID}, []string{kind, id})
+	kind, id = (&Note{ProjectID: &projectID}).Target()
+	assert.Equal(t, []string{NoteTargetProject, projectID}, []string{kind, id})
+}
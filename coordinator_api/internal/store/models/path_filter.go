@@ -0,0 +1,112 @@
+package models
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Path filters select the changed files that trigger a project
+// (Project.PathFilters). Each pattern is a slash-separated glob matched
+// segment by segment, as runnerlib matches a job definition's paths:
+//
+//	services/api/**   everything under services/api
+//	*.md              a Markdown file at the root
+//	**/*.proto        a .proto file anywhere
+//
+// "*" matches within one segment and "**" matches any number of segments.
+// A leading "!" excludes matching files. A change is selected when at
+// least one changed file matches a positive pattern (or the list has only
+// negative ones) and no negative pattern.
+
+// ValidatePathFilters reports the first malformed pattern in patterns.
+func ValidatePathFilters(patterns []string) error {
+	for _, p := range patterns {
+		body := strings.TrimPrefix(p, "!")
+		// Commas separate the patterns passed to the eval job.
+		if body == "" || strings.HasPrefix(body, "/") || strings.Contains(body, ",") {
+			return fmt.Errorf("invalid path filter %q", p)
+		}
+		for _, segment := range strings.Split(body, "/") {
+			if _, err := path.Match(segment, ""); err != nil {
+				return fmt.Errorf("invalid path filter %q: %w", p, err)
+			}
+		}
+	}
+	return nil
+}
+
+// MatchPathFilters reports whether any of files is selected by patterns.
+// An empty list selects every change, even one with no files.
+func MatchPathFilters(patterns []string, files []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	var include, exclude []string
+	for _, p := range patterns {
+		if strings.HasPrefix(p, "!") {
+			exclude = append(exclude, strings.TrimPrefix(p, "!"))
+		} else {
+			include = append(include, p)
+		}
+	}
+	for _, file := range files {
+		if len(include) > 0 && !matchAnyPath(include, file) {
+			continue
+		}
+		if matchAnyPath(exclude, file) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// ValidateConfigPath cleans a project's config path: a relative directory
+// inside the repository, without "..". The root is "".
+func ValidateConfigPath(configPath string) (string, error) {
+	if configPath == "" {
+		return "", nil
+	}
+	if strings.HasPrefix(configPath, "/") {
+		return "", fmt.Errorf("config path %q must be relative", configPath)
+	}
+	cleaned := path.Clean(configPath)
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("config path %q leaves the repository", configPath)
+	}
+	if cleaned == "." {
+		return "", nil
+	}
+	return cleaned, nil
+}
+
+func matchAnyPath(patterns []string, file string) bool {
+	for _, p := range patterns {
+		if matchPathSegments(strings.Split(p, "/"), strings.Split(file, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPathSegments matches a pattern against a path one segment at a
+// time, with "**" matching zero or more segments.
+func matchPathSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		if matchPathSegments(pattern[1:], name) {
+			return true
+		}
+		return len(name) > 0 && matchPathSegments(pattern, name[1:])
+	}
+	if len(name) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], name[0]); !ok {
+		return false
+	}
+	return matchPathSegments(pattern[1:], name[1:])
+}
@@ -0,0 +1,65 @@
+package models
+
+import "testing"
+
+func TestMatchPathFilters(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		files    []string
+		want     bool
+	}{
+		{"empty list allows all", nil, nil, true},
+		{"subtree", []string{"services/api/**"}, []string{"services/api/cmd/main.go"}, true},
+		{"other subtree", []string{"services/api/**"}, []string{"services/web/app.ts"}, false},
+		{"any file matches", []string{"services/api/**"}, []string{"README.md", "services/api/go.mod"}, true},
+		{"star stays in a segment", []string{"*.md"}, []string{"docs/guide.md"}, false},
+		{"double star anywhere", []string{"**/*.proto"}, []string{"proto/v1/api.proto"}, true},
+		{"double star matches zero segments", []string{"**/*.proto"}, []string{"api.proto"}, true},
+		{"negative excludes", []string{"services/api/**", "!services/api/docs/**"}, []string{"services/api/docs/a.md"}, false},
+		{"negative only", []string{"!*.md"}, []string{"main.go"}, true},
+		{"negative only excludes", []string{"!*.md"}, []string{"README.md"}, false},
+		{"no files", []string{"services/api/**"}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchPathFilters(tt.patterns, tt.files); got != tt.want {
+				t.Errorf("MatchPathFilters(%q, %q) = %v, want %v", tt.patterns, tt.files, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatePathFilters(t *testing.T) {
+	valid := []string{"services/api/**", "!**/*.md", "go.mod"}
+	if err := ValidatePathFilters(valid); err != nil {
+		t.Errorf("ValidatePathFilters(%q) = %v, want nil", valid, err)
+	}
+	for _, p := range []string{"", "!", "/abs/**", "a,b", "src/[a"} {
+		if ValidatePathFilters([]string{p}) == nil {
+			t.Errorf("ValidatePathFilters(%q) = nil, want error", p)
+		}
+	}
+}
+
+func TestValidateConfigPath(t *testing.T) {
+	tests := []struct {
+		in, want string
+		wantErr  bool
+	}{
+		{"", "", false},
+		{".", "", false},
+		{"services/api/", "services/api", false},
+		{"services/./api", "services/api", false},
+		{"/services/api", "", true},
+		{"../other", "", true},
+		{"services/../..", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ValidateConfigPath(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ValidateConfigPath(%q) = %q, %v; want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// Preview environment statuses. Active environments are torn down when
+// their PR is closed or merged.
+const (
+	PreviewEnvironmentActive         = "active"
+	PreviewEnvironmentTearingDown    = "tearing_down"
+	PreviewEnvironmentTornDown       = "torn_down"
+	PreviewEnvironmentTeardownFailed = "teardown_failed"
+)
+
+// PreviewEnvironment is an ephemeral environment deployed for a pull
+// request. A deploy job registers it (and re-registers it on each PR
+// update); when the PR is closed or merged the coordinator runs
+// TeardownCommand as a teardown job cloned from the last deploy job.
+type PreviewEnvironment struct {
+	EnvironmentID   string     `gorm:"primaryKey;type:uuid;default:generate_ulid()" json:"environment_id"`
+	ProjectID       *string    `gorm:"type:uuid" json:"project_id,omitempty"`
+	VCSRepo         string     `gorm:"type:text;not null" json:"vcs_repo"`
+	PRNumber        int        `gorm:"not null" json:"pr_number"`
+	Name            string     `gorm:"type:text;not null" json:"name"`
+	URL             string     `gorm:"type:text;not null;default:''" json:"url"`
+	Status          string     `gorm:"type:text;not null" json:"status"`
+	HeadSHA         string     `gorm:"type:text;not null;default:''" json:"head_sha,omitempty"`
+	DeployJobID     *string    `gorm:"type:uuid" json:"deploy_job_id,omitempty"`
+	TeardownCommand string     `gorm:"type:text;not null;default:''" json:"teardown_command,omitempty"`
+	TeardownJobID   *string    `gorm:"type:uuid" json:"teardown_job_id,omitempty"`
+	CreatedAt       time.Time  `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"autoUpdateTime:false;default:timezone('utc', now())" json:"updated_at"`
+	TornDownAt      *time.Time `json:"torn_down_at,omitempty"`
+}
+
+// TableName specifies the table name for the model.
+func (PreviewEnvironment) TableName() string {
+	return "preview_environments"
+}
+
+// CanTearDown reports whether a teardown may be started: the environment
+// is active, or its last teardown failed.
+func (e *PreviewEnvironment) CanTearDown() bool {
+	return e.Status == PreviewEnvironmentActive || e.Status == PreviewEnvironmentTeardownFailed
+}
@@ -1,18 +1,25 @@
 package models
 
 import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/lib/pq"
+	"gorm.io/gorm"
 )
 
 // SourceType represents the type of source code preparation
 type SourceType string
 
 const (
-	SourceTypeGit  SourceType = "git"
-	SourceTypeCopy SourceType = "copy"
-	SourceTypeNone SourceType = "none"
+	SourceTypeGit    SourceType = "git"
+	SourceTypeCopy   SourceType = "copy"
+	SourceTypeNone   SourceType = "none"
+	SourceTypeUpload SourceType = "upload"
+	SourceTypeOCI    SourceType = "oci"
 )
 
 // Project represents a repository configuration for CI/CD
@@ -20,18 +27,41 @@ type Project struct {
 	ProjectID string    `gorm:"primaryKey;type:uuid;default:generate_ulid()" json:"project_id"`
 	CreatedAt time.Time `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"created_at"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime:false;default:timezone('utc', now())" json:"updated_at"`
-	UserID    *string   `gorm:"type:uuid" json:"user_id,omitempty"`
+	// DeletedAt is when the project was moved to the trash. Queries through
+	// the model leave out deleted projects; see
+	// postgres_store/trash_operations.go.
+	DeletedAt gorm.DeletedAt `json:"-"`
+	UserID    *string        `gorm:"type:uuid" json:"user_id,omitempty"`
 
 	// Project identification
 	Name        string `gorm:"type:text;not null" json:"name"`
 	Description string `gorm:"type:text" json:"description"`
 	// RepoURL in canonical form: github.com/org/repo (no protocol, no .git suffix)
-	RepoURL string `gorm:"type:text;not null;uniqueIndex" json:"repo_url"`
+	RepoURL string `gorm:"type:text;not null;uniqueIndex:projects_repo_url_config_path_key" json:"repo_url"`
+	// ConfigPath is the directory, relative to the repository root, holding
+	// the project's .reactorcide config. Empty is the root. Projects sharing
+	// a RepoURL (a monorepo) must each have their own.
+	ConfigPath string `gorm:"type:text;not null;default:'';uniqueIndex:projects_repo_url_config_path_key" json:"config_path"`
+	// PathFilters limits the project to changes touching matching paths.
+	// See path_filter.go for pattern syntax. Empty matches every change.
+	PathFilters pq.StringArray `gorm:"type:text[];not null;default:'{}'" json:"path_filters"`
 
 	// Event filtering configuration
-	Enabled           bool           `gorm:"default:true;not null" json:"enabled"`
-	TargetBranches    pq.StringArray `gorm:"type:text[];default:ARRAY['main','master','develop']" json:"target_branches"`
+	Enabled        bool           `gorm:"default:true;not null" json:"enabled"`
+	TargetBranches pq.StringArray `gorm:"type:text[];default:ARRAY['main','master','develop']" json:"target_branches"`
+	// TagPatterns selects the tags that trigger tag_created events. Empty
+	// falls back to TargetBranches. See ref_pattern.go for pattern syntax.
+	TagPatterns       pq.StringArray `gorm:"type:text[]" json:"tag_patterns"`
 	AllowedEventTypes pq.StringArray `gorm:"type:text[];default:ARRAY['push','pull_request_opened','pull_request_updated','tag_created']" json:"allowed_event_types"`
+	// DraftPRPolicy decides whether draft PRs, and PRs whose title matches
+	// WIPTitlePatterns, build: one of the DraftPRPolicy* constants.
+	DraftPRPolicy string `gorm:"type:text;not null;default:'run'" json:"draft_pr_policy"`
+	// WIPTitlePatterns are case-insensitive regular expressions; a PR
+	// whose title matches one is treated like a draft.
+	WIPTitlePatterns pq.StringArray `gorm:"type:text[]" json:"wip_title_patterns"`
+	// RunParameters are the environment variables a manual run may set.
+	// Manual runs also need "manual" in AllowedEventTypes.
+	RunParameters pq.StringArray `gorm:"type:text[];not null;default:'{}'" json:"run_parameters"`
 
 	// Default CI source configuration (trusted CI code)
 	DefaultCISourceType SourceType `gorm:"type:source_type;default:'git'" json:"default_ci_source_type"`
@@ -55,10 +85,83 @@ type Project struct {
 	DefaultJobCommand     string `gorm:"type:text" json:"default_job_command"`
 	DefaultTimeoutSeconds int    `gorm:"default:3600" json:"default_timeout_seconds"`
 	DefaultQueueName      string `gorm:"type:text;default:'reactorcide-jobs'" json:"default_queue_name"`
+	// SpilloverQueues are tried in order for the project's jobs when its
+	// DefaultQueueName has no free worker; see worker.RouteProjectQueue.
+	// Empty leaves jobs on the coordinator's queue, unrouted.
+	SpilloverQueues pq.StringArray `gorm:"type:text[]" json:"spillover_queues,omitempty"`
+	// Canary, when set, routes a share of the project's new jobs to a new
+	// runner image or worker queue; see ProjectCanary.
+	Canary *ProjectCanary `gorm:"type:jsonb" json:"canary,omitempty"`
+
+	// StrictTriggerValidation rejects unknown fields in triggers.json and
+	// applies the v2 semantic checks to v1 documents too. Off by default so
+	// existing pipelines keep working. See worker/trigger_schema.go.
+	StrictTriggerValidation bool `gorm:"not null;default:false" json:"strict_trigger_validation"`
+
+	// JobTemplates, when set, are the project's jobs: its webhooks create
+	// them directly instead of an eval job. See JobTemplate.
+	JobTemplates JobTemplates `gorm:"type:jsonb;not null;default:'[]'" json:"job_templates"`
+
+	// AwaitChildJobs sets AwaitChildren on the eval jobs this project's
+	// webhooks create, so the eval job's status covers every job it triggers.
+	AwaitChildJobs bool `gorm:"not null;default:false" json:"await_child_jobs"`
+
+	// CoalesceWindowSeconds, when set, makes a push to a branch cancel the
+	// unfinished pipelines of the pushes to it in the window before; see
+	// MaxCoalesceWindowSeconds.
+	CoalesceWindowSeconds int `gorm:"not null;default:0" json:"coalesce_window_seconds"`
+
+	// Stuck-job policy overrides. Nil inherits the global
+	// REACTORCIDE_STUCK_JOB_* setting; see StuckJobPolicy.ForProject.
+	StuckJobAction        *string  `gorm:"type:text" json:"stuck_job_action,omitempty"`
+	StuckJobNoLogMinutes  *int     `json:"stuck_job_no_log_minutes,omitempty"`
+	StuckJobP95Multiplier *float64 `json:"stuck_job_p95_multiplier,omitempty"`
+
+	// QualityGates fail the project's jobs whose reported outputs break a
+	// threshold, even when their command exits 0. See QualityGate.
+	QualityGates QualityGates `gorm:"type:jsonb;not null;default:'[]'" json:"quality_gates"`
+
+	// ScanThresholds is the most findings of each severity the project's
+	// scan jobs may have. See ScanThresholds.
+	ScanThresholds ScanThresholds `gorm:"type:jsonb;not null;default:'{}'" json:"scan_thresholds"`
+
+	// ReleaseConfig publishes a VCS release for each tag whose pipeline
+	// succeeds. See ReleaseConfig.
+	ReleaseConfig ReleaseConfig `gorm:"type:jsonb;not null;default:'{}'" json:"release_config"`
+
+	// ArtifactPublishTargets are where the project's completed jobs push
+	// their artifacts. See ArtifactPublishTarget.
+	ArtifactPublishTargets ArtifactPublishTargets `gorm:"type:jsonb;not null;default:'[]'" json:"artifact_publish_targets"`
+
+	// PromotionRules says, per environment, where the project's deploys
+	// may be promoted to it from and how many approvals that takes. See
+	// PromotionRules.
+	PromotionRules PromotionRules `gorm:"type:jsonb;not null;default:'{}'" json:"promotion_rules"`
+
+	// StatusContextTemplate names the commit statuses of the project's
+	// jobs, e.g. "reactorcide/{project}/{job}"; empty keeps the built-in
+	// names. See ValidateStatusContextTemplate.
+	StatusContextTemplate string `gorm:"type:text;not null;default:''" json:"status_context_template"`
+
+	// StatusContextAliases are old status contexts still reported alongside
+	// the ones that replaced them. See StatusContextAliases.
+	StatusContextAliases StatusContextAliases `gorm:"type:jsonb;not null;default:'{}'" json:"status_context_aliases"`
 
 	// IsPrivate marks the project as private. Effective visibility is
 	// IsPrivate OR the owning org's (user's) IsPrivate.
 	IsPrivate bool `gorm:"not null;default:false" json:"is_private"`
+
+	// GroupID is the ProjectGroup the project inherits defaults from.
+	// GroupOverrides lists the inheritable fields it sets itself; the store
+	// fills in the rest from the group on every read, listing them in
+	// InheritedFields. See ApplyGroupDefaults.
+	GroupID         *string        `gorm:"type:uuid" json:"group_id,omitempty"`
+	GroupOverrides  pq.StringArray `gorm:"type:text[];not null;default:'{}'" json:"group_overrides"`
+	InheritedFields []string       `gorm:"-" json:"inherited_fields,omitempty"`
+
+	// LintWarnings are the problems the last create or update found in the
+	// project's configuration; see ProjectLintWarning.
+	LintWarnings ProjectLintWarnings `gorm:"type:jsonb;not null;default:'[]'" json:"lint_warnings"`
 }
 
 // TableName specifies the table name for the model
@@ -104,35 +207,187 @@ func (p *Project) IsEffectivelyPrivate(orgIsPrivate bool) bool {
 	return p.IsPrivate || orgIsPrivate
 }
 
-// ShouldProcessEvent checks if an event should trigger CI based on filtering rules
-func (p *Project) ShouldProcessEvent(eventType string, targetBranch string) bool {
-	if !p.Enabled {
-		return false
+// Reasons a project filters out a VCS event. See EventFilterReason.
+const (
+	EventFilterProjectDisabled = "project_disabled"
+	EventFilterEventType       = "event_type"
+	EventFilterBranch          = "branch_filter"
+	EventFilterTag             = "tag_filter"
+	EventFilterDraftPR         = "draft_pr"
+	EventFilterPaths           = "path_filter"
+)
+
+// MaxCoalesceWindowSeconds caps a project's CoalesceWindowSeconds.
+const MaxCoalesceWindowSeconds = 3600
+
+// MaxSpilloverQueues caps a project's SpilloverQueues.
+const MaxSpilloverQueues = 5
+
+// ValidateSpilloverQueues checks a project's spillover queues: named,
+// distinct, none of them its primary queue, and at most
+// MaxSpilloverQueues.
+func ValidateSpilloverQueues(primary string, queues []string) error {
+	if len(queues) > MaxSpilloverQueues {
+		return fmt.Errorf("at most %d spillover queues are allowed", MaxSpilloverQueues)
+	}
+	seen := map[string]bool{primary: true}
+	for _, queue := range queues {
+		if strings.TrimSpace(queue) == "" {
+			return fmt.Errorf("spillover queue names must not be empty")
+		}
+		if seen[queue] {
+			return fmt.Errorf("spillover queue %q is the primary queue or listed twice", queue)
+		}
+		seen[queue] = true
 	}
+	return nil
+}
 
-	// Check if event type is allowed
-	eventAllowed := false
-	for _, allowedType := range p.AllowedEventTypes {
-		if allowedType == eventType {
-			eventAllowed = true
-			break
+// Draft PR policies.
+const (
+	// DraftPRPolicyRun builds draft and WIP PRs like any other (default).
+	DraftPRPolicyRun = "run"
+	// DraftPRPolicySkip doesn't build draft or WIP PRs; the first push
+	// after they're ready builds them.
+	DraftPRPolicySkip = "skip"
+	// DraftPRPolicyDefer doesn't build draft or WIP PRs, and builds them
+	// as soon as they're marked ready for review or lose their WIP title.
+	DraftPRPolicyDefer = "defer"
+)
+
+// IsValidDraftPRPolicy reports whether policy is a known draft PR policy.
+func IsValidDraftPRPolicy(policy string) bool {
+	switch policy {
+	case DraftPRPolicyRun, DraftPRPolicySkip, DraftPRPolicyDefer:
+		return true
+	}
+	return false
+}
+
+// wipTitlePatterns caches compiled WIP title patterns by their source, so
+// IsWIPTitle, on the webhook path, compiles each pattern once rather than
+// on every event.
+var wipTitlePatterns sync.Map // pattern -> compiledWIPTitlePattern
+
+type compiledWIPTitlePattern struct {
+	re  *regexp.Regexp
+	err error
+}
+
+// compileWIPTitlePattern compiles pattern case-insensitively, through
+// wipTitlePatterns.
+func compileWIPTitlePattern(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := wipTitlePatterns.Load(pattern); ok {
+		c := cached.(compiledWIPTitlePattern)
+		return c.re, c.err
+	}
+	re, err := regexp.Compile("(?i)" + pattern)
+	wipTitlePatterns.Store(pattern, compiledWIPTitlePattern{re: re, err: err})
+	return re, err
+}
+
+// ValidateWIPTitlePatterns reports the first pattern that doesn't compile.
+func ValidateWIPTitlePatterns(patterns []string) error {
+	for _, p := range patterns {
+		if _, err := compileWIPTitlePattern(p); err != nil {
+			return fmt.Errorf("invalid WIP title pattern %q: %w", p, err)
 		}
 	}
-	if !eventAllowed {
-		return false
+	return nil
+}
+
+// runParameterPattern is an environment variable name.
+var runParameterPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidateRunParameters reports the first name that isn't an environment
+// variable name, or is reserved: REACTORCIDE_ variables describe the run
+// itself.
+func ValidateRunParameters(names []string) error {
+	for _, name := range names {
+		if !runParameterPattern.MatchString(name) {
+			return fmt.Errorf("invalid run parameter %q: not an environment variable name", name)
+		}
+		if strings.HasPrefix(strings.ToUpper(name), "REACTORCIDE_") {
+			return fmt.Errorf("invalid run parameter %q: REACTORCIDE_ variables are reserved", name)
+		}
 	}
+	return nil
+}
 
-	// Check if branch is in target branches
-	// Empty target branches means allow all branches
-	if len(p.TargetBranches) == 0 {
-		return true
+// AllowsRunParameter reports whether a manual run may set name.
+func (p *Project) AllowsRunParameter(name string) bool {
+	for _, allowed := range p.RunParameters {
+		if allowed == name {
+			return true
+		}
 	}
+	return false
+}
 
-	for _, branch := range p.TargetBranches {
-		if branch == targetBranch {
+// DefersDraftPRs reports whether draft and WIP PRs are held back from
+// building.
+func (p *Project) DefersDraftPRs() bool {
+	return p.DraftPRPolicy == DraftPRPolicySkip || p.DraftPRPolicy == DraftPRPolicyDefer
+}
+
+// IsWIPTitle reports whether title matches one of WIPTitlePatterns.
+// Patterns that don't compile never match.
+func (p *Project) IsWIPTitle(title string) bool {
+	for _, pattern := range p.WIPTitlePatterns {
+		re, err := compileWIPTitlePattern(pattern)
+		if err == nil && re.MatchString(title) {
 			return true
 		}
 	}
+	return false
+}
+
+// tagCreatedEvent is vcs.EventTagCreated, whose ref is filtered by
+// TagPatterns.
+const tagCreatedEvent = "tag_created"
+
+// ShouldProcessEvent checks if an event should trigger CI based on filtering rules
+func (p *Project) ShouldProcessEvent(eventType string, targetBranch string) bool {
+	return p.EventFilterReason(eventType, targetBranch) == ""
+}
+
+// EventFilterReason returns why the project's filtering rules reject an
+// event (one of the EventFilter* constants), or "" if it should trigger CI.
+// targetBranch is the branch name, or the tag name for tag_created events.
+func (p *Project) EventFilterReason(eventType string, targetBranch string) string {
+	if !p.Enabled {
+		return EventFilterProjectDisabled
+	}
+	if !p.AllowsEventType(eventType) {
+		return EventFilterEventType
+	}
+	return p.RefFilterReason(eventType, targetBranch)
+}
 
+// AllowsEventType reports whether eventType is one of AllowedEventTypes.
+func (p *Project) AllowsEventType(eventType string) bool {
+	for _, allowedType := range p.AllowedEventTypes {
+		if allowedType == eventType {
+			return true
+		}
+	}
 	return false
 }
+
+// RefFilterReason returns EventFilterTag or EventFilterBranch if the
+// project's ref patterns reject ref, or "" if they select it. ref is the
+// branch name, or the tag name for tag_created events.
+func (p *Project) RefFilterReason(eventType string, ref string) string {
+	// Tags are matched against TagPatterns when set; otherwise tags and
+	// branches share TargetBranches. Empty patterns allow every ref.
+	if eventType == tagCreatedEvent && len(p.TagPatterns) > 0 {
+		if !MatchRefPatterns(p.TagPatterns, ref) {
+			return EventFilterTag
+		}
+		return ""
+	}
+	if !MatchRefPatterns(p.TargetBranches, ref) {
+		return EventFilterBranch
+	}
+	return ""
+}
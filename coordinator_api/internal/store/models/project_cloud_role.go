@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Cloud providers a ProjectCloudRole can mint credentials for.
+const (
+	CloudProviderAWS = "aws"
+	CloudProviderGCP = "gcp"
+)
+
+// ProjectCloudRole maps a project's jobs to a cloud role. At job start the
+// worker mints short-lived credentials for the role and injects them into
+// the job's environment, so no long-lived cloud keys need to be stored.
+type ProjectCloudRole struct {
+	RoleID    string    `gorm:"primaryKey;type:uuid;default:generate_ulid()" json:"role_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime:false;default:timezone('utc', now())" json:"updated_at"`
+	ProjectID string    `gorm:"type:uuid;not null" json:"project_id"`
+	Name      string    `gorm:"type:text;not null" json:"name"`
+	// Provider is CloudProviderAWS or CloudProviderGCP.
+	Provider string `gorm:"type:text;not null" json:"provider"`
+	// Role is the AWS role ARN to assume, or the email of the GCP service
+	// account to impersonate.
+	Role string `gorm:"type:text;not null" json:"role"`
+	// JobNameMatch and JobNamePattern pick the jobs the role applies to,
+	// like a SecretGrant's job match.
+	JobNameMatch    string `gorm:"type:text;not null;default:'any'" json:"job_name_match"`
+	JobNamePattern  string `gorm:"type:text;not null;default:''" json:"job_name_pattern,omitempty"`
+	DurationSeconds int    `gorm:"not null;default:3600" json:"duration_seconds"`
+	// Region sets AWS_REGION for AWS roles.
+	Region string `gorm:"type:text;not null;default:''" json:"region,omitempty"`
+	// ExternalID is passed to AWS AssumeRole.
+	ExternalID string `gorm:"type:text;not null;default:''" json:"external_id,omitempty"`
+	// Scopes are the OAuth scopes of a GCP access token (default:
+	// cloud-platform).
+	Scopes pq.StringArray `gorm:"type:text[];not null;default:'{}'" json:"scopes,omitempty"`
+	// EnvPrefix is prepended to the injected variable names, so a job can
+	// hold credentials for more than one role of the same provider.
+	EnvPrefix   string `gorm:"type:text;not null;default:''" json:"env_prefix,omitempty"`
+	Description string `gorm:"type:text" json:"description,omitempty"`
+}
+
+func (ProjectCloudRole) TableName() string {
+	return "project_cloud_roles"
+}
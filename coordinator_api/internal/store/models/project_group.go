@@ -0,0 +1,152 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Project fields a ProjectGroup can set defaults for, named by their JSON
+// keys. Project.GroupOverrides lists the ones a member project sets itself.
+const (
+	ProjectFieldRunnerImage       = "default_runner_image"
+	ProjectFieldTimeoutSeconds    = "default_timeout_seconds"
+	ProjectFieldQueueName         = "default_queue_name"
+	ProjectFieldAllowedEventTypes = "allowed_event_types"
+	ProjectFieldVCSTokenSecret    = "vcs_token_secret"
+	ProjectFieldWebhookSecret     = "webhook_secret"
+)
+
+// InheritableProjectFields lists the fields a group can set, in the order
+// responses show them.
+var InheritableProjectFields = []string{
+	ProjectFieldRunnerImage,
+	ProjectFieldTimeoutSeconds,
+	ProjectFieldQueueName,
+	ProjectFieldAllowedEventTypes,
+	ProjectFieldVCSTokenSecret,
+	ProjectFieldWebhookSecret,
+}
+
+// IsInheritableProjectField reports whether field is one a group can set.
+func IsInheritableProjectField(field string) bool {
+	for _, f := range InheritableProjectFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// ProjectGroup is a folder of projects sharing defaults. A nil default is
+// unset, leaving members their own value.
+type ProjectGroup struct {
+	GroupID     string    `gorm:"primaryKey;type:uuid;default:generate_ulid()" json:"group_id"`
+	CreatedAt   time.Time `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime:false;default:timezone('utc', now())" json:"updated_at"`
+	UserID      *string   `gorm:"type:uuid" json:"user_id,omitempty"`
+	Name        string    `gorm:"type:text;not null;uniqueIndex" json:"name"`
+	Description string    `gorm:"type:text;not null;default:''" json:"description"`
+
+	DefaultRunnerImage    *string        `gorm:"type:text" json:"default_runner_image,omitempty"`
+	DefaultTimeoutSeconds *int           `json:"default_timeout_seconds,omitempty"`
+	DefaultQueueName      *string        `gorm:"type:text" json:"default_queue_name,omitempty"`
+	AllowedEventTypes     pq.StringArray `gorm:"type:text[]" json:"allowed_event_types,omitempty"`
+	// VCSTokenSecret and WebhookSecret are "path:key" secret refs, as on
+	// Project.
+	VCSTokenSecret *string `gorm:"type:text" json:"vcs_token_secret,omitempty"`
+	WebhookSecret  *string `gorm:"type:text" json:"webhook_secret,omitempty"`
+}
+
+// TableName specifies the table name for the model.
+func (ProjectGroup) TableName() string {
+	return "project_groups"
+}
+
+// SetFields returns the inheritable fields the group sets.
+func (g *ProjectGroup) SetFields() []string {
+	var fields []string
+	for _, field := range InheritableProjectFields {
+		if g.sets(field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func (g *ProjectGroup) sets(field string) bool {
+	switch field {
+	case ProjectFieldRunnerImage:
+		return g.DefaultRunnerImage != nil
+	case ProjectFieldTimeoutSeconds:
+		return g.DefaultTimeoutSeconds != nil
+	case ProjectFieldQueueName:
+		return g.DefaultQueueName != nil
+	case ProjectFieldAllowedEventTypes:
+		return g.AllowedEventTypes != nil
+	case ProjectFieldVCSTokenSecret:
+		return g.VCSTokenSecret != nil
+	case ProjectFieldWebhookSecret:
+		return g.WebhookSecret != nil
+	}
+	return false
+}
+
+// OverridesGroupField reports whether the project sets field itself rather
+// than inheriting it from its group.
+func (p *Project) OverridesGroupField(field string) bool {
+	for _, f := range p.GroupOverrides {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// SetGroupOverride records whether the project sets field itself.
+func (p *Project) SetGroupOverride(field string, override bool) {
+	if override == p.OverridesGroupField(field) {
+		return
+	}
+	if override {
+		p.GroupOverrides = append(p.GroupOverrides, field)
+		return
+	}
+	kept := pq.StringArray{}
+	for _, f := range p.GroupOverrides {
+		if f != field {
+			kept = append(kept, f)
+		}
+	}
+	p.GroupOverrides = kept
+}
+
+// ApplyGroupDefaults replaces the project's value of every field the group
+// sets and the project doesn't override, recording them in
+// InheritedFields.
+func (p *Project) ApplyGroupDefaults(g *ProjectGroup) {
+	p.InheritedFields = nil
+	if g == nil {
+		return
+	}
+	for _, field := range g.SetFields() {
+		if p.OverridesGroupField(field) {
+			continue
+		}
+		switch field {
+		case ProjectFieldRunnerImage:
+			p.DefaultRunnerImage = *g.DefaultRunnerImage
+		case ProjectFieldTimeoutSeconds:
+			p.DefaultTimeoutSeconds = *g.DefaultTimeoutSeconds
+		case ProjectFieldQueueName:
+			p.DefaultQueueName = *g.DefaultQueueName
+		case ProjectFieldAllowedEventTypes:
+			p.AllowedEventTypes = append(pq.StringArray{}, g.AllowedEventTypes...)
+		case ProjectFieldVCSTokenSecret:
+			p.VCSTokenSecret = *g.VCSTokenSecret
+		case ProjectFieldWebhookSecret:
+			p.WebhookSecret = *g.WebhookSecret
+		}
+		p.InheritedFields = append(p.InheritedFields, field)
+	}
+}
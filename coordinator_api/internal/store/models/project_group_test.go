@@ -0,0 +1,83 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestProject_ApplyGroupDefaults(t *testing.T) {
+	image := "golang:1.22"
+	timeout := 900
+	group := &ProjectGroup{
+		DefaultRunnerImage:    &image,
+		DefaultTimeoutSeconds: &timeout,
+		AllowedEventTypes:     pq.StringArray{"push"},
+	}
+
+	project := &Project{
+		DefaultRunnerImage:    "alpine:latest",
+		DefaultTimeoutSeconds: 3600,
+		DefaultQueueName:      "reactorcide-jobs",
+		AllowedEventTypes:     pq.StringArray{"push", "pull_request"},
+		GroupOverrides:        pq.StringArray{ProjectFieldTimeoutSeconds},
+	}
+	project.ApplyGroupDefaults(group)
+
+	if project.DefaultRunnerImage != image {
+		t.Errorf("DefaultRunnerImage = %q, want inherited %q", project.DefaultRunnerImage, image)
+	}
+	if project.DefaultTimeoutSeconds != 3600 {
+		t.Errorf("DefaultTimeoutSeconds = %d, want overridden 3600", project.DefaultTimeoutSeconds)
+	}
+	if project.DefaultQueueName != "reactorcide-jobs" {
+		t.Errorf("DefaultQueueName = %q, want own value kept for unset default", project.DefaultQueueName)
+	}
+	if !reflect.DeepEqual([]string(project.AllowedEventTypes), []string{"push"}) {
+		t.Errorf("AllowedEventTypes = %v, want inherited [push]", project.AllowedEventTypes)
+	}
+	want := []string{ProjectFieldRunnerImage, ProjectFieldAllowedEventTypes}
+	if !reflect.DeepEqual(project.InheritedFields, want) {
+		t.Errorf("InheritedFields = %v, want %v", project.InheritedFields, want)
+	}
+
+	// The project's copy must not alias the group's slice.
+	project.AllowedEventTypes[0] = "tag"
+	if group.AllowedEventTypes[0] != "push" {
+		t.Error("ApplyGroupDefaults aliased the group's allowed event types")
+	}
+
+	project.ApplyGroupDefaults(nil)
+	if project.InheritedFields != nil {
+		t.Errorf("InheritedFields = %v after nil group, want nil", project.InheritedFields)
+	}
+}
+
+func TestProject_SetGroupOverride(t *testing.T) {
+	project := &Project{GroupOverrides: pq.StringArray{}}
+
+	project.SetGroupOverride(ProjectFieldQueueName, true)
+	project.SetGroupOverride(ProjectFieldQueueName, true)
+	project.SetGroupOverride(ProjectFieldWebhookSecret, true)
+	if !reflect.DeepEqual([]string(project.GroupOverrides), []string{ProjectFieldQueueName, ProjectFieldWebhookSecret}) {
+		t.Fatalf("GroupOverrides = %v", project.GroupOverrides)
+	}
+
+	project.SetGroupOverride(ProjectFieldQueueName, false)
+	if project.OverridesGroupField(ProjectFieldQueueName) {
+		t.Error("queue name still overridden after clearing")
+	}
+	if !project.OverridesGroupField(ProjectFieldWebhookSecret) {
+		t.Error("webhook secret override lost")
+	}
+}
+
+func TestIsInheritableProjectField(t *testing.T) {
+	if !IsInheritableProjectField(ProjectFieldVCSTokenSecret) {
+		t.Error("vcs_token_secret should be inheritable")
+	}
+	if IsInheritableProjectField("repo_url") {
+		t.Error("repo_url should not be inheritable")
+	}
+}
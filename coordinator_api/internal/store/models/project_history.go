@@ -0,0 +1,183 @@
+package models
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Project config history actions. See ProjectConfigVersion.
+const (
+	ProjectHistoryCreated  = "created"
+	ProjectHistoryUpdated  = "updated"
+	ProjectHistoryReverted = "reverted"
+)
+
+// projectConfigOmittedFields are the Project JSON fields that aren't its
+// configuration: its identity, timestamps, the lint results of its last
+// save and the fields it inherits from its group on read.
+var projectConfigOmittedFields = []string{"project_id", "created_at", "updated_at", "lint_warnings", "inherited_fields"}
+
+// ProjectConfigVersion is a numbered snapshot of a project's configuration
+// after a create, update or revert: what it was, who changed it, and the
+// fields that changed from the version before. Versions count up from 1
+// per project.
+type ProjectConfigVersion struct {
+	HistoryID string `gorm:"primaryKey;type:uuid;default:generate_ulid()" json:"history_id"`
+	ProjectID string `gorm:"type:uuid;not null" json:"project_id"`
+	Version   int    `gorm:"not null" json:"version"`
+	// Action is one of the ProjectHistory* constants.
+	Action string `gorm:"type:text;not null" json:"action"`
+	// ActorID is the user who made the change; nil for changes made
+	// without one, e.g. by the system, or by a since-deleted user.
+	ActorID *string `gorm:"type:uuid" json:"actor_id,omitempty"`
+	// RevertedTo is the version a revert restored.
+	RevertedTo *int                 `json:"reverted_to,omitempty"`
+	Config     JSONB                `gorm:"type:jsonb;not null" json:"config"`
+	Changes    ProjectConfigChanges `gorm:"type:jsonb;not null;default:'[]'" json:"changes"`
+	ChangedAt  time.Time            `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"changed_at"`
+}
+
+// TableName specifies the table name for the model
+func (ProjectConfigVersion) TableName() string {
+	return "project_config_history"
+}
+
+// ProjectConfigChange is a field of a project's configuration that a
+// version changed, with its JSON value before and after. Old is absent for
+// the fields a project was created with.
+type ProjectConfigChange struct {
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old,omitempty"`
+	New   json.RawMessage `json:"new,omitempty"`
+}
+
+// ProjectConfigChanges is a version's changes, stored in a jsonb column.
+type ProjectConfigChanges []ProjectConfigChange
+
+// Value implements driver.Valuer interface for database storage.
+func (c ProjectConfigChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(c)
+}
+
+// Scan implements sql.Scanner interface for database retrieval.
+func (c *ProjectConfigChanges) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into ProjectConfigChanges", value)
+	}
+	return json.Unmarshal(data, c)
+}
+
+// Fields returns the names of the fields c changed.
+func (c ProjectConfigChanges) Fields() []string {
+	fields := make([]string, len(c))
+	for i, change := range c {
+		fields[i] = change.Field
+	}
+	return fields
+}
+
+// ProjectConfig returns project's configuration as it's kept in its
+// history: its JSON form, less the fields that aren't configuration.
+func ProjectConfig(project *Project) (JSONB, error) {
+	data, err := json.Marshal(project)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode project config: %w", err)
+	}
+	config := JSONB{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to decode project config: %w", err)
+	}
+	for _, field := range projectConfigOmittedFields {
+		delete(config, field)
+	}
+	return config, nil
+}
+
+// DiffProjectConfigs returns the fields whose values differ between two
+// ProjectConfig snapshots, sorted by name. A nil before diffs against
+// nothing, so every field is a change.
+func DiffProjectConfigs(before, after JSONB) ProjectConfigChanges {
+	fields := map[string]bool{}
+	for field := range before {
+		fields[field] = true
+	}
+	for field := range after {
+		fields[field] = true
+	}
+	names := make([]string, 0, len(fields))
+	for field := range fields {
+		names = append(names, field)
+	}
+	sort.Strings(names)
+
+	changes := ProjectConfigChanges{}
+	for _, field := range names {
+		oldValue, hadOld := configValue(before, field)
+		newValue, hadNew := configValue(after, field)
+		if hadOld == hadNew && bytes.Equal(oldValue, newValue) {
+			continue
+		}
+		changes = append(changes, ProjectConfigChange{Field: field, Old: oldValue, New: newValue})
+	}
+	return changes
+}
+
+// configValue returns config's field as JSON, and whether it has it. A
+// null field counts as absent, as omitempty fields are when they're unset.
+func configValue(config JSONB, field string) (json.RawMessage, bool) {
+	value, ok := config[field]
+	if !ok || value == nil {
+		return nil, false
+	}
+	// Maps marshal with sorted keys, so equal values encode the same.
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// ApplyProjectConfig replaces project's configuration with a ProjectConfig
+// snapshot, for reverting to it. The project keeps its identity,
+// timestamps and lint warnings, and its owner: a revert doesn't undo an
+// ownership transfer.
+func ApplyProjectConfig(project *Project, config JSONB) error {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to encode project config: %w", err)
+	}
+	var restored Project
+	if err := json.Unmarshal(data, &restored); err != nil {
+		return fmt.Errorf("failed to decode project config: %w", err)
+	}
+	restored.ProjectID = project.ProjectID
+	restored.CreatedAt = project.CreatedAt
+	restored.UpdatedAt = project.UpdatedAt
+	restored.DeletedAt = project.DeletedAt
+	restored.UserID = project.UserID
+	restored.LintWarnings = project.LintWarnings
+	if restored.VCSCredentialSecrets == nil {
+		restored.VCSCredentialSecrets = JSONB{}
+	}
+	if restored.WebhookSecrets == nil {
+		restored.WebhookSecrets = JSONB{}
+	}
+	*project = restored
+	return nil
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectConfigLeavesOutIdentity(t *testing.T) {
+	owner := "user-1"
+	config, err := ProjectConfig(&Project{
+		ProjectID:       "project-1",
+		UserID:          &owner,
+		Name:            "api",
+		LintWarnings:    ProjectLintWarnings{{Code: LintNoEventTypes}},
+		InheritedFields: []string{ProjectFieldQueueName},
+	})
+	require.NoError(t, err)
+	for _, field := range []string{"project_id", "created_at", "updated_at", "lint_warnings", "inherited_fields"} {
+		assert.NotContains(t, config, field)
+	}
+	assert.Equal(t, "api", config["name"])
+	assert.Equal(t, owner, config["user_id"])
+}
+
+func TestDiffProjectConfigs(t *testing.T) {
+	before, err := ProjectConfig(&Project{
+		Name:           "api",
+		TargetBranches: pq.StringArray{"main"},
+		WebhookSecrets: JSONB{"github": "hooks:github"},
+	})
+	require.NoError(t, err)
+	timeout := 30
+	after, err := ProjectConfig(&Project{
+		Name:                 "api",
+		TargetBranches:       pq.StringArray{"main", "release/*"},
+		WebhookSecrets:       JSONB{"github": "hooks:github"},
+		StuckJobNoLogMinutes: &timeout,
+	})
+	require.NoError(t, err)
+
+	changes := DiffProjectConfigs(before, after)
+	assert.Equal(t, []string{"stuck_job_no_log_minutes", "target_branches"}, changes.Fields())
+	assert.Nil(t, changes[0].Old)
+	assert.JSONEq(t, `30`, string(changes[0].New))
+	assert.JSONEq(t, `["main"]`, string(changes[1].Old))
+	assert.JSONEq(t, `["main","release/*"]`, string(changes[1].New))
+
+	assert.Empty(t, DiffProjectConfigs(after, after))
+	created := DiffProjectConfigs(nil, after).Fields()
+	assert.Contains(t, created, "name")
+	assert.NotContains(t, created, "path_filters", "null fields aren't changes")
+}
+
+func TestApplyProjectConfig(t *testing.T) {
+	owner, newOwner := "user-1", "user-2"
+	config, err := ProjectConfig(&Project{
+		UserID:         &owner,
+		Name:           "api",
+		TargetBranches: pq.StringArray{"main"},
+	})
+	require.NoError(t, err)
+
+	project := &Project{
+		ProjectID:      "project-1",
+		UserID:         &newOwner,
+		Name:           "renamed",
+		TargetBranches: pq.StringArray{"develop"},
+		WebhookSecret:  "hooks:secret",
+		LintWarnings:   ProjectLintWarnings{{Code: LintNoEventTypes}},
+	}
+	require.NoError(t, ApplyProjectConfig(project, config))
+
+	assert.Equal(t, "project-1", project.ProjectID)
+	assert.Equal(t, newOwner, *project.UserID, "ownership isn't reverted")
+	assert.Equal(t, "api", project.Name)
+	assert.Equal(t, pq.StringArray{"main"}, project.TargetBranches)
+	assert.Empty(t, project.WebhookSecret)
+	assert.NotNil(t, project.WebhookSecrets)
+	assert.Len(t, project.LintWarnings, 1)
+}
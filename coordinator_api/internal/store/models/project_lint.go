@@ -0,0 +1,65 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// Project lint warning codes. See ProjectLintWarning.
+const (
+	// LintNoEventTypes: the project allows no event types, so nothing
+	// builds it.
+	LintNoEventTypes = "no_event_types"
+	// LintUnreachableEventType: an allowed event type, or one a job
+	// template runs for, that no event the project filters ever has.
+	LintUnreachableEventType = "unreachable_event_type"
+	// LintUnmatchableRefPattern: a branch or tag pattern no name can match.
+	LintUnmatchableRefPattern = "unmatchable_ref_pattern"
+	// LintRunnerImageNotAllowed: a runner image the job admission policies
+	// reject, so the jobs using it can't be created.
+	LintRunnerImageNotAllowed = "runner_image_not_allowed"
+	// LintCISourceNotAllowed: a default CI source URL that isn't in
+	// REACTORCIDE_CI_CODE_ALLOWLIST.
+	LintCISourceNotAllowed = "ci_source_not_allowed"
+)
+
+// ProjectLintWarning is something in a project's configuration that
+// doesn't stop it from being saved but is unlikely to be what was meant,
+// e.g. a branch pattern that never matches. Field is the JSON field it's
+// about, Value the offending value, if there is one.
+type ProjectLintWarning struct {
+	Code    string `json:"code"`
+	Field   string `json:"field"`
+	Value   string `json:"value,omitempty"`
+	Message string `json:"message"`
+}
+
+// ProjectLintWarnings is the warnings from a project's last save, stored
+// in a jsonb column so the UI can show them.
+type ProjectLintWarnings []ProjectLintWarning
+
+// Value implements driver.Valuer interface for database storage.
+func (w ProjectLintWarnings) Value() (driver.Value, error) {
+	if w == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(w)
+}
+
+// Scan implements sql.Scanner interface for database retrieval.
+func (w *ProjectLintWarnings) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		*w = nil
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into ProjectLintWarnings", value)
+	}
+	return json.Unmarshal(bytes, w)
+}
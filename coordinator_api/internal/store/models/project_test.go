@@ -102,6 +102,46 @@ func TestProject_ShouldProcessEvent(t *testing.T) {
 	}
 }
 
+func TestProject_EventFilterReason(t *testing.T) {
+	project := &Project{
+		Enabled:           true,
+		TargetBranches:    []string{"main"},
+		AllowedEventTypes: []string{"push"},
+	}
+	disabled := *project
+	disabled.Enabled = false
+	tagged := *project
+	tagged.AllowedEventTypes = []string{"push", "tag_created"}
+	tagged.TagPatterns = []string{"v*"}
+	untagged := tagged
+	untagged.TagPatterns = nil
+
+	tests := []struct {
+		name         string
+		project      *Project
+		eventType    string
+		targetBranch string
+		want         string
+	}{
+		{"allowed", project, "push", "main", ""},
+		{"disabled project", &disabled, "push", "main", EventFilterProjectDisabled},
+		{"event type not allowed", project, "pull_request", "main", EventFilterEventType},
+		{"branch not targeted", project, "push", "feature", EventFilterBranch},
+		{"tag falls back to target branches", &untagged, "tag_created", "v1.0.0", EventFilterBranch},
+		{"tag matches tag patterns", &tagged, "tag_created", "v1.0.0", ""},
+		{"tag outside tag patterns", &tagged, "tag_created", "nightly", EventFilterTag},
+		{"tag patterns leave branches alone", &tagged, "push", "main", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.project.EventFilterReason(tt.eventType, tt.targetBranch); got != tt.want {
+				t.Errorf("EventFilterReason(%q, %q) = %q, want %q", tt.eventType, tt.targetBranch, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestProject_IsEffectivelyPrivate(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -157,3 +197,47 @@ func TestSourceType_Constants(t *testing.T) {
 		t.Errorf("SourceTypeNone = %q, want %q", SourceTypeNone, "none")
 	}
 }
+
+func TestValidateSpilloverQueues(t *testing.T) {
+	tests := []struct {
+		name    string
+		queues  []string
+		wantErr bool
+	}{
+		{name: "none", queues: nil},
+		{name: "shared queues", queues: []string{"shared", "overflow"}},
+		{name: "primary queue", queues: []string{"team-a"}, wantErr: true},
+		{name: "duplicate", queues: []string{"shared", "shared"}, wantErr: true},
+		{name: "empty name", queues: []string{" "}, wantErr: true},
+		{name: "too many", queues: []string{"q1", "q2", "q3", "q4", "q5", "q6"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSpilloverQueues("team-a", tt.queues)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSpilloverQueues(%v) error = %v, wantErr %v", tt.queues, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProject_IsWIPTitle(t *testing.T) {
+	p := &Project{WIPTitlePatterns: []string{`^\[?wip\b`, "(broken"}}
+	for title, want := range map[string]bool{
+		"WIP: new parser":  true,
+		"[wip] new parser": true,
+		"Wipe the cache":   false,
+	} {
+		if got := p.IsWIPTitle(title); got != want {
+			t.Errorf("IsWIPTitle(%q) = %v, want %v", title, got, want)
+		}
+	}
+
+	cached, ok := wipTitlePatterns.Load(`^\[?wip\b`)
+	if !ok || cached.(compiledWIPTitlePattern).re == nil {
+		t.Fatal("expected the pattern to be compiled once and cached")
+	}
+	if err := ValidateWIPTitlePatterns(p.WIPTitlePatterns); err == nil {
+		t.Error("expected the cached failure of (broken to be reported")
+	}
+}
@@ -0,0 +1,181 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Promotion statuses. A promotion is "pending" until it has its approvals,
+// then "promoted" once its job is created, or "failed" if that failed. A
+// rejected promotion doesn't run.
+const (
+	PromotionPending  = "pending"
+	PromotionRejected = "rejected"
+	PromotionPromoted = "promoted"
+	PromotionFailed   = "failed"
+)
+
+// MaxPromotionApprovals caps the approvals a promotion rule may require.
+const MaxPromotionApprovals = 10
+
+// PromotionRule governs promotions to one environment. From lists the
+// environments deploys may be promoted from, any when empty, and
+// Approvals how many approvals a promotion needs before it runs; zero runs
+// it straight away.
+type PromotionRule struct {
+	From      []string `json:"from,omitempty"`
+	Approvals int      `json:"approvals"`
+}
+
+// AllowsFrom reports whether a deploy to environment may be promoted under
+// the rule.
+func (r PromotionRule) AllowsFrom(environment string) bool {
+	if len(r.From) == 0 {
+		return true
+	}
+	for _, from := range r.From {
+		if from == environment {
+			return true
+		}
+	}
+	return false
+}
+
+// PromotionRules maps a target environment to its PromotionRule, stored in
+// a jsonb column. Promotions to environments without a rule need one
+// approval, from any environment.
+type PromotionRules map[string]PromotionRule
+
+// Rule returns the rule for promotions to environment.
+func (r PromotionRules) Rule(environment string) PromotionRule {
+	if rule, ok := r[environment]; ok {
+		return rule
+	}
+	return PromotionRule{Approvals: 1}
+}
+
+// Value implements driver.Valuer interface for database storage.
+func (r PromotionRules) Value() (driver.Value, error) {
+	if r == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(map[string]PromotionRule(r))
+}
+
+// Scan implements sql.Scanner interface for database retrieval.
+func (r *PromotionRules) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		*r = nil
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into PromotionRules", value)
+	}
+	return json.Unmarshal(bytes, r)
+}
+
+// Validate checks the environment names and the approval counts.
+func (r PromotionRules) Validate() error {
+	for environment, rule := range r {
+		if err := ValidateEnvironmentName(environment); err != nil {
+			return fmt.Errorf("promotion_rules: %w", err)
+		}
+		for _, from := range rule.From {
+			if err := ValidateEnvironmentName(from); err != nil {
+				return fmt.Errorf("promotion_rules.%s: %w", environment, err)
+			}
+			if from == environment {
+				return fmt.Errorf("promotion_rules.%s: can't promote an environment to itself", environment)
+			}
+		}
+		if rule.Approvals < 0 || rule.Approvals > MaxPromotionApprovals {
+			return fmt.Errorf("promotion_rules.%s: approvals must be between 0 and %d", environment, MaxPromotionApprovals)
+		}
+	}
+	return nil
+}
+
+// PromotionApproval is one user's approval of a promotion.
+type PromotionApproval struct {
+	UserID     string    `json:"user_id"`
+	ApprovedAt time.Time `json:"approved_at"`
+}
+
+// PromotionApprovals is a promotion's approvals, oldest first, stored in a
+// jsonb column.
+type PromotionApprovals []PromotionApproval
+
+// Value implements driver.Valuer interface for database storage.
+func (a PromotionApprovals) Value() (driver.Value, error) {
+	if a == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]PromotionApproval(a))
+}
+
+// Scan implements sql.Scanner interface for database retrieval.
+func (a *PromotionApprovals) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into PromotionApprovals", value)
+	}
+	return json.Unmarshal(bytes, a)
+}
+
+// Includes reports whether userID has approved.
+func (a PromotionApprovals) Includes(userID string) bool {
+	for _, approval := range a {
+		if approval.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// Promotion reruns a completed deploy job, SourceJobID, against another
+// environment. JobID is the rerun, once the promotion has its approvals.
+// ArtifactDigest identifies what's deployed, carried from promotion to
+// promotion so its path through the environments can be traced.
+type Promotion struct {
+	PromotionID       string             `gorm:"primaryKey;type:uuid;default:generate_ulid()" json:"promotion_id"`
+	ProjectID         string             `gorm:"type:uuid;not null" json:"project_id"`
+	SourceJobID       string             `gorm:"type:uuid;not null" json:"source_job_id"`
+	JobID             *string            `gorm:"type:uuid" json:"job_id,omitempty"`
+	FromEnvironment   string             `gorm:"type:text;not null" json:"from_environment"`
+	ToEnvironment     string             `gorm:"type:text;not null" json:"to_environment"`
+	ArtifactDigest    string             `gorm:"type:text;not null" json:"artifact_digest"`
+	Status            string             `gorm:"type:text;not null;default:'pending'" json:"status"`
+	ApprovalsRequired int                `gorm:"not null;default:1" json:"approvals_required"`
+	Approvals         PromotionApprovals `gorm:"type:jsonb;not null;default:'[]'" json:"approvals"`
+	RequestedBy       string             `gorm:"type:uuid;not null" json:"requested_by"`
+	DecidedBy         *string            `gorm:"type:uuid" json:"decided_by,omitempty"`
+	Reason            string             `gorm:"type:text;not null;default:''" json:"reason,omitempty"`
+	LastError         string             `gorm:"type:text;not null;default:''" json:"last_error,omitempty"`
+	CreatedAt         time.Time          `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"created_at"`
+	DecidedAt         *time.Time         `json:"decided_at,omitempty"`
+}
+
+// TableName specifies the table name for the model.
+func (Promotion) TableName() string {
+	return "promotions"
+}
+
+// Approved reports whether the promotion has the approvals it needs.
+func (p *Promotion) Approved() bool {
+	return len(p.Approvals) >= p.ApprovalsRequired
+}
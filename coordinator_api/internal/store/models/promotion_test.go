@@ -0,0 +1,35 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromotionRules(t *testing.T) {
+	rules := PromotionRules{"prod": {From: []string{"staging"}, Approvals: 2}}
+	assert.NoError(t, rules.Validate())
+
+	prod := rules.Rule("prod")
+	assert.Equal(t, 2, prod.Approvals)
+	assert.True(t, prod.AllowsFrom("staging"))
+	assert.False(t, prod.AllowsFrom("dev"))
+
+	qa := rules.Rule("qa")
+	assert.Equal(t, 1, qa.Approvals, "environments without a rule need one approval")
+	assert.True(t, qa.AllowsFrom("dev"))
+
+	assert.Error(t, PromotionRules{"prod env": {}}.Validate())
+	assert.Error(t, PromotionRules{"prod": {From: []string{"prod"}}}.Validate())
+	assert.Error(t, PromotionRules{"prod": {Approvals: -1}}.Validate())
+	assert.Error(t, PromotionRules{"prod": {Approvals: MaxPromotionApprovals + 1}}.Validate())
+}
+
+func TestPromotionApproved(t *testing.T) {
+	p := Promotion{ApprovalsRequired: 2, Approvals: PromotionApprovals{{UserID: "a"}}}
+	assert.False(t, p.Approved())
+	assert.True(t, p.Approvals.Includes("a"))
+	p.Approvals = append(p.Approvals, PromotionApproval{UserID: "b"})
+	assert.True(t, p.Approved())
+	assert.True(t, (&Promotion{}).Approved(), "no approvals required")
+}
@@ -0,0 +1,236 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Quality gate comparisons: the reported value against the threshold.
+const (
+	GateOpGTE = ">="
+	GateOpGT  = ">"
+	GateOpLTE = "<="
+	GateOpLT  = "<"
+	GateOpEQ  = "=="
+	GateOpNE  = "!="
+)
+
+// QualityGate is a threshold on a numeric output jobs report in the
+// "outputs" of their workflow output file, e.g. {"name": "coverage",
+// "output": "coverage_percent", "op": ">=", "threshold": 80}. A job whose
+// command exits 0 still fails if one of its project's gates does. Jobs
+// limits the gate to jobs whose names match one of its globs; a gate
+// without Jobs applies to every job of the project. A job that doesn't
+// report the output fails the gate unless it's Optional.
+type QualityGate struct {
+	Name      string   `json:"name"`
+	Output    string   `json:"output"`
+	Op        string   `json:"op"`
+	Threshold float64  `json:"threshold"`
+	Jobs      []string `json:"jobs,omitempty"`
+	Optional  bool     `json:"optional,omitempty"`
+}
+
+// AppliesTo reports whether the gate checks jobs named jobName.
+func (g *QualityGate) AppliesTo(jobName string) bool {
+	if len(g.Jobs) == 0 {
+		return true
+	}
+	for _, pattern := range g.Jobs {
+		if ok, _ := path.Match(pattern, jobName); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// QualityGates is a project's gates, stored in a jsonb column.
+type QualityGates []QualityGate
+
+// Value implements driver.Valuer interface for database storage.
+func (g QualityGates) Value() (driver.Value, error) {
+	if g == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(g)
+}
+
+// Scan implements sql.Scanner interface for database retrieval.
+func (g *QualityGates) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		*g = nil
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into QualityGates", value)
+	}
+	return json.Unmarshal(bytes, g)
+}
+
+// Validate checks every gate's name, output, comparison and job globs.
+// Gate names must be unique.
+func (g QualityGates) Validate() error {
+	seen := make(map[string]bool, len(g))
+	for i, gate := range g {
+		if strings.TrimSpace(gate.Name) == "" {
+			return fmt.Errorf("quality_gates[%d]: name is required", i)
+		}
+		if seen[gate.Name] {
+			return fmt.Errorf("quality_gates[%d]: duplicate gate %q", i, gate.Name)
+		}
+		seen[gate.Name] = true
+		if strings.TrimSpace(gate.Output) == "" {
+			return fmt.Errorf("quality_gates[%d]: output is required", i)
+		}
+		switch gate.Op {
+		case GateOpGTE, GateOpGT, GateOpLTE, GateOpLT, GateOpEQ, GateOpNE:
+		default:
+			return fmt.Errorf("quality_gates[%d]: op must be one of >=, >, <=, <, ==, !=", i)
+		}
+		for _, pattern := range gate.Jobs {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("quality_gates[%d]: invalid job pattern %q", i, pattern)
+			}
+		}
+	}
+	return nil
+}
+
+// GateResult is how a job's output fared against a quality gate. Value is
+// nil when the job didn't report a number for the output.
+type GateResult struct {
+	Name      string   `json:"name"`
+	Output    string   `json:"output"`
+	Op        string   `json:"op"`
+	Threshold float64  `json:"threshold"`
+	Value     *float64 `json:"value,omitempty"`
+	Passed    bool     `json:"passed"`
+	Message   string   `json:"message,omitempty"`
+}
+
+// String describes the result, e.g. "coverage: 72.5 >= 80 failed".
+func (r GateResult) String() string {
+	if r.Value == nil {
+		return fmt.Sprintf("%s: %s", r.Name, r.Message)
+	}
+	verdict := "passed"
+	if !r.Passed {
+		verdict = "failed"
+	}
+	return fmt.Sprintf("%s: %s %s %s %s", r.Name, formatGateNumber(*r.Value), r.Op, formatGateNumber(r.Threshold), verdict)
+}
+
+// GateResults is a job's gate results, stored in a jsonb column.
+type GateResults []GateResult
+
+// Value implements driver.Valuer interface for database storage.
+func (r GateResults) Value() (driver.Value, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return json.Marshal(r)
+}
+
+// Scan implements sql.Scanner interface for database retrieval.
+func (r *GateResults) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		*r = nil
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into GateResults", value)
+	}
+	return json.Unmarshal(bytes, r)
+}
+
+// Failed returns the results of the gates that failed.
+func (r GateResults) Failed() GateResults {
+	var failed GateResults
+	for _, result := range r {
+		if !result.Passed {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// EvaluateQualityGates checks the gates that apply to jobName against the
+// outputs the job reported. Outputs may be numbers or numeric strings.
+func EvaluateQualityGates(gates QualityGates, jobName string, outputs map[string]interface{}) GateResults {
+	var results GateResults
+	for i := range gates {
+		gate := &gates[i]
+		if !gate.AppliesTo(jobName) {
+			continue
+		}
+		result := GateResult{Name: gate.Name, Output: gate.Output, Op: gate.Op, Threshold: gate.Threshold}
+		raw, reported := outputs[gate.Output]
+		value, numeric := gateNumber(raw)
+		switch {
+		case !reported:
+			result.Passed = gate.Optional
+			result.Message = fmt.Sprintf("output %q not reported", gate.Output)
+		case !numeric:
+			result.Message = fmt.Sprintf("output %q is not a number", gate.Output)
+		default:
+			result.Value = &value
+			result.Passed = compareGate(value, gate.Op, gate.Threshold)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func compareGate(value float64, op string, threshold float64) bool {
+	switch op {
+	case GateOpGTE:
+		return value >= threshold
+	case GateOpGT:
+		return value > threshold
+	case GateOpLTE:
+		return value <= threshold
+	case GateOpLT:
+		return value < threshold
+	case GateOpEQ:
+		return value == threshold
+	case GateOpNE:
+		return value != threshold
+	}
+	return false
+}
+
+func gateNumber(raw interface{}) (float64, bool) {
+	switch v := raw.(type) {
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(v), "%"), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func formatGateNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
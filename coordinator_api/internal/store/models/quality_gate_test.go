@@ -0,0 +1,53 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQualityGates_Validate(t *testing.T) {
+	assert.NoError(t, QualityGates{
+		{Name: "coverage", Output: "coverage", Op: GateOpGTE, Threshold: 80},
+		{Name: "bundle", Output: "bundle_kb", Op: GateOpLTE, Threshold: 250, Jobs: []string{"web-*"}},
+	}.Validate())
+
+	for name, gates := range map[string]QualityGates{
+		"no name":   {{Output: "coverage", Op: GateOpGTE}},
+		"no output": {{Name: "coverage", Op: GateOpGTE}},
+		"bad op":    {{Name: "coverage", Output: "coverage", Op: "=>"}},
+		"bad glob":  {{Name: "coverage", Output: "coverage", Op: GateOpGTE, Jobs: []string{"["}}},
+		"duplicate": {{Name: "c", Output: "a", Op: GateOpGTE}, {Name: "c", Output: "b", Op: GateOpGTE}},
+	} {
+		assert.Error(t, gates.Validate(), name)
+	}
+}
+
+func TestEvaluateQualityGates(t *testing.T) {
+	gates := QualityGates{
+		{Name: "coverage", Output: "coverage", Op: GateOpGTE, Threshold: 80},
+		{Name: "bundle", Output: "bundle_kb", Op: GateOpLTE, Threshold: 250, Jobs: []string{"web-*"}},
+		{Name: "vulns", Output: "critical_vulns", Op: GateOpEQ, Threshold: 0},
+		{Name: "perf", Output: "p95_ms", Op: GateOpLT, Threshold: 300, Optional: true},
+	}
+
+	results := EvaluateQualityGates(gates, "api", map[string]interface{}{
+		"coverage":       "81.5%",
+		"critical_vulns": float64(2),
+	})
+	require.Len(t, results, 3, "the bundle gate only applies to web-* jobs")
+	assert.True(t, results[0].Passed)
+	assert.Equal(t, 81.5, *results[0].Value)
+	assert.False(t, results[1].Passed)
+	assert.Equal(t, "vulns: 2 == 0 failed", results[1].String())
+	assert.True(t, results[2].Passed, "an optional gate passes when its output isn't reported")
+	assert.Equal(t, GateResults{results[1]}, results.Failed())
+
+	results = EvaluateQualityGates(gates, "web-app", map[string]interface{}{"bundle_kb": "big"})
+	require.Len(t, results, 4)
+	assert.False(t, results[0].Passed, "a missing output fails")
+	assert.Equal(t, `coverage: output "coverage" not reported`, results[0].String())
+	assert.False(t, results[1].Passed, "a non-numeric output fails")
+	assert.Nil(t, results[1].Value)
+}
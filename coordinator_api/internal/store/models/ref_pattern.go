@@ -0,0 +1,110 @@
+package models
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Ref patterns select branches (Project.TargetBranches) and tags
+// (Project.TagPatterns). Each pattern is one of:
+//
+//	main            exact name
+//	release/*       glob (path.Match syntax; * does not cross "/")
+//	/^v\d+\.\d+$/   regular expression between slashes, matched against
+//	                the whole name
+//
+// A leading "!" negates a pattern. A name is selected when it matches at
+// least one positive pattern (or the list has only negative ones) and no
+// negative pattern. An empty list selects every name.
+
+// ValidateRefPatterns reports the first malformed pattern in patterns.
+func ValidateRefPatterns(patterns []string) error {
+	for _, p := range patterns {
+		if _, _, err := parseRefPattern(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MatchRefPatterns reports whether name is selected by patterns. Malformed
+// patterns never match.
+func MatchRefPatterns(patterns []string, name string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	matched, hasPositive := false, false
+	for _, p := range patterns {
+		negated, match, err := parseRefPattern(p)
+		if err != nil {
+			continue
+		}
+		if negated {
+			if match(name) {
+				return false
+			}
+			continue
+		}
+		hasPositive = true
+		if !matched && match(name) {
+			matched = true
+		}
+	}
+	return matched || !hasPositive
+}
+
+// parseRefPattern compiles one pattern into a matcher.
+func parseRefPattern(pattern string) (bool, func(string) bool, error) {
+	negated := strings.HasPrefix(pattern, "!")
+	body := strings.TrimPrefix(pattern, "!")
+	if body == "" {
+		return false, nil, fmt.Errorf("empty ref pattern %q", pattern)
+	}
+
+	if len(body) >= 2 && strings.HasPrefix(body, "/") && strings.HasSuffix(body, "/") {
+		re, err := regexp.Compile("^(?:" + body[1:len(body)-1] + ")$")
+		if err != nil {
+			return false, nil, fmt.Errorf("invalid ref pattern %q: %w", pattern, err)
+		}
+		return negated, re.MatchString, nil
+	}
+
+	if strings.ContainsAny(body, "*?[") {
+		if _, err := path.Match(body, ""); err != nil {
+			return false, nil, fmt.Errorf("invalid ref pattern %q: %w", pattern, err)
+		}
+		return negated, func(name string) bool {
+			ok, _ := path.Match(body, name)
+			return ok
+		}, nil
+	}
+
+	return negated, func(name string) bool { return name == body }, nil
+}
+
+// UnmatchableRefPattern returns why pattern, one of patterns, can select
+// no name, or "" if it may select one. It catches the usual mistakes: a
+// full ref ("refs/heads/main") where names are matched without their
+// refs/heads/ or refs/tags/ prefix, and an exact name a negative pattern
+// of the same list excludes. Negative and malformed patterns return "".
+func UnmatchableRefPattern(patterns []string, pattern string) string {
+	negated, _, err := parseRefPattern(pattern)
+	if err != nil || negated {
+		return ""
+	}
+	if strings.HasPrefix(pattern, "refs/") {
+		return "names are matched without their refs/heads/ or refs/tags/ prefix"
+	}
+	if strings.HasPrefix(pattern, "/") || strings.ContainsAny(pattern, "*?[") {
+		return ""
+	}
+	for _, other := range patterns {
+		excluded, match, err := parseRefPattern(other)
+		if err == nil && excluded && match(pattern) {
+			return fmt.Sprintf("%q excludes it", other)
+		}
+	}
+	return ""
+}
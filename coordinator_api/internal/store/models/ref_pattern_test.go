@@ -0,0 +1,56 @@
+package models
+
+import "testing"
+
+func TestMatchRefPatterns(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		ref      string
+		want     bool
+	}{
+		{"empty list allows all", nil, "anything", true},
+		{"exact match", []string{"main"}, "main", true},
+		{"exact mismatch", []string{"main"}, "Main", false},
+		{"glob", []string{"release/*"}, "release/1.2", true},
+		{"glob does not cross slashes", []string{"release/*"}, "release/1.2/hotfix", false},
+		{"regex", []string{`/v\d+\.\d+\.\d+/`}, "v1.0.0", true},
+		{"regex is anchored", []string{`/v\d+/`}, "v1-beta", false},
+		{"negative only", []string{"!wip/*"}, "main", true},
+		{"negative only excludes", []string{"!wip/*"}, "wip/x", false},
+		{"negative wins over positive", []string{"feature/*", "!feature/skip-ci"}, "feature/skip-ci", false},
+		{"positive with negative", []string{"feature/*", "!feature/skip-ci"}, "feature/login", true},
+		{"no positive match", []string{"main", "!wip"}, "develop", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchRefPatterns(tt.patterns, tt.ref); got != tt.want {
+				t.Errorf("MatchRefPatterns(%q, %q) = %v, want %v", tt.patterns, tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRefPatterns(t *testing.T) {
+	valid := []string{"main", "release/*", "!wip/*", `/^v\d+$/`, "v[0-9]*"}
+	if err := ValidateRefPatterns(valid); err != nil {
+		t.Errorf("ValidateRefPatterns(%q) = %v, want nil", valid, err)
+	}
+
+	for _, pattern := range []string{"", "!", "/(unclosed/", "release/[", "!/[/"} {
+		if err := ValidateRefPatterns([]string{pattern}); err == nil {
+			t.Errorf("ValidateRefPatterns(%q) = nil, want error", pattern)
+		}
+	}
+}
+
+func TestUnmatchableRefPattern(t *testing.T) {
+	patterns := []string{"refs/heads/main", "develop", "!develop", "release/*", "!release/old", `/v\d+/`, "!wip/*", "main"}
+	want := map[string]bool{"refs/heads/main": true, "develop": true}
+	for _, p := range patterns {
+		if got := UnmatchableRefPattern(patterns, p) != ""; got != want[p] {
+			t.Errorf("UnmatchableRefPattern(%q) unmatchable = %v, want %v", p, got, want[p])
+		}
+	}
+}
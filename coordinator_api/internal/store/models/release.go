@@ -0,0 +1,155 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Release statuses. A release is "pending" while its tag's pipeline runs,
+// "publishing" while a worker creates it in the VCS, then "published", or
+// "failed" when the pipeline or the publishing failed.
+const (
+	ReleasePending    = "pending"
+	ReleasePublishing = "publishing"
+	ReleasePublished  = "published"
+	ReleaseFailed     = "failed"
+)
+
+// ReleaseConfig is a project's release settings, stored in a jsonb column.
+// With Enabled, every tag_created event's pipeline that succeeds publishes
+// a VCS release for the tag, with a changelog since the previous release
+// and the artifacts of the pipeline's jobs whose names match one of
+// Artifacts (every artifact when empty). Draft releases aren't published
+// to the repository's readers until someone does it in the VCS.
+type ReleaseConfig struct {
+	Enabled   bool     `json:"enabled"`
+	Artifacts []string `json:"artifacts,omitempty"`
+	Draft     bool     `json:"draft,omitempty"`
+}
+
+// Value implements driver.Valuer interface for database storage.
+func (c ReleaseConfig) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+// Scan implements sql.Scanner interface for database retrieval.
+func (c *ReleaseConfig) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		*c = ReleaseConfig{}
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into ReleaseConfig", value)
+	}
+	return json.Unmarshal(bytes, c)
+}
+
+// Validate checks the artifact globs.
+func (c *ReleaseConfig) Validate() error {
+	for _, pattern := range c.Artifacts {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("release_config: invalid artifact pattern %q", pattern)
+		}
+	}
+	return nil
+}
+
+// IncludesArtifact reports whether the artifact named name (its path under
+// the job's artifacts) is attached to releases.
+func (c *ReleaseConfig) IncludesArtifact(name string) bool {
+	if len(c.Artifacts) == 0 {
+		return true
+	}
+	for _, pattern := range c.Artifacts {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(name)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+var prereleaseTagPattern = regexp.MustCompile(`^v?\d+(\.\d+)*-`)
+
+// IsPrereleaseTag reports whether tag is a semver prerelease, e.g.
+// "v2.0.0-rc.1", which is published as a prerelease.
+func IsPrereleaseTag(tag string) bool {
+	return prereleaseTagPattern.MatchString(tag)
+}
+
+// ReleaseArtifact is an artifact attached to a release.
+type ReleaseArtifact struct {
+	JobID string `json:"job_id"`
+	Name  string `json:"name"`
+	Size  int64  `json:"size"`
+	URL   string `json:"url,omitempty"`
+}
+
+// ReleaseArtifacts is a release's artifacts, stored in a jsonb column.
+type ReleaseArtifacts []ReleaseArtifact
+
+// Value implements driver.Valuer interface for database storage.
+func (a ReleaseArtifacts) Value() (driver.Value, error) {
+	if a == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(a)
+}
+
+// Scan implements sql.Scanner interface for database retrieval.
+func (a *ReleaseArtifacts) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into ReleaseArtifacts", value)
+	}
+	return json.Unmarshal(bytes, a)
+}
+
+// Release records a tag's release: the pipeline that built it (EvalJobID
+// and, once published, every job of the pipeline), its changelog, the
+// artifacts attached and the release in the VCS.
+type Release struct {
+	ReleaseID    string           `gorm:"primaryKey;type:uuid;default:generate_ulid()" json:"release_id"`
+	ProjectID    string           `gorm:"type:uuid;not null" json:"project_id"`
+	Tag          string           `gorm:"type:text;not null" json:"tag"`
+	CommitSHA    string           `gorm:"type:text;not null;default:''" json:"commit_sha"`
+	PreviousTag  string           `gorm:"type:text;not null;default:''" json:"previous_tag,omitempty"`
+	Provider     string           `gorm:"type:text;not null" json:"provider"`
+	Repo         string           `gorm:"type:text;not null" json:"repo"`
+	EvalJobID    string           `gorm:"type:uuid;not null" json:"eval_job_id"`
+	Status       string           `gorm:"type:text;not null;default:'pending'" json:"status"`
+	Changelog    string           `gorm:"type:text;not null;default:''" json:"changelog,omitempty"`
+	JobIDs       pq.StringArray   `gorm:"type:text[];not null;default:'{}'" json:"job_ids"`
+	Artifacts    ReleaseArtifacts `gorm:"type:jsonb;not null;default:'[]'" json:"artifacts"`
+	VCSReleaseID string           `gorm:"column:vcs_release_id;type:text;not null;default:''" json:"vcs_release_id,omitempty"`
+	URL          string           `gorm:"type:text;not null;default:''" json:"url,omitempty"`
+	LastError    string           `gorm:"type:text;not null;default:''" json:"last_error,omitempty"`
+	CreatedAt    time.Time        `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"created_at"`
+	PublishedAt  *time.Time       `json:"published_at,omitempty"`
+}
+
+// TableName specifies the table name for the model.
+func (Release) TableName() string {
+	return "releases"
+}
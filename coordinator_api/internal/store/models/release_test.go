@@ -0,0 +1,33 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReleaseConfig(t *testing.T) {
+	all := ReleaseConfig{Enabled: true}
+	assert.True(t, all.IncludesArtifact("dist/app.tar.gz"))
+
+	some := ReleaseConfig{Enabled: true, Artifacts: []string{"*.tar.gz", "docs/*.pdf"}}
+	assert.True(t, some.IncludesArtifact("dist/app.tar.gz"), "globs match the file name too")
+	assert.True(t, some.IncludesArtifact("docs/manual.pdf"))
+	assert.False(t, some.IncludesArtifact("coverage.txt"))
+
+	assert.NoError(t, some.Validate())
+	assert.Error(t, (&ReleaseConfig{Artifacts: []string{"["}}).Validate())
+}
+
+func TestIsPrereleaseTag(t *testing.T) {
+	for tag, want := range map[string]bool{
+		"v1.2.0":        false,
+		"1.2.0":         false,
+		"v2.0.0-rc.1":   true,
+		"3.0.0-beta":    true,
+		"release-2024":  false,
+		"nightly-build": false,
+	} {
+		assert.Equal(t, want, IsPrereleaseTag(tag), tag)
+	}
+}
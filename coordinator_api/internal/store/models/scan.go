@@ -0,0 +1,324 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Scanners a scan job can run.
+const (
+	ScannerTrivy = "trivy"
+	ScannerGrype = "grype"
+)
+
+// Scan targets: a container image, or the source in the job's code_dir.
+const (
+	ScanTargetImage  = "image"
+	ScanTargetSource = "source"
+)
+
+// Finding severities, most severe first. Scanners' other severities
+// (e.g. grype's "Negligible") are normalized to these.
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+	SeverityLow      = "low"
+	SeverityUnknown  = "unknown"
+)
+
+// Severities lists the finding severities, most severe first.
+var Severities = []string{SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow, SeverityUnknown}
+
+// ScanReportFile is where, in the job's workspace (/job in the container),
+// a scan job's scanner writes its JSON report.
+const ScanReportFile = "scan-report.json"
+
+var scanImagePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/:@-]*$`)
+
+// ScanSpec makes a job a built-in scan job: it runs Scanner against Image
+// or the job's source, and its findings are stored and checked against
+// its project's ScanThresholds. See worker.ApplyScanSpec.
+type ScanSpec struct {
+	Scanner string `json:"scanner" yaml:"scanner"`
+	Target  string `json:"target" yaml:"target"`
+	Image   string `json:"image,omitempty" yaml:"image"`
+}
+
+// Validate checks the scanner, the target and, for image scans, the image
+// reference.
+func (s *ScanSpec) Validate() error {
+	switch s.Scanner {
+	case ScannerTrivy, ScannerGrype:
+	default:
+		return fmt.Errorf("scanner must be %q or %q", ScannerTrivy, ScannerGrype)
+	}
+	switch s.Target {
+	case ScanTargetImage:
+		if !scanImagePattern.MatchString(s.Image) {
+			return fmt.Errorf("an image scan needs a valid image reference")
+		}
+	case ScanTargetSource:
+		if s.Image != "" {
+			return fmt.Errorf("a source scan doesn't take an image")
+		}
+	default:
+		return fmt.Errorf("target must be %q or %q", ScanTargetImage, ScanTargetSource)
+	}
+	return nil
+}
+
+// Command is the scanner command line, writing its report to
+// ScanReportFile. A source scan scans codeDir, which nothing checks out in
+// the scanner's image; a trivy source scan of a job with a git source
+// (fromRepo) clones it itself from the REACTORCIDE_SOURCE_URL and
+// REACTORCIDE_SOURCE_REF the worker sets.
+func (s *ScanSpec) Command(codeDir string, fromRepo bool) string {
+	report := "/job/" + ScanReportFile
+	switch s.Scanner {
+	case ScannerGrype:
+		subject := s.Image
+		if s.Target == ScanTargetSource {
+			subject = "dir:" + codeDir
+		}
+		return fmt.Sprintf("grype %s --quiet --output json --file %s", subject, report)
+	default:
+		if s.Target == ScanTargetSource && fromRepo {
+			return fmt.Sprintf(`trivy repo --quiet --format json --output %s ${REACTORCIDE_SOURCE_REF:+--commit "$REACTORCIDE_SOURCE_REF"} "$REACTORCIDE_SOURCE_URL"`, report)
+		}
+		if s.Target == ScanTargetSource {
+			return fmt.Sprintf("trivy fs --quiet --format json --output %s %s", report, codeDir)
+		}
+		return fmt.Sprintf("trivy image --quiet --format json --output %s %s", report, s.Image)
+	}
+}
+
+// Value implements driver.Valuer interface for database storage.
+func (s ScanSpec) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// Scan implements sql.Scanner interface for database retrieval.
+func (s *ScanSpec) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into ScanSpec", value)
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+// ScanFinding is a vulnerability a scan job found, normalized across
+// scanners.
+type ScanFinding struct {
+	FindingID        string    `gorm:"primaryKey;type:uuid;default:generate_ulid()" json:"finding_id"`
+	JobID            string    `gorm:"type:uuid;not null" json:"job_id"`
+	VulnerabilityID  string    `gorm:"type:text;not null" json:"vulnerability_id"`
+	Severity         string    `gorm:"type:text;not null" json:"severity"`
+	Package          string    `gorm:"type:text;not null;default:''" json:"package"`
+	InstalledVersion string    `gorm:"type:text;not null;default:''" json:"installed_version,omitempty"`
+	FixedVersion     string    `gorm:"type:text;not null;default:''" json:"fixed_version,omitempty"`
+	Target           string    `gorm:"type:text;not null;default:''" json:"target,omitempty"`
+	Title            string    `gorm:"type:text;not null;default:''" json:"title,omitempty"`
+	CreatedAt        time.Time `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"created_at"`
+}
+
+// TableName specifies the table name for the model.
+func (ScanFinding) TableName() string {
+	return "scan_findings"
+}
+
+// NormalizeSeverity maps a scanner's severity to one of Severities.
+func NormalizeSeverity(severity string) string {
+	switch s := strings.ToLower(strings.TrimSpace(severity)); s {
+	case SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow:
+		return s
+	case "negligible":
+		return SeverityLow
+	}
+	return SeverityUnknown
+}
+
+// ParseScanReport normalizes scanner's JSON report into findings, most
+// severe first.
+func ParseScanReport(scanner string, data []byte) ([]ScanFinding, error) {
+	var findings []ScanFinding
+	switch scanner {
+	case ScannerTrivy:
+		var report struct {
+			Results []struct {
+				Target          string `json:"Target"`
+				Vulnerabilities []struct {
+					VulnerabilityID  string `json:"VulnerabilityID"`
+					PkgName          string `json:"PkgName"`
+					InstalledVersion string `json:"InstalledVersion"`
+					FixedVersion     string `json:"FixedVersion"`
+					Severity         string `json:"Severity"`
+					Title            string `json:"Title"`
+				} `json:"Vulnerabilities"`
+			} `json:"Results"`
+		}
+		if err := json.Unmarshal(data, &report); err != nil {
+			return nil, fmt.Errorf("parse trivy report: %w", err)
+		}
+		for _, result := range report.Results {
+			for _, v := range result.Vulnerabilities {
+				findings = append(findings, ScanFinding{
+					VulnerabilityID:  v.VulnerabilityID,
+					Severity:         NormalizeSeverity(v.Severity),
+					Package:          v.PkgName,
+					InstalledVersion: v.InstalledVersion,
+					FixedVersion:     v.FixedVersion,
+					Target:           result.Target,
+					Title:            v.Title,
+				})
+			}
+		}
+	case ScannerGrype:
+		var report struct {
+			Matches []struct {
+				Vulnerability struct {
+					ID          string `json:"id"`
+					Severity    string `json:"severity"`
+					Description string `json:"description"`
+					Fix         struct {
+						Versions []string `json:"versions"`
+					} `json:"fix"`
+				} `json:"vulnerability"`
+				Artifact struct {
+					Name      string `json:"name"`
+					Version   string `json:"version"`
+					Locations []struct {
+						Path string `json:"path"`
+					} `json:"locations"`
+				} `json:"artifact"`
+			} `json:"matches"`
+		}
+		if err := json.Unmarshal(data, &report); err != nil {
+			return nil, fmt.Errorf("parse grype report: %w", err)
+		}
+		for _, m := range report.Matches {
+			finding := ScanFinding{
+				VulnerabilityID:  m.Vulnerability.ID,
+				Severity:         NormalizeSeverity(m.Vulnerability.Severity),
+				Package:          m.Artifact.Name,
+				InstalledVersion: m.Artifact.Version,
+				FixedVersion:     strings.Join(m.Vulnerability.Fix.Versions, ", "),
+				Title:            m.Vulnerability.Description,
+			}
+			if len(m.Artifact.Locations) > 0 {
+				finding.Target = m.Artifact.Locations[0].Path
+			}
+			findings = append(findings, finding)
+		}
+	default:
+		return nil, fmt.Errorf("unknown scanner %q", scanner)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return severityRank(findings[i].Severity) < severityRank(findings[j].Severity)
+	})
+	return findings, nil
+}
+
+// CountFindings counts findings by severity, with every severity present.
+func CountFindings(findings []ScanFinding) map[string]int {
+	counts := make(map[string]int, len(Severities))
+	for _, severity := range Severities {
+		counts[severity] = 0
+	}
+	for _, f := range findings {
+		counts[f.Severity]++
+	}
+	return counts
+}
+
+// ScanOutputName is the output a scan job reports its count of findings
+// of severity under, for quality gates, e.g. "vulns_critical".
+func ScanOutputName(severity string) string {
+	return "vulns_" + severity
+}
+
+// ScanThresholds is the most findings of each severity a project's scan
+// jobs may have, e.g. {"critical": 0, "high": 5}, stored in a jsonb
+// column. Severities not listed aren't limited.
+type ScanThresholds map[string]int
+
+// Value implements driver.Valuer interface for database storage.
+func (t ScanThresholds) Value() (driver.Value, error) {
+	if t == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(map[string]int(t))
+}
+
+// Scan implements sql.Scanner interface for database retrieval.
+func (t *ScanThresholds) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into ScanThresholds", value)
+	}
+	return json.Unmarshal(bytes, t)
+}
+
+// Validate checks the severities and that the limits aren't negative.
+func (t ScanThresholds) Validate() error {
+	for severity, max := range t {
+		if severityRank(severity) < 0 {
+			return fmt.Errorf("scan_thresholds: unknown severity %q", severity)
+		}
+		if max < 0 {
+			return fmt.Errorf("scan_thresholds: %s must not be negative", severity)
+		}
+	}
+	return nil
+}
+
+// Evaluate checks counts, from CountFindings, against the thresholds, as
+// gate results named "scan <severity>".
+func (t ScanThresholds) Evaluate(counts map[string]int) GateResults {
+	var results GateResults
+	for _, severity := range Severities {
+		max, ok := t[severity]
+		if !ok {
+			continue
+		}
+		value := float64(counts[severity])
+		results = append(results, GateResult{
+			Name:      "scan " + severity,
+			Output:    ScanOutputName(severity),
+			Op:        GateOpLTE,
+			Threshold: float64(max),
+			Value:     &value,
+			Passed:    counts[severity] <= max,
+		})
+	}
+	return results
+}
+
+func severityRank(severity string) int {
+	for i, s := range Severities {
+		if s == severity {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanSpec_Validate(t *testing.T) {
+	assert.NoError(t, (&ScanSpec{Scanner: ScannerTrivy, Target: ScanTargetImage, Image: "registry.example.com/app:1.4@sha256:abc"}).Validate())
+	assert.NoError(t, (&ScanSpec{Scanner: ScannerGrype, Target: ScanTargetSource}).Validate())
+
+	for name, spec := range map[string]ScanSpec{
+		"unknown scanner":     {Scanner: "clair", Target: ScanTargetSource},
+		"unknown target":      {Scanner: ScannerTrivy, Target: "repo"},
+		"image scan no image": {Scanner: ScannerTrivy, Target: ScanTargetImage},
+		"image with a space":  {Scanner: ScannerTrivy, Target: ScanTargetImage, Image: "app; rm -rf /"},
+		"source with image":   {Scanner: ScannerTrivy, Target: ScanTargetSource, Image: "app"},
+	} {
+		assert.Error(t, spec.Validate(), name)
+	}
+}
+
+func TestScanSpec_Command(t *testing.T) {
+	image := ScanSpec{Scanner: ScannerTrivy, Target: ScanTargetImage, Image: "app:1"}
+	assert.Equal(t, "trivy image --quiet --format json --output /job/scan-report.json app:1", image.Command("/job/src", true))
+
+	source := ScanSpec{Scanner: ScannerTrivy, Target: ScanTargetSource}
+	assert.Equal(t, "trivy fs --quiet --format json --output /job/scan-report.json /job/src", source.Command("/job/src", false))
+	assert.Contains(t, source.Command("/job/src", true), `trivy repo`)
+	assert.Contains(t, source.Command("/job/src", true), `"$REACTORCIDE_SOURCE_URL"`)
+
+	grype := ScanSpec{Scanner: ScannerGrype, Target: ScanTargetSource}
+	assert.Equal(t, "grype dir:/job/src --quiet --output json --file /job/scan-report.json", grype.Command("/job/src", true))
+}
+
+func TestParseScanReport(t *testing.T) {
+	trivy := []byte(`{"Results":[
+		{"Target":"app (alpine 3.19)","Vulnerabilities":[
+			{"VulnerabilityID":"CVE-2024-1","PkgName":"openssl","InstalledVersion":"3.1.0","FixedVersion":"3.1.5","Severity":"MEDIUM","Title":"t1"},
+			{"VulnerabilityID":"CVE-2024-2","PkgName":"zlib","InstalledVersion":"1.2","Severity":"CRITICAL"}
+		]},
+		{"Target":"go.mod"}
+	]}`)
+	findings, err := ParseScanReport(ScannerTrivy, trivy)
+	require.NoError(t, err)
+	require.Len(t, findings, 2)
+	assert.Equal(t, "CVE-2024-2", findings[0].VulnerabilityID, "most severe first")
+	assert.Equal(t, SeverityCritical, findings[0].Severity)
+	assert.Equal(t, "3.1.5", findings[1].FixedVersion)
+	assert.Equal(t, "app (alpine 3.19)", findings[1].Target)
+
+	grype := []byte(`{"matches":[
+		{"vulnerability":{"id":"GHSA-1","severity":"Negligible","fix":{"versions":["2.0.1","1.9.9"]}},"artifact":{"name":"lodash","version":"1.0","locations":[{"path":"/package-lock.json"}]}},
+		{"vulnerability":{"id":"GHSA-2","severity":"High"},"artifact":{"name":"left-pad","version":"0.1"}}
+	]}`)
+	findings, err = ParseScanReport(ScannerGrype, grype)
+	require.NoError(t, err)
+	require.Len(t, findings, 2)
+	assert.Equal(t, SeverityHigh, findings[0].Severity)
+	assert.Equal(t, SeverityLow, findings[1].Severity, "negligible counts as low")
+	assert.Equal(t, "2.0.1, 1.9.9", findings[1].FixedVersion)
+	assert.Equal(t, "/package-lock.json", findings[1].Target)
+
+	counts := CountFindings(findings)
+	assert.Equal(t, map[string]int{"critical": 0, "high": 1, "medium": 0, "low": 1, "unknown": 0}, counts)
+
+	_, err = ParseScanReport(ScannerTrivy, []byte("not json"))
+	assert.Error(t, err)
+	_, err = ParseScanReport("clair", trivy)
+	assert.Error(t, err)
+}
+
+func TestScanThresholds(t *testing.T) {
+	assert.NoError(t, ScanThresholds{"critical": 0, "high": 5}.Validate())
+	assert.Error(t, ScanThresholds{"severe": 0}.Validate())
+	assert.Error(t, ScanThresholds{"high": -1}.Validate())
+
+	results := ScanThresholds{"critical": 0, "high": 5}.Evaluate(map[string]int{"critical": 1, "high": 5, "low": 40})
+	require.Len(t, results, 2)
+	assert.Equal(t, "scan critical: 1 <= 0 failed", results[0].String())
+	assert.True(t, results[1].Passed)
+	assert.Len(t, results.Failed(), 1)
+
+	var scanned ScanThresholds
+	require.NoError(t, scanned.Scan([]byte(`{"high":2}`)))
+	assert.Equal(t, ScanThresholds{"high": 2}, scanned)
+}
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// SCIMScope is the API token scope the SCIM endpoints require, on top of
+// the admin role, so an identity provider's token can't be used for
+// anything else an admin could do.
+const SCIMScope = "scim"
+
+// SCIMUser links a user to the identity provider that provisions it over
+// SCIM. Whether the user is active lives on User.DeactivatedAt.
+type SCIMUser struct {
+	UserID string `gorm:"primaryKey;type:uuid" json:"user_id"`
+	// UserName is the SCIM userName, lowercased: SCIM compares it
+	// case-insensitively. SSO logins whose "handle@domain" equals it are
+	// linked to this user.
+	UserName    string    `gorm:"type:text;not null" json:"user_name"`
+	ExternalID  string    `gorm:"type:text;not null;default:''" json:"external_id"`
+	DisplayName string    `gorm:"type:text;not null;default:''" json:"display_name"`
+	GivenName   string    `gorm:"type:text;not null;default:''" json:"given_name"`
+	FamilyName  string    `gorm:"type:text;not null;default:''" json:"family_name"`
+	CreatedAt   time.Time `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime:false;default:timezone('utc', now())" json:"updated_at"`
+}
+
+// TableName specifies the table name for the model
+func (SCIMUser) TableName() string {
+	return "scim_users"
+}
+
+// SCIMGroup is a group an identity provider pushes over SCIM. Its members
+// get the user roles its display name maps to (see auth.ParseSCIMGroupRoles).
+type SCIMGroup struct {
+	GroupID     string    `gorm:"primaryKey;type:uuid;default:generate_ulid()" json:"group_id"`
+	DisplayName string    `gorm:"type:text;not null" json:"display_name"`
+	ExternalID  string    `gorm:"type:text;not null;default:''" json:"external_id"`
+	CreatedAt   time.Time `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime:false;default:timezone('utc', now())" json:"updated_at"`
+}
+
+// TableName specifies the table name for the model
+func (SCIMGroup) TableName() string {
+	return "scim_groups"
+}
+
+// SCIMGroupMember is a SCIM user's membership of a SCIM group.
+type SCIMGroupMember struct {
+	GroupID string `gorm:"primaryKey;type:uuid" json:"group_id"`
+	UserID  string `gorm:"primaryKey;type:uuid" json:"user_id"`
+}
+
+// TableName specifies the table name for the model
+func (SCIMGroupMember) TableName() string {
+	return "scim_group_members"
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Secret access levels. Write implies read.
+const (
+	SecretAccessNone  = "none"
+	SecretAccessRead  = "read"
+	SecretAccessWrite = "write"
+)
+
+// Secret access policy subject types.
+const (
+	SecretSubjectUser       = "user"
+	SecretSubjectRole       = "role"
+	SecretSubjectTokenScope = "token_scope"
+)
+
+// SecretAccessPolicy grants a subject (a user ID, a user role, or an API
+// token scope) read, write or no access to the org's secret paths matching
+// PathPattern: a path, a path followed by /* for it and everything below,
+// or * for every path.
+type SecretAccessPolicy struct {
+	PolicyID    string    `gorm:"primaryKey;type:uuid;default:generate_ulid()" json:"policy_id"`
+	CreatedAt   time.Time `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime:false;default:timezone('utc', now())" json:"updated_at"`
+	OrgID       string    `gorm:"type:uuid;not null" json:"org_id"`
+	SubjectType string    `gorm:"type:text;not null" json:"subject_type"`
+	Subject     string    `gorm:"type:text;not null" json:"subject"`
+	PathPattern string    `gorm:"type:text;not null" json:"path_pattern"`
+	Access      string    `gorm:"type:text;not null" json:"access"`
+	Description string    `gorm:"type:text;not null;default:''" json:"description,omitempty"`
+}
+
+// TableName specifies the table name for the model.
+func (SecretAccessPolicy) TableName() string {
+	return "secret_access_policies"
+}
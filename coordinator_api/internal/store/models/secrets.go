@@ -16,6 +16,10 @@ type MasterKey struct {
 	// KeyMaterial stores the 32-byte key for auto-generated keys.
 	// NULL for env-var-provided keys (those keys live only in the environment).
 	KeyMaterial []byte `gorm:"type:bytea" json:"-"`
+	// Canary is a known value encrypted with the key, checked by the master
+	// key health check. NULL for keys registered before canaries existed,
+	// until the health check backfills it.
+	Canary []byte `gorm:"type:bytea" json:"-"`
 }
 
 // TableName specifies the table name for the model
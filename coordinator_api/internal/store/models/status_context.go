@@ -0,0 +1,140 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// EvalStatusKey is the status key of a project's eval jobs.
+const EvalStatusKey = "eval"
+
+// MaxStatusContextLength caps a status context template and the contexts
+// in status context aliases.
+const MaxStatusContextLength = 255
+
+// statusContextPlaceholder matches the placeholders of a status context
+// template.
+var statusContextPlaceholder = regexp.MustCompile(`\{[^{}]*\}`)
+
+// ValidateStatusContextTemplate checks a project's status_context_template:
+// it must name each job with {job}, and may also use {project}.
+func ValidateStatusContextTemplate(template string) error {
+	if strings.TrimSpace(template) != template {
+		return fmt.Errorf("status_context_template must not start or end with whitespace")
+	}
+	if len(template) > MaxStatusContextLength {
+		return fmt.Errorf("status_context_template must be at most %d characters", MaxStatusContextLength)
+	}
+	for _, placeholder := range statusContextPlaceholder.FindAllString(template, -1) {
+		if placeholder != "{job}" && placeholder != "{project}" {
+			return fmt.Errorf("status_context_template: unknown placeholder %s", placeholder)
+		}
+	}
+	if !strings.Contains(template, "{job}") {
+		return fmt.Errorf("status_context_template must contain {job}")
+	}
+	return nil
+}
+
+// RenderStatusContext fills in a status context template for the job of
+// project with the given status key.
+func RenderStatusContext(template string, project *Project, key string) string {
+	return strings.NewReplacer("{project}", project.Name, "{job}", key).Replace(template)
+}
+
+// StatusContext is the commit status context of the project's job with the
+// given status key: the project's StatusContextTemplate rendered for it,
+// or fallback when the project has none.
+func (p *Project) StatusContext(key, fallback string) string {
+	if p == nil || p.StatusContextTemplate == "" {
+		return fallback
+	}
+	return RenderStatusContext(p.StatusContextTemplate, p, key)
+}
+
+// StatusContextAliases maps an old commit status context to the context
+// that replaced it, stored in a jsonb column. A job reporting the new
+// context reports the old one too, so branch protection rules requiring
+// it keep passing until they're moved over.
+type StatusContextAliases map[string]string
+
+// For returns the old contexts aliasing context, sorted.
+func (a StatusContextAliases) For(context string) []string {
+	var old []string
+	for from, to := range a {
+		if to == context {
+			old = append(old, from)
+		}
+	}
+	sort.Strings(old)
+	return old
+}
+
+// Value implements driver.Valuer interface for database storage.
+func (a StatusContextAliases) Value() (driver.Value, error) {
+	if a == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(map[string]string(a))
+}
+
+// Scan implements sql.Scanner interface for database retrieval.
+func (a *StatusContextAliases) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into StatusContextAliases", value)
+	}
+	return json.Unmarshal(bytes, a)
+}
+
+// Validate checks the aliases' contexts.
+func (a StatusContextAliases) Validate() error {
+	for from, to := range a {
+		switch {
+		case from == "" || to == "":
+			return fmt.Errorf("status_context_aliases: contexts must not be empty")
+		case len(from) > MaxStatusContextLength || len(to) > MaxStatusContextLength:
+			return fmt.Errorf("status_context_aliases: contexts must be at most %d characters", MaxStatusContextLength)
+		case from == to:
+			return fmt.Errorf("status_context_aliases.%s: can't alias a context to itself", from)
+		}
+	}
+	return nil
+}
+
+// Migrate records that the contexts in renames, old to new, have been
+// replaced. Aliases of a renamed context follow it to its new name, and
+// an alias of a context that's now in use again is dropped.
+func (a StatusContextAliases) Migrate(renames map[string]string) StatusContextAliases {
+	migrated := StatusContextAliases{}
+	for from, to := range a {
+		if renamed, ok := renames[to]; ok {
+			to = renamed
+		}
+		migrated[from] = to
+	}
+	for from, to := range renames {
+		migrated[from] = to
+	}
+	for from, to := range migrated {
+		if from == to {
+			delete(migrated, from)
+		}
+	}
+	for _, to := range renames {
+		delete(migrated, to)
+	}
+	return migrated
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateStatusContextTemplate(t *testing.T) {
+	assert.NoError(t, ValidateStatusContextTemplate("reactorcide/{project}/{job}"))
+	assert.NoError(t, ValidateStatusContextTemplate("ci: {job}"))
+
+	assert.Error(t, ValidateStatusContextTemplate("reactorcide/{project}"), "must name the job")
+	assert.Error(t, ValidateStatusContextTemplate("reactorcide/{branch}/{job}"))
+	assert.Error(t, ValidateStatusContextTemplate(" {job}"))
+	assert.Error(t, ValidateStatusContextTemplate(strings.Repeat("x", MaxStatusContextLength)+"{job}"))
+}
+
+func TestProjectStatusContext(t *testing.T) {
+	project := &Project{Name: "api"}
+	assert.Equal(t, "build", project.StatusContext("build", "build"), "no template keeps the fallback")
+
+	project.StatusContextTemplate = "reactorcide/{project}/{job}"
+	assert.Equal(t, "reactorcide/api/build", project.StatusContext("build", "build"))
+	assert.Equal(t, "reactorcide/api/eval", project.StatusContext(EvalStatusKey, "reactorcide/eval"))
+
+	var none *Project
+	assert.Equal(t, "reactorcide/eval", none.StatusContext(EvalStatusKey, "reactorcide/eval"))
+}
+
+func TestStatusContextAliases(t *testing.T) {
+	aliases := StatusContextAliases{"build": "ci/build", "compile": "ci/build", "test": "ci/test"}
+	assert.NoError(t, aliases.Validate())
+	assert.Equal(t, []string{"build", "compile"}, aliases.For("ci/build"))
+	assert.Empty(t, aliases.For("build"))
+
+	assert.Error(t, StatusContextAliases{"build": "build"}.Validate())
+	assert.Error(t, StatusContextAliases{"": "build"}.Validate())
+
+	migrated := aliases.Migrate(map[string]string{"ci/build": "reactorcide/build", "ci/test": "test"})
+	assert.Equal(t, StatusContextAliases{
+		"build":    "reactorcide/build",
+		"compile":  "reactorcide/build",
+		"ci/build": "reactorcide/build",
+		"ci/test":  "test",
+	}, migrated, "aliases follow renames, and a context back in use isn't an alias")
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Stuck-job actions, in increasing order of severity. StuckJobActionOff is
+// only meaningful as a policy value; reports never carry it.
+const (
+	StuckJobActionOff       = "off"
+	StuckJobActionWarn      = "warn"
+	StuckJobActionKillRetry = "kill_retry"
+	StuckJobActionKillFail  = "kill_fail"
+)
+
+// Reasons a running job is reported as stuck.
+const (
+	StuckReasonExceededP95 = "exceeded_p95"
+	StuckReasonNoLogOutput = "no_log_output"
+)
+
+// IsValidStuckJobAction reports whether action is a known policy action.
+func IsValidStuckJobAction(action string) bool {
+	switch action {
+	case StuckJobActionOff, StuckJobActionWarn, StuckJobActionKillRetry, StuckJobActionKillFail:
+		return true
+	}
+	return false
+}
+
+// StuckJobPolicy decides when a running job counts as stuck and what to do
+// about it. A zero NoLogAfter or P95Multiplier disables that check.
+type StuckJobPolicy struct {
+	Action        string
+	NoLogAfter    time.Duration
+	P95Multiplier float64
+}
+
+// ForProject returns p with project's overrides applied. project may be nil.
+func (p StuckJobPolicy) ForProject(project *Project) StuckJobPolicy {
+	if project == nil {
+		return p
+	}
+	if project.StuckJobAction != nil {
+		p.Action = *project.StuckJobAction
+	}
+	if project.StuckJobNoLogMinutes != nil {
+		p.NoLogAfter = time.Duration(*project.StuckJobNoLogMinutes) * time.Minute
+	}
+	if project.StuckJobP95Multiplier != nil {
+		p.P95Multiplier = *project.StuckJobP95Multiplier
+	}
+	return p
+}
+
+// Enabled reports whether the policy checks anything at all.
+func (p StuckJobPolicy) Enabled() bool {
+	return p.Action != "" && p.Action != StuckJobActionOff && (p.NoLogAfter > 0 || p.P95Multiplier > 0)
+}
+
+// Evaluate checks a running job against the policy at now. p95 is the
+// historical p95 run time of the job's name, or zero when there isn't
+// enough history. Returns the reason and a human-readable detail, or an
+// empty reason when the job isn't stuck.
+func (p StuckJobPolicy) Evaluate(job *Job, p95 time.Duration, now time.Time) (reason, detail string) {
+	if job.StartedAt == nil {
+		return "", ""
+	}
+	runtime := now.Sub(*job.StartedAt)
+
+	if p.P95Multiplier > 0 && p95 > 0 {
+		limit := time.Duration(float64(p95) * p.P95Multiplier)
+		if runtime > limit {
+			return StuckReasonExceededP95, fmt.Sprintf("running for %s, %.1fx the historical p95 of %s",
+				runtime.Round(time.Second), p.P95Multiplier, p95.Round(time.Second))
+		}
+	}
+
+	if p.NoLogAfter > 0 {
+		lastOutput := *job.StartedAt
+		if job.LastLogAt != nil && job.LastLogAt.After(lastOutput) {
+			lastOutput = *job.LastLogAt
+		}
+		if silent := now.Sub(lastOutput); silent > p.NoLogAfter {
+			return StuckReasonNoLogOutput, fmt.Sprintf("no log output for %s", silent.Round(time.Second))
+		}
+	}
+	return "", ""
+}
+
+// StuckJobReport records a job the stuck-job monitor flagged and what it
+// did about it. For the kill actions, RemediatedAt is set once the killed
+// job has been retried (RetryJobID) or failed; for warn, at detection.
+type StuckJobReport struct {
+	JobID        string     `gorm:"primaryKey;type:uuid" json:"job_id"`
+	ProjectID    *string    `gorm:"type:uuid" json:"project_id,omitempty"`
+	Reason       string     `gorm:"type:text;not null" json:"reason"`
+	Action       string     `gorm:"type:text;not null" json:"action"`
+	Detail       string     `gorm:"type:text;not null;default:''" json:"detail"`
+	DetectedAt   time.Time  `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"detected_at"`
+	RemediatedAt *time.Time `json:"remediated_at,omitempty"`
+	RetryJobID   *string    `gorm:"type:uuid" json:"retry_job_id,omitempty"`
+	LastError    string     `gorm:"type:text;not null;default:''" json:"last_error,omitempty"`
+
+	Job *Job `gorm:"foreignKey:JobID" json:"job,omitempty"`
+}
+
+// TableName specifies the table name for the model.
+func (StuckJobReport) TableName() string {
+	return "stuck_job_reports"
+}
@@ -0,0 +1,170 @@
+package models
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Test outcomes in a test report.
+const (
+	TestPassed  = "passed"
+	TestFailed  = "failed"
+	TestSkipped = "skipped"
+)
+
+// TestReportDir is where, in the job's workspace (/job in the container),
+// a job writes JUnit XML test reports (*.xml) for its project's test
+// quarantine and pass rates.
+const TestReportDir = "test-reports"
+
+// MaxTestQuarantine is how far ahead a test quarantine may expire, and
+// DefaultTestQuarantine when one expires if not given. Quarantines expire
+// so flaky tests still get fixed.
+const (
+	MaxTestQuarantine     = 90 * 24 * time.Hour
+	DefaultTestQuarantine = 14 * 24 * time.Hour
+)
+
+// MaxTestNameLength caps a test's name, as reported or quarantined.
+const MaxTestNameLength = 1000
+
+// TestResult is one test's outcome in a job's test reports.
+type TestResult struct {
+	ResultID  string    `gorm:"primaryKey;type:uuid;default:generate_ulid()" json:"-"`
+	JobID     string    `gorm:"type:uuid;not null" json:"job_id"`
+	ProjectID string    `gorm:"type:uuid;not null" json:"project_id"`
+	TestName  string    `gorm:"type:text;not null" json:"test_name"`
+	Status    string    `gorm:"type:text;not null" json:"status"`
+	CreatedAt time.Time `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"created_at"`
+}
+
+// TableName specifies the table name for the model.
+func (TestResult) TableName() string {
+	return "test_results"
+}
+
+// TestPassRate is how a test fared over its recent runs. Skipped runs
+// aren't counted.
+type TestPassRate struct {
+	Runs   int `json:"runs"`
+	Passed int `json:"passed"`
+}
+
+// Rate returns the share of runs that passed, or nil without runs.
+func (r TestPassRate) Rate() *float64 {
+	if r.Runs == 0 {
+		return nil
+	}
+	rate := float64(r.Passed) / float64(r.Runs)
+	return &rate
+}
+
+// TestQuarantine is a test of a project whose failures don't fail the
+// project's jobs until ExpiresAt. TestName is as in TestResult: the JUnit
+// classname and name joined by a dot.
+type TestQuarantine struct {
+	QuarantineID string    `gorm:"primaryKey;type:uuid;default:generate_ulid()" json:"quarantine_id"`
+	ProjectID    string    `gorm:"type:uuid;not null" json:"project_id"`
+	TestName     string    `gorm:"type:text;not null" json:"test_name"`
+	Reason       string    `gorm:"type:text;not null;default:''" json:"reason,omitempty"`
+	ExpiresAt    time.Time `gorm:"not null" json:"expires_at"`
+	CreatedBy    *string   `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt    time.Time `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime:false;default:timezone('utc', now())" json:"updated_at"`
+}
+
+// TableName specifies the table name for the model.
+func (TestQuarantine) TableName() string {
+	return "test_quarantines"
+}
+
+// ActiveAt reports whether the quarantine still holds at now.
+func (q *TestQuarantine) ActiveAt(now time.Time) bool {
+	return now.Before(q.ExpiresAt)
+}
+
+// Validate checks the test name and that the quarantine expires, but not
+// more than MaxTestQuarantine after now.
+func (q *TestQuarantine) Validate(now time.Time) error {
+	if strings.TrimSpace(q.TestName) == "" {
+		return errors.New("test is required")
+	}
+	if len(q.TestName) > MaxTestNameLength {
+		return fmt.Errorf("test is longer than %d bytes", MaxTestNameLength)
+	}
+	if !q.ExpiresAt.After(now) {
+		return errors.New("expires_at must be in the future")
+	}
+	if q.ExpiresAt.After(now.Add(MaxTestQuarantine)) {
+		return fmt.Errorf("expires_at must be within %d days", int(MaxTestQuarantine/(24*time.Hour)))
+	}
+	return nil
+}
+
+type junitSuite struct {
+	Suites []junitSuite `xml:"testsuite"`
+	Cases  []junitCase  `xml:"testcase"`
+}
+
+type junitCase struct {
+	ClassName string    `xml:"classname,attr"`
+	Name      string    `xml:"name,attr"`
+	Failure   *struct{} `xml:"failure"`
+	Error     *struct{} `xml:"error"`
+	Skipped   *struct{} `xml:"skipped"`
+}
+
+// ParseJUnitReport reads a JUnit XML report, <testsuites> or a single
+// <testsuite>, into its tests' results, named classname.name. A test with
+// a <failure> or <error> failed.
+func ParseJUnitReport(data []byte) ([]TestResult, error) {
+	var root junitSuite
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("parse JUnit report: %w", err)
+	}
+	var results []TestResult
+	var walk func(suite junitSuite)
+	walk = func(suite junitSuite) {
+		for _, c := range suite.Cases {
+			name := strings.TrimSpace(c.Name)
+			if class := strings.TrimSpace(c.ClassName); class != "" {
+				name = class + "." + name
+			}
+			if name == "" || len(name) > MaxTestNameLength {
+				continue
+			}
+			status := TestPassed
+			switch {
+			case c.Failure != nil || c.Error != nil:
+				status = TestFailed
+			case c.Skipped != nil:
+				status = TestSkipped
+			}
+			results = append(results, TestResult{TestName: name, Status: status})
+		}
+		for _, s := range suite.Suites {
+			walk(s)
+		}
+	}
+	walk(root)
+	return results, nil
+}
+
+// FailedTests returns the names of the tests that failed in results,
+// sorted, each once.
+func FailedTests(results []TestResult) []string {
+	seen := make(map[string]bool)
+	var failed []string
+	for _, r := range results {
+		if r.Status == TestFailed && !seen[r.TestName] {
+			seen[r.TestName] = true
+			failed = append(failed, r.TestName)
+		}
+	}
+	sort.Strings(failed)
+	return failed
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJUnitReport(t *testing.T) {
+	results, err := ParseJUnitReport([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="api">
+    <testcase classname="api.Jobs" name="TestCreate" time="0.1"/>
+    <testcase classname="api.Jobs" name="TestCancel"><failure message="expected 200">trace</failure></testcase>
+    <testsuite name="nested">
+      <testcase name="test_login"><error type="Timeout"/></testcase>
+      <testcase classname="ui" name="test_theme"><skipped/></testcase>
+    </testsuite>
+  </testsuite>
+</testsuites>`))
+	require.NoError(t, err)
+	assert.Equal(t, []TestResult{
+		{TestName: "api.Jobs.TestCreate", Status: TestPassed},
+		{TestName: "api.Jobs.TestCancel", Status: TestFailed},
+		{TestName: "test_login", Status: TestFailed},
+		{TestName: "ui.test_theme", Status: TestSkipped},
+	}, results)
+	assert.Equal(t, []string{"api.Jobs.TestCancel", "test_login"}, FailedTests(results))
+
+	single, err := ParseJUnitReport([]byte(`<testsuite><testcase classname="a" name="b"/></testsuite>`))
+	require.NoError(t, err)
+	assert.Equal(t, []TestResult{{TestName: "a.b", Status: TestPassed}}, single)
+
+	_, err = ParseJUnitReport([]byte(`not xml`))
+	assert.Error(t, err)
+}
+
+func TestTestQuarantine_Validate(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, (&TestQuarantine{TestName: "pkg.TestFlaky", ExpiresAt: now.Add(DefaultTestQuarantine)}).Validate(now))
+
+	for name, q := range map[string]TestQuarantine{
+		"no test":       {TestName: " ", ExpiresAt: now.Add(time.Hour)},
+		"long test":     {TestName: strings.Repeat("x", MaxTestNameLength+1), ExpiresAt: now.Add(time.Hour)},
+		"expired":       {TestName: "t", ExpiresAt: now},
+		"too far ahead": {TestName: "t", ExpiresAt: now.Add(MaxTestQuarantine + time.Hour)},
+	} {
+		assert.Error(t, q.Validate(now), name)
+	}
+}
+
+func TestTestPassRate_Rate(t *testing.T) {
+	assert.Nil(t, TestPassRate{}.Rate())
+	assert.InDelta(t, 0.75, *TestPassRate{Runs: 4, Passed: 3}.Rate(), 1e-9)
+}
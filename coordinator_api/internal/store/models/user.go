@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/lib/pq"
@@ -31,9 +32,39 @@ type User struct {
 	// IsPrivate marks the org (this user, since users act as orgs today) as
 	// private. See Project.IsEffectivelyPrivate.
 	IsPrivate bool `gorm:"not null;default:false" json:"is_private"`
+	// IsServiceAccount marks a headless user created through the admin API
+	// for automation. Service accounts have no email, can't log in
+	// interactively, and hold only the "user" role and project-scoped role
+	// assignments (see ValidateServiceAccountRole).
+	IsServiceAccount bool   `gorm:"not null;default:false" json:"is_service_account,omitempty"`
+	Description      string `gorm:"type:text;not null;default:''" json:"description,omitempty"`
+	// DeactivatedAt is set when the user is deprovisioned (see SCIMUser).
+	// A deactivated user can't log in or use API tokens.
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty"`
 }
 
 // TableName specifies the table name for the model
 func (User) TableName() string {
 	return "users"
 }
+
+// IsDeactivated reports whether the user has been deprovisioned.
+func (u *User) IsDeactivated() bool {
+	return u.DeactivatedAt != nil
+}
+
+// ServiceAccountWorkerScope is the API token scope that lets a service
+// account act for workers: it may upload the logs of any job.
+const ServiceAccountWorkerScope = "worker"
+
+// ValidateServiceAccountRole reports whether a service account may be
+// assigned role at scopeType: only owner or member of a project.
+func ValidateServiceAccountRole(scopeType, role string) error {
+	if scopeType != ScopeTypeProject {
+		return fmt.Errorf("service accounts can only hold project-scoped roles")
+	}
+	if role != RoleOwner && role != RoleMember {
+		return fmt.Errorf("service accounts can't hold the %s role", role)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// User erasure modes.
+const (
+	// UserErasureAnonymize keeps the user as a deactivated placeholder,
+	// so everything it owns (jobs, projects, secrets) stays where it is,
+	// with its personal data scrubbed.
+	UserErasureAnonymize = "anonymize"
+	// UserErasureDelete deletes the user, after handing its jobs,
+	// workflows and projects to another user.
+	UserErasureDelete = "delete"
+)
+
+// minErasureIdentifierLength is the shortest identifier scrubbed from
+// jobs; shorter ones would match too much unrelated text.
+const minErasureIdentifierLength = 3
+
+// UserErasureOptions says how to erase a user.
+type UserErasureOptions struct {
+	// Mode is UserErasureAnonymize or UserErasureDelete.
+	Mode string
+	// ReassignTo is the user that takes over a deleted user's jobs,
+	// workflows and projects. Required to delete a user that has any.
+	ReassignTo string
+	// DryRun reports what erasing would do without doing it.
+	DryRun bool
+	// RequestedBy is the admin erasing the user.
+	RequestedBy string
+}
+
+// Validate checks the options.
+func (o UserErasureOptions) Validate(userID string) error {
+	switch o.Mode {
+	case UserErasureAnonymize:
+		if o.ReassignTo != "" {
+			return fmt.Errorf("reassign_to only applies to delete")
+		}
+	case UserErasureDelete:
+		if o.ReassignTo == userID {
+			return fmt.Errorf("reassign_to must be another user")
+		}
+	default:
+		return fmt.Errorf("mode must be %s or %s", UserErasureAnonymize, UserErasureDelete)
+	}
+	return nil
+}
+
+// UserErasureReport is what erasing a user did, or with DryRun would do.
+// It names no personal data, so it's kept after the user is gone.
+type UserErasureReport struct {
+	ErasureID   string    `json:"erasure_id,omitempty"`
+	UserID      string    `json:"user_id"`
+	Mode        string    `json:"mode"`
+	DryRun      bool      `json:"dry_run"`
+	RequestedBy string    `json:"requested_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	// AnonymizedUsername is the placeholder username an anonymized user
+	// keeps, and what its identifiers are replaced with in jobs.
+	AnonymizedUsername string `json:"anonymized_username"`
+	// IdentifiersScrubbed is how many of the user's identifiers (email,
+	// usernames, SSO handles, display names) were searched for.
+	IdentifiersScrubbed int `json:"identifiers_scrubbed"`
+
+	TokensRevoked           int64 `json:"tokens_revoked"`
+	SessionsRevoked         int64 `json:"sessions_revoked"`
+	IdentitiesRemoved       int64 `json:"identities_removed"`
+	SCIMLinksRemoved        int64 `json:"scim_links_removed"`
+	GroupMembershipsRemoved int64 `json:"group_memberships_removed"`
+	RoleAssignmentsRemoved  int64 `json:"role_assignments_removed"`
+	JobsScrubbed            int64 `json:"jobs_scrubbed"`
+	ConfigSnapshotsScrubbed int64 `json:"config_snapshots_scrubbed"`
+
+	ReassignedTo        string `json:"reassigned_to,omitempty"`
+	JobsReassigned      int64  `json:"jobs_reassigned"`
+	WorkflowsReassigned int64  `json:"workflows_reassigned"`
+	ProjectsReassigned  int64  `json:"projects_reassigned"`
+	UsageReassigned     int64  `json:"usage_reassigned"`
+
+	// SecretsRetained counts the secrets an anonymized user still owns:
+	// they're encrypted with the user's org key, so they stay with it
+	// for its projects.
+	SecretsRetained int64 `json:"secrets_retained"`
+	UserDeleted     bool  `json:"user_deleted"`
+	LogsDeleted     int64 `json:"logs_deleted"`
+
+	// Notes lists what was left for the operator to handle.
+	Notes []string `json:"notes,omitempty"`
+
+	// JobIDs are the user's jobs, whose logs are deleted on request.
+	JobIDs []string `json:"-"`
+}
+
+// Value implements driver.Valuer interface for database storage.
+func (r UserErasureReport) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+// Scan implements sql.Scanner interface for database retrieval.
+func (r *UserErasureReport) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		*r = UserErasureReport{}
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into UserErasureReport", value)
+	}
+	return json.Unmarshal(bytes, r)
+}
+
+// UserErasure is the record of one erasure, its report included.
+type UserErasure struct {
+	ErasureID   string            `gorm:"primaryKey;type:uuid;default:generate_ulid()" json:"erasure_id"`
+	UserID      string            `gorm:"type:uuid;not null" json:"user_id"`
+	Mode        string            `gorm:"type:text;not null" json:"mode"`
+	RequestedBy *string           `gorm:"type:uuid" json:"requested_by,omitempty"`
+	Report      UserErasureReport `gorm:"type:jsonb;not null" json:"report"`
+	CreatedAt   time.Time         `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"created_at"`
+}
+
+// TableName specifies the table name for the model
+func (UserErasure) TableName() string {
+	return "user_erasures"
+}
+
+// AnonymizedUsername is the username an anonymized user is left with.
+func AnonymizedUsername(userID string) string {
+	return "deleted-user-" + userID
+}
+
+// ErasureIdentifiers returns the distinct identifiers worth scrubbing,
+// longest first so an email is replaced before the username inside it.
+// Identifiers too short to be distinctive, or that would break the JSON
+// they're scrubbed from, are left out.
+func ErasureIdentifiers(candidates ...string) []string {
+	seen := map[string]bool{}
+	var identifiers []string
+	for _, c := range candidates {
+		c = strings.TrimSpace(c)
+		key := strings.ToLower(c)
+		if len(c) < minErasureIdentifierLength || seen[key] || strings.ContainsAny(c, "\"\\") {
+			continue
+		}
+		seen[key] = true
+		identifiers = append(identifiers, c)
+	}
+	sort.SliceStable(identifiers, func(i, j int) bool {
+		return len(identifiers[i]) > len(identifiers[j])
+	})
+	return identifiers
+}
+
+// ScrubIdentifiers replaces each whole-word, case-insensitive occurrence
+// of identifiers in text with replacement. A match must not sit inside a
+// longer word: "ann" is scrubbed from "by ann." but not from "annual".
+func ScrubIdentifiers(text string, identifiers []string, replacement string) string {
+	for _, identifier := range identifiers {
+		text = scrubIdentifier(text, identifier, replacement)
+	}
+	return text
+}
+
+func scrubIdentifier(text, identifier, replacement string) string {
+	lower, needle := strings.ToLower(text), strings.ToLower(identifier)
+	if len(lower) != len(text) {
+		// Lower-casing changed byte lengths, so offsets wouldn't line up;
+		// fall back to exact matches.
+		lower, needle = text, identifier
+	}
+	var b strings.Builder
+	start := 0
+	for {
+		i := strings.Index(lower[start:], needle)
+		if i < 0 {
+			break
+		}
+		i += start
+		end := i + len(needle)
+		if isWordBoundary(text, i-1) && isWordBoundary(text, end) {
+			b.WriteString(text[start:i])
+			b.WriteString(replacement)
+		} else {
+			b.WriteString(text[start:end])
+		}
+		start = end
+	}
+	if start == 0 {
+		return text
+	}
+	b.WriteString(text[start:])
+	return b.String()
+}
+
+// isWordBoundary reports whether the byte at i, if any, ends a word.
+func isWordBoundary(text string, i int) bool {
+	if i < 0 || i >= len(text) {
+		return true
+	}
+	c := rune(text[i])
+	return c < unicode.MaxASCII && !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_'
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserErasureOptionsValidate(t *testing.T) {
+	assert.NoError(t, UserErasureOptions{Mode: UserErasureAnonymize}.Validate("u1"))
+	assert.NoError(t, UserErasureOptions{Mode: UserErasureDelete, ReassignTo: "u2"}.Validate("u1"))
+	assert.NoError(t, UserErasureOptions{Mode: UserErasureDelete}.Validate("u1"), "the store checks whether reassign_to is needed")
+
+	assert.Error(t, UserErasureOptions{Mode: "purge"}.Validate("u1"))
+	assert.Error(t, UserErasureOptions{Mode: UserErasureAnonymize, ReassignTo: "u2"}.Validate("u1"))
+	assert.Error(t, UserErasureOptions{Mode: UserErasureDelete, ReassignTo: "u1"}.Validate("u1"))
+}
+
+func TestErasureIdentifiers(t *testing.T) {
+	identifiers := ErasureIdentifiers("ann", "ann@example.com", "Ann", "", "al", `a"b"c`, " Ann Smith ")
+	assert.Equal(t, []string{"ann@example.com", "Ann Smith", "ann"}, identifiers)
+}
+
+func TestScrubIdentifiers(t *testing.T) {
+	identifiers := ErasureIdentifiers("ann", "ann@example.com")
+	text := `{"author":"ANN@example.com","note":"reviewed by ann, annual report by annie"}`
+	assert.Equal(t,
+		`{"author":"deleted-user-1","note":"reviewed by deleted-user-1, annual report by annie"}`,
+		ScrubIdentifiers(text, identifiers, "deleted-user-1"))
+	assert.Equal(t, "nothing here", ScrubIdentifiers("nothing here", identifiers, "x"))
+	assert.Equal(t, "ann_b stays", ScrubIdentifiers("ann_b stays", identifiers, "x"))
+}
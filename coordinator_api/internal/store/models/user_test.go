@@ -0,0 +1,21 @@
+package models
+
+import "testing"
+
+func TestValidateServiceAccountRole(t *testing.T) {
+	tests := []struct {
+		scopeType, role string
+		ok              bool
+	}{
+		{ScopeTypeProject, RoleOwner, true},
+		{ScopeTypeProject, RoleMember, true},
+		{ScopeTypeProject, RoleAdmin, false},
+		{ScopeTypeOrg, RoleMember, false},
+		{ScopeTypeGlobal, RoleMember, false},
+	}
+	for _, tt := range tests {
+		if err := ValidateServiceAccountRole(tt.scopeType, tt.role); (err == nil) != tt.ok {
+			t.Errorf("ValidateServiceAccountRole(%s, %s) = %v, want ok %v", tt.scopeType, tt.role, err, tt.ok)
+		}
+	}
+}
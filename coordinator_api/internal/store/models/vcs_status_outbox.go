@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// VCS status outbox delivery kinds.
+const (
+	// StatusDeliveryCommitStatus sets the job's commit status.
+	StatusDeliveryCommitStatus = "commit_status"
+	// StatusDeliveryPRComment updates the job's PR comment.
+	StatusDeliveryPRComment = "pr_comment"
+)
+
+// VCS status outbox entry statuses.
+const (
+	// StatusDeliveryPending entries are delivered when NextAttemptAt comes.
+	StatusDeliveryPending = "pending"
+	// StatusDeliveryFailed entries ran out of attempts. The job's next
+	// status change makes them pending again.
+	StatusDeliveryFailed = "failed"
+)
+
+// StatusDelivery is a commit status or PR comment update waiting in the
+// outbox to be delivered to the VCS provider. Each job has at most one per
+// kind; Generation counts the status changes folded into it, so a delivery
+// finished for an older generation leaves the entry pending.
+type StatusDelivery struct {
+	OutboxID      string    `gorm:"primaryKey;type:uuid;default:generate_ulid()" json:"outbox_id"`
+	JobID         string    `gorm:"type:uuid;not null" json:"job_id"`
+	Kind          string    `gorm:"type:text;not null" json:"kind"`
+	Status        string    `gorm:"type:text;not null;default:pending" json:"status"`
+	Generation    int       `gorm:"not null;default:1" json:"generation"`
+	Attempts      int       `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time `gorm:"not null" json:"next_attempt_at"`
+	LastError     string    `gorm:"type:text;not null;default:''" json:"last_error,omitempty"`
+	CreatedAt     time.Time `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"created_at"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime:false;default:timezone('utc', now())" json:"updated_at"`
+}
+
+// TableName specifies the table name for the model.
+func (StatusDelivery) TableName() string {
+	return "vcs_status_outbox"
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Webhook event ledger statuses.
+const (
+	// WebhookEventProcessing marks a delivery claimed by a coordinator that
+	// hasn't finished with it yet, including one waiting to be retried.
+	WebhookEventProcessing = "processing"
+	// WebhookEventProcessed means the event produced an eval job, or an
+	// eval-less project's jobs.
+	WebhookEventProcessed = "processed"
+	// WebhookEventFiltered means the event was deliberately not built;
+	// FilterReason says why.
+	WebhookEventFiltered = "filtered"
+	// WebhookEventFailed means processing errored. A redelivery of a failed
+	// event is processed again.
+	WebhookEventFailed = "failed"
+)
+
+// WebhookEventClaimLease is how long a claimed delivery that isn't queued
+// for asynchronous processing may stay "processing". After it, a
+// redelivery can claim the event again, so one left behind by a
+// coordinator that died mid-request doesn't block its redelivery forever.
+const WebhookEventClaimLease = 5 * time.Minute
+
+// Filter reasons beyond the project's own rules (see
+// Project.EventFilterReason).
+const (
+	EventFilterNoProject        = "no_project"
+	EventFilterUnsupportedEvent = "unsupported_event"
+	EventFilterBranchDeleted    = "branch_deleted"
+	// EventFilterNoJobTemplates: none of an eval-less project's job
+	// templates run for the event.
+	EventFilterNoJobTemplates = "no_job_templates"
+)
+
+// WebhookEvent is the ledger entry for one received VCS webhook delivery:
+// what it was, whether it was built or filtered, and the eval job it
+// produced. DeliveryID (the provider's delivery id) is unique per
+// provider, so a redelivered webhook never creates a second job.
+type WebhookEvent struct {
+	EventID      string     `gorm:"primaryKey;type:uuid;default:generate_ulid()" json:"event_id"`
+	ProjectID    *string    `gorm:"type:uuid" json:"project_id,omitempty"`
+	Provider     string     `gorm:"type:text;not null" json:"provider"`
+	DeliveryID   *string    `gorm:"type:text" json:"delivery_id,omitempty"`
+	EventType    string     `gorm:"type:text;not null" json:"event_type"`
+	GenericEvent string     `gorm:"type:text;not null;default:''" json:"generic_event"`
+	Repo         string     `gorm:"type:text;not null;default:''" json:"repo"`
+	Ref          string     `gorm:"type:text;not null;default:''" json:"ref,omitempty"`
+	CommitSHA    string     `gorm:"type:text;not null;default:''" json:"commit_sha,omitempty"`
+	PRNumber     *int       `json:"pr_number,omitempty"`
+	Status       string     `gorm:"type:text;not null" json:"status"`
+	FilterReason string     `gorm:"type:text;not null;default:''" json:"filter_reason,omitempty"`
+	Error        string     `gorm:"type:text;not null;default:''" json:"error,omitempty"`
+	JobID        *string    `gorm:"type:uuid" json:"job_id,omitempty"`
+	ReceivedAt   time.Time  `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"received_at"`
+	ProcessedAt  *time.Time `json:"processed_at,omitempty"`
+
+	// Payload and Headers are the raw delivery, and ProjectIDs the
+	// projects it authenticated for, kept while it waits to be processed
+	// asynchronously. Attempts counts failed processing attempts; the next
+	// is due at NextAttemptAt. Without a payload, NextAttemptAt is when
+	// the claim's lease (WebhookEventClaimLease) runs out.
+	Payload       []byte         `gorm:"type:bytea" json:"-"`
+	Headers       JSONB          `gorm:"type:jsonb" json:"-"`
+	ProjectIDs    pq.StringArray `gorm:"type:uuid[]" json:"-"`
+	Attempts      int            `gorm:"not null;default:0" json:"attempts,omitempty"`
+	NextAttemptAt *time.Time     `json:"next_attempt_at,omitempty"`
+
+	// ProjectOutcomes is how processing went for each of the event's
+	// projects. Retries and redeliveries only process the projects whose
+	// outcome is missing or failed.
+	ProjectOutcomes WebhookProjectOutcomes `gorm:"type:jsonb" json:"project_outcomes,omitempty"`
+}
+
+// WebhookProjectOutcome is how processing a webhook event went for one
+// project: Status is processed, filtered or failed.
+type WebhookProjectOutcome struct {
+	Status       string  `json:"status"`
+	FilterReason string  `json:"filter_reason,omitempty"`
+	Error        string  `json:"error,omitempty"`
+	JobID        *string `json:"job_id,omitempty"`
+}
+
+// WebhookProjectOutcomes maps project ids to their outcomes, stored in a
+// jsonb column.
+type WebhookProjectOutcomes map[string]WebhookProjectOutcome
+
+// Value implements driver.Valuer interface for database storage.
+func (o WebhookProjectOutcomes) Value() (driver.Value, error) {
+	if o == nil {
+		return nil, nil
+	}
+	return json.Marshal(o)
+}
+
+// Scan implements sql.Scanner interface for database retrieval.
+func (o *WebhookProjectOutcomes) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*o = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into WebhookProjectOutcomes", value)
+	}
+	return json.Unmarshal(data, o)
+}
+
+// TableName specifies the table name for the model.
+func (WebhookEvent) TableName() string {
+	return "webhook_events"
+}
+
+// Filter marks the event filtered for reason.
+func (e *WebhookEvent) Filter(reason string) {
+	e.Status = WebhookEventFiltered
+	e.FilterReason = reason
+}
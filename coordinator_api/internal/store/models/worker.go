@@ -0,0 +1,75 @@
+package models
+
+import "time"
+
+// Worker states. Admins set a worker's DesiredState to active, draining or
+// quarantined; the worker reports State, which is drained once a draining
+// worker has finished its jobs.
+const (
+	WorkerStateActive      = "active"
+	WorkerStateDraining    = "draining"
+	WorkerStateDrained     = "drained"
+	WorkerStateQuarantined = "quarantined"
+)
+
+// Worker is a registered worker and the state admins want it in. A
+// draining or quarantined worker takes no new jobs but finishes the ones it
+// runs. A drain ends when the worker restarts; a quarantine lasts until an
+// admin resumes the worker.
+type Worker struct {
+	WorkerID     string     `gorm:"primaryKey;type:text" json:"worker_id"`
+	QueueName    string     `gorm:"type:text;not null;default:''" json:"queue_name"`
+	Platform     string     `gorm:"type:text;not null;default:''" json:"platform,omitempty"`
+	Concurrency  int        `gorm:"not null;default:0" json:"concurrency"`
+	RunningJobs  int        `gorm:"not null;default:0" json:"running_jobs"`
+	State        string     `gorm:"type:text;not null;default:'active'" json:"state"`
+	DesiredState string     `gorm:"type:text;not null;default:'active'" json:"desired_state"`
+	Reason       string     `gorm:"type:text;not null;default:''" json:"reason,omitempty"`
+	ChangedBy    *string    `gorm:"type:uuid" json:"changed_by,omitempty"`
+	ChangedAt    *time.Time `json:"changed_at,omitempty"`
+	StartedAt    time.Time  `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"started_at"`
+	LastSeenAt   time.Time  `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"last_seen_at"`
+}
+
+// TableName specifies the table name for the model.
+func (Worker) TableName() string {
+	return "workers"
+}
+
+// IsValidWorkerDesiredState reports whether state is one admins may set.
+func IsValidWorkerDesiredState(state string) bool {
+	switch state {
+	case WorkerStateActive, WorkerStateDraining, WorkerStateQuarantined:
+		return true
+	}
+	return false
+}
+
+// ReportedState is the state a worker that wants to be in DesiredState
+// reports while running runningJobs jobs.
+func (w Worker) ReportedState(runningJobs int) string {
+	switch w.DesiredState {
+	case WorkerStateDraining:
+		if runningJobs == 0 {
+			return WorkerStateDrained
+		}
+		return WorkerStateDraining
+	case WorkerStateQuarantined:
+		return WorkerStateQuarantined
+	}
+	return WorkerStateActive
+}
+
+// Accepting reports whether the worker should take new jobs.
+func (w Worker) Accepting() bool {
+	return w.DesiredState != WorkerStateDraining && w.DesiredState != WorkerStateQuarantined
+}
+
+// QueuedJobCount is how many jobs targeting one platform wait on a queue:
+// submitted or queued, not yet claimed by a worker.
+type QueuedJobCount struct {
+	QueueName  string `gorm:"column:queue_name"`
+	TargetOS   string `gorm:"column:target_os"`
+	TargetArch string `gorm:"column:target_arch"`
+	Jobs       int    `gorm:"column:jobs"`
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// WorkerDiagnostics is the diagnostics report a worker last sent with its
+// heartbeat, so operators can see into a worker without host access.
+type WorkerDiagnostics struct {
+	WorkerID   string                  `gorm:"primaryKey;type:text" json:"worker_id"`
+	Report     WorkerDiagnosticsReport `gorm:"type:jsonb;not null" json:"report"`
+	ReportedAt time.Time               `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"reported_at"`
+}
+
+// TableName specifies the table name for the model.
+func (WorkerDiagnostics) TableName() string {
+	return "worker_diagnostics"
+}
+
+// WorkerDiagnosticsReport is what a worker reports about itself: the jobs
+// it runs, its executor, its disk, and its own most recent log lines.
+type WorkerDiagnosticsReport struct {
+	Jobs       []WorkerJobAssignment `json:"jobs"`
+	Executor   WorkerExecutorStatus  `json:"executor"`
+	Disk       *WorkerDiskUsage      `json:"disk,omitempty"`
+	Goroutines int                   `json:"goroutines"`
+	Logs       []WorkerLogLine       `json:"logs,omitempty"`
+}
+
+// WorkerJobAssignment is a job a worker is running.
+type WorkerJobAssignment struct {
+	JobID     string    `json:"job_id"`
+	Name      string    `json:"name"`
+	ProjectID *string   `json:"project_id,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// WorkerExecutorStatus is the state of the runtime a worker runs jobs on
+// and of its image cache. Images and ImageBytes are only reported by
+// runtimes that keep images on the worker's host.
+type WorkerExecutorStatus struct {
+	Runtime    string `json:"runtime"`
+	Healthy    bool   `json:"healthy"`
+	Error      string `json:"error,omitempty"`
+	Images     *int   `json:"images,omitempty"`
+	ImageBytes *int64 `json:"image_bytes,omitempty"`
+}
+
+// WorkerDiskUsage is the usage of the filesystem holding a worker's job
+// workspaces.
+type WorkerDiskUsage struct {
+	Path       string `json:"path"`
+	TotalBytes uint64 `json:"total_bytes"`
+	FreeBytes  uint64 `json:"free_bytes"`
+}
+
+// WorkerLogLine is one line of a worker's own log.
+type WorkerLogLine struct {
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Message string    `json:"message"`
+}
+
+// Value implements driver.Valuer interface for database storage.
+func (r WorkerDiagnosticsReport) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+// Scan implements sql.Scanner interface for database retrieval.
+func (r *WorkerDiagnosticsReport) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into WorkerDiagnosticsReport", value)
+	}
+	return json.Unmarshal(bytes, r)
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultImagePrePullInterval is how often workers pull the pre-pull images
+// again, to pick up new pushes of their tags, when the setting doesn't say.
+const DefaultImagePrePullInterval = 6 * time.Hour
+
+// Worker image pull statuses.
+const (
+	WorkerImagePulled = "pulled"
+	WorkerImageFailed = "failed"
+)
+
+// RunnerImagePrePull is the "runner_image_prepull" global setting: the
+// runner images every worker keeps pulled. Generation goes up on every
+// change and on every pull requested by an admin; a worker that sees a new
+// generation pulls at once rather than waiting for the interval.
+type RunnerImagePrePull struct {
+	Images          []string  `json:"images"`
+	IntervalMinutes int       `json:"interval_minutes,omitempty"`
+	Generation      int       `json:"generation"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Interval returns how often the images are pulled again.
+func (p *RunnerImagePrePull) Interval() time.Duration {
+	if p.IntervalMinutes <= 0 {
+		return DefaultImagePrePullInterval
+	}
+	return time.Duration(p.IntervalMinutes) * time.Minute
+}
+
+// ValidateRunnerImages checks a pre-pull image list: image references
+// without whitespace, each listed once.
+func ValidateRunnerImages(images []string) error {
+	seen := make(map[string]bool, len(images))
+	for _, image := range images {
+		if image == "" || strings.ContainsAny(image, " \t\r\n") {
+			return fmt.Errorf("invalid image reference %q", image)
+		}
+		if seen[image] {
+			return fmt.Errorf("image %q listed twice", image)
+		}
+		seen[image] = true
+	}
+	return nil
+}
+
+// WorkerImage is how a worker's last pull of a pre-pull image went.
+type WorkerImage struct {
+	WorkerID    string     `gorm:"primaryKey;type:text" json:"worker_id"`
+	Image       string     `gorm:"primaryKey;type:text" json:"image"`
+	Status      string     `gorm:"type:text;not null" json:"status"`
+	Error       string     `gorm:"type:text;not null;default:''" json:"error,omitempty"`
+	Generation  int        `gorm:"not null;default:0" json:"generation"`
+	PullSeconds float64    `gorm:"not null;default:0" json:"pull_seconds"`
+	PulledAt    *time.Time `json:"pulled_at,omitempty"`
+	CheckedAt   time.Time  `gorm:"autoCreateTime:false;default:timezone('utc', now())" json:"checked_at"`
+}
+
+// TableName specifies the table name for the model.
+func (WorkerImage) TableName() string {
+	return "worker_images"
+}
@@ -0,0 +1,126 @@
+package postgres_store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// activityEventsSQL is the activity feed: one row per event, read from the
+// records the events describe. Project updates come from the config
+// history, or from updated_at for projects last changed before it was
+// kept. Every row has the project_id and user_id (the owning org)
+// visibilityPredicateSQL needs. Filters on kind and occurred_at are pushed
+// into each branch by Postgres.
+const activityEventsSQL = `
+SELECT 'job_started:' || j.job_id AS event_id, 'job_started' AS kind, j.started_at AS occurred_at,
+	j.project_id, j.user_id, j.job_id::text AS subject_id, j.name AS summary, j.status AS detail, NULL::text AS actor_id
+FROM jobs j
+WHERE j.started_at IS NOT NULL AND j.deleted_at IS NULL
+UNION ALL
+SELECT 'job_failed:' || j.job_id, 'job_failed', j.completed_at,
+	j.project_id, j.user_id, j.job_id::text, j.name, left(COALESCE(j.last_error, ''), 500), NULL
+FROM jobs j
+WHERE j.status IN ('failed', 'timeout') AND j.completed_at IS NOT NULL AND j.deleted_at IS NULL
+UNION ALL
+SELECT 'project_created:' || pc.project_id, 'project_created', pc.created_at,
+	pc.project_id, pc.user_id, pc.project_id::text, pc.name, '', NULL
+FROM projects pc
+WHERE pc.deleted_at IS NULL
+UNION ALL
+SELECT 'project_updated:' || ph.history_id, 'project_updated', ph.changed_at,
+	ph.project_id, hp.user_id, ph.project_id::text, hp.name,
+	CASE WHEN ph.action = 'reverted' THEN 'reverted to version ' || ph.reverted_to || ': ' ELSE '' END
+		|| COALESCE((SELECT string_agg(c->>'field', ', ') FROM jsonb_array_elements(ph.changes) c), ''),
+	ph.actor_id::text
+FROM project_config_history ph JOIN projects hp ON hp.project_id = ph.project_id
+WHERE ph.action <> 'created' AND hp.deleted_at IS NULL
+UNION ALL
+SELECT 'project_updated:' || pu.project_id, 'project_updated', pu.updated_at,
+	pu.project_id, pu.user_id, pu.project_id::text, pu.name, '', NULL
+FROM projects pu
+WHERE pu.deleted_at IS NULL AND pu.updated_at > pu.created_at
+	AND NOT EXISTS (SELECT 1 FROM project_config_history uh WHERE uh.project_id = pu.project_id AND uh.action <> 'created')
+UNION ALL
+SELECT 'credential_rotated:' || ws.id, 'credential_rotated', ws.created_at,
+	ws.project_id, wp.user_id, ws.id::text, ws.name, 'webhook_secret ' || ws.provider, NULL
+FROM project_webhook_secrets ws JOIN projects wp ON wp.project_id = ws.project_id
+UNION ALL
+SELECT 'credential_rotated:' || vc.id, 'credential_rotated', vc.created_at,
+	vc.project_id, vp.user_id, vc.id::text, vc.name, 'vcs_credential ' || vc.provider, NULL
+FROM project_vcs_credentials vc JOIN projects vp ON vp.project_id = vc.project_id
+UNION ALL
+SELECT 'secret_rotated:' || s.secret_id, 'secret_rotated', s.updated_at,
+	NULL::uuid, s.user_id, s.secret_id::text, s.path || ':' || s.key, '', NULL
+FROM secrets s
+WHERE s.updated_at > s.created_at
+UNION ALL
+SELECT 'approval_granted:' || pr.promotion_id || ':' || (a->>'user_id'), 'approval_granted',
+	(a->>'approved_at')::timestamptz AT TIME ZONE 'utc',
+	pr.project_id, ap.user_id, pr.promotion_id::text, pr.to_environment, pr.artifact_digest, a->>'user_id'
+FROM promotions pr JOIN projects ap ON ap.project_id = pr.project_id
+CROSS JOIN LATERAL jsonb_array_elements(pr.approvals) a
+`
+
+// secretEventOwnerSQL limits secret_rotated events to the secret's org and
+// its admins, whatever the org's visibility: a secret's path is nobody
+// else's business. Its 3 placeholders all take the viewer's user ID.
+const secretEventOwnerSQL = `(
+	e.kind <> 'secret_rotated'
+	OR e.user_id = ?
+	OR EXISTS (
+		SELECT 1 FROM role_assignments ra
+		WHERE ra.scope_type = 'org' AND ra.scope_id = e.user_id AND ra.role = 'admin'
+		AND (
+			(ra.principal_type = 'user' AND ra.principal_id = ?)
+			OR (ra.principal_type = 'group' AND ra.principal_id IN (
+				SELECT gm.group_id FROM group_members gm WHERE gm.user_id = ?))
+		)
+	)
+)`
+
+// ListActivityVisibleTo lists the activity feed events filter selects
+// that viewerID may see, newest first: those of projects and orgs it can
+// view (see visibilityPredicateSQL), and secret changes only in its own
+// org or orgs it administers. isGlobalAdmin sees everything.
+func (ps PostgresDbStore) ListActivityVisibleTo(ctx context.Context, viewerID string, isGlobalAdmin bool, filter store.ActivityFilter) ([]models.ActivityEvent, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+
+	q := ps.getDB(ctx).Table("(" + activityEventsSQL + ") e")
+	for _, join := range visibilityJoins("e", "p", "proj_owner", "ent_owner") {
+		q = q.Joins(join)
+	}
+	q = q.Where("e.occurred_at >= ?", filter.Since)
+	if len(filter.Kinds) > 0 {
+		q = q.Where("e.kind IN ?", filter.Kinds)
+	}
+	if filter.ProjectID != "" {
+		if !isValidUUID(filter.ProjectID) {
+			return []models.ActivityEvent{}, nil
+		}
+		q = q.Where("e.project_id = ?", filter.ProjectID)
+	}
+	if filter.After != nil {
+		q = q.Where("(e.occurred_at, e.event_id) < (?, ?)", filter.After.OccurredAt, filter.After.EventID)
+	}
+	if !isGlobalAdmin {
+		q = q.Where(visibilityPredicateSQL("e", "p", "proj_owner", "ent_owner"), visibilityArgs(viewerID)...)
+		q = q.Where(secretEventOwnerSQL, viewerID, viewerID, viewerID)
+	}
+
+	events := []models.ActivityEvent{}
+	err := q.Select("e.event_id, e.kind, e.occurred_at, e.project_id, COALESCE(p.name, '') AS project_name, " +
+		"e.subject_id, e.summary, COALESCE(e.detail, '') AS detail, e.actor_id").
+		Order("e.occurred_at DESC, e.event_id DESC").
+		Limit(limit).
+		Scan(&events).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list activity: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,93 @@
+package postgres_store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// ListAdmissionPolicies returns every admission policy, by target and name.
+func (ps PostgresDbStore) ListAdmissionPolicies(ctx context.Context) ([]models.AdmissionPolicy, error) {
+	var policies []models.AdmissionPolicy
+	if err := ps.getDB(ctx).Order("target ASC, name ASC, policy_id ASC").Find(&policies).Error; err != nil {
+		return nil, fmt.Errorf("failed to list admission policies: %w", err)
+	}
+	return policies, nil
+}
+
+// GetAdmissionPolicy returns an admission policy.
+func (ps PostgresDbStore) GetAdmissionPolicy(ctx context.Context, policyID string) (*models.AdmissionPolicy, error) {
+	if !isValidUUID(policyID) {
+		return nil, store.ErrNotFound
+	}
+	var policy models.AdmissionPolicy
+	if err := ps.getDB(ctx).Where("policy_id = ?", policyID).First(&policy).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, store.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get admission policy: %w", err)
+	}
+	return &policy, nil
+}
+
+// CreateAdmissionPolicy creates an admission policy.
+func (ps PostgresDbStore) CreateAdmissionPolicy(ctx context.Context, policy *models.AdmissionPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return store.ErrInvalidInput
+	}
+	now := time.Now().UTC()
+	policy.CreatedAt, policy.UpdatedAt = now, now
+	if err := ps.getDB(ctx).Create(policy).Error; err != nil {
+		return fmt.Errorf("failed to create admission policy: %w", err)
+	}
+	return nil
+}
+
+// UpdateAdmissionPolicy saves an admission policy's settings and rules.
+// Returns store.ErrNotFound if it doesn't exist.
+func (ps PostgresDbStore) UpdateAdmissionPolicy(ctx context.Context, policy *models.AdmissionPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return store.ErrInvalidInput
+	}
+	policy.UpdatedAt = time.Now().UTC()
+	result := ps.getDB(ctx).Model(&models.AdmissionPolicy{}).
+		Where("policy_id = ?", policy.PolicyID).
+		Updates(map[string]interface{}{
+			"name":        policy.Name,
+			"description": policy.Description,
+			"target":      policy.Target,
+			"enforcement": policy.Enforcement,
+			"project_id":  policy.ProjectID,
+			"rules":       policy.Rules,
+			"updated_at":  policy.UpdatedAt,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update admission policy: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteAdmissionPolicy deletes an admission policy. Returns
+// store.ErrNotFound if it doesn't exist.
+func (ps PostgresDbStore) DeleteAdmissionPolicy(ctx context.Context, policyID string) error {
+	if !isValidUUID(policyID) {
+		return store.ErrNotFound
+	}
+	result := ps.getDB(ctx).Where("policy_id = ?", policyID).Delete(&models.AdmissionPolicy{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete admission policy: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package postgres_store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// ListRecentProjectWorkers returns the workers that started a job of the
+// project since the given time, most recent first. Workers use it to honor
+// project affinity (see worker.AffinityProject).
+func (ps PostgresDbStore) ListRecentProjectWorkers(ctx context.Context, projectID string, since time.Time) ([]string, error) {
+	if !isValidUUID(projectID) {
+		return nil, nil
+	}
+	var workers []string
+	err := ps.getDB(ctx).Model(&models.Job{}).
+		Select("worker_id").
+		Where("project_id = ? AND worker_id IS NOT NULL AND worker_id <> '' AND started_at >= ?", projectID, since).
+		Group("worker_id").
+		Order("MAX(started_at) DESC").
+		Pluck("worker_id", &workers).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recent workers of project %s: %w", projectID, err)
+	}
+	return workers, nil
+}
@@ -0,0 +1,121 @@
+package postgres_store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// analyticsBuckets are the trend bucket sizes GetProjectAnalytics accepts,
+// as Postgres date_trunc units.
+var analyticsBuckets = map[string]bool{
+	"hour": true,
+	"day":  true,
+	"week": true,
+}
+
+// jobRunStatsColumns is the aggregate select list shared by the overall,
+// per-job-name and per-bucket queries. GetProjectAnalytics only feeds it
+// terminal runs; "failed" covers every unsuccessful terminal status, the
+// same set models.Job.IsRetryable admits.
+const jobRunStatsColumns = `
+	COUNT(*) AS runs,
+	COUNT(*) FILTER (WHERE status = 'completed') AS succeeded,
+	COUNT(*) FILTER (WHERE status IN ('failed', 'cancelled', 'timeout')) AS failed,
+	percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM (completed_at - started_at)) * 1000)
+		FILTER (WHERE status = 'completed' AND started_at IS NOT NULL) AS duration_p50_ms,
+	percentile_cont(0.95) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM (completed_at - started_at)) * 1000)
+		FILTER (WHERE status = 'completed' AND started_at IS NOT NULL) AS duration_p95_ms,
+	percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM (started_at - created_at)) * 1000)
+		FILTER (WHERE started_at IS NOT NULL) AS queue_wait_p50_ms,
+	percentile_cont(0.95) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM (started_at - created_at)) * 1000)
+		FILTER (WHERE started_at IS NOT NULL) AS queue_wait_p95_ms`
+
+type jobRunStatsRow struct {
+	JobName        string    `gorm:"column:job_name"`
+	BucketStart    time.Time `gorm:"column:bucket_start"`
+	Runs           int       `gorm:"column:runs"`
+	Succeeded      int       `gorm:"column:succeeded"`
+	Failed         int       `gorm:"column:failed"`
+	DurationP50Ms  *float64  `gorm:"column:duration_p50_ms"`
+	DurationP95Ms  *float64  `gorm:"column:duration_p95_ms"`
+	QueueWaitP50Ms *float64  `gorm:"column:queue_wait_p50_ms"`
+	QueueWaitP95Ms *float64  `gorm:"column:queue_wait_p95_ms"`
+}
+
+func (r jobRunStatsRow) stats() models.JobRunStats {
+	s := models.JobRunStats{
+		Runs:           r.Runs,
+		Succeeded:      r.Succeeded,
+		Failed:         r.Failed,
+		DurationP50Ms:  r.DurationP50Ms,
+		DurationP95Ms:  r.DurationP95Ms,
+		QueueWaitP50Ms: r.QueueWaitP50Ms,
+		QueueWaitP95Ms: r.QueueWaitP95Ms,
+	}
+	if r.Runs > 0 {
+		s.FailureRate = float64(r.Failed) / float64(r.Runs)
+	}
+	return s
+}
+
+// GetProjectAnalytics summarizes the project's jobs that reached a terminal
+// status in [since, until): overall, per job name, and as a trend over
+// bucket-sized windows (see analyticsBuckets). jobName, when non-empty,
+// restricts every section to that job name.
+func (ps PostgresDbStore) GetProjectAnalytics(ctx context.Context, projectID string, since, until time.Time, bucket, jobName string) (*models.ProjectAnalytics, error) {
+	if !isValidUUID(projectID) {
+		return nil, store.ErrNotFound
+	}
+	if !analyticsBuckets[bucket] {
+		return nil, store.ErrInvalidInput
+	}
+
+	where := "project_id = ? AND deleted_at IS NULL AND status IN ('completed', 'failed', 'cancelled', 'timeout') AND completed_at >= ? AND completed_at < ?"
+	args := []interface{}{projectID, since, until}
+	if jobName != "" {
+		where += " AND name = ?"
+		args = append(args, jobName)
+	}
+
+	var overall jobRunStatsRow
+	if err := ps.getDB(ctx).Raw("SELECT"+jobRunStatsColumns+" FROM jobs WHERE "+where, args...).
+		Scan(&overall).Error; err != nil {
+		return nil, fmt.Errorf("failed to compute project analytics: %w", err)
+	}
+
+	var byName []jobRunStatsRow
+	if err := ps.getDB(ctx).Raw("SELECT name AS job_name,"+jobRunStatsColumns+" FROM jobs WHERE "+where+
+		" GROUP BY name ORDER BY runs DESC, name", args...).Scan(&byName).Error; err != nil {
+		return nil, fmt.Errorf("failed to compute per-job analytics: %w", err)
+	}
+
+	// bucket is validated against analyticsBuckets above, so it is safe to
+	// pass as a literal date_trunc unit.
+	var byBucket []jobRunStatsRow
+	if err := ps.getDB(ctx).Raw("SELECT date_trunc('"+bucket+"', completed_at) AS bucket_start,"+jobRunStatsColumns+
+		" FROM jobs WHERE "+where+" GROUP BY bucket_start ORDER BY bucket_start", args...).Scan(&byBucket).Error; err != nil {
+		return nil, fmt.Errorf("failed to compute analytics trend: %w", err)
+	}
+
+	result := &models.ProjectAnalytics{
+		ProjectID: projectID,
+		Since:     since,
+		Until:     until,
+		Bucket:    bucket,
+		JobName:   jobName,
+		Overall:   overall.stats(),
+		Jobs:      make([]models.JobNameStats, 0, len(byName)),
+		Trend:     make([]models.AnalyticsBucket, 0, len(byBucket)),
+	}
+	for _, row := range byName {
+		result.Jobs = append(result.Jobs, models.JobNameStats{JobName: row.JobName, JobRunStats: row.stats()})
+	}
+	for _, row := range byBucket {
+		result.Trend = append(result.Trend, models.AnalyticsBucket{Start: row.BucketStart, JobRunStats: row.stats()})
+	}
+	return result, nil
+}
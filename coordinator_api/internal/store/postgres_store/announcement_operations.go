@@ -0,0 +1,91 @@
+package postgres_store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// ListAnnouncements returns every announcement, newest first.
+func (ps PostgresDbStore) ListAnnouncements(ctx context.Context) ([]models.Announcement, error) {
+	var announcements []models.Announcement
+	if err := ps.getDB(ctx).Order("created_at DESC, announcement_id DESC").Find(&announcements).Error; err != nil {
+		return nil, fmt.Errorf("failed to list announcements: %w", err)
+	}
+	return announcements, nil
+}
+
+// GetAnnouncement returns an announcement.
+func (ps PostgresDbStore) GetAnnouncement(ctx context.Context, announcementID string) (*models.Announcement, error) {
+	if !isValidUUID(announcementID) {
+		return nil, store.ErrNotFound
+	}
+	var announcement models.Announcement
+	if err := ps.getDB(ctx).Where("announcement_id = ?", announcementID).First(&announcement).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, store.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get announcement: %w", err)
+	}
+	return &announcement, nil
+}
+
+// CreateAnnouncement creates an announcement.
+func (ps PostgresDbStore) CreateAnnouncement(ctx context.Context, announcement *models.Announcement) error {
+	if err := announcement.Validate(); err != nil {
+		return store.ErrInvalidInput
+	}
+	now := time.Now().UTC()
+	announcement.CreatedAt, announcement.UpdatedAt = now, now
+	if err := ps.getDB(ctx).Create(announcement).Error; err != nil {
+		return fmt.Errorf("failed to create announcement: %w", err)
+	}
+	return nil
+}
+
+// UpdateAnnouncement saves an announcement's message, severity and
+// schedule. Returns store.ErrNotFound if it doesn't exist.
+func (ps PostgresDbStore) UpdateAnnouncement(ctx context.Context, announcement *models.Announcement) error {
+	if err := announcement.Validate(); err != nil {
+		return store.ErrInvalidInput
+	}
+	announcement.UpdatedAt = time.Now().UTC()
+	result := ps.getDB(ctx).Model(&models.Announcement{}).
+		Where("announcement_id = ?", announcement.AnnouncementID).
+		Updates(map[string]interface{}{
+			"message":    announcement.Message,
+			"severity":   announcement.Severity,
+			"starts_at":  announcement.StartsAt,
+			"ends_at":    announcement.EndsAt,
+			"updated_at": announcement.UpdatedAt,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update announcement: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteAnnouncement deletes an announcement. Returns store.ErrNotFound if
+// it doesn't exist.
+func (ps PostgresDbStore) DeleteAnnouncement(ctx context.Context, announcementID string) error {
+	if !isValidUUID(announcementID) {
+		return store.ErrNotFound
+	}
+	result := ps.getDB(ctx).Where("announcement_id = ?", announcementID).Delete(&models.Announcement{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete announcement: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
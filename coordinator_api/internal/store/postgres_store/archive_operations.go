@@ -0,0 +1,78 @@
+package postgres_store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"gorm.io/gorm"
+)
+
+// jobsWithArchiveSQL is jobs and jobs_archive as one relation, with an
+// archived column saying which each row came from.
+const jobsWithArchiveSQL = "(SELECT *, false AS archived FROM jobs UNION ALL SELECT *, true AS archived FROM jobs_archive)"
+
+// webhookEventsWithArchiveSQL is webhook_events and webhook_events_archive
+// as one relation.
+const webhookEventsWithArchiveSQL = "(SELECT * FROM webhook_events UNION ALL SELECT * FROM webhook_events_archive)"
+
+// ArchiveJobs moves up to limit jobs that finished before completedBefore
+// from jobs to jobs_archive, oldest first, and returns how many it moved.
+// Rows locked by another transaction are skipped, so replicas archiving at
+// once don't wait on each other.
+func (ps PostgresDbStore) ArchiveJobs(ctx context.Context, completedBefore time.Time, limit int) (int64, error) {
+	result := ps.getDB(ctx).Exec(`
+		WITH moved AS (
+			DELETE FROM jobs WHERE job_id IN (
+				SELECT job_id FROM jobs
+				WHERE completed_at IS NOT NULL AND completed_at < ?
+					AND status IN ('completed', 'failed', 'cancelled', 'timeout')
+				ORDER BY completed_at
+				LIMIT ?
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		INSERT INTO jobs_archive SELECT * FROM moved`, completedBefore, limit)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to archive jobs: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// ArchiveWebhookEvents moves up to limit webhook events received before
+// receivedBefore, and no longer processing, to webhook_events_archive.
+func (ps PostgresDbStore) ArchiveWebhookEvents(ctx context.Context, receivedBefore time.Time, limit int) (int64, error) {
+	result := ps.getDB(ctx).Exec(`
+		WITH moved AS (
+			DELETE FROM webhook_events WHERE event_id IN (
+				SELECT event_id FROM webhook_events
+				WHERE received_at < ? AND status <> 'processing'
+				ORDER BY received_at
+				LIMIT ?
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		INSERT INTO webhook_events_archive SELECT * FROM moved`, receivedBefore, limit)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to archive webhook events: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// getArchivedJob returns an archived job, marked Archived.
+func (ps PostgresDbStore) getArchivedJob(ctx context.Context, jobID string) (*models.Job, error) {
+	var job models.Job
+	if err := ps.getDB(ctx).Table("jobs_archive").Where("job_id = ?", jobID).First(&job).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, store.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get archived job %s: %w", jobID, err)
+	}
+	job.Archived = true
+	return &job, nil
+}
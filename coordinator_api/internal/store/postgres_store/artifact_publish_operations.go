@@ -0,0 +1,25 @@
+package postgres_store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// SetJobPublishedArtifacts records where a job's artifacts were published.
+// It writes only that column, so it doesn't race the job's other writers.
+func (ps PostgresDbStore) SetJobPublishedArtifacts(ctx context.Context, jobID string, artifacts models.PublishedArtifacts) error {
+	if !isValidUUID(jobID) {
+		return store.ErrNotFound
+	}
+	result := ps.getDB(ctx).Model(&models.Job{}).Where("job_id = ?", jobID).UpdateColumn("published_artifacts", artifacts)
+	if result.Error != nil {
+		return fmt.Errorf("failed to set job published artifacts: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
@@ -67,6 +67,18 @@ func (ps PostgresDbStore) RevokeUISession(ctx context.Context, sessionID string)
 	return nil
 }
 
+// RevokeUISessionsByUser revokes every live session of a user and returns
+// how many it revoked.
+func (ps PostgresDbStore) RevokeUISessionsByUser(ctx context.Context, userID string) (int64, error) {
+	result := ps.getDB(ctx).Model(&models.UISession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now().UTC())
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to revoke ui sessions of user %s: %w", userID, result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
 // DeleteExpiredUISessions deletes sessions past their expiry and returns the
 // number of rows removed.
 func (ps PostgresDbStore) DeleteExpiredUISessions(ctx context.Context) (int64, error) {
@@ -107,6 +119,16 @@ func (ps PostgresDbStore) GetAuthIdentityByUserID(ctx context.Context, userID st
 	return &identity, nil
 }
 
+// ListAuthIdentitiesByDomain lists the identities from one domain, e.g.
+// every LDAP user for the group sync.
+func (ps PostgresDbStore) ListAuthIdentitiesByDomain(ctx context.Context, domain string) ([]models.AuthIdentity, error) {
+	var identities []models.AuthIdentity
+	if err := ps.getDB(ctx).Where("domain = ?", domain).Order("subject ASC").Find(&identities).Error; err != nil {
+		return nil, fmt.Errorf("failed to list auth identities: %w", err)
+	}
+	return identities, nil
+}
+
 // CreateAuthIdentity creates a new auth identity.
 func (ps PostgresDbStore) CreateAuthIdentity(ctx context.Context, identity *models.AuthIdentity) error {
 	if err := ps.getDB(ctx).Create(identity).Error; err != nil {
@@ -264,7 +286,7 @@ func (ps PostgresDbStore) CreateLoginAttempt(ctx context.Context, attempt *model
 // created).
 func (ps PostgresDbStore) ConsumeLoginAttempt(ctx context.Context, attemptHash []byte) (*models.AuthLoginAttempt, error) {
 	var attempt models.AuthLoginAttempt
-	err := ps.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+	err := ps.transaction(ctx, func(tx *gorm.DB) error {
 		if err := tx.Where("attempt_hash = ?", attemptHash).First(&attempt).Error; err != nil {
 			return err
 		}
@@ -0,0 +1,36 @@
+package postgres_store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// CompareCanaryJobs summarizes a project's canary jobs and its other jobs
+// that were created since since and have reached a terminal status, as
+// GetProjectAnalytics does.
+func (ps PostgresDbStore) CompareCanaryJobs(ctx context.Context, projectID string, since time.Time) (canary, baseline models.JobRunStats, err error) {
+	if !isValidUUID(projectID) {
+		return canary, baseline, store.ErrNotFound
+	}
+	var rows []struct {
+		jobRunStatsRow
+		Canary bool `gorm:"column:canary"`
+	}
+	if err := ps.getDB(ctx).Raw("SELECT canary,"+jobRunStatsColumns+
+		" FROM jobs WHERE project_id = ? AND deleted_at IS NULL AND created_at >= ? AND status IN ('completed', 'failed', 'cancelled', 'timeout')"+
+		" GROUP BY canary", projectID, since).Scan(&rows).Error; err != nil {
+		return canary, baseline, fmt.Errorf("failed to compare canary jobs: %w", err)
+	}
+	for _, row := range rows {
+		if row.Canary {
+			canary = row.stats()
+		} else {
+			baseline = row.stats()
+		}
+	}
+	return canary, baseline, nil
+}
@@ -0,0 +1,28 @@
+package postgres_store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// ListRecentPushEvalJobs returns the eval jobs the project's pushes to
+// branch created since since, newest first. Eval jobs are the ones a
+// webhook created: they have no parent and carry the event in their env.
+func (ps PostgresDbStore) ListRecentPushEvalJobs(ctx context.Context, projectID, branch string, since time.Time) ([]models.Job, error) {
+	if !isValidUUID(projectID) {
+		return nil, nil
+	}
+	var jobs []models.Job
+	err := ps.getDB(ctx).
+		Where("project_id = ? AND parent_job_id IS NULL AND created_at >= ?", projectID, since).
+		Where("job_env_vars->>'REACTORCIDE_EVENT_TYPE' = ? AND job_env_vars->>'REACTORCIDE_BRANCH' = ?", "push", branch).
+		Order("created_at DESC").
+		Find(&jobs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recent push eval jobs: %w", err)
+	}
+	return jobs, nil
+}
@@ -0,0 +1,123 @@
+package postgres_store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm/clause"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// costGroupColumns maps the groupings ListCostRollups accepts to the
+// job_usage column each one groups by.
+var costGroupColumns = map[string]string{
+	"project": "project_id",
+	"org":     "org_id",
+}
+
+// RecordJobUsage stores a finished job's usage, replacing any earlier
+// record for the same job.
+func (ps PostgresDbStore) RecordJobUsage(ctx context.Context, usage *models.JobUsage) error {
+	if !isValidUUID(usage.JobID) || !isValidUUID(usage.OrgID) {
+		return store.ErrInvalidInput
+	}
+	err := ps.getDB(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "job_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"project_id", "completed_at", "cpu_seconds", "memory_gb_seconds", "storage_bytes", "estimated",
+			"cpu_second_rate", "memory_gb_second_rate", "storage_gb_rate", "cost", "currency",
+		}),
+	}).Create(usage).Error
+	if err != nil {
+		return fmt.Errorf("failed to record job usage: %w", err)
+	}
+	return nil
+}
+
+// SetJobResources records the resources a job's container consumed on the
+// job. Returns store.ErrNotFound if the job doesn't exist.
+func (ps PostgresDbStore) SetJobResources(ctx context.Context, jobID string, resources *models.JobResources) error {
+	if !isValidUUID(jobID) {
+		return store.ErrInvalidInput
+	}
+	result := ps.getDB(ctx).Model(&models.Job{}).Where("job_id = ?", jobID).Update("resources", resources)
+	if result.Error != nil {
+		return fmt.Errorf("failed to set job resources: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+type costRollupRow struct {
+	Month           time.Time `gorm:"column:month"`
+	GroupID         *string   `gorm:"column:group_id"`
+	Jobs            int       `gorm:"column:jobs"`
+	CPUSeconds      float64   `gorm:"column:cpu_seconds"`
+	MemoryGBSeconds float64   `gorm:"column:memory_gb_seconds"`
+	StorageBytes    int64     `gorm:"column:storage_bytes"`
+	Cost            float64   `gorm:"column:cost"`
+	Currency        string    `gorm:"column:currency"`
+}
+
+// ListCostRollups sums the usage of jobs that finished in [since, until)
+// per calendar month and per project or org (see costGroupColumns), oldest
+// month first. orgID and projectID, when non-empty, restrict the rows
+// summed. Rows priced in different currencies are never added together.
+func (ps PostgresDbStore) ListCostRollups(ctx context.Context, groupBy string, since, until time.Time, orgID, projectID string) ([]models.CostRollup, error) {
+	column, ok := costGroupColumns[groupBy]
+	if !ok {
+		return nil, store.ErrInvalidInput
+	}
+
+	query := ps.getDB(ctx).Table("job_usage").
+		Where("completed_at >= ? AND completed_at < ?", since, until)
+	if orgID != "" {
+		if !isValidUUID(orgID) {
+			return []models.CostRollup{}, nil
+		}
+		query = query.Where("org_id = ?", orgID)
+	}
+	if projectID != "" {
+		if !isValidUUID(projectID) {
+			return []models.CostRollup{}, nil
+		}
+		query = query.Where("project_id = ?", projectID)
+	}
+
+	// column comes from costGroupColumns, so it is safe to interpolate.
+	var rows []costRollupRow
+	err := query.Select("date_trunc('month', completed_at) AS month, " + column + "::text AS group_id, " +
+		"COUNT(*) AS jobs, SUM(cpu_seconds) AS cpu_seconds, SUM(memory_gb_seconds) AS memory_gb_seconds, " +
+		"SUM(storage_bytes) AS storage_bytes, SUM(cost) AS cost, currency").
+		Group("month, group_id, currency").
+		Order("month, cost DESC, group_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute cost rollups: %w", err)
+	}
+
+	rollups := make([]models.CostRollup, 0, len(rows))
+	for _, row := range rows {
+		rollup := models.CostRollup{
+			Month:           row.Month,
+			Jobs:            row.Jobs,
+			CPUSeconds:      row.CPUSeconds,
+			MemoryGBSeconds: row.MemoryGBSeconds,
+			StorageBytes:    row.StorageBytes,
+			Cost:            row.Cost,
+			Currency:        row.Currency,
+		}
+		if groupBy == "project" {
+			rollup.ProjectID = row.GroupID
+		} else if row.GroupID != nil {
+			rollup.OrgID = *row.GroupID
+		}
+		rollups = append(rollups, rollup)
+	}
+	return rollups, nil
+}
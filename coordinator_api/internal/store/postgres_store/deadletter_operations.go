@@ -0,0 +1,46 @@
+package postgres_store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// RecordJobDeliveryFailure records a claim of a job that ended without a
+// result.
+func (ps PostgresDbStore) RecordJobDeliveryFailure(ctx context.Context, failure *models.JobDeliveryFailure) error {
+	if !isValidUUID(failure.JobID) {
+		return store.ErrInvalidInput
+	}
+	if err := ps.getDB(ctx).Create(failure).Error; err != nil {
+		return fmt.Errorf("failed to record job delivery failure: %w", err)
+	}
+	return nil
+}
+
+// ListJobDeliveryFailures returns the job's delivery failures, oldest
+// first.
+func (ps PostgresDbStore) ListJobDeliveryFailures(ctx context.Context, jobID string) ([]models.JobDeliveryFailure, error) {
+	if !isValidUUID(jobID) {
+		return nil, nil
+	}
+	var failures []models.JobDeliveryFailure
+	if err := ps.getDB(ctx).Where("job_id = ?", jobID).Order("failed_at ASC").Find(&failures).Error; err != nil {
+		return nil, fmt.Errorf("failed to list job delivery failures: %w", err)
+	}
+	return failures, nil
+}
+
+// ListDeadletteredJobs returns deadlettered jobs, most recently set aside
+// first.
+func (ps PostgresDbStore) ListDeadletteredJobs(ctx context.Context, limit, offset int) ([]models.Job, error) {
+	var jobs []models.Job
+	err := ps.getDB(ctx).Where("status = ?", models.JobStatusDeadlettered).
+		Order("deadlettered_at DESC, job_id DESC").Limit(limit).Offset(offset).Find(&jobs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deadlettered jobs: %w", err)
+	}
+	return jobs, nil
+}
@@ -0,0 +1,127 @@
+package postgres_store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// maxRecentDeadlineMisses caps DeadlineReport.RecentMisses.
+const maxRecentDeadlineMisses = 50
+
+// deadlineStatsColumns is the aggregate select list shared by the overall
+// and per-job-name deadline queries.
+const deadlineStatsColumns = `
+	COUNT(*) AS total,
+	COUNT(*) FILTER (WHERE status = 'completed' AND completed_at <= deadline) AS met,
+	COUNT(*) FILTER (WHERE deadline_missed_at IS NOT NULL) AS missed`
+
+type deadlineStatsRow struct {
+	JobName string `gorm:"column:job_name"`
+	Total   int    `gorm:"column:total"`
+	Met     int    `gorm:"column:met"`
+	Missed  int    `gorm:"column:missed"`
+}
+
+func (r deadlineStatsRow) stats() models.DeadlineStats {
+	return models.DeadlineStats{Total: r.Total, Met: r.Met, Missed: r.Missed, Open: r.Total - r.Met - r.Missed}
+}
+
+// ListMissedDeadlineJobs returns jobs whose deadline fell in [since, now)
+// without their completing successfully by it, and not yet marked missed,
+// oldest deadline first.
+func (ps PostgresDbStore) ListMissedDeadlineJobs(ctx context.Context, since, now time.Time, limit int) ([]models.Job, error) {
+	var jobs []models.Job
+	err := ps.getDB(ctx).
+		Where("deadline >= ? AND deadline < ? AND deadline_missed_at IS NULL", since, now).
+		Where("(status <> 'completed' OR completed_at > deadline)").
+		Order("deadline ASC").Limit(limit).Find(&jobs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list missed deadline jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// MarkJobDeadlineMissed records that the job missed its deadline, unless
+// it was already recorded. Returns whether this call recorded it, so
+// monitors on several replicas alert once.
+func (ps PostgresDbStore) MarkJobDeadlineMissed(ctx context.Context, jobID string, at time.Time) (bool, error) {
+	if !isValidUUID(jobID) {
+		return false, store.ErrNotFound
+	}
+	result := ps.getDB(ctx).Model(&models.Job{}).
+		Where("job_id = ? AND deadline_missed_at IS NULL", jobID).
+		UpdateColumn("deadline_missed_at", at)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to mark job deadline missed: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ListQueuedJobsNearDeadline returns jobs still waiting in Corndogs whose
+// deadline falls in (now, until], nearest deadline first.
+func (ps PostgresDbStore) ListQueuedJobsNearDeadline(ctx context.Context, now, until time.Time, limit int) ([]models.Job, error) {
+	var jobs []models.Job
+	err := ps.getDB(ctx).
+		Where("deadline > ? AND deadline <= ? AND deadline_missed_at IS NULL", now, until).
+		Where("status IN ('submitted', 'queued') AND corndogs_task_id IS NOT NULL").
+		Order("deadline ASC").Limit(limit).Find(&jobs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list queued jobs near their deadline: %w", err)
+	}
+	return jobs, nil
+}
+
+// GetProjectDeadlineReport summarizes the project's jobs with deadlines in
+// [since, until): overall, per job name, and the most recent misses.
+func (ps PostgresDbStore) GetProjectDeadlineReport(ctx context.Context, projectID string, since, until time.Time) (*models.DeadlineReport, error) {
+	if !isValidUUID(projectID) {
+		return nil, store.ErrNotFound
+	}
+	where := "project_id = ? AND deleted_at IS NULL AND deadline >= ? AND deadline < ?"
+	args := []interface{}{projectID, since, until}
+
+	var overall deadlineStatsRow
+	if err := ps.getDB(ctx).Raw("SELECT"+deadlineStatsColumns+" FROM jobs WHERE "+where, args...).
+		Scan(&overall).Error; err != nil {
+		return nil, fmt.Errorf("failed to compute deadline report: %w", err)
+	}
+
+	var byName []deadlineStatsRow
+	if err := ps.getDB(ctx).Raw("SELECT name AS job_name,"+deadlineStatsColumns+" FROM jobs WHERE "+where+
+		" GROUP BY name ORDER BY missed DESC, name", args...).Scan(&byName).Error; err != nil {
+		return nil, fmt.Errorf("failed to compute per-job deadline report: %w", err)
+	}
+
+	var misses []models.Job
+	if err := ps.getDB(ctx).Where(where+" AND deadline_missed_at IS NOT NULL", args...).
+		Order("deadline DESC").Limit(maxRecentDeadlineMisses).Find(&misses).Error; err != nil {
+		return nil, fmt.Errorf("failed to list deadline misses: %w", err)
+	}
+
+	report := &models.DeadlineReport{
+		ProjectID:    projectID,
+		Since:        since,
+		Until:        until,
+		Overall:      overall.stats(),
+		Jobs:         make([]models.DeadlineJobNameStats, 0, len(byName)),
+		RecentMisses: make([]models.DeadlineMiss, 0, len(misses)),
+	}
+	for _, row := range byName {
+		report.Jobs = append(report.Jobs, models.DeadlineJobNameStats{JobName: row.JobName, DeadlineStats: row.stats()})
+	}
+	for _, job := range misses {
+		report.RecentMisses = append(report.RecentMisses, models.DeadlineMiss{
+			JobID:            job.JobID,
+			Name:             job.Name,
+			Status:           job.Status,
+			Deadline:         *job.Deadline,
+			DeadlineMissedAt: *job.DeadlineMissedAt,
+			CompletedAt:      job.CompletedAt,
+		})
+	}
+	return report, nil
+}
@@ -0,0 +1,87 @@
+package postgres_store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// CreateDebugSession records a worker's live debug session for a failed
+// job. Returns store.ErrAlreadyExists if the job already has one.
+func (ps PostgresDbStore) CreateDebugSession(ctx context.Context, session *models.DebugSession) error {
+	if !isValidUUID(session.JobID) || session.BridgeURL == "" || session.BridgeToken == "" {
+		return store.ErrInvalidInput
+	}
+	session.Status = models.DebugSessionActive
+	result := ps.getDB(ctx).Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "job_id"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "status = 'active'"}}},
+		DoNothing:   true,
+	}).Create(session)
+	if result.Error != nil {
+		return fmt.Errorf("failed to create debug session: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrAlreadyExists
+	}
+	return nil
+}
+
+// GetDebugSession returns a debug session by ID.
+func (ps PostgresDbStore) GetDebugSession(ctx context.Context, sessionID string) (*models.DebugSession, error) {
+	if !isValidUUID(sessionID) {
+		return nil, store.ErrNotFound
+	}
+	var session models.DebugSession
+	if err := ps.getDB(ctx).Where("session_id = ?", sessionID).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, store.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get debug session: %w", err)
+	}
+	return &session, nil
+}
+
+// GetActiveDebugSession returns the job's active debug session.
+func (ps PostgresDbStore) GetActiveDebugSession(ctx context.Context, jobID string) (*models.DebugSession, error) {
+	if !isValidUUID(jobID) {
+		return nil, store.ErrNotFound
+	}
+	var session models.DebugSession
+	err := ps.getDB(ctx).Where("job_id = ? AND status = ?", jobID, models.DebugSessionActive).First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, store.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get active debug session: %w", err)
+	}
+	return &session, nil
+}
+
+// EndDebugSession moves an active session to status (closed or expired)
+// and reports whether it was still active.
+func (ps PostgresDbStore) EndDebugSession(ctx context.Context, sessionID, status string) (bool, error) {
+	if !isValidUUID(sessionID) {
+		return false, store.ErrNotFound
+	}
+	if status != models.DebugSessionClosed && status != models.DebugSessionExpired {
+		return false, store.ErrInvalidInput
+	}
+	result := ps.getDB(ctx).Model(&models.DebugSession{}).
+		Where("session_id = ? AND status = ?", sessionID, models.DebugSessionActive).
+		Updates(map[string]interface{}{
+			"status":    status,
+			"closed_at": time.Now().UTC(),
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to end debug session: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
@@ -0,0 +1,77 @@
+package postgres_store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"gorm.io/gorm"
+)
+
+func (ps PostgresDbStore) CreateDownstreamTrigger(ctx context.Context, trigger *models.DownstreamTrigger) error {
+	if err := ps.getDB(ctx).Create(trigger).Error; err != nil {
+		return fmt.Errorf("failed to create downstream trigger: %w", err)
+	}
+	return nil
+}
+
+// ListDownstreamTriggers returns the triggers a project's jobs fire.
+func (ps PostgresDbStore) ListDownstreamTriggers(ctx context.Context, projectID string) ([]models.DownstreamTrigger, error) {
+	var triggers []models.DownstreamTrigger
+	if err := ps.getDB(ctx).Where("project_id = ?", projectID).Order("created_at ASC").Find(&triggers).Error; err != nil {
+		return nil, fmt.Errorf("failed to list downstream triggers: %w", err)
+	}
+	return triggers, nil
+}
+
+// GetDownstreamTrigger returns one of a project's downstream triggers.
+func (ps PostgresDbStore) GetDownstreamTrigger(ctx context.Context, projectID, triggerID string) (*models.DownstreamTrigger, error) {
+	if !isValidUUID(triggerID) {
+		return nil, store.ErrNotFound
+	}
+	var trigger models.DownstreamTrigger
+	if err := ps.getDB(ctx).Where("project_id = ? AND trigger_id = ?", projectID, triggerID).First(&trigger).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, store.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get downstream trigger: %w", err)
+	}
+	return &trigger, nil
+}
+
+func (ps PostgresDbStore) DeleteDownstreamTrigger(ctx context.Context, projectID, triggerID string) error {
+	if !isValidUUID(triggerID) {
+		return store.ErrNotFound
+	}
+	result := ps.getDB(ctx).
+		Where("project_id = ? AND trigger_id = ?", projectID, triggerID).
+		Delete(&models.DownstreamTrigger{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete downstream trigger: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// DownstreamTriggerGraph returns every trigger as an edge from its project
+// to its downstream project, for cycle detection.
+func (ps PostgresDbStore) DownstreamTriggerGraph(ctx context.Context) (map[string][]string, error) {
+	var rows []struct {
+		ProjectID           string
+		DownstreamProjectID string
+	}
+	if err := ps.getDB(ctx).Model(&models.DownstreamTrigger{}).
+		Select("project_id, downstream_project_id").
+		Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load downstream trigger graph: %w", err)
+	}
+	edges := map[string][]string{}
+	for _, row := range rows {
+		edges[row.ProjectID] = append(edges[row.ProjectID], row.DownstreamProjectID)
+	}
+	return edges, nil
+}
@@ -0,0 +1,86 @@
+package postgres_store
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+)
+
+// classifyError wraps a Postgres error in a *store.DBError naming the
+// store error it amounts to. Other errors, and SQLSTATEs with no store
+// equivalent, are returned unchanged.
+func classifyError(err error) error {
+	var dbErr *store.DBError
+	if errors.As(err, &dbErr) {
+		return err
+	}
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	kind := sqlStateKind(pgErr.Code)
+	if kind == nil {
+		return err
+	}
+	return &store.DBError{Kind: kind, Code: pgErr.Code, Constraint: pgErr.ConstraintName, Err: err}
+}
+
+// sqlStateKind maps a SQLSTATE to a store error, or nil.
+func sqlStateKind(code string) error {
+	switch code {
+	case "23505", "23P01": // unique_violation, exclusion_violation
+		return store.ErrAlreadyExists
+	case "23503": // foreign_key_violation
+		return store.ErrReferenceViolation
+	case "23502", "23514": // not_null_violation, check_violation
+		return store.ErrInvalidInput
+	case "40001", "40P01", "55P03": // serialization_failure, deadlock_detected, lock_not_available
+		return store.ErrConflict
+	case "57P01", "57P02", "57P03": // admin_shutdown, crash_shutdown, cannot_connect_now
+		return store.ErrServiceUnavailable
+	}
+	switch {
+	case strings.HasPrefix(code, "22"): // data_exception
+		return store.ErrInvalidInput
+	case strings.HasPrefix(code, "08"): // connection_exception
+		return store.ErrServiceUnavailable
+	}
+	return nil
+}
+
+// registerErrorClassification classifies the error of every statement db
+// runs (see classifyError), so store methods wrapping it with %w pass the
+// classification on to handlers.
+func registerErrorClassification(db *gorm.DB) error {
+	classify := func(tx *gorm.DB) {
+		if tx.Error != nil {
+			tx.Error = classifyError(tx.Error)
+		}
+	}
+	const name = "reactorcide:classify_error"
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Create().After("*").Register(name, classify),
+		cb.Query().After("*").Register(name, classify),
+		cb.Update().After("*").Register(name, classify),
+		cb.Delete().After("*").Register(name, classify),
+		cb.Row().After("*").Register(name, classify),
+		cb.Raw().After("*").Register(name, classify),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// transaction runs fn in a transaction, retrying conflicts (see
+// store.RunInTransaction).
+func (ps PostgresDbStore) transaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return store.RunInTransaction(ctx, ps.getDB(ctx), fn)
+}
@@ -0,0 +1,54 @@
+package postgres_store
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		code string
+		want error
+	}{
+		{"23505", store.ErrAlreadyExists},
+		{"23503", store.ErrReferenceViolation},
+		{"23514", store.ErrInvalidInput},
+		{"22P02", store.ErrInvalidInput},
+		{"40001", store.ErrConflict},
+		{"40P01", store.ErrConflict},
+		{"08006", store.ErrServiceUnavailable},
+		{"42P01", nil},
+	}
+	for _, tt := range tests {
+		pgErr := &pgconn.PgError{Code: tt.code, ConstraintName: "c"}
+		err := classifyError(fmt.Errorf("query failed: %w", pgErr))
+
+		var dbErr *store.DBError
+		if tt.want == nil {
+			if errors.As(err, &dbErr) {
+				t.Errorf("%s: should be left unclassified, got %v", tt.code, dbErr.Kind)
+			}
+			continue
+		}
+		if !errors.As(err, &dbErr) || dbErr.Kind != tt.want || dbErr.Code != tt.code || dbErr.Constraint != "c" {
+			t.Errorf("%s: got %#v, want kind %v", tt.code, dbErr, tt.want)
+			continue
+		}
+		if !errors.Is(err, pgErr) {
+			t.Errorf("%s: the driver error should still match", tt.code)
+		}
+		// Wrapping a classified error again is a no-op.
+		if again := classifyError(err); again != err {
+			t.Errorf("%s: classifyError should be idempotent", tt.code)
+		}
+	}
+
+	if err := classifyError(errors.New("plain")); err.Error() != "plain" {
+		t.Errorf("non-Postgres errors should pass through, got %v", err)
+	}
+}
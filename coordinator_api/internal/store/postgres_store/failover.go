@@ -0,0 +1,237 @@
+package postgres_store
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	pgxv4 "github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// dbProbe is what one health check learned about a database.
+type dbProbe struct {
+	err        error // Non-nil if the database couldn't be reached
+	inRecovery bool  // The database is a replica (or a demoted primary)
+}
+
+func (p dbProbe) writable() bool {
+	return p.err == nil && !p.inRecovery
+}
+
+// dbFailover moves the store's connections, both the GORM pool and the pgx
+// pool, from the primary database to a standby when the standby is
+// promoted. Failover is one way: once the standby takes writes, the old
+// primary is fenced off for the life of the process (connections to it are
+// discarded instead of reused), so a primary that comes back can't take
+// writes behind the standby's back. Failing back takes a restart with the
+// URIs swapped.
+//
+// A promoted standby is the signal to fail over even while the primary
+// still answers, since only one of them may take writes. When the primary
+// fails threshold checks in a row, dbFailover promotes the standby itself
+// (pg_promote) if promote is set; otherwise it waits for the platform
+// (Patroni, a managed database's failover) to do it.
+type dbFailover struct {
+	primaryURI string
+	standbyURI string
+	standby    *pgx.ConnConfig
+	standbyV4  *pgxv4.ConnConfig
+	interval   time.Duration
+	threshold  int
+	promote    bool
+
+	failedOver atomic.Bool
+	failures   int
+
+	probe          func(ctx context.Context, uri string) dbProbe
+	promoteStandby func(ctx context.Context, uri string) error
+}
+
+// newDBFailover parses the standby URI. A threshold or interval of zero
+// takes the default.
+func newDBFailover(primaryURI, standbyURI string, interval time.Duration, threshold int, promote bool) (*dbFailover, error) {
+	standby, err := pgx.ParseConfig(standbyURI)
+	if err != nil {
+		return nil, fmt.Errorf("invalid standby database URI: %w", err)
+	}
+	standbyV4, err := pgxv4.ParseConfig(standbyURI)
+	if err != nil {
+		return nil, fmt.Errorf("invalid standby database URI: %w", err)
+	}
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	if threshold <= 0 {
+		threshold = 3
+	}
+	return &dbFailover{
+		primaryURI:     primaryURI,
+		standbyURI:     standbyURI,
+		standby:        standby,
+		standbyV4:      standbyV4,
+		interval:       interval,
+		threshold:      threshold,
+		promote:        promote,
+		probe:          probeDB,
+		promoteStandby: promoteDB,
+	}, nil
+}
+
+// FailedOver reports whether the store has failed over to the standby.
+func (f *dbFailover) FailedOver() bool {
+	return f.failedOver.Load()
+}
+
+// checkStartup fails over before the first connection if the standby was
+// already promoted, e.g. while this process was down.
+func (f *dbFailover) checkStartup(ctx context.Context) {
+	if f.probe(ctx, f.standbyURI).writable() {
+		f.failOver("standby database is already promoted")
+	}
+}
+
+// Start checks the databases every interval until ctx is done or the
+// store fails over.
+func (f *dbFailover) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(f.interval)
+		defer ticker.Stop()
+		for !f.FailedOver() {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				f.check(ctx)
+			}
+		}
+	}()
+}
+
+// check runs one health check of both databases.
+func (f *dbFailover) check(ctx context.Context) {
+	if f.FailedOver() {
+		return
+	}
+	standby := f.probe(ctx, f.standbyURI)
+	if standby.writable() {
+		f.failOver("standby database was promoted")
+		return
+	}
+
+	primary := f.probe(ctx, f.primaryURI)
+	if primary.writable() {
+		f.failures = 0
+		return
+	}
+	f.failures++
+	entry := logging.Log.WithField("failures", f.failures)
+	if primary.err != nil {
+		entry = entry.WithError(primary.err)
+	}
+	if f.failures < f.threshold {
+		entry.Warn("Primary database health check failed")
+		return
+	}
+	if !f.promote {
+		entry.Error("Primary database is down; waiting for the standby to be promoted")
+		return
+	}
+	if standby.err != nil {
+		entry.WithField("standby_error", standby.err.Error()).Error("Primary database is down and the standby is unreachable")
+		return
+	}
+	if err := f.promoteStandby(ctx, f.standbyURI); err != nil {
+		entry.WithField("promote_error", err.Error()).Error("Primary database is down and promoting the standby failed")
+		return
+	}
+	f.failOver("promoted the standby database after the primary failed")
+}
+
+func (f *dbFailover) failOver(reason string) {
+	if f.failedOver.CompareAndSwap(false, true) {
+		logging.Log.WithField("reason", reason).Warn("Failing over to the standby database; the primary is fenced off until restart")
+	}
+}
+
+// beforeConnect points new GORM pool connections at the standby once
+// failed over.
+func (f *dbFailover) beforeConnect(ctx context.Context, config *pgx.ConnConfig) error {
+	if f.FailedOver() {
+		config.Config = f.standby.Config
+	}
+	return nil
+}
+
+// resetSession discards pooled GORM connections to the fenced primary.
+func (f *dbFailover) resetSession(ctx context.Context, conn *pgx.Conn) error {
+	if f.FailedOver() && (conn.Config().Host != f.standby.Host || conn.Config().Port != f.standby.Port) {
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
+// beforeConnectV4 is beforeConnect for the pgx pool.
+func (f *dbFailover) beforeConnectV4(ctx context.Context, config *pgxv4.ConnConfig) error {
+	if f.FailedOver() {
+		config.Config = f.standbyV4.Config
+	}
+	return nil
+}
+
+// beforeAcquireV4 is resetSession for the pgx pool.
+func (f *dbFailover) beforeAcquireV4(ctx context.Context, conn *pgxv4.Conn) bool {
+	return !f.FailedOver() || (conn.Config().Host == f.standbyV4.Host && conn.Config().Port == f.standbyV4.Port)
+}
+
+// openDB opens the GORM pool's database/sql handle with the failover hooks.
+func (f *dbFailover) openDB(uri string) (*sql.DB, error) {
+	config, err := pgx.ParseConfig(uri)
+	if err != nil {
+		return nil, err
+	}
+	return stdlib.OpenDB(*config,
+		stdlib.OptionBeforeConnect(f.beforeConnect),
+		stdlib.OptionResetSession(f.resetSession),
+	), nil
+}
+
+// probeDB connects to uri and asks whether it's in recovery.
+func probeDB(ctx context.Context, uri string) dbProbe {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	conn, err := pgx.Connect(ctx, uri)
+	if err != nil {
+		return dbProbe{err: err}
+	}
+	defer conn.Close(context.Background())
+	var inRecovery bool
+	if err := conn.QueryRow(ctx, "SELECT pg_is_in_recovery()").Scan(&inRecovery); err != nil {
+		return dbProbe{err: err}
+	}
+	return dbProbe{inRecovery: inRecovery}
+}
+
+// promoteDB promotes the standby at uri and waits for it to finish.
+func promoteDB(ctx context.Context, uri string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	conn, err := pgx.Connect(ctx, uri)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+	var promoted bool
+	if err := conn.QueryRow(ctx, "SELECT pg_promote(true, 60)").Scan(&promoted); err != nil {
+		return err
+	}
+	if !promoted {
+		return fmt.Errorf("pg_promote did not finish")
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package postgres_store
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func testFailover(t *testing.T, promote bool) (*dbFailover, map[string]dbProbe, *int) {
+	t.Helper()
+	f, err := newDBFailover("postgres://primary:5432/app", "postgres://standby:5433/app", 0, 2, promote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	probes := map[string]dbProbe{
+		f.primaryURI: {},
+		f.standbyURI: {inRecovery: true},
+	}
+	promotions := 0
+	f.probe = func(ctx context.Context, uri string) dbProbe { return probes[uri] }
+	f.promoteStandby = func(ctx context.Context, uri string) error {
+		promotions++
+		probes[uri] = dbProbe{}
+		return nil
+	}
+	return f, probes, &promotions
+}
+
+func TestDBFailover_FailsOverWhenStandbyPromoted(t *testing.T) {
+	f, probes, _ := testFailover(t, false)
+	f.check(context.Background())
+	if f.FailedOver() {
+		t.Fatal("expected no failover while the standby is a replica")
+	}
+
+	// The standby is promoted while the primary still answers: the primary
+	// must be fenced off.
+	probes[f.standbyURI] = dbProbe{}
+	f.check(context.Background())
+	if !f.FailedOver() {
+		t.Fatal("expected failover once the standby is promoted")
+	}
+}
+
+func TestDBFailover_WaitsForPromotion(t *testing.T) {
+	f, probes, promotions := testFailover(t, false)
+	probes[f.primaryURI] = dbProbe{err: errors.New("connection refused")}
+	for i := 0; i < 5; i++ {
+		f.check(context.Background())
+	}
+	if f.FailedOver() || *promotions != 0 {
+		t.Fatal("expected no failover to an unpromoted standby without promote")
+	}
+}
+
+func TestDBFailover_PromotesAfterThreshold(t *testing.T) {
+	f, probes, promotions := testFailover(t, true)
+	probes[f.primaryURI] = dbProbe{err: errors.New("connection refused")}
+	f.check(context.Background())
+	if f.FailedOver() {
+		t.Fatal("expected no failover below the threshold")
+	}
+
+	// A recovered primary resets the count.
+	probes[f.primaryURI] = dbProbe{}
+	f.check(context.Background())
+	probes[f.primaryURI] = dbProbe{err: errors.New("connection refused")}
+	f.check(context.Background())
+	if f.FailedOver() {
+		t.Fatal("expected the failure count reset by a healthy check")
+	}
+
+	f.check(context.Background())
+	if !f.FailedOver() || *promotions != 1 {
+		t.Fatalf("expected the standby promoted and failed over to, got failed over %v, %d promotions", f.FailedOver(), *promotions)
+	}
+}
+
+func TestDBFailover_RoutesNewConnectionsToStandby(t *testing.T) {
+	f, _, _ := testFailover(t, false)
+	primary, err := f.openDB(f.primaryURI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer primary.Close()
+
+	config := *f.standby
+	config.Host = "primary"
+	if err := f.beforeConnect(context.Background(), &config); err != nil || config.Host != "primary" {
+		t.Fatalf("expected connections to the primary before failover, got %s", config.Host)
+	}
+	f.failOver("test")
+	if err := f.beforeConnect(context.Background(), &config); err != nil || config.Host != "standby" || config.Port != 5433 {
+		t.Errorf("expected connections to the standby after failover, got %s:%d", config.Host, config.Port)
+	}
+}
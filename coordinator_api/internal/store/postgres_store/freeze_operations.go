@@ -0,0 +1,122 @@
+package postgres_store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// ListFreezeWindows returns every freeze window, by name.
+func (ps PostgresDbStore) ListFreezeWindows(ctx context.Context) ([]models.FreezeWindow, error) {
+	var windows []models.FreezeWindow
+	if err := ps.getDB(ctx).Order("name ASC, window_id ASC").Find(&windows).Error; err != nil {
+		return nil, fmt.Errorf("failed to list freeze windows: %w", err)
+	}
+	return windows, nil
+}
+
+// GetFreezeWindow returns a freeze window.
+func (ps PostgresDbStore) GetFreezeWindow(ctx context.Context, windowID string) (*models.FreezeWindow, error) {
+	if !isValidUUID(windowID) {
+		return nil, store.ErrNotFound
+	}
+	var window models.FreezeWindow
+	if err := ps.getDB(ctx).Where("window_id = ?", windowID).First(&window).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, store.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get freeze window: %w", err)
+	}
+	return &window, nil
+}
+
+// CreateFreezeWindow creates a freeze window.
+func (ps PostgresDbStore) CreateFreezeWindow(ctx context.Context, window *models.FreezeWindow) error {
+	if err := window.Validate(); err != nil {
+		return store.ErrInvalidInput
+	}
+	now := time.Now().UTC()
+	window.CreatedAt, window.UpdatedAt = now, now
+	if err := ps.getDB(ctx).Create(window).Error; err != nil {
+		return fmt.Errorf("failed to create freeze window: %w", err)
+	}
+	return nil
+}
+
+// UpdateFreezeWindow saves a freeze window's settings. Returns
+// store.ErrNotFound if it doesn't exist.
+func (ps PostgresDbStore) UpdateFreezeWindow(ctx context.Context, window *models.FreezeWindow) error {
+	if err := window.Validate(); err != nil {
+		return store.ErrInvalidInput
+	}
+	window.UpdatedAt = time.Now().UTC()
+	result := ps.getDB(ctx).Model(&models.FreezeWindow{}).
+		Where("window_id = ?", window.WindowID).
+		Updates(map[string]interface{}{
+			"project_id":       window.ProjectID,
+			"environment":      window.Environment,
+			"name":             window.Name,
+			"reason":           window.Reason,
+			"mode":             window.Mode,
+			"schedule":         window.Schedule,
+			"duration_minutes": window.DurationMinutes,
+			"timezone":         window.Timezone,
+			"manual":           window.Manual,
+			"updated_at":       window.UpdatedAt,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update freeze window: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteFreezeWindow deletes a freeze window. Jobs it held are released
+// by the next sweep unless another window holds them. Returns
+// store.ErrNotFound if it doesn't exist.
+func (ps PostgresDbStore) DeleteFreezeWindow(ctx context.Context, windowID string) error {
+	if !isValidUUID(windowID) {
+		return store.ErrNotFound
+	}
+	result := ps.getDB(ctx).Where("window_id = ?", windowID).Delete(&models.FreezeWindow{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete freeze window: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// CreateFreezeOverride records an admin releasing a held deploy job.
+func (ps PostgresDbStore) CreateFreezeOverride(ctx context.Context, override *models.FreezeOverride) error {
+	if override.JobID == "" || override.Reason == "" {
+		return store.ErrInvalidInput
+	}
+	override.CreatedAt = time.Now().UTC()
+	if err := ps.getDB(ctx).Create(override).Error; err != nil {
+		return fmt.Errorf("failed to record freeze override: %w", err)
+	}
+	return nil
+}
+
+// ListFreezeOverrides returns the most recent freeze overrides, newest
+// first.
+func (ps PostgresDbStore) ListFreezeOverrides(ctx context.Context, limit, offset int) ([]models.FreezeOverride, error) {
+	var overrides []models.FreezeOverride
+	err := ps.getDB(ctx).Order("created_at DESC, override_id DESC").
+		Limit(limit).Offset(offset).
+		Find(&overrides).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list freeze overrides: %w", err)
+	}
+	return overrides, nil
+}
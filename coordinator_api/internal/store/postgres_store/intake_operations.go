@@ -0,0 +1,72 @@
+package postgres_store
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm/clause"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// ListIntakePauses returns every active intake pause, global first.
+func (ps PostgresDbStore) ListIntakePauses(ctx context.Context) ([]models.IntakePause, error) {
+	var pauses []models.IntakePause
+	err := ps.getDB(ctx).
+		Order("CASE scope WHEN 'global' THEN 0 WHEN 'queue' THEN 1 ELSE 2 END, target").
+		Find(&pauses).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list intake pauses: %w", err)
+	}
+	return pauses, nil
+}
+
+// MatchIntakePause returns the active pause covering a job in queueName
+// and projectID, global first, or nil if there is none. It reads only the
+// pauses that could match, by primary key, since it runs on every job
+// submission.
+func (ps PostgresDbStore) MatchIntakePause(ctx context.Context, queueName, projectID string) (*models.IntakePause, error) {
+	var pauses []models.IntakePause
+	err := ps.getDB(ctx).
+		Where("scope = ? OR (scope = ? AND target = ?) OR (scope = ? AND target = ?)",
+			models.IntakeScopeGlobal, models.IntakeScopeQueue, queueName, models.IntakeScopeProject, projectID).
+		Order("CASE scope WHEN 'global' THEN 0 WHEN 'queue' THEN 1 ELSE 2 END").
+		Limit(1).Find(&pauses).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to match intake pause: %w", err)
+	}
+	if len(pauses) == 0 {
+		return nil, nil
+	}
+	return &pauses[0], nil
+}
+
+// SetIntakePause creates the pause, or replaces the reason and banner of an
+// existing pause on the same scope and target.
+func (ps PostgresDbStore) SetIntakePause(ctx context.Context, pause *models.IntakePause) error {
+	if !models.IsValidIntakeScope(pause.Scope) {
+		return store.ErrInvalidInput
+	}
+	err := ps.getDB(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "scope"}, {Name: "target"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reason", "banner", "paused_by", "paused_at"}),
+	}).Create(pause).Error
+	if err != nil {
+		return fmt.Errorf("failed to set intake pause: %w", err)
+	}
+	return nil
+}
+
+// DeleteIntakePause removes a pause. Returns store.ErrNotFound if there
+// was none.
+func (ps PostgresDbStore) DeleteIntakePause(ctx context.Context, scope, target string) error {
+	result := ps.getDB(ctx).Where("scope = ? AND target = ?", scope, target).Delete(&models.IntakePause{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete intake pause: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package postgres_store
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm/clause"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// SaveJobConfigSnapshot records the configuration a job ran with. Snapshots
+// are immutable: if the job already has one (a redelivered job starting
+// again), it is kept and this call is a no-op. Returns whether this call
+// saved it.
+func (ps PostgresDbStore) SaveJobConfigSnapshot(ctx context.Context, snapshot *models.JobConfigSnapshot) (bool, error) {
+	if !isValidUUID(snapshot.JobID) {
+		return false, store.ErrInvalidInput
+	}
+	result := ps.getDB(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(snapshot)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to save job config snapshot: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// GetJobConfigSnapshot returns the configuration a job ran with, or
+// store.ErrNotFound if it has none: it never started, or started before
+// snapshots were recorded.
+func (ps PostgresDbStore) GetJobConfigSnapshot(ctx context.Context, jobID string) (*models.JobConfigSnapshot, error) {
+	if !isValidUUID(jobID) {
+		return nil, store.ErrNotFound
+	}
+	var snapshots []models.JobConfigSnapshot
+	if err := ps.getDB(ctx).Where("job_id = ?", jobID).Limit(1).Find(&snapshots).Error; err != nil {
+		return nil, fmt.Errorf("failed to get job config snapshot: %w", err)
+	}
+	if len(snapshots) == 0 {
+		return nil, store.ErrNotFound
+	}
+	return &snapshots[0], nil
+}
@@ -0,0 +1,36 @@
+package postgres_store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// ListJobsForExport lists up to limit jobs matching filter, archived ones
+// included, oldest first: by created_at, then job_id, so keyset pages
+// neither skip nor repeat jobs.
+func (ps PostgresDbStore) ListJobsForExport(ctx context.Context, filter store.JobExportFilter, limit int) ([]models.Job, error) {
+	jobs := []models.Job{}
+	query := ps.getDB(ctx).Table(jobsWithArchiveSQL + " jobs")
+	if filter.ProjectID != "" {
+		if !isValidUUID(filter.ProjectID) {
+			return jobs, nil
+		}
+		query = query.Where("project_id = ?", filter.ProjectID)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		query = query.Where("created_at >= ?", filter.CreatedFrom)
+	}
+	if !filter.CreatedBefore.IsZero() {
+		query = query.Where("created_at < ?", filter.CreatedBefore)
+	}
+	if filter.After != nil {
+		query = query.Where("(created_at, job_id) > (?, ?)", filter.After.CreatedAt, filter.After.JobID)
+	}
+	if err := query.Order("created_at, job_id").Limit(limit).Find(&jobs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list jobs for export: %w", err)
+	}
+	return jobs, nil
+}
@@ -2,6 +2,7 @@ package postgres_store
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -28,7 +29,8 @@ import (
 // Returns (nil, false, nil) — not an error — when the row's status wasn't
 // in fromStatuses by the time the lock was acquired; this is an expected
 // outcome under concurrency (someone else already moved the row on), not a
-// failure. Returns store.ErrNotFound if the row doesn't exist at all.
+// failure. Returns store.ErrNotFound if the row doesn't exist at all. A
+// deadlock retries the whole transaction, so apply may run more than once.
 func (ps PostgresDbStore) UpdateJobStatusGuarded(ctx context.Context, jobID string, fromStatuses []string, apply func(*models.Job)) (*models.Job, bool, error) {
 	if !isValidUUID(jobID) {
 		return nil, false, store.ErrNotFound
@@ -37,11 +39,12 @@ func (ps PostgresDbStore) UpdateJobStatusGuarded(ctx context.Context, jobID stri
 	var result *models.Job
 	matched := false
 
-	err := ps.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+	err := ps.transaction(ctx, func(tx *gorm.DB) error {
+		result, matched = nil, false
 		var job models.Job
 		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
 			Where("job_id = ?", jobID).First(&job).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
 				return store.ErrNotFound
 			}
 			return fmt.Errorf("failed to load job %s for guarded update: %w", jobID, err)
@@ -2,6 +2,7 @@ package postgres_store
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
@@ -34,8 +35,10 @@ func (ps PostgresDbStore) GetJobByID(ctx context.Context, jobID string) (*models
 	var job models.Job
 
 	if err := ps.getDB(ctx).Where("job_id = ?", jobID).First(&job).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, store.ErrNotFound
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Links to archived jobs (parents, workflow nodes, webhook
+			// events) still resolve.
+			return ps.getArchivedJob(ctx, jobID)
 		}
 		return nil, fmt.Errorf("failed to get job %s: %w", jobID, err)
 	}
@@ -63,7 +66,24 @@ func (ps PostgresDbStore) UpdateJob(ctx context.Context, job *models.Job) error
 	return nil
 }
 
-// DeleteJob deletes a job by its ID
+// SetJobTriggerValidation stores (or clears, when record is nil) a job's
+// trigger validation result. It writes only that column, so it can't
+// overwrite a status change the worker makes concurrently.
+func (ps PostgresDbStore) SetJobTriggerValidation(ctx context.Context, jobID string, record models.JSONB) error {
+	if !isValidUUID(jobID) {
+		return store.ErrNotFound
+	}
+	result := ps.getDB(ctx).Model(&models.Job{}).Where("job_id = ?", jobID).UpdateColumn("trigger_validation", record)
+	if result.Error != nil {
+		return fmt.Errorf("failed to set job trigger validation: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteJob moves a job to the trash (see trash_operations.go)
 func (ps PostgresDbStore) DeleteJob(ctx context.Context, jobID string) error {
 	if !isValidUUID(jobID) {
 		return store.ErrNotFound
@@ -84,6 +104,9 @@ func (ps PostgresDbStore) ListJobs(ctx context.Context, filters map[string]inter
 	var jobs []models.Job
 
 	query := ps.getDB(ctx).Model(&models.Job{})
+	if includeArchived, _ := filters[store.IncludeArchivedFilter].(bool); includeArchived {
+		query = ps.getDB(ctx).Table(jobsWithArchiveSQL + " jobs")
+	}
 
 	// Apply filters
 	for key, value := range filters {
@@ -100,11 +123,21 @@ func (ps PostgresDbStore) ListJobs(ctx context.Context, filters map[string]inter
 			query = query.Where("project_id = ?", value)
 		case "workflow_id":
 			query = query.Where("workflow_id = ?", value)
+		case "parent_job_id":
+			query = query.Where("parent_job_id = ?", value)
+		case "upstream_job_id":
+			query = query.Where("upstream_job_id = ?", value)
+		case "group_id":
+			query = query.Where("project_id IN (SELECT project_id FROM projects WHERE group_id = ?)", value)
+		case store.JobCursorFilter:
+			cursor := value.(store.JobCursor)
+			query = query.Where("(created_at, job_id) < (?, ?)", cursor.CreatedAt, cursor.JobID)
 		}
 	}
 
-	// Apply pagination and ordering
-	query = query.Order("created_at DESC").
+	// Apply pagination and ordering. job_id breaks created_at ties so
+	// keyset pages neither skip nor repeat jobs.
+	query = query.Order("created_at DESC, job_id DESC").
 		Limit(limit).
 		Offset(offset)
 
@@ -114,3 +147,28 @@ func (ps PostgresDbStore) ListJobs(ctx context.Context, filters map[string]inter
 
 	return jobs, nil
 }
+
+// CountChildJobsByStatus counts the jobs spawned by parentJobID, by status.
+// Unlike ListJobs it isn't capped, so roll-ups cover every child of a wide
+// fan-out.
+func (ps PostgresDbStore) CountChildJobsByStatus(ctx context.Context, parentJobID string) (map[string]int, error) {
+	counts := map[string]int{}
+	if !isValidUUID(parentJobID) {
+		return counts, nil
+	}
+	var rows []struct {
+		Status string
+		Count  int
+	}
+	if err := ps.getDB(ctx).Model(&models.Job{}).
+		Select("status, COUNT(*) AS count").
+		Where("parent_job_id = ?", parentJobID).
+		Group("status").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count child jobs: %w", err)
+	}
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
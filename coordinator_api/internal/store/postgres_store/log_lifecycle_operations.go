@@ -0,0 +1,81 @@
+package postgres_store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// ListJobsForLogLifecycle returns up to limit finished jobs that completed
+// before completedBefore and whose logs are in one of tiers ("" for jobs
+// the log lifecycle manager hasn't seen yet), oldest first: by created_at,
+// then job_id, after the after cursor if set.
+func (ps PostgresDbStore) ListJobsForLogLifecycle(ctx context.Context, tiers []string, completedBefore time.Time, after *store.JobCursor, limit int) ([]models.Job, error) {
+	var jobs []models.Job
+	query := ps.getDB(ctx).
+		Where("completed_at IS NOT NULL AND completed_at < ?", completedBefore).
+		Where("logs_tier IN ?", tiers).
+		Where("status IN ?", []string{"completed", "failed", "cancelled", "timeout"})
+	if after != nil {
+		query = query.Where("(created_at, job_id) > (?, ?)", after.CreatedAt, after.JobID)
+	}
+	err := query.Order("created_at, job_id").
+		Limit(limit).
+		Find(&jobs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs for log lifecycle: %w", err)
+	}
+	return jobs, nil
+}
+
+// SetJobLogsTier records that the job's logs are now in tier, totalling
+// bytes, with logsObjectKey their new primary key. Like
+// TouchJobLogActivity it leaves updated_at alone.
+func (ps PostgresDbStore) SetJobLogsTier(ctx context.Context, jobID, tier string, bytes int64, logsObjectKey string, at time.Time) error {
+	if !isValidUUID(jobID) {
+		return store.ErrNotFound
+	}
+	result := ps.getDB(ctx).Model(&models.Job{}).Where("job_id = ?", jobID).
+		UpdateColumns(map[string]interface{}{
+			"logs_tier":       tier,
+			"logs_bytes":      bytes,
+			"logs_object_key": logsObjectKey,
+			"logs_tiered_at":  at,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update job logs tier: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// ListProjectStorageUsage sums the log storage of jobs per project, hot
+// and cold, largest first. projectID, when non-empty, restricts it to one
+// project.
+func (ps PostgresDbStore) ListProjectStorageUsage(ctx context.Context, projectID string) ([]models.ProjectStorageUsage, error) {
+	query := ps.getDB(ctx).Model(&models.Job{}).
+		Where("logs_tier IN ?", []string{models.LogsTierHot, models.LogsTierCold})
+	if projectID != "" {
+		if !isValidUUID(projectID) {
+			return []models.ProjectStorageUsage{}, nil
+		}
+		query = query.Where("project_id = ?", projectID)
+	}
+
+	var usage []models.ProjectStorageUsage
+	err := query.Select("project_id::text AS project_id, COUNT(*) AS jobs, " +
+		"COALESCE(SUM(logs_bytes) FILTER (WHERE logs_tier = 'hot'), 0) AS hot_bytes, " +
+		"COALESCE(SUM(logs_bytes) FILTER (WHERE logs_tier = 'cold'), 0) AS cold_bytes").
+		Group("project_id").
+		Order("SUM(logs_bytes) DESC, project_id").
+		Scan(&usage).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute project storage usage: %w", err)
+	}
+	return usage, nil
+}
@@ -0,0 +1,94 @@
+package postgres_store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm/clause"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// EnqueueMergeQueueEntry adds a PR to the end of its project's merge queue.
+// Returns store.ErrAlreadyExists if the PR is already queued or testing.
+func (ps PostgresDbStore) EnqueueMergeQueueEntry(ctx context.Context, entry *models.MergeQueueEntry) error {
+	if !isValidUUID(entry.ProjectID) || entry.PRNumber <= 0 {
+		return store.ErrInvalidInput
+	}
+	entry.Status = models.MergeQueueQueued
+	result := ps.getDB(ctx).Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "project_id"}, {Name: "pr_number"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "status IN ('queued', 'testing')"}}},
+		DoNothing:   true,
+	}).Create(entry)
+	if result.Error != nil {
+		return fmt.Errorf("failed to enqueue merge queue entry: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrAlreadyExists
+	}
+	return nil
+}
+
+// ListMergeQueueEntries returns a project's merge queue. With activeOnly,
+// it returns the queued and testing entries in queue order; otherwise the
+// most recent limit entries of any status, newest first.
+func (ps PostgresDbStore) ListMergeQueueEntries(ctx context.Context, projectID string, activeOnly bool, limit int) ([]models.MergeQueueEntry, error) {
+	if !isValidUUID(projectID) {
+		return nil, store.ErrNotFound
+	}
+	query := ps.getDB(ctx).Where("project_id = ?", projectID)
+	if activeOnly {
+		query = query.Where("status IN ?", []string{models.MergeQueueQueued, models.MergeQueueTesting}).Order("created_at ASC")
+	} else {
+		query = query.Order("created_at DESC").Limit(limit)
+	}
+	var entries []models.MergeQueueEntry
+	if err := query.Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to list merge queue entries: %w", err)
+	}
+	return entries, nil
+}
+
+// ListMergeQueueProjects returns the IDs of projects with active merge
+// queue entries.
+func (ps PostgresDbStore) ListMergeQueueProjects(ctx context.Context) ([]string, error) {
+	var projectIDs []string
+	err := ps.getDB(ctx).Model(&models.MergeQueueEntry{}).
+		Where("status IN ?", []string{models.MergeQueueQueued, models.MergeQueueTesting}).
+		Distinct().Pluck("project_id", &projectIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list merge queue projects: %w", err)
+	}
+	return projectIDs, nil
+}
+
+// UpdateMergeQueueEntry saves the entry if its status is still fromStatus,
+// and reports whether it did. Moving an entry to testing also requires that
+// no other entry of the project is testing, which serializes merges across
+// coordinator replicas.
+func (ps PostgresDbStore) UpdateMergeQueueEntry(ctx context.Context, entry *models.MergeQueueEntry, fromStatus string) (bool, error) {
+	entry.UpdatedAt = time.Now().UTC()
+	query := ps.getDB(ctx).Model(&models.MergeQueueEntry{}).
+		Where("entry_id = ? AND status = ?", entry.EntryID, fromStatus)
+	if entry.Status == models.MergeQueueTesting && fromStatus != models.MergeQueueTesting {
+		query = query.Where("NOT EXISTS (SELECT 1 FROM merge_queue_entries t WHERE t.project_id = ? AND t.status = ?)",
+			entry.ProjectID, models.MergeQueueTesting)
+	}
+	result := query.Updates(map[string]interface{}{
+		"status":          entry.Status,
+		"head_sha":        entry.HeadSHA,
+		"tested_sha":      entry.TestedSHA,
+		"job_id":          entry.JobID,
+		"error":           entry.Error,
+		"lookup_failures": entry.LookupFailures,
+		"updated_at":      entry.UpdatedAt,
+		"finished_at":     entry.FinishedAt,
+	})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to update merge queue entry: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
@@ -0,0 +1,139 @@
+package postgres_store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// noteColumn returns the notes column holding a target's ID.
+func noteColumn(target string) (string, error) {
+	switch target {
+	case models.NoteTargetJob:
+		return "job_id", nil
+	case models.NoteTargetProject:
+		return "project_id", nil
+	}
+	return "", store.ErrInvalidInput
+}
+
+// ListNotes returns the notes on a job or project (target is
+// models.NoteTargetJob or models.NoteTargetProject), newest first.
+func (ps PostgresDbStore) ListNotes(ctx context.Context, target, targetID string) ([]models.Note, error) {
+	column, err := noteColumn(target)
+	if err != nil {
+		return nil, err
+	}
+	notes := []models.Note{}
+	if !isValidUUID(targetID) {
+		return notes, nil
+	}
+	if err := ps.getDB(ctx).Where(column+" = ?", targetID).Order("created_at DESC, note_id DESC").Find(&notes).Error; err != nil {
+		return nil, fmt.Errorf("failed to list notes: %w", err)
+	}
+	return notes, nil
+}
+
+// LatestNotes returns the newest note on each of the targetIDs that has
+// one, by target ID, in one query.
+func (ps PostgresDbStore) LatestNotes(ctx context.Context, target string, targetIDs []string) (map[string]models.Note, error) {
+	column, err := noteColumn(target)
+	if err != nil {
+		return nil, err
+	}
+	latest := make(map[string]models.Note)
+	ids := make([]string, 0, len(targetIDs))
+	for _, id := range targetIDs {
+		if isValidUUID(id) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return latest, nil
+	}
+	var notes []models.Note
+	if err := ps.getDB(ctx).
+		Select("DISTINCT ON ("+column+") *").
+		Where(column+" IN ?", ids).
+		Order(column + ", created_at DESC, note_id DESC").
+		Find(&notes).Error; err != nil {
+		return nil, fmt.Errorf("failed to get latest notes: %w", err)
+	}
+	for _, note := range notes {
+		_, id := note.Target()
+		latest[id] = note
+	}
+	return latest, nil
+}
+
+// GetNote returns a note.
+func (ps PostgresDbStore) GetNote(ctx context.Context, noteID string) (*models.Note, error) {
+	if !isValidUUID(noteID) {
+		return nil, store.ErrNotFound
+	}
+	var note models.Note
+	if err := ps.getDB(ctx).Where("note_id = ?", noteID).First(&note).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, store.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get note: %w", err)
+	}
+	return &note, nil
+}
+
+// CreateNote creates a note.
+func (ps PostgresDbStore) CreateNote(ctx context.Context, note *models.Note) error {
+	if err := note.Validate(); err != nil {
+		return store.ErrInvalidInput
+	}
+	now := time.Now().UTC()
+	note.CreatedAt, note.UpdatedAt = now, now
+	if err := ps.getDB(ctx).Create(note).Error; err != nil {
+		return fmt.Errorf("failed to create note: %w", err)
+	}
+	return nil
+}
+
+// UpdateNote saves a note's body. Returns store.ErrNotFound if it doesn't
+// exist.
+func (ps PostgresDbStore) UpdateNote(ctx context.Context, note *models.Note) error {
+	if err := note.Validate(); err != nil {
+		return store.ErrInvalidInput
+	}
+	note.UpdatedAt = time.Now().UTC()
+	result := ps.getDB(ctx).Model(&models.Note{}).
+		Where("note_id = ?", note.NoteID).
+		Updates(map[string]interface{}{
+			"body":       note.Body,
+			"updated_at": note.UpdatedAt,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update note: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteNote deletes a note. Returns store.ErrNotFound if it doesn't
+// exist.
+func (ps PostgresDbStore) DeleteNote(ctx context.Context, noteID string) error {
+	if !isValidUUID(noteID) {
+		return store.ErrNotFound
+	}
+	result := ps.getDB(ctx).Where("note_id = ?", noteID).Delete(&models.Note{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete note: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package postgres_store
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log"
 	"os"
@@ -10,7 +11,9 @@ import (
 
 	"github.com/catalystcommunity/app-utils-go/env"
 	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/chaos"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/metrics"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/ctxkey"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v4/log/logrusadapter"
@@ -48,6 +51,7 @@ var (
 	PostgresStore = PostgresDbStore{}
 	db            *gorm.DB
 	pgxPool       *pgxpool.Pool
+	failover      *dbFailover
 )
 
 type PostgresDbStore struct{}
@@ -64,6 +68,15 @@ func PgxPool() *pgxpool.Pool {
 	return pgxPool
 }
 
+// DatabaseFailover reports whether a standby database is configured and
+// whether the store has failed over to it.
+func (s PostgresDbStore) DatabaseFailover() (configured, failedOver bool) {
+	if failover == nil {
+		return false, false
+	}
+	return true, failover.FailedOver()
+}
+
 // getDB returns either the transaction from the context or the global DB
 func (ps PostgresDbStore) getDB(ctx context.Context) *gorm.DB {
 	return GetDBFromContext(ctx)
@@ -81,8 +94,12 @@ func GetDBFromContext(ctx context.Context) *gorm.DB {
 		return tx
 	}
 
-	// Otherwise return the global DB
-	return db
+	// Otherwise the global DB, bound to ctx so cancelling ctx cancels the
+	// query
+	if db != nil {
+		return db.WithContext(ctx)
+	}
+	return nil
 }
 
 func (s PostgresDbStore) Initialize() (func(), error) {
@@ -104,6 +121,18 @@ func (s PostgresDbStore) Initialize() (func(), error) {
 	}
 	pgxpoolConfig.ConnConfig.Logger = logrusadapter.NewLogger(logrusLogger)
 
+	if config.DbStandbyUri != "" {
+		failover, err = newDBFailover(uri, config.DbStandbyUri,
+			time.Duration(config.DbFailoverCheckSeconds)*time.Second,
+			config.DbFailoverThreshold, config.DbPromoteStandby)
+		if err != nil {
+			return nil, err
+		}
+		failover.checkStartup(context.Background())
+		pgxpoolConfig.BeforeConnect = failover.beforeConnectV4
+		pgxpoolConfig.BeforeAcquire = failover.beforeAcquireV4
+	}
+
 	// Retry connection with backoff
 	for attempt := 1; attempt <= maxRetries; attempt++ {
 		pgxPool, err = pgxpool.ConnectConfig(context.Background(), pgxpoolConfig)
@@ -121,12 +150,44 @@ func (s PostgresDbStore) Initialize() (func(), error) {
 	nowFunc := func() time.Time {
 		return time.Now().UTC()
 	}
-	db, err = gorm.Open(postgres.Open(uri), &gorm.Config{Logger: gormLogger, NowFunc: nowFunc})
+	dialector := postgres.Open(uri)
+	if failover != nil {
+		sqlDB, err := failover.openDB(uri)
+		if err != nil {
+			pgxPool.Close()
+			return nil, err
+		}
+		dialector = postgres.New(postgres.Config{Conn: sqlDB})
+	}
+	db, err = gorm.Open(dialector, &gorm.Config{Logger: gormLogger, NowFunc: nowFunc})
 	if err != nil {
 		pgxPool.Close()
 		return nil, err
 	}
+	if err := registerErrorClassification(db); err != nil {
+		pgxPool.Close()
+		return nil, err
+	}
+	if err := chaos.RegisterDBLatency(db); err != nil {
+		pgxPool.Close()
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		pgxPool.Close()
+		return nil, err
+	}
+	configurePool(sqlDB)
+	if err := metrics.RegisterDBStats(sqlDB, "reactorcide"); err != nil {
+		logging.Log.WithError(err).Warn("Failed to register database pool metrics")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if failover != nil {
+		failover.Start(ctx)
+	}
 	return func() {
+		cancel()
 		pgxPool.Close()
 	}, nil
 }
@@ -136,7 +197,7 @@ func getLogger() logger.Interface {
 	logLevel := env.GetEnvOrDefault("SQL_LOGGER_LEVEL", "error")
 	ignoreRecordNotFound := env.GetEnvAsBoolOrDefault("SQL_LOGGER_IGNORE_RECORD_NOT_FOUND", "true")
 	colorful := env.GetEnvAsBoolOrDefault("SQL_LOGGER_COLORFUL_LOGS", "true")
-	return logger.New(
+	gormLogger := logger.New(
 		log.New(os.Stdout, "\r\n", log.LstdFlags), // io writer
 		logger.Config{
 			SlowThreshold:             time.Duration(slowThresholdSeconds) * time.Second, // Slow SQL threshold
@@ -145,6 +206,17 @@ func getLogger() logger.Interface {
 			Colorful:                  colorful,                                          // Disable color
 		},
 	)
+	return newSlowQueryLogger(gormLogger,
+		time.Duration(config.DbSlowQueryMillis)*time.Millisecond,
+		config.DbSlowQueryExplainRate, explainGenericPlan)
+}
+
+// configurePool applies the connection pool settings from config.
+func configurePool(sqlDB *sql.DB) {
+	sqlDB.SetMaxOpenConns(config.DbMaxOpenConns)
+	sqlDB.SetMaxIdleConns(config.DbMaxIdleConns)
+	sqlDB.SetConnMaxLifetime(time.Duration(config.DbConnMaxLifetimeMinutes) * time.Minute)
+	sqlDB.SetConnMaxIdleTime(time.Duration(config.DbConnMaxIdleTimeMinutes) * time.Minute)
 }
 
 // isValidUUID returns true if the given string is a valid UUID.
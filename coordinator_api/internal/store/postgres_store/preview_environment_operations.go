@@ -0,0 +1,122 @@
+package postgres_store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// RegisterPreviewEnvironment records a PR's preview environment as active,
+// or refreshes the active one of the same name (URL, head SHA, deploy job
+// and teardown command) when the PR is updated.
+func (ps PostgresDbStore) RegisterPreviewEnvironment(ctx context.Context, env *models.PreviewEnvironment) error {
+	if env.VCSRepo == "" || env.PRNumber <= 0 || env.Name == "" {
+		return store.ErrInvalidInput
+	}
+	if env.ProjectID != nil && !isValidUUID(*env.ProjectID) {
+		return store.ErrInvalidInput
+	}
+	env.Status = models.PreviewEnvironmentActive
+	env.UpdatedAt = time.Now().UTC()
+	result := ps.getDB(ctx).Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "vcs_repo"}, {Name: "pr_number"}, {Name: "name"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "status = 'active'"}}},
+		DoUpdates:   clause.AssignmentColumns([]string{"project_id", "url", "head_sha", "deploy_job_id", "teardown_command", "updated_at"}),
+	}).Create(env)
+	if result.Error != nil {
+		return fmt.Errorf("failed to register preview environment: %w", result.Error)
+	}
+	return nil
+}
+
+// GetPreviewEnvironment returns a preview environment by ID.
+func (ps PostgresDbStore) GetPreviewEnvironment(ctx context.Context, environmentID string) (*models.PreviewEnvironment, error) {
+	if !isValidUUID(environmentID) {
+		return nil, store.ErrNotFound
+	}
+	var env models.PreviewEnvironment
+	if err := ps.getDB(ctx).Where("environment_id = ?", environmentID).First(&env).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, store.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get preview environment: %w", err)
+	}
+	return &env, nil
+}
+
+// GetPreviewEnvironmentByTeardownJob returns the environment a teardown
+// job is tearing down.
+func (ps PostgresDbStore) GetPreviewEnvironmentByTeardownJob(ctx context.Context, jobID string) (*models.PreviewEnvironment, error) {
+	if !isValidUUID(jobID) {
+		return nil, store.ErrNotFound
+	}
+	var env models.PreviewEnvironment
+	if err := ps.getDB(ctx).Where("teardown_job_id = ?", jobID).First(&env).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, store.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get preview environment: %w", err)
+	}
+	return &env, nil
+}
+
+// ListPreviewEnvironmentsForPR returns a PR's environments that can still
+// be torn down (active, or whose last teardown failed).
+func (ps PostgresDbStore) ListPreviewEnvironmentsForPR(ctx context.Context, repo string, prNumber int) ([]models.PreviewEnvironment, error) {
+	var envs []models.PreviewEnvironment
+	err := ps.getDB(ctx).
+		Where("vcs_repo = ? AND pr_number = ? AND status IN ?", repo, prNumber,
+			[]string{models.PreviewEnvironmentActive, models.PreviewEnvironmentTeardownFailed}).
+		Order("created_at ASC").Find(&envs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list preview environments for PR: %w", err)
+	}
+	return envs, nil
+}
+
+// ListProjectPreviewEnvironments returns a project's environments, newest
+// first. prNumber and status narrow the list when set.
+func (ps PostgresDbStore) ListProjectPreviewEnvironments(ctx context.Context, projectID string, prNumber int, status string, limit, offset int) ([]models.PreviewEnvironment, error) {
+	if !isValidUUID(projectID) {
+		return nil, store.ErrNotFound
+	}
+	query := ps.getDB(ctx).Where("project_id = ?", projectID)
+	if prNumber > 0 {
+		query = query.Where("pr_number = ?", prNumber)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var envs []models.PreviewEnvironment
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&envs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list preview environments: %w", err)
+	}
+	return envs, nil
+}
+
+// UpdatePreviewEnvironment saves the environment's teardown state if its
+// status is still fromStatus, and reports whether it did. Claiming a
+// teardown this way makes it happen once across coordinator replicas and
+// webhook redeliveries.
+func (ps PostgresDbStore) UpdatePreviewEnvironment(ctx context.Context, env *models.PreviewEnvironment, fromStatus string) (bool, error) {
+	env.UpdatedAt = time.Now().UTC()
+	result := ps.getDB(ctx).Model(&models.PreviewEnvironment{}).
+		Where("environment_id = ? AND status = ?", env.EnvironmentID, fromStatus).
+		Updates(map[string]interface{}{
+			"status":          env.Status,
+			"teardown_job_id": env.TeardownJobID,
+			"updated_at":      env.UpdatedAt,
+			"torn_down_at":    env.TornDownAt,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to update preview environment: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
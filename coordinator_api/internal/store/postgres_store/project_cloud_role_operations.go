@@ -0,0 +1,61 @@
+package postgres_store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"gorm.io/gorm"
+)
+
+func (ps PostgresDbStore) CreateProjectCloudRole(ctx context.Context, role *models.ProjectCloudRole) error {
+	if err := ps.getDB(ctx).Create(role).Error; err != nil {
+		return fmt.Errorf("failed to create project cloud role: %w", err)
+	}
+	return nil
+}
+
+// ListProjectCloudRoles returns a project's cloud role mappings.
+func (ps PostgresDbStore) ListProjectCloudRoles(ctx context.Context, projectID string) ([]models.ProjectCloudRole, error) {
+	var roles []models.ProjectCloudRole
+	if err := ps.getDB(ctx).Where("project_id = ?", projectID).Order("name ASC").Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("failed to list project cloud roles: %w", err)
+	}
+	return roles, nil
+}
+
+// GetProjectCloudRole returns one of a project's cloud role mappings by ID
+// or name.
+func (ps PostgresDbStore) GetProjectCloudRole(ctx context.Context, projectID, ref string) (*models.ProjectCloudRole, error) {
+	query := ps.getDB(ctx).Where("project_id = ? AND (role_id::text = ? OR name = ?)", projectID, ref, ref)
+	var role models.ProjectCloudRole
+	if err := query.First(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, store.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get project cloud role: %w", err)
+	}
+	return &role, nil
+}
+
+func (ps PostgresDbStore) UpdateProjectCloudRole(ctx context.Context, role *models.ProjectCloudRole) error {
+	if err := ps.getDB(ctx).Save(role).Error; err != nil {
+		return fmt.Errorf("failed to update project cloud role: %w", err)
+	}
+	return nil
+}
+
+func (ps PostgresDbStore) DeleteProjectCloudRole(ctx context.Context, projectID, ref string) error {
+	result := ps.getDB(ctx).
+		Where("project_id = ? AND (role_id::text = ? OR name = ?)", projectID, ref, ref).
+		Delete(&models.ProjectCloudRole{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete project cloud role: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package postgres_store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// CreateProjectGroup creates a project group. Returns
+// store.ErrAlreadyExists if another group has its name.
+func (ps PostgresDbStore) CreateProjectGroup(ctx context.Context, group *models.ProjectGroup) error {
+	if group.Name == "" {
+		return store.ErrInvalidInput
+	}
+	result := ps.getDB(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoNothing: true,
+	}).Create(group)
+	if result.Error != nil {
+		return fmt.Errorf("failed to create project group: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrAlreadyExists
+	}
+	return nil
+}
+
+// GetProjectGroup returns a project group by ID.
+func (ps PostgresDbStore) GetProjectGroup(ctx context.Context, groupID string) (*models.ProjectGroup, error) {
+	if !isValidUUID(groupID) {
+		return nil, store.ErrNotFound
+	}
+	var group models.ProjectGroup
+	if err := ps.getDB(ctx).Where("group_id = ?", groupID).First(&group).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, store.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get project group: %w", err)
+	}
+	return &group, nil
+}
+
+// ListProjectGroups returns project groups by name.
+func (ps PostgresDbStore) ListProjectGroups(ctx context.Context, limit, offset int) ([]models.ProjectGroup, error) {
+	var groups []models.ProjectGroup
+	if err := ps.getDB(ctx).Order("name").Limit(limit).Offset(offset).Find(&groups).Error; err != nil {
+		return nil, fmt.Errorf("failed to list project groups: %w", err)
+	}
+	return groups, nil
+}
+
+// UpdateProjectGroup saves a project group. Its members pick up the new
+// defaults on their next read.
+func (ps PostgresDbStore) UpdateProjectGroup(ctx context.Context, group *models.ProjectGroup) error {
+	if !isValidUUID(group.GroupID) {
+		return store.ErrNotFound
+	}
+	if err := ps.persistInheritedValues(ctx, group.GroupID); err != nil {
+		return err
+	}
+	group.UpdatedAt = time.Now().UTC()
+	if err := ps.getDB(ctx).Save(group).Error; err != nil {
+		return fmt.Errorf("failed to update project group: %w", err)
+	}
+	return nil
+}
+
+// DeleteProjectGroup deletes a project group. Its members leave the group
+// keeping the values they last inherited.
+func (ps PostgresDbStore) DeleteProjectGroup(ctx context.Context, groupID string) error {
+	if !isValidUUID(groupID) {
+		return store.ErrNotFound
+	}
+	if err := ps.persistInheritedValues(ctx, groupID); err != nil {
+		return err
+	}
+	result := ps.getDB(ctx).Where("group_id = ?", groupID).Delete(&models.ProjectGroup{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete project group: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// ListProjectsInGroup returns a group's member projects, newest first, with
+// the group's defaults applied.
+func (ps PostgresDbStore) ListProjectsInGroup(ctx context.Context, groupID string, limit, offset int) ([]models.Project, error) {
+	if !isValidUUID(groupID) {
+		return nil, store.ErrNotFound
+	}
+	var projects []models.Project
+	result := ps.getDB(ctx).Where("group_id = ?", groupID).Limit(limit).Offset(offset).Order("created_at DESC").Find(&projects)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to list projects in group: %w", result.Error)
+	}
+	if err := ps.applyProjectGroups(ctx, projects); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// persistInheritedValues writes the values a group's members inherit from
+// it now into their own columns, so a member keeps them if the group stops
+// setting a default or goes away.
+func (ps PostgresDbStore) persistInheritedValues(ctx context.Context, groupID string) error {
+	var group models.ProjectGroup
+	if err := ps.getDB(ctx).Where("group_id = ?", groupID).First(&group).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return store.ErrNotFound
+		}
+		return fmt.Errorf("failed to get project group: %w", err)
+	}
+	var projects []models.Project
+	if err := ps.getDB(ctx).Where("group_id = ?", groupID).Find(&projects).Error; err != nil {
+		return fmt.Errorf("failed to list projects in group: %w", err)
+	}
+	for i := range projects {
+		p := &projects[i]
+		p.ApplyGroupDefaults(&group)
+		if len(p.InheritedFields) == 0 {
+			continue
+		}
+		err := ps.getDB(ctx).Model(&models.Project{}).Where("project_id = ?", p.ProjectID).Updates(map[string]interface{}{
+			"default_runner_image":    p.DefaultRunnerImage,
+			"default_timeout_seconds": p.DefaultTimeoutSeconds,
+			"default_queue_name":      p.DefaultQueueName,
+			"allowed_event_types":     p.AllowedEventTypes,
+			"vcs_token_secret":        p.VCSTokenSecret,
+			"webhook_secret":          p.WebhookSecret,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to persist inherited values: %w", err)
+		}
+	}
+	return nil
+}
+
+// applyProjectGroup applies a grouped project's group defaults.
+func (ps PostgresDbStore) applyProjectGroup(ctx context.Context, project *models.Project) error {
+	if project.GroupID == nil {
+		return nil
+	}
+	group, err := ps.GetProjectGroup(ctx, *project.GroupID)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return err
+	}
+	project.ApplyGroupDefaults(group)
+	return nil
+}
+
+// applyProjectGroups applies each grouped project's group defaults. See
+// models.Project.ApplyGroupDefaults.
+func (ps PostgresDbStore) applyProjectGroups(ctx context.Context, projects []models.Project) error {
+	var groupIDs []string
+	for _, p := range projects {
+		if p.GroupID != nil {
+			groupIDs = append(groupIDs, *p.GroupID)
+		}
+	}
+	if len(groupIDs) == 0 {
+		return nil
+	}
+	var groups []models.ProjectGroup
+	if err := ps.getDB(ctx).Where("group_id IN ?", groupIDs).Find(&groups).Error; err != nil {
+		return fmt.Errorf("failed to load project groups: %w", err)
+	}
+	byID := make(map[string]*models.ProjectGroup, len(groups))
+	for i := range groups {
+		byID[groups[i].GroupID] = &groups[i]
+	}
+	for i := range projects {
+		if projects[i].GroupID != nil {
+			projects[i].ApplyGroupDefaults(byID[*projects[i].GroupID])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package postgres_store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ListProjectHistory lists a project's config versions, newest first, and
+// how many there are in all.
+func (ps PostgresDbStore) ListProjectHistory(ctx context.Context, projectID string, limit, offset int) ([]models.ProjectConfigVersion, int64, error) {
+	versions := []models.ProjectConfigVersion{}
+	if !isValidUUID(projectID) {
+		return versions, 0, nil
+	}
+	q := ps.getDB(ctx).Model(&models.ProjectConfigVersion{}).Where("project_id = ?", projectID)
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count project history: %w", err)
+	}
+	if err := q.Order("version DESC").Limit(limit).Offset(offset).Find(&versions).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list project history: %w", err)
+	}
+	return versions, total, nil
+}
+
+// GetProjectVersion returns one of a project's config versions, or
+// store.ErrNotFound.
+func (ps PostgresDbStore) GetProjectVersion(ctx context.Context, projectID string, version int) (*models.ProjectConfigVersion, error) {
+	if !isValidUUID(projectID) {
+		return nil, store.ErrNotFound
+	}
+	var v models.ProjectConfigVersion
+	err := ps.getDB(ctx).Where("project_id = ? AND version = ?", projectID, version).First(&v).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project version: %w", err)
+	}
+	return &v, nil
+}
+
+// RevertProject saves project, already restored to version's
+// configuration (see models.ApplyProjectConfig), recording it in its
+// history as a revert to version.
+func (ps PostgresDbStore) RevertProject(ctx context.Context, project *models.Project, version int) error {
+	return ps.saveProjectVersion(ctx, project, models.ProjectHistoryReverted, &version)
+}
+
+// saveProjectVersion saves project and records the change in its history
+// as action, holding the project's row so concurrent saves number their
+// versions in the order they happened.
+func (ps PostgresDbStore) saveProjectVersion(ctx context.Context, project *models.Project, action string, revertedTo *int) error {
+	return ps.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		before, err := storedProjectConfig(tx, project.ProjectID, true)
+		if err != nil {
+			return fmt.Errorf("failed to update project: %w", err)
+		}
+		if err := tx.Save(project).Error; err != nil {
+			return fmt.Errorf("failed to update project: %w", err)
+		}
+		return recordProjectVersion(ctx, tx, project.ProjectID, action, before, revertedTo)
+	})
+}
+
+// storedProjectConfig returns the configuration in a project's row, as
+// saved, without its group's defaults, locking the row when lock is set.
+func storedProjectConfig(tx *gorm.DB, projectID string, lock bool) (models.JSONB, error) {
+	q := tx.Unscoped()
+	if lock {
+		q = q.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	var project models.Project
+	if err := q.Where("project_id = ?", projectID).First(&project).Error; err != nil {
+		return nil, err
+	}
+	return models.ProjectConfig(&project)
+}
+
+// recordProjectVersion adds the next version of a project's history, the
+// configuration now saved in its row, by the user in ctx. Updates and
+// reverts that changed nothing from before aren't recorded.
+func recordProjectVersion(ctx context.Context, tx *gorm.DB, projectID, action string, before models.JSONB, revertedTo *int) error {
+	after, err := storedProjectConfig(tx, projectID, false)
+	if err != nil {
+		return fmt.Errorf("failed to read project config: %w", err)
+	}
+	changes := models.DiffProjectConfigs(before, after)
+	if len(changes) == 0 && action != models.ProjectHistoryCreated {
+		return nil
+	}
+
+	var latest int
+	if err := tx.Model(&models.ProjectConfigVersion{}).Where("project_id = ?", projectID).
+		Select("COALESCE(MAX(version), 0)").Scan(&latest).Error; err != nil {
+		return fmt.Errorf("failed to get project version: %w", err)
+	}
+	v := models.ProjectConfigVersion{
+		ProjectID:  projectID,
+		Version:    latest + 1,
+		Action:     action,
+		RevertedTo: revertedTo,
+		Config:     after,
+		Changes:    changes,
+	}
+	if user := checkauth.GetUserFromContext(ctx); user != nil && isValidUUID(user.UserID) {
+		v.ActorID = &user.UserID
+	}
+	if err := tx.Create(&v).Error; err != nil {
+		return fmt.Errorf("failed to record project version: %w", err)
+	}
+	return nil
+}
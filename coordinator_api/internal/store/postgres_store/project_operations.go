@@ -4,18 +4,21 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/repourl"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"gorm.io/gorm"
 )
 
-// CreateProject creates a new project in the database
+// CreateProject creates a new project in the database, recording its
+// configuration as version 1 of its history.
 func (ps PostgresDbStore) CreateProject(ctx context.Context, project *models.Project) error {
-	db := ps.getDB(ctx)
-	result := db.Create(project)
-	if result.Error != nil {
-		return fmt.Errorf("failed to create project: %w", result.Error)
-	}
-	return nil
+	return ps.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(project).Error; err != nil {
+			return fmt.Errorf("failed to create project: %w", err)
+		}
+		return recordProjectVersion(ctx, tx, project.ProjectID, models.ProjectHistoryCreated, nil, nil)
+	})
 }
 
 // GetProjectByID retrieves a project by its ID
@@ -30,32 +33,55 @@ func (ps PostgresDbStore) GetProjectByID(ctx context.Context, projectID string)
 	if result.Error != nil {
 		return nil, fmt.Errorf("failed to get project: %w", result.Error)
 	}
+	if err := ps.applyProjectGroup(ctx, &project); err != nil {
+		return nil, err
+	}
 	return &project, nil
 }
 
-// GetProjectByRepoURL retrieves a project by its repository URL
-// The repoURL should be in canonical form (e.g., github.com/org/repo)
-func (ps PostgresDbStore) GetProjectByRepoURL(ctx context.Context, repoURL string) (*models.Project, error) {
+// GetProjectsByRepoURL retrieves every project for a repository URL,
+// ordered by config path. Several projects share a repository in a
+// monorepo. The repoURL may be in any form; it's normalized to the
+// canonical one projects are stored in (e.g., github.com/org/repo).
+// Returns store.ErrNotFound if there are none.
+func (ps PostgresDbStore) GetProjectsByRepoURL(ctx context.Context, repoURL string) ([]models.Project, error) {
 	db := ps.getDB(ctx)
-	var project models.Project
-	result := db.Where("repo_url = ?", repoURL).First(&project)
+	var projects []models.Project
+	result := db.Where("repo_url = ?", repourl.Normalize(repoURL)).Order("config_path").Find(&projects)
 	if result.Error != nil {
-		return nil, fmt.Errorf("failed to get project by repo URL: %w", result.Error)
+		return nil, fmt.Errorf("failed to get projects by repo URL: %w", result.Error)
 	}
-	return &project, nil
+	if len(projects) == 0 {
+		return nil, store.ErrNotFound
+	}
+	if err := ps.applyProjectGroups(ctx, projects); err != nil {
+		return nil, err
+	}
+	return projects, nil
 }
 
-// UpdateProject updates an existing project
+// UpdateProject updates an existing project, recording the change in its
+// history.
 func (ps PostgresDbStore) UpdateProject(ctx context.Context, project *models.Project) error {
-	db := ps.getDB(ctx)
-	result := db.Save(project)
+	return ps.saveProjectVersion(ctx, project, models.ProjectHistoryUpdated, nil)
+}
+
+// SetProjectLintWarnings replaces the lint warnings stored with a project.
+func (ps PostgresDbStore) SetProjectLintWarnings(ctx context.Context, projectID string, warnings models.ProjectLintWarnings) error {
+	if !isValidUUID(projectID) {
+		return store.ErrNotFound
+	}
+	result := ps.getDB(ctx).Model(&models.Project{}).Where("project_id = ?", projectID).UpdateColumn("lint_warnings", warnings)
 	if result.Error != nil {
-		return fmt.Errorf("failed to update project: %w", result.Error)
+		return fmt.Errorf("failed to set project lint warnings: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
 	}
 	return nil
 }
 
-// DeleteProject deletes a project by its ID
+// DeleteProject moves a project to the trash (see trash_operations.go)
 func (ps PostgresDbStore) DeleteProject(ctx context.Context, projectID string) error {
 	if !isValidUUID(projectID) {
 		return store.ErrNotFound
@@ -80,6 +106,9 @@ func (ps PostgresDbStore) ListProjects(ctx context.Context, limit, offset int) (
 	if result.Error != nil {
 		return nil, fmt.Errorf("failed to list projects: %w", result.Error)
 	}
+	if err := ps.applyProjectGroups(ctx, projects); err != nil {
+		return nil, err
+	}
 	return projects, nil
 }
 
@@ -93,5 +122,8 @@ func (ps PostgresDbStore) ListProjectsByOrg(ctx context.Context, orgID string, l
 	if result.Error != nil {
 		return nil, fmt.Errorf("failed to list projects by org: %w", result.Error)
 	}
+	if err := ps.applyProjectGroups(ctx, projects); err != nil {
+		return nil, err
+	}
 	return projects, nil
 }
@@ -0,0 +1,117 @@
+package postgres_store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// CreatePromotion records a promotion request.
+func (ps PostgresDbStore) CreatePromotion(ctx context.Context, promotion *models.Promotion) error {
+	if !isValidUUID(promotion.ProjectID) || !isValidUUID(promotion.SourceJobID) || !isValidUUID(promotion.RequestedBy) {
+		return store.ErrInvalidInput
+	}
+	if err := ps.getDB(ctx).Create(promotion).Error; err != nil {
+		return fmt.Errorf("failed to create promotion: %w", err)
+	}
+	return nil
+}
+
+// GetPromotion returns a promotion.
+func (ps PostgresDbStore) GetPromotion(ctx context.Context, promotionID string) (*models.Promotion, error) {
+	if !isValidUUID(promotionID) {
+		return nil, store.ErrNotFound
+	}
+	return ps.findPromotion(ctx, "promotion_id = ?", promotionID)
+}
+
+// GetPromotionByJob returns the promotion that created jobID.
+func (ps PostgresDbStore) GetPromotionByJob(ctx context.Context, jobID string) (*models.Promotion, error) {
+	if !isValidUUID(jobID) {
+		return nil, store.ErrNotFound
+	}
+	return ps.findPromotion(ctx, "job_id = ?", jobID)
+}
+
+func (ps PostgresDbStore) findPromotion(ctx context.Context, query string, args ...interface{}) (*models.Promotion, error) {
+	var promotion models.Promotion
+	if err := ps.getDB(ctx).Where(query, args...).First(&promotion).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, store.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get promotion: %w", err)
+	}
+	return &promotion, nil
+}
+
+// ListPromotions returns a page of the project's promotions and how many
+// match. An artifact digest filter lists its promotions oldest first, the
+// order it moved through the environments; otherwise they're newest first.
+func (ps PostgresDbStore) ListPromotions(ctx context.Context, projectID, artifactDigest, environment string, limit, offset int) ([]models.Promotion, int64, error) {
+	if !isValidUUID(projectID) {
+		return nil, 0, nil
+	}
+	query := ps.getDB(ctx).Model(&models.Promotion{}).Where("project_id = ?", projectID)
+	order := "created_at DESC, promotion_id DESC"
+	if artifactDigest != "" {
+		query = query.Where("artifact_digest = ?", artifactDigest)
+		order = "created_at ASC, promotion_id ASC"
+	}
+	if environment != "" {
+		query = query.Where("from_environment = ? OR to_environment = ?", environment, environment)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count promotions: %w", err)
+	}
+	var promotions []models.Promotion
+	if err := query.Order(order).Limit(limit).Offset(offset).Find(&promotions).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list promotions: %w", err)
+	}
+	return promotions, total, nil
+}
+
+// AddPromotionApproval appends approval to a pending promotion's approvals
+// unless its user already approved, reporting whether it was added.
+func (ps PostgresDbStore) AddPromotionApproval(ctx context.Context, promotionID string, approval models.PromotionApproval) (bool, error) {
+	entry, err := json.Marshal([]models.PromotionApproval{approval})
+	if err != nil {
+		return false, err
+	}
+	user, err := json.Marshal([]map[string]string{{"user_id": approval.UserID}})
+	if err != nil {
+		return false, err
+	}
+	result := ps.getDB(ctx).Model(&models.Promotion{}).
+		Where("promotion_id = ? AND status = ? AND NOT approvals @> ?::jsonb", promotionID, models.PromotionPending, string(user)).
+		Update("approvals", gorm.Expr("approvals || ?::jsonb", string(entry)))
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to approve promotion: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// UpdatePromotion saves a promotion's outcome if its status is still
+// fromStatus, reporting whether it was.
+func (ps PostgresDbStore) UpdatePromotion(ctx context.Context, promotion *models.Promotion, fromStatus string) (bool, error) {
+	result := ps.getDB(ctx).Model(&models.Promotion{}).
+		Where("promotion_id = ? AND status = ?", promotion.PromotionID, fromStatus).
+		Updates(map[string]interface{}{
+			"status":     promotion.Status,
+			"job_id":     promotion.JobID,
+			"decided_by": promotion.DecidedBy,
+			"decided_at": promotion.DecidedAt,
+			"reason":     promotion.Reason,
+			"last_error": promotion.LastError,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to update promotion: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
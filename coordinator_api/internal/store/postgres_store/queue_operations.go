@@ -0,0 +1,21 @@
+package postgres_store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// ListQueuedJobs returns jobs waiting in Corndogs, in the order Corndogs
+// hands them out: highest priority first, then oldest.
+func (ps PostgresDbStore) ListQueuedJobs(ctx context.Context, limit int) ([]models.Job, error) {
+	var jobs []models.Job
+	err := ps.getDB(ctx).
+		Where("status IN ('submitted', 'queued') AND corndogs_task_id IS NOT NULL").
+		Order("priority DESC, created_at ASC").Limit(limit).Find(&jobs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list queued jobs: %w", err)
+	}
+	return jobs, nil
+}
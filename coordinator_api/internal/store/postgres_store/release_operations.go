@@ -0,0 +1,124 @@
+package postgres_store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// CreateRelease records a pending release for a tag. Returns
+// store.ErrAlreadyExists if the project already has a release for the tag.
+func (ps PostgresDbStore) CreateRelease(ctx context.Context, release *models.Release) error {
+	if !isValidUUID(release.ProjectID) || !isValidUUID(release.EvalJobID) || release.Tag == "" {
+		return store.ErrInvalidInput
+	}
+	release.Status = models.ReleasePending
+	if err := ps.getDB(ctx).Create(release).Error; err != nil {
+		if errors.Is(err, store.ErrAlreadyExists) {
+			return store.ErrAlreadyExists
+		}
+		return fmt.Errorf("failed to create release: %w", err)
+	}
+	return nil
+}
+
+// GetRelease returns a release.
+func (ps PostgresDbStore) GetRelease(ctx context.Context, releaseID string) (*models.Release, error) {
+	if !isValidUUID(releaseID) {
+		return nil, store.ErrNotFound
+	}
+	return ps.findRelease(ctx, "release_id = ?", releaseID)
+}
+
+// GetReleaseByEvalJob returns the release whose pipeline eval job is jobID.
+func (ps PostgresDbStore) GetReleaseByEvalJob(ctx context.Context, jobID string) (*models.Release, error) {
+	if !isValidUUID(jobID) {
+		return nil, store.ErrNotFound
+	}
+	return ps.findRelease(ctx, "eval_job_id = ?", jobID)
+}
+
+// GetLatestPublishedRelease returns the project's most recently published
+// release.
+func (ps PostgresDbStore) GetLatestPublishedRelease(ctx context.Context, projectID string) (*models.Release, error) {
+	if !isValidUUID(projectID) {
+		return nil, store.ErrNotFound
+	}
+	return ps.findRelease(ctx, "project_id = ? AND status = ?", projectID, models.ReleasePublished)
+}
+
+func (ps PostgresDbStore) findRelease(ctx context.Context, query string, args ...interface{}) (*models.Release, error) {
+	var release models.Release
+	err := ps.getDB(ctx).Where(query, args...).Order("published_at DESC NULLS LAST, created_at DESC").First(&release).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, store.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get release: %w", err)
+	}
+	return &release, nil
+}
+
+// ListReleases returns a page of the project's releases, newest first,
+// and how many it has.
+func (ps PostgresDbStore) ListReleases(ctx context.Context, projectID string, limit, offset int) ([]models.Release, int64, error) {
+	if !isValidUUID(projectID) {
+		return nil, 0, nil
+	}
+	query := ps.getDB(ctx).Model(&models.Release{}).Where("project_id = ?", projectID)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count releases: %w", err)
+	}
+	var releases []models.Release
+	if err := query.Order("created_at DESC, release_id DESC").Limit(limit).Offset(offset).Find(&releases).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list releases: %w", err)
+	}
+	return releases, total, nil
+}
+
+// ClaimRelease moves a pending release to "publishing", reporting whether
+// this caller got it.
+func (ps PostgresDbStore) ClaimRelease(ctx context.Context, releaseID string) (bool, error) {
+	result := ps.getDB(ctx).Model(&models.Release{}).
+		Where("release_id = ? AND status = ?", releaseID, models.ReleasePending).
+		Update("status", models.ReleasePublishing)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to claim release: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// UpdateRelease saves a release's status and what publishing it produced.
+func (ps PostgresDbStore) UpdateRelease(ctx context.Context, release *models.Release) error {
+	jobIDs := release.JobIDs
+	if jobIDs == nil {
+		jobIDs = pq.StringArray{}
+	}
+	result := ps.getDB(ctx).Model(&models.Release{}).
+		Where("release_id = ?", release.ReleaseID).
+		Updates(map[string]interface{}{
+			"status":         release.Status,
+			"previous_tag":   release.PreviousTag,
+			"changelog":      release.Changelog,
+			"job_ids":        jobIDs,
+			"artifacts":      release.Artifacts,
+			"vcs_release_id": release.VCSReleaseID,
+			"url":            release.URL,
+			"last_error":     release.LastError,
+			"published_at":   release.PublishedAt,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update release: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package postgres_store
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// ReplaceScanFindings replaces a scan job's stored findings, so a retried
+// scan doesn't keep its earlier attempt's findings.
+func (ps PostgresDbStore) ReplaceScanFindings(ctx context.Context, jobID string, findings []models.ScanFinding) error {
+	return ps.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("job_id = ?", jobID).Delete(&models.ScanFinding{}).Error; err != nil {
+			return fmt.Errorf("failed to delete scan findings: %w", err)
+		}
+		if len(findings) == 0 {
+			return nil
+		}
+		rows := make([]models.ScanFinding, len(findings))
+		for i, f := range findings {
+			f.FindingID = ""
+			f.JobID = jobID
+			rows[i] = f
+		}
+		if err := tx.CreateInBatches(rows, 500).Error; err != nil {
+			return fmt.Errorf("failed to store scan findings: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListScanFindings returns a job's findings, most severe first.
+func (ps PostgresDbStore) ListScanFindings(ctx context.Context, jobID string) ([]models.ScanFinding, error) {
+	if !isValidUUID(jobID) {
+		return nil, nil
+	}
+	var findings []models.ScanFinding
+	order := "CASE severity WHEN 'critical' THEN 0 WHEN 'high' THEN 1 WHEN 'medium' THEN 2 WHEN 'low' THEN 3 ELSE 4 END, vulnerability_id, package"
+	if err := ps.getDB(ctx).Where("job_id = ?", jobID).Order(order).Find(&findings).Error; err != nil {
+		return nil, fmt.Errorf("failed to list scan findings: %w", err)
+	}
+	return findings, nil
+}
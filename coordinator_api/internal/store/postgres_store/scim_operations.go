@@ -0,0 +1,257 @@
+package postgres_store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// GetSCIMUser returns the SCIM link of a user.
+func (ps PostgresDbStore) GetSCIMUser(ctx context.Context, userID string) (*models.SCIMUser, error) {
+	if !isValidUUID(userID) {
+		return nil, store.ErrNotFound
+	}
+	var user models.SCIMUser
+	if err := ps.getDB(ctx).Where("user_id = ?", userID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, store.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get scim user: %w", err)
+	}
+	return &user, nil
+}
+
+// GetSCIMUserByUserName returns the SCIM user with a userName, compared
+// case-insensitively.
+func (ps PostgresDbStore) GetSCIMUserByUserName(ctx context.Context, userName string) (*models.SCIMUser, error) {
+	var user models.SCIMUser
+	if err := ps.getDB(ctx).Where("user_name = ?", strings.ToLower(userName)).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, store.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get scim user: %w", err)
+	}
+	return &user, nil
+}
+
+// ListSCIMUsers returns a page of SCIM users by userName, only the one
+// named userName if it's set, and how many there are in all.
+func (ps PostgresDbStore) ListSCIMUsers(ctx context.Context, userName string, limit, offset int) ([]models.SCIMUser, int64, error) {
+	query := ps.getDB(ctx).Model(&models.SCIMUser{})
+	if userName != "" {
+		query = query.Where("user_name = ?", strings.ToLower(userName))
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count scim users: %w", err)
+	}
+	var users []models.SCIMUser
+	if err := query.Order("user_name ASC").Limit(limit).Offset(offset).Find(&users).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list scim users: %w", err)
+	}
+	return users, total, nil
+}
+
+// CreateSCIMUser links a user to SCIM. Returns store.ErrAlreadyExists if
+// another SCIM user has its userName.
+func (ps PostgresDbStore) CreateSCIMUser(ctx context.Context, user *models.SCIMUser) error {
+	if user.UserName == "" {
+		return store.ErrInvalidInput
+	}
+	now := time.Now().UTC()
+	user.CreatedAt, user.UpdatedAt = now, now
+	result := ps.getDB(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(user)
+	if result.Error != nil {
+		return fmt.Errorf("failed to create scim user: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrAlreadyExists
+	}
+	return nil
+}
+
+// UpdateSCIMUser saves a SCIM user's attributes.
+func (ps PostgresDbStore) UpdateSCIMUser(ctx context.Context, user *models.SCIMUser) error {
+	user.UpdatedAt = time.Now().UTC()
+	result := ps.getDB(ctx).Model(&models.SCIMUser{}).
+		Where("user_id = ?", user.UserID).
+		Updates(map[string]interface{}{
+			"user_name":    user.UserName,
+			"external_id":  user.ExternalID,
+			"display_name": user.DisplayName,
+			"given_name":   user.GivenName,
+			"family_name":  user.FamilyName,
+			"updated_at":   user.UpdatedAt,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update scim user: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteSCIMUser removes a user's SCIM link and group memberships; the
+// user itself stays.
+func (ps PostgresDbStore) DeleteSCIMUser(ctx context.Context, userID string) error {
+	if !isValidUUID(userID) {
+		return store.ErrNotFound
+	}
+	result := ps.getDB(ctx).Where("user_id = ?", userID).Delete(&models.SCIMUser{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete scim user: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// GetSCIMGroup returns a SCIM group.
+func (ps PostgresDbStore) GetSCIMGroup(ctx context.Context, groupID string) (*models.SCIMGroup, error) {
+	if !isValidUUID(groupID) {
+		return nil, store.ErrNotFound
+	}
+	var group models.SCIMGroup
+	if err := ps.getDB(ctx).Where("group_id = ?", groupID).First(&group).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, store.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get scim group: %w", err)
+	}
+	return &group, nil
+}
+
+// ListSCIMGroups returns a page of SCIM groups by display name, only the
+// one named displayName (case-insensitively) if it's set, and how many
+// there are in all.
+func (ps PostgresDbStore) ListSCIMGroups(ctx context.Context, displayName string, limit, offset int) ([]models.SCIMGroup, int64, error) {
+	query := ps.getDB(ctx).Model(&models.SCIMGroup{})
+	if displayName != "" {
+		query = query.Where("lower(display_name) = ?", strings.ToLower(displayName))
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count scim groups: %w", err)
+	}
+	var groups []models.SCIMGroup
+	if err := query.Order("display_name ASC").Limit(limit).Offset(offset).Find(&groups).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list scim groups: %w", err)
+	}
+	return groups, total, nil
+}
+
+// ListSCIMGroupsByUser returns the SCIM groups a user is a member of.
+func (ps PostgresDbStore) ListSCIMGroupsByUser(ctx context.Context, userID string) ([]models.SCIMGroup, error) {
+	var groups []models.SCIMGroup
+	if err := ps.getDB(ctx).
+		Where("group_id IN (?)", ps.getDB(ctx).Model(&models.SCIMGroupMember{}).Select("group_id").Where("user_id = ?", userID)).
+		Order("display_name ASC").
+		Find(&groups).Error; err != nil {
+		return nil, fmt.Errorf("failed to list scim groups of user %s: %w", userID, err)
+	}
+	return groups, nil
+}
+
+// CreateSCIMGroup creates a SCIM group. Returns store.ErrAlreadyExists if
+// another group has its display name.
+func (ps PostgresDbStore) CreateSCIMGroup(ctx context.Context, group *models.SCIMGroup) error {
+	if group.DisplayName == "" {
+		return store.ErrInvalidInput
+	}
+	now := time.Now().UTC()
+	group.CreatedAt, group.UpdatedAt = now, now
+	result := ps.getDB(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "display_name"}},
+		DoNothing: true,
+	}).Create(group)
+	if result.Error != nil {
+		return fmt.Errorf("failed to create scim group: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrAlreadyExists
+	}
+	return nil
+}
+
+// UpdateSCIMGroup saves a SCIM group's display name and external ID.
+func (ps PostgresDbStore) UpdateSCIMGroup(ctx context.Context, group *models.SCIMGroup) error {
+	group.UpdatedAt = time.Now().UTC()
+	result := ps.getDB(ctx).Model(&models.SCIMGroup{}).
+		Where("group_id = ?", group.GroupID).
+		Updates(map[string]interface{}{
+			"display_name": group.DisplayName,
+			"external_id":  group.ExternalID,
+			"updated_at":   group.UpdatedAt,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update scim group: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteSCIMGroup deletes a SCIM group and its memberships.
+func (ps PostgresDbStore) DeleteSCIMGroup(ctx context.Context, groupID string) error {
+	if !isValidUUID(groupID) {
+		return store.ErrNotFound
+	}
+	result := ps.getDB(ctx).Where("group_id = ?", groupID).Delete(&models.SCIMGroup{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete scim group: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// ListSCIMGroupMembers returns the user IDs of a SCIM group's members.
+func (ps PostgresDbStore) ListSCIMGroupMembers(ctx context.Context, groupID string) ([]string, error) {
+	var userIDs []string
+	if err := ps.getDB(ctx).Model(&models.SCIMGroupMember{}).
+		Where("group_id = ?", groupID).
+		Order("user_id ASC").
+		Pluck("user_id", &userIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list scim group members: %w", err)
+	}
+	return userIDs, nil
+}
+
+// AddSCIMGroupMembers adds users to a SCIM group, skipping those already
+// in it.
+func (ps PostgresDbStore) AddSCIMGroupMembers(ctx context.Context, groupID string, userIDs []string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	members := make([]models.SCIMGroupMember, len(userIDs))
+	for i, userID := range userIDs {
+		members[i] = models.SCIMGroupMember{GroupID: groupID, UserID: userID}
+	}
+	if err := ps.getDB(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&members).Error; err != nil {
+		return fmt.Errorf("failed to add scim group members: %w", err)
+	}
+	return nil
+}
+
+// RemoveSCIMGroupMembers removes users from a SCIM group.
+func (ps PostgresDbStore) RemoveSCIMGroupMembers(ctx context.Context, groupID string, userIDs []string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	if err := ps.getDB(ctx).Where("group_id = ? AND user_id IN ?", groupID, userIDs).Delete(&models.SCIMGroupMember{}).Error; err != nil {
+		return fmt.Errorf("failed to remove scim group members: %w", err)
+	}
+	return nil
+}
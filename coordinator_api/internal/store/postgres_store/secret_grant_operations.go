@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/secrets"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
 	"gorm.io/gorm"
@@ -121,3 +122,9 @@ func (ps PostgresDbStore) ListSecretGrantsForJob(ctx context.Context, userID str
 	}
 	return grants, nil
 }
+
+// ListSecretAccessPolicies returns the org's secret access policies, which
+// jobs' secrets are resolved under (see worker.JobSecretAccessPolicy).
+func (ps PostgresDbStore) ListSecretAccessPolicies(ctx context.Context, orgID string) ([]models.SecretAccessPolicy, error) {
+	return secrets.ListAccessPolicies(ctx, ps.getDB(ctx), orgID)
+}
@@ -0,0 +1,53 @@
+package postgres_store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"gorm.io/gorm/clause"
+)
+
+// CreateServiceAccount creates user as a service account. Returns
+// store.ErrAlreadyExists if another user has its username.
+func (ps PostgresDbStore) CreateServiceAccount(ctx context.Context, user *models.User) error {
+	if user.Username == "" {
+		return store.ErrInvalidInput
+	}
+	user.IsServiceAccount = true
+	user.Email = ""
+	result := ps.getDB(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "username"}},
+		DoNothing: true,
+	}).Create(user)
+	if result.Error != nil {
+		return fmt.Errorf("failed to create service account: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrAlreadyExists
+	}
+	return nil
+}
+
+// ListServiceAccounts returns every service account by username.
+func (ps PostgresDbStore) ListServiceAccounts(ctx context.Context) ([]models.User, error) {
+	var users []models.User
+	if err := ps.getDB(ctx).Where("is_service_account").Order("username ASC").Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to list service accounts: %w", err)
+	}
+	return users, nil
+}
+
+// DeactivateAPITokensByUser deactivates every active API token of a user
+// and returns how many it deactivated.
+func (ps PostgresDbStore) DeactivateAPITokensByUser(ctx context.Context, userID string) (int64, error) {
+	result := ps.getDB(ctx).Model(&models.APIToken{}).
+		Where("user_id = ? AND is_active", userID).
+		Updates(map[string]interface{}{"is_active": false, "updated_at": time.Now().UTC()})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to deactivate API tokens for user %s: %w", userID, result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,133 @@
+package postgres_store
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/metrics"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// explainTimeout bounds capturing a slow query's plan.
+const explainTimeout = 5 * time.Second
+
+// slowQueryLogger wraps a GORM logger to log and count queries slower than
+// threshold, and to log the generic plan of a sample of them.
+//
+// It makes GORM hand loggers parameterized SQL (see ParamsFilter), so
+// query values, which include secret ciphertexts and token hashes, never
+// reach the logs.
+type slowQueryLogger struct {
+	logger.Interface
+	threshold   time.Duration
+	explainRate float64
+	// explain returns the plan of a parameterized query; nil disables
+	// plans.
+	explain    func(ctx context.Context, sql string) (string, error)
+	explaining *atomic.Bool
+}
+
+func newSlowQueryLogger(inner logger.Interface, threshold time.Duration, explainRate float64, explain func(ctx context.Context, sql string) (string, error)) *slowQueryLogger {
+	return &slowQueryLogger{
+		Interface:   inner,
+		threshold:   threshold,
+		explainRate: explainRate,
+		explain:     explain,
+		explaining:  &atomic.Bool{},
+	}
+}
+
+// LogMode implements logger.Interface, keeping the wrapper.
+func (l *slowQueryLogger) LogMode(level logger.LogLevel) logger.Interface {
+	copied := *l
+	copied.Interface = l.Interface.LogMode(level)
+	return &copied
+}
+
+// ParamsFilter implements gorm.ParamsFilter: logged SQL keeps its $n
+// placeholders.
+func (l *slowQueryLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
+
+// Trace implements logger.Interface.
+func (l *slowQueryLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	l.Interface.Trace(ctx, begin, fc, err)
+	elapsed := time.Since(begin)
+	if l.threshold <= 0 || elapsed < l.threshold {
+		return
+	}
+	sql, rows := fc()
+	operation := queryOperation(sql)
+	metrics.RecordDBSlowQuery(operation)
+	entry := logging.Log.WithField("duration_ms", elapsed.Milliseconds()).
+		WithField("rows", rows).
+		WithField("operation", operation).
+		WithField("sql", sql)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		entry = entry.WithError(err)
+	}
+	entry.Warn("Slow database query")
+
+	if l.explain != nil && explainable(operation) && rand.Float64() < l.explainRate {
+		l.capturePlan(sql)
+	}
+}
+
+// capturePlan logs sql's plan in the background, one plan at a time, so a
+// burst of slow queries doesn't take more connections to explain them.
+func (l *slowQueryLogger) capturePlan(sql string) {
+	if !l.explaining.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer l.explaining.Store(false)
+		ctx, cancel := context.WithTimeout(context.Background(), explainTimeout)
+		defer cancel()
+		plan, err := l.explain(ctx, sql)
+		if err != nil {
+			logging.Log.WithError(err).WithField("sql", sql).Warn("Failed to capture slow query plan")
+			return
+		}
+		logging.Log.WithField("sql", sql).WithField("plan", plan).Warn("Slow database query plan")
+	}()
+}
+
+// queryOperation returns the statement type of sql, e.g. "SELECT".
+func queryOperation(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "UNKNOWN"
+	}
+	return strings.ToUpper(fields[0])
+}
+
+// explainable reports whether EXPLAIN accepts statements of operation.
+func explainable(operation string) bool {
+	switch operation {
+	case "SELECT", "INSERT", "UPDATE", "DELETE", "WITH":
+		return true
+	}
+	return false
+}
+
+// explainGenericPlan returns the generic plan of a parameterized query
+// (Postgres 16+), without running it.
+func explainGenericPlan(ctx context.Context, sql string) (string, error) {
+	if db == nil {
+		return "", errors.New("database not initialized")
+	}
+	var lines []string
+	err := db.Session(&gorm.Session{Logger: logger.Discard, NewDB: true}).WithContext(ctx).
+		Raw("EXPLAIN (GENERIC_PLAN) " + sql).Scan(&lines).Error
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,72 @@
+package postgres_store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+func TestSlowQueryLogger(t *testing.T) {
+	explained := make(chan string, 4)
+	l := newSlowQueryLogger(logger.Discard, 100*time.Millisecond, 1, func(ctx context.Context, sql string) (string, error) {
+		explained <- sql
+		return "Seq Scan on jobs", nil
+	})
+	ctx := context.Background()
+	query := func(sql string) func() (string, int64) {
+		return func() (string, int64) { return sql, 1 }
+	}
+
+	sql, vars := l.ParamsFilter(ctx, "SELECT * FROM jobs WHERE job_id = $1", "secret-value")
+	if sql != "SELECT * FROM jobs WHERE job_id = $1" || vars != nil {
+		t.Fatalf("ParamsFilter = %q, %v; want the SQL without its values", sql, vars)
+	}
+
+	// Fast queries are left alone.
+	l.Trace(ctx, time.Now(), query("SELECT 1"), nil)
+	select {
+	case got := <-explained:
+		t.Fatalf("explained a fast query: %q", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	l.Trace(ctx, time.Now().Add(-time.Second), query("SELECT * FROM jobs WHERE job_id = $1"), nil)
+	select {
+	case got := <-explained:
+		if got != "SELECT * FROM jobs WHERE job_id = $1" {
+			t.Errorf("explained %q", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the slow query's plan to be captured")
+	}
+
+	// Statements EXPLAIN doesn't take aren't explained.
+	for l.explaining.Load() {
+		time.Sleep(time.Millisecond)
+	}
+	l.Trace(ctx, time.Now().Add(-time.Second), query("BEGIN"), nil)
+	select {
+	case got := <-explained:
+		t.Fatalf("explained %q", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// LogMode keeps the wrapper.
+	if _, ok := l.LogMode(logger.Silent).(*slowQueryLogger); !ok {
+		t.Error("LogMode dropped the slow query logger")
+	}
+}
+
+func TestQueryOperation(t *testing.T) {
+	for sql, want := range map[string]string{
+		"select * from jobs":         "SELECT",
+		"\n  UPDATE jobs SET x = $1": "UPDATE",
+		"":                           "UNKNOWN",
+	} {
+		if got := queryOperation(sql); got != want {
+			t.Errorf("queryOperation(%q) = %q, want %q", sql, got, want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package postgres_store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// EnqueueStatusDelivery adds a delivery of kind for the job to the VCS
+// status outbox, due at now. A job's pending or failed delivery of the same
+// kind is reused: its generation is bumped and its attempts reset.
+func (ps PostgresDbStore) EnqueueStatusDelivery(ctx context.Context, jobID, kind string, now time.Time) error {
+	if !isValidUUID(jobID) {
+		return store.ErrNotFound
+	}
+	err := ps.getDB(ctx).Exec(`
+		INSERT INTO vcs_status_outbox (job_id, kind, next_attempt_at, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (job_id, kind) DO UPDATE SET
+			generation = vcs_status_outbox.generation + 1,
+			status = ?,
+			attempts = 0,
+			next_attempt_at = EXCLUDED.next_attempt_at,
+			last_error = '',
+			updated_at = EXCLUDED.updated_at`,
+		jobID, kind, now, now, models.StatusDeliveryPending).Error
+	if err != nil {
+		return fmt.Errorf("failed to enqueue status delivery: %w", err)
+	}
+	return nil
+}
+
+// ClaimStatusDeliveries claims up to limit pending deliveries due by now,
+// oldest first, counting an attempt and pushing each out by lease so no
+// other dispatcher claims it while it's being delivered. Rows another
+// dispatcher is claiming are skipped.
+func (ps PostgresDbStore) ClaimStatusDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.StatusDelivery, error) {
+	var deliveries []models.StatusDelivery
+	err := ps.getDB(ctx).Raw(`
+		UPDATE vcs_status_outbox SET attempts = attempts + 1, next_attempt_at = ?, updated_at = ?
+		WHERE outbox_id IN (
+			SELECT outbox_id FROM vcs_status_outbox
+			WHERE status = ? AND next_attempt_at <= ?
+			ORDER BY next_attempt_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED)
+		RETURNING *`,
+		now.Add(lease), now, models.StatusDeliveryPending, now, limit).Scan(&deliveries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim status deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// CompleteStatusDelivery removes a delivered entry, unless a status change
+// since it was claimed bumped its generation.
+func (ps PostgresDbStore) CompleteStatusDelivery(ctx context.Context, delivery *models.StatusDelivery) error {
+	err := ps.getDB(ctx).
+		Where("outbox_id = ? AND generation = ?", delivery.OutboxID, delivery.Generation).
+		Delete(&models.StatusDelivery{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to complete status delivery: %w", err)
+	}
+	return nil
+}
+
+// RetryStatusDelivery records a failed attempt: the entry is retried at
+// next, or marked failed when out of attempts. Entries a status change
+// bumped since they were claimed are left alone.
+func (ps PostgresDbStore) RetryStatusDelivery(ctx context.Context, delivery *models.StatusDelivery, lastError string, next time.Time, failed bool) error {
+	status := models.StatusDeliveryPending
+	if failed {
+		status = models.StatusDeliveryFailed
+	}
+	err := ps.getDB(ctx).Model(&models.StatusDelivery{}).
+		Where("outbox_id = ? AND generation = ?", delivery.OutboxID, delivery.Generation).
+		Updates(map[string]interface{}{
+			"status":          status,
+			"next_attempt_at": next,
+			"last_error":      lastError,
+			"updated_at":      time.Now().UTC(),
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to reschedule status delivery: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package postgres_store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm/clause"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// TouchJobLogActivity records that the job produced log output at at. It
+// deliberately leaves updated_at alone: the cancelling-job reaper reads
+// updated_at as "time since the last status change".
+func (ps PostgresDbStore) TouchJobLogActivity(ctx context.Context, jobID string, at time.Time) error {
+	if !isValidUUID(jobID) {
+		return store.ErrNotFound
+	}
+	if err := ps.getDB(ctx).Model(&models.Job{}).Where("job_id = ?", jobID).
+		UpdateColumn("last_log_at", at).Error; err != nil {
+		return fmt.Errorf("failed to update job log activity: %w", err)
+	}
+	return nil
+}
+
+// GetJobDurationP95 returns the p95 run time of the successful runs of
+// name in the project that completed after since, or zero when fewer than
+// minRuns qualify.
+func (ps PostgresDbStore) GetJobDurationP95(ctx context.Context, projectID, name string, since time.Time, minRuns int) (time.Duration, error) {
+	if !isValidUUID(projectID) {
+		return 0, nil
+	}
+	var row struct {
+		Runs       int      `gorm:"column:runs"`
+		P95Seconds *float64 `gorm:"column:p95_seconds"`
+	}
+	err := ps.getDB(ctx).Raw(`SELECT COUNT(*) AS runs,
+			percentile_cont(0.95) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM (completed_at - started_at))) AS p95_seconds
+		FROM jobs
+		WHERE project_id = ? AND name = ? AND status = 'completed' AND started_at IS NOT NULL AND completed_at >= ?
+			AND deleted_at IS NULL`,
+		projectID, name, since).Scan(&row).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to compute job duration p95: %w", err)
+	}
+	if row.Runs < minRuns || row.P95Seconds == nil {
+		return 0, nil
+	}
+	return time.Duration(*row.P95Seconds * float64(time.Second)), nil
+}
+
+// CreateStuckJobReport inserts report unless the job was already reported.
+// Returns whether this call created it, so concurrent monitors on several
+// replicas agree on which one remediates.
+func (ps PostgresDbStore) CreateStuckJobReport(ctx context.Context, report *models.StuckJobReport) (bool, error) {
+	if !isValidUUID(report.JobID) {
+		return false, store.ErrInvalidInput
+	}
+	result := ps.getDB(ctx).Omit("Job").Clauses(clause.OnConflict{DoNothing: true}).Create(report)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to create stuck job report: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// UpdateStuckJobReport saves a report's remediation fields.
+func (ps PostgresDbStore) UpdateStuckJobReport(ctx context.Context, report *models.StuckJobReport) error {
+	err := ps.getDB(ctx).Model(&models.StuckJobReport{}).Where("job_id = ?", report.JobID).
+		Updates(map[string]interface{}{
+			"remediated_at": report.RemediatedAt,
+			"retry_job_id":  report.RetryJobID,
+			"last_error":    report.LastError,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update stuck job report: %w", err)
+	}
+	return nil
+}
+
+// ListPendingStuckJobReports returns kill-action reports whose follow-up
+// (retry or fail) hasn't happened yet, oldest first.
+func (ps PostgresDbStore) ListPendingStuckJobReports(ctx context.Context, limit int) ([]models.StuckJobReport, error) {
+	var reports []models.StuckJobReport
+	err := ps.getDB(ctx).
+		Where("remediated_at IS NULL AND action IN ?", []string{models.StuckJobActionKillRetry, models.StuckJobActionKillFail}).
+		Order("detected_at").Limit(limit).Find(&reports).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending stuck job reports: %w", err)
+	}
+	return reports, nil
+}
+
+// ListStuckJobReports returns the reports detected at or after since,
+// newest first, with their jobs loaded.
+func (ps PostgresDbStore) ListStuckJobReports(ctx context.Context, since time.Time, limit, offset int) ([]models.StuckJobReport, error) {
+	var reports []models.StuckJobReport
+	err := ps.getDB(ctx).Preload("Job").Where("detected_at >= ?", since).
+		Order("detected_at DESC").Limit(limit).Offset(offset).Find(&reports).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stuck job reports: %w", err)
+	}
+	return reports, nil
+}
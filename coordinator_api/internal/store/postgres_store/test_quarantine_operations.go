@@ -0,0 +1,150 @@
+package postgres_store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// ReplaceTestResults replaces a job's stored test results, so a retried
+// job doesn't keep its earlier attempt's, and drops the project's results
+// created before prunedBefore.
+func (ps PostgresDbStore) ReplaceTestResults(ctx context.Context, jobID, projectID string, results []models.TestResult, prunedBefore time.Time) error {
+	return ps.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("job_id = ?", jobID).Delete(&models.TestResult{}).Error; err != nil {
+			return fmt.Errorf("failed to delete test results: %w", err)
+		}
+		if err := tx.Where("project_id = ? AND created_at < ?", projectID, prunedBefore).Delete(&models.TestResult{}).Error; err != nil {
+			return fmt.Errorf("failed to prune test results: %w", err)
+		}
+		if len(results) == 0 {
+			return nil
+		}
+		now := time.Now().UTC()
+		rows := make([]models.TestResult, len(results))
+		for i, r := range results {
+			r.ResultID = ""
+			r.JobID = jobID
+			r.ProjectID = projectID
+			r.CreatedAt = now
+			rows[i] = r
+		}
+		if err := tx.CreateInBatches(rows, 500).Error; err != nil {
+			return fmt.Errorf("failed to store test results: %w", err)
+		}
+		return nil
+	})
+}
+
+// TestPassRates returns how each of a project's tests named fared in the
+// results created since since, by test name.
+func (ps PostgresDbStore) TestPassRates(ctx context.Context, projectID string, testNames []string, since time.Time) (map[string]models.TestPassRate, error) {
+	rates := make(map[string]models.TestPassRate)
+	if !isValidUUID(projectID) || len(testNames) == 0 {
+		return rates, nil
+	}
+	var rows []struct {
+		TestName string
+		Runs     int
+		Passed   int
+	}
+	if err := ps.getDB(ctx).Model(&models.TestResult{}).
+		Select("test_name, COUNT(*) AS runs, COUNT(*) FILTER (WHERE status = ?) AS passed", models.TestPassed).
+		Where("project_id = ? AND test_name IN ? AND created_at >= ? AND status <> ?", projectID, testNames, since, models.TestSkipped).
+		Group("test_name").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get test pass rates: %w", err)
+	}
+	for _, row := range rows {
+		rates[row.TestName] = models.TestPassRate{Runs: row.Runs, Passed: row.Passed}
+	}
+	return rates, nil
+}
+
+// ListTestQuarantines returns a project's test quarantines, expired ones
+// included, by test name.
+func (ps PostgresDbStore) ListTestQuarantines(ctx context.Context, projectID string) ([]models.TestQuarantine, error) {
+	quarantines := []models.TestQuarantine{}
+	if !isValidUUID(projectID) {
+		return quarantines, nil
+	}
+	if err := ps.getDB(ctx).Where("project_id = ?", projectID).Order("test_name").Find(&quarantines).Error; err != nil {
+		return nil, fmt.Errorf("failed to list test quarantines: %w", err)
+	}
+	return quarantines, nil
+}
+
+// GetTestQuarantine returns one of a project's test quarantines.
+func (ps PostgresDbStore) GetTestQuarantine(ctx context.Context, projectID, quarantineID string) (*models.TestQuarantine, error) {
+	if !isValidUUID(projectID) || !isValidUUID(quarantineID) {
+		return nil, store.ErrNotFound
+	}
+	var quarantine models.TestQuarantine
+	if err := ps.getDB(ctx).Where("project_id = ? AND quarantine_id = ?", projectID, quarantineID).First(&quarantine).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, store.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get test quarantine: %w", err)
+	}
+	return &quarantine, nil
+}
+
+// SaveTestQuarantine quarantines a test of a project, replacing the
+// reason, expiry and creator of any quarantine the test already has.
+func (ps PostgresDbStore) SaveTestQuarantine(ctx context.Context, quarantine *models.TestQuarantine) error {
+	now := time.Now().UTC()
+	quarantine.CreatedAt, quarantine.UpdatedAt = now, now
+	if err := ps.getDB(ctx).Clauses(
+		clause.OnConflict{
+			Columns:   []clause.Column{{Name: "project_id"}, {Name: "test_name"}},
+			DoUpdates: clause.AssignmentColumns([]string{"reason", "expires_at", "created_by", "updated_at"}),
+		},
+		clause.Returning{},
+	).Create(quarantine).Error; err != nil {
+		return fmt.Errorf("failed to save test quarantine: %w", err)
+	}
+	return nil
+}
+
+// UpdateTestQuarantine saves a test quarantine's reason and expiry.
+// Returns store.ErrNotFound if it doesn't exist.
+func (ps PostgresDbStore) UpdateTestQuarantine(ctx context.Context, quarantine *models.TestQuarantine) error {
+	quarantine.UpdatedAt = time.Now().UTC()
+	result := ps.getDB(ctx).Model(&models.TestQuarantine{}).
+		Where("project_id = ? AND quarantine_id = ?", quarantine.ProjectID, quarantine.QuarantineID).
+		Updates(map[string]interface{}{
+			"reason":     quarantine.Reason,
+			"expires_at": quarantine.ExpiresAt,
+			"updated_at": quarantine.UpdatedAt,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update test quarantine: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteTestQuarantine releases a test from quarantine. Returns
+// store.ErrNotFound if the quarantine doesn't exist.
+func (ps PostgresDbStore) DeleteTestQuarantine(ctx context.Context, projectID, quarantineID string) error {
+	if !isValidUUID(projectID) || !isValidUUID(quarantineID) {
+		return store.ErrNotFound
+	}
+	result := ps.getDB(ctx).Where("project_id = ? AND quarantine_id = ?", projectID, quarantineID).Delete(&models.TestQuarantine{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete test quarantine: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
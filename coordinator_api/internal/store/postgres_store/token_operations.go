@@ -2,6 +2,7 @@ package postgres_store
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -17,7 +18,7 @@ func (ps PostgresDbStore) ValidateAPIToken(ctx context.Context, token string) (*
 
 	var apiToken models.APIToken
 	if err := ps.getDB(ctx).Where("token_hash = ? AND is_active = true", tokenHash).First(&apiToken).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil, store.ErrNotFound
 		}
 		return nil, nil, fmt.Errorf("failed to validate API token: %w", err)
@@ -31,11 +32,14 @@ func (ps PostgresDbStore) ValidateAPIToken(ctx context.Context, token string) (*
 	// Load the associated user separately (Preload wasn't working correctly)
 	var user models.User
 	if err := ps.getDB(ctx).Where("user_id = ?", apiToken.UserID).First(&user).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil, store.ErrNotFound
 		}
 		return nil, nil, fmt.Errorf("failed to load user for API token: %w", err)
 	}
+	if user.IsDeactivated() {
+		return nil, nil, store.ErrNotFound
+	}
 
 	return &apiToken, &user, nil
 }
@@ -0,0 +1,173 @@
+package postgres_store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// Projects and jobs are soft deleted: their models' DeletedAt makes
+// DeleteProject and DeleteJob set deleted_at, and every query through the
+// models leaves trashed rows out. Raw SQL over jobs filters deleted_at
+// itself. The operations here are the only ones that see the trash; they
+// use Unscoped to do so.
+
+// ListTrash lists the projects and jobs, archived ones included, trashed
+// since since, most recently trashed first. A non-empty userID limits it to
+// that user's.
+func (ps PostgresDbStore) ListTrash(ctx context.Context, userID string, since time.Time) ([]models.Project, []models.Job, error) {
+	if userID != "" && !isValidUUID(userID) {
+		return nil, nil, nil
+	}
+
+	projectQuery := ps.getDB(ctx).Unscoped().Where("deleted_at IS NOT NULL AND deleted_at >= ?", since)
+	jobQuery := ps.getDB(ctx).Unscoped().Table(jobsWithArchiveSQL+" jobs").Where("deleted_at IS NOT NULL AND deleted_at >= ?", since)
+	if userID != "" {
+		projectQuery = projectQuery.Where("user_id = ?", userID)
+		jobQuery = jobQuery.Where("user_id = ?", userID)
+	}
+
+	var projects []models.Project
+	if err := projectQuery.Order("deleted_at DESC").Find(&projects).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to list trashed projects: %w", err)
+	}
+	var jobs []models.Job
+	if err := jobQuery.Order("deleted_at DESC").Find(&jobs).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to list trashed jobs: %w", err)
+	}
+	return projects, jobs, nil
+}
+
+// GetTrashedProject returns a project in the trash.
+func (ps PostgresDbStore) GetTrashedProject(ctx context.Context, projectID string) (*models.Project, error) {
+	if !isValidUUID(projectID) {
+		return nil, store.ErrNotFound
+	}
+	var projects []models.Project
+	if err := ps.getDB(ctx).Unscoped().Where("project_id = ? AND deleted_at IS NOT NULL", projectID).Limit(1).Find(&projects).Error; err != nil {
+		return nil, fmt.Errorf("failed to get trashed project: %w", err)
+	}
+	if len(projects) == 0 {
+		return nil, store.ErrNotFound
+	}
+	return &projects[0], nil
+}
+
+// GetTrashedJob returns a job in the trash, archived or not.
+func (ps PostgresDbStore) GetTrashedJob(ctx context.Context, jobID string) (*models.Job, error) {
+	if !isValidUUID(jobID) {
+		return nil, store.ErrNotFound
+	}
+	var jobs []models.Job
+	if err := ps.getDB(ctx).Unscoped().Table(jobsWithArchiveSQL+" jobs").
+		Where("job_id = ? AND deleted_at IS NOT NULL", jobID).Limit(1).Find(&jobs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get trashed job %s: %w", jobID, err)
+	}
+	if len(jobs) == 0 {
+		return nil, store.ErrNotFound
+	}
+	return &jobs[0], nil
+}
+
+// RestoreProject takes a project out of the trash. It fails with
+// store.ErrAlreadyExists if another project has since taken its repository
+// and config path.
+func (ps PostgresDbStore) RestoreProject(ctx context.Context, projectID string) error {
+	if !isValidUUID(projectID) {
+		return store.ErrNotFound
+	}
+	result := ps.getDB(ctx).Unscoped().Model(&models.Project{}).
+		Where("project_id = ? AND deleted_at IS NOT NULL", projectID).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return fmt.Errorf("failed to restore project: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// RestoreJob takes a job, archived or not, out of the trash.
+func (ps PostgresDbStore) RestoreJob(ctx context.Context, jobID string) error {
+	if !isValidUUID(jobID) {
+		return store.ErrNotFound
+	}
+	var restored int64
+	for _, table := range []string{"jobs", "jobs_archive"} {
+		result := ps.getDB(ctx).Exec("UPDATE "+table+" SET deleted_at = NULL WHERE job_id = ? AND deleted_at IS NOT NULL", jobID)
+		if result.Error != nil {
+			return fmt.Errorf("failed to restore job %s: %w", jobID, result.Error)
+		}
+		restored += result.RowsAffected
+	}
+	if restored == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// PurgeProject permanently deletes a project in the trash. Its jobs stay,
+// without a project.
+func (ps PostgresDbStore) PurgeProject(ctx context.Context, projectID string) error {
+	if !isValidUUID(projectID) {
+		return store.ErrNotFound
+	}
+	result := ps.getDB(ctx).Unscoped().Where("project_id = ? AND deleted_at IS NOT NULL", projectID).Delete(&models.Project{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to purge project: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// PurgeJob permanently deletes a job, archived or not, in the trash, and
+// its config snapshot.
+func (ps PostgresDbStore) PurgeJob(ctx context.Context, jobID string) error {
+	if !isValidUUID(jobID) {
+		return store.ErrNotFound
+	}
+	var purged int64
+	for _, table := range []string{"jobs", "jobs_archive"} {
+		err := ps.getDB(ctx).Exec("DELETE FROM job_config_snapshots WHERE job_id IN (SELECT job_id FROM "+table+" WHERE job_id = ? AND deleted_at IS NOT NULL)", jobID).Error
+		if err != nil {
+			return fmt.Errorf("failed to purge job %s config snapshot: %w", jobID, err)
+		}
+		result := ps.getDB(ctx).Exec("DELETE FROM "+table+" WHERE job_id = ? AND deleted_at IS NOT NULL", jobID)
+		if result.Error != nil {
+			return fmt.Errorf("failed to purge job %s: %w", jobID, result.Error)
+		}
+		purged += result.RowsAffected
+	}
+	if purged == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// PurgeTrash permanently deletes the projects and jobs trashed before
+// before, with the jobs' config snapshots, and returns how many projects
+// and jobs it deleted.
+func (ps PostgresDbStore) PurgeTrash(ctx context.Context, before time.Time) (projects, jobs int64, err error) {
+	for _, table := range []string{"jobs", "jobs_archive"} {
+		err := ps.getDB(ctx).Exec("DELETE FROM job_config_snapshots WHERE job_id IN (SELECT job_id FROM "+table+" WHERE deleted_at IS NOT NULL AND deleted_at < ?)", before).Error
+		if err != nil {
+			return 0, jobs, fmt.Errorf("failed to purge trashed jobs' config snapshots: %w", err)
+		}
+		result := ps.getDB(ctx).Exec("DELETE FROM "+table+" WHERE deleted_at IS NOT NULL AND deleted_at < ?", before)
+		if result.Error != nil {
+			return 0, jobs, fmt.Errorf("failed to purge trashed jobs: %w", result.Error)
+		}
+		jobs += result.RowsAffected
+	}
+	result := ps.getDB(ctx).Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Delete(&models.Project{})
+	if result.Error != nil {
+		return 0, jobs, fmt.Errorf("failed to purge trashed projects: %w", result.Error)
+	}
+	return result.RowsAffected, jobs, nil
+}
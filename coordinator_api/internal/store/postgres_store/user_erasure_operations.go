@@ -0,0 +1,405 @@
+package postgres_store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// errErasureDryRun rolls back a dry run's changes.
+var errErasureDryRun = errors.New("user erasure dry run")
+
+// erasureJobRow is the part of a job scrubbed of a user's identifiers.
+type erasureJobRow struct {
+	JobID         string
+	UserID        string
+	Notes         string
+	Description   string
+	LastError     string
+	EventMetadata models.JSONB
+}
+
+// EraseUser anonymizes or deletes a user, per opts, and returns the
+// report of what it did. Either way the user's API tokens, sessions, SSO
+// identities, SCIM link, group memberships and role assignments go, and
+// its identifiers are scrubbed from jobs (its own and any other naming
+// it, archived ones included) and their config snapshots. Anonymizing
+// keeps the user as a deactivated placeholder owning what it owned;
+// deleting first hands its jobs, workflows, projects and usage to
+// opts.ReassignTo, and is refused with store.ErrReferenceViolation while
+// the user owns secrets, org keys, groups or secret policies, which can't
+// move with them. A dry run reports the same counts and changes nothing.
+// Other erasures are recorded in user_erasures.
+func (ps PostgresDbStore) EraseUser(ctx context.Context, userID string, opts models.UserErasureOptions) (*models.UserErasureReport, error) {
+	if !isValidUUID(userID) {
+		return nil, store.ErrNotFound
+	}
+	if err := opts.Validate(userID); err != nil {
+		return nil, fmt.Errorf("%w: %v", store.ErrInvalidInput, err)
+	}
+	base := models.UserErasureReport{
+		UserID:             userID,
+		Mode:               opts.Mode,
+		DryRun:             opts.DryRun,
+		RequestedBy:        opts.RequestedBy,
+		CreatedAt:          time.Now().UTC(),
+		AnonymizedUsername: models.AnonymizedUsername(userID),
+	}
+
+	report := &models.UserErasureReport{}
+	err := ps.transaction(ctx, func(tx *gorm.DB) error {
+		// A conflict retries the whole transaction: start the report over.
+		*report = base
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).First(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return store.ErrNotFound
+			}
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		if opts.Mode == models.UserErasureDelete {
+			if err := checkUserDeletable(tx, userID, opts.ReassignTo); err != nil {
+				return err
+			}
+			report.ReassignedTo = opts.ReassignTo
+		}
+
+		identifiers, err := erasureIdentifiers(tx, &user)
+		if err != nil {
+			return err
+		}
+		report.IdentifiersScrubbed = len(identifiers)
+
+		if err := revokeUserAccess(tx, userID, report); err != nil {
+			return err
+		}
+		for _, table := range []string{"jobs", "jobs_archive"} {
+			if err := scrubJobs(tx, table, userID, identifiers, report); err != nil {
+				return err
+			}
+		}
+		if err := scrubJobConfigSnapshots(tx, identifiers, report); err != nil {
+			return err
+		}
+
+		if opts.Mode == models.UserErasureDelete {
+			if err := reassignUserData(tx, userID, opts.ReassignTo, report); err != nil {
+				return err
+			}
+			if err := tx.Where("user_id = ?", userID).Delete(&models.User{}).Error; err != nil {
+				return fmt.Errorf("failed to delete user: %w", err)
+			}
+			report.UserDeleted = true
+		} else {
+			if err := tx.Table("secrets").Where("user_id = ?", userID).Count(&report.SecretsRetained).Error; err != nil {
+				return fmt.Errorf("failed to count secrets: %w", err)
+			}
+			err := tx.Model(&models.User{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+				"username":       report.AnonymizedUsername,
+				"email":          "",
+				"password":       []byte{},
+				"salt":           []byte{},
+				"description":    "",
+				"roles":          pq.StringArray{string(models.UserRoleUser)},
+				"deactivated_at": report.CreatedAt,
+				"updated_at":     report.CreatedAt,
+			}).Error
+			if err != nil {
+				return fmt.Errorf("failed to anonymize user: %w", err)
+			}
+		}
+		report.Notes = append(report.Notes, "Job environment variables were not scrubbed: they may hold secret references.")
+
+		if opts.DryRun {
+			return errErasureDryRun
+		}
+		erasure := &models.UserErasure{UserID: userID, Mode: opts.Mode, Report: *report, CreatedAt: report.CreatedAt}
+		if opts.RequestedBy != "" {
+			erasure.RequestedBy = &opts.RequestedBy
+		}
+		if err := tx.Create(erasure).Error; err != nil {
+			return fmt.Errorf("failed to record user erasure: %w", err)
+		}
+		report.ErasureID = erasure.ErasureID
+		return nil
+	})
+	if err != nil && !errors.Is(err, errErasureDryRun) {
+		return nil, err
+	}
+	return report, nil
+}
+
+// SetUserErasureReport replaces the recorded report of an erasure, e.g.
+// once the erased user's logs are deleted.
+func (ps PostgresDbStore) SetUserErasureReport(ctx context.Context, report *models.UserErasureReport) error {
+	if !isValidUUID(report.ErasureID) {
+		return store.ErrNotFound
+	}
+	result := ps.getDB(ctx).Model(&models.UserErasure{}).Where("erasure_id = ?", report.ErasureID).Update("report", *report)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update user erasure: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// ListUserErasures returns the recorded erasures, newest first, only
+// those of userID if it's set.
+func (ps PostgresDbStore) ListUserErasures(ctx context.Context, userID string) ([]models.UserErasure, error) {
+	erasures := []models.UserErasure{}
+	query := ps.getDB(ctx).Order("created_at DESC")
+	if userID != "" {
+		if !isValidUUID(userID) {
+			return erasures, nil
+		}
+		query = query.Where("user_id = ?", userID)
+	}
+	if err := query.Find(&erasures).Error; err != nil {
+		return nil, fmt.Errorf("failed to list user erasures: %w", err)
+	}
+	return erasures, nil
+}
+
+// checkUserDeletable returns store.ErrReferenceViolation if the user owns
+// data that can't be handed over, and store.ErrInvalidInput if it owns
+// data needing reassignTo and that's missing or not a user.
+func checkUserDeletable(tx *gorm.DB, userID, reassignTo string) error {
+	for _, owned := range []struct{ table, column, what string }{
+		{"secrets", "user_id", "secrets"},
+		{"org_encryption_keys", "user_id", "org encryption keys"},
+		{"secret_grants", "user_id", "secret grants"},
+		{"secret_access_policies", "org_id", "secret access policies"},
+		{"groups", "org_id", "groups"},
+	} {
+		var count int64
+		if err := tx.Table(owned.table).Where(owned.column+" = ?", userID).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to count %s: %w", owned.what, err)
+		}
+		if count > 0 {
+			return fmt.Errorf("%w: the user owns %d %s, which are encrypted or scoped to it; anonymize the user instead",
+				store.ErrReferenceViolation, count, owned.what)
+		}
+	}
+
+	var owned int64
+	for _, q := range []struct{ table, column string }{
+		{"jobs", "user_id"}, {"jobs_archive", "user_id"}, {"workflow_instances", "user_id"},
+		{"projects", "user_id"}, {"job_usage", "org_id"},
+	} {
+		var count int64
+		if err := tx.Table(q.table).Where(q.column+" = ?", userID).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to count %s: %w", q.table, err)
+		}
+		owned += count
+	}
+	if owned == 0 && reassignTo == "" {
+		return nil
+	}
+	if reassignTo == "" {
+		return fmt.Errorf("%w: reassign_to is required, the user owns jobs, workflows or projects", store.ErrInvalidInput)
+	}
+	var target int64
+	if isValidUUID(reassignTo) {
+		if err := tx.Model(&models.User{}).Where("user_id = ? AND deactivated_at IS NULL", reassignTo).Count(&target).Error; err != nil {
+			return fmt.Errorf("failed to get reassign_to user: %w", err)
+		}
+	}
+	if target == 0 {
+		return fmt.Errorf("%w: reassign_to must be an active user", store.ErrInvalidInput)
+	}
+	return nil
+}
+
+// erasureIdentifiers collects the identifiers of user to scrub: its
+// username and email, its SSO handles and display names, and its SCIM
+// names.
+func erasureIdentifiers(tx *gorm.DB, user *models.User) ([]string, error) {
+	candidates := []string{user.Username, user.Email}
+	var identities []models.AuthIdentity
+	if err := tx.Where("user_id = ?", user.UserID).Find(&identities).Error; err != nil {
+		return nil, fmt.Errorf("failed to list auth identities: %w", err)
+	}
+	for _, identity := range identities {
+		candidates = append(candidates, identity.Handle, identity.DisplayName)
+		if identity.Handle != "" {
+			candidates = append(candidates, identity.Handle+"@"+identity.Domain)
+		}
+	}
+	var scimUsers []models.SCIMUser
+	if err := tx.Where("user_id = ?", user.UserID).Find(&scimUsers).Error; err != nil {
+		return nil, fmt.Errorf("failed to get scim user: %w", err)
+	}
+	for _, scimUser := range scimUsers {
+		candidates = append(candidates, scimUser.UserName, scimUser.DisplayName,
+			strings.TrimSpace(scimUser.GivenName+" "+scimUser.FamilyName))
+	}
+	return models.ErasureIdentifiers(candidates...), nil
+}
+
+// revokeUserAccess removes everything that lets the user in or names it.
+func revokeUserAccess(tx *gorm.DB, userID string, report *models.UserErasureReport) error {
+	now := time.Now().UTC()
+	steps := []struct {
+		what  string
+		count *int64
+		run   func() *gorm.DB
+	}{
+		{"API tokens", &report.TokensRevoked, func() *gorm.DB {
+			return tx.Model(&models.APIToken{}).Where("user_id = ? AND is_active", userID).
+				Updates(map[string]interface{}{"is_active": false, "updated_at": now})
+		}},
+		{"UI sessions", &report.SessionsRevoked, func() *gorm.DB {
+			return tx.Model(&models.UISession{}).Where("user_id = ? AND revoked_at IS NULL", userID).Update("revoked_at", now)
+		}},
+		{"auth identities", &report.IdentitiesRemoved, func() *gorm.DB {
+			return tx.Where("user_id = ?", userID).Delete(&models.AuthIdentity{})
+		}},
+		{"SCIM link", &report.SCIMLinksRemoved, func() *gorm.DB {
+			return tx.Where("user_id = ?", userID).Delete(&models.SCIMUser{})
+		}},
+		{"group memberships", &report.GroupMembershipsRemoved, func() *gorm.DB {
+			return tx.Exec("DELETE FROM group_members WHERE user_id = ?", userID)
+		}},
+		{"role assignments", &report.RoleAssignmentsRemoved, func() *gorm.DB {
+			return tx.Exec("DELETE FROM role_assignments WHERE principal_type = 'user' AND principal_id = ?", userID)
+		}},
+	}
+	for _, step := range steps {
+		result := step.run()
+		if result.Error != nil {
+			return fmt.Errorf("failed to remove %s: %w", step.what, result.Error)
+		}
+		*step.count = result.RowsAffected
+	}
+	return nil
+}
+
+// scrubJobs replaces identifiers in the notes, description, last error and
+// event metadata of the jobs in table that belong to the user or mention
+// one of them, and records the user's own jobs in the report.
+func scrubJobs(tx *gorm.DB, table, userID string, identifiers []string, report *models.UserErasureReport) error {
+	query := tx.Table(table).Select("job_id, user_id, COALESCE(notes, '') AS notes, COALESCE(description, '') AS description, " +
+		"COALESCE(last_error, '') AS last_error, event_metadata")
+	if len(identifiers) == 0 {
+		query = query.Where("user_id = ?", userID)
+	} else {
+		patterns := make(pq.StringArray, len(identifiers))
+		for i, identifier := range identifiers {
+			patterns[i] = "%" + escapeLikePattern(identifier) + "%"
+		}
+		query = query.Where("user_id = ? OR notes ILIKE ANY (?) OR description ILIKE ANY (?) OR last_error ILIKE ANY (?) OR event_metadata::text ILIKE ANY (?)",
+			userID, patterns, patterns, patterns, patterns)
+	}
+	var rows []erasureJobRow
+	if err := query.Scan(&rows).Error; err != nil {
+		return fmt.Errorf("failed to list %s to scrub: %w", table, err)
+	}
+
+	for _, row := range rows {
+		if row.UserID == userID {
+			report.JobIDs = append(report.JobIDs, row.JobID)
+		}
+		updates := map[string]interface{}{}
+		for column, value := range map[string]string{"notes": row.Notes, "description": row.Description, "last_error": row.LastError} {
+			if scrubbed := models.ScrubIdentifiers(value, identifiers, report.AnonymizedUsername); scrubbed != value {
+				updates[column] = scrubbed
+			}
+		}
+		if row.EventMetadata != nil {
+			raw, err := json.Marshal(row.EventMetadata)
+			if err != nil {
+				return fmt.Errorf("failed to encode job %s event metadata: %w", row.JobID, err)
+			}
+			if scrubbed := models.ScrubIdentifiers(string(raw), identifiers, report.AnonymizedUsername); scrubbed != string(raw) {
+				var metadata models.JSONB
+				if err := json.Unmarshal([]byte(scrubbed), &metadata); err != nil {
+					return fmt.Errorf("failed to scrub job %s event metadata: %w", row.JobID, err)
+				}
+				updates["event_metadata"] = metadata
+			}
+		}
+		if len(updates) == 0 {
+			continue
+		}
+		if err := tx.Table(table).Where("job_id = ?", row.JobID).UpdateColumns(updates).Error; err != nil {
+			return fmt.Errorf("failed to scrub job %s: %w", row.JobID, err)
+		}
+		report.JobsScrubbed++
+	}
+	return nil
+}
+
+// scrubJobConfigSnapshots replaces the user's identifiers in the jobs'
+// config snapshots, which copy the jobs' event metadata. It's the one
+// change made to a snapshot after it's written.
+func scrubJobConfigSnapshots(tx *gorm.DB, identifiers []string, report *models.UserErasureReport) error {
+	if len(identifiers) == 0 {
+		return nil
+	}
+	patterns := make(pq.StringArray, len(identifiers))
+	for i, identifier := range identifiers {
+		patterns[i] = "%" + escapeLikePattern(identifier) + "%"
+	}
+	var snapshots []models.JobConfigSnapshot
+	if err := tx.Where("config::text ILIKE ANY (?)", patterns).Find(&snapshots).Error; err != nil {
+		return fmt.Errorf("failed to list job config snapshots to scrub: %w", err)
+	}
+	for _, snapshot := range snapshots {
+		raw, err := json.Marshal(snapshot.Config)
+		if err != nil {
+			return fmt.Errorf("failed to encode job %s config snapshot: %w", snapshot.JobID, err)
+		}
+		scrubbed := models.ScrubIdentifiers(string(raw), identifiers, report.AnonymizedUsername)
+		if scrubbed == string(raw) {
+			continue
+		}
+		var config models.ResolvedJobConfig
+		if err := json.Unmarshal([]byte(scrubbed), &config); err != nil {
+			return fmt.Errorf("failed to scrub job %s config snapshot: %w", snapshot.JobID, err)
+		}
+		if err := tx.Model(&models.JobConfigSnapshot{}).Where("job_id = ?", snapshot.JobID).UpdateColumn("config", config).Error; err != nil {
+			return fmt.Errorf("failed to scrub job %s config snapshot: %w", snapshot.JobID, err)
+		}
+		report.ConfigSnapshotsScrubbed++
+	}
+	return nil
+}
+
+// reassignUserData hands the user's jobs, workflows, projects and usage to
+// reassignTo.
+func reassignUserData(tx *gorm.DB, userID, reassignTo string, report *models.UserErasureReport) error {
+	for _, q := range []struct {
+		table, column string
+		count         *int64
+	}{
+		{"jobs", "user_id", &report.JobsReassigned},
+		{"jobs_archive", "user_id", &report.JobsReassigned},
+		{"workflow_instances", "user_id", &report.WorkflowsReassigned},
+		{"projects", "user_id", &report.ProjectsReassigned},
+		{"job_usage", "org_id", &report.UsageReassigned},
+	} {
+		result := tx.Exec("UPDATE "+q.table+" SET "+q.column+" = ? WHERE "+q.column+" = ?", reassignTo, userID)
+		if result.Error != nil {
+			return fmt.Errorf("failed to reassign %s: %w", q.table, result.Error)
+		}
+		*q.count += result.RowsAffected
+	}
+	return nil
+}
+
+// escapeLikePattern escapes the LIKE wildcards in s.
+func escapeLikePattern(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/checkauth"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
@@ -13,6 +15,7 @@ import (
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"gorm.io/gorm"
 )
 
@@ -25,7 +28,7 @@ func (ps PostgresDbStore) GetUserByID(ctx context.Context, userID string) (*mode
 	var user models.User
 
 	if err := ps.getDB(ctx).Where("user_id = ?", userID).First(&user).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, store.ErrNotFound
 		}
 		return nil, fmt.Errorf("failed to get user %s: %w", userID, err)
@@ -52,6 +55,35 @@ func (ps PostgresDbStore) UpdateUser(ctx context.Context, user *models.User) err
 	return nil
 }
 
+// SetUserRoles replaces a user's roles.
+func (ps PostgresDbStore) SetUserRoles(ctx context.Context, userID string, roles []string) error {
+	result := ps.getDB(ctx).Model(&models.User{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]interface{}{"roles": pq.StringArray(roles), "updated_at": time.Now().UTC()})
+	if result.Error != nil {
+		return fmt.Errorf("failed to set user roles: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// SetUserDeactivated sets or, with nil, clears when a user was
+// deprovisioned.
+func (ps PostgresDbStore) SetUserDeactivated(ctx context.Context, userID string, deactivatedAt *time.Time) error {
+	result := ps.getDB(ctx).Model(&models.User{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]interface{}{"deactivated_at": deactivatedAt, "updated_at": time.Now().UTC()})
+	if result.Error != nil {
+		return fmt.Errorf("failed to set user deactivation: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
 // EnsureDefaultUser creates a default user if DEFAULT_USER_ID is configured and the user doesn't exist
 func (ps PostgresDbStore) EnsureDefaultUser() error {
 	if config.DefaultUserID == "" {
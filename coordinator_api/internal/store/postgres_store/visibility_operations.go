@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
 	"gorm.io/gorm"
 )
@@ -139,9 +140,11 @@ func visibilityArgs(viewerID string) []interface{} {
 // (limit/offset) and the returned total count both operate on the
 // already-visibility-filtered row set. filters honors the same keys as
 // ListJobs (status, user_id, queue_name, source_type, project_id,
-// workflow_id). isGlobalAdmin, resolved once by the caller via
+// workflow_id, store.JobCursorFilter, store.IncludeArchivedFilter).
+// isGlobalAdmin, resolved once by the caller via
 // authz.Resolver.IsGlobalAdmin, bypasses the visibility predicate entirely
 // (a global admin sees every row that matches filters).
+// With a cursor, offset is ignored and the total is -1, not counted.
 func (ps PostgresDbStore) ListJobsVisibleTo(ctx context.Context, viewerID string, isGlobalAdmin bool, filters map[string]interface{}, limit, offset int) ([]models.Job, int64, error) {
 	if limit <= 0 {
 		limit = 20
@@ -154,7 +157,14 @@ func (ps PostgresDbStore) ListJobsVisibleTo(ctx context.Context, viewerID string
 	// than reused, so clause state from one call (e.g. Select/Order/Limit)
 	// can never leak into the other.
 	build := func() *gorm.DB {
-		q := ps.getDB(ctx).Table("jobs j")
+		// Trashed jobs are filtered explicitly: gorm's soft-delete scope
+		// can't find the "j" alias behind jobsWithArchiveSQL, and Count
+		// (with no model) wouldn't apply it at all.
+		q := ps.getDB(ctx).Unscoped().Table("jobs j")
+		if includeArchived, _ := filters[store.IncludeArchivedFilter].(bool); includeArchived {
+			q = ps.getDB(ctx).Unscoped().Table(jobsWithArchiveSQL + " j")
+		}
+		q = q.Where("j.deleted_at IS NULL")
 		for _, join := range visibilityJoins("j", "p", "proj_owner", "job_owner") {
 			q = q.Joins(join)
 		}
@@ -172,6 +182,15 @@ func (ps PostgresDbStore) ListJobsVisibleTo(ctx context.Context, viewerID string
 				q = q.Where("j.project_id = ?", value)
 			case "workflow_id":
 				q = q.Where("j.workflow_id = ?", value)
+			case "parent_job_id":
+				q = q.Where("j.parent_job_id = ?", value)
+			case "upstream_job_id":
+				q = q.Where("j.upstream_job_id = ?", value)
+			case "group_id":
+				q = q.Where("j.project_id IN (SELECT project_id FROM projects WHERE group_id = ?)", value)
+			case store.JobCursorFilter:
+				cursor := value.(store.JobCursor)
+				q = q.Where("(j.created_at, j.job_id) < (?, ?)", cursor.CreatedAt, cursor.JobID)
 			}
 		}
 		if !isGlobalAdmin {
@@ -180,13 +199,17 @@ func (ps PostgresDbStore) ListJobsVisibleTo(ctx context.Context, viewerID string
 		return q
 	}
 
-	var total int64
-	if err := build().Count(&total).Error; err != nil {
+	// Keyset pages skip the count: counting a large table is what keyset
+	// pagination avoids, and the cursor already says where the page is.
+	total := int64(-1)
+	if _, keyset := filters[store.JobCursorFilter]; keyset {
+		offset = 0
+	} else if err := build().Count(&total).Error; err != nil {
 		return nil, 0, fmt.Errorf("failed to count visible jobs: %w", err)
 	}
 
 	var jobs []models.Job
-	if err := build().Select("j.*").Order("j.created_at DESC").Limit(limit).Offset(offset).Find(&jobs).Error; err != nil {
+	if err := build().Select("j.*").Order("j.created_at DESC, j.job_id DESC").Limit(limit).Offset(offset).Find(&jobs).Error; err != nil {
 		return nil, 0, fmt.Errorf("failed to list visible jobs: %w", err)
 	}
 
@@ -257,7 +280,7 @@ func (ps PostgresDbStore) ListWorkflowSummariesVisibleTo(ctx context.Context, vi
 	if len(whereWorkflow) > 0 {
 		workflowClause = "WHERE " + strings.Join(whereWorkflow, " AND ")
 	}
-	looseClause := "WHERE j.workflow_id IS NULL"
+	looseClause := "WHERE j.workflow_id IS NULL AND j.deleted_at IS NULL"
 	if len(whereLoose) > 0 {
 		looseClause += " AND " + strings.Join(whereLoose, " AND ")
 	}
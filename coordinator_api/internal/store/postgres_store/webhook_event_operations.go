@@ -0,0 +1,226 @@
+package postgres_store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// ClaimWebhookEvent records a received delivery as "processing" and reports
+// whether the caller owns processing it. A redelivery (same provider and
+// delivery id) is only claimed again if the earlier attempt failed, or
+// was processed synchronously and its claim's lease ran out (see
+// models.WebhookEventClaimLease); otherwise the earlier ledger entry is
+// returned and the caller must not create jobs for it.
+func (ps PostgresDbStore) ClaimWebhookEvent(ctx context.Context, event *models.WebhookEvent) (bool, *models.WebhookEvent, error) {
+	now := time.Now().UTC()
+	event.Status = models.WebhookEventProcessing
+	if event.NextAttemptAt == nil {
+		lease := now.Add(models.WebhookEventClaimLease).Truncate(time.Microsecond)
+		event.NextAttemptAt = &lease
+	}
+	db := ps.getDB(ctx)
+	result := db.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "provider"}, {Name: "delivery_id"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "delivery_id IS NOT NULL"}}},
+		DoNothing:   true,
+	}).Create(event)
+	if result.Error != nil {
+		return false, nil, fmt.Errorf("failed to record webhook event: %w", result.Error)
+	}
+	if result.RowsAffected == 1 {
+		return true, nil, nil
+	}
+
+	var existing models.WebhookEvent
+	if err := db.Where("provider = ? AND delivery_id = ?", event.Provider, event.DeliveryID).
+		First(&existing).Error; err != nil {
+		return false, nil, fmt.Errorf("failed to load webhook event: %w", err)
+	}
+	if !webhookEventReclaimable(&existing, now) {
+		return false, &existing, nil
+	}
+
+	// Retry a failed or abandoned delivery, keeping the project outcomes
+	// so only the failed projects are processed again. The guard on the
+	// status and lease read above lets one redelivery win.
+	result = db.Model(&models.WebhookEvent{}).
+		Where("event_id = ? AND status = ? AND next_attempt_at IS NOT DISTINCT FROM ?", existing.EventID, existing.Status, existing.NextAttemptAt).
+		Updates(map[string]interface{}{
+			"status":          models.WebhookEventProcessing,
+			"error":           "",
+			"payload":         event.Payload,
+			"headers":         event.Headers,
+			"project_ids":     event.ProjectIDs,
+			"attempts":        0,
+			"next_attempt_at": event.NextAttemptAt,
+		})
+	if result.Error != nil {
+		return false, nil, fmt.Errorf("failed to reclaim webhook event: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, &existing, nil
+	}
+	event.EventID = existing.EventID
+	event.ReceivedAt = existing.ReceivedAt
+	event.ProjectOutcomes = existing.ProjectOutcomes
+	return true, nil, nil
+}
+
+// webhookEventReclaimable reports whether a redelivery may claim an
+// existing ledger entry: its processing failed, or it was being processed
+// synchronously (it has no stored payload for the asynchronous queue's
+// own lease to recover) and its claim's lease has run out. Entries from
+// before claims had a lease are timed from when they were received.
+func webhookEventReclaimable(existing *models.WebhookEvent, now time.Time) bool {
+	switch {
+	case existing.Status == models.WebhookEventFailed:
+		return true
+	case existing.Status != models.WebhookEventProcessing || existing.Payload != nil:
+		return false
+	case existing.NextAttemptAt != nil:
+		return !now.Before(*existing.NextAttemptAt)
+	default:
+		return !now.Before(existing.ReceivedAt.Add(models.WebhookEventClaimLease))
+	}
+}
+
+// UpdateWebhookEvent saves the outcome of processing a claimed event. The
+// event's NextAttemptAt is the caller's lease: if the stored event is no
+// longer processing under it (the lease ran out and another coordinator
+// took the event over), nothing is saved and store.ErrNotFound returned.
+func (ps PostgresDbStore) UpdateWebhookEvent(ctx context.Context, event *models.WebhookEvent) error {
+	updates := map[string]interface{}{
+		"project_id":       event.ProjectID,
+		"status":           event.Status,
+		"filter_reason":    event.FilterReason,
+		"error":            event.Error,
+		"job_id":           event.JobID,
+		"processed_at":     event.ProcessedAt,
+		"attempts":         event.Attempts,
+		"project_outcomes": event.ProjectOutcomes,
+	}
+	// A finished event no longer needs its raw delivery.
+	if event.Status != models.WebhookEventProcessing {
+		updates["payload"] = nil
+		updates["headers"] = nil
+		updates["next_attempt_at"] = nil
+	}
+	result := ps.getDB(ctx).Model(&models.WebhookEvent{}).
+		Where("event_id = ? AND status = ? AND next_attempt_at IS NOT DISTINCT FROM ?", event.EventID, models.WebhookEventProcessing, event.NextAttemptAt).
+		Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update webhook event: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// ListWebhookEvents returns a project's ledger entries, newest first,
+// optionally restricted to one status.
+func (ps PostgresDbStore) ListWebhookEvents(ctx context.Context, projectID, status string, limit, offset int) ([]models.WebhookEvent, error) {
+	return ps.listWebhookEvents(ps.getDB(ctx), projectID, status, limit, offset)
+}
+
+// ListWebhookEventsWithArchive is ListWebhookEvents over both
+// webhook_events and webhook_events_archive.
+func (ps PostgresDbStore) ListWebhookEventsWithArchive(ctx context.Context, projectID, status string, limit, offset int) ([]models.WebhookEvent, error) {
+	return ps.listWebhookEvents(ps.getDB(ctx).Table(webhookEventsWithArchiveSQL+" webhook_events"), projectID, status, limit, offset)
+}
+
+func (ps PostgresDbStore) listWebhookEvents(db *gorm.DB, projectID, status string, limit, offset int) ([]models.WebhookEvent, error) {
+	if !isValidUUID(projectID) {
+		return nil, store.ErrNotFound
+	}
+	query := db.Omit("payload", "headers").Where("project_id = ?", projectID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var events []models.WebhookEvent
+	if err := query.Order("received_at DESC").Limit(limit).Offset(offset).Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to list webhook events: %w", err)
+	}
+	return events, nil
+}
+
+// ClaimDueWebhookEvents leases up to limit queued events whose next
+// attempt is due, pushing their next attempt lease into the future so no
+// other coordinator picks them up meanwhile. Events leased by a
+// coordinator that then died become due again once the lease runs out.
+func (ps PostgresDbStore) ClaimDueWebhookEvents(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookEvent, error) {
+	var events []models.WebhookEvent
+	err := ps.getDB(ctx).Raw(`
+		UPDATE webhook_events SET next_attempt_at = ?
+		WHERE event_id IN (
+			SELECT event_id FROM webhook_events
+			WHERE status = ? AND payload IS NOT NULL AND next_attempt_at <= ?
+			ORDER BY next_attempt_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`, now.Add(lease), models.WebhookEventProcessing, now, limit).Scan(&events).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim due webhook events: %w", err)
+	}
+	return events, nil
+}
+
+// CountDueWebhookEvents counts the queued events due at now that no
+// coordinator has leased: the backlog behind every coordinator's workers.
+func (ps PostgresDbStore) CountDueWebhookEvents(ctx context.Context, now time.Time) (int64, error) {
+	var count int64
+	err := ps.getDB(ctx).Model(&models.WebhookEvent{}).
+		Where("status = ? AND payload IS NOT NULL AND next_attempt_at <= ?", models.WebhookEventProcessing, now).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count due webhook events: %w", err)
+	}
+	return count, nil
+}
+
+// RetryWebhookEvent records a failed processing attempt of a queued event,
+// its project outcomes so far, and when to try it next, if the caller
+// still holds the event's lease (see UpdateWebhookEvent); otherwise it
+// returns store.ErrNotFound.
+func (ps PostgresDbStore) RetryWebhookEvent(ctx context.Context, eventID string, lease *time.Time, attempts int, nextAttemptAt time.Time, errMsg string, outcomes models.WebhookProjectOutcomes) error {
+	result := ps.getDB(ctx).Model(&models.WebhookEvent{}).
+		Where("event_id = ? AND status = ? AND next_attempt_at IS NOT DISTINCT FROM ?", eventID, models.WebhookEventProcessing, lease).
+		Updates(map[string]interface{}{
+			"attempts":         attempts,
+			"next_attempt_at":  nextAttemptAt,
+			"error":            errMsg,
+			"project_outcomes": outcomes,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to schedule webhook event retry: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// RenewWebhookEventLease moves a queued event's lease from lease to
+// renewed, so a sweep doesn't hand the event to another coordinator while
+// the caller is still processing it. It returns store.ErrNotFound if the
+// caller no longer holds the lease.
+func (ps PostgresDbStore) RenewWebhookEventLease(ctx context.Context, eventID string, lease, renewed time.Time) error {
+	result := ps.getDB(ctx).Model(&models.WebhookEvent{}).
+		Where("event_id = ? AND status = ? AND next_attempt_at = ?", eventID, models.WebhookEventProcessing, lease).
+		Update("next_attempt_at", renewed)
+	if result.Error != nil {
+		return fmt.Errorf("failed to renew webhook event lease: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package postgres_store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+func TestWebhookEventReclaimable(t *testing.T) {
+	now := time.Now().UTC()
+	past, future := now.Add(-time.Second), now.Add(time.Minute)
+
+	for name, tc := range map[string]struct {
+		event models.WebhookEvent
+		want  bool
+	}{
+		"failed":                        {models.WebhookEvent{Status: models.WebhookEventFailed}, true},
+		"processed":                     {models.WebhookEvent{Status: models.WebhookEventProcessed, NextAttemptAt: &past}, false},
+		"filtered":                      {models.WebhookEvent{Status: models.WebhookEventFiltered}, false},
+		"processing within its lease":   {models.WebhookEvent{Status: models.WebhookEventProcessing, NextAttemptAt: &future}, false},
+		"processing past its lease":     {models.WebhookEvent{Status: models.WebhookEventProcessing, NextAttemptAt: &past}, true},
+		"queued for async processing":   {models.WebhookEvent{Status: models.WebhookEventProcessing, NextAttemptAt: &past, Payload: []byte("{}")}, false},
+		"unleased and recent":           {models.WebhookEvent{Status: models.WebhookEventProcessing, ReceivedAt: now}, false},
+		"unleased and older than lease": {models.WebhookEvent{Status: models.WebhookEventProcessing, ReceivedAt: now.Add(-models.WebhookEventClaimLease)}, true},
+	} {
+		if got := webhookEventReclaimable(&tc.event, now); got != tc.want {
+			t.Errorf("%s: reclaimable = %v, want %v", name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package postgres_store
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// RecordWorkerImage records how a worker's pull of an image went,
+// replacing the previous record. A failed pull keeps the last successful
+// pull's time.
+func (ps PostgresDbStore) RecordWorkerImage(ctx context.Context, image *models.WorkerImage) error {
+	if image.WorkerID == "" || image.Image == "" {
+		return store.ErrInvalidInput
+	}
+	err := ps.getDB(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "worker_id"}, {Name: "image"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"status":       gorm.Expr("EXCLUDED.status"),
+			"error":        gorm.Expr("EXCLUDED.error"),
+			"generation":   gorm.Expr("EXCLUDED.generation"),
+			"pull_seconds": gorm.Expr("EXCLUDED.pull_seconds"),
+			"pulled_at":    gorm.Expr("COALESCE(EXCLUDED.pulled_at, worker_images.pulled_at)"),
+			"checked_at":   gorm.Expr("EXCLUDED.checked_at"),
+		}),
+	}).Create(image).Error
+	if err != nil {
+		return fmt.Errorf("failed to record worker image: %w", err)
+	}
+	return nil
+}
+
+// PruneWorkerImages deletes a worker's records of images not in keep, once
+// they're no longer pre-pulled.
+func (ps PostgresDbStore) PruneWorkerImages(ctx context.Context, workerID string, keep []string) error {
+	query := ps.getDB(ctx).Where("worker_id = ?", workerID)
+	if len(keep) > 0 {
+		query = query.Where("image NOT IN ?", keep)
+	}
+	if err := query.Delete(&models.WorkerImage{}).Error; err != nil {
+		return fmt.Errorf("failed to prune worker images: %w", err)
+	}
+	return nil
+}
+
+// ListWorkerImages returns every worker's image records, by worker and
+// image.
+func (ps PostgresDbStore) ListWorkerImages(ctx context.Context) ([]models.WorkerImage, error) {
+	var images []models.WorkerImage
+	if err := ps.getDB(ctx).Order("worker_id ASC, image ASC").Find(&images).Error; err != nil {
+		return nil, fmt.Errorf("failed to list worker images: %w", err)
+	}
+	return images, nil
+}
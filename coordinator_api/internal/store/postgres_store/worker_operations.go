@@ -0,0 +1,149 @@
+package postgres_store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// RegisterWorker records a worker starting up and returns its row. A
+// worker that registered before keeps its quarantine, but a restart ends a
+// drain.
+func (ps PostgresDbStore) RegisterWorker(ctx context.Context, worker *models.Worker) (*models.Worker, error) {
+	if worker.WorkerID == "" {
+		return nil, store.ErrInvalidInput
+	}
+	err := ps.getDB(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "worker_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"queue_name":    gorm.Expr("EXCLUDED.queue_name"),
+			"platform":      gorm.Expr("EXCLUDED.platform"),
+			"concurrency":   gorm.Expr("EXCLUDED.concurrency"),
+			"running_jobs":  gorm.Expr("EXCLUDED.running_jobs"),
+			"state":         gorm.Expr("CASE WHEN workers.desired_state = 'quarantined' THEN 'quarantined' ELSE 'active' END"),
+			"desired_state": gorm.Expr("CASE WHEN workers.desired_state = 'draining' THEN 'active' ELSE workers.desired_state END"),
+			"started_at":    gorm.Expr("EXCLUDED.started_at"),
+			"last_seen_at":  gorm.Expr("EXCLUDED.last_seen_at"),
+		}),
+	}).Create(worker).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to register worker: %w", err)
+	}
+	return ps.GetWorker(ctx, worker.WorkerID)
+}
+
+// ReportWorker records a worker's state and running job count, and returns
+// its row, carrying the state admins want it in. Returns store.ErrNotFound
+// if the worker isn't registered.
+func (ps PostgresDbStore) ReportWorker(ctx context.Context, workerID, state string, runningJobs int) (*models.Worker, error) {
+	result := ps.getDB(ctx).Model(&models.Worker{}).
+		Where("worker_id = ?", workerID).
+		Updates(map[string]interface{}{
+			"state":        state,
+			"running_jobs": runningJobs,
+			"last_seen_at": time.Now().UTC(),
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to report worker: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, store.ErrNotFound
+	}
+	return ps.GetWorker(ctx, workerID)
+}
+
+// GetWorker returns a registered worker.
+func (ps PostgresDbStore) GetWorker(ctx context.Context, workerID string) (*models.Worker, error) {
+	var worker models.Worker
+	if err := ps.getDB(ctx).Where("worker_id = ?", workerID).First(&worker).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, store.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get worker: %w", err)
+	}
+	return &worker, nil
+}
+
+// ListWorkers returns every registered worker, by ID.
+func (ps PostgresDbStore) ListWorkers(ctx context.Context) ([]models.Worker, error) {
+	var workers []models.Worker
+	if err := ps.getDB(ctx).Order("worker_id ASC").Find(&workers).Error; err != nil {
+		return nil, fmt.Errorf("failed to list workers: %w", err)
+	}
+	return workers, nil
+}
+
+// CountQueuedJobs counts the jobs waiting on each of queues, per target
+// platform.
+func (ps PostgresDbStore) CountQueuedJobs(ctx context.Context, queues []string) ([]models.QueuedJobCount, error) {
+	var counts []models.QueuedJobCount
+	if len(queues) == 0 {
+		return counts, nil
+	}
+	err := ps.getDB(ctx).Model(&models.Job{}).
+		Select("queue_name, target_os, target_arch, COUNT(*) AS jobs").
+		Where("queue_name IN ? AND status IN ('submitted', 'queued')", queues).
+		Group("queue_name, target_os, target_arch").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count queued jobs: %w", err)
+	}
+	return counts, nil
+}
+
+// SetWorkerDesiredState sets the state admins want a worker in, and why.
+// Returns store.ErrNotFound if the worker isn't registered.
+func (ps PostgresDbStore) SetWorkerDesiredState(ctx context.Context, workerID, desiredState, reason string, changedBy *string) (*models.Worker, error) {
+	if !models.IsValidWorkerDesiredState(desiredState) {
+		return nil, store.ErrInvalidInput
+	}
+	result := ps.getDB(ctx).Model(&models.Worker{}).
+		Where("worker_id = ?", workerID).
+		Updates(map[string]interface{}{
+			"desired_state": desiredState,
+			"reason":        reason,
+			"changed_by":    changedBy,
+			"changed_at":    time.Now().UTC(),
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to set worker state: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, store.ErrNotFound
+	}
+	return ps.GetWorker(ctx, workerID)
+}
+
+// ReportWorkerDiagnostics replaces a registered worker's diagnostics
+// report.
+func (ps PostgresDbStore) ReportWorkerDiagnostics(ctx context.Context, workerID string, report models.WorkerDiagnosticsReport) error {
+	diagnostics := models.WorkerDiagnostics{WorkerID: workerID, Report: report, ReportedAt: time.Now().UTC()}
+	err := ps.getDB(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "worker_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"report", "reported_at"}),
+	}).Create(&diagnostics).Error
+	if err != nil {
+		return fmt.Errorf("failed to report worker diagnostics: %w", err)
+	}
+	return nil
+}
+
+// GetWorkerDiagnostics returns the diagnostics a worker last reported.
+// Returns store.ErrNotFound if it hasn't reported any.
+func (ps PostgresDbStore) GetWorkerDiagnostics(ctx context.Context, workerID string) (*models.WorkerDiagnostics, error) {
+	var diagnostics models.WorkerDiagnostics
+	if err := ps.getDB(ctx).Where("worker_id = ?", workerID).First(&diagnostics).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, store.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get worker diagnostics: %w", err)
+	}
+	return &diagnostics, nil
+}
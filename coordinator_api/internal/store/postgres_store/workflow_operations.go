@@ -3,6 +3,7 @@ package postgres_store
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -24,7 +25,7 @@ func (ps PostgresDbStore) GetWorkflowInstance(ctx context.Context, workflowID st
 	}
 	var wf models.WorkflowInstance
 	if err := ps.getDB(ctx).Where("workflow_id = ?", workflowID).First(&wf).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, store.ErrNotFound
 		}
 		return nil, fmt.Errorf("failed to get workflow %s: %w", workflowID, err)
@@ -75,7 +76,7 @@ func (ps PostgresDbStore) GetWorkflowNodeByJobID(ctx context.Context, jobID stri
 	}
 	var node models.WorkflowNode
 	if err := ps.getDB(ctx).Where("job_id = ?", jobID).First(&node).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, store.ErrNotFound
 		}
 		return nil, fmt.Errorf("failed to get workflow node for job %s: %w", jobID, err)
@@ -219,7 +220,7 @@ func (ps PostgresDbStore) ListWorkflowSummaries(ctx context.Context, filters map
 	if len(whereWorkflow) > 0 {
 		workflowClause = "WHERE " + strings.Join(whereWorkflow, " AND ")
 	}
-	looseClause := "WHERE j.workflow_id IS NULL"
+	looseClause := "WHERE j.workflow_id IS NULL AND j.deleted_at IS NULL"
 	if len(whereLoose) > 0 {
 		looseClause += " AND " + strings.Join(whereLoose, " AND ")
 	}
@@ -27,8 +27,12 @@ func GetDBFromContext(ctx context.Context) *gorm.DB {
 	if tx, ok := ctx.Value(ctxkey.TxKey()).(*gorm.DB); ok && tx != nil {
 		return tx
 	}
-	// Otherwise return the global DB
-	return GetDB()
+	// Otherwise the global DB, bound to ctx so cancelling ctx cancels the
+	// query
+	if db := GetDB(); db != nil {
+		return db.WithContext(ctx)
+	}
+	return nil
 }
 
 type Store interface {
@@ -37,7 +41,7 @@ type Store interface {
 	// Project operations
 	CreateProject(ctx context.Context, project *models.Project) error
 	GetProjectByID(ctx context.Context, projectID string) (*models.Project, error)
-	GetProjectByRepoURL(ctx context.Context, repoURL string) (*models.Project, error)
+	GetProjectsByRepoURL(ctx context.Context, repoURL string) ([]models.Project, error)
 	UpdateProject(ctx context.Context, project *models.Project) error
 	DeleteProject(ctx context.Context, projectID string) error
 	ListProjects(ctx context.Context, limit, offset int) ([]models.Project, error)
@@ -12,8 +12,10 @@ var (
 	ErrAlreadyExists      = errors.New("record already exists")
 	ErrInternal           = errors.New("internal error")
 	ErrUnauthorized       = errors.New("unauthorized")
-	ErrForbidden          = errors.New("forbidden")           // 403 Forbidden - for permission issues
-	ErrServiceUnavailable = errors.New("service unavailable") // 503 Service Unavailable - for external dependencies
+	ErrForbidden          = errors.New("forbidden")                     // 403 Forbidden - for permission issues
+	ErrServiceUnavailable = errors.New("service unavailable")           // 503 Service Unavailable - for external dependencies
+	ErrConflict           = errors.New("conflicting concurrent update") // 409 Conflict - serialization failure or deadlock; retryable
+	ErrReferenceViolation = errors.New("reference violation")           // 409 Conflict - a referenced record is missing or still referenced
 )
 
 // PaginationParams contains common pagination parameters
@@ -82,6 +82,10 @@ func mapLoginErr(err error) error {
 		return NewServiceError("login_disabled", "login is disabled")
 	case errors.Is(err, auth.ErrNotAdmitted):
 		return NewServiceError("forbidden", "this identity is not on the admission list")
+	case errors.Is(err, auth.ErrServiceAccountLogin):
+		return NewServiceError("forbidden", "service accounts cannot log in interactively")
+	case errors.Is(err, auth.ErrUserDeactivated):
+		return NewServiceError("forbidden", "this user has been deactivated")
 	case errors.Is(err, auth.ErrAttemptExpired):
 		return NewServiceError("invalid_argument", "login attempt has expired; please start over")
 	case errors.Is(err, auth.ErrAssertionNotVerified):
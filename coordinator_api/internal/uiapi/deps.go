@@ -89,7 +89,8 @@ func (d *Deps) requireUser(ctx context.Context) (authz.Identity, *models.User, e
 // mirroring handlers/router.go's makeTokenResolver and
 // handlers/secrets_handler.go's getProvider: resolve the org's encryption
 // key under the configured master keys, then wrap the request-scoped (or
-// global, outside a transaction) DB handle in a DatabaseProvider. Returns a
+// global, outside a transaction) DB handle in a DatabaseProvider restricted
+// to the caller's secret access policies. Returns a
 // ServiceErr "internal" if secrets aren't configured on this server (no
 // master key manager) rather than a nil-pointer panic. This is Deps'
 // production SecretsProvider; see that field's doc comment for how tests
@@ -110,5 +111,14 @@ func (d *Deps) defaultSecretsProviderForOrg(ctx context.Context, orgID string) (
 	if err != nil {
 		return nil, NewServiceError("internal", "failed to build secrets provider")
 	}
+	principal := secrets.Principal{}
+	if _, user := d.resolveIdentity(ctx); user != nil {
+		principal.UserID, principal.Roles = user.UserID, user.Roles
+	}
+	access, err := secrets.LoadAccessPolicy(ctx, db, orgID, principal)
+	if err != nil {
+		return nil, NewServiceError("internal", "failed to load secret access policies")
+	}
+	provider.RestrictTo(access)
 	return provider, nil
 }
@@ -172,15 +172,19 @@ func (f *fakeStore) GetProjectByID(_ context.Context, projectID string) (*models
 	return &p, nil
 }
 
-func (f *fakeStore) GetProjectByRepoURL(_ context.Context, repoURL string) (*models.Project, error) {
+func (f *fakeStore) GetProjectsByRepoURL(_ context.Context, repoURL string) ([]models.Project, error) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
+	var projects []models.Project
 	for _, p := range f.projects {
 		if p.RepoURL == repoURL {
-			return &p, nil
+			projects = append(projects, p)
 		}
 	}
-	return nil, store.ErrNotFound
+	if len(projects) == 0 {
+		return nil, store.ErrNotFound
+	}
+	return projects, nil
 }
 
 func (f *fakeStore) UpdateProject(_ context.Context, project *models.Project) error {
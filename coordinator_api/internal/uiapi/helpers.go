@@ -76,6 +76,9 @@ func mapSecretsErr(err error) error {
 	if errors.Is(err, secrets.ErrInvalidPath) || errors.Is(err, secrets.ErrInvalidKey) {
 		return NewServiceError("invalid_argument", err.Error())
 	}
+	if secrets.IsAuthorizationError(err) {
+		return NewServiceError("forbidden", err.Error())
+	}
 	if errors.Is(err, secrets.ErrNotInitialized) {
 		return NewServiceError("internal", "secrets storage is not initialized for this org")
 	}
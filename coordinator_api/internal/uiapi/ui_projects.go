@@ -206,6 +206,9 @@ func (s *UiService) CreateProject(ctx context.Context, req csilapi.CreateProject
 		Enabled:     true,
 	}
 	if req.TargetBranches != nil {
+		if err := models.ValidateRefPatterns(req.TargetBranches); err != nil {
+			return csilapi.CreateProjectResponse{}, NewServiceError("invalid_argument", err.Error())
+		}
 		project.TargetBranches = req.TargetBranches
 	}
 	if req.AllowedEventTypes != nil {
@@ -253,6 +256,9 @@ func (s *UiService) UpdateProject(ctx context.Context, req csilapi.UpdateProject
 		project.Enabled = *req.Enabled
 	}
 	if req.TargetBranches != nil {
+		if err := models.ValidateRefPatterns(req.TargetBranches); err != nil {
+			return csilapi.UpdateProjectResponse{}, NewServiceError("invalid_argument", err.Error())
+		}
 		project.TargetBranches = req.TargetBranches
 	}
 	if req.AllowedEventTypes != nil {
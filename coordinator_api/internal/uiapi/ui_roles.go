@@ -139,9 +139,15 @@ func (s *UiService) AssignRole(ctx context.Context, req csilapi.AssignRoleReques
 	}
 
 	if req.PrincipalType == models.PrincipalTypeUser {
-		if _, err := s.deps.Store.GetUserByID(ctx, req.PrincipalId); err != nil {
+		principal, err := s.deps.Store.GetUserByID(ctx, req.PrincipalId)
+		if err != nil {
 			return csilapi.AssignRoleResponse{}, NewServiceError("invalid_argument", "principal_id does not refer to a known user")
 		}
+		if principal.IsServiceAccount {
+			if err := models.ValidateServiceAccountRole(req.ScopeType, req.Role); err != nil {
+				return csilapi.AssignRoleResponse{}, NewServiceError("invalid_argument", err.Error())
+			}
+		}
 	} else {
 		if _, err := s.deps.Store.GetGroupByID(ctx, req.PrincipalId); err != nil {
 			return csilapi.AssignRoleResponse{}, NewServiceError("invalid_argument", "principal_id does not refer to a known group")
@@ -17,4 +17,8 @@ var (
 
 	// ErrInvalidPayload indicates the webhook payload is invalid
 	ErrInvalidPayload = errors.New("invalid webhook payload")
+
+	// ErrRateLimited indicates the VCS API quota is exhausted for longer
+	// than the client waits for it to reset
+	ErrRateLimited = errors.New("VCS API rate limit exceeded")
 )
\ No newline at end of file
@@ -13,12 +13,30 @@ const (
 	EventPullRequestClosed  EventType = "pull_request_closed"
 	EventTagCreated         EventType = "tag_created"
 	EventPing               EventType = "ping"
+
+	// EventPullRequestReadyForReview is a draft PR marked ready for review.
+	EventPullRequestReadyForReview EventType = "pull_request_ready_for_review"
+	// EventPullRequestRetitled is a PR whose title was edited;
+	// PullRequestInfo.PreviousTitle holds the old title. Together with
+	// EventPullRequestReadyForReview it lets deferred draft/WIP PRs build
+	// once they're ready.
+	EventPullRequestRetitled EventType = "pull_request_retitled"
+	// EventMergeGroup asks for checks on a speculative merge commit before
+	// it lands (see MergeGroupInfo).
+	EventMergeGroup EventType = "merge_group"
+
 	// EventDirectlySubmitted marks jobs submitted directly through the API/CLI
 	// rather than by a VCS webhook. Such jobs have no VCS provider integration,
 	// so they never post commit statuses or PR comments; the type exists to keep
 	// their workflow comment marker distinct from real VCS events.
 	EventDirectlySubmitted EventType = "directly_submitted"
-	EventUnknown           EventType = ""
+	// EventUpstreamCompleted marks eval jobs started by a job finishing in
+	// another project (see models.DownstreamTrigger), not by a VCS event.
+	EventUpstreamCompleted EventType = "upstream_completed"
+	// EventManual marks eval jobs started by hand from a project (POST
+	// /api/v1/projects/{id}/run), not by a VCS event.
+	EventManual  EventType = "manual"
+	EventUnknown EventType = ""
 )
 
 // GenericEventFromGitHub translates a GitHub webhook event into a generic EventType.
@@ -39,12 +57,25 @@ func GenericEventFromGitHub(eventType, action string, pr *PullRequestInfo, push
 		}
 		return EventUnknown
 
+	case "merge_group":
+		if action == "checks_requested" {
+			return EventMergeGroup
+		}
+		return EventUnknown
+
 	case "pull_request":
 		switch action {
 		case "opened", "reopened":
 			return EventPullRequestOpened
 		case "synchronize":
 			return EventPullRequestUpdated
+		case "ready_for_review":
+			return EventPullRequestReadyForReview
+		case "edited":
+			if pr != nil && pr.PreviousTitle != "" {
+				return EventPullRequestRetitled
+			}
+			return EventUnknown
 		case "closed":
 			if pr != nil && pr.Merged {
 				return EventPullRequestMerged
@@ -58,3 +89,39 @@ func GenericEventFromGitHub(eventType, action string, pr *PullRequestInfo, push
 		return EventUnknown
 	}
 }
+
+// GenericEventFromGerrit translates a Gerrit event into a generic EventType.
+// Changes are treated as pull requests, so a new patchset updates one.
+func GenericEventFromGerrit(eventType string, pr *PullRequestInfo, push *PushInfo) EventType {
+	switch eventType {
+	case "ref-updated":
+		if push == nil {
+			return EventUnknown
+		}
+		if strings.HasPrefix(push.Ref, "refs/tags/") {
+			return EventTagCreated
+		}
+		return EventPush
+
+	case "patchset-created":
+		if pr != nil && pr.Action == "opened" {
+			return EventPullRequestOpened
+		}
+		return EventPullRequestUpdated
+
+	case "change-merged":
+		return EventPullRequestMerged
+
+	case "change-abandoned":
+		return EventPullRequestClosed
+
+	case "wip-state-changed":
+		if pr != nil && pr.Action == "ready_for_review" {
+			return EventPullRequestReadyForReview
+		}
+		return EventUnknown
+
+	default:
+		return EventUnknown
+	}
+}
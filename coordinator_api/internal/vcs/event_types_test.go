@@ -75,6 +75,27 @@ func TestGenericEventFromGitHub(t *testing.T) {
 			pr:        &PullRequestInfo{Action: "synchronize"},
 			want:      EventPullRequestUpdated,
 		},
+		{
+			name:      "PR ready for review",
+			eventType: "pull_request",
+			action:    "ready_for_review",
+			pr:        &PullRequestInfo{Action: "ready_for_review"},
+			want:      EventPullRequestReadyForReview,
+		},
+		{
+			name:      "PR title edited",
+			eventType: "pull_request",
+			action:    "edited",
+			pr:        &PullRequestInfo{Action: "edited", Title: "Add login", PreviousTitle: "WIP: Add login"},
+			want:      EventPullRequestRetitled,
+		},
+		{
+			name:      "PR body edited",
+			eventType: "pull_request",
+			action:    "edited",
+			pr:        &PullRequestInfo{Action: "edited", Title: "Add login"},
+			want:      EventUnknown,
+		},
 		{
 			name:      "PR closed and merged",
 			eventType: "pull_request",
@@ -117,6 +138,19 @@ func TestGenericEventFromGitHub(t *testing.T) {
 			eventType: "ping",
 			want:      EventPing,
 		},
+		// Merge group events
+		{
+			name:      "merge group checks requested",
+			eventType: "merge_group",
+			action:    "checks_requested",
+			want:      EventMergeGroup,
+		},
+		{
+			name:      "merge group destroyed",
+			eventType: "merge_group",
+			action:    "destroyed",
+			want:      EventUnknown,
+		},
 		// Unknown event types
 		{
 			name:      "issues event",
@@ -145,6 +179,9 @@ func TestEventTypeConstants(t *testing.T) {
 	assert.Equal(t, EventType("pull_request_updated"), EventPullRequestUpdated)
 	assert.Equal(t, EventType("pull_request_merged"), EventPullRequestMerged)
 	assert.Equal(t, EventType("pull_request_closed"), EventPullRequestClosed)
+	assert.Equal(t, EventType("pull_request_ready_for_review"), EventPullRequestReadyForReview)
+	assert.Equal(t, EventType("pull_request_retitled"), EventPullRequestRetitled)
 	assert.Equal(t, EventType("tag_created"), EventTagCreated)
+	assert.Equal(t, EventType("merge_group"), EventMergeGroup)
 	assert.Equal(t, EventType(""), EventUnknown)
 }
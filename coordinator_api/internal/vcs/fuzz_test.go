@@ -0,0 +1,41 @@
+package vcs
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+)
+
+// The webhook parsers take untrusted input, so they must fail cleanly, never
+// panic, on anything.
+
+func FuzzGitHubParseWebhook(f *testing.F) {
+	client, err := NewGitHubClient(Config{Provider: GitHub})
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add("pull_request", []byte(`{"action":"opened","number":1,"pull_request":{"head":{"ref":"f","sha":"abc"},"base":{"ref":"main"}},"repository":{"full_name":"o/r"}}`))
+	f.Add("push", []byte(`{"ref":"refs/heads/main","after":"abc","commits":[{"id":"abc"}],"repository":{"full_name":"o/r"}}`))
+	f.Add("merge_group", []byte(`{"action":"checks_requested","merge_group":{"head_sha":"abc"}}`))
+	f.Add("ping", []byte(`{}`))
+	f.Fuzz(func(t *testing.T, eventType string, body []byte) {
+		req := httptest.NewRequest("POST", "/webhook", bytes.NewReader(body))
+		req.Header.Set("X-GitHub-Event", eventType)
+		client.ParseWebhook(req)
+	})
+}
+
+func FuzzGitLabParseWebhook(f *testing.F) {
+	client, err := NewGitLabClient(Config{Provider: GitLab})
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add("Merge Request Hook", []byte(`{"object_kind":"merge_request","object_attributes":{"iid":1,"action":"open","source_branch":"f","target_branch":"main","last_commit":{"id":"abc"}},"project":{"path_with_namespace":"g/p"}}`))
+	f.Add("Push Hook", []byte(`{"object_kind":"push","ref":"refs/heads/main","after":"abc","project":{"path_with_namespace":"g/p"}}`))
+	f.Add("System Hook", []byte(`{"event_name":"push"}`))
+	f.Fuzz(func(t *testing.T, eventType string, body []byte) {
+		req := httptest.NewRequest("POST", "/webhook", bytes.NewReader(body))
+		req.Header.Set("X-Gitlab-Event", eventType)
+		client.ParseWebhook(req)
+	})
+}
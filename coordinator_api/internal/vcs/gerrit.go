@@ -0,0 +1,571 @@
+package vcs
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+// GerritTokenHeader carries the shared webhook secret on Gerrit event
+// deliveries. Gerrit's webhooks plugin doesn't sign its requests, so it (or
+// the stream-events bridge) is configured to send the secret as a header.
+const GerritTokenHeader = "X-Gerrit-Token"
+
+// gerritReviewTag marks Reactorcide's review messages as automated, so
+// Gerrit's UI can hide them behind "Only comments".
+const gerritReviewTag = "autogenerated:reactorcide"
+
+// gerritXSSIPrefix starts every Gerrit REST response body.
+const gerritXSSIPrefix = ")]}'"
+
+// GerritClient implements VCS client for Gerrit. Changes map to pull
+// requests, numbered by change number, with the patchset's ref (e.g.
+// refs/changes/45/12345/2) as the head ref. Job results are reported as
+// votes on labels (Verified by default) with a review message, since
+// Gerrit has no commit statuses.
+type GerritClient struct {
+	config   Config
+	client   *http.Client
+	logger   *logrus.Logger
+	username string
+	password string
+	labels   []string
+}
+
+// NewGerritClient creates a new Gerrit VCS client. cfg.BaseURL is the
+// server's web URL, and cfg.Token "username:http-password".
+func NewGerritClient(cfg Config) (*GerritClient, error) {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = config.VCSGerritURL
+	}
+	cfg.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+
+	logger := logrus.New()
+	logger.SetFormatter(&logrus.JSONFormatter{})
+
+	c := &GerritClient{
+		config: cfg,
+		client: newSharedHTTPClient(),
+		logger: logger,
+	}
+	c.username, c.password, _ = strings.Cut(cfg.Token, ":")
+	for _, label := range strings.Split(config.VCSGerritLabels, ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			c.labels = append(c.labels, label)
+		}
+	}
+	return c, nil
+}
+
+// GetProvider returns the provider type
+func (c *GerritClient) GetProvider() Provider {
+	return Gerrit
+}
+
+// ParseWebhook parses a Gerrit event, as sent by the webhooks plugin or a
+// stream-events bridge: patchset-created, change-merged, change-abandoned,
+// wip-state-changed and ref-updated are understood.
+func (c *GerritClient) ParseWebhook(r *http.Request) (*WebhookEvent, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading request body: %w", err)
+	}
+
+	var payload gerritEvent
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	if payload.Type == "" {
+		return nil, ErrMissingEventHeader
+	}
+
+	event := &WebhookEvent{
+		Provider:   Gerrit,
+		EventType:  payload.Type,
+		DeliveryID: payload.deliveryID(),
+		RawPayload: body,
+	}
+	if project := payload.project(); project != "" {
+		base := gerritBaseURL(c.config.BaseURL, payload.Change)
+		event.Repository = RepositoryInfo{
+			FullName: project,
+			CloneURL: base + "/" + project,
+			HTMLURL:  base + "/q/project:" + url.QueryEscape(project),
+		}
+	}
+
+	switch payload.Type {
+	case "patchset-created", "change-merged", "change-abandoned", "wip-state-changed":
+		if payload.Change == nil || payload.PatchSet == nil {
+			return nil, fmt.Errorf("%w: %s event without change or patchset", ErrInvalidPayload, payload.Type)
+		}
+		event.PullRequest = payload.pullRequest()
+	case "ref-updated":
+		if payload.RefUpdate == nil {
+			return nil, fmt.Errorf("%w: ref-updated event without refUpdate", ErrInvalidPayload)
+		}
+		event.Push = payload.push()
+	default:
+		c.logger.WithField("event_type", payload.Type).Debug("Unsupported Gerrit event type")
+	}
+
+	event.GenericEvent = GenericEventFromGerrit(payload.Type, event.PullRequest, event.Push)
+	return event, nil
+}
+
+// ValidateWebhook checks the shared secret in GerritTokenHeader.
+func (c *GerritClient) ValidateWebhook(r *http.Request, secret string) error {
+	if secret == "" {
+		return nil // No validation if secret not configured
+	}
+
+	token := r.Header.Get(GerritTokenHeader)
+	if token == "" {
+		return ErrMissingSignature
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// UpdateCommitStatus reports a job's result on the change update.SHA
+// belongs to: +1 on each configured label for success, -1 for failure or
+// error, with a review message either way. A cancelled job only gets the
+// message, and pending and running states aren't reported, since every
+// review adds a message to the change. A -1 already given to the change's
+// current patchset is kept, so one job passing can't hide another's
+// failure.
+func (c *GerritClient) UpdateCommitStatus(ctx context.Context, repo string, update StatusUpdate) error {
+	var vote int
+	switch update.State {
+	case StatusSuccess:
+		vote = 1
+	case StatusFailure, StatusError:
+		vote = -1
+	case StatusCancelled:
+	default:
+		return nil
+	}
+
+	change, err := c.changeForCommit(ctx, repo, update.SHA)
+	if err != nil {
+		return err
+	}
+
+	review := gerritReviewInput{
+		Message: gerritReviewMessage(update),
+		Tag:     gerritReviewTag,
+	}
+	if vote != 0 {
+		review.Labels = make(map[string]int)
+		for _, label := range c.labels {
+			if vote > 0 && change.CurrentRevision == update.SHA && change.votedAgainst(label, c.username) {
+				continue
+			}
+			review.Labels[label] = vote
+		}
+	}
+	// Gerrit returns change IDs already escaped for use in paths.
+	path := fmt.Sprintf("/changes/%s/revisions/%s/review", change.ID, update.SHA)
+	if err := c.do(ctx, http.MethodPost, path, review, nil); err != nil {
+		return err
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"repo":    repo,
+		"change":  change.Number,
+		"sha":     update.SHA,
+		"state":   update.State,
+		"context": update.Context,
+	}).Info("Posted Gerrit review")
+
+	return nil
+}
+
+// UpdatePRComment posts comment as a review message on the change's
+// current patchset.
+func (c *GerritClient) UpdatePRComment(ctx context.Context, repo string, prNumber int, comment string) error {
+	path := fmt.Sprintf("/changes/%s/revisions/current/review", gerritChangeID(repo, prNumber))
+	return c.do(ctx, http.MethodPost, path, gerritReviewInput{Message: comment, Tag: gerritReviewTag}, nil)
+}
+
+// UpsertPRCommentByMarker posts body as a new review message: Gerrit
+// review messages can't be edited. Gerrit's registry entry doesn't offer
+// PR comments, so job status updates don't come here.
+func (c *GerritClient) UpsertPRCommentByMarker(ctx context.Context, repo string, prNumber int, marker, body string) error {
+	return c.UpdatePRComment(ctx, repo, prNumber, body)
+}
+
+// GetPRInfo gets information about a Gerrit change
+func (c *GerritClient) GetPRInfo(ctx context.Context, repo string, prNumber int) (*PullRequestInfo, error) {
+	var change gerritChangeInfo
+	path := fmt.Sprintf("/changes/%s?o=CURRENT_REVISION&o=CURRENT_COMMIT&o=DETAILED_ACCOUNTS", gerritChangeID(repo, prNumber))
+	if err := c.do(ctx, http.MethodGet, path, nil, &change); err != nil {
+		return nil, err
+	}
+
+	info := &PullRequestInfo{
+		Number:      change.Number,
+		Title:       change.Subject,
+		State:       gerritChangeState(change.Status),
+		Merged:      change.Status == "MERGED",
+		HeadSHA:     change.CurrentRevision,
+		BaseRef:     change.Branch,
+		AuthorLogin: change.Owner.Username,
+		AuthorEmail: change.Owner.Email,
+		Draft:       change.WorkInProgress,
+		HTMLURL:     fmt.Sprintf("%s/c/%s/+/%d", c.config.BaseURL, change.Project, change.Number),
+	}
+	if revision, ok := change.Revisions[change.CurrentRevision]; ok {
+		info.HeadRef = revision.Ref
+		info.Description = revision.Commit.Message
+		if len(revision.Commit.Parents) > 0 {
+			info.BaseSHA = revision.Commit.Parents[0].Commit
+		}
+	}
+	return info, nil
+}
+
+// changeForCommit finds the change in repo with a patchset at sha, with
+// its current labels, bypassing the response cache.
+func (c *GerritClient) changeForCommit(ctx context.Context, repo, sha string) (*gerritChangeInfo, error) {
+	query := url.QueryEscape(fmt.Sprintf("commit:%s project:%s", sha, repo))
+	var changes []gerritChangeInfo
+	path := "/changes/?q=" + query + "&o=CURRENT_REVISION&o=DETAILED_LABELS&o=DETAILED_ACCOUNTS"
+	// The votes decide what's posted, so a cached response won't do.
+	if err := c.do(ctx, http.MethodGet, path, nil, &changes, "no-cache"); err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("no Gerrit change in %s has commit %s", repo, sha)
+	}
+	return &changes[0], nil
+}
+
+// do sends a REST request, authenticated (under /a/) when the client has
+// credentials, and decodes the response into out unless it's nil. A
+// cacheControl of "no-cache" skips the shared transport's response cache.
+func (c *GerritClient) do(ctx context.Context, method, path string, in, out interface{}, cacheControl ...string) error {
+	if c.config.BaseURL == "" {
+		return fmt.Errorf("Gerrit URL not configured (REACTORCIDE_VCS_GERRIT_URL)")
+	}
+	if c.username != "" {
+		path = "/a" + path
+	}
+
+	var body io.Reader
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("marshaling request: %w", err)
+		}
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.config.BaseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if len(cacheControl) > 0 {
+		req.Header.Set("Cache-Control", cacheControl[0])
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(respBody))
+	}
+	if out == nil {
+		return nil
+	}
+	respBody = bytes.TrimPrefix(respBody, []byte(gerritXSSIPrefix))
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
+
+// gerritChangeID identifies change number in repo for the REST API.
+func gerritChangeID(repo string, number int) string {
+	return url.PathEscape(repo) + "~" + strconv.Itoa(number)
+}
+
+// gerritBaseURL returns the server's web URL: configured, or else taken
+// from the change's URL (https://host/c/project/+/123).
+func gerritBaseURL(configured string, change *gerritChange) string {
+	if configured != "" {
+		return strings.TrimSuffix(configured, "/")
+	}
+	if change != nil {
+		if i := strings.Index(change.URL, "/c/"); i >= 0 {
+			return change.URL[:i]
+		}
+		if u, err := url.Parse(change.URL); err == nil && u.Host != "" {
+			return u.Scheme + "://" + u.Host
+		}
+	}
+	return ""
+}
+
+// GerritRepoURL returns the clone URL of the repository a Gerrit event
+// payload is for, to look up its projects before the delivery is
+// validated. See ProviderSpec.RepoURL.
+func GerritRepoURL(body []byte) (string, error) {
+	var payload gerritEvent
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return "", fmt.Errorf("unmarshal: %w", err)
+	}
+	project := payload.project()
+	if project == "" {
+		return "", fmt.Errorf("no project found in payload")
+	}
+	base := gerritBaseURL(config.VCSGerritURL, payload.Change)
+	if base == "" {
+		return "", fmt.Errorf("no Gerrit URL configured or in payload")
+	}
+	return base + "/" + project, nil
+}
+
+// gerritReviewMessage describes update for a review message.
+func gerritReviewMessage(update StatusUpdate) string {
+	var verdict string
+	switch update.State {
+	case StatusSuccess:
+		verdict = "succeeded"
+	case StatusCancelled:
+		verdict = "was cancelled"
+	default:
+		verdict = "failed"
+	}
+	message := fmt.Sprintf("Reactorcide: %s %s", update.Context, verdict)
+	if update.Description != "" {
+		message += ": " + update.Description
+	}
+	if update.TargetURL != "" {
+		message += "\n\n" + update.TargetURL
+	}
+	return message
+}
+
+// gerritChangeState maps a change's status to a pull request state.
+func gerritChangeState(status string) string {
+	switch status {
+	case "MERGED":
+		return "merged"
+	case "ABANDONED":
+		return "closed"
+	default:
+		return "open"
+	}
+}
+
+// Gerrit event structures (stream-events and the webhooks plugin share
+// them)
+type gerritEvent struct {
+	Type           string           `json:"type"`
+	Change         *gerritChange    `json:"change"`
+	PatchSet       *gerritPatchSet  `json:"patchSet"`
+	RefUpdate      *gerritRefUpdate `json:"refUpdate"`
+	Submitter      *gerritAccount   `json:"submitter"`
+	NewRev         string           `json:"newRev"`
+	EventCreatedOn int64            `json:"eventCreatedOn"`
+}
+
+type gerritChange struct {
+	Project       string        `json:"project"`
+	Branch        string        `json:"branch"`
+	ID            string        `json:"id"`
+	Number        int           `json:"number"`
+	Subject       string        `json:"subject"`
+	Owner         gerritAccount `json:"owner"`
+	URL           string        `json:"url"`
+	CommitMessage string        `json:"commitMessage"`
+	Status        string        `json:"status"`
+	WIP           bool          `json:"wip"`
+}
+
+type gerritPatchSet struct {
+	Number   int           `json:"number"`
+	Revision string        `json:"revision"`
+	Parents  []string      `json:"parents"`
+	Ref      string        `json:"ref"`
+	Uploader gerritAccount `json:"uploader"`
+	Kind     string        `json:"kind"`
+}
+
+type gerritRefUpdate struct {
+	OldRev  string `json:"oldRev"`
+	NewRev  string `json:"newRev"`
+	RefName string `json:"refName"`
+	Project string `json:"project"`
+}
+
+type gerritAccount struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Username string `json:"username"`
+}
+
+func (e *gerritEvent) project() string {
+	if e.Change != nil {
+		return e.Change.Project
+	}
+	if e.RefUpdate != nil {
+		return e.RefUpdate.Project
+	}
+	return ""
+}
+
+// deliveryID identifies the event for the webhook event ledger. Gerrit
+// doesn't number its events, so it's built from what the event is about,
+// which a redelivery repeats.
+func (e *gerritEvent) deliveryID() string {
+	switch {
+	case e.Change != nil && e.PatchSet != nil:
+		return fmt.Sprintf("%s:%s:%d:%d", e.Type, e.Change.Project, e.Change.Number, e.PatchSet.Number)
+	case e.RefUpdate != nil:
+		return fmt.Sprintf("%s:%s:%s:%s", e.Type, e.RefUpdate.Project, e.RefUpdate.RefName, e.RefUpdate.NewRev)
+	default:
+		return ""
+	}
+}
+
+func (e *gerritEvent) pullRequest() *PullRequestInfo {
+	pr := &PullRequestInfo{
+		Number:      e.Change.Number,
+		Title:       e.Change.Subject,
+		Description: e.Change.CommitMessage,
+		State:       "open",
+		HeadSHA:     e.PatchSet.Revision,
+		HeadRef:     e.PatchSet.Ref,
+		BaseRef:     e.Change.Branch,
+		Action:      "synchronize",
+		HTMLURL:     e.Change.URL,
+		AuthorLogin: e.Change.Owner.Username,
+		AuthorEmail: e.Change.Owner.Email,
+		Draft:       e.Change.WIP,
+	}
+	if len(e.PatchSet.Parents) > 0 {
+		pr.BaseSHA = e.PatchSet.Parents[0]
+	}
+	switch e.Type {
+	case "patchset-created":
+		if e.PatchSet.Number == 1 {
+			pr.Action = "opened"
+		}
+	case "change-merged":
+		pr.State = "merged"
+		pr.Merged = true
+		pr.Action = "closed"
+	case "change-abandoned":
+		pr.State = "closed"
+		pr.Action = "closed"
+	case "wip-state-changed":
+		pr.Action = "edited"
+		if !e.Change.WIP {
+			pr.Action = "ready_for_review"
+		}
+	}
+	return pr
+}
+
+// push returns a ref-updated event's branch or tag update, or nil for
+// Gerrit's internal refs (changes, meta data).
+func (e *gerritEvent) push() *PushInfo {
+	ref := e.RefUpdate.RefName
+	if !strings.HasPrefix(ref, "refs/") {
+		// Older Gerrit versions send branch names unqualified.
+		ref = "refs/heads/" + ref
+	}
+	if !strings.HasPrefix(ref, "refs/heads/") && !strings.HasPrefix(ref, "refs/tags/") {
+		return nil
+	}
+	const zeroSHA = "0000000000000000000000000000000000000000"
+	push := &PushInfo{
+		Ref:     ref,
+		Before:  e.RefUpdate.OldRev,
+		After:   e.RefUpdate.NewRev,
+		Created: e.RefUpdate.OldRev == zeroSHA,
+		Deleted: e.RefUpdate.NewRev == zeroSHA,
+	}
+	if e.Submitter != nil {
+		push.Pusher = e.Submitter.Username
+		push.PusherEmail = e.Submitter.Email
+	}
+	return push
+}
+
+// Gerrit REST API structures
+type gerritChangeInfo struct {
+	ID              string                        `json:"id"`
+	Project         string                        `json:"project"`
+	Branch          string                        `json:"branch"`
+	Subject         string                        `json:"subject"`
+	Status          string                        `json:"status"`
+	Number          int                           `json:"_number"`
+	Owner           gerritAccount                 `json:"owner"`
+	WorkInProgress  bool                          `json:"work_in_progress"`
+	CurrentRevision string                        `json:"current_revision"`
+	Revisions       map[string]gerritRevisionInfo `json:"revisions"`
+	Labels          map[string]gerritLabelInfo    `json:"labels"`
+}
+
+type gerritRevisionInfo struct {
+	Ref    string `json:"ref"`
+	Commit struct {
+		Message string `json:"message"`
+		Parents []struct {
+			Commit string `json:"commit"`
+		} `json:"parents"`
+	} `json:"commit"`
+}
+
+type gerritLabelInfo struct {
+	All []struct {
+		Username string `json:"username"`
+		Value    int    `json:"value"`
+	} `json:"all"`
+}
+
+type gerritReviewInput struct {
+	Message string         `json:"message,omitempty"`
+	Labels  map[string]int `json:"labels,omitempty"`
+	Tag     string         `json:"tag,omitempty"`
+}
+
+// votedAgainst reports whether username voted below zero on label.
+func (c *gerritChangeInfo) votedAgainst(label, username string) bool {
+	for _, approval := range c.Labels[label].All {
+		if approval.Username == username && approval.Value < 0 {
+			return true
+		}
+	}
+	return false
+}
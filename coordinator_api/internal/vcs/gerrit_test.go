@@ -0,0 +1,222 @@
+package vcs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const gerritPatchsetCreated = `{
+	"type": "patchset-created",
+	"change": {
+		"project": "platform/api",
+		"branch": "main",
+		"id": "I8473b95934b5732ac55d26311a706c9c2bde9940",
+		"number": 12345,
+		"subject": "Add retries",
+		"owner": {"name": "Dev", "email": "dev@example.com", "username": "dev"},
+		"url": "https://review.example.com/c/platform/api/+/12345",
+		"commitMessage": "Add retries\n\nChange-Id: I8473b95934b5732ac55d26311a706c9c2bde9940\n",
+		"status": "NEW"
+	},
+	"patchSet": {
+		"number": 2,
+		"revision": "aaa111",
+		"parents": ["bbb222"],
+		"ref": "refs/changes/45/12345/2",
+		"uploader": {"username": "dev"},
+		"kind": "REWORK"
+	},
+	"eventCreatedOn": 1792281600
+}`
+
+func parseGerrit(t *testing.T, client *GerritClient, payload string) *WebhookEvent {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/gerrit", strings.NewReader(payload))
+	event, err := client.ParseWebhook(req)
+	require.NoError(t, err)
+	return event
+}
+
+func TestGerritClient_ParseWebhook(t *testing.T) {
+	client, err := NewGerritClient(Config{Provider: Gerrit})
+	require.NoError(t, err)
+
+	t.Run("patchset-created", func(t *testing.T) {
+		event := parseGerrit(t, client, gerritPatchsetCreated)
+		assert.Equal(t, Gerrit, event.Provider)
+		assert.Equal(t, EventPullRequestUpdated, event.GenericEvent)
+		assert.Equal(t, "patchset-created:platform/api:12345:2", event.DeliveryID)
+		assert.Equal(t, "platform/api", event.Repository.FullName)
+		assert.Equal(t, "https://review.example.com/platform/api", event.Repository.CloneURL)
+		require.NotNil(t, event.PullRequest)
+		assert.Equal(t, 12345, event.PullRequest.Number)
+		assert.Equal(t, "aaa111", event.PullRequest.HeadSHA)
+		assert.Equal(t, "refs/changes/45/12345/2", event.PullRequest.HeadRef)
+		assert.Equal(t, "main", event.PullRequest.BaseRef)
+		assert.Equal(t, "bbb222", event.PullRequest.BaseSHA)
+		assert.Equal(t, "dev", event.PullRequest.AuthorLogin)
+	})
+
+	t.Run("first patchset opens the change", func(t *testing.T) {
+		payload := strings.Replace(gerritPatchsetCreated, `"number": 2`, `"number": 1`, 1)
+		event := parseGerrit(t, client, payload)
+		assert.Equal(t, EventPullRequestOpened, event.GenericEvent)
+		assert.Equal(t, "opened", event.PullRequest.Action)
+	})
+
+	t.Run("change-merged", func(t *testing.T) {
+		payload := strings.Replace(gerritPatchsetCreated, "patchset-created", "change-merged", 1)
+		event := parseGerrit(t, client, payload)
+		assert.Equal(t, EventPullRequestMerged, event.GenericEvent)
+		assert.True(t, event.PullRequest.Merged)
+	})
+
+	t.Run("change-abandoned", func(t *testing.T) {
+		payload := strings.Replace(gerritPatchsetCreated, "patchset-created", "change-abandoned", 1)
+		assert.Equal(t, EventPullRequestClosed, parseGerrit(t, client, payload).GenericEvent)
+	})
+
+	t.Run("ref-updated", func(t *testing.T) {
+		event := parseGerrit(t, client, `{
+			"type": "ref-updated",
+			"submitter": {"username": "dev", "email": "dev@example.com"},
+			"refUpdate": {"oldRev": "bbb222", "newRev": "ccc333", "refName": "main", "project": "platform/api"}
+		}`)
+		assert.Equal(t, EventPush, event.GenericEvent)
+		require.NotNil(t, event.Push)
+		assert.Equal(t, "refs/heads/main", event.Push.Ref)
+		assert.Equal(t, "ccc333", event.Push.After)
+		assert.Equal(t, "dev", event.Push.Pusher)
+		// No change URL to take the server from, and none configured.
+		assert.Equal(t, "/platform/api", event.Repository.CloneURL)
+	})
+
+	t.Run("change refs aren't pushes", func(t *testing.T) {
+		event := parseGerrit(t, client, `{
+			"type": "ref-updated",
+			"refUpdate": {"oldRev": "bbb222", "newRev": "ccc333", "refName": "refs/changes/45/12345/meta", "project": "platform/api"}
+		}`)
+		assert.Nil(t, event.Push)
+		assert.Equal(t, EventUnknown, event.GenericEvent)
+	})
+
+	t.Run("invalid payloads", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"type": "patchset-created"}`))
+		_, err := client.ParseWebhook(req)
+		assert.ErrorIs(t, err, ErrInvalidPayload)
+		req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+		_, err = client.ParseWebhook(req)
+		assert.ErrorIs(t, err, ErrMissingEventHeader)
+	})
+}
+
+func TestGerritClient_ValidateWebhook(t *testing.T) {
+	client, err := NewGerritClient(Config{Provider: Gerrit})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	assert.NoError(t, client.ValidateWebhook(req, ""))
+	assert.ErrorIs(t, client.ValidateWebhook(req, "s3cret"), ErrMissingSignature)
+	req.Header.Set(GerritTokenHeader, "wrong")
+	assert.ErrorIs(t, client.ValidateWebhook(req, "s3cret"), ErrInvalidSignature)
+	req.Header.Set(GerritTokenHeader, "s3cret")
+	assert.NoError(t, client.ValidateWebhook(req, "s3cret"))
+}
+
+func TestGerritRepoURL(t *testing.T) {
+	repoURL, err := GerritRepoURL([]byte(gerritPatchsetCreated))
+	require.NoError(t, err)
+	assert.Equal(t, "https://review.example.com/platform/api", repoURL)
+
+	_, err = GerritRepoURL([]byte(`{"type": "ref-updated"}`))
+	assert.Error(t, err)
+}
+
+// fakeGerrit serves the change query and records the reviews posted.
+type fakeGerrit struct {
+	change  gerritChangeInfo
+	reviews []gerritReviewInput
+}
+
+func (f *fakeGerrit) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if user, _, ok := r.BasicAuth(); !ok || user != "reactorcide" || !strings.HasPrefix(r.URL.Path, "/a/") {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/a/changes/":
+		w.Write([]byte(gerritXSSIPrefix + "\n"))
+		json.NewEncoder(w).Encode([]gerritChangeInfo{f.change})
+	case r.Method == http.MethodPost && r.URL.EscapedPath() == "/a/changes/"+f.change.ID+"/revisions/aaa111/review":
+		var review gerritReviewInput
+		json.NewDecoder(r.Body).Decode(&review)
+		f.reviews = append(f.reviews, review)
+		w.Write([]byte(gerritXSSIPrefix + "\n{}"))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestGerritClient_UpdateCommitStatus(t *testing.T) {
+	fake := &fakeGerrit{change: gerritChangeInfo{
+		ID:              "platform%2Fapi~main~I8473b",
+		Number:          12345,
+		CurrentRevision: "aaa111",
+	}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client, err := NewGerritClient(Config{Provider: Gerrit, BaseURL: server.URL, Token: "reactorcide:http-password"})
+	require.NoError(t, err)
+	ctx := context.Background()
+	update := func(state StatusState) error {
+		return client.UpdateCommitStatus(ctx, "platform/api", StatusUpdate{
+			SHA:         "aaa111",
+			State:       state,
+			Context:     "reactorcide/test",
+			Description: "Job completed",
+			TargetURL:   "https://ci.example.com/jobs/1",
+		})
+	}
+
+	require.NoError(t, update(StatusRunning))
+	assert.Empty(t, fake.reviews, "running isn't reported")
+
+	require.NoError(t, update(StatusSuccess))
+	require.Len(t, fake.reviews, 1)
+	assert.Equal(t, map[string]int{"Verified": 1}, fake.reviews[0].Labels)
+	assert.Equal(t, gerritReviewTag, fake.reviews[0].Tag)
+	assert.Contains(t, fake.reviews[0].Message, "reactorcide/test succeeded: Job completed")
+	assert.Contains(t, fake.reviews[0].Message, "https://ci.example.com/jobs/1")
+
+	require.NoError(t, update(StatusFailure))
+	assert.Equal(t, map[string]int{"Verified": -1}, fake.reviews[1].Labels)
+
+	require.NoError(t, update(StatusCancelled))
+	assert.Nil(t, fake.reviews[2].Labels, "cancelled jobs don't vote")
+
+	t.Run("a failure on the current patchset sticks", func(t *testing.T) {
+		require.NoError(t, json.Unmarshal([]byte(`{"Verified": {"all": [{"username": "reactorcide", "value": -1}]}}`), &fake.change.Labels))
+		require.NoError(t, update(StatusSuccess))
+		last := fake.reviews[len(fake.reviews)-1]
+		assert.Empty(t, last.Labels)
+		assert.Contains(t, last.Message, "succeeded")
+	})
+
+	t.Run("unknown commit", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(gerritXSSIPrefix + "\n[]"))
+		}))
+		defer server.Close()
+		client, err := NewGerritClient(Config{Provider: Gerrit, BaseURL: server.URL})
+		require.NoError(t, err)
+		assert.Error(t, client.UpdateCommitStatus(ctx, "platform/api", StatusUpdate{SHA: "zzz", State: StatusSuccess}))
+	})
+}
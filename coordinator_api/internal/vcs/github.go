@@ -33,7 +33,7 @@ func NewGitHubClient(config Config) (*GitHubClient, error) {
 
 	return &GitHubClient{
 		config: config,
-		client: &http.Client{},
+		client: newSharedHTTPClient(),
 		logger: logger,
 	}, nil
 }
@@ -72,10 +72,12 @@ func (c *GitHubClient) ParseWebhook(r *http.Request) (*WebhookEvent, error) {
 	event := &WebhookEvent{
 		Provider:   GitHub,
 		EventType:  eventType,
+		DeliveryID: r.Header.Get("X-GitHub-Delivery"),
 		RawPayload: body,
 	}
 
 	// Parse based on event type
+	var mergeGroupAction string
 	switch eventType {
 	case "pull_request":
 		if err := c.parsePullRequestEvent(body, event); err != nil {
@@ -85,6 +87,10 @@ func (c *GitHubClient) ParseWebhook(r *http.Request) (*WebhookEvent, error) {
 		if err := c.parsePushEvent(body, event); err != nil {
 			return nil, fmt.Errorf("parsing push event: %w", err)
 		}
+	case "merge_group":
+		if mergeGroupAction, err = c.parseMergeGroupEvent(body, event); err != nil {
+			return nil, fmt.Errorf("parsing merge group event: %w", err)
+		}
 	case "ping":
 		// Ping event for webhook setup verification
 		c.logger.Info("Received GitHub ping event")
@@ -97,6 +103,9 @@ func (c *GitHubClient) ParseWebhook(r *http.Request) (*WebhookEvent, error) {
 	if event.PullRequest != nil {
 		action = event.PullRequest.Action
 	}
+	if event.MergeGroup != nil {
+		action = mergeGroupAction
+	}
 	event.GenericEvent = GenericEventFromGitHub(eventType, action, event.PullRequest, event.Push)
 
 	return event, nil
@@ -169,9 +178,9 @@ func (c *GitHubClient) UpdateCommitStatus(ctx context.Context, repo string, upda
 	}
 
 	c.logger.WithFields(logrus.Fields{
-		"repo":   repo,
-		"sha":    update.SHA,
-		"state":  githubState,
+		"repo":    repo,
+		"sha":     update.SHA,
+		"state":   githubState,
 		"context": update.Context,
 	}).Info("Updated GitHub commit status")
 
@@ -353,6 +362,113 @@ func (c *GitHubClient) GetPRInfo(ctx context.Context, repo string, prNumber int)
 	return c.convertPRInfo(pr), nil
 }
 
+// githubPRFilesLimit is the most files GitHub lists for a pull request.
+const githubPRFilesLimit = 3000
+
+// ListPRFiles lists the files a GitHub pull request changes, following
+// pagination. Renamed files are listed under both names. The list is
+// incomplete when the PR changes more files than GitHub lists.
+func (c *GitHubClient) ListPRFiles(ctx context.Context, repo string, prNumber int) ([]string, bool, error) {
+	next := fmt.Sprintf("%s/repos/%s/pulls/%d/files?per_page=100", c.config.BaseURL, repo, prNumber)
+	var files []string
+	listed := 0
+	for next != "" {
+		req, err := http.NewRequestWithContext(ctx, "GET", next, nil)
+		if err != nil {
+			return nil, false, fmt.Errorf("creating request: %w", err)
+		}
+		req.Header.Set("Authorization", "token "+c.config.Token)
+		req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return nil, false, fmt.Errorf("sending request: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, false, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
+		}
+
+		var page []struct {
+			Filename         string `json:"filename"`
+			PreviousFilename string `json:"previous_filename"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+			resp.Body.Close()
+			return nil, false, fmt.Errorf("decoding files: %w", err)
+		}
+		next = parseGitHubNextLink(resp.Header.Get("Link"))
+		resp.Body.Close()
+
+		listed += len(page)
+		for _, f := range page {
+			files = append(files, f.Filename)
+			if f.PreviousFilename != "" {
+				files = append(files, f.PreviousFilename)
+			}
+		}
+	}
+	return files, listed < githubPRFilesLimit, nil
+}
+
+// parseMergeGroupEvent parses a GitHub merge queue merge_group event and
+// returns its action.
+func (c *GitHubClient) parseMergeGroupEvent(body []byte, event *WebhookEvent) (string, error) {
+	var payload githubMergeGroupEvent
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return "", err
+	}
+
+	event.Repository = RepositoryInfo{
+		FullName:      payload.Repository.FullName,
+		CloneURL:      payload.Repository.CloneURL,
+		SSHURL:        payload.Repository.SSHURL,
+		HTMLURL:       payload.Repository.HTMLURL,
+		DefaultBranch: payload.Repository.DefaultBranch,
+	}
+	event.MergeGroup = &MergeGroupInfo{
+		HeadSHA: payload.MergeGroup.HeadSHA,
+		HeadRef: payload.MergeGroup.HeadRef,
+		BaseSHA: payload.MergeGroup.BaseSHA,
+		BaseRef: strings.TrimPrefix(payload.MergeGroup.BaseRef, "refs/heads/"),
+	}
+	return payload.Action, nil
+}
+
+// MergePullRequest merges a GitHub pull request. GitHub rejects the merge
+// if the PR head is no longer headSHA.
+func (c *GitHubClient) MergePullRequest(ctx context.Context, repo string, prNumber int, headSHA string) error {
+	url := fmt.Sprintf("%s/repos/%s/pulls/%d/merge", c.config.BaseURL, repo, prNumber)
+	payload, err := json.Marshal(map[string]string{
+		"sha":          headSHA,
+		"merge_method": "merge",
+	})
+	if err != nil {
+		return fmt.Errorf("marshaling request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "PUT", url, strings.NewReader(string(payload)))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Authorization", "token "+c.config.Token)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
 // parsePullRequestEvent parses a GitHub pull request event
 func (c *GitHubClient) parsePullRequestEvent(body []byte, event *WebhookEvent) error {
 	var payload githubPullRequestEvent
@@ -382,7 +498,9 @@ func (c *GitHubClient) parsePullRequestEvent(body []byte, event *WebhookEvent) e
 		HTMLURL:     payload.PullRequest.HTMLURL,
 		AuthorLogin: payload.PullRequest.User.Login,
 		AuthorEmail: "", // Not provided in webhook
+		Draft:       payload.PullRequest.Draft,
 	}
+	event.PullRequest.PreviousTitle = payload.Changes.Title.From
 
 	// Cross-repo (fork) PR: the head branch lives on a different repository
 	// than the base. Capture the head repository so downstream code can clone
@@ -466,12 +584,14 @@ func (c *GitHubClient) mapStatusState(state StatusState) string {
 
 // convertPRInfo converts GitHub PR to our format
 func (c *GitHubClient) convertPRInfo(pr githubPullRequest) *PullRequestInfo {
-	return &PullRequestInfo{
+	info := &PullRequestInfo{
 		Number:      pr.Number,
 		Title:       pr.Title,
 		Description: pr.Body,
 		State:       pr.State,
 		Merged:      pr.Merged,
+		Draft:       pr.Draft,
+		Mergeable:   pr.Mergeable,
 		HeadSHA:     pr.Head.SHA,
 		HeadRef:     pr.Head.Ref,
 		BaseSHA:     pr.Base.SHA,
@@ -479,26 +599,56 @@ func (c *GitHubClient) convertPRInfo(pr githubPullRequest) *PullRequestInfo {
 		HTMLURL:     pr.HTMLURL,
 		AuthorLogin: pr.User.Login,
 	}
+	if pr.Mergeable != nil && *pr.Mergeable {
+		info.MergeCommitSHA = pr.MergeCommitSHA
+	}
+	return info
 }
 
 // GitHub API structures
 type githubPullRequestEvent struct {
-	Action      string              `json:"action"`
-	Number      int                 `json:"number"`
-	PullRequest githubPullRequest   `json:"pull_request"`
-	Repository  githubRepository    `json:"repository"`
+	Action      string            `json:"action"`
+	Number      int               `json:"number"`
+	PullRequest githubPullRequest `json:"pull_request"`
+	Repository  githubRepository  `json:"repository"`
+	Changes     githubPRChanges   `json:"changes"`
+}
+
+type githubMergeGroupEvent struct {
+	Action     string           `json:"action"`
+	MergeGroup githubMergeGroup `json:"merge_group"`
+	Repository githubRepository `json:"repository"`
+}
+
+type githubMergeGroup struct {
+	HeadSHA string `json:"head_sha"`
+	HeadRef string `json:"head_ref"`
+	BaseSHA string `json:"base_sha"`
+	BaseRef string `json:"base_ref"`
+}
+
+// githubPRChanges holds the previous values of fields an "edited" action
+// changed.
+type githubPRChanges struct {
+	Title struct {
+		From string `json:"from"`
+	} `json:"title"`
 }
 
 type githubPullRequest struct {
-	Number  int              `json:"number"`
-	Title   string           `json:"title"`
-	Body    string           `json:"body"`
-	State   string           `json:"state"`
-	Merged  bool             `json:"merged"`
-	HTMLURL string           `json:"html_url"`
-	Head    githubRef        `json:"head"`
-	Base    githubRef        `json:"base"`
-	User    githubUser       `json:"user"`
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	State   string `json:"state"`
+	Merged  bool   `json:"merged"`
+	Draft   bool   `json:"draft"`
+	HTMLURL string `json:"html_url"`
+	// Mergeable and MergeCommitSHA are only present in the pulls API.
+	Mergeable      *bool      `json:"mergeable"`
+	MergeCommitSHA string     `json:"merge_commit_sha"`
+	Head           githubRef  `json:"head"`
+	Base           githubRef  `json:"base"`
+	User           githubUser `json:"user"`
 }
 
 type githubRef struct {
@@ -547,4 +697,4 @@ type githubCommit struct {
 type githubAuthor struct {
 	Name  string `json:"name"`
 	Email string `json:"email"`
-}
\ No newline at end of file
+}
@@ -0,0 +1,178 @@
+package vcs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// githubChangelogCommitLimit is the most commits CommitsBetween lists for a
+// first release, which has no base to compare with.
+const githubChangelogCommitLimit = 100
+
+// githubAPICommit is a commit as the commits and compare APIs list it
+// (githubCommit is a push webhook's).
+type githubAPICommit struct {
+	SHA     string `json:"sha"`
+	HTMLURL string `json:"html_url"`
+	Commit  struct {
+		Message string `json:"message"`
+		Author  struct {
+			Name  string `json:"name"`
+			Email string `json:"email"`
+			Date  string `json:"date"`
+		} `json:"author"`
+	} `json:"commit"`
+}
+
+func (c githubAPICommit) toCommit() Commit {
+	return Commit{
+		ID:          c.SHA,
+		Message:     c.Commit.Message,
+		Author:      c.Commit.Author.Name,
+		AuthorEmail: c.Commit.Author.Email,
+		Timestamp:   c.Commit.Author.Date,
+		URL:         c.HTMLURL,
+	}
+}
+
+// CommitsBetween lists the commits between two refs with the compare API,
+// which lists at most 250. Without a base, it lists head's most recent
+// githubChangelogCommitLimit commits, newest first from the API.
+func (c *GitHubClient) CommitsBetween(ctx context.Context, repo, base, head string) ([]Commit, error) {
+	var endpoint string
+	if base == "" {
+		endpoint = fmt.Sprintf("%s/repos/%s/commits?sha=%s&per_page=%d", c.config.BaseURL, repo, url.QueryEscape(head), githubChangelogCommitLimit)
+	} else {
+		endpoint = fmt.Sprintf("%s/repos/%s/compare/%s...%s", c.config.BaseURL, repo, url.PathEscape(base), url.PathEscape(head))
+	}
+	body, err := c.doJSON(ctx, "GET", endpoint, nil, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var commits []githubAPICommit
+	if base == "" {
+		if err := json.NewDecoder(body).Decode(&commits); err != nil {
+			return nil, fmt.Errorf("decoding commits: %w", err)
+		}
+		// The commits API lists newest first.
+		for i, j := 0, len(commits)-1; i < j; i, j = i+1, j-1 {
+			commits[i], commits[j] = commits[j], commits[i]
+		}
+	} else {
+		var comparison struct {
+			Commits []githubAPICommit `json:"commits"`
+		}
+		if err := json.NewDecoder(body).Decode(&comparison); err != nil {
+			return nil, fmt.Errorf("decoding comparison: %w", err)
+		}
+		commits = comparison.Commits
+	}
+	result := make([]Commit, len(commits))
+	for i, commit := range commits {
+		result[i] = commit.toCommit()
+	}
+	return result, nil
+}
+
+// CreateRelease creates a GitHub release for an existing tag.
+func (c *GitHubClient) CreateRelease(ctx context.Context, repo string, release ReleaseRequest) (*ReleaseInfo, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"tag_name":   release.Tag,
+		"name":       release.Name,
+		"body":       release.Body,
+		"draft":      release.Draft,
+		"prerelease": release.Prerelease,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling request: %w", err)
+	}
+	body, err := c.doJSON(ctx, "POST", fmt.Sprintf("%s/repos/%s/releases", c.config.BaseURL, repo), payload, http.StatusCreated)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var created struct {
+		ID        int64  `json:"id"`
+		HTMLURL   string `json:"html_url"`
+		UploadURL string `json:"upload_url"`
+	}
+	if err := json.NewDecoder(body).Decode(&created); err != nil {
+		return nil, fmt.Errorf("decoding release: %w", err)
+	}
+	uploadURL := created.UploadURL
+	// upload_url is a URI template, e.g. ".../assets{?name,label}".
+	if i := strings.Index(uploadURL, "{"); i >= 0 {
+		uploadURL = uploadURL[:i]
+	}
+	return &ReleaseInfo{ID: strconv.FormatInt(created.ID, 10), HTMLURL: created.HTMLURL, UploadURL: uploadURL}, nil
+}
+
+// UploadReleaseAsset uploads an asset to the release's upload URL.
+func (c *GitHubClient) UploadReleaseAsset(ctx context.Context, release *ReleaseInfo, name string, content io.Reader, size int64) (string, error) {
+	if release.UploadURL == "" {
+		return "", fmt.Errorf("release %s has no upload URL", release.ID)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", release.UploadURL+"?name="+url.QueryEscape(name), content)
+	if err != nil {
+		return "", fmt.Errorf("creating request: %w", err)
+	}
+	req.ContentLength = size
+	req.Header.Set("Authorization", "token "+c.config.Token)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("sending request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
+	}
+	var asset struct {
+		BrowserDownloadURL string `json:"browser_download_url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&asset); err != nil {
+		return "", fmt.Errorf("decoding asset: %w", err)
+	}
+	return asset.BrowserDownloadURL, nil
+}
+
+// doJSON sends a GitHub API request with an optional JSON payload and
+// returns the response body when the status is want.
+func (c *GitHubClient) doJSON(ctx context.Context, method, endpoint string, payload []byte, want int) (io.ReadCloser, error) {
+	var reqBody io.Reader
+	if payload != nil {
+		reqBody = strings.NewReader(string(payload))
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Authorization", "token "+c.config.Token)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("sending request: %w", err)
+	}
+	if resp.StatusCode != want {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
+	}
+	return resp.Body, nil
+}
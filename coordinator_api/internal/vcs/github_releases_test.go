@@ -0,0 +1,71 @@
+package vcs
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHubClient_Releases(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token test-token", r.Header.Get("Authorization"))
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/repos/test/repo/compare/v1.0.0...v1.1.0":
+			w.Write([]byte(`{"commits":[{"sha":"abc","html_url":"https://x/abc","commit":{"message":"Add a","author":{"name":"Sam"}}}]}`))
+		case r.Method == "GET" && r.URL.Path == "/repos/test/repo/commits":
+			assert.Equal(t, "v1.0.0", r.URL.Query().Get("sha"))
+			w.Write([]byte(`[{"sha":"new","commit":{"message":"Second"}},{"sha":"old","commit":{"message":"First"}}]`))
+		case r.Method == "POST" && r.URL.Path == "/repos/test/repo/releases":
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "v1.1.0", body["tag_name"])
+			assert.Equal(t, true, body["prerelease"])
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":42,"html_url":"https://github.com/test/repo/releases/tag/v1.1.0","upload_url":"` + server.URL + `/uploads/42/assets{?name,label}"}`))
+		case r.Method == "POST" && r.URL.Path == "/uploads/42/assets":
+			assert.Equal(t, "app.tar.gz", r.URL.Query().Get("name"))
+			assert.Equal(t, int64(4), r.ContentLength)
+			data, _ := io.ReadAll(r.Body)
+			assert.Equal(t, "data", string(data))
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"browser_download_url":"https://github.com/test/repo/releases/download/v1.1.0/app.tar.gz"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewGitHubClient(Config{Provider: GitHub, Token: "test-token", BaseURL: server.URL})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	commits, err := client.CommitsBetween(ctx, "test/repo", "v1.0.0", "v1.1.0")
+	require.NoError(t, err)
+	require.Len(t, commits, 1)
+	assert.Equal(t, Commit{ID: "abc", Message: "Add a", Author: "Sam", URL: "https://x/abc"}, commits[0])
+
+	commits, err = client.CommitsBetween(ctx, "test/repo", "", "v1.0.0")
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	assert.Equal(t, "old", commits[0].ID, "oldest first")
+
+	release, err := client.CreateRelease(ctx, "test/repo", ReleaseRequest{Tag: "v1.1.0", Name: "v1.1.0", Prerelease: true})
+	require.NoError(t, err)
+	assert.Equal(t, "42", release.ID)
+	assert.Equal(t, server.URL+"/uploads/42/assets", release.UploadURL)
+
+	url, err := client.UploadReleaseAsset(ctx, release, "app.tar.gz", strings.NewReader("data"), 4)
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/test/repo/releases/download/v1.1.0/app.tar.gz", url)
+
+	var _ ReleasePublisher = client
+}
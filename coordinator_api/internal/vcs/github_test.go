@@ -3,6 +3,7 @@ package vcs
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -168,6 +169,36 @@ func TestGitHubClient_ParseWebhook(t *testing.T) {
 				assert.Equal(t, "synchronize", event.PullRequest.Action)
 			},
 		},
+		{
+			name:      "pull_request_retitled_draft",
+			eventType: "pull_request",
+			payload: `{
+				"action": "edited",
+				"number": 123,
+				"changes": {
+					"title": {"from": "WIP: Test PR"}
+				},
+				"pull_request": {
+					"number": 123,
+					"title": "Test PR",
+					"state": "open",
+					"draft": true,
+					"head": {"ref": "feature-branch", "sha": "abc123"},
+					"base": {"ref": "main", "sha": "def456"},
+					"user": {"login": "testuser"}
+				},
+				"repository": {
+					"full_name": "test/repo",
+					"clone_url": "https://github.com/test/repo.git"
+				}
+			}`,
+			wantErr: false,
+			checkResult: func(t *testing.T, event *WebhookEvent) {
+				assert.Equal(t, EventPullRequestRetitled, event.GenericEvent)
+				assert.True(t, event.PullRequest.Draft)
+				assert.Equal(t, "WIP: Test PR", event.PullRequest.PreviousTitle)
+			},
+		},
 		{
 			name:      "pull_request_merged",
 			eventType: "pull_request",
@@ -326,6 +357,37 @@ func TestGitHubClient_ParseWebhook(t *testing.T) {
 				assert.Equal(t, "refs/tags/v1.0.0", event.Push.Ref)
 			},
 		},
+		{
+			name:      "merge_group_checks_requested",
+			eventType: "merge_group",
+			payload: `{
+				"action": "checks_requested",
+				"merge_group": {
+					"head_sha": "mg123",
+					"head_ref": "refs/heads/gh-readonly-queue/main/pr-42-def456",
+					"base_sha": "def456",
+					"base_ref": "refs/heads/main"
+				},
+				"repository": {
+					"full_name": "test/repo",
+					"clone_url": "https://github.com/test/repo.git",
+					"ssh_url": "git@github.com:test/repo.git",
+					"html_url": "https://github.com/test/repo",
+					"default_branch": "main"
+				}
+			}`,
+			wantErr: false,
+			checkResult: func(t *testing.T, event *WebhookEvent) {
+				assert.Equal(t, EventMergeGroup, event.GenericEvent)
+				require.NotNil(t, event.MergeGroup)
+				assert.Equal(t, "mg123", event.MergeGroup.HeadSHA)
+				assert.Equal(t, "refs/heads/gh-readonly-queue/main/pr-42-def456", event.MergeGroup.HeadRef)
+				assert.Equal(t, "def456", event.MergeGroup.BaseSHA)
+				assert.Equal(t, "main", event.MergeGroup.BaseRef)
+				assert.Nil(t, event.PullRequest)
+				assert.Nil(t, event.Push)
+			},
+		},
 		{
 			name:      "ping_event",
 			eventType: "ping",
@@ -491,6 +553,35 @@ func TestGitHubClient_UpdateCommitStatus(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestGitHubClient_MergePullRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PUT", r.Method)
+		assert.Equal(t, "/repos/test/repo/pulls/42/merge", r.URL.Path)
+
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if body["sha"] != "abc123" {
+			// GitHub refuses when the head moved since it was tested.
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"message": "Head branch was modified"}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"merged": true}`))
+	}))
+	defer server.Close()
+
+	client, err := NewGitHubClient(Config{
+		Provider: GitHub,
+		Token:    "test-token",
+		BaseURL:  server.URL,
+	})
+	require.NoError(t, err)
+
+	assert.NoError(t, client.MergePullRequest(context.Background(), "test/repo", 42, "abc123"))
+	assert.Error(t, client.MergePullRequest(context.Background(), "test/repo", 42, "stale"))
+}
+
 func TestGitHubClient_MapStatusState(t *testing.T) {
 	client, err := NewGitHubClient(Config{Provider: GitHub})
 	require.NoError(t, err)
@@ -30,7 +30,7 @@ func NewGitLabClient(config Config) (*GitLabClient, error) {
 
 	return &GitLabClient{
 		config: config,
-		client: &http.Client{},
+		client: newSharedHTTPClient(),
 		logger: logger,
 	}, nil
 }
@@ -55,6 +55,7 @@ func (c *GitLabClient) ParseWebhook(r *http.Request) (*WebhookEvent, error) {
 	event := &WebhookEvent{
 		Provider:   GitLab,
 		EventType:  eventType,
+		DeliveryID: r.Header.Get("X-Gitlab-Event-UUID"),
 		RawPayload: body,
 	}
 
@@ -345,6 +346,7 @@ func (c *GitLabClient) parseMergeRequestEvent(body []byte, event *WebhookEvent)
 		HTMLURL:     payload.ObjectAttributes.URL,
 		AuthorLogin: payload.User.Username,
 		AuthorEmail: payload.User.Email,
+		Draft:       payload.ObjectAttributes.Draft || payload.ObjectAttributes.WorkInProgress,
 	}
 
 	return nil
@@ -480,6 +482,9 @@ type gitlabMergeRequestAttrs struct {
 	URL          string              `json:"url"`
 	Action       string              `json:"action"`
 	OldRev       string              `json:"oldrev"`
+	Draft        bool                `json:"draft"`
+	// WorkInProgress is the pre-15.0 name for Draft.
+	WorkInProgress bool `json:"work_in_progress"`
 }
 
 type gitlabMergeRequest struct {
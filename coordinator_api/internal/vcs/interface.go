@@ -2,6 +2,7 @@ package vcs
 
 import (
 	"context"
+	"io"
 	"net/http"
 )
 
@@ -11,16 +12,19 @@ type Provider string
 const (
 	GitHub Provider = "github"
 	GitLab Provider = "gitlab"
+	Gerrit Provider = "gerrit"
 )
 
 // WebhookEvent represents a parsed webhook event from a VCS provider
 type WebhookEvent struct {
 	Provider     Provider
 	EventType    string // raw event type from the VCS provider (e.g., "pull_request", "push")
+	DeliveryID   string // provider's unique id for this delivery, stable across redeliveries; may be empty
 	GenericEvent EventType // VCS-agnostic event type (e.g., EventPullRequestOpened)
 	Repository   RepositoryInfo
 	PullRequest  *PullRequestInfo
 	Push         *PushInfo
+	MergeGroup   *MergeGroupInfo
 	RawPayload   []byte
 }
 
@@ -48,6 +52,15 @@ type PullRequestInfo struct {
 	HTMLURL     string
 	AuthorLogin string
 	AuthorEmail string
+	// Draft is true for draft PRs (GitLab: draft/WIP merge requests).
+	Draft bool
+	// MergeCommitSHA is GitHub's test merge of the head into the base.
+	// Only set by GetPRInfo, and empty while Mergeable is unknown or false.
+	MergeCommitSHA string
+	// Mergeable is nil while GitHub is still computing it.
+	Mergeable *bool
+	// PreviousTitle is the title before an "edited" action changed it.
+	PreviousTitle string
 
 	// HeadRepository is set only for cross-repository PRs (forks).
 	// When nil, the PR's head branch lives on the same repository as Repository
@@ -71,6 +84,18 @@ type PushInfo struct {
 	PusherEmail string
 }
 
+// MergeGroupInfo describes a speculative merge commit to test before it
+// lands: a GitHub merge queue group, or an entry in Reactorcide's native
+// merge queue.
+type MergeGroupInfo struct {
+	HeadSHA string // the speculative merge commit
+	HeadRef string // e.g., "refs/heads/gh-readonly-queue/main/pr-12-abc"
+	BaseSHA string // base branch commit the merge was made on
+	BaseRef string // target branch name
+	// PRNumber is set for native merge queue entries, which test one PR.
+	PRNumber int
+}
+
 // Commit represents a commit in a push event
 type Commit struct {
 	ID        string
@@ -134,6 +159,54 @@ type StatusUpdater interface {
 	GetPRInfo(ctx context.Context, repo string, prNumber int) (*PullRequestInfo, error)
 }
 
+// PullRequestMerger merges pull requests. It's optional: only clients
+// that implement it can back the native merge queue.
+type PullRequestMerger interface {
+	// MergePullRequest merges the PR, failing if its head is no longer
+	// headSHA.
+	MergePullRequest(ctx context.Context, repo string, prNumber int, headSHA string) error
+}
+
+// PullRequestFilesLister lists the files a pull request changes. It's
+// optional: without it, path filters are applied by the eval job.
+type PullRequestFilesLister interface {
+	// ListPRFiles returns the paths the PR changes, and whether the list
+	// is complete.
+	ListPRFiles(ctx context.Context, repo string, prNumber int) ([]string, bool, error)
+}
+
+// ReleaseRequest describes a release to create for an existing tag.
+type ReleaseRequest struct {
+	Tag        string
+	Name       string
+	Body       string
+	Draft      bool
+	Prerelease bool
+}
+
+// ReleaseInfo is a release created in the VCS.
+type ReleaseInfo struct {
+	ID        string
+	HTMLURL   string
+	UploadURL string // where assets are uploaded, without URL template parts
+}
+
+// ReleasePublisher publishes releases for tags. It's optional: only
+// clients that implement it can back project releases.
+type ReleasePublisher interface {
+	// CommitsBetween returns the commits reachable from head but not from
+	// base, oldest first. With base "", it returns head's most recent
+	// commits.
+	CommitsBetween(ctx context.Context, repo, base, head string) ([]Commit, error)
+
+	// CreateRelease creates a release for an existing tag.
+	CreateRelease(ctx context.Context, repo string, release ReleaseRequest) (*ReleaseInfo, error)
+
+	// UploadReleaseAsset attaches content as the asset name and returns its
+	// download URL.
+	UploadReleaseAsset(ctx context.Context, release *ReleaseInfo, name string, content io.Reader, size int64) (string, error)
+}
+
 // Client combines webhook handling and status updating
 type Client interface {
 	WebhookHandler
@@ -152,12 +225,9 @@ type Config struct {
 
 // NewClient creates a new VCS client based on the provider
 func NewClient(config Config) (Client, error) {
-	switch config.Provider {
-	case GitHub:
-		return NewGitHubClient(config)
-	case GitLab:
-		return NewGitLabClient(config)
-	default:
+	spec, ok := Providers.Lookup(config.Provider)
+	if !ok {
 		return nil, ErrUnsupportedProvider
 	}
+	return spec.NewClient(config)
 }
\ No newline at end of file
@@ -26,6 +26,9 @@ func NewManager() *Manager {
 	statusUpdater.SetBaseURL(config.VCSBaseURL)
 	if store.AppStore != nil {
 		statusUpdater.SetStore(store.AppStore)
+		if outbox, ok := store.AppStore.(StatusOutbox); ok && config.VCSStatusOutbox {
+			statusUpdater.SetOutbox(outbox, config.VCSOutboxMaxAttempts)
+		}
 	}
 
 	m := &Manager{
@@ -44,38 +47,28 @@ func NewManager() *Manager {
 	return m
 }
 
-// initializeClients initializes VCS clients based on configuration.
-// Clients are always created when VCS is enabled. Webhook secret validation
-// is handled per-project by the webhook handler, not by the client.
+// initializeClients initializes a client for each enabled provider (see
+// EnabledProviders). Clients are always created when VCS is enabled, even
+// without a global token, since status updates use per-project tokens.
+// Webhook secret validation is handled per-project by the webhook handler,
+// not by the client.
 func (m *Manager) initializeClients() {
-	// Initialize GitHub client (token may be empty; status updates use per-project tokens)
-	githubConfig := Config{
-		Provider: GitHub,
-		Token:    config.VCSGitHubToken,
-	}
-
-	client, err := NewGitHubClient(githubConfig)
-	if err != nil {
-		m.logger.WithError(err).Error("Failed to create GitHub client")
-	} else {
-		m.clients[GitHub] = client
-		m.statusUpdater.AddVCSClient(GitHub, client)
-		m.logger.Info("GitHub VCS client initialized")
-	}
-
-	// Initialize GitLab client (token may be empty; status updates use per-project tokens)
-	gitlabConfig := Config{
-		Provider: GitLab,
-		Token:    config.VCSGitLabToken,
-	}
-
-	gitlabClient, err := NewGitLabClient(gitlabConfig)
-	if err != nil {
-		m.logger.WithError(err).Error("Failed to create GitLab client")
-	} else {
-		m.clients[GitLab] = gitlabClient
-		m.statusUpdater.AddVCSClient(GitLab, gitlabClient)
-		m.logger.Info("GitLab VCS client initialized")
+	for _, spec := range EnabledProviders() {
+		var token string
+		if spec.Token != nil {
+			token = spec.Token()
+		}
+		client, err := spec.NewClient(Config{
+			Provider: spec.Provider,
+			Token:    token,
+		})
+		if err != nil {
+			m.logger.WithError(err).WithField("provider", spec.Provider).Error("Failed to create VCS client")
+			continue
+		}
+		m.clients[spec.Provider] = client
+		m.statusUpdater.AddVCSClient(spec.Provider, client)
+		m.logger.WithField("provider", spec.Provider).Info("VCS client initialized")
 	}
 
 	// Configure base URL for status updater
@@ -114,20 +107,14 @@ func (m *Manager) IsEnabled() bool {
 // (e.g., https://api.github.com for GitHub). For GitHub Enterprise or
 // self-hosted GitLab, per-project API URL configuration would be needed.
 func (m *Manager) CreateClientWithToken(provider Provider, token string) (Client, error) {
-	switch provider {
-	case GitHub:
-		return NewGitHubClient(Config{
-			Provider: GitHub,
-			Token:    token,
-		})
-	case GitLab:
-		return NewGitLabClient(Config{
-			Provider: GitLab,
-			Token:    token,
-		})
-	default:
+	spec, ok := Providers.Lookup(provider)
+	if !ok {
 		return nil, fmt.Errorf("unsupported provider: %s", provider)
 	}
+	return spec.NewClient(Config{
+		Provider: provider,
+		Token:    token,
+	})
 }
 
 // GetClients returns all configured VCS clients
@@ -3,6 +3,7 @@ package vcs
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -50,13 +51,13 @@ func jobCommentKey(job *models.Job) string {
 // job status change. Pre-merge: one rolling comment per (PR, commit) shared
 // by all jobs. Post-merge: one comment per job, updated through its lifecycle.
 //
-// Called from UpdateJobStatus whenever a job has PR metadata and is not an
-// eval job. Errors are logged but not propagated — a failed comment update
-// should never block a job's status transition.
-func (u *JobStatusUpdater) updatePRCommentForJob(ctx context.Context, client Client, job *models.Job, metadata *JobMetadata) {
+// Called for every status change of a job with PR metadata that is not an
+// eval job. A failed comment update should never block a job's status
+// transition: callers log the error, or retry it from the outbox.
+func (u *JobStatusUpdater) updatePRCommentForJob(ctx context.Context, client Client, job *models.Job, metadata *JobMetadata) error {
 	if u.store == nil {
 		u.logger.Debug("No store configured on JobStatusUpdater; skipping PR comment")
-		return
+		return nil
 	}
 
 	merged, err := u.store.IsPRMerged(ctx, metadata.Repo, metadata.PRNumber)
@@ -65,16 +66,15 @@ func (u *JobStatusUpdater) updatePRCommentForJob(ctx context.Context, client Cli
 	}
 
 	if merged {
-		u.postPerJobComment(ctx, client, job, metadata)
-		return
+		return u.postPerJobComment(ctx, client, job, metadata)
 	}
-	u.postRollingComment(ctx, client, job, metadata)
+	return u.postRollingComment(ctx, client, job, metadata)
 }
 
 // postRollingComment regenerates the rolling comment for (repo, PR, commit).
 // Wrapped in ForPRCommit so concurrent updates for the same commit serialize
 // cleanly via a Postgres advisory lock.
-func (u *JobStatusUpdater) postRollingComment(ctx context.Context, client Client, job *models.Job, metadata *JobMetadata) {
+func (u *JobStatusUpdater) postRollingComment(ctx context.Context, client Client, job *models.Job, metadata *JobMetadata) error {
 	err := u.store.ForPRCommit(ctx, metadata.Repo, metadata.PRNumber, metadata.CommitSHA, func(ctx context.Context) error {
 		jobs, err := u.store.ListJobsForPRCommit(ctx, metadata.Repo, metadata.PRNumber, metadata.CommitSHA)
 		if err != nil {
@@ -87,29 +87,23 @@ func (u *JobStatusUpdater) postRollingComment(ctx context.Context, client Client
 		return client.UpsertPRCommentByMarker(ctx, metadata.Repo, metadata.PRNumber, marker, body)
 	})
 	if err != nil {
-		u.logger.WithError(err).WithFields(map[string]interface{}{
-			"repo":      metadata.Repo,
-			"pr_number": metadata.PRNumber,
-			"sha":       metadata.CommitSHA,
-		}).Warn("Failed to update rolling PR comment")
+		return fmt.Errorf("updating rolling PR comment: %w", err)
 	}
+	return nil
 }
 
 // postPerJobComment updates the per-job comment for a merged PR. Keyed by
 // (commit, job name) rather than job.JobID (see prCommentMarkerPerJob) so a
 // retried job's completion updates the existing comment in place instead of
 // posting a new one alongside it — last run wins.
-func (u *JobStatusUpdater) postPerJobComment(ctx context.Context, client Client, job *models.Job, metadata *JobMetadata) {
+func (u *JobStatusUpdater) postPerJobComment(ctx context.Context, client Client, job *models.Job, metadata *JobMetadata) error {
 	marker := prCommentMarkerPerJob(metadata.CommitSHA, jobCommentKey(job))
 	body := u.renderPerJobCommentBody(job, marker)
 
 	if err := client.UpsertPRCommentByMarker(ctx, metadata.Repo, metadata.PRNumber, marker, body); err != nil {
-		u.logger.WithError(err).WithFields(map[string]interface{}{
-			"repo":      metadata.Repo,
-			"pr_number": metadata.PRNumber,
-			"job_id":    job.JobID,
-		}).Warn("Failed to update per-job PR comment")
+		return fmt.Errorf("updating per-job PR comment: %w", err)
 	}
+	return nil
 }
 
 // renderRollingCommentBody produces the markdown table summarizing every
@@ -151,6 +145,8 @@ func (u *JobStatusUpdater) renderRollingCommentBody(jobs []models.Job, commitSHA
 		}
 	}
 
+	renderGateTable(&b, deduped)
+
 	fmt.Fprintf(&b, "\n<sub>Updated %s · %s</sub>\n", time.Now().UTC().Format(time.RFC3339), marker)
 	return b.String()
 }
@@ -209,6 +205,12 @@ func (u *JobStatusUpdater) renderPerJobCommentBody(job *models.Job, marker strin
 	if job.LastError != "" && job.Status == "failed" {
 		fmt.Fprintf(&b, "\n\n### Error\n```\n%s\n```", job.LastError)
 	}
+	if len(job.GateResults) > 0 {
+		b.WriteString("\n\n### Quality gates\n")
+		for _, result := range job.GateResults {
+			fmt.Fprintf(&b, "\n- %s %s", gateEmoji(result), result.String())
+		}
+	}
 
 	fmt.Fprintf(&b, "\n\n<sub>%s</sub>\n", marker)
 	return b.String()
@@ -227,6 +229,10 @@ func renderStatus(job *models.Job) (emoji string, text string) {
 		if job.ExitCode != nil && *job.ExitCode == 0 {
 			return "✅", "succeeded"
 		}
+		if job.ExitCode != nil {
+			// Only quarantined tests failed; see the job's gate results.
+			return "✅", fmt.Sprintf("succeeded (exit code %d)", *job.ExitCode)
+		}
 		return "❌", "failed"
 	case "failed":
 		return "❌", "failed"
@@ -239,6 +245,45 @@ func renderStatus(job *models.Job) (emoji string, text string) {
 	}
 }
 
+// renderGateTable adds a table of the jobs' quality gate results, if any
+// job has them.
+func renderGateTable(b *strings.Builder, jobs []models.Job) {
+	header := false
+	for i := range jobs {
+		job := &jobs[i]
+		for _, result := range job.GateResults {
+			if !header {
+				b.WriteString("\n### Quality gates\n\n")
+				b.WriteString("| Job | Gate | Value | Threshold | Result |\n")
+				b.WriteString("|-----|------|-------|-----------|--------|\n")
+				header = true
+			}
+			name := job.Name
+			if name == "" {
+				name = job.JobID
+			}
+			value := "—"
+			if result.Value != nil {
+				value = strconv.FormatFloat(*result.Value, 'f', -1, 64)
+			}
+			threshold := fmt.Sprintf("%s %s", result.Op, strconv.FormatFloat(result.Threshold, 'f', -1, 64))
+			verdict := gateEmoji(result)
+			if result.Message != "" {
+				verdict += " " + result.Message
+			}
+			fmt.Fprintf(b, "| %s | %s | %s | %s | %s |\n", escapeTableCell(name), escapeTableCell(result.Name), value, threshold, escapeTableCell(verdict))
+		}
+	}
+}
+
+// gateEmoji marks a quality gate result passed or failed.
+func gateEmoji(result models.GateResult) string {
+	if result.Passed {
+		return "✅"
+	}
+	return "❌"
+}
+
 // renderDuration returns a 3-decimal-place seconds string for a completed
 // job, or an em-dash placeholder for jobs that haven't completed.
 func renderDuration(job *models.Job) string {
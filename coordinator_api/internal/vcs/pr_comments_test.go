@@ -115,6 +115,41 @@ func TestLegacyPRCommentsIncludeDeprecationNotice(t *testing.T) {
 	}
 }
 
+func TestPRCommentsShowQualityGates(t *testing.T) {
+	updater := NewJobStatusUpdater()
+	exitCode := 0
+	coverage := 72.5
+	job := models.Job{
+		JobID:     "job-1",
+		Name:      "test",
+		Status:    "failed",
+		ExitCode:  &exitCode,
+		LastError: "quality gates failed: coverage: 72.5 >= 80 failed",
+		CreatedAt: time.Unix(0, 0),
+		GateResults: models.GateResults{
+			{Name: "coverage", Output: "coverage", Op: ">=", Threshold: 80, Value: &coverage},
+			{Name: "vulns", Output: "critical_vulns", Op: "==", Message: `output "critical_vulns" not reported`},
+		},
+	}
+
+	rolling := updater.renderRollingCommentBody([]models.Job{job}, "abcdef123456", "<!-- marker -->")
+	for _, want := range []string{"### Quality gates", "| test | coverage | 72.5 | >= 80 | ❌ |", `| test | vulns | — | == 0 | ❌ output "critical_vulns" not reported |`} {
+		if !strings.Contains(rolling, want) {
+			t.Errorf("rolling comment missing %q, got:\n%s", want, rolling)
+		}
+	}
+
+	perJob := updater.renderPerJobCommentBody(&job, "<!-- marker -->")
+	if !strings.Contains(perJob, "- ❌ coverage: 72.5 >= 80 failed") {
+		t.Errorf("per-job comment missing the coverage gate, got:\n%s", perJob)
+	}
+
+	job.GateResults = nil
+	if rolling := updater.renderRollingCommentBody([]models.Job{job}, "abcdef123456", "<!-- marker -->"); strings.Contains(rolling, "Quality gates") {
+		t.Errorf("rolling comment without gate results shows a gates section:\n%s", rolling)
+	}
+}
+
 // TestPostPerJobComment_RetryUpdatesSameCommentInPlace verifies the
 // post-merge per-job comment marker is stable across a retry: a retried job
 // (jobcontrol.RetryJob clones a brand-new JobID but carries the same Name
@@ -0,0 +1,28 @@
+package vcs
+
+// pushCommitListLimit is the most commits a push webhook is sure to list:
+// GitLab lists at most 20, so a push listing that many may have more.
+const pushCommitListLimit = 20
+
+// ChangedFiles returns the files added, modified or removed by the push's
+// commits, and whether the list is complete. It isn't for new branches,
+// force pushes, pushes listing no commits (such as tags), or pushes that
+// may have more commits than the webhook lists.
+func (p *PushInfo) ChangedFiles() ([]string, bool) {
+	if p.Created || p.Forced || len(p.Commits) == 0 || len(p.Commits) >= pushCommitListLimit {
+		return nil, false
+	}
+	seen := map[string]bool{}
+	var files []string
+	for _, c := range p.Commits {
+		for _, list := range [][]string{c.Added, c.Modified, c.Removed} {
+			for _, f := range list {
+				if !seen[f] {
+					seen[f] = true
+					files = append(files, f)
+				}
+			}
+		}
+	}
+	return files, true
+}
@@ -0,0 +1,389 @@
+package vcs
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/metrics"
+)
+
+const (
+	// retryBaseDelay is the first backoff between retries of a request
+	// that failed without saying when to retry; it doubles each attempt.
+	retryBaseDelay = time.Second
+
+	// responseCacheMaxEntries bounds the GET response cache.
+	responseCacheMaxEntries = 1024
+)
+
+// rateLimitTransport is the http.RoundTripper all VCS clients share. Clients
+// are created per credential, often per request, so quota and cached
+// responses live here, keyed by host and credential, rather than on a
+// client.
+//
+// It tracks the quota providers report in their rate limit headers
+// (X-RateLimit-* on GitHub, RateLimit-* on GitLab) and holds requests back
+// while it's exhausted. Rate limited responses are retried after the
+// provider's Retry-After or reset time, and GETs are also retried after
+// network and server errors. GET responses are reused for the cache TTL,
+// then revalidated with If-None-Match, which GitHub doesn't count against
+// the quota; a successful write to a repository drops its cached responses.
+type rateLimitTransport struct {
+	base       http.RoundTripper
+	maxRetries int
+	maxWait    time.Duration
+	cacheTTL   time.Duration
+	now        func() time.Time
+	sleep      func(ctx context.Context, d time.Duration) error
+
+	mu     sync.Mutex
+	quotas map[string]rateLimitQuota
+	cache  map[string]*cachedResponse
+}
+
+// rateLimitQuota is the last quota a credential's responses reported.
+type rateLimitQuota struct {
+	remaining int
+	reset     time.Time
+}
+
+// cachedResponse is a GET response kept for reuse and revalidation.
+type cachedResponse struct {
+	status   int
+	header   http.Header
+	body     []byte
+	etag     string
+	cred     string
+	scope    string
+	storedAt time.Time
+}
+
+var (
+	sharedTransportOnce sync.Once
+	sharedTransport     *rateLimitTransport
+)
+
+// newSharedHTTPClient returns an HTTP client sending through the shared
+// rate limit transport.
+func newSharedHTTPClient() *http.Client {
+	sharedTransportOnce.Do(func() {
+		sharedTransport = newRateLimitTransport(http.DefaultTransport,
+			config.VCSRetryMax,
+			time.Duration(config.VCSRateLimitMaxWaitSeconds)*time.Second,
+			time.Duration(config.VCSResponseCacheTTLSeconds)*time.Second)
+	})
+	return &http.Client{Transport: sharedTransport}
+}
+
+func newRateLimitTransport(base http.RoundTripper, maxRetries int, maxWait, cacheTTL time.Duration) *rateLimitTransport {
+	return &rateLimitTransport{
+		base:       base,
+		maxRetries: maxRetries,
+		maxWait:    maxWait,
+		cacheTTL:   cacheTTL,
+		now:        time.Now,
+		sleep:      sleepContext,
+		quotas:     make(map[string]rateLimitQuota),
+		cache:      make(map[string]*cachedResponse),
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	cred := credentialKey(req)
+
+	// Callers that must see the current state send Cache-Control: no-cache.
+	cacheable := req.Method == http.MethodGet && t.cacheTTL > 0 && req.Header.Get("If-None-Match") == "" &&
+		req.Header.Get("Cache-Control") != "no-cache"
+	cacheKey := cred + " " + req.Header.Get("Accept") + " " + req.URL.String()
+	var cached *cachedResponse
+	if cacheable {
+		cached = t.lookup(cacheKey)
+		if cached != nil && t.now().Sub(cached.storedAt) < t.cacheTTL {
+			metrics.RecordVCSCacheLookup(host, "hit")
+			return cached.response(req), nil
+		}
+		if cached != nil && cached.etag != "" {
+			req = req.Clone(req.Context())
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+	}
+
+	if err := t.waitForQuota(req.Context(), cred); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.send(req, host, cred)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case cacheable && cached != nil && resp.StatusCode == http.StatusNotModified:
+		drainBody(resp)
+		refreshed := *cached
+		refreshed.storedAt = t.now()
+		t.store(cacheKey, &refreshed)
+		metrics.RecordVCSCacheLookup(host, "revalidated")
+		return refreshed.response(req), nil
+	case cacheable && resp.StatusCode == http.StatusOK:
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading response body: %w", err)
+		}
+		t.store(cacheKey, &cachedResponse{
+			status:   resp.StatusCode,
+			header:   resp.Header.Clone(),
+			body:     body,
+			etag:     resp.Header.Get("ETag"),
+			cred:     cred,
+			scope:    repoScope(req.URL.Path),
+			storedAt: t.now(),
+		})
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		metrics.RecordVCSCacheLookup(host, "miss")
+	case !isIdempotent(req.Method) && resp.StatusCode < http.StatusBadRequest:
+		t.invalidate(cred, repoScope(req.URL.Path))
+	}
+	return resp, nil
+}
+
+// send sends req, retrying rate limited responses and, for GETs, network
+// and server errors. Once out of retries, the last response is returned for
+// the caller to report.
+func (t *rateLimitTransport) send(req *http.Request, host, cred string) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if err == nil {
+			t.observe(resp, host, cred)
+		}
+
+		delay, reason, retry := t.retryDelay(req, resp, err, attempt)
+		if !retry || attempt >= t.maxRetries || !rewindable(req) {
+			return resp, err
+		}
+		if resp != nil {
+			drainBody(resp)
+		}
+		metrics.RecordVCSRequestRetry(host, reason)
+		if err := t.sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("rewinding request body: %w", err)
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// retryDelay reports whether and after how long a request should be
+// retried, and why.
+func (t *rateLimitTransport) retryDelay(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, string, bool) {
+	backoff := retryBaseDelay << attempt
+	if err != nil {
+		return backoff, "error", isIdempotent(req.Method) && req.Context().Err() == nil
+	}
+
+	if isRateLimited(resp) {
+		delay := backoff
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			delay = time.Duration(seconds) * time.Second
+		} else if remaining, reset, ok := parseRateLimit(resp.Header); ok && remaining == 0 {
+			delay = reset.Sub(t.now())
+		}
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, "rate_limited", delay <= t.maxWait
+	}
+
+	switch resp.StatusCode {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return backoff, "server_error", isIdempotent(req.Method)
+	}
+	return 0, "", false
+}
+
+// waitForQuota holds a request back while its credential's quota is
+// exhausted, failing with ErrRateLimited if the reset is further away than
+// the transport waits.
+func (t *rateLimitTransport) waitForQuota(ctx context.Context, cred string) error {
+	t.mu.Lock()
+	quota, ok := t.quotas[cred]
+	t.mu.Unlock()
+	if !ok || quota.remaining > 0 {
+		return nil
+	}
+	wait := quota.reset.Sub(t.now())
+	if wait <= 0 {
+		return nil
+	}
+	if wait > t.maxWait {
+		return fmt.Errorf("%w: quota resets at %s", ErrRateLimited, quota.reset.UTC().Format(time.RFC3339))
+	}
+	return t.sleep(ctx, wait)
+}
+
+// observe records the quota a response reports.
+func (t *rateLimitTransport) observe(resp *http.Response, host, cred string) {
+	remaining, reset, ok := parseRateLimit(resp.Header)
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	t.quotas[cred] = rateLimitQuota{remaining: remaining, reset: reset}
+	t.mu.Unlock()
+
+	resource := resp.Header.Get("X-RateLimit-Resource")
+	if resource == "" {
+		resource = "default"
+	}
+	metrics.SetVCSRateLimitRemaining(host, resource, float64(remaining))
+}
+
+func (t *rateLimitTransport) lookup(key string) *cachedResponse {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.cache[key]
+}
+
+func (t *rateLimitTransport) store(key string, entry *cachedResponse) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.cache[key]; !ok && len(t.cache) >= responseCacheMaxEntries {
+		t.evictOldest()
+	}
+	t.cache[key] = entry
+}
+
+// evictOldest drops the least recently stored entry. Callers hold t.mu.
+func (t *rateLimitTransport) evictOldest() {
+	var oldestKey string
+	var oldest time.Time
+	for key, entry := range t.cache {
+		if oldestKey == "" || entry.storedAt.Before(oldest) {
+			oldestKey, oldest = key, entry.storedAt
+		}
+	}
+	delete(t.cache, oldestKey)
+}
+
+// invalidate drops a credential's cached responses for a repository.
+func (t *rateLimitTransport) invalidate(cred, scope string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key, entry := range t.cache {
+		if entry.cred == cred && entry.scope == scope {
+			delete(t.cache, key)
+		}
+	}
+}
+
+// response builds a fresh response for req from the cached one.
+func (c *cachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", c.status, http.StatusText(c.status)),
+		StatusCode:    c.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        c.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(c.body)),
+		ContentLength: int64(len(c.body)),
+		Request:       req,
+	}
+}
+
+// parseRateLimit reads the remaining quota and its reset time from GitHub
+// (X-RateLimit-*) or GitLab (RateLimit-*) response headers.
+func parseRateLimit(header http.Header) (int, time.Time, bool) {
+	for _, prefix := range []string{"X-RateLimit-", "RateLimit-"} {
+		remaining, err := strconv.Atoi(header.Get(prefix + "Remaining"))
+		if err != nil {
+			continue
+		}
+		var reset time.Time
+		if unix, err := strconv.ParseInt(header.Get(prefix+"Reset"), 10, 64); err == nil {
+			reset = time.Unix(unix, 0)
+		}
+		return remaining, reset, true
+	}
+	return 0, time.Time{}, false
+}
+
+// isRateLimited reports whether a response is the provider refusing a
+// request for exceeding a rate limit: a 429, or a GitHub 403 for an
+// exhausted quota or a secondary rate limit.
+func isRateLimited(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusForbidden:
+		return resp.Header.Get("X-RateLimit-Remaining") == "0" || resp.Header.Get("Retry-After") != ""
+	}
+	return false
+}
+
+// credentialKey identifies the host and credential a request is sent with,
+// without keeping the credential itself.
+func credentialKey(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.Header.Get("Authorization") + "\x00" + req.Header.Get("PRIVATE-TOKEN")))
+	return req.URL.Host + "/" + hex.EncodeToString(sum[:8])
+}
+
+// repoScope is the repository part of an API path ("/repos/org/repo" on
+// GitHub, "/api/v4/projects/<id>" on GitLab), or the whole path when it
+// names none.
+func repoScope(path string) string {
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		switch {
+		case part == "repos" && i+2 < len(parts):
+			return strings.Join(parts[:i+3], "/")
+		case part == "projects" && i+1 < len(parts):
+			return strings.Join(parts[:i+2], "/")
+		}
+	}
+	return path
+}
+
+func isIdempotent(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+// rewindable reports whether req can be sent again.
+func rewindable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+func drainBody(resp *http.Response) {
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
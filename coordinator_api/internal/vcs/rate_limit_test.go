@@ -0,0 +1,270 @@
+package vcs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func fakeResponse(status int, header http.Header, body string) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+// newTestTransport returns a transport over base on a fake clock that
+// records its sleeps instead of sleeping.
+func newTestTransport(base roundTripFunc, cacheTTL time.Duration) (*rateLimitTransport, *time.Time, *[]time.Duration) {
+	now := time.Unix(1700000000, 0)
+	var slept []time.Duration
+	tr := newRateLimitTransport(base, 3, time.Minute, cacheTTL)
+	tr.now = func() time.Time { return now }
+	tr.sleep = func(ctx context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		now = now.Add(d)
+		return nil
+	}
+	return tr, &now, &slept
+}
+
+func newTestRequest(t *testing.T, method, url, token, body string) *http.Request {
+	t.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "token "+token)
+	return req
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestRateLimitTransport_RetriesRateLimitedStatus(t *testing.T) {
+	var bodies []string
+	var now *time.Time
+	tr, now, slept := newTestTransport(func(req *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(req.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			reset := now.Add(5 * time.Second).Unix()
+			return fakeResponse(http.StatusForbidden, http.Header{
+				"X-Ratelimit-Remaining": {"0"},
+				"X-Ratelimit-Reset":     {strconv.FormatInt(reset, 10)},
+			}, `{"message":"API rate limit exceeded"}`), nil
+		}
+		return fakeResponse(http.StatusCreated, nil, `{}`), nil
+	}, 0)
+
+	resp, err := tr.RoundTrip(newTestRequest(t, "POST", "https://api.github.com/repos/org/repo/statuses/abc", "t1", `{"state":"success"}`))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	// The status is sent again, body and all, once the quota resets.
+	assert.Equal(t, []string{`{"state":"success"}`, `{"state":"success"}`}, bodies)
+	assert.Equal(t, []time.Duration{5 * time.Second}, *slept)
+}
+
+func TestRateLimitTransport_HonorsRetryAfter(t *testing.T) {
+	calls := 0
+	tr, _, slept := newTestTransport(func(req *http.Request) (*http.Response, error) {
+		calls++
+		if calls == 1 {
+			return fakeResponse(http.StatusTooManyRequests, http.Header{"Retry-After": {"7"}}, ""), nil
+		}
+		return fakeResponse(http.StatusOK, nil, `{}`), nil
+	}, 0)
+
+	resp, err := tr.RoundTrip(newTestRequest(t, "GET", "https://gitlab.com/api/v4/projects/1", "t1", ""))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []time.Duration{7 * time.Second}, *slept)
+}
+
+func TestRateLimitTransport_GivesUpPastMaxWait(t *testing.T) {
+	calls := 0
+	var reset time.Time
+	tr, now, slept := newTestTransport(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return fakeResponse(http.StatusForbidden, http.Header{
+			"X-Ratelimit-Remaining": {"0"},
+			"X-Ratelimit-Reset":     {strconv.FormatInt(reset.Unix(), 10)},
+		}, ""), nil
+	}, 0)
+	reset = now.Add(time.Hour)
+
+	// The quota resets in an hour: the response is returned as is, and
+	// later requests fail without being sent.
+	resp, err := tr.RoundTrip(newTestRequest(t, "POST", "https://api.github.com/repos/org/repo/statuses/abc", "t1", `{}`))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Equal(t, 1, calls)
+	assert.Empty(t, *slept)
+
+	_, err = tr.RoundTrip(newTestRequest(t, "POST", "https://api.github.com/repos/org/repo/statuses/abc", "t1", `{}`))
+	assert.True(t, errors.Is(err, ErrRateLimited))
+	assert.Equal(t, 1, calls)
+
+	// Another credential has its own quota.
+	_, err = tr.RoundTrip(newTestRequest(t, "POST", "https://api.github.com/repos/org/repo/statuses/abc", "t2", `{}`))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestRateLimitTransport_RetriesServerErrorsOnlyForGET(t *testing.T) {
+	calls := 0
+	tr, _, slept := newTestTransport(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return fakeResponse(http.StatusBadGateway, nil, ""), nil
+	}, 0)
+
+	resp, err := tr.RoundTrip(newTestRequest(t, "GET", "https://api.github.com/repos/org/repo/pulls/1", "t1", ""))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, 4, calls)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}, *slept)
+
+	calls = 0
+	_, err = tr.RoundTrip(newTestRequest(t, "POST", "https://api.github.com/repos/org/repo/issues/1/comments", "t1", `{}`))
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRateLimitTransport_CachesGETResponses(t *testing.T) {
+	var conditional []string
+	tr, now, _ := newTestTransport(func(req *http.Request) (*http.Response, error) {
+		conditional = append(conditional, req.Header.Get("If-None-Match"))
+		if req.Header.Get("If-None-Match") == `"v1"` {
+			return fakeResponse(http.StatusNotModified, nil, ""), nil
+		}
+		return fakeResponse(http.StatusOK, http.Header{"Etag": {`"v1"`}}, `{"number":1}`), nil
+	}, 10*time.Second)
+	url := "https://api.github.com/repos/org/repo/pulls/1"
+
+	for i := 0; i < 3; i++ {
+		resp, err := tr.RoundTrip(newTestRequest(t, "GET", url, "t1", ""))
+		require.NoError(t, err)
+		assert.Equal(t, `{"number":1}`, readBody(t, resp))
+	}
+	assert.Equal(t, []string{""}, conditional, "fresh responses are reused")
+
+	// A stale response is revalidated, and reused when unchanged.
+	*now = now.Add(11 * time.Second)
+	resp, err := tr.RoundTrip(newTestRequest(t, "GET", url, "t1", ""))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"number":1}`, readBody(t, resp))
+	assert.Equal(t, []string{"", `"v1"`}, conditional)
+
+	// Responses aren't shared between credentials.
+	_, err = tr.RoundTrip(newTestRequest(t, "GET", url, "t2", ""))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", `"v1"`, ""}, conditional)
+}
+
+func TestRateLimitTransport_NoCacheBypassesCache(t *testing.T) {
+	gets := 0
+	tr, _, _ := newTestTransport(func(req *http.Request) (*http.Response, error) {
+		gets++
+		return fakeResponse(http.StatusOK, nil, `{}`), nil
+	}, time.Minute)
+	url := "https://review.example.com/a/changes/?q=commit:abc"
+
+	for i := 0; i < 2; i++ {
+		req := newTestRequest(t, "GET", url, "t1", "")
+		req.Header.Set("Cache-Control", "no-cache")
+		_, err := tr.RoundTrip(req)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, gets)
+}
+
+func TestRateLimitTransport_WriteInvalidatesRepository(t *testing.T) {
+	gets := 0
+	tr, _, _ := newTestTransport(func(req *http.Request) (*http.Response, error) {
+		if req.Method == "GET" {
+			gets++
+			return fakeResponse(http.StatusOK, nil, `{}`), nil
+		}
+		return fakeResponse(http.StatusOK, nil, `{"merged":true}`), nil
+	}, time.Minute)
+	prURL := "https://api.github.com/repos/org/repo/pulls/1"
+	otherURL := "https://api.github.com/repos/org/other/pulls/1"
+
+	for _, url := range []string{prURL, otherURL} {
+		_, err := tr.RoundTrip(newTestRequest(t, "GET", url, "t1", ""))
+		require.NoError(t, err)
+	}
+	_, err := tr.RoundTrip(newTestRequest(t, "PUT", prURL+"/merge", "t1", `{}`))
+	require.NoError(t, err)
+
+	for _, url := range []string{prURL, otherURL} {
+		_, err := tr.RoundTrip(newTestRequest(t, "GET", url, "t1", ""))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 3, gets, "only the merged repository is fetched again")
+}
+
+func TestRepoScope(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/repos/org/repo/pulls/1", "/repos/org/repo"},
+		{"/api/v3/repos/org/repo/statuses/abc", "/api/v3/repos/org/repo"},
+		{"/api/v4/projects/org%2Frepo/merge_requests/1", "/api/v4/projects/org%2Frepo"},
+		{"/user", "/user"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, repoScope(tt.path), tt.path)
+	}
+}
+
+func TestGitHubClient_ListPRFiles(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/test/repo/pulls/42/files", r.URL.Path)
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("Link", `<`+server.URL+`/repos/test/repo/pulls/42/files?per_page=100&page=2>; rel="next"`)
+			w.Write([]byte(`[{"filename":"services/api/main.go"},{"filename":"docs/new.md","previous_filename":"docs/old.md"}]`))
+			return
+		}
+		w.Write([]byte(`[{"filename":"README.md"}]`))
+	}))
+	defer server.Close()
+
+	client, err := NewGitHubClient(Config{Provider: GitHub, Token: "test-token", BaseURL: server.URL})
+	require.NoError(t, err)
+
+	files, complete, err := client.ListPRFiles(context.Background(), "test/repo", 42)
+	require.NoError(t, err)
+	assert.True(t, complete)
+	assert.Equal(t, []string{"services/api/main.go", "docs/new.md", "docs/old.md", "README.md"}, files)
+}
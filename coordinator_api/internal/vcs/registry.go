@@ -0,0 +1,171 @@
+package vcs
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+// Capabilities are the optional features of a provider's API. Code that
+// relies on one checks it first and falls back when the provider lacks it.
+type Capabilities struct {
+	// ChecksAPI is set when the provider has a checks API richer than
+	// commit statuses. Results are reported as commit statuses, which
+	// every provider supports, so this only says what a provider could do.
+	ChecksAPI bool
+	// PRComments is set when results can be posted as PR (or MR)
+	// comments. Without it, results are reported as commit statuses only.
+	PRComments bool
+	// MergeQueue is set when the provider can back the native merge
+	// queue. Without it, enqueueing a PR is rejected.
+	MergeQueue bool
+}
+
+// ProviderSpec describes a VCS provider to a Registry.
+type ProviderSpec struct {
+	Provider Provider
+	// NewClient creates a client for the provider.
+	NewClient func(config Config) (Client, error)
+	// Token returns the global API token, used when a project has none.
+	// Credentials are read when needed rather than captured at
+	// registration, so they follow the configuration.
+	Token func() string
+	// WebhookSecret returns the provider's global webhook secret. When
+	// it's nil or returns "", the shared REACTORCIDE_VCS_WEBHOOK_SECRET is
+	// used.
+	WebhookSecret func() string
+	// WebhookPath is where the provider's webhooks are received, under
+	// /api/v1/webhooks/. It defaults to the provider's name.
+	WebhookPath string
+	// IsPing reports whether a webhook delivery is the provider's
+	// connectivity check. Nil means the provider sends none.
+	IsPing func(r *http.Request) bool
+	// RepoURL returns the clone URL of the repository a webhook payload is
+	// for, used to find its projects before the delivery is validated. Nil
+	// means the payload has GitHub's or GitLab's repository fields.
+	RepoURL      func(body []byte) (string, error)
+	Capabilities Capabilities
+}
+
+// Registry holds the VCS providers the coordinator knows how to talk to.
+// Adding a provider means registering its ProviderSpec; clients, webhook
+// routes and secrets are then set up from the spec.
+type Registry struct {
+	mu    sync.RWMutex
+	specs map[Provider]ProviderSpec
+	order []Provider
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{specs: make(map[Provider]ProviderSpec)}
+}
+
+// Register adds spec, replacing any spec registered for the same provider.
+func (r *Registry) Register(spec ProviderSpec) {
+	if spec.WebhookPath == "" {
+		spec.WebhookPath = string(spec.Provider)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.specs[spec.Provider]; !ok {
+		r.order = append(r.order, spec.Provider)
+	}
+	r.specs[spec.Provider] = spec
+}
+
+// Lookup returns the spec registered for provider.
+func (r *Registry) Lookup(provider Provider) (ProviderSpec, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	spec, ok := r.specs[provider]
+	return spec, ok
+}
+
+// Capabilities returns provider's capabilities, which are all unset for an
+// unregistered provider.
+func (r *Registry) Capabilities(provider Provider) Capabilities {
+	spec, _ := r.Lookup(provider)
+	return spec.Capabilities
+}
+
+// Enabled returns the specs of the named providers in registration order,
+// or of every registered provider when names is empty. It also returns the
+// names that aren't registered.
+func (r *Registry) Enabled(names []string) ([]ProviderSpec, []string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	wanted := make(map[Provider]bool, len(names))
+	var unknown []string
+	for _, name := range names {
+		provider := Provider(strings.ToLower(strings.TrimSpace(name)))
+		if provider == "" {
+			continue
+		}
+		if _, ok := r.specs[provider]; !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		wanted[provider] = true
+	}
+	var specs []ProviderSpec
+	for _, provider := range r.order {
+		if len(names) == 0 || wanted[provider] {
+			specs = append(specs, r.specs[provider])
+		}
+	}
+	return specs, unknown
+}
+
+// Providers is the registry of built-in providers, with their global
+// credentials from the REACTORCIDE_VCS_* settings.
+var Providers = newBuiltinRegistry()
+
+func newBuiltinRegistry() *Registry {
+	r := NewRegistry()
+	r.Register(ProviderSpec{
+		Provider:      GitHub,
+		NewClient:     func(config Config) (Client, error) { return NewGitHubClient(config) },
+		Token:         func() string { return config.VCSGitHubToken },
+		WebhookSecret: func() string { return config.VCSGitHubSecret },
+		IsPing: func(r *http.Request) bool {
+			return r.Header.Get("X-GitHub-Event") == "ping"
+		},
+		Capabilities: Capabilities{ChecksAPI: true, PRComments: true, MergeQueue: true},
+	})
+	r.Register(ProviderSpec{
+		Provider:      GitLab,
+		NewClient:     func(config Config) (Client, error) { return NewGitLabClient(config) },
+		Token:         func() string { return config.VCSGitLabToken },
+		WebhookSecret: func() string { return config.VCSGitLabSecret },
+		Capabilities:  Capabilities{PRComments: true},
+	})
+	r.Register(ProviderSpec{
+		Provider:      Gerrit,
+		NewClient:     func(config Config) (Client, error) { return NewGerritClient(config) },
+		Token:         func() string { return config.VCSGerritToken },
+		WebhookSecret: func() string { return config.VCSGerritSecret },
+		RepoURL:       GerritRepoURL,
+		// Results are votes on the change's labels, with a review message.
+		Capabilities: Capabilities{},
+	})
+	return r
+}
+
+// EnabledProviders returns the built-in providers enabled by
+// REACTORCIDE_VCS_PROVIDERS (all of them when it's unset), logging any it
+// names that don't exist.
+func EnabledProviders() []ProviderSpec {
+	var names []string
+	if config.VCSProviders != "" {
+		names = strings.Split(config.VCSProviders, ",")
+	}
+	specs, unknown := Providers.Enabled(names)
+	for _, name := range unknown {
+		logrus.WithField("provider", name).Warn("Ignoring unknown VCS provider in REACTORCIDE_VCS_PROVIDERS")
+	}
+	return specs
+}
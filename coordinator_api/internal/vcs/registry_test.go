@@ -0,0 +1,76 @@
+package vcs
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	r.Register(ProviderSpec{Provider: "forge", Capabilities: Capabilities{PRComments: true}})
+	r.Register(ProviderSpec{Provider: "hub", WebhookPath: "hub/events"})
+
+	spec, ok := r.Lookup("forge")
+	require.True(t, ok)
+	assert.Equal(t, "forge", spec.WebhookPath, "webhook path defaults to the provider name")
+	spec, _ = r.Lookup("hub")
+	assert.Equal(t, "hub/events", spec.WebhookPath)
+
+	assert.True(t, r.Capabilities("forge").PRComments)
+	assert.Equal(t, Capabilities{}, r.Capabilities("nowhere"))
+
+	t.Run("enabled", func(t *testing.T) {
+		specs, unknown := r.Enabled(nil)
+		assert.Equal(t, []Provider{"forge", "hub"}, providersOf(specs))
+		assert.Empty(t, unknown)
+
+		specs, unknown = r.Enabled([]string{" HUB ", "nowhere", ""})
+		assert.Equal(t, []Provider{"hub"}, providersOf(specs))
+		assert.Equal(t, []string{"nowhere"}, unknown)
+	})
+
+	t.Run("re-registering replaces in place", func(t *testing.T) {
+		r.Register(ProviderSpec{Provider: "forge"})
+		assert.False(t, r.Capabilities("forge").PRComments)
+		specs, _ := r.Enabled(nil)
+		assert.Equal(t, []Provider{"forge", "hub"}, providersOf(specs))
+	})
+}
+
+func providersOf(specs []ProviderSpec) []Provider {
+	var providers []Provider
+	for _, spec := range specs {
+		providers = append(providers, spec.Provider)
+	}
+	return providers
+}
+
+func TestBuiltinProviders(t *testing.T) {
+	for _, provider := range []Provider{GitHub, GitLab, Gerrit} {
+		client, err := NewClient(Config{Provider: provider})
+		require.NoError(t, err)
+		assert.Equal(t, provider, client.GetProvider())
+	}
+	_, err := NewClient(Config{Provider: "nowhere"})
+	assert.ErrorIs(t, err, ErrUnsupportedProvider)
+
+	github, _ := Providers.Lookup(GitHub)
+	ping := httptest.NewRequest("POST", "/api/v1/webhooks/github", nil)
+	ping.Header.Set("X-GitHub-Event", "ping")
+	assert.True(t, github.IsPing(ping))
+	assert.True(t, Providers.Capabilities(GitHub).MergeQueue)
+	assert.False(t, Providers.Capabilities(GitLab).MergeQueue)
+	// Gerrit results are label votes, not comments.
+	assert.False(t, Providers.Capabilities(Gerrit).PRComments)
+}
+
+func TestJobMetadataCommentsOnPR(t *testing.T) {
+	assert.True(t, (&JobMetadata{VCSProvider: "github", PRNumber: 1}).commentsOnPR())
+	assert.False(t, (&JobMetadata{VCSProvider: "github", PRNumber: 1, IsEval: true}).commentsOnPR())
+	assert.False(t, (&JobMetadata{VCSProvider: "github"}).commentsOnPR())
+	// Providers without PR comments fall back to commit statuses only.
+	assert.False(t, (&JobMetadata{VCSProvider: "nowhere", PRNumber: 1}).commentsOnPR())
+}
@@ -0,0 +1,194 @@
+package vcs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/metrics"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// outboxBatchSize caps how many deliveries one dispatch claims.
+	outboxBatchSize = 100
+	// outboxLease is how long a claimed delivery is hidden from other
+	// dispatchers; a dispatcher that dies mid-delivery is retried after it.
+	outboxLease = 5 * time.Minute
+	// outboxDeliveryTimeout bounds one delivery, rate limit waits included.
+	outboxDeliveryTimeout = 2 * time.Minute
+	// outboxRetryBaseDelay is the wait after a first failed delivery; it
+	// doubles each attempt, up to outboxRetryMaxDelay.
+	outboxRetryBaseDelay = 5 * time.Second
+	outboxRetryMaxDelay  = 10 * time.Minute
+)
+
+// StatusOutbox is the store capability backing the status outbox. See
+// postgres_store/status_outbox_operations.go.
+type StatusOutbox interface {
+	GetJobByID(ctx context.Context, jobID string) (*models.Job, error)
+	EnqueueStatusDelivery(ctx context.Context, jobID, kind string, now time.Time) error
+	ClaimStatusDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.StatusDelivery, error)
+	CompleteStatusDelivery(ctx context.Context, delivery *models.StatusDelivery) error
+	RetryStatusDelivery(ctx context.Context, delivery *models.StatusDelivery, lastError string, next time.Time, failed bool) error
+}
+
+// SetOutbox makes UpdateJobStatus queue updates in outbox rather than
+// deliver them inline. A dispatcher (see StartOutboxDispatcher) delivers
+// them, in this process or another sharing the database, retrying up to
+// maxAttempts times.
+func (u *JobStatusUpdater) SetOutbox(outbox StatusOutbox, maxAttempts int) {
+	u.outbox = outbox
+	u.outboxMaxAttempts = maxAttempts
+}
+
+// enqueueJobStatus queues the job's commit status, and its PR comment if
+// it has one.
+func (u *JobStatusUpdater) enqueueJobStatus(ctx context.Context, job *models.Job, metadata *JobMetadata) error {
+	now := time.Now().UTC()
+	if err := u.outbox.EnqueueStatusDelivery(ctx, job.JobID, models.StatusDeliveryCommitStatus, now); err != nil {
+		return err
+	}
+	if metadata.commentsOnPR() {
+		if err := u.outbox.EnqueueStatusDelivery(ctx, job.JobID, models.StatusDeliveryPRComment, now); err != nil {
+			return err
+		}
+	}
+	select {
+	case u.outboxWake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// StartOutboxDispatcher delivers queued updates every interval, and as
+// soon as this process queues one, until ctx is done. It returns
+// immediately; without an outbox it does nothing.
+func (u *JobStatusUpdater) StartOutboxDispatcher(ctx context.Context, interval time.Duration) {
+	if u.outbox == nil || interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-u.outboxWake:
+			}
+			for {
+				claimed, err := u.DispatchOutbox(ctx)
+				if err != nil {
+					u.logger.WithError(err).Warn("Status outbox dispatch failed")
+				}
+				if err != nil || claimed < outboxBatchSize {
+					break
+				}
+			}
+		}
+	}()
+}
+
+// DispatchOutbox claims a batch of due deliveries and delivers them. It
+// returns how many it claimed.
+func (u *JobStatusUpdater) DispatchOutbox(ctx context.Context) (int, error) {
+	if u.outbox == nil {
+		return 0, errors.New("no status outbox configured")
+	}
+	deliveries, err := u.outbox.ClaimStatusDeliveries(ctx, time.Now().UTC(), outboxLease, outboxBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	for i := range deliveries {
+		u.dispatch(ctx, &deliveries[i])
+	}
+	return len(deliveries), nil
+}
+
+// dispatch delivers one claimed entry and records the outcome.
+func (u *JobStatusUpdater) dispatch(ctx context.Context, delivery *models.StatusDelivery) {
+	deliveryCtx, cancel := context.WithTimeout(ctx, outboxDeliveryTimeout)
+	err := u.deliver(deliveryCtx, delivery)
+	cancel()
+
+	logger := u.logger.WithFields(logrus.Fields{
+		"job_id":   delivery.JobID,
+		"kind":     delivery.Kind,
+		"attempts": delivery.Attempts,
+	})
+	if err == nil {
+		if err := u.outbox.CompleteStatusDelivery(ctx, delivery); err != nil {
+			logger.WithError(err).Warn("Failed to remove delivered status update from outbox")
+		}
+		metrics.RecordVCSStatusDelivery(delivery.Kind, "delivered")
+		return
+	}
+
+	failed := delivery.Attempts >= u.outboxMaxAttempts
+	next := time.Now().UTC().Add(outboxRetryDelay(delivery.Attempts))
+	if err := u.outbox.RetryStatusDelivery(ctx, delivery, err.Error(), next, failed); err != nil {
+		logger.WithError(err).Warn("Failed to reschedule status update")
+	}
+	if failed {
+		logger.WithError(err).Error("Giving up on VCS status update")
+		metrics.RecordVCSStatusDelivery(delivery.Kind, "failed")
+		return
+	}
+	logger.WithError(err).WithField("next_attempt_at", next).Warn("VCS status update failed, will retry")
+	metrics.RecordVCSStatusDelivery(delivery.Kind, "retried")
+}
+
+// deliver sends the update for the job's current state. Jobs that are
+// gone, have no VCS metadata or have no client for their provider have
+// nothing to deliver.
+func (u *JobStatusUpdater) deliver(ctx context.Context, delivery *models.StatusDelivery) error {
+	job, err := u.outbox.GetJobByID(ctx, delivery.JobID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("loading job: %w", err)
+	}
+	if job.Notes == "" {
+		return nil
+	}
+	var metadata JobMetadata
+	if err := json.Unmarshal([]byte(job.Notes), &metadata); err != nil {
+		return nil
+	}
+
+	provider := Provider(metadata.VCSProvider)
+	client := u.getClientForJob(ctx, job, provider)
+	if client == nil {
+		u.logger.WithField("provider", provider).Debug("No VCS client available for provider")
+		return nil
+	}
+
+	switch delivery.Kind {
+	case models.StatusDeliveryCommitStatus:
+		return u.sendCommitStatus(ctx, client, job, &metadata)
+	case models.StatusDeliveryPRComment:
+		return u.updatePRCommentForJob(ctx, client, job, &metadata)
+	}
+	u.logger.WithField("kind", delivery.Kind).Warn("Dropping status update of unknown kind")
+	return nil
+}
+
+// outboxRetryDelay is the wait before the next attempt after attempts
+// failed ones.
+func outboxRetryDelay(attempts int) time.Duration {
+	delay := outboxRetryBaseDelay
+	for i := 1; i < attempts && delay < outboxRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > outboxRetryMaxDelay {
+		delay = outboxRetryMaxDelay
+	}
+	return delay
+}
@@ -0,0 +1,176 @@
+package vcs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOutbox is an in-memory StatusOutbox. Every entry is due at once.
+type fakeOutbox struct {
+	jobs       map[string]*models.Job
+	deliveries map[string]*models.StatusDelivery
+}
+
+func newFakeOutbox(jobs ...*models.Job) *fakeOutbox {
+	o := &fakeOutbox{jobs: map[string]*models.Job{}, deliveries: map[string]*models.StatusDelivery{}}
+	for _, job := range jobs {
+		o.jobs[job.JobID] = job
+	}
+	return o
+}
+
+func (o *fakeOutbox) GetJobByID(ctx context.Context, jobID string) (*models.Job, error) {
+	if job, ok := o.jobs[jobID]; ok {
+		return job, nil
+	}
+	return nil, store.ErrNotFound
+}
+
+func (o *fakeOutbox) EnqueueStatusDelivery(ctx context.Context, jobID, kind string, now time.Time) error {
+	key := jobID + "/" + kind
+	if d, ok := o.deliveries[key]; ok {
+		d.Generation++
+		d.Status = models.StatusDeliveryPending
+		d.Attempts = 0
+		return nil
+	}
+	o.deliveries[key] = &models.StatusDelivery{OutboxID: key, JobID: jobID, Kind: kind, Status: models.StatusDeliveryPending, Generation: 1}
+	return nil
+}
+
+func (o *fakeOutbox) ClaimStatusDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.StatusDelivery, error) {
+	var claimed []models.StatusDelivery
+	for _, d := range o.deliveries {
+		if d.Status == models.StatusDeliveryPending {
+			d.Attempts++
+			claimed = append(claimed, *d)
+		}
+	}
+	return claimed, nil
+}
+
+func (o *fakeOutbox) CompleteStatusDelivery(ctx context.Context, delivery *models.StatusDelivery) error {
+	if d, ok := o.deliveries[delivery.OutboxID]; ok && d.Generation == delivery.Generation {
+		delete(o.deliveries, delivery.OutboxID)
+	}
+	return nil
+}
+
+func (o *fakeOutbox) RetryStatusDelivery(ctx context.Context, delivery *models.StatusDelivery, lastError string, next time.Time, failed bool) error {
+	if d, ok := o.deliveries[delivery.OutboxID]; ok && d.Generation == delivery.Generation {
+		d.LastError = lastError
+		d.NextAttemptAt = next
+		if failed {
+			d.Status = models.StatusDeliveryFailed
+		}
+	}
+	return nil
+}
+
+func outboxTestJob() *models.Job {
+	return &models.Job{
+		JobID:  "job-1",
+		Status: "running",
+		Notes:  `{"vcs_provider":"github","repo":"test/repo","pr_number":7,"commit_sha":"abc123"}`,
+	}
+}
+
+func TestStatusOutbox_QueuesInsteadOfDelivering(t *testing.T) {
+	job := outboxTestJob()
+	outbox := newFakeOutbox(job)
+	mockClient := new(MockClient)
+	updater := NewJobStatusUpdater()
+	updater.AddVCSClient(GitHub, mockClient)
+	updater.SetOutbox(outbox, 3)
+
+	require.NoError(t, updater.UpdateJobStatus(context.Background(), job))
+	mockClient.AssertNotCalled(t, "UpdateCommitStatus", mock.Anything, mock.Anything, mock.Anything)
+	assert.Contains(t, outbox.deliveries, "job-1/"+models.StatusDeliveryCommitStatus)
+	assert.Contains(t, outbox.deliveries, "job-1/"+models.StatusDeliveryPRComment)
+
+	// A second status change folds into the pending entries.
+	require.NoError(t, updater.UpdateJobStatus(context.Background(), job))
+	assert.Len(t, outbox.deliveries, 2)
+	assert.Equal(t, 2, outbox.deliveries["job-1/"+models.StatusDeliveryCommitStatus].Generation)
+}
+
+func TestStatusOutbox_DeliversCurrentState(t *testing.T) {
+	job := outboxTestJob()
+	outbox := newFakeOutbox(job)
+	mockClient := new(MockClient)
+	updater := NewJobStatusUpdater()
+	updater.AddVCSClient(GitHub, mockClient)
+	updater.SetOutbox(outbox, 3)
+
+	require.NoError(t, updater.UpdateJobStatus(context.Background(), job))
+	exitCode := 0
+	job.Status = "completed"
+	job.ExitCode = &exitCode
+
+	mockClient.On("UpdateCommitStatus", mock.Anything, "test/repo", mock.MatchedBy(func(update StatusUpdate) bool {
+		return update.State == StatusSuccess && update.SHA == "abc123"
+	})).Return(nil).Once()
+
+	// Without a store there's no PR comment to post, so both entries are
+	// delivered.
+	claimed, err := updater.DispatchOutbox(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, claimed)
+	assert.Empty(t, outbox.deliveries)
+	mockClient.AssertExpectations(t)
+}
+
+func TestStatusOutbox_RetriesThenGivesUp(t *testing.T) {
+	job := outboxTestJob()
+	job.Notes = `{"vcs_provider":"github","repo":"test/repo","commit_sha":"abc123"}`
+	outbox := newFakeOutbox(job)
+	mockClient := new(MockClient)
+	mockClient.On("UpdateCommitStatus", mock.Anything, "test/repo", mock.Anything).Return(errors.New("502 Bad Gateway"))
+	updater := NewJobStatusUpdater()
+	updater.AddVCSClient(GitHub, mockClient)
+	updater.SetOutbox(outbox, 2)
+
+	require.NoError(t, updater.UpdateJobStatus(context.Background(), job))
+	delivery := outbox.deliveries["job-1/"+models.StatusDeliveryCommitStatus]
+
+	_, err := updater.DispatchOutbox(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusDeliveryPending, delivery.Status)
+	assert.Contains(t, delivery.LastError, "502 Bad Gateway")
+	assert.True(t, delivery.NextAttemptAt.After(time.Now()))
+
+	_, err = updater.DispatchOutbox(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusDeliveryFailed, delivery.Status)
+
+	// The job's next status change revives it.
+	require.NoError(t, updater.UpdateJobStatus(context.Background(), job))
+	assert.Equal(t, models.StatusDeliveryPending, delivery.Status)
+	assert.Equal(t, 0, delivery.Attempts)
+}
+
+func TestStatusOutbox_DropsDeletedJobs(t *testing.T) {
+	outbox := newFakeOutbox()
+	updater := NewJobStatusUpdater()
+	updater.SetOutbox(outbox, 3)
+	require.NoError(t, outbox.EnqueueStatusDelivery(context.Background(), "gone", models.StatusDeliveryCommitStatus, time.Now()))
+
+	_, err := updater.DispatchOutbox(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, outbox.deliveries)
+}
+
+func TestOutboxRetryDelay(t *testing.T) {
+	assert.Equal(t, 5*time.Second, outboxRetryDelay(1))
+	assert.Equal(t, 10*time.Second, outboxRetryDelay(2))
+	assert.Equal(t, 40*time.Second, outboxRetryDelay(4))
+	assert.Equal(t, outboxRetryMaxDelay, outboxRetryDelay(30))
+}
@@ -38,6 +38,11 @@ type JobStatusUpdater struct {
 	baseURL       string            // base URL for job links in commit statuses
 	store         store.Store       // optional: used for rolling PR comment coordination
 	logger        *logrus.Logger
+
+	// Status outbox (see SetOutbox).
+	outbox            StatusOutbox
+	outboxWake        chan struct{}
+	outboxMaxAttempts int
 }
 
 // SetStore wires the data store used to look up sibling jobs for a PR and
@@ -54,6 +59,7 @@ func NewJobStatusUpdater() *JobStatusUpdater {
 
 	return &JobStatusUpdater{
 		vcsClients: make(map[Provider]Client),
+		outboxWake: make(chan struct{}, 1),
 		logger:     logger,
 	}
 }
@@ -96,6 +102,20 @@ type JobMetadata struct {
 	CommitSHA     string `json:"commit_sha"`
 	StatusContext string `json:"status_context,omitempty"`
 	IsEval        bool   `json:"is_eval,omitempty"`
+	// StatusKey is the job's stable name in its project's status context
+	// template: "eval" for eval jobs, the job definition's status_key or
+	// name for the jobs they trigger.
+	StatusKey string `json:"status_key,omitempty"`
+	// StatusContextAliases are old contexts the job's status is also
+	// reported under; see models.StatusContextAliases.
+	StatusContextAliases []string `json:"status_context_aliases,omitempty"`
+}
+
+// commentsOnPR reports whether the job's status changes are posted as PR
+// comments: it belongs to a PR, isn't an eval job, and its provider
+// supports comments. Otherwise its commit status is all that's reported.
+func (m *JobMetadata) commentsOnPR() bool {
+	return m.PRNumber > 0 && !m.IsEval && Providers.Capabilities(Provider(m.VCSProvider)).PRComments
 }
 
 // GetStatusContext returns the status context, falling back to the default.
@@ -106,6 +126,16 @@ func (m *JobMetadata) GetStatusContext() string {
 	return DefaultStatusContext
 }
 
+// UseStatusContext sets the status context, along with the old contexts
+// project still reports it under.
+func (m *JobMetadata) UseStatusContext(project *models.Project, statusContext string) {
+	m.StatusContext = statusContext
+	m.StatusContextAliases = nil
+	if project != nil {
+		m.StatusContextAliases = project.StatusContextAliases.For(statusContext)
+	}
+}
+
 // ApplyToJob writes the metadata as Notes JSON and also populates the
 // denormalized VCS columns used for fast (repo, pr, commit) lookups.
 // Single writer per job — Notes stays authoritative, columns mirror it.
@@ -147,7 +177,9 @@ func MetadataFromJob(job *models.Job) (*JobMetadata, error) {
 	return &m, nil
 }
 
-// UpdateJobStatus updates the VCS commit status based on job status
+// UpdateJobStatus updates the VCS commit status based on job status. With
+// an outbox (see SetOutbox), the update is queued for the outbox
+// dispatcher to deliver; otherwise it's delivered inline.
 func (u *JobStatusUpdater) UpdateJobStatus(ctx context.Context, job *models.Job) error {
 	// Parse VCS metadata from job notes
 	if job.Notes == "" {
@@ -161,6 +193,14 @@ func (u *JobStatusUpdater) UpdateJobStatus(ctx context.Context, job *models.Job)
 		return nil
 	}
 
+	if u.outbox != nil {
+		err := u.enqueueJobStatus(ctx, job, &metadata)
+		if err == nil {
+			return nil
+		}
+		u.logger.WithError(err).WithField("job_id", job.JobID).Warn("Failed to queue VCS status update, delivering inline")
+	}
+
 	// Get the appropriate VCS client (per-project token takes priority)
 	provider := Provider(metadata.VCSProvider)
 	client := u.getClientForJob(ctx, job, provider)
@@ -169,6 +209,30 @@ func (u *JobStatusUpdater) UpdateJobStatus(ctx context.Context, job *models.Job)
 		return nil
 	}
 
+	if err := u.sendCommitStatus(ctx, client, job, &metadata); err != nil {
+		return err
+	}
+
+	// Update the PR comment (rolling pre-merge, or per-job post-merge).
+	// Eval jobs don't comment — the rolling comment shows the eval job as a
+	// row alongside its children, so a separate eval-only comment would be
+	// redundant. Runs on every status change, not just completions, so the
+	// PR reflects live progress.
+	if metadata.commentsOnPR() {
+		if err := u.updatePRCommentForJob(ctx, client, job, &metadata); err != nil {
+			u.logger.WithError(err).WithFields(logrus.Fields{
+				"job_id":    job.JobID,
+				"repo":      metadata.Repo,
+				"pr_number": metadata.PRNumber,
+			}).Warn("Failed to update PR comment")
+		}
+	}
+
+	return nil
+}
+
+// sendCommitStatus sets the commit status for the job's current state.
+func (u *JobStatusUpdater) sendCommitStatus(ctx context.Context, client Client, job *models.Job, metadata *JobMetadata) error {
 	// Map job status to VCS status
 	vcsStatus := u.mapJobStatusToVCSStatus(job.Status)
 
@@ -187,10 +251,20 @@ func (u *JobStatusUpdater) UpdateJobStatus(ctx context.Context, job *models.Job)
 			"job_id":   job.JobID,
 			"repo":     metadata.Repo,
 			"sha":      metadata.CommitSHA,
-			"provider": provider,
+			"provider": metadata.VCSProvider,
 		}).Error("Failed to update commit status")
 		return fmt.Errorf("updating commit status: %w", err)
 	}
+	for _, alias := range metadata.StatusContextAliases {
+		update.Context = alias
+		if err := client.UpdateCommitStatus(ctx, metadata.Repo, update); err != nil {
+			u.logger.WithError(err).WithFields(logrus.Fields{
+				"job_id":  job.JobID,
+				"repo":    metadata.Repo,
+				"context": alias,
+			}).Warn("Failed to update aliased commit status")
+		}
+	}
 
 	u.logger.WithFields(logrus.Fields{
 		"job_id":     job.JobID,
@@ -199,16 +273,6 @@ func (u *JobStatusUpdater) UpdateJobStatus(ctx context.Context, job *models.Job)
 		"repo":       metadata.Repo,
 		"sha":        metadata.CommitSHA,
 	}).Info("Updated VCS commit status")
-
-	// Update the PR comment (rolling pre-merge, or per-job post-merge).
-	// Eval jobs don't comment — the rolling comment shows the eval job as a
-	// row alongside its children, so a separate eval-only comment would be
-	// redundant. Runs on every status change, not just completions, so the
-	// PR reflects live progress.
-	if metadata.PRNumber > 0 && !metadata.IsEval {
-		u.updatePRCommentForJob(ctx, client, job, &metadata)
-	}
-
 	return nil
 }
 
@@ -301,6 +365,17 @@ func (u *JobStatusUpdater) getClientForJob(ctx context.Context, job *models.Job,
 	return nil
 }
 
+// ClientForJob returns the VCS client for the job's provider, using its
+// project's or owner's token when there's one. Nil when the job has no VCS
+// metadata or there's no client for its provider.
+func (u *JobStatusUpdater) ClientForJob(ctx context.Context, job *models.Job) Client {
+	metadata, err := MetadataFromJob(job)
+	if err != nil || metadata == nil || metadata.VCSProvider == "" {
+		return nil
+	}
+	return u.getClientForJob(ctx, job, Provider(metadata.VCSProvider))
+}
+
 func (u *JobStatusUpdater) getProjectClient(ctx context.Context, projectID *string, provider Provider) Client {
 	if projectID == nil || u.projectLookup == nil {
 		return nil
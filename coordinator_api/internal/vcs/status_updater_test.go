@@ -143,6 +143,27 @@ func TestJobStatusUpdater_UpdateJobStatus(t *testing.T) {
 	}
 }
 
+func TestJobStatusUpdater_StatusContextAliases(t *testing.T) {
+	updater := NewJobStatusUpdater()
+	mockClient := new(MockClient)
+	updater.AddVCSClient(GitHub, mockClient)
+
+	for _, context := range []string{"ci/api/unit", "unit-tests", "unit"} {
+		context := context
+		mockClient.On("UpdateCommitStatus", mock.Anything, "test/repo", mock.MatchedBy(func(update StatusUpdate) bool {
+			return update.Context == context && update.State == StatusRunning
+		})).Return(nil).Once()
+	}
+
+	job := &models.Job{
+		JobID:  "test-job",
+		Status: "running",
+		Notes:  `{"vcs_provider":"github","repo":"test/repo","commit_sha":"abc123","status_context":"ci/api/unit","status_key":"unit","status_context_aliases":["unit-tests","unit"]}`,
+	}
+	assert.NoError(t, updater.UpdateJobStatus(context.Background(), job))
+	mockClient.AssertExpectations(t)
+}
+
 func TestJobStatusUpdater_MapJobStatusToVCSStatus(t *testing.T) {
 	updater := NewJobStatusUpdater()
 
@@ -35,7 +35,7 @@ func (u *JobStatusUpdater) UpdateWorkflowStatus(ctx context.Context, wf *models.
 	if err := client.UpdateCommitStatus(ctx, wf.VCSRepo, update); err != nil {
 		return fmt.Errorf("updating workflow commit status: %w", err)
 	}
-	if wf.PRNumber != nil && *wf.PRNumber > 0 {
+	if wf.PRNumber != nil && *wf.PRNumber > 0 && Providers.Capabilities(provider).PRComments {
 		marker := wf.CommentMarker
 		if marker == "" {
 			marker = fmt.Sprintf("<!-- reactorcide:workflows:%s -->", wf.CommitSHA)
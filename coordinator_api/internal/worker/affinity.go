@@ -0,0 +1,124 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/sirupsen/logrus"
+)
+
+// AffinityProject is the job affinity that prefers a worker which recently
+// started a job of the same project, so its git mirror and caches are warm.
+const AffinityProject = "project"
+
+// Defaults for AffinityConfig.
+const (
+	DefaultAffinityWait   = 30 * time.Second
+	DefaultAffinityWindow = time.Hour
+)
+
+// AffinityConfig bounds project affinity. A worker that hasn't started a
+// job of the project within Window passes the job over, leaving it to a
+// worker that has, until the job has waited Wait since it was created.
+type AffinityConfig struct {
+	Wait   time.Duration
+	Window time.Duration
+}
+
+// Affinity decisions, the "result" label of
+// reactorcide_job_affinity_decisions_total.
+const (
+	// affinityHit: the job ran on a warm worker.
+	affinityHit = "hit"
+	// affinityMiss: the job ran on a cold worker after waiting out
+	// AffinityConfig.Wait for a warm one.
+	affinityMiss = "miss"
+	// affinityCold: no worker was warm, so the job ran where it landed.
+	affinityCold = "cold"
+	// affinityDeferred: a cold worker put the job back for a warm one.
+	affinityDeferred = "deferred"
+	// affinityAvoided: a worker in the job's avoid_workers put it back.
+	affinityAvoided = "avoided"
+)
+
+// affinityStore is the narrow store capability behind project affinity.
+// See postgres_store/affinity_operations.go.
+type affinityStore interface {
+	ListRecentProjectWorkers(ctx context.Context, projectID string, since time.Time) ([]string, error)
+}
+
+// ValidateAffinity checks a job's scheduling hints: affinity is empty or
+// AffinityProject, and each avoid_workers entry is a worker ID or a
+// path.Match glob.
+func ValidateAffinity(affinity string, avoidWorkers []string) error {
+	if affinity != "" && affinity != AffinityProject {
+		return fmt.Errorf("unsupported affinity %q (want %q)", affinity, AffinityProject)
+	}
+	for _, pattern := range avoidWorkers {
+		if pattern == "" {
+			return errors.New("avoid_workers entries must not be empty")
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid avoid_workers pattern %q", pattern)
+		}
+	}
+	return nil
+}
+
+// workerAvoided reports whether workerID matches one of patterns.
+func workerAvoided(patterns []string, workerID string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, workerID); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// affinityDecision decides whether this worker runs a job it dequeued or
+// puts it back for another worker, and returns the decision to record; ""
+// when the job has no scheduling hints. Failing to look up warm workers
+// runs the job here rather than stalling it.
+func (w *CornDogsWorker) affinityDecision(ctx context.Context, job *models.Job, now time.Time, logger *logrus.Entry) (result string, run bool) {
+	if workerAvoided(job.AvoidWorkers, w.config.WorkerID) {
+		return affinityAvoided, false
+	}
+	if job.Affinity != AffinityProject || job.ProjectID == nil {
+		return "", true
+	}
+	as, ok := w.config.Store.(affinityStore)
+	if !ok {
+		return affinityCold, true
+	}
+
+	window := w.config.Affinity.Window
+	if window <= 0 {
+		window = DefaultAffinityWindow
+	}
+	warm, err := as.ListRecentProjectWorkers(ctx, *job.ProjectID, now.Add(-window))
+	if err != nil {
+		logger.WithError(err).Warn("Failed to look up warm workers for job affinity")
+		return affinityCold, true
+	}
+	if len(warm) == 0 {
+		return affinityCold, true
+	}
+	for _, id := range warm {
+		if id == w.config.WorkerID {
+			return affinityHit, true
+		}
+	}
+
+	wait := w.config.Affinity.Wait
+	if wait <= 0 {
+		wait = DefaultAffinityWait
+	}
+	if now.Sub(job.CreatedAt) < wait {
+		return affinityDeferred, false
+	}
+	return affinityMiss, true
+}
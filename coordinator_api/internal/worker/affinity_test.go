@@ -0,0 +1,69 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+type affinityMockStore struct {
+	MockStore
+	warm []string
+}
+
+func (m *affinityMockStore) ListRecentProjectWorkers(ctx context.Context, projectID string, since time.Time) ([]string, error) {
+	return m.warm, nil
+}
+
+func TestAffinityDecision(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	projectID := "6f1c8f9e-2d6b-4a55-9d1c-3f1f4a0c7b21"
+	s := &affinityMockStore{}
+	w := &CornDogsWorker{config: &Config{Store: s, WorkerID: "build-2", Affinity: AffinityConfig{Wait: time.Minute, Window: time.Hour}}}
+	logger := logging.Log.WithField("component", "affinity")
+
+	decide := func(job *models.Job) (string, bool) {
+		return w.affinityDecision(context.Background(), job, now, logger)
+	}
+	fresh := now.Add(-10 * time.Second)
+	stale := now.Add(-2 * time.Minute)
+
+	result, run := decide(&models.Job{CreatedAt: fresh})
+	assert.Equal(t, "", result)
+	assert.True(t, run)
+
+	result, run = decide(&models.Job{CreatedAt: fresh, AvoidWorkers: pq.StringArray{"build-1", "build-2*"}})
+	assert.Equal(t, affinityAvoided, result)
+	assert.False(t, run)
+
+	result, run = decide(&models.Job{CreatedAt: fresh, ProjectID: &projectID, Affinity: AffinityProject})
+	assert.Equal(t, affinityCold, result)
+	assert.True(t, run)
+
+	s.warm = []string{"build-1", "build-2"}
+	result, run = decide(&models.Job{CreatedAt: fresh, ProjectID: &projectID, Affinity: AffinityProject})
+	assert.Equal(t, affinityHit, result)
+	assert.True(t, run)
+
+	s.warm = []string{"build-1"}
+	result, run = decide(&models.Job{CreatedAt: fresh, ProjectID: &projectID, Affinity: AffinityProject})
+	assert.Equal(t, affinityDeferred, result)
+	assert.False(t, run)
+
+	result, run = decide(&models.Job{CreatedAt: stale, ProjectID: &projectID, Affinity: AffinityProject})
+	assert.Equal(t, affinityMiss, result)
+	assert.True(t, run)
+}
+
+func TestValidateAffinity(t *testing.T) {
+	assert.NoError(t, ValidateAffinity("", nil))
+	assert.NoError(t, ValidateAffinity(AffinityProject, []string{"build-1", "gpu-*"}))
+	assert.Error(t, ValidateAffinity("repo", nil))
+	assert.Error(t, ValidateAffinity("", []string{""}))
+	assert.Error(t, ValidateAffinity("", []string{"build-["}))
+}
@@ -0,0 +1,490 @@
+package worker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+const (
+	// ociEmptyConfigMediaType and ociEmptyConfig are the empty config
+	// blob OCI artifacts carry, whose digest is ociEmptyConfigDigest.
+	ociEmptyConfigMediaType = "application/vnd.oci.empty.v1+json"
+	ociEmptyConfig          = "{}"
+	ociEmptyConfigDigest    = "sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a"
+
+	// publishedArtifactType is the artifactType of the OCI artifacts jobs
+	// publish, and publishedLayerMediaType their layers'.
+	publishedArtifactType   = "application/vnd.reactorcide.artifacts.v1"
+	publishedLayerMediaType = "application/octet-stream"
+)
+
+// ociTagUnsafe matches what can't be in an OCI tag, for filling in
+// destination placeholders.
+var ociTagUnsafe = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// artifactPublishStore is the store support for recording where a job's
+// artifacts were published.
+type artifactPublishStore interface {
+	SetJobPublishedArtifacts(ctx context.Context, jobID string, artifacts models.PublishedArtifacts) error
+}
+
+// publishJobArtifacts pushes a completed job's artifacts to its project's
+// publish targets and records where they went on the job. Publishing
+// never changes the job's status; failures are recorded with the
+// artifacts they're about.
+func (w *CornDogsWorker) publishJobArtifacts(ctx context.Context, job *models.Job, logger *logrus.Entry) {
+	if job.Status != "completed" || job.ProjectID == nil || *job.ProjectID == "" {
+		return
+	}
+	ps, ok := w.config.Store.(artifactPublishStore)
+	if !ok {
+		return
+	}
+	project, err := w.config.Store.GetProjectByID(ctx, *job.ProjectID)
+	if err != nil || len(project.ArtifactPublishTargets) == 0 {
+		return
+	}
+
+	owner := job.UserID
+	if project.UserID != nil && *project.UserID != "" {
+		owner = *project.UserID
+	}
+	publisher := &ArtifactPublisher{
+		ObjectStore: w.config.ObjectStore,
+		OCISources:  w.config.OCISources,
+		GetSecret: func(ref string) (string, error) {
+			jp, ok := w.processor.(*JobProcessor)
+			if !ok {
+				return "", errors.New("secrets are not available on this worker")
+			}
+			return jp.resolveSecretRefForUser(ctx, owner, ref)
+		},
+	}
+	published := publisher.Publish(ctx, project, job)
+	if len(published) == 0 {
+		return
+	}
+	if err := ps.SetJobPublishedArtifacts(ctx, job.JobID, published); err != nil {
+		logger.WithError(err).Error("Failed to record published artifacts")
+		return
+	}
+	job.PublishedArtifacts = published
+	if failed := published.Failed(); len(failed) > 0 {
+		logger.WithField("failed", len(failed)).WithField("published", len(published)-len(failed)).Warn("Some artifacts were not published")
+	} else {
+		logger.WithField("published", len(published)).Info("Published artifacts")
+	}
+}
+
+// ArtifactPublisher pushes jobs' artifacts, from the object store, to
+// their projects' publish targets. See models.ArtifactPublishTarget.
+type ArtifactPublisher struct {
+	ObjectStore objects.ObjectStore
+	// OCISources holds the worker's registry credentials and plain-HTTP
+	// registries, used for OCI targets too.
+	OCISources OCISourceConfig
+	// GetSecret resolves a target's "path:key" credential references.
+	GetSecret func(ref string) (string, error)
+	// HTTPClient makes HTTP and OCI requests; http.DefaultClient if nil.
+	HTTPClient *http.Client
+	// NewS3Store opens an S3 target's bucket; objects.NewS3ObjectStore if
+	// nil.
+	NewS3Store func(cfg objects.S3Config) (objects.ObjectStore, error)
+}
+
+// publishCredentials are a target's resolved credentials.
+type publishCredentials struct {
+	username, password, token string
+}
+
+// Publish pushes job's artifacts to every target of project that applies
+// to it and returns what happened to each artifact, in target order. If
+// the job's artifacts can't be listed, each target gets one entry, without
+// an artifact, with the reason.
+func (p *ArtifactPublisher) Publish(ctx context.Context, project *models.Project, job *models.Job) models.PublishedArtifacts {
+	var targets []models.ArtifactPublishTarget
+	for _, target := range project.ArtifactPublishTargets {
+		if target.AppliesTo(job.Name) {
+			targets = append(targets, target)
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	prefix, artifacts, err := p.listArtifacts(ctx, job)
+	var published models.PublishedArtifacts
+	for _, target := range targets {
+		if err != nil {
+			published = append(published, models.PublishedArtifact{Target: target.Name, Type: target.Type, Error: err.Error()})
+			continue
+		}
+		var matched []objects.ObjectInfo
+		for _, obj := range artifacts {
+			if target.IncludesArtifact(strings.TrimPrefix(obj.Key, prefix)) {
+				matched = append(matched, obj)
+			}
+		}
+		if len(matched) == 0 {
+			continue
+		}
+		published = append(published, p.publishTarget(ctx, target, project, job, prefix, matched)...)
+	}
+	return published
+}
+
+// listArtifacts lists job's artifacts: under its ArtifactsObjectKey, if
+// the worker set one, else under artifacts/{job_id}/. It returns the
+// prefix their keys have before their names.
+func (p *ArtifactPublisher) listArtifacts(ctx context.Context, job *models.Job) (string, []objects.ObjectInfo, error) {
+	if p.ObjectStore == nil {
+		return "", nil, errors.New("artifacts not published: this worker has no object store")
+	}
+	prefix := strings.TrimSuffix(job.ArtifactsObjectKey, "/")
+	if prefix == "" {
+		prefix = "artifacts/" + job.JobID
+	}
+	prefix += "/"
+	artifacts, err := p.ObjectStore.List(ctx, prefix)
+	if err != nil {
+		return "", nil, fmt.Errorf("list artifacts: %w", err)
+	}
+	return prefix, artifacts, nil
+}
+
+// publishTarget pushes the artifacts to one target.
+func (p *ArtifactPublisher) publishTarget(ctx context.Context, target models.ArtifactPublishTarget, project *models.Project, job *models.Job, prefix string, artifacts []objects.ObjectInfo) models.PublishedArtifacts {
+	results := make(models.PublishedArtifacts, len(artifacts))
+	for i, obj := range artifacts {
+		results[i] = models.PublishedArtifact{Target: target.Name, Type: target.Type, Artifact: strings.TrimPrefix(obj.Key, prefix), Size: obj.Size}
+	}
+	failAll := func(err error) models.PublishedArtifacts {
+		for i := range results {
+			results[i].Error = err.Error()
+		}
+		return results
+	}
+
+	creds, err := p.credentials(target)
+	if err != nil {
+		return failAll(err)
+	}
+	switch target.Type {
+	case models.PublishTargetS3:
+		err = p.publishS3(ctx, target, creds, expandPublishDestination(target.Destination, project, job, func(s string) string { return s }), artifacts, results)
+	case models.PublishTargetHTTP:
+		err = p.publishHTTP(ctx, creds, expandPublishDestination(target.Destination, project, job, url.PathEscape), artifacts, results)
+	case models.PublishTargetOCI:
+		err = p.publishOCI(ctx, creds, expandPublishDestination(target.Destination, project, job, func(s string) string { return ociTagUnsafe.ReplaceAllString(s, "-") }), artifacts, results)
+	default:
+		err = fmt.Errorf("unknown target type %q", target.Type)
+	}
+	if err != nil {
+		return failAll(err)
+	}
+	return results
+}
+
+// credentials resolves target's credential references.
+func (p *ArtifactPublisher) credentials(target models.ArtifactPublishTarget) (publishCredentials, error) {
+	var creds publishCredentials
+	for _, c := range []struct {
+		ref   string
+		value *string
+	}{
+		{target.UsernameSecret, &creds.username},
+		{target.PasswordSecret, &creds.password},
+		{target.TokenSecret, &creds.token},
+	} {
+		if c.ref == "" {
+			continue
+		}
+		if p.GetSecret == nil {
+			return creds, errors.New("secrets are not available on this worker")
+		}
+		value, err := p.GetSecret(c.ref)
+		if err != nil {
+			return creds, fmt.Errorf("resolve secret %s: %w", c.ref, err)
+		}
+		*c.value = value
+	}
+	return creds, nil
+}
+
+// expandPublishDestination fills in destination's placeholders from job,
+// each value passed through escape.
+func expandPublishDestination(destination string, project *models.Project, job *models.Job, escape func(string) string) string {
+	commit := derefString(job.CommitSHA)
+	shortCommit := commit
+	if len(shortCommit) > 7 {
+		shortCommit = shortCommit[:7]
+	}
+	return strings.NewReplacer(
+		"{project}", escape(project.Name),
+		"{job_id}", escape(job.JobID),
+		"{job_name}", escape(job.Name),
+		"{commit}", escape(commit),
+		"{short_commit}", escape(shortCommit),
+		"{ref}", escape(derefString(job.SourceRef)),
+	).Replace(destination)
+}
+
+// publishS3 puts each artifact under the s3://bucket/prefix destination.
+func (p *ArtifactPublisher) publishS3(ctx context.Context, target models.ArtifactPublishTarget, creds publishCredentials, destination string, artifacts []objects.ObjectInfo, results models.PublishedArtifacts) error {
+	u, err := url.Parse(destination)
+	if err != nil || u.Scheme != "s3" || u.Host == "" {
+		return fmt.Errorf("invalid s3 destination %q", destination)
+	}
+	newStore := p.NewS3Store
+	if newStore == nil {
+		newStore = func(cfg objects.S3Config) (objects.ObjectStore, error) { return objects.NewS3ObjectStore(cfg) }
+	}
+	bucket, err := newStore(objects.S3Config{
+		Bucket:    u.Host,
+		Region:    target.Region,
+		Endpoint:  target.Endpoint,
+		AccessKey: creds.username,
+		SecretKey: creds.password,
+	})
+	if err != nil {
+		return err
+	}
+	keyPrefix := strings.Trim(u.Path, "/")
+	for i, obj := range artifacts {
+		key := path.Join(keyPrefix, results[i].Artifact)
+		err := p.copyArtifact(ctx, obj, func(content io.Reader) error {
+			return bucket.Put(ctx, key, content, obj.ContentType)
+		})
+		settlePublished(&results[i], "s3://"+u.Host+"/"+key, err)
+	}
+	return nil
+}
+
+// publishHTTP PUTs each artifact to the destination URL with its path
+// appended.
+func (p *ArtifactPublisher) publishHTTP(ctx context.Context, creds publishCredentials, destination string, artifacts []objects.ObjectInfo, results models.PublishedArtifacts) error {
+	base, err := url.Parse(destination)
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return fmt.Errorf("invalid http destination %q", destination)
+	}
+	for i, obj := range artifacts {
+		location := base.JoinPath(strings.Split(results[i].Artifact, "/")...).String()
+		err := p.copyArtifact(ctx, obj, func(content io.Reader) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodPut, location, content)
+			if err != nil {
+				return err
+			}
+			req.ContentLength = obj.Size
+			req.Header.Set("Content-Type", "application/octet-stream")
+			switch {
+			case creds.token != "":
+				req.Header.Set("Authorization", "Bearer "+creds.token)
+			case creds.username != "":
+				req.SetBasicAuth(creds.username, creds.password)
+			}
+			resp, err := p.httpClient().Do(req)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+			if resp.StatusCode < 200 || resp.StatusCode > 299 {
+				return fmt.Errorf("PUT %s: %s", location, resp.Status)
+			}
+			return nil
+		})
+		settlePublished(&results[i], location, err)
+	}
+	return nil
+}
+
+// publishOCI pushes the artifacts as the layers of one OCI artifact,
+// titled by their paths so an OCI source of the reference gets them back
+// as files, and tags it as the destination says.
+func (p *ArtifactPublisher) publishOCI(ctx context.Context, creds publishCredentials, destination string, artifacts []objects.ObjectInfo, results models.PublishedArtifacts) error {
+	ref, err := ParseOCIReference(destination)
+	if err != nil {
+		return err
+	}
+	if ref.Digest != "" {
+		return fmt.Errorf("oci destination %q must name a tag, not a digest", destination)
+	}
+	registry := &ociPuller{client: p.httpClient(), ref: ref, scheme: "https", actions: "pull,push"}
+	if containsFold(p.OCISources.InsecureRegistries, ref.Registry) {
+		registry.scheme = "http"
+	}
+	if creds.username != "" {
+		registry.credentials = base64.StdEncoding.EncodeToString([]byte(creds.username + ":" + creds.password))
+	} else if registry.credentials, err = registryCredentials(p.OCISources.AuthPath, ref.Registry); err != nil {
+		return err
+	}
+
+	manifest := struct {
+		SchemaVersion int             `json:"schemaVersion"`
+		MediaType     string          `json:"mediaType"`
+		ArtifactType  string          `json:"artifactType"`
+		Config        ociDescriptor   `json:"config"`
+		Layers        []ociDescriptor `json:"layers"`
+	}{
+		SchemaVersion: 2,
+		MediaType:     ociManifestMediaType,
+		ArtifactType:  publishedArtifactType,
+		Config:        ociDescriptor{MediaType: ociEmptyConfigMediaType, Digest: ociEmptyConfigDigest, Size: int64(len(ociEmptyConfig))},
+		Layers:        []ociDescriptor{},
+	}
+	if err := registry.pushBlob(ctx, ociEmptyConfigDigest, func() (io.ReadCloser, int64, error) {
+		return io.NopCloser(strings.NewReader(ociEmptyConfig)), int64(len(ociEmptyConfig)), nil
+	}); err != nil {
+		return fmt.Errorf("push config: %w", err)
+	}
+	for i, obj := range artifacts {
+		digest, err := p.artifactDigest(ctx, obj)
+		if err == nil {
+			err = registry.pushBlob(ctx, digest, func() (io.ReadCloser, int64, error) {
+				content, err := p.ObjectStore.Get(ctx, obj.Key)
+				return content, obj.Size, err
+			})
+		}
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		results[i].Digest = digest
+		manifest.Layers = append(manifest.Layers, ociDescriptor{
+			MediaType:   publishedLayerMediaType,
+			Digest:      digest,
+			Size:        obj.Size,
+			Annotations: map[string]string{ociTitleAnnotation: results[i].Artifact},
+		})
+	}
+	if len(manifest.Layers) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	resp, err := registry.send(ctx, http.MethodPut, registry.repositoryURL("/manifests/"+ref.Tag),
+		http.Header{"Content-Type": {ociManifestMediaType}},
+		func() (io.ReadCloser, int64, error) {
+			return io.NopCloser(strings.NewReader(string(body))), int64(len(body)), nil
+		})
+	if err != nil {
+		return fmt.Errorf("push manifest: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("push manifest: %s", resp.Status)
+	}
+	sum := sha256.Sum256(body)
+	location := ref.Registry + "/" + ref.Repository + "@sha256:" + hex.EncodeToString(sum[:])
+	now := time.Now().UTC()
+	for i := range results {
+		if results[i].Error == "" {
+			results[i].Location = location
+			results[i].PublishedAt = &now
+		}
+	}
+	return nil
+}
+
+// pushBlob uploads a blob the repository doesn't have yet, in one
+// request.
+func (p *ociPuller) pushBlob(ctx context.Context, digest string, body func() (io.ReadCloser, int64, error)) error {
+	resp, err := p.send(ctx, http.MethodHead, p.repositoryURL("/blobs/"+digest), nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	resp, err = p.send(ctx, http.MethodPost, p.repositoryURL("/blobs/uploads/"), nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("start upload: %s", resp.Status)
+	}
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || resp.Header.Get("Location") == "" {
+		return errors.New("start upload: no upload location")
+	}
+	upload := resp.Request.URL.ResolveReference(location)
+	query := upload.Query()
+	query.Set("digest", digest)
+	upload.RawQuery = query.Encode()
+
+	resp, err = p.send(ctx, http.MethodPut, upload.String(), http.Header{"Content-Type": {"application/octet-stream"}}, body)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("upload blob %s: %s", digest, resp.Status)
+	}
+	return nil
+}
+
+// artifactDigest reads an artifact through for its sha256 digest.
+func (p *ArtifactPublisher) artifactDigest(ctx context.Context, obj objects.ObjectInfo) (string, error) {
+	content, err := p.ObjectStore.Get(ctx, obj.Key)
+	if err != nil {
+		return "", err
+	}
+	defer content.Close()
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, content); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// copyArtifact streams an artifact from the object store to put.
+func (p *ArtifactPublisher) copyArtifact(ctx context.Context, obj objects.ObjectInfo, put func(io.Reader) error) error {
+	content, err := p.ObjectStore.Get(ctx, obj.Key)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+	return put(content)
+}
+
+func (p *ArtifactPublisher) httpClient() *http.Client {
+	if p.HTTPClient != nil {
+		return p.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// settlePublished records the outcome of publishing an artifact to
+// location.
+func settlePublished(result *models.PublishedArtifact, location string, err error) {
+	if err != nil {
+		result.Error = err.Error()
+		return
+	}
+	now := time.Now().UTC()
+	result.Location = location
+	result.PublishedAt = &now
+}
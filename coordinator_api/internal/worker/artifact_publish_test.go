@@ -0,0 +1,206 @@
+package worker
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// publishFixture is a completed job with two artifacts in an in-memory
+// object store.
+func publishFixture(t *testing.T) (*objects.MemoryObjectStore, *models.Project, *models.Job) {
+	t.Helper()
+	artifacts := objects.NewMemoryObjectStore()
+	ctx := context.Background()
+	require.NoError(t, artifacts.Put(ctx, "artifacts/job-1/dist/app.tar.gz", strings.NewReader("app"), "application/gzip"))
+	require.NoError(t, artifacts.Put(ctx, "artifacts/job-1/coverage.txt", strings.NewReader("coverage"), "text/plain"))
+	commit, ref := "0123456789abcdef", "feature/x"
+	project := &models.Project{ProjectID: "project-1", Name: "api"}
+	job := &models.Job{JobID: "job-1", Name: "build", Status: "completed", CommitSHA: &commit, SourceRef: &ref}
+	return artifacts, project, job
+}
+
+func TestPublishArtifactsToS3(t *testing.T) {
+	artifacts, project, job := publishFixture(t)
+	bucket := objects.NewMemoryObjectStore()
+	var opened objects.S3Config
+	publisher := &ArtifactPublisher{
+		ObjectStore: artifacts,
+		GetSecret: func(ref string) (string, error) {
+			return map[string]string{"aws:id": "AKID", "aws:key": "shh"}[ref], nil
+		},
+		NewS3Store: func(cfg objects.S3Config) (objects.ObjectStore, error) {
+			opened = cfg
+			return bucket, nil
+		},
+	}
+	project.ArtifactPublishTargets = models.ArtifactPublishTargets{{
+		Name:           "dist",
+		Type:           models.PublishTargetS3,
+		Destination:    "s3://releases/{project}/{short_commit}/",
+		Artifacts:      []string{"dist/*"},
+		Region:         "eu-west-1",
+		UsernameSecret: "aws:id",
+		PasswordSecret: "aws:key",
+	}}
+
+	published := publisher.Publish(context.Background(), project, job)
+	require.Len(t, published, 1)
+	assert.Empty(t, published[0].Error)
+	assert.Equal(t, "dist/app.tar.gz", published[0].Artifact)
+	assert.Equal(t, "s3://releases/api/0123456/dist/app.tar.gz", published[0].Location)
+	assert.NotNil(t, published[0].PublishedAt)
+	assert.Equal(t, objects.S3Config{Bucket: "releases", Region: "eu-west-1", AccessKey: "AKID", SecretKey: "shh"}, opened)
+
+	content, err := bucket.Get(context.Background(), "api/0123456/dist/app.tar.gz")
+	require.NoError(t, err)
+	data, _ := io.ReadAll(content)
+	assert.Equal(t, "app", string(data))
+}
+
+func TestPublishArtifactsOverHTTP(t *testing.T) {
+	artifacts, project, job := publishFixture(t)
+	received := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer upload-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.EscapedPath() == "/uploads/feature%2Fx/coverage.txt" {
+			w.WriteHeader(http.StatusInsufficientStorage)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		received[r.URL.EscapedPath()] = string(data)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	publisher := &ArtifactPublisher{
+		ObjectStore: artifacts,
+		GetSecret: func(ref string) (string, error) {
+			if ref != "uploads:token" {
+				return "", errors.New("no such secret")
+			}
+			return "upload-token", nil
+		},
+	}
+	project.ArtifactPublishTargets = models.ArtifactPublishTargets{
+		{Name: "uploads", Type: models.PublishTargetHTTP, Destination: server.URL + "/uploads/{ref}", TokenSecret: "uploads:token"},
+		{Name: "other-jobs", Type: models.PublishTargetHTTP, Destination: server.URL, Jobs: []string{"deploy-*"}},
+		{Name: "missing-secret", Type: models.PublishTargetHTTP, Destination: server.URL, TokenSecret: "gone:token", Artifacts: []string{"coverage.txt"}},
+	}
+
+	published := publisher.Publish(context.Background(), project, job)
+	require.Len(t, published, 3, "other-jobs doesn't apply to build")
+	byArtifact := map[string]models.PublishedArtifact{}
+	for _, artifact := range published[:2] {
+		byArtifact[artifact.Artifact] = artifact
+	}
+	assert.Equal(t, server.URL+"/uploads/feature%2Fx/dist/app.tar.gz", byArtifact["dist/app.tar.gz"].Location)
+	assert.Empty(t, byArtifact["dist/app.tar.gz"].Error)
+	assert.Equal(t, "app", received["/uploads/feature%2Fx/dist/app.tar.gz"])
+	assert.Contains(t, byArtifact["coverage.txt"].Error, "507")
+	assert.Empty(t, byArtifact["coverage.txt"].Location)
+
+	assert.Equal(t, "missing-secret", published[2].Target)
+	assert.Contains(t, published[2].Error, "resolve secret gone:token")
+	assert.Len(t, published.Failed(), 2)
+}
+
+func TestPublishArtifactsToOCI(t *testing.T) {
+	artifacts, project, job := publishFixture(t)
+	reg := newFakeRegistry(t)
+	publisher := &ArtifactPublisher{
+		ObjectStore: artifacts,
+		OCISources:  OCISourceConfig{InsecureRegistries: []string{reg.host()}},
+		GetSecret: func(ref string) (string, error) {
+			return map[string]string{"registry:user": "user", "registry:password": "secret"}[ref], nil
+		},
+	}
+	project.ArtifactPublishTargets = models.ArtifactPublishTargets{{
+		Name:           "bundle",
+		Type:           models.PublishTargetOCI,
+		Destination:    reg.host() + "/ctx:{ref}",
+		UsernameSecret: "registry:user",
+		PasswordSecret: "registry:password",
+	}}
+
+	published := publisher.Publish(context.Background(), project, job)
+	require.Len(t, published, 2)
+	for _, artifact := range published {
+		assert.Empty(t, artifact.Error)
+		assert.True(t, strings.HasPrefix(artifact.Location, reg.host()+"/ctx@sha256:"), artifact.Location)
+		assert.Contains(t, reg.blobs, artifact.Digest)
+	}
+	assert.Contains(t, reg.manifests, "feature-x")
+
+	// What was published pulls back as an OCI source, file for file.
+	authPath := filepath.Join(t.TempDir(), "config.json")
+	auth := fmt.Sprintf(`{"auths":{"%s":{"auth":"%s"}}}`, reg.host(), base64.StdEncoding.EncodeToString([]byte("user:secret")))
+	require.NoError(t, os.WriteFile(authPath, []byte(auth), 0600))
+	jp := &JobProcessor{config: &JobProcessorConfig{OCISources: OCISourceConfig{AuthPath: authPath, InsecureRegistries: []string{reg.host()}}}}
+	st, sourceURL := models.SourceTypeOCI, reg.host()+"/ctx:feature-x"
+	codeDir := t.TempDir()
+	_, err := jp.prepareOCISource(context.Background(), &models.Job{JobID: "consumer", SourceType: &st, SourceURL: &sourceURL}, codeDir)
+	require.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(codeDir, "dist", "app.tar.gz"))
+	require.NoError(t, err)
+	assert.Equal(t, "app", string(data))
+}
+
+// publishRecordingStore is MockStore with a project that has publish
+// targets, recording what SetJobPublishedArtifacts is given.
+type publishRecordingStore struct {
+	MockStore
+	project  *models.Project
+	recorded models.PublishedArtifacts
+}
+
+func (s *publishRecordingStore) GetProjectByID(ctx context.Context, projectID string) (*models.Project, error) {
+	return s.project, nil
+}
+
+func (s *publishRecordingStore) SetJobPublishedArtifacts(ctx context.Context, jobID string, artifacts models.PublishedArtifacts) error {
+	s.recorded = artifacts
+	return nil
+}
+
+func TestPublishJobArtifacts(t *testing.T) {
+	artifacts, project, job := publishFixture(t)
+	projectID := project.ProjectID
+	job.ProjectID = &projectID
+	project.ArtifactPublishTargets = models.ArtifactPublishTargets{{Name: "dist", Type: models.PublishTargetHTTP, Destination: "https://uploads.example.com/{job_id}", TokenSecret: "uploads:token"}}
+	s := &publishRecordingStore{project: project}
+	w := &CornDogsWorker{config: &Config{Store: s, ObjectStore: artifacts}}
+	logger := logrus.NewEntry(logrus.New())
+
+	failed := *job
+	failed.Status = "failed"
+	w.publishJobArtifacts(context.Background(), &failed, logger)
+	assert.Nil(t, s.recorded, "only completed jobs publish")
+
+	// This worker has no job processor to resolve secrets with, so every
+	// artifact records the failure, and the job itself is untouched.
+	w.publishJobArtifacts(context.Background(), job, logger)
+	require.Len(t, s.recorded, 2)
+	assert.Len(t, s.recorded.Failed(), 2)
+	assert.Equal(t, s.recorded, job.PublishedArtifacts)
+	assert.Contains(t, s.recorded[0].Error, "secrets are not available")
+	assert.Equal(t, "completed", job.Status)
+}
@@ -1,6 +1,9 @@
 package worker
 
-import "os"
+import (
+	"os"
+	"strings"
+)
 
 // BuilderConfig holds operator-level configuration for the buildkitd sidecar
 // launched for jobs with CapabilityBuilder. Runners pick the fields they need
@@ -25,6 +28,21 @@ type BuilderConfig struct {
 	// at buildkit's state dir for cross-job layer cache. Empty means no cache
 	// volume — each sidecar starts clean.
 	CacheVolume string
+
+	// CacheRegistry is an optional registry repository prefix (e.g.
+	// "registry.example.com/reactorcide-cache") for BuildKit layer caches
+	// shared by all workers. Each project gets its own repository under it;
+	// see CacheRef. Empty means no remote build cache.
+	CacheRegistry string
+}
+
+// CacheRef returns the registry cache ref for a job's BuildKit layer cache,
+// in namespace (see jobCacheNamespace), or "" without a CacheRegistry.
+func (c BuilderConfig) CacheRef(namespace string) string {
+	if c.CacheRegistry == "" {
+		return ""
+	}
+	return strings.TrimSuffix(c.CacheRegistry, "/") + "/" + strings.ToLower(namespace) + ":buildcache"
 }
 
 // LoadBuilderConfig resolves BuilderConfig from environment variables. This is
@@ -35,6 +53,7 @@ type BuilderConfig struct {
 //   - REACTORCIDE_BUILDER_CONFIG_PATH     (optional, no default)
 //   - REACTORCIDE_BUILDER_REGISTRY_AUTH_PATH (optional, no default)
 //   - REACTORCIDE_BUILDER_CACHE_VOLUME    (optional, no default)
+//   - REACTORCIDE_BUILDER_CACHE_REGISTRY  (optional, no default)
 func LoadBuilderConfig() BuilderConfig {
 	image := os.Getenv("REACTORCIDE_BUILDER_IMAGE")
 	if image == "" {
@@ -45,6 +64,7 @@ func LoadBuilderConfig() BuilderConfig {
 		ConfigPath:       os.Getenv("REACTORCIDE_BUILDER_CONFIG_PATH"),
 		RegistryAuthPath: os.Getenv("REACTORCIDE_BUILDER_REGISTRY_AUTH_PATH"),
 		CacheVolume:      os.Getenv("REACTORCIDE_BUILDER_CACHE_VOLUME"),
+		CacheRegistry:    os.Getenv("REACTORCIDE_BUILDER_CACHE_REGISTRY"),
 	}
 }
 
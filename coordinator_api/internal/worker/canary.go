@@ -0,0 +1,44 @@
+package worker
+
+import (
+	"math/rand"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// canaryRoll picks a number in [0, 100) deciding whether a job is a
+// canary. Tests replace it.
+var canaryRoll = func() int { return rand.Intn(100) }
+
+// routeProjectCanary sends Percent of a project's new jobs to its canary
+// (see models.ProjectCanary): the canary's runner image replaces the
+// project's default image, though not an image the job chose itself, and
+// the canary's queue replaces whatever queue the job would use. It reports
+// whether the job was routed, and marks it Canary if so. Jobs triggered by
+// a canary job are already marked, and always follow it.
+func routeProjectCanary(project *models.Project, job *models.Job) bool {
+	canary := project.Canary
+	if canary == nil || (!job.Canary && canaryRoll() >= canary.Percent) {
+		return false
+	}
+	routed := job.Canary
+	if canary.RunnerImage != "" && (job.RunnerImage == "" || job.RunnerImage == project.DefaultRunnerImage) {
+		job.RunnerImage = canary.RunnerImage
+		routed = true
+	}
+	if canary.QueueName != "" {
+		job.QueueName = canary.QueueName
+		job.QueueRouted = true
+		routed = true
+	}
+	if !routed {
+		return false
+	}
+	job.Canary = true
+	logging.Log.WithField("project_id", project.ProjectID).
+		WithField("runner_image", job.RunnerImage).
+		WithField("queue", job.QueueName).
+		Debug("Routed job to the project's canary")
+	return true
+}
@@ -0,0 +1,42 @@
+package worker
+
+import (
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteProjectCanary(t *testing.T) {
+	roll := 50
+	defer func(orig func() int) { canaryRoll = orig }(canaryRoll)
+	canaryRoll = func() int { return roll }
+
+	project := &models.Project{
+		ProjectID:          "project-1",
+		DefaultRunnerImage: "runner:1",
+		Canary:             &models.ProjectCanary{Percent: 10, RunnerImage: "runner:2"},
+	}
+
+	job := &models.Job{RunnerImage: "runner:1"}
+	assert.False(t, routeProjectCanary(project, job), "rolls outside the percentage aren't canaries")
+	assert.Equal(t, "runner:1", job.RunnerImage)
+
+	roll = 9
+	job = &models.Job{RunnerImage: "runner:1"}
+	assert.True(t, routeProjectCanary(project, job))
+	assert.Equal(t, "runner:2", job.RunnerImage)
+	assert.True(t, job.Canary)
+
+	job = &models.Job{RunnerImage: "custom:1"}
+	assert.False(t, routeProjectCanary(project, job), "an image the job chose is kept")
+	assert.Equal(t, "custom:1", job.RunnerImage)
+	assert.False(t, job.Canary)
+
+	roll = 99
+	project.Canary.QueueName = "canary-workers"
+	job = &models.Job{RunnerImage: "runner:2", Canary: true}
+	assert.True(t, routeProjectCanary(project, job), "jobs triggered by a canary follow it")
+	assert.Equal(t, "canary-workers", job.QueueName)
+	assert.True(t, job.QueueRouted)
+}
@@ -0,0 +1,199 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// MaxChildJobs caps how many children ListChildJobs loads. Roll-ups count
+// every child in the store instead (see childJobCounter), so a fan-out wider
+// than this still waits on, and reports, all of its children.
+const MaxChildJobs = 1000
+
+// ListChildJobs returns the jobs spawned by parentJobID (newest first),
+// capped at MaxChildJobs.
+func ListChildJobs(ctx context.Context, s store.Store, parentJobID string) ([]models.Job, error) {
+	return s.ListJobs(ctx, map[string]interface{}{"parent_job_id": parentJobID}, MaxChildJobs, 0)
+}
+
+// childJobCounter is the narrow store capability that counts a parent's
+// children by status without loading them (see
+// internal/store/postgres_store.PostgresDbStore.CountChildJobsByStatus).
+// Stores without it are summarized from the children ListChildJobs loaded.
+type childJobCounter interface {
+	CountChildJobsByStatus(ctx context.Context, parentJobID string) (map[string]int, error)
+}
+
+// SettleChildFanIn applies the AwaitChildren fan-in rules after job reaches
+// a terminal status:
+//
+//   - If job has AwaitChildren set, completed successfully, and spawned
+//     children (or a workflow), it is re-opened as "running" so its status
+//     keeps covering the pipeline it started.
+//   - Every held job whose children are now all terminal — job itself, if
+//     its children already finished before it was held, and then each
+//     ancestor up the ParentJobID chain — is finalized with the children's
+//     aggregate result.
+//
+// Returns every job whose status this call changed, in the order the
+// changes were made, so the caller can publish them.
+func (tp *TriggerProcessor) SettleChildFanIn(ctx context.Context, job *models.Job) ([]*models.Job, error) {
+	var changed []*models.Job
+
+	held, err := tp.holdForChildren(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	current := job
+	if held != nil {
+		changed = append(changed, held)
+		current = held
+		// Children can finish before the parent's own hold lands; check now
+		// instead of waiting for a child completion that already happened.
+		done, err := tp.finishHeldJob(ctx, held)
+		if err != nil || done == nil {
+			return changed, err
+		}
+		changed = append(changed, done)
+		current = done
+	}
+
+	for current.IsCompleted() && current.ParentJobID != nil && *current.ParentJobID != "" {
+		parent, err := tp.store.GetJobByID(ctx, *current.ParentJobID)
+		if err != nil {
+			return changed, fmt.Errorf("failed to load parent job %s: %w", *current.ParentJobID, err)
+		}
+		done, err := tp.finishHeldJob(ctx, parent)
+		if err != nil || done == nil {
+			return changed, err
+		}
+		changed = append(changed, done)
+		current = done
+	}
+	return changed, nil
+}
+
+// holdForChildren re-opens a just-completed AwaitChildren job as "running".
+// Returns nil when the job doesn't qualify: it didn't opt in, didn't
+// succeed, or has nothing to wait for.
+func (tp *TriggerProcessor) holdForChildren(ctx context.Context, job *models.Job) (*models.Job, error) {
+	if !job.AwaitChildren || job.Status != "completed" {
+		return nil, nil
+	}
+	if derefString(job.WorkflowID) == "" {
+		children, err := ListChildJobs(ctx, tp.store, job.JobID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list child jobs: %w", err)
+		}
+		if len(children) == 0 {
+			return nil, nil
+		}
+	}
+
+	return tp.transitionJob(ctx, job, []string{"completed"}, func(j *models.Job) {
+		j.Status = "running"
+		j.CompletedAt = nil
+	})
+}
+
+// finishHeldJob lands a held job on its children's aggregate result once
+// none of them are still running. Returns nil when the job isn't held or
+// its children haven't all finished.
+func (tp *TriggerProcessor) finishHeldJob(ctx context.Context, job *models.Job) (*models.Job, error) {
+	if !job.IsAwaitingChildren() {
+		return nil, nil
+	}
+	children, err := ListChildJobs(ctx, tp.store, job.JobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list child jobs: %w", err)
+	}
+	summary, err := tp.SummarizeChildJobs(ctx, job, children)
+	if err != nil {
+		return nil, err
+	}
+	if summary.Status == models.ChildStatusRunning {
+		return nil, nil
+	}
+
+	status := "completed"
+	lastError := ""
+	if summary.Status == models.ChildStatusFailed {
+		status = "failed"
+		lastError = fmt.Sprintf("%d of %d child jobs did not succeed", summary.Failed, summary.Total)
+	}
+	now := time.Now().UTC()
+	return tp.transitionJob(ctx, job, []string{"running"}, func(j *models.Job) {
+		j.Status = status
+		j.LastError = lastError
+		j.CompletedAt = &now
+	})
+}
+
+// SummarizeChildJobs rolls up job's children. The counts cover every child
+// when the store can count them, and otherwise the children passed in (as
+// loaded by ListChildJobs). When job owns a workflow, the workflow's status
+// decides the aggregate instead of the job rows: nodes still waiting on
+// dependencies have no job yet, so the jobs alone would report "succeeded"
+// before the workflow is actually done.
+func (tp *TriggerProcessor) SummarizeChildJobs(ctx context.Context, job *models.Job, children []models.Job) (models.ChildJobsSummary, error) {
+	summary := models.AggregateChildJobs(children)
+	if cs, ok := tp.store.(childJobCounter); ok {
+		counts, err := cs.CountChildJobsByStatus(ctx, job.JobID)
+		if err != nil {
+			return summary, fmt.Errorf("failed to count child jobs: %w", err)
+		}
+		summary = models.AggregateChildJobCounts(counts)
+	}
+
+	if ws, err := tp.workflowStore(); err == nil && derefString(job.WorkflowID) != "" {
+		wf, err := ws.GetWorkflowInstance(ctx, *job.WorkflowID)
+		if err != nil {
+			return summary, fmt.Errorf("failed to load workflow %s: %w", *job.WorkflowID, err)
+		}
+		if derefString(wf.ParentJobID) == job.JobID {
+			summary.Status = childStatusFromWorkflow(wf.Status)
+		}
+	}
+	return summary, nil
+}
+
+func childStatusFromWorkflow(status string) string {
+	switch status {
+	case "success", "skipped":
+		return models.ChildStatusSucceeded
+	case "failed", "cancelled":
+		return models.ChildStatusFailed
+	default:
+		return models.ChildStatusRunning
+	}
+}
+
+// transitionJob applies a status change through the guarded store update
+// when available, so two children finishing at once can't both finalize
+// the same parent. Returns nil when the job had already moved on.
+func (tp *TriggerProcessor) transitionJob(ctx context.Context, job *models.Job, fromStatuses []string, apply func(*models.Job)) (*models.Job, error) {
+	if gs, ok := tp.store.(guardedJobStore); ok {
+		updated, matched, err := gs.UpdateJobStatusGuarded(ctx, job.JobID, fromStatuses, apply)
+		if err != nil || !matched {
+			return nil, err
+		}
+		return updated, nil
+	}
+
+	matched := false
+	for _, s := range fromStatuses {
+		matched = matched || job.Status == s
+	}
+	if !matched {
+		return nil, nil
+	}
+	apply(job)
+	if err := tp.store.UpdateJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to update job %s: %w", job.JobID, err)
+	}
+	return job, nil
+}
@@ -0,0 +1,202 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// fanInStore is MockStore backed by an in-memory job table, so parent/child
+// lookups see each other's writes.
+type fanInStore struct {
+	MockStore
+	jobs map[string]*models.Job
+}
+
+func newFanInStore(jobs ...*models.Job) *fanInStore {
+	s := &fanInStore{jobs: map[string]*models.Job{}}
+	for _, j := range jobs {
+		s.jobs[j.JobID] = j
+	}
+	return s
+}
+
+func (s *fanInStore) GetJobByID(ctx context.Context, jobID string) (*models.Job, error) {
+	j, ok := s.jobs[jobID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	cp := *j
+	return &cp, nil
+}
+
+func (s *fanInStore) UpdateJob(ctx context.Context, job *models.Job) error {
+	cp := *job
+	s.jobs[job.JobID] = &cp
+	return nil
+}
+
+func (s *fanInStore) ListJobs(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]models.Job, error) {
+	var out []models.Job
+	for _, j := range s.jobs {
+		if j.ParentJobID != nil && *j.ParentJobID == filters["parent_job_id"] {
+			out = append(out, *j)
+		}
+	}
+	sort.Slice(out, func(i, k int) bool { return out[i].JobID < out[k].JobID })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// countingFanInStore is fanInStore with the uncapped child count the
+// postgres store provides.
+type countingFanInStore struct {
+	*fanInStore
+}
+
+func (s countingFanInStore) CountChildJobsByStatus(ctx context.Context, parentJobID string) (map[string]int, error) {
+	counts := map[string]int{}
+	for _, j := range s.jobs {
+		if j.ParentJobID != nil && *j.ParentJobID == parentJobID {
+			counts[j.Status]++
+		}
+	}
+	return counts, nil
+}
+
+func fanInJobs(childStatuses ...string) (*models.Job, []*models.Job) {
+	exitCode := 0
+	parentID := "parent"
+	parent := &models.Job{JobID: parentID, Status: "completed", ExitCode: &exitCode, AwaitChildren: true}
+	var children []*models.Job
+	for i, status := range childStatuses {
+		children = append(children, &models.Job{JobID: "child-" + string(rune('a'+i)), Status: status, ParentJobID: &parentID})
+	}
+	return parent, children
+}
+
+func TestSettleChildFanIn_HoldsParentUntilChildrenFinish(t *testing.T) {
+	parent, children := fanInJobs("completed", "running")
+	s := newFanInStore(append(children, parent)...)
+	tp := NewTriggerProcessor(s, nil)
+
+	changed, err := tp.SettleChildFanIn(context.Background(), parent)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changed) != 1 || changed[0].Status != "running" || changed[0].CompletedAt != nil {
+		t.Fatalf("expected parent to be held running, got %+v", changed)
+	}
+
+	child := s.jobs["child-b"]
+	child.Status = "completed"
+	changed, err = tp.SettleChildFanIn(context.Background(), child)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changed) != 1 || changed[0].JobID != "parent" || changed[0].Status != "completed" {
+		t.Fatalf("expected parent to complete with its last child, got %+v", changed)
+	}
+	if s.jobs["parent"].CompletedAt == nil {
+		t.Error("expected parent CompletedAt to be set")
+	}
+}
+
+func TestSettleChildFanIn_ParentFailsWithChild(t *testing.T) {
+	parent, children := fanInJobs("running")
+	s := newFanInStore(append(children, parent)...)
+	tp := NewTriggerProcessor(s, nil)
+
+	if _, err := tp.SettleChildFanIn(context.Background(), parent); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	child := s.jobs["child-a"]
+	child.Status = "timeout"
+	if _, err := tp.SettleChildFanIn(context.Background(), child); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := s.jobs["parent"]; got.Status != "failed" || got.LastError == "" {
+		t.Errorf("expected parent to fail with a reason, got status=%q last_error=%q", got.Status, got.LastError)
+	}
+}
+
+func TestSettleChildFanIn_ChildrenAlreadyDone(t *testing.T) {
+	parent, children := fanInJobs("completed")
+	s := newFanInStore(append(children, parent)...)
+	tp := NewTriggerProcessor(s, nil)
+
+	changed, err := tp.SettleChildFanIn(context.Background(), parent)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changed) != 2 || changed[1].Status != "completed" {
+		t.Fatalf("expected hold then immediate completion, got %+v", changed)
+	}
+}
+
+func TestSettleChildFanIn_NoChildrenOrNotOptedIn(t *testing.T) {
+	parent, _ := fanInJobs()
+	tp := NewTriggerProcessor(newFanInStore(parent), nil)
+	if changed, _ := tp.SettleChildFanIn(context.Background(), parent); len(changed) != 0 {
+		t.Errorf("expected no hold without children, got %+v", changed)
+	}
+
+	parent, children := fanInJobs("running")
+	parent.AwaitChildren = false
+	tp = NewTriggerProcessor(newFanInStore(append(children, parent)...), nil)
+	if changed, _ := tp.SettleChildFanIn(context.Background(), parent); len(changed) != 0 {
+		t.Errorf("expected no hold without AwaitChildren, got %+v", changed)
+	}
+}
+
+func TestSettleChildFanIn_CountsChildrenBeyondListCap(t *testing.T) {
+	statuses := make([]string, MaxChildJobs+1)
+	for i := range statuses {
+		statuses[i] = "completed"
+	}
+	// The running child sorts last, outside the MaxChildJobs that are listed.
+	statuses[MaxChildJobs] = "running"
+	parent, children := fanInJobs(statuses...)
+	for i, child := range children {
+		child.JobID = fmt.Sprintf("child-%05d", i)
+	}
+	s := countingFanInStore{newFanInStore(append(children, parent)...)}
+	tp := NewTriggerProcessor(s, nil)
+
+	changed, err := tp.SettleChildFanIn(context.Background(), parent)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changed) != 1 || changed[0].Status != "running" {
+		t.Fatalf("expected parent to stay held on the unlisted child, got %+v", changed)
+	}
+
+	held := s.jobs[parent.JobID]
+	summary, err := tp.SummarizeChildJobs(context.Background(), held, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Total != MaxChildJobs+1 || summary.Running != 1 || summary.Status != models.ChildStatusRunning {
+		t.Errorf("expected every child in the summary, got %+v", summary)
+	}
+
+	last := s.jobs[children[MaxChildJobs].JobID]
+	last.Status = "failed"
+	changed, err = tp.SettleChildFanIn(context.Background(), last)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changed) != 1 || changed[0].Status != "failed" {
+		t.Fatalf("expected parent to fail on the unlisted child, got %+v", changed)
+	}
+	if want := fmt.Sprintf("1 of %d child jobs did not succeed", MaxChildJobs+1); changed[0].LastError != want {
+		t.Errorf("expected last error %q, got %q", want, changed[0].LastError)
+	}
+}
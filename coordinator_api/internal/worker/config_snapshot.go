@@ -0,0 +1,144 @@
+package worker
+
+import (
+	"context"
+	"strings"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// jobConfigSnapshotStore is the narrow store capability for recording the
+// configuration jobs run with. See
+// postgres_store/job_config_snapshot_operations.go.
+type jobConfigSnapshotStore interface {
+	SaveJobConfigSnapshot(ctx context.Context, snapshot *models.JobConfigSnapshot) (bool, error)
+}
+
+// snapshotEnv copies a job's environment for its config snapshot, with
+// the values of secretNames and the worker's API token masked. It's taken
+// before secret resolution, so ${secret:...} references stay references.
+func snapshotEnv(env map[string]string, secretNames []string) map[string]string {
+	masked := make(map[string]string, len(env))
+	for name, value := range env {
+		masked[name] = value
+	}
+	for _, name := range secretNames {
+		if _, ok := masked[name]; ok {
+			masked[name] = models.ResolvedConfigMasked
+		}
+	}
+	if _, ok := masked["REACTORCIDE_API_TOKEN"]; ok {
+		masked["REACTORCIDE_API_TOKEN"] = models.ResolvedConfigMasked
+	}
+	return masked
+}
+
+// pinnedImageDigest returns the digest an image reference pins
+// ("repo@sha256:..."), if it pins one.
+func pinnedImageDigest(image string) string {
+	if _, digest, ok := strings.Cut(image, "@"); ok {
+		return digest
+	}
+	return ""
+}
+
+// resolvedJobConfig is the config snapshot of job, about to run as config
+// with env, the snapshot's copy of its environment (see snapshotEnv).
+func (jp *JobProcessor) resolvedJobConfig(ctx context.Context, job *models.Job, config *JobConfig, env map[string]string) models.ResolvedJobConfig {
+	resolved := models.ResolvedJobConfig{
+		JobName:        job.Name,
+		Image:          config.Image,
+		ImageDigest:    pinnedImageDigest(config.Image),
+		Command:        config.Command,
+		WorkingDir:     config.WorkingDir,
+		Env:            env,
+		TimeoutSeconds: config.TimeoutSeconds,
+		Priority:       job.Priority,
+		QueueName:      job.QueueName,
+		Capabilities:   config.Capabilities,
+		RunAsUser:      config.RunAsUser,
+		TargetOS:       job.TargetOS,
+		TargetArch:     job.TargetArch,
+		Environment:    job.Environment,
+		Canary:         job.Canary,
+	}
+	if job.WorkerID != nil {
+		resolved.WorkerID = *job.WorkerID
+	}
+	if digester, ok := jp.runner.(ImageDigester); ok && resolved.ImageDigest == "" {
+		digest, err := digester.ImageDigest(ctx, config.Image)
+		if err != nil {
+			logging.Log.WithError(err).WithField("job_id", job.JobID).Warn("Failed to resolve job image digest")
+		}
+		resolved.ImageDigest = digest
+	}
+
+	if job.SourceType != nil {
+		resolved.Source = &models.ResolvedJobSource{
+			Type: string(*job.SourceType),
+			URL:  derefString(job.SourceURL),
+			Ref:  derefString(job.SourceRef),
+			Path: derefString(job.SourcePath),
+		}
+	}
+	if job.CISourceType != nil {
+		resolved.CISource = &models.ResolvedJobSource{
+			Type: string(*job.CISourceType),
+			URL:  derefString(job.CISourceURL),
+			Ref:  derefString(job.CISourceRef),
+		}
+	}
+
+	if job.EventMetadata != nil || job.ParentJobID != nil || job.UpstreamJobID != nil || job.WorkflowID != nil {
+		resolved.Trigger = &models.ResolvedJobTrigger{
+			EventMetadata:    job.EventMetadata,
+			ParentJobID:      job.ParentJobID,
+			UpstreamJobID:    job.UpstreamJobID,
+			WorkflowID:       job.WorkflowID,
+			WorkflowRunID:    job.WorkflowRunID,
+			WorkflowNodeName: job.WorkflowNodeName,
+		}
+	}
+
+	if job.ProjectID != nil && *job.ProjectID != "" {
+		project, err := jp.store.GetProjectByID(ctx, *job.ProjectID)
+		if err != nil || project == nil {
+			logging.Log.WithError(err).WithField("job_id", job.JobID).Warn("Failed to load project for job config snapshot")
+			return resolved
+		}
+		resolved.Project = &models.ResolvedProjectDefaults{
+			ProjectID:             project.ProjectID,
+			Name:                  project.Name,
+			GroupID:               project.GroupID,
+			DefaultRunnerImage:    project.DefaultRunnerImage,
+			DefaultJobCommand:     project.DefaultJobCommand,
+			DefaultTimeoutSeconds: project.DefaultTimeoutSeconds,
+			DefaultQueueName:      project.DefaultQueueName,
+			DefaultCISourceType:   project.DefaultCISourceType,
+			DefaultCISourceURL:    project.DefaultCISourceURL,
+			DefaultCISourceRef:    project.DefaultCISourceRef,
+			Canary:                project.Canary,
+		}
+		resolved.Template = jobTemplateFor(project, job)
+	}
+	return resolved
+}
+
+// recordJobConfigSnapshot stores the config snapshot of job, which has
+// just started as config. A redelivered job keeps the snapshot of its
+// first start. It is best-effort: stores without snapshot support are
+// skipped and failures are logged, since a snapshot must not fail the job.
+func (jp *JobProcessor) recordJobConfigSnapshot(ctx context.Context, job *models.Job, config *JobConfig, env map[string]string) {
+	recorder, ok := jp.store.(jobConfigSnapshotStore)
+	if !ok {
+		return
+	}
+	snapshot := &models.JobConfigSnapshot{
+		JobID:  job.JobID,
+		Config: jp.resolvedJobConfig(ctx, job, config, env),
+	}
+	if _, err := recorder.SaveJobConfigSnapshot(ctx, snapshot); err != nil {
+		logging.Log.WithError(err).WithField("job_id", job.JobID).Warn("Failed to record job config snapshot")
+	}
+}
@@ -0,0 +1,121 @@
+package worker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// snapshotStore records SaveJobConfigSnapshot calls on top of MockStore
+// and serves one project.
+type snapshotStore struct {
+	MockStore
+	project *models.Project
+	saved   []*models.JobConfigSnapshot
+}
+
+func (s *snapshotStore) GetProjectByID(ctx context.Context, projectID string) (*models.Project, error) {
+	return s.project, nil
+}
+
+func (s *snapshotStore) SaveJobConfigSnapshot(ctx context.Context, snapshot *models.JobConfigSnapshot) (bool, error) {
+	s.saved = append(s.saved, snapshot)
+	return true, nil
+}
+
+// digestingJobRunner is a fakeJobRunner that implements ImageDigester.
+type digestingJobRunner struct {
+	*fakeJobRunner
+	digest string
+}
+
+func (d *digestingJobRunner) ImageDigest(ctx context.Context, image string) (string, error) {
+	return d.digest, nil
+}
+
+var _ ImageDigester = (*digestingJobRunner)(nil)
+
+func TestSnapshotEnv(t *testing.T) {
+	env := map[string]string{
+		"GREETING":              "hello",
+		"DB_PASSWORD":           "${secret:db:password}",
+		"RENDERED_TOKEN":        "plaintext-from-env-file",
+		"REACTORCIDE_API_TOKEN": "worker-token",
+	}
+	got := snapshotEnv(env, []string{"RENDERED_TOKEN", "NOT_SET"})
+
+	assert.Equal(t, "hello", got["GREETING"])
+	assert.Equal(t, "${secret:db:password}", got["DB_PASSWORD"], "secret references stay references")
+	assert.Equal(t, models.ResolvedConfigMasked, got["RENDERED_TOKEN"])
+	assert.Equal(t, models.ResolvedConfigMasked, got["REACTORCIDE_API_TOKEN"])
+	assert.NotContains(t, got, "NOT_SET")
+	assert.Equal(t, "worker-token", env["REACTORCIDE_API_TOKEN"], "the job's own env is untouched")
+}
+
+func TestRecordJobConfigSnapshot(t *testing.T) {
+	projectID := "project-1"
+	sourceType := models.SourceTypeGit
+	sourceURL := "https://example.com/repo.git"
+	template := models.JobTemplate{Name: "test", Command: "make test", Image: "golang:1.22"}
+	s := &snapshotStore{project: &models.Project{
+		ProjectID:          projectID,
+		Name:               "repo",
+		DefaultRunnerImage: "runner:1",
+		DefaultQueueName:   "reactorcide-jobs",
+		JobTemplates:       models.JobTemplates{{Name: "lint", Command: "make lint"}, template},
+	}}
+	base := &models.Job{Description: "push to main", Priority: 5, SourceType: &sourceType, SourceURL: &sourceURL}
+	job, err := BuildTemplateJob(template, base)
+	require.NoError(t, err)
+	job.JobID = "job-1"
+	job.ProjectID = &projectID
+	job.EventMetadata = models.JSONB{"event_type": "push"}
+
+	jp := NewJobProcessor(s, &digestingJobRunner{fakeJobRunner: newFakeJobRunner(), digest: "sha256:abc"}, false)
+	config := jp.buildJobConfig(job, t.TempDir())
+	jp.recordJobConfigSnapshot(context.Background(), job, config, snapshotEnv(config.Env, nil))
+
+	require.Len(t, s.saved, 1)
+	got := s.saved[0]
+	assert.Equal(t, "job-1", got.JobID)
+	assert.Equal(t, "golang:1.22", got.Config.Image)
+	assert.Equal(t, "sha256:abc", got.Config.ImageDigest)
+	assert.Equal(t, config.Command, got.Config.Command)
+	assert.Equal(t, 5, got.Config.Priority)
+	require.NotNil(t, got.Config.Source)
+	assert.Equal(t, sourceURL, got.Config.Source.URL)
+	require.NotNil(t, got.Config.Project)
+	assert.Equal(t, "runner:1", got.Config.Project.DefaultRunnerImage)
+	require.NotNil(t, got.Config.Template, "the template is found by the job's description")
+	assert.Equal(t, "make test", got.Config.Template.Command)
+	require.NotNil(t, got.Config.Trigger)
+	assert.Equal(t, "push", got.Config.Trigger.EventMetadata["event_type"])
+
+	// A pinned image reference is its own digest.
+	pinned := "app@sha256:def"
+	job.ContainerImage = &pinned
+	config = jp.buildJobConfig(job, t.TempDir())
+	jp.recordJobConfigSnapshot(context.Background(), job, config, nil)
+	require.Len(t, s.saved, 2)
+	assert.Equal(t, "sha256:def", s.saved[1].Config.ImageDigest)
+
+	// Stores without snapshot support are skipped.
+	NewJobProcessor(&MockStore{}, newFakeJobRunner(), false).recordJobConfigSnapshot(context.Background(), job, config, nil)
+}
+
+func TestJobTemplateFor(t *testing.T) {
+	project := &models.Project{JobTemplates: models.JobTemplates{{Name: "test", Command: "make test"}}}
+	job, err := BuildTemplateJob(project.JobTemplates[0], &models.Job{Description: "push"})
+	require.NoError(t, err)
+	require.NotNil(t, jobTemplateFor(project, job))
+
+	assert.Nil(t, jobTemplateFor(project, &models.Job{Name: "test", Description: "push"}), "not a template job")
+	renamed := *job
+	renamed.Name = "other"
+	assert.Nil(t, jobTemplateFor(project, &renamed), "the description must name the job's own template")
+	assert.Nil(t, jobTemplateFor(&models.Project{}, job), "the template has since been removed")
+}
@@ -516,6 +516,13 @@ func (cr *ContainerdRunner) pullImage(ctx context.Context, imageName string) err
 		return nil
 	}
 
+	return cr.PullImage(ctx, imageName)
+}
+
+// PullImage pulls the image, whether or not it exists locally, so a
+// moved tag is picked up. See ImagePuller.
+func (cr *ContainerdRunner) PullImage(ctx context.Context, imageName string) error {
+	logger := logging.Log.WithField("image", imageName)
 	logger.Info("Pulling container image")
 
 	cmd := exec.CommandContext(ctx, nerdctlBinary, "--namespace", containerdNamespace, "pull", imageName)
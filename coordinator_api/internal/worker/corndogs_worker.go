@@ -12,6 +12,7 @@ import (
 	"github.com/catalystcommunity/app-utils-go/logging"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
 	pb "github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs/v1alpha1"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/hooks"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/metrics"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/pubsub"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/secrets"
@@ -101,6 +102,11 @@ type CornDogsWorker struct {
 	triggerProcessor *TriggerProcessor
 	statusUpdater    vcs.JobStatusUpdaterInterface
 	publisher        *pubsub.Publisher
+	debugSessions    *DebugSessions
+	imagePrePuller   *ImagePrePuller
+	gitMirrors       *GitMirrors
+	control          *WorkerControl
+	diagnostics      *Diagnostics
 	wg               sync.WaitGroup
 	workerPool       chan struct{}
 }
@@ -143,6 +149,14 @@ func NewCornDogsWorker(config *Config, corndogsClient corndogs.ClientInterface,
 		config.CancelGrace = 60 * time.Second
 	}
 
+	// Jobs record, and avoid_workers matches, the worker ID, so it must be
+	// stable across restarts: default to the host name.
+	if config.WorkerID == "" {
+		if hostname, err := os.Hostname(); err == nil {
+			config.WorkerID = hostname
+		}
+	}
+
 	// Create job runner
 	runner, err := NewJobRunner(config.ContainerRuntime)
 	if err != nil {
@@ -173,17 +187,32 @@ func NewCornDogsWorker(config *Config, corndogsClient corndogs.ClientInterface,
 		}
 	}
 
+	debugConfig := config.DebugSessions
+	debugConfig.WorkerID = config.WorkerID
+	debugSessions := NewDebugSessions(config.Store, runner, debugConfig)
+	gitMirrors := NewGitMirrors(config.GitMirrors)
+
 	// Create job processor with configuration. Publisher is wired in after
 	// construction via SetPublisher, so callers that don't want WS live
 	// updates can still use the worker unchanged.
 	processor := NewJobProcessorWithConfig(config.Store, runner, config.DryRun, &JobProcessorConfig{
-		ObjectStore:        config.ObjectStore,
-		LogChunkInterval:   config.LogChunkInterval,
-		HeartbeatInterval:  config.HeartbeatInterval,
-		HeartbeatTimeout:   config.HeartbeatTimeout,
-		CancelGrace:        config.CancelGrace,
-		SecretsKeyManager:  keyManager,
-		SecretsStorageType: secretsStorageType,
+		ObjectStore:            config.ObjectStore,
+		LogChunkInterval:       config.LogChunkInterval,
+		HeartbeatInterval:      config.HeartbeatInterval,
+		HeartbeatTimeout:       config.HeartbeatTimeout,
+		CancelGrace:            config.CancelGrace,
+		ResourceSampleInterval: config.ResourceSampleInterval,
+		OnLogUpdate:            logActivityRecorder(config.Store),
+		SecretsKeyManager:      keyManager,
+		SecretsStorageType:     secretsStorageType,
+		DebugSessions:          debugSessions,
+		Platform:               config.Platform,
+		SharedWorkspaces:       NewSharedWorkspaces(config.ObjectStore, config.SharedWorkspaces),
+		JobCaches:              NewJobCaches(config.ObjectStore, config.JobCaches),
+		LogUploader:            config.LogUploader,
+		DynamicSecrets:         config.DynamicSecrets,
+		OCISources:             config.OCISources,
+		GitMirrors:             gitMirrors,
 	})
 
 	// Create trigger processor for handling eval job output
@@ -192,14 +221,28 @@ func NewCornDogsWorker(config *Config, corndogsClient corndogs.ClientInterface,
 		triggerProc.SetStatusUpdater(statusUpdater)
 	}
 
-	return &CornDogsWorker{
+	w := &CornDogsWorker{
 		config:           config,
 		corndogsClient:   corndogsClient,
 		processor:        processor,
 		triggerProcessor: triggerProc,
 		statusUpdater:    statusUpdater,
+		debugSessions:    debugSessions,
+		imagePrePuller:   NewImagePrePuller(config.Store, runner, config.WorkerID),
+		gitMirrors:       gitMirrors,
 		workerPool:       make(chan struct{}, config.Concurrency),
 	}
+	w.control = NewWorkerControl(config.Store, models.Worker{
+		WorkerID:    config.WorkerID,
+		QueueName:   config.QueueName,
+		Platform:    config.Platform.String(),
+		Concurrency: config.Concurrency,
+	}, func() int { return len(w.workerPool) })
+	if w.control != nil {
+		w.diagnostics = NewDiagnostics(runner)
+		w.control.diagnostics = w.diagnostics
+	}
+	return w
 }
 
 // NewCornDogsWorkerWithProcessor creates a new worker with a custom processor (for testing).
@@ -227,6 +270,19 @@ func (w *CornDogsWorker) Start(ctx context.Context) error {
 	// Set active workers metric
 	metrics.SetWorkersActive(w.config.QueueName, float64(w.config.Concurrency))
 
+	// Register before polling, so a quarantined worker that restarts takes
+	// no job, then keep reporting in for drain and quarantine requests.
+	if w.control != nil {
+		if err := w.control.sync(ctx); err != nil {
+			logging.Log.WithError(err).Warn("Failed to register worker; accepting jobs until it registers")
+		}
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			w.control.Start(ctx)
+		}()
+	}
+
 	// Start worker goroutines
 	for i := 0; i < w.config.Concurrency; i++ {
 		w.wg.Add(1)
@@ -241,6 +297,34 @@ func (w *CornDogsWorker) Start(ctx context.Context) error {
 	w.wg.Add(1)
 	go w.runCancellingReaper(ctx)
 
+	// Serve debug sessions for failed jobs held by DebugOnFailure, and end
+	// them all on shutdown so their containers and workspaces don't leak.
+	if w.debugSessions != nil {
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			w.debugSessions.Start(ctx)
+		}()
+	}
+
+	// Keep the pre-pull runner images warm on this worker.
+	if w.imagePrePuller != nil {
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			w.imagePrePuller.Start(ctx)
+		}()
+	}
+
+	// Keep the git mirrors of recently built repositories fresh.
+	if w.gitMirrors != nil {
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			w.gitMirrors.Start(ctx)
+		}()
+	}
+
 	// Wait for all goroutines to finish
 	w.wg.Wait()
 
@@ -264,8 +348,11 @@ func (w *CornDogsWorker) worker(ctx context.Context, workerID int) {
 			logger.Info("Worker stopping due to context cancellation")
 			return
 		default:
-			// Try to get the next task from Corndogs
-			w.processNextTask(ctx, workerID)
+			// Try to get the next task from Corndogs, unless the worker
+			// is drained or quarantined
+			if w.control.Accepting() {
+				w.processNextTask(ctx, workerID)
+			}
 
 			// Small delay between polls to avoid hammering Corndogs
 			select {
@@ -312,7 +399,7 @@ func (w *CornDogsWorker) processNextTask(ctx context.Context, workerID int) {
 	metrics.RecordCornDogsTaskPoll(w.config.QueueName, true)
 
 	// Parse the task payload
-	payload, err := corndogs.ParseTaskPayload(task)
+	payload, err := corndogs.OpenTaskPayload(task, w.config.PayloadOpener)
 	if err != nil {
 		logger.WithError(err).Error("Failed to parse task payload")
 		// Update task state to failed
@@ -370,14 +457,53 @@ func (w *CornDogsWorker) processNextTask(ctx context.Context, workerID int) {
 		return
 	}
 
+	// Honor the job's scheduling hints: a worker the job avoids, or a cold
+	// one while a warm worker may still pick it up, puts the task back.
+	// The poll delay that follows keeps this worker from taking it again
+	// at once.
+	now := time.Now().UTC()
+	affinity, run := w.affinityDecision(jobCtx, job, now, logger)
+	if affinity != "" {
+		metrics.RecordJobAffinity(w.config.QueueName, affinity)
+	}
+	if !run {
+		logger.WithField("affinity", affinity).Debug("Passing job over for another worker")
+		w.requeueTask(jobCtx, task.Uuid, task.CurrentState)
+		return
+	}
+
+	// A job claimed again while "running" was lost by the worker that ran
+	// it; it runs again from the start, unless it has used up its delivery
+	// attempts and is set aside for an admin.
+	claimFrom := []string{"submitted", "queued"}
+	if w.lostDelivery(jobCtx, job) {
+		previous := ""
+		if job.WorkerID != nil {
+			previous = *job.WorkerID
+		}
+		logger.WithField("previous_worker_id", previous).Warn("Job redelivered after its worker stopped heartbeating")
+		recordDeliveryFailure(jobCtx, w.config.Store, job, previous, models.DeliveryFailureWorkerLost, "task redelivered after the worker stopped heartbeating")
+		claimFrom = []string{"running"}
+	}
+	if w.deliveriesExhausted(job) {
+		w.deadletterJob(jobCtx, job, task, claimFrom, logger)
+		return
+	}
+
 	// Update job status to running. Guarded so a cancel that races in
 	// between the IsCancelling() check above and this write — a narrow but
 	// real window, since both are separate store round trips — can't be
 	// silently clobbered back to "running" (see Finding 1c/1d).
-	now := time.Now().UTC()
-	running, matched := w.finalizeJobGuarded(jobCtx, job, []string{"submitted", "queued"}, func(j *models.Job) {
+	workerName := w.config.WorkerID
+	running, matched := w.finalizeJobGuarded(jobCtx, job, claimFrom, func(j *models.Job) {
 		j.Status = "running"
 		j.StartedAt = &now
+		j.DeliveryAttempts++
+		if workerName != "" {
+			j.WorkerID = &workerName
+		} else {
+			j.WorkerID = nil
+		}
 	}, logger)
 	if !matched {
 		// Raced: the job was cancelled between our IsCancelling() check and
@@ -394,6 +520,15 @@ func (w *CornDogsWorker) processNextTask(ctx context.Context, workerID int) {
 	}
 	job = running
 	w.publisher.PublishJobUpdate(jobCtx, job.JobID, job.Status, now.Format(time.RFC3339Nano))
+	defer w.diagnostics.track(job, now)()
+
+	// A panic from here on would otherwise take the worker down and leave
+	// the job to be redelivered; count it against the job instead.
+	defer func() {
+		if r := recover(); r != nil {
+			w.releasePanickedJob(jobCtx, job, task, r, logger)
+		}
+	}()
 	if w.triggerProcessor != nil {
 		if workflowErr := w.triggerProcessor.ProcessWorkflowJobStarted(jobCtx, job); workflowErr != nil {
 			logger.WithError(workflowErr).Error("Failed to process workflow job start")
@@ -422,11 +557,41 @@ func (w *CornDogsWorker) processNextTask(ctx context.Context, workerID int) {
 	result := w.processor.ProcessJobWithContext(jobCtx, job, execCtx)
 	duration := time.Since(startTime).Seconds()
 
-	// Ensure workspace cleanup happens after trigger processing
-	if result.WorkspaceDir != "" {
+	// Ensure workspace cleanup happens after trigger processing,
+	// unless a debug session now owns it.
+	if result.WorkspaceDir != "" && !result.WorkspaceRetained {
 		defer os.RemoveAll(result.WorkspaceDir)
 	}
 
+	// The job's test reports are kept for its project's pass rates. A
+	// command that failed only because quarantined tests did is forgiven.
+	commandFailed := result.ExitCode != 0
+	var tests testReport
+	if !result.Cancelled && result.WorkspaceDir != "" {
+		var testErr error
+		if tests, testErr = checkTestReports(jobCtx, w.config.Store, job, result.WorkspaceDir, time.Now()); testErr != nil {
+			logger.WithError(testErr).Warn("Failed to check test reports")
+		}
+		if commandFailed && tests.onlyQuarantinedFailed() {
+			logger.WithField("tests", tests.Quarantined).Info("Only quarantined tests failed; not failing the job")
+			commandFailed = false
+		}
+	}
+
+	// A command that exits 0 still fails the project's quality gates if
+	// the outputs it reported break them.
+	var gateResults models.GateResults
+	if !result.Cancelled && !commandFailed && result.WorkspaceDir != "" {
+		var gateErr error
+		if gateResults, gateErr = checkQualityGates(jobCtx, w.config.Store, job, result.WorkspaceDir); gateErr != nil {
+			logger.WithError(gateErr).Warn("Failed to check quality gates")
+		}
+	}
+	if quarantined := tests.gateResult(); quarantined != nil {
+		gateResults = append(gateResults, *quarantined)
+	}
+	failedGates := gateResults.Failed()
+
 	// Record job processing metrics. A runner-initiated stop (cancel/kill)
 	// is neither a normal completion nor a failure of the job's own logic,
 	// so it gets its own metrics/status bucket rather than being derived
@@ -436,7 +601,7 @@ func (w *CornDogsWorker) processNextTask(ctx context.Context, workerID int) {
 	switch {
 	case result.Cancelled:
 		status = "cancelled"
-	case result.ExitCode != 0:
+	case commandFailed, len(failedGates) > 0:
 		status = "failed"
 	}
 	metrics.RecordJobProcessed(w.config.QueueName, status, workerIDStr, duration)
@@ -445,6 +610,7 @@ func (w *CornDogsWorker) processNextTask(ctx context.Context, workerID int) {
 	completedAt := time.Now().UTC()
 	job.CompletedAt = &completedAt
 	job.ExitCode = &result.ExitCode
+	job.GateResults = gateResults
 
 	switch {
 	case result.Cancelled:
@@ -466,7 +632,7 @@ func (w *CornDogsWorker) processNextTask(ctx context.Context, workerID int) {
 		if _, err := w.corndogsClient.CancelTask(jobCtx, task.Uuid, "processing"); err != nil {
 			logger.WithError(err).Warn("Failed to cancel task in Corndogs after job cancellation")
 		}
-	case result.ExitCode == 0:
+	case !commandFailed && len(failedGates) == 0:
 		job.Status = "completed"
 
 		// Complete the task in Corndogs
@@ -474,6 +640,10 @@ func (w *CornDogsWorker) processNextTask(ctx context.Context, workerID int) {
 		if err != nil {
 			logger.WithError(err).Error("Failed to complete task in Corndogs")
 		}
+	case !commandFailed:
+		job.Status = "failed"
+		job.LastError = gateFailureMessage(failedGates)
+		w.updateTaskFailed(jobCtx, task.Uuid, "processing", job.LastError)
 	default:
 		job.Status = "failed"
 		// Update task state to failed
@@ -498,6 +668,7 @@ func (w *CornDogsWorker) processNextTask(ctx context.Context, workerID int) {
 		j.LastError = job.LastError
 		j.CompletedAt = job.CompletedAt
 		j.ExitCode = job.ExitCode
+		j.GateResults = job.GateResults
 		if job.LogsObjectKey != "" {
 			j.LogsObjectKey = job.LogsObjectKey
 		}
@@ -521,6 +692,12 @@ func (w *CornDogsWorker) processNextTask(ctx context.Context, workerID int) {
 		job = finalized
 	}
 	w.publisher.PublishJobUpdate(jobCtx, job.JobID, job.Status, completedAt.Format(time.RFC3339Nano))
+	recordJobUsage(jobCtx, w.config.Store, w.config.CostRates, job, result.Usage, completedAt)
+	if w.config.ResourceMetrics {
+		recordJobResources(jobCtx, w.config.Store, w.config.QueueName, job, result.Usage)
+	}
+	settlePreviewTeardown(jobCtx, w.config.Store, job, logger)
+	w.publishJobArtifacts(jobCtx, job, logger)
 
 	if w.triggerProcessor != nil && result.WorkspaceDir != "" {
 		workflowOK := true
@@ -534,6 +711,9 @@ func (w *CornDogsWorker) processNextTask(ctx context.Context, workerID int) {
 			}
 		}
 	}
+	if w.triggerProcessor != nil {
+		job = w.settleChildFanIn(jobCtx, job, logger)
+	}
 
 	// Update VCS commit status with bounded retry. Transient GitHub failures
 	// (network blips, rate limits, 5xx) shouldn't drop the terminal status —
@@ -548,6 +728,24 @@ func (w *CornDogsWorker) processNextTask(ctx context.Context, workerID int) {
 	logger.WithField("status", job.Status).WithField("exit_code", result.ExitCode).Info("Task processing completed")
 }
 
+// jobLogActivityStore is the narrow store capability for stamping a job's
+// last log output time, which the coordinator's stuck-job monitor reads.
+type jobLogActivityStore interface {
+	TouchJobLogActivity(ctx context.Context, jobID string, at time.Time) error
+}
+
+// logActivityRecorder returns a JobProcessorConfig.OnLogUpdate callback that
+// stamps last_log_at on every shipped chunk, or nil when s can't.
+func logActivityRecorder(s store.Store) func(jobID, objectKey string, bytesWritten int64) error {
+	touch, ok := s.(jobLogActivityStore)
+	if !ok {
+		return nil
+	}
+	return func(jobID, objectKey string, bytesWritten int64) error {
+		return touch.TouchJobLogActivity(context.Background(), jobID, time.Now().UTC())
+	}
+}
+
 // finalizeJobGuarded performs a race-safe status write for job (JobID is
 // authoritative; apply mutates whichever copy of the row actually gets
 // persisted). It prefers a guarded (race-safe) store transition when the
@@ -563,13 +761,20 @@ func (w *CornDogsWorker) processNextTask(ctx context.Context, workerID int) {
 // The fallback (non-guarded) path always reports matched=true, matching
 // its pre-existing best-effort semantics; a store error either way returns
 // (nil, false).
+//
+// A write that lands runs the post_status_change hooks (see
+// hooks.NotifyStatusChange).
 func (w *CornDogsWorker) finalizeJobGuarded(ctx context.Context, job *models.Job, fromStatuses []string, apply func(*models.Job), logger *logrus.Entry) (*models.Job, bool) {
+	oldStatus := job.Status
 	if gs, ok := w.config.Store.(guardedJobStore); ok {
 		updated, matched, err := gs.UpdateJobStatusGuarded(ctx, job.JobID, fromStatuses, apply)
 		if err != nil {
 			logger.WithError(err).Error("Guarded job status update failed")
 			return nil, false
 		}
+		if matched {
+			hooks.NotifyStatusChange(updated, oldStatus)
+		}
 		return updated, matched
 	}
 
@@ -578,9 +783,48 @@ func (w *CornDogsWorker) finalizeJobGuarded(ctx context.Context, job *models.Job
 		logger.WithError(err).Error("Failed to update job result")
 		return nil, false
 	}
+	hooks.NotifyStatusChange(job, oldStatus)
 	return job, true
 }
 
+// settleChildFanIn runs TriggerProcessor.SettleChildFanIn for a job that
+// just reached a terminal status and publishes every status it changed.
+// Returns job's own latest state: still "running" if it is now held on its
+// children. Ancestors finalized along the way get their VCS status pushed
+// and their downstream triggers fired here, since no worker is executing
+// them to do it; so does job, unless it's held. Finished tag pipelines
+// publish their release (see settleRelease).
+func (w *CornDogsWorker) settleChildFanIn(ctx context.Context, job *models.Job, logger *logrus.Entry) *models.Job {
+	changed, err := w.triggerProcessor.SettleChildFanIn(ctx, job)
+	if err != nil {
+		logger.WithError(err).Error("Failed to settle child job fan-in")
+	}
+	for _, c := range changed {
+		w.publisher.PublishJobUpdate(ctx, c.JobID, c.Status, time.Now().UTC().Format(time.RFC3339Nano))
+		if c.JobID == job.JobID {
+			job = c
+			continue
+		}
+		logger.WithField("parent_job_id", c.JobID).WithField("parent_status", c.Status).Info("Parent job finished with its children")
+		if w.statusUpdater != nil && derefString(c.WorkflowID) == "" {
+			w.updateVCSStatusWithRetry(ctx, c)
+		}
+		w.fireDownstreamTriggers(ctx, c, logger)
+		w.settleRelease(ctx, c, logger)
+	}
+	w.fireDownstreamTriggers(ctx, job, logger)
+	w.settleRelease(ctx, job, logger)
+	return job
+}
+
+// fireDownstreamTriggers starts the downstream projects job's completion
+// triggers. Jobs still held on their children fire nothing yet.
+func (w *CornDogsWorker) fireDownstreamTriggers(ctx context.Context, job *models.Job, logger *logrus.Entry) {
+	if _, err := w.triggerProcessor.FireDownstreamTriggers(ctx, job); err != nil {
+		logger.WithError(err).Error("Failed to fire downstream triggers")
+	}
+}
+
 // finalizeClaimedCancellingJob closes the claim-time cancel race (Finding
 // 1c/1d): a job can be "cancelling" by the time this worker has claimed its
 // Corndogs task, either because internal/jobcontrol.transitionJob lost its
@@ -614,6 +858,9 @@ func (w *CornDogsWorker) finalizeClaimedCancellingJob(ctx context.Context, job *
 		status = finalized.Status
 	}
 	w.publisher.PublishJobUpdate(ctx, job.JobID, status, now.Format(time.RFC3339Nano))
+	if w.triggerProcessor != nil && finalized != nil {
+		w.settleChildFanIn(ctx, finalized, logger)
+	}
 	logger.Info("Job was already cancelling when claimed; finalized without executing")
 }
 
@@ -681,6 +928,9 @@ func (w *CornDogsWorker) reapStaleCancellingJobs(ctx context.Context) {
 			status = finalized.Status
 		}
 		w.publisher.PublishJobUpdate(ctx, job.JobID, status, now.Format(time.RFC3339Nano))
+		if w.triggerProcessor != nil && finalized != nil {
+			w.settleChildFanIn(ctx, finalized, logger)
+		}
 		logger.Warn("Reaped orphaned cancelling job with no active worker")
 	}
 }
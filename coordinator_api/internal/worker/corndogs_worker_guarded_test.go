@@ -105,7 +105,7 @@ func (g *guardedMockStore) CreateProject(ctx context.Context, project *models.Pr
 func (g *guardedMockStore) GetProjectByID(ctx context.Context, projectID string) (*models.Project, error) {
 	return nil, nil
 }
-func (g *guardedMockStore) GetProjectByRepoURL(ctx context.Context, repoURL string) (*models.Project, error) {
+func (g *guardedMockStore) GetProjectsByRepoURL(ctx context.Context, repoURL string) ([]models.Project, error) {
 	return nil, nil
 }
 func (g *guardedMockStore) UpdateProject(ctx context.Context, project *models.Project) error {
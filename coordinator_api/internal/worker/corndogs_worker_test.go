@@ -138,7 +138,7 @@ func (m *MockStore) CreateProject(ctx context.Context, project *models.Project)
 func (m *MockStore) GetProjectByID(ctx context.Context, projectID string) (*models.Project, error) {
 	return nil, nil
 }
-func (m *MockStore) GetProjectByRepoURL(ctx context.Context, repoURL string) (*models.Project, error) {
+func (m *MockStore) GetProjectsByRepoURL(ctx context.Context, repoURL string) ([]models.Project, error) {
 	return nil, nil
 }
 func (m *MockStore) UpdateProject(ctx context.Context, project *models.Project) error { return nil }
@@ -0,0 +1,120 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	pb "github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs/v1alpha1"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/metrics"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/sirupsen/logrus"
+)
+
+// deliveryFailureStore is the narrow store capability for recording claims
+// of a job that ended without a result. See
+// postgres_store/deadletter_operations.go.
+type deliveryFailureStore interface {
+	RecordJobDeliveryFailure(ctx context.Context, failure *models.JobDeliveryFailure) error
+}
+
+// workerLookupStore is the narrow store capability for checking on the
+// worker that last claimed a job. See postgres_store/worker_operations.go.
+type workerLookupStore interface {
+	GetWorker(ctx context.Context, workerID string) (*models.Worker, error)
+}
+
+// recordDeliveryFailure records that workerID's claim of job ended without
+// a result. Best-effort: the failure history is for admins, and the
+// attempt itself is already counted on the job.
+func recordDeliveryFailure(ctx context.Context, st store.Store, job *models.Job, workerID, reason, detail string) {
+	metrics.RecordJobDeliveryFailure(job.QueueName, reason)
+	fs, ok := st.(deliveryFailureStore)
+	if !ok {
+		return
+	}
+	err := fs.RecordJobDeliveryFailure(ctx, &models.JobDeliveryFailure{
+		JobID:    job.JobID,
+		Attempt:  job.DeliveryAttempts,
+		WorkerID: workerID,
+		Reason:   reason,
+		Detail:   detail,
+		FailedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		logging.Log.WithError(err).WithField("job_id", job.JobID).Warn("Failed to record job delivery failure")
+	}
+}
+
+// lostDelivery reports whether job, claimed again while "running", was
+// lost by the worker that claimed it before: Corndogs only hands its task
+// out again once that worker stopped heartbeating. A job held open on its
+// children has no worker, and a previous worker that is another one and
+// still reporting in keeps the job.
+func (w *CornDogsWorker) lostDelivery(ctx context.Context, job *models.Job) bool {
+	if !job.IsRunning() || job.IsAwaitingChildren() {
+		return false
+	}
+	if job.WorkerID == nil || *job.WorkerID == "" || *job.WorkerID == w.config.WorkerID {
+		return true
+	}
+	ws, ok := w.config.Store.(workerLookupStore)
+	if !ok {
+		return true
+	}
+	previous, err := ws.GetWorker(ctx, *job.WorkerID)
+	if err != nil {
+		return true
+	}
+	return time.Since(previous.LastSeenAt) > workerSeenWithin
+}
+
+// deliveriesExhausted reports whether job has used up its delivery
+// attempts. A zero MaxDeliveryAttempts never sets a job aside.
+func (w *CornDogsWorker) deliveriesExhausted(job *models.Job) bool {
+	return w.config.MaxDeliveryAttempts > 0 && job.DeliveryAttempts >= w.config.MaxDeliveryAttempts
+}
+
+// deadletterJob sets job aside instead of running it again, and fails its
+// task so Corndogs stops handing it out.
+func (w *CornDogsWorker) deadletterJob(ctx context.Context, job *models.Job, task *pb.Task, fromStatuses []string, logger *logrus.Entry) {
+	now := time.Now().UTC()
+	lastError := fmt.Sprintf("deadlettered after %d delivery attempts", job.DeliveryAttempts)
+	updated, matched := w.finalizeJobGuarded(ctx, job, fromStatuses, func(j *models.Job) {
+		j.Status = models.JobStatusDeadlettered
+		j.DeadletteredAt = &now
+		j.LastError = lastError
+		j.WorkerID = nil
+	}, logger)
+	w.updateTaskFailed(ctx, task.Uuid, task.CurrentState, lastError)
+	if !matched {
+		logger.Warn("Job changed before it could be deadlettered; leaving it")
+		return
+	}
+	metrics.RecordJobDeadlettered(w.config.QueueName)
+	w.publisher.PublishJobUpdate(ctx, updated.JobID, updated.Status, now.Format(time.RFC3339Nano))
+	logger.WithField("delivery_attempts", updated.DeliveryAttempts).Warn("Job deadlettered")
+}
+
+// releasePanickedJob puts back a job whose processing panicked, so the
+// next claim runs it again or, once its attempts are used up, deadletters
+// it. A job cancelled meanwhile is left to the cancelling-job reaper.
+func (w *CornDogsWorker) releasePanickedJob(ctx context.Context, job *models.Job, task *pb.Task, recovered interface{}, logger *logrus.Entry) {
+	logger.WithField("panic", recovered).WithField("stack", string(debug.Stack())).Error("Panic while processing job")
+	recordDeliveryFailure(ctx, w.config.Store, job, w.config.WorkerID, models.DeliveryFailureWorkerPanic, fmt.Sprint(recovered))
+
+	released, matched := w.finalizeJobGuarded(ctx, job, []string{"running"}, func(j *models.Job) {
+		j.Status = "submitted"
+		j.WorkerID = nil
+		j.StartedAt = nil
+	}, logger)
+	if !matched {
+		w.updateTaskFailed(ctx, task.Uuid, "processing", "Worker panicked")
+		return
+	}
+	w.requeueTask(ctx, task.Uuid, "processing")
+	w.publisher.PublishJobUpdate(ctx, released.JobID, released.Status, time.Now().UTC().Format(time.RFC3339Nano))
+}
@@ -0,0 +1,144 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	pb "github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs/v1alpha1"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// deliveryMockStore adds delivery failures and registered workers to
+// guardedMockStore.
+type deliveryMockStore struct {
+	*guardedMockStore
+	failures []models.JobDeliveryFailure
+	workers  map[string]*models.Worker
+}
+
+func (d *deliveryMockStore) RecordJobDeliveryFailure(ctx context.Context, failure *models.JobDeliveryFailure) error {
+	d.failures = append(d.failures, *failure)
+	return nil
+}
+
+func (d *deliveryMockStore) GetWorker(ctx context.Context, workerID string) (*models.Worker, error) {
+	if w, ok := d.workers[workerID]; ok {
+		return w, nil
+	}
+	return nil, store.ErrNotFound
+}
+
+// newDeliveryFixture returns a worker allowing three delivery attempts,
+// with one task for job waiting in Corndogs.
+func newDeliveryFixture(job *models.Job, processor *MockJobProcessor) (*CornDogsWorker, *deliveryMockStore, *corndogs.MockClient) {
+	st := &deliveryMockStore{guardedMockStore: newGuardedMockStore(job), workers: map[string]*models.Worker{}}
+	cd := corndogs.NewMockClient()
+	payload, _ := json.Marshal(&corndogs.TaskPayload{JobID: job.JobID, JobType: "run"})
+	cd.GetNextTaskFunc = func(ctx context.Context, state string, timeout int64) (*pb.Task, error) {
+		return &pb.Task{Uuid: "task-id", CurrentState: "submitted-working", Payload: payload}, nil
+	}
+	config := &Config{QueueName: "test-queue", PollInterval: 100 * time.Millisecond, Concurrency: 1, Store: st, WorkerID: "worker-b", MaxDeliveryAttempts: 3}
+	return NewCornDogsWorkerWithProcessor(config, cd, processor, nil, nil), st, cd
+}
+
+func lastTaskState(cd *corndogs.MockClient) string {
+	if len(cd.UpdateTaskCalls) == 0 {
+		return ""
+	}
+	return cd.UpdateTaskCalls[len(cd.UpdateTaskCalls)-1].NewState
+}
+
+func TestCornDogsWorker_Deadletter_CountsClaims(t *testing.T) {
+	job := &models.Job{JobID: "fresh-job", Status: "submitted", JobCommand: "echo hi"}
+	w, st, _ := newDeliveryFixture(job, &MockJobProcessor{})
+
+	w.processNextTask(context.Background(), 0)
+
+	stored, err := st.GetJobByID(context.Background(), job.JobID)
+	require.NoError(t, err)
+	assert.Equal(t, "completed", stored.Status)
+	assert.Equal(t, 1, stored.DeliveryAttempts)
+	assert.Empty(t, st.failures)
+}
+
+func TestCornDogsWorker_Deadletter_LostDeliveryRunsAgain(t *testing.T) {
+	lost := "worker-a"
+	job := &models.Job{JobID: "lost-job", Status: "running", JobCommand: "echo hi", WorkerID: &lost, DeliveryAttempts: 1}
+	processor := &MockJobProcessor{}
+	w, st, _ := newDeliveryFixture(job, processor)
+	st.workers[lost] = &models.Worker{WorkerID: lost, LastSeenAt: time.Now().Add(-time.Hour)}
+
+	w.processNextTask(context.Background(), 0)
+
+	require.Len(t, processor.ProcessJobCalls, 1)
+	stored, err := st.GetJobByID(context.Background(), job.JobID)
+	require.NoError(t, err)
+	assert.Equal(t, "completed", stored.Status)
+	assert.Equal(t, 2, stored.DeliveryAttempts)
+	require.NotNil(t, stored.WorkerID)
+	assert.Equal(t, "worker-b", *stored.WorkerID)
+	require.Len(t, st.failures, 1)
+	assert.Equal(t, models.DeliveryFailureWorkerLost, st.failures[0].Reason)
+	assert.Equal(t, lost, st.failures[0].WorkerID)
+	assert.Equal(t, 1, st.failures[0].Attempt)
+}
+
+func TestCornDogsWorker_Deadletter_LiveWorkerKeepsJob(t *testing.T) {
+	busy := "worker-a"
+	job := &models.Job{JobID: "busy-job", Status: "running", JobCommand: "echo hi", WorkerID: &busy, DeliveryAttempts: 1}
+	processor := &MockJobProcessor{}
+	w, st, _ := newDeliveryFixture(job, processor)
+	st.workers[busy] = &models.Worker{WorkerID: busy, LastSeenAt: time.Now()}
+
+	w.processNextTask(context.Background(), 0)
+
+	assert.Empty(t, processor.ProcessJobCalls)
+	assert.Empty(t, st.failures)
+	stored, err := st.GetJobByID(context.Background(), job.JobID)
+	require.NoError(t, err)
+	assert.Equal(t, "running", stored.Status)
+	assert.Equal(t, 1, stored.DeliveryAttempts)
+}
+
+func TestCornDogsWorker_Deadletter_SetsAsideExhaustedJob(t *testing.T) {
+	job := &models.Job{JobID: "exhausted-job", Status: "running", JobCommand: "echo hi", DeliveryAttempts: 3}
+	processor := &MockJobProcessor{}
+	w, st, cd := newDeliveryFixture(job, processor)
+
+	w.processNextTask(context.Background(), 0)
+
+	assert.Empty(t, processor.ProcessJobCalls)
+	stored, err := st.GetJobByID(context.Background(), job.JobID)
+	require.NoError(t, err)
+	assert.Equal(t, models.JobStatusDeadlettered, stored.Status)
+	assert.NotNil(t, stored.DeadletteredAt)
+	assert.Equal(t, "deadlettered after 3 delivery attempts", stored.LastError)
+	assert.Equal(t, "failed", lastTaskState(cd))
+	require.Len(t, st.failures, 1, "the lost delivery is still recorded")
+}
+
+func TestCornDogsWorker_Deadletter_PanicRequeuesJob(t *testing.T) {
+	job := &models.Job{JobID: "panic-job", Status: "submitted", JobCommand: "echo hi"}
+	processor := &MockJobProcessor{ProcessJobFunc: func(ctx context.Context, job *models.Job) *JobResult {
+		panic("runner exploded")
+	}}
+	w, st, cd := newDeliveryFixture(job, processor)
+
+	require.NotPanics(t, func() { w.processNextTask(context.Background(), 0) })
+
+	stored, err := st.GetJobByID(context.Background(), job.JobID)
+	require.NoError(t, err)
+	assert.Equal(t, "submitted", stored.Status)
+	assert.Nil(t, stored.WorkerID)
+	assert.Equal(t, 1, stored.DeliveryAttempts)
+	assert.Equal(t, "submitted", lastTaskState(cd))
+	require.Len(t, st.failures, 1)
+	assert.Equal(t, models.DeliveryFailureWorkerPanic, st.failures[0].Reason)
+	assert.Equal(t, "runner exploded", st.failures[0].Detail)
+}
@@ -0,0 +1,356 @@
+package worker
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultDebugSessionTTL is how long a failed job's environment is kept
+// when DebugSessionConfig.TTL is unset.
+const DefaultDebugSessionTTL = 30 * time.Minute
+
+// debugReapInterval is how often sessions are checked for expiry or for
+// being closed through the coordinator.
+const debugReapInterval = 10 * time.Second
+
+// debugShell starts bash where the image has it and sh otherwise.
+var debugShell = []string{"/bin/sh", "-c", "command -v bash >/dev/null 2>&1 && exec bash -l || exec sh -l"}
+
+// debugSessionStore is the narrow store capability behind debug sessions.
+// See postgres_store/debug_session_operations.go.
+type debugSessionStore interface {
+	CreateDebugSession(ctx context.Context, session *models.DebugSession) error
+	GetDebugSession(ctx context.Context, sessionID string) (*models.DebugSession, error)
+	EndDebugSession(ctx context.Context, sessionID, status string) (bool, error)
+}
+
+// DebugSessionConfig configures the worker's debug-on-failure support.
+type DebugSessionConfig struct {
+	// ListenAddr is where the exec bridge listens (e.g. ":7070").
+	ListenAddr string
+	// AdvertiseURL is the bridge's base URL as the coordinator reaches it
+	// (e.g. "http://worker-0.workers:7070").
+	AdvertiseURL string
+	// TTL bounds how long a failed job's environment is kept (default:
+	// DefaultDebugSessionTTL).
+	TTL time.Duration
+	// WorkerID is recorded on each session.
+	WorkerID string
+}
+
+// DebugSessions keeps failed jobs that asked for it (Job.DebugOnFailure)
+// alive in debug containers and serves the exec bridge the coordinator
+// connects developers' terminals to. Each session owns its debug container
+// and the job's workspace until it expires or is closed.
+type DebugSessions struct {
+	config  DebugSessionConfig
+	runner  JobRunner
+	debug   DebugRunner
+	store   debugSessionStore
+	logger  *logrus.Entry
+	closing bool
+
+	mu       sync.Mutex
+	sessions map[string]*debugSession
+
+	upgrader websocket.Upgrader
+}
+
+// debugSession is one live debug container.
+type debugSession struct {
+	id           string
+	jobID        string
+	debugID      string
+	workspaceDir string
+	token        string
+	expiresAt    time.Time
+}
+
+// NewDebugSessions returns nil, disabling debug on failure, unless the
+// runner implements DebugRunner, the store can record sessions, and the
+// bridge has a listen address and an advertised URL.
+func NewDebugSessions(s store.Store, runner JobRunner, config DebugSessionConfig) *DebugSessions {
+	logger := logging.Log.WithField("component", "debug_sessions")
+	if config.ListenAddr == "" || config.AdvertiseURL == "" {
+		return nil
+	}
+	debug, ok := runner.(DebugRunner)
+	if !ok {
+		logger.Warn("Container runtime does not support debug sessions; debug on failure disabled")
+		return nil
+	}
+	ds, ok := s.(debugSessionStore)
+	if !ok {
+		logger.Warn("Store does not support debug sessions; debug on failure disabled")
+		return nil
+	}
+	if config.TTL <= 0 {
+		config.TTL = DefaultDebugSessionTTL
+	}
+	return &DebugSessions{
+		config:   config,
+		runner:   runner,
+		debug:    debug,
+		store:    ds,
+		logger:   logger,
+		sessions: make(map[string]*debugSession),
+		upgrader: websocket.Upgrader{ReadBufferSize: 4096, WriteBufferSize: 4096},
+	}
+}
+
+// Hold starts a debug session for a failed job whose container has exited
+// but not been cleaned up yet. It reports whether the session took
+// ownership of workspaceDir, in which case the caller must not remove it.
+func (d *DebugSessions) Hold(ctx context.Context, job *models.Job, containerID string, env map[string]string, workspaceDir string) bool {
+	logger := d.logger.WithField("job_id", job.JobID)
+	d.mu.Lock()
+	closing := d.closing
+	d.mu.Unlock()
+	if closing {
+		return false
+	}
+
+	debugID, err := d.debug.StartDebugContainer(ctx, containerID, debugEnv(env), d.config.TTL)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to start debug container")
+		return false
+	}
+
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		logger.WithError(err).Warn("Failed to generate debug session token")
+		d.cleanupContainer(debugID)
+		return false
+	}
+	session := &debugSession{
+		id:           uuid.New().String(),
+		jobID:        job.JobID,
+		debugID:      debugID,
+		workspaceDir: workspaceDir,
+		token:        hex.EncodeToString(token),
+		expiresAt:    time.Now().UTC().Add(d.config.TTL),
+	}
+	if err := d.store.CreateDebugSession(ctx, &models.DebugSession{
+		SessionID:   session.id,
+		JobID:       job.JobID,
+		WorkerID:    d.config.WorkerID,
+		BridgeURL:   d.config.AdvertiseURL,
+		BridgeToken: session.token,
+		ExpiresAt:   session.expiresAt,
+	}); err != nil {
+		logger.WithError(err).Warn("Failed to record debug session")
+		d.cleanupContainer(debugID)
+		return false
+	}
+
+	d.mu.Lock()
+	d.sessions[session.id] = session
+	d.mu.Unlock()
+	logger.WithFields(logrus.Fields{
+		"session_id": session.id,
+		"expires_at": session.expiresAt,
+	}).Info("Holding failed job for debugging")
+	return true
+}
+
+// Start serves the exec bridge and ends sessions as they expire or are
+// closed, until ctx is done; then it ends every remaining session.
+func (d *DebugSessions) Start(ctx context.Context) {
+	server := &http.Server{Addr: d.config.ListenAddr, Handler: d}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			d.logger.WithError(err).Error("Debug session bridge stopped")
+		}
+	}()
+
+	ticker := time.NewTicker(debugReapInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			d.mu.Lock()
+			d.closing = true
+			d.mu.Unlock()
+			_ = server.Close()
+			for _, s := range d.snapshot() {
+				d.end(s, models.DebugSessionClosed)
+			}
+			return
+		case <-ticker.C:
+			d.reap(ctx)
+		}
+	}
+}
+
+// reap ends sessions that expired or were closed through the coordinator.
+func (d *DebugSessions) reap(ctx context.Context) {
+	now := time.Now().UTC()
+	for _, s := range d.snapshot() {
+		if !now.Before(s.expiresAt) {
+			d.end(s, models.DebugSessionExpired)
+			continue
+		}
+		row, err := d.store.GetDebugSession(ctx, s.id)
+		if err == nil && row.Status != models.DebugSessionActive {
+			d.end(s, row.Status)
+		}
+	}
+}
+
+// end releases the session's container and workspace and records status.
+func (d *DebugSessions) end(s *debugSession, status string) {
+	d.mu.Lock()
+	if _, ok := d.sessions[s.id]; !ok {
+		d.mu.Unlock()
+		return
+	}
+	delete(d.sessions, s.id)
+	d.mu.Unlock()
+
+	d.cleanupContainer(s.debugID)
+	if err := os.RemoveAll(s.workspaceDir); err != nil {
+		d.logger.WithError(err).WithField("session_id", s.id).Warn("Failed to remove debug session workspace")
+	}
+	if _, err := d.store.EndDebugSession(context.Background(), s.id, status); err != nil {
+		d.logger.WithError(err).WithField("session_id", s.id).Warn("Failed to end debug session")
+	}
+	d.logger.WithFields(logrus.Fields{
+		"session_id": s.id,
+		"job_id":     s.jobID,
+		"status":     status,
+	}).Info("Debug session ended")
+}
+
+func (d *DebugSessions) cleanupContainer(debugID string) {
+	if err := d.runner.Cleanup(context.Background(), debugID); err != nil {
+		d.logger.WithError(err).WithField("debug_container_id", debugID).Warn("Failed to clean up debug container")
+	}
+}
+
+func (d *DebugSessions) snapshot() []*debugSession {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	sessions := make([]*debugSession, 0, len(d.sessions))
+	for _, s := range d.sessions {
+		sessions = append(sessions, s)
+	}
+	return sessions
+}
+
+// debugResizeMessage is the text frame a bridge client sends when its
+// terminal is resized. Binary frames carry terminal input and output.
+type debugResizeMessage struct {
+	Type string `json:"type"`
+	Rows uint   `json:"rows"`
+	Cols uint   `json:"cols"`
+}
+
+// ServeHTTP serves GET /debug/sessions/{session_id}/attach, authenticated
+// with the session's bearer token: it upgrades to a WebSocket and bridges
+// it to a shell in the session's debug container.
+func (d *DebugSessions) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/debug/sessions/")
+	sessionID := strings.TrimSuffix(path, "/attach")
+	if r.Method != http.MethodGet || path == r.URL.Path || sessionID == path {
+		http.NotFound(w, r)
+		return
+	}
+
+	d.mu.Lock()
+	s, ok := d.sessions[sessionID]
+	d.mu.Unlock()
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	exec, err := d.debug.ExecDebug(r.Context(), s.debugID, debugShell)
+	if err != nil {
+		d.logger.WithError(err).WithField("session_id", s.id).Warn("Failed to start debug shell")
+		http.Error(w, "failed to start shell", http.StatusBadGateway)
+		return
+	}
+	defer exec.Close()
+
+	ws, err := d.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer ws.Close()
+	d.logger.WithField("session_id", s.id).Info("Debug shell attached")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		buf := make([]byte, 4096)
+		for {
+			n, err := exec.Read(buf)
+			if n > 0 {
+				if werr := ws.WriteMessage(websocket.BinaryMessage, buf[:n]); werr != nil {
+					return
+				}
+			}
+			if err != nil {
+				_ = ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "shell exited"))
+				return
+			}
+		}
+	}()
+
+	for {
+		msgType, data, err := ws.ReadMessage()
+		if err != nil {
+			break
+		}
+		if msgType == websocket.TextMessage {
+			var resize debugResizeMessage
+			if json.Unmarshal(data, &resize) == nil && resize.Type == "resize" && resize.Rows > 0 && resize.Cols > 0 {
+				_ = exec.Resize(r.Context(), resize.Rows, resize.Cols)
+			}
+			continue
+		}
+		if _, err := exec.Write(data); err != nil {
+			break
+		}
+	}
+	exec.Close()
+	<-done
+}
+
+// debugEnv is the failed job's environment without its resolved secrets
+// and credentials: a debug shell shouldn't hand them to whoever attaches.
+func debugEnv(env map[string]string) map[string]string {
+	drop := map[string]bool{
+		"REACTORCIDE_API_TOKEN": true,
+	}
+	for _, name := range strings.Split(env["REACTORCIDE_SECRET_ENV_NAMES"], ",") {
+		if name != "" {
+			drop[name] = true
+		}
+	}
+	result := make(map[string]string, len(env))
+	for k, v := range env {
+		if drop[k] || strings.HasPrefix(k, "REACTORCIDE_VCS_AUTH_") || k == "GIT_CONFIG_GLOBAL" {
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
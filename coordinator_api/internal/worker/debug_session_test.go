@@ -0,0 +1,237 @@
+package worker
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// debuggingJobRunner is a fakeJobRunner that also implements DebugRunner.
+// Its debug shells echo their input back.
+type debuggingJobRunner struct {
+	*fakeJobRunner
+	started  []string
+	debugEnv map[string]string
+}
+
+func (d *debuggingJobRunner) StartDebugContainer(ctx context.Context, jobID string, env map[string]string, ttl time.Duration) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.started = append(d.started, jobID)
+	d.debugEnv = env
+	return "debug-" + jobID, nil
+}
+
+func (d *debuggingJobRunner) ExecDebug(ctx context.Context, debugID string, cmd []string) (DebugExec, error) {
+	r, w := io.Pipe()
+	return &echoDebugExec{PipeReader: r, PipeWriter: w}, nil
+}
+
+var _ DebugRunner = (*debuggingJobRunner)(nil)
+
+type echoDebugExec struct {
+	*io.PipeReader
+	*io.PipeWriter
+}
+
+func (e *echoDebugExec) Close() error {
+	e.PipeWriter.Close()
+	return e.PipeReader.Close()
+}
+
+func (e *echoDebugExec) Resize(ctx context.Context, rows, cols uint) error { return nil }
+
+// debugSessionTestStore records debug sessions on top of MockStore.
+type debugSessionTestStore struct {
+	MockStore
+	mu       sync.Mutex
+	sessions map[string]*models.DebugSession
+}
+
+func (s *debugSessionTestStore) CreateDebugSession(ctx context.Context, session *models.DebugSession) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session.Status = models.DebugSessionActive
+	s.sessions[session.SessionID] = session
+	return nil
+}
+
+func (s *debugSessionTestStore) GetDebugSession(ctx context.Context, sessionID string) (*models.DebugSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[sessionID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	copied := *session
+	return &copied, nil
+}
+
+func (s *debugSessionTestStore) EndDebugSession(ctx context.Context, sessionID, status string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[sessionID]
+	if !ok || session.Status != models.DebugSessionActive {
+		return false, nil
+	}
+	session.Status = status
+	return true, nil
+}
+
+func (s *debugSessionTestStore) only(t *testing.T) *models.DebugSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	require.Len(t, s.sessions, 1)
+	for _, session := range s.sessions {
+		return session
+	}
+	return nil
+}
+
+func newDebugSessionsForTest(t *testing.T) (*DebugSessions, *debuggingJobRunner, *debugSessionTestStore) {
+	runner := &debuggingJobRunner{fakeJobRunner: newFakeJobRunner()}
+	s := &debugSessionTestStore{sessions: make(map[string]*models.DebugSession)}
+	d := NewDebugSessions(s, runner, DebugSessionConfig{
+		ListenAddr:   "127.0.0.1:0",
+		AdvertiseURL: "http://worker-1:7070",
+		WorkerID:     "worker-1",
+	})
+	require.NotNil(t, d)
+	return d, runner, s
+}
+
+func TestNewDebugSessions_Disabled(t *testing.T) {
+	config := DebugSessionConfig{ListenAddr: ":7070", AdvertiseURL: "http://worker:7070"}
+	debugStore := &debugSessionTestStore{}
+	debugRunner := &debuggingJobRunner{fakeJobRunner: newFakeJobRunner()}
+
+	assert.Nil(t, NewDebugSessions(debugStore, debugRunner, DebugSessionConfig{}), "unconfigured")
+	assert.Nil(t, NewDebugSessions(debugStore, newFakeJobRunner(), config), "runner without DebugRunner")
+	assert.Nil(t, NewDebugSessions(&MockStore{}, debugRunner, config), "store without debug sessions")
+	assert.NotNil(t, NewDebugSessions(debugStore, debugRunner, config))
+}
+
+func TestDebugSessions_HoldAndReap(t *testing.T) {
+	d, runner, s := newDebugSessionsForTest(t)
+	workspace := t.TempDir()
+
+	held := d.Hold(context.Background(), &models.Job{JobID: "job-1"}, "job-container", map[string]string{
+		"REACTORCIDE_SECRET_ENV_NAMES": "DEPLOY_KEY",
+		"DEPLOY_KEY":                   "secret",
+		"REACTORCIDE_API_TOKEN":        "token",
+		"REACTORCIDE_VCS_AUTH_DIR":     "/job/.vcs-auth",
+		"GIT_CONFIG_GLOBAL":            "/job/.vcs-auth/gitconfig",
+		"REACTORCIDE_SOURCE_URL":       "https://example.com/repo.git",
+	}, workspace)
+	require.True(t, held)
+
+	assert.Equal(t, []string{"job-container"}, runner.started)
+	assert.Equal(t, map[string]string{
+		"REACTORCIDE_SECRET_ENV_NAMES": "DEPLOY_KEY",
+		"REACTORCIDE_SOURCE_URL":       "https://example.com/repo.git",
+	}, runner.debugEnv)
+
+	session := s.only(t)
+	assert.Equal(t, "job-1", session.JobID)
+	assert.Equal(t, "worker-1", session.WorkerID)
+	assert.Equal(t, "http://worker-1:7070", session.BridgeURL)
+	assert.Len(t, session.BridgeToken, 64)
+	assert.WithinDuration(t, time.Now().Add(DefaultDebugSessionTTL), session.ExpiresAt, time.Minute)
+
+	// Still active: the reaper leaves it alone.
+	d.reap(context.Background())
+	assert.Zero(t, runner.cleanupCallCount())
+	assert.DirExists(t, workspace)
+
+	// Closed through the coordinator: the reaper releases it.
+	_, err := s.EndDebugSession(context.Background(), session.SessionID, models.DebugSessionClosed)
+	require.NoError(t, err)
+	d.reap(context.Background())
+	assert.Equal(t, []string{"debug-job-container"}, runner.cleanupCalls)
+	_, err = os.Stat(workspace)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestDebugSessions_ReapExpired(t *testing.T) {
+	d, runner, s := newDebugSessionsForTest(t)
+	d.config.TTL = -time.Second
+
+	require.True(t, d.Hold(context.Background(), &models.Job{JobID: "job-1"}, "job-container", nil, t.TempDir()))
+	d.reap(context.Background())
+
+	assert.Equal(t, 1, runner.cleanupCallCount())
+	assert.Equal(t, models.DebugSessionExpired, s.only(t).Status)
+}
+
+func TestDebugSessions_Attach(t *testing.T) {
+	d, _, s := newDebugSessionsForTest(t)
+	require.True(t, d.Hold(context.Background(), &models.Job{JobID: "job-1"}, "job-container", nil, t.TempDir()))
+	session := s.only(t)
+
+	server := httptest.NewServer(d)
+	defer server.Close()
+	attachURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/debug/sessions/" + session.SessionID + "/attach"
+
+	_, resp, err := websocket.DefaultDialer.Dial(attachURL, http.Header{"Authorization": []string{"Bearer wrong"}})
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	ws, _, err := websocket.DefaultDialer.Dial(attachURL, http.Header{"Authorization": []string{"Bearer " + session.BridgeToken}})
+	require.NoError(t, err)
+	defer ws.Close()
+
+	require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte(`{"type":"resize","rows":40,"cols":120}`)))
+	require.NoError(t, ws.WriteMessage(websocket.BinaryMessage, []byte("pwd\n")))
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	msgType, data, err := ws.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, websocket.BinaryMessage, msgType)
+	assert.Equal(t, "pwd\n", string(data))
+}
+
+// TestJobProcessor_DebugOnFailure verifies a failed job with DebugOnFailure
+// is handed to the debug sessions keeper, which keeps its workspace.
+func TestJobProcessor_DebugOnFailure(t *testing.T) {
+	ensureJobWorkspaceBaseDir(t)
+	d, runner, _ := newDebugSessionsForTest(t)
+	runner.exitCode = 1
+	runner.unblockWait()
+
+	config := newCancelPollTestConfig()
+	config.DebugSessions = d
+	mockStore := &MockStore{
+		GetJobByIDFunc: func(ctx context.Context, jobID string) (*models.Job, error) {
+			return &models.Job{JobID: jobID, Status: "running"}, nil
+		},
+	}
+	jp := NewJobProcessorWithConfig(mockStore, runner, false, config)
+
+	job := newCancelPollTestJob()
+	job.DebugOnFailure = true
+	result := jp.ProcessJobWithContext(context.Background(), job, &JobExecutionContext{})
+	defer os.RemoveAll(result.WorkspaceDir)
+
+	assert.Equal(t, 1, result.ExitCode)
+	assert.True(t, result.WorkspaceRetained)
+	assert.Equal(t, []string{"fake-container-1"}, runner.started)
+
+	// Without the flag the job just fails.
+	job = newCancelPollTestJob()
+	result = jp.ProcessJobWithContext(context.Background(), job, &JobExecutionContext{})
+	defer os.RemoveAll(result.WorkspaceDir)
+	assert.False(t, result.WorkspaceRetained)
+	assert.Len(t, runner.started, 1)
+}
@@ -0,0 +1,184 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// diagnosticsLogLines is how many of its own log lines a worker keeps
+	// for its diagnostics, and maxDiagnosticsLogLine how much of each.
+	diagnosticsLogLines   = 200
+	maxDiagnosticsLogLine = 2000
+
+	// diagnoseTimeout bounds checking the executor for one report.
+	diagnoseTimeout = 5 * time.Second
+)
+
+// workerDiagnosticsStore is the store support for keeping workers'
+// diagnostics. See postgres_store/worker_operations.go.
+type workerDiagnosticsStore interface {
+	ReportWorkerDiagnostics(ctx context.Context, workerID string, report models.WorkerDiagnosticsReport) error
+}
+
+// Diagnostics gathers what a worker reports about itself with each
+// heartbeat (see WorkerControl): the jobs it runs, its executor and image
+// cache, the disk its workspaces are on, and its own recent log lines. Job
+// output isn't in the worker's log, so it isn't reported.
+type Diagnostics struct {
+	runner JobRunner
+
+	mu   sync.Mutex
+	jobs map[string]models.WorkerJobAssignment
+}
+
+// NewDiagnostics starts keeping the worker's recent log lines and returns
+// the diagnostics of a worker running jobs on runner.
+func NewDiagnostics(runner JobRunner) *Diagnostics {
+	workerLogsOnce.Do(func() { logging.Log.AddHook(workerLogs) })
+	return &Diagnostics{runner: runner, jobs: make(map[string]models.WorkerJobAssignment)}
+}
+
+// track records that the worker runs job, until the returned func is
+// called. A nil Diagnostics tracks nothing.
+func (d *Diagnostics) track(job *models.Job, startedAt time.Time) func() {
+	if d == nil {
+		return func() {}
+	}
+	d.mu.Lock()
+	d.jobs[job.JobID] = models.WorkerJobAssignment{
+		JobID:     job.JobID,
+		Name:      job.Name,
+		ProjectID: job.ProjectID,
+		StartedAt: startedAt,
+	}
+	d.mu.Unlock()
+	return func() {
+		d.mu.Lock()
+		delete(d.jobs, job.JobID)
+		d.mu.Unlock()
+	}
+}
+
+// Report gathers the worker's diagnostics now.
+func (d *Diagnostics) Report(ctx context.Context) models.WorkerDiagnosticsReport {
+	report := models.WorkerDiagnosticsReport{
+		Jobs:       []models.WorkerJobAssignment{},
+		Goroutines: runtime.NumGoroutine(),
+		Logs:       workerLogs.lines(),
+	}
+	d.mu.Lock()
+	for _, job := range d.jobs {
+		report.Jobs = append(report.Jobs, job)
+	}
+	d.mu.Unlock()
+	sort.Slice(report.Jobs, func(i, j int) bool {
+		return report.Jobs[i].StartedAt.Before(report.Jobs[j].StartedAt)
+	})
+
+	if diagnoser, ok := d.runner.(ExecutorDiagnoser); ok {
+		diagnoseCtx, cancel := context.WithTimeout(ctx, diagnoseTimeout)
+		report.Executor = diagnoser.DiagnoseExecutor(diagnoseCtx)
+		cancel()
+	} else {
+		report.Executor = models.WorkerExecutorStatus{Runtime: runnerRuntime(d.runner), Healthy: true}
+	}
+
+	dir := jobWorkspaceBaseDir()
+	if usage, err := diskUsage(dir); err == nil && usage != nil {
+		report.Disk = usage
+	}
+	return report
+}
+
+// runnerRuntime names the runtime runner runs jobs on.
+func runnerRuntime(runner JobRunner) string {
+	switch runner.(type) {
+	case *DockerRunner:
+		return "docker"
+	case *ContainerdRunner:
+		return "containerd"
+	case *KubernetesRunner:
+		return "kubernetes"
+	case *HostRunner:
+		return "host"
+	}
+	return fmt.Sprintf("%T", runner)
+}
+
+// workerLogs keeps the worker's recent log lines for its diagnostics. It
+// is installed on logging.Log by the first NewDiagnostics.
+var (
+	workerLogs     = &logRing{size: diagnosticsLogLines}
+	workerLogsOnce sync.Once
+)
+
+// logRing is a logrus hook keeping the last size log lines.
+type logRing struct {
+	size int
+
+	mu    sync.Mutex
+	buf   []models.WorkerLogLine
+	start int
+}
+
+// Levels implements logrus.Hook.
+func (r *logRing) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements logrus.Hook.
+func (r *logRing) Fire(entry *logrus.Entry) error {
+	line := models.WorkerLogLine{
+		Time:    entry.Time.UTC(),
+		Level:   entry.Level.String(),
+		Message: formatLogLine(entry),
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.buf) < r.size {
+		r.buf = append(r.buf, line)
+		return nil
+	}
+	r.buf[r.start] = line
+	r.start = (r.start + 1) % r.size
+	return nil
+}
+
+// lines returns the kept lines, oldest first.
+func (r *logRing) lines() []models.WorkerLogLine {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lines := make([]models.WorkerLogLine, 0, len(r.buf))
+	lines = append(lines, r.buf[r.start:]...)
+	return append(lines, r.buf[:r.start]...)
+}
+
+// formatLogLine is entry's message followed by its fields, sorted, as
+// key=value, cut to maxDiagnosticsLogLine bytes.
+func formatLogLine(entry *logrus.Entry) string {
+	keys := make([]string, 0, len(entry.Data))
+	for key := range entry.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(entry.Message)
+	for _, key := range keys {
+		fmt.Fprintf(&b, " %s=%v", key, entry.Data[key])
+	}
+	line := b.String()
+	if len(line) > maxDiagnosticsLogLine {
+		line = line[:maxDiagnosticsLogLine]
+	}
+	return line
+}
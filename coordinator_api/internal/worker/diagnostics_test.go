@@ -0,0 +1,81 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// diagnosticsMockStore is workerControlMockStore keeping the reported
+// diagnostics.
+type diagnosticsMockStore struct {
+	workerControlMockStore
+	reports []models.WorkerDiagnosticsReport
+}
+
+func (s *diagnosticsMockStore) ReportWorkerDiagnostics(ctx context.Context, workerID string, report models.WorkerDiagnosticsReport) error {
+	s.reports = append(s.reports, report)
+	return nil
+}
+
+func TestLogRing_KeepsLastLines(t *testing.T) {
+	ring := &logRing{size: 3}
+	for i := 1; i <= 5; i++ {
+		require.NoError(t, ring.Fire(&logrus.Entry{
+			Time:    time.Now(),
+			Level:   logrus.InfoLevel,
+			Message: fmt.Sprintf("line %d", i),
+			Data:    logrus.Fields{"job_id": "job-1", "attempt": i},
+		}))
+	}
+	lines := ring.lines()
+	require.Len(t, lines, 3)
+	assert.Equal(t, "line 3 attempt=3 job_id=job-1", lines[0].Message)
+	assert.Equal(t, "line 5 attempt=5 job_id=job-1", lines[2].Message)
+	assert.Equal(t, "info", lines[2].Level)
+}
+
+func TestWorkerControl_ReportsDiagnostics(t *testing.T) {
+	s := &diagnosticsMockStore{}
+	diagnostics := NewDiagnostics(&fakeJobRunner{})
+	c := &WorkerControl{
+		store:       s,
+		worker:      models.Worker{WorkerID: "build-1"},
+		running:     func() int { return 1 },
+		diagnostics: diagnostics,
+		logger:      logging.Log.WithField("component", "worker_control"),
+	}
+	projectID := "project-1"
+	untrack := diagnostics.track(&models.Job{JobID: "job-1", Name: "build", ProjectID: &projectID}, time.Now())
+	logging.Log.WithField("job_id", "job-1").Warn("Diagnostics test line")
+
+	require.NoError(t, c.sync(context.Background()))
+	require.Len(t, s.reports, 1)
+	report := s.reports[0]
+	require.Len(t, report.Jobs, 1)
+	assert.Equal(t, "build", report.Jobs[0].Name)
+	assert.Equal(t, "project-1", *report.Jobs[0].ProjectID)
+	assert.Equal(t, "*worker.fakeJobRunner", report.Executor.Runtime)
+	assert.Positive(t, report.Goroutines)
+	var messages []string
+	for _, line := range report.Logs {
+		messages = append(messages, line.Message)
+	}
+	assert.Contains(t, messages, "Diagnostics test line job_id=job-1")
+
+	untrack()
+	require.NoError(t, c.sync(context.Background()))
+	assert.Empty(t, s.reports[1].Jobs)
+
+	// Without diagnostics nothing is reported.
+	c.diagnostics = nil
+	require.NoError(t, c.sync(context.Background()))
+	assert.Len(t, s.reports, 2)
+}
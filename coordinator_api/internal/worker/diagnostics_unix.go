@@ -0,0 +1,22 @@
+//go:build !windows
+
+package worker
+
+import (
+	"syscall"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// diskUsage reports the usage of the filesystem holding path.
+func diskUsage(path string) (*models.WorkerDiskUsage, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return nil, err
+	}
+	return &models.WorkerDiskUsage{
+		Path:       path,
+		TotalBytes: st.Blocks * uint64(st.Bsize),
+		FreeBytes:  st.Bavail * uint64(st.Bsize),
+	}, nil
+}
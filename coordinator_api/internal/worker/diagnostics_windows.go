@@ -0,0 +1,10 @@
+//go:build windows
+
+package worker
+
+import "github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+
+// diskUsage isn't reported on Windows.
+func diskUsage(path string) (*models.WorkerDiskUsage, error) {
+	return nil, nil
+}
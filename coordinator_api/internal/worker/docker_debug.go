@@ -0,0 +1,141 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+)
+
+// debugSnapshotLabel marks images committed from failed job containers, so
+// sweepLeaked can remove ones a crashed worker left behind.
+const debugSnapshotLabel = "reactorcide.component=debug-snapshot"
+
+// StartDebugContainer implements DebugRunner. It commits the exited job
+// container to an image and starts an unprivileged container from it that
+// sleeps for ttl. The debug container gets the job container's mounts,
+// working directory, user and resource limits, but its own network: a
+// builder sidecar's netns doesn't outlive the job.
+func (dr *DockerRunner) StartDebugContainer(ctx context.Context, jobContainerID string, env map[string]string, ttl time.Duration) (string, error) {
+	logger := logging.Log.WithField("container_id", jobContainerID)
+
+	inspect, err := dr.client.ContainerInspect(ctx, jobContainerID)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect job container: %w", err)
+	}
+	jobID := inspect.Config.Labels["reactorcide.job_id"]
+
+	snapshot, err := dr.client.ContainerCommit(ctx, jobContainerID, container.CommitOptions{
+		Reference: "reactorcide-debug:" + jobID,
+		Comment:   "Reactorcide debug snapshot of job " + jobID,
+		Changes:   []string{"LABEL " + debugSnapshotLabel},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to snapshot job container: %w", err)
+	}
+
+	removeSnapshot := func() {
+		if _, err := dr.client.ImageRemove(ctx, snapshot.ID, image.RemoveOptions{Force: true, PruneChildren: true}); err != nil {
+			logger.WithError(err).Warn("Failed to remove debug snapshot")
+		}
+	}
+
+	debugConfig := &container.Config{
+		Image:      snapshot.ID,
+		Entrypoint: []string{},
+		Cmd:        []string{"sleep", strconv.Itoa(int(ttl.Seconds()))},
+		Env:        dr.envMapToSlice(env),
+		WorkingDir: inspect.Config.WorkingDir,
+		User:       inspect.Config.User,
+		Labels: map[string]string{
+			"reactorcide.job_id":    jobID,
+			"reactorcide.component": "debug-container",
+		},
+	}
+	hostConfig := &container.HostConfig{
+		Binds: inspect.HostConfig.Binds,
+		Resources: container.Resources{
+			NanoCPUs: inspect.HostConfig.NanoCPUs,
+			Memory:   inspect.HostConfig.Memory,
+		},
+	}
+
+	resp, err := dr.client.ContainerCreate(ctx, debugConfig, hostConfig, nil, nil, fmt.Sprintf("reactorcide-debug-%s", jobID))
+	if err != nil {
+		removeSnapshot()
+		return "", fmt.Errorf("failed to create debug container: %w", err)
+	}
+	if err := dr.client.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
+		dr.client.ContainerRemove(ctx, resp.ID, container.RemoveOptions{Force: true})
+		removeSnapshot()
+		return "", fmt.Errorf("failed to start debug container: %w", err)
+	}
+
+	dr.sidecarsMu.Lock()
+	dr.debugSnapshots[resp.ID] = snapshot.ID
+	dr.sidecarsMu.Unlock()
+
+	logger.WithField("debug_container_id", resp.ID).Info("Debug container started")
+	return resp.ID, nil
+}
+
+// ExecDebug implements DebugRunner.
+func (dr *DockerRunner) ExecDebug(ctx context.Context, debugID string, cmd []string) (DebugExec, error) {
+	created, err := dr.client.ContainerExecCreate(ctx, debugID, container.ExecOptions{
+		Cmd:          cmd,
+		Tty:          true,
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exec: %w", err)
+	}
+	attached, err := dr.client.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{Tty: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach exec: %w", err)
+	}
+	return &dockerDebugExec{runner: dr, execID: created.ID, conn: attached}, nil
+}
+
+// dockerDebugExec is a DebugExec over a hijacked Docker exec connection.
+type dockerDebugExec struct {
+	runner *DockerRunner
+	execID string
+	conn   types.HijackedResponse
+}
+
+func (e *dockerDebugExec) Read(p []byte) (int, error)  { return e.conn.Reader.Read(p) }
+func (e *dockerDebugExec) Write(p []byte) (int, error) { return e.conn.Conn.Write(p) }
+
+func (e *dockerDebugExec) Close() error {
+	e.conn.Close()
+	return nil
+}
+
+func (e *dockerDebugExec) Resize(ctx context.Context, rows, cols uint) error {
+	return e.runner.client.ContainerExecResize(ctx, e.execID, container.ResizeOptions{Height: rows, Width: cols})
+}
+
+// removeDebugSnapshot deletes the image a debug container was started from,
+// if containerID is one. Called from Cleanup.
+func (dr *DockerRunner) removeDebugSnapshot(ctx context.Context, containerID string) {
+	dr.sidecarsMu.Lock()
+	snapshotID, ok := dr.debugSnapshots[containerID]
+	delete(dr.debugSnapshots, containerID)
+	dr.sidecarsMu.Unlock()
+	if !ok {
+		return
+	}
+	if _, err := dr.client.ImageRemove(ctx, snapshotID, image.RemoveOptions{Force: true, PruneChildren: true}); err != nil {
+		logging.Log.WithError(err).WithField("image_id", snapshotID).Warn("Failed to remove debug snapshot")
+	}
+}
+
+// Ensure DockerRunner implements DebugRunner interface
+var _ DebugRunner = (*DockerRunner)(nil)
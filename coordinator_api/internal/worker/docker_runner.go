@@ -2,6 +2,7 @@ package worker
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
@@ -15,6 +16,7 @@ import (
 	"github.com/docker/docker/pkg/stdcopy"
 
 	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
 )
 
 // DockerRunner implements JobRunner using the Docker daemon
@@ -26,6 +28,10 @@ type DockerRunner struct {
 	// Cleanup can tear down both. Populated when CapabilityBuilder is set.
 	sidecars   map[string]string
 	sidecarsMu sync.Mutex
+
+	// debugSnapshots maps debug container ID to the image it was started
+	// from, so Cleanup can remove both. Guarded by sidecarsMu.
+	debugSnapshots map[string]string
 }
 
 // NewDockerRunner creates a new Docker-based job runner
@@ -40,6 +46,8 @@ func NewDockerRunner() (*DockerRunner, error) {
 		client:   cli,
 		builder:  LoadBuilderConfig(),
 		sidecars: make(map[string]string),
+
+		debugSnapshots: make(map[string]string),
 	}
 	dr.sweepLeaked(context.Background())
 	return dr, nil
@@ -52,6 +60,8 @@ func NewDockerRunnerWithClient(cli *client.Client) *DockerRunner {
 		client:   cli,
 		builder:  LoadBuilderConfig(),
 		sidecars: make(map[string]string),
+
+		debugSnapshots: make(map[string]string),
 	}
 }
 
@@ -60,7 +70,7 @@ func NewDockerRunnerWithClient(cli *client.Client) *DockerRunner {
 // not share a runtime.
 func (dr *DockerRunner) sweepLeaked(ctx context.Context) {
 	logger := logging.Log
-	for _, component := range []string{"builder-sidecar", "job-container"} {
+	for _, component := range []string{"builder-sidecar", "job-container", "debug-container"} {
 		f := filters.NewArgs()
 		f.Add("label", "reactorcide.component="+component)
 		list, err := dr.client.ContainerList(ctx, container.ListOptions{All: true, Filters: f})
@@ -79,6 +89,17 @@ func (dr *DockerRunner) sweepLeaked(ctx context.Context) {
 			}).Info("Swept leaked container from prior worker run")
 		}
 	}
+
+	snapshots, err := dr.client.ImageList(ctx, image.ListOptions{Filters: filters.NewArgs(filters.Arg("label", debugSnapshotLabel))})
+	if err != nil {
+		logger.WithError(err).Warn("Failed to list leaked debug snapshots")
+		return
+	}
+	for _, img := range snapshots {
+		if _, err := dr.client.ImageRemove(ctx, img.ID, image.RemoveOptions{Force: true, PruneChildren: true}); err != nil {
+			logger.WithError(err).WithField("image_id", img.ID).Warn("Failed to sweep leaked debug snapshot")
+		}
+	}
 }
 
 // SpawnJob creates and starts a Docker container for the job
@@ -474,6 +495,31 @@ func (dr *DockerRunner) Stop(ctx context.Context, containerID string, grace time
 	return nil
 }
 
+// SampleResources implements ResourceSampler using the Docker stats API.
+func (dr *DockerRunner) SampleResources(ctx context.Context, containerID string) (ResourceSample, error) {
+	resp, err := dr.client.ContainerStatsOneShot(ctx, containerID)
+	if err != nil {
+		return ResourceSample{}, fmt.Errorf("failed to read container stats: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var stats container.StatsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return ResourceSample{}, fmt.Errorf("failed to decode container stats: %w", err)
+	}
+	sample := ResourceSample{
+		CPUSeconds:  float64(stats.CPUStats.CPUUsage.TotalUsage) / float64(time.Second),
+		MemoryBytes: stats.MemoryStats.Usage,
+		// Only reported on cgroup v1 hosts.
+		PeakMemoryBytes: stats.MemoryStats.MaxUsage,
+	}
+	for _, network := range stats.Networks {
+		sample.NetworkRxBytes += network.RxBytes
+		sample.NetworkTxBytes += network.TxBytes
+	}
+	return sample, nil
+}
+
 // Cleanup removes the container and any builder sidecar launched for it.
 func (dr *DockerRunner) Cleanup(ctx context.Context, containerID string) error {
 	logger := logging.Log.WithField("container_id", containerID)
@@ -502,6 +548,7 @@ func (dr *DockerRunner) Cleanup(ctx context.Context, containerID string) error {
 			logger.WithField("sidecar_id", sidecarID).Info("Builder sidecar cleaned up")
 		}
 	}
+	dr.removeDebugSnapshot(ctx, containerID)
 
 	if jobErr != nil {
 		return fmt.Errorf("failed to remove container: %w", jobErr)
@@ -540,6 +587,29 @@ func (dr *DockerRunner) ensureImage(ctx context.Context, imageName string) error
 	}
 
 	// Image doesn't exist, pull it
+	return dr.PullImage(ctx, imageName)
+}
+
+// ImageDigest returns the repository digest of the local copy of
+// imageName, or its image ID if it was never pulled from a registry. See
+// ImageDigester.
+func (dr *DockerRunner) ImageDigest(ctx context.Context, imageName string) (string, error) {
+	inspect, _, err := dr.client.ImageInspectWithRaw(ctx, imageName)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect image: %w", err)
+	}
+	for _, repoDigest := range inspect.RepoDigests {
+		if _, digest, ok := strings.Cut(repoDigest, "@"); ok {
+			return digest, nil
+		}
+	}
+	return inspect.ID, nil
+}
+
+// PullImage pulls the image, whether or not it exists locally, so a
+// moved tag is picked up. See ImagePuller.
+func (dr *DockerRunner) PullImage(ctx context.Context, imageName string) error {
+	logger := logging.Log.WithField("image", imageName)
 	logger.Info("Pulling Docker image")
 	pullResp, err := dr.client.ImagePull(ctx, imageName, image.PullOptions{})
 	if err != nil {
@@ -558,6 +628,27 @@ func (dr *DockerRunner) ensureImage(ctx context.Context, imageName string) error
 	return nil
 }
 
+// DiagnoseExecutor pings the Docker daemon and sums its local images.
+func (dr *DockerRunner) DiagnoseExecutor(ctx context.Context) models.WorkerExecutorStatus {
+	status := models.WorkerExecutorStatus{Runtime: "docker"}
+	if _, err := dr.client.Ping(ctx); err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	status.Healthy = true
+	images, err := dr.client.ImageList(ctx, image.ListOptions{})
+	if err != nil {
+		status.Error = fmt.Sprintf("failed to list images: %v", err)
+		return status
+	}
+	count, size := len(images), int64(0)
+	for _, img := range images {
+		size += img.Size
+	}
+	status.Images, status.ImageBytes = &count, &size
+	return status
+}
+
 // envMapToSlice converts an environment variable map to a slice of "KEY=VALUE" strings
 func (dr *DockerRunner) envMapToSlice(envMap map[string]string) []string {
 	if envMap == nil {
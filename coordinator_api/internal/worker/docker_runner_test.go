@@ -287,6 +287,7 @@ func TestIsBackendImplemented(t *testing.T) {
 		{"docker", true},      // fully implemented
 		{"containerd", true},  // fully implemented
 		{"kubernetes", true},  // fully implemented
+		{"host", true},        // fully implemented
 		{"auto", true},        // fully implemented (auto-detection)
 		{"invalid", false},
 		{"DOCKER", true},       // case insensitive
@@ -307,8 +308,8 @@ func TestIsBackendImplemented(t *testing.T) {
 // TestGetSupportedBackends tests getting the list of supported backends
 func TestGetSupportedBackends(t *testing.T) {
 	backends := GetSupportedBackends()
-	if len(backends) != 4 {
-		t.Errorf("expected 4 supported backends, got %d", len(backends))
+	if len(backends) != 5 {
+		t.Errorf("expected 5 supported backends, got %d", len(backends))
 	}
 
 	expectedBackends := map[RunnerBackend]bool{
@@ -316,6 +317,7 @@ func TestGetSupportedBackends(t *testing.T) {
 		BackendDocker:     true,
 		BackendContainerd: true,
 		BackendKubernetes: true,
+		BackendHost:       true,
 	}
 
 	for _, backend := range backends {
@@ -0,0 +1,213 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/hooks"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
+	"github.com/lib/pq"
+)
+
+// MaxDownstreamJobs caps how many downstream jobs are loaded for one
+// upstream job.
+const MaxDownstreamJobs = 100
+
+// downstreamTriggerStore is the narrow store capability for cross-project
+// triggers; stores without it never fire any.
+type downstreamTriggerStore interface {
+	ListDownstreamTriggers(ctx context.Context, projectID string) ([]models.DownstreamTrigger, error)
+}
+
+// FireDownstreamTriggers starts the downstream projects of job's project
+// whose triggers match job, now that it's finished, and returns the IDs of
+// the jobs it created. Each downstream project gets one eval job per
+// upstream job, however many of its triggers match, so calling this again
+// for the same job starts nothing new.
+//
+// A trigger is skipped when its downstream project already ran earlier in
+// the chain of upstream jobs that led to job (a cycle), or when the chain
+// is MaxDownstreamDepth jobs long. Triggers are checked for cycles when
+// they're created too; this catches the chains that formed anyway.
+func (tp *TriggerProcessor) FireDownstreamTriggers(ctx context.Context, job *models.Job) ([]string, error) {
+	if job.ProjectID == nil || *job.ProjectID == "" {
+		return nil, nil
+	}
+	triggerStore, ok := tp.store.(downstreamTriggerStore)
+	if !ok {
+		return nil, nil
+	}
+	triggers, err := triggerStore.ListDownstreamTriggers(ctx, *job.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list downstream triggers: %w", err)
+	}
+	var matching []models.DownstreamTrigger
+	for _, t := range triggers {
+		if t.FiresOn(job.Status) && matchGrantPattern(t.JobNameMatch, t.JobNamePattern, job.Name, true) {
+			matching = append(matching, t)
+		}
+	}
+	if len(matching) == 0 {
+		return nil, nil
+	}
+
+	chain, depth, err := tp.upstreamProjects(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := tp.store.ListJobs(ctx, map[string]interface{}{"upstream_job_id": job.JobID}, MaxDownstreamJobs, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list downstream jobs: %w", err)
+	}
+	started := map[string]bool{}
+	for _, d := range existing {
+		if d.ProjectID != nil {
+			started[*d.ProjectID] = true
+		}
+	}
+	upstreamProject, err := tp.store.GetProjectByID(ctx, *job.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get upstream project: %w", err)
+	}
+
+	var created []string
+	for i := range matching {
+		trigger := &matching[i]
+		logger := logging.Log.WithField("job_id", job.JobID).
+			WithField("trigger_id", trigger.TriggerID).
+			WithField("downstream_project_id", trigger.DownstreamProjectID)
+		if started[trigger.DownstreamProjectID] {
+			continue
+		}
+		if chain[trigger.DownstreamProjectID] {
+			logger.Warn("Skipping downstream trigger: the downstream project already ran in this chain")
+			continue
+		}
+		if depth >= models.MaxDownstreamDepth {
+			logger.WithField("depth", depth).Warn("Skipping downstream trigger: the chain of downstream jobs is too long")
+			continue
+		}
+		project, err := tp.store.GetProjectByID(ctx, trigger.DownstreamProjectID)
+		if err != nil {
+			logger.WithError(err).Error("Failed to get downstream project")
+			continue
+		}
+		if !project.Enabled {
+			logger.Debug("Skipping downstream trigger: the downstream project is disabled")
+			continue
+		}
+		downstream := buildDownstreamJob(job, upstreamProject, trigger, project)
+		if err := tp.submitNewJob(ctx, downstream, hooks.SourceDownstream); err != nil {
+			logger.WithError(err).Error("Failed to start downstream job")
+			continue
+		}
+		started[trigger.DownstreamProjectID] = true
+		created = append(created, downstream.JobID)
+		logger.WithField("downstream_job_id", downstream.JobID).WithField("status", downstream.Status).Info("Started downstream job")
+	}
+	return created, nil
+}
+
+// upstreamProjects walks job's UpstreamJobID chain and returns the projects
+// it passed through, job's own included, and the number of upstream jobs
+// before job.
+func (tp *TriggerProcessor) upstreamProjects(ctx context.Context, job *models.Job) (map[string]bool, int, error) {
+	projects := map[string]bool{*job.ProjectID: true}
+	depth := 0
+	for current := job; current.UpstreamJobID != nil && *current.UpstreamJobID != ""; depth++ {
+		if depth >= models.MaxDownstreamDepth {
+			break
+		}
+		upstream, err := tp.store.GetJobByID(ctx, *current.UpstreamJobID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get upstream job: %w", err)
+		}
+		if upstream.ProjectID != nil {
+			projects[*upstream.ProjectID] = true
+		}
+		current = upstream
+	}
+	return projects, depth, nil
+}
+
+// buildDownstreamJob constructs the eval job trigger starts in project
+// when upstream finishes. Like a push eval job, it runs runnerlib eval on
+// the downstream project's branch, here with the upstream_completed event
+// and the upstream job described in REACTORCIDE_UPSTREAM_* variables.
+func buildDownstreamJob(upstream *models.Job, upstreamProject *models.Project, trigger *models.DownstreamTrigger, project *models.Project) *models.Job {
+	sourceType := models.SourceTypeGit
+	sourceURL := project.RepoURL
+	ref := trigger.Ref
+	upstreamJobID := upstream.JobID
+
+	envVars := models.JSONB{
+		"REACTORCIDE_CI":                  "true",
+		"REACTORCIDE_EVENT_TYPE":          string(vcs.EventUpstreamCompleted),
+		"REACTORCIDE_SOURCE_URL":          sourceURL,
+		"REACTORCIDE_BRANCH":              ref,
+		"REACTORCIDE_UPSTREAM_PROJECT":    upstreamProject.Name,
+		"REACTORCIDE_UPSTREAM_JOB_ID":     upstream.JobID,
+		"REACTORCIDE_UPSTREAM_JOB_NAME":   upstream.Name,
+		"REACTORCIDE_UPSTREAM_JOB_STATUS": upstream.Status,
+	}
+	if upstream.CommitSHA != nil {
+		envVars["REACTORCIDE_UPSTREAM_SHA"] = *upstream.CommitSHA
+	}
+
+	ciSourceType := models.SourceTypeGit
+	ciSourceURL := sourceURL
+	ciSourceRef := ref
+	if project.DefaultCISourceURL != "" {
+		if project.DefaultCISourceType != "" {
+			ciSourceType = project.DefaultCISourceType
+		}
+		ciSourceURL = project.DefaultCISourceURL
+		ciSourceRef = project.DefaultCISourceRef
+	}
+	envVars["REACTORCIDE_CI_SOURCE_URL"] = ciSourceURL
+	envVars["REACTORCIDE_CI_SOURCE_REF"] = ciSourceRef
+
+	jobName := fmt.Sprintf("eval: %s %s in %s", upstream.Name, upstream.Status, upstreamProject.Name)
+	if project.ConfigPath != "" {
+		envVars["REACTORCIDE_CONFIG_PATH"] = project.ConfigPath
+		jobName = fmt.Sprintf("%s [%s]", jobName, project.ConfigPath)
+	}
+
+	jobCommand := project.DefaultJobCommand
+	if jobCommand == "" {
+		jobCommand = "runnerlib eval --event-type $REACTORCIDE_EVENT_TYPE --branch $REACTORCIDE_BRANCH"
+	}
+	userID := config.AutomationUserID()
+	if userID == "" {
+		userID = upstream.UserID
+	}
+
+	job := &models.Job{
+		UserID:        userID,
+		ProjectID:     &project.ProjectID,
+		Name:          jobName,
+		Description:   fmt.Sprintf("Eval job for %s on %s", vcs.EventUpstreamCompleted, project.Name),
+		Status:        "submitted",
+		SourceURL:     &sourceURL,
+		SourceRef:     &ref,
+		SourceType:    &sourceType,
+		CISourceType:  &ciSourceType,
+		CISourceURL:   &ciSourceURL,
+		CISourceRef:   &ciSourceRef,
+		JobCommand:    jobCommand,
+		RunnerImage:   project.DefaultRunnerImage,
+		JobEnvVars:    envVars,
+		Priority:      5,
+		QueueName:     project.DefaultQueueName,
+		AwaitChildren: project.AwaitChildJobs,
+		UpstreamJobID: &upstreamJobID,
+		SecretScopes:  append(pq.StringArray(nil), upstream.SecretScopes...),
+	}
+	if project.DefaultTimeoutSeconds > 0 {
+		job.TimeoutSeconds = project.DefaultTimeoutSeconds
+	}
+	return job
+}
@@ -0,0 +1,158 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// downstreamStore is fanInStore plus projects and downstream triggers.
+type downstreamStore struct {
+	*fanInStore
+	projects map[string]*models.Project
+	triggers []models.DownstreamTrigger
+	created  int
+}
+
+func newDownstreamStore(triggers ...models.DownstreamTrigger) *downstreamStore {
+	s := &downstreamStore{fanInStore: newFanInStore(), projects: map[string]*models.Project{}, triggers: triggers}
+	for _, id := range []string{"lib", "svc", "app"} {
+		s.projects[id] = &models.Project{ProjectID: id, Name: id, RepoURL: "https://github.com/org/" + id + ".git", Enabled: true}
+	}
+	return s
+}
+
+func (s *downstreamStore) GetProjectByID(ctx context.Context, projectID string) (*models.Project, error) {
+	p, ok := s.projects[projectID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return p, nil
+}
+
+func (s *downstreamStore) CreateJob(ctx context.Context, job *models.Job) error {
+	s.created++
+	job.JobID = fmt.Sprintf("downstream-%d", s.created)
+	return s.UpdateJob(ctx, job)
+}
+
+func (s *downstreamStore) ListJobs(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]models.Job, error) {
+	var out []models.Job
+	for _, j := range s.jobs {
+		if j.UpstreamJobID != nil && *j.UpstreamJobID == filters["upstream_job_id"] {
+			out = append(out, *j)
+		}
+	}
+	return out, nil
+}
+
+func (s *downstreamStore) ListDownstreamTriggers(ctx context.Context, projectID string) ([]models.DownstreamTrigger, error) {
+	var out []models.DownstreamTrigger
+	for _, t := range s.triggers {
+		if t.ProjectID == projectID {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func downstreamTrigger(from, to, on string) models.DownstreamTrigger {
+	return models.DownstreamTrigger{
+		TriggerID:           from + "->" + to,
+		ProjectID:           from,
+		DownstreamProjectID: to,
+		JobNameMatch:        models.SecretGrantMatchExact,
+		JobNamePattern:      "release",
+		On:                  on,
+		Ref:                 "main",
+	}
+}
+
+func finishedJob(s *downstreamStore, id, projectID, status string) *models.Job {
+	job := &models.Job{JobID: id, ProjectID: &projectID, Name: "release", Status: status}
+	s.jobs[id] = job
+	return job
+}
+
+func TestFireDownstreamTriggers(t *testing.T) {
+	s := newDownstreamStore(
+		downstreamTrigger("lib", "svc", models.DownstreamOnSuccess),
+		downstreamTrigger("lib", "app", models.DownstreamOnFailure),
+	)
+	tp := NewTriggerProcessor(s, nil)
+	upstream := finishedJob(s, "lib-job", "lib", "completed")
+
+	ids, err := tp.FireDownstreamTriggers(context.Background(), upstream)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ids) != 1 {
+		t.Fatalf("expected one downstream job, got %v", ids)
+	}
+	job := s.jobs[ids[0]]
+	if *job.ProjectID != "svc" || job.UpstreamJobID == nil || *job.UpstreamJobID != "lib-job" {
+		t.Errorf("downstream job = project %v upstream %v, want svc started by lib-job", *job.ProjectID, job.UpstreamJobID)
+	}
+	if job.JobEnvVars["REACTORCIDE_EVENT_TYPE"] != "upstream_completed" || job.JobEnvVars["REACTORCIDE_UPSTREAM_JOB_ID"] != "lib-job" {
+		t.Errorf("unexpected env %v", job.JobEnvVars)
+	}
+	if *job.SourceURL != "https://github.com/org/svc.git" || *job.SourceRef != "main" {
+		t.Errorf("source = %s@%s, want svc at main", *job.SourceURL, *job.SourceRef)
+	}
+
+	// Firing again for the same job starts nothing new.
+	ids, err = tp.FireDownstreamTriggers(context.Background(), upstream)
+	if err != nil || len(ids) != 0 {
+		t.Errorf("second fire = %v, %v; want nothing", ids, err)
+	}
+}
+
+func TestFireDownstreamTriggers_NotFired(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+		setup  func(s *downstreamStore)
+	}{
+		{"cancelled", "cancelled", nil},
+		{"held on children", "running", nil},
+		{"other job name", "completed", func(s *downstreamStore) { s.triggers[0].JobNamePattern = "publish" }},
+		{"disabled downstream", "completed", func(s *downstreamStore) { s.projects["svc"].Enabled = false }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newDownstreamStore(downstreamTrigger("lib", "svc", models.DownstreamOnAlways))
+			if tt.setup != nil {
+				tt.setup(s)
+			}
+			ids, err := NewTriggerProcessor(s, nil).FireDownstreamTriggers(context.Background(), finishedJob(s, "lib-job", "lib", tt.status))
+			if err != nil || len(ids) != 0 {
+				t.Errorf("FireDownstreamTriggers = %v, %v; want nothing", ids, err)
+			}
+		})
+	}
+}
+
+func TestFireDownstreamTriggers_StopsCycles(t *testing.T) {
+	// lib -> svc -> lib formed despite the create-time check.
+	s := newDownstreamStore(
+		downstreamTrigger("lib", "svc", models.DownstreamOnSuccess),
+		downstreamTrigger("svc", "lib", models.DownstreamOnSuccess),
+	)
+	tp := NewTriggerProcessor(s, nil)
+
+	ids, err := tp.FireDownstreamTriggers(context.Background(), finishedJob(s, "lib-job", "lib", "completed"))
+	if err != nil || len(ids) != 1 {
+		t.Fatalf("first hop = %v, %v; want one job", ids, err)
+	}
+	svcJob := s.jobs[ids[0]]
+	svcJob.Name = "release"
+	svcJob.Status = "completed"
+
+	ids, err = tp.FireDownstreamTriggers(context.Background(), svcJob)
+	if err != nil || len(ids) != 0 {
+		t.Errorf("second hop = %v, %v; want the cycle back to lib skipped", ids, err)
+	}
+}
@@ -0,0 +1,102 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/secrets"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// cloudRoleStore is the narrow store interface needed to list a project's
+// cloud role mappings.
+type cloudRoleStore interface {
+	ListProjectCloudRoles(ctx context.Context, projectID string) ([]models.ProjectCloudRole, error)
+}
+
+// mintDynamicCredentials mints short-lived credentials for each of the
+// job's project cloud roles that applies to the job, and adds them to env.
+// The caller revokes the returned credentials once the job ends; they are
+// also revoked here if a later role fails to mint.
+func (jp *JobProcessor) mintDynamicCredentials(ctx context.Context, job *models.Job, env map[string]string) ([]*secrets.DynamicCredential, error) {
+	if job.ProjectID == nil || *job.ProjectID == "" {
+		return nil, nil
+	}
+	roleStore, ok := jp.store.(cloudRoleStore)
+	if !ok {
+		return nil, nil
+	}
+	roles, err := roleStore.ListProjectCloudRoles(ctx, *job.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("listing project cloud roles: %w", err)
+	}
+	var applicable []models.ProjectCloudRole
+	for _, role := range roles {
+		if matchGrantPattern(role.JobNameMatch, role.JobNamePattern, job.Name, true) {
+			applicable = append(applicable, role)
+		}
+	}
+	if len(applicable) == 0 {
+		return nil, nil
+	}
+	if jp.config.DynamicSecrets == nil {
+		return nil, fmt.Errorf("project maps cloud roles but dynamic secrets are disabled on this worker")
+	}
+
+	var creds []*secrets.DynamicCredential
+	setBy := map[string]string{}
+	for _, role := range applicable {
+		cred, err := jp.config.DynamicSecrets.Mint(ctx, role, "reactorcide-"+job.JobID)
+		if err != nil {
+			revokeDynamicCredentials(job, creds)
+			return nil, err
+		}
+		creds = append(creds, cred)
+		for name := range cred.Env {
+			if other, ok := setBy[name]; ok {
+				revokeDynamicCredentials(job, creds)
+				return nil, fmt.Errorf("cloud roles %s and %s both set %s; give one an env_prefix", other, role.Name, name)
+			}
+			setBy[name] = role.Name
+		}
+	}
+
+	for _, cred := range creds {
+		for name, value := range cred.Env {
+			env[name] = value
+		}
+		logging.Log.WithFields(map[string]interface{}{
+			"job_id":     job.JobID,
+			"cloud_role": cred.Role,
+			"provider":   cred.Provider,
+			"expires_at": cred.ExpiresAt,
+			"env":        sortedKeys(cred.Env),
+		}).Info("Minted dynamic cloud credentials")
+	}
+	return creds, nil
+}
+
+// revokeDynamicCredentials revokes job credentials where the provider
+// supports it; the rest expire on their own.
+func revokeDynamicCredentials(job *models.Job, creds []*secrets.DynamicCredential) {
+	for _, cred := range creds {
+		if err := cred.Revoke(context.Background()); err != nil {
+			logging.Log.WithError(err).WithFields(map[string]interface{}{
+				"job_id":     job.JobID,
+				"cloud_role": cred.Role,
+				"provider":   cred.Provider,
+			}).Warn("Failed to revoke dynamic cloud credentials; they will expire on their own")
+		}
+	}
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
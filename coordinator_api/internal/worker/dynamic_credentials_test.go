@@ -0,0 +1,91 @@
+package worker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/secrets"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type cloudRoleMockStore struct {
+	MockStore
+	roles []models.ProjectCloudRole
+}
+
+func (s *cloudRoleMockStore) ListProjectCloudRoles(ctx context.Context, projectID string) ([]models.ProjectCloudRole, error) {
+	return s.roles, nil
+}
+
+// fakeDynamicEngine mints a fixed AWS-style credential per role.
+type fakeDynamicEngine struct {
+	minted []string
+}
+
+func (f *fakeDynamicEngine) Mint(ctx context.Context, role models.ProjectCloudRole, sessionName string) (*secrets.DynamicCredential, error) {
+	f.minted = append(f.minted, role.Name+"/"+sessionName)
+	return &secrets.DynamicCredential{
+		Env:            map[string]string{"AWS_ACCESS_KEY_ID": "key-" + role.Name, "AWS_REGION": "us-east-1"},
+		SecretEnvNames: []string{"AWS_ACCESS_KEY_ID"},
+	}, nil
+}
+
+func dynamicCredentialsJob() *models.Job {
+	projectID := "project-1"
+	return &models.Job{JobID: "job-1", ProjectID: &projectID, Name: "deploy"}
+}
+
+func TestMintDynamicCredentials_InjectsMatchingRoles(t *testing.T) {
+	engine := &fakeDynamicEngine{}
+	jp := &JobProcessor{
+		store: &cloudRoleMockStore{roles: []models.ProjectCloudRole{
+			{Name: "deploy", Provider: models.CloudProviderAWS, JobNameMatch: models.SecretGrantMatchExact, JobNamePattern: "deploy"},
+			{Name: "test", Provider: models.CloudProviderAWS, JobNameMatch: models.SecretGrantMatchExact, JobNamePattern: "test"},
+		}},
+		config: &JobProcessorConfig{DynamicSecrets: secrets.NewDynamicSecrets(map[string]secrets.DynamicSecretsEngine{
+			models.CloudProviderAWS: engine,
+		})},
+	}
+
+	env := map[string]string{"EXISTING": "value"}
+	creds, err := jp.mintDynamicCredentials(context.Background(), dynamicCredentialsJob(), env)
+	require.NoError(t, err)
+	require.Len(t, creds, 1)
+	assert.Equal(t, []string{"deploy/reactorcide-job-1"}, engine.minted)
+	assert.Equal(t, "key-deploy", env["AWS_ACCESS_KEY_ID"])
+	assert.Equal(t, "value", env["EXISTING"])
+	assert.Equal(t, []string{"key-deploy"}, creds[0].SecretValues())
+}
+
+func TestMintDynamicCredentials_RejectsConflictingRoles(t *testing.T) {
+	jp := &JobProcessor{
+		store: &cloudRoleMockStore{roles: []models.ProjectCloudRole{
+			{Name: "prod", Provider: models.CloudProviderAWS},
+			{Name: "staging", Provider: models.CloudProviderAWS},
+		}},
+		config: &JobProcessorConfig{DynamicSecrets: secrets.NewDynamicSecrets(map[string]secrets.DynamicSecretsEngine{
+			models.CloudProviderAWS: &fakeDynamicEngine{},
+		})},
+	}
+
+	_, err := jp.mintDynamicCredentials(context.Background(), dynamicCredentialsJob(), map[string]string{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "env_prefix")
+}
+
+func TestMintDynamicCredentials_RequiresEngineWhenProjectMapsRoles(t *testing.T) {
+	jp := &JobProcessor{
+		store:  &cloudRoleMockStore{roles: []models.ProjectCloudRole{{Name: "prod", Provider: models.CloudProviderAWS}}},
+		config: &JobProcessorConfig{},
+	}
+	_, err := jp.mintDynamicCredentials(context.Background(), dynamicCredentialsJob(), map[string]string{})
+	assert.Error(t, err)
+
+	// Projects without cloud roles don't need one.
+	jp.store = &cloudRoleMockStore{}
+	creds, err := jp.mintDynamicCredentials(context.Background(), dynamicCredentialsJob(), map[string]string{})
+	assert.NoError(t, err)
+	assert.Empty(t, creds)
+}
@@ -0,0 +1,176 @@
+package worker
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+const (
+	// maxEnvFileSize bounds a job's env file, before and after rendering.
+	maxEnvFileSize = 64 << 10
+)
+
+// envFileNamePattern is what an env file's variable names must look like.
+var envFileNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// errEnvFileSecrets stands in for the secret getter when an env file is
+// only being validated.
+var errEnvFileSecrets = errors.New("secrets are not available")
+
+// envFileLine is one KEY=value line of a job's env file, with its value
+// parsed as a template.
+type envFileLine struct {
+	number int
+	name   string
+	value  *template.Template
+}
+
+// ValidateJobEnvFile checks a job's env file (Job.JobEnvFile) parses, so a
+// job that can't render it is refused at creation rather than failing on
+// a worker. See RenderJobEnvFile for the format.
+func ValidateJobEnvFile(text string) error {
+	_, err := parseJobEnvFile(text, nil, nil)
+	return err
+}
+
+// EnvFileRender is the outcome of rendering a job's env file.
+type EnvFileRender struct {
+	// Env holds the rendered variables.
+	Env map[string]string
+	// SecretValues are the values the file read with secret or secretref,
+	// and the rendered values of the variables that read them, for
+	// masking.
+	SecretValues []string
+	// SecretEnvNames are the variables that read a secret.
+	SecretEnvNames []string
+}
+
+// RenderJobEnvFile renders a job's env file just before the job runs.
+//
+// The file has a KEY=value line per variable; blank lines and lines
+// starting with # are skipped. Each value is a Go text/template, with only
+// these functions:
+//
+//   - env NAME: the job's variable NAME, from env (empty if unset)
+//   - secret PATH KEY: the secret at PATH and KEY
+//   - secretref REF: the secret a ${secret:path:key} or secretref://path/key
+//     reference names
+//   - b64enc, b64dec: base64 encoding
+//   - toJson: the value as JSON; fromJson: a JSON document, to index into
+//   - default DEFAULT VALUE: VALUE, or DEFAULT if VALUE is empty
+//   - trim, quote
+//
+// Values are rendered against env alone, never the worker's own
+// environment, so concurrent jobs can't see each other's variables.
+// getSecret is only called for secrets the file reads.
+func RenderJobEnvFile(text string, env map[string]string, getSecret func(path, key string) (string, error)) (*EnvFileRender, error) {
+	render := &EnvFileRender{Env: make(map[string]string)}
+	lines, err := parseJobEnvFile(text, env, func(path, key string) (string, error) {
+		value, err := getSecret(path, key)
+		if err != nil {
+			return "", err
+		}
+		render.SecretValues = append(render.SecretValues, value)
+		return value, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	size := 0
+	for _, line := range lines {
+		secrets := len(render.SecretValues)
+		var b bytes.Buffer
+		if err := line.value.Execute(&b, nil); err != nil {
+			return nil, fmt.Errorf("env file line %d (%s): %w", line.number, line.name, err)
+		}
+		value := b.String()
+		if size += len(line.name) + len(value); size > maxEnvFileSize {
+			return nil, fmt.Errorf("rendered env file is larger than %d bytes", maxEnvFileSize)
+		}
+		render.Env[line.name] = value
+		if len(render.SecretValues) > secrets {
+			render.SecretEnvNames = append(render.SecretEnvNames, line.name)
+			render.SecretValues = append(render.SecretValues, value)
+		}
+	}
+	return render, nil
+}
+
+// parseJobEnvFile parses an env file's lines, with its templates' functions
+// reading env and getSecret. With a nil getSecret, reading a secret fails.
+func parseJobEnvFile(text string, env map[string]string, getSecret func(path, key string) (string, error)) ([]envFileLine, error) {
+	if len(text) > maxEnvFileSize {
+		return nil, fmt.Errorf("env file is larger than %d bytes", maxEnvFileSize)
+	}
+	if getSecret == nil {
+		getSecret = func(path, key string) (string, error) { return "", errEnvFileSecrets }
+	}
+	funcs := envFileFuncs(env, getSecret)
+	var lines []envFileLine
+	seen := make(map[string]bool)
+	for i, raw := range strings.Split(text, "\n") {
+		number := i + 1
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(strings.TrimPrefix(name, "export "))
+		if !ok || !envFileNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("env file line %d: expected KEY=value", number)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("env file line %d: %s is set twice", number, name)
+		}
+		seen[name] = true
+		tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("env file line %d (%s): %w", number, name, err)
+		}
+		lines = append(lines, envFileLine{number: number, name: name, value: tmpl})
+	}
+	return lines, nil
+}
+
+func envFileFuncs(env map[string]string, getSecret func(path, key string) (string, error)) template.FuncMap {
+	return template.FuncMap{
+		"env": func(name string) string { return env[name] },
+		"secret": func(path, key string) (string, error) {
+			return getSecret(path, key)
+		},
+		"secretref": func(ref string) (string, error) {
+			if !HasSecretRefs(ref) {
+				return "", fmt.Errorf("%q is not a secret reference", ref)
+			}
+			return ResolveSecretRefs(ref, getSecret)
+		},
+		"b64enc": func(value string) string { return base64.StdEncoding.EncodeToString([]byte(value)) },
+		"b64dec": func(value string) (string, error) {
+			decoded, err := base64.StdEncoding.DecodeString(value)
+			return string(decoded), err
+		},
+		"toJson": func(value interface{}) (string, error) {
+			encoded, err := json.Marshal(value)
+			return string(encoded), err
+		},
+		"fromJson": func(value string) (interface{}, error) {
+			var decoded interface{}
+			err := json.Unmarshal([]byte(value), &decoded)
+			return decoded, err
+		},
+		"default": func(def, value interface{}) interface{} {
+			if value == nil || value == "" {
+				return def
+			}
+			return value
+		},
+		"trim":  strings.TrimSpace,
+		"quote": func(value string) string { return fmt.Sprintf("%q", value) },
+	}
+}
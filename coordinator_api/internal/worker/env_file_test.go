@@ -0,0 +1,87 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateJobEnvFile(t *testing.T) {
+	assert.NoError(t, ValidateJobEnvFile("# comment\n\nA=plain\nexport B={{ env \"A\" | b64enc }}\nC={{ secret \"ci\" \"token\" }}\n"))
+	assert.Error(t, ValidateJobEnvFile("not a variable"))
+	assert.Error(t, ValidateJobEnvFile("1A=x"))
+	assert.Error(t, ValidateJobEnvFile("A=x\nA=y"))
+	assert.Error(t, ValidateJobEnvFile("A={{ env \"B\""))
+	assert.Error(t, ValidateJobEnvFile("A={{ exec \"ls\" }}"), "only the env file functions are available")
+	assert.Error(t, ValidateJobEnvFile("A="+strings.Repeat("x", maxEnvFileSize)))
+}
+
+func TestRenderJobEnvFile(t *testing.T) {
+	env := map[string]string{"REGION": "eu-west-1", "CONFIG": `{"bucket":{"name":"artifacts"}}`}
+	var reads []string
+	getSecret := func(path, key string) (string, error) {
+		reads = append(reads, path+":"+key)
+		if path == "missing" {
+			return "", errors.New("secret not found")
+		}
+		return "s3cr3t", nil
+	}
+
+	render, err := RenderJobEnvFile(`
+# deploy settings
+REGION_UPPER={{ env "REGION" | upper }}
+`, env, getSecret)
+	assert.Error(t, err, "unknown functions fail to parse")
+	assert.Nil(t, render)
+
+	render, err = RenderJobEnvFile(`
+# deploy settings
+AWS_REGION={{ env "REGION" }}
+BUCKET={{ (fromJson (env "CONFIG")).bucket.name }}
+STAGE={{ env "STAGE" | default "dev" }}
+AUTH={{ printf "user:%s" (secret "ci" "token") | b64enc }}
+TOKEN={{ secretref "${secret:ci:token}" }}
+ARGS={{ toJson (env "REGION") }}
+LATER=${secret:ci:other}
+`, env, getSecret)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"AWS_REGION": "eu-west-1",
+		"BUCKET":     "artifacts",
+		"STAGE":      "dev",
+		"AUTH":       "dXNlcjpzM2NyM3Q=",
+		"TOKEN":      "s3cr3t",
+		"ARGS":       `"eu-west-1"`,
+		"LATER":      "${secret:ci:other}",
+	}, render.Env)
+	assert.Equal(t, []string{"ci:token", "ci:token"}, reads, "plain references are left to the job's secret resolution")
+	assert.ElementsMatch(t, []string{"AUTH", "TOKEN"}, render.SecretEnvNames)
+	assert.Contains(t, render.SecretValues, "s3cr3t")
+	assert.Contains(t, render.SecretValues, "dXNlcjpzM2NyM3Q=")
+
+	_, err = RenderJobEnvFile(`A={{ secret "missing" "key" }}`, env, getSecret)
+	assert.ErrorContains(t, err, "line 1 (A)")
+	_, err = RenderJobEnvFile(`A={{ secretref "plain" }}`, env, getSecret)
+	assert.Error(t, err)
+	_, err = RenderJobEnvFile(`A={{ b64dec "not base64!" }}`, env, getSecret)
+	assert.Error(t, err)
+}
+
+func TestRenderJobEnvFileKeepsJobEnv(t *testing.T) {
+	jp := &JobProcessor{}
+	env := map[string]string{"REACTORCIDE_JOB_ID": "job-1", "STAGE": "prod"}
+	job := &models.Job{JobID: "job-1", JobEnvFile: "STAGE=dev\nREACTORCIDE_JOB_ID=other\nDEPLOY_ID={{ env \"REACTORCIDE_JOB_ID\" }}-{{ env \"STAGE\" }}"}
+
+	_, err := jp.renderJobEnvFile(context.Background(), job, env)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"REACTORCIDE_JOB_ID": "job-1",
+		"STAGE":              "prod",
+		"DEPLOY_ID":          "job-1-prod",
+	}, env)
+}
@@ -0,0 +1,478 @@
+package worker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/metrics"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/repourl"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// GitMirrorMountPath is where a job's git mirror appears inside the
+	// job, read-only.
+	GitMirrorMountPath = "/reactorcide/git-mirror"
+
+	// GitMirrorEnv is set to the mirror's path for jobs that have one.
+	// runnerlib clones with --reference to it, so only objects the mirror
+	// lacks come over the network.
+	GitMirrorEnv = "REACTORCIDE_GIT_REFERENCE"
+
+	// DefaultGitMirrorMaxBytes bounds the mirrors' disk use when
+	// GitMirrorConfig.MaxBytes is unset.
+	DefaultGitMirrorMaxBytes = 10 << 30
+
+	// DefaultGitMirrorRefresh is how often a mirror is fetched when
+	// GitMirrorConfig.Refresh is unset.
+	DefaultGitMirrorRefresh = 5 * time.Minute
+
+	// gitMirrorIdle is how long a mirror goes unused before the background
+	// refresh stops fetching it. It's fetched again on its next use.
+	gitMirrorIdle = 24 * time.Hour
+
+	// gitMirrorTimeout bounds a single clone or fetch.
+	gitMirrorTimeout = 30 * time.Minute
+)
+
+// GitMirrorConfig configures the worker's git mirror cache.
+type GitMirrorConfig struct {
+	// Dir holds the mirrors. The cache is disabled when it's empty.
+	Dir string
+	// MaxBytes bounds the mirrors' disk use (default:
+	// DefaultGitMirrorMaxBytes). The least recently used mirrors not in
+	// use are removed to stay under it.
+	MaxBytes int64
+	// Refresh is how often a mirror is fetched (default:
+	// DefaultGitMirrorRefresh).
+	Refresh time.Duration
+}
+
+// GitMirrors keeps bare mirrors of the git repositories this worker's jobs
+// clone, keyed by normalized repository URL. A job gets its repository's
+// mirror mounted read-only and runnerlib clones with --reference to it, so
+// a busy repository's history comes over the network once per refresh
+// rather than once per job.
+//
+// A mirror is cloned on first use and fetched when it's used more than
+// Refresh after its last fetch. Mirrors cloned without credentials are also
+// fetched in the background while they're in use. Those that needed the
+// job's checkout credentials are only fetched by jobs, which have them.
+type GitMirrors struct {
+	config GitMirrorConfig
+	logger *logrus.Entry
+	now    func() time.Time
+	git    func(ctx context.Context, env []string, args ...string) error
+
+	mu      sync.Mutex
+	mirrors map[string]*gitMirror
+}
+
+// gitMirror is one repository's mirror.
+type gitMirror struct {
+	key string
+	url string
+	dir string
+
+	// lock serializes the mirror's clones and fetches.
+	lock sync.Mutex
+
+	// Guarded by GitMirrors.mu.
+	users     int
+	usedAt    time.Time
+	fetchedAt time.Time
+	needsAuth bool
+	size      int64
+}
+
+// NewGitMirrors returns nil, disabling the cache, when config.Dir is empty
+// or git isn't installed.
+func NewGitMirrors(config GitMirrorConfig) *GitMirrors {
+	if config.Dir == "" {
+		return nil
+	}
+	logger := logging.Log.WithField("component", "git_mirror")
+	if _, err := exec.LookPath("git"); err != nil {
+		logger.Warn("git is not installed; git mirror cache disabled")
+		return nil
+	}
+	if err := os.MkdirAll(config.Dir, 0755); err != nil {
+		logger.WithError(err).Warn("Failed to create git mirror directory; git mirror cache disabled")
+		return nil
+	}
+	if config.MaxBytes <= 0 {
+		config.MaxBytes = DefaultGitMirrorMaxBytes
+	}
+	if config.Refresh <= 0 {
+		config.Refresh = DefaultGitMirrorRefresh
+	}
+	m := &GitMirrors{
+		config:  config,
+		logger:  logger,
+		now:     time.Now,
+		git:     runGit,
+		mirrors: make(map[string]*gitMirror),
+	}
+	m.load()
+	return m
+}
+
+// GitMirrorKey is the name of a repository's mirror: a hash of its
+// normalized URL, so that the https, ssh and .git forms of one repository
+// share a mirror.
+func GitMirrorKey(repoURL string) string {
+	sum := sha256.Sum256([]byte(repourl.Normalize(repoURL)))
+	return hex.EncodeToString(sum[:16])
+}
+
+// mirrorable reports whether the worker can mirror repoURL itself. It
+// fetches over HTTP(S) only: SSH keys are the job's, not the worker's.
+func mirrorable(repoURL string) bool {
+	u, err := url.Parse(repoURL)
+	if err != nil || u.Host == "" || u.User != nil {
+		return false
+	}
+	return u.Scheme == "https" || u.Scheme == "http"
+}
+
+// load picks up the mirrors a previous run of the worker left, treating
+// each as used and fetched when its directory was last modified.
+func (m *GitMirrors) load() {
+	entries, err := os.ReadDir(m.config.Dir)
+	if err != nil {
+		m.logger.WithError(err).Warn("Failed to read git mirror directory")
+		return
+	}
+	for _, entry := range entries {
+		dir := filepath.Join(m.config.Dir, entry.Name())
+		if strings.HasSuffix(entry.Name(), ".tmp") || strings.HasSuffix(entry.Name(), ".evicted") {
+			_ = os.RemoveAll(dir)
+			continue
+		}
+		if !entry.IsDir() {
+			continue
+		}
+		out, err := exec.Command("git", "--git-dir", dir, "config", "--get", "remote.origin.url").Output()
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		repoURL := strings.TrimSpace(string(out))
+		m.mirrors[entry.Name()] = &gitMirror{
+			key:       entry.Name(),
+			url:       repoURL,
+			dir:       dir,
+			usedAt:    info.ModTime(),
+			fetchedAt: info.ModTime(),
+			// Unknown; jobs refresh it either way.
+			needsAuth: true,
+			size:      dirSize(dir),
+		}
+	}
+	m.updateMetrics()
+}
+
+// Acquire returns the host directory of repoURL's mirror, cloning or
+// fetching it as needed, and marks it in use until release is called.
+// credentialsFile, if set, is a git credential store file for the fetch.
+// A mirror that can't be brought up to date is still returned if it exists:
+// --reference only saves objects, and the clone fetches what it lacks.
+func (m *GitMirrors) Acquire(ctx context.Context, repoURL, credentialsFile string) (dir string, release func(), err error) {
+	if !mirrorable(repoURL) {
+		return "", nil, fmt.Errorf("repository URL is not mirrorable")
+	}
+	key := GitMirrorKey(repoURL)
+
+	m.mu.Lock()
+	mirror, ok := m.mirrors[key]
+	if !ok {
+		mirror = &gitMirror{key: key, url: repoURL, dir: filepath.Join(m.config.Dir, key)}
+		m.mirrors[key] = mirror
+	}
+	mirror.users++
+	mirror.usedAt = m.now()
+	m.mu.Unlock()
+	release = func() {
+		m.mu.Lock()
+		mirror.users--
+		m.mu.Unlock()
+	}
+
+	if err := m.update(ctx, mirror, credentialsFile); err != nil {
+		if _, statErr := os.Stat(mirror.dir); statErr != nil {
+			release()
+			m.mu.Lock()
+			if mirror.users == 0 && m.mirrors[key] == mirror {
+				delete(m.mirrors, key)
+			}
+			m.mu.Unlock()
+			return "", nil, err
+		}
+		m.logger.WithError(err).WithField("mirror", key).Warn("Failed to refresh git mirror; using it as it is")
+	}
+	now := m.now()
+	_ = os.Chtimes(mirror.dir, now, now)
+	m.evict()
+	return mirror.dir, release, nil
+}
+
+// update clones the mirror if it's missing and fetches it if it's due.
+func (m *GitMirrors) update(ctx context.Context, mirror *gitMirror, credentialsFile string) error {
+	mirror.lock.Lock()
+	defer mirror.lock.Unlock()
+
+	m.mu.Lock()
+	due := m.now().Sub(mirror.fetchedAt) >= m.config.Refresh
+	m.mu.Unlock()
+	_, statErr := os.Stat(mirror.dir)
+	switch {
+	case statErr == nil && !due:
+		metrics.RecordGitMirrorRequest("hit")
+		return nil
+	case statErr == nil:
+		metrics.RecordGitMirrorRequest("refresh")
+		return m.fetch(ctx, mirror, credentialsFile)
+	default:
+		metrics.RecordGitMirrorRequest("miss")
+		return m.clone(ctx, mirror, credentialsFile)
+	}
+}
+
+// clone creates the mirror beside its final directory and renames it into
+// place, so a failed clone leaves nothing behind.
+func (m *GitMirrors) clone(ctx context.Context, mirror *gitMirror, credentialsFile string) error {
+	tmp := mirror.dir + ".tmp"
+	_ = os.RemoveAll(tmp)
+	started := m.now()
+	if err := m.runGit(ctx, "", credentialsFile, "clone", "--mirror", "--quiet", mirror.url, tmp); err != nil {
+		_ = os.RemoveAll(tmp)
+		metrics.RecordGitMirrorRequest("error")
+		return fmt.Errorf("cloning git mirror: %w", err)
+	}
+	if err := os.Rename(tmp, mirror.dir); err != nil {
+		_ = os.RemoveAll(tmp)
+		return fmt.Errorf("moving git mirror into place: %w", err)
+	}
+	metrics.RecordGitMirrorFetch("clone", m.now().Sub(started).Seconds())
+	m.fetched(mirror, credentialsFile != "")
+	m.logger.WithField("mirror", mirror.key).WithField("seconds", m.now().Sub(started).Seconds()).Info("Cloned git mirror")
+	return nil
+}
+
+func (m *GitMirrors) fetch(ctx context.Context, mirror *gitMirror, credentialsFile string) error {
+	started := m.now()
+	if err := m.runGit(ctx, mirror.dir, credentialsFile, "fetch", "--prune", "--quiet", "origin"); err != nil {
+		metrics.RecordGitMirrorRequest("error")
+		return fmt.Errorf("fetching git mirror: %w", err)
+	}
+	metrics.RecordGitMirrorFetch("fetch", m.now().Sub(started).Seconds())
+	m.fetched(mirror, credentialsFile != "")
+	return nil
+}
+
+func (m *GitMirrors) fetched(mirror *gitMirror, withCredentials bool) {
+	size := dirSize(mirror.dir)
+	m.mu.Lock()
+	mirror.fetchedAt = m.now()
+	mirror.needsAuth = withCredentials
+	mirror.size = size
+	m.mu.Unlock()
+	m.updateMetrics()
+}
+
+// runGit runs git non-interactively with auto gc off, since jobs may be
+// reading the mirror's objects through their alternates while it's fetched.
+// The credentials file is passed by path, so the token never appears in
+// git's arguments or environment.
+func (m *GitMirrors) runGit(ctx context.Context, dir, credentialsFile string, args ...string) error {
+	ctx, cancel := context.WithTimeout(ctx, gitMirrorTimeout)
+	defer cancel()
+	config := []string{"-c", "gc.auto=0", "-c", "credential.helper="}
+	if credentialsFile != "" {
+		config = append(config, "-c", "credential.helper=store --file "+credentialsFile, "-c", "credential.useHttpPath=true")
+	}
+	if dir != "" {
+		config = append(config, "--git-dir", dir)
+	}
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ASKPASS=true", "GIT_CONFIG_NOSYSTEM=1")
+	return m.git(ctx, env, append(config, args...)...)
+}
+
+func runGit(ctx context.Context, env []string, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = env
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git %s: %w: %s", args[len(args)-1], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// evict removes the least recently used mirrors that aren't in use until
+// the cache fits in MaxBytes. Each is renamed aside before m.mu is released,
+// so a job acquiring the repository again clones a fresh mirror rather than
+// racing the removal.
+func (m *GitMirrors) evict() {
+	m.mu.Lock()
+	var total int64
+	candidates := make([]*gitMirror, 0, len(m.mirrors))
+	for _, mirror := range m.mirrors {
+		total += mirror.size
+		if mirror.users == 0 {
+			candidates = append(candidates, mirror)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].usedAt.Before(candidates[j].usedAt) })
+	var evicted []*gitMirror
+	for _, mirror := range candidates {
+		if total <= m.config.MaxBytes {
+			break
+		}
+		if err := os.Rename(mirror.dir, mirror.dir+".evicted"); err != nil && !os.IsNotExist(err) {
+			m.logger.WithError(err).WithField("mirror", mirror.key).Warn("Failed to remove git mirror")
+			continue
+		}
+		total -= mirror.size
+		delete(m.mirrors, mirror.key)
+		evicted = append(evicted, mirror)
+	}
+	m.mu.Unlock()
+
+	for _, mirror := range evicted {
+		if err := os.RemoveAll(mirror.dir + ".evicted"); err != nil {
+			m.logger.WithError(err).WithField("mirror", mirror.key).Warn("Failed to remove git mirror")
+		}
+		metrics.RecordGitMirrorEviction()
+		m.logger.WithField("mirror", mirror.key).WithField("bytes", mirror.size).Info("Evicted git mirror")
+	}
+	if len(evicted) > 0 {
+		m.updateMetrics()
+	}
+}
+
+func (m *GitMirrors) updateMetrics() {
+	m.mu.Lock()
+	var total int64
+	for _, mirror := range m.mirrors {
+		total += mirror.size
+	}
+	count := len(m.mirrors)
+	m.mu.Unlock()
+	metrics.SetGitMirrorUsage(float64(count), float64(total))
+}
+
+// Start fetches the mirrors that are due every Refresh until ctx is
+// cancelled. It skips mirrors that needed credentials and those unused for
+// gitMirrorIdle, which are fetched on their next use.
+func (m *GitMirrors) Start(ctx context.Context) {
+	ticker := time.NewTicker(m.config.Refresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		m.refresh(ctx)
+		m.evict()
+	}
+}
+
+func (m *GitMirrors) refresh(ctx context.Context) {
+	now := m.now()
+	m.mu.Lock()
+	var due []*gitMirror
+	for _, mirror := range m.mirrors {
+		if !mirror.needsAuth && now.Sub(mirror.usedAt) < gitMirrorIdle && now.Sub(mirror.fetchedAt) >= m.config.Refresh {
+			due = append(due, mirror)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, mirror := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		mirror.lock.Lock()
+		if _, err := os.Stat(mirror.dir); err == nil {
+			if err := m.fetch(ctx, mirror, ""); err != nil && ctx.Err() == nil {
+				m.logger.WithError(err).WithField("mirror", mirror.key).Warn("Failed to refresh git mirror")
+			}
+		}
+		mirror.lock.Unlock()
+	}
+}
+
+// attachGitMirror gives a git-sourced job its repository's mirror. The
+// cache only saves time, so a job whose mirror can't be had runs without
+// it. Kubernetes jobs don't run in the worker's filesystem and get none.
+// The returned release func is nil when no mirror was attached.
+func (jp *JobProcessor) attachGitMirror(ctx context.Context, job *models.Job, jobConfig *JobConfig, workspaceDir string) func() {
+	mirrors := jp.config.GitMirrors
+	if mirrors == nil || job.SourceType == nil || *job.SourceType != models.SourceTypeGit || job.SourceURL == nil {
+		return nil
+	}
+	if _, ok := jp.runner.(*KubernetesRunner); ok {
+		return nil
+	}
+	if !mirrorable(*job.SourceURL) {
+		return nil
+	}
+	var credentialsFile string
+	if jobConfig.VCSAuth != nil {
+		credentialsFile = filepath.Join(workspaceDir, ".reactorcide", "vcs-auth", "credentials")
+	}
+	logger := logging.Log.WithField("job_id", job.JobID).WithField("source_url", *job.SourceURL)
+	// The URL was checked when the job was submitted; check it again now,
+	// as git fetches it, so its name can't since have been rebound to an
+	// internal address.
+	if err := LoadSourceURLPolicy(ctx, jp.store).Check(ctx, *job.SourceURL); err != nil {
+		logger.WithError(err).Warn("Not mirroring a source URL the policy rejects")
+		return nil
+	}
+	dir, release, err := mirrors.Acquire(ctx, *job.SourceURL, credentialsFile)
+	if err != nil {
+		logger.WithError(err).Warn("Git mirror unavailable; cloning without it")
+		return nil
+	}
+	if _, ok := jp.runner.(*HostRunner); ok {
+		jobConfig.Env[GitMirrorEnv] = dir
+	} else {
+		jobConfig.ExtraMounts = append(jobConfig.ExtraMounts, dir+":"+GitMirrorMountPath+":ro")
+		jobConfig.Env[GitMirrorEnv] = GitMirrorMountPath
+	}
+	logger.Debug("Attached git mirror")
+	return release
+}
+
+// dirSize is the total size of the files under dir.
+func dirSize(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
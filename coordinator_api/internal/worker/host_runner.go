@@ -0,0 +1,414 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+)
+
+// hostEnvPassthrough are the worker environment variables a host job
+// inherits. Everything else comes from the job's own env, as in a container;
+// these are just what the OS and its toolchains need to function.
+var hostEnvPassthrough = []string{
+	"PATH", "HOME", "USER", "LANG", "TMPDIR",
+	"SystemRoot", "SystemDrive", "windir", "ComSpec", "PATHEXT",
+	"TEMP", "TMP", "USERPROFILE", "APPDATA", "LOCALAPPDATA",
+	"ProgramData", "ProgramFiles", "ProgramFiles(x86)",
+}
+
+// HostRunnerConfig holds operator configuration for the host runner.
+type HostRunnerConfig struct {
+	// AllowedProjects are the IDs of the projects whose jobs may run on the
+	// host; "*" allows every job, including ones without a project. Empty
+	// allows none: jobs run without container isolation, so an admin has
+	// to opt projects in.
+	AllowedProjects []string
+
+	// RunAsUser is a local account that jobs run as, instead of the
+	// worker's own (Unix only; the worker must run as root to switch to
+	// it). The job's workspace is handed to the account before it starts.
+	RunAsUser string
+}
+
+// LoadHostRunnerConfig resolves HostRunnerConfig from environment variables.
+//
+// Env vars:
+//   - REACTORCIDE_HOST_ALLOWED_PROJECTS (comma-separated project IDs, or "*")
+//   - REACTORCIDE_HOST_RUN_AS_USER      (optional, no default)
+func LoadHostRunnerConfig() HostRunnerConfig {
+	var projects []string
+	for _, project := range strings.Split(os.Getenv("REACTORCIDE_HOST_ALLOWED_PROJECTS"), ",") {
+		if project = strings.TrimSpace(project); project != "" {
+			projects = append(projects, project)
+		}
+	}
+	return HostRunnerConfig{
+		AllowedProjects: projects,
+		RunAsUser:       strings.TrimSpace(os.Getenv("REACTORCIDE_HOST_RUN_AS_USER")),
+	}
+}
+
+// AllowsProject reports whether jobs of the project may run on the host.
+func (c HostRunnerConfig) AllowsProject(projectID *string) bool {
+	for _, allowed := range c.AllowedProjects {
+		if allowed == "*" || (projectID != nil && allowed == *projectID) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostAccount is the local account host jobs run as (see
+// HostRunnerConfig.RunAsUser).
+type hostAccount struct {
+	name string
+	uid  uint32
+	gid  uint32
+	home string
+}
+
+// hostProcess holds a job process started by the HostRunner.
+type hostProcess struct {
+	cmd          *exec.Cmd
+	stdoutReader *io.PipeReader
+	stdoutWriter *io.PipeWriter
+	stderrReader *io.PipeReader
+	stderrWriter *io.PipeWriter
+	done         chan struct{}
+	exitCode     int
+	exitErr      error
+}
+
+// HostRunner implements JobRunner by running jobs as processes directly on
+// the worker's host, without a container. It's the runtime for Windows and
+// macOS workers, where Linux job containers aren't available: macOS signing
+// builds need the host's keychain and Xcode, and Windows jobs run in the
+// host's cmd/PowerShell.
+//
+// It's also the static binary mode for Linux hosts where containers aren't
+// permitted: the worker binary alone runs jobs, as a constrained local
+// account (HostRunnerConfig.RunAsUser) in a temporary workspace.
+//
+// The job's image is ignored. Container paths under /job are mapped onto the
+// job's workspace directory, so the working directory and /job/... env
+// values resolve on the host, and temp files go to the workspace too. The
+// docker and builder capabilities aren't available. The worker only hands
+// it jobs of allowlisted projects (see HostRunnerConfig.AllowedProjects).
+type HostRunner struct {
+	config  HostRunnerConfig
+	account *hostAccount
+
+	processes map[string]*hostProcess
+	mu        sync.RWMutex
+}
+
+// NewHostRunner creates a new host process job runner configured from the
+// environment (see LoadHostRunnerConfig).
+func NewHostRunner() (*HostRunner, error) {
+	return NewHostRunnerWithConfig(LoadHostRunnerConfig())
+}
+
+// NewHostRunnerWithConfig creates a new host process job runner.
+func NewHostRunnerWithConfig(config HostRunnerConfig) (*HostRunner, error) {
+	hr := &HostRunner{config: config, processes: make(map[string]*hostProcess)}
+	if config.RunAsUser != "" {
+		account, err := lookupHostAccount(config.RunAsUser)
+		if err != nil {
+			return nil, fmt.Errorf("host run-as user %q: %w", config.RunAsUser, err)
+		}
+		hr.account = account
+	}
+
+	logger := logging.Log.WithField("allowed_projects", strings.Join(config.AllowedProjects, ","))
+	if hr.account != nil {
+		logger = logger.WithField("run_as_user", hr.account.name)
+	} else if os.Geteuid() == 0 {
+		logger.Warn("Host runner has no REACTORCIDE_HOST_RUN_AS_USER and the worker runs as root; host jobs will run as root")
+	}
+	if len(config.AllowedProjects) == 0 {
+		logger.Warn("Host runner has no allowed projects (REACTORCIDE_HOST_ALLOWED_PROJECTS); every job will be rejected")
+	}
+	logger.Info("Host runner initialized; jobs run as processes on this host without isolation")
+	return hr, nil
+}
+
+// Config returns the runner's configuration.
+func (hr *HostRunner) Config() HostRunnerConfig {
+	return hr.config
+}
+
+// SpawnJob starts the job command as a host process.
+func (hr *HostRunner) SpawnJob(ctx context.Context, config *JobConfig) (string, error) {
+	logger := logging.Log.WithField("job_id", config.JobID)
+
+	if err := hr.validateConfig(config); err != nil {
+		return "", fmt.Errorf("invalid job configuration: %w", err)
+	}
+	if config.RunAsUser != "" {
+		logger.WithField("run_as_user", config.RunAsUser).Warn("Host runner ignores the job's run_as_user; the job runs as the host runner's account")
+	}
+
+	workingDir := hostJobPath(config, config.WorkingDir)
+	if workingDir == "" {
+		workingDir = config.WorkspaceDir
+	}
+	for _, dir := range []string{workingDir, hostTempDir(config)} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create job directory: %w", err)
+		}
+	}
+	if hr.account != nil {
+		if err := chownHostWorkspace(config.WorkspaceDir, hr.account); err != nil {
+			return "", fmt.Errorf("failed to hand workspace to %s: %w", hr.account.name, err)
+		}
+	}
+
+	processID := fmt.Sprintf("reactorcide-job-%s", config.JobID)
+
+	// Not CommandContext: the process outlives SpawnJob's context and is
+	// ended through Stop/Cleanup, as a container would be.
+	cmd := exec.Command(config.Command[0], config.Command[1:]...)
+	cmd.Dir = workingDir
+	cmd.Env = hostJobEnv(config, hr.account)
+	configureHostProcess(cmd, hr.account)
+
+	stdoutReader, stdoutWriter := io.Pipe()
+	stderrReader, stderrWriter := io.Pipe()
+	cmd.Stdout = stdoutWriter
+	cmd.Stderr = stderrWriter
+
+	if err := cmd.Start(); err != nil {
+		stdoutWriter.Close()
+		stderrWriter.Close()
+		return "", fmt.Errorf("failed to start job process: %w", err)
+	}
+
+	proc := &hostProcess{
+		cmd:          cmd,
+		stdoutReader: stdoutReader,
+		stdoutWriter: stdoutWriter,
+		stderrReader: stderrReader,
+		stderrWriter: stderrWriter,
+		done:         make(chan struct{}),
+	}
+
+	hr.mu.Lock()
+	hr.processes[processID] = proc
+	hr.mu.Unlock()
+
+	go func() {
+		err := cmd.Wait()
+		if err != nil {
+			if exitErr, ok := err.(*exec.ExitError); ok {
+				proc.exitCode = hostExitCode(exitErr)
+			} else {
+				proc.exitCode = -1
+				proc.exitErr = err
+			}
+		}
+		stdoutWriter.Close()
+		stderrWriter.Close()
+		close(proc.done)
+	}()
+
+	logger.WithFields(map[string]interface{}{
+		"process_id":  processID,
+		"pid":         cmd.Process.Pid,
+		"working_dir": workingDir,
+	}).Info("Host job process started successfully")
+	return processID, nil
+}
+
+// StreamLogs returns the job process's stdout and stderr.
+func (hr *HostRunner) StreamLogs(ctx context.Context, processID string) (stdout io.ReadCloser, stderr io.ReadCloser, err error) {
+	proc, ok := hr.process(processID)
+	if !ok {
+		return nil, nil, fmt.Errorf("no process found for %s", processID)
+	}
+	return proc.stdoutReader, proc.stderrReader, nil
+}
+
+// WaitForCompletion waits for the job process to exit and returns its exit
+// code.
+func (hr *HostRunner) WaitForCompletion(ctx context.Context, processID string) (int, error) {
+	proc, ok := hr.process(processID)
+	if !ok {
+		return -1, fmt.Errorf("no process found for %s", processID)
+	}
+
+	select {
+	case <-proc.done:
+		if proc.exitErr != nil {
+			return -1, fmt.Errorf("failed to wait for process: %w", proc.exitErr)
+		}
+		logging.Log.WithField("process_id", processID).WithField("exit_code", proc.exitCode).Info("Host job process exited")
+		return proc.exitCode, nil
+	case <-ctx.Done():
+		return -1, ctx.Err()
+	}
+}
+
+// Stop asks the job process to terminate and kills it if it hasn't exited
+// within grace. On Unix the request is SIGTERM to the job's process group;
+// Windows has no equivalent, so there the process tree is killed at once
+// whatever the grace. Stopping a process that has already exited is a no-op.
+func (hr *HostRunner) Stop(ctx context.Context, processID string, grace time.Duration) error {
+	proc, ok := hr.process(processID)
+	if !ok {
+		return nil
+	}
+	select {
+	case <-proc.done:
+		return nil
+	default:
+	}
+
+	logger := logging.Log.WithField("process_id", processID).WithField("grace", grace)
+	logger.Info("Stopping host job process")
+
+	if grace <= 0 || !terminateHostProcess(proc.cmd) {
+		killHostProcess(proc.cmd)
+		return nil
+	}
+
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+	select {
+	case <-proc.done:
+	case <-timer.C:
+		logger.Info("Host job process did not exit within grace; killing it")
+		killHostProcess(proc.cmd)
+	case <-ctx.Done():
+		killHostProcess(proc.cmd)
+		return ctx.Err()
+	}
+	return nil
+}
+
+// Cleanup kills the job process if it's still running and forgets it. The
+// workspace directory is the caller's to remove, as with container runners.
+func (hr *HostRunner) Cleanup(ctx context.Context, processID string) error {
+	hr.mu.Lock()
+	proc, ok := hr.processes[processID]
+	delete(hr.processes, processID)
+	hr.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	select {
+	case <-proc.done:
+	default:
+		killHostProcess(proc.cmd)
+	}
+	proc.stdoutWriter.Close()
+	proc.stderrWriter.Close()
+	proc.stdoutReader.Close()
+	proc.stderrReader.Close()
+
+	logging.Log.WithField("process_id", processID).Info("Host job process cleaned up successfully")
+	return nil
+}
+
+func (hr *HostRunner) process(processID string) (*hostProcess, bool) {
+	hr.mu.RLock()
+	defer hr.mu.RUnlock()
+	proc, ok := hr.processes[processID]
+	return proc, ok
+}
+
+// validateConfig validates the job configuration
+func (hr *HostRunner) validateConfig(config *JobConfig) error {
+	if len(config.Command) == 0 {
+		return fmt.Errorf("command is required")
+	}
+	if config.WorkspaceDir == "" {
+		return fmt.Errorf("workspace directory is required")
+	}
+	if config.JobID == "" {
+		return fmt.Errorf("job ID is required")
+	}
+	for _, capability := range []string{CapabilityDocker, CapabilityBuilder} {
+		if HasCapability(config.Capabilities, capability) {
+			return fmt.Errorf("capability %q is not available on the host runner", capability)
+		}
+	}
+	return nil
+}
+
+// hostJobPath maps a container path onto the host: the source mount path
+// onto SourceDir, if one is set, and anything else under /job onto the
+// workspace directory. Other paths are returned unchanged.
+func hostJobPath(config *JobConfig, path string) string {
+	if config.SourceDir != "" {
+		mount := config.SourceMountPath
+		if mount == "" {
+			mount = defaultCodeDir
+		}
+		if rel, ok := cutPathPrefix(path, mount); ok {
+			return filepath.Join(config.SourceDir, filepath.FromSlash(rel))
+		}
+	}
+	if rel, ok := cutPathPrefix(path, "/job"); ok {
+		return filepath.Join(config.WorkspaceDir, filepath.FromSlash(rel))
+	}
+	return path
+}
+
+// cutPathPrefix returns path relative to prefix if path is prefix or lies
+// under it.
+func cutPathPrefix(path, prefix string) (string, bool) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if path == prefix {
+		return "", true
+	}
+	if rest, ok := strings.CutPrefix(path, prefix+"/"); ok {
+		return rest, true
+	}
+	return "", false
+}
+
+// hostTempDir is the job's temp directory, inside its workspace so that
+// it's cleaned up with it.
+func hostTempDir(config *JobConfig) string {
+	return filepath.Join(config.WorkspaceDir, "tmp")
+}
+
+// hostJobEnv builds a host job's environment: the passthrough variables
+// from the worker's (with the run-as account's HOME and USER, and temp
+// variables pointing into the workspace), then the job's env with /job
+// paths mapped to the host.
+func hostJobEnv(config *JobConfig, account *hostAccount) []string {
+	overrides := map[string]string{}
+	for _, key := range []string{"TMPDIR", "TEMP", "TMP"} {
+		overrides[key] = hostTempDir(config)
+	}
+	if account != nil {
+		overrides["HOME"] = account.home
+		overrides["USER"] = account.name
+	}
+
+	env := make([]string, 0, len(hostEnvPassthrough)+len(config.Env))
+	for _, key := range hostEnvPassthrough {
+		if _, overridden := config.Env[key]; overridden {
+			continue
+		}
+		if value, ok := overrides[key]; ok {
+			env = append(env, key+"="+value)
+		} else if value, ok := os.LookupEnv(key); ok {
+			env = append(env, key+"="+value)
+		}
+	}
+	for key, value := range config.Env {
+		env = append(env, key+"="+hostJobPath(config, value))
+	}
+	return env
+}
@@ -0,0 +1,218 @@
+//go:build !windows
+
+package worker
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runHostJob(t *testing.T, hr *HostRunner, config *JobConfig) (string, string, int) {
+	t.Helper()
+	ctx := context.Background()
+
+	id, err := hr.SpawnJob(ctx, config)
+	require.NoError(t, err)
+	defer hr.Cleanup(ctx, id)
+
+	stdout, stderr, err := hr.StreamLogs(ctx, id)
+	require.NoError(t, err)
+	outCh := make(chan []byte, 1)
+	go func() {
+		out, _ := io.ReadAll(stdout)
+		outCh <- out
+	}()
+	errOut, _ := io.ReadAll(stderr)
+
+	exitCode, err := hr.WaitForCompletion(ctx, id)
+	require.NoError(t, err)
+	return string(<-outCh), string(errOut), exitCode
+}
+
+func TestHostRunner_RunsJobInWorkspace(t *testing.T) {
+	hr, err := NewHostRunner()
+	require.NoError(t, err)
+	workspace := t.TempDir()
+
+	stdout, stderr, exitCode := runHostJob(t, hr, &JobConfig{
+		JobID:        "host-1",
+		Command:      []string{"sh", "-c", "pwd; echo $OUTPUT_FILE; echo oops >&2; exit 3"},
+		Env:          map[string]string{"OUTPUT_FILE": "/job/out.json"},
+		WorkspaceDir: workspace,
+		WorkingDir:   "/job/src",
+	})
+
+	assert.Equal(t, 3, exitCode)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	require.Len(t, lines, 2)
+	wantDir, _ := filepath.EvalSymlinks(filepath.Join(workspace, "src"))
+	gotDir, _ := filepath.EvalSymlinks(lines[0])
+	assert.Equal(t, wantDir, gotDir)
+	assert.Equal(t, filepath.Join(workspace, "out.json"), lines[1])
+	assert.Equal(t, "oops\n", stderr)
+}
+
+func TestHostRunner_OnlyPassesThroughHostEnv(t *testing.T) {
+	t.Setenv("REACTORCIDE_HOST_RUNNER_TEST_SECRET", "worker-only")
+	hr, err := NewHostRunner()
+	require.NoError(t, err)
+
+	stdout, _, exitCode := runHostJob(t, hr, &JobConfig{
+		JobID:        "host-2",
+		Command:      []string{"sh", "-c", "echo \"[$REACTORCIDE_HOST_RUNNER_TEST_SECRET]\""},
+		WorkspaceDir: t.TempDir(),
+	})
+
+	assert.Equal(t, 0, exitCode)
+	assert.Equal(t, "[]\n", stdout)
+}
+
+func TestHostRunner_TempDirInWorkspace(t *testing.T) {
+	hr, err := NewHostRunner()
+	require.NoError(t, err)
+	workspace := t.TempDir()
+
+	stdout, _, exitCode := runHostJob(t, hr, &JobConfig{
+		JobID:        "host-6",
+		Command:      []string{"sh", "-c", "echo $TMPDIR; test -d $TMPDIR"},
+		WorkspaceDir: workspace,
+	})
+
+	assert.Equal(t, 0, exitCode)
+	assert.Equal(t, filepath.Join(workspace, "tmp")+"\n", stdout)
+}
+
+func TestHostRunner_RunAsUser(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("switching accounts requires root")
+	}
+	account, err := lookupHostAccount("nobody")
+	if err != nil {
+		t.Skipf("no nobody account: %v", err)
+	}
+	hr, err := NewHostRunnerWithConfig(HostRunnerConfig{RunAsUser: "nobody"})
+	require.NoError(t, err)
+	workspace := t.TempDir()
+	require.NoError(t, os.Chmod(filepath.Dir(workspace), 0755))
+
+	stdout, stderr, exitCode := runHostJob(t, hr, &JobConfig{
+		JobID:        "host-7",
+		Command:      []string{"sh", "-c", "id -u; touch \"$MARKER\""},
+		Env:          map[string]string{"MARKER": "/job/written"},
+		WorkspaceDir: workspace,
+	})
+
+	assert.Equal(t, 0, exitCode, stderr)
+	assert.Equal(t, fmt.Sprintf("%d\n", account.uid), stdout)
+	assert.FileExists(t, filepath.Join(workspace, "written"))
+}
+
+func TestNewHostRunnerWithConfig_RejectsRoot(t *testing.T) {
+	_, err := NewHostRunnerWithConfig(HostRunnerConfig{RunAsUser: "root"})
+	assert.Error(t, err)
+}
+
+func TestHostRunnerConfig(t *testing.T) {
+	t.Setenv("REACTORCIDE_HOST_ALLOWED_PROJECTS", " project-a, ,project-b ")
+	t.Setenv("REACTORCIDE_HOST_RUN_AS_USER", "")
+	config := LoadHostRunnerConfig()
+	assert.Equal(t, []string{"project-a", "project-b"}, config.AllowedProjects)
+
+	projectA, projectC := "project-a", "project-c"
+	assert.True(t, config.AllowsProject(&projectA))
+	assert.False(t, config.AllowsProject(&projectC))
+	assert.False(t, config.AllowsProject(nil))
+	assert.False(t, HostRunnerConfig{}.AllowsProject(&projectA))
+	assert.True(t, HostRunnerConfig{AllowedProjects: []string{"*"}}.AllowsProject(nil))
+}
+
+func TestValidateJob_HostRunnerAllowlist(t *testing.T) {
+	hr, err := NewHostRunnerWithConfig(HostRunnerConfig{AllowedProjects: []string{"project-a"}})
+	require.NoError(t, err)
+	jp := NewJobProcessor(nil, hr, false)
+
+	projectA, projectB := "project-a", "project-b"
+	assert.NoError(t, jp.validateJob(&models.Job{JobCommand: "make", ProjectID: &projectA}))
+	assert.Error(t, jp.validateJob(&models.Job{JobCommand: "make", ProjectID: &projectB}))
+	assert.Error(t, jp.validateJob(&models.Job{JobCommand: "make"}))
+}
+
+func TestHostRunner_Stop(t *testing.T) {
+	hr, err := NewHostRunner()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	id, err := hr.SpawnJob(ctx, &JobConfig{
+		JobID:        "host-3",
+		Command:      []string{"sh", "-c", "trap 'echo stopping; exit 0' TERM; echo ready; while true; do sleep 0.1; done"},
+		WorkspaceDir: t.TempDir(),
+	})
+	require.NoError(t, err)
+	defer hr.Cleanup(ctx, id)
+	stdout, stderr, err := hr.StreamLogs(ctx, id)
+	require.NoError(t, err)
+	go io.Copy(io.Discard, stderr)
+	lines := bufio.NewScanner(stdout)
+	require.True(t, lines.Scan())
+	require.Equal(t, "ready", lines.Text())
+	go func() {
+		for lines.Scan() {
+		}
+	}()
+
+	// The job traps SIGTERM and exits cleanly within its grace.
+	require.NoError(t, hr.Stop(ctx, id, 5*time.Second))
+	exitCode, err := hr.WaitForCompletion(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, 0, exitCode)
+
+	// Stopping an exited job is a no-op.
+	assert.NoError(t, hr.Stop(ctx, id, time.Second))
+}
+
+func TestHostRunner_RejectsContainerCapabilities(t *testing.T) {
+	hr, err := NewHostRunner()
+	require.NoError(t, err)
+
+	_, err = hr.SpawnJob(context.Background(), &JobConfig{
+		JobID:        "host-4",
+		Command:      []string{"true"},
+		WorkspaceDir: os.TempDir(),
+		Capabilities: []string{CapabilityDocker},
+	})
+	assert.Error(t, err)
+}
+
+func TestHostRunner_StopWithoutGraceKills(t *testing.T) {
+	hr, err := NewHostRunner()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	id, err := hr.SpawnJob(ctx, &JobConfig{
+		JobID:        "host-5",
+		Command:      []string{"sleep", "60"},
+		WorkspaceDir: t.TempDir(),
+	})
+	require.NoError(t, err)
+	defer hr.Cleanup(ctx, id)
+	stdout, stderr, err := hr.StreamLogs(ctx, id)
+	require.NoError(t, err)
+	go io.Copy(io.Discard, stdout)
+	go io.Copy(io.Discard, stderr)
+
+	require.NoError(t, hr.Stop(ctx, id, 0))
+	exitCode, err := hr.WaitForCompletion(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, 137, exitCode)
+}
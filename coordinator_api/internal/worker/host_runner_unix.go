@@ -0,0 +1,73 @@
+//go:build !windows
+
+package worker
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"syscall"
+)
+
+// configureHostProcess starts the job in its own process group, so Stop and
+// Cleanup reach everything the job spawned, and as account if one is set.
+func configureHostProcess(cmd *exec.Cmd, account *hostAccount) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if account != nil {
+		cmd.SysProcAttr.Credential = &syscall.Credential{Uid: account.uid, Gid: account.gid}
+	}
+}
+
+// lookupHostAccount resolves a local account by name.
+func lookupHostAccount(name string) (*hostAccount, error) {
+	u, err := user.Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid uid %q: %w", u.Uid, err)
+	}
+	gid, err := strconv.ParseUint(u.Gid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gid %q: %w", u.Gid, err)
+	}
+	if uid == 0 {
+		return nil, fmt.Errorf("refusing to run host jobs as root")
+	}
+	return &hostAccount{name: u.Username, uid: uint32(uid), gid: uint32(gid), home: u.HomeDir}, nil
+}
+
+// chownHostWorkspace gives account ownership of the job's workspace.
+func chownHostWorkspace(dir string, account *hostAccount) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(path, int(account.uid), int(account.gid))
+	})
+}
+
+// terminateHostProcess sends SIGTERM to the job's process group. It reports
+// whether the signal was sent.
+func terminateHostProcess(cmd *exec.Cmd) bool {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM) == nil
+}
+
+// hostExitCode reports a job killed by a signal as 128+signal, the way a
+// shell (and a container runtime) does, rather than ExitCode's -1.
+func hostExitCode(exitErr *exec.ExitError) int {
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return exitErr.ExitCode()
+}
+
+// killHostProcess sends SIGKILL to the job's process group.
+func killHostProcess(cmd *exec.Cmd) {
+	_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
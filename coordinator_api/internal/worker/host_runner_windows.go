@@ -0,0 +1,48 @@
+//go:build windows
+
+package worker
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// configureHostProcess starts the job in its own process group, detached
+// from the worker's console. Accounts aren't supported on Windows (see
+// lookupHostAccount), so account is always nil.
+func configureHostProcess(cmd *exec.Cmd, account *hostAccount) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
+
+// lookupHostAccount fails: switching accounts would need the account's
+// password or a service logon token. Run the worker service as the
+// constrained account instead.
+func lookupHostAccount(name string) (*hostAccount, error) {
+	return nil, fmt.Errorf("running host jobs as another account is not supported on Windows; run the worker as that account")
+}
+
+// chownHostWorkspace is a no-op; see lookupHostAccount.
+func chownHostWorkspace(dir string, account *hostAccount) error {
+	return nil
+}
+
+// terminateHostProcess reports false: Windows has no signal a job could
+// trap, so a graceful stop falls through to killHostProcess.
+func terminateHostProcess(cmd *exec.Cmd) bool {
+	return false
+}
+
+// hostExitCode returns the job process's exit code.
+func hostExitCode(exitErr *exec.ExitError) int {
+	return exitErr.ExitCode()
+}
+
+// killHostProcess kills the job's whole process tree; Process.Kill would
+// only end the top-level process.
+func killHostProcess(cmd *exec.Cmd) {
+	if err := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run(); err != nil {
+		_ = cmd.Process.Kill()
+	}
+}
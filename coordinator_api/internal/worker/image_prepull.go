@@ -0,0 +1,151 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/sirupsen/logrus"
+)
+
+// imagePrePullCheckInterval is how often the worker reads the pre-pull
+// setting, and so how soon it acts on a change.
+const imagePrePullCheckInterval = time.Minute
+
+// imagePrePullTimeout bounds a single image pull.
+const imagePrePullTimeout = 30 * time.Minute
+
+// imagePrePullStore is the narrow store capability behind the pre-pull.
+// See postgres_store/settings_operations.go and
+// postgres_store/worker_image_operations.go.
+type imagePrePullStore interface {
+	GetGlobalSetting(ctx context.Context, key string) (*models.GlobalSetting, error)
+	RecordWorkerImage(ctx context.Context, image *models.WorkerImage) error
+	PruneWorkerImages(ctx context.Context, workerID string, keep []string) error
+}
+
+// ImagePrePuller keeps the runner images of the "runner_image_prepull"
+// global setting pulled on this worker, so jobs using them don't wait on a
+// cold pull. It pulls them when the setting's generation changes and again
+// every interval, and records each pull's outcome for the admin API.
+type ImagePrePuller struct {
+	store    imagePrePullStore
+	puller   ImagePuller
+	workerID string
+	logger   *logrus.Entry
+	now      func() time.Time
+
+	generation int
+	pulledAt   time.Time
+}
+
+// NewImagePrePuller returns nil, disabling the pre-pull, unless the runner
+// implements ImagePuller and the store can record pulls.
+func NewImagePrePuller(s store.Store, runner JobRunner, workerID string) *ImagePrePuller {
+	logger := logging.Log.WithField("component", "image_prepull")
+	puller, ok := runner.(ImagePuller)
+	if !ok {
+		logger.Debug("Container runtime does not pull images locally; image pre-pull disabled")
+		return nil
+	}
+	ps, ok := s.(imagePrePullStore)
+	if !ok {
+		logger.Warn("Store does not support image pre-pull; image pre-pull disabled")
+		return nil
+	}
+	return &ImagePrePuller{
+		store:      ps,
+		puller:     puller,
+		workerID:   workerID,
+		logger:     logger.WithField("worker_id", workerID),
+		now:        time.Now,
+		generation: -1,
+	}
+}
+
+// Start checks the setting every imagePrePullCheckInterval until ctx is
+// cancelled, starting at once.
+func (p *ImagePrePuller) Start(ctx context.Context) {
+	ticker := time.NewTicker(imagePrePullCheckInterval)
+	defer ticker.Stop()
+	for {
+		if err := p.check(ctx); err != nil && ctx.Err() == nil {
+			p.logger.WithError(err).Warn("Image pre-pull check failed")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check pulls the configured images if they're due: the generation moved
+// or the interval passed since the last pull.
+func (p *ImagePrePuller) check(ctx context.Context) error {
+	setting, err := p.store.GetGlobalSetting(ctx, models.GlobalSettingRunnerImagePrePull)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var prePull models.RunnerImagePrePull
+	if err := json.Unmarshal(setting.Value, &prePull); err != nil {
+		return err
+	}
+
+	now := p.now()
+	if prePull.Generation == p.generation && now.Sub(p.pulledAt) < prePull.Interval() {
+		return nil
+	}
+	if prePull.Generation != p.generation {
+		if err := p.store.PruneWorkerImages(ctx, p.workerID, prePull.Images); err != nil {
+			return err
+		}
+	}
+	for _, image := range prePull.Images {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		p.pull(ctx, image, prePull.Generation)
+	}
+	p.generation = prePull.Generation
+	p.pulledAt = now
+	return nil
+}
+
+// pull pulls one image and records the outcome.
+func (p *ImagePrePuller) pull(ctx context.Context, image string, generation int) {
+	logger := p.logger.WithField("image", image)
+	pullCtx, cancel := context.WithTimeout(ctx, imagePrePullTimeout)
+	defer cancel()
+
+	started := p.now()
+	err := p.puller.PullImage(pullCtx, image)
+	finished := p.now()
+	record := &models.WorkerImage{
+		WorkerID:    p.workerID,
+		Image:       image,
+		Status:      models.WorkerImagePulled,
+		Generation:  generation,
+		PullSeconds: finished.Sub(started).Seconds(),
+		CheckedAt:   finished.UTC(),
+	}
+	if err != nil {
+		logger.WithError(err).Warn("Failed to pre-pull image")
+		record.Status = models.WorkerImageFailed
+		record.Error = err.Error()
+	} else {
+		pulledAt := finished.UTC()
+		record.PulledAt = &pulledAt
+		logger.WithField("seconds", record.PullSeconds).Info("Pre-pulled image")
+	}
+	if err := p.store.RecordWorkerImage(ctx, record); err != nil {
+		logger.WithError(err).Warn("Failed to record image pre-pull")
+	}
+}
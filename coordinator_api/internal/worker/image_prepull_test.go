@@ -0,0 +1,95 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type prePullStore struct {
+	setting *models.RunnerImagePrePull
+	records map[string]models.WorkerImage
+	pruned  [][]string
+}
+
+func (s *prePullStore) GetGlobalSetting(ctx context.Context, key string) (*models.GlobalSetting, error) {
+	if s.setting == nil || key != models.GlobalSettingRunnerImagePrePull {
+		return nil, store.ErrNotFound
+	}
+	value, _ := json.Marshal(s.setting)
+	return &models.GlobalSetting{Key: key, Value: value}, nil
+}
+
+func (s *prePullStore) RecordWorkerImage(ctx context.Context, image *models.WorkerImage) error {
+	s.records[image.Image] = *image
+	return nil
+}
+
+func (s *prePullStore) PruneWorkerImages(ctx context.Context, workerID string, keep []string) error {
+	s.pruned = append(s.pruned, keep)
+	return nil
+}
+
+type fakeImagePuller struct {
+	pulls []string
+	fail  map[string]bool
+}
+
+func (f *fakeImagePuller) PullImage(ctx context.Context, image string) error {
+	f.pulls = append(f.pulls, image)
+	if f.fail[image] {
+		return errors.New("manifest unknown")
+	}
+	return nil
+}
+
+func TestImagePrePuller(t *testing.T) {
+	s := &prePullStore{
+		setting: &models.RunnerImagePrePull{Images: []string{"runner:latest", "missing:1"}, IntervalMinutes: 60, Generation: 1},
+		records: map[string]models.WorkerImage{},
+	}
+	puller := &fakeImagePuller{fail: map[string]bool{"missing:1": true}}
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	p := &ImagePrePuller{store: s, puller: puller, workerID: "worker-a", logger: logging.Log.WithField("component", "image_prepull"), now: func() time.Time { return now }, generation: -1}
+
+	require.NoError(t, p.check(context.Background()))
+	assert.Equal(t, []string{"runner:latest", "missing:1"}, puller.pulls)
+	assert.Equal(t, models.WorkerImagePulled, s.records["runner:latest"].Status)
+	assert.NotNil(t, s.records["runner:latest"].PulledAt)
+	assert.Equal(t, 1, s.records["runner:latest"].Generation)
+	assert.Equal(t, models.WorkerImageFailed, s.records["missing:1"].Status)
+	assert.Equal(t, "manifest unknown", s.records["missing:1"].Error)
+	assert.Equal(t, [][]string{{"runner:latest", "missing:1"}}, s.pruned)
+
+	// Nothing due: same generation, within the interval.
+	now = now.Add(30 * time.Minute)
+	require.NoError(t, p.check(context.Background()))
+	assert.Len(t, puller.pulls, 2)
+
+	// The interval passed.
+	now = now.Add(30 * time.Minute)
+	require.NoError(t, p.check(context.Background()))
+	assert.Len(t, puller.pulls, 4)
+	assert.Len(t, s.pruned, 1, "the list didn't change")
+
+	// A new generation pulls at once.
+	s.setting = &models.RunnerImagePrePull{Images: []string{"runner:v2"}, Generation: 2}
+	require.NoError(t, p.check(context.Background()))
+	assert.Equal(t, "runner:v2", puller.pulls[4])
+	assert.Equal(t, []string{"runner:v2"}, s.pruned[1])
+}
+
+func TestImagePrePuller_NoSetting(t *testing.T) {
+	puller := &fakeImagePuller{}
+	p := &ImagePrePuller{store: &prePullStore{records: map[string]models.WorkerImage{}}, puller: puller, logger: logging.Log.WithField("component", "image_prepull"), now: time.Now, generation: -1}
+	require.NoError(t, p.check(context.Background()))
+	assert.Empty(t, puller.pulls)
+}
@@ -0,0 +1,209 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// heldJobBatchSize is how many held jobs ReleaseHeldJobs loads at a time.
+const heldJobBatchSize = 500
+
+// intakePauseStore is the narrow store capability behind intake pausing
+// (see postgres_store/intake_operations.go). Stores without it never hold
+// jobs.
+type intakePauseStore interface {
+	ListIntakePauses(ctx context.Context) ([]models.IntakePause, error)
+}
+
+// intakePauseMatcher is the narrow store capability HoldIfPaused uses: it
+// runs on every job submission, so it looks up only the pauses that could
+// cover the job rather than listing them all. Stores without it never
+// hold jobs.
+type intakePauseMatcher interface {
+	MatchIntakePause(ctx context.Context, queueName, projectID string) (*models.IntakePause, error)
+}
+
+// freezeWindowStore is the narrow store capability behind deploy freeze
+// windows (see postgres_store/freeze_operations.go). Stores without it
+// never hold deploy jobs.
+type freezeWindowStore interface {
+	ListFreezeWindows(ctx context.Context) ([]models.FreezeWindow, error)
+}
+
+// HoldIfPaused checks a just-created job against the active intake pauses,
+// and a deploy job against the active freeze windows. If one covers it,
+// the job is saved as "held" and HoldIfPaused returns true: the caller must
+// not submit it to Corndogs. ReleaseHeldJobs submits it once the pause is
+// lifted or the window ends.
+//
+// A failed lookup lets the job through rather than blocking intake.
+func HoldIfPaused(ctx context.Context, st store.Store, job *models.Job) bool {
+	pm, ok := st.(intakePauseMatcher)
+	if !ok {
+		return false
+	}
+	projectID := ""
+	if job.ProjectID != nil {
+		projectID = *job.ProjectID
+	}
+	pause, err := pm.MatchIntakePause(ctx, job.QueueName, projectID)
+	if err != nil {
+		logging.Log.WithError(err).WithField("job_id", job.JobID).Warn("Failed to check intake pauses; submitting job")
+		return false
+	}
+	if pause == nil {
+		return holdIfFrozen(ctx, st, job)
+	}
+
+	job.Status = models.JobStatusHeld
+	if err := st.UpdateJob(ctx, job); err != nil {
+		logging.Log.WithError(err).WithField("job_id", job.JobID).Error("Failed to mark job held")
+	}
+	logging.Log.WithFields(map[string]interface{}{
+		"job_id": job.JobID,
+		"scope":  pause.Scope,
+		"target": pause.Target,
+	}).Info("Intake paused; job held")
+	return true
+}
+
+// holdIfFrozen holds a deploy job an active freeze window covers, and
+// records the window on it.
+func holdIfFrozen(ctx context.Context, st store.Store, job *models.Job) bool {
+	fs, ok := st.(freezeWindowStore)
+	if !ok || job.Environment == "" {
+		return false
+	}
+	windows, err := fs.ListFreezeWindows(ctx)
+	if err != nil {
+		logging.Log.WithError(err).WithField("job_id", job.JobID).Warn("Failed to check freeze windows; submitting job")
+		return false
+	}
+	window := models.MatchFreeze(windows, job, time.Now())
+	if window == nil {
+		return false
+	}
+
+	job.Status = models.JobStatusHeld
+	job.FreezeWindowID = &window.WindowID
+	if err := st.UpdateJob(ctx, job); err != nil {
+		logging.Log.WithError(err).WithField("job_id", job.JobID).Error("Failed to mark job held")
+	}
+	logging.Log.WithFields(map[string]interface{}{
+		"job_id":      job.JobID,
+		"window_id":   window.WindowID,
+		"environment": job.Environment,
+		"mode":        window.Mode,
+	}).Info("Deploy freeze; job held")
+	return true
+}
+
+// frozen reports whether a freeze still holds a held job: a window covers
+// it now, or the approval window that held it still exists.
+func frozen(windows []models.FreezeWindow, job *models.Job, now time.Time) bool {
+	if models.MatchFreeze(windows, job, now) != nil {
+		return true
+	}
+	if job.FreezeWindowID == nil {
+		return false
+	}
+	for _, w := range windows {
+		if w.WindowID == *job.FreezeWindowID {
+			return w.Mode == models.FreezeModeApproval
+		}
+	}
+	return false
+}
+
+// ReleaseHeldJobs submits every held job that no remaining intake pause
+// covers and no freeze window still holds, and returns how many it
+// released. Each job is claimed with a guarded held -> submitted
+// transition first, so concurrent releases on several replicas submit each
+// job once.
+func ReleaseHeldJobs(ctx context.Context, st store.Store, corndogsClient corndogs.ClientInterface) (int, error) {
+	ps, ok := st.(intakePauseStore)
+	if !ok {
+		return 0, nil
+	}
+	pauses, err := ps.ListIntakePauses(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var windows []models.FreezeWindow
+	if fs, ok := st.(freezeWindowStore); ok {
+		if windows, err = fs.ListFreezeWindows(ctx); err != nil {
+			return 0, err
+		}
+	}
+	now := time.Now()
+
+	released := 0
+	// The iterator pages by cursor, so jobs released along the way don't
+	// shift the ones still to come, and jobs still held (covered by
+	// another pause, or failed to release) aren't seen twice.
+	it := store.NewJobIterator(store.ListJobsPager(st, map[string]interface{}{"status": models.JobStatusHeld}), heldJobBatchSize)
+	for it.Next(ctx) {
+		job := it.Job()
+		if models.MatchIntakePause(pauses, job) != nil || frozen(windows, job, now) {
+			continue
+		}
+		ok, err := ReleaseHeldJob(ctx, st, corndogsClient, job)
+		if err != nil {
+			logging.Log.WithError(err).WithField("job_id", job.JobID).Error("Failed to release held job")
+			continue
+		}
+		if ok {
+			released++
+		}
+	}
+	if err := it.Err(); err != nil {
+		return released, fmt.Errorf("failed to list held jobs: %w", err)
+	}
+	return released, nil
+}
+
+// ReleaseHeldJob claims a held job and submits it to Corndogs, whatever
+// holds it. Returns false if the job had already left "held" (released
+// elsewhere, or cancelled).
+func ReleaseHeldJob(ctx context.Context, st store.Store, corndogsClient corndogs.ClientInterface, job *models.Job) (bool, error) {
+	if gs, ok := st.(guardedJobStore); ok {
+		updated, matched, err := gs.UpdateJobStatusGuarded(ctx, job.JobID, []string{models.JobStatusHeld}, func(j *models.Job) {
+			j.Status = "submitted"
+			j.FreezeWindowID = nil
+		})
+		if err != nil || !matched {
+			return false, err
+		}
+		job = updated
+	} else {
+		job.Status = "submitted"
+		job.FreezeWindowID = nil
+		if err := st.UpdateJob(ctx, job); err != nil {
+			return false, err
+		}
+	}
+
+	if corndogsClient == nil {
+		return true, nil
+	}
+	task, err := corndogsClient.SubmitTask(ctx, BuildTaskPayload(job), int64(job.Priority))
+	if err != nil {
+		logging.Log.WithError(err).WithField("job_id", job.JobID).Error("Failed to submit released job to Corndogs")
+		job.Status = "failed"
+		job.LastError = fmt.Sprintf("failed to submit to Corndogs: %v", err)
+	} else {
+		taskID := task.Uuid
+		job.CorndogsTaskID = &taskID
+		job.Status = task.CurrentState
+	}
+	if err := st.UpdateJob(ctx, job); err != nil {
+		return true, fmt.Errorf("failed to update released job after Corndogs submission: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,158 @@
+package worker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// intakeStore adds intake pauses, their matching and held-job listing to
+// MockStore.
+type intakeStore struct {
+	MockStore
+	pauses  []models.IntakePause
+	held    []models.Job
+	matches int
+}
+
+func (s *intakeStore) ListIntakePauses(ctx context.Context) ([]models.IntakePause, error) {
+	return s.pauses, nil
+}
+
+func (s *intakeStore) MatchIntakePause(ctx context.Context, queueName, projectID string) (*models.IntakePause, error) {
+	s.matches++
+	return models.MatchIntakePause(s.pauses, &models.Job{QueueName: queueName, ProjectID: &projectID}), nil
+}
+
+func (s *intakeStore) ListJobs(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]models.Job, error) {
+	var jobs []models.Job
+	for _, j := range s.held {
+		if j.Status == filters["status"] {
+			jobs = append(jobs, j)
+		}
+	}
+	if offset >= len(jobs) {
+		return nil, nil
+	}
+	return jobs[offset:], nil
+}
+
+func TestHoldIfPaused(t *testing.T) {
+	projectID := "project-1"
+	job := func() *models.Job {
+		return &models.Job{JobID: "job-1", QueueName: "builds", ProjectID: &projectID, Status: "submitted"}
+	}
+
+	t.Run("no pause", func(t *testing.T) {
+		s := &intakeStore{pauses: []models.IntakePause{{Scope: models.IntakeScopeQueue, Target: "deploys"}}}
+		j := job()
+		assert.False(t, HoldIfPaused(context.Background(), s, j))
+		assert.Equal(t, "submitted", j.Status)
+		assert.Empty(t, s.UpdateJobCalls)
+		assert.Equal(t, 1, s.matches, "only the matching pause is looked up")
+	})
+
+	for _, pause := range []models.IntakePause{
+		{Scope: models.IntakeScopeGlobal},
+		{Scope: models.IntakeScopeQueue, Target: "builds"},
+		{Scope: models.IntakeScopeProject, Target: projectID},
+	} {
+		t.Run(pause.Scope, func(t *testing.T) {
+			s := &intakeStore{pauses: []models.IntakePause{pause}}
+			j := job()
+			assert.True(t, HoldIfPaused(context.Background(), s, j))
+			assert.Equal(t, models.JobStatusHeld, j.Status)
+			require.Len(t, s.UpdateJobCalls, 1)
+			assert.Equal(t, models.JobStatusHeld, s.UpdateJobCalls[0].Status)
+		})
+	}
+
+	t.Run("store without intake pausing", func(t *testing.T) {
+		assert.False(t, HoldIfPaused(context.Background(), &MockStore{}, job()))
+	})
+}
+
+func TestReleaseHeldJobs(t *testing.T) {
+	s := &intakeStore{
+		pauses: []models.IntakePause{{Scope: models.IntakeScopeQueue, Target: "deploys"}},
+		held: []models.Job{
+			{JobID: "build-job", QueueName: "builds", Status: models.JobStatusHeld},
+			{JobID: "deploy-job", QueueName: "deploys", Status: models.JobStatusHeld},
+		},
+	}
+	client := corndogs.NewMockClient()
+
+	released, err := ReleaseHeldJobs(context.Background(), s, client)
+	require.NoError(t, err)
+	assert.Equal(t, 1, released, "the deploys queue is still paused")
+	assert.Equal(t, 1, client.GetSubmitTaskCallCount())
+
+	require.NotEmpty(t, s.UpdateJobCalls)
+	last := s.UpdateJobCalls[len(s.UpdateJobCalls)-1]
+	assert.Equal(t, "build-job", last.JobID)
+	assert.Equal(t, "submitted", last.Status)
+	assert.NotNil(t, last.CorndogsTaskID)
+}
+
+// freezeIntakeStore adds freeze windows to intakeStore.
+type freezeIntakeStore struct {
+	intakeStore
+	windows []models.FreezeWindow
+}
+
+func (s *freezeIntakeStore) ListFreezeWindows(ctx context.Context) ([]models.FreezeWindow, error) {
+	return s.windows, nil
+}
+
+func TestHoldIfPaused_Freeze(t *testing.T) {
+	projectID := "project-1"
+	s := &freezeIntakeStore{windows: []models.FreezeWindow{
+		{WindowID: "window-1", ProjectID: &projectID, Environment: "production", Mode: models.FreezeModeHold, Manual: models.FreezeManualOn},
+	}}
+
+	build := &models.Job{JobID: "build-job", ProjectID: &projectID, Status: "submitted"}
+	assert.False(t, HoldIfPaused(context.Background(), s, build), "only deploy jobs are frozen")
+
+	staging := &models.Job{JobID: "staging-job", ProjectID: &projectID, Environment: "staging", Status: "submitted"}
+	assert.False(t, HoldIfPaused(context.Background(), s, staging))
+
+	deploy := &models.Job{JobID: "deploy-job", ProjectID: &projectID, Environment: "production", Status: "submitted"}
+	assert.True(t, HoldIfPaused(context.Background(), s, deploy))
+	assert.Equal(t, models.JobStatusHeld, deploy.Status)
+	require.NotNil(t, deploy.FreezeWindowID)
+	assert.Equal(t, "window-1", *deploy.FreezeWindowID)
+}
+
+func TestReleaseHeldJobs_Freeze(t *testing.T) {
+	projectID := "project-1"
+	holdWindow, approvalWindow := "hold-window", "approval-window"
+	s := &freezeIntakeStore{
+		intakeStore: intakeStore{held: []models.Job{
+			{JobID: "hold-job", ProjectID: &projectID, Environment: "production", Status: models.JobStatusHeld, FreezeWindowID: &holdWindow},
+			{JobID: "approval-job", ProjectID: &projectID, Environment: "production", Status: models.JobStatusHeld, FreezeWindowID: &approvalWindow},
+		}},
+		windows: []models.FreezeWindow{
+			{WindowID: holdWindow, Environment: "production", Mode: models.FreezeModeHold, Manual: models.FreezeManualOn},
+			{WindowID: approvalWindow, Environment: "production", Mode: models.FreezeModeApproval, Manual: models.FreezeManualOff},
+		},
+	}
+	client := corndogs.NewMockClient()
+
+	released, err := ReleaseHeldJobs(context.Background(), s, client)
+	require.NoError(t, err)
+	assert.Equal(t, 0, released, "the hold window is on and the approval job needs an override")
+
+	// Turning the hold window off releases its job, but not the job
+	// awaiting approval.
+	s.windows[0].Manual = models.FreezeManualOff
+	released, err = ReleaseHeldJobs(context.Background(), s, client)
+	require.NoError(t, err)
+	assert.Equal(t, 1, released)
+	last := s.UpdateJobCalls[len(s.UpdateJobCalls)-1]
+	assert.Equal(t, "hold-job", last.JobID)
+	assert.Nil(t, last.FreezeWindowID)
+}
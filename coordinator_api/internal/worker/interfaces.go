@@ -60,6 +60,82 @@ type JobRunner interface {
 	Cleanup(ctx context.Context, jobID string) error
 }
 
+// ResourceSampler is implemented by runners that can report a running job
+// container's resource consumption. It is optional: the job processor
+// samples runners that implement it for cost accounting and estimates usage
+// from wall-clock time for those that don't (see resourceUsageTracker).
+type ResourceSampler interface {
+	// SampleResources returns the container's cumulative CPU time and
+	// network bytes and its current memory usage. Sampling a container
+	// that has already exited may return a zero sample or an error;
+	// callers keep the last good one.
+	SampleResources(ctx context.Context, jobID string) (ResourceSample, error)
+}
+
+// ResourceSample is a point-in-time reading from a ResourceSampler.
+type ResourceSample struct {
+	CPUSeconds  float64 // cumulative CPU time since the container started
+	MemoryBytes uint64  // memory in use at the time of the sample
+
+	// PeakMemoryBytes is the most memory the container has used, when the
+	// runtime tracks it (zero otherwise). NetworkRxBytes and NetworkTxBytes
+	// are cumulative over the container's interfaces.
+	PeakMemoryBytes uint64
+	NetworkRxBytes  uint64
+	NetworkTxBytes  uint64
+}
+
+// DebugRunner is implemented by runners that can keep a failed job's
+// environment alive for an interactive debug session. It is optional: jobs
+// that ask for debug on failure just fail normally on runners that don't
+// implement it (see DebugSessions).
+type DebugRunner interface {
+	// StartDebugContainer snapshots the exited job container's filesystem
+	// and starts an idle container from it, with the job's mounts and
+	// working directory and the given env, that exits after ttl. It must be
+	// called before the job container is cleaned up. The returned ID is
+	// released with Cleanup.
+	StartDebugContainer(ctx context.Context, jobID string, env map[string]string, ttl time.Duration) (string, error)
+
+	// ExecDebug starts cmd in a debug container attached to a TTY.
+	ExecDebug(ctx context.Context, debugID string, cmd []string) (DebugExec, error)
+}
+
+// ExecutorDiagnoser is implemented by runners that can check their runtime
+// for worker diagnostics. It is optional: the diagnostics of workers whose
+// runner doesn't only name the runtime (see Diagnostics).
+type ExecutorDiagnoser interface {
+	// DiagnoseExecutor checks the runtime is reachable and reports its
+	// image cache, when it keeps one on the worker's host.
+	DiagnoseExecutor(ctx context.Context) models.WorkerExecutorStatus
+}
+
+// ImagePuller is implemented by runners that pull images onto the worker's
+// own host, so they can be pulled ahead of jobs. It is optional: runners
+// that don't, such as Kubernetes, where images land on whichever node runs
+// the pod, skip the pre-pull (see ImagePrePuller).
+type ImagePuller interface {
+	// PullImage pulls image, even if a copy exists locally.
+	PullImage(ctx context.Context, image string) error
+}
+
+// ImageDigester is implemented by runners that can tell which image a
+// reference resolved to on the worker's host. It is optional: the config
+// snapshots of jobs on runners that don't only record a digest the image
+// reference pins itself (see recordJobConfigSnapshot).
+type ImageDigester interface {
+	// ImageDigest returns the digest ("sha256:...") of the local copy of
+	// image.
+	ImageDigest(ctx context.Context, image string) (string, error)
+}
+
+// DebugExec is a process started by DebugRunner.ExecDebug. Reads return the
+// TTY's output and writes go to its input; Close ends the connection.
+type DebugExec interface {
+	io.ReadWriteCloser
+	Resize(ctx context.Context, rows, cols uint) error
+}
+
 // Capability constants for job requirements
 const (
 	// CapabilityDocker provides access to a docker CLI for running ad-hoc
@@ -93,6 +169,12 @@ const BuilderSidecarPort = 1234
 // CapabilityBuilder is present, pointing buildctl at the sidecar.
 const BuilderHostEnv = "BUILDKIT_HOST"
 
+// BuildCacheRefEnv is set, for CapabilityBuilder jobs on workers with a
+// remote build cache (BuilderConfig.CacheRegistry), to the registry ref of
+// the project's BuildKit layer cache, for buildctl's --import-cache and
+// --export-cache.
+const BuildCacheRefEnv = "REACTORCIDE_BUILD_CACHE_REF"
+
 // DefaultBuilderImage is the buildkitd image used for builder sidecars when
 // REACTORCIDE_BUILDER_IMAGE is not set.
 const DefaultBuilderImage = "moby/buildkit:v0.17.3"
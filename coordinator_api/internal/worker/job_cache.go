@@ -0,0 +1,258 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// JobCacheMountPath is where a job's cache appears inside the job.
+	JobCacheMountPath = "/job/cache"
+
+	// JobCacheEnv is set to JobCacheMountPath for jobs that have a cache.
+	JobCacheEnv = "REACTORCIDE_CACHE"
+
+	// DefaultJobCacheQuota is the per-project quota when
+	// JobCacheConfig.QuotaBytes is unset.
+	DefaultJobCacheQuota = 2 << 30
+)
+
+// errJobCacheOverQuota is returned when a cache alone is bigger than its
+// project's quota, so it can't be stored however much is evicted.
+var errJobCacheOverQuota = errors.New("cache is larger than the project's cache quota")
+
+// ValidateJobCacheName checks a cache name requested by a trigger spec.
+func ValidateJobCacheName(name string) error {
+	if !sharedWorkspaceNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid cache name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	return nil
+}
+
+// JobCacheConfig configures the worker's job caches.
+type JobCacheConfig struct {
+	// QuotaBytes caps the stored size of one project's caches (default:
+	// DefaultJobCacheQuota).
+	QuotaBytes int64
+}
+
+// JobCaches provides the named caches jobs keep between runs (Job.Cache):
+// dependency downloads, compiler caches, a BuildKit local cache export.
+// Unlike a shared workspace, a cache outlives the pipeline and is shared
+// by every job of the project that names it.
+//
+// Caches are kept in the object store, so any worker can restore one. They
+// are namespaced by project (by user for jobs without a project) and each
+// namespace is held to the quota: saving a cache evicts the namespace's
+// least recently saved caches until it fits. A cache is an optimisation,
+// so failing to restore or save one never fails the job.
+type JobCaches struct {
+	config      JobCacheConfig
+	objectStore objects.ObjectStore
+
+	// mu serializes quota enforcement on this worker. Workers saving
+	// into one namespace at once may briefly exceed its quota.
+	mu sync.Mutex
+}
+
+// NewJobCaches creates the worker's job caches. It returns nil without an
+// object store, as caches couldn't be shared with other workers.
+func NewJobCaches(objectStore objects.ObjectStore, config JobCacheConfig) *JobCaches {
+	if objectStore == nil {
+		return nil
+	}
+	if config.QuotaBytes <= 0 {
+		config.QuotaBytes = DefaultJobCacheQuota
+	}
+	return &JobCaches{config: config, objectStore: objectStore}
+}
+
+// jobCacheNamespace is the namespace job's caches and remote BuildKit cache
+// live in: its project, or its user for jobs without one.
+func jobCacheNamespace(job *models.Job) string {
+	if job.ProjectID != nil && *job.ProjectID != "" {
+		return *job.ProjectID
+	}
+	return "user-" + job.UserID
+}
+
+// attachedJobCache is a cache restored into a running job's workspace.
+// Release saves it.
+type attachedJobCache struct {
+	jc        *JobCaches
+	namespace string
+	name      string
+	jobDir    string
+	logger    *logrus.Entry
+
+	releaseOnce sync.Once
+}
+
+// attachJobCache gives the job its cache, if it asked for one. Kubernetes
+// jobs don't run in the worker's filesystem, so they can't have one; they
+// and workers without job caches run the job without it.
+func (jp *JobProcessor) attachJobCache(ctx context.Context, job *models.Job, workspaceDir string) *attachedJobCache {
+	if job.Cache == "" {
+		return nil
+	}
+	logger := logging.Log.WithField("job_id", job.JobID).WithField("cache", job.Cache)
+	if jp.config.JobCaches == nil {
+		logger.Warn("Job caches are not available on this worker; running the job without one")
+		return nil
+	}
+	if _, ok := jp.runner.(*KubernetesRunner); ok {
+		logger.Warn("Job caches are not supported by the Kubernetes runner; running the job without one")
+		return nil
+	}
+	cache, err := jp.config.JobCaches.Attach(ctx, job, workspaceDir)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to attach job cache; running the job without one")
+		return nil
+	}
+	return cache
+}
+
+// Attach restores the job's cache into workspaceDir, at
+// JobCacheMountPath. A cache that was never saved, or can't be restored,
+// starts empty.
+func (c *JobCaches) Attach(ctx context.Context, job *models.Job, workspaceDir string) (*attachedJobCache, error) {
+	if err := ValidateJobCacheName(job.Cache); err != nil {
+		return nil, err
+	}
+	namespace := jobCacheNamespace(job)
+	a := &attachedJobCache{
+		jc:        c,
+		namespace: namespace,
+		name:      job.Cache,
+		jobDir:    filepath.Join(workspaceDir, filepath.FromSlash(strings.TrimPrefix(JobCacheMountPath, "/job/"))),
+		logger: logging.Log.WithFields(map[string]interface{}{
+			"job_id":    job.JobID,
+			"cache":     job.Cache,
+			"namespace": namespace,
+		}),
+	}
+
+	if err := a.restore(ctx); err != nil {
+		a.logger.WithError(err).Warn("Failed to restore job cache; starting with an empty one")
+		os.RemoveAll(a.jobDir)
+	}
+	if err := os.MkdirAll(a.jobDir, 0777); err != nil {
+		return nil, fmt.Errorf("failed to attach cache %q: %w", job.Cache, err)
+	}
+	if err := chownTree(a.jobDir, 1001, 1001); err != nil {
+		a.logger.WithError(err).Warn("Failed to chown job cache - job may fail if running as non-root")
+	}
+	return a, nil
+}
+
+func (a *attachedJobCache) restore(ctx context.Context) error {
+	archive, err := a.jc.objectStore.Get(ctx, a.objectKey())
+	if errors.Is(err, objects.ErrNotFound) {
+		a.logger.Info("Starting new job cache")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+	a.logger.Info("Restoring job cache")
+	return extractTarGz(archive, a.jobDir)
+}
+
+// Release saves the cache's contents if the job succeeded, so a failed
+// job can't leave a broken cache behind. Release is safe to call more than
+// once; only the first call has any effect.
+func (a *attachedJobCache) Release(ctx context.Context, succeeded bool) error {
+	var err error
+	a.releaseOnce.Do(func() {
+		if succeeded {
+			err = a.save(ctx)
+		}
+	})
+	return err
+}
+
+func (a *attachedJobCache) save(ctx context.Context) error {
+	// The archive is written out first: its size decides what is evicted.
+	tmp, err := os.CreateTemp("", "reactorcide-cache-*.tar.gz")
+	if err != nil {
+		return fmt.Errorf("failed to save job cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	if err := writeTarGz(tmp, a.jobDir); err != nil {
+		return fmt.Errorf("failed to save job cache: %w", err)
+	}
+	info, err := tmp.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to save job cache: %w", err)
+	}
+	if _, err := tmp.Seek(0, 0); err != nil {
+		return fmt.Errorf("failed to save job cache: %w", err)
+	}
+
+	a.jc.mu.Lock()
+	defer a.jc.mu.Unlock()
+	if err := a.jc.makeRoom(ctx, a.namespace, a.objectKey(), info.Size()); err != nil {
+		return fmt.Errorf("failed to save job cache: %w", err)
+	}
+	if err := a.jc.objectStore.Put(ctx, a.objectKey(), tmp, "application/gzip"); err != nil {
+		return fmt.Errorf("failed to save job cache: %w", err)
+	}
+	a.logger.WithField("bytes", info.Size()).Info("Saved job cache")
+	return nil
+}
+
+// makeRoom evicts the namespace's least recently saved caches, other than
+// key, until a cache of size bytes stored at key fits in the quota.
+func (c *JobCaches) makeRoom(ctx context.Context, namespace, key string, size int64) error {
+	if size > c.config.QuotaBytes {
+		return errJobCacheOverQuota
+	}
+	stored, err := c.objectStore.List(ctx, jobCacheNamespacePrefix(namespace))
+	if err != nil {
+		return fmt.Errorf("failed to list caches: %w", err)
+	}
+	var others []objects.ObjectInfo
+	total := size
+	for _, object := range stored {
+		if object.Key != key {
+			others = append(others, object)
+			total += object.Size
+		}
+	}
+	sort.Slice(others, func(i, j int) bool {
+		return others[i].LastModified.Before(others[j].LastModified)
+	})
+	for _, object := range others {
+		if total <= c.config.QuotaBytes {
+			break
+		}
+		if err := c.objectStore.Delete(ctx, object.Key); err != nil && !errors.Is(err, objects.ErrNotFound) {
+			return fmt.Errorf("failed to evict cache %s: %w", object.Key, err)
+		}
+		logging.Log.WithField("cache_key", object.Key).WithField("bytes", object.Size).Info("Evicted job cache over the project's quota")
+		total -= object.Size
+	}
+	return nil
+}
+
+func (a *attachedJobCache) objectKey() string {
+	return path.Join(jobCacheNamespacePrefix(a.namespace), a.name+".tar.gz")
+}
+
+func jobCacheNamespacePrefix(namespace string) string {
+	return path.Join("caches", namespace) + "/"
+}
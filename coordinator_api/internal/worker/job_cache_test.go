@@ -0,0 +1,101 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// runJobCacheJob attaches the job's cache, runs fn in it and releases it.
+func runJobCacheJob(t *testing.T, jc *JobCaches, job *models.Job, succeeded bool, fn func(dir string)) {
+	t.Helper()
+	workspaceDir := t.TempDir()
+	attached, err := jc.Attach(context.Background(), job, workspaceDir)
+	require.NoError(t, err)
+	fn(filepath.Join(workspaceDir, "cache"))
+	require.NoError(t, attached.Release(context.Background(), succeeded))
+}
+
+func jobCacheJob(projectID, cache string) *models.Job {
+	return &models.Job{JobID: "job-" + cache, UserID: "user-1", ProjectID: &projectID, Cache: cache}
+}
+
+func TestJobCaches_RestoredOnOtherWorker(t *testing.T) {
+	objectStore := objects.NewMemoryObjectStore()
+	worker1 := NewJobCaches(objectStore, JobCacheConfig{})
+	worker2 := NewJobCaches(objectStore, JobCacheConfig{})
+
+	runJobCacheJob(t, worker1, jobCacheJob("project-1", "gomod"), true, func(dir string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "module.zip"), []byte("deps"), 0644))
+	})
+	runJobCacheJob(t, worker2, jobCacheJob("project-1", "gomod"), true, func(dir string) {
+		data, err := os.ReadFile(filepath.Join(dir, "module.zip"))
+		require.NoError(t, err)
+		assert.Equal(t, "deps", string(data))
+	})
+	runJobCacheJob(t, worker2, jobCacheJob("project-2", "gomod"), true, func(dir string) {
+		_, err := os.Stat(filepath.Join(dir, "module.zip"))
+		assert.True(t, os.IsNotExist(err), "caches are namespaced by project")
+	})
+}
+
+func TestJobCaches_FailedJobNotSaved(t *testing.T) {
+	jc := NewJobCaches(objects.NewMemoryObjectStore(), JobCacheConfig{})
+
+	runJobCacheJob(t, jc, jobCacheJob("project-1", "gomod"), true, func(dir string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "module.zip"), []byte("good"), 0644))
+	})
+	runJobCacheJob(t, jc, jobCacheJob("project-1", "gomod"), false, func(dir string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "module.zip"), []byte("half written"), 0644))
+	})
+	runJobCacheJob(t, jc, jobCacheJob("project-1", "gomod"), true, func(dir string) {
+		data, err := os.ReadFile(filepath.Join(dir, "module.zip"))
+		require.NoError(t, err)
+		assert.Equal(t, "good", string(data))
+	})
+}
+
+func TestJobCaches_QuotaEvictsLeastRecentlySaved(t *testing.T) {
+	ctx := context.Background()
+	objectStore := objects.NewMemoryObjectStore()
+	jc := NewJobCaches(objectStore, JobCacheConfig{QuotaBytes: 250})
+	for _, key := range []string{"caches/project-1/old.tar.gz", "caches/project-1/newer.tar.gz", "caches/project-2/other.tar.gz"} {
+		require.NoError(t, objectStore.Put(ctx, key, bytes.NewReader(make([]byte, 100)), "application/gzip"))
+		time.Sleep(time.Millisecond)
+	}
+
+	require.NoError(t, jc.makeRoom(ctx, "project-1", "caches/project-1/new.tar.gz", 100))
+	stored, err := objectStore.List(ctx, "caches/")
+	require.NoError(t, err)
+	var keys []string
+	for _, object := range stored {
+		keys = append(keys, object.Key)
+	}
+	assert.ElementsMatch(t, []string{"caches/project-1/newer.tar.gz", "caches/project-2/other.tar.gz"}, keys)
+
+	assert.ErrorIs(t, jc.makeRoom(ctx, "project-1", "caches/project-1/huge.tar.gz", 251), errJobCacheOverQuota)
+}
+
+func TestJobCaches_RejectsInvalidName(t *testing.T) {
+	jc := NewJobCaches(objects.NewMemoryObjectStore(), JobCacheConfig{})
+	_, err := jc.Attach(context.Background(), jobCacheJob("project-1", "../escape"), t.TempDir())
+	assert.Error(t, err)
+	assert.Nil(t, NewJobCaches(nil, JobCacheConfig{}))
+}
+
+func TestBuilderConfig_CacheRef(t *testing.T) {
+	assert.Empty(t, BuilderConfig{}.CacheRef("project-1"))
+	ref := BuilderConfig{CacheRegistry: "registry.example.com/ci-cache/"}.CacheRef(jobCacheNamespace(&models.Job{UserID: "ABC"}))
+	assert.Equal(t, "registry.example.com/ci-cache/user-abc:buildcache", ref)
+	assert.False(t, strings.Contains(ref, "//"))
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/hooks"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/metrics"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/pubsub"
@@ -45,6 +46,14 @@ type JobResult struct {
 	// kill (immediate force-Cleanup, no SIGTERM grace) rather than a
 	// graceful cancel (JobRunner.Stop).
 	Killed bool
+
+	// Usage is the container's resource consumption, for cost accounting.
+	// Zero when the job never got as far as spawning a container.
+	Usage ResourceUsage
+
+	// WorkspaceRetained is true when a debug session took ownership of
+	// WorkspaceDir (see DebugSessions.Hold); the caller must not remove it.
+	WorkspaceRetained bool
 }
 
 // DefaultCancelGrace is the fallback grace period used when
@@ -70,6 +79,11 @@ type JobProcessorConfig struct {
 	// DefaultCancelGrace).
 	CancelGrace time.Duration
 
+	// ResourceSampleInterval is how often runners that implement
+	// ResourceSampler are polled for cost accounting (default: 10s, see
+	// DefaultResourceSampleInterval).
+	ResourceSampleInterval time.Duration
+
 	// Publisher, if non-nil, is threaded into each LogShipper so chunk
 	// flushes trigger NOTIFY events to WebSocket subscribers.
 	Publisher *pubsub.Publisher
@@ -86,6 +100,38 @@ type JobProcessorConfig struct {
 	SecretsLocalPath string
 	// SecretsLocalPassword is the password for local secrets storage
 	SecretsLocalPassword string
+
+	// DebugSessions, if non-nil, keeps failed jobs with DebugOnFailure set
+	// alive for an interactive debug session.
+	DebugSessions *DebugSessions
+
+	// Platform is the platform this worker claims jobs for. Jobs targeting
+	// another platform fail validation rather than running on the wrong OS.
+	Platform Platform
+
+	// SharedWorkspaces, if non-nil, provides the shared workspaces jobs of
+	// one pipeline can request. Without it, such jobs run without one.
+	SharedWorkspaces *SharedWorkspaces
+
+	// JobCaches, if non-nil, provides the caches jobs can keep between
+	// runs. Without it, such jobs run without one.
+	JobCaches *JobCaches
+
+	// LogUploader, if non-nil, ships logs through the coordinator's chunk
+	// API in place of ObjectStore.
+	LogUploader *LogUploadClient
+
+	// DynamicSecrets, if non-nil, mints short-lived cloud credentials for
+	// the project cloud roles that apply to each job. Without it, jobs of
+	// projects that map cloud roles fail rather than run without them.
+	DynamicSecrets *secrets.DynamicSecrets
+
+	// OCISources configures pulling jobs' OCI sources.
+	OCISources OCISourceConfig
+
+	// GitMirrors, if non-nil, provides the mirrors git jobs clone with
+	// --reference to. Without it, they clone from scratch.
+	GitMirrors *GitMirrors
 }
 
 // JobExecutionContext holds context for job execution
@@ -264,10 +310,24 @@ func (jp *JobProcessor) validateJob(job *models.Job) error {
 		return fmt.Errorf("job command is required")
 	}
 
+	target, err := JobPlatform(job)
+	if err != nil {
+		return err
+	}
+	if !jp.config.Platform.Accepts(target) {
+		return fmt.Errorf("job targets %s but this worker runs %s jobs", target, jp.config.Platform)
+	}
+
+	// Host jobs run without container isolation; only allowlisted projects
+	// may use it.
+	if hr, ok := jp.runner.(*HostRunner); ok && !hr.Config().AllowsProject(job.ProjectID) {
+		return fmt.Errorf("project %q is not allowed to run jobs on the host runner (REACTORCIDE_HOST_ALLOWED_PROJECTS)", derefSecretProjectID(job.ProjectID))
+	}
+
 	// Source type is optional now (can run without source checkout)
 	if job.SourceType != nil {
 		sourceType := string(*job.SourceType)
-		if sourceType != "git" && sourceType != "copy" && sourceType != "none" {
+		if sourceType != "git" && sourceType != "copy" && sourceType != "none" && sourceType != "upload" && sourceType != "oci" {
 			return fmt.Errorf("invalid source type: %s", sourceType)
 		}
 
@@ -278,6 +338,14 @@ func (jp *JobProcessor) validateJob(job *models.Job) error {
 		if sourceType == "copy" && (job.SourcePath == nil || *job.SourcePath == "") {
 			return fmt.Errorf("source path is required for copy source type")
 		}
+
+		if sourceType == "upload" && (job.SourcePath == nil || *job.SourcePath == "") {
+			return fmt.Errorf("source path is required for upload source type")
+		}
+
+		if sourceType == "oci" && (job.SourceURL == nil || *job.SourceURL == "") {
+			return fmt.Errorf("source URL is required for oci source type")
+		}
 	}
 
 	return nil
@@ -356,26 +424,14 @@ func (jp *JobProcessor) buildJobEnv(job *models.Job) map[string]string {
 		}).Warn("Job API trigger submission not fully configured — job containers will use file-based triggers only")
 	}
 
-	// Add job-specific environment variables
-	if job.JobEnvVars != nil && len(job.JobEnvVars) > 0 {
-		for key, value := range job.JobEnvVars {
-			// Convert value to string
-			var valueStr string
-			switch v := value.(type) {
-			case string:
-				valueStr = v
-			case int, int64, float64, bool:
-				valueStr = fmt.Sprintf("%v", v)
-			default:
-				// For complex types, try JSON marshaling
-				if jsonBytes, err := json.Marshal(v); err == nil {
-					valueStr = string(jsonBytes)
-				} else {
-					valueStr = fmt.Sprintf("%v", v)
-				}
-			}
-			env[key] = valueStr
-		}
+	// Add job-specific environment variables, coerced to strings (see
+	// models.EnvValueString).
+	if keys := job.JobEnvVars.NonScalarKeys(); len(keys) > 0 {
+		logging.Log.WithField("job_id", job.JobID).WithField("keys", keys).
+			Warn("Job environment has non-scalar values; passing them as JSON")
+	}
+	for key, value := range job.JobEnvVars.EnvStrings() {
+		env[key] = value
 	}
 
 	return env
@@ -452,7 +508,16 @@ func (jp *JobProcessor) getSecretsProvider(ctx context.Context, job *models.Job)
 			return nil, fmt.Errorf("failed to get org encryption key: %w", err)
 		}
 
-		return secrets.NewDatabaseProvider(db, job.UserID, orgKey)
+		provider, err := secrets.NewDatabaseProvider(db, job.UserID, orgKey)
+		if err != nil {
+			return nil, err
+		}
+		access, err := JobSecretAccessPolicy(ctx, jp.store, job)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load secret access policy: %w", err)
+		}
+		provider.RestrictTo(access)
+		return provider, nil
 
 	default:
 		return nil, fmt.Errorf("unknown secrets storage type: %s", storageType)
@@ -481,7 +546,26 @@ func (jp *JobProcessor) resolveJobSecrets(ctx context.Context, job *models.Job,
 		}, nil
 	}
 
-	// Get secrets provider
+	if err := hooks.CheckSecretInject(ctx, job, SecretRefs(env)); err != nil {
+		return nil, err
+	}
+
+	getSecret, err := jp.jobSecretGetter(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve secrets in environment
+	result, err := ResolveSecretsInEnvFull(env, getSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// jobSecretGetter returns a getter for the secrets job may read.
+func (jp *JobProcessor) jobSecretGetter(ctx context.Context, job *models.Job) (func(path, key string) (string, error), error) {
 	provider, err := jp.getSecretsProvider(ctx, job)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get secrets provider: %w", err)
@@ -492,21 +576,42 @@ func (jp *JobProcessor) resolveJobSecrets(ctx context.Context, job *models.Job,
 		return nil, fmt.Errorf("job contains secret references but secrets are not configured")
 	}
 
-	// Create getter function for ResolveSecretsInEnvFull
-	getSecret := func(path, key string) (string, error) {
+	return func(path, key string) (string, error) {
 		if err := jp.authorizeSecretAccess(ctx, job, path, key); err != nil {
 			return "", err
 		}
 		return provider.Get(ctx, path, key)
-	}
+	}, nil
+}
 
-	// Resolve secrets in environment
-	result, err := ResolveSecretsInEnvFull(env, getSecret)
+// renderJobEnvFile renders the job's env file (see RenderJobEnvFile)
+// against env and adds its variables to env. Variables env already has,
+// the system's and the job's own, win over the file's. Secrets the file
+// reads pass the pre_secret_inject hooks, and the secret access checks,
+// one at a time.
+func (jp *JobProcessor) renderJobEnvFile(ctx context.Context, job *models.Job, env map[string]string) (*EnvFileRender, error) {
+	var getSecret func(path, key string) (string, error)
+	render, err := RenderJobEnvFile(job.JobEnvFile, env, func(path, key string) (string, error) {
+		if err := hooks.CheckSecretInject(ctx, job, []hooks.SecretRef{{Env: "job_env_file", Path: path, Key: key}}); err != nil {
+			return "", err
+		}
+		if getSecret == nil {
+			var err error
+			if getSecret, err = jp.jobSecretGetter(ctx, job); err != nil {
+				return "", err
+			}
+		}
+		return getSecret(path, key)
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	return result, nil
+	for name, value := range render.Env {
+		if _, ok := env[name]; !ok {
+			env[name] = value
+		}
+	}
+	return render, nil
 }
 
 // buildJobConfig creates a JobConfig from a models.Job
@@ -528,12 +633,13 @@ func (jp *JobProcessor) buildJobConfig(job *models.Job, workspaceDir string) *Jo
 	// Check for REACTORCIDE_JOB_SHELLCMD in job environment to override shell wrapper
 	// This allows users to specify a custom shell (e.g., "/bin/bash -c") for multiline commands
 	shellPrefix := ""
-	if job.JobEnvVars != nil {
-		if shellCmd, ok := job.JobEnvVars["REACTORCIDE_JOB_SHELLCMD"]; ok {
-			if shellCmdStr, ok := shellCmd.(string); ok {
-				shellPrefix = shellCmdStr
-			}
-		}
+	if shellCmd, ok := job.JobEnvVars.String("REACTORCIDE_JOB_SHELLCMD"); ok {
+		shellPrefix = shellCmd
+	}
+
+	// Windows hosts have no sh; jobs targeting Windows default to PowerShell.
+	if shellPrefix == "" && strings.EqualFold(job.TargetOS, OSWindows) {
+		shellPrefix = windowsShellPrefix
 	}
 
 	// Parse the job command into a proper []string array
@@ -591,7 +697,7 @@ func (jp *JobProcessor) executeWithRunnerlib(ctx context.Context, job *models.Jo
 	// Create a temporary workspace directory for this job
 	// Use /tmp/reactorcide-jobs as base so it's accessible from host (for containerd/runc)
 	// This path should be mounted as a volume shared between the worker and host
-	workspaceDir, err := os.MkdirTemp("/tmp/reactorcide-jobs", fmt.Sprintf("reactorcide-job-%s-*", job.JobID))
+	workspaceDir, err := os.MkdirTemp(jobWorkspaceBaseDir(), fmt.Sprintf("reactorcide-job-%s-*", job.JobID))
 	if err != nil {
 		logger.WithError(err).Error("Failed to create workspace directory")
 		return &JobResult{
@@ -631,10 +737,80 @@ func (jp *JobProcessor) executeWithRunnerlib(ctx context.Context, job *models.Jo
 		}
 	}
 
+	if err := jp.prepareUploadedSource(ctx, job, hostCodeDir); err != nil {
+		logger.WithError(err).Error("Failed to prepare uploaded source")
+		return &JobResult{
+			ExitCode:     1,
+			Error:        err.Error(),
+			WorkspaceDir: workspaceDir,
+		}
+	}
+	sourceDigest, err := jp.prepareOCISource(ctx, job, hostCodeDir)
+	if err != nil {
+		logger.WithError(err).Error("Failed to prepare OCI source")
+		return &JobResult{
+			ExitCode:     1,
+			Error:        err.Error(),
+			WorkspaceDir: workspaceDir,
+		}
+	}
+
+	sharedWorkspace, err := jp.attachSharedWorkspace(ctx, job, workspaceDir)
+	if err != nil {
+		logger.WithError(err).Error("Failed to attach shared workspace")
+		return &JobResult{
+			ExitCode:     1,
+			Error:        err.Error(),
+			WorkspaceDir: workspaceDir,
+		}
+	}
+	if sharedWorkspace != nil {
+		// Discards the job's changes on the early returns below; the normal
+		// path releases it explicitly once the job's outcome is known.
+		defer sharedWorkspace.Release(context.Background(), job.JobID, false)
+	}
+	jobCache := jp.attachJobCache(ctx, job, workspaceDir)
+	if jobCache != nil {
+		// Keeps nothing on the early returns below, as above.
+		defer jobCache.Release(context.Background(), false)
+	}
+
 	logger.WithField("workspace_dir", workspaceDir).Info("Created workspace directory")
 
 	// Build job configuration for container runner
 	jobConfig := jp.buildJobConfig(job, workspaceDir)
+	if sharedWorkspace != nil {
+		jobConfig.Env[SharedWorkspaceEnv] = SharedWorkspaceMountPath
+	}
+	if jobCache != nil {
+		jobConfig.Env[JobCacheEnv] = JobCacheMountPath
+	}
+	if HasCapability(jobConfig.Capabilities, CapabilityBuilder) {
+		if ref := LoadBuilderConfig().CacheRef(jobCacheNamespace(job)); ref != "" {
+			jobConfig.Env[BuildCacheRefEnv] = ref
+		}
+	}
+	if sourceDigest != "" {
+		jobConfig.Env[SourceDigestEnv] = sourceDigest
+	}
+
+	var envFile *EnvFileRender
+	if job.JobEnvFile != "" {
+		if envFile, err = jp.renderJobEnvFile(ctx, job, jobConfig.Env); err != nil {
+			logger.WithError(err).Error("Failed to render job env file")
+			return &JobResult{
+				ExitCode:     1,
+				Error:        fmt.Sprintf("Failed to render env file: %v", err),
+				WorkspaceDir: workspaceDir,
+			}
+		}
+	}
+
+	var envFileSecretNames []string
+	if envFile != nil {
+		envFileSecretNames = envFile.SecretEnvNames
+	}
+	snapshottedEnv := snapshotEnv(jobConfig.Env, envFileSecretNames)
 
 	// Resolve secret references in environment variables
 	secretResult, err := jp.resolveJobSecrets(ctx, job, jobConfig.Env)
@@ -655,9 +831,34 @@ func (jp *JobProcessor) executeWithRunnerlib(ctx context.Context, job *models.Jo
 		masker.RegisterSecret(secretValue)
 	}
 
+	// Mint short-lived cloud credentials for the project's cloud roles
+	dynamicCreds, err := jp.mintDynamicCredentials(ctx, job, jobConfig.Env)
+	if err != nil {
+		logger.WithError(err).Error("Failed to mint dynamic cloud credentials")
+		return &JobResult{
+			ExitCode:     1,
+			Error:        fmt.Sprintf("Failed to mint dynamic cloud credentials: %v", err),
+			WorkspaceDir: workspaceDir,
+		}
+	}
+	defer revokeDynamicCredentials(job, dynamicCreds)
+	secretEnvNames := secretResult.SecretEnvNames
+	if envFile != nil {
+		for _, secretValue := range envFile.SecretValues {
+			masker.RegisterSecret(secretValue)
+		}
+		secretEnvNames = append(secretEnvNames, envFile.SecretEnvNames...)
+	}
+	for _, cred := range dynamicCreds {
+		for _, secretValue := range cred.SecretValues() {
+			masker.RegisterSecret(secretValue)
+		}
+		secretEnvNames = append(secretEnvNames, cred.SecretEnvNames...)
+	}
+
 	// Set REACTORCIDE_SECRET_ENV_NAMES so runnerlib knows which env vars contain secrets
-	if len(secretResult.SecretEnvNames) > 0 {
-		jobConfig.Env["REACTORCIDE_SECRET_ENV_NAMES"] = strings.Join(secretResult.SecretEnvNames, ",")
+	if len(secretEnvNames) > 0 {
+		jobConfig.Env["REACTORCIDE_SECRET_ENV_NAMES"] = strings.Join(secretEnvNames, ",")
 	}
 
 	vcsAuth, err := jp.prepareVCSCheckoutAuth(ctx, job, jobConfig.Env, workspaceDir)
@@ -681,6 +882,11 @@ func (jp *JobProcessor) executeWithRunnerlib(ctx context.Context, job *models.Jo
 		defer cleanupVCSCheckoutAuth(workspaceDir)
 	}
 
+	// Held until the job ends: its clone reads the mirror's objects.
+	if releaseMirror := jp.attachGitMirror(ctx, job, jobConfig, workspaceDir); releaseMirror != nil {
+		defer releaseMirror()
+	}
+
 	// Mask command for logging
 	maskedCmd := masker.MaskCommandArgs(jobConfig.Command)
 	logger.WithFields(map[string]interface{}{
@@ -698,6 +904,7 @@ func (jp *JobProcessor) executeWithRunnerlib(ctx context.Context, job *models.Jo
 			WorkspaceDir: workspaceDir,
 		}
 	}
+	jp.recordJobConfigSnapshot(ctx, job, jobConfig, snapshottedEnv)
 
 	// Ensure cleanup happens
 	defer func() {
@@ -709,6 +916,11 @@ func (jp *JobProcessor) executeWithRunnerlib(ctx context.Context, job *models.Jo
 
 	logger.WithField("container_id", containerID).Info("Job container spawned successfully")
 
+	// Deferred after Cleanup's defer so sampling has stopped before the
+	// container is removed; the explicit stop below covers the normal path.
+	usageTracker := startResourceUsageTracker(ctx, jp.runner, containerID, jp.config.ResourceSampleInterval)
+	defer usageTracker.stop()
+
 	// Start heartbeat goroutine if heartbeat function is provided. This is
 	// also the cancel-poll point: on every heartbeat tick we also check the
 	// job's current DB status and, if it has moved to "cancelling", stop
@@ -750,7 +962,7 @@ func (jp *JobProcessor) executeWithRunnerlib(ctx context.Context, job *models.Jo
 	var logShipErrors []error
 	var logShipErrorMu sync.Mutex
 
-	if jp.config.ObjectStore != nil {
+	if jp.config.ObjectStore != nil || jp.config.LogUploader != nil {
 		// Create callback for log updates
 		onChunkUploaded := func(objectKey string, bytesWritten int64) error {
 			if jp.config.OnLogUpdate != nil {
@@ -768,6 +980,7 @@ func (jp *JobProcessor) executeWithRunnerlib(ctx context.Context, job *models.Jo
 				ChunkInterval:   jp.config.LogChunkInterval,
 				OnChunkUploaded: onChunkUploaded,
 				Publisher:       jp.config.Publisher,
+				Uploader:        jp.config.LogUploader,
 			}, masker)
 
 			logWg.Add(1)
@@ -793,6 +1006,7 @@ func (jp *JobProcessor) executeWithRunnerlib(ctx context.Context, job *models.Jo
 				ChunkInterval:   jp.config.LogChunkInterval,
 				OnChunkUploaded: onChunkUploaded,
 				Publisher:       jp.config.Publisher,
+				Uploader:        jp.config.LogUploader,
 			}, masker)
 
 			logWg.Add(1)
@@ -847,13 +1061,16 @@ func (jp *JobProcessor) executeWithRunnerlib(ctx context.Context, job *models.Jo
 
 	// Wait for the container to complete
 	exitCode, err := jp.runner.WaitForCompletion(ctx, containerID)
+	usage := usageTracker.stop()
 
 	// Wait for log streaming/shipping to finish
 	logWg.Wait()
 
+	usage.StorageBytes = stdoutBytes + stderrBytes
 	result := &JobResult{
 		ExitCode:     exitCode,
 		WorkspaceDir: workspaceDir,
+		Usage:        usage,
 	}
 
 	// If the cancel-poll intervened (JobRunner.Stop or an immediate kill
@@ -866,6 +1083,32 @@ func (jp *JobProcessor) executeWithRunnerlib(ctx context.Context, job *models.Jo
 	// stays false here and the job's real exit code/status wins.
 	result.Cancelled, result.Killed = cancelResult.snapshot()
 
+	// Keep the shared workspace's new contents only if the job succeeded. A
+	// job whose output can't be kept for the jobs after it hasn't.
+	if sharedWorkspace != nil {
+		succeeded := err == nil && exitCode == 0 && !result.Cancelled
+		if releaseErr := sharedWorkspace.Release(ctx, job.JobID, succeeded); releaseErr != nil {
+			logger.WithError(releaseErr).Error("Failed to keep shared workspace")
+			result.ExitCode = 1
+			result.Error = releaseErr.Error()
+		}
+	}
+	// Likewise the cache, but a cache that can't be saved doesn't fail the
+	// job: the next run just starts colder.
+	if jobCache != nil {
+		succeeded := err == nil && result.ExitCode == 0 && !result.Cancelled
+		if releaseErr := jobCache.Release(ctx, succeeded); releaseErr != nil {
+			logger.WithError(releaseErr).Warn("Failed to save job cache")
+		}
+	}
+
+	// Hand a failed job that asked for it to a debug session before the
+	// deferred Cleanup removes its container. Cancelled jobs didn't fail on
+	// their own, so there is nothing to debug.
+	if job.DebugOnFailure && err == nil && exitCode != 0 && !result.Cancelled && jp.config.DebugSessions != nil {
+		result.WorkspaceRetained = jp.config.DebugSessions.Hold(ctx, job, containerID, jobConfig.Env, workspaceDir)
+	}
+
 	// Set log object keys if logs were shipped
 	if stdoutKey != "" || stderrKey != "" {
 		// Use stdout key as primary log key (stderr is separate)
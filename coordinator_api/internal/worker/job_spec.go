@@ -6,8 +6,10 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/hooks"
 	"gopkg.in/yaml.v3"
 )
 
@@ -321,6 +323,13 @@ var shellPrefixes = []string{
 	"/usr/bin/sh -c",
 	"/usr/bin/bash -c",
 	"/usr/bin/zsh -c",
+	"cmd /c",
+	"cmd.exe /c",
+	"powershell -command",
+	"powershell -c",
+	"powershell.exe -command",
+	"pwsh -command",
+	"pwsh -c",
 }
 
 // ParseCommandWithPrefix converts a command string to []string for container execution.
@@ -465,9 +474,75 @@ var SecretRefPattern = regexp.MustCompile(`\$\{secret:([^:}]+):([^}]+)\}`)
 // This allows job YAMLs to reference host environment variables
 var EnvRefPattern = regexp.MustCompile(`\$\{env:([^}]+)\}`)
 
-// HasSecretRefs checks if a string contains secret references
+// SecretURIScheme prefixes an env value that is wholly a reference to a
+// secret, secretref://path/key, the key being the last path segment.
+// Triggered jobs use it to name a secret they need without the eval job
+// resolving it: like a ${secret:path:key} reference, it's carried through
+// triggers.json and the Corndogs payload as is, and resolved by the worker
+// running the job, under the job's own secret grants.
+const SecretURIScheme = "secretref://"
+
+// ParseSecretURI splits a secretref://path/key value into its secret path
+// and key. ok is false if value isn't a secret URI; err is set if it is
+// one but malformed.
+func ParseSecretURI(value string) (path, key string, ok bool, err error) {
+	rest, found := strings.CutPrefix(value, SecretURIScheme)
+	if !found {
+		return "", "", false, nil
+	}
+	i := strings.LastIndex(rest, "/")
+	if i <= 0 || i == len(rest)-1 {
+		return "", "", true, fmt.Errorf("secret reference %q must be %spath/key", value, SecretURIScheme)
+	}
+	path, key = rest[:i], rest[i+1:]
+	if strings.ContainsAny(path, ":}") || strings.ContainsAny(key, "}") {
+		return "", "", true, fmt.Errorf("secret reference %q has a ':' or '}' in its path or key", value)
+	}
+	return path, key, true, nil
+}
+
+// ValidateSecretURIs checks that every secret URI in env is well formed.
+func ValidateSecretURIs(env map[string]string) error {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, _, _, err := ParseSecretURI(env[name]); err != nil {
+			return fmt.Errorf("env %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// SecretRefs lists the secret references in env, sorted by variable name.
+func SecretRefs(env map[string]string) []hooks.SecretRef {
+	var refs []hooks.SecretRef
+	for name, value := range env {
+		if path, key, ok, err := ParseSecretURI(value); ok {
+			if err == nil {
+				refs = append(refs, hooks.SecretRef{Env: name, Path: path, Key: key})
+			}
+			continue
+		}
+		for _, m := range SecretRefPattern.FindAllStringSubmatch(value, -1) {
+			refs = append(refs, hooks.SecretRef{Env: name, Path: m[1], Key: m[2]})
+		}
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Env != refs[j].Env {
+			return refs[i].Env < refs[j].Env
+		}
+		return refs[i].Path+":"+refs[i].Key < refs[j].Path+":"+refs[j].Key
+	})
+	return refs
+}
+
+// HasSecretRefs checks if a string contains secret references, or is a
+// secret URI
 func HasSecretRefs(s string) bool {
-	return SecretRefPattern.MatchString(s)
+	return SecretRefPattern.MatchString(s) || strings.HasPrefix(s, SecretURIScheme)
 }
 
 // HasEnvRefs checks if a string contains environment variable references
@@ -494,9 +569,16 @@ func ResolveEnvRefs(value string) string {
 	return result
 }
 
-// ResolveSecretRefs resolves ${secret:path:key} references in a string
-// using the provided getter function
+// ResolveSecretRefs resolves ${secret:path:key} references in a string, or
+// the secret a secretref://path/key string names, using the provided getter
+// function
 func ResolveSecretRefs(value string, getSecret func(path, key string) (string, error)) (string, error) {
+	if path, key, ok, err := ParseSecretURI(value); ok {
+		if err != nil {
+			return "", err
+		}
+		value = "${secret:" + path + ":" + key + "}"
+	}
 	result := value
 	matches := SecretRefPattern.FindAllStringSubmatch(value, -1)
 
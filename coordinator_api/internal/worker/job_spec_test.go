@@ -429,6 +429,18 @@ func TestParseCommandWithPrefix(t *testing.T) {
 			prefix:   "",
 			expected: []string{"sh", "-c", "echo hello\nexit 0"},
 		},
+		{
+			name:     "multiline command with powershell prefix",
+			cmd:      "Write-Output hello\nexit 0",
+			prefix:   "powershell -Command",
+			expected: []string{"powershell", "-Command", "Write-Output hello\nexit 0"},
+		},
+		{
+			name:     "multiline already has cmd prefix",
+			cmd:      "cmd /C 'echo hello\nexit 0'",
+			prefix:   "powershell -Command",
+			expected: []string{"cmd", "/C", "echo hello\nexit 0"},
+		},
 		{
 			name:     "single-line with custom prefix (ignored for single-line)",
 			cmd:      "echo hello",
@@ -464,6 +476,8 @@ func TestHasSecretRefs(t *testing.T) {
 		{"no secret here", false},
 		{"${env:VAR}", false},
 		{"${secret:a/b/c:mykey}", true},
+		{"secretref://a/b/c/mykey", true},
+		{"prefix secretref://a/b", false},
 	}
 
 	for _, tt := range tests {
@@ -889,6 +903,16 @@ func TestResolveSecretRefs(t *testing.T) {
 			input:       "${secret:vault/prod:nonexistent}",
 			shouldError: true,
 		},
+		{
+			name:     "secret URI",
+			input:    "secretref://vault/prod/api_key",
+			expected: "secret-api-key-123",
+		},
+		{
+			name:        "secret URI without a key",
+			input:       "secretref://vault",
+			shouldError: true,
+		},
 	}
 
 	for _, tt := range tests {
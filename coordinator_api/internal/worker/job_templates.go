@@ -0,0 +1,129 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/hooks"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// CreateTemplateJobs creates and submits a job for each of templates, the
+// job templates of an eval-less project, in place of the eval job base
+// describes: base is built for the event like an eval job but never
+// stored. The jobs are built the way triggered jobs are, inheriting
+// base's source, event variables, queue and VCS metadata, so they report
+// their own commit statuses. They have no parent job. Templates are
+// checked against the project's "trigger" admission policies first, and
+// none are created if one is denied.
+func (tp *TriggerProcessor) CreateTemplateJobs(ctx context.Context, templates models.JobTemplates, base *models.Job) ([]*models.Job, error) {
+	specs := make([]triggerJobSpec, 0, len(templates))
+	index := make([]int, 0, len(templates))
+	built := make([]*models.Job, 0, len(templates))
+	for i, template := range templates {
+		job, err := BuildTemplateJob(template, base)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, templateJobSpec(template, base))
+		index = append(index, i)
+		built = append(built, job)
+	}
+	errs, err := tp.checkTriggerPolicies(ctx, specs, index, base)
+	if err != nil {
+		return nil, err
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	var jobs []*models.Job
+	for i, job := range built {
+		tp.applyStatusContext(ctx, job)
+		if err := tp.submitNewJob(ctx, job, hooks.SourceWebhook); err != nil {
+			return jobs, fmt.Errorf("job template %q: %w", templates[i].Name, err)
+		}
+		logging.Log.WithFields(map[string]interface{}{
+			"job_id":   job.JobID,
+			"job_name": job.Name,
+			"status":   job.Status,
+		}).Info("Created job from project job template")
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// BuildTemplateJob builds, without creating, template's job in place of
+// the eval job base. See CreateTemplateJobs.
+func BuildTemplateJob(template models.JobTemplate, base *models.Job) (*models.Job, error) {
+	spec := templateJobSpec(template, base)
+	if _, err := NormalizePlatform(spec.TargetOS, spec.TargetArch); err != nil {
+		return nil, fmt.Errorf("job template %q: %w", template.Name, err)
+	}
+	job := (&TriggerProcessor{}).buildJobFromTrigger(spec, base)
+	job.ParentJobID = nil
+	job.Description = fmt.Sprintf(templateJobDescriptionPrefix+"%q: %s", template.Name, base.Description)
+	return job, nil
+}
+
+// templateJobDescriptionPrefix starts the description of a job template's
+// job, which is followed by the quoted template name.
+const templateJobDescriptionPrefix = "Job template "
+
+// jobTemplateFor returns the job template of project that job was built
+// from, if any: the template its description names, by the name it has.
+func jobTemplateFor(project *models.Project, job *models.Job) *models.JobTemplate {
+	if project == nil || job.ParentJobID != nil || !strings.HasPrefix(job.Description, templateJobDescriptionPrefix) {
+		return nil
+	}
+	quoted, err := strconv.QuotedPrefix(strings.TrimPrefix(job.Description, templateJobDescriptionPrefix))
+	if err != nil {
+		return nil
+	}
+	name, err := strconv.Unquote(quoted)
+	if err != nil || name != job.Name {
+		return nil
+	}
+	for i := range project.JobTemplates {
+		if project.JobTemplates[i].Name == name {
+			return &project.JobTemplates[i]
+		}
+	}
+	return nil
+}
+
+// templateJobSpec is the trigger spec of a job template, checking out
+// base's source.
+func templateJobSpec(template models.JobTemplate, base *models.Job) triggerJobSpec {
+	spec := triggerJobSpec{
+		JobName:        template.Name,
+		StatusKey:      template.StatusKey,
+		Env:            template.Env,
+		ContainerImage: template.Image,
+		JobCommand:     template.Command,
+		Priority:       template.Priority,
+		TargetOS:       template.TargetOS,
+		TargetArch:     template.TargetArch,
+	}
+	if template.TimeoutSeconds > 0 {
+		timeout := template.TimeoutSeconds
+		spec.Timeout = &timeout
+	}
+	if spec.Priority == nil {
+		priority := base.Priority
+		spec.Priority = &priority
+	}
+	if base.SourceType != nil {
+		spec.SourceType = string(*base.SourceType)
+	}
+	if base.SourceURL != nil {
+		spec.SourceURL = *base.SourceURL
+	}
+	if base.SourceRef != nil {
+		spec.SourceRef = *base.SourceRef
+	}
+	return spec
+}
@@ -114,6 +114,11 @@ func (lm *LifecycleManager) RecoverJobs(ctx context.Context, workerID string) er
 	logging.Log.WithField("count", len(stuckJobs)).Info("Found stuck jobs to recover")
 
 	for _, job := range stuckJobs {
+		if job.IsAwaitingChildren() {
+			// Finished executing and held open on its children; there is
+			// nothing to re-run, and SettleChildFanIn will finalize it.
+			continue
+		}
 		if err := lm.recoverJob(ctx, &job); err != nil {
 			logging.Log.WithField("job_id", job.JobID).
 				WithError(err).
@@ -129,6 +134,11 @@ func (lm *LifecycleManager) RecoverJobs(ctx context.Context, workerID string) er
 func (lm *LifecycleManager) recoverJob(ctx context.Context, job *models.Job) error {
 	logger := logging.Log.WithField("job_id", job.JobID)
 	logger.Info("Recovering stuck job")
+	workerID := ""
+	if job.WorkerID != nil {
+		workerID = *job.WorkerID
+	}
+	recordDeliveryFailure(ctx, lm.store, job, workerID, models.DeliveryFailureWorkerLost, "worker restarted while running the job")
 
 	// Update job status back to "submitted" so it can be retried
 	// You might want different logic here depending on your requirements
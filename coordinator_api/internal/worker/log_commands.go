@@ -0,0 +1,123 @@
+package worker
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Group markers on log entries (LogEntry.Section).
+const (
+	LogSectionStart = "start"
+	LogSectionEnd   = "end"
+)
+
+// LogAnnotation is a problem a job reported with an ::error::, ::warning::
+// or ::notice:: command, optionally pointing at a file.
+type LogAnnotation struct {
+	Level     string `json:"level"`
+	Title     string `json:"title,omitempty"`
+	File      string `json:"file,omitempty"`
+	Line      int    `json:"line,omitempty"`
+	EndLine   int    `json:"end_line,omitempty"`
+	Col       int    `json:"col,omitempty"`
+	EndColumn int    `json:"end_column,omitempty"`
+}
+
+// logCommands parses the workflow commands job scripts emit, in GitHub
+// Actions syntax, from one stream's lines:
+//
+//	::group::Title          opens a section; a new group closes the open one
+//	::endgroup::            closes it
+//	::error file=f,line=1::message  (also ::warning:: and ::notice::)
+//
+// Groups don't nest. Unrecognized commands are left as plain output.
+type logCommands struct {
+	group string
+}
+
+// apply rewrites entry for the command on its line, if any, and tags it
+// with the section it belongs to.
+func (lc *logCommands) apply(entry *LogEntry) {
+	name, params, data, ok := parseLogCommand(entry.Message)
+	if !ok {
+		entry.Group = lc.group
+		return
+	}
+	switch name {
+	case "group":
+		lc.group = data
+		entry.Group = data
+		entry.Section = LogSectionStart
+		entry.Message = data
+	case "endgroup":
+		if lc.group == "" {
+			return
+		}
+		entry.Group = lc.group
+		entry.Section = LogSectionEnd
+		entry.Message = lc.group
+		lc.group = ""
+	case "error", "warning", "notice":
+		annotation := &LogAnnotation{Level: name}
+		for key, value := range params {
+			switch key {
+			case "title":
+				annotation.Title = value
+			case "file":
+				annotation.File = value
+			case "line":
+				annotation.Line, _ = strconv.Atoi(value)
+			case "endLine":
+				annotation.EndLine, _ = strconv.Atoi(value)
+			case "col":
+				annotation.Col, _ = strconv.Atoi(value)
+			case "endColumn":
+				annotation.EndColumn, _ = strconv.Atoi(value)
+			}
+		}
+		entry.Group = lc.group
+		entry.Level = name
+		entry.Message = data
+		entry.Annotation = annotation
+	default:
+		entry.Group = lc.group
+	}
+}
+
+// parseLogCommand splits a "::name key=value,...::data" line. Leading
+// whitespace is allowed, as scripts often indent their output.
+func parseLogCommand(line string) (name string, params map[string]string, data string, ok bool) {
+	rest, found := strings.CutPrefix(strings.TrimLeft(line, " \t"), "::")
+	if !found {
+		return "", nil, "", false
+	}
+	command, data, found := strings.Cut(rest, "::")
+	if !found {
+		return "", nil, "", false
+	}
+	name, props, _ := strings.Cut(command, " ")
+	if name == "" || strings.ContainsAny(name, " \t") {
+		return "", nil, "", false
+	}
+	params = map[string]string{}
+	for _, prop := range strings.Split(props, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(prop), "=")
+		if found && key != "" {
+			params[key] = unescapeLogCommandProperty(value)
+		}
+	}
+	return name, params, unescapeLogCommandData(data), true
+}
+
+var (
+	logCommandDataUnescaper     = strings.NewReplacer("%0D", "\r", "%0A", "\n", "%25", "%")
+	logCommandPropertyUnescaper = strings.NewReplacer("%0D", "\r", "%0A", "\n", "%3A", ":", "%2C", ",", "%25", "%")
+)
+
+func unescapeLogCommandData(s string) string {
+	return logCommandDataUnescaper.Replace(s)
+}
+
+func unescapeLogCommandProperty(s string) string {
+	return logCommandPropertyUnescaper.Replace(s)
+}
@@ -0,0 +1,80 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogCommands(t *testing.T) {
+	var lc logCommands
+	apply := func(message string) LogEntry {
+		entry := LogEntry{Stream: "stdout", Level: "info", Message: message}
+		lc.apply(&entry)
+		return entry
+	}
+
+	assert.Equal(t, LogEntry{Stream: "stdout", Level: "info", Message: "before"}, apply("before"))
+
+	start := apply("::group::Install deps")
+	assert.Equal(t, LogSectionStart, start.Section)
+	assert.Equal(t, "Install deps", start.Group)
+	assert.Equal(t, "Install deps", start.Message)
+
+	assert.Equal(t, "Install deps", apply("npm ci").Group)
+
+	problem := apply("  ::error file=src/app.go,line=12,col=3,title=Build failed::undefined: foo%0Asecond line")
+	assert.Equal(t, "error", problem.Level)
+	assert.Equal(t, "undefined: foo\nsecond line", problem.Message)
+	assert.Equal(t, "Install deps", problem.Group)
+	assert.Equal(t, &LogAnnotation{Level: "error", Title: "Build failed", File: "src/app.go", Line: 12, Col: 3}, problem.Annotation)
+
+	end := apply("::endgroup::")
+	assert.Equal(t, LogSectionEnd, end.Section)
+	assert.Equal(t, "Install deps", end.Group)
+
+	after := apply("after")
+	assert.Empty(t, after.Group)
+
+	// A stray ::endgroup:: and unknown commands stay plain output.
+	assert.Equal(t, "::endgroup::", apply("::endgroup::").Message)
+	unknown := apply("::set-output name=x::y")
+	assert.Equal(t, "::set-output name=x::y", unknown.Message)
+	assert.Nil(t, unknown.Annotation)
+
+	warning := apply("::warning title=a%2Cb::careful")
+	assert.Equal(t, "warning", warning.Level)
+	assert.Equal(t, "a,b", warning.Annotation.Title)
+}
+
+func TestLogShipper_ParsesLogCommands(t *testing.T) {
+	store := objects.NewMemoryObjectStore()
+	shipper := NewLogShipper(LogShipperConfig{
+		ObjectStore:   store,
+		JobID:         "job-1",
+		StreamType:    "stdout",
+		ChunkInterval: time.Hour,
+	}, nil)
+	output := "::group::Test\nok pkg\n::error::1 test failed\n::endgroup::\n"
+	key, _, err := shipper.StreamAndShip(context.Background(), io.NopCloser(strings.NewReader(output)))
+	require.NoError(t, err)
+
+	reader, err := store.Get(context.Background(), key)
+	require.NoError(t, err)
+	defer reader.Close()
+	var entries []LogEntry
+	require.NoError(t, json.NewDecoder(reader).Decode(&entries))
+	require.Len(t, entries, 4)
+	assert.Equal(t, LogSectionStart, entries[0].Section)
+	assert.Equal(t, "Test", entries[1].Group)
+	require.NotNil(t, entries[2].Annotation)
+	assert.Equal(t, "1 test failed", entries[2].Message)
+	assert.Equal(t, LogSectionEnd, entries[3].Section)
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/pubsub"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/secrets"
+	"github.com/sirupsen/logrus"
 )
 
 // LogEntry represents a single log line in JSON format
@@ -22,6 +23,12 @@ type LogEntry struct {
 	Stream    string `json:"stream"`
 	Level     string `json:"level,omitempty"`
 	Message   string `json:"message"`
+	// Group is the ::group:: section the entry is in, and Section marks
+	// the entries that open and close it (see log_commands.go).
+	Group   string `json:"group,omitempty"`
+	Section string `json:"section,omitempty"`
+	// Annotation is set on ::error::, ::warning:: and ::notice:: lines.
+	Annotation *LogAnnotation `json:"annotation,omitempty"`
 }
 
 // LogShipperConfig holds configuration for log shipping
@@ -32,6 +39,7 @@ type LogShipperConfig struct {
 	ChunkInterval  time.Duration
 	OnChunkUploaded func(objectKey string, bytesWritten int64) error // Callback for chunk uploads
 	Publisher      *pubsub.Publisher // optional: NOTIFY WS clients when a chunk is flushed
+	Uploader       *LogUploadClient  // optional: upload through the coordinator's chunk API instead of ObjectStore
 }
 
 // LogShipper handles streaming logs to object storage in chunks
@@ -44,9 +52,13 @@ type LogShipper struct {
 	totalBytes    int64
 	chunksWritten int
 	objectKey     string
+	nextSequence  int // next chunk sequence when shipping through Uploader
 
 	// Secret masking
 	masker *secrets.Masker
+
+	// Group and annotation commands in the output
+	commands logCommands
 }
 
 // NewLogShipper creates a new log shipper
@@ -79,6 +91,23 @@ func (ls *LogShipper) StreamAndShip(ctx context.Context, reader io.ReadCloser) (
 
 	logger.Info("Starting log streaming and shipping")
 
+	// Through the chunk API, carry on after any chunks a previous run of
+	// this job already uploaded.
+	if ls.config.Uploader != nil {
+		state, err := ls.config.Uploader.Status(ctx, ls.config.JobID, ls.config.StreamType)
+		if err != nil {
+			return ls.objectKey, 0, fmt.Errorf("failed to get log upload status: %w", err)
+		}
+		if state.Committed {
+			return ls.objectKey, state.Bytes, fmt.Errorf("log stream %s already committed", ls.config.StreamType)
+		}
+		ls.nextSequence = state.NextSequence
+		ls.totalBytes = state.Bytes
+		if state.NextSequence > 0 {
+			logger.WithField("next_sequence", state.NextSequence).Info("Resuming chunked log upload")
+		}
+	}
+
 	// Create a ticker for periodic chunk uploads
 	ticker := time.NewTicker(ls.config.ChunkInterval)
 	defer ticker.Stop()
@@ -102,8 +131,9 @@ func (ls *LogShipper) StreamAndShip(ctx context.Context, reader io.ReadCloser) (
 			maskedLine = ls.masker.MaskString(line)
 		}
 
-		// Create log entry
+		// Create log entry, applying any group or annotation command
 		entry := ls.parseLogLine(maskedLine)
+		ls.commands.apply(&entry)
 
 		// Add to entries slice
 		ls.mu.Lock()
@@ -134,6 +164,15 @@ func (ls *LogShipper) StreamAndShip(ctx context.Context, reader io.ReadCloser) (
 		// No errors
 	}
 
+	if ls.config.Uploader != nil {
+		state, err := ls.config.Uploader.Commit(ctx, ls.config.JobID, ls.config.StreamType, ls.nextSequence-1)
+		if err != nil {
+			logger.WithError(err).Error("Failed to commit log upload")
+			return ls.objectKey, ls.totalBytes, fmt.Errorf("failed to commit log upload: %w", err)
+		}
+		ls.totalBytes = state.Bytes
+	}
+
 	logger.WithFields(map[string]interface{}{
 		"object_key":    ls.objectKey,
 		"total_bytes":   ls.totalBytes,
@@ -182,6 +221,10 @@ func (ls *LogShipper) uploadChunk(ctx context.Context) error {
 		"chunk_num":   ls.chunksWritten + 1,
 	})
 
+	if ls.config.Uploader != nil {
+		return ls.putChunk(ctx, logger)
+	}
+
 	contentType := "application/json"
 
 	// Read existing entries from storage if this isn't the first chunk
@@ -235,6 +278,36 @@ func (ls *LogShipper) uploadChunk(ctx context.Context) error {
 
 	logger.WithField("total_bytes", ls.totalBytes).Debug("Log chunk uploaded successfully")
 
+	ls.chunkUploaded(ctx, logger)
+	return nil
+}
+
+// putChunk uploads the buffered entries as the next chunk through the
+// coordinator's chunk API. Callers hold ls.mu.
+func (ls *LogShipper) putChunk(ctx context.Context, logger *logrus.Entry) error {
+	jsonData, err := json.Marshal(ls.entries)
+	if err != nil {
+		logger.WithError(err).Error("Failed to marshal log entries to JSON")
+		return fmt.Errorf("failed to marshal entries: %w", err)
+	}
+	if _, err := ls.config.Uploader.PutChunk(ctx, ls.config.JobID, ls.config.StreamType, ls.nextSequence, jsonData); err != nil {
+		logger.WithError(err).Error("Failed to upload log chunk")
+		return fmt.Errorf("failed to upload chunk: %w", err)
+	}
+
+	ls.nextSequence++
+	ls.totalBytes += int64(len(jsonData))
+	ls.chunksWritten++
+	ls.entries = ls.entries[:0]
+
+	logger.WithField("total_bytes", ls.totalBytes).Debug("Log chunk uploaded successfully")
+
+	ls.chunkUploaded(ctx, logger)
+	return nil
+}
+
+// chunkUploaded runs the per-chunk callback and notification.
+func (ls *LogShipper) chunkUploaded(ctx context.Context, logger *logrus.Entry) {
 	// Call the callback if provided
 	if ls.config.OnChunkUploaded != nil {
 		if err := ls.config.OnChunkUploaded(ls.objectKey, ls.totalBytes); err != nil {
@@ -248,8 +321,6 @@ func (ls *LogShipper) uploadChunk(ctx context.Context) error {
 	if ls.config.Publisher != nil {
 		ls.config.Publisher.PublishLogAvailable(ctx, ls.config.JobID, ls.config.StreamType, 0, ls.totalBytes)
 	}
-
-	return nil
 }
 
 // parseLogLine parses a log line, preserving existing JSON structure if present
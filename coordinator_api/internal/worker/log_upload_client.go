@@ -0,0 +1,181 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrLogUploadConflict is returned when the coordinator refuses a log chunk
+// or commit because it doesn't fit the upload so far (see
+// jobcontrol.PutLogChunk).
+var ErrLogUploadConflict = errors.New("log upload conflict")
+
+// logChunkState mirrors jobcontrol.LogChunkState, the coordinator's view of
+// a stream's chunked upload.
+type logChunkState struct {
+	Stream       string `json:"stream"`
+	NextSequence int    `json:"next_sequence"`
+	Bytes        int64  `json:"bytes"`
+	Committed    bool   `json:"committed"`
+}
+
+// LogUploadClient ships job logs through the coordinator's chunked log
+// upload API (/api/v1/jobs/{job_id}/logs/chunks/...) rather than writing
+// the object store directly, for workers without object store access.
+// Chunks are numbered and checksummed, and uploading one again is safe,
+// so transient failures are retried and a worker that restarts mid-job
+// resumes after the chunks already uploaded.
+type LogUploadClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+	maxRetries int
+	backoff    time.Duration
+}
+
+// NewLogUploadClient creates a client for the coordinator at baseURL,
+// authenticating with an API token that can write every job's logs (an
+// admin's).
+func NewLogUploadClient(baseURL, token string) *LogUploadClient {
+	return &LogUploadClient{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		token:      token,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		maxRetries: 5,
+		backoff:    time.Second,
+	}
+}
+
+// NewLogUploadClientWithTLS creates a client like NewLogUploadClient that
+// connects with tlsConfig, to present a client certificate to a
+// coordinator that requires one for worker endpoints.
+func NewLogUploadClientWithTLS(baseURL, token string, tlsConfig *tls.Config) *LogUploadClient {
+	c := NewLogUploadClient(baseURL, token)
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	c.httpClient.Transport = transport
+	return c
+}
+
+// LoadClientTLSConfig builds the TLS configuration for a log upload client
+// presenting the certificate in certFile and keyFile, trusting the CAs in
+// caFile for the coordinator's certificate on top of the system roots.
+// caFile may be empty.
+func LoadClientTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client certificate: %w", err)
+	}
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		caPEM, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		roots, err := x509.SystemCertPool()
+		if err != nil {
+			roots = x509.NewCertPool()
+		}
+		if !roots.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in %s", caFile)
+		}
+		tlsConfig.RootCAs = roots
+	}
+	return tlsConfig, nil
+}
+
+// Status returns where the stream's upload stands.
+func (c *LogUploadClient) Status(ctx context.Context, jobID, stream string) (*logChunkState, error) {
+	return c.do(ctx, http.MethodGet, c.streamURL(jobID, stream), nil, nil)
+}
+
+// PutChunk uploads chunk sequence of the stream, a JSON array of entries.
+func (c *LogUploadClient) PutChunk(ctx context.Context, jobID, stream string, sequence int, data []byte) (*logChunkState, error) {
+	sum := sha256.Sum256(data)
+	headers := map[string]string{
+		"Content-Type":      "application/json",
+		"X-Checksum-Sha256": hex.EncodeToString(sum[:]),
+	}
+	return c.do(ctx, http.MethodPut, fmt.Sprintf("%s/%d", c.streamURL(jobID, stream), sequence), data, headers)
+}
+
+// Commit marks the stream's upload complete after chunk finalSequence (-1
+// for none).
+func (c *LogUploadClient) Commit(ctx context.Context, jobID, stream string, finalSequence int) (*logChunkState, error) {
+	body, _ := json.Marshal(map[string]int{"final_sequence": finalSequence})
+	return c.do(ctx, http.MethodPost, c.streamURL(jobID, stream)+"/commit", body, map[string]string{"Content-Type": "application/json"})
+}
+
+func (c *LogUploadClient) streamURL(jobID, stream string) string {
+	return fmt.Sprintf("%s/api/v1/jobs/%s/logs/chunks/%s", c.baseURL, url.PathEscape(jobID), url.PathEscape(stream))
+}
+
+// do sends the request, retrying network errors and 5xx responses with
+// backoff. A 409 returns ErrLogUploadConflict with the coordinator's state.
+func (c *LogUploadClient) do(ctx context.Context, method, target string, body []byte, headers map[string]string) (*logChunkState, error) {
+	var lastErr error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(c.backoff * time.Duration(1<<(attempt-1))):
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+c.token)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		state, err := decodeLogUploadResponse(resp)
+		if resp.StatusCode >= 500 {
+			lastErr = err
+			continue
+		}
+		return state, err
+	}
+	return nil, fmt.Errorf("log upload failed after %d attempts: %w", c.maxRetries+1, lastErr)
+}
+
+func decodeLogUploadResponse(resp *http.Response) (*logChunkState, error) {
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		var state logChunkState
+		if err := json.NewDecoder(resp.Body).Decode(&state); err != nil {
+			return nil, fmt.Errorf("failed to decode log upload response: %w", err)
+		}
+		return &state, nil
+	case resp.StatusCode == http.StatusConflict:
+		var conflict struct {
+			Message string         `json:"message"`
+			State   *logChunkState `json:"state"`
+		}
+		json.NewDecoder(resp.Body).Decode(&conflict)
+		return conflict.State, fmt.Errorf("%w: %s", ErrLogUploadConflict, conflict.Message)
+	default:
+		return nil, fmt.Errorf("log upload returned status %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,180 @@
+package worker
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeChunkAPI is a minimal stand-in for the coordinator's chunk API for
+// one stream.
+type fakeChunkAPI struct {
+	mu        sync.Mutex
+	chunks    []string
+	committed bool
+	failNext  int // fail this many requests with a 503 first
+}
+
+func (f *fakeChunkAPI) state() logChunkState {
+	state := logChunkState{Stream: "stdout", NextSequence: len(f.chunks), Committed: f.committed}
+	for _, chunk := range f.chunks {
+		state.Bytes += int64(len(chunk))
+	}
+	return state
+}
+
+func (f *fakeChunkAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failNext > 0 {
+		f.failNext--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer test-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	rest := strings.TrimPrefix(r.URL.Path, "/api/v1/jobs/job-1/logs/chunks/stdout")
+	switch {
+	case r.Method == http.MethodGet && rest == "":
+	case r.Method == http.MethodPost && rest == "/commit":
+		f.committed = true
+	case r.Method == http.MethodPut:
+		sequence, _ := strconv.Atoi(strings.TrimPrefix(rest, "/"))
+		data, _ := io.ReadAll(r.Body)
+		if sequence != len(f.chunks) || r.Header.Get("X-Checksum-Sha256") == "" {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]interface{}{"message": "out of sequence", "state": f.state()})
+			return
+		}
+		f.chunks = append(f.chunks, string(data))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(f.state())
+}
+
+func TestLogShipper_ChunkUploadResumesAndCommits(t *testing.T) {
+	api := &fakeChunkAPI{chunks: []string{`[{"message":"before restart"}]`}, failNext: 1}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	uploader := NewLogUploadClient(server.URL+"/", "test-token")
+	uploader.backoff = time.Millisecond
+
+	shipper := NewLogShipper(LogShipperConfig{
+		JobID:         "job-1",
+		StreamType:    "stdout",
+		ChunkInterval: time.Hour,
+		Uploader:      uploader,
+	}, nil)
+	key, _, err := shipper.StreamAndShip(context.Background(), io.NopCloser(strings.NewReader("after restart\n")))
+	require.NoError(t, err)
+	assert.Equal(t, "logs/job-1/stdout.json", key)
+
+	require.Len(t, api.chunks, 2, "expected the new output appended after the existing chunk")
+	assert.Contains(t, api.chunks[1], "after restart")
+	assert.True(t, api.committed)
+}
+
+func TestLogUploadClient_Conflict(t *testing.T) {
+	api := &fakeChunkAPI{}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	state, err := NewLogUploadClient(server.URL, "test-token").PutChunk(context.Background(), "job-1", "stdout", 3, []byte(`[]`))
+	assert.ErrorIs(t, err, ErrLogUploadConflict)
+	require.NotNil(t, state)
+	assert.Equal(t, 0, state.NextSequence)
+}
+
+// writeClientCert issues a client certificate from a fresh CA into dir,
+// returning the CA's pool and the certificate and key files.
+func writeClientCert(t *testing.T, dir string) (*x509.CertPool, string, string) {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "workers-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "worker-1"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, caCert, &key.PublicKey, caKey)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile, keyFile := filepath.Join(dir, "worker.crt"), filepath.Join(dir, "worker.key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	pool := x509.NewCertPool()
+	pool.AddCert(caCert)
+	return pool, certFile, keyFile
+}
+
+func TestLogUploadClient_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	clientCAs, certFile, keyFile := writeClientCert(t, dir)
+
+	server := httptest.NewUnstartedServer(&fakeChunkAPI{})
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+	serverCAFile := filepath.Join(dir, "coordinator-ca.crt")
+	require.NoError(t, os.WriteFile(serverCAFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600))
+
+	tlsConfig, err := LoadClientTLSConfig(certFile, keyFile, serverCAFile)
+	require.NoError(t, err)
+	state, err := NewLogUploadClientWithTLS(server.URL, "test-token", tlsConfig).Status(context.Background(), "job-1", "stdout")
+	require.NoError(t, err)
+	assert.Equal(t, 0, state.NextSequence)
+
+	// Without the client certificate the handshake fails.
+	anonymous := NewLogUploadClientWithTLS(server.URL, "test-token", &tls.Config{RootCAs: tlsConfig.RootCAs})
+	anonymous.maxRetries = 0
+	_, err = anonymous.Status(context.Background(), "job-1", "stdout")
+	assert.Error(t, err)
+
+	_, err = LoadClientTLSConfig(certFile, keyFile, filepath.Join(dir, "missing.crt"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,585 @@
+package worker
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+const (
+	// SourceDigestEnv is set, for jobs with an OCI source, to the digest
+	// of the manifest the worker pulled.
+	SourceDigestEnv = "REACTORCIDE_SOURCE_DIGEST"
+
+	dockerHubRegistry = "registry-1.docker.io"
+
+	ociManifestMediaType        = "application/vnd.oci.image.manifest.v1+json"
+	ociIndexMediaType           = "application/vnd.oci.image.index.v1+json"
+	dockerManifestMediaType     = "application/vnd.docker.distribution.manifest.v2+json"
+	dockerManifestListMediaType = "application/vnd.docker.distribution.manifest.list.v2+json"
+
+	// ociTitleAnnotation names the file an artifact layer holds (as pushed
+	// by oras); ociUnpackAnnotation marks one that is a gzipped tar of a
+	// directory by that name.
+	ociTitleAnnotation  = "org.opencontainers.image.title"
+	ociUnpackAnnotation = "io.deis.oras.content.unpack"
+
+	ociWhiteoutPrefix = ".wh."
+	ociOpaqueWhiteout = ".wh..wh..opq"
+
+	maxOCIManifestBytes = 4 << 20
+)
+
+var (
+	ociDigestPattern     = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+	ociRepositoryPattern = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*$`)
+	ociTagPattern        = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+	authParamPattern     = regexp.MustCompile(`(\w+)="([^"]*)"`)
+)
+
+// OCISourceConfig configures how the worker pulls OCI sources.
+type OCISourceConfig struct {
+	// AuthPath is a docker config.json whose "auths" hold registry
+	// credentials. Registries without an entry are pulled anonymously.
+	AuthPath string
+	// InsecureRegistries are registry hosts (host[:port]) reached over
+	// plain HTTP.
+	InsecureRegistries []string
+}
+
+// OCIReference is a parsed image or artifact reference.
+type OCIReference struct {
+	Registry   string // host[:port]
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseOCIReference parses registry/repository[:tag][@digest] as docker
+// does: without a registry it's Docker Hub, where one-part repositories are
+// under library/, and without a tag or digest the tag is "latest".
+func ParseOCIReference(ref string) (OCIReference, error) {
+	var r OCIReference
+	rest := ref
+	if name, digest, ok := strings.Cut(rest, "@"); ok {
+		if !ociDigestPattern.MatchString(digest) {
+			return r, fmt.Errorf("invalid OCI reference %q: digest must be sha256:<64 hex digits>", ref)
+		}
+		r.Digest = digest
+		rest = name
+	}
+	first, remainder, hasSlash := strings.Cut(rest, "/")
+	switch {
+	case hasSlash && (strings.ContainsAny(first, ".:") || first == "localhost"):
+		r.Registry = strings.ToLower(first)
+		rest = remainder
+	case hasSlash:
+		r.Registry = dockerHubRegistry
+	default:
+		r.Registry = dockerHubRegistry
+		rest = "library/" + rest
+	}
+	if colon := strings.LastIndex(rest, ":"); colon > strings.LastIndex(rest, "/") {
+		r.Tag = rest[colon+1:]
+		rest = rest[:colon]
+		if !ociTagPattern.MatchString(r.Tag) {
+			return r, fmt.Errorf("invalid OCI reference %q: bad tag", ref)
+		}
+	}
+	if !ociRepositoryPattern.MatchString(rest) {
+		return r, fmt.Errorf("invalid OCI reference %q: bad repository", ref)
+	}
+	r.Repository = rest
+	if r.Tag == "" && r.Digest == "" {
+		r.Tag = "latest"
+	}
+	return r, nil
+}
+
+// ValidateOCISource checks an OCI source: the reference in the job's
+// source URL and, if set, the digest in its source ref that pins it.
+func ValidateOCISource(sourceURL, sourceRef string) error {
+	ref, err := ParseOCIReference(sourceURL)
+	if err != nil {
+		return err
+	}
+	if sourceRef == "" {
+		return nil
+	}
+	if !ociDigestPattern.MatchString(sourceRef) {
+		return fmt.Errorf("OCI source ref must be a digest (sha256:<64 hex digits>), got %q", sourceRef)
+	}
+	if ref.Digest != "" && ref.Digest != sourceRef {
+		return fmt.Errorf("OCI source ref %s does not match the reference's digest %s", sourceRef, ref.Digest)
+	}
+	return nil
+}
+
+// SourcePolicyURL is the URL the source URL policy checks for a job's
+// source: the registry for an OCI source, else the source URL itself.
+func SourcePolicyURL(sourceType, sourceURL string) string {
+	if models.SourceType(sourceType) != models.SourceTypeOCI || sourceURL == "" {
+		return sourceURL
+	}
+	ref, err := ParseOCIReference(sourceURL)
+	if err != nil {
+		return sourceURL
+	}
+	return "https://" + ref.Registry
+}
+
+// prepareOCISource pulls the job's OCI source into its code directory,
+// where runnerlib finds it already checked out, and returns the digest of
+// the manifest it pulled. A digest in the source ref is pulled by digest,
+// so the job gets exactly that content whatever its tag points at now.
+// Like uploaded sources, the Kubernetes runner can't run such jobs.
+func (jp *JobProcessor) prepareOCISource(ctx context.Context, job *models.Job, hostCodeDir string) (string, error) {
+	if job.SourceType == nil || *job.SourceType != models.SourceTypeOCI {
+		return "", nil
+	}
+	if _, ok := jp.runner.(*KubernetesRunner); ok {
+		return "", fmt.Errorf("OCI sources are not supported by the Kubernetes runner")
+	}
+	sourceURL, pin := derefString(job.SourceURL), derefString(job.SourceRef)
+	if err := ValidateOCISource(sourceURL, pin); err != nil {
+		return "", err
+	}
+	ref, _ := ParseOCIReference(sourceURL)
+	if pin != "" {
+		ref.Digest = pin
+	}
+
+	// The registry, and the token realm it names, are dialed through the
+	// source URL policy; insecure registries are operator-configured and
+	// may be internal.
+	puller := &ociPuller{client: LoadSourceURLPolicy(ctx, jp.store).HTTPClient(), ref: ref, scheme: "https"}
+	if containsFold(jp.config.OCISources.InsecureRegistries, ref.Registry) {
+		puller.client, puller.scheme = http.DefaultClient, "http"
+	}
+	credentials, err := registryCredentials(jp.config.OCISources.AuthPath, ref.Registry)
+	if err != nil {
+		return "", err
+	}
+	puller.credentials = credentials
+
+	digest, err := puller.pull(ctx, hostCodeDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to pull OCI source %s: %w", sourceURL, err)
+	}
+	if err := chownTree(hostCodeDir, 1001, 1001); err != nil {
+		logging.Log.WithError(err).WithField("job_id", job.JobID).
+			Warn("Failed to chown OCI source - job may fail if running as non-root")
+	}
+	logging.Log.WithField("job_id", job.JobID).WithField("source", sourceURL).WithField("digest", digest).
+		Info("Pulled OCI source")
+	return digest, nil
+}
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Platform    *struct {
+		OS           string `json:"os"`
+		Architecture string `json:"architecture"`
+	} `json:"platform,omitempty"`
+}
+
+// ociManifest is an image manifest (Layers) or an index (Manifests).
+type ociManifest struct {
+	MediaType string          `json:"mediaType"`
+	Manifests []ociDescriptor `json:"manifests"`
+	Layers    []ociDescriptor `json:"layers"`
+}
+
+// ociPuller pulls one reference through the registry API. Every manifest
+// and blob is checked against its digest.
+type ociPuller struct {
+	client        *http.Client
+	ref           OCIReference
+	scheme        string
+	credentials   string // base64 user:password, or empty
+	authorization string
+	// actions are the repository actions a token is asked for when the
+	// registry's challenge names no scope; "pull" when empty.
+	actions string
+}
+
+// pull extracts ref's layers into dir, in order, and returns the digest of
+// the manifest it pulled: the index's for a multi-platform reference.
+func (p *ociPuller) pull(ctx context.Context, dir string) (string, error) {
+	reference := p.ref.Digest
+	if reference == "" {
+		reference = p.ref.Tag
+	}
+	manifest, digest, err := p.fetchManifest(ctx, reference)
+	if err != nil {
+		return "", err
+	}
+	if len(manifest.Manifests) > 0 {
+		desc, err := selectPlatformManifest(manifest.Manifests)
+		if err != nil {
+			return "", err
+		}
+		if manifest, _, err = p.fetchManifest(ctx, desc.Digest); err != nil {
+			return "", err
+		}
+	}
+	for _, layer := range manifest.Layers {
+		if err := p.extractLayer(ctx, layer, dir); err != nil {
+			return "", fmt.Errorf("layer %s: %w", layer.Digest, err)
+		}
+	}
+	return digest, nil
+}
+
+func (p *ociPuller) fetchManifest(ctx context.Context, reference string) (ociManifest, string, error) {
+	var manifest ociManifest
+	resp, err := p.get(ctx, "/manifests/"+reference,
+		ociManifestMediaType, ociIndexMediaType, dockerManifestMediaType, dockerManifestListMediaType)
+	if err != nil {
+		return manifest, "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxOCIManifestBytes+1))
+	if err != nil {
+		return manifest, "", err
+	}
+	if len(body) > maxOCIManifestBytes {
+		return manifest, "", fmt.Errorf("manifest %s is larger than %d bytes", reference, maxOCIManifestBytes)
+	}
+	sum := sha256.Sum256(body)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+	if ociDigestPattern.MatchString(reference) && digest != reference {
+		return manifest, "", fmt.Errorf("manifest digest %s does not match %s", digest, reference)
+	}
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		return manifest, "", fmt.Errorf("invalid manifest %s: %w", reference, err)
+	}
+	return manifest, digest, nil
+}
+
+// selectPlatformManifest picks the manifest for the worker's
+// architecture from an index. An index without platforms, as artifact
+// indexes are, gives its first manifest.
+func selectPlatformManifest(manifests []ociDescriptor) (ociDescriptor, error) {
+	for _, m := range manifests {
+		if m.Platform == nil {
+			return m, nil
+		}
+		if m.Platform.OS == "linux" && m.Platform.Architecture == runtime.GOARCH {
+			return m, nil
+		}
+	}
+	return ociDescriptor{}, fmt.Errorf("no manifest for linux/%s", runtime.GOARCH)
+}
+
+// extractLayer extracts an image layer (a tar, possibly gzipped) into dir,
+// or writes an artifact layer to the file its title annotation names.
+func (p *ociPuller) extractLayer(ctx context.Context, layer ociDescriptor, dir string) error {
+	if !ociDigestPattern.MatchString(layer.Digest) {
+		return fmt.Errorf("unsupported digest")
+	}
+	resp, err := p.get(ctx, "/blobs/"+layer.Digest)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	hasher := sha256.New()
+	blob := io.TeeReader(resp.Body, hasher)
+
+	title := layer.Annotations[ociTitleAnnotation]
+	switch {
+	case title != "" && layer.Annotations[ociUnpackAnnotation] != "true":
+		err = writeArtifactFile(blob, dir, title)
+	case title != "":
+		err = extractGzipLayer(blob, filepath.Join(dir, filepath.FromSlash(path.Clean("/"+title))))
+	case strings.HasSuffix(layer.MediaType, "tar+gzip") || strings.HasSuffix(layer.MediaType, ".tar.gzip"):
+		err = extractGzipLayer(blob, dir)
+	case strings.HasSuffix(layer.MediaType, ".tar"):
+		err = extractTar(tar.NewReader(blob), dir, true)
+	default:
+		return fmt.Errorf("unsupported media type %s", layer.MediaType)
+	}
+	if err != nil {
+		return err
+	}
+	return verifyDigest(blob, hasher, layer.Digest)
+}
+
+func extractGzipLayer(r io.Reader, dir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	return extractTar(tar.NewReader(gz), dir, true)
+}
+
+// verifyDigest reads what's left of r, which tees into hasher, and checks
+// the whole blob against digest.
+func verifyDigest(r io.Reader, hasher hash.Hash, digest string) error {
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return err
+	}
+	if got := "sha256:" + hex.EncodeToString(hasher.Sum(nil)); got != digest {
+		return fmt.Errorf("content digest %s does not match", got)
+	}
+	return nil
+}
+
+// writeArtifactFile writes an artifact layer to name under dir.
+func writeArtifactFile(r io.Reader, dir, name string) error {
+	target := filepath.Join(dir, filepath.FromSlash(path.Clean("/"+name)))
+	if !withinDir(dir, target) || target == filepath.Clean(dir) {
+		return fmt.Errorf("artifact file %q escapes the code directory", name)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0777); err != nil {
+		return err
+	}
+	if info, err := os.Lstat(target); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		return fmt.Errorf("artifact file %q would write through a symlink", name)
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// applyWhiteout removes what an OCI whiteout entry in dir stands for: the
+// named entry, or everything in dir for an opaque whiteout.
+func applyWhiteout(dir, whiteout string) error {
+	if whiteout == ociOpaqueWhiteout {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil
+		}
+		for _, entry := range entries {
+			if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	name := strings.TrimPrefix(whiteout, ociWhiteoutPrefix)
+	if name == "" || name == "." || name == ".." {
+		return fmt.Errorf("invalid whiteout %q", whiteout)
+	}
+	return os.RemoveAll(filepath.Join(dir, name))
+}
+
+// get fetches /v2/<repository><path>, authenticating on the registry's
+// first 401 as its challenge asks.
+func (p *ociPuller) get(ctx context.Context, path string, accept ...string) (*http.Response, error) {
+	header := http.Header{}
+	if len(accept) > 0 {
+		header.Set("Accept", strings.Join(accept, ", "))
+	}
+	resp, err := p.send(ctx, http.MethodGet, p.repositoryURL(path), header, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s", path, resp.Status)
+	}
+	return resp, nil
+}
+
+// repositoryURL is the registry API URL /v2/<repository><path>.
+func (p *ociPuller) repositoryURL(path string) string {
+	return fmt.Sprintf("%s://%s/v2/%s%s", p.scheme, p.ref.Registry, p.ref.Repository, path)
+}
+
+// send makes a request to the registry, authenticating on its first 401
+// as its challenge asks, and returns the response whatever its status.
+// body, if set, opens the request body and its length, again for the
+// request after authenticating.
+func (p *ociPuller) send(ctx context.Context, method, target string, header http.Header, body func() (io.ReadCloser, int64, error)) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, target, nil)
+		if err != nil {
+			return nil, err
+		}
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		if body != nil {
+			content, size, err := body()
+			if err != nil {
+				return nil, err
+			}
+			req.Body, req.ContentLength = content, size
+		}
+		if p.authorization != "" {
+			req.Header.Set("Authorization", p.authorization)
+		}
+		resp, err := p.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusUnauthorized && attempt == 0 {
+			challenge := resp.Header.Get("WWW-Authenticate")
+			resp.Body.Close()
+			if err := p.authenticate(ctx, challenge); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		return resp, nil
+	}
+}
+
+// authenticate answers a registry's WWW-Authenticate challenge: with the
+// credentials for Basic, or with a token from the realm for Bearer.
+func (p *ociPuller) authenticate(ctx context.Context, challenge string) error {
+	scheme, rest, _ := strings.Cut(challenge, " ")
+	params := map[string]string{}
+	for _, m := range authParamPattern.FindAllStringSubmatch(rest, -1) {
+		params[strings.ToLower(m[1])] = m[2]
+	}
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if p.credentials == "" {
+			return fmt.Errorf("registry %s requires credentials", p.ref.Registry)
+		}
+		p.authorization = "Basic " + p.credentials
+		return nil
+	case "bearer":
+		token, err := p.fetchToken(ctx, params)
+		if err != nil {
+			return err
+		}
+		p.authorization = "Bearer " + token
+		return nil
+	default:
+		return fmt.Errorf("registry %s asks for unsupported authentication %q", p.ref.Registry, scheme)
+	}
+}
+
+func (p *ociPuller) fetchToken(ctx context.Context, params map[string]string) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || (realm.Scheme != "https" && realm.Scheme != "http") {
+		return "", fmt.Errorf("registry %s sent an invalid token realm", p.ref.Registry)
+	}
+	query := realm.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	scope := params["scope"]
+	if scope == "" {
+		actions := p.actions
+		if actions == "" {
+			actions = "pull"
+		}
+		scope = "repository:" + p.ref.Repository + ":" + actions
+	}
+	query.Set("scope", scope)
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if p.credentials != "" {
+		req.Header.Set("Authorization", "Basic "+p.credentials)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry %s token request: %s", p.ref.Registry, resp.Status)
+	}
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return "", fmt.Errorf("registry %s token response: %w", p.ref.Registry, err)
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	if body.AccessToken != "" {
+		return body.AccessToken, nil
+	}
+	return "", fmt.Errorf("registry %s returned no token", p.ref.Registry)
+}
+
+// registryCredentials returns the base64 user:password for registry from
+// the docker config.json at authPath, or "" if it has none.
+func registryCredentials(authPath, registry string) (string, error) {
+	if authPath == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(authPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read registry credentials: %w", err)
+	}
+	var config struct {
+		Auths map[string]struct {
+			Auth     string `json:"auth"`
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"auths"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return "", fmt.Errorf("failed to parse registry credentials: %w", err)
+	}
+	for key, entry := range config.Auths {
+		if registryHost(key) != registryHost(registry) {
+			continue
+		}
+		if entry.Auth != "" {
+			return entry.Auth, nil
+		}
+		if entry.Username != "" {
+			return base64.StdEncoding.EncodeToString([]byte(entry.Username + ":" + entry.Password)), nil
+		}
+	}
+	return "", nil
+}
+
+// registryHost normalizes a docker config "auths" key or registry: no
+// scheme or path, and one name for Docker Hub.
+func registryHost(key string) string {
+	if _, rest, ok := strings.Cut(key, "://"); ok {
+		key = rest
+	}
+	host, _, _ := strings.Cut(strings.ToLower(key), "/")
+	switch host {
+	case "docker.io", "index.docker.io":
+		return dockerHubRegistry
+	}
+	return host
+}
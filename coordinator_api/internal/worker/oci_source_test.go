@@ -0,0 +1,225 @@
+package worker
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+func TestParseOCIReference(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	tests := []struct {
+		ref  string
+		want OCIReference
+	}{
+		{"alpine", OCIReference{Registry: dockerHubRegistry, Repository: "library/alpine", Tag: "latest"}},
+		{"org/ctx:v1", OCIReference{Registry: dockerHubRegistry, Repository: "org/ctx", Tag: "v1"}},
+		{"ghcr.io/org/ctx:v1", OCIReference{Registry: "ghcr.io", Repository: "org/ctx", Tag: "v1"}},
+		{"registry.local:5000/ctx@" + digest, OCIReference{Registry: "registry.local:5000", Repository: "ctx", Digest: digest}},
+		{"ghcr.io/org/ctx:v1@" + digest, OCIReference{Registry: "ghcr.io", Repository: "org/ctx", Tag: "v1", Digest: digest}},
+	}
+	for _, tt := range tests {
+		got, err := ParseOCIReference(tt.ref)
+		require.NoError(t, err, tt.ref)
+		assert.Equal(t, tt.want, got, tt.ref)
+	}
+
+	for _, bad := range []string{"", "ghcr.io/Org/ctx", "ghcr.io/org/ctx@sha256:abc", "ghcr.io/org/ctx:-bad"} {
+		_, err := ParseOCIReference(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestValidateOCISource(t *testing.T) {
+	pin := "sha256:" + strings.Repeat("b", 64)
+	assert.NoError(t, ValidateOCISource("ghcr.io/org/ctx:v1", pin))
+	assert.Error(t, ValidateOCISource("ghcr.io/org/ctx:v1", "main"), "refs must be digests")
+	assert.Error(t, ValidateOCISource("ghcr.io/org/ctx@sha256:"+strings.Repeat("c", 64), pin), "conflicting digests")
+	assert.Equal(t, "https://ghcr.io", SourcePolicyURL("oci", "ghcr.io/org/ctx:v1"))
+	assert.Equal(t, "https://github.com/org/repo", SourcePolicyURL("git", "https://github.com/org/repo"))
+}
+
+// fakeRegistry serves one repository's manifests and blobs, and takes
+// pushes of them, behind bearer token auth for user:secret.
+type fakeRegistry struct {
+	server    *httptest.Server
+	manifests map[string][]byte // by tag and digest
+	blobs     map[string][]byte
+}
+
+func newFakeRegistry(t *testing.T) *fakeRegistry {
+	r := &fakeRegistry{manifests: map[string][]byte{}, blobs: map[string][]byte{}}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/token" {
+			if req.Header.Get("Authorization") != "Basic "+base64.StdEncoding.EncodeToString([]byte("user:secret")) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"token": "pull-token"})
+			return
+		}
+		if req.Header.Get("Authorization") != "Bearer pull-token" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="fake",scope="repository:ctx:pull"`, r.server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if req.Method == http.MethodPost && req.URL.Path == "/v2/ctx/blobs/uploads/" {
+			w.Header().Set("Location", "/v2/ctx/blobs/uploads/1?state=opaque")
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		if req.Method == http.MethodPut {
+			data, _ := io.ReadAll(req.Body)
+			if strings.HasPrefix(req.URL.Path, "/v2/ctx/blobs/uploads/") {
+				if r.addBlob(data) != req.URL.Query().Get("digest") || req.URL.Query().Get("state") != "opaque" {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+			} else if tag, ok := strings.CutPrefix(req.URL.Path, "/v2/ctx/manifests/"); ok {
+				var manifest interface{}
+				json.Unmarshal(data, &manifest)
+				r.addManifest(tag, manifest)
+			}
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		if ref, ok := strings.CutPrefix(req.URL.Path, "/v2/ctx/manifests/"); ok {
+			if body, ok := r.manifests[ref]; ok {
+				w.Write(body)
+				return
+			}
+		}
+		if digest, ok := strings.CutPrefix(req.URL.Path, "/v2/ctx/blobs/"); ok {
+			if body, ok := r.blobs[digest]; ok {
+				w.Write(body)
+				return
+			}
+		}
+		http.NotFound(w, req)
+	}))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func (r *fakeRegistry) host() string {
+	return strings.TrimPrefix(r.server.URL, "http://")
+}
+
+func (r *fakeRegistry) addBlob(data []byte) string {
+	sum := sha256.Sum256(data)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+	r.blobs[digest] = data
+	return digest
+}
+
+func (r *fakeRegistry) addManifest(tag string, manifest interface{}) string {
+	data, _ := json.Marshal(manifest)
+	sum := sha256.Sum256(data)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+	r.manifests[digest] = data
+	if tag != "" {
+		r.manifests[tag] = data
+	}
+	return digest
+}
+
+func tarGzLayer(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestPrepareOCISource(t *testing.T) {
+	reg := newFakeRegistry(t)
+	base := reg.addBlob(tarGzLayer(t, map[string]string{"Dockerfile": "FROM scratch", "old.txt": "stale"}))
+	top := reg.addBlob(tarGzLayer(t, map[string]string{"app/main.go": "package main", ".wh.old.txt": ""}))
+	notes := reg.addBlob([]byte("release notes"))
+	manifestDigest := reg.addManifest("", map[string]interface{}{
+		"mediaType": ociManifestMediaType,
+		"layers": []map[string]interface{}{
+			{"mediaType": "application/vnd.oci.image.layer.v1.tar+gzip", "digest": base},
+			{"mediaType": "application/vnd.oci.image.layer.v1.tar+gzip", "digest": top},
+			{"mediaType": "text/plain", "digest": notes, "annotations": map[string]string{ociTitleAnnotation: "NOTES.txt"}},
+		},
+	})
+	indexDigest := reg.addManifest("v1", map[string]interface{}{
+		"mediaType": ociIndexMediaType,
+		"manifests": []map[string]interface{}{
+			{"mediaType": ociManifestMediaType, "digest": "sha256:" + strings.Repeat("0", 64), "platform": map[string]string{"os": "linux", "architecture": "not-" + runtime.GOARCH}},
+			{"mediaType": ociManifestMediaType, "digest": manifestDigest, "platform": map[string]string{"os": "linux", "architecture": runtime.GOARCH}},
+		},
+	})
+
+	authPath := filepath.Join(t.TempDir(), "config.json")
+	auth := fmt.Sprintf(`{"auths":{"http://%s":{"auth":"%s"}}}`, reg.host(), base64.StdEncoding.EncodeToString([]byte("user:secret")))
+	require.NoError(t, os.WriteFile(authPath, []byte(auth), 0600))
+	jp := &JobProcessor{config: &JobProcessorConfig{OCISources: OCISourceConfig{AuthPath: authPath, InsecureRegistries: []string{reg.host()}}}}
+
+	ociJob := func(sourceURL, sourceRef string) *models.Job {
+		st := models.SourceTypeOCI
+		return &models.Job{JobID: "oci-job", SourceType: &st, SourceURL: &sourceURL, SourceRef: &sourceRef}
+	}
+
+	t.Run("pulls the layers for this platform", func(t *testing.T) {
+		codeDir := t.TempDir()
+		digest, err := jp.prepareOCISource(context.Background(), ociJob(reg.host()+"/ctx:v1", ""), codeDir)
+		require.NoError(t, err)
+		assert.Equal(t, indexDigest, digest)
+
+		data, err := os.ReadFile(filepath.Join(codeDir, "app", "main.go"))
+		require.NoError(t, err)
+		assert.Equal(t, "package main", string(data))
+		assert.FileExists(t, filepath.Join(codeDir, "Dockerfile"))
+		assert.NoFileExists(t, filepath.Join(codeDir, "old.txt"), "whited out by the top layer")
+		notes, err := os.ReadFile(filepath.Join(codeDir, "NOTES.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "release notes", string(notes))
+	})
+
+	t.Run("a pinned digest is pulled by digest", func(t *testing.T) {
+		reg.manifests["v1"] = []byte(`{"mediaType":"` + ociManifestMediaType + `","layers":[]}`)
+		digest, err := jp.prepareOCISource(context.Background(), ociJob(reg.host()+"/ctx:v1", manifestDigest), t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, manifestDigest, digest)
+	})
+
+	t.Run("content must match its digest", func(t *testing.T) {
+		reg.blobs[notes] = []byte("tampered")
+		_, err := jp.prepareOCISource(context.Background(), ociJob(reg.host()+"/ctx@"+manifestDigest, ""), t.TempDir())
+		assert.ErrorContains(t, err, "does not match")
+	})
+
+	t.Run("no credentials", func(t *testing.T) {
+		anonymous := &JobProcessor{config: &JobProcessorConfig{OCISources: OCISourceConfig{InsecureRegistries: []string{reg.host()}}}}
+		_, err := anonymous.prepareOCISource(context.Background(), ociJob(reg.host()+"/ctx:v1", ""), t.TempDir())
+		assert.ErrorContains(t, err, "token request")
+	})
+}
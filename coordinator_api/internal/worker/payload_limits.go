@@ -0,0 +1,67 @@
+package worker
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Payload limits. Job fields are stored in JSONB and text columns and
+// copied into Corndogs payloads, so they're bounded wherever a job comes
+// from: the jobs API and triggers documents alike. The name and description
+// limits apply to projects too.
+const (
+	MaxNameLength        = 255
+	MaxDescriptionLength = 4096
+	MaxJobCommandLength  = 64 << 10
+	MaxJobEnvVars        = 256
+	MaxJobEnvKeyLength   = 256
+	MaxJobEnvValueLength = 32 << 10
+	// MaxJobEnvBytes caps the sum of every env var's key and value.
+	MaxJobEnvBytes = 256 << 10
+
+	// MaxTriggersBytes caps the size of a triggers document.
+	MaxTriggersBytes = 4 << 20
+	// MaxTriggerJobs caps the jobs one triggers document can define.
+	MaxTriggerJobs = MaxChildJobs
+)
+
+// PayloadLimitError reports a job field over its limit.
+type PayloadLimitError struct {
+	Field  string `json:"field"`
+	Limit  int    `json:"limit"`
+	Actual int    `json:"actual"`
+}
+
+func (e *PayloadLimitError) Error() string {
+	return fmt.Sprintf("%s is %d, over the limit of %d", e.Field, e.Actual, e.Limit)
+}
+
+// CheckJobPayloadLimits checks a job's name, description, command and env
+// vars against the payload limits, returning every field over its limit,
+// env vars in key order.
+func CheckJobPayloadLimits(name, description, command string, env map[string]string) []*PayloadLimitError {
+	var errs []*PayloadLimitError
+	check := func(field string, actual, limit int) {
+		if actual > limit {
+			errs = append(errs, &PayloadLimitError{Field: field, Limit: limit, Actual: actual})
+		}
+	}
+	check("name length", len(name), MaxNameLength)
+	check("description length", len(description), MaxDescriptionLength)
+	check("command length", len(command), MaxJobCommandLength)
+	check("env var count", len(env), MaxJobEnvVars)
+
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	total := 0
+	for _, key := range keys {
+		total += len(key) + len(env[key])
+		check("env var key length", len(key), MaxJobEnvKeyLength)
+		check(fmt.Sprintf("env var %.64s value length", key), len(env[key]), MaxJobEnvValueLength)
+	}
+	check("env var total size", total, MaxJobEnvBytes)
+	return errs
+}
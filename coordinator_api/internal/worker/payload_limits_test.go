@@ -0,0 +1,53 @@
+package worker
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckJobPayloadLimits(t *testing.T) {
+	if errs := CheckJobPayloadLimits("build", "Builds things", "make", map[string]string{"A": "1"}); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+
+	env := map[string]string{
+		strings.Repeat("K", MaxJobEnvKeyLength+1): "v",
+		"BIG": strings.Repeat("v", MaxJobEnvValueLength+1),
+	}
+	errs := CheckJobPayloadLimits(strings.Repeat("n", MaxNameLength+1), "", "make", env)
+	fields := make([]string, 0, len(errs))
+	for _, err := range errs {
+		fields = append(fields, err.Field)
+	}
+	want := []string{"name length", "env var BIG value length", "env var key length"}
+	if strings.Join(fields, ",") != strings.Join(want, ",") {
+		t.Errorf("expected fields %v, got %v", want, fields)
+	}
+	if errs[0].Limit != MaxNameLength || errs[0].Actual != MaxNameLength+1 {
+		t.Errorf("unexpected name error %+v", errs[0])
+	}
+}
+
+func TestParseTriggers_PayloadLimits(t *testing.T) {
+	data := []byte(`{"type":"trigger_job","jobs":[{"job_name":"` + strings.Repeat("n", MaxNameLength+1) + `","job_command":"make"}]}`)
+	_, _, err := parseTriggers(data, false)
+	var verrs TriggerValidationErrors
+	if !errors.As(err, &verrs) || len(verrs) != 1 || verrs[0].Path != "jobs[0]" {
+		t.Fatalf("expected one jobs[0] limit error, got %v", err)
+	}
+
+	big := make([]byte, MaxTriggersBytes+1)
+	if _, _, err := parseTriggers(big, false); !errors.As(err, &verrs) {
+		t.Fatalf("expected an oversized document to be rejected, got %v", err)
+	}
+}
+
+func FuzzParseTriggers(f *testing.F) {
+	f.Add([]byte(`{"type":"trigger_job","jobs":[{"job_name":"a","job_command":"make"}]}`), false)
+	f.Add([]byte(`{"type":"trigger_job","version":2,"jobs":[{"job_name":"a","depends_on":["a"]}]}`), true)
+	f.Add([]byte(`{"jobs":null}`), false)
+	f.Fuzz(func(t *testing.T, data []byte, strict bool) {
+		parseTriggers(data, strict)
+	})
+}
@@ -0,0 +1,126 @@
+package worker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// Operating systems and architectures jobs can target.
+const (
+	OSLinux   = "linux"
+	OSWindows = "windows"
+	OSDarwin  = "darwin"
+
+	ArchAMD64 = "amd64"
+	ArchARM64 = "arm64"
+)
+
+// windowsShellPrefix wraps multiline and env-referencing commands of jobs
+// targeting Windows, in place of "sh -c". REACTORCIDE_JOB_SHELLCMD
+// overrides it, e.g. with "cmd /C".
+const windowsShellPrefix = "powershell -Command"
+
+var platformOSAliases = map[string]string{
+	OSLinux:   OSLinux,
+	OSWindows: OSWindows,
+	OSDarwin:  OSDarwin,
+	"macos":   OSDarwin,
+}
+
+var platformArchAliases = map[string]string{
+	ArchAMD64: ArchAMD64,
+	"x86_64":  ArchAMD64,
+	ArchARM64: ArchARM64,
+	"aarch64": ArchARM64,
+}
+
+// Platform is an OS/architecture pair: the platform a job targets, or the
+// one a worker claims jobs for. The zero Platform is the default, served by
+// the base queue alongside linux/amd64.
+type Platform struct {
+	OS   string
+	Arch string
+}
+
+// NormalizePlatform validates a target OS and arch, resolving aliases
+// ("macos", "x86_64", "aarch64"). An arch without an OS targets linux, and
+// an OS without an arch targets amd64. Both empty is the zero Platform.
+func NormalizePlatform(targetOS, targetArch string) (Platform, error) {
+	targetOS = strings.ToLower(strings.TrimSpace(targetOS))
+	targetArch = strings.ToLower(strings.TrimSpace(targetArch))
+	if targetOS == "" && targetArch == "" {
+		return Platform{}, nil
+	}
+	if targetOS == "" {
+		targetOS = OSLinux
+	}
+	if targetArch == "" {
+		targetArch = ArchAMD64
+	}
+	p := Platform{OS: platformOSAliases[targetOS], Arch: platformArchAliases[targetArch]}
+	if p.OS == "" {
+		return Platform{}, fmt.Errorf("unsupported target OS %q (supported: linux, windows, darwin)", targetOS)
+	}
+	if p.Arch == "" {
+		return Platform{}, fmt.Errorf("unsupported target arch %q (supported: amd64, arm64)", targetArch)
+	}
+	return p, nil
+}
+
+// ParsePlatform parses an "os/arch" (or bare "os") platform label, as used
+// by REACTORCIDE_WORKER_PLATFORM.
+func ParsePlatform(label string) (Platform, error) {
+	targetOS, targetArch, _ := strings.Cut(label, "/")
+	return NormalizePlatform(targetOS, targetArch)
+}
+
+// JobPlatform returns the platform a job targets.
+func JobPlatform(job *models.Job) (Platform, error) {
+	return NormalizePlatform(job.TargetOS, job.TargetArch)
+}
+
+// DefaultWorkerPlatform is the platform a worker claims jobs for when none
+// is configured. Linux workers keep working the base queue whatever their
+// arch, as they did before platform routing; Windows and macOS workers work
+// their own platform's queue.
+func DefaultWorkerPlatform() Platform {
+	if runtime.GOOS == OSLinux {
+		return Platform{}
+	}
+	return Platform{OS: runtime.GOOS, Arch: runtime.GOARCH}
+}
+
+// Queue returns the Corndogs queue for the platform (see
+// corndogs.PlatformQueue).
+func (p Platform) Queue(base string) string {
+	return corndogs.PlatformQueue(base, p.OS, p.Arch)
+}
+
+// Accepts reports whether a worker claiming jobs for p may run a job
+// targeting target, i.e. whether both are routed to the same queue.
+func (p Platform) Accepts(target Platform) bool {
+	return p.Queue("") == target.Queue("")
+}
+
+func (p Platform) String() string {
+	if p.OS == "" {
+		return "default"
+	}
+	return p.OS + "/" + p.Arch
+}
+
+// jobWorkspaceBaseDir is where job workspaces are created. Container
+// runtimes need it at the same path on the host, so it's fixed everywhere
+// but Windows, which has no /tmp.
+func jobWorkspaceBaseDir() string {
+	if runtime.GOOS == OSWindows {
+		return filepath.Join(os.TempDir(), "reactorcide-jobs")
+	}
+	return "/tmp/reactorcide-jobs"
+}
@@ -0,0 +1,100 @@
+package worker
+
+import (
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizePlatform(t *testing.T) {
+	tests := []struct {
+		name    string
+		os      string
+		arch    string
+		want    Platform
+		wantErr bool
+	}{
+		{name: "untargeted", want: Platform{}},
+		{name: "os only", os: "windows", want: Platform{OS: OSWindows, Arch: ArchAMD64}},
+		{name: "arch only", arch: "arm64", want: Platform{OS: OSLinux, Arch: ArchARM64}},
+		{name: "aliases", os: "macOS", arch: "aarch64", want: Platform{OS: OSDarwin, Arch: ArchARM64}},
+		{name: "x86_64", os: "linux", arch: "x86_64", want: Platform{OS: OSLinux, Arch: ArchAMD64}},
+		{name: "unsupported os", os: "plan9", wantErr: true},
+		{name: "unsupported arch", os: "linux", arch: "riscv64", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizePlatform(tt.os, tt.arch)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParsePlatform(t *testing.T) {
+	p, err := ParsePlatform("windows/arm64")
+	require.NoError(t, err)
+	assert.Equal(t, Platform{OS: OSWindows, Arch: ArchARM64}, p)
+
+	p, err = ParsePlatform("darwin")
+	require.NoError(t, err)
+	assert.Equal(t, Platform{OS: OSDarwin, Arch: ArchAMD64}, p)
+
+	_, err = ParsePlatform("beos/amd64")
+	assert.Error(t, err)
+}
+
+func TestPlatformQueueAndAccepts(t *testing.T) {
+	linuxAMD64 := Platform{OS: OSLinux, Arch: ArchAMD64}
+	windows := Platform{OS: OSWindows, Arch: ArchAMD64}
+
+	assert.Equal(t, "reactorcide-jobs", Platform{}.Queue("reactorcide-jobs"))
+	assert.Equal(t, "reactorcide-jobs", linuxAMD64.Queue("reactorcide-jobs"))
+	assert.Equal(t, "reactorcide-jobs-windows-amd64", windows.Queue("reactorcide-jobs"))
+
+	// The default worker runs untargeted and linux/amd64 jobs alike.
+	assert.True(t, Platform{}.Accepts(Platform{}))
+	assert.True(t, Platform{}.Accepts(linuxAMD64))
+	assert.True(t, linuxAMD64.Accepts(Platform{}))
+	assert.False(t, Platform{}.Accepts(windows))
+	assert.False(t, windows.Accepts(Platform{}))
+	assert.True(t, windows.Accepts(windows))
+
+	assert.Equal(t, "default", Platform{}.String())
+	assert.Equal(t, "windows/amd64", windows.String())
+}
+
+func TestValidateJob_Platform(t *testing.T) {
+	windowsJob := &models.Job{JobCommand: "Write-Output hi", TargetOS: OSWindows, TargetArch: ArchAMD64}
+
+	linuxProcessor := NewJobProcessorWithConfig(nil, nil, true, nil)
+	err := linuxProcessor.validateJob(windowsJob)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "windows/amd64")
+	assert.NoError(t, linuxProcessor.validateJob(&models.Job{JobCommand: "make"}))
+
+	windowsProcessor := NewJobProcessorWithConfig(nil, nil, true, &JobProcessorConfig{Platform: Platform{OS: OSWindows, Arch: ArchAMD64}})
+	assert.NoError(t, windowsProcessor.validateJob(windowsJob))
+	assert.Error(t, windowsProcessor.validateJob(&models.Job{JobCommand: "make"}))
+}
+
+func TestBuildJobConfig_WindowsDefaultsToPowerShell(t *testing.T) {
+	jp := NewJobProcessor(nil, nil, false)
+
+	config := jp.buildJobConfig(&models.Job{JobID: "job-1", JobCommand: "Write-Output $env:GREETING", TargetOS: OSWindows}, "/tmp/ws")
+	assert.Equal(t, []string{"powershell", "-Command", "Write-Output $env:GREETING"}, config.Command)
+
+	config = jp.buildJobConfig(&models.Job{
+		JobID:      "job-2",
+		JobCommand: "echo %PATH%\necho done",
+		TargetOS:   OSWindows,
+		JobEnvVars: models.JSONB{"REACTORCIDE_JOB_SHELLCMD": "cmd /C"},
+	}, "/tmp/ws")
+	assert.Equal(t, []string{"cmd", "/C", "echo %PATH%\necho done"}, config.Command)
+}
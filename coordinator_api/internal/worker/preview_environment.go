@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Env vars set on preview environment teardown jobs (see
+// jobcontrol.TeardownPreviewEnvironment).
+const (
+	PreviewActionEnv          = "REACTORCIDE_PREVIEW_ACTION"
+	PreviewEnvironmentIDEnv   = "REACTORCIDE_PREVIEW_ENVIRONMENT_ID"
+	PreviewEnvironmentNameEnv = "REACTORCIDE_PREVIEW_ENVIRONMENT_NAME"
+	PreviewEnvironmentURLEnv  = "REACTORCIDE_PREVIEW_ENVIRONMENT_URL"
+
+	// PreviewActionTeardown is PreviewActionEnv's value on teardown jobs.
+	PreviewActionTeardown = "teardown"
+)
+
+// previewTeardownStore is the narrow store capability for settling a
+// preview environment once its teardown job finishes. See
+// postgres_store/preview_environment_operations.go.
+type previewTeardownStore interface {
+	GetPreviewEnvironmentByTeardownJob(ctx context.Context, jobID string) (*models.PreviewEnvironment, error)
+	UpdatePreviewEnvironment(ctx context.Context, env *models.PreviewEnvironment, fromStatus string) (bool, error)
+}
+
+// settlePreviewTeardown marks the environment a finished teardown job was
+// tearing down as torn down, or as teardown_failed (so it can be torn down
+// again) if the job didn't complete successfully.
+func settlePreviewTeardown(ctx context.Context, s store.Store, job *models.Job, logger *logrus.Entry) {
+	if action, _ := job.JobEnvVars.String(PreviewActionEnv); action != PreviewActionTeardown || !job.IsCompleted() {
+		return
+	}
+	ps, ok := s.(previewTeardownStore)
+	if !ok {
+		return
+	}
+	env, err := ps.GetPreviewEnvironmentByTeardownJob(ctx, job.JobID)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to look up preview environment for teardown job")
+		return
+	}
+	if job.Status == "completed" {
+		now := time.Now().UTC()
+		env.Status = models.PreviewEnvironmentTornDown
+		env.TornDownAt = &now
+	} else {
+		env.Status = models.PreviewEnvironmentTeardownFailed
+	}
+	if _, err := ps.UpdatePreviewEnvironment(ctx, env, models.PreviewEnvironmentTearingDown); err != nil {
+		logger.WithError(err).Warn("Failed to settle preview environment teardown")
+		return
+	}
+	logger.WithFields(logrus.Fields{
+		"environment_id": env.EnvironmentID,
+		"status":         env.Status,
+	}).Info("Preview environment teardown finished")
+}
@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// previewTeardownTestStore records preview environments on top of MockStore.
+type previewTeardownTestStore struct {
+	MockStore
+	env *models.PreviewEnvironment
+}
+
+func (s *previewTeardownTestStore) GetPreviewEnvironmentByTeardownJob(ctx context.Context, jobID string) (*models.PreviewEnvironment, error) {
+	if s.env == nil || s.env.TeardownJobID == nil || *s.env.TeardownJobID != jobID {
+		return nil, store.ErrNotFound
+	}
+	copied := *s.env
+	return &copied, nil
+}
+
+func (s *previewTeardownTestStore) UpdatePreviewEnvironment(ctx context.Context, env *models.PreviewEnvironment, fromStatus string) (bool, error) {
+	if s.env.Status != fromStatus {
+		return false, nil
+	}
+	s.env.Status = env.Status
+	s.env.TornDownAt = env.TornDownAt
+	return true, nil
+}
+
+func TestSettlePreviewTeardown(t *testing.T) {
+	logger := logrus.NewEntry(logrus.New())
+	teardownEnv := models.JSONB{PreviewActionEnv: PreviewActionTeardown}
+
+	tests := []struct {
+		name       string
+		job        *models.Job
+		wantStatus string
+	}{
+		{"completed", &models.Job{JobID: "teardown-1", Status: "completed", JobEnvVars: teardownEnv}, models.PreviewEnvironmentTornDown},
+		{"failed", &models.Job{JobID: "teardown-1", Status: "failed", JobEnvVars: teardownEnv}, models.PreviewEnvironmentTeardownFailed},
+		{"still running", &models.Job{JobID: "teardown-1", Status: "running", JobEnvVars: teardownEnv}, models.PreviewEnvironmentTearingDown},
+		{"not a teardown job", &models.Job{JobID: "teardown-1", Status: "completed"}, models.PreviewEnvironmentTearingDown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teardownJobID := "teardown-1"
+			s := &previewTeardownTestStore{env: &models.PreviewEnvironment{
+				EnvironmentID: "env-1",
+				Status:        models.PreviewEnvironmentTearingDown,
+				TeardownJobID: &teardownJobID,
+			}}
+
+			settlePreviewTeardown(context.Background(), s, tt.job, logger)
+
+			assert.Equal(t, tt.wantStatus, s.env.Status)
+			assert.Equal(t, tt.wantStatus == models.PreviewEnvironmentTornDown, s.env.TornDownAt != nil)
+		})
+	}
+}
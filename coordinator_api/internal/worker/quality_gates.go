@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// checkQualityGates checks the quality gates of job's project against the
+// outputs the job reported in the "outputs" of its workflow output file.
+// A scan job's findings are checked against the project's scan thresholds
+// first, and their counts are outputs too (see checkScanFindings). It
+// returns nil when the job has no project or the project no gates that
+// apply to it. An output file that can't be parsed reports no outputs, so
+// the gates needing them fail.
+func checkQualityGates(ctx context.Context, s store.Store, job *models.Job, workspaceDir string) (models.GateResults, error) {
+	var project *models.Project
+	if job.ProjectID != nil && *job.ProjectID != "" {
+		var err error
+		if project, err = s.GetProjectByID(ctx, *job.ProjectID); err != nil {
+			return nil, fmt.Errorf("get project: %w", err)
+		}
+	}
+	var results models.GateResults
+	var counts map[string]int
+	if job.Scan != nil {
+		var thresholds models.ScanThresholds
+		if project != nil {
+			thresholds = project.ScanThresholds
+		}
+		results, counts = checkScanFindings(ctx, s, job, workspaceDir, thresholds)
+	}
+	if project == nil || len(project.QualityGates) == 0 {
+		return results, nil
+	}
+	var output workflowOutputFile
+	if data, err := os.ReadFile(filepath.Join(workspaceDir, "workflow-output.json")); err == nil {
+		_ = json.Unmarshal(data, &output)
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read workflow output file: %w", err)
+	}
+	if len(counts) > 0 && output.Outputs == nil {
+		output.Outputs = make(map[string]interface{}, len(counts))
+	}
+	for severity, n := range counts {
+		output.Outputs[models.ScanOutputName(severity)] = n
+	}
+	return append(results, models.EvaluateQualityGates(project.QualityGates, job.Name, output.Outputs)...), nil
+}
+
+// gateFailureMessage is a failed-gates job's LastError, e.g. "quality gates
+// failed: coverage: 72 >= 80 failed".
+func gateFailureMessage(failed models.GateResults) string {
+	parts := make([]string, len(failed))
+	for i, result := range failed {
+		parts[i] = result.String()
+	}
+	return "quality gates failed: " + strings.Join(parts, "; ")
+}
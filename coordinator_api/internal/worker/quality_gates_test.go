@@ -0,0 +1,135 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// gatedProjectStore is MockStore with a project that has quality gates
+// and scan thresholds, keeping the scan findings it's given.
+type gatedProjectStore struct {
+	MockStore
+	gates      models.QualityGates
+	thresholds models.ScanThresholds
+	findings   map[string][]models.ScanFinding
+}
+
+func (s *gatedProjectStore) GetProjectByID(ctx context.Context, projectID string) (*models.Project, error) {
+	return &models.Project{ProjectID: projectID, QualityGates: s.gates, ScanThresholds: s.thresholds}, nil
+}
+
+func (s *gatedProjectStore) ReplaceScanFindings(ctx context.Context, jobID string, findings []models.ScanFinding) error {
+	if s.findings == nil {
+		s.findings = make(map[string][]models.ScanFinding)
+	}
+	s.findings[jobID] = findings
+	return nil
+}
+
+func TestCheckQualityGates(t *testing.T) {
+	s := &gatedProjectStore{gates: models.QualityGates{
+		{Name: "coverage", Output: "coverage", Op: models.GateOpGTE, Threshold: 80},
+		{Name: "critical vulns", Output: "critical_vulns", Op: models.GateOpEQ, Threshold: 0, Jobs: []string{"scan-*"}},
+	}}
+	projectID := "project-1"
+	job := &models.Job{JobID: "job-1", Name: "test", ProjectID: &projectID}
+	dir := t.TempDir()
+
+	// No output file: the coverage gate fails, the scan gate doesn't apply.
+	results, err := checkQualityGates(context.Background(), s, job, dir)
+	if err != nil {
+		t.Fatalf("checkQualityGates() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Passed {
+		t.Fatalf("results without outputs = %+v, want one failed coverage gate", results)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "workflow-output.json"), []byte(`{"outputs":{"coverage":72.5}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	results, err = checkQualityGates(context.Background(), s, job, dir)
+	if err != nil {
+		t.Fatalf("checkQualityGates() error = %v", err)
+	}
+	failed := results.Failed()
+	if len(failed) != 1 {
+		t.Fatalf("failed gates = %+v, want coverage", failed)
+	}
+	if msg := gateFailureMessage(failed); !strings.Contains(msg, "coverage: 72.5 >= 80 failed") {
+		t.Errorf("gateFailureMessage() = %q", msg)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "workflow-output.json"), []byte(`{"outputs":{"coverage":"91"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if results, _ = checkQualityGates(context.Background(), s, job, dir); len(results.Failed()) != 0 {
+		t.Errorf("failed gates at 91%% coverage = %+v, want none", results.Failed())
+	}
+
+	// A job without a project isn't gated.
+	if results, _ := checkQualityGates(context.Background(), s, &models.Job{JobID: "job-2"}, dir); results != nil {
+		t.Errorf("results for a job without a project = %+v, want nil", results)
+	}
+}
+
+func TestCheckQualityGates_ScanJob(t *testing.T) {
+	s := &gatedProjectStore{
+		thresholds: models.ScanThresholds{"critical": 0, "high": 1},
+		gates:      models.QualityGates{{Name: "no medium", Output: "vulns_medium", Op: models.GateOpEQ, Threshold: 0}},
+	}
+	projectID := "project-1"
+	job := &models.Job{JobID: "job-1", Name: "scan", ProjectID: &projectID,
+		Scan: &models.ScanSpec{Scanner: models.ScannerTrivy, Target: models.ScanTargetImage, Image: "app:1"}}
+	dir := t.TempDir()
+
+	// No report: the scan fails.
+	results, err := checkQualityGates(context.Background(), s, job, dir)
+	if err != nil {
+		t.Fatalf("checkQualityGates() error = %v", err)
+	}
+	if failed := results.Failed(); len(failed) == 0 || failed[0].Name != "scan" {
+		t.Fatalf("results without a report = %+v, want a failed scan", results)
+	}
+
+	report := `{"Results":[{"Target":"app","Vulnerabilities":[
+		{"VulnerabilityID":"CVE-1","PkgName":"a","Severity":"HIGH"},
+		{"VulnerabilityID":"CVE-2","PkgName":"b","Severity":"MEDIUM"}]}]}`
+	if err := os.WriteFile(filepath.Join(dir, models.ScanReportFile), []byte(report), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	results, err = checkQualityGates(context.Background(), s, job, dir)
+	if err != nil {
+		t.Fatalf("checkQualityGates() error = %v", err)
+	}
+	if len(s.findings["job-1"]) != 2 {
+		t.Errorf("stored findings = %+v, want 2", s.findings["job-1"])
+	}
+	failed := results.Failed()
+	if len(results) != 3 || len(failed) != 1 || failed[0].Name != "no medium" {
+		t.Errorf("results = %+v, want critical and high thresholds passed, medium gate failed", results)
+	}
+}
+
+func TestApplyScanSpec(t *testing.T) {
+	git := models.SourceTypeGit
+	job := &models.Job{JobCommand: "make test", RunnerImage: "runner:1", SourceType: &git,
+		Scan: &models.ScanSpec{Scanner: models.ScannerGrype, Target: models.ScanTargetSource}}
+	ApplyScanSpec(job)
+	if job.RunnerImage != "anchore/grype:latest" {
+		t.Errorf("RunnerImage = %q", job.RunnerImage)
+	}
+	if job.JobCommand != "grype dir:/job/src --quiet --output json --file /job/scan-report.json" {
+		t.Errorf("JobCommand = %q", job.JobCommand)
+	}
+
+	plain := &models.Job{JobCommand: "make test"}
+	ApplyScanSpec(plain)
+	if plain.JobCommand != "make test" {
+		t.Errorf("a job without a scan changed: %q", plain.JobCommand)
+	}
+}
@@ -0,0 +1,124 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/metrics"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// queueRoutingStore is the narrow store capability for reading queue
+// occupancy. See postgres_store/worker_operations.go.
+type queueRoutingStore interface {
+	ListWorkers(ctx context.Context) ([]models.Worker, error)
+	CountQueuedJobs(ctx context.Context, queues []string) ([]models.QueuedJobCount, error)
+}
+
+// workerSeenWithin is how recently a worker must have reported in for its
+// free slots to count. A few missed reports mean it's gone.
+const workerSeenWithin = 3 * workerControlInterval
+
+// RouteProjectQueue picks the queue for a new job of a project with
+// spillover queues, before the job is created: the project's default queue
+// while it has a free worker for the job's platform, otherwise the first
+// spillover queue that has one, and the default queue again when none
+// does. A queue's free workers are its active workers' unused concurrency
+// less the jobs already waiting on it. The job is marked QueueRouted so it
+// is submitted to that queue. Jobs of projects without spillover queues
+// are left alone.
+//
+// A project's canary (see routeProjectCanary) is routed first; a canary
+// job sent to the canary's queue doesn't spill over.
+func RouteProjectQueue(ctx context.Context, st store.Store, project *models.Project, job *models.Job) {
+	if project == nil {
+		return
+	}
+	if routeProjectCanary(project, job) && project.Canary.QueueName != "" {
+		return
+	}
+	if len(project.SpilloverQueues) == 0 {
+		return
+	}
+	primary := project.DefaultQueueName
+	job.QueueName = primary
+	job.QueueRouted = true
+
+	rs, ok := st.(queueRoutingStore)
+	if !ok {
+		return
+	}
+	target, err := JobPlatform(job)
+	if err != nil {
+		return
+	}
+	free, err := freeWorkerSlots(ctx, rs, append([]string{primary}, project.SpilloverQueues...), target, time.Now().UTC())
+	if err != nil {
+		logging.Log.WithError(err).WithField("project_id", project.ProjectID).Warn("Failed to read queue occupancy, keeping the default queue")
+		return
+	}
+	if free[primary] > 0 {
+		return
+	}
+	for _, queue := range project.SpilloverQueues {
+		if free[queue] > 0 {
+			job.QueueName = queue
+			metrics.RecordQueueSpillover(primary, queue)
+			logging.Log.WithField("project_id", project.ProjectID).
+				WithField("queue", primary).
+				WithField("spillover_queue", queue).
+				Info("Default queue has no free worker, spilling job over")
+			return
+		}
+	}
+}
+
+// freeWorkerSlots returns the free worker slots for jobs targeting target
+// on each of queues. It may be negative where jobs are already waiting.
+func freeWorkerSlots(ctx context.Context, rs queueRoutingStore, queues []string, target Platform, now time.Time) (map[string]int, error) {
+	workers, err := rs.ListWorkers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	waiting, err := rs.CountQueuedJobs(ctx, queues)
+	if err != nil {
+		return nil, err
+	}
+
+	free := make(map[string]int, len(queues))
+	for _, queue := range queues {
+		free[queue] = 0
+	}
+	for _, w := range workers {
+		if _, ok := free[w.QueueName]; !ok || !w.Accepting() || now.Sub(w.LastSeenAt) > workerSeenWithin {
+			continue
+		}
+		if !workerPlatform(w).Accepts(target) {
+			continue
+		}
+		free[w.QueueName] += max(w.Concurrency-w.RunningJobs, 0)
+	}
+	for _, count := range waiting {
+		platform, err := NormalizePlatform(count.TargetOS, count.TargetArch)
+		if err != nil || !platform.Accepts(target) {
+			continue
+		}
+		free[count.QueueName] -= count.Jobs
+	}
+	return free, nil
+}
+
+// workerPlatform parses the platform a worker registered with (see
+// Platform.String).
+func workerPlatform(w models.Worker) Platform {
+	if w.Platform == "" || w.Platform == "default" {
+		return Platform{}
+	}
+	platform, err := ParsePlatform(w.Platform)
+	if err != nil {
+		return Platform{}
+	}
+	return platform
+}
@@ -0,0 +1,102 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// occupancyStore serves fixed workers and queued job counts on top of
+// MockStore.
+type occupancyStore struct {
+	MockStore
+	workers []models.Worker
+	queued  []models.QueuedJobCount
+}
+
+func (s *occupancyStore) ListWorkers(ctx context.Context) ([]models.Worker, error) {
+	return s.workers, nil
+}
+
+func (s *occupancyStore) CountQueuedJobs(ctx context.Context, queues []string) ([]models.QueuedJobCount, error) {
+	return s.queued, nil
+}
+
+func TestRouteProjectQueue(t *testing.T) {
+	now := time.Now().UTC()
+	worker := func(id, queue, platform string, concurrency, running int) models.Worker {
+		return models.Worker{
+			WorkerID: id, QueueName: queue, Platform: platform,
+			Concurrency: concurrency, RunningJobs: running,
+			DesiredState: models.WorkerStateActive, LastSeenAt: now,
+		}
+	}
+	project := &models.Project{ProjectID: "project-1", DefaultQueueName: "team-a", SpilloverQueues: []string{"shared", "overflow"}}
+
+	tests := []struct {
+		name    string
+		workers []models.Worker
+		queued  []models.QueuedJobCount
+		job     models.Job
+		want    string
+	}{
+		{
+			name:    "free dedicated worker",
+			workers: []models.Worker{worker("w1", "team-a", "default", 2, 1), worker("w2", "shared", "default", 4, 0)},
+			want:    "team-a",
+		},
+		{
+			name:    "dedicated queue busy",
+			workers: []models.Worker{worker("w1", "team-a", "default", 2, 2), worker("w2", "shared", "default", 4, 0)},
+			want:    "shared",
+		},
+		{
+			name:    "jobs already waiting take the free slots",
+			workers: []models.Worker{worker("w1", "team-a", "default", 2, 1), worker("w2", "shared", "default", 1, 0), worker("w3", "overflow", "default", 1, 0)},
+			queued:  []models.QueuedJobCount{{QueueName: "team-a", Jobs: 1}, {QueueName: "shared", Jobs: 1}},
+			want:    "overflow",
+		},
+		{
+			name: "drained and silent workers don't count",
+			workers: []models.Worker{
+				worker("w1", "team-a", "default", 2, 2),
+				{WorkerID: "w2", QueueName: "shared", Concurrency: 4, DesiredState: models.WorkerStateDraining, LastSeenAt: now},
+				{WorkerID: "w3", QueueName: "overflow", Concurrency: 4, DesiredState: models.WorkerStateActive, LastSeenAt: now.Add(-time.Hour)},
+			},
+			want: "team-a",
+		},
+		{
+			name:    "only workers of the job's platform count",
+			workers: []models.Worker{worker("w1", "team-a", "linux/arm64", 2, 0), worker("w2", "shared", "default", 2, 0), worker("w3", "overflow", "linux/arm64", 2, 0)},
+			job:     models.Job{TargetOS: "linux", TargetArch: "arm64"},
+			want:    "team-a",
+		},
+		{
+			name:    "no free worker anywhere",
+			workers: []models.Worker{worker("w1", "team-a", "default", 1, 1)},
+			want:    "team-a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &occupancyStore{workers: tt.workers, queued: tt.queued}
+			job := tt.job
+			job.QueueName = "reactorcide-jobs"
+			RouteProjectQueue(context.Background(), s, project, &job)
+			assert.Equal(t, tt.want, job.QueueName)
+			assert.True(t, job.QueueRouted)
+			assert.Equal(t, tt.want, BuildTaskPayload(&job).TargetQueue())
+		})
+	}
+
+	t.Run("project without spillover queues", func(t *testing.T) {
+		job := models.Job{QueueName: "team-b"}
+		RouteProjectQueue(context.Background(), &occupancyStore{}, &models.Project{DefaultQueueName: "team-b"}, &job)
+		assert.Equal(t, "team-b", job.QueueName)
+		assert.False(t, job.QueueRouted)
+		assert.Empty(t, BuildTaskPayload(&job).TargetQueue())
+	})
+}
@@ -0,0 +1,242 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
+)
+
+// releaseStore is the store support for publishing releases.
+type releaseStore interface {
+	GetReleaseByEvalJob(ctx context.Context, jobID string) (*models.Release, error)
+	ClaimRelease(ctx context.Context, releaseID string) (bool, error)
+	GetLatestPublishedRelease(ctx context.Context, projectID string) (*models.Release, error)
+	UpdateRelease(ctx context.Context, release *models.Release) error
+}
+
+// releaseClientSource resolves a job's VCS client; vcs.JobStatusUpdater
+// is one.
+type releaseClientSource interface {
+	ClientForJob(ctx context.Context, job *models.Job) vcs.Client
+}
+
+// settleRelease publishes the release whose tag pipeline job started, once
+// job has finished: it fails the release if the pipeline did. Jobs that
+// started no release are ignored.
+func (w *CornDogsWorker) settleRelease(ctx context.Context, job *models.Job, logger *logrus.Entry) {
+	if !job.IsCompleted() {
+		return
+	}
+	rs, ok := w.config.Store.(releaseStore)
+	if !ok {
+		return
+	}
+	release, err := rs.GetReleaseByEvalJob(ctx, job.JobID)
+	if err != nil {
+		if !errors.Is(err, store.ErrNotFound) {
+			logger.WithError(err).Warn("Failed to look up the job's release")
+		}
+		return
+	}
+	claimed, err := rs.ClaimRelease(ctx, release.ReleaseID)
+	if err != nil || !claimed {
+		if err != nil {
+			logger.WithError(err).Warn("Failed to claim release")
+		}
+		return
+	}
+	logger = logger.WithFields(logrus.Fields{"release_id": release.ReleaseID, "tag": release.Tag})
+
+	if job.Status != "completed" {
+		release.Status = models.ReleaseFailed
+		release.LastError = fmt.Sprintf("tag pipeline %s", job.Status)
+	} else {
+		var publisher vcs.ReleasePublisher
+		if source, ok := w.statusUpdater.(releaseClientSource); ok {
+			publisher, _ = source.ClientForJob(ctx, job).(vcs.ReleasePublisher)
+		}
+		PublishRelease(ctx, w.config.Store, w.config.ObjectStore, publisher, release, job)
+	}
+	if err := rs.UpdateRelease(ctx, release); err != nil {
+		logger.WithError(err).Error("Failed to save release")
+		return
+	}
+	if release.Status == models.ReleasePublished {
+		logger.WithField("url", release.URL).Info("Published release")
+	} else {
+		logger.WithField("error", release.LastError).Warn("Release failed")
+	}
+}
+
+// PublishRelease creates release in the VCS for the tag pipeline evalJob
+// started, which succeeded: with a changelog of the commits since the
+// project's previous published release, and the artifacts of the
+// pipeline's jobs that the project's ReleaseConfig includes. It links the
+// pipeline's jobs and sets the release's status: "failed", with LastError,
+// when the release couldn't be created. Artifacts that can't be attached
+// are noted in LastError of the published release.
+func PublishRelease(ctx context.Context, s store.Store, objectStore objects.ObjectStore, publisher vcs.ReleasePublisher, release *models.Release, evalJob *models.Job) {
+	fail := func(format string, args ...interface{}) {
+		release.Status = models.ReleaseFailed
+		release.LastError = fmt.Sprintf(format, args...)
+	}
+	jobs, err := releaseJobs(ctx, s, evalJob)
+	if err != nil {
+		fail("list pipeline jobs: %v", err)
+		return
+	}
+	release.JobIDs = make([]string, len(jobs))
+	for i := range jobs {
+		release.JobIDs[i] = jobs[i].JobID
+	}
+	if publisher == nil {
+		fail("no %s client that can publish releases", release.Provider)
+		return
+	}
+	project, err := s.GetProjectByID(ctx, release.ProjectID)
+	if err != nil {
+		fail("get project: %v", err)
+		return
+	}
+
+	if rs, ok := s.(releaseStore); ok {
+		if previous, err := rs.GetLatestPublishedRelease(ctx, release.ProjectID); err == nil && previous.Tag != release.Tag {
+			release.PreviousTag = previous.Tag
+		}
+	}
+	commits, err := publisher.CommitsBetween(ctx, release.Repo, release.PreviousTag, release.Tag)
+	if err != nil {
+		fail("list commits: %v", err)
+		return
+	}
+	release.Changelog = RenderChangelog(release.PreviousTag, commits)
+
+	info, err := publisher.CreateRelease(ctx, release.Repo, vcs.ReleaseRequest{
+		Tag:        release.Tag,
+		Name:       release.Tag,
+		Body:       release.Changelog,
+		Draft:      project.ReleaseConfig.Draft,
+		Prerelease: models.IsPrereleaseTag(release.Tag),
+	})
+	if err != nil {
+		fail("create release: %v", err)
+		return
+	}
+	release.VCSReleaseID = info.ID
+	release.URL = info.HTMLURL
+	release.Status = models.ReleasePublished
+	now := time.Now().UTC()
+	release.PublishedAt = &now
+
+	release.Artifacts, err = attachReleaseArtifacts(ctx, objectStore, publisher, info, &project.ReleaseConfig, jobs)
+	if err != nil {
+		release.LastError = err.Error()
+	}
+}
+
+// releaseJobs returns evalJob and the jobs its pipeline spawned, breadth
+// first, at most MaxChildJobs of them.
+func releaseJobs(ctx context.Context, s store.Store, evalJob *models.Job) ([]models.Job, error) {
+	jobs := []models.Job{*evalJob}
+	for i := 0; i < len(jobs) && len(jobs) < MaxChildJobs; i++ {
+		children, err := ListChildJobs(ctx, s, jobs[i].JobID)
+		if err != nil {
+			return nil, err
+		}
+		for _, child := range children {
+			if len(jobs) == MaxChildJobs {
+				break
+			}
+			jobs = append(jobs, child)
+		}
+	}
+	return jobs, nil
+}
+
+// attachReleaseArtifacts uploads the jobs' stored artifacts that config
+// includes to the release, as assets named after the artifact's file name.
+// Of artifacts with the same file name, the first job's wins.
+func attachReleaseArtifacts(ctx context.Context, objectStore objects.ObjectStore, publisher vcs.ReleasePublisher, info *vcs.ReleaseInfo, config *models.ReleaseConfig, jobs []models.Job) (models.ReleaseArtifacts, error) {
+	var attached models.ReleaseArtifacts
+	var problems []string
+	seen := map[string]bool{}
+	for _, job := range jobs {
+		prefix := strings.TrimSuffix(job.ArtifactsObjectKey, "/")
+		if prefix == "" {
+			continue
+		}
+		if objectStore == nil {
+			return attached, errors.New("artifacts not attached: this worker has no object store")
+		}
+		objs, err := objectStore.List(ctx, prefix+"/")
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("list artifacts of job %s: %v", job.JobID, err))
+			continue
+		}
+		for _, obj := range objs {
+			name := strings.TrimPrefix(obj.Key, prefix+"/")
+			asset := path.Base(name)
+			if !config.IncludesArtifact(name) || seen[asset] {
+				continue
+			}
+			seen[asset] = true
+			url, err := uploadReleaseArtifact(ctx, objectStore, publisher, info, obj, asset)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("attach %s: %v", asset, err))
+				continue
+			}
+			attached = append(attached, models.ReleaseArtifact{JobID: job.JobID, Name: asset, Size: obj.Size, URL: url})
+		}
+	}
+	if len(problems) > 0 {
+		return attached, errors.New(strings.Join(problems, "; "))
+	}
+	return attached, nil
+}
+
+func uploadReleaseArtifact(ctx context.Context, objectStore objects.ObjectStore, publisher vcs.ReleasePublisher, info *vcs.ReleaseInfo, obj objects.ObjectInfo, asset string) (string, error) {
+	content, err := objectStore.Get(ctx, obj.Key)
+	if err != nil {
+		return "", err
+	}
+	defer content.Close()
+	return publisher.UploadReleaseAsset(ctx, info, asset, content, obj.Size)
+}
+
+// RenderChangelog is a release's Markdown changelog: one line per commit,
+// oldest first, with the commit's subject, short SHA and author.
+func RenderChangelog(previousTag string, commits []vcs.Commit) string {
+	var b strings.Builder
+	if previousTag != "" {
+		fmt.Fprintf(&b, "## Changes since %s\n\n", previousTag)
+	} else {
+		b.WriteString("## Changes\n\n")
+	}
+	if len(commits) == 0 {
+		b.WriteString("No changes.\n")
+		return b.String()
+	}
+	for _, c := range commits {
+		subject, _, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+		sha := c.ID
+		if len(sha) > 7 {
+			sha = sha[:7]
+		}
+		fmt.Fprintf(&b, "- %s (%s", subject, sha)
+		if c.Author != "" {
+			fmt.Fprintf(&b, ", %s", c.Author)
+		}
+		b.WriteString(")\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,139 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
+)
+
+// releasePipelineStore is MockStore with a release-enabled project, a tag
+// pipeline of jobs and the project's previous release.
+type releasePipelineStore struct {
+	MockStore
+	config   models.ReleaseConfig
+	children map[string][]models.Job
+	previous *models.Release
+}
+
+func (s *releasePipelineStore) GetProjectByID(ctx context.Context, projectID string) (*models.Project, error) {
+	return &models.Project{ProjectID: projectID, ReleaseConfig: s.config}, nil
+}
+
+func (s *releasePipelineStore) ListJobs(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]models.Job, error) {
+	return s.children[filters["parent_job_id"].(string)], nil
+}
+
+func (s *releasePipelineStore) GetReleaseByEvalJob(ctx context.Context, jobID string) (*models.Release, error) {
+	return nil, fmt.Errorf("not used")
+}
+
+func (s *releasePipelineStore) ClaimRelease(ctx context.Context, releaseID string) (bool, error) {
+	return true, nil
+}
+
+func (s *releasePipelineStore) GetLatestPublishedRelease(ctx context.Context, projectID string) (*models.Release, error) {
+	if s.previous == nil {
+		return nil, fmt.Errorf("no release")
+	}
+	return s.previous, nil
+}
+
+func (s *releasePipelineStore) UpdateRelease(ctx context.Context, release *models.Release) error {
+	return nil
+}
+
+// fakeReleasePublisher records the release and assets it's given.
+type fakeReleasePublisher struct {
+	base    string
+	request vcs.ReleaseRequest
+	assets  map[string]string
+}
+
+func (p *fakeReleasePublisher) CommitsBetween(ctx context.Context, repo, base, head string) ([]vcs.Commit, error) {
+	p.base = base
+	return []vcs.Commit{
+		{ID: "1111111aaaa", Message: "Add widgets\n\nLong description.", Author: "Sam"},
+		{ID: "2222222bbbb", Message: "Fix widget crash"},
+	}, nil
+}
+
+func (p *fakeReleasePublisher) CreateRelease(ctx context.Context, repo string, release vcs.ReleaseRequest) (*vcs.ReleaseInfo, error) {
+	p.request = release
+	return &vcs.ReleaseInfo{ID: "77", HTMLURL: "https://github.com/org/repo/releases/tag/" + release.Tag}, nil
+}
+
+func (p *fakeReleasePublisher) UploadReleaseAsset(ctx context.Context, release *vcs.ReleaseInfo, name string, content io.Reader, size int64) (string, error) {
+	data, _ := io.ReadAll(content)
+	if p.assets == nil {
+		p.assets = map[string]string{}
+	}
+	p.assets[name] = string(data)
+	return "https://github.com/org/repo/releases/download/" + name, nil
+}
+
+func TestPublishRelease(t *testing.T) {
+	ctx := context.Background()
+	objectStore := objects.NewMemoryObjectStore()
+	for key, content := range map[string]string{
+		"artifacts/build/dist/app-linux.tar.gz": "linux",
+		"artifacts/build/coverage.txt":          "82%",
+		"artifacts/docs/dist/app-linux.tar.gz":  "duplicate",
+	} {
+		if err := objectStore.Put(ctx, key, strings.NewReader(content), "application/octet-stream"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := &releasePipelineStore{
+		config: models.ReleaseConfig{Enabled: true, Artifacts: []string{"*.tar.gz"}},
+		children: map[string][]models.Job{
+			"eval":  {{JobID: "build", ArtifactsObjectKey: "artifacts/build"}},
+			"build": {{JobID: "docs", ArtifactsObjectKey: "artifacts/docs/"}},
+		},
+		previous: &models.Release{Tag: "v1.1.0"},
+	}
+	release := &models.Release{ReleaseID: "r1", ProjectID: "p1", Tag: "v1.2.0-rc.1", Repo: "org/repo", Provider: "github"}
+	publisher := &fakeReleasePublisher{}
+
+	PublishRelease(ctx, s, objectStore, publisher, release, &models.Job{JobID: "eval", Status: "completed"})
+
+	if release.Status != models.ReleasePublished || release.LastError != "" {
+		t.Fatalf("release = %+v, want published", release)
+	}
+	if publisher.base != "v1.1.0" || release.PreviousTag != "v1.1.0" {
+		t.Errorf("changelog base = %q, want the previous release's tag", publisher.base)
+	}
+	if !publisher.request.Prerelease {
+		t.Errorf("an -rc tag should be a prerelease")
+	}
+	if want := "## Changes since v1.1.0\n\n- Add widgets (1111111, Sam)\n- Fix widget crash (2222222)\n"; release.Changelog != want {
+		t.Errorf("changelog = %q, want %q", release.Changelog, want)
+	}
+	if got := strings.Join(release.JobIDs, ","); got != "eval,build,docs" {
+		t.Errorf("job IDs = %s, want the whole pipeline", got)
+	}
+	if len(release.Artifacts) != 1 || publisher.assets["app-linux.tar.gz"] != "linux" {
+		t.Errorf("artifacts = %+v, assets = %v; want only the first app-linux.tar.gz", release.Artifacts, publisher.assets)
+	}
+	if release.URL == "" || release.VCSReleaseID != "77" || release.PublishedAt == nil {
+		t.Errorf("release = %+v, want the VCS release recorded", release)
+	}
+
+	// Without a client that can publish, the release fails.
+	failed := &models.Release{ProjectID: "p1", Tag: "v1.3.0", Provider: "gitlab"}
+	PublishRelease(ctx, s, objectStore, nil, failed, &models.Job{JobID: "eval", Status: "completed"})
+	if failed.Status != models.ReleaseFailed || !strings.Contains(failed.LastError, "gitlab") {
+		t.Errorf("release without a publisher = %+v, want failed", failed)
+	}
+}
+
+func TestRenderChangelog(t *testing.T) {
+	if got := RenderChangelog("", nil); got != "## Changes\n\nNo changes.\n" {
+		t.Errorf("RenderChangelog() = %q", got)
+	}
+}
@@ -0,0 +1,215 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/metrics"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// DefaultResourceSampleInterval is how often a ResourceSampler runner is
+// polled when JobProcessorConfig.ResourceSampleInterval is unset.
+const DefaultResourceSampleInterval = 10 * time.Second
+
+// bytesPerGB converts memory samples to GB for MemoryGBSeconds.
+const bytesPerGB = 1 << 30
+
+// ResourceUsage is what a job consumed while running, recorded per job for
+// cost accounting and, when enabled, as the job's resource metrics.
+type ResourceUsage struct {
+	CPUSeconds      float64
+	MemoryGBSeconds float64
+	StorageBytes    int64
+
+	// PeakMemoryBytes and the network byte counts come from the samples,
+	// of which there were Samples.
+	PeakMemoryBytes uint64
+	NetworkRxBytes  uint64
+	NetworkTxBytes  uint64
+	Samples         int
+
+	// Estimated is true when the runner couldn't be sampled, in which case
+	// CPUSeconds is the job's wall-clock time (one CPU) and MemoryGBSeconds
+	// is zero.
+	Estimated bool
+}
+
+// resourceUsageTracker polls a ResourceSampler runner for the lifetime of a
+// job container. CPU time and network bytes are cumulative, so the last
+// sample wins; memory is integrated over the sampling intervals, which
+// makes MemoryGBSeconds (and PeakMemoryBytes, where the runtime doesn't
+// track the peak itself) only as precise as the interval is short.
+type resourceUsageTracker struct {
+	sampler     ResourceSampler
+	containerID string
+	start       time.Time
+
+	mu              sync.Mutex
+	cpuSeconds      float64
+	memoryGBSeconds float64
+	peakMemoryBytes uint64
+	networkRxBytes  uint64
+	networkTxBytes  uint64
+	lastSample      time.Time
+	samples         int
+
+	done     chan struct{}
+	stopped  chan struct{}
+	stopOnce sync.Once
+	usage    ResourceUsage
+}
+
+// startResourceUsageTracker begins sampling containerID every interval when
+// runner implements ResourceSampler. Callers must call stop, which is safe
+// to call more than once.
+func startResourceUsageTracker(ctx context.Context, runner JobRunner, containerID string, interval time.Duration) *resourceUsageTracker {
+	now := time.Now()
+	t := &resourceUsageTracker{
+		containerID: containerID,
+		start:       now,
+		lastSample:  now,
+		done:        make(chan struct{}),
+		stopped:     make(chan struct{}),
+	}
+	sampler, ok := runner.(ResourceSampler)
+	if !ok {
+		close(t.stopped)
+		return t
+	}
+	if interval <= 0 {
+		interval = DefaultResourceSampleInterval
+	}
+	t.sampler = sampler
+	go t.run(ctx, interval)
+	return t
+}
+
+func (t *resourceUsageTracker) run(ctx context.Context, interval time.Duration) {
+	defer close(t.stopped)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.done:
+			return
+		case <-ticker.C:
+			t.sample(ctx)
+		}
+	}
+}
+
+func (t *resourceUsageTracker) sample(ctx context.Context) {
+	s, err := t.sampler.SampleResources(ctx, t.containerID)
+	if err != nil {
+		logging.Log.WithError(err).WithField("container_id", t.containerID).Debug("Failed to sample container resources")
+		return
+	}
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if s.CPUSeconds > t.cpuSeconds {
+		t.cpuSeconds = s.CPUSeconds
+	}
+	t.memoryGBSeconds += float64(s.MemoryBytes) / bytesPerGB * now.Sub(t.lastSample).Seconds()
+	t.peakMemoryBytes = max(t.peakMemoryBytes, s.MemoryBytes, s.PeakMemoryBytes)
+	t.networkRxBytes = max(t.networkRxBytes, s.NetworkRxBytes)
+	t.networkTxBytes = max(t.networkTxBytes, s.NetworkTxBytes)
+	t.lastSample = now
+	t.samples++
+}
+
+// stop ends sampling and returns the job's usage. StorageBytes is left for
+// the caller, which knows what was shipped to the object store.
+func (t *resourceUsageTracker) stop() ResourceUsage {
+	t.stopOnce.Do(func() {
+		close(t.done)
+		<-t.stopped
+
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if t.samples > 0 {
+			t.usage = ResourceUsage{
+				CPUSeconds:      t.cpuSeconds,
+				MemoryGBSeconds: t.memoryGBSeconds,
+				PeakMemoryBytes: t.peakMemoryBytes,
+				NetworkRxBytes:  t.networkRxBytes,
+				NetworkTxBytes:  t.networkTxBytes,
+				Samples:         t.samples,
+			}
+		} else {
+			t.usage = ResourceUsage{CPUSeconds: time.Since(t.start).Seconds(), Estimated: true}
+		}
+	})
+	return t.usage
+}
+
+// jobUsageRecorder is the narrow store capability for persisting per-job
+// usage. See postgres_store/cost_operations.go.
+type jobUsageRecorder interface {
+	RecordJobUsage(ctx context.Context, usage *models.JobUsage) error
+}
+
+// recordJobUsage prices usage at rates and stores it against job. It is
+// best-effort: stores without usage support are skipped and failures are
+// logged, never surfaced, since accounting must not fail a finished job.
+func recordJobUsage(ctx context.Context, s store.Store, rates models.CostRates, job *models.Job, usage ResourceUsage, completedAt time.Time) {
+	recorder, ok := s.(jobUsageRecorder)
+	if !ok || job.UserID == "" {
+		return
+	}
+	record := &models.JobUsage{
+		JobID:           job.JobID,
+		ProjectID:       job.ProjectID,
+		OrgID:           job.UserID,
+		CompletedAt:     completedAt,
+		CPUSeconds:      usage.CPUSeconds,
+		MemoryGBSeconds: usage.MemoryGBSeconds,
+		StorageBytes:    usage.StorageBytes,
+		Estimated:       usage.Estimated,
+	}
+	rates.Apply(record)
+	if err := recorder.RecordJobUsage(ctx, record); err != nil {
+		logging.Log.WithError(err).WithField("job_id", job.JobID).Warn("Failed to record job usage")
+	}
+}
+
+// jobResourcesRecorder is the narrow store capability for recording a job's
+// resource metrics on it. See postgres_store/cost_operations.go.
+type jobResourcesRecorder interface {
+	SetJobResources(ctx context.Context, jobID string, resources *models.JobResources) error
+}
+
+// recordJobResources stores usage's resource metrics on job and exports
+// them to Prometheus. Estimated usage has nothing measured and is skipped.
+// Like recordJobUsage it is best-effort.
+func recordJobResources(ctx context.Context, s store.Store, queue string, job *models.Job, usage ResourceUsage) {
+	if usage.Estimated || usage.Samples == 0 {
+		return
+	}
+	resources := &models.JobResources{
+		CPUSeconds:      usage.CPUSeconds,
+		PeakMemoryBytes: usage.PeakMemoryBytes,
+		NetworkRxBytes:  usage.NetworkRxBytes,
+		NetworkTxBytes:  usage.NetworkTxBytes,
+		Samples:         usage.Samples,
+	}
+	metrics.RecordJobResources(queue, resources.CPUSeconds, float64(resources.PeakMemoryBytes),
+		float64(resources.NetworkRxBytes), float64(resources.NetworkTxBytes))
+
+	recorder, ok := s.(jobResourcesRecorder)
+	if !ok {
+		return
+	}
+	if err := recorder.SetJobResources(ctx, job.JobID, resources); err != nil {
+		logging.Log.WithError(err).WithField("job_id", job.JobID).Warn("Failed to record job resources")
+		return
+	}
+	job.Resources = resources
+}
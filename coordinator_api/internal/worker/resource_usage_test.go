@@ -0,0 +1,143 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// samplingJobRunner is a fakeJobRunner that also implements
+// ResourceSampler, reporting one more CPU second and 1 KB more network
+// traffic per sample at 2 GB of memory, spiking to 3 GB on the second.
+type samplingJobRunner struct {
+	*fakeJobRunner
+	mu      sync.Mutex
+	samples int
+}
+
+func (s *samplingJobRunner) SampleResources(ctx context.Context, jobID string) (ResourceSample, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.samples++
+	memory := uint64(2 << 30)
+	if s.samples == 2 {
+		memory = 3 << 30
+	}
+	return ResourceSample{
+		CPUSeconds:     float64(s.samples),
+		MemoryBytes:    memory,
+		NetworkRxBytes: uint64(s.samples) * 1024,
+		NetworkTxBytes: uint64(s.samples) * 512,
+	}, nil
+}
+
+func (s *samplingJobRunner) sampleCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.samples
+}
+
+var _ ResourceSampler = (*samplingJobRunner)(nil)
+
+func TestResourceUsageTracker_Samples(t *testing.T) {
+	runner := &samplingJobRunner{fakeJobRunner: newFakeJobRunner()}
+	tracker := startResourceUsageTracker(context.Background(), runner, "c1", 5*time.Millisecond)
+
+	require.Eventually(t, func() bool { return runner.sampleCount() >= 3 }, time.Second, time.Millisecond)
+	usage := tracker.stop()
+
+	assert.False(t, usage.Estimated)
+	assert.GreaterOrEqual(t, usage.CPUSeconds, 3.0)
+	assert.Greater(t, usage.MemoryGBSeconds, 0.0)
+	assert.Equal(t, uint64(3<<30), usage.PeakMemoryBytes)
+	assert.GreaterOrEqual(t, usage.Samples, 3)
+	assert.Equal(t, uint64(usage.Samples)*1024, usage.NetworkRxBytes)
+	assert.Equal(t, uint64(usage.Samples)*512, usage.NetworkTxBytes)
+	assert.Equal(t, usage, tracker.stop(), "stop is idempotent")
+}
+
+func TestResourceUsageTracker_EstimatesWithoutSampler(t *testing.T) {
+	tracker := startResourceUsageTracker(context.Background(), newFakeJobRunner(), "c1", time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	usage := tracker.stop()
+
+	assert.True(t, usage.Estimated)
+	assert.GreaterOrEqual(t, usage.CPUSeconds, 0.01)
+	assert.Zero(t, usage.MemoryGBSeconds)
+}
+
+// usageStore records RecordJobUsage calls on top of MockStore.
+type usageStore struct {
+	MockStore
+	recorded []*models.JobUsage
+}
+
+func (s *usageStore) RecordJobUsage(ctx context.Context, usage *models.JobUsage) error {
+	s.recorded = append(s.recorded, usage)
+	return nil
+}
+
+func TestRecordJobUsage(t *testing.T) {
+	projectID := "project-1"
+	job := &models.Job{JobID: "job-1", UserID: "user-1", ProjectID: &projectID}
+	rates := models.CostRates{CPUSecond: 0.01, MemoryGBSecond: 0.001, StorageGB: 2, Currency: "EUR"}
+	completedAt := time.Now().UTC()
+
+	s := &usageStore{}
+	recordJobUsage(context.Background(), s, rates, job, ResourceUsage{
+		CPUSeconds:      100,
+		MemoryGBSeconds: 50,
+		StorageBytes:    1 << 29,
+	}, completedAt)
+
+	require.Len(t, s.recorded, 1)
+	got := s.recorded[0]
+	assert.Equal(t, "job-1", got.JobID)
+	assert.Equal(t, "user-1", got.OrgID)
+	assert.Equal(t, &projectID, got.ProjectID)
+	assert.Equal(t, completedAt, got.CompletedAt)
+	assert.Equal(t, "EUR", got.Currency)
+	assert.InDelta(t, 100*0.01+50*0.001+0.5*2, got.Cost, 1e-9)
+
+	// Stores without usage support are skipped.
+	recordJobUsage(context.Background(), &MockStore{}, rates, job, ResourceUsage{}, completedAt)
+}
+
+// resourcesStore records SetJobResources calls on top of MockStore.
+type resourcesStore struct {
+	MockStore
+	recorded map[string]*models.JobResources
+}
+
+func (s *resourcesStore) SetJobResources(ctx context.Context, jobID string, resources *models.JobResources) error {
+	s.recorded[jobID] = resources
+	return nil
+}
+
+func TestRecordJobResources(t *testing.T) {
+	s := &resourcesStore{recorded: map[string]*models.JobResources{}}
+	job := &models.Job{JobID: "job-1"}
+	recordJobResources(context.Background(), s, "default", job, ResourceUsage{
+		CPUSeconds:      12.5,
+		MemoryGBSeconds: 40,
+		PeakMemoryBytes: 3 << 30,
+		NetworkRxBytes:  4096,
+		NetworkTxBytes:  1024,
+		Samples:         4,
+	})
+
+	want := &models.JobResources{CPUSeconds: 12.5, PeakMemoryBytes: 3 << 30, NetworkRxBytes: 4096, NetworkTxBytes: 1024, Samples: 4}
+	assert.Equal(t, want, s.recorded["job-1"])
+	assert.Equal(t, want, job.Resources)
+
+	// Estimated usage measured nothing, so there is nothing to record.
+	job = &models.Job{JobID: "job-2"}
+	recordJobResources(context.Background(), s, "default", job, ResourceUsage{CPUSeconds: 30, Estimated: true})
+	assert.NotContains(t, s.recorded, "job-2")
+	assert.Nil(t, job.Resources)
+}
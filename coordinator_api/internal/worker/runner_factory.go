@@ -2,6 +2,7 @@ package worker
 
 import (
 	"fmt"
+	"runtime"
 	"strings"
 
 	"github.com/catalystcommunity/app-utils-go/logging"
@@ -20,13 +21,18 @@ const (
 	// BackendKubernetes uses Kubernetes Jobs
 	BackendKubernetes RunnerBackend = "kubernetes"
 
+	// BackendHost runs jobs as processes on the worker's host (Windows and
+	// macOS workers)
+	BackendHost RunnerBackend = "host"
+
 	// BackendAuto automatically detects the best backend
 	BackendAuto RunnerBackend = "auto"
 )
 
 // NewJobRunner creates a new JobRunner based on the specified backend
-// Supported backends: "docker", "containerd", "kubernetes", "auto"
+// Supported backends: "docker", "containerd", "kubernetes", "host", "auto"
 // "auto" will detect if running in Kubernetes and use that, otherwise Docker
+// (or host on Windows and macOS)
 func NewJobRunner(backend string) (JobRunner, error) {
 	// Normalize backend string (lowercase, trim whitespace)
 	backend = strings.ToLower(strings.TrimSpace(backend))
@@ -46,16 +52,26 @@ func NewJobRunner(backend string) (JobRunner, error) {
 	case BackendKubernetes:
 		return NewKubernetesRunner()
 
+	case BackendHost:
+		return NewHostRunner()
+
 	default:
-		return nil, fmt.Errorf("unsupported job runner backend: %s (supported: docker, containerd, kubernetes, auto)", backend)
+		return nil, fmt.Errorf("unsupported job runner backend: %s (supported: docker, containerd, kubernetes, host, auto)", backend)
 	}
 }
 
 // NewJobRunnerAuto automatically detects the best runner backend
-// It checks if running in Kubernetes first, then falls back to Docker
+// It checks if running in Kubernetes first, then falls back to Docker. On
+// Windows and macOS, where Linux job containers aren't available, it uses
+// the host runner.
 func NewJobRunnerAuto() (JobRunner, error) {
 	logger := logging.Log
 
+	if runtime.GOOS != OSLinux {
+		logger.WithField("os", runtime.GOOS).Info("Not running on Linux, using host runner")
+		return NewHostRunner()
+	}
+
 	// Check if running in Kubernetes
 	if IsKubernetesEnvironment() {
 		logger.Info("Detected Kubernetes environment, using Kubernetes Jobs runner")
@@ -79,6 +95,7 @@ func GetSupportedBackends() []RunnerBackend {
 		BackendDocker,
 		BackendContainerd,
 		BackendKubernetes,
+		BackendHost,
 	}
 }
 
@@ -96,9 +113,10 @@ func IsBackendSupported(backend string) bool {
 // IsBackendImplemented checks if a backend is fully implemented (not just stubbed)
 func IsBackendImplemented(backend string) bool {
 	backend = strings.ToLower(strings.TrimSpace(backend))
-	// Docker, Containerd, and Kubernetes are fully implemented
+	// Docker, Containerd, Kubernetes, and Host are fully implemented
 	return backend == string(BackendDocker) ||
 		backend == string(BackendContainerd) ||
 		backend == string(BackendKubernetes) ||
+		backend == string(BackendHost) ||
 		backend == string(BackendAuto)
 }
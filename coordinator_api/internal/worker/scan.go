@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// scanFindingStore is the store support for keeping scan jobs' findings.
+type scanFindingStore interface {
+	ReplaceScanFindings(ctx context.Context, jobID string, findings []models.ScanFinding) error
+}
+
+// ApplyScanSpec makes a scan job (one with a Scan) run its scanner: the
+// scanner's configured image and command replace the job's own.
+func ApplyScanSpec(job *models.Job) {
+	if job.Scan == nil {
+		return
+	}
+	switch job.Scan.Scanner {
+	case models.ScannerGrype:
+		job.RunnerImage = config.ScanGrypeImage
+	default:
+		job.RunnerImage = config.ScanTrivyImage
+	}
+	fromRepo := job.SourceType != nil && *job.SourceType == models.SourceTypeGit
+	job.JobCommand = job.Scan.Command(defaultJobCodeDir(job.CodeDir), fromRepo)
+}
+
+// checkScanFindings reads a scan job's report from its workspace, stores
+// the findings and checks their counts against thresholds. It also returns
+// the counts, for quality gates on the vulns_<severity> outputs. A report
+// that's missing or can't be parsed, or findings that can't be stored,
+// fail the scan with a result named "scan".
+func checkScanFindings(ctx context.Context, s store.Store, job *models.Job, workspaceDir string, thresholds models.ScanThresholds) (models.GateResults, map[string]int) {
+	failed := func(format string, args ...interface{}) models.GateResults {
+		return models.GateResults{{Name: "scan", Message: fmt.Sprintf(format, args...)}}
+	}
+	data, err := os.ReadFile(filepath.Join(workspaceDir, models.ScanReportFile))
+	if err != nil {
+		return failed("no scan report: %v", err), nil
+	}
+	findings, err := models.ParseScanReport(job.Scan.Scanner, data)
+	if err != nil {
+		return failed("%v", err), nil
+	}
+	if fs, ok := s.(scanFindingStore); ok {
+		if err := fs.ReplaceScanFindings(ctx, job.JobID, findings); err != nil {
+			return failed("store findings: %v", err), nil
+		}
+	}
+	counts := models.CountFindings(findings)
+	return thresholds.Evaluate(counts), counts
+}
@@ -8,6 +8,8 @@ import (
 	"strings"
 
 	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/secrets"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
 )
 
@@ -15,7 +17,67 @@ type secretGrantStore interface {
 	ListSecretGrantsForJob(ctx context.Context, userID string, projectID *string, jobName string) ([]models.SecretGrant, error)
 }
 
+// secretAccessPolicyStore lists an org's secret access policies.
+type secretAccessPolicyStore interface {
+	ListSecretAccessPolicies(ctx context.Context, orgID string) ([]models.SecretAccessPolicy, error)
+}
+
+// JobSecretAccessPolicy is the secret access policy job's secrets are
+// resolved under: its user's, as the API token it was submitted with sees
+// it (see models.Job.SecretScopes).
+func JobSecretAccessPolicy(ctx context.Context, st store.Store, job *models.Job) (*secrets.AccessPolicy, error) {
+	return userSecretAccessPolicy(ctx, st, job.UserID, job.SecretScopes)
+}
+
+// userSecretAccessPolicy is userID's secret access policy in their own
+// org, limited to tokenScopes if there are any. A store without access
+// policies leaves unscoped users unrestricted and scoped ones with no
+// access.
+func userSecretAccessPolicy(ctx context.Context, st store.Store, userID string, tokenScopes []string) (*secrets.AccessPolicy, error) {
+	principal := secrets.Principal{UserID: userID, TokenScopes: tokenScopes}
+	ps, ok := st.(secretAccessPolicyStore)
+	if !ok {
+		return secrets.NewAccessPolicy(principal, nil), nil
+	}
+	policies, err := ps.ListSecretAccessPolicies(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(policies) > 0 && len(tokenScopes) == 0 {
+		// Role policies only apply to unscoped principals.
+		user, err := st.GetUserByID(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load roles of user %s: %w", userID, err)
+		}
+		if user != nil {
+			principal.Roles = user.Roles
+		}
+	}
+	return secrets.NewAccessPolicy(principal, policies), nil
+}
+
 func (jp *JobProcessor) authorizeSecretAccess(ctx context.Context, job *models.Job, path, key string) error {
+	return AuthorizeSecretAccess(ctx, jp.store, job, path, key)
+}
+
+// AuthorizeSecretAccess checks that job may read the secret at path and
+// key: its access policy allows it, and it's the job's own or one of the
+// secret grants in st covers it.
+func AuthorizeSecretAccess(ctx context.Context, st store.Store, job *models.Job, path, key string) error {
+	access, err := JobSecretAccessPolicy(ctx, st, job)
+	if err != nil {
+		return err
+	}
+	if !access.Allows(path, models.SecretAccessRead) {
+		logging.Log.WithFields(map[string]interface{}{
+			"job_id": job.JobID,
+			"path":   path,
+			"key":    key,
+			"scopes": job.SecretScopes,
+		}).Warn("Secret access denied by access policy")
+		return fmt.Errorf("secret access denied for %s:%s: the job's access policy doesn't allow reading %s", path, key, path)
+	}
+
 	if isJobScopedSecret(job, path) {
 		logging.Log.WithFields(map[string]interface{}{
 			"job_id": job.JobID,
@@ -26,7 +88,7 @@ func (jp *JobProcessor) authorizeSecretAccess(ctx context.Context, job *models.J
 		return nil
 	}
 
-	grantStore, ok := jp.store.(secretGrantStore)
+	grantStore, ok := st.(secretGrantStore)
 	if !ok {
 		return fmt.Errorf("secret access denied for %s:%s: secret grants are not available", path, key)
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/secrets"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
 	"github.com/stretchr/testify/require"
 )
@@ -84,3 +85,56 @@ func TestAuthorizeSecretAccess_SupportsGrantPatterns(t *testing.T) {
 	job.Name = "test-linux-amd64"
 	require.Error(t, jp.authorizeSecretAccess(context.Background(), job, "catalystcommunity/registry", "password"))
 }
+
+// secretPolicyMockStore adds secret access policies to secretGrantMockStore.
+type secretPolicyMockStore struct {
+	secretGrantMockStore
+	policies []models.SecretAccessPolicy
+}
+
+func (s *secretPolicyMockStore) ListSecretAccessPolicies(ctx context.Context, orgID string) ([]models.SecretAccessPolicy, error) {
+	return s.policies, nil
+}
+
+func TestResolveJobSecrets_ScopedTokenJob(t *testing.T) {
+	secretsPath, secretsPassword := setupLocalSecretsProvider(t)
+	provider, err := secrets.NewLocalProvider(secretsPath, secretsPassword)
+	require.NoError(t, err)
+	ctx := context.Background()
+	require.NoError(t, provider.Set(ctx, "deploy/app", "token", "deploy-token-fake"))
+	require.NoError(t, provider.Set(ctx, "prod/db", "password", "db-password-fake"))
+
+	jp := &JobProcessor{
+		store: &secretPolicyMockStore{
+			secretGrantMockStore: secretGrantMockStore{grants: []models.SecretGrant{
+				{GrantID: "grant-1", SecretPathMatch: models.SecretGrantMatchPrefix, SecretPathPattern: "deploy"},
+				{GrantID: "grant-2", SecretPathMatch: models.SecretGrantMatchPrefix, SecretPathPattern: "prod"},
+			}},
+			policies: []models.SecretAccessPolicy{{
+				SubjectType: models.SecretSubjectTokenScope,
+				Subject:     "deploy",
+				PathPattern: "deploy/*",
+				Access:      models.SecretAccessRead,
+			}},
+		},
+		config: &JobProcessorConfig{
+			SecretsStorageType:   "local",
+			SecretsLocalPath:     secretsPath,
+			SecretsLocalPassword: secretsPassword,
+		},
+	}
+	scoped := &models.Job{JobID: "job-1", UserID: "user-1", Name: "deploy", SecretScopes: []string{"deploy"}}
+
+	result, err := jp.resolveJobSecrets(ctx, scoped, map[string]string{"TOKEN": "${secret:deploy/app:token}"})
+	require.NoError(t, err)
+	require.Equal(t, "deploy-token-fake", result.Resolved["TOKEN"])
+
+	_, err = jp.resolveJobSecrets(ctx, scoped, map[string]string{"DB_PASSWORD": "${secret:prod/db:password}"})
+	require.ErrorContains(t, err, "access policy")
+
+	// The same job submitted without a scoped token keeps its user's access.
+	unscoped := &models.Job{JobID: "job-2", UserID: "user-1", Name: "deploy"}
+	result, err = jp.resolveJobSecrets(ctx, unscoped, map[string]string{"DB_PASSWORD": "${secret:prod/db:password}"})
+	require.NoError(t, err)
+	require.Equal(t, "db-password-fake", result.Resolved["DB_PASSWORD"])
+}
@@ -0,0 +1,466 @@
+package worker
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// SharedWorkspaceMountPath is where a job's shared workspace appears
+	// inside the job.
+	SharedWorkspaceMountPath = "/job/shared"
+
+	// SharedWorkspaceEnv is set to SharedWorkspaceMountPath for jobs that
+	// have a shared workspace.
+	SharedWorkspaceEnv = "REACTORCIDE_SHARED_WORKSPACE"
+
+	// DefaultSharedWorkspaceTTL is how long an unused local copy of a
+	// shared workspace is kept when SharedWorkspaceConfig.TTL is unset.
+	DefaultSharedWorkspaceTTL = 24 * time.Hour
+)
+
+var sharedWorkspaceNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,62}$`)
+
+// ValidateSharedWorkspaceName checks a shared workspace name requested by a
+// trigger spec.
+func ValidateSharedWorkspaceName(name string) error {
+	if !sharedWorkspaceNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid shared workspace name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	return nil
+}
+
+// SharedWorkspaceConfig configures the worker's shared workspaces.
+type SharedWorkspaceConfig struct {
+	// Upload stores each shared workspace in the object store after the
+	// job that wrote it, so that a downstream job picked up by another
+	// worker can download it. It can be turned off when every worker sees
+	// the same workspace directory (a single worker, or a shared volume).
+	Upload bool
+	// TTL bounds how long an unused local copy is kept (default:
+	// DefaultSharedWorkspaceTTL).
+	TTL time.Duration
+}
+
+// SharedWorkspaces provides the named workspaces that jobs of one pipeline
+// share (Job.SharedWorkspace, scoped by Job.SharedWorkspaceScope), so that a
+// build job's output is there for the test and deploy jobs after it.
+//
+// A shared workspace lives on the worker's disk between jobs and is moved
+// into each job's workspace at SharedWorkspaceMountPath while the job runs.
+// A downstream job that runs on the same worker finds it there. One that
+// runs elsewhere downloads the copy the last successful writer uploaded to
+// the object store. The object store holds the writer's job ID alongside
+// the archive, so a local copy is only used while it's the latest.
+type SharedWorkspaces struct {
+	config      SharedWorkspaceConfig
+	baseDir     string
+	objectStore objects.ObjectStore
+
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// NewSharedWorkspaces creates the worker's shared workspaces. objectStore
+// may be nil, in which case nothing is uploaded.
+func NewSharedWorkspaces(objectStore objects.ObjectStore, config SharedWorkspaceConfig) *SharedWorkspaces {
+	if config.TTL == 0 {
+		config.TTL = DefaultSharedWorkspaceTTL
+	}
+	if objectStore == nil {
+		config.Upload = false
+	}
+	return &SharedWorkspaces{
+		config:      config,
+		baseDir:     filepath.Join(jobWorkspaceBaseDir(), "shared"),
+		objectStore: objectStore,
+		locks:       make(map[string]*sync.Mutex),
+	}
+}
+
+// attachedSharedWorkspace is a shared workspace moved into a running job's
+// workspace. Release moves it back.
+type attachedSharedWorkspace struct {
+	sw       *SharedWorkspaces
+	scope    string
+	name     string
+	cacheDir string
+	jobDir   string
+	lock     *sync.Mutex
+	logger   *logrus.Entry
+
+	releaseOnce sync.Once
+}
+
+// attachSharedWorkspace gives the job its shared workspace, if it asked for
+// one. Kubernetes jobs don't run in the worker's filesystem, so they can't
+// have one; they and workers without shared workspaces run the job without
+// it.
+func (jp *JobProcessor) attachSharedWorkspace(ctx context.Context, job *models.Job, workspaceDir string) (*attachedSharedWorkspace, error) {
+	if job.SharedWorkspace == "" {
+		return nil, nil
+	}
+	logger := logging.Log.WithField("job_id", job.JobID).WithField("shared_workspace", job.SharedWorkspace)
+	if jp.config.SharedWorkspaces == nil {
+		logger.Warn("Shared workspaces are not available on this worker; running the job without one")
+		return nil, nil
+	}
+	if _, ok := jp.runner.(*KubernetesRunner); ok {
+		logger.Warn("Shared workspaces are not supported by the Kubernetes runner; running the job without one")
+		return nil, nil
+	}
+	return jp.config.SharedWorkspaces.Attach(ctx, job, workspaceDir)
+}
+
+// Attach moves the job's shared workspace into workspaceDir. Jobs sharing a
+// workspace on this worker run one at a time; the next waits for Release.
+func (s *SharedWorkspaces) Attach(ctx context.Context, job *models.Job, workspaceDir string) (*attachedSharedWorkspace, error) {
+	scope := job.SharedWorkspaceScope
+	if scope == "" {
+		scope = job.JobID
+	}
+	if err := ValidateSharedWorkspaceName(job.SharedWorkspace); err != nil {
+		return nil, err
+	}
+	if !sharedWorkspaceNamePattern.MatchString(scope) {
+		return nil, fmt.Errorf("invalid shared workspace scope %q", scope)
+	}
+
+	a := &attachedSharedWorkspace{
+		sw:       s,
+		scope:    scope,
+		name:     job.SharedWorkspace,
+		cacheDir: filepath.Join(s.baseDir, scope, job.SharedWorkspace),
+		jobDir:   filepath.Join(workspaceDir, filepath.FromSlash(strings.TrimPrefix(SharedWorkspaceMountPath, "/job/"))),
+		lock:     s.lock(scope + "/" + job.SharedWorkspace),
+		logger: logging.Log.WithFields(map[string]interface{}{
+			"job_id":           job.JobID,
+			"shared_workspace": job.SharedWorkspace,
+			"scope":            scope,
+		}),
+	}
+	a.lock.Lock()
+	s.prune(scope)
+
+	if err := a.fetch(ctx); err != nil {
+		a.lock.Unlock()
+		return nil, fmt.Errorf("failed to attach shared workspace %q: %w", job.SharedWorkspace, err)
+	}
+	if err := chownTree(a.jobDir, 1001, 1001); err != nil {
+		a.logger.WithError(err).Warn("Failed to chown shared workspace - job may fail if running as non-root")
+	}
+	return a, nil
+}
+
+// fetch puts the latest copy of the workspace at jobDir: the local copy if
+// it's the latest, else the uploaded one, else a new empty directory.
+func (a *attachedSharedWorkspace) fetch(ctx context.Context) error {
+	localGeneration, _ := os.ReadFile(a.generationFile())
+	remoteGeneration, err := a.remoteGeneration(ctx)
+	if err != nil {
+		return err
+	}
+	_, statErr := os.Stat(a.cacheDir)
+	haveLocal := statErr == nil
+
+	switch {
+	case haveLocal && (remoteGeneration == "" || remoteGeneration == string(localGeneration)):
+		a.logger.Info("Using local copy of shared workspace")
+		return os.Rename(a.cacheDir, a.jobDir)
+	case remoteGeneration != "":
+		a.logger.WithField("generation", remoteGeneration).Info("Downloading shared workspace")
+		os.RemoveAll(a.cacheDir)
+		os.Remove(a.generationFile())
+		archive, err := a.sw.objectStore.Get(ctx, a.objectKey("workspace.tar.gz"))
+		if err != nil {
+			return fmt.Errorf("failed to download shared workspace: %w", err)
+		}
+		defer archive.Close()
+		return extractTarGz(archive, a.jobDir)
+	default:
+		a.logger.Info("Starting new shared workspace")
+		return os.MkdirAll(a.jobDir, 0777)
+	}
+}
+
+// Release moves the workspace back out of the job's workspace. If the job
+// succeeded, its changes become the workspace's latest copy and, when
+// uploads are on, are uploaded for other workers. If it didn't, they are
+// discarded with the job's workspace and the next job starts from the
+// latest uploaded copy. Release is safe to call more than once; only the
+// first call has any effect.
+func (a *attachedSharedWorkspace) Release(ctx context.Context, jobID string, succeeded bool) error {
+	var err error
+	a.releaseOnce.Do(func() {
+		defer a.lock.Unlock()
+		if !succeeded {
+			os.Remove(a.generationFile())
+			return
+		}
+		err = a.persist(ctx, jobID)
+	})
+	return err
+}
+
+func (a *attachedSharedWorkspace) persist(ctx context.Context, jobID string) error {
+	os.RemoveAll(a.cacheDir)
+	if err := os.MkdirAll(filepath.Dir(a.cacheDir), 0755); err != nil {
+		return fmt.Errorf("failed to keep shared workspace: %w", err)
+	}
+	if err := os.Rename(a.jobDir, a.cacheDir); err != nil {
+		return fmt.Errorf("failed to keep shared workspace: %w", err)
+	}
+	if err := os.WriteFile(a.generationFile(), []byte(jobID), 0644); err != nil {
+		return fmt.Errorf("failed to keep shared workspace: %w", err)
+	}
+	if !a.sw.config.Upload {
+		return nil
+	}
+
+	// The generation is written last: until it names this job, other
+	// workers keep using the previous upload.
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeTarGz(pw, a.cacheDir))
+	}()
+	if err := a.sw.objectStore.Put(ctx, a.objectKey("workspace.tar.gz"), pr, "application/gzip"); err != nil {
+		pr.CloseWithError(err)
+		return fmt.Errorf("failed to upload shared workspace: %w", err)
+	}
+	if err := a.sw.objectStore.Put(ctx, a.objectKey("generation"), strings.NewReader(jobID), "text/plain"); err != nil {
+		return fmt.Errorf("failed to upload shared workspace: %w", err)
+	}
+	a.logger.Info("Uploaded shared workspace")
+	return nil
+}
+
+func (a *attachedSharedWorkspace) remoteGeneration(ctx context.Context) (string, error) {
+	if !a.sw.config.Upload {
+		return "", nil
+	}
+	r, err := a.sw.objectStore.Get(ctx, a.objectKey("generation"))
+	if errors.Is(err, objects.ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to check shared workspace: %w", err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to check shared workspace: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func (a *attachedSharedWorkspace) generationFile() string {
+	return a.cacheDir + ".generation"
+}
+
+func (a *attachedSharedWorkspace) objectKey(file string) string {
+	return path.Join("workspaces", a.scope, a.name, file)
+}
+
+func (s *SharedWorkspaces) lock(key string) *sync.Mutex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l, ok := s.locks[key]
+	if !ok {
+		l = &sync.Mutex{}
+		s.locks[key] = l
+	}
+	return l
+}
+
+// prune removes local copies of other pipelines' workspaces that haven't
+// been used for the TTL.
+func (s *SharedWorkspaces) prune(keepScope string) {
+	entries, err := os.ReadDir(s.baseDir)
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().Add(-s.config.TTL)
+	for _, entry := range entries {
+		if entry.Name() == keepScope {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(s.baseDir, entry.Name())); err != nil {
+			logging.Log.WithError(err).WithField("scope", entry.Name()).Warn("Failed to prune shared workspace")
+		}
+	}
+}
+
+// chownTree gives uid:gid ownership of everything under dir.
+func chownTree(dir string, uid, gid int) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(p, uid, gid)
+	})
+}
+
+// writeTarGz writes the contents of dir to w as a gzipped tar.
+func writeTarGz(w io.Writer, dir string) error {
+	return writeTarGzSkipping(w, dir, nil)
+}
+
+// writeTarGzSkipping is writeTarGz leaving out the directories, given
+// relative to dir, that skipDir reports. A nil skipDir keeps everything.
+func writeTarGzSkipping(w io.Writer, dir string, skipDir func(rel string) bool) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		if d.IsDir() && skipDir != nil && skipDir(filepath.ToSlash(rel)) {
+			return fs.SkipDir
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		link := ""
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// extractTarGz extracts a gzipped tar written by writeTarGz into dir.
+func extractTarGz(r io.Reader, dir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	return extractTar(tar.NewReader(gz), dir, false)
+}
+
+// extractTar extracts tr into dir. Entries that would land outside dir,
+// directly or through a symlink extracted before them, are rejected. With
+// whiteouts set, OCI layer whiteouts (.wh.<name> and .wh..wh..opq) remove
+// what earlier layers extracted instead of being extracted themselves.
+func extractTar(tr *tar.Reader, dir string, whiteouts bool) error {
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return err
+	}
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(root, filepath.FromSlash(header.Name))
+		if !withinDir(root, target) {
+			return fmt.Errorf("archive entry %q escapes the workspace", header.Name)
+		}
+		parent, err := filepath.EvalSymlinks(filepath.Dir(target))
+		if err == nil && !withinDir(root, parent) {
+			return fmt.Errorf("archive entry %q escapes the workspace", header.Name)
+		}
+		if base := path.Base(header.Name); whiteouts && strings.HasPrefix(base, ociWhiteoutPrefix) {
+			if err := applyWhiteout(filepath.Dir(target), base); err != nil {
+				return err
+			}
+			continue
+		}
+		mode := fs.FileMode(header.Mode).Perm()
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, mode|0700); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := os.MkdirAll(filepath.Dir(target), 0777); err != nil {
+				return err
+			}
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0777); err != nil {
+				return err
+			}
+			if info, err := os.Lstat(target); err == nil && info.Mode()&fs.ModeSymlink != 0 {
+				return fmt.Errorf("archive entry %q would write through a symlink", header.Name)
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func withinDir(dir, target string) bool {
+	rel, err := filepath.Rel(dir, target)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
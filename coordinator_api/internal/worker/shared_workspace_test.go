@@ -0,0 +1,159 @@
+package worker
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// newTestSharedWorkspaces creates shared workspaces for one "worker", with
+// its own local directory.
+func newTestSharedWorkspaces(t *testing.T, objectStore objects.ObjectStore, upload bool) *SharedWorkspaces {
+	t.Helper()
+	sw := NewSharedWorkspaces(objectStore, SharedWorkspaceConfig{Upload: upload})
+	sw.baseDir = t.TempDir()
+	return sw
+}
+
+// runSharedWorkspaceJob attaches the job's shared workspace, runs fn in it
+// and releases it.
+func runSharedWorkspaceJob(t *testing.T, sw *SharedWorkspaces, job *models.Job, succeeded bool, fn func(dir string)) {
+	t.Helper()
+	workspaceDir := t.TempDir()
+	attached, err := sw.Attach(context.Background(), job, workspaceDir)
+	require.NoError(t, err)
+	fn(filepath.Join(workspaceDir, "shared"))
+	require.NoError(t, attached.Release(context.Background(), job.JobID, succeeded))
+}
+
+func sharedWorkspaceJob(jobID string) *models.Job {
+	return &models.Job{JobID: jobID, SharedWorkspace: "build", SharedWorkspaceScope: "eval-1"}
+}
+
+func TestSharedWorkspaces_ReusedOnSameWorker(t *testing.T) {
+	sw := newTestSharedWorkspaces(t, nil, false)
+
+	runSharedWorkspaceJob(t, sw, sharedWorkspaceJob("build-1"), true, func(dir string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "app"), []byte("binary"), 0644))
+	})
+	runSharedWorkspaceJob(t, sw, sharedWorkspaceJob("test-1"), true, func(dir string) {
+		data, err := os.ReadFile(filepath.Join(dir, "app"))
+		require.NoError(t, err)
+		assert.Equal(t, "binary", string(data))
+	})
+}
+
+func TestSharedWorkspaces_DownloadedOnOtherWorker(t *testing.T) {
+	objectStore := objects.NewMemoryObjectStore()
+	workerA := newTestSharedWorkspaces(t, objectStore, true)
+	workerB := newTestSharedWorkspaces(t, objectStore, true)
+
+	runSharedWorkspaceJob(t, workerA, sharedWorkspaceJob("build-1"), true, func(dir string) {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "bin"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "bin", "app"), []byte("v1"), 0755))
+	})
+	runSharedWorkspaceJob(t, workerB, sharedWorkspaceJob("test-1"), true, func(dir string) {
+		data, err := os.ReadFile(filepath.Join(dir, "bin", "app"))
+		require.NoError(t, err)
+		assert.Equal(t, "v1", string(data))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "bin", "app"), []byte("v2"), 0755))
+	})
+
+	// Worker A's local copy is stale now; it must use worker B's upload.
+	runSharedWorkspaceJob(t, workerA, sharedWorkspaceJob("deploy-1"), true, func(dir string) {
+		data, err := os.ReadFile(filepath.Join(dir, "bin", "app"))
+		require.NoError(t, err)
+		assert.Equal(t, "v2", string(data))
+	})
+}
+
+func TestSharedWorkspaces_FailedJobDiscarded(t *testing.T) {
+	objectStore := objects.NewMemoryObjectStore()
+	sw := newTestSharedWorkspaces(t, objectStore, true)
+
+	runSharedWorkspaceJob(t, sw, sharedWorkspaceJob("build-1"), true, func(dir string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "app"), []byte("good"), 0644))
+	})
+	runSharedWorkspaceJob(t, sw, sharedWorkspaceJob("test-1"), false, func(dir string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "app"), []byte("bad"), 0644))
+	})
+	runSharedWorkspaceJob(t, sw, sharedWorkspaceJob("test-2"), true, func(dir string) {
+		data, err := os.ReadFile(filepath.Join(dir, "app"))
+		require.NoError(t, err)
+		assert.Equal(t, "good", string(data))
+	})
+}
+
+func TestSharedWorkspaces_ScopedToPipeline(t *testing.T) {
+	sw := newTestSharedWorkspaces(t, nil, false)
+
+	runSharedWorkspaceJob(t, sw, sharedWorkspaceJob("build-1"), true, func(dir string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "app"), []byte("binary"), 0644))
+	})
+	other := sharedWorkspaceJob("build-2")
+	other.SharedWorkspaceScope = "eval-2"
+	runSharedWorkspaceJob(t, sw, other, true, func(dir string) {
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
+
+func TestSharedWorkspaces_RejectsInvalidName(t *testing.T) {
+	sw := newTestSharedWorkspaces(t, nil, false)
+	job := sharedWorkspaceJob("build-1")
+	job.SharedWorkspace = "../escape"
+	_, err := sw.Attach(context.Background(), job, t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestTarGz_RoundTrip(t *testing.T) {
+	src := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "a", "b"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "a", "b", "file"), []byte("content"), 0600))
+	require.NoError(t, os.Symlink("b/file", filepath.Join(src, "a", "link")))
+
+	var buf bytes.Buffer
+	require.NoError(t, writeTarGz(&buf, src))
+
+	dst := filepath.Join(t.TempDir(), "out")
+	require.NoError(t, extractTarGz(&buf, dst))
+
+	data, err := os.ReadFile(filepath.Join(dst, "a", "b", "file"))
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(data))
+	info, err := os.Stat(filepath.Join(dst, "a", "b", "file"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	target, err := os.Readlink(filepath.Join(dst, "a", "link"))
+	require.NoError(t, err)
+	assert.Equal(t, "b/file", target)
+}
+
+func TestExtractTarGz_RejectsPathTraversal(t *testing.T) {
+	for _, name := range []string{"../escape", "a/../../escape"} {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gz)
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: 1, Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte("x"))
+		require.NoError(t, err)
+		require.NoError(t, tw.Close())
+		require.NoError(t, gz.Close())
+
+		dir := t.TempDir()
+		assert.Error(t, extractTarGz(&buf, filepath.Join(dir, "out")), name)
+		_, err = os.Stat(filepath.Join(dir, "escape"))
+		assert.True(t, os.IsNotExist(err), name)
+	}
+}
@@ -0,0 +1,354 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// sourceHostLookupTimeout bounds the DNS lookup behind a source URL check.
+const sourceHostLookupTimeout = 2 * time.Second
+
+// LookupSourceHost resolves a source URL's host. Tests, here and in the
+// handlers, replace it to stay off the network.
+var LookupSourceHost = net.DefaultResolver.LookupNetIP
+
+// SourceURLPolicy decides which source and CI source URLs jobs may use.
+// Workers fetch whatever URL a job names, so without it a job could make a
+// worker read local files or reach internal services.
+type SourceURLPolicy struct {
+	AllowedSchemes []string `json:"allowed_schemes,omitempty"`
+	// AllowedHosts, when set, is the only hosts URLs may name. Hosts listed
+	// here may resolve to private addresses.
+	AllowedHosts         []string `json:"allowed_hosts,omitempty"`
+	DeniedHosts          []string `json:"denied_hosts,omitempty"`
+	AllowPrivateNetworks bool     `json:"allow_private_networks"`
+}
+
+// SourceURLError reports a URL the policy rejects. It matches
+// store.ErrForbidden.
+type SourceURLError struct {
+	URL    string
+	Reason string
+}
+
+func (e *SourceURLError) Error() string {
+	return fmt.Sprintf("source URL %q is not allowed: %s", e.URL, e.Reason)
+}
+
+func (e *SourceURLError) Unwrap() error {
+	return store.ErrForbidden
+}
+
+// sourceURLPolicySettings is the "source_url_policy" global setting. Fields
+// it sets replace the configured policy's.
+type sourceURLPolicySettings struct {
+	AllowedSchemes       []string `json:"allowed_schemes"`
+	AllowedHosts         []string `json:"allowed_hosts"`
+	DeniedHosts          []string `json:"denied_hosts"`
+	AllowPrivateNetworks *bool    `json:"allow_private_networks"`
+}
+
+// settingsStore is the narrow store capability behind the admin-managed
+// policy (see postgres_store/settings_operations.go).
+type settingsStore interface {
+	GetGlobalSetting(ctx context.Context, key string) (*models.GlobalSetting, error)
+}
+
+// ConfiguredSourceURLPolicy returns the policy from the environment.
+func ConfiguredSourceURLPolicy() SourceURLPolicy {
+	return SourceURLPolicy{
+		AllowedSchemes:       splitList(config.SourceURLAllowedSchemes),
+		AllowedHosts:         splitList(config.SourceURLAllowedHosts),
+		DeniedHosts:          splitList(config.SourceURLDeniedHosts),
+		AllowPrivateNetworks: config.SourceURLAllowPrivateNetworks,
+	}
+}
+
+// LoadSourceURLPolicy returns the configured policy with the
+// "source_url_policy" global setting applied. A missing or unreadable
+// setting leaves the configured policy.
+func LoadSourceURLPolicy(ctx context.Context, st store.Store) SourceURLPolicy {
+	policy := ConfiguredSourceURLPolicy()
+	ss, ok := st.(settingsStore)
+	if !ok {
+		return policy
+	}
+	setting, err := ss.GetGlobalSetting(ctx, models.GlobalSettingSourceURLPolicy)
+	if err != nil || setting == nil {
+		return policy
+	}
+	var overrides sourceURLPolicySettings
+	if err := json.Unmarshal(setting.Value, &overrides); err != nil {
+		logging.Log.WithError(err).Warn("Ignoring malformed source_url_policy setting")
+		return policy
+	}
+	if overrides.AllowedSchemes != nil {
+		policy.AllowedSchemes = overrides.AllowedSchemes
+	}
+	if overrides.AllowedHosts != nil {
+		policy.AllowedHosts = overrides.AllowedHosts
+	}
+	if overrides.DeniedHosts != nil {
+		policy.DeniedHosts = overrides.DeniedHosts
+	}
+	if overrides.AllowPrivateNetworks != nil {
+		policy.AllowPrivateNetworks = *overrides.AllowPrivateNetworks
+	}
+	return policy
+}
+
+// CheckSourceURLs checks each non-empty URL against the current policy.
+func CheckSourceURLs(ctx context.Context, st store.Store, rawURLs ...string) error {
+	return LoadSourceURLPolicy(ctx, st).CheckAll(ctx, rawURLs...)
+}
+
+// CheckAll checks each non-empty URL, returning the first rejection.
+func (p SourceURLPolicy) CheckAll(ctx context.Context, rawURLs ...string) error {
+	for _, rawURL := range rawURLs {
+		if rawURL == "" {
+			continue
+		}
+		if err := p.Check(ctx, rawURL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Check returns a *SourceURLError if the policy rejects rawURL. Hostnames
+// are resolved and every address checked; a name that doesn't resolve is
+// rejected, since the worker's resolver may well answer it with an
+// internal address.
+func (p SourceURLPolicy) Check(ctx context.Context, rawURL string) error {
+	reject := func(format string, args ...interface{}) error {
+		return &SourceURLError{URL: rawURL, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	scheme, host, err := splitSourceURL(rawURL)
+	if err != nil {
+		return reject("%v", err)
+	}
+	if !containsFold(p.AllowedSchemes, scheme) {
+		return reject("scheme %q is not allowed", scheme)
+	}
+	if host == "" {
+		return reject("no host")
+	}
+	if matchHostList(p.DeniedHosts, host) {
+		return reject("host %s is denied", host)
+	}
+	if len(p.AllowedHosts) > 0 {
+		if !matchHostList(p.AllowedHosts, host) {
+			return reject("host %s is not in the allowed hosts", host)
+		}
+		return nil
+	}
+	if p.AllowPrivateNetworks {
+		return nil
+	}
+
+	if addr, ok := parseHostAddr(host); ok {
+		if addr = addr.Unmap(); internalAddr(addr) {
+			return reject("address %s is internal", addr)
+		}
+		return nil
+	}
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return reject("host %s is internal", host)
+	}
+	lookupCtx, cancel := context.WithTimeout(ctx, sourceHostLookupTimeout)
+	defer cancel()
+	addrs, err := LookupSourceHost(lookupCtx, "ip", host)
+	if err != nil || len(addrs) == 0 {
+		return reject("host %s does not resolve", host)
+	}
+	for _, addr := range addrs {
+		if internalAddr(addr.Unmap()) {
+			return reject("host %s resolves to internal address %s", host, addr.Unmap())
+		}
+	}
+	return nil
+}
+
+// DialControl returns a net.Dialer Control func that refuses connections to
+// the addresses Check refuses, so a name that resolved to a public address
+// when the job was submitted can't be rebound to an internal one by the
+// time the worker fetches it. It is nil, allowing any address, when the
+// policy allows private networks or lists the allowed hosts.
+func (p SourceURLPolicy) DialControl() func(network, address string, c syscall.RawConn) error {
+	if p.AllowPrivateNetworks || len(p.AllowedHosts) > 0 {
+		return nil
+	}
+	return func(network, address string, c syscall.RawConn) error {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return err
+		}
+		addr, err := netip.ParseAddr(host)
+		if err != nil {
+			return err
+		}
+		addr = addr.Unmap()
+		if internalAddr(addr) || matchHostList(p.DeniedHosts, addr.String()) {
+			return &SourceURLError{URL: address, Reason: fmt.Sprintf("address %s is internal or denied", addr)}
+		}
+		return nil
+	}
+}
+
+// HTTPClient returns an HTTP client for fetching sources whose connections
+// are checked by DialControl.
+func (p SourceURLPolicy) HTTPClient() *http.Client {
+	control := p.DialControl()
+	if control == nil {
+		return http.DefaultClient
+	}
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: control}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Transport: transport}
+}
+
+// parseHostAddr parses host as an IP address, including the IPv4 forms
+// inet_aton accepts besides dotted decimal: fewer than four parts (127.1,
+// 2130706433) and octal or hex parts (0177.0.0.1, 0x7f.0.0.1). Resolvers
+// built on libc take those for addresses rather than names to look up.
+func parseHostAddr(host string) (netip.Addr, bool) {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return addr, true
+	}
+	parts := strings.Split(strings.TrimSuffix(host, "."), ".")
+	if len(parts) > 4 {
+		return netip.Addr{}, false
+	}
+	var value uint64
+	for i, part := range parts {
+		n, ok := parseInetAtonPart(part)
+		if !ok {
+			return netip.Addr{}, false
+		}
+		if i < len(parts)-1 {
+			if n > 0xff {
+				return netip.Addr{}, false
+			}
+			value = value<<8 | n
+			continue
+		}
+		bits := uint(8 * (5 - len(parts)))
+		if n >= 1<<bits {
+			return netip.Addr{}, false
+		}
+		value = value<<bits | n
+	}
+	return netip.AddrFrom4([4]byte{byte(value >> 24), byte(value >> 16), byte(value >> 8), byte(value)}), true
+}
+
+// parseInetAtonPart parses one part of an inet_aton address: hex with a
+// 0x prefix, octal with a leading 0, decimal otherwise.
+func parseInetAtonPart(part string) (uint64, bool) {
+	base := 10
+	switch {
+	case len(part) > 2 && (part[:2] == "0x" || part[:2] == "0X"):
+		part, base = part[2:], 16
+	case len(part) > 1 && part[0] == '0':
+		part, base = part[1:], 8
+	}
+	if part == "" || strings.ContainsAny(part, "+-_") {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(part, base, 32)
+	return n, err == nil
+}
+
+// splitSourceURL returns a URL's lowercased scheme and host, without port.
+// scp-style git URLs (git@host:org/repo) are ssh, and normalized repo URLs
+// (github.com/org/repo) https.
+func splitSourceURL(rawURL string) (scheme, host string, err error) {
+	if !strings.Contains(rawURL, "://") {
+		if at := strings.Index(rawURL, "@"); at >= 0 {
+			if colon := strings.Index(rawURL[at:], ":"); colon > 0 {
+				return "ssh", strings.ToLower(rawURL[at+1 : at+colon]), nil
+			}
+		}
+		if first, _, _ := strings.Cut(rawURL, "/"); strings.Contains(first, ".") && !strings.HasPrefix(first, ".") {
+			rawURL = "https://" + rawURL
+		} else {
+			return "", "", fmt.Errorf("not an absolute URL")
+		}
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid URL")
+	}
+	return strings.ToLower(u.Scheme), strings.ToLower(u.Hostname()), nil
+}
+
+// internalAddr reports whether addr is loopback, private, link-local (which
+// includes cloud metadata services), unspecified or multicast.
+func internalAddr(addr netip.Addr) bool {
+	return addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() || addr.IsUnspecified() ||
+		sharedAddressSpace.Contains(addr)
+}
+
+// sharedAddressSpace is carrier-grade NAT space (RFC 6598), internal in
+// most deployments but not covered by netip.Addr.IsPrivate.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// matchHostList reports whether host matches an entry: exactly, or as a
+// subdomain of a "*.example.com" entry. IP entries may be CIDR prefixes.
+func matchHostList(list []string, host string) bool {
+	addr, isAddr := parseHostAddr(host)
+	for _, entry := range list {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "":
+		case strings.HasPrefix(entry, "*."):
+			if strings.HasSuffix(host, entry[1:]) {
+				return true
+			}
+		case strings.Contains(entry, "/"):
+			if prefix, err := netip.ParsePrefix(entry); err == nil && isAddr && prefix.Contains(addr.Unmap()) {
+				return true
+			}
+		case entry == host:
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(list []string, s string) bool {
+	for _, entry := range list {
+		if strings.EqualFold(strings.TrimSpace(entry), s) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitList splits a comma-separated config value, dropping empty entries.
+func splitList(value string) []string {
+	var out []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
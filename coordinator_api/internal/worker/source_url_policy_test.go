@@ -0,0 +1,150 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+func stubSourceHostLookup(t *testing.T, hosts map[string]string) {
+	old := LookupSourceHost
+	LookupSourceHost = func(ctx context.Context, network, host string) ([]netip.Addr, error) {
+		addr, ok := hosts[host]
+		if !ok {
+			return nil, errors.New("no such host")
+		}
+		return []netip.Addr{netip.MustParseAddr(addr)}, nil
+	}
+	t.Cleanup(func() { LookupSourceHost = old })
+}
+
+func TestSourceURLPolicy_Check(t *testing.T) {
+	stubSourceHostLookup(t, map[string]string{
+		"github.com":        "140.82.112.3",
+		"git.internal.corp": "10.0.4.2",
+	})
+	policy := SourceURLPolicy{AllowedSchemes: []string{"https", "ssh"}, DeniedHosts: []string{"*.blocked.example"}}
+
+	tests := []struct {
+		url     string
+		allowed bool
+	}{
+		{"https://github.com/org/repo.git", true},
+		{"git@github.com:org/repo.git", true},
+		{"github.com/org/repo", true},
+		{"https://unresolvable.example/repo.git", false},
+		{"file:///etc/passwd", false},
+		{"http://github.com/org/repo.git", false},
+		{"/srv/repos/repo", false},
+		{"../repo", false},
+		{"https://169.254.169.254/latest/meta-data", false},
+		{"https://[::ffff:127.0.0.1]/repo", false},
+		{"https://127.1/repo", false},
+		{"https://2130706433/repo", false},
+		{"https://0x7f.0.0.1/repo", false},
+		{"https://017700000001/repo", false},
+		{"https://0177.0.0.1/repo", false},
+		{"https://10.1025/repo", false},
+		{"https://169.254.43518/latest/meta-data", false},
+		{"https://0x8c.0x52.0x70.0x3/org/repo.git", true},
+		{"https://localhost:8080/repo", false},
+		{"https://git.internal.corp/repo.git", false},
+		{"https://mirror.blocked.example/repo.git", false},
+	}
+	for _, tt := range tests {
+		err := policy.Check(context.Background(), tt.url)
+		if tt.allowed && err != nil {
+			t.Errorf("%s: expected allowed, got %v", tt.url, err)
+		}
+		if !tt.allowed && !errors.Is(err, store.ErrForbidden) {
+			t.Errorf("%s: expected rejection, got %v", tt.url, err)
+		}
+	}
+}
+
+func TestSourceURLPolicy_DialControl(t *testing.T) {
+	control := SourceURLPolicy{DeniedHosts: []string{"203.0.113.0/24"}}.DialControl()
+	for address, allowed := range map[string]bool{
+		"140.82.112.3:443":      true,
+		"127.0.0.1:443":         false,
+		"[::ffff:10.0.0.1]:443": false,
+		"169.254.169.254:80":    false,
+		"203.0.113.9:443":       false,
+		"[2606:4700::1111]:443": true,
+	} {
+		err := control("tcp", address, nil)
+		if allowed && err != nil {
+			t.Errorf("%s: expected allowed, got %v", address, err)
+		}
+		if !allowed && !errors.Is(err, store.ErrForbidden) {
+			t.Errorf("%s: expected rejection, got %v", address, err)
+		}
+	}
+
+	if (SourceURLPolicy{AllowPrivateNetworks: true}).DialControl() != nil {
+		t.Error("expected no dial check when private networks are allowed")
+	}
+}
+
+func TestSourceURLPolicy_HTTPClientRefusesInternalAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// A name that passed Check when the job was submitted but resolves to
+	// loopback by fetch time is refused when dialed.
+	_, err := SourceURLPolicy{}.HTTPClient().Get(server.URL)
+	if !errors.Is(err, store.ErrForbidden) {
+		t.Errorf("expected the loopback dial to be refused, got %v", err)
+	}
+	resp, err := SourceURLPolicy{AllowPrivateNetworks: true}.HTTPClient().Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected private networks to be reachable when allowed, got %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestSourceURLPolicy_AllowedHostsMayBeInternal(t *testing.T) {
+	stubSourceHostLookup(t, map[string]string{"git.internal.corp": "10.0.4.2"})
+	policy := SourceURLPolicy{AllowedSchemes: []string{"https"}, AllowedHosts: []string{"git.internal.corp"}}
+
+	if err := policy.Check(context.Background(), "https://git.internal.corp/repo.git"); err != nil {
+		t.Errorf("expected an allowed internal host to pass, got %v", err)
+	}
+	if err := policy.Check(context.Background(), "https://github.com/org/repo.git"); err == nil {
+		t.Error("expected hosts outside the allowed hosts to be rejected")
+	}
+}
+
+// settingsMockStore is MockStore with a global settings lookup.
+type settingsMockStore struct {
+	MockStore
+	settings map[string]models.JSONValue
+}
+
+func (s *settingsMockStore) GetGlobalSetting(ctx context.Context, key string) (*models.GlobalSetting, error) {
+	value, ok := s.settings[key]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &models.GlobalSetting{Key: key, Value: value}, nil
+}
+
+func TestLoadSourceURLPolicy_AppliesSetting(t *testing.T) {
+	st := &settingsMockStore{settings: map[string]models.JSONValue{
+		models.GlobalSettingSourceURLPolicy: models.JSONValue(`{"denied_hosts":["github.com"],"allow_private_networks":true}`),
+	}}
+	policy := LoadSourceURLPolicy(context.Background(), st)
+
+	if len(policy.DeniedHosts) != 1 || !policy.AllowPrivateNetworks {
+		t.Errorf("expected the setting applied, got %+v", policy)
+	}
+	if len(policy.AllowedSchemes) == 0 {
+		t.Error("expected the configured schemes kept when the setting doesn't set them")
+	}
+}
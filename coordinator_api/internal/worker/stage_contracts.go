@@ -0,0 +1,330 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// Stage contract value types. An output or input without a type is a
+// string.
+const (
+	StageValueString  = "string"
+	StageValueNumber  = "number"
+	StageValueBoolean = "boolean"
+	StageValueJSON    = "json"
+)
+
+// stageVarsPrefix starts an input's "from" that reads a workflow variable,
+// set by the triggering job, instead of another job's output.
+const stageVarsPrefix = "vars."
+
+var stageValueNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,63}$`)
+
+// triggerOutputSpec declares a value a job publishes in the "outputs" of
+// its workflow output file.
+type triggerOutputSpec struct {
+	Name string `json:"name" yaml:"name"`
+	Type string `json:"type,omitempty" yaml:"type"`
+}
+
+// triggerInputSpec declares a value a job needs. From is
+// "<job_name>.<output>", an output another job in the workflow declares
+// and that this job depends on, or "vars.<key>", a workflow variable. The
+// value is injected as Env, RC_INPUT_<NAME> by default.
+type triggerInputSpec struct {
+	Name     string `json:"name" yaml:"name"`
+	From     string `json:"from" yaml:"from"`
+	Type     string `json:"type,omitempty" yaml:"type"`
+	Env      string `json:"env,omitempty" yaml:"env"`
+	Optional bool   `json:"optional,omitempty" yaml:"optional"`
+}
+
+// envName returns the environment variable the input is injected as.
+func (in triggerInputSpec) envName() string {
+	if in.Env != "" {
+		return in.Env
+	}
+	return "RC_INPUT_" + strings.ToUpper(in.Name)
+}
+
+// source splits From into the producing job's name and the workflow
+// variable key the value is stored under. The job name is empty for a
+// "vars." input.
+func (in triggerInputSpec) source() (job, key string, ok bool) {
+	if strings.HasPrefix(in.From, stageVarsPrefix) {
+		key = strings.TrimPrefix(in.From, stageVarsPrefix)
+		return "", key, key != ""
+	}
+	i := strings.LastIndex(in.From, ".")
+	if i <= 0 || i == len(in.From)-1 {
+		return "", "", false
+	}
+	// mergeWorkflowOutputFile stores outputs as "<job_name>.<output>".
+	return in.From[:i], in.From, true
+}
+
+func validStageValueType(t string) bool {
+	switch t {
+	case "", StageValueString, StageValueNumber, StageValueBoolean, StageValueJSON:
+		return true
+	}
+	return false
+}
+
+func stageValueType(t string) string {
+	if t == "" {
+		return StageValueString
+	}
+	return t
+}
+
+// checkStageContracts checks the inputs and outputs of the jobs a triggers
+// document adds to a workflow, before any of them is created: names and
+// types must be valid, every input must come from an output a job the
+// consumer depends on declares, with the same type, and every required
+// "vars." input from a workflow variable that's already set. existing are
+// the specs of jobs earlier documents added to the workflow, and vars the
+// workflow variables set so far. index maps specs to their position in the
+// document, for error paths. It fills in each input's type from its
+// producer's output.
+func checkStageContracts(specs []triggerJobSpec, index []int, existing []triggerJobSpec, vars map[string]bool) TriggerValidationErrors {
+	var errs TriggerValidationErrors
+	all := append(append([]triggerJobSpec(nil), existing...), specs...)
+	dependsOn := make(map[string][]string, len(all))
+	outputs := make(map[string]string)
+	for _, spec := range all {
+		dependsOn[spec.JobName] = append(dependsOn[spec.JobName], spec.DependsOn...)
+		for _, out := range spec.Outputs {
+			outputs[spec.JobName+"."+out.Name] = stageValueType(out.Type)
+		}
+	}
+
+	for i := range specs {
+		spec := &specs[i]
+		path := fmt.Sprintf("jobs[%d]", index[i])
+		seen := make(map[string]bool, len(spec.Outputs))
+		for j, out := range spec.Outputs {
+			outPath := fmt.Sprintf("%s.outputs[%d]", path, j)
+			if !stageValueNamePattern.MatchString(out.Name) {
+				errs = append(errs, TriggerValidationError{Path: outPath + ".name", Message: fmt.Sprintf("invalid output name %q", out.Name)})
+			} else if seen[out.Name] {
+				errs = append(errs, TriggerValidationError{Path: outPath + ".name", Message: fmt.Sprintf("duplicate output %q", out.Name)})
+			}
+			seen[out.Name] = true
+			if !validStageValueType(out.Type) {
+				errs = append(errs, TriggerValidationError{Path: outPath + ".type", Message: fmt.Sprintf("unsupported type %q", out.Type)})
+			}
+		}
+
+		seen = make(map[string]bool, len(spec.Inputs))
+		for j := range spec.Inputs {
+			in := &spec.Inputs[j]
+			inPath := fmt.Sprintf("%s.inputs[%d]", path, j)
+			if !stageValueNamePattern.MatchString(in.Name) {
+				errs = append(errs, TriggerValidationError{Path: inPath + ".name", Message: fmt.Sprintf("invalid input name %q", in.Name)})
+			} else if seen[in.Name] {
+				errs = append(errs, TriggerValidationError{Path: inPath + ".name", Message: fmt.Sprintf("duplicate input %q", in.Name)})
+			}
+			seen[in.Name] = true
+			if in.Env != "" && !stageValueNamePattern.MatchString(in.Env) {
+				errs = append(errs, TriggerValidationError{Path: inPath + ".env", Message: fmt.Sprintf("invalid environment variable name %q", in.Env)})
+			}
+			if !validStageValueType(in.Type) {
+				errs = append(errs, TriggerValidationError{Path: inPath + ".type", Message: fmt.Sprintf("unsupported type %q", in.Type)})
+				continue
+			}
+
+			producer, key, ok := in.source()
+			switch {
+			case !ok:
+				errs = append(errs, TriggerValidationError{Path: inPath + ".from", Message: fmt.Sprintf("%q must be \"<job_name>.<output>\" or \"vars.<key>\"", in.From)})
+			case producer == "":
+				if !in.Optional && !vars[key] {
+					errs = append(errs, TriggerValidationError{Path: inPath + ".from", Message: fmt.Sprintf("workflow variable %q is not set", key)})
+				}
+			default:
+				outType, declared := outputs[key]
+				switch {
+				case !declared:
+					errs = append(errs, TriggerValidationError{Path: inPath + ".from", Message: fmt.Sprintf("no job %q declaring output %q", producer, strings.TrimPrefix(key, producer+"."))})
+				case !dependsUpon(dependsOn, spec.JobName, producer):
+					errs = append(errs, TriggerValidationError{Path: inPath + ".from", Message: fmt.Sprintf("job %q must depend on %q to use its output", spec.JobName, producer)})
+				case in.Type != "" && in.Type != outType:
+					errs = append(errs, TriggerValidationError{Path: inPath + ".type", Message: fmt.Sprintf("is %s but %s is %s", in.Type, in.From, outType)})
+				default:
+					in.Type = outType
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// checkTriggerContracts runs checkStageContracts on the jobs a triggers
+// document adds, against the workflow they join: the parent's, if it
+// already has one, plus the document's own workflow vars. Inputs are
+// passed through workflow variables, so without workflow support
+// declaring one is an error.
+func (tp *TriggerProcessor) checkTriggerContracts(ctx context.Context, specs []triggerJobSpec, index []int, wfSpec *triggerWorkflowSpec, parentJob *models.Job) (TriggerValidationErrors, error) {
+	declared := false
+	for _, spec := range specs {
+		if len(spec.Inputs) > 0 || len(spec.Outputs) > 0 {
+			declared = true
+			break
+		}
+	}
+	if !declared {
+		return nil, nil
+	}
+
+	ws, err := tp.workflowStore()
+	if err != nil {
+		var errs TriggerValidationErrors
+		for i, spec := range specs {
+			if len(spec.Inputs) > 0 {
+				errs = append(errs, TriggerValidationError{Path: fmt.Sprintf("jobs[%d].inputs", index[i]), Message: "inputs need workflow support"})
+			}
+		}
+		return errs, nil
+	}
+
+	vars := make(map[string]bool)
+	if wfSpec != nil {
+		for key := range wfSpec.Vars {
+			vars[strings.TrimSpace(key)] = true
+		}
+	}
+	var existing []triggerJobSpec
+	if parentJob.WorkflowID != nil && *parentJob.WorkflowID != "" {
+		nodes, err := ws.ListWorkflowNodes(ctx, *parentJob.WorkflowID)
+		if err != nil {
+			return nil, err
+		}
+		for i := range nodes {
+			if spec, err := workflowNodeSpec(&nodes[i]); err == nil {
+				existing = append(existing, spec)
+			}
+		}
+		current, err := ws.GetWorkflowVars(ctx, *parentJob.WorkflowID)
+		if err != nil {
+			return nil, err
+		}
+		for key := range current {
+			vars[key] = true
+		}
+	}
+	return checkStageContracts(specs, index, existing, vars), nil
+}
+
+// dependsUpon reports whether job depends on producer, directly or through
+// other jobs.
+func dependsUpon(dependsOn map[string][]string, job, producer string) bool {
+	visited := map[string]bool{job: true}
+	queue := []string{job}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, dep := range dependsOn[name] {
+			if dep == producer {
+				return true
+			}
+			if !visited[dep] {
+				visited[dep] = true
+				queue = append(queue, dep)
+			}
+		}
+	}
+	return false
+}
+
+// resolveStageInputs returns the environment variables injecting spec's
+// inputs, read from the workflow's variables. A required input that has no
+// value, or one of the wrong type, is an error.
+func resolveStageInputs(spec triggerJobSpec, vars map[string]models.JSONB) (map[string]string, error) {
+	env := make(map[string]string, len(spec.Inputs))
+	for _, in := range spec.Inputs {
+		_, key, _ := in.source()
+		raw, ok := vars[key]
+		if !ok {
+			if in.Optional {
+				continue
+			}
+			return nil, fmt.Errorf("input %q: %s has no value", in.Name, in.From)
+		}
+		value := unwrapWorkflowJSONB(raw)
+		if err := checkStageValue(in.Type, value); err != nil {
+			return nil, fmt.Errorf("input %q: %s %w", in.Name, in.From, err)
+		}
+		env[in.envName()] = stageValueString(value)
+	}
+	return env, nil
+}
+
+// checkStageOutputs checks that a completed job published every output it
+// declares, with the declared type.
+func checkStageOutputs(spec triggerJobSpec, outputs map[string]interface{}) error {
+	var missing []string
+	for _, out := range spec.Outputs {
+		value, ok := outputs[out.Name]
+		if !ok {
+			missing = append(missing, out.Name)
+			continue
+		}
+		if err := checkStageValue(out.Type, value); err != nil {
+			return fmt.Errorf("output %q %w", out.Name, err)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("declared outputs not produced: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func checkStageValue(t string, value interface{}) error {
+	ok := false
+	switch stageValueType(t) {
+	case StageValueString:
+		_, ok = value.(string)
+	case StageValueNumber:
+		switch value.(type) {
+		case float64, int, int64:
+			ok = true
+		}
+	case StageValueBoolean:
+		_, ok = value.(bool)
+	case StageValueJSON:
+		ok = value != nil
+	}
+	if !ok {
+		return fmt.Errorf("is not a %s", stageValueType(t))
+	}
+	return nil
+}
+
+// stageValueString renders an input value for the environment: scalars as
+// themselves, lists and objects as JSON.
+func stageValueString(value interface{}) string {
+	if s, ok := workflowScalarString(value); ok {
+		return s
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// workflowNodeSpec decodes the trigger spec a workflow node was created
+// from.
+func workflowNodeSpec(node *models.WorkflowNode) (triggerJobSpec, error) {
+	var spec triggerJobSpec
+	specBytes, _ := json.Marshal(node.JobSpec)
+	if err := json.Unmarshal(specBytes, &spec); err != nil {
+		return spec, err
+	}
+	return spec, nil
+}
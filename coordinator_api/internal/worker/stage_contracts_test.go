@@ -0,0 +1,205 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckStageContracts(t *testing.T) {
+	specs := []triggerJobSpec{
+		{JobName: "build", Outputs: []triggerOutputSpec{{Name: "digest"}, {Name: "size", Type: StageValueNumber}}},
+		{JobName: "test", DependsOn: []string{"build"}},
+		{JobName: "deploy", DependsOn: []string{"test"}, Inputs: []triggerInputSpec{
+			{Name: "digest", From: "build.digest"},
+			{Name: "size", From: "build.size", Env: "IMAGE_SIZE"},
+			{Name: "target", From: "vars.target"},
+			{Name: "channel", From: "vars.channel", Optional: true},
+		}},
+	}
+	errs := checkStageContracts(specs, []int{0, 1, 2}, nil, map[string]bool{"target": true})
+	require.Empty(t, errs)
+	assert.Equal(t, StageValueString, specs[2].Inputs[0].Type)
+	assert.Equal(t, StageValueNumber, specs[2].Inputs[1].Type, "an input takes its producer's type")
+	assert.Equal(t, "RC_INPUT_DIGEST", specs[2].Inputs[0].envName())
+	assert.Equal(t, "IMAGE_SIZE", specs[2].Inputs[1].envName())
+
+	// The producer can come from an earlier document in the workflow.
+	existing := []triggerJobSpec{{JobName: "build", Outputs: []triggerOutputSpec{{Name: "digest"}}}}
+	later := []triggerJobSpec{{JobName: "scan", DependsOn: []string{"build"}, Inputs: []triggerInputSpec{{Name: "digest", From: "build.digest"}}}}
+	assert.Empty(t, checkStageContracts(later, []int{0}, existing, nil))
+
+	cases := map[string]struct {
+		spec triggerJobSpec
+		path string
+	}{
+		"unknown producer": {
+			triggerJobSpec{JobName: "deploy", DependsOn: []string{"build"}, Inputs: []triggerInputSpec{{Name: "digest", From: "package.digest"}}},
+			"jobs[1].inputs[0].from",
+		},
+		"undeclared output": {
+			triggerJobSpec{JobName: "deploy", DependsOn: []string{"build"}, Inputs: []triggerInputSpec{{Name: "tag", From: "build.tag"}}},
+			"jobs[1].inputs[0].from",
+		},
+		"no dependency": {
+			triggerJobSpec{JobName: "deploy", Inputs: []triggerInputSpec{{Name: "digest", From: "build.digest"}}},
+			"jobs[1].inputs[0].from",
+		},
+		"type mismatch": {
+			triggerJobSpec{JobName: "deploy", DependsOn: []string{"build"}, Inputs: []triggerInputSpec{{Name: "digest", From: "build.digest", Type: StageValueNumber}}},
+			"jobs[1].inputs[0].type",
+		},
+		"malformed from": {
+			triggerJobSpec{JobName: "deploy", DependsOn: []string{"build"}, Inputs: []triggerInputSpec{{Name: "digest", From: "build"}}},
+			"jobs[1].inputs[0].from",
+		},
+		"unset var": {
+			triggerJobSpec{JobName: "deploy", Inputs: []triggerInputSpec{{Name: "target", From: "vars.target"}}},
+			"jobs[1].inputs[0].from",
+		},
+		"bad input name": {
+			triggerJobSpec{JobName: "deploy", DependsOn: []string{"build"}, Inputs: []triggerInputSpec{{Name: "image-digest", From: "build.digest"}}},
+			"jobs[1].inputs[0].name",
+		},
+		"bad output type": {
+			triggerJobSpec{JobName: "deploy", Outputs: []triggerOutputSpec{{Name: "url", Type: "uri"}}},
+			"jobs[1].outputs[0].type",
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			specs := []triggerJobSpec{{JobName: "build", Outputs: []triggerOutputSpec{{Name: "digest"}}}, tc.spec}
+			errs := checkStageContracts(specs, []int{0, 1}, nil, nil)
+			require.Len(t, errs, 1, errs)
+			assert.Equal(t, tc.path, errs[0].Path)
+		})
+	}
+}
+
+func TestResolveStageInputs(t *testing.T) {
+	spec := triggerJobSpec{Inputs: []triggerInputSpec{
+		{Name: "digest", From: "build.digest", Type: StageValueString},
+		{Name: "replicas", From: "vars.replicas", Type: StageValueNumber},
+		{Name: "regions", From: "plan.regions", Type: StageValueJSON},
+		{Name: "channel", From: "vars.channel", Type: StageValueString, Optional: true},
+	}}
+	vars := map[string]models.JSONB{
+		"build.digest": {"value": "sha256:abc"},
+		"replicas":     {"value": float64(3)},
+		"plan.regions": {"value": []interface{}{"us", "eu"}},
+	}
+	env, err := resolveStageInputs(spec, vars)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"RC_INPUT_DIGEST":   "sha256:abc",
+		"RC_INPUT_REPLICAS": "3",
+		"RC_INPUT_REGIONS":  `["us","eu"]`,
+	}, env)
+
+	delete(vars, "build.digest")
+	_, err = resolveStageInputs(spec, vars)
+	assert.EqualError(t, err, `input "digest": build.digest has no value`)
+
+	vars["build.digest"] = models.JSONB{"value": float64(1)}
+	_, err = resolveStageInputs(spec, vars)
+	assert.EqualError(t, err, `input "digest": build.digest is not a string`)
+}
+
+func TestCheckStageOutputs(t *testing.T) {
+	spec := triggerJobSpec{Outputs: []triggerOutputSpec{{Name: "digest"}, {Name: "ok", Type: StageValueBoolean}}}
+	assert.NoError(t, checkStageOutputs(spec, map[string]interface{}{"digest": "sha256:abc", "ok": true, "extra": 1}))
+	assert.EqualError(t, checkStageOutputs(spec, map[string]interface{}{"ok": true}), "declared outputs not produced: digest")
+	assert.EqualError(t, checkStageOutputs(spec, map[string]interface{}{"digest": "sha256:abc", "ok": "yes"}), `output "ok" is not a boolean`)
+	assert.NoError(t, checkStageOutputs(triggerJobSpec{}, nil))
+}
+
+// contractWorkflowStore returns the jobs it creates by ID.
+func contractWorkflowStore() *workflowRuntimeStore {
+	s := newWorkflowRuntimeStore()
+	jobs := map[string]*models.Job{"parent-1": {JobID: "parent-1", UserID: "user-1", QueueName: "reactorcide-jobs"}}
+	s.CreateJobFunc = func(ctx context.Context, job *models.Job) error {
+		job.JobID = fmt.Sprintf("job-%d", len(jobs))
+		jobs[job.JobID] = job
+		return nil
+	}
+	s.GetJobByIDFunc = func(ctx context.Context, jobID string) (*models.Job, error) {
+		return jobs[jobID], nil
+	}
+	return s
+}
+
+func TestProcessTriggersFromData_StageContracts(t *testing.T) {
+	t.Run("miswired pipeline is rejected before any job", func(t *testing.T) {
+		s := contractWorkflowStore()
+		tp := NewTriggerProcessor(s, nil)
+		parent, _ := s.GetJobByID(context.Background(), "parent-1")
+		data := []byte(`{"type":"trigger_job","jobs":[
+			{"job_name":"build","job_command":"make","outputs":[{"name":"digest"}]},
+			{"job_name":"deploy","job_command":"deploy","inputs":[{"name":"digest","from":"build.digest"}]}
+		]}`)
+
+		_, err := tp.ProcessTriggersFromData(context.Background(), data, "", parent)
+		var verrs TriggerValidationErrors
+		require.True(t, errors.As(err, &verrs), err)
+		assert.Equal(t, "jobs[1].inputs[0].from", verrs[0].Path)
+		assert.Empty(t, s.CreateJobCalls)
+		assert.Empty(t, s.workflows)
+		assert.NotNil(t, parent.TriggerValidation)
+	})
+
+	t.Run("outputs are injected into the consumer", func(t *testing.T) {
+		s := contractWorkflowStore()
+		tp := NewTriggerProcessor(s, nil)
+		parent, _ := s.GetJobByID(context.Background(), "parent-1")
+		data := []byte(`{"type":"trigger_job","workflow":{"vars":{"target":"prod"}},"jobs":[
+			{"job_name":"build","job_command":"make","outputs":[{"name":"digest"}]},
+			{"job_name":"deploy","job_command":"deploy","depends_on":["build"],"inputs":[
+				{"name":"digest","from":"build.digest"},{"name":"target","from":"vars.target","env":"TARGET"}]}
+		]}`)
+
+		jobIDs, err := tp.ProcessTriggersFromData(context.Background(), data, "", parent)
+		require.NoError(t, err)
+		require.Len(t, jobIDs, 1)
+		build, _ := s.GetJobByID(context.Background(), jobIDs[0])
+		build.Status = "completed"
+
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "workflow-output.json"), []byte(`{"outputs":{"digest":"sha256:abc"}}`), 0644))
+		require.NoError(t, tp.ProcessWorkflowCompletion(context.Background(), dir, build))
+
+		require.Len(t, s.CreateJobCalls, 2)
+		deploy := s.CreateJobCalls[1]
+		assert.Equal(t, "sha256:abc", deploy.JobEnvVars["RC_INPUT_DIGEST"])
+		assert.Equal(t, "prod", deploy.JobEnvVars["TARGET"])
+	})
+
+	t.Run("missing declared output fails the producer", func(t *testing.T) {
+		s := contractWorkflowStore()
+		tp := NewTriggerProcessor(s, nil)
+		parent, _ := s.GetJobByID(context.Background(), "parent-1")
+		data := []byte(`{"type":"trigger_job","jobs":[
+			{"job_name":"build","job_command":"make","outputs":[{"name":"digest"}]},
+			{"job_name":"deploy","job_command":"deploy","depends_on":["build"],"inputs":[{"name":"digest","from":"build.digest"}]}
+		]}`)
+
+		jobIDs, err := tp.ProcessTriggersFromData(context.Background(), data, "", parent)
+		require.NoError(t, err)
+		build, _ := s.GetJobByID(context.Background(), jobIDs[0])
+		build.Status = "completed"
+
+		err = tp.ProcessWorkflowCompletion(context.Background(), t.TempDir(), build)
+		require.Error(t, err)
+		assert.Len(t, s.CreateJobCalls, 1, "deploy never runs")
+		for _, node := range s.nodes {
+			assert.Contains(t, []string{"failed", "waiting", "pending"}, node.Status, node.Name)
+		}
+		assert.Equal(t, "failed", s.workflows[*parent.WorkflowID].Status)
+	})
+}
@@ -0,0 +1,107 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/config"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// maxTestResults caps the test results kept of one job.
+const maxTestResults = 50000
+
+// testResultStore is the store support for keeping test results and
+// reading test quarantines.
+type testResultStore interface {
+	ReplaceTestResults(ctx context.Context, jobID, projectID string, results []models.TestResult, prunedBefore time.Time) error
+	ListTestQuarantines(ctx context.Context, projectID string) ([]models.TestQuarantine, error)
+}
+
+// testReport is what a job's test reports said.
+type testReport struct {
+	// Failed are the tests that failed, and Quarantined those of them
+	// quarantined in the job's project.
+	Failed      []string
+	Quarantined []string
+}
+
+// onlyQuarantinedFailed reports whether tests failed, but only quarantined
+// ones: a failed command is then forgiven.
+func (r testReport) onlyQuarantinedFailed() bool {
+	return len(r.Failed) > 0 && len(r.Quarantined) == len(r.Failed)
+}
+
+// gateResult records the quarantined tests that failed with the job's gate
+// results, as passed, so they're seen on the job and its PR comment.
+func (r testReport) gateResult() *models.GateResult {
+	if len(r.Quarantined) == 0 {
+		return nil
+	}
+	return &models.GateResult{
+		Name:    "quarantined tests",
+		Passed:  true,
+		Message: fmt.Sprintf("%d failed: %s", len(r.Quarantined), strings.Join(r.Quarantined, ", ")),
+	}
+}
+
+// checkTestReports reads the JUnit reports job wrote to models.TestReportDir,
+// stores their results for its project's pass rates, and finds which of
+// the failed tests are quarantined. It does nothing for jobs without a
+// project or reports. Reports that can't be parsed are skipped.
+func checkTestReports(ctx context.Context, s store.Store, job *models.Job, workspaceDir string, now time.Time) (testReport, error) {
+	ts, ok := s.(testResultStore)
+	if !ok || job.ProjectID == nil || *job.ProjectID == "" {
+		return testReport{}, nil
+	}
+	paths, err := filepath.Glob(filepath.Join(workspaceDir, models.TestReportDir, "*.xml"))
+	if err != nil || len(paths) == 0 {
+		return testReport{}, nil
+	}
+	var results []models.TestResult
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		parsed, err := models.ParseJUnitReport(data)
+		if err != nil {
+			continue
+		}
+		results = append(results, parsed...)
+	}
+	if len(results) > maxTestResults {
+		results = results[:maxTestResults]
+	}
+
+	retention := time.Duration(config.TestResultRetentionDays) * 24 * time.Hour
+	if err := ts.ReplaceTestResults(ctx, job.JobID, *job.ProjectID, results, now.Add(-retention)); err != nil {
+		return testReport{}, fmt.Errorf("store test results: %w", err)
+	}
+
+	report := testReport{Failed: models.FailedTests(results)}
+	if len(report.Failed) == 0 {
+		return report, nil
+	}
+	quarantines, err := ts.ListTestQuarantines(ctx, *job.ProjectID)
+	if err != nil {
+		return report, fmt.Errorf("list test quarantines: %w", err)
+	}
+	active := make(map[string]bool, len(quarantines))
+	for _, q := range quarantines {
+		if q.ActiveAt(now) {
+			active[q.TestName] = true
+		}
+	}
+	for _, name := range report.Failed {
+		if active[name] {
+			report.Quarantined = append(report.Quarantined, name)
+		}
+	}
+	return report, nil
+}
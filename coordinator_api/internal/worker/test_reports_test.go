@@ -0,0 +1,99 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// quarantineStore is MockStore keeping the test results it's given, with
+// fixed quarantines.
+type quarantineStore struct {
+	MockStore
+	quarantines  []models.TestQuarantine
+	results      map[string][]models.TestResult
+	prunedBefore time.Time
+}
+
+func (s *quarantineStore) ReplaceTestResults(ctx context.Context, jobID, projectID string, results []models.TestResult, prunedBefore time.Time) error {
+	if s.results == nil {
+		s.results = make(map[string][]models.TestResult)
+	}
+	s.results[jobID] = results
+	s.prunedBefore = prunedBefore
+	return nil
+}
+
+func (s *quarantineStore) ListTestQuarantines(ctx context.Context, projectID string) ([]models.TestQuarantine, error) {
+	return s.quarantines, nil
+}
+
+func writeTestReport(t *testing.T, dir, name, report string) {
+	t.Helper()
+	reports := filepath.Join(dir, models.TestReportDir)
+	if err := os.MkdirAll(reports, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(reports, name), []byte(report), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckTestReports(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	s := &quarantineStore{quarantines: []models.TestQuarantine{
+		{TestName: "pkg.TestFlaky", ExpiresAt: now.Add(time.Hour)},
+		{TestName: "pkg.TestExpired", ExpiresAt: now.Add(-time.Hour)},
+	}}
+	projectID := "project-1"
+	job := &models.Job{JobID: "job-1", ProjectID: &projectID}
+	dir := t.TempDir()
+
+	// No reports: nothing to record.
+	report, err := checkTestReports(context.Background(), s, job, dir, now)
+	if err != nil || report.onlyQuarantinedFailed() || s.results != nil {
+		t.Fatalf("without reports: report = %+v, err = %v, results = %v", report, err, s.results)
+	}
+
+	writeTestReport(t, dir, "unit.xml", `<testsuite name="pkg">
+  <testcase classname="pkg" name="TestOK"/>
+  <testcase classname="pkg" name="TestFlaky"><failure message="timeout"/></testcase>
+</testsuite>`)
+	writeTestReport(t, dir, "broken.xml", `<testsuite`)
+	report, err = checkTestReports(context.Background(), s, job, dir, now)
+	if err != nil {
+		t.Fatalf("checkTestReports() error = %v", err)
+	}
+	if len(s.results["job-1"]) != 2 {
+		t.Errorf("stored results = %+v, want the two parsed tests", s.results["job-1"])
+	}
+	if want := now.AddDate(0, 0, -14); !s.prunedBefore.Equal(want) {
+		t.Errorf("pruned before %v, want %v", s.prunedBefore, want)
+	}
+	if !report.onlyQuarantinedFailed() {
+		t.Errorf("report = %+v, want only quarantined tests failed", report)
+	}
+	if gate := report.gateResult(); gate == nil || !gate.Passed || gate.Message != "1 failed: pkg.TestFlaky" {
+		t.Errorf("gateResult() = %+v", gate)
+	}
+
+	// An expired quarantine doesn't forgive the test.
+	writeTestReport(t, dir, "more.xml", `<testsuites><testsuite><testcase classname="pkg" name="TestExpired"><error/></testcase></testsuite></testsuites>`)
+	report, _ = checkTestReports(context.Background(), s, job, dir, now)
+	if report.onlyQuarantinedFailed() {
+		t.Errorf("report = %+v, want a failure that isn't quarantined", report)
+	}
+	if !reflect.DeepEqual(report.Failed, []string{"pkg.TestExpired", "pkg.TestFlaky"}) {
+		t.Errorf("failed = %v", report.Failed)
+	}
+
+	// Jobs outside a project have no quarantine.
+	if report, _ := checkTestReports(context.Background(), s, &models.Job{JobID: "job-2"}, dir, now); report.Failed != nil {
+		t.Errorf("report for a job without a project = %+v", report)
+	}
+}
@@ -3,16 +3,21 @@ package worker
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"time"
 
 	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/admission"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/hooks"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
+	"github.com/lib/pq"
 	"gopkg.in/yaml.v3"
 )
 
@@ -41,7 +46,10 @@ func (tp *TriggerProcessor) SetStatusUpdater(u vcs.JobStatusUpdaterInterface) {
 
 // triggersFile represents the top-level structure of triggers.json.
 type triggersFile struct {
-	Type     string               `json:"type"`
+	Type string `json:"type"`
+	// Version is the trigger schema version (see trigger_schema.go). Zero
+	// means the field was omitted and is treated as TriggerSchemaV1.
+	Version  int                  `json:"version,omitempty"`
 	Workflow *triggerWorkflowSpec `json:"workflow,omitempty"`
 	Jobs     []triggerJobSpec     `json:"jobs"`
 }
@@ -53,8 +61,12 @@ type triggerWorkflowSpec struct {
 
 // triggerJobSpec represents a single triggered job from triggers.json.
 type triggerJobSpec struct {
-	JobFile        string            `json:"job_file"` // Path to YAML job definition, relative to source root
-	JobName        string            `json:"job_name"`
+	JobFile string `json:"job_file"` // Path to YAML job definition, relative to source root
+	JobName string `json:"job_name"`
+	// StatusKey names the job in its project's status context template
+	// in place of JobName, so the job can be renamed without changing
+	// the commit status branch protection rules require.
+	StatusKey      string            `json:"status_key"`
 	DependsOn      []string          `json:"depends_on"`
 	Condition      string            `json:"condition"`
 	Env            map[string]string `json:"env"`
@@ -72,9 +84,34 @@ type triggerJobSpec struct {
 	RunAsUser      string            `json:"run_as_user"`
 	Priority       *int              `json:"priority"`
 	Timeout        *int              `json:"timeout"`
-	Capabilities   []string          `json:"capabilities"`
-	ForEach        []interface{}     `json:"for_each"`
-	ItemVar        string            `json:"item_var"`
+	// Deadline (RFC 3339) is when the job must have completed; unset, the
+	// job inherits its parent's.
+	Deadline     *time.Time `json:"deadline"`
+	Capabilities []string   `json:"capabilities"`
+	TargetOS     string     `json:"target_os"`
+	TargetArch   string     `json:"target_arch"`
+	// Affinity and AvoidWorkers are scheduling hints (see
+	// ValidateAffinity).
+	Affinity     string   `json:"affinity"`
+	AvoidWorkers []string `json:"avoid_workers"`
+	// Environment makes the job a deploy to that environment (see
+	// models.FreezeWindow).
+	Environment string `json:"environment"`
+	// Scan makes the job a built-in vulnerability scan (see
+	// ApplyScanSpec); it then needs no job_command.
+	Scan *models.ScanSpec `json:"scan"`
+	// SharedWorkspace names a workspace shared with the other jobs of
+	// this pipeline that name it (see SharedWorkspaces).
+	SharedWorkspace string `json:"shared_workspace"`
+	// Cache names a cache kept between runs, shared with the other jobs
+	// of the project that name it (see JobCaches).
+	Cache   string        `json:"cache"`
+	ForEach []interface{} `json:"for_each"`
+	ItemVar string        `json:"item_var"`
+	// Inputs and Outputs are the job's contract with the other jobs of
+	// its workflow (see checkStageContracts).
+	Inputs  []triggerInputSpec  `json:"inputs"`
+	Outputs []triggerOutputSpec `json:"outputs"`
 }
 
 // jobDefinitionFile represents a YAML job definition file (e.g., .reactorcide/jobs/*.yaml).
@@ -97,6 +134,22 @@ type jobDefinitionJobConfig struct {
 	Priority     *int       `yaml:"priority"`
 	RawCommand   bool       `yaml:"raw_command"`
 	Capabilities []string   `yaml:"capabilities"`
+	TargetOS     string     `yaml:"target_os"`
+	TargetArch   string     `yaml:"target_arch"`
+	Affinity     string     `yaml:"affinity"`
+	AvoidWorkers []string   `yaml:"avoid_workers"`
+	Environment  string     `yaml:"environment"`
+	// StatusKey: see triggerJobSpec.StatusKey.
+	StatusKey string `yaml:"status_key"`
+	// Scan: see triggerJobSpec.Scan.
+	Scan *models.ScanSpec `yaml:"scan"`
+	// SharedWorkspace: see triggerJobSpec.SharedWorkspace.
+	SharedWorkspace string `yaml:"shared_workspace"`
+	// Cache: see triggerJobSpec.Cache.
+	Cache string `yaml:"cache"`
+	// Inputs and Outputs: see triggerJobSpec.Inputs.
+	Inputs  []triggerInputSpec  `yaml:"inputs"`
+	Outputs []triggerOutputSpec `yaml:"outputs"`
 }
 
 func runAsUserFromSpec(spec *RunAsSpec) string {
@@ -111,7 +164,7 @@ func runAsUserFromSpec(spec *RunAsSpec) string {
 func (tp *TriggerProcessor) ProcessTriggers(ctx context.Context, workspaceDir string, parentJob *models.Job) error {
 	triggersPath := filepath.Join(workspaceDir, "triggers.json")
 
-	data, err := os.ReadFile(triggersPath)
+	file, err := os.Open(triggersPath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			// No triggers file means no jobs to create - this is normal
@@ -120,6 +173,13 @@ func (tp *TriggerProcessor) ProcessTriggers(ctx context.Context, workspaceDir st
 		}
 		return fmt.Errorf("failed to read triggers file: %w", err)
 	}
+	defer file.Close()
+	// Read one byte past the limit so an oversized file is rejected by
+	// validation without loading all of it.
+	data, err := io.ReadAll(io.LimitReader(file, MaxTriggersBytes+1))
+	if err != nil {
+		return fmt.Errorf("failed to read triggers file: %w", err)
+	}
 
 	_, err = tp.ProcessTriggersFromData(ctx, data, workspaceDir, parentJob)
 	return err
@@ -129,13 +189,19 @@ func (tp *TriggerProcessor) ProcessTriggers(ctx context.Context, workspaceDir st
 // in the database, submits them to Corndogs, and returns the created job IDs.
 // workspaceDir is the host workspace directory used to resolve job_file references.
 func (tp *TriggerProcessor) ProcessTriggersFromData(ctx context.Context, data []byte, workspaceDir string, parentJob *models.Job) ([]string, error) {
-	var tf triggersFile
-	if err := json.Unmarshal(data, &tf); err != nil {
-		return nil, fmt.Errorf("failed to parse triggers data: %w", err)
+	strict := tp.strictTriggerValidation(ctx, parentJob)
+	tf, version, err := parseTriggers(data, strict)
+	if err != nil {
+		var verrs TriggerValidationErrors
+		if errors.As(err, &verrs) {
+			tp.recordTriggerValidation(ctx, parentJob, TriggerValidationRecord(version, strict, verrs))
+		}
+		return nil, err
 	}
-
-	if tf.Type != "trigger_job" {
-		return nil, fmt.Errorf("unexpected trigger type: %q", tf.Type)
+	if parentJob.TriggerValidation != nil {
+		// A previous submission for this parent was rejected; clear the
+		// stale errors now that a valid document has been accepted.
+		tp.recordTriggerValidation(ctx, parentJob, nil)
 	}
 
 	if len(tf.Jobs) == 0 {
@@ -146,8 +212,10 @@ func (tp *TriggerProcessor) ProcessTriggersFromData(ctx context.Context, data []
 	logger := logging.Log.WithField("parent_job_id", parentJob.JobID).WithField("trigger_count", len(tf.Jobs))
 	logger.Info("Processing triggers from eval job")
 
+	urlPolicy := LoadSourceURLPolicy(ctx, tp.store)
 	specs := make([]triggerJobSpec, 0, len(tf.Jobs))
-	for _, spec := range tf.Jobs {
+	index := make([]int, 0, len(tf.Jobs))
+	for i, spec := range tf.Jobs {
 		// If job_file is specified, load the YAML definition as base and overlay inline fields
 		if spec.JobFile != "" {
 			jobFile := spec.JobFile
@@ -162,7 +230,30 @@ func (tp *TriggerProcessor) ProcessTriggersFromData(ctx context.Context, data []
 			spec = tp.overlaySpec(baseSpec, spec)
 			spec.JobFile = jobFile
 		}
+		if err := urlPolicy.CheckAll(ctx, SourcePolicyURL(spec.SourceType, spec.SourceURL), spec.CISourceURL); err != nil {
+			logger.WithError(err).WithField("job_name", spec.JobName).Error("Rejected triggered job")
+			continue
+		}
+		if models.SourceType(spec.SourceType) == models.SourceTypeUpload && !hasUploadedSource(parentJob) {
+			logger.WithField("job_name", spec.JobName).Error("Rejected triggered job: an upload source needs a parent that ran from an upload")
+			continue
+		}
 		specs = append(specs, spec)
+		index = append(index, i)
+	}
+
+	errs, err := tp.checkTriggerContracts(ctx, specs, index, tf.Workflow, parentJob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check job contracts: %w", err)
+	}
+	policyErrs, err := tp.checkTriggerPolicies(ctx, specs, index, parentJob)
+	if err != nil {
+		return nil, err
+	}
+	errs = append(errs, policyErrs...)
+	if len(errs) > 0 {
+		tp.recordTriggerValidation(ctx, parentJob, TriggerValidationRecord(version, strict, errs))
+		return nil, errs
 	}
 
 	if _, err := tp.workflowStore(); err != nil {
@@ -194,6 +285,43 @@ func (tp *TriggerProcessor) ProcessTriggersFromData(ctx context.Context, data []
 	return tp.evaluateWorkflow(ctx, wf)
 }
 
+// strictTriggerValidation reports whether the parent job's project has opted
+// into strict trigger validation. Jobs without a project (or whose project
+// can't be loaded) get the lenient default.
+func (tp *TriggerProcessor) strictTriggerValidation(ctx context.Context, parentJob *models.Job) bool {
+	if parentJob.ProjectID == nil || *parentJob.ProjectID == "" {
+		return false
+	}
+	project, err := tp.store.GetProjectByID(ctx, *parentJob.ProjectID)
+	if err != nil || project == nil {
+		return false
+	}
+	return project.StrictTriggerValidation
+}
+
+// triggerValidationStore is the narrow store capability for recording a
+// job's trigger validation result without rewriting the rest of its row.
+type triggerValidationStore interface {
+	SetJobTriggerValidation(ctx context.Context, jobID string, record models.JSONB) error
+}
+
+// recordTriggerValidation stores (or clears, when record is nil) the
+// structured trigger validation result on the parent job so it can be
+// surfaced through the job API. Only that column is written: the parent
+// is usually still being finalized by its worker, and a full-row save
+// could overwrite its status. Failures are logged, not returned: the
+// validation error itself is what the caller needs to see.
+func (tp *TriggerProcessor) recordTriggerValidation(ctx context.Context, parentJob *models.Job, record models.JSONB) {
+	parentJob.TriggerValidation = record
+	vs, ok := tp.store.(triggerValidationStore)
+	if !ok {
+		return
+	}
+	if err := vs.SetJobTriggerValidation(ctx, parentJob.JobID, record); err != nil {
+		logging.Log.WithError(err).WithField("parent_job_id", parentJob.JobID).Warn("Failed to record trigger validation result on parent job")
+	}
+}
+
 // loadJobFile reads a YAML job definition file from the workspace and converts it to a triggerJobSpec.
 func (tp *TriggerProcessor) loadJobFile(workspaceDir, jobFile string) (triggerJobSpec, error) {
 	filePath := filepath.Join(workspaceDir, "src", jobFile)
@@ -219,7 +347,19 @@ func (tp *TriggerProcessor) loadJobFile(workspaceDir, jobFile string) (triggerJo
 		Timeout:        def.Job.Timeout,
 		Priority:       def.Job.Priority,
 		Capabilities:   def.Job.Capabilities,
+		TargetOS:       def.Job.TargetOS,
+		TargetArch:     def.Job.TargetArch,
+		Affinity:       def.Job.Affinity,
+		AvoidWorkers:   def.Job.AvoidWorkers,
+		Environment:    def.Job.Environment,
+		StatusKey:      def.Job.StatusKey,
+		Scan:           def.Job.Scan,
 		Env:            def.Environment,
+
+		SharedWorkspace: def.Job.SharedWorkspace,
+		Cache:           def.Job.Cache,
+		Inputs:          def.Job.Inputs,
+		Outputs:         def.Job.Outputs,
 	}
 
 	return spec, nil
@@ -275,11 +415,41 @@ func (tp *TriggerProcessor) overlaySpec(base, overlay triggerJobSpec) triggerJob
 	if overlay.RunAsUser != "" {
 		result.RunAsUser = overlay.RunAsUser
 	}
+	if overlay.TargetOS != "" {
+		result.TargetOS = overlay.TargetOS
+	}
+	if overlay.TargetArch != "" {
+		result.TargetArch = overlay.TargetArch
+	}
+	if overlay.Affinity != "" {
+		result.Affinity = overlay.Affinity
+	}
+	if len(overlay.AvoidWorkers) > 0 {
+		result.AvoidWorkers = overlay.AvoidWorkers
+	}
+	if overlay.Environment != "" {
+		result.Environment = overlay.Environment
+	}
+	if overlay.StatusKey != "" {
+		result.StatusKey = overlay.StatusKey
+	}
+	if overlay.Scan != nil {
+		result.Scan = overlay.Scan
+	}
+	if overlay.SharedWorkspace != "" {
+		result.SharedWorkspace = overlay.SharedWorkspace
+	}
+	if overlay.Cache != "" {
+		result.Cache = overlay.Cache
+	}
 
 	// Overlay pointer fields if non-nil
 	if overlay.Priority != nil {
 		result.Priority = overlay.Priority
 	}
+	if overlay.Deadline != nil {
+		result.Deadline = overlay.Deadline
+	}
 	if overlay.Timeout != nil {
 		result.Timeout = overlay.Timeout
 	}
@@ -297,6 +467,12 @@ func (tp *TriggerProcessor) overlaySpec(base, overlay triggerJobSpec) triggerJob
 	if overlay.ItemVar != "" {
 		result.ItemVar = overlay.ItemVar
 	}
+	if len(overlay.Inputs) > 0 {
+		result.Inputs = overlay.Inputs
+	}
+	if len(overlay.Outputs) > 0 {
+		result.Outputs = overlay.Outputs
+	}
 
 	// Merge env vars: base first, then overlay on top
 	if len(overlay.Env) > 0 {
@@ -314,10 +490,78 @@ func (tp *TriggerProcessor) overlaySpec(base, overlay triggerJobSpec) triggerJob
 // createAndSubmitJob creates a single job from a trigger spec and submits it to Corndogs.
 // Returns the created job ID on success.
 func (tp *TriggerProcessor) createAndSubmitJob(ctx context.Context, spec triggerJobSpec, parentJob *models.Job) (string, error) {
+	if _, err := NormalizePlatform(spec.TargetOS, spec.TargetArch); err != nil {
+		return "", err
+	}
+	if err := ValidateAffinity(spec.Affinity, spec.AvoidWorkers); err != nil {
+		return "", err
+	}
+	if err := ValidateSecretURIs(spec.Env); err != nil {
+		return "", err
+	}
+	if spec.Environment != "" {
+		if err := models.ValidateEnvironmentName(spec.Environment); err != nil {
+			return "", err
+		}
+	}
+	if spec.Scan != nil {
+		if err := spec.Scan.Validate(); err != nil {
+			return "", err
+		}
+	}
 	job := tp.buildJobFromTrigger(spec, parentJob)
+	tp.applyStatusContext(ctx, job)
+	if err := tp.submitNewJob(ctx, job, hooks.SourceTrigger); err != nil {
+		return "", err
+	}
 
+	logging.Log.WithFields(map[string]interface{}{
+		"job_id":        job.JobID,
+		"job_name":      job.Name,
+		"parent_job_id": parentJob.JobID,
+		"status":        job.Status,
+	}).Info("Created triggered job")
+
+	return job.JobID, nil
+}
+
+// checkTriggerPolicies checks each job spec against the "trigger"
+// admission policies for the parent's project. Every "deny" rule a spec
+// breaks is a validation error at jobs[i].<field>.
+func (tp *TriggerProcessor) checkTriggerPolicies(ctx context.Context, specs []triggerJobSpec, index []int, parentJob *models.Job) (TriggerValidationErrors, error) {
+	var errs TriggerValidationErrors
+	projectID := admission.ProjectID(parentJob.ProjectID)
+	for i, spec := range specs {
+		violations, err := admission.Violations(ctx, tp.store, models.PolicyTargetTrigger, projectID, spec)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range violations {
+			errs = append(errs, TriggerValidationError{
+				Path:    fmt.Sprintf("jobs[%d].%s", index[i], v.Field),
+				Message: fmt.Sprintf("denied by admission policy %q: %s", v.Policy, v.Message),
+			})
+		}
+	}
+	return errs, nil
+}
+
+// submitNewJob runs the pre_job_create hooks for job, created from source,
+// routes it to its project's queue, creates it in the database, registers
+// it as a pending check, and submits it to Corndogs unless job intake is
+// paused. A failed submission marks the job failed rather than returning an
+// error: the job exists by then.
+func (tp *TriggerProcessor) submitNewJob(ctx context.Context, job *models.Job, source string) error {
+	if err := hooks.CheckJobCreate(ctx, job, source); err != nil {
+		return err
+	}
+	if job.ProjectID != nil {
+		if project, err := tp.store.GetProjectByID(ctx, *job.ProjectID); err == nil {
+			RouteProjectQueue(ctx, tp.store, project, job)
+		}
+	}
 	if err := tp.store.CreateJob(ctx, job); err != nil {
-		return "", fmt.Errorf("failed to create job in database: %w", err)
+		return fmt.Errorf("failed to create job in database: %w", err)
 	}
 
 	// Register as a pending check on the commit immediately, before Corndogs
@@ -329,8 +573,8 @@ func (tp *TriggerProcessor) createAndSubmitJob(ctx context.Context, spec trigger
 		}
 	}
 
-	if tp.corndogsClient == nil {
-		return job.JobID, nil
+	if HoldIfPaused(ctx, tp.store, job) || tp.corndogsClient == nil {
+		return nil
 	}
 
 	taskPayload := tp.buildTaskPayload(job)
@@ -349,15 +593,7 @@ func (tp *TriggerProcessor) createAndSubmitJob(ctx context.Context, spec trigger
 	if err := tp.store.UpdateJob(ctx, job); err != nil {
 		logging.Log.WithError(err).WithField("job_id", job.JobID).Error("Failed to update triggered job after Corndogs submission")
 	}
-
-	logging.Log.WithFields(map[string]interface{}{
-		"job_id":        job.JobID,
-		"job_name":      job.Name,
-		"parent_job_id": parentJob.JobID,
-		"status":        job.Status,
-	}).Info("Created triggered job")
-
-	return job.JobID, nil
+	return nil
 }
 
 // buildJobFromTrigger creates a models.Job from a trigger spec and parent job.
@@ -387,10 +623,13 @@ func (tp *TriggerProcessor) buildJobFromTrigger(spec triggerJobSpec, parentJob *
 		Description: fmt.Sprintf("Triggered by eval job %s", parentJob.JobID),
 		Status:      "submitted",
 		QueueName:   parentJob.QueueName,
+		Canary:      parentJob.Canary,
 		JobEnvVars:  envVars,
 		CodeDir:     DefaultJobCodeDir(parentJob.CodeDir),
 		JobDir:      DefaultJobDir(parentJob.CodeDir, parentJob.JobDir),
 	}
+	// Triggered jobs can't reach secrets their parent couldn't.
+	job.SecretScopes = append(pq.StringArray(nil), parentJob.SecretScopes...)
 
 	// Source configuration
 	if spec.SourceType != "" {
@@ -403,6 +642,11 @@ func (tp *TriggerProcessor) buildJobFromTrigger(spec triggerJobSpec, parentJob *
 	if spec.SourceRef != "" {
 		job.SourceRef = &spec.SourceRef
 	}
+	if job.SourceType != nil && *job.SourceType == models.SourceTypeUpload && hasUploadedSource(parentJob) {
+		// Triggers can't name an upload; the child reuses its parent's.
+		sourcePath := *parentJob.SourcePath
+		job.SourcePath = &sourcePath
+	}
 
 	// CI source configuration
 	if spec.CISourceType != "" {
@@ -448,9 +692,38 @@ func (tp *TriggerProcessor) buildJobFromTrigger(spec triggerJobSpec, parentJob *
 	if spec.Priority != nil {
 		job.Priority = *spec.Priority
 	}
+	job.Deadline = parentJob.Deadline
+	if spec.Deadline != nil {
+		deadline := spec.Deadline.UTC()
+		job.Deadline = &deadline
+	}
 	if len(spec.Capabilities) > 0 {
 		job.Capabilities = spec.Capabilities
 	}
+	// Triggered jobs don't inherit the eval job's platform; callers reject
+	// invalid targets before building the job.
+	if platform, err := NormalizePlatform(spec.TargetOS, spec.TargetArch); err == nil {
+		job.TargetOS = platform.OS
+		job.TargetArch = platform.Arch
+	}
+	job.Affinity = spec.Affinity
+	job.AvoidWorkers = spec.AvoidWorkers
+	job.Environment = spec.Environment
+	if spec.Scan != nil {
+		job.Scan = spec.Scan
+		ApplyScanSpec(job)
+	}
+
+	// The pipeline's shared workspaces are scoped to the job whose triggers
+	// started it, so jobs triggered further down still share them.
+	if spec.SharedWorkspace != "" {
+		job.SharedWorkspace = spec.SharedWorkspace
+		job.SharedWorkspaceScope = parentJob.SharedWorkspaceScope
+		if job.SharedWorkspaceScope == "" {
+			job.SharedWorkspaceScope = parentJob.JobID
+		}
+	}
+	job.Cache = spec.Cache
 
 	// Copy event metadata from parent
 	if parentJob.EventMetadata != nil {
@@ -459,12 +732,19 @@ func (tp *TriggerProcessor) buildJobFromTrigger(spec triggerJobSpec, parentJob *
 
 	// Copy VCS metadata (Notes) so child jobs can report commit status.
 	// Strip the IsEval flag so child jobs actually update commit status.
-	// Set the StatusContext to the job name so each job gets a distinct GitHub status check.
+	// Set the StatusContext to the job's status key, its name by default,
+	// so each job gets a distinct GitHub status check; applyStatusContext
+	// applies the project's template to it.
 	if parentJob.Notes != "" {
 		var metadata vcs.JobMetadata
 		if err := json.Unmarshal([]byte(parentJob.Notes), &metadata); err == nil {
 			metadata.IsEval = false
-			metadata.StatusContext = spec.JobName
+			metadata.StatusKey = spec.StatusKey
+			if metadata.StatusKey == "" {
+				metadata.StatusKey = spec.JobName
+			}
+			metadata.StatusContext = metadata.StatusKey
+			metadata.StatusContextAliases = nil
 			if err := metadata.ApplyToJob(job); err != nil {
 				job.Notes = parentJob.Notes
 			}
@@ -476,6 +756,27 @@ func (tp *TriggerProcessor) buildJobFromTrigger(spec triggerJobSpec, parentJob *
 	return job
 }
 
+// applyStatusContext names job's commit status after its project's status
+// context template, with the project's aliases of it, when the project
+// has a template or aliases.
+func (tp *TriggerProcessor) applyStatusContext(ctx context.Context, job *models.Job) {
+	if job.ProjectID == nil || *job.ProjectID == "" {
+		return
+	}
+	metadata, err := vcs.MetadataFromJob(job)
+	if err != nil || metadata == nil || metadata.StatusKey == "" {
+		return
+	}
+	project, err := tp.store.GetProjectByID(ctx, *job.ProjectID)
+	if err != nil || project == nil {
+		return
+	}
+	metadata.UseStatusContext(project, project.StatusContext(metadata.StatusKey, metadata.StatusKey))
+	if err := metadata.ApplyToJob(job); err != nil {
+		logging.Log.WithError(err).WithField("job_id", job.JobID).Warn("Failed to apply the project's status context")
+	}
+}
+
 // buildTaskPayload creates a Corndogs TaskPayload from a job.
 func (tp *TriggerProcessor) buildTaskPayload(job *models.Job) *corndogs.TaskPayload {
 	return BuildTaskPayload(job)
@@ -534,6 +835,10 @@ func BuildTaskPayload(job *models.Job) *corndogs.TaskPayload {
 	if job.JobEnvVars != nil {
 		payload.Config["environment"] = job.JobEnvVars
 	}
+	payload.SetTargetPlatform(job.TargetOS, job.TargetArch)
+	if job.QueueRouted {
+		payload.SetTargetQueue(job.QueueName)
+	}
 
 	return payload
 }
@@ -3,9 +3,11 @@ package worker
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -87,6 +89,7 @@ func TestProcessTriggers_WrongType(t *testing.T) {
 }
 
 func TestProcessTriggers_SingleJob(t *testing.T) {
+	stubSourceHostLookup(t, map[string]string{"github.com": "140.82.112.3"})
 	tmpDir := t.TempDir()
 	priority := 10
 	timeout := 1800
@@ -494,6 +497,7 @@ func TestProcessTriggers_CornDogsSubmitError(t *testing.T) {
 }
 
 func TestProcessTriggers_TaskPayloadStructure(t *testing.T) {
+	stubSourceHostLookup(t, map[string]string{"github.com": "140.82.112.3"})
 	tmpDir := t.TempDir()
 	priority := 15
 	timeout := 900
@@ -510,7 +514,8 @@ func TestProcessTriggers_TaskPayloadStructure(t *testing.T) {
 				Priority:       &priority,
 				Timeout:        &timeout,
 				Env: map[string]string{
-					"DEPLOY_ENV": "production",
+					"DEPLOY_ENV":     "production",
+					"REGISTRY_TOKEN": "secretref://registry/prod/token",
 				},
 			},
 		},
@@ -591,6 +596,10 @@ func TestProcessTriggers_TaskPayloadStructure(t *testing.T) {
 	if envVars["DEPLOY_ENV"] != "production" {
 		t.Errorf("expected DEPLOY_ENV 'production', got %v", envVars["DEPLOY_ENV"])
 	}
+	// Secret URIs are left for the worker running the job to resolve.
+	if envVars["REGISTRY_TOKEN"] != "secretref://registry/prod/token" {
+		t.Errorf("expected REGISTRY_TOKEN left unresolved, got %v", envVars["REGISTRY_TOKEN"])
+	}
 }
 
 func TestProcessTriggersFromData_ReturnsJobIDs(t *testing.T) {
@@ -828,6 +837,59 @@ func TestBuildJobFromTrigger_CopiesNotesFromParent(t *testing.T) {
 	}
 }
 
+// statusContextStore is MockStore with a project.
+type statusContextStore struct {
+	MockStore
+	project *models.Project
+}
+
+func (s *statusContextStore) GetProjectByID(ctx context.Context, projectID string) (*models.Project, error) {
+	return s.project, nil
+}
+
+func TestApplyStatusContext_ProjectTemplate(t *testing.T) {
+	projectID := "proj-1"
+	st := &statusContextStore{project: &models.Project{
+		ProjectID:             projectID,
+		Name:                  "api",
+		StatusContextTemplate: "ci/{project}/{job}",
+		StatusContextAliases:  models.StatusContextAliases{"unit-tests": "ci/api/unit"},
+	}}
+	tp := NewTriggerProcessor(st, nil)
+	parentJob := &models.Job{
+		JobID:     "parent-id",
+		ProjectID: &projectID,
+		Notes:     `{"vcs_provider":"github","repo":"org/repo","commit_sha":"abc123","status_context":"reactorcide/eval","is_eval":true,"status_key":"eval"}`,
+	}
+
+	tests := []struct {
+		name        string
+		spec        triggerJobSpec
+		wantContext string
+		wantAliases []string
+	}{
+		{"named by job name", triggerJobSpec{JobName: "lint"}, "ci/api/lint", nil},
+		{"named by status key", triggerJobSpec{JobName: "Unit tests (renamed)", StatusKey: "unit"}, "ci/api/unit", []string{"unit-tests"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := tp.buildJobFromTrigger(tt.spec, parentJob)
+			tp.applyStatusContext(context.Background(), job)
+
+			metadata, err := vcs.MetadataFromJob(job)
+			if err != nil || metadata == nil {
+				t.Fatalf("failed to parse job notes: %v", err)
+			}
+			if metadata.StatusContext != tt.wantContext {
+				t.Errorf("expected status_context %q, got %q", tt.wantContext, metadata.StatusContext)
+			}
+			if !reflect.DeepEqual(metadata.StatusContextAliases, tt.wantAliases) {
+				t.Errorf("expected aliases %v, got %v", tt.wantAliases, metadata.StatusContextAliases)
+			}
+		})
+	}
+}
+
 func TestBuildJobFromTrigger_EmptyNotesNotCopied(t *testing.T) {
 	mockStore := &MockStore{}
 	tp := NewTriggerProcessor(mockStore, nil)
@@ -852,6 +914,34 @@ func TestBuildJobFromTrigger_EmptyNotesNotCopied(t *testing.T) {
 	}
 }
 
+func TestBuildJobFromTrigger_SharedWorkspaceScope(t *testing.T) {
+	tp := NewTriggerProcessor(&MockStore{}, nil)
+	spec := triggerJobSpec{
+		JobName:         "test",
+		JobCommand:      "make test",
+		SharedWorkspace: "build",
+	}
+
+	// Jobs triggered by the eval job are scoped to it.
+	evalJob := &models.Job{JobID: "eval-id", UserID: "user-123"}
+	job := tp.buildJobFromTrigger(spec, evalJob)
+	if job.SharedWorkspace != "build" || job.SharedWorkspaceScope != "eval-id" {
+		t.Errorf("expected shared workspace build scoped to eval-id, got %q scoped to %q", job.SharedWorkspace, job.SharedWorkspaceScope)
+	}
+
+	// Jobs triggered further down keep the pipeline's scope.
+	job = tp.buildJobFromTrigger(spec, &models.Job{JobID: "build-id", UserID: "user-123", SharedWorkspaceScope: "eval-id"})
+	if job.SharedWorkspaceScope != "eval-id" {
+		t.Errorf("expected scope eval-id, got %q", job.SharedWorkspaceScope)
+	}
+
+	spec.SharedWorkspace = ""
+	job = tp.buildJobFromTrigger(spec, evalJob)
+	if job.SharedWorkspace != "" || job.SharedWorkspaceScope != "" {
+		t.Errorf("expected no shared workspace, got %q scoped to %q", job.SharedWorkspace, job.SharedWorkspaceScope)
+	}
+}
+
 func TestProcessTriggers_JobFile(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -1249,3 +1339,78 @@ func writeTriggersFile(t *testing.T, dir string, tf triggersFile) {
 		t.Fatalf("failed to write triggers file: %v", err)
 	}
 }
+
+func TestBuildJobEnv_CoercesNonStringValues(t *testing.T) {
+	jp := NewJobProcessor(&MockStore{}, nil, false)
+	job := &models.Job{
+		JobID:     "test-job",
+		QueueName: "reactorcide-jobs",
+		JobEnvVars: models.JSONB{
+			"COUNT":   float64(1000000),
+			"RATIO":   0.5,
+			"ENABLED": true,
+			"NESTED":  map[string]interface{}{"a": "b"},
+			"EMPTY":   nil,
+		},
+	}
+
+	env := jp.buildJobEnv(job)
+
+	want := map[string]string{"COUNT": "1000000", "RATIO": "0.5", "ENABLED": "true", "NESTED": `{"a":"b"}`}
+	for key, value := range want {
+		if env[key] != value {
+			t.Errorf("%s = %q, want %q", key, env[key], value)
+		}
+	}
+	if _, ok := env["EMPTY"]; ok {
+		t.Error("null env value should be left out")
+	}
+}
+
+// policyMockStore adds admission policies to MockStore.
+type policyMockStore struct {
+	MockStore
+	policies []models.AdmissionPolicy
+}
+
+func (m *policyMockStore) ListAdmissionPolicies(ctx context.Context) ([]models.AdmissionPolicy, error) {
+	return m.policies, nil
+}
+
+func TestProcessTriggersFromData_AdmissionPolicy(t *testing.T) {
+	limit := 3600.0
+	mockStore := &policyMockStore{policies: []models.AdmissionPolicy{{
+		Name:        "org-rules",
+		Target:      models.PolicyTargetTrigger,
+		Enforcement: models.PolicyEnforceDeny,
+		Rules: models.PolicyRules{
+			{Field: "container_image", Op: models.PolicyOpIn, Values: []string{"registry.example.com/*"}},
+			{Field: "timeout", Op: models.PolicyOpMax, Limit: &limit},
+		},
+	}}}
+	mockStore.CreateJobFunc = func(ctx context.Context, job *models.Job) error {
+		job.JobID = "generated-job-id"
+		return nil
+	}
+	tp := NewTriggerProcessor(mockStore, corndogs.NewMockClient())
+	parent := &models.Job{JobID: "parent-job-id", UserID: "user-123", QueueName: "reactorcide-jobs"}
+
+	data := []byte(`{"type":"trigger_job","jobs":[
+		{"job_name":"build","job_command":"make","container_image":"registry.example.com/builder"},
+		{"job_name":"deploy","job_command":"deploy","container_image":"docker.io/deployer","timeout":7200}
+	]}`)
+	_, err := tp.ProcessTriggersFromData(context.Background(), data, "", parent)
+	var verrs TriggerValidationErrors
+	if !errors.As(err, &verrs) {
+		t.Fatalf("expected validation errors, got %v", err)
+	}
+	if len(verrs) != 2 || verrs[0].Path != "jobs[1].container_image" || verrs[1].Path != "jobs[1].timeout" {
+		t.Fatalf("unexpected validation errors: %v", verrs)
+	}
+	if !strings.Contains(verrs[1].Message, `admission policy "org-rules"`) {
+		t.Errorf("expected the policy to be named, got %q", verrs[1].Message)
+	}
+	if len(mockStore.CreateJobCalls) != 0 {
+		t.Errorf("expected no jobs created, got %d", len(mockStore.CreateJobCalls))
+	}
+}
@@ -0,0 +1,300 @@
+package worker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// Trigger schema versions understood by ProcessTriggersFromData.
+//
+// v1 is the original triggers.json shape: "version" absent (or 1), loose
+// per-job validation, unknown fields silently ignored. Every triggers.json
+// written before versioning existed is v1, so it stays the default.
+//
+// v2 has the same field set but opts into the semantic checks below
+// unconditionally (job_name required and unique, a command or job_file on
+// every job, known conditions/source types, for_each/item_var pairing).
+// v1 only gets those checks when the project enables strict validation.
+const (
+	TriggerSchemaV1 = 1
+	TriggerSchemaV2 = 2
+
+	triggerTypeJob = "trigger_job"
+)
+
+// knownTriggerConditions mirrors the conditions evaluateWorkflowCondition
+// understands. Anything else evaluates to "unsupported condition" at run
+// time, so strict/v2 validation rejects it up front instead.
+var knownTriggerConditions = map[string]bool{
+	"":                   true,
+	"all_success":        true,
+	"all_success(needs)": true,
+	"any_failed":         true,
+	"any_failed(needs)":  true,
+	"always":             true,
+	"always()":           true,
+}
+
+// TriggerValidationError is one problem found in a triggers document. Path
+// is a JSON-path-like pointer to the offending value (e.g. "jobs[2].condition")
+// so the message can be traced back to the eval job's output.
+type TriggerValidationError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// TriggerValidationErrors is the full set of problems found in one triggers
+// document. It implements error so ProcessTriggersFromData can return it
+// directly; callers that want the structured list use errors.As.
+type TriggerValidationErrors []TriggerValidationError
+
+func (e TriggerValidationErrors) Error() string {
+	parts := make([]string, 0, len(e))
+	for _, ve := range e {
+		parts = append(parts, ve.Path+": "+ve.Message)
+	}
+	return "invalid triggers: " + strings.Join(parts, "; ")
+}
+
+// TriggerValidationRecord builds the JSONB value stored on the parent job's
+// trigger_validation column when its triggers are rejected.
+func TriggerValidationRecord(version int, strict bool, errs TriggerValidationErrors) models.JSONB {
+	return models.JSONB{
+		"schema_version": version,
+		"strict":         strict,
+		"errors":         errs,
+	}
+}
+
+// triggerDocumentKeys, triggerWorkflowKeys and triggerJobKeys are the field
+// names accepted in strict mode. Job keys are derived from triggerJobSpec's
+// json tags so adding a field there can't silently make strict mode reject it.
+var (
+	triggerDocumentKeys = fieldSet(reflect.TypeOf(triggersFile{}))
+	triggerWorkflowKeys = fieldSet(reflect.TypeOf(triggerWorkflowSpec{}))
+	triggerJobKeys      = fieldSet(reflect.TypeOf(triggerJobSpec{}))
+)
+
+func fieldSet(t reflect.Type) map[string]bool {
+	out := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			out[name] = true
+		}
+	}
+	return out
+}
+
+// parseTriggers decodes and validates a triggers document. strict enables
+// unknown-field rejection and (for v1 documents) the semantic checks that v2
+// applies unconditionally. The returned version is the document's effective
+// schema version, even when validation fails, so it can be recorded with the
+// errors.
+func parseTriggers(data []byte, strict bool) (*triggersFile, int, error) {
+	if len(data) > MaxTriggersBytes {
+		return nil, TriggerSchemaV1, TriggerValidationErrors{{
+			Message: fmt.Sprintf("document is larger than the limit of %d bytes", MaxTriggersBytes),
+		}}
+	}
+
+	var tf triggersFile
+	if err := json.Unmarshal(data, &tf); err != nil {
+		return nil, TriggerSchemaV1, fmt.Errorf("failed to parse triggers data: %w", err)
+	}
+
+	version := tf.Version
+	if version == 0 {
+		version = TriggerSchemaV1
+	}
+
+	var errs TriggerValidationErrors
+	if version != TriggerSchemaV1 && version != TriggerSchemaV2 {
+		errs = append(errs, TriggerValidationError{
+			Path:    "version",
+			Message: fmt.Sprintf("unsupported schema version %d (supported: %d, %d)", tf.Version, TriggerSchemaV1, TriggerSchemaV2),
+		})
+		return &tf, version, errs
+	}
+	if tf.Type != triggerTypeJob {
+		errs = append(errs, TriggerValidationError{
+			Path:    "type",
+			Message: fmt.Sprintf("unexpected trigger type %q (expected %q)", tf.Type, triggerTypeJob),
+		})
+	}
+	if strict {
+		errs = append(errs, unknownTriggerFields(data)...)
+	}
+	if strict || version >= TriggerSchemaV2 {
+		errs = append(errs, validateTriggerJobs(tf.Jobs)...)
+	}
+	// Payload limits apply to every version.
+	errs = append(errs, triggerPayloadLimits(tf.Jobs)...)
+
+	if len(errs) > 0 {
+		return &tf, version, errs
+	}
+	return &tf, version, nil
+}
+
+// unknownTriggerFields walks the raw document and reports every key that
+// isn't part of the schema. It decodes separately from the typed unmarshal
+// (rather than using json.Decoder.DisallowUnknownFields) so every unknown
+// field is reported at once instead of only the first one.
+func unknownTriggerFields(data []byte) TriggerValidationErrors {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil
+	}
+
+	var errs TriggerValidationErrors
+	errs = append(errs, unknownKeys("", raw, triggerDocumentKeys)...)
+
+	if wfRaw, ok := raw["workflow"]; ok && !isJSONNull(wfRaw) {
+		var wf map[string]json.RawMessage
+		if err := json.Unmarshal(wfRaw, &wf); err == nil {
+			errs = append(errs, unknownKeys("workflow.", wf, triggerWorkflowKeys)...)
+		}
+	}
+	if jobsRaw, ok := raw["jobs"]; ok {
+		var jobs []map[string]json.RawMessage
+		if err := json.Unmarshal(jobsRaw, &jobs); err == nil {
+			for i, job := range jobs {
+				errs = append(errs, unknownKeys(fmt.Sprintf("jobs[%d].", i), job, triggerJobKeys)...)
+			}
+		}
+	}
+	return errs
+}
+
+func unknownKeys(prefix string, obj map[string]json.RawMessage, allowed map[string]bool) TriggerValidationErrors {
+	var keys []string
+	for k := range obj {
+		if !allowed[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	errs := make(TriggerValidationErrors, 0, len(keys))
+	for _, k := range keys {
+		errs = append(errs, TriggerValidationError{Path: prefix + k, Message: "unknown field"})
+	}
+	return errs
+}
+
+func isJSONNull(raw json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+}
+
+// validateTriggerJobs runs the per-job semantic checks shared by v2 and
+// strict-mode v1.
+func validateTriggerJobs(jobs []triggerJobSpec) TriggerValidationErrors {
+	var errs TriggerValidationErrors
+	seen := make(map[string]int, len(jobs))
+	for i, job := range jobs {
+		path := fmt.Sprintf("jobs[%d]", i)
+		name := strings.TrimSpace(job.JobName)
+		if name == "" {
+			errs = append(errs, TriggerValidationError{Path: path + ".job_name", Message: "is required"})
+		} else if first, dup := seen[name]; dup {
+			errs = append(errs, TriggerValidationError{Path: path + ".job_name", Message: fmt.Sprintf("duplicates jobs[%d].job_name %q", first, name)})
+		} else {
+			seen[name] = i
+		}
+		if job.JobFile == "" && strings.TrimSpace(job.JobCommand) == "" && job.Scan == nil {
+			errs = append(errs, TriggerValidationError{Path: path, Message: "one of job_file, job_command or scan is required"})
+		}
+		if !knownTriggerConditions[strings.TrimSpace(job.Condition)] {
+			errs = append(errs, TriggerValidationError{Path: path + ".condition", Message: fmt.Sprintf("unsupported condition %q", job.Condition)})
+		}
+		for j, dep := range job.DependsOn {
+			if name != "" && dep == name {
+				errs = append(errs, TriggerValidationError{Path: fmt.Sprintf("%s.depends_on[%d]", path, j), Message: "job cannot depend on itself"})
+			}
+		}
+		if !validTriggerSourceType(job.SourceType, false) {
+			errs = append(errs, TriggerValidationError{Path: path + ".source_type", Message: fmt.Sprintf("unsupported source type %q", job.SourceType)})
+		}
+		if models.SourceType(job.SourceType) == models.SourceTypeOCI {
+			if err := ValidateOCISource(job.SourceURL, job.SourceRef); err != nil {
+				errs = append(errs, TriggerValidationError{Path: path + ".source_url", Message: err.Error()})
+			}
+		}
+		if !validTriggerSourceType(job.CISourceType, true) {
+			errs = append(errs, TriggerValidationError{Path: path + ".ci_source_type", Message: fmt.Sprintf("unsupported source type %q", job.CISourceType)})
+		}
+		if err := ValidateSecretURIs(job.Env); err != nil {
+			errs = append(errs, TriggerValidationError{Path: path + ".env", Message: err.Error()})
+		}
+		if job.Timeout != nil && *job.Timeout < 0 {
+			errs = append(errs, TriggerValidationError{Path: path + ".timeout", Message: "must not be negative"})
+		}
+		if _, err := NormalizePlatform(job.TargetOS, job.TargetArch); err != nil {
+			errs = append(errs, TriggerValidationError{Path: path + ".target_os", Message: err.Error()})
+		}
+		if err := ValidateAffinity(job.Affinity, job.AvoidWorkers); err != nil {
+			errs = append(errs, TriggerValidationError{Path: path + ".affinity", Message: err.Error()})
+		}
+		if job.Environment != "" {
+			if err := models.ValidateEnvironmentName(job.Environment); err != nil {
+				errs = append(errs, TriggerValidationError{Path: path + ".environment", Message: err.Error()})
+			}
+		}
+		if job.Scan != nil {
+			if err := job.Scan.Validate(); err != nil {
+				errs = append(errs, TriggerValidationError{Path: path + ".scan", Message: err.Error()})
+			}
+		}
+		if job.SharedWorkspace != "" {
+			if err := ValidateSharedWorkspaceName(job.SharedWorkspace); err != nil {
+				errs = append(errs, TriggerValidationError{Path: path + ".shared_workspace", Message: err.Error()})
+			}
+		}
+		if job.Cache != "" {
+			if err := ValidateJobCacheName(job.Cache); err != nil {
+				errs = append(errs, TriggerValidationError{Path: path + ".cache", Message: err.Error()})
+			}
+		}
+		if job.ItemVar != "" && len(job.ForEach) == 0 {
+			errs = append(errs, TriggerValidationError{Path: path + ".item_var", Message: "requires for_each"})
+		}
+	}
+	return errs
+}
+
+// triggerPayloadLimits checks the number of jobs and each job's fields
+// against the job payload limits.
+func triggerPayloadLimits(jobs []triggerJobSpec) TriggerValidationErrors {
+	var errs TriggerValidationErrors
+	if len(jobs) > MaxTriggerJobs {
+		errs = append(errs, TriggerValidationError{
+			Path:    "jobs",
+			Message: fmt.Sprintf("defines %d jobs, over the limit of %d", len(jobs), MaxTriggerJobs),
+		})
+	}
+	for i, job := range jobs {
+		for _, limitErr := range CheckJobPayloadLimits(job.JobName, "", job.JobCommand, job.Env) {
+			errs = append(errs, TriggerValidationError{Path: fmt.Sprintf("jobs[%d]", i), Message: limitErr.Error()})
+		}
+	}
+	return errs
+}
+
+// validTriggerSourceType reports whether a triggered job can use st. As in
+// the job API, uploads and OCI artifacts can only be the job's source, not
+// its CI source.
+func validTriggerSourceType(st string, ci bool) bool {
+	switch models.SourceType(st) {
+	case "", models.SourceTypeGit, models.SourceTypeCopy, models.SourceTypeNone:
+		return true
+	case models.SourceTypeUpload, models.SourceTypeOCI:
+		return !ci
+	}
+	return false
+}
@@ -0,0 +1,210 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// strictProjectStore is MockStore with a project lookup that reports
+// strict trigger validation enabled, recording trigger validation writes.
+type strictProjectStore struct {
+	MockStore
+	validations []models.JSONB
+}
+
+func (s *strictProjectStore) SetJobTriggerValidation(ctx context.Context, jobID string, record models.JSONB) error {
+	s.validations = append(s.validations, record)
+	return nil
+}
+
+func (s *strictProjectStore) GetProjectByID(ctx context.Context, projectID string) (*models.Project, error) {
+	return &models.Project{ProjectID: projectID, StrictTriggerValidation: true}, nil
+}
+
+func TestParseTriggers_V1LenientByDefault(t *testing.T) {
+	data := []byte(`{"type":"trigger_job","extra":true,"jobs":[{"job_command":"make","condition":"whenever","surprise":1}]}`)
+
+	tf, version, err := parseTriggers(data, false)
+	if err != nil {
+		t.Fatalf("expected lenient v1 parse to succeed, got %v", err)
+	}
+	if version != TriggerSchemaV1 {
+		t.Errorf("expected version %d, got %d", TriggerSchemaV1, version)
+	}
+	if len(tf.Jobs) != 1 {
+		t.Errorf("expected 1 job, got %d", len(tf.Jobs))
+	}
+}
+
+func TestParseTriggers_StrictRejectsUnknownFields(t *testing.T) {
+	data := []byte(`{"type":"trigger_job","extra":true,"workflow":{"name":"wf","colour":"red"},"jobs":[{"job_name":"a","job_command":"make","surprise":1}]}`)
+
+	_, _, err := parseTriggers(data, true)
+	var verrs TriggerValidationErrors
+	if !errors.As(err, &verrs) {
+		t.Fatalf("expected TriggerValidationErrors, got %v", err)
+	}
+
+	paths := map[string]bool{}
+	for _, ve := range verrs {
+		paths[ve.Path] = true
+	}
+	for _, want := range []string{"extra", "workflow.colour", "jobs[0].surprise"} {
+		if !paths[want] {
+			t.Errorf("expected error for %q, got %v", want, verrs)
+		}
+	}
+}
+
+func TestParseTriggers_V2SemanticChecks(t *testing.T) {
+	data := []byte(`{
+		"type": "trigger_job",
+		"version": 2,
+		"jobs": [
+			{"job_name": "build", "job_command": "make"},
+			{"job_name": "build", "job_command": "make again"},
+			{"job_command": "no name"},
+			{"job_name": "lint"},
+			{"job_name": "test", "job_command": "go test", "condition": "sometimes", "depends_on": ["test"], "item_var": "SUITE"},
+			{"job_name": "sign", "job_command": "make sign", "target_os": "beos"},
+			{"job_name": "deploy", "job_command": "make deploy", "shared_workspace": "../build", "cache": "go mod"},
+			{"job_name": "publish", "job_command": "make publish", "env": {"TOKEN": "secretref://registry"}}
+		]
+	}`)
+
+	_, version, err := parseTriggers(data, false)
+	if version != TriggerSchemaV2 {
+		t.Errorf("expected version %d, got %d", TriggerSchemaV2, version)
+	}
+	var verrs TriggerValidationErrors
+	if !errors.As(err, &verrs) {
+		t.Fatalf("expected TriggerValidationErrors, got %v", err)
+	}
+
+	want := map[string]bool{
+		"jobs[1].job_name":         false,
+		"jobs[2].job_name":         false,
+		"jobs[3]":                  false,
+		"jobs[4].condition":        false,
+		"jobs[4].depends_on[0]":    false,
+		"jobs[4].item_var":         false,
+		"jobs[5].target_os":        false,
+		"jobs[6].shared_workspace": false,
+		"jobs[6].cache":            false,
+		"jobs[7].env":              false,
+	}
+	for _, ve := range verrs {
+		if _, ok := want[ve.Path]; ok {
+			want[ve.Path] = true
+		}
+	}
+	for path, found := range want {
+		if !found {
+			t.Errorf("expected validation error at %q, got %v", path, verrs)
+		}
+	}
+}
+
+func TestParseTriggers_UnsupportedVersion(t *testing.T) {
+	_, _, err := parseTriggers([]byte(`{"type":"trigger_job","version":7,"jobs":[]}`), false)
+	var verrs TriggerValidationErrors
+	if !errors.As(err, &verrs) || len(verrs) != 1 || verrs[0].Path != "version" {
+		t.Fatalf("expected a single version error, got %v", err)
+	}
+}
+
+func TestProcessTriggersFromData_RecordsValidationOnParent(t *testing.T) {
+	projectID := "project-1"
+	store := &strictProjectStore{}
+	tp := NewTriggerProcessor(store, nil)
+	parent := &models.Job{JobID: "parent", ProjectID: &projectID}
+
+	_, err := tp.ProcessTriggersFromData(context.Background(), []byte(`{"type":"trigger_job","jobs":[{"job_name":"a","job_command":"x","bogus":1}]}`), "", parent)
+	if err == nil {
+		t.Fatal("expected strict validation error")
+	}
+	if len(store.validations) != 1 {
+		t.Fatalf("expected trigger validation to be recorded once, got %d", len(store.validations))
+	}
+	if len(store.UpdateJobCalls) != 0 {
+		t.Errorf("expected no full-row job updates, got %d", len(store.UpdateJobCalls))
+	}
+	record := store.validations[0]
+	if record == nil || record["strict"] != true || record["schema_version"] != TriggerSchemaV1 {
+		t.Errorf("unexpected trigger validation record: %#v", record)
+	}
+	if len(store.CreateJobCalls) != 0 {
+		t.Errorf("expected no jobs to be created, got %d", len(store.CreateJobCalls))
+	}
+}
+
+func TestParseTriggers_SourceTypes(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		job  string
+		want string
+	}{
+		{"git", `{"job_name":"a","job_command":"x","source_type":"git"}`, ""},
+		{"upload", `{"job_name":"a","job_command":"x","source_type":"upload"}`, ""},
+		{"oci", `{"job_name":"a","job_command":"x","source_type":"oci","source_url":"ghcr.io/org/ctx:v1"}`, ""},
+		{"oci without image", `{"job_name":"a","job_command":"x","source_type":"oci"}`, "jobs[0].source_url"},
+		{"unknown", `{"job_name":"a","job_command":"x","source_type":"svn"}`, "jobs[0].source_type"},
+		{"upload ci source", `{"job_name":"a","job_command":"x","ci_source_type":"upload"}`, "jobs[0].ci_source_type"},
+		{"oci ci source", `{"job_name":"a","job_command":"x","ci_source_type":"oci"}`, "jobs[0].ci_source_type"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := parseTriggers([]byte(`{"type":"trigger_job","version":2,"jobs":[`+tc.job+`]}`), false)
+			if tc.want == "" {
+				if err != nil {
+					t.Fatalf("expected no validation errors, got %v", err)
+				}
+				return
+			}
+			var verrs TriggerValidationErrors
+			if !errors.As(err, &verrs) || len(verrs) != 1 || verrs[0].Path != tc.want {
+				t.Fatalf("expected a single error at %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestProcessTriggersFromData_UploadSourceReusesParentUpload(t *testing.T) {
+	data := []byte(`{"type":"trigger_job","jobs":[{"job_name":"test","job_command":"make test","source_type":"upload"}]}`)
+	upload := models.SourceTypeUpload
+	sourcePath := "sources/user-1/abc.tar.gz"
+
+	t.Run("parent ran from an upload", func(t *testing.T) {
+		store := &MockStore{}
+		tp := NewTriggerProcessor(store, nil)
+		parent := &models.Job{JobID: "parent", UserID: "user-1", SourceType: &upload, SourcePath: &sourcePath}
+
+		if _, err := tp.ProcessTriggersFromData(context.Background(), data, "", parent); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(store.CreateJobCalls) != 1 {
+			t.Fatalf("expected 1 job to be created, got %d", len(store.CreateJobCalls))
+		}
+		child := store.CreateJobCalls[0]
+		if child.SourceType == nil || *child.SourceType != models.SourceTypeUpload {
+			t.Errorf("expected upload source type, got %v", child.SourceType)
+		}
+		if child.SourcePath == nil || *child.SourcePath != sourcePath {
+			t.Errorf("expected source path %q, got %v", sourcePath, child.SourcePath)
+		}
+	})
+
+	t.Run("parent has no upload", func(t *testing.T) {
+		store := &MockStore{}
+		tp := NewTriggerProcessor(store, nil)
+
+		if _, err := tp.ProcessTriggersFromData(context.Background(), data, "", &models.Job{JobID: "parent", UserID: "user-1"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(store.CreateJobCalls) != 0 {
+			t.Errorf("expected the upload job to be rejected, got %d created", len(store.CreateJobCalls))
+		}
+	})
+}
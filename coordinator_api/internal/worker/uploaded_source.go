@@ -0,0 +1,89 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+// uploadedSourcePrefix is where POST /api/v1/sources keeps uploaded
+// sources in the object store.
+const uploadedSourcePrefix = "sources/"
+
+var uploadIDPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// ValidateUploadID checks the ID of an uploaded source, as returned by
+// POST /api/v1/sources.
+func ValidateUploadID(uploadID string) error {
+	if !uploadIDPattern.MatchString(uploadID) {
+		return fmt.Errorf("invalid upload ID %q", uploadID)
+	}
+	return nil
+}
+
+// UploadedSourceKey is the object key of userID's uploaded source. Jobs with
+// the "upload" source type keep it in SourcePath.
+func UploadedSourceKey(userID, uploadID string) string {
+	return path.Join(strings.TrimSuffix(uploadedSourcePrefix, "/"), userID, uploadID+".tar.gz")
+}
+
+// vcsDirs are the VCS metadata directories WriteSourceArchive leaves out.
+var vcsDirs = map[string]bool{".git": true, ".hg": true, ".svn": true}
+
+// WriteSourceArchive writes dir to w as a gzipped tar for
+// POST /api/v1/sources. VCS metadata directories are left out unless
+// includeVCS is set.
+func WriteSourceArchive(w io.Writer, dir string, includeVCS bool) error {
+	var skipDir func(rel string) bool
+	if !includeVCS {
+		skipDir = func(rel string) bool { return vcsDirs[path.Base(rel)] }
+	}
+	return writeTarGzSkipping(w, dir, skipDir)
+}
+
+// hasUploadedSource reports whether job runs from an uploaded source, which
+// jobs it triggers with source_type "upload" reuse.
+func hasUploadedSource(job *models.Job) bool {
+	return job.SourceType != nil && *job.SourceType == models.SourceTypeUpload && derefString(job.SourcePath) != ""
+}
+
+// prepareUploadedSource extracts the job's uploaded source into its code
+// directory, where runnerlib finds it already checked out. Like shared
+// workspaces, it needs the job to run in the worker's filesystem, so the
+// Kubernetes runner can't run such jobs.
+func (jp *JobProcessor) prepareUploadedSource(ctx context.Context, job *models.Job, hostCodeDir string) error {
+	if job.SourceType == nil || *job.SourceType != models.SourceTypeUpload {
+		return nil
+	}
+	if jp.config.ObjectStore == nil {
+		return fmt.Errorf("uploaded sources need an object store on the worker")
+	}
+	if _, ok := jp.runner.(*KubernetesRunner); ok {
+		return fmt.Errorf("uploaded sources are not supported by the Kubernetes runner")
+	}
+	key := derefString(job.SourcePath)
+	if !strings.HasPrefix(key, uploadedSourcePrefix) || path.Clean(key) != key {
+		return fmt.Errorf("invalid uploaded source %q", key)
+	}
+
+	archive, err := jp.config.ObjectStore.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to download uploaded source: %w", err)
+	}
+	defer archive.Close()
+	if err := extractTarGz(archive, hostCodeDir); err != nil {
+		return fmt.Errorf("failed to extract uploaded source: %w", err)
+	}
+	if err := chownTree(hostCodeDir, 1001, 1001); err != nil {
+		logging.Log.WithError(err).WithField("job_id", job.JobID).
+			Warn("Failed to chown uploaded source - job may fail if running as non-root")
+	}
+	logging.Log.WithField("job_id", job.JobID).WithField("source", key).Info("Extracted uploaded source")
+	return nil
+}
@@ -0,0 +1,56 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+)
+
+func TestPrepareUploadedSource(t *testing.T) {
+	src := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "pkg"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "pkg", "main.go"), []byte("package main"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(src, ".git"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, ".git", "HEAD"), []byte("ref: refs/heads/main"), 0644))
+
+	var archive bytes.Buffer
+	require.NoError(t, WriteSourceArchive(&archive, src, false))
+	objectStore := objects.NewMemoryObjectStore()
+	key := UploadedSourceKey("user-1", "0123456789abcdef0123456789abcdef")
+	require.NoError(t, objectStore.Put(context.Background(), key, &archive, "application/gzip"))
+
+	jp := &JobProcessor{config: &JobProcessorConfig{ObjectStore: objectStore}}
+	upload := models.SourceTypeUpload
+
+	t.Run("extracts into the code directory", func(t *testing.T) {
+		codeDir := t.TempDir()
+		require.NoError(t, jp.prepareUploadedSource(context.Background(), &models.Job{JobID: "job-1", SourceType: &upload, SourcePath: &key}, codeDir))
+
+		data, err := os.ReadFile(filepath.Join(codeDir, "pkg", "main.go"))
+		require.NoError(t, err)
+		assert.Equal(t, "package main", string(data))
+		assert.NoDirExists(t, filepath.Join(codeDir, ".git"), "VCS metadata is left out by default")
+	})
+
+	t.Run("only keys under sources/", func(t *testing.T) {
+		other := "logs/job-2/stdout.json"
+		err := jp.prepareUploadedSource(context.Background(), &models.Job{JobID: "job-2", SourceType: &upload, SourcePath: &other}, t.TempDir())
+		assert.ErrorContains(t, err, "invalid uploaded source")
+	})
+
+	t.Run("other source types are left to runnerlib", func(t *testing.T) {
+		git := models.SourceTypeGit
+		codeDir := t.TempDir()
+		require.NoError(t, jp.prepareUploadedSource(context.Background(), &models.Job{JobID: "job-3", SourceType: &git}, codeDir))
+		entries, _ := os.ReadDir(codeDir)
+		assert.Empty(t, entries)
+	})
+}
@@ -261,7 +261,18 @@ func (jp *JobProcessor) getSecretsProviderForUser(ctx context.Context, userID st
 			}
 			return nil, fmt.Errorf("failed to get org encryption key: %w", err)
 		}
-		return secrets.NewDatabaseProvider(db, userID, orgKey)
+		provider, err := secrets.NewDatabaseProvider(db, userID, orgKey)
+		if err != nil {
+			return nil, err
+		}
+		// Checkout and publishing credentials come from project and org
+		// config rather than the job, so they're read as their owner.
+		access, err := userSecretAccessPolicy(ctx, jp.store, userID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load secret access policy: %w", err)
+		}
+		provider.RestrictTo(access)
+		return provider, nil
 	default:
 		return nil, fmt.Errorf("unknown secrets storage type: %s", storageType)
 	}
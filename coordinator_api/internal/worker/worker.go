@@ -7,7 +7,9 @@ import (
 	"time"
 
 	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/corndogs"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/objects"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/secrets"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
 )
@@ -34,6 +36,61 @@ type Config struct {
 	// (JobRunner.Stop) and a forced Cleanup, checked on the heartbeat tick
 	// (default: 60 seconds). Not used for kill (immediate, no grace).
 	CancelGrace time.Duration
+
+	// Cost accounting: the rates applied to each finished job's resource
+	// usage, and how often running containers are sampled for it (default:
+	// DefaultResourceSampleInterval).
+	CostRates              models.CostRates
+	ResourceSampleInterval time.Duration
+
+	// ResourceMetrics records each job's sampled CPU time, peak memory and
+	// network bytes on the job and in Prometheus (see recordJobResources).
+	ResourceMetrics bool
+
+	// DebugSessions configures debug on failure (see DebugSessions). Left
+	// empty, failed jobs are never held for debugging.
+	DebugSessions DebugSessionConfig
+
+	// Platform is the OS/arch this worker claims jobs for (see Platform).
+	// The zero value works the base queue.
+	Platform Platform
+
+	// Affinity bounds how long jobs with affinity wait for a warm worker
+	// (see AffinityConfig).
+	Affinity AffinityConfig
+
+	// SharedWorkspaces configures the shared workspaces jobs of one
+	// pipeline can request (see SharedWorkspaces).
+	SharedWorkspaces SharedWorkspaceConfig
+
+	// JobCaches configures the caches jobs can keep between runs (see
+	// JobCaches).
+	JobCaches JobCacheConfig
+
+	// LogUploader, if non-nil, ships job logs through the coordinator's
+	// chunk API instead of ObjectStore (see LogUploadClient).
+	LogUploader *LogUploadClient
+
+	// DynamicSecrets, if non-nil, mints short-lived cloud credentials for
+	// jobs of projects that map cloud roles.
+	DynamicSecrets *secrets.DynamicSecrets
+
+	// PayloadOpener, if non-nil, decrypts the encrypted task payloads
+	// claimed from Corndogs (see corndogs.PayloadSealer).
+	PayloadOpener *corndogs.PayloadOpener
+
+	// MaxDeliveryAttempts is how many times a job may be claimed before a
+	// worker that claims it again deadletters it instead (see
+	// models.JobStatusDeadlettered). Zero never deadletters.
+	MaxDeliveryAttempts int
+
+	// OCISources configures pulling jobs' OCI sources (see
+	// OCISourceConfig).
+	OCISources OCISourceConfig
+
+	// GitMirrors configures the git mirror cache git jobs clone with
+	// --reference to (see GitMirrors).
+	GitMirrors GitMirrorConfig
 }
 
 // Worker represents a job processing worker
@@ -104,7 +161,7 @@ func New(config *Config) *Worker {
 	return &Worker{
 		config:     config,
 		jobChan:    make(chan *models.Job, config.Concurrency*2), // Buffered channel
-		processor:  NewJobProcessor(config.Store, runner, config.DryRun),
+		processor:  NewJobProcessorWithConfig(config.Store, runner, config.DryRun, &JobProcessorConfig{Platform: config.Platform}),
 		workerPool: make(chan struct{}, config.Concurrency),
 		lifecycle:  NewLifecycleManager(config.Store),
 		monitor:    monitor,
@@ -202,6 +259,11 @@ func (w *Worker) pollForJobs(ctx context.Context) {
 	}
 
 	for _, job := range jobs {
+		// Jobs for other platforms share the queue name here; leave them for
+		// workers on their platform.
+		if target, err := JobPlatform(&job); err == nil && !w.config.Platform.Accepts(target) {
+			continue
+		}
 		select {
 		case w.jobChan <- &job:
 			// Job sent to processing channel
@@ -287,6 +349,12 @@ func (w *Worker) processJob(ctx context.Context, job *models.Job, workerID int)
 	if err := w.updateJobResult(jobCtx, job, result); err != nil {
 		logger.WithError(err).Error("Failed to update job result")
 	}
+	if job.CompletedAt != nil {
+		recordJobUsage(jobCtx, w.config.Store, w.config.CostRates, job, result.Usage, *job.CompletedAt)
+	}
+	if w.config.ResourceMetrics {
+		recordJobResources(jobCtx, w.config.Store, w.config.QueueName, job, result.Usage)
+	}
 
 	logger.WithField("status", job.Status).
 		WithField("exit_code", result.ExitCode).
@@ -0,0 +1,142 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/sirupsen/logrus"
+)
+
+// workerControlInterval is how often the worker reports in, and so how
+// soon it acts on a drain or quarantine.
+const workerControlInterval = 15 * time.Second
+
+// workerControlStore is the narrow store capability behind worker
+// registration. See postgres_store/worker_operations.go.
+type workerControlStore interface {
+	RegisterWorker(ctx context.Context, worker *models.Worker) (*models.Worker, error)
+	ReportWorker(ctx context.Context, workerID, state string, runningJobs int) (*models.Worker, error)
+}
+
+// WorkerControl registers the worker, reports its state and running jobs
+// every workerControlInterval, and reads back the state admins want it in,
+// so a worker can be drained or quarantined through the API. With
+// diagnostics set, each report also carries the worker's diagnostics.
+type WorkerControl struct {
+	store       workerControlStore
+	worker      models.Worker
+	running     func() int
+	diagnostics *Diagnostics
+	logger      *logrus.Entry
+
+	mu         sync.Mutex
+	registered bool
+	desired    models.Worker
+}
+
+// NewWorkerControl returns nil, leaving the worker always accepting jobs,
+// unless the store can register workers. running reports how many jobs the
+// worker is running.
+func NewWorkerControl(s store.Store, worker models.Worker, running func() int) *WorkerControl {
+	logger := logging.Log.WithField("component", "worker_control")
+	ws, ok := s.(workerControlStore)
+	if !ok || worker.WorkerID == "" {
+		logger.Warn("Store does not support worker registration; drain and quarantine disabled")
+		return nil
+	}
+	return &WorkerControl{
+		store:   ws,
+		worker:  worker,
+		running: running,
+		logger:  logger.WithField("worker_id", worker.WorkerID),
+	}
+}
+
+// Accepting reports whether the worker should take new jobs: false while
+// it's drained or quarantined. A nil WorkerControl always accepts.
+func (c *WorkerControl) Accepting() bool {
+	if c == nil {
+		return true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.desired.Accepting()
+}
+
+// Start reports in every workerControlInterval until ctx is cancelled.
+// Call sync once first, so the worker knows its state before it polls.
+func (c *WorkerControl) Start(ctx context.Context) {
+	ticker := time.NewTicker(workerControlInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := c.sync(ctx); err != nil && ctx.Err() == nil {
+			c.logger.WithError(err).Warn("Failed to report worker state")
+		}
+	}
+}
+
+// sync registers the worker, or reports its state, and takes on the state
+// admins want it in. A worker whose row was deleted registers again.
+func (c *WorkerControl) sync(ctx context.Context) error {
+	c.mu.Lock()
+	registered, desired := c.registered, c.desired
+	c.mu.Unlock()
+
+	running := c.running()
+	var row *models.Worker
+	var err error
+	if registered {
+		row, err = c.store.ReportWorker(ctx, c.worker.WorkerID, desired.ReportedState(running), running)
+		if errors.Is(err, store.ErrNotFound) {
+			registered = false
+		}
+	}
+	if !registered {
+		worker := c.worker
+		now := time.Now().UTC()
+		worker.RunningJobs = running
+		worker.State = models.WorkerStateActive
+		worker.DesiredState = models.WorkerStateActive
+		worker.StartedAt = now
+		worker.LastSeenAt = now
+		row, err = c.store.RegisterWorker(ctx, &worker)
+	}
+	if err != nil {
+		return err
+	}
+
+	if row.DesiredState != desired.DesiredState {
+		c.logger.WithFields(logrus.Fields{"state": row.DesiredState, "reason": row.Reason}).Info("Worker state changed")
+	}
+	// Report a state the new desired state implies, e.g. drained, now
+	// rather than a tick later.
+	if state := row.ReportedState(running); state != row.State {
+		if updated, err := c.store.ReportWorker(ctx, c.worker.WorkerID, state, running); err == nil {
+			row = updated
+		}
+	}
+
+	c.mu.Lock()
+	c.registered = true
+	c.desired = *row
+	c.mu.Unlock()
+
+	// Diagnostics only help debugging, so failing to report them doesn't
+	// fail the report.
+	if ds, ok := c.store.(workerDiagnosticsStore); ok && c.diagnostics != nil {
+		if err := ds.ReportWorkerDiagnostics(ctx, c.worker.WorkerID, c.diagnostics.Report(ctx)); err != nil && ctx.Err() == nil {
+			c.logger.WithError(err).Warn("Failed to report worker diagnostics")
+		}
+	}
+	return nil
+}
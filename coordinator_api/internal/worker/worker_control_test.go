@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// workerControlMockStore keeps one worker row in memory.
+type workerControlMockStore struct {
+	row         *models.Worker
+	registers   int
+	reportCalls []string
+}
+
+func (s *workerControlMockStore) RegisterWorker(ctx context.Context, worker *models.Worker) (*models.Worker, error) {
+	s.registers++
+	row := *worker
+	if s.row != nil && s.row.DesiredState == models.WorkerStateQuarantined {
+		row.DesiredState = models.WorkerStateQuarantined
+		row.State = models.WorkerStateQuarantined
+	}
+	s.row = &row
+	return &row, nil
+}
+
+func (s *workerControlMockStore) ReportWorker(ctx context.Context, workerID, state string, runningJobs int) (*models.Worker, error) {
+	if s.row == nil {
+		return nil, store.ErrNotFound
+	}
+	s.reportCalls = append(s.reportCalls, state)
+	s.row.State = state
+	s.row.RunningJobs = runningJobs
+	row := *s.row
+	return &row, nil
+}
+
+func TestWorkerControl(t *testing.T) {
+	s := &workerControlMockStore{}
+	running := 1
+	c := &WorkerControl{
+		store:   s,
+		worker:  models.Worker{WorkerID: "build-1", QueueName: "reactorcide-jobs", Concurrency: 2},
+		running: func() int { return running },
+		logger:  logging.Log.WithField("component", "worker_control"),
+	}
+	ctx := context.Background()
+
+	require.NoError(t, c.sync(ctx))
+	assert.Equal(t, 1, s.registers)
+	assert.True(t, c.Accepting())
+
+	// A drain stops intake at once but reports draining until the running
+	// job finishes.
+	s.row.DesiredState = models.WorkerStateDraining
+	require.NoError(t, c.sync(ctx))
+	assert.False(t, c.Accepting())
+	assert.Equal(t, models.WorkerStateDraining, s.row.State)
+
+	running = 0
+	require.NoError(t, c.sync(ctx))
+	assert.Equal(t, models.WorkerStateDrained, s.row.State)
+
+	s.row.DesiredState = models.WorkerStateActive
+	require.NoError(t, c.sync(ctx))
+	assert.True(t, c.Accepting())
+	assert.Equal(t, models.WorkerStateActive, s.row.State)
+
+	// A quarantine survives the worker registering again, e.g. after its
+	// row was deleted or it restarted.
+	s.row.DesiredState = models.WorkerStateQuarantined
+	require.NoError(t, c.sync(ctx))
+	assert.False(t, c.Accepting())
+	c.registered = false
+	require.NoError(t, c.sync(ctx))
+	assert.Equal(t, 2, s.registers)
+	assert.False(t, c.Accepting())
+	assert.Equal(t, models.WorkerStateQuarantined, s.row.State)
+}
+
+func TestWorkerControl_Nil(t *testing.T) {
+	var c *WorkerControl
+	assert.True(t, c.Accepting())
+	assert.Nil(t, NewWorkerControl(&MockStore{}, models.Worker{WorkerID: "build-1"}, func() int { return 0 }))
+}
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/catalystcommunity/app-utils-go/logging"
+	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/hooks"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/store/models"
 	"github.com/catalystcommunity/reactorcide/coordinator_api/internal/vcs"
@@ -127,10 +128,8 @@ func (tp *TriggerProcessor) ensureWorkflow(ctx context.Context, parentJob *model
 // commit context, so the status updater posts nothing for them.
 func workflowEventType(parentJob *models.Job) vcs.EventType {
 	if parentJob != nil {
-		if raw, ok := parentJob.JobEnvVars["REACTORCIDE_EVENT_TYPE"]; ok {
-			if s, ok := raw.(string); ok && s != "" {
-				return vcs.EventType(s)
-			}
+		if s, ok := parentJob.JobEnvVars.String("REACTORCIDE_EVENT_TYPE"); ok && s != "" {
+			return vcs.EventType(s)
 		}
 	}
 	return vcs.EventDirectlySubmitted
@@ -331,7 +330,9 @@ func (tp *TriggerProcessor) evaluateWorkflow(ctx context.Context, wf *models.Wor
 		if err != nil {
 			return created, err
 		}
-		created = append(created, jobID)
+		if jobID != "" {
+			created = append(created, jobID)
+		}
 	}
 	if err := tp.refreshWorkflowStatus(ctx, wf); err != nil {
 		return created, err
@@ -344,21 +345,54 @@ func (tp *TriggerProcessor) submitWorkflowNode(ctx context.Context, wf *models.W
 	if err != nil {
 		return "", err
 	}
-	var spec triggerJobSpec
-	specBytes, _ := json.Marshal(node.JobSpec)
-	if err := json.Unmarshal(specBytes, &spec); err != nil {
+	spec, err := workflowNodeSpec(node)
+	if err != nil {
 		return "", err
 	}
+	if len(spec.Inputs) > 0 {
+		vars, err := ws.GetWorkflowVars(ctx, wf.WorkflowID)
+		if err != nil {
+			return "", err
+		}
+		inputs, err := resolveStageInputs(spec, vars)
+		if err != nil {
+			return "", tp.failUnsubmittedNode(ctx, ws, wf, node, err)
+		}
+		spec.Env = cloneStringMap(spec.Env)
+		for key, value := range inputs {
+			spec.Env[key] = value
+		}
+	}
 	parentJob, err := tp.store.GetJobByID(ctx, derefString(wf.ParentJobID))
 	if err != nil {
 		return "", err
 	}
+	if _, err := NormalizePlatform(spec.TargetOS, spec.TargetArch); err != nil {
+		return "", err
+	}
+	if err := ValidateAffinity(spec.Affinity, spec.AvoidWorkers); err != nil {
+		return "", err
+	}
+	if spec.Environment != "" {
+		if err := models.ValidateEnvironmentName(spec.Environment); err != nil {
+			return "", err
+		}
+	}
+	if spec.Scan != nil {
+		if err := spec.Scan.Validate(); err != nil {
+			return "", err
+		}
+	}
 	job := tp.buildJobFromTrigger(spec, parentJob)
+	tp.applyStatusContext(ctx, job)
 	job.WorkflowID = &wf.WorkflowID
 	job.WorkflowNodeID = &node.NodeID
 	runID := uuid.New().String()
 	job.WorkflowRunID = &runID
 	job.WorkflowNodeName = node.DisplayName
+	if err := hooks.CheckJobCreate(ctx, job, hooks.SourceWorkflow); err != nil {
+		return "", tp.failUnsubmittedNode(ctx, ws, wf, node, err)
+	}
 	if err := tp.store.CreateJob(ctx, job); err != nil {
 		return "", err
 	}
@@ -368,7 +402,7 @@ func (tp *TriggerProcessor) submitWorkflowNode(ctx context.Context, wf *models.W
 	if err := ws.UpdateWorkflowNode(ctx, node); err != nil {
 		return "", err
 	}
-	if tp.corndogsClient != nil {
+	if !HoldIfPaused(ctx, tp.store, job) && tp.corndogsClient != nil {
 		taskPayload := tp.buildTaskPayload(job)
 		task, err := tp.corndogsClient.SubmitTask(ctx, taskPayload, int64(job.Priority))
 		if err != nil {
@@ -399,6 +433,24 @@ func (tp *TriggerProcessor) submitWorkflowNode(ctx context.Context, wf *models.W
 	return job.JobID, nil
 }
 
+// failUnsubmittedNode fails a node that can't be submitted, because an
+// input has no value or a hook rejected its job. It fails without a job,
+// the same as one whose job fails, so the nodes depending on it are
+// decided normally.
+func (tp *TriggerProcessor) failUnsubmittedNode(ctx context.Context, ws workflowStore, wf *models.WorkflowInstance, node *models.WorkflowNode, cause error) error {
+	now := time.Now().UTC()
+	node.Status = "failed"
+	node.CompletedAt = &now
+	node.DecisionReason = cause.Error()
+	if err := ws.UpdateWorkflowNode(ctx, node); err != nil {
+		return err
+	}
+	tp.recordWorkflowEvent(ctx, wf.WorkflowID, &node.NodeID, nil, "node_completed", node.DecisionReason, models.JSONB{
+		"status": node.Status,
+	})
+	return nil
+}
+
 func (tp *TriggerProcessor) ProcessWorkflowCompletion(ctx context.Context, workspaceDir string, job *models.Job) error {
 	ws, err := tp.workflowStore()
 	if err != nil {
@@ -494,16 +546,24 @@ func (tp *TriggerProcessor) ProcessWorkflowJobStarted(ctx context.Context, job *
 
 func (tp *TriggerProcessor) mergeWorkflowOutputFile(ctx context.Context, workspaceDir string, wf *models.WorkflowInstance, node *models.WorkflowNode, job *models.Job) error {
 	path := filepath.Join(workspaceDir, "workflow-output.json")
+	var output workflowOutputFile
 	data, err := os.ReadFile(path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
+	if err != nil && !os.IsNotExist(err) {
 		return err
 	}
-	var output workflowOutputFile
-	if err := json.Unmarshal(data, &output); err != nil {
-		return fmt.Errorf("parse workflow output file: %w", err)
+	if err == nil {
+		if err := json.Unmarshal(data, &output); err != nil {
+			return fmt.Errorf("parse workflow output file: %w", err)
+		}
+	}
+	if job.Status == "completed" {
+		spec, err := workflowNodeSpec(node)
+		if err != nil {
+			return err
+		}
+		if err := checkStageOutputs(spec, output.Outputs); err != nil {
+			return err
+		}
 	}
 	if len(output.Vars) > 0 {
 		if err := tp.addWorkflowVars(ctx, wf, output.Vars, &node.NodeID, &job.JobID); err != nil {
@@ -15,6 +15,8 @@ func main() {
 		Commands: []*cli.Command{
 			cmd.ServeCommand,
 			cmd.MigrateCommand,
+			cmd.BackupCommand,
+			cmd.RestoreCommand,
 			cmd.WorkerCommand,
 			cmd.HealthCheckCommand,
 			cmd.TokenCommand,
@@ -22,7 +24,10 @@ func main() {
 			cmd.SecretGrantsCommand,
 			cmd.RunLocalCommand,
 			cmd.SubmitCommand,
+			cmd.RunCommand,
 			cmd.LogsCommand,
+			cmd.ExportJobsCommand,
+			cmd.DoctorCommand,
 		},
 	}
 	err := app.Run(os.Args)
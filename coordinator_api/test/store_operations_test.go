@@ -92,6 +92,12 @@ func TestJobOperations(t *testing.T) {
 			testJobEventMetadataAndParentJob(t, ctx, tx)
 		})
 	})
+
+	t.Run("Count Child Jobs By Status", func(t *testing.T) {
+		RunTransactionalTest(t, func(ctx context.Context, tx *gorm.DB) {
+			testCountChildJobsByStatus(t, ctx, tx)
+		})
+	})
 }
 
 // TestAPITokenOperations tests API token CRUD operations
@@ -490,6 +496,36 @@ func testJobEventMetadataAndParentJob(t *testing.T, ctx context.Context, tx *gor
 	assert.Nil(t, retrievedPlain.ParentJobID)
 }
 
+func testCountChildJobsByStatus(t *testing.T, ctx context.Context, tx *gorm.DB) {
+	dataUtils := &DataUtils{db: tx}
+	counter, ok := store.AppStore.(interface {
+		CountChildJobsByStatus(ctx context.Context, parentJobID string) (map[string]int, error)
+	})
+	require.True(t, ok, "store should count child jobs")
+
+	parentJob, err := dataUtils.CreateJob(DataSetup{"Status": "running"})
+	require.NoError(t, err)
+	for _, status := range []string{"completed", "completed", "failed", "running"} {
+		_, err := dataUtils.CreateJob(DataSetup{
+			"UserID":      parentJob.UserID,
+			"Status":      status,
+			"ParentJobID": parentJob.JobID,
+		})
+		require.NoError(t, err)
+	}
+	// Not a child of parentJob.
+	_, err = dataUtils.CreateJob(DataSetup{"UserID": parentJob.UserID, "Status": "completed"})
+	require.NoError(t, err)
+
+	counts, err := counter.CountChildJobsByStatus(ctx, parentJob.JobID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"completed": 2, "failed": 1, "running": 1}, counts)
+
+	summary := models.AggregateChildJobCounts(counts)
+	assert.Equal(t, 4, summary.Total)
+	assert.Equal(t, models.ChildStatusRunning, summary.Status)
+}
+
 // API Token operation test implementations
 
 func testCreateAPITokenAndValidateAPIToken(t *testing.T, ctx context.Context, tx *gorm.DB) {
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Versioned triggers.json validation. Projects can opt into strict mode
+-- (unknown fields rejected, v2 semantic checks applied to v1 documents), and
+-- a rejected triggers document's structured errors are kept on the eval job
+-- that emitted it so they can be shown alongside that job.
+ALTER TABLE projects ADD COLUMN strict_trigger_validation boolean NOT NULL DEFAULT false;
+ALTER TABLE jobs ADD COLUMN trigger_validation jsonb;
+
+-- +goose Down
+ALTER TABLE jobs DROP COLUMN IF EXISTS trigger_validation;
+ALTER TABLE projects DROP COLUMN IF EXISTS strict_trigger_validation;
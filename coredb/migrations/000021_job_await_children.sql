@@ -0,0 +1,11 @@
+-- +goose Up
+-- Child job fan-in. A job with await_children set stays "running" after its
+-- own execution finishes until every job it spawned (parent_job_id) reaches a
+-- terminal status, so the parent's status reflects the whole pipeline.
+-- Projects can turn this on for the eval jobs their webhooks create.
+ALTER TABLE jobs ADD COLUMN await_children boolean NOT NULL DEFAULT false;
+ALTER TABLE projects ADD COLUMN await_child_jobs boolean NOT NULL DEFAULT false;
+
+-- +goose Down
+ALTER TABLE projects DROP COLUMN IF EXISTS await_child_jobs;
+ALTER TABLE jobs DROP COLUMN IF EXISTS await_children;
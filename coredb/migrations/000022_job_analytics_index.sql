@@ -0,0 +1,7 @@
+-- +goose Up
+-- Supports GET /api/v1/projects/{id}/analytics, which aggregates a project's
+-- terminal jobs by completion time.
+CREATE INDEX jobs_project_completed_at_idx ON jobs(project_id, completed_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS jobs_project_completed_at_idx;
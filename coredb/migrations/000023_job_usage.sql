@@ -0,0 +1,29 @@
+-- +goose Up
+-- Per-job resource usage and its priced cost, recorded by the worker when a
+-- job finishes. Rates are copied onto each row so rate changes never
+-- reprice history. org_id is the job's owning user (orgs are users).
+
+CREATE TABLE job_usage (
+  job_id uuid PRIMARY KEY REFERENCES jobs(job_id) ON DELETE CASCADE,
+  project_id uuid REFERENCES projects(project_id) ON DELETE SET NULL,
+  org_id uuid NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+  completed_at timestamp NOT NULL,
+  cpu_seconds double precision NOT NULL DEFAULT 0,
+  memory_gb_seconds double precision NOT NULL DEFAULT 0,
+  storage_bytes bigint NOT NULL DEFAULT 0,
+  estimated boolean NOT NULL DEFAULT false,
+  cpu_second_rate double precision NOT NULL DEFAULT 0,
+  memory_gb_second_rate double precision NOT NULL DEFAULT 0,
+  storage_gb_rate double precision NOT NULL DEFAULT 0,
+  cost double precision NOT NULL DEFAULT 0,
+  currency text NOT NULL DEFAULT 'USD',
+  created_at timestamp DEFAULT timezone('utc', now()) NOT NULL
+);
+
+CREATE INDEX job_usage_org_completed_at_idx ON job_usage(org_id, completed_at);
+CREATE INDEX job_usage_project_completed_at_idx ON job_usage(project_id, completed_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS job_usage_project_completed_at_idx;
+DROP INDEX IF EXISTS job_usage_org_completed_at_idx;
+DROP TABLE IF EXISTS job_usage;
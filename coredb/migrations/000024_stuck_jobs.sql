@@ -0,0 +1,38 @@
+-- +goose Up
+-- Stuck job detection. The worker stamps last_log_at whenever it ships a log
+-- chunk; the coordinator's stuck-job monitor compares it (and run time
+-- against the job name's historical p95) to the effective policy, and
+-- records what it found and did in stuck_job_reports.
+
+ALTER TABLE jobs ADD COLUMN last_log_at timestamp;
+
+-- Per-project overrides of the global REACTORCIDE_STUCK_JOB_* policy.
+-- NULL inherits the global value; stuck_job_action 'off' disables detection.
+ALTER TABLE projects ADD COLUMN stuck_job_action text
+  CHECK (stuck_job_action IN ('off', 'warn', 'kill_retry', 'kill_fail'));
+ALTER TABLE projects ADD COLUMN stuck_job_no_log_minutes integer;
+ALTER TABLE projects ADD COLUMN stuck_job_p95_multiplier double precision;
+
+CREATE TABLE stuck_job_reports (
+  job_id uuid PRIMARY KEY REFERENCES jobs(job_id) ON DELETE CASCADE,
+  project_id uuid REFERENCES projects(project_id) ON DELETE SET NULL,
+  reason text NOT NULL CHECK (reason IN ('exceeded_p95', 'no_log_output')),
+  action text NOT NULL CHECK (action IN ('warn', 'kill_retry', 'kill_fail')),
+  detail text NOT NULL DEFAULT '',
+  detected_at timestamp DEFAULT timezone('utc', now()) NOT NULL,
+  remediated_at timestamp,
+  retry_job_id uuid REFERENCES jobs(job_id) ON DELETE SET NULL,
+  last_error text NOT NULL DEFAULT ''
+);
+
+CREATE INDEX stuck_job_reports_detected_at_idx ON stuck_job_reports(detected_at);
+CREATE INDEX stuck_job_reports_pending_idx ON stuck_job_reports(action) WHERE remediated_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS stuck_job_reports_pending_idx;
+DROP INDEX IF EXISTS stuck_job_reports_detected_at_idx;
+DROP TABLE IF EXISTS stuck_job_reports;
+ALTER TABLE projects DROP COLUMN IF EXISTS stuck_job_p95_multiplier;
+ALTER TABLE projects DROP COLUMN IF EXISTS stuck_job_no_log_minutes;
+ALTER TABLE projects DROP COLUMN IF EXISTS stuck_job_action;
+ALTER TABLE jobs DROP COLUMN IF EXISTS last_log_at;
@@ -0,0 +1,34 @@
+-- +goose Up
+-- Intake pauses for maintenance windows. While a pause covers a job, the
+-- coordinator records it as 'held' instead of submitting it to Corndogs;
+-- resuming submits the held jobs no remaining pause covers. target is ''
+-- for the global scope, the queue name for 'queue', and the project id for
+-- 'project'.
+
+ALTER TABLE jobs DROP CONSTRAINT jobs_status_check;
+ALTER TABLE jobs ADD CONSTRAINT jobs_status_check CHECK (status IN (
+    'held', 'submitted', 'queued', 'running', 'cancelling', 'completed', 'failed', 'cancelled', 'timeout'
+));
+
+CREATE INDEX jobs_held_created_at_idx ON jobs(created_at) WHERE status = 'held';
+
+CREATE TABLE intake_pauses (
+  scope text NOT NULL CHECK (scope IN ('global', 'queue', 'project')),
+  target text NOT NULL DEFAULT '',
+  reason text NOT NULL DEFAULT '',
+  banner text NOT NULL DEFAULT '',
+  paused_by uuid REFERENCES users(user_id) ON DELETE SET NULL,
+  paused_at timestamp DEFAULT timezone('utc', now()) NOT NULL,
+  PRIMARY KEY (scope, target)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS intake_pauses;
+
+DROP INDEX IF EXISTS jobs_held_created_at_idx;
+
+UPDATE jobs SET status = 'submitted' WHERE status = 'held';
+ALTER TABLE jobs DROP CONSTRAINT jobs_status_check;
+ALTER TABLE jobs ADD CONSTRAINT jobs_status_check CHECK (status IN (
+    'submitted', 'queued', 'running', 'cancelling', 'completed', 'failed', 'cancelled', 'timeout'
+));
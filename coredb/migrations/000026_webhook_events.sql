@@ -0,0 +1,31 @@
+-- +goose Up
+-- Ledger of received VCS webhook deliveries: whether each was built or
+-- filtered (and why), and the eval job it produced. The unique delivery id
+-- makes job creation exactly-once across provider redeliveries.
+
+CREATE TABLE webhook_events (
+  event_id uuid DEFAULT generate_ulid() PRIMARY KEY,
+  project_id uuid REFERENCES projects(project_id) ON DELETE CASCADE,
+  provider text NOT NULL,
+  delivery_id text,
+  event_type text NOT NULL,
+  generic_event text NOT NULL DEFAULT '',
+  repo text NOT NULL DEFAULT '',
+  ref text NOT NULL DEFAULT '',
+  commit_sha text NOT NULL DEFAULT '',
+  pr_number integer,
+  status text NOT NULL CHECK (status IN ('processing', 'processed', 'filtered', 'failed')),
+  filter_reason text NOT NULL DEFAULT '',
+  error text NOT NULL DEFAULT '',
+  job_id uuid REFERENCES jobs(job_id) ON DELETE SET NULL,
+  received_at timestamp DEFAULT timezone('utc', now()) NOT NULL,
+  processed_at timestamp
+);
+
+CREATE UNIQUE INDEX webhook_events_delivery_idx ON webhook_events(provider, delivery_id) WHERE delivery_id IS NOT NULL;
+CREATE INDEX webhook_events_project_received_at_idx ON webhook_events(project_id, received_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS webhook_events_project_received_at_idx;
+DROP INDEX IF EXISTS webhook_events_delivery_idx;
+DROP TABLE IF EXISTS webhook_events;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Tag filters, separate from target_branches. NULL/empty keeps the old
+-- behaviour of matching tags against target_branches. Both columns accept
+-- exact names, globs, /regex/ and !negated patterns.
+ALTER TABLE projects ADD COLUMN tag_patterns text[];
+
+-- +goose Down
+ALTER TABLE projects DROP COLUMN tag_patterns;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Draft/WIP pull request policy. 'run' builds them as before; 'skip' and
+-- 'defer' hold them back, and 'defer' builds them once they're marked
+-- ready for review or lose their WIP title.
+ALTER TABLE projects ADD COLUMN draft_pr_policy text NOT NULL DEFAULT 'run'
+  CHECK (draft_pr_policy IN ('run', 'skip', 'defer'));
+-- Case-insensitive regular expressions marking a PR title as work in progress.
+ALTER TABLE projects ADD COLUMN wip_title_patterns text[];
+
+-- +goose Down
+ALTER TABLE projects DROP COLUMN wip_title_patterns;
+ALTER TABLE projects DROP COLUMN draft_pr_policy;
@@ -0,0 +1,29 @@
+-- +goose Up
+-- Native merge queue. Each project tests one entry at a time against the
+-- provider's speculative merge commit, and merges it only when green.
+CREATE TABLE merge_queue_entries (
+  entry_id uuid PRIMARY KEY DEFAULT generate_ulid(),
+  project_id uuid NOT NULL REFERENCES projects(project_id) ON DELETE CASCADE,
+  provider text NOT NULL,
+  pr_number integer NOT NULL,
+  status text NOT NULL CHECK (status IN ('queued', 'testing', 'merged', 'failed', 'cancelled')),
+  head_sha text NOT NULL DEFAULT '',
+  tested_sha text NOT NULL DEFAULT '',
+  job_id uuid REFERENCES jobs(job_id) ON DELETE SET NULL,
+  error text NOT NULL DEFAULT '',
+  enqueued_by uuid REFERENCES users(user_id) ON DELETE SET NULL,
+  created_at timestamp NOT NULL DEFAULT timezone('utc', now()),
+  updated_at timestamp NOT NULL DEFAULT timezone('utc', now()),
+  finished_at timestamp
+);
+
+-- A PR is in a project's queue at most once.
+CREATE UNIQUE INDEX merge_queue_entries_active_pr_idx ON merge_queue_entries (project_id, pr_number)
+  WHERE status IN ('queued', 'testing');
+-- Merges are serialized: one testing entry per project, across replicas.
+CREATE UNIQUE INDEX merge_queue_entries_testing_idx ON merge_queue_entries (project_id)
+  WHERE status = 'testing';
+CREATE INDEX merge_queue_entries_project_created_at_idx ON merge_queue_entries (project_id, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS merge_queue_entries;
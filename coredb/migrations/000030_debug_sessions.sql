@@ -0,0 +1,26 @@
+-- +goose Up
+-- Opt-in debug on failure: a failed job's environment is kept alive for a
+-- bounded time so developers can open a shell in it through the coordinator.
+ALTER TABLE jobs ADD COLUMN debug_on_failure boolean NOT NULL DEFAULT false;
+
+CREATE TABLE debug_sessions (
+  session_id uuid PRIMARY KEY DEFAULT generate_ulid(),
+  job_id uuid NOT NULL REFERENCES jobs(job_id) ON DELETE CASCADE,
+  worker_id text NOT NULL DEFAULT '',
+  status text NOT NULL CHECK (status IN ('active', 'closed', 'expired')),
+  -- Where the coordinator reaches the worker's exec bridge, and the bearer
+  -- token the worker expects for this session.
+  bridge_url text NOT NULL,
+  bridge_token text NOT NULL,
+  expires_at timestamp NOT NULL,
+  created_at timestamp NOT NULL DEFAULT timezone('utc', now()),
+  closed_at timestamp
+);
+
+-- A job has at most one live debug session.
+CREATE UNIQUE INDEX debug_sessions_active_job_idx ON debug_sessions (job_id)
+  WHERE status = 'active';
+
+-- +goose Down
+DROP TABLE IF EXISTS debug_sessions;
+ALTER TABLE jobs DROP COLUMN debug_on_failure;
@@ -0,0 +1,30 @@
+-- +goose Up
+-- Preview environments: deploy jobs register a PR's environment URL, and
+-- closing or merging the PR runs the teardown command they registered.
+CREATE TABLE preview_environments (
+  environment_id uuid PRIMARY KEY DEFAULT generate_ulid(),
+  project_id uuid REFERENCES projects(project_id) ON DELETE CASCADE,
+  vcs_repo text NOT NULL,
+  pr_number integer NOT NULL,
+  name text NOT NULL,
+  url text NOT NULL DEFAULT '',
+  status text NOT NULL CHECK (status IN ('active', 'tearing_down', 'torn_down', 'teardown_failed')),
+  head_sha text NOT NULL DEFAULT '',
+  deploy_job_id uuid REFERENCES jobs(job_id) ON DELETE SET NULL,
+  teardown_command text NOT NULL DEFAULT '',
+  teardown_job_id uuid REFERENCES jobs(job_id) ON DELETE SET NULL,
+  created_at timestamp NOT NULL DEFAULT timezone('utc', now()),
+  updated_at timestamp NOT NULL DEFAULT timezone('utc', now()),
+  torn_down_at timestamp
+);
+
+-- A PR has at most one active environment of each name; a reopened PR can
+-- deploy again while the previous one is still being torn down.
+CREATE UNIQUE INDEX preview_environments_active_idx ON preview_environments (vcs_repo, pr_number, name)
+  WHERE status = 'active';
+CREATE INDEX preview_environments_project_idx ON preview_environments (project_id, created_at DESC);
+CREATE INDEX preview_environments_teardown_job_idx ON preview_environments (teardown_job_id)
+  WHERE teardown_job_id IS NOT NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS preview_environments;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- OS/arch targeting: jobs pinned to a platform are routed to that
+-- platform's worker queue.
+ALTER TABLE jobs ADD COLUMN target_os text NOT NULL DEFAULT '';
+ALTER TABLE jobs ADD COLUMN target_arch text NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE jobs DROP COLUMN target_arch;
+ALTER TABLE jobs DROP COLUMN target_os;
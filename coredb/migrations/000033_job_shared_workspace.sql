@@ -0,0 +1,9 @@
+-- +goose Up
+-- Shared workspaces: a named workspace the jobs of one pipeline share,
+-- scoped to the job whose triggers started the pipeline.
+ALTER TABLE jobs ADD COLUMN shared_workspace text NOT NULL DEFAULT '';
+ALTER TABLE jobs ADD COLUMN shared_workspace_scope text NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE jobs DROP COLUMN shared_workspace_scope;
+ALTER TABLE jobs DROP COLUMN shared_workspace;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Log lifecycle: which storage tier a finished job's logs are in (hot,
+-- cold, deleted) and their size, for tiering and per-project usage.
+ALTER TABLE jobs ADD COLUMN logs_tier text NOT NULL DEFAULT '';
+ALTER TABLE jobs ADD COLUMN logs_bytes bigint NOT NULL DEFAULT 0;
+ALTER TABLE jobs ADD COLUMN logs_tiered_at timestamp;
+
+CREATE INDEX jobs_logs_tier_completed_at_idx ON jobs(logs_tier, completed_at) WHERE completed_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS jobs_logs_tier_completed_at_idx;
+ALTER TABLE jobs DROP COLUMN logs_tiered_at;
+ALTER TABLE jobs DROP COLUMN logs_bytes;
+ALTER TABLE jobs DROP COLUMN logs_tier;
@@ -0,0 +1,26 @@
+-- +goose Up
+-- Per-path secret access policies: read, write or no access to the secret
+-- paths matching path_pattern, for a user, a user role, or API tokens
+-- carrying a scope.
+CREATE TABLE secret_access_policies (
+  policy_id uuid PRIMARY KEY DEFAULT generate_ulid(),
+  created_at timestamp NOT NULL DEFAULT timezone('utc', now()),
+  updated_at timestamp NOT NULL DEFAULT timezone('utc', now()),
+  org_id uuid NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+  subject_type text NOT NULL CHECK (subject_type IN ('user', 'role', 'token_scope')),
+  subject text NOT NULL,
+  path_pattern text NOT NULL,
+  access text NOT NULL CHECK (access IN ('read', 'write', 'none')),
+  description text NOT NULL DEFAULT ''
+);
+
+CREATE UNIQUE INDEX secret_access_policies_subject_path_idx
+  ON secret_access_policies(org_id, subject_type, subject, path_pattern);
+
+-- Scopes an API token carries, matched by token_scope policies.
+ALTER TABLE api_tokens ADD COLUMN scopes text[] NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE api_tokens DROP COLUMN scopes;
+DROP INDEX IF EXISTS secret_access_policies_subject_path_idx;
+DROP TABLE IF EXISTS secret_access_policies;
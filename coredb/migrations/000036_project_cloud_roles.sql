@@ -0,0 +1,27 @@
+-- +goose Up
+-- Dynamic secrets: per-project mappings to cloud roles the worker mints
+-- short-lived credentials for at job start (an AWS role ARN to assume, or a
+-- GCP service account to impersonate).
+CREATE TABLE project_cloud_roles (
+  role_id uuid DEFAULT generate_ulid() PRIMARY KEY,
+  created_at timestamp DEFAULT timezone('utc', now()) NOT NULL,
+  updated_at timestamp DEFAULT timezone('utc', now()) NOT NULL,
+  project_id uuid NOT NULL REFERENCES projects(project_id) ON DELETE CASCADE,
+  name text NOT NULL,
+  provider text NOT NULL CHECK (provider IN ('aws', 'gcp')),
+  role text NOT NULL,
+  job_name_match text NOT NULL DEFAULT 'any',
+  job_name_pattern text NOT NULL DEFAULT '',
+  duration_seconds integer NOT NULL DEFAULT 3600,
+  region text NOT NULL DEFAULT '',
+  external_id text NOT NULL DEFAULT '',
+  scopes text[] NOT NULL DEFAULT '{}',
+  env_prefix text NOT NULL DEFAULT '',
+  description text
+);
+
+CREATE UNIQUE INDEX project_cloud_roles_project_name_idx ON project_cloud_roles(project_id, name);
+
+-- +goose Down
+DROP INDEX IF EXISTS project_cloud_roles_project_name_idx;
+DROP TABLE IF EXISTS project_cloud_roles;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- A known value encrypted with each master key, so a health check can tell
+-- a key that's present but wrong from a good one.
+ALTER TABLE master_keys ADD COLUMN canary bytea;
+
+-- +goose Down
+ALTER TABLE master_keys DROP COLUMN canary;
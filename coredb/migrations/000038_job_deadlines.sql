@@ -0,0 +1,14 @@
+-- +goose Up
+-- Job deadlines: when a job must have completed, and when the deadline
+-- monitor found it missed.
+ALTER TABLE jobs ADD COLUMN deadline timestamp;
+ALTER TABLE jobs ADD COLUMN deadline_missed_at timestamp;
+
+CREATE INDEX jobs_open_deadline_idx ON jobs(deadline) WHERE deadline IS NOT NULL AND deadline_missed_at IS NULL;
+CREATE INDEX jobs_project_deadline_idx ON jobs(project_id, deadline) WHERE deadline IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS jobs_project_deadline_idx;
+DROP INDEX IF EXISTS jobs_open_deadline_idx;
+ALTER TABLE jobs DROP COLUMN deadline_missed_at;
+ALTER TABLE jobs DROP COLUMN deadline;
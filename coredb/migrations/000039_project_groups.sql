@@ -0,0 +1,29 @@
+-- +goose Up
+-- Project groups: folders of projects sharing defaults. A NULL default is
+-- unset. Member projects inherit each default the group sets unless the
+-- field is in their group_overrides.
+CREATE TABLE project_groups (
+  group_id uuid PRIMARY KEY DEFAULT generate_ulid(),
+  created_at timestamp NOT NULL DEFAULT timezone('utc', now()),
+  updated_at timestamp NOT NULL DEFAULT timezone('utc', now()),
+  user_id uuid REFERENCES users(user_id) ON DELETE SET NULL,
+  name text NOT NULL UNIQUE,
+  description text NOT NULL DEFAULT '',
+  default_runner_image text,
+  default_timeout_seconds integer,
+  default_queue_name text,
+  allowed_event_types text[],
+  vcs_token_secret text,
+  webhook_secret text
+);
+
+ALTER TABLE projects ADD COLUMN group_id uuid REFERENCES project_groups(group_id) ON DELETE SET NULL;
+ALTER TABLE projects ADD COLUMN group_overrides text[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX projects_group_id_idx ON projects(group_id) WHERE group_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS projects_group_id_idx;
+ALTER TABLE projects DROP COLUMN IF EXISTS group_overrides;
+ALTER TABLE projects DROP COLUMN IF EXISTS group_id;
+DROP TABLE IF EXISTS project_groups;
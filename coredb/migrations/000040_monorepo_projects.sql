@@ -0,0 +1,17 @@
+-- +goose Up
+-- Several projects can share a repository (a monorepo), told apart by the
+-- directory holding their .reactorcide config and by the paths whose
+-- changes trigger them. A webhook for the repository fans out to each.
+ALTER TABLE projects ADD COLUMN config_path text NOT NULL DEFAULT '';
+ALTER TABLE projects ADD COLUMN path_filters text[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE projects DROP CONSTRAINT IF EXISTS projects_repo_url_unique;
+CREATE UNIQUE INDEX projects_repo_url_config_path_key ON projects(repo_url, config_path);
+
+-- +goose Down
+-- Fails while two projects share a repository; remove or move them first.
+DROP INDEX IF EXISTS projects_repo_url_config_path_key;
+ALTER TABLE projects ADD CONSTRAINT projects_repo_url_unique UNIQUE (repo_url);
+
+ALTER TABLE projects DROP COLUMN IF EXISTS path_filters;
+ALTER TABLE projects DROP COLUMN IF EXISTS config_path;
@@ -0,0 +1,25 @@
+-- +goose Up
+-- Commit status and PR comment updates waiting to be delivered to the VCS
+-- provider. A job has at most one pending delivery of each kind: a newer
+-- status change bumps the generation, and the dispatcher delivers the
+-- job's state as of delivery time.
+CREATE TABLE vcs_status_outbox (
+  outbox_id uuid DEFAULT generate_ulid() PRIMARY KEY,
+  job_id uuid NOT NULL REFERENCES jobs(job_id) ON DELETE CASCADE,
+  kind text NOT NULL CHECK (kind IN ('commit_status', 'pr_comment')),
+  status text NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'failed')),
+  generation integer NOT NULL DEFAULT 1,
+  attempts integer NOT NULL DEFAULT 0,
+  next_attempt_at timestamp DEFAULT timezone('utc', now()) NOT NULL,
+  last_error text NOT NULL DEFAULT '',
+  created_at timestamp DEFAULT timezone('utc', now()) NOT NULL,
+  updated_at timestamp DEFAULT timezone('utc', now()) NOT NULL
+);
+
+CREATE UNIQUE INDEX vcs_status_outbox_job_kind_key ON vcs_status_outbox(job_id, kind);
+CREATE INDEX vcs_status_outbox_due_idx ON vcs_status_outbox(next_attempt_at) WHERE status = 'pending';
+
+-- +goose Down
+DROP INDEX IF EXISTS vcs_status_outbox_due_idx;
+DROP INDEX IF EXISTS vcs_status_outbox_job_kind_key;
+DROP TABLE IF EXISTS vcs_status_outbox;
@@ -0,0 +1,32 @@
+-- +goose Up
+-- Cross-project triggers: when a job of project_id whose name matches
+-- finishes with a matching result, an eval job runs in
+-- downstream_project_id at ref.
+CREATE TABLE project_downstream_triggers (
+  trigger_id uuid DEFAULT generate_ulid() PRIMARY KEY,
+  created_at timestamp DEFAULT timezone('utc', now()) NOT NULL,
+  updated_at timestamp DEFAULT timezone('utc', now()) NOT NULL,
+  project_id uuid NOT NULL REFERENCES projects(project_id) ON DELETE CASCADE,
+  downstream_project_id uuid NOT NULL REFERENCES projects(project_id) ON DELETE CASCADE,
+  job_name_match text NOT NULL DEFAULT 'exact',
+  job_name_pattern text NOT NULL,
+  on_status text NOT NULL DEFAULT 'success' CHECK (on_status IN ('success', 'failure', 'always')),
+  ref text NOT NULL DEFAULT 'main',
+  created_by uuid REFERENCES users(user_id) ON DELETE SET NULL,
+  description text,
+  CHECK (project_id <> downstream_project_id)
+);
+
+CREATE INDEX project_downstream_triggers_project_idx ON project_downstream_triggers(project_id);
+CREATE INDEX project_downstream_triggers_downstream_idx ON project_downstream_triggers(downstream_project_id);
+
+-- The job whose completion started this one, in another project.
+ALTER TABLE jobs ADD COLUMN upstream_job_id uuid REFERENCES jobs(job_id) ON DELETE SET NULL;
+CREATE INDEX jobs_upstream_job_id_idx ON jobs(upstream_job_id) WHERE upstream_job_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS jobs_upstream_job_id_idx;
+ALTER TABLE jobs DROP COLUMN IF EXISTS upstream_job_id;
+DROP INDEX IF EXISTS project_downstream_triggers_downstream_idx;
+DROP INDEX IF EXISTS project_downstream_triggers_project_idx;
+DROP TABLE IF EXISTS project_downstream_triggers;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Service accounts: headless users for automation (webhook-originated
+-- jobs, workers), created by admins. They have no email, can't log in
+-- interactively, and never hold the admin, system_admin or support roles.
+ALTER TABLE users ADD COLUMN is_service_account boolean NOT NULL DEFAULT false;
+ALTER TABLE users ADD COLUMN description text NOT NULL DEFAULT '';
+
+-- Users without an email (service accounts, and identities whose provider
+-- sent none) store ''; only real addresses need to be unique.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_unique;
+CREATE UNIQUE INDEX users_email_unique ON users(email) WHERE email <> '';
+
+ALTER TABLE users ADD CONSTRAINT users_service_account_check CHECK (
+  NOT is_service_account
+  OR (coalesce(email, '') = '' AND NOT roles && ARRAY['admin', 'system_admin', 'support']::user_role[])
+);
+
+-- +goose Down
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_service_account_check;
+DROP INDEX IF EXISTS users_email_unique;
+ALTER TABLE users ADD CONSTRAINT users_email_unique UNIQUE (email);
+ALTER TABLE users DROP COLUMN IF EXISTS description;
+ALTER TABLE users DROP COLUMN IF EXISTS is_service_account;
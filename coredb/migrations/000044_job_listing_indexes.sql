@@ -0,0 +1,37 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+-- Job listing: every filter ListJobs takes is an equality, and results are
+-- ordered by (created_at, job_id) descending for keyset pagination, so each
+-- filter gets a composite index that ends in the sort key. They supersede
+-- the single-column indexes on the same leading column.
+--
+-- Built CONCURRENTLY so writes to jobs carry on while they build. If a
+-- build fails, drop the INVALID index it leaves and rerun the migration.
+CREATE INDEX CONCURRENTLY IF NOT EXISTS jobs_created_at_job_id_idx ON jobs(created_at DESC, job_id DESC);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS jobs_user_id_created_at_idx ON jobs(user_id, created_at DESC, job_id DESC);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS jobs_status_created_at_idx ON jobs(status, created_at DESC, job_id DESC);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS jobs_queue_name_created_at_idx ON jobs(queue_name, created_at DESC, job_id DESC);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS jobs_project_id_created_at_idx ON jobs(project_id, created_at DESC, job_id DESC);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS jobs_parent_job_id_created_at_idx ON jobs(parent_job_id, created_at DESC, job_id DESC) WHERE parent_job_id IS NOT NULL;
+
+DROP INDEX CONCURRENTLY IF EXISTS jobs_created_at_idx;
+DROP INDEX CONCURRENTLY IF EXISTS jobs_user_id_idx;
+DROP INDEX CONCURRENTLY IF EXISTS jobs_status_idx;
+DROP INDEX CONCURRENTLY IF EXISTS jobs_queue_name_idx;
+DROP INDEX CONCURRENTLY IF EXISTS jobs_project_id_idx;
+DROP INDEX CONCURRENTLY IF EXISTS jobs_parent_job_id_idx;
+
+-- +goose Down
+CREATE INDEX CONCURRENTLY IF NOT EXISTS jobs_parent_job_id_idx ON jobs(parent_job_id);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS jobs_project_id_idx ON jobs(project_id);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS jobs_queue_name_idx ON jobs(queue_name);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS jobs_status_idx ON jobs(status);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS jobs_user_id_idx ON jobs(user_id);
+CREATE INDEX CONCURRENTLY IF NOT EXISTS jobs_created_at_idx ON jobs(created_at);
+
+DROP INDEX CONCURRENTLY IF EXISTS jobs_parent_job_id_created_at_idx;
+DROP INDEX CONCURRENTLY IF EXISTS jobs_project_id_created_at_idx;
+DROP INDEX CONCURRENTLY IF EXISTS jobs_queue_name_created_at_idx;
+DROP INDEX CONCURRENTLY IF EXISTS jobs_status_created_at_idx;
+DROP INDEX CONCURRENTLY IF EXISTS jobs_user_id_created_at_idx;
+DROP INDEX CONCURRENTLY IF EXISTS jobs_created_at_job_id_idx;
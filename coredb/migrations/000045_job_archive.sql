@@ -0,0 +1,54 @@
+-- +goose Up
+-- Archive tables for finished jobs and processed webhook events past
+-- REACTORCIDE_JOB_ARCHIVE_AFTER_DAYS, so operational queries on jobs and
+-- webhook_events stay on recent rows. The archiver moves rows in batches
+-- with INSERT ... SELECT *, so each archive table must keep its table's
+-- columns in the same order: add a column to both in the same migration.
+CREATE TABLE jobs_archive (LIKE jobs INCLUDING DEFAULTS INCLUDING CONSTRAINTS);
+ALTER TABLE jobs_archive ADD PRIMARY KEY (job_id);
+CREATE INDEX jobs_archive_created_at_idx ON jobs_archive(created_at DESC, job_id DESC);
+CREATE INDEX jobs_archive_user_id_created_at_idx ON jobs_archive(user_id, created_at DESC, job_id DESC);
+CREATE INDEX jobs_archive_project_id_created_at_idx ON jobs_archive(project_id, created_at DESC, job_id DESC);
+
+CREATE TABLE webhook_events_archive (LIKE webhook_events INCLUDING DEFAULTS INCLUDING CONSTRAINTS);
+ALTER TABLE webhook_events_archive ADD PRIMARY KEY (event_id);
+CREATE INDEX webhook_events_archive_project_received_at_idx ON webhook_events_archive(project_id, received_at);
+
+-- Moving a job out of jobs must not delete its usage history or stuck
+-- report, or unlink the jobs, workflow rows, webhook events, merge queue
+-- entries and previews that refer to it; those references now point at
+-- jobs or jobs_archive, and the store resolves either. Debug sessions and
+-- outbox rows are operational and still go with the job.
+ALTER TABLE jobs DROP CONSTRAINT IF EXISTS jobs_parent_job_id_fkey;
+ALTER TABLE jobs DROP CONSTRAINT IF EXISTS jobs_upstream_job_id_fkey;
+ALTER TABLE job_usage DROP CONSTRAINT IF EXISTS job_usage_job_id_fkey;
+ALTER TABLE stuck_job_reports DROP CONSTRAINT IF EXISTS stuck_job_reports_job_id_fkey;
+ALTER TABLE stuck_job_reports DROP CONSTRAINT IF EXISTS stuck_job_reports_retry_job_id_fkey;
+ALTER TABLE workflow_instances DROP CONSTRAINT IF EXISTS workflow_instances_parent_job_id_fkey;
+ALTER TABLE workflow_nodes DROP CONSTRAINT IF EXISTS workflow_nodes_job_id_fkey;
+ALTER TABLE workflow_vars DROP CONSTRAINT IF EXISTS workflow_vars_source_job_id_fkey;
+ALTER TABLE workflow_events DROP CONSTRAINT IF EXISTS workflow_events_job_id_fkey;
+ALTER TABLE webhook_events DROP CONSTRAINT IF EXISTS webhook_events_job_id_fkey;
+ALTER TABLE merge_queue_entries DROP CONSTRAINT IF EXISTS merge_queue_entries_job_id_fkey;
+ALTER TABLE preview_environments DROP CONSTRAINT IF EXISTS preview_environments_deploy_job_id_fkey;
+ALTER TABLE preview_environments DROP CONSTRAINT IF EXISTS preview_environments_teardown_job_id_fkey;
+
+-- +goose Down
+-- Archived rows go back first so the restored foreign keys hold.
+INSERT INTO jobs SELECT * FROM jobs_archive ON CONFLICT (job_id) DO NOTHING;
+INSERT INTO webhook_events SELECT * FROM webhook_events_archive ON CONFLICT (event_id) DO NOTHING;
+ALTER TABLE preview_environments ADD CONSTRAINT preview_environments_teardown_job_id_fkey FOREIGN KEY (teardown_job_id) REFERENCES jobs(job_id) ON DELETE SET NULL NOT VALID;
+ALTER TABLE preview_environments ADD CONSTRAINT preview_environments_deploy_job_id_fkey FOREIGN KEY (deploy_job_id) REFERENCES jobs(job_id) ON DELETE SET NULL NOT VALID;
+ALTER TABLE merge_queue_entries ADD CONSTRAINT merge_queue_entries_job_id_fkey FOREIGN KEY (job_id) REFERENCES jobs(job_id) ON DELETE SET NULL NOT VALID;
+ALTER TABLE webhook_events ADD CONSTRAINT webhook_events_job_id_fkey FOREIGN KEY (job_id) REFERENCES jobs(job_id) ON DELETE SET NULL NOT VALID;
+ALTER TABLE workflow_events ADD CONSTRAINT workflow_events_job_id_fkey FOREIGN KEY (job_id) REFERENCES jobs(job_id) ON DELETE SET NULL NOT VALID;
+ALTER TABLE workflow_vars ADD CONSTRAINT workflow_vars_source_job_id_fkey FOREIGN KEY (source_job_id) REFERENCES jobs(job_id) ON DELETE SET NULL NOT VALID;
+ALTER TABLE workflow_nodes ADD CONSTRAINT workflow_nodes_job_id_fkey FOREIGN KEY (job_id) REFERENCES jobs(job_id) ON DELETE SET NULL NOT VALID;
+ALTER TABLE workflow_instances ADD CONSTRAINT workflow_instances_parent_job_id_fkey FOREIGN KEY (parent_job_id) REFERENCES jobs(job_id) ON DELETE SET NULL NOT VALID;
+ALTER TABLE stuck_job_reports ADD CONSTRAINT stuck_job_reports_retry_job_id_fkey FOREIGN KEY (retry_job_id) REFERENCES jobs(job_id) ON DELETE SET NULL NOT VALID;
+ALTER TABLE stuck_job_reports ADD CONSTRAINT stuck_job_reports_job_id_fkey FOREIGN KEY (job_id) REFERENCES jobs(job_id) ON DELETE CASCADE NOT VALID;
+ALTER TABLE job_usage ADD CONSTRAINT job_usage_job_id_fkey FOREIGN KEY (job_id) REFERENCES jobs(job_id) ON DELETE CASCADE NOT VALID;
+ALTER TABLE jobs ADD CONSTRAINT jobs_upstream_job_id_fkey FOREIGN KEY (upstream_job_id) REFERENCES jobs(job_id) ON DELETE SET NULL NOT VALID;
+ALTER TABLE jobs ADD CONSTRAINT jobs_parent_job_id_fkey FOREIGN KEY (parent_job_id) REFERENCES jobs(job_id) ON DELETE SET NULL NOT VALID;
+DROP TABLE IF EXISTS webhook_events_archive;
+DROP TABLE IF EXISTS jobs_archive;
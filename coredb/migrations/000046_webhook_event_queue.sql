@@ -0,0 +1,33 @@
+-- +goose Up
+-- Webhook deliveries are acknowledged once stored and processed afterwards.
+-- The raw delivery (payload and the headers the parser reads) and the
+-- projects it authenticated for are kept until processing finishes, so a
+-- retry, or another coordinator after a crash, can process it. The archive
+-- table gets the same columns in the same order.
+ALTER TABLE webhook_events ADD COLUMN payload bytea;
+ALTER TABLE webhook_events ADD COLUMN headers jsonb;
+ALTER TABLE webhook_events ADD COLUMN project_ids uuid[];
+ALTER TABLE webhook_events ADD COLUMN attempts integer NOT NULL DEFAULT 0;
+ALTER TABLE webhook_events ADD COLUMN next_attempt_at timestamp;
+
+ALTER TABLE webhook_events_archive ADD COLUMN payload bytea;
+ALTER TABLE webhook_events_archive ADD COLUMN headers jsonb;
+ALTER TABLE webhook_events_archive ADD COLUMN project_ids uuid[];
+ALTER TABLE webhook_events_archive ADD COLUMN attempts integer NOT NULL DEFAULT 0;
+ALTER TABLE webhook_events_archive ADD COLUMN next_attempt_at timestamp;
+
+CREATE INDEX webhook_events_next_attempt_idx ON webhook_events(next_attempt_at)
+  WHERE status = 'processing' AND payload IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS webhook_events_next_attempt_idx;
+ALTER TABLE webhook_events_archive DROP COLUMN IF EXISTS next_attempt_at;
+ALTER TABLE webhook_events_archive DROP COLUMN IF EXISTS attempts;
+ALTER TABLE webhook_events_archive DROP COLUMN IF EXISTS project_ids;
+ALTER TABLE webhook_events_archive DROP COLUMN IF EXISTS headers;
+ALTER TABLE webhook_events_archive DROP COLUMN IF EXISTS payload;
+ALTER TABLE webhook_events DROP COLUMN IF EXISTS next_attempt_at;
+ALTER TABLE webhook_events DROP COLUMN IF EXISTS attempts;
+ALTER TABLE webhook_events DROP COLUMN IF EXISTS project_ids;
+ALTER TABLE webhook_events DROP COLUMN IF EXISTS headers;
+ALTER TABLE webhook_events DROP COLUMN IF EXISTS payload;
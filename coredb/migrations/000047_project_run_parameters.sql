@@ -0,0 +1,7 @@
+-- +goose Up
+-- The environment variables a manual run (POST /api/v1/projects/{id}/run)
+-- may set. Manual runs can't override anything else.
+ALTER TABLE projects ADD COLUMN run_parameters text[] NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE projects DROP COLUMN IF EXISTS run_parameters;
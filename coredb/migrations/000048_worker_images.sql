@@ -0,0 +1,20 @@
+-- +goose Up
+-- Runner image pre-pull: each worker pulls the images of the
+-- "runner_image_prepull" global setting ahead of jobs and records here how
+-- each pull went, so admins can see which workers have which images warm.
+CREATE TABLE worker_images (
+  worker_id text NOT NULL,
+  image text NOT NULL,
+  status text NOT NULL CHECK (status IN ('pulled', 'failed')),
+  error text NOT NULL DEFAULT '',
+  -- The setting's generation the last pull was for.
+  generation integer NOT NULL DEFAULT 0,
+  pull_seconds double precision NOT NULL DEFAULT 0,
+  -- The last successful pull, kept when a later pull fails.
+  pulled_at timestamp,
+  checked_at timestamp NOT NULL DEFAULT timezone('utc', now()),
+  PRIMARY KEY (worker_id, image)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS worker_images;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Scheduling hints honored by workers when they dequeue a job: affinity
+-- ('project' prefers workers that recently ran the same project) and the
+-- workers (IDs or globs) the job must not run on.
+ALTER TABLE jobs ADD COLUMN affinity text NOT NULL DEFAULT '';
+ALTER TABLE jobs ADD COLUMN avoid_workers text[] NOT NULL DEFAULT '{}';
+-- jobs_archive keeps jobs' columns, in order (see 000045_job_archive.sql).
+ALTER TABLE jobs_archive ADD COLUMN affinity text NOT NULL DEFAULT '';
+ALTER TABLE jobs_archive ADD COLUMN avoid_workers text[] NOT NULL DEFAULT '{}';
+CREATE INDEX IF NOT EXISTS idx_jobs_project_worker_started
+    ON jobs (project_id, started_at DESC) WHERE worker_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_jobs_project_worker_started;
+ALTER TABLE jobs_archive DROP COLUMN IF EXISTS avoid_workers;
+ALTER TABLE jobs_archive DROP COLUMN IF EXISTS affinity;
+ALTER TABLE jobs DROP COLUMN IF EXISTS avoid_workers;
+ALTER TABLE jobs DROP COLUMN IF EXISTS affinity;
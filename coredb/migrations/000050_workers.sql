@@ -0,0 +1,23 @@
+-- +goose Up
+-- Workers register themselves and report in every few seconds. Admins set
+-- desired_state to drain or quarantine a worker; the worker reads it back
+-- on its next report and stops taking jobs.
+CREATE TABLE IF NOT EXISTS workers (
+    worker_id text PRIMARY KEY,
+    queue_name text NOT NULL DEFAULT '',
+    platform text NOT NULL DEFAULT '',
+    concurrency integer NOT NULL DEFAULT 0,
+    running_jobs integer NOT NULL DEFAULT 0,
+    state text NOT NULL DEFAULT 'active'
+        CHECK (state IN ('active', 'draining', 'drained', 'quarantined')),
+    desired_state text NOT NULL DEFAULT 'active'
+        CHECK (desired_state IN ('active', 'draining', 'quarantined')),
+    reason text NOT NULL DEFAULT '',
+    changed_by uuid REFERENCES users(user_id) ON DELETE SET NULL,
+    changed_at timestamp with time zone,
+    started_at timestamp with time zone NOT NULL DEFAULT timezone('utc', now()),
+    last_seen_at timestamp with time zone NOT NULL DEFAULT timezone('utc', now())
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS workers;
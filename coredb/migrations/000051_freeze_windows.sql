@@ -0,0 +1,43 @@
+-- +goose Up
+-- Deploy freeze windows. Jobs that name an environment are deploy jobs;
+-- while a window covering one is active, new deploy jobs are held
+-- (freeze_window_id records which window holds them). freeze_overrides is
+-- the audit trail of admins releasing held deploys early.
+ALTER TABLE jobs ADD COLUMN environment text NOT NULL DEFAULT '';
+ALTER TABLE jobs ADD COLUMN freeze_window_id uuid;
+ALTER TABLE jobs_archive ADD COLUMN environment text NOT NULL DEFAULT '';
+ALTER TABLE jobs_archive ADD COLUMN freeze_window_id uuid;
+
+CREATE TABLE IF NOT EXISTS freeze_windows (
+    window_id uuid PRIMARY KEY DEFAULT generate_ulid(),
+    project_id uuid REFERENCES projects(project_id) ON DELETE CASCADE,
+    environment text NOT NULL DEFAULT '',
+    name text NOT NULL,
+    reason text NOT NULL DEFAULT '',
+    mode text NOT NULL DEFAULT 'hold' CHECK (mode IN ('hold', 'approval')),
+    schedule text NOT NULL DEFAULT '',
+    duration_minutes integer NOT NULL DEFAULT 0,
+    timezone text NOT NULL DEFAULT 'UTC',
+    manual text NOT NULL DEFAULT '' CHECK (manual IN ('', 'on', 'off')),
+    created_by uuid REFERENCES users(user_id) ON DELETE SET NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT timezone('utc', now()),
+    updated_at timestamp with time zone NOT NULL DEFAULT timezone('utc', now())
+);
+
+CREATE TABLE IF NOT EXISTS freeze_overrides (
+    override_id uuid PRIMARY KEY DEFAULT generate_ulid(),
+    job_id uuid NOT NULL REFERENCES jobs(job_id) ON DELETE CASCADE,
+    window_id uuid REFERENCES freeze_windows(window_id) ON DELETE SET NULL,
+    user_id uuid REFERENCES users(user_id) ON DELETE SET NULL,
+    reason text NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT timezone('utc', now())
+);
+CREATE INDEX IF NOT EXISTS idx_freeze_overrides_created ON freeze_overrides (created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS freeze_overrides;
+DROP TABLE IF EXISTS freeze_windows;
+ALTER TABLE jobs_archive DROP COLUMN IF EXISTS freeze_window_id;
+ALTER TABLE jobs_archive DROP COLUMN IF EXISTS environment;
+ALTER TABLE jobs DROP COLUMN IF EXISTS freeze_window_id;
+ALTER TABLE jobs DROP COLUMN IF EXISTS environment;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- Admission policies: admin-authored rules that jobs about to be created,
+-- the job specs of a triggers document, or projects being created or
+-- updated must pass. rules is a JSON array of models.PolicyRule.
+CREATE TABLE IF NOT EXISTS admission_policies (
+    policy_id uuid PRIMARY KEY DEFAULT generate_ulid(),
+    name text NOT NULL,
+    description text NOT NULL DEFAULT '',
+    target text NOT NULL CHECK (target IN ('job', 'trigger', 'project')),
+    enforcement text NOT NULL DEFAULT 'deny' CHECK (enforcement IN ('deny', 'warn', 'off')),
+    project_id uuid REFERENCES projects(project_id) ON DELETE CASCADE,
+    rules jsonb NOT NULL DEFAULT '[]',
+    created_by uuid REFERENCES users(user_id) ON DELETE SET NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT timezone('utc', now()),
+    updated_at timestamp with time zone NOT NULL DEFAULT timezone('utc', now())
+);
+CREATE INDEX IF NOT EXISTS idx_admission_policies_target ON admission_policies (target);
+
+-- +goose Down
+DROP TABLE IF EXISTS admission_policies;
@@ -0,0 +1,41 @@
+-- +goose Up
+-- SCIM 2.0 provisioning. A deprovisioned user keeps their users row (jobs,
+-- projects and audit entries still point at it) but is deactivated: they
+-- can't log in and their API tokens are off.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deactivated_at timestamp with time zone;
+
+-- scim_users links the users an identity provider manages to its userName
+-- (stored lowercased; SCIM userNames are case-insensitive) and externalId.
+CREATE TABLE IF NOT EXISTS scim_users (
+    user_id uuid PRIMARY KEY REFERENCES users(user_id) ON DELETE CASCADE,
+    user_name text NOT NULL UNIQUE,
+    external_id text NOT NULL DEFAULT '',
+    display_name text NOT NULL DEFAULT '',
+    given_name text NOT NULL DEFAULT '',
+    family_name text NOT NULL DEFAULT '',
+    created_at timestamp with time zone NOT NULL DEFAULT timezone('utc', now()),
+    updated_at timestamp with time zone NOT NULL DEFAULT timezone('utc', now())
+);
+
+-- scim_groups are the identity provider's groups; members get the user
+-- roles REACTORCIDE_SCIM_GROUP_ROLES maps their display_name to.
+CREATE TABLE IF NOT EXISTS scim_groups (
+    group_id uuid PRIMARY KEY DEFAULT generate_ulid(),
+    display_name text NOT NULL UNIQUE,
+    external_id text NOT NULL DEFAULT '',
+    created_at timestamp with time zone NOT NULL DEFAULT timezone('utc', now()),
+    updated_at timestamp with time zone NOT NULL DEFAULT timezone('utc', now())
+);
+
+CREATE TABLE IF NOT EXISTS scim_group_members (
+    group_id uuid NOT NULL REFERENCES scim_groups(group_id) ON DELETE CASCADE,
+    user_id uuid NOT NULL REFERENCES scim_users(user_id) ON DELETE CASCADE,
+    PRIMARY KEY (group_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_scim_group_members_user ON scim_group_members (user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS scim_group_members;
+DROP TABLE IF EXISTS scim_groups;
+DROP TABLE IF EXISTS scim_users;
+ALTER TABLE users DROP COLUMN IF EXISTS deactivated_at;
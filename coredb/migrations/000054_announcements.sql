@@ -0,0 +1,18 @@
+-- +goose Up
+-- Announcements: admin-written messages, e.g. of planned maintenance, shown
+-- to every API client between starts_at and ends_at (either open-ended).
+CREATE TABLE IF NOT EXISTS announcements (
+    announcement_id uuid PRIMARY KEY DEFAULT generate_ulid(),
+    message text NOT NULL,
+    severity text NOT NULL DEFAULT 'info' CHECK (severity IN ('info', 'warning', 'critical')),
+    starts_at timestamp with time zone,
+    ends_at timestamp with time zone,
+    created_by uuid REFERENCES users(user_id) ON DELETE SET NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT timezone('utc', now()),
+    updated_at timestamp with time zone NOT NULL DEFAULT timezone('utc', now()),
+    CHECK (starts_at IS NULL OR ends_at IS NULL OR starts_at < ends_at)
+);
+CREATE INDEX IF NOT EXISTS idx_announcements_ends_at ON announcements (ends_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS announcements;
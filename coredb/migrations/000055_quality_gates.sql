@@ -0,0 +1,14 @@
+-- +goose Up
+-- Quality gates: thresholds on the numeric outputs a job reports (coverage,
+-- bundle size, vulnerability counts) that fail the job even when its
+-- command exits 0. projects.quality_gates holds the project's gates and
+-- jobs.gate_results how a job's outputs fared against them.
+ALTER TABLE projects ADD COLUMN quality_gates jsonb NOT NULL DEFAULT '[]';
+ALTER TABLE jobs ADD COLUMN gate_results jsonb;
+-- jobs_archive keeps jobs' columns, in order (see 000045_job_archive.sql).
+ALTER TABLE jobs_archive ADD COLUMN gate_results jsonb;
+
+-- +goose Down
+ALTER TABLE jobs_archive DROP COLUMN IF EXISTS gate_results;
+ALTER TABLE jobs DROP COLUMN IF EXISTS gate_results;
+ALTER TABLE projects DROP COLUMN IF EXISTS quality_gates;
@@ -0,0 +1,29 @@
+-- +goose Up
+-- Built-in scan jobs: jobs.scan holds a scan job's scanner and target,
+-- scan_findings the vulnerabilities it found, and projects.scan_thresholds
+-- the most findings of each severity the project's scan jobs may have.
+ALTER TABLE jobs ADD COLUMN scan jsonb;
+-- jobs_archive keeps jobs' columns, in order (see 000045_job_archive.sql).
+ALTER TABLE jobs_archive ADD COLUMN scan jsonb;
+ALTER TABLE projects ADD COLUMN scan_thresholds jsonb NOT NULL DEFAULT '{}';
+
+-- No foreign key to jobs: findings stay with a job moved to jobs_archive.
+CREATE TABLE IF NOT EXISTS scan_findings (
+    finding_id uuid PRIMARY KEY DEFAULT generate_ulid(),
+    job_id uuid NOT NULL,
+    vulnerability_id text NOT NULL,
+    severity text NOT NULL CHECK (severity IN ('critical', 'high', 'medium', 'low', 'unknown')),
+    package text NOT NULL DEFAULT '',
+    installed_version text NOT NULL DEFAULT '',
+    fixed_version text NOT NULL DEFAULT '',
+    target text NOT NULL DEFAULT '',
+    title text NOT NULL DEFAULT '',
+    created_at timestamp NOT NULL DEFAULT timezone('utc', now())
+);
+CREATE INDEX IF NOT EXISTS idx_scan_findings_job ON scan_findings (job_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS scan_findings;
+ALTER TABLE projects DROP COLUMN IF EXISTS scan_thresholds;
+ALTER TABLE jobs_archive DROP COLUMN IF EXISTS scan;
+ALTER TABLE jobs DROP COLUMN IF EXISTS scan;
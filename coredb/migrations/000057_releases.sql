@@ -0,0 +1,32 @@
+-- +goose Up
+-- Releases: a project with release_config.enabled publishes a VCS release for
+-- each tag_created event once the tag's pipeline succeeds. A release row
+-- links the tag to the pipeline's jobs and the artifacts attached.
+ALTER TABLE projects ADD COLUMN release_config jsonb NOT NULL DEFAULT '{}';
+
+CREATE TABLE IF NOT EXISTS releases (
+    release_id uuid PRIMARY KEY DEFAULT generate_ulid(),
+    project_id uuid NOT NULL REFERENCES projects(project_id) ON DELETE CASCADE,
+    tag text NOT NULL,
+    commit_sha text NOT NULL DEFAULT '',
+    previous_tag text NOT NULL DEFAULT '',
+    provider text NOT NULL,
+    repo text NOT NULL,
+    -- No foreign key: the eval job may move to jobs_archive.
+    eval_job_id uuid NOT NULL,
+    status text NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'publishing', 'published', 'failed')),
+    changelog text NOT NULL DEFAULT '',
+    job_ids text[] NOT NULL DEFAULT '{}',
+    artifacts jsonb NOT NULL DEFAULT '[]',
+    vcs_release_id text NOT NULL DEFAULT '',
+    url text NOT NULL DEFAULT '',
+    last_error text NOT NULL DEFAULT '',
+    created_at timestamp NOT NULL DEFAULT timezone('utc', now()),
+    published_at timestamp,
+    UNIQUE (project_id, tag)
+);
+CREATE INDEX IF NOT EXISTS idx_releases_eval_job ON releases (eval_job_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS releases;
+ALTER TABLE projects DROP COLUMN IF EXISTS release_config;
@@ -0,0 +1,34 @@
+-- +goose Up
+-- Environment promotions: a completed deploy job (one with an environment)
+-- is rerun against another environment once enough approvals are in.
+-- projects.promotion_rules says, per target environment, which environments
+-- may be promoted to it and how many approvals it takes. A promotion row
+-- records the artifact digest it carries, so a digest's path through the
+-- environments can be traced.
+ALTER TABLE projects ADD COLUMN promotion_rules jsonb NOT NULL DEFAULT '{}';
+
+CREATE TABLE IF NOT EXISTS promotions (
+    promotion_id uuid PRIMARY KEY DEFAULT generate_ulid(),
+    project_id uuid NOT NULL REFERENCES projects(project_id) ON DELETE CASCADE,
+    -- No foreign keys on the jobs: they may move to jobs_archive.
+    source_job_id uuid NOT NULL,
+    job_id uuid,
+    from_environment text NOT NULL,
+    to_environment text NOT NULL,
+    artifact_digest text NOT NULL,
+    status text NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'rejected', 'promoted', 'failed')),
+    approvals_required integer NOT NULL DEFAULT 1,
+    approvals jsonb NOT NULL DEFAULT '[]',
+    requested_by uuid NOT NULL,
+    decided_by uuid,
+    reason text NOT NULL DEFAULT '',
+    last_error text NOT NULL DEFAULT '',
+    created_at timestamp NOT NULL DEFAULT timezone('utc', now()),
+    decided_at timestamp
+);
+CREATE INDEX IF NOT EXISTS idx_promotions_project_digest ON promotions (project_id, artifact_digest, created_at);
+CREATE INDEX IF NOT EXISTS idx_promotions_job ON promotions (job_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS promotions;
+ALTER TABLE projects DROP COLUMN IF EXISTS promotion_rules;
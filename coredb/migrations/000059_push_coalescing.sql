@@ -0,0 +1,13 @@
+-- +goose Up
+-- Push coalescing: a push to a branch cancels the still-running pipelines
+-- of the project's pushes to it in the last coalesce_window_seconds, and
+-- the new eval job records their commits in coalesced_shas.
+ALTER TABLE projects ADD COLUMN coalesce_window_seconds integer NOT NULL DEFAULT 0;
+ALTER TABLE jobs ADD COLUMN coalesced_shas text[];
+-- jobs_archive keeps jobs' columns, in order (see 000045_job_archive.sql).
+ALTER TABLE jobs_archive ADD COLUMN coalesced_shas text[];
+
+-- +goose Down
+ALTER TABLE jobs_archive DROP COLUMN IF EXISTS coalesced_shas;
+ALTER TABLE jobs DROP COLUMN IF EXISTS coalesced_shas;
+ALTER TABLE projects DROP COLUMN IF EXISTS coalesce_window_seconds;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Commit status context naming: status_context_template names the statuses
+-- of a project's jobs, and status_context_aliases maps old contexts to the
+-- ones replacing them, which are reported under both until branch
+-- protection rules are moved over.
+ALTER TABLE projects ADD COLUMN status_context_template text NOT NULL DEFAULT '';
+ALTER TABLE projects ADD COLUMN status_context_aliases jsonb NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE projects DROP COLUMN IF EXISTS status_context_aliases;
+ALTER TABLE projects DROP COLUMN IF EXISTS status_context_template;
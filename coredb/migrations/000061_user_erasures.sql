@@ -0,0 +1,16 @@
+-- +goose Up
+-- User erasures: the report of each time an admin anonymized or deleted a
+-- user. No foreign key, since a deleted user's report outlives it; the
+-- report names no personal data, only what was done.
+CREATE TABLE IF NOT EXISTS user_erasures (
+    erasure_id uuid PRIMARY KEY DEFAULT generate_ulid(),
+    user_id uuid NOT NULL,
+    mode text NOT NULL CHECK (mode IN ('anonymize', 'delete')),
+    requested_by uuid,
+    report jsonb NOT NULL DEFAULT '{}',
+    created_at timestamp NOT NULL DEFAULT timezone('utc', now())
+);
+CREATE INDEX IF NOT EXISTS idx_user_erasures_user ON user_erasures (user_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS user_erasures;
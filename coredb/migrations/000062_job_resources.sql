@@ -0,0 +1,10 @@
+-- +goose Up
+-- Job resource metrics: the CPU time, peak memory and network bytes the
+-- worker measured for a job's container, when it has them enabled.
+ALTER TABLE jobs ADD COLUMN resources jsonb;
+-- jobs_archive keeps jobs' columns, in order (see 000045_job_archive.sql).
+ALTER TABLE jobs_archive ADD COLUMN resources jsonb;
+
+-- +goose Down
+ALTER TABLE jobs_archive DROP COLUMN IF EXISTS resources;
+ALTER TABLE jobs DROP COLUMN IF EXISTS resources;
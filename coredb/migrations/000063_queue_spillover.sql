@@ -0,0 +1,13 @@
+-- +goose Up
+-- Queue spillover: a project's jobs go to its default queue, or to the
+-- first of its spillover_queues with a free worker when the default queue
+-- has none; such jobs are queue_routed.
+ALTER TABLE projects ADD COLUMN spillover_queues text[];
+ALTER TABLE jobs ADD COLUMN queue_routed boolean NOT NULL DEFAULT false;
+-- jobs_archive keeps jobs' columns, in order (see 000045_job_archive.sql).
+ALTER TABLE jobs_archive ADD COLUMN queue_routed boolean NOT NULL DEFAULT false;
+
+-- +goose Down
+ALTER TABLE jobs_archive DROP COLUMN IF EXISTS queue_routed;
+ALTER TABLE jobs DROP COLUMN IF EXISTS queue_routed;
+ALTER TABLE projects DROP COLUMN IF EXISTS spillover_queues;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Eval-less projects: a project with job_templates runs them directly for
+-- its VCS events instead of creating an eval job.
+ALTER TABLE projects ADD COLUMN job_templates jsonb NOT NULL DEFAULT '[]';
+
+-- +goose Down
+ALTER TABLE projects DROP COLUMN IF EXISTS job_templates;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Job boosts: who last raised a queued job's priority through
+-- POST /api/v1/jobs/{id}/boost, and when.
+ALTER TABLE jobs ADD COLUMN boosted_by text;
+ALTER TABLE jobs ADD COLUMN boosted_at timestamptz;
+-- jobs_archive keeps jobs' columns, in order (see 000045_job_archive.sql).
+ALTER TABLE jobs_archive ADD COLUMN boosted_by text;
+ALTER TABLE jobs_archive ADD COLUMN boosted_at timestamptz;
+
+-- +goose Down
+ALTER TABLE jobs_archive DROP COLUMN IF EXISTS boosted_at;
+ALTER TABLE jobs_archive DROP COLUMN IF EXISTS boosted_by;
+ALTER TABLE jobs DROP COLUMN IF EXISTS boosted_at;
+ALTER TABLE jobs DROP COLUMN IF EXISTS boosted_by;
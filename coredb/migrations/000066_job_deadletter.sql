@@ -0,0 +1,48 @@
+-- +goose Up
+-- Job deadletter. Workers count each claim of a job in delivery_attempts;
+-- a claim that ends without a result (the worker lost mid-run, or a panic)
+-- is recorded in job_delivery_failures and the job goes back on its queue.
+-- Once a job has used up its attempts the claiming worker moves it to
+-- 'deadlettered' instead of running it, until an admin requeues or fails it.
+
+ALTER TABLE jobs DROP CONSTRAINT jobs_status_check;
+ALTER TABLE jobs ADD CONSTRAINT jobs_status_check CHECK (status IN (
+    'held', 'submitted', 'queued', 'running', 'cancelling', 'completed', 'failed', 'cancelled', 'timeout', 'deadlettered'
+));
+
+ALTER TABLE jobs ADD COLUMN delivery_attempts integer NOT NULL DEFAULT 0;
+ALTER TABLE jobs ADD COLUMN deadlettered_at timestamptz;
+-- jobs_archive keeps jobs' columns, in order (see 000045_job_archive.sql).
+ALTER TABLE jobs_archive ADD COLUMN delivery_attempts integer NOT NULL DEFAULT 0;
+ALTER TABLE jobs_archive ADD COLUMN deadlettered_at timestamptz;
+
+CREATE INDEX jobs_deadlettered_at_idx ON jobs(deadlettered_at) WHERE status = 'deadlettered';
+
+-- No foreign key to jobs, so a job's failure history survives archiving.
+CREATE TABLE job_delivery_failures (
+    failure_id uuid PRIMARY KEY DEFAULT generate_ulid(),
+    job_id uuid NOT NULL,
+    attempt integer NOT NULL,
+    worker_id text NOT NULL DEFAULT '',
+    reason text NOT NULL CHECK (reason IN ('worker_lost', 'worker_panic')),
+    detail text NOT NULL DEFAULT '',
+    failed_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX job_delivery_failures_job_id_idx ON job_delivery_failures(job_id, failed_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS job_delivery_failures;
+
+DROP INDEX IF EXISTS jobs_deadlettered_at_idx;
+
+ALTER TABLE jobs_archive DROP COLUMN IF EXISTS deadlettered_at;
+ALTER TABLE jobs_archive DROP COLUMN IF EXISTS delivery_attempts;
+ALTER TABLE jobs DROP COLUMN IF EXISTS deadlettered_at;
+ALTER TABLE jobs DROP COLUMN IF EXISTS delivery_attempts;
+
+UPDATE jobs SET status = 'failed', last_error = 'deadlettered' WHERE status = 'deadlettered';
+ALTER TABLE jobs DROP CONSTRAINT jobs_status_check;
+ALTER TABLE jobs ADD CONSTRAINT jobs_status_check CHECK (status IN (
+    'held', 'submitted', 'queued', 'running', 'cancelling', 'completed', 'failed', 'cancelled', 'timeout'
+));
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Uploaded sources: jobs created by `reactorcide run` check out a tarball
+-- the CLI uploaded to the object store (see POST /api/v1/sources). The
+-- object key is kept in source_path.
+ALTER TYPE source_type ADD VALUE IF NOT EXISTS 'upload';
+
+-- +goose Down
+-- Note: Cannot easily remove enum values in PostgreSQL, so 'upload' stays;
+-- jobs using it are left without a source.
+UPDATE jobs SET source_type = 'none' WHERE source_type = 'upload';
+UPDATE jobs_archive SET source_type = 'none' WHERE source_type = 'upload';
@@ -0,0 +1,11 @@
+-- +goose Up
+-- OCI sources: the job's source is an image or artifact the worker pulls
+-- and extracts into the code dir. source_url holds the reference and
+-- source_ref, if set, the digest it's pinned to.
+ALTER TYPE source_type ADD VALUE IF NOT EXISTS 'oci';
+
+-- +goose Down
+-- Note: Cannot easily remove enum values in PostgreSQL, so 'oci' stays;
+-- jobs using it are left without a source.
+UPDATE jobs SET source_type = 'none' WHERE source_type = 'oci';
+UPDATE jobs_archive SET source_type = 'none' WHERE source_type = 'oci';
@@ -0,0 +1,39 @@
+-- +goose Up
+-- Projects created with a clone URL (https://github.com/org/repo.git,
+-- git@github.com:org/repo.git) rather than the canonical
+-- github.com/org/repo were never found by webhook lookups. The API now
+-- normalizes repo_url on write; this brings existing rows into line.
+-- The scheme, credentials, scp-style "git@host:" form and trailing .git
+-- and slashes are dropped, and the host is lowercased. A row whose
+-- canonical form another project on the same config path already has, or
+-- would have, is left as it is: resolve those by hand.
+WITH stripped AS (
+    SELECT project_id, config_path,
+           regexp_replace(
+               regexp_replace(
+                   regexp_replace(btrim(repo_url), '^[A-Za-z][A-Za-z0-9+.-]*://([^/@]*@)?', ''),
+                   '^[^/@:]+@([^/:]+):', '\1/'),
+               '(/|\.git)+$', '') AS url
+    FROM projects
+    WHERE repo_url NOT LIKE '/%'
+),
+normalized AS (
+    SELECT project_id, config_path,
+           lower(split_part(url, '/', 1)) || substr(url, length(split_part(url, '/', 1)) + 1) AS repo_url
+    FROM stripped
+)
+UPDATE projects p
+SET repo_url = n.repo_url, updated_at = timezone('utc', now())
+FROM normalized n
+WHERE p.project_id = n.project_id
+  AND p.repo_url <> n.repo_url
+  AND n.repo_url <> ''
+  AND NOT EXISTS (
+      SELECT 1 FROM normalized o
+      WHERE o.project_id <> n.project_id
+        AND o.config_path = n.config_path
+        AND o.repo_url = n.repo_url
+  );
+
+-- +goose Down
+-- The original URLs aren't kept; canonical ones work with the old code.
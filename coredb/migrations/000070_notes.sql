@@ -0,0 +1,19 @@
+-- +goose Up
+-- Notes: markdown annotations on a job or a project, e.g. on-call's "known
+-- infra outage, ignore failures". List responses show each one's latest.
+-- No foreign key to jobs, so a job's notes survive archiving.
+CREATE TABLE IF NOT EXISTS notes (
+    note_id uuid PRIMARY KEY DEFAULT generate_ulid(),
+    job_id uuid,
+    project_id uuid REFERENCES projects(project_id) ON DELETE CASCADE,
+    author_id uuid REFERENCES users(user_id) ON DELETE SET NULL,
+    body text NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT timezone('utc', now()),
+    updated_at timestamp with time zone NOT NULL DEFAULT timezone('utc', now()),
+    CHECK ((job_id IS NULL) <> (project_id IS NULL))
+);
+CREATE INDEX IF NOT EXISTS idx_notes_job_id ON notes (job_id, created_at) WHERE job_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_notes_project_id ON notes (project_id, created_at) WHERE project_id IS NOT NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS notes;
@@ -0,0 +1,32 @@
+-- +goose Up
+-- Flaky test quarantine. test_results keeps each test's outcome in the
+-- JUnit reports a project's jobs write, for pass rates; test_quarantines
+-- the tests whose failures don't fail a project's jobs until expires_at.
+-- No foreign key to jobs: results stay with a job moved to jobs_archive.
+CREATE TABLE IF NOT EXISTS test_results (
+    result_id uuid PRIMARY KEY DEFAULT generate_ulid(),
+    job_id uuid NOT NULL,
+    project_id uuid NOT NULL REFERENCES projects(project_id) ON DELETE CASCADE,
+    test_name text NOT NULL,
+    status text NOT NULL CHECK (status IN ('passed', 'failed', 'skipped')),
+    created_at timestamp with time zone NOT NULL DEFAULT timezone('utc', now())
+);
+CREATE INDEX IF NOT EXISTS idx_test_results_job ON test_results (job_id);
+CREATE INDEX IF NOT EXISTS idx_test_results_project_test ON test_results (project_id, test_name, created_at);
+CREATE INDEX IF NOT EXISTS idx_test_results_project_created ON test_results (project_id, created_at);
+
+CREATE TABLE IF NOT EXISTS test_quarantines (
+    quarantine_id uuid PRIMARY KEY DEFAULT generate_ulid(),
+    project_id uuid NOT NULL REFERENCES projects(project_id) ON DELETE CASCADE,
+    test_name text NOT NULL,
+    reason text NOT NULL DEFAULT '',
+    expires_at timestamp with time zone NOT NULL,
+    created_by uuid REFERENCES users(user_id) ON DELETE SET NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT timezone('utc', now()),
+    updated_at timestamp with time zone NOT NULL DEFAULT timezone('utc', now()),
+    UNIQUE (project_id, test_name)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS test_quarantines;
+DROP TABLE IF EXISTS test_results;
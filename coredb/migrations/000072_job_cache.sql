@@ -0,0 +1,10 @@
+-- +goose Up
+-- Job caches: a named cache a job keeps between runs, shared with the
+-- other jobs of its project that name it.
+ALTER TABLE jobs ADD COLUMN cache text NOT NULL DEFAULT '';
+-- jobs_archive keeps jobs' columns, in order (see 000045_job_archive.sql).
+ALTER TABLE jobs_archive ADD COLUMN cache text NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE jobs_archive DROP COLUMN IF EXISTS cache;
+ALTER TABLE jobs DROP COLUMN IF EXISTS cache;
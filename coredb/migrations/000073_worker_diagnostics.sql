@@ -0,0 +1,12 @@
+-- +goose Up
+-- Worker diagnostics: the report each worker sends with its heartbeat (the
+-- jobs it runs, its executor and image cache, its disk, and its own recent
+-- log lines), for the admin API. Only the latest report is kept.
+CREATE TABLE worker_diagnostics (
+    worker_id text PRIMARY KEY REFERENCES workers(worker_id) ON DELETE CASCADE,
+    report jsonb NOT NULL,
+    reported_at timestamp with time zone NOT NULL DEFAULT timezone('utc', now())
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS worker_diagnostics;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Canaries: a project's canary routes a share of its new jobs to a new
+-- runner image or worker queue. Those jobs are marked canary so they can
+-- be compared with the project's other jobs.
+ALTER TABLE projects ADD COLUMN canary jsonb;
+ALTER TABLE jobs ADD COLUMN canary boolean NOT NULL DEFAULT false;
+-- jobs_archive keeps jobs' columns, in order (see 000045_job_archive.sql).
+ALTER TABLE jobs_archive ADD COLUMN canary boolean NOT NULL DEFAULT false;
+
+-- +goose Down
+ALTER TABLE jobs_archive DROP COLUMN IF EXISTS canary;
+ALTER TABLE jobs DROP COLUMN IF EXISTS canary;
+ALTER TABLE projects DROP COLUMN IF EXISTS canary;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Deploy tokens: an API token bound to one project can only run that
+-- project and read its jobs and their logs.
+ALTER TABLE api_tokens ADD COLUMN project_id uuid REFERENCES projects(project_id) ON DELETE CASCADE;
+CREATE INDEX api_tokens_project_id_idx ON api_tokens(project_id) WHERE project_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS api_tokens_project_id_idx;
+ALTER TABLE api_tokens DROP COLUMN IF EXISTS project_id;
@@ -0,0 +1,29 @@
+-- +goose Up
+-- Soft delete: deleting a project or job sets deleted_at and leaves the
+-- row in the trash, from which it can be restored until it is purged.
+ALTER TABLE projects ADD COLUMN deleted_at timestamp;
+ALTER TABLE jobs ADD COLUMN deleted_at timestamp;
+-- jobs_archive keeps jobs' columns, in order (see 000045_job_archive.sql).
+ALTER TABLE jobs_archive ADD COLUMN deleted_at timestamp;
+
+CREATE INDEX projects_deleted_at_idx ON projects(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX jobs_deleted_at_idx ON jobs(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX jobs_archive_deleted_at_idx ON jobs_archive(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- A project in the trash doesn't keep its repository and config path from
+-- a new project.
+DROP INDEX IF EXISTS projects_repo_url_config_path_key;
+CREATE UNIQUE INDEX projects_repo_url_config_path_key ON projects(repo_url, config_path) WHERE deleted_at IS NULL;
+
+-- +goose Down
+DELETE FROM jobs_archive WHERE deleted_at IS NOT NULL;
+DELETE FROM jobs WHERE deleted_at IS NOT NULL;
+DELETE FROM projects WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS projects_repo_url_config_path_key;
+CREATE UNIQUE INDEX projects_repo_url_config_path_key ON projects(repo_url, config_path);
+DROP INDEX IF EXISTS jobs_archive_deleted_at_idx;
+DROP INDEX IF EXISTS jobs_deleted_at_idx;
+DROP INDEX IF EXISTS projects_deleted_at_idx;
+ALTER TABLE jobs_archive DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE jobs DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE projects DROP COLUMN IF EXISTS deleted_at;
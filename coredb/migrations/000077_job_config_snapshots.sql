@@ -0,0 +1,12 @@
+-- +goose Up
+-- Job config snapshots: the fully resolved configuration each job ran with,
+-- written once by the worker when it starts the job. No foreign key to
+-- jobs, so a job's snapshot survives archiving.
+CREATE TABLE IF NOT EXISTS job_config_snapshots (
+    job_id uuid PRIMARY KEY,
+    config jsonb NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT timezone('utc', now())
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS job_config_snapshots;
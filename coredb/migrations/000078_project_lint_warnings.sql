@@ -0,0 +1,7 @@
+-- +goose Up
+-- The warnings the last create or update of a project found in its
+-- configuration, kept for the UI.
+ALTER TABLE projects ADD COLUMN lint_warnings jsonb NOT NULL DEFAULT '[]';
+
+-- +goose Down
+ALTER TABLE projects DROP COLUMN IF EXISTS lint_warnings;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- The activity feed (GET /api/v1/activity) reads recent job starts and
+-- failures newest first.
+CREATE INDEX IF NOT EXISTS jobs_started_at_idx ON jobs(started_at DESC) WHERE started_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS jobs_failed_completed_at_idx ON jobs(completed_at DESC) WHERE status IN ('failed', 'timeout');
+
+-- +goose Down
+DROP INDEX IF EXISTS jobs_failed_completed_at_idx;
+DROP INDEX IF EXISTS jobs_started_at_idx;
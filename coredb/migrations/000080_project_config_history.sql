@@ -0,0 +1,21 @@
+-- +goose Up
+-- Project config history: a numbered snapshot of a project's configuration
+-- after each create, update and revert, with who made it and the fields it
+-- changed. Purging the project purges its history.
+CREATE TABLE IF NOT EXISTS project_config_history (
+    history_id uuid PRIMARY KEY DEFAULT generate_ulid(),
+    project_id uuid NOT NULL REFERENCES projects(project_id) ON DELETE CASCADE,
+    version integer NOT NULL,
+    action text NOT NULL,
+    actor_id uuid REFERENCES users(user_id) ON DELETE SET NULL,
+    reverted_to integer,
+    config jsonb NOT NULL,
+    changes jsonb NOT NULL DEFAULT '[]',
+    changed_at timestamp with time zone NOT NULL DEFAULT timezone('utc', now()),
+    UNIQUE (project_id, version)
+);
+
+CREATE INDEX IF NOT EXISTS project_config_history_changed_at_idx ON project_config_history(changed_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS project_config_history;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Artifact publishing: once a job completes, the worker pushes the job's
+-- artifacts to the external destinations in its project's
+-- artifact_publish_targets (S3 buckets, HTTP PUT URLs, OCI registries).
+-- jobs.published_artifacts records where each artifact went, or why it
+-- didn't.
+ALTER TABLE projects ADD COLUMN artifact_publish_targets jsonb NOT NULL DEFAULT '[]';
+ALTER TABLE jobs ADD COLUMN published_artifacts jsonb NOT NULL DEFAULT '[]';
+-- jobs_archive keeps jobs' columns, in order (see 000045_job_archive.sql).
+ALTER TABLE jobs_archive ADD COLUMN published_artifacts jsonb NOT NULL DEFAULT '[]';
+
+-- +goose Down
+ALTER TABLE jobs_archive DROP COLUMN IF EXISTS published_artifacts;
+ALTER TABLE jobs DROP COLUMN IF EXISTS published_artifacts;
+ALTER TABLE projects DROP COLUMN IF EXISTS artifact_publish_targets;
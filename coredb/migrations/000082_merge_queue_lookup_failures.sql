@@ -0,0 +1,8 @@
+-- +goose Up
+-- Consecutive failures to load a merge queue entry's pull request from its
+-- provider. The entry is failed once they reach the merge queue's limit, so
+-- one PR that can't be loaded doesn't stall its project's queue.
+ALTER TABLE merge_queue_entries ADD COLUMN lookup_failures integer NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE merge_queue_entries DROP COLUMN IF EXISTS lookup_failures;
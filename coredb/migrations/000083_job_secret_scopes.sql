@@ -0,0 +1,12 @@
+-- +goose Up
+-- The scopes of the API token a job was submitted with. The job's secret
+-- references are resolved under the submitter's secret access policies,
+-- limited to these scopes, so a scoped token can't reach secrets outside
+-- its scopes by submitting a job. Jobs derived from a job keep its scopes.
+ALTER TABLE jobs ADD COLUMN secret_scopes text[] NOT NULL DEFAULT '{}';
+-- jobs_archive keeps jobs' columns, in order (see 000045_job_archive.sql).
+ALTER TABLE jobs_archive ADD COLUMN secret_scopes text[] NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE jobs_archive DROP COLUMN IF EXISTS secret_scopes;
+ALTER TABLE jobs DROP COLUMN IF EXISTS secret_scopes;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- How processing a webhook event went for each of its projects, keyed by
+-- project id. A retry or redelivery processes only the projects that
+-- failed, so the jobs built for the others aren't built twice. The archive
+-- table gets the same column in the same position.
+ALTER TABLE webhook_events ADD COLUMN project_outcomes jsonb;
+ALTER TABLE webhook_events_archive ADD COLUMN project_outcomes jsonb;
+
+-- +goose Down
+ALTER TABLE webhook_events_archive DROP COLUMN IF EXISTS project_outcomes;
+ALTER TABLE webhook_events DROP COLUMN IF EXISTS project_outcomes;
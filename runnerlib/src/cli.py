@@ -861,6 +861,10 @@ def eval_cmd(
     base_url: str = typer.Option("", envvar="REACTORCIDE_BASE_URL", help="PR base/upstream repository URL"),
     base_ref: str = typer.Option("", envvar="REACTORCIDE_BASE_REF", help="PR base branch name"),
     is_fork_pr: str = typer.Option("", envvar="REACTORCIDE_IS_FORK_PR", help="Set to 'true' when PR is cross-repository"),
+    config_path: str = typer.Option("", envvar="REACTORCIDE_CONFIG_PATH", help="Directory within the CI source holding the project's .reactorcide config"),
+    path_filters: str = typer.Option("", envvar="REACTORCIDE_PATH_FILTERS", help="Comma-separated path patterns; skip the event unless a changed file matches"),
+    jobs: str = typer.Option("", envvar="REACTORCIDE_JOBS", help="Comma-separated job names; trigger only these of the matched jobs"),
+    run_params: str = typer.Option("", envvar="REACTORCIDE_RUN_PARAMS", help="Comma-separated environment variables to pass on to triggered jobs (manual runs)"),
     triggers_file: str = typer.Option("/job/triggers.json", help="Path to write triggers output"),
 ):
     """Evaluate job definitions against an event and generate triggers.
@@ -874,6 +878,7 @@ def eval_cmd(
         load_job_definitions,
         evaluate_event,
         generate_triggers,
+        project_paths_match,
         EventContext,
         VALID_EVENT_TYPES,
     )
@@ -891,7 +896,7 @@ def eval_cmd(
     # Prepare CI source if not already present.
     # When running as an eval job, the coordinator passes CI source info via env vars
     # but doesn't pre-clone the repository — the eval command needs to do it.
-    if ci_source_url and not (ci_source_path / ".reactorcide" / "jobs").is_dir():
+    if ci_source_url and not (ci_source_path / config_path / ".reactorcide" / "jobs").is_dir():
         log_stdout(f"CI source not found at {ci_source_path}, cloning from {ci_source_url}")
         from src.source_prep import _prepare_git_source
         _prepare_git_source(ci_source_url, ci_source_ref or None, ci_source_path)
@@ -905,7 +910,7 @@ def eval_cmd(
 
     # Load job definitions
     log_stdout(f"Loading job definitions from {ci_source_path}")
-    definitions = load_job_definitions(ci_source_path)
+    definitions = load_job_definitions(ci_source_path, config_path)
 
     if not definitions:
         log_stdout("No job definitions found, nothing to evaluate")
@@ -913,9 +918,10 @@ def eval_cmd(
 
     log_stdout(f"Loaded {len(definitions)} job definition(s)")
 
-    # Get changed files via git diff if source dir is a git repo
+    # Get changed files via git diff if source dir is a git repo. A manual
+    # run isn't about a change, so path triggers don't apply to it.
     changed = None
-    if source_path.exists() and (source_path / ".git").exists():
+    if event_type != "manual" and source_path.exists() and (source_path / ".git").exists():
         try:
             if diff_base:
                 # Use the stable base SHA (works correctly even after merge)
@@ -929,9 +935,24 @@ def eval_cmd(
         except Exception as e:
             log_stderr(f"Warning: could not determine changed files: {e}")
 
+    # A project sharing a monorepo only builds changes under its paths
+    filters = [p.strip() for p in path_filters.split(",") if p.strip()]
+    if filters and changed is not None and not project_paths_match(filters, changed):
+        log_stdout("No changed files match the project's path filters, nothing to evaluate")
+        raise typer.Exit(0)
+
     # Evaluate definitions against event
     matched = evaluate_event(definitions, event_type, branch, changed)
 
+    # A manual run may select some of the matched jobs
+    selected = [j.strip() for j in jobs.split(",") if j.strip()]
+    if selected:
+        unknown = sorted(set(selected) - {d.name for d in matched})
+        if unknown:
+            log_stderr(f"Selected job(s) not triggered by '{event_type}' on this branch: {', '.join(unknown)}")
+            raise typer.Exit(1)
+        matched = [d for d in matched if d.name in selected]
+
     log_stdout(f"Matched {len(matched)} job(s) for event '{event_type}'")
 
     if not matched:
@@ -953,6 +974,11 @@ def eval_cmd(
         base_url=base_url,
         base_ref=base_ref,
         is_fork_pr=is_fork_pr,
+        params={
+            key: os.environ.get(key, "")
+            for key in (p.strip() for p in run_params.split(","))
+            if key and not key.startswith("REACTORCIDE_")
+        },
     )
 
     # Generate triggers
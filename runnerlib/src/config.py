@@ -18,7 +18,7 @@ class RunnerConfig:
     secrets_file: Optional[str] = None  # Path to secrets file to mount into container
 
     # Source code configuration (optional - for untrusted code from PRs, etc.)
-    source_type: Optional[str] = None  # git, copy, tarball, hg, svn, none
+    source_type: Optional[str] = None  # git, copy, tarball, hg, svn, upload, oci, none
     source_url: Optional[str] = None  # URL or path to source code
     source_ref: Optional[str] = None  # Branch, tag, commit, or version ref
 
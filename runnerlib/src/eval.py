@@ -32,6 +32,8 @@ VALID_EVENT_TYPES = frozenset({
     "pull_request_merged",
     "pull_request_closed",
     "tag_created",
+    "upstream_completed",
+    "manual",
 })
 
 
@@ -143,6 +145,8 @@ class EventContext:
             second remote (e.g. for `git log base..head` operations).
         base_ref: Target branch for PRs (== pr_base_ref; convenience).
         is_fork_pr: "true" when the PR head is on a different repo than base.
+        params: Parameters of a manual run, set in every triggered job's
+            environment over the definition's.
     """
     event_type: str = ""
     branch: str = ""
@@ -157,6 +161,7 @@ class EventContext:
     base_url: str = ""
     base_ref: str = ""
     is_fork_pr: str = ""
+    params: Dict[str, str] = field(default_factory=dict)
 
 
 def _parse_triggers_config(data: Any) -> TriggersConfig:
@@ -241,18 +246,22 @@ def parse_job_definition(data: Dict[str, Any], source_file: Optional[str] = None
     )
 
 
-def load_job_definitions(ci_source_path: Path) -> List[JobDefinition]:
+def load_job_definitions(ci_source_path: Path, config_path: str = "") -> List[JobDefinition]:
     """Load all job definitions from the CI source directory.
 
-    Reads all .yaml and .yml files from {ci_source_path}/.reactorcide/jobs/
+    Reads all .yaml and .yml files from
+    {ci_source_path}/{config_path}/.reactorcide/jobs/
 
     Args:
         ci_source_path: Path to the CI source checkout (e.g. /job/ci).
+        config_path: Directory within the CI source holding the project's
+            .reactorcide config, for projects sharing a monorepo. Empty is
+            the root.
 
     Returns:
         List of parsed JobDefinition instances.
     """
-    jobs_dir = ci_source_path / ".reactorcide" / "jobs"
+    jobs_dir = ci_source_path / config_path / ".reactorcide" / "jobs"
     if not jobs_dir.is_dir():
         print(f"No job definitions directory found at {jobs_dir}", file=sys.stderr)
         return []
@@ -383,6 +392,28 @@ def paths_match(paths_config: PathsConfig, changed_files: List[str]) -> bool:
     return False
 
 
+def project_paths_match(path_filters: List[str], changed_files: List[str]) -> bool:
+    """Check if changed files match a project's path filters.
+
+    Path filters (REACTORCIDE_PATH_FILTERS) use the same patterns as a job
+    definition's paths; a leading "!" excludes matching files.
+
+    Args:
+        path_filters: The project's path filter patterns.
+        changed_files: List of file paths that changed.
+
+    Returns:
+        True if any changed file matches the filters, or there are none.
+    """
+    return paths_match(
+        PathsConfig(
+            include=[p for p in path_filters if not p.startswith("!")],
+            exclude=[p[1:] for p in path_filters if p.startswith("!")],
+        ),
+        changed_files,
+    )
+
+
 def _glob_match_path(pattern: str, file_path: str) -> bool:
     """Match a file path against a glob pattern.
 
@@ -470,6 +501,7 @@ def generate_triggers(
     for defn in matched_definitions:
         # Build environment from definition + event context
         env = dict(defn.environment)
+        env.update(event_context.params)
         env["REACTORCIDE_EVENT_TYPE"] = event_context.event_type
         if event_context.branch:
             env["REACTORCIDE_BRANCH"] = event_context.branch
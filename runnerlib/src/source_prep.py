@@ -455,8 +455,15 @@ def _prepare_git_source(source_url: str, source_ref: Optional[str], target_path:
         shutil.rmtree(target_path)
 
     try:
-        # Clone the repository
-        repo = Repo.clone_from(source_url, target_path)
+        # Clone the repository, borrowing objects from the worker's mirror
+        # of it when there is one. The clone still fetches whatever the
+        # mirror lacks from source_url.
+        clone_kwargs = {}
+        reference = os.getenv("REACTORCIDE_GIT_REFERENCE")
+        if reference and Path(reference).is_dir():
+            logger.debug("Cloning with git mirror reference", fields={"reference": reference})
+            clone_kwargs["multi_options"] = ["--reference-if-able", reference]
+        repo = Repo.clone_from(source_url, target_path, **clone_kwargs)
 
         # Checkout specific ref if provided
         if source_ref:
@@ -662,6 +669,13 @@ def prepare_source(config: RunnerConfig) -> Optional[Path]:
             raise ValueError("source_url is required when source_type='tarball'")
         return _prepare_tarball_source(config.source_url, config.source_ref, target_path)
 
+    elif config.source_type in ('upload', 'oci'):
+        # The worker extracts uploaded and OCI sources into the code directory
+        # before the job starts; an empty one leaves it empty.
+        target_path.mkdir(parents=True, exist_ok=True)
+        log_stdout(f"ℹ️  Using {config.source_type} source at {target_path}")
+        return target_path
+
     elif config.source_type == 'hg':
         if not config.source_url:
             raise ValueError("source_url is required when source_type='hg'")
@@ -675,7 +689,7 @@ def prepare_source(config: RunnerConfig) -> Optional[Path]:
     else:
         raise ValueError(
             f"Invalid source_type: {config.source_type}. "
-            f"Supported types: git, copy, tarball, hg, svn, none"
+            f"Supported types: git, copy, tarball, hg, svn, upload, oci, none"
         )
 
 
@@ -22,6 +22,7 @@ from src.eval import (
     load_job_definitions,
     parse_job_definition,
     paths_match,
+    project_paths_match,
 )
 from src.workflow import JobTrigger
 
@@ -280,6 +281,18 @@ class TestLoadJobDefinitions:
 
         assert definitions == []
 
+    def test_load_from_config_path(self, temp_ci_dir):
+        """Test loading a monorepo project's definitions from its config path."""
+        ci_path, jobs_dir = temp_ci_dir
+        _write_yaml(jobs_dir / "root.yaml", {"name": "root"})
+        api_jobs = ci_path / "services" / "api" / ".reactorcide" / "jobs"
+        api_jobs.mkdir(parents=True)
+        _write_yaml(api_jobs / "api.yaml", {"name": "api"})
+
+        definitions = load_job_definitions(ci_path, "services/api")
+
+        assert [d.name for d in definitions] == ["api"]
+
     def test_load_empty_directory(self, temp_ci_dir):
         """Test loading from an empty jobs directory."""
         ci_path, _ = temp_ci_dir
@@ -1028,3 +1041,26 @@ class TestEndToEnd:
 
         assert len(matched) == 1
         assert matched[0].name == "release"
+
+
+# --- Test project_paths_match ---
+
+
+class TestProjectPathsMatch:
+    """Tests for project path filters (REACTORCIDE_PATH_FILTERS)."""
+
+    def test_include(self):
+        """Test that only changes under the project's paths match."""
+        assert project_paths_match(["services/api/**"], ["services/api/main.go"]) is True
+        assert project_paths_match(["services/api/**"], ["services/web/app.ts"]) is False
+
+    def test_negated_pattern_excludes(self):
+        """Test that a leading ! excludes files."""
+        filters = ["services/api/**", "!services/api/docs/**"]
+        assert project_paths_match(filters, ["services/api/docs/readme.md"]) is False
+        assert project_paths_match(filters, ["services/api/docs/readme.md", "services/api/main.go"]) is True
+
+    def test_only_negated(self):
+        """Test that only negated patterns match everything else."""
+        assert project_paths_match(["!*.md"], ["main.go"]) is True
+        assert project_paths_match(["!*.md"], ["README.md"]) is False
@@ -480,6 +480,83 @@ class TestEvalCommand:
         assert triggers_file.exists()
 
 
+class TestEvalManualRun:
+    """Tests for eval of manual runs (POST /api/v1/projects/{id}/run)."""
+
+    def _write_jobs(self, jobs_dir):
+        _write_yaml(jobs_dir / "deploy.yaml", {
+            "name": "deploy",
+            "triggers": {"events": ["manual"]},
+            "job": {"image": "alpine:latest", "command": "make deploy"},
+            "environment": {"TARGET": "staging", "REGION": "us-east-1"},
+        })
+        _write_yaml(jobs_dir / "build.yaml", {
+            "name": "build",
+            "triggers": {"events": ["manual", "push"]},
+            "job": {"image": "alpine:latest", "command": "make build"},
+        })
+        _write_yaml(jobs_dir / "lint.yaml", {
+            "name": "lint",
+            "triggers": {"events": ["push"]},
+            "job": {"image": "alpine:latest", "command": "make lint"},
+        })
+
+    def _eval(self, temp_dirs, env):
+        ci_dir, src_dir, _, triggers_file = temp_dirs
+        return runner.invoke(app, [
+            "eval",
+            "--ci-source-dir", str(ci_dir),
+            "--source-dir", str(src_dir),
+            "--event-type", "manual",
+            "--branch", "main",
+            "--triggers-file", str(triggers_file),
+        ], env=env)
+
+    def test_manual_run_triggers_manual_jobs(self, temp_dirs):
+        """Without a selection, every job triggered by manual events runs."""
+        _, _, jobs_dir, triggers_file = temp_dirs
+        self._write_jobs(jobs_dir)
+
+        result = self._eval(temp_dirs, {})
+
+        assert result.exit_code == 0
+        with open(triggers_file) as f:
+            data = json.load(f)
+        assert sorted(j["job_name"] for j in data["jobs"]) == ["build", "deploy"]
+
+    def test_manual_run_selected_jobs_and_params(self, temp_dirs):
+        """Selected jobs run with the run's parameters over their own env."""
+        _, _, jobs_dir, triggers_file = temp_dirs
+        self._write_jobs(jobs_dir)
+
+        result = self._eval(temp_dirs, {
+            "REACTORCIDE_JOBS": "deploy",
+            "REACTORCIDE_RUN_PARAMS": "TARGET,VERSION",
+            "TARGET": "production",
+            "VERSION": "1.2.3",
+        })
+
+        assert result.exit_code == 0
+        with open(triggers_file) as f:
+            data = json.load(f)
+        assert [j["job_name"] for j in data["jobs"]] == ["deploy"]
+        env = data["jobs"][0]["env"]
+        assert env["TARGET"] == "production"
+        assert env["VERSION"] == "1.2.3"
+        assert env["REGION"] == "us-east-1"
+        assert env["REACTORCIDE_EVENT_TYPE"] == "manual"
+
+    def test_manual_run_unknown_job(self, temp_dirs):
+        """Selecting a job manual events don't trigger fails the eval."""
+        _, _, jobs_dir, triggers_file = temp_dirs
+        self._write_jobs(jobs_dir)
+
+        result = self._eval(temp_dirs, {"REACTORCIDE_JOBS": "lint"})
+
+        assert result.exit_code == 1
+        assert not triggers_file.exists()
+
+
 class TestEvalSourcePreparation:
     """Tests for eval command source preparation (cloning CI/source repos)."""
 
@@ -139,6 +139,32 @@ class TestSourcePreparation:
         assert (result / "test.txt").exists()
         assert (result / "test.txt").read_text() == "test content"
 
+    def test_git_source_preparation_with_mirror_reference(self, monkeypatch):
+        """Test the clone borrows objects from REACTORCIDE_GIT_REFERENCE."""
+        test_repo_dir = Path(self.temp_dir) / "test_repo"
+        test_repo_dir.mkdir()
+        repo = _init_repo_with_main(test_repo_dir)
+        (test_repo_dir / "test.txt").write_text("mirrored")
+        repo.index.add(["test.txt"])
+        repo.index.commit("Initial commit")
+
+        mirror_dir = Path(self.temp_dir) / "mirror.git"
+        Repo.clone_from(str(test_repo_dir), mirror_dir, multi_options=["--mirror"])
+        monkeypatch.setenv("REACTORCIDE_GIT_REFERENCE", str(mirror_dir))
+
+        config = get_config(
+            job_command="cat /job/src/test.txt",
+            source_type="git",
+            source_url=str(test_repo_dir),
+            source_ref="main"
+        )
+
+        result = prepare_source(config)
+        assert (result / "test.txt").read_text() == "mirrored"
+        alternates = result / ".git" / "objects" / "info" / "alternates"
+        assert alternates.exists()
+        assert str(mirror_dir) in alternates.read_text()
+
     def test_git_source_preparation_uses_configured_code_dir(self):
         """Test prepare_source checks out into code_dir, not job_dir/src."""
         test_repo_dir = Path(self.temp_dir) / "test_repo"